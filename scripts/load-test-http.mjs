@@ -0,0 +1,265 @@
+#!/usr/bin/env node
+
+/**
+ * HTTP transport load test — simulates N concurrent MCP sessions issuing a
+ * weighted tool mix against a running server and reports latency percentiles,
+ * throughput, and error rates.
+ *
+ * Usage:
+ *   pnpm start:http   # in another terminal (or point --url at a deployment)
+ *   node scripts/load-test-http.mjs --sessions=20 --requests=50
+ *   node scripts/load-test-http.mjs --duration=60 --mix=harness_describe:3,harness_status:1
+ *   node scripts/load-test-http.mjs --mix-file=loadtest-mix.json --json
+ *
+ * The default mix only calls registry-local tools (harness_describe,
+ * harness_schema) so it exercises the transport and session layer without
+ * touching the Harness API. Add API-backed tools explicitly via --mix/--mix-file.
+ *
+ * The server's per-IP limit (HARNESS_MCP_IP_RATE_LIMIT_PER_MIN, default 60)
+ * caps a single load-test host; raise it or set 0 on the server under test.
+ */
+
+import { readFileSync } from "node:fs";
+import { performance } from "node:perf_hooks";
+import {
+  DEFAULT_TOOL_MIX,
+  classifyToolCallResponse,
+  createWeightedPicker,
+  parseMcpResponseBody,
+  parseToolMixSpec,
+  rateLimitWarning,
+  summarizeSamples,
+  validateToolMix,
+} from "./load-test-lib.mjs";
+
+const PROTOCOL_VERSION = "2025-03-26";
+
+function parseArgs(argv) {
+  const options = {
+    url: process.env.HARNESS_LOADTEST_URL ?? "http://127.0.0.1:3000/mcp",
+    sessions: 10,
+    requests: 20,
+    durationSec: undefined,
+    mix: DEFAULT_TOOL_MIX,
+    token: process.env.HARNESS_MCP_AUTH_TOKEN,
+    apiKey: process.env.HARNESS_LOADTEST_API_KEY,
+    json: false,
+    maxErrorRate: undefined,
+  };
+  for (const arg of argv) {
+    if (arg === "--") {
+      continue;
+    } else if (arg === "--json") {
+      options.json = true;
+    } else if (arg.startsWith("--url=")) {
+      options.url = arg.slice("--url=".length);
+    } else if (arg.startsWith("--sessions=")) {
+      options.sessions = parsePositiveInt(arg, "--sessions=");
+    } else if (arg.startsWith("--requests=")) {
+      options.requests = parsePositiveInt(arg, "--requests=");
+    } else if (arg.startsWith("--duration=")) {
+      options.durationSec = parsePositiveInt(arg, "--duration=");
+    } else if (arg.startsWith("--mix=")) {
+      options.mix = parseToolMixSpec(arg.slice("--mix=".length));
+    } else if (arg.startsWith("--mix-file=")) {
+      const path = arg.slice("--mix-file=".length);
+      options.mix = validateToolMix(JSON.parse(readFileSync(path, "utf8")));
+    } else if (arg.startsWith("--max-error-rate=")) {
+      const value = Number(arg.slice("--max-error-rate=".length));
+      if (!Number.isFinite(value) || value < 0 || value > 1) {
+        throw new Error(`Invalid --max-error-rate value: ${arg} (expected 0..1)`);
+      }
+      options.maxErrorRate = value;
+    } else if (arg === "--help" || arg === "-h") {
+      printHelp();
+      process.exit(0);
+    } else {
+      throw new Error(`Unknown argument: ${arg}`);
+    }
+  }
+  return options;
+}
+
+function parsePositiveInt(arg, prefix) {
+  const value = Number(arg.slice(prefix.length));
+  if (!Number.isInteger(value) || value <= 0) {
+    throw new Error(`Invalid ${prefix.slice(0, -1)} value: ${arg}`);
+  }
+  return value;
+}
+
+function printHelp() {
+  console.log(`HTTP transport load test
+
+Usage:
+  node scripts/load-test-http.mjs [options]
+
+Options:
+  --url=URL               MCP endpoint (default http://127.0.0.1:3000/mcp, or HARNESS_LOADTEST_URL)
+  --sessions=N            Concurrent MCP sessions (default 10)
+  --requests=N            Tool calls per session (default 20; ignored when --duration is set)
+  --duration=SECONDS      Run each session until the deadline instead of a fixed request count
+  --mix=tool:w,tool:w     Weighted tool mix with empty arguments
+  --mix-file=PATH         JSON array of { tool, weight, arguments } entries
+  --max-error-rate=R      Exit non-zero when the overall error rate exceeds R (0..1)
+  --json                  Print machine-readable JSON instead of a text report
+
+Environment:
+  HARNESS_MCP_AUTH_TOKEN     Sent as Authorization: Bearer <token> when set
+  HARNESS_LOADTEST_API_KEY   Sent as x-harness-api-key on initialize (multi-user mode)
+
+The server under test limits each client IP to HARNESS_MCP_IP_RATE_LIMIT_PER_MIN
+requests a minute (default 60). Raise it, or set 0, before load testing from one host.
+`);
+}
+
+function baseHeaders(options) {
+  return {
+    "Content-Type": "application/json",
+    Accept: "application/json, text/event-stream",
+    ...(options.token ? { Authorization: `Bearer ${options.token}` } : {}),
+  };
+}
+
+async function postRpc(options, sessionId, message) {
+  const response = await fetch(options.url, {
+    method: "POST",
+    headers: {
+      ...baseHeaders(options),
+      ...(sessionId ? { "mcp-session-id": sessionId } : { ...(options.apiKey ? { "x-harness-api-key": options.apiKey } : {}) }),
+    },
+    body: JSON.stringify(message),
+  });
+  const text = await response.text();
+  return {
+    status: response.status,
+    sessionId: response.headers.get("mcp-session-id") ?? sessionId,
+    messages: response.ok ? parseMcpResponseBody(text, response.headers.get("content-type") ?? "") : [],
+  };
+}
+
+async function openSession(options, index) {
+  const init = await postRpc(options, undefined, {
+    jsonrpc: "2.0",
+    id: 0,
+    method: "initialize",
+    params: {
+      protocolVersion: PROTOCOL_VERSION,
+      capabilities: {},
+      clientInfo: { name: "harness-mcp-loadtest", version: `session-${index}` },
+    },
+  });
+  if (init.status !== 200 || !init.sessionId) {
+    throw new Error(`initialize failed with HTTP ${init.status}`);
+  }
+  await postRpc(options, init.sessionId, { jsonrpc: "2.0", method: "notifications/initialized" });
+  return init.sessionId;
+}
+
+async function closeSession(options, sessionId) {
+  await fetch(options.url, {
+    method: "DELETE",
+    headers: { ...baseHeaders(options), "mcp-session-id": sessionId },
+  }).catch(() => {});
+}
+
+async function runSession(options, index, deadline, samples, sessionErrors) {
+  let sessionId;
+  try {
+    sessionId = await openSession(options, index);
+  } catch (err) {
+    sessionErrors.push(err instanceof Error ? err.message : String(err));
+    return;
+  }
+
+  const pick = createWeightedPicker(options.mix);
+  let id = 1;
+  const shouldContinue = () => (deadline !== undefined ? performance.now() < deadline : id <= options.requests);
+
+  while (shouldContinue()) {
+    const entry = pick();
+    const callId = id++;
+    const started = performance.now();
+    let errorKind;
+    try {
+      const result = await postRpc(options, sessionId, {
+        jsonrpc: "2.0",
+        id: callId,
+        method: "tools/call",
+        params: { name: entry.tool, arguments: entry.arguments },
+      });
+      errorKind = result.status === 200
+        ? classifyToolCallResponse(result.messages, callId)
+        : `http_${result.status}`;
+    } catch {
+      errorKind = "network";
+    }
+    samples.push({
+      tool: entry.tool,
+      latencyMs: performance.now() - started,
+      ok: errorKind === undefined,
+      ...(errorKind ? { errorKind } : {}),
+    });
+  }
+
+  await closeSession(options, sessionId);
+}
+
+function printReport(options, summary, sessionErrors) {
+  console.log("HTTP transport load test");
+  console.log(`Target: ${options.url}`);
+  console.log(`Sessions: ${options.sessions} (${sessionErrors.length} failed to initialize)`);
+  console.log(`Calls: ${summary.total_calls}, errors: ${summary.errors} (${(summary.error_rate * 100).toFixed(1)}%)`);
+  console.log(`Throughput: ${summary.throughput_rps} calls/s over ${summary.wall_clock_ms} ms`);
+  const l = summary.latency_ms;
+  console.log(`Latency ms: p50=${l.p50} p90=${l.p90} p95=${l.p95} p99=${l.p99} max=${l.max} mean=${l.mean}`);
+  if (Object.keys(summary.errors_by_kind).length > 0) {
+    console.log(`Errors by kind: ${JSON.stringify(summary.errors_by_kind)}`);
+  }
+  console.log("");
+  for (const [tool, stats] of Object.entries(summary.by_tool)) {
+    const t = stats.latency_ms;
+    console.log(`${tool}: calls=${stats.calls} errors=${stats.errors} p50=${t.p50} p95=${t.p95} p99=${t.p99}`);
+  }
+  for (const message of new Set(sessionErrors)) {
+    console.log(`Session error: ${message}`);
+  }
+}
+
+async function main() {
+  const options = parseArgs(process.argv.slice(2));
+  const samples = [];
+  const sessionErrors = [];
+
+  const started = performance.now();
+  const deadline = options.durationSec !== undefined ? started + options.durationSec * 1000 : undefined;
+  await Promise.all(
+    Array.from({ length: options.sessions }, (_, index) => runSession(options, index, deadline, samples, sessionErrors)),
+  );
+  const summary = summarizeSamples(samples, performance.now() - started);
+
+  if (options.json) {
+    console.log(JSON.stringify({
+      target: options.url,
+      sessions: options.sessions,
+      session_init_failures: sessionErrors.length,
+      ...summary,
+    }, null, 2));
+  } else {
+    printReport(options, summary, sessionErrors);
+  }
+  const warning = rateLimitWarning(summary, sessionErrors);
+  if (warning) console.error(warning);
+
+  if (sessionErrors.length === options.sessions) {
+    process.exit(1);
+  }
+  if (options.maxErrorRate !== undefined && summary.error_rate > options.maxErrorRate) {
+    process.exit(1);
+  }
+}
+
+main().catch((err) => {
+  console.error(err instanceof Error ? err.message : String(err));
+  process.exit(1);
+});
@@ -0,0 +1,203 @@
+/**
+ * Pure helpers for scripts/load-test-http.mjs — tool-mix parsing, weighted
+ * selection, MCP response decoding, and latency/error aggregation.
+ *
+ * Kept free of network and process side effects so they can be unit tested.
+ */
+
+/**
+ * Default tool mix: registry-local tools only, so a load test never reaches
+ * the Harness API unless the operator opts into API-backed tools explicitly.
+ */
+export const DEFAULT_TOOL_MIX = [
+  { tool: "harness_describe", weight: 3, arguments: {} },
+  { tool: "harness_describe", weight: 2, arguments: { search_term: "pipeline" } },
+  { tool: "harness_describe", weight: 1, arguments: { resource_type: "pipeline" } },
+  { tool: "harness_schema", weight: 1, arguments: { resource_type: "pipeline" } },
+];
+
+export const LATENCY_PERCENTILES = [50, 90, 95, 99];
+
+/**
+ * Parse a compact tool-mix spec: "harness_describe:3,harness_status:1".
+ * Weight defaults to 1 when omitted. Arguments are always empty — use a
+ * mix file for per-call arguments.
+ */
+export function parseToolMixSpec(spec) {
+  const entries = [];
+  for (const raw of spec.split(",")) {
+    const token = raw.trim();
+    if (!token) continue;
+    const [tool, weightText] = token.split(":");
+    const weight = weightText === undefined ? 1 : Number(weightText);
+    if (!tool || !/^[a-z][a-z0-9_]*$/.test(tool)) {
+      throw new Error(`Invalid tool name in mix: "${token}"`);
+    }
+    if (!Number.isFinite(weight) || weight <= 0) {
+      throw new Error(`Invalid weight in mix: "${token}" (must be a positive number)`);
+    }
+    entries.push({ tool, weight, arguments: {} });
+  }
+  if (entries.length === 0) {
+    throw new Error("Tool mix is empty");
+  }
+  return entries;
+}
+
+/**
+ * Validate a tool mix loaded from JSON: an array of
+ * `{ tool, weight?, arguments? }` objects.
+ */
+export function validateToolMix(value) {
+  if (!Array.isArray(value) || value.length === 0) {
+    throw new Error("Tool mix file must contain a non-empty JSON array");
+  }
+  return value.map((entry, index) => {
+    if (!entry || typeof entry !== "object" || typeof entry.tool !== "string" || !entry.tool) {
+      throw new Error(`Tool mix entry ${index} is missing a "tool" name`);
+    }
+    const weight = entry.weight === undefined ? 1 : Number(entry.weight);
+    if (!Number.isFinite(weight) || weight <= 0) {
+      throw new Error(`Tool mix entry ${index} (${entry.tool}) has an invalid weight`);
+    }
+    const args = entry.arguments ?? {};
+    if (typeof args !== "object" || Array.isArray(args)) {
+      throw new Error(`Tool mix entry ${index} (${entry.tool}) has non-object arguments`);
+    }
+    return { tool: entry.tool, weight, arguments: args };
+  });
+}
+
+/** Return a function that picks a mix entry with probability proportional to its weight. */
+export function createWeightedPicker(mix, random = Math.random) {
+  const total = mix.reduce((sum, entry) => sum + entry.weight, 0);
+  return () => {
+    let roll = random() * total;
+    for (const entry of mix) {
+      roll -= entry.weight;
+      if (roll < 0) return entry;
+    }
+    return mix[mix.length - 1];
+  };
+}
+
+/** Nearest-rank percentile over an ascending-sorted array. Returns 0 for empty input. */
+export function percentile(sorted, p) {
+  if (sorted.length === 0) return 0;
+  const rank = Math.ceil((p / 100) * sorted.length);
+  const index = Math.min(sorted.length - 1, Math.max(0, rank - 1));
+  return sorted[index];
+}
+
+function round(value) {
+  return Math.round(value * 100) / 100;
+}
+
+function latencyStats(latencies) {
+  const sorted = [...latencies].sort((a, b) => a - b);
+  const stats = {};
+  for (const p of LATENCY_PERCENTILES) {
+    stats[`p${p}`] = round(percentile(sorted, p));
+  }
+  const sum = sorted.reduce((acc, value) => acc + value, 0);
+  stats.mean = sorted.length > 0 ? round(sum / sorted.length) : 0;
+  stats.max = sorted.length > 0 ? round(sorted[sorted.length - 1]) : 0;
+  return stats;
+}
+
+/**
+ * Aggregate per-call samples `{ tool, latencyMs, ok, errorKind? }` into
+ * overall and per-tool latency percentiles, error rates, and throughput.
+ */
+export function summarizeSamples(samples, wallClockMs) {
+  const byTool = new Map();
+  const errorKinds = {};
+  let errors = 0;
+
+  for (const sample of samples) {
+    let bucket = byTool.get(sample.tool);
+    if (!bucket) {
+      bucket = { latencies: [], calls: 0, errors: 0 };
+      byTool.set(sample.tool, bucket);
+    }
+    bucket.calls++;
+    bucket.latencies.push(sample.latencyMs);
+    if (!sample.ok) {
+      bucket.errors++;
+      errors++;
+      const kind = sample.errorKind ?? "unknown";
+      errorKinds[kind] = (errorKinds[kind] ?? 0) + 1;
+    }
+  }
+
+  const tools = {};
+  for (const [tool, bucket] of [...byTool.entries()].sort(([a], [b]) => a.localeCompare(b))) {
+    tools[tool] = {
+      calls: bucket.calls,
+      errors: bucket.errors,
+      error_rate: round(bucket.errors / bucket.calls),
+      latency_ms: latencyStats(bucket.latencies),
+    };
+  }
+
+  return {
+    total_calls: samples.length,
+    errors,
+    error_rate: samples.length > 0 ? round(errors / samples.length) : 0,
+    throughput_rps: wallClockMs > 0 ? round(samples.length / (wallClockMs / 1000)) : 0,
+    wall_clock_ms: round(wallClockMs),
+    latency_ms: latencyStats(samples.map((sample) => sample.latencyMs)),
+    errors_by_kind: errorKinds,
+    by_tool: tools,
+  };
+}
+
+/**
+ * Warning text when HTTP 429s make up most of a run (tool calls plus failed
+ * initializes), or `undefined`. A run like that measures the server's per-IP
+ * limit, not its throughput.
+ */
+export function rateLimitWarning(summary, sessionErrors = []) {
+  const rejectedInits = sessionErrors.filter((message) => message.includes("HTTP 429")).length;
+  const attempts = summary.total_calls + sessionErrors.length;
+  const throttled = (summary.errors_by_kind.http_429 ?? 0) + rejectedInits;
+  if (attempts === 0 || throttled / attempts <= 0.5) return undefined;
+  return `Warning: ${throttled} of ${attempts} requests got HTTP 429. The server's per-IP limit is throttling this run; ` +
+    "restart it with a higher HARNESS_MCP_IP_RATE_LIMIT_PER_MIN (0 disables) or spread load across client hosts.";
+}
+
+/**
+ * Decode a Streamable HTTP response body into JSON-RPC messages. The server
+ * answers either with `application/json` or with an SSE stream whose `data:`
+ * lines each carry one JSON-RPC message.
+ */
+export function parseMcpResponseBody(text, contentType = "") {
+  if (!text.trim()) return [];
+  if (contentType.includes("text/event-stream")) {
+    const messages = [];
+    for (const event of text.split(/\r?\n\r?\n/)) {
+      const data = event
+        .split(/\r?\n/)
+        .filter((line) => line.startsWith("data:"))
+        .map((line) => line.slice("data:".length).trimStart())
+        .join("\n");
+      if (data) messages.push(JSON.parse(data));
+    }
+    return messages;
+  }
+  const parsed = JSON.parse(text);
+  return Array.isArray(parsed) ? parsed : [parsed];
+}
+
+/**
+ * Classify a tools/call JSON-RPC response. Returns `undefined` on success or
+ * a short error kind: "rpc_error" for protocol errors, "tool_error" when the
+ * tool returned `isError: true`, "no_response" when no matching id was found.
+ */
+export function classifyToolCallResponse(messages, id) {
+  const response = messages.find((message) => message && message.id === id);
+  if (!response) return "no_response";
+  if (response.error) return "rpc_error";
+  if (response.result?.isError) return "tool_error";
+  return undefined;
+}
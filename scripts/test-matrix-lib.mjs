@@ -0,0 +1,228 @@
+/**
+ * Pure helpers for scripts/test-matrix.mjs — matrix validation, argument
+ * templating, expectation checks on tool results, and per-toolset rollups.
+ *
+ * Kept free of network and process side effects so they can be unit tested.
+ */
+
+export const MATRIX_TOOLS = new Set([
+  "harness_list",
+  "harness_get",
+  "harness_describe",
+  "harness_schema",
+  "harness_search",
+  "harness_status",
+  "harness_diagnose",
+]);
+
+const SHAPE_TYPES = new Set(["object", "array", "string", "number", "boolean"]);
+
+/**
+ * Validate a parsed matrix document:
+ * `{ vars?: {NAME: value}, cases: [{ name, toolset, tool, arguments?, expect?, skip? }] }`.
+ *
+ * Only read-only tools are accepted: the matrix runs unattended against a
+ * shared sandbox and must never create, change, or delete anything.
+ */
+export function validateMatrix(doc) {
+  if (!doc || typeof doc !== "object" || !Array.isArray(doc.cases) || doc.cases.length === 0) {
+    throw new Error("Matrix must contain a non-empty \"cases\" list");
+  }
+  const vars = doc.vars ?? {};
+  if (typeof vars !== "object" || Array.isArray(vars)) {
+    throw new Error("Matrix \"vars\" must be a mapping of NAME: value");
+  }
+  const names = new Set();
+  const cases = doc.cases.map((entry, index) => {
+    const label = entry?.name ?? `#${index}`;
+    if (!entry || typeof entry !== "object" || typeof entry.name !== "string" || !entry.name) {
+      throw new Error(`Case ${index} is missing a "name"`);
+    }
+    if (names.has(entry.name)) throw new Error(`Duplicate case name "${entry.name}"`);
+    names.add(entry.name);
+    if (typeof entry.toolset !== "string" || !entry.toolset) {
+      throw new Error(`Case ${label} is missing a "toolset"`);
+    }
+    if (!MATRIX_TOOLS.has(entry.tool)) {
+      throw new Error(`Case ${label} uses "${entry.tool}"; the matrix only runs read-only tools: ${[...MATRIX_TOOLS].join(", ")}`);
+    }
+    const args = entry.arguments ?? {};
+    if (typeof args !== "object" || Array.isArray(args)) {
+      throw new Error(`Case ${label} has non-object arguments`);
+    }
+    const expect = entry.expect ?? {};
+    const status = expect.status ?? "ok";
+    if (status !== "ok" && status !== "error") {
+      throw new Error(`Case ${label} has expect.status "${status}" (use ok or error)`);
+    }
+    if (expect.type !== undefined && !SHAPE_TYPES.has(expect.type)) {
+      throw new Error(`Case ${label} has unknown expect.type "${expect.type}"`);
+    }
+    for (const key of ["has", "items_have"]) {
+      if (expect[key] !== undefined && (!Array.isArray(expect[key]) || expect[key].some((p) => typeof p !== "string"))) {
+        throw new Error(`Case ${label} expect.${key} must be a list of field paths`);
+      }
+    }
+    return {
+      name: entry.name,
+      toolset: entry.toolset,
+      tool: entry.tool,
+      arguments: args,
+      expect: { ...expect, status },
+      ...(entry.skip ? { skip: String(entry.skip) } : {}),
+    };
+  });
+  return { vars, cases };
+}
+
+/**
+ * Replace `${NAME}` placeholders in string arguments. Values come from the
+ * matrix `vars`, overridden by `env` (e.g. MATRIX_PROJECT_ID=... for NAME
+ * PROJECT_ID). An unresolved placeholder is an error, not an empty string.
+ */
+export function resolveArguments(args, vars, env = {}) {
+  const lookup = (name) => {
+    const fromEnv = env[`MATRIX_${name}`];
+    if (fromEnv !== undefined && fromEnv !== "") return fromEnv;
+    if (vars[name] !== undefined) return String(vars[name]);
+    throw new Error(`Unresolved matrix variable \${${name}} (set vars.${name} or MATRIX_${name})`);
+  };
+  const walk = (value) => {
+    if (typeof value === "string") return value.replace(/\$\{([A-Z0-9_]+)\}/g, (_, name) => lookup(name));
+    if (Array.isArray(value)) return value.map(walk);
+    if (value && typeof value === "object") {
+      return Object.fromEntries(Object.entries(value).map(([k, v]) => [k, walk(v)]));
+    }
+    return value;
+  };
+  return walk(args);
+}
+
+/** Read a dotted path (`data.items`, `items.0.identifier`) from a value. */
+export function readPath(value, path) {
+  let current = value;
+  for (const part of path.split(".")) {
+    if (current === null || current === undefined) return undefined;
+    current = current[part];
+  }
+  return current;
+}
+
+function typeOf(value) {
+  if (Array.isArray(value)) return "array";
+  if (value === null) return "null";
+  return typeof value;
+}
+
+/**
+ * Decode an MCP tools/call result into `{ isError, text, json }`. The JSON is
+ * the first text block parsed, or undefined when it is not JSON.
+ */
+export function decodeToolResult(result) {
+  const text = (result?.content ?? [])
+    .filter((block) => block?.type === "text" && typeof block.text === "string")
+    .map((block) => block.text)
+    .join("\n");
+  let json;
+  try {
+    json = result?.structuredContent ?? JSON.parse(text);
+  } catch {
+    json = undefined;
+  }
+  return { isError: result?.isError === true, text, json };
+}
+
+/**
+ * Check a decoded result against a case's expectations. Returns the list of
+ * failures; empty means the case passed.
+ */
+export function checkExpectations(expect, decoded) {
+  const failures = [];
+  if (expect.status === "error") {
+    if (!decoded.isError) failures.push("expected an error result, got success");
+    if (expect.error_match && !new RegExp(expect.error_match, "i").test(decoded.text)) {
+      failures.push(`error text does not match /${expect.error_match}/`);
+    }
+    return failures;
+  }
+  if (decoded.isError) {
+    failures.push(`expected success, got error: ${decoded.text.slice(0, 200)}`);
+    return failures;
+  }
+  const body = decoded.json;
+  if ((expect.type || expect.has || expect.items_have || expect.min_items !== undefined) && body === undefined) {
+    failures.push("result is not JSON");
+    return failures;
+  }
+  if (expect.type && typeOf(body) !== expect.type) {
+    failures.push(`expected ${expect.type}, got ${typeOf(body)}`);
+  }
+  for (const path of expect.has ?? []) {
+    if (readPath(body, path) === undefined) failures.push(`missing field ${path}`);
+  }
+  const items = Array.isArray(body?.items) ? body.items : Array.isArray(body) ? body : undefined;
+  if (expect.min_items !== undefined) {
+    if (!items) failures.push("expected an items list");
+    else if (items.length < Number(expect.min_items)) failures.push(`expected at least ${expect.min_items} items, got ${items.length}`);
+  }
+  if (expect.items_have && items) {
+    for (const [i, item] of items.entries()) {
+      for (const path of expect.items_have) {
+        if (readPath(item, path) === undefined) {
+          failures.push(`items[${i}] missing field ${path}`);
+          break;
+        }
+      }
+    }
+  }
+  return failures;
+}
+
+/**
+ * Roll case outcomes up per toolset. Skipped cases are counted separately
+ * and do not affect the pass rate.
+ */
+export function summarizeOutcomes(outcomes) {
+  const byToolset = new Map();
+  for (const outcome of outcomes) {
+    const row = byToolset.get(outcome.toolset) ?? { toolset: outcome.toolset, passed: 0, failed: 0, skipped: 0 };
+    row[outcome.status]++;
+    byToolset.set(outcome.toolset, row);
+  }
+  const rate = (passed, failed) => (passed + failed > 0 ? passed / (passed + failed) : 1);
+  const toolsets = [...byToolset.values()]
+    .map((row) => ({ ...row, pass_rate: rate(row.passed, row.failed) }))
+    .sort((a, b) => a.pass_rate - b.pass_rate || a.toolset.localeCompare(b.toolset));
+  const passed = toolsets.reduce((n, row) => n + row.passed, 0);
+  const failed = toolsets.reduce((n, row) => n + row.failed, 0);
+  const skipped = toolsets.reduce((n, row) => n + row.skipped, 0);
+  return { passed, failed, skipped, pass_rate: rate(passed, failed), toolsets };
+}
+
+/** Plain-text report: per-toolset table followed by failure details. */
+export function formatReport(summary, outcomes) {
+  const pct = (value) => `${(value * 100).toFixed(1)}%`;
+  const lines = [
+    `Test matrix: ${summary.passed} passed, ${summary.failed} failed, ${summary.skipped} skipped (${pct(summary.pass_rate)})`,
+    "",
+    "toolset".padEnd(24) + "pass".padStart(6) + "fail".padStart(6) + "skip".padStart(6) + "rate".padStart(9),
+  ];
+  for (const row of summary.toolsets) {
+    lines.push(
+      row.toolset.padEnd(24) +
+      String(row.passed).padStart(6) +
+      String(row.failed).padStart(6) +
+      String(row.skipped).padStart(6) +
+      pct(row.pass_rate).padStart(9),
+    );
+  }
+  const failures = outcomes.filter((o) => o.status === "failed");
+  if (failures.length > 0) {
+    lines.push("", "Failures:");
+    for (const outcome of failures) {
+      lines.push(`  [${outcome.toolset}] ${outcome.name}`);
+      for (const failure of outcome.failures) lines.push(`      ${failure}`);
+    }
+  }
+  return lines.join("\n");
+}
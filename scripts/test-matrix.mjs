@@ -0,0 +1,165 @@
+#!/usr/bin/env node
+
+/**
+ * Declarative test matrix — runs the tool calls listed in a YAML matrix
+ * against a live Harness sandbox account and reports per-toolset pass rates,
+ * so drift between the registry and the live APIs shows up before users hit it.
+ *
+ * Usage:
+ *   pnpm build
+ *   HARNESS_API_KEY=<sandbox pat> node scripts/test-matrix.mjs
+ *   node scripts/test-matrix.mjs --matrix=tests/matrix/sandbox.yaml --toolsets=pipelines,connectors
+ *   node scripts/test-matrix.mjs --json --report=matrix-report.json --min-pass-rate=0.95
+ *
+ * Each case is `{ name, toolset, tool, arguments, expect }`; see
+ * tests/matrix/sandbox.yaml. Only read-only tools are accepted. `${NAME}`
+ * placeholders in arguments resolve from the matrix `vars` or MATRIX_<NAME>.
+ */
+
+import { readFileSync, writeFileSync } from "node:fs";
+import { dirname, join } from "node:path";
+import { fileURLToPath } from "node:url";
+import { performance } from "node:perf_hooks";
+import YAML from "yaml";
+import { Client } from "@modelcontextprotocol/sdk/client/index.js";
+import { StdioClientTransport } from "@modelcontextprotocol/sdk/client/stdio.js";
+import {
+  checkExpectations,
+  decodeToolResult,
+  formatReport,
+  resolveArguments,
+  summarizeOutcomes,
+  validateMatrix,
+} from "./test-matrix-lib.mjs";
+
+const __dirname = dirname(fileURLToPath(import.meta.url));
+const ROOT = join(__dirname, "..");
+const SERVER_PATH = join(ROOT, "build", "index.js");
+
+function parseArgs(argv) {
+  const options = {
+    matrix: join(ROOT, "tests", "matrix", "sandbox.yaml"),
+    toolsets: undefined,
+    json: false,
+    report: undefined,
+    minPassRate: undefined,
+    timeoutMs: 60_000,
+  };
+  for (const arg of argv) {
+    if (arg === "--") {
+      continue;
+    } else if (arg === "--json") {
+      options.json = true;
+    } else if (arg.startsWith("--matrix=")) {
+      options.matrix = arg.slice("--matrix=".length);
+    } else if (arg.startsWith("--toolsets=")) {
+      options.toolsets = new Set(arg.slice("--toolsets=".length).split(",").map((t) => t.trim()).filter(Boolean));
+    } else if (arg.startsWith("--report=")) {
+      options.report = arg.slice("--report=".length);
+    } else if (arg.startsWith("--min-pass-rate=")) {
+      const value = Number(arg.slice("--min-pass-rate=".length));
+      if (!Number.isFinite(value) || value < 0 || value > 1) {
+        throw new Error(`Invalid --min-pass-rate value: ${arg} (expected 0..1)`);
+      }
+      options.minPassRate = value;
+    } else if (arg.startsWith("--timeout=")) {
+      const value = Number(arg.slice("--timeout=".length));
+      if (!Number.isInteger(value) || value <= 0) throw new Error(`Invalid --timeout value: ${arg}`);
+      options.timeoutMs = value * 1000;
+    } else if (arg === "--help" || arg === "-h") {
+      printHelp();
+      process.exit(0);
+    } else {
+      throw new Error(`Unknown argument: ${arg}`);
+    }
+  }
+  return options;
+}
+
+function printHelp() {
+  console.log(`Declarative test matrix against a live sandbox
+
+Usage:
+  node scripts/test-matrix.mjs [options]
+
+Options:
+  --matrix=PATH           YAML matrix (default tests/matrix/sandbox.yaml)
+  --toolsets=a,b          Only run cases for these toolsets
+  --timeout=SECONDS       Per-call timeout (default 60)
+  --report=PATH           Also write the JSON report to PATH
+  --min-pass-rate=R       Exit non-zero when the overall pass rate is below R (0..1)
+  --json                  Print machine-readable JSON instead of a text report
+
+Environment:
+  HARNESS_API_KEY, HARNESS_ACCOUNT_ID, HARNESS_BASE_URL   Sandbox credentials (required)
+  MATRIX_<NAME>                                           Overrides vars.<NAME> in the matrix
+`);
+}
+
+async function main() {
+  const options = parseArgs(process.argv.slice(2));
+  if (!process.env.HARNESS_API_KEY) {
+    throw new Error("HARNESS_API_KEY must be set to a sandbox account token");
+  }
+
+  const { vars, cases } = validateMatrix(YAML.parse(readFileSync(options.matrix, "utf8")));
+  const selected = options.toolsets ? cases.filter((c) => options.toolsets.has(c.toolset)) : cases;
+  const toolsets = [...new Set(selected.map((c) => c.toolset))];
+
+  const transport = new StdioClientTransport({
+    command: "node",
+    args: [SERVER_PATH, "stdio"],
+    env: {
+      ...process.env,
+      // Enable exactly the toolsets under test; never allow writes.
+      HARNESS_TOOLSETS: toolsets.join(","),
+      HARNESS_READ_ONLY: "true",
+      LOG_LEVEL: process.env.LOG_LEVEL ?? "error",
+    },
+  });
+  const client = new Client({ name: "harness-test-matrix", version: "1.0.0" });
+  await client.connect(transport);
+
+  const outcomes = [];
+  try {
+    for (const testCase of selected) {
+      if (testCase.skip) {
+        outcomes.push({ name: testCase.name, toolset: testCase.toolset, status: "skipped", reason: testCase.skip, failures: [] });
+        continue;
+      }
+      const started = performance.now();
+      let failures;
+      try {
+        const args = resolveArguments(testCase.arguments, vars, process.env);
+        const result = await client.callTool({ name: testCase.tool, arguments: args }, undefined, { timeout: options.timeoutMs });
+        failures = checkExpectations(testCase.expect, decodeToolResult(result));
+      } catch (err) {
+        failures = [`call failed: ${err instanceof Error ? err.message : String(err)}`];
+      }
+      outcomes.push({
+        name: testCase.name,
+        toolset: testCase.toolset,
+        status: failures.length === 0 ? "passed" : "failed",
+        duration_ms: Math.round(performance.now() - started),
+        failures,
+      });
+    }
+  } finally {
+    await client.close();
+  }
+
+  const summary = summarizeOutcomes(outcomes);
+  const report = { matrix: options.matrix, generated_at: new Date().toISOString(), ...summary, cases: outcomes };
+  if (options.report) writeFileSync(options.report, `${JSON.stringify(report, null, 2)}\n`);
+  console.log(options.json ? JSON.stringify(report, null, 2) : formatReport(summary, outcomes));
+
+  if (options.minPassRate !== undefined && summary.pass_rate < options.minPassRate) {
+    console.error(`Pass rate ${(summary.pass_rate * 100).toFixed(1)}% is below --min-pass-rate ${(options.minPassRate * 100).toFixed(1)}%`);
+    process.exit(1);
+  }
+}
+
+main().catch((err) => {
+  console.error(err instanceof Error ? err.message : err);
+  process.exit(1);
+});
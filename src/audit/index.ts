@@ -4,6 +4,7 @@ import { StderrSink } from "./sinks/stderr.js";
 import { JsonlFileSink } from "./sinks/jsonl-file.js";
 import { WebhookSink } from "./sinks/webhook.js";
 import { OTelSink } from "./sinks/otel.js";
+import { UsageMetricsSink } from "./sinks/usage-metrics.js";
 import { createLogger } from "../utils/logger.js";
 
 export { AuditManager } from "./manager.js";
@@ -15,6 +16,7 @@ const log = createLogger("audit");
  * Create an AuditManager with sinks enabled based on configuration.
  *
  * - StderrSink is always active
+ * - UsageMetricsSink is always active (per-account counters for /metrics)
  * - JsonlFileSink activates when HARNESS_AUDIT_FILE is set
  * - WebhookSink activates when HARNESS_AUDIT_WEBHOOK_URL is set
  * - OTelSink activates when @opentelemetry/api is importable + OTEL endpoint is set
@@ -23,6 +25,7 @@ export function createAuditManager(config: Config): AuditManager {
   const manager = new AuditManager();
 
   manager.addSink(new StderrSink());
+  manager.addSink(new UsageMetricsSink());
 
   const auditFile = (config as Record<string, unknown>).HARNESS_AUDIT_FILE as string | undefined;
   if (auditFile) {
@@ -0,0 +1,14 @@
+import type { AuditEvent, AuditSink } from "../types.js";
+import { recordUsage } from "../../utils/usage-metrics.js";
+
+/**
+ * Feeds audit events into the per-account usage counters behind GET /metrics
+ * and GET /metrics/usage. Always active; counting is in-memory and cheap.
+ */
+export class UsageMetricsSink implements AuditSink {
+  readonly name = "usage-metrics";
+
+  emit(event: AuditEvent): void {
+    recordUsage(event);
+  }
+}
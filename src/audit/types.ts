@@ -64,6 +64,8 @@ export interface AuditEvent {
   http_status?: number;
   http_method?: string;
   http_path?: string;
+  /** Conversation ID from request `_meta` when a stdio server is shared by several chats. */
+  conversation_id?: string;
 }
 
 /**
@@ -2,6 +2,7 @@ import { type Config, isPlaceholderCredential, resolveFmeApiKey } from "../confi
 import type { RequestOptions } from "./types.js";
 import { HarnessApiError } from "../utils/errors.js";
 import { RateLimiter } from "../utils/rate-limiter.js";
+import { getRequestSignal } from "../utils/request-signal.js";
 import { createLogger } from "../utils/logger.js";
 import { redactJsonString } from "../utils/redact.js";
 import { isFormDataBody } from "../utils/type-guards.js";
@@ -48,7 +49,39 @@ function deleteHeaderValues(headers: Record<string, string>, headerName: string)
   return values;
 }
 
-const BASE_BACKOFF_MS = 1000;
+/** Methods that are safe to replay without an explicit retry policy. */
+const IDEMPOTENT_METHODS = new Set(["GET", "HEAD"]);
+
+/**
+ * Whether a failed attempt may be replayed. GET/HEAD retry by default; other
+ * methods only when the operation is marked safe, or carries an idempotency
+ * key when the operation requires one. `do_not_retry` always wins.
+ */
+function isRetryableRequest(method: string, options: RequestOptions): boolean {
+  switch (options.retryPolicy) {
+    case "do_not_retry":
+      return false;
+    case "safe":
+      return true;
+    case "idempotency_key_required":
+      return getHeaderValue(options.headers ?? {}, "Idempotency-Key") !== undefined;
+    default:
+      return IDEMPOTENT_METHODS.has(method.toUpperCase());
+  }
+}
+
+/**
+ * Parse a Retry-After header (delta-seconds or HTTP-date) into milliseconds.
+ * Returns undefined when absent or unparseable.
+ */
+export function parseRetryAfter(value: string | null, now = Date.now()): number | undefined {
+  if (!value) return undefined;
+  const trimmed = value.trim();
+  if (/^\d+$/.test(trimmed)) return Number(trimmed) * 1000;
+  const date = Date.parse(trimmed);
+  if (Number.isNaN(date)) return undefined;
+  return Math.max(0, date - now);
+}
 
 /** Strip HTML tags, script/style contents, and collapse whitespace. */
 function stripHtml(html: string): string {
@@ -132,9 +165,12 @@ export type AccountIdResolver = () => string | undefined;
 export class HarnessClient {
   private readonly baseUrl: string;
   private readonly token: string;
+  private readonly authScheme: Config["HARNESS_API_AUTH_SCHEME"];
   private readonly accountId: string;
   private readonly timeout: number;
   private readonly maxRetries: number;
+  private readonly retryBaseMs: number;
+  private readonly retryMaxBackoffMs: number;
   private readonly rateLimiter: RateLimiter;
   private readonly logUnsafeBodies: boolean;
   private readonly fmeApiKey: string | undefined;
@@ -146,9 +182,12 @@ export class HarnessClient {
   constructor(config: Config) {
     this.baseUrl = config.HARNESS_BASE_URL.replace(/\/$/, "");
     this.token = config.HARNESS_API_KEY;
+    this.authScheme = config.HARNESS_API_AUTH_SCHEME ?? "api_key";
     this.accountId = config.HARNESS_ACCOUNT_ID;
     this.timeout = config.HARNESS_API_TIMEOUT_MS;
     this.maxRetries = config.HARNESS_MAX_RETRIES;
+    this.retryBaseMs = config.HARNESS_RETRY_BASE_MS ?? 1000;
+    this.retryMaxBackoffMs = config.HARNESS_RETRY_MAX_BACKOFF_MS ?? 30_000;
     this.rateLimiter = new RateLimiter(config.HARNESS_RATE_LIMIT_RPS);
     this.logUnsafeBodies = config.HARNESS_LOG_UNSAFE_BODIES;
     this.fmeApiKey = resolveFmeApiKey(config);
@@ -234,6 +273,12 @@ export class HarnessClient {
     // Preserve caller-provided auth instead of layering fallback credentials on top.
     if (getHeaderValue(headers, "authorization")) return;
 
+    // OAuth sessions forward the user's access token as a bearer.
+    if (this.authScheme === "bearer" && !getHeaderValue(headers, "x-api-key")) {
+      headers["Authorization"] = `Bearer ${this.token}`;
+      return;
+    }
+
     // Non-FME Harness services continue to use the standard API-key header.
     if (!getHeaderValue(headers, "x-api-key")) {
       headers["x-api-key"] = this.token;
@@ -277,7 +322,10 @@ export class HarnessClient {
   }
 
   async request<T>(options: RequestOptions): Promise<T> {
-    await this.rateLimiter.acquire();
+    // Without an explicit signal, follow the tool call being handled so a
+    // cancelled call stops its upstream requests too.
+    const callerSignal = options.signal ?? getRequestSignal();
+    await this.acquireToken(callerSignal);
 
     const method = options.method ?? "GET";
     const url = this.buildUrl(options);
@@ -294,26 +342,28 @@ export class HarnessClient {
     }
 
     let lastError: Error | undefined;
+    const retryable = isRetryableRequest(method, options);
+    let retryAfterMs: number | undefined;
 
     for (let attempt = 0; attempt <= this.maxRetries; attempt++) {
       if (attempt > 0) {
-        const backoff = BASE_BACKOFF_MS * Math.pow(2, attempt - 1) * (0.5 + Math.random() * 0.5);
+        const backoff = this.retryDelay(attempt, retryAfterMs);
         log.debug(`Retry attempt ${attempt}/${this.maxRetries}`, { backoffMs: Math.round(backoff) });
-        await new Promise((r) => setTimeout(r, backoff));
+        await this.sleep(backoff, callerSignal);
       }
 
       try {
         // Check if already aborted before starting the request
-        if (options.signal?.aborted) {
-          throw options.signal.reason ?? new DOMException("The operation was aborted", "AbortError");
+        if (callerSignal?.aborted) {
+          throw new HarnessApiError("Request cancelled", 499, undefined, undefined, callerSignal.reason);
         }
 
         const timeoutController = new AbortController();
         const effectiveTimeout = options.timeoutMs ?? this.timeout;
         const timer = setTimeout(() => timeoutController.abort(), effectiveTimeout);
         // Merge external signal (client disconnect) with timeout signal
-        const signal = options.signal
-          ? AbortSignal.any([options.signal, timeoutController.signal])
+        const signal = callerSignal
+          ? AbortSignal.any([callerSignal, timeoutController.signal])
           : timeoutController.signal;
 
         const formBody = isFormDataBody(options.body) ? options.body : undefined;
@@ -329,14 +379,17 @@ export class HarnessClient {
           });
         }
 
-        const response = await fetch(url, {
-          method,
-          headers,
-          body: fetchBody,
-          signal,
-        });
-
-        clearTimeout(timer);
+        let response: Response;
+        try {
+          response = await fetch(url, {
+            method,
+            headers,
+            body: fetchBody,
+            signal,
+          });
+        } finally {
+          clearTimeout(timer);
+        }
 
         if (!response.ok) {
           const body = await response.text();
@@ -365,9 +418,10 @@ export class HarnessClient {
           if (
             RETRYABLE_STATUS_CODES.has(response.status) &&
             attempt < this.maxRetries &&
-            options.retryPolicy !== "do_not_retry"
+            retryable
           ) {
             lastError = error;
+            retryAfterMs = parseRetryAfter(response.headers.get("retry-after"));
             continue;
           }
 
@@ -413,12 +467,13 @@ export class HarnessClient {
         if (err instanceof HarnessApiError) throw err;
         if (err instanceof Error && err.name === "AbortError") {
           // External signal (client disconnect) — stop immediately, don't retry
-          if (options.signal?.aborted) {
+          if (callerSignal?.aborted) {
             throw new HarnessApiError("Request cancelled", 499, undefined, undefined, err);
           }
           // Timeout — retry if allowed (and policy permits)
           lastError = new HarnessApiError("Request timed out", 408, undefined, undefined, err);
-          if (attempt < this.maxRetries && options.retryPolicy !== "do_not_retry") continue;
+          retryAfterMs = undefined;
+          if (attempt < this.maxRetries && retryable) continue;
           throw lastError;
         }
         throw new HarnessApiError(
@@ -440,7 +495,8 @@ export class HarnessClient {
    * (before body consumption). Caller is responsible for reading the body.
    */
   async requestStream(options: RequestOptions): Promise<Response> {
-    await this.rateLimiter.acquire();
+    const callerSignal = options.signal ?? getRequestSignal();
+    await this.acquireToken(callerSignal);
 
     const method = options.method ?? "POST";
     const url = this.buildUrl(options);
@@ -457,24 +513,26 @@ export class HarnessClient {
     }
 
     let lastError: Error | undefined;
+    const retryable = isRetryableRequest(method, options);
+    let retryAfterMs: number | undefined;
 
     for (let attempt = 0; attempt <= this.maxRetries; attempt++) {
       if (attempt > 0) {
-        const backoff = BASE_BACKOFF_MS * Math.pow(2, attempt - 1) * (0.5 + Math.random() * 0.5);
+        const backoff = this.retryDelay(attempt, retryAfterMs);
         log.debug(`Stream retry attempt ${attempt}/${this.maxRetries}`, { backoffMs: Math.round(backoff) });
-        await new Promise((r) => setTimeout(r, backoff));
+        await this.sleep(backoff, callerSignal);
       }
 
       try {
-        if (options.signal?.aborted) {
-          throw options.signal.reason ?? new DOMException("The operation was aborted", "AbortError");
+        if (callerSignal?.aborted) {
+          throw new HarnessApiError("Request cancelled", 499, undefined, undefined, callerSignal.reason);
         }
 
         const timeoutController = new AbortController();
         const effectiveTimeout = options.timeoutMs ?? this.timeout;
         const timer = setTimeout(() => timeoutController.abort(), effectiveTimeout);
-        const signal = options.signal
-          ? AbortSignal.any([options.signal, timeoutController.signal])
+        const signal = callerSignal
+          ? AbortSignal.any([callerSignal, timeoutController.signal])
           : timeoutController.signal;
 
         const formBody = isFormDataBody(options.body) ? options.body : undefined;
@@ -483,9 +541,12 @@ export class HarnessClient {
 
         log.debug(`STREAM ${method} ${url}`);
 
-        const response = await fetch(url, { method, headers, body: fetchBody, signal });
-
-        clearTimeout(timer);
+        let response: Response;
+        try {
+          response = await fetch(url, { method, headers, body: fetchBody, signal });
+        } finally {
+          clearTimeout(timer);
+        }
 
         if (!response.ok) {
           const body = await response.text();
@@ -501,9 +562,10 @@ export class HarnessClient {
           if (
             RETRYABLE_STATUS_CODES.has(response.status) &&
             attempt < this.maxRetries &&
-            options.retryPolicy !== "do_not_retry"
+            retryable
           ) {
             lastError = error;
+            retryAfterMs = parseRetryAfter(response.headers.get("retry-after"));
             continue;
           }
           throw error;
@@ -513,11 +575,12 @@ export class HarnessClient {
       } catch (err) {
         if (err instanceof HarnessApiError) throw err;
         if (err instanceof Error && err.name === "AbortError") {
-          if (options.signal?.aborted) {
+          if (callerSignal?.aborted) {
             throw new HarnessApiError("Request cancelled", 499, undefined, undefined, err);
           }
           lastError = new HarnessApiError("Request timed out", 408, undefined, undefined, err);
-          if (attempt < this.maxRetries && options.retryPolicy !== "do_not_retry") continue;
+          retryAfterMs = undefined;
+          if (attempt < this.maxRetries && retryable) continue;
           throw lastError;
         }
         throw new HarnessApiError(
@@ -530,6 +593,44 @@ export class HarnessClient {
     throw lastError ?? new HarnessApiError("Max retries exceeded", 500);
   }
 
+  /**
+   * Delay before retry `attempt` (1-based): exponential backoff with jitter,
+   * or the server's Retry-After when longer, never above the configured cap.
+   */
+  private retryDelay(attempt: number, retryAfterMs?: number): number {
+    const backoff = this.retryBaseMs * Math.pow(2, attempt - 1) * (0.5 + Math.random() * 0.5);
+    return Math.min(Math.max(backoff, retryAfterMs ?? 0), this.retryMaxBackoffMs);
+  }
+
+  /** Take a rate limiter token, giving up as soon as the caller aborts. */
+  private async acquireToken(signal?: AbortSignal): Promise<void> {
+    try {
+      await this.rateLimiter.acquire(signal);
+    } catch (err) {
+      if (signal?.aborted) throw new HarnessApiError("Request cancelled", 499, undefined, undefined, signal.reason);
+      throw err;
+    }
+  }
+
+  /** Wait `ms`, waking early (and rejecting) when the caller aborts. */
+  private sleep(ms: number, signal?: AbortSignal): Promise<void> {
+    return new Promise((resolve, reject) => {
+      if (signal?.aborted) {
+        reject(new HarnessApiError("Request cancelled", 499, undefined, undefined, signal.reason));
+        return;
+      }
+      const onAbort = () => {
+        clearTimeout(timer);
+        reject(new HarnessApiError("Request cancelled", 499, undefined, undefined, signal?.reason));
+      };
+      const timer = setTimeout(() => {
+        signal?.removeEventListener("abort", onAbort);
+        resolve();
+      }, ms);
+      signal?.addEventListener("abort", onAbort, { once: true });
+    });
+  }
+
   private buildUrl(options: RequestOptions): string {
     const baseUrl = (options.baseUrl ?? this.baseUrl).replace(/\/$/, "");
     let path = options.path;
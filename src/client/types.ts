@@ -59,8 +59,10 @@ export interface RequestOptions {
   /** When true, omit the automatic `accountIdentifier` query param.
    *  Some APIs (e.g. SEI) use only the `Harness-Account` header for account scoping. */
   headerBasedScoping?: boolean;
-  /** Retry policy from OperationPolicy. When "do_not_retry", transient errors
-   *  (5xx, timeouts) throw immediately instead of retrying. */
+  /** Retry policy from OperationPolicy. Unset: only GET/HEAD retry transient
+   *  errors (429, 5xx, timeouts). "safe" retries any method;
+   *  "idempotency_key_required" retries only with an Idempotency-Key header;
+   *  "do_not_retry" throws immediately. */
   retryPolicy?: "safe" | "idempotency_key_required" | "do_not_retry";
   /** Internal tracing metadata. Never serialized into HTTP headers/query/body. */
   tracing?: {
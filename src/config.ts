@@ -1,3 +1,5 @@
+import { tmpdir } from "node:os";
+import { join } from "node:path";
 import * as z from "zod/v4";
 import { normalizeHttpAllowedHost } from "./utils/http-hosts.js";
 
@@ -78,12 +80,19 @@ const RawConfigSchema = z.object({
   HARNESS_DEFAULT_PROJECT_ID: optionalStringFromEnv,
   HARNESS_API_TIMEOUT_MS: z.coerce.number().default(30000),
   HARNESS_MAX_RETRIES: z.coerce.number().default(3),
+  // Exponential backoff between retries: base * 2^(attempt-1) with jitter,
+  // never longer than the cap. Retry-After from 429/503 is honoured up to the cap.
+  HARNESS_RETRY_BASE_MS: z.coerce.number().min(1).default(1000),
+  HARNESS_RETRY_MAX_BACKOFF_MS: z.coerce.number().min(1).default(30_000),
   // Idle HTTP sessions are reaped after this many ms once no request or SSE
   // stream is active. Kept generous (30 min) so interactive clients (e.g. the
   // claude.ai connector, which does not hold a persistent SSE stream between
   // prompts) are not evicted mid-conversation, which surfaces to users as
   // repeated "Session not found" → re-authenticate prompts.
   MCP_SESSION_TTL_MS: z.coerce.number().min(1).default(30 * 60_000),
+  // Interval for SSE comment heartbeats on legacy `sse` transport streams, so
+  // proxies and load balancers do not cut idle connections. 0 disables.
+  HARNESS_SSE_HEARTBEAT_MS: z.coerce.number().int().min(0).default(15_000),
   LOG_LEVEL: z.preprocess(
     (val) => (val === "" ? undefined : val),
     z.enum(["debug", "info", "warn", "error"]).default("info"),
@@ -92,6 +101,9 @@ const RawConfigSchema = z.object({
   HARNESS_MAX_BODY_SIZE_MB: z.coerce.number().default(10),
   HARNESS_RATE_LIMIT_RPS: z.coerce.number().default(10),
   HARNESS_READ_ONLY: booleanFromEnv.default(false),
+  // Record writes in a per-session undo log (with the entity state before
+  // updates and deletes where it can be read) so undo_last_change can reverse them.
+  HARNESS_UNDO_LOG: booleanFromEnv.default(false),
   HARNESS_SKIP_ELICITATION: booleanFromEnv.default(false),
   HARNESS_AUTO_APPROVE_RISK: z.preprocess(
     emptyStringAsUndefined,
@@ -100,13 +112,88 @@ const RawConfigSchema = z.object({
   HARNESS_ALLOW_HTTP: booleanFromEnv.default(false),
   HARNESS_MCP_ALLOWED_HOSTS: optionalStringFromEnv.transform(validateAllowedHosts),
   HARNESS_MCP_AUTH_TOKEN: optionalStringFromEnv,
+  // HS256 secret for signed HTTP bearer tokens. An embedding surface mints a
+  // JWT with a `toolsets` claim; sessions opened with it only see those
+  // toolsets (intersected with HARNESS_TOOLSETS). The static
+  // HARNESS_MCP_AUTH_TOKEN keeps working and is not restricted.
+  HARNESS_MCP_ENTITLEMENTS_SECRET: z.preprocess(
+    emptyStringAsUndefined,
+    z.string().min(32, "HARNESS_MCP_ENTITLEMENTS_SECRET must be at least 32 characters").optional(),
+  ),
   HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP: booleanFromEnv.default(false),
+  // OAuth 2.1 resource-server mode for HTTP transport. Bearer access tokens
+  // issued by this authorization server (Harness OIDC) are checked via RFC 7662
+  // token introspection. HARNESS_MCP_OAUTH_RESOURCE is this server's public
+  // MCP URL, advertised in /.well-known/oauth-protected-resource and required
+  // in the token audience.
+  HARNESS_MCP_OAUTH_ISSUER: z.preprocess(emptyStringAsUndefined, z.string().url().optional()),
+  HARNESS_MCP_OAUTH_RESOURCE: z.preprocess(emptyStringAsUndefined, z.string().url().optional()),
+  HARNESS_MCP_OAUTH_INTROSPECTION_URL: z.preprocess(emptyStringAsUndefined, z.string().url().optional()),
+  HARNESS_MCP_OAUTH_CLIENT_ID: optionalStringFromEnv,
+  HARNESS_MCP_OAUTH_CLIENT_SECRET: optionalStringFromEnv,
+  HARNESS_MCP_OAUTH_SCOPES: z.preprocess(emptyStringAsUndefined, z.string().default("openid")),
+  // Scope-escalation guardrail. Requests whose org_id/project_id differ from
+  // the pinned HARNESS_ORG/HARNESS_PROJECT (or session headers) are flagged
+  // ("warn") or rejected ("block") unless the caller passes cross_scope: true.
+  HARNESS_SCOPE_GUARD: z.preprocess(
+    emptyStringAsUndefined,
+    z.enum(["off", "warn", "block"]).default("warn"),
+  ),
   // Number of proxy hops to trust for client IP resolution (Express `trust
   // proxy`). Set to the count of reverse proxies / load balancers in front of
   // the server so per-IP rate limiting keys on the real client rather than the
   // proxy socket peer (which would bucket every user together). Default 0
   // (trust nothing) preserves prior behaviour for direct binds.
   HARNESS_MCP_TRUST_PROXY: z.coerce.number().int().min(0).default(0),
+  // HTTP requests per minute per client IP, counted before the body is parsed.
+  // Raise it (or set 0 to disable) for load tests from a single host.
+  HARNESS_MCP_IP_RATE_LIMIT_PER_MIN: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(0).default(60)),
+  // Token-bucket limits on tools/call in HTTP mode, keyed by principal (OAuth
+  // subject, session API key, or client IP). Calls per minute across all tools,
+  // per tool, and per-tool overrides as "harness_execute=10,harness_list=120".
+  // 0 disables a limit. Throttled calls are counted on GET /metrics.
+  HARNESS_TOOL_RATE_LIMIT_PER_MIN: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(0).default(0)),
+  HARNESS_TOOL_RATE_LIMIT_PER_TOOL_PER_MIN: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(0).default(0)),
+  HARNESS_TOOL_RATE_LIMITS: optionalStringFromEnv,
+  // Distinct account label values on /metrics before further accounts are
+  // folded into account="__other__", bounding Prometheus series count.
+  HARNESS_METRICS_MAX_ACCOUNTS: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(1).default(50)),
+  // HTTP mode: push usage snapshots (calls, error rates, busiest accounts and
+  // tools) to this Harness custom dashboard every HARNESS_USAGE_EXPORT_INTERVAL_MS.
+  // Pushed with HARNESS_USAGE_DASHBOARD_API_KEY, or HARNESS_API_KEY when unset.
+  // See utils/usage-export.ts.
+  HARNESS_USAGE_DASHBOARD_ID: optionalStringFromEnv,
+  HARNESS_USAGE_DASHBOARD_API_KEY: optionalStringFromEnv,
+  HARNESS_USAGE_EXPORT_INTERVAL_MS: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(60_000).default(300_000)),
+  // Fraction of tool calls whose result size is measured for the context cost
+  // report (harness_describe context_cost=true, /metrics). 0 disables.
+  HARNESS_CONTEXT_COST_SAMPLE_RATE: z.preprocess(emptyStringAsUndefined, z.coerce.number().min(0).max(1).default(1)),
+  // Post-processors run on tool results before they are returned: a JSON
+  // array of rules, or the path of a JSON file holding one. Each rule matches
+  // tools by glob and runs a built-in processor or an HTTP webhook. Parsed and
+  // validated at startup by utils/result-processors.ts.
+  HARNESS_RESULT_PROCESSORS: optionalStringFromEnv,
+  // Time fields in tool results: "raw" (default) returns them as Harness sent
+  // them; "rfc3339" opts in to rewriting epoch values to RFC 3339 strings and
+  // keeps the instant under <field>_epoch_ms. See utils/time-fields.ts.
+  HARNESS_TIME_FORMAT: z.preprocess(emptyStringAsUndefined, z.enum(["rfc3339", "raw"]).default("raw")),
+  // Tool descriptions in tools/list: "full" sends all usage guidance; "short"
+  // sends first sentences and leaves the rest to harness_describe(tool=...).
+  // See utils/tool-descriptions.ts.
+  HARNESS_TOOL_DESCRIPTIONS: z.preprocess(emptyStringAsUndefined, z.enum(["full", "short"]).default("full")),
+  // Individual-contributor SEI metrics: "off" removes the per-developer
+  // resources, "aggregate" reduces their results to team-level distributions
+  // (suppressed below HARNESS_SEI_MIN_GROUP_SIZE developers), "individual"
+  // returns per-developer rows. See utils/developer-privacy.ts.
+  HARNESS_SEI_DEVELOPER_METRICS: z.preprocess(emptyStringAsUndefined, z.enum(["off", "aggregate", "individual"]).default("aggregate")),
+  HARNESS_SEI_MIN_GROUP_SIZE: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(1).default(5)),
+  // How HARNESS_API_KEY is sent to Harness: as the x-api-key header (PAT/SAT)
+  // or as an Authorization bearer. OAuth sessions in multi-user mode switch
+  // to "bearer" automatically to forward the user's access token.
+  HARNESS_API_AUTH_SCHEME: z.preprocess(
+    emptyStringAsUndefined,
+    z.enum(["api_key", "bearer"]).default("api_key"),
+  ),
   HARNESS_FME_API_KEY: optionalStringFromEnv,
   HARNESS_FME_BASE_URL: urlFromEnv("https://api.split.io"),
   HARNESS_LOG_UNSAFE_BODIES: booleanFromEnv.default(false),
@@ -116,6 +203,23 @@ const RawConfigSchema = z.object({
   HARNESS_AUDIT_WEBHOOK_TOKEN: optionalStringFromEnv,
   HARNESS_AUDIT_WEBHOOK_BATCH_SIZE: z.preprocess(emptyStringAsUndefined, z.coerce.number().min(1).default(10)),
   HARNESS_AUDIT_WEBHOOK_FLUSH_MS: z.preprocess(emptyStringAsUndefined, z.coerce.number().min(1).default(5000)),
+  // Response cache for read-only list/get calls, per MCP session. 0 disables.
+  // HARNESS_CACHE_TOOLSETS limits caching to the listed toolsets (default all).
+  // Any successful write in the session clears the cache.
+  HARNESS_CACHE_TTL_MS: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(0).default(0)),
+  HARNESS_CACHE_MAX_ENTRIES: z.preprocess(emptyStringAsUndefined, z.coerce.number().int().min(1).default(500)),
+  HARNESS_CACHE_TOOLSETS: optionalStringFromEnv,
+  // Account-wide fan-out (harness_list all_projects=true): per-project list
+  // calls in flight, and the wall-clock budget before returning a partial
+  // result with a continuation cursor.
+  HARNESS_FANOUT_CONCURRENCY: z.preprocess(
+    emptyStringAsUndefined,
+    z.coerce.number().int().min(1).max(32).default(8),
+  ),
+  HARNESS_FANOUT_BUDGET_MS: z.preprocess(
+    emptyStringAsUndefined,
+    z.coerce.number().int().min(1000).default(25_000),
+  ),
   // Maximum number of concurrent log-blob downloads issued by harness_diagnose
   // when fetching logs for failed steps. Default 3 keeps peak memory bounded
   // while still parallelising the common case (1–3 failed steps). Increase
@@ -139,10 +243,18 @@ const RawConfigSchema = z.object({
   HARNESS_SEARCH_SERVICE_HEADERS: optionalStringFromEnv,
   // Directory for @huggingface/transformers model cache (local search provider).
   // Use a persistent volume in production; Docker image bakes models into /app/.cache/hf.
-  HARNESS_HF_CACHE_DIR: z.preprocess(
-    emptyStringAsUndefined,
-    z.string().default("/tmp/hf-cache"),
-  ),
+  // Defaults to <HARNESS_TEMP_DIR>/hf-cache.
+  HARNESS_HF_CACHE_DIR: optionalStringFromEnv,
+  // Scratch directory for caches the server creates, and for the temp files
+  // that output_dir exports are written through before being moved into place.
+  // Cache default: the OS temp directory (/tmp, or %TEMP% on Windows); unset,
+  // export temp files are written next to their target.
+  HARNESS_TEMP_DIR: optionalStringFromEnv,
+  // Directory tools may write output_dir files under (audit SIEM export, list
+  // export, SBOM download, agent manifests, tokens). Paths outside it are
+  // rejected and relative paths resolve against it. Over HTTP, output_dir is
+  // refused unless this is set.
+  HARNESS_OUTPUT_ROOT: optionalStringFromEnv,
 });
 
 export const ConfigSchema = RawConfigSchema.transform((data) => {
@@ -201,6 +313,34 @@ export const ConfigSchema = RawConfigSchema.transform((data) => {
     );
   }
 
+  if (data.HARNESS_USAGE_DASHBOARD_ID && !data.HARNESS_USAGE_DASHBOARD_API_KEY && !data.HARNESS_API_KEY) {
+    throw new Error(
+      "HARNESS_USAGE_DASHBOARD_API_KEY is required when HARNESS_USAGE_DASHBOARD_ID is set in multi-user mode. " +
+      "Use a service account token with permission to edit the dashboard.",
+    );
+  }
+
+  if (data.HARNESS_MCP_OAUTH_ISSUER) {
+    if (!data.HARNESS_MCP_OAUTH_RESOURCE) {
+      throw new Error(
+        "HARNESS_MCP_OAUTH_RESOURCE is required when HARNESS_MCP_OAUTH_ISSUER is set. " +
+        "Set it to the public URL of this server's /mcp endpoint.",
+      );
+    }
+    for (const [name, value] of [
+      ["HARNESS_MCP_OAUTH_ISSUER", data.HARNESS_MCP_OAUTH_ISSUER],
+      ["HARNESS_MCP_OAUTH_RESOURCE", data.HARNESS_MCP_OAUTH_RESOURCE],
+      ["HARNESS_MCP_OAUTH_INTROSPECTION_URL", data.HARNESS_MCP_OAUTH_INTROSPECTION_URL],
+    ] as const) {
+      if (value && !value.startsWith("https://") && !data.HARNESS_ALLOW_HTTP) {
+        throw new Error(
+          `${name} must use HTTPS (got "${value}"). ` +
+          "If you need HTTP for local development, set HARNESS_ALLOW_HTTP=true.",
+        );
+      }
+    }
+  }
+
   // Resolve org/project: prefer new names, fall back to deprecated names
   if (!data.HARNESS_ORG && data.HARNESS_DEFAULT_ORG_ID) {
     console.error('[DEPRECATION] HARNESS_DEFAULT_ORG_ID is deprecated. Use HARNESS_ORG instead.');
@@ -224,7 +364,9 @@ export const ConfigSchema = RawConfigSchema.transform((data) => {
   // Remove deprecated keys from output, expose only the canonical names
   const { HARNESS_DEFAULT_ORG_ID: _oldOrg, HARNESS_DEFAULT_PROJECT_ID: _oldProject, ...rest } = data;
 
-  return { ...rest, HARNESS_API_KEY: data.HARNESS_API_KEY ?? "", HARNESS_ACCOUNT_ID: accountId, HARNESS_ORG, HARNESS_PROJECT, HARNESS_AUTO_APPROVE_RISK };
+  const HARNESS_HF_CACHE_DIR = data.HARNESS_HF_CACHE_DIR ?? join(data.HARNESS_TEMP_DIR ?? tmpdir(), "hf-cache");
+
+  return { ...rest, HARNESS_API_KEY: data.HARNESS_API_KEY ?? "", HARNESS_ACCOUNT_ID: accountId, HARNESS_ORG, HARNESS_PROJECT, HARNESS_AUTO_APPROVE_RISK, HARNESS_HF_CACHE_DIR };
 });
 
 export type Config = z.infer<typeof ConfigSchema>;
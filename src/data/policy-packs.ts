@@ -0,0 +1,215 @@
+/**
+ * Built-in OPA policy packs for governance rollout.
+ *
+ * Each pack is a small set of Rego policies that share one entity type and
+ * one enforcement action, so it installs as a single policy set. The Rego
+ * follows the Harness policy samples and evaluates the entity as Harness
+ * passes it to OPA (e.g. `input.pipeline` for pipeline policies). Adding a
+ * pack is a data-only change.
+ */
+
+export type PolicyPackCategory = "security" | "cost" | "quality";
+
+export interface PolicyPackPolicy {
+  /** Policy identifier used when the pack is installed. */
+  identifier: string;
+  name: string;
+  /** What the policy denies, in one sentence. */
+  description: string;
+  rego: string;
+}
+
+export interface PolicyPack {
+  /** Stable identifier; also the identifier of the installed policy set. */
+  id: string;
+  name: string;
+  category: PolicyPackCategory;
+  description: string;
+  /** Policy set entity type (policy_set `type`). */
+  entityType: string;
+  /** Policy set enforcement action (policy_set `action`). */
+  action: string;
+  policies: PolicyPackPolicy[];
+}
+
+export const POLICY_PACKS: PolicyPack[] = [
+  {
+    id: "pipeline_security_baseline",
+    name: "Pipeline Security Baseline",
+    category: "security",
+    description: "Guard production deployments and keep secrets out of pipeline YAML.",
+    entityType: "pipeline",
+    action: "onrun",
+    policies: [
+      {
+        identifier: "require_approval_before_prod",
+        name: "Require approval before production deployments",
+        description: "Denies pipelines that deploy to a Production environment without an approval stage earlier in the pipeline.",
+        rego: `package pipeline
+
+deny[msg] {
+  some i
+  stage := input.pipeline.stages[i].stage
+  stage.type == "Deployment"
+  stage.spec.environment.environmentRef != null
+  contains(lower(stage.spec.environment.environmentRef), "prod")
+  not approval_before(i)
+  msg := sprintf("Deployment stage '%s' targets production without a preceding approval stage", [stage.name])
+}
+
+approval_before(i) {
+  some j
+  j < i
+  input.pipeline.stages[j].stage.type == "Approval"
+}
+`,
+      },
+      {
+        identifier: "no_plaintext_secret_variables",
+        name: "No plaintext secrets in variables",
+        description: "Denies String variables whose name suggests a credential (password, token, secret, key) and whose value is not an expression.",
+        rego: `package pipeline
+
+secret_names := ["password", "passwd", "token", "secret", "api_key", "apikey", "private_key"]
+
+deny[msg] {
+  variable := input.pipeline.variables[_]
+  variable.type == "String"
+  name := lower(variable.name)
+  contains(name, secret_names[_])
+  not startswith(variable.value, "<+")
+  msg := sprintf("Variable '%s' looks like a credential; use a Secret variable or <+secrets.getValue()> instead", [variable.name])
+}
+`,
+      },
+    ],
+  },
+  {
+    id: "connector_security_baseline",
+    name: "Connector Security Baseline",
+    category: "security",
+    description: "Reject connectors saved with anonymous or password-in-URL access.",
+    entityType: "connector",
+    action: "onsave",
+    policies: [
+      {
+        identifier: "deny_anonymous_docker_registries",
+        name: "Deny anonymous Docker registry connectors",
+        description: "Denies Docker registry connectors that use anonymous authentication.",
+        rego: `package connector
+
+deny[msg] {
+  input.entity.type == "DockerRegistry"
+  input.entity.spec.auth.type == "Anonymous"
+  msg := sprintf("Docker connector '%s' must authenticate; anonymous access is not allowed", [input.entity.name])
+}
+`,
+      },
+      {
+        identifier: "deny_credentials_in_urls",
+        name: "Deny credentials embedded in connector URLs",
+        description: "Denies connectors whose URL carries a user:password@ segment.",
+        rego: `package connector
+
+deny[msg] {
+  url := input.entity.spec.url
+  regex.match("^[a-z]+://[^/@]+:[^/@]+@", url)
+  msg := sprintf("Connector '%s' embeds credentials in its URL; store them in a secret", [input.entity.name])
+}
+`,
+      },
+    ],
+  },
+  {
+    id: "ci_cost_controls",
+    name: "CI Cost Controls",
+    category: "cost",
+    description: "Keep build minutes bounded: every step has a timeout and fan-out is capped.",
+    entityType: "pipeline",
+    action: "onrun",
+    policies: [
+      {
+        identifier: "require_step_timeouts",
+        name: "Require step timeouts",
+        description: "Denies CI steps without an explicit timeout, so a hung build cannot run for the default maximum.",
+        rego: `package pipeline
+
+deny[msg] {
+  stage := input.pipeline.stages[_].stage
+  stage.type == "CI"
+  step := stage.spec.execution.steps[_].step
+  not step.timeout
+  msg := sprintf("Step '%s' in stage '%s' has no timeout", [step.name, stage.name])
+}
+`,
+      },
+      {
+        identifier: "cap_parallelism",
+        name: "Cap looping strategy parallelism",
+        description: "Denies stages or steps whose looping strategy runs more than 10 instances in parallel.",
+        rego: `package pipeline
+
+max_parallelism := 10
+
+deny[msg] {
+  [path, value] := walk(input.pipeline)
+  path[count(path) - 1] == "strategy"
+  value.parallelism > max_parallelism
+  msg := sprintf("Looping strategy runs %d instances in parallel; the limit is %d", [value.parallelism, max_parallelism])
+}
+
+deny[msg] {
+  [path, value] := walk(input.pipeline)
+  path[count(path) - 1] == "strategy"
+  value.matrix.maxConcurrency > max_parallelism
+  msg := sprintf("Matrix maxConcurrency %d exceeds the limit of %d", [value.matrix.maxConcurrency, max_parallelism])
+}
+`,
+      },
+    ],
+  },
+  {
+    id: "pipeline_quality_gates",
+    name: "Pipeline Quality Gates",
+    category: "quality",
+    description: "Require tests in builds and a rollback path in deployments.",
+    entityType: "pipeline",
+    action: "onrun",
+    policies: [
+      {
+        identifier: "require_ci_tests",
+        name: "Require a test step in CI stages",
+        description: "Denies CI stages without a RunTests or Test step.",
+        rego: `package pipeline
+
+test_step_types := {"RunTests", "Test"}
+
+deny[msg] {
+  stage := input.pipeline.stages[_].stage
+  stage.type == "CI"
+  not has_test_step(stage)
+  msg := sprintf("CI stage '%s' has no test step", [stage.name])
+}
+
+has_test_step(stage) {
+  test_step_types[stage.spec.execution.steps[_].step.type]
+}
+`,
+      },
+      {
+        identifier: "require_rollback_steps",
+        name: "Require rollback steps in deployments",
+        description: "Denies Deployment stages with no rollback steps.",
+        rego: `package pipeline
+
+deny[msg] {
+  stage := input.pipeline.stages[_].stage
+  stage.type == "Deployment"
+  count(object.get(stage.spec.execution, "rollbackSteps", [])) == 0
+  msg := sprintf("Deployment stage '%s' has no rollback steps", [stage.name])
+}
+`,
+      },
+    ],
+  },
+];
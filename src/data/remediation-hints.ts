@@ -0,0 +1,123 @@
+/**
+ * Remediation hints for common Harness API error signatures.
+ *
+ * Each entry matches an error by message pattern (and optionally HTTP status)
+ * and carries the steps an agent or user should take next. The engine in
+ * src/utils/remediation.ts picks the first matching entry, so keep specific
+ * signatures above generic ones. Adding a hint is a data-only change.
+ */
+
+export interface RemediationHint {
+  /** Stable identifier, surfaced to clients as `remediation.id`. */
+  id: string;
+  /** One-line summary of the likely cause. */
+  title: string;
+  match: {
+    /** Tested against the error message (case-insensitive patterns recommended). */
+    pattern: RegExp;
+    /**
+     * Only match these HTTP statuses. When the caller has no status (a wrapped
+     * message), the message itself must mention one of them, e.g. "HTTP 403".
+     */
+    status?: number[];
+  };
+  /** Ordered remediation steps. */
+  steps: string[];
+  /** Optional documentation link. */
+  see?: string;
+}
+
+export const REMEDIATION_HINTS: RemediationHint[] = [
+  {
+    id: "logs-forbidden",
+    title: "The token cannot read execution logs",
+    match: { pattern: /(execution logs|log-service|log blob)[\s\S]*(403|forbidden|access denied)/i, status: [403] },
+    steps: [
+      "Grant the principal behind HARNESS_API_KEY the Pipeline View permission in the execution's project; log downloads are authorized against the pipeline, not the account.",
+      "If the account restricts log access by IP allowlist, add the MCP server's egress IP.",
+      "As a fallback, call harness_diagnose with options.include_logs=true, which returns the failing step's error summary without the raw log blob.",
+    ],
+    see: "https://developer.harness.io/docs/platform/role-based-access-control/permissions-reference",
+  },
+  {
+    id: "no-eligible-delegates",
+    title: "No delegate can run this task",
+    match: { pattern: /no eligible delegates?|no delegates? (are )?available|delegates? (is |are )?not available/i },
+    steps: [
+      "Call harness_diagnose with resource_type=delegate to check that at least one delegate is connected and heartbeating.",
+      "Compare the step or connector's delegate selectors with the tags on the connected delegates; a selector that matches no delegate produces this error.",
+      "Confirm the delegate's scope (account, org, or project) covers the project the pipeline runs in.",
+      "If the delegate is connected but rejects the task, check its capability checks (network reachability to the target, installed binaries).",
+    ],
+    see: "https://developer.harness.io/docs/platform/delegates/manage-delegates/select-delegates-with-selectors",
+  },
+  {
+    id: "expression-resolution",
+    title: "A <+...> expression could not be resolved",
+    match: { pattern: /(unresolved|could not resolve|failed to resolve|cannot resolve|invalid) (expression|yaml expression)|expression[\s\S]{0,40}(could not be resolved|not resolved|evaluat)/i },
+    steps: [
+      "Check the expression path for typos; stage and step identifiers in <+pipeline.stages.ID.spec...> are case-sensitive.",
+      "Make sure the referenced stage or step ran before the expression is evaluated; outputs of later or skipped steps are null.",
+      "For runtime inputs, confirm the value was supplied at execution time (harness_get resource_type=runtime_input_template lists the required fields).",
+      "For secrets, use <+secrets.getValue(\"id\")> with the scoped identifier (org. or account. prefix) when the secret is not in the project.",
+    ],
+    see: "https://developer.harness.io/docs/platform/variables-and-expressions/harness-variables",
+  },
+  {
+    id: "invalid-api-key",
+    title: "The API key is invalid, expired, or for another account",
+    match: { pattern: /unauthori[sz]ed|invalid (api )?(key|token)|token (has )?expired|expired token/i, status: [401] },
+    steps: [
+      "Verify HARNESS_API_KEY is a current PAT or service account token (pat.<accountId>.<tokenId>.<secret>).",
+      "Check that HARNESS_ACCOUNT_ID matches the account embedded in the token.",
+      "Rotate the token if it has passed its expiry date.",
+    ],
+  },
+  {
+    id: "rbac-forbidden",
+    title: "The principal lacks permission for this action",
+    match: { pattern: /forbidden|access denied|not authori[sz]ed|permission|missing.*role/i, status: [403] },
+    steps: [
+      "Check the role bindings of the principal behind HARNESS_API_KEY for the resource type and scope in the request.",
+      "Confirm the org and project identifiers are correct; a wrong scope often surfaces as 403 rather than 404.",
+      "If the account uses IP allowlists, add the MCP server's egress IP.",
+    ],
+    see: "https://developer.harness.io/docs/platform/role-based-access-control/rbac-in-harness",
+  },
+  {
+    id: "secret-not-found",
+    title: "A referenced secret does not exist at this scope",
+    match: { pattern: /secret[\s\S]{0,80}(not found|does not exist|doesn't exist)/i },
+    steps: [
+      "List secrets with harness_list resource_type=secret at project, org, and account scope to find where it lives.",
+      "Reference org- or account-level secrets with the org. or account. prefix.",
+    ],
+  },
+  {
+    id: "rate-limited",
+    title: "Harness rate-limited the request",
+    match: { pattern: /rate limit|too many requests/i, status: [429] },
+    steps: [
+      "Wait before retrying; the server already retries with backoff, so repeated 429s mean sustained pressure.",
+      "Reduce page size or fan-out (fewer parallel harness_list calls) and enable HARNESS_CACHE_TTL_MS to answer repeated reads locally.",
+    ],
+  },
+  {
+    id: "git-branch-not-found",
+    title: "The Git branch or file for a remote entity was not found",
+    match: { pattern: /(branch|file path|filepath)[\s\S]{0,60}(not found|does not exist)|invalid branch/i },
+    steps: [
+      "Pass the branch that holds the entity (params.branch) for remote pipelines and templates.",
+      "Check that the Git connector can read the repository and that the file path is correct.",
+    ],
+  },
+  {
+    id: "module-not-licensed",
+    title: "The account has no license for this module",
+    match: { pattern: /not licensed|license (is )?(not|expired|missing)|module is not enabled/i },
+    steps: [
+      "Confirm the module (CI, CD, STO, CCM, FF, ...) is enabled for the account in Account Settings > Subscriptions.",
+      "Restrict HARNESS_TOOLSETS to licensed modules to avoid calling unlicensed APIs.",
+    ],
+  },
+];
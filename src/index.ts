@@ -1,12 +1,15 @@
 #!/usr/bin/env node
 
 import { randomUUID } from "node:crypto";
-import { appendFileSync } from "node:fs";
+import { appendFileSync, readFileSync } from "node:fs";
+import { Client } from "@modelcontextprotocol/sdk/client/index.js";
+import { InMemoryTransport } from "@modelcontextprotocol/sdk/inMemory.js";
 import { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
 import { StdioServerTransport } from "@modelcontextprotocol/sdk/server/stdio.js";
 import { StreamableHTTPServerTransport } from "@modelcontextprotocol/sdk/server/streamableHttp.js";
+import { SSEServerTransport } from "@modelcontextprotocol/sdk/server/sse.js";
 import { json } from "express";
-import { loadConfig, type Config } from "./config.js";
+import { extractAccountIdFromToken, loadConfig, type Config } from "./config.js";
 import { setLogLevel, createLogger } from "./utils/logger.js";
 import { HarnessClient } from "./client/harness-client.js";
 import { Registry } from "./registry/index.js";
@@ -16,14 +19,34 @@ import { registerAllPrompts } from "./prompts/index.js";
 import { parseArgs, resolvePort, getVersion } from "./utils/cli.js";
 import { configureElicitation } from "./utils/elicitation.js";
 import { resolveHttpHostValidationOptions } from "./utils/http-hosts.js";
-import { createHttpAuthMiddleware, validateHttpAuthForBindHost } from "./utils/http-auth.js";
+import { createHttpAuthMiddleware, getOAuthPrincipal, isOperatorRequest, validateHttpAuthForBindHost } from "./utils/http-auth.js";
+import {
+  OAUTH_AUTHORIZATION_SERVER_PATH,
+  OAUTH_PROTECTED_RESOURCE_PATH,
+  TokenIntrospector,
+  buildAuthorizationServerMetadata,
+  buildProtectedResourceMetadata,
+  isOAuthEnabled,
+} from "./utils/http-oauth.js";
 import { loadEnvFile } from "./utils/env.js";
 import { createAuditManager, type AuditManager } from "./audit/index.js";
 import { SearchManager } from "./search/index.js";
 import { mergeConfigWithSessionHeaders, MissingSessionCredentialsError } from "./utils/session-headers.js";
+import { parseSessionEntitlements, InvalidEntitlementsError } from "./utils/http-entitlements.js";
 import { buildHttpHealthResponse } from "./utils/http-health.js";
+import { ToolRateLimiter, createIpRateLimiter, createToolRateLimitMiddleware, renderToolRateLimitMetrics, toolRateLimitOptions } from "./utils/http-tool-rate-limit.js";
+import { configureUsageMetrics, renderUsageMetrics, summarizeUsageByAccount } from "./utils/usage-metrics.js";
+import { UsageDashboardExporter } from "./utils/usage-export.js";
+import { configureContextCost, renderContextCostMetrics, summarizeContextCost } from "./utils/context-cost.js";
+import { configureResultProcessors, loadResultProcessors, type ResultProcessorRule } from "./utils/result-processors.js";
 import { beginSessionRequest, endSessionRequest, isSessionExpired, type HttpSessionActivity } from "./utils/http-sessions.js";
 import { createHarnessHttpExpressApp } from "./utils/http-app.js";
+import { isolateConversations } from "./utils/conversation-context.js";
+import { collectSupportBundle, defaultLogFilePath, writeSupportBundle } from "./utils/support-bundle.js";
+import { configureOutputRoot } from "./utils/output-paths.js";
+import { ConfigReloader } from "./utils/config-reload.js";
+import { parsePlaybook, parsePlaybookVars, runPlaybook, toolResultData } from "./utils/playbook.js";
+import { LEGACY_MESSAGES_PATH, LEGACY_SSE_PATH, legacySseSessionId, startSseHeartbeat } from "./utils/http-sse.js";
 
 
 const log = createLogger("main");
@@ -37,11 +60,19 @@ interface HarnessServerResult {
 /**
  * Create a fully-configured MCP server instance with all tools, resources, and prompts.
  * @param sharedAuditManager When set (HTTP mode), reuse this manager instead of creating one per session.
+ * @param entitledToolsets When set (HTTP mode with signed bearer tokens), restrict the session to these toolsets.
+ * @param operator Serve operator-only resources (support:///bundle). True for stdio; HTTP sessions need the static auth token.
  */
-function createHarnessServer(config: Config, sharedAuditManager?: AuditManager, sharedSearchManager?: SearchManager): HarnessServerResult {
+function createHarnessServer(
+  config: Config,
+  sharedAuditManager?: AuditManager,
+  sharedSearchManager?: SearchManager,
+  entitledToolsets?: ReadonlySet<string>,
+  operator = false,
+): HarnessServerResult {
   const auditManager = sharedAuditManager ?? createAuditManager(config);
   const client = new HarnessClient(config);
-  const registry = new Registry(config, { auditManager });
+  const registry = new Registry(config, { auditManager, entitledToolsets });
   const searchManager = sharedSearchManager ?? new SearchManager(config);
 
   const server = new McpServer(
@@ -96,7 +127,7 @@ function createHarnessServer(config: Config, sharedAuditManager?: AuditManager,
   }
 
   registerAllTools(server, registry, client, config, undefined, searchManager);
-  registerAllResources(server, registry, client, config);
+  registerAllResources(server, registry, client, config, undefined, operator);
   registerAllPrompts(server);
 
   return { server, auditManager, searchManager };
@@ -117,8 +148,7 @@ function logToFile(message: string, data?: Record<string, unknown>): void {
       ...data,
     });
     // Try env var first, then fall back to ~/.claude/harness-mcp.log
-    const logPath = process.env.HARNESS_MCP_LOG_FILE
-      ?? (process.env.HOME ? `${process.env.HOME}/.claude/harness-mcp.log` : undefined);
+    const logPath = defaultLogFilePath();
     if (logPath) {
       appendFileSync(logPath, entry + "\n");
     }
@@ -133,9 +163,12 @@ function logToFile(message: string, data?: Record<string, unknown>): void {
  * Start the server in stdio mode — single persistent connection.
  */
 async function startStdio(config: Config): Promise<void> {
-  const { server, auditManager } = createHarnessServer(config);
+  const { server, auditManager } = createHarnessServer(config, undefined, undefined, undefined, true);
   const transport = new StdioServerTransport();
   await server.connect(transport);
+  // Wrappers may multiplex several chats through one stdio process — key
+  // caches, logs, and audit events on the conversation ID from request _meta.
+  isolateConversations(transport);
   log.info("harness-mcp-server connected via stdio", {
     pid: process.pid,
     node_version: process.version,
@@ -233,6 +266,12 @@ interface Session extends HttpSessionActivity {
   transport: StreamableHTTPServerTransport;
 }
 
+/** Legacy SSE session — lives exactly as long as its GET /sse stream. */
+interface SseSession extends HttpSessionActivity {
+  server: McpServer;
+  transport: SSEServerTransport;
+}
+
 const REAP_INTERVAL_MS = 60_000; // check every minute
 
 /**
@@ -241,10 +280,14 @@ const REAP_INTERVAL_MS = 60_000; // check every minute
  * Subsequent requests re-use the session via the `mcp-session-id` header.
  * GET /mcp opens an SSE stream for server-initiated messages (progress, elicitation).
  * DELETE /mcp terminates a session.
+ * In `sse` mode, GET /sse and POST /messages also serve the legacy HTTP+SSE
+ * transport for clients that predate Streamable HTTP.
  * Uses the MCP SDK's Express adapter which provides automatic DNS rebinding protection
  * when bound to localhost (validates Host header against allowed hostnames).
  */
-async function startHttp(config: Config, port: number): Promise<void> {
+async function startHttp(reloader: ConfigReloader, port: number, mode: "http" | "sse" = "http"): Promise<void> {
+  // Settings fixed at startup. Sessions start from reloader.current, which a reload can change.
+  const config = reloader.current;
   const host = process.env.HOST || "127.0.0.1";
 
   validateHttpAuthForBindHost(host, config);
@@ -264,43 +307,41 @@ async function startHttp(config: Config, port: number): Promise<void> {
     res.setHeader("Access-Control-Allow-Origin", `http://${host}:${port}`);
     res.setHeader("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS");
     res.setHeader("Access-Control-Allow-Headers", "Authorization, Content-Type, mcp-session-id, x-harness-api-key, x-harness-account-id, x-harness-org, x-harness-project, x-harness-pipeline-version, x-harness-auto-approve-risk");
-    res.setHeader("Access-Control-Expose-Headers", "mcp-session-id");
+    res.setHeader("Access-Control-Expose-Headers", "mcp-session-id, WWW-Authenticate");
     next();
   });
 
   // Auth gate before body parsing — reject unauthenticated requests without allocating body memory
-  app.use(createHttpAuthMiddleware(config.HARNESS_MCP_AUTH_TOKEN));
-
-  // Simple per-IP rate limiting: 60 requests per minute
-  const ipHits = new Map<string, { count: number; resetAt: number }>();
-  const RATE_WINDOW_MS = 60_000;
-  const RATE_LIMIT = 60;
-
-  app.use((req, res, next) => {
-    const ip = req.ip ?? "unknown";
-    const now = Date.now();
-    let entry = ipHits.get(ip);
-    if (!entry || now >= entry.resetAt) {
-      entry = { count: 0, resetAt: now + RATE_WINDOW_MS };
-      ipHits.set(ip, entry);
-    }
-    entry.count++;
-    if (entry.count > RATE_LIMIT) {
-      res.status(429).json({
-        jsonrpc: "2.0",
-        error: { code: -32000, message: "Too many requests. Try again later." },
-        id: null,
-      });
-      return;
-    }
-    next();
-  });
+  const introspector = isOAuthEnabled(config) ? new TokenIntrospector(config) : undefined;
+  app.use(createHttpAuthMiddleware(
+    config.HARNESS_MCP_AUTH_TOKEN,
+    config.HARNESS_MCP_ENTITLEMENTS_SECRET,
+    introspector ? { introspector, config } : undefined,
+  ));
+
+  // Simple per-IP rate limiting: HARNESS_MCP_IP_RATE_LIMIT_PER_MIN requests per
+  // minute (default 60, 0 disables), counted before body parsing so an
+  // over-limit client is rejected cheaply. A request that turns out to be a
+  // single ping gets its count back once the body is parsed.
+  const ipRateLimiter = createIpRateLimiter(config.HARNESS_MCP_IP_RATE_LIMIT_PER_MIN, 60_000);
+  app.use(ipRateLimiter.middleware);
 
   const maxBodySize = config.HARNESS_MAX_BODY_SIZE_MB * 1024 * 1024;
   app.use(json({ limit: maxBodySize }));
+  app.use(ipRateLimiter.refundExempt);
+
+  // Per-principal / per-tool limits on tools/call (needs the parsed body).
+  // Always mounted so a config reload can turn limits on.
+  const toolRateLimiter = new ToolRateLimiter(toolRateLimitOptions(config));
+  app.post(["/mcp", LEGACY_MESSAGES_PATH], createToolRateLimitMiddleware(toolRateLimiter, config.HARNESS_ACCOUNT_ID));
+  reloader.onReload((next) => {
+    toolRateLimiter.reconfigure(toolRateLimitOptions(next));
+    configureUsageMetrics({ maxAccounts: next.HARNESS_METRICS_MAX_ACCOUNTS });
+  });
 
   // ---- Session store ----
   const sessions = new Map<string, Session>();
+  const sseSessions = new Map<string, SseSession>();
   const sharedAuditManager = createAuditManager(config);
   const sharedSearchManager = new SearchManager(config);
   // In HTTP mode: initialize + index static content using a baseline registry (no account needed)
@@ -322,6 +363,15 @@ async function startHttp(config: Config, port: number): Promise<void> {
     log.info("Session destroyed", { sessionId, remaining: sessions.size });
   }
 
+  async function destroySseSession(sessionId: string): Promise<void> {
+    const session = sseSessions.get(sessionId);
+    if (!session) return;
+    sseSessions.delete(sessionId);
+    await session.transport.close().catch(() => {});
+    await session.server.close().catch(() => {});
+    log.info("SSE session destroyed", { sessionId, remaining: sseSessions.size });
+  }
+
   // TTL reaper — evicts idle sessions and expired rate-limit entries
   const reaper = setInterval(() => {
     const now = Date.now();
@@ -332,11 +382,8 @@ async function startHttp(config: Config, port: number): Promise<void> {
       }
     }
     // Evict expired rate-limit entries to prevent unbounded map growth
-    for (const [ip, entry] of ipHits) {
-      if (now >= entry.resetAt) {
-        ipHits.delete(ip);
-      }
-    }
+    ipRateLimiter.prune(now);
+    toolRateLimiter.prune(now);
   }, REAP_INTERVAL_MS);
   reaper.unref();
 
@@ -345,10 +392,75 @@ async function startHttp(config: Config, port: number): Promise<void> {
   // Health check (includes session count and search readiness for observability)
   app.get("/health", (_req, res) => {
     const search = sharedSearchManager.getReadiness();
-    const health = buildHttpHealthResponse(search, sessions.size);
+    const health = buildHttpHealthResponse(search, sessions.size + sseSessions.size);
     res.status(health.statusCode).json(health.body);
   });
 
+  // Prometheus metrics (behind HTTP auth, like every route except /health)
+  configureUsageMetrics({ maxAccounts: config.HARNESS_METRICS_MAX_ACCOUNTS });
+  app.get("/metrics", (_req, res) => {
+    res.type("text/plain; version=0.0.4").send(renderToolRateLimitMetrics() + renderUsageMetrics() + renderContextCostMetrics());
+  });
+
+  // Per-account usage summary for chargeback (JSON view of the counters above)
+  app.get("/metrics/usage", (_req, res) => {
+    res.json(summarizeUsageByAccount());
+  });
+
+  // Same counters pushed to a Harness custom dashboard for account admins
+  let usageExporter: UsageDashboardExporter | undefined;
+  if (config.HARNESS_USAGE_DASHBOARD_ID) {
+    const apiKey = config.HARNESS_USAGE_DASHBOARD_API_KEY ?? config.HARNESS_API_KEY;
+    usageExporter = new UsageDashboardExporter(
+      new HarnessClient({
+        ...config,
+        HARNESS_API_KEY: apiKey,
+        HARNESS_ACCOUNT_ID: extractAccountIdFromToken(apiKey) ?? config.HARNESS_ACCOUNT_ID,
+        HARNESS_API_AUTH_SCHEME: "api_key",
+      }),
+      { dashboardId: config.HARNESS_USAGE_DASHBOARD_ID, intervalMs: config.HARNESS_USAGE_EXPORT_INTERVAL_MS },
+    );
+    usageExporter.start();
+  }
+
+  // Estimated tokens per tool result (JSON view of the context cost counters)
+  app.get("/metrics/context-cost", (_req, res) => {
+    res.json(summarizeContextCost());
+  });
+
+  // Config reload — operators only: the static HARNESS_MCP_AUTH_TOKEN, not
+  // entitlement tokens or OAuth users. Not served without that token.
+  if (config.HARNESS_MCP_AUTH_TOKEN) {
+    app.post("/admin/reload-config", (req, res) => {
+      if (!isOperatorRequest(req.headers, config.HARNESS_MCP_AUTH_TOKEN)) {
+        res.status(403).json({ error: "forbidden", error_description: "Config reload requires the HARNESS_MCP_AUTH_TOKEN bearer" });
+        return;
+      }
+      try {
+        res.json(reloader.reload());
+      } catch (err) {
+        log.error("Configuration reload failed — keeping the current configuration", { error: String(err) });
+        res.status(422).json({ error: "invalid_configuration", error_description: err instanceof Error ? err.message : String(err) });
+      }
+    });
+  }
+
+  // OAuth discovery metadata (unauthenticated). Clients follow the 401
+  // WWW-Authenticate challenge here, then authorize against Harness OIDC.
+  if (introspector) {
+    app.get(OAUTH_PROTECTED_RESOURCE_PATH, (_req, res) => {
+      res.json(buildProtectedResourceMetadata(config));
+    });
+    app.get(OAUTH_AUTHORIZATION_SERVER_PATH, async (_req, res) => {
+      try {
+        res.json(await buildAuthorizationServerMetadata(config, introspector));
+      } catch (err) {
+        log.error("Failed to load OAuth issuer metadata", { error: String(err) });
+        res.status(502).json({ error: "temporarily_unavailable", error_description: "Authorization server metadata unavailable" });
+      }
+    });
+  }
+
   // POST /mcp — initialize new sessions or route to existing session
   app.post("/mcp", async (req, res) => {
     const sessionId = req.headers["mcp-session-id"] as string | undefined;
@@ -386,8 +498,12 @@ async function startHttp(config: Config, port: number): Promise<void> {
     let server: McpServer | undefined;
     let transport: StreamableHTTPServerTransport | undefined;
     try {
-      const sessionConfig = mergeConfigWithSessionHeaders(config, req.headers);
-      const result = createHarnessServer(sessionConfig, sharedAuditManager, sharedSearchManager);
+      const principal = getOAuthPrincipal(res.locals);
+      const sessionConfig = mergeConfigWithSessionHeaders(reloader.current, req.headers, principal);
+      const entitledToolsets = parseSessionEntitlements(req.headers, config.HARNESS_MCP_ENTITLEMENTS_SECRET)
+        ?? principal?.toolsets;
+      const operator = isOperatorRequest(req.headers, config.HARNESS_MCP_AUTH_TOKEN);
+      const result = createHarnessServer(sessionConfig, sharedAuditManager, sharedSearchManager, entitledToolsets, operator);
       server = result.server;
       transport = new StreamableHTTPServerTransport({
         sessionIdGenerator: () => randomUUID(),
@@ -398,7 +514,7 @@ async function startHttp(config: Config, port: number): Promise<void> {
             lastActivity: Date.now(),
             activeRequests: 0,
           });
-          log.info("Session created", { sessionId: id, total: sessions.size });
+          log.info("Session created", { sessionId: id, total: sessions.size, ...(principal ? { principal: principal.subject } : {}) });
         },
       });
 
@@ -411,8 +527,8 @@ async function startHttp(config: Config, port: number): Promise<void> {
       await server.connect(transport);
       await transport.handleRequest(req, res, req.body);
     } catch (err) {
-      if (err instanceof MissingSessionCredentialsError) {
-        log.warn("Session rejected — missing credentials", { error: err.message });
+      if (err instanceof MissingSessionCredentialsError || err instanceof InvalidEntitlementsError) {
+        log.warn("Session rejected — missing or invalid credentials", { error: err.message });
         if (!res.headersSent) {
           res.status(401).json({
             jsonrpc: "2.0",
@@ -517,13 +633,95 @@ async function startHttp(config: Config, port: number): Promise<void> {
     destroySession(sessionId);
   });
 
+  // ---- Legacy SSE transport (sse mode only) ----
+  // Older MCP clients (protocol 2024-11-05) hold a GET /sse stream and POST
+  // messages to /messages?sessionId=…. Served behind the same CORS, auth,
+  // rate-limit, and body-size middleware as /mcp.
+  if (mode === "sse") {
+    app.get(LEGACY_SSE_PATH, async (req, res) => {
+      let server: McpServer;
+      try {
+        const principal = getOAuthPrincipal(res.locals);
+        const sessionConfig = mergeConfigWithSessionHeaders(reloader.current, req.headers, principal);
+        const entitledToolsets = parseSessionEntitlements(req.headers, config.HARNESS_MCP_ENTITLEMENTS_SECRET)
+          ?? principal?.toolsets;
+        const operator = isOperatorRequest(req.headers, config.HARNESS_MCP_AUTH_TOKEN);
+        server = createHarnessServer(sessionConfig, sharedAuditManager, sharedSearchManager, entitledToolsets, operator).server;
+      } catch (err) {
+        if (err instanceof MissingSessionCredentialsError || err instanceof InvalidEntitlementsError) {
+          log.warn("SSE session rejected — missing or invalid credentials", { error: err.message });
+          res.status(401).json({ jsonrpc: "2.0", error: { code: -32001, message: err.message }, id: null });
+          return;
+        }
+        log.error("Error creating SSE session", { error: String(err) });
+        res.status(500).json({ jsonrpc: "2.0", error: { code: -32000, message: "Failed to create session" }, id: null });
+        return;
+      }
+
+      const transport = new SSEServerTransport(LEGACY_MESSAGES_PATH, res);
+      const session: SseSession = { server, transport, lastActivity: Date.now(), activeRequests: 0 };
+      const sessionId = transport.sessionId;
+      sseSessions.set(sessionId, session);
+      // The open stream counts as an active request so the reaper never evicts a live session.
+      beginSessionRequest(session);
+      const stopHeartbeat = startSseHeartbeat(res, config.HARNESS_SSE_HEARTBEAT_MS);
+      res.once("close", () => {
+        stopHeartbeat();
+        endSessionRequest(session);
+        destroySseSession(sessionId);
+      });
+
+      try {
+        await server.connect(transport);
+        log.info("SSE session created", { sessionId, total: sseSessions.size });
+      } catch (err) {
+        log.error("Error establishing SSE stream", { sessionId, error: String(err) });
+        await destroySseSession(sessionId);
+      }
+    });
+
+    app.post(LEGACY_MESSAGES_PATH, async (req, res) => {
+      const sessionId = legacySseSessionId(req.query);
+      const session = sessionId ? sseSessions.get(sessionId) : undefined;
+      if (!session) {
+        res.status(404).json({
+          jsonrpc: "2.0",
+          error: { code: -32000, message: `Session not found. Open a new stream via GET ${LEGACY_SSE_PATH}.` },
+          id: null,
+        });
+        return;
+      }
+      beginSessionRequest(session);
+      try {
+        await session.transport.handlePostMessage(req, res, req.body);
+      } catch (err) {
+        log.error("Error handling SSE message", { sessionId, error: String(err) });
+        if (!res.headersSent) {
+          res.status(400).json({ jsonrpc: "2.0", error: { code: -32700, message: "Invalid request" }, id: null });
+        }
+      } finally {
+        endSessionRequest(session);
+      }
+    });
+  }
+
   // Graceful shutdown — drain in-flight requests, then close all sessions
   const httpServer = app.listen(port, host, () => {
     log.info(`harness-mcp-server listening on http://${host}:${port}`);
     log.info(`  POST   /mcp    — MCP endpoint (session-based, DNS rebinding protected)`);
     log.info(`  GET    /mcp    — SSE stream (progress, elicitation)`);
     log.info(`  DELETE /mcp    — Terminate session`);
+    if (mode === "sse") {
+      log.info(`  GET    ${LEGACY_SSE_PATH}    — Legacy SSE stream (heartbeat every ${config.HARNESS_SSE_HEARTBEAT_MS}ms)`);
+      log.info(`  POST   ${LEGACY_MESSAGES_PATH} — Legacy SSE messages (?sessionId=)`);
+    }
     log.info(`  GET    /health — Health check`);
+    log.info(`  GET    /metrics — Prometheus metrics`);
+    log.info(`  GET    /metrics/usage — Per-account usage summary (JSON)`);
+    log.info(`  GET    /metrics/context-cost — Estimated tokens per tool result (JSON)`);
+    if (config.HARNESS_MCP_AUTH_TOKEN) {
+      log.info(`  POST   /admin/reload-config — Reload configuration (static auth token only)`);
+    }
   });
 
   let draining = false;
@@ -549,10 +747,12 @@ async function startHttp(config: Config, port: number): Promise<void> {
 
     // 3. Close all sessions (terminates SSE streams, notifies transports)
     clearInterval(reaper);
-    await Promise.allSettled(
-      [...sessions.keys()].map((id) => destroySession(id)),
-    );
+    await Promise.allSettled([
+      ...[...sessions.keys()].map((id) => destroySession(id)),
+      ...[...sseSessions.keys()].map((id) => destroySseSession(id)),
+    ]);
     await sharedAuditManager.close().catch(() => {});
+    await usageExporter?.stop();
 
     // 4. Allow in-flight responses to flush, then exit
     const DRAIN_TIMEOUT_MS = 10_000;
@@ -579,13 +779,81 @@ async function startHttp(config: Config, port: number): Promise<void> {
   process.on("SIGTERM", () => shutdown("SIGTERM"));
 }
 
+/**
+ * `support-bundle` subcommand: write a sanitized diagnostics archive and exit.
+ * Invalid configuration is recorded in the bundle rather than aborting, since
+ * that is often what the ticket is about.
+ */
+async function runSupportBundle(outputDir = process.cwd()): Promise<void> {
+  let config: Config | Error;
+  try {
+    config = loadConfig();
+  } catch (err) {
+    config = err instanceof Error ? err : new Error(String(err));
+  }
+  const client = config instanceof Error ? undefined : new HarnessClient(config);
+  const bundle = await collectSupportBundle({ config, client });
+  const path = writeSupportBundle(bundle, outputDir);
+  console.error(`Support bundle written to ${path}`);
+  console.error("Review it before attaching to a ticket: secrets are redacted, but logs may name orgs, projects, and pipelines.");
+}
+
+/**
+ * `run-playbook` subcommand: run each step through an in-process MCP client
+ * connected to a normal server, print the JSON report to stdout, and exit 1
+ * when a step failed.
+ */
+async function runPlaybookCommand(file: string, varPairs: string[] = []): Promise<void> {
+  const playbook = parsePlaybook(readFileSync(file, "utf8"));
+  const vars = parsePlaybookVars(varPairs);
+  const config = loadConfig();
+  applyLiveConfig(config);
+  const { server } = createHarnessServer(config, undefined, undefined, undefined, true);
+  const [clientTransport, serverTransport] = InMemoryTransport.createLinkedPair();
+  const client = new Client({ name: "harness-playbook", version: getVersion() });
+  await Promise.all([server.connect(serverTransport), client.connect(clientTransport)]);
+  try {
+    const report = await runPlaybook(
+      playbook,
+      async (tool, args) => toolResultData(await client.callTool({ name: tool, arguments: args })),
+      vars,
+    );
+    process.stdout.write(`${JSON.stringify(report, null, 2)}\n`);
+    if (!report.passed) process.exitCode = 1;
+  } finally {
+    await client.close();
+    await server.close();
+  }
+}
+
+/**
+ * Apply the process-wide settings of `config`. Result processors are parsed
+ * first so an invalid reload throws before anything changes.
+ */
+function applyLiveConfig(config: Config): ResultProcessorRule[] {
+  const resultProcessors = loadResultProcessors(config);
+  setLogLevel(config.LOG_LEVEL);
+  configureContextCost({ sampleRate: config.HARNESS_CONTEXT_COST_SAMPLE_RATE });
+  configureResultProcessors(resultProcessors);
+  return resultProcessors;
+}
+
 async function main(): Promise<void> {
   // Parse CLI args first to get env file path
-  const { transport, envFile } = parseArgs();
+  const { command, transport, envFile, outputDir, playbookFile, playbookVars } = parseArgs();
 
   // Load .env file (custom path if specified, otherwise .env in current directory)
   loadEnvFile(envFile);
 
+  if (command === "support-bundle") {
+    await runSupportBundle(outputDir);
+    return;
+  }
+  if (command === "run-playbook") {
+    await runPlaybookCommand(playbookFile!, playbookVars);
+    return;
+  }
+
   // Resolve the HTTP port after dotenv is loaded so --env-file PORT is honored.
   const port = resolvePort();
 
@@ -608,7 +876,17 @@ async function main(): Promise<void> {
   });
 
   const config = loadConfig();
-  setLogLevel(config.LOG_LEVEL);
+  const resultProcessors = applyLiveConfig(config);
+  configureOutputRoot({ root: config.HARNESS_OUTPUT_ROOT, remote: transport !== "stdio", tempDir: config.HARNESS_TEMP_DIR });
+  const reloader = new ConfigReloader(config, envFile);
+  reloader.onReload(applyLiveConfig);
+  process.on("SIGHUP", () => {
+    try {
+      reloader.reload();
+    } catch (err) {
+      log.error("Configuration reload failed — keeping the current configuration", { error: String(err) });
+    }
+  });
 
   if (config.HARNESS_MCP_MODE === "multi-user" && transport === "stdio") {
     throw new Error(
@@ -625,12 +903,13 @@ async function main(): Promise<void> {
     defaultOrg: config.HARNESS_ORG ?? "(none)",
     defaultProject: config.HARNESS_PROJECT ?? "(none)",
     toolsets: config.HARNESS_TOOLSETS ?? "(all)",
+    ...(resultProcessors.length > 0 ? { resultProcessors: resultProcessors.length } : {}),
   });
 
   if (transport === "stdio") {
     await startStdio(config);
   } else {
-    await startHttp(config, port);
+    await startHttp(reloader, port, transport);
   }
 }
 
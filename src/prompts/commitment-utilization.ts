@@ -25,7 +25,8 @@ Steps:
 3. **Savings realized**: Call harness_get with resource_type="cost_commitment"${projectFilter}, params={aspect: "savings"} to quantify actual savings from commitments
 4. **Detailed analysis**: Call harness_get with resource_type="cost_commitment"${projectFilter}, params={aspect: "analysis"} for detailed breakdown by commitment type
 5. **Estimated savings**: Call harness_get with resource_type="cost_commitment"${projectFilter}, params={aspect: "estimated_savings", cloud_account_id: "<cloud_account_id>"} to see potential additional savings (pass cloud_account_id for a specific cloud account)
-6. **Present findings**:
+6. **Breakdowns**: Call harness_get with resource_type="cost_commitment_breakdown"${projectFilter}, params={metric: "utilisation", group_by: "service"} (then group_by: "account" with cloud_account_ids, and group_by: "day" for the trend) to find which services and accounts waste commitments; repeat with metric: "savings"
+7. **Present findings**:
    - **Utilization rate**: Percentage of commitments being used (target >80%)
    - **Coverage rate**: Percentage of eligible compute covered (identify gaps)
    - **Wasted spend**: Dollar value of underutilized commitments
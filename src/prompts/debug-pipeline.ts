@@ -33,6 +33,7 @@ Then analyze the diagnostic payload:
 - **failure section**: failed stage, step, error message, and delegate
 - **child_pipeline section**: if present, the failure is in a chained pipeline — focus on the child's failure details
 - **failed_step_logs**: actual log output from the failed steps — look for error patterns, stack traces, and exit codes
+- **root_cause**: a heuristic error category, the log lines around the error, and a suggested fix — confirm it against the logs before relying on it
 - **runtime input issues**: if the error mentions unresolved \`<+input>\` expressions or missing variables, check:
   - For **post-run forensics** (what values *actually* ran?): call harness_get with resource_type="execution_inputs" and resource_id=<execution_id> to see the merged input set YAML used at runtime — this is the source of truth for what the failed run received
   - For **pre-run expectations** (what does the pipeline expect?): call harness_get with resource_type="runtime_input_template" and resource_id=<pipeline_id> to see which inputs the pipeline declares
@@ -21,7 +21,7 @@ export function registerDelegateHealthPrompt(server: McpServer): void {
 
 Steps:
 1. **List delegates**: Call harness_list with resource_type="delegate"${projectFilter} to get all delegate groups and their status
-2. **Check tokens**: Call harness_list with resource_type="delegate_token"${projectFilter} to see delegate token status and expiration
+2. **Check versions and tokens**: Call harness_list with resource_type="delegate_upgrade_status"${projectFilter} to compare each delegate with the latest supported version and see auto-upgrade state, image expiry, and expired or expiring tokens
 3. **Analyze health**: For each delegate group, assess:
    - **Connectivity**: Is the delegate connected and heartbeating?
    - **Version**: Is it running the latest delegate version?
@@ -32,7 +32,7 @@ Steps:
 5. **Diagnose issues**: For any unhealthy delegates:
    - Identify likely root cause (network, expired token, resource constraints)
    - Provide specific remediation steps
-6. **Token warnings**: Flag any tokens expiring within 30 days`,
+6. **Token warnings**: Flag any tokens expiring within 30 days. For an expired or compromised token, offer harness_execute with resource_type="delegate_token", action="regenerate"`,
           },
         }],
       };
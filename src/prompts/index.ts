@@ -39,6 +39,7 @@ import { registerBranchCleanupPrompt } from "./branch-cleanup.js";
 
 // Approval prompts
 import { registerPendingApprovalsPrompt } from "./pending-approvals.js";
+import { registerMyActionItemsPrompt } from "./my-action-items.js";
 
 // Deployment workflow prompts
 import { registerBuildDeployAppPrompt } from "./build-deploy-app.js";
@@ -92,6 +93,7 @@ export function registerAllPrompts(server: McpServer): void {
 
   // Approvals
   registerPendingApprovalsPrompt(server);
+  registerMyActionItemsPrompt(server);
 
   // Deployment workflows
   registerBuildDeployAppPrompt(server);
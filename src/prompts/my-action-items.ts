@@ -0,0 +1,42 @@
+import * as z from "zod/v4";
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+
+export function registerMyActionItemsPrompt(server: McpServer): void {
+  server.registerPrompt(
+    "my-action-items",
+    {
+      description: "Daily to-do list of approvals, pull request reviews, and pipeline inputs waiting on me",
+      argsSchema: {
+        projectId: z.string().describe("Project identifier").optional(),
+        orgId: z.string().describe("Organization identifier").optional(),
+        repoIds: z.string().describe("Comma-separated Harness Code repositories to check for review requests").optional(),
+      },
+    },
+    async ({ projectId, orgId, repoIds }) => ({
+      messages: [{
+        role: "user" as const,
+        content: {
+          type: "text" as const,
+          text: `Build my to-do list for today: everything in Harness that is waiting on me.
+
+**Step 1: Fetch my action items**
+Use harness_list with resource_type="my_action_item"${orgId ? `, org_id="${orgId}"` : ""}${projectId ? `, project_id="${projectId}"` : ""}${repoIds ? `, params={repo_ids: "${repoIds}"}` : ""}. It returns approvals assigned to my user groups, pull requests waiting on my review, and executions waiting on runtime input, each as a section with a status.
+
+**Step 2: Render the to-do list**
+Start with one line: the total and the count per section (from summary). Then one checklist per section, most urgent first:
+- **Approvals** — pipeline, step, approval message, and deadline. Flag anything due within 24 hours.
+- **Pull request reviews** — repository, PR number and title, author, and how long it has been open.
+- **Waiting for input** — pipeline, run sequence, the stage that is waiting, and how long it has waited.
+Write each item as a checkbox line ("- [ ] ...") with the IDs needed to act on it.
+
+If a section's status is not_configured, unavailable, or error, show its reason in one line instead of the checklist, so I know the list is incomplete. If approvals has a note (my user groups could not be read), say that the approvals shown may include ones I cannot act on.
+
+**Step 3: Offer to work through it**
+Ask which item I want to handle first. Use each item's next_action: approve or reject with harness_execute(resource_type="approval_instance"), open a pull request with harness_get(resource_type="pull_request"), or provide inputs with harness_execute(resource_type="waiting_execution", action="resume").
+
+If every section is empty, tell me there is nothing waiting on me.`,
+        },
+      }],
+    }),
+  );
+}
@@ -19,23 +19,20 @@ export function registerPendingApprovalsPrompt(server: McpServer): void {
           type: "text" as const,
           text: `Find all pipeline executions that are currently waiting for approval and help me take action on them.
 
-**Step 1: Find executions with approval-waiting status**
-Use harness_list with resource_type="execution"${orgId ? `, org_id="${orgId}"` : ""}${projectId ? `, project_id="${projectId}"` : ""}${pipelineId ? `, pipeline_id="${pipelineId}"` : ""}, status="ApprovalWaiting" to find executions that are blocked on approvals.
+**Step 1: List pending approvals**
+Use harness_list with resource_type="pending_approval"${orgId ? `, org_id="${orgId}"` : ""}${projectId ? `, project_id="${projectId}"` : ""}${pipelineId ? `, filters={pipeline_id: "${pipelineId}"}` : ""}. It finds executions in ApprovalWaiting and returns each WAITING approval with its approval_id, approvers, required approver inputs, and deadline.
 
-**Step 2: For each waiting execution, get approval details**
-For each execution found, use harness_list with resource_type="approval_instance", execution_id=<execution_id>, approval_status="WAITING" to get the pending approval instances.
-
-**Step 3: Present a summary**
+**Step 2: Present a summary**
 For each pending approval, show:
 - Pipeline name and execution ID
 - Approval type (Harness, Jira, ServiceNow, Custom)
 - Approval message/description from the step configuration
-- How long it has been waiting (from the created timestamp)
-- Who needs to approve (approver user groups if available)
-- A deep link to the execution in Harness
+- How long it has been waiting (from created_at) and the deadline
+- Who needs to approve (user_groups, minimum_count) and any approver_inputs the approver must fill in
+- The pipeline and execution IDs so I can open the run in Harness
 
-**Step 4: Offer to take action**
-Ask me if I want to approve or reject any of the pending approvals. If I choose to act, use harness_execute with resource_type="approval_instance", action="approve" (or "reject"), approval_id=<id>.
+**Step 3: Offer to take action**
+Ask me if I want to approve or reject any of the pending approvals. If I choose to act, use harness_execute with resource_type="approval_instance", action="approve" (or "reject"), params={approval_id: <id>, comments: <my comment>, approver_inputs: {name: value}}. Only HarnessApproval items (actionable: true) can be approved here; Jira, ServiceNow, and Custom approvals resolve in the external system.
 
 If no executions are waiting for approval, let me know the project is clear.`,
         },
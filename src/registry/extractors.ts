@@ -2,8 +2,29 @@
  * Shared response extractors for Harness API responses.
  * Used across all toolset definitions — eliminates per-file duplication.
  */
+import { join } from "node:path";
+import YAML from "yaml";
 import { isRecord } from "../utils/type-guards.js";
 import { parseZipCsv } from "../utils/zip-csv.js";
+import { formatSiemRecords, writeSiemExport, type SiemFormat } from "../utils/siem-export.js";
+import { formatInTimeZone, isValidTimeZone, nextCronRuns, parseCron } from "../utils/cron.js";
+import { diffLines } from "../utils/text-diff.js";
+import { resolveToolOutputDir, safeFileName, writeOutputFile } from "../utils/output-paths.js";
+import type { LogLine } from "../utils/log-stream.js";
+import { evaluateTriggerConditions, extractWebhookFacts, readTriggerConditions } from "../utils/trigger-conditions.js";
+import { buildPackageMetadata, type HarVersionSources } from "../utils/har-metadata.js";
+import type { UndoEntry } from "../utils/undo-log.js";
+import type { AppSetRenderResult } from "../utils/appset-generate.js";
+import { linearForecast } from "../utils/cost-forecast.js";
+import type { SbomComponent, SbomDiff } from "../utils/sbom-diff.js";
+import { summarizeSbom } from "../utils/sbom-summary.js";
+import { buildLicenseInventory, type InventoryArtifact } from "../utils/license-inventory.js";
+import { blastRadius, buildDependencyGraph, type DependencyTreeRow } from "../utils/dependency-graph.js";
+import { STO_ISSUE_MAX_OCCURRENCES, stoOccurrence, stoReferenceIds, stoRemediation } from "../utils/sto-issue.js";
+import { flattenTeams, matchTeams } from "../utils/sei-teams.js";
+import { integrationHealth, integrationRows, STALE_INGESTION_HOURS, type IntegrationHealth } from "../utils/sei-integrations.js";
+import { correlate, joinSeries, MIN_CORRELATION_PERIODS, timeSeries } from "../utils/metric-correlation.js";
+import { burndownPoints, burndownSummary, carryOverAnalysis, sprintStats, velocityTrend } from "../utils/sprint-metrics.js";
 
 /** Extract `data` from standard NG API responses: `{ status, data, ... }` */
 export const ngExtract = (raw: unknown): unknown => {
@@ -43,6 +64,158 @@ export const offsetListExtract = (raw: unknown): { items: unknown[]; total: numb
   };
 };
 
+/** Raw detail gathered by security_issue get's collect hook. */
+export interface StoIssueScan {
+  issue: unknown;
+  occurrences?: unknown;
+  occurrences_error?: string;
+}
+
+/**
+ * security_issue get extractor: the issue's identity and severity, its
+ * reference IDs (CVE, CWE, GHSA), remediation guidance, and where it occurs.
+ */
+export const stoIssueDetailExtract = (raw: unknown): unknown => {
+  const scan = raw as StoIssueScan;
+  const issue = isRecord(scan.issue) ? scan.issue : {};
+  const page = isRecord(scan.occurrences) ? scan.occurrences.occurrences ?? scan.occurrences.items ?? scan.occurrences.content : scan.occurrences;
+  const rows = Array.isArray(page) ? page.filter(isRecord) : [];
+  const pagination = isRecord(scan.occurrences) && isRecord(scan.occurrences.pagination) ? scan.occurrences.pagination : {};
+  const total = Number(pagination.totalItems ?? issue.numOccurrences ?? issue.occurrenceCount);
+  const remediation = stoRemediation(issue);
+  const pick = (...keys: string[]) => keys.map((k) => issue[k]).find((v) => v !== undefined && v !== null && v !== "");
+  const fields: Record<string, unknown> = {
+    id: pick("id", "issueId"),
+    title: pick("title"),
+    severity: pick("severityCode", "severity"),
+    type: pick("issueType", "type"),
+    scan_tool: pick("productName", "scanTool", "scanner"),
+    target: pick("targetName"),
+    exemption_status: pick("exemptionStatus"),
+    first_detected: pick("firstDetected", "created"),
+    last_detected: pick("lastDetected", "lastSeen"),
+    description: pick("description"),
+  };
+  return {
+    ...Object.fromEntries(Object.entries(fields).filter(([, v]) => v !== undefined)),
+    reference_ids: stoReferenceIds(issue.referenceIdentifiers ?? issue.references),
+    ...(remediation ? { remediation } : { _remediation_note: "The scanner gave no remediation guidance for this issue. Do NOT invent a fix version." }),
+    occurrence_count: Number.isFinite(total) ? total : rows.length,
+    occurrences: rows.slice(0, STO_ISSUE_MAX_OCCURRENCES).map(stoOccurrence),
+    ...(scan.occurrences_error ? { occurrences_error: scan.occurrences_error } : {}),
+  };
+};
+
+/** Raw scans gathered by security_scan_summary's collect hook. */
+export interface StoScanSummaryScan {
+  target_id?: string;
+  scans: Array<Record<string, unknown>>;
+  /** More scans exist than were read. */
+  truncated: boolean;
+}
+
+const STO_SEVERITIES = ["critical", "high", "medium", "low", "info"] as const;
+
+/**
+ * security_scan_summary extractor: per target, the latest scan of each
+ * scanner type, and the scanners seen on other targets but not this one.
+ */
+export const stoScanSummaryExtract = (raw: unknown): unknown => {
+  const scan = raw as StoScanSummaryScan;
+  const text = (...values: unknown[]) => values.map((v) => (typeof v === "string" || typeof v === "number" ? String(v) : "")).find(Boolean);
+  const time = (row: Record<string, unknown>) => {
+    const value = row.lastModified ?? row.lastUpdated ?? row.created ?? row.startedAt;
+    const ms = typeof value === "number" ? value : Date.parse(String(value ?? ""));
+    return Number.isFinite(ms) ? ms : 0;
+  };
+  const counts = (row: Record<string, unknown>) => {
+    const source = isRecord(row.issueCounts) ? row.issueCounts : isRecord(row.counts) ? row.counts : row;
+    const entries = STO_SEVERITIES.map((sev) => [sev, Number(source[sev] ?? source[`${sev}Count`])] as const).filter(([, n]) => Number.isFinite(n));
+    return entries.length > 0 ? Object.fromEntries(entries) : undefined;
+  };
+
+  const targets = new Map<string, { target_id: string; target_name?: string; latest: Map<string, Record<string, unknown>> }>();
+  for (const row of scan.scans) {
+    const targetId = text(row.targetId, row.target_id, isRecord(row.target) ? row.target.id : undefined, scan.target_id) ?? "unknown";
+    const scanner = text(row.productName, row.scanTool, row.scanner, row.product) ?? "unknown";
+    const entry = targets.get(targetId) ?? { target_id: targetId, latest: new Map() };
+    const name = text(row.targetName, isRecord(row.target) ? row.target.name : undefined);
+    if (name && !entry.target_name) entry.target_name = name;
+    const current = entry.latest.get(scanner);
+    if (!current || time(row) > time(current)) entry.latest.set(scanner, row);
+    targets.set(targetId, entry);
+  }
+
+  const allScanners = [...new Set([...targets.values()].flatMap((t) => [...t.latest.keys()]))].sort();
+  let failing = 0;
+  const summaries = [...targets.values()].map((t) => {
+    const scanners = [...t.latest.entries()].sort(([a], [b]) => a.localeCompare(b)).map(([scanner, row]) => {
+      const status = text(row.status, row.state);
+      if (status && /fail|error/i.test(status)) failing++;
+      const issues = counts(row);
+      const when = time(row);
+      return {
+        scanner,
+        ...(text(row.id, row.scanId) ? { scan_id: text(row.id, row.scanId) } : {}),
+        ...(status ? { status } : {}),
+        ...(when ? { last_scanned: new Date(when).toISOString() } : {}),
+        ...(text(row.targetVariantName, row.variantName) ? { variant: text(row.targetVariantName, row.variantName) } : {}),
+        ...(text(row.executionId, row.pipelineExecutionId) ? { execution_id: text(row.executionId, row.pipelineExecutionId) } : {}),
+        ...(issues ? { issues } : {}),
+      };
+    });
+    const missing = allScanners.filter((s) => !t.latest.has(s));
+    return {
+      target_id: t.target_id,
+      ...(t.target_name ? { target_name: t.target_name } : {}),
+      scanners,
+      ...(missing.length > 0 && !scan.target_id ? { missing_scanners: missing } : {}),
+    };
+  });
+
+  return {
+    summary: {
+      targets: summaries.length,
+      scanners: allScanners,
+      scans_read: scan.scans.length,
+      latest_scans_failed: failing,
+    },
+    targets: summaries,
+    ...(scan.truncated
+      ? { _hint: "Only the most recent scans were read; targets scanned earlier are missing. Pass target_id to summarize one target, and list security_target to find targets with no recent scans." }
+      : {}),
+  };
+};
+
+/**
+ * security_exemption request extractor: the issues the exemption was
+ * requested for, the created exemption(s), and how to get them approved.
+ */
+export const stoExemptionRequestExtract = (raw: unknown): unknown => {
+  const r = raw as { issues: Array<Record<string, unknown>>; created: unknown; duration_days: number; cve?: string; component?: string };
+  const created = isRecord(r.created) && "data" in r.created ? r.created.data : r.created;
+  // Single create returns the exemption; bulk create returns { results: [{ issueId, id }] }.
+  const exemptionIds = (isRecord(created) && Array.isArray(created.results) ? created.results : [created])
+    .map((e) => (isRecord(e) ? e.id ?? e.exemptionId : undefined))
+    .filter((id): id is string => typeof id === "string");
+  return {
+    ...(r.cve ? { cve: r.cve } : {}),
+    ...(r.component ? { component: r.component } : {}),
+    duration_days: r.duration_days,
+    issues: r.issues.map((i) => ({
+      issue_id: i.id,
+      ...(i.title ? { title: i.title } : {}),
+      ...(i.severityCode ? { severity: i.severityCode } : {}),
+      ...(i.targetName ? { target: i.targetName } : {}),
+    })),
+    exemption_ids: exemptionIds,
+    created,
+    _hint: "The exemption stays Pending until a reviewer acts on it with harness_execute(resource_type='security_exemption', "
+      + `action='approve', resource_id='${exemptionIds[0] ?? "<exemption_id>"}', body={scope:'CURRENT'}) or action='reject'. `
+      + "Find it later with harness_list(resource_type='security_exemption', filters={status:'Pending'}).",
+  };
+};
+
 /**
  * STO Global Exemptions extractor.
  * API response: `{ exemptions: [...], pagination: { page, pageSize, totalPages, totalItems }, counts: {...} }`
@@ -95,7 +268,7 @@ export const stoExemptionsExtract = (raw: unknown, input?: Record<string, unknow
     occurrences: e.numOccurrences,
   }));
   // Keep IDs OUT of the items so the LLM can't accidentally render them as a column.
-  // Provide them in a separate lookup keyed by row index (1-based) for approve/reject actions.
+  // Provide them in a separate lookup keyed by row index (1-based) for approve/reject/expire actions.
   const _action_id_by_row: Record<number, string> = {};
   exemptions.forEach((e, idx) => { if (e.id) _action_id_by_row[idx + 1] = e.id; });
 
@@ -120,7 +293,7 @@ export const stoExemptionsExtract = (raw: unknown, input?: Record<string, unknow
     totalPages,
     counts: r.counts,
     _action_id_by_row,
-    _display_hint: "Render a compact table with columns: # | Issue Title | Severity | Type | Requested by | Target | Status. NEVER add an 'ID' column — the items contain no ID field by design. If the user asks to approve/reject row N, look up the ID in _action_id_by_row[N].",
+    _display_hint: "Render a compact table with columns: # | Issue Title | Severity | Type | Requested by | Target | Status. NEVER add an 'ID' column — the items contain no ID field by design. If the user asks to approve/reject/expire row N, look up the ID in _action_id_by_row[N].",
     _nextPageHint: hasMore
       ? `For the next page, call harness_list with resource_type='security_exemption' and filters=${filterJson}. You MUST keep size=${pageSize} and ALL other filters identical — the backend computes offset = page × size, so changing size or dropping filters silently shifts the dataset. Pages remaining: ${totalPages - page - 1}.`
       : "No more pages — all exemptions have been returned.",
@@ -173,6 +346,275 @@ export const scsListExtract = (fields: string[]) => (raw: unknown): unknown => {
   });
 };
 
+/** Raw comparison gathered by scs_sbom_comparison's collect hook. */
+export interface SbomComparisonScan {
+  base: { artifact_id: string; orchestration_id?: string; name?: string; tag?: string; component_count: number; truncated: boolean };
+  target: { artifact_id: string; orchestration_id?: string; name?: string; tag?: string; component_count: number; truncated: boolean };
+  diff: SbomDiff;
+}
+
+/** Entries kept per change list in an SBOM comparison; counts cover all of them. */
+const SBOM_COMPARISON_MAX_ITEMS = 100;
+
+/**
+ * SBOM comparison for release gating: counts per change kind up front, then
+ * the capped change lists. Added and removed components are reduced to
+ * name@version, license, and vulnerability count.
+ */
+export const scsSbomComparisonExtract = (raw: unknown): unknown => {
+  const scan = raw as SbomComparisonScan;
+  const { diff } = scan;
+  const cap = <T>(items: T[]) => items.slice(0, SBOM_COMPARISON_MAX_ITEMS);
+  const component = (c: SbomDiff["added"][number]) => ({
+    name: c.name,
+    ...(c.version ? { version: c.version } : {}),
+    ...(c.license ? { license: c.license } : {}),
+    ...(c.vulnerabilities ? { vulnerabilities: c.vulnerabilities } : {}),
+    ...(c.purl ? { purl: c.purl } : {}),
+  });
+  const lists = { added: diff.added, removed: diff.removed, upgraded: diff.upgraded, downgraded: diff.downgraded, changed: diff.changed, relicensed: diff.relicensed };
+  const truncatedLists = Object.entries(lists).filter(([, items]) => items.length > SBOM_COMPARISON_MAX_ITEMS).map(([name]) => name);
+  const partial = [scan.base.truncated ? "base" : "", scan.target.truncated ? "target" : ""].filter(Boolean);
+  return {
+    base: scan.base,
+    target: scan.target,
+    summary: {
+      ...Object.fromEntries(Object.entries(lists).map(([name, items]) => [name, items.length])),
+      licenses_introduced: diff.licenses.introduced.length,
+      vulnerability_delta: diff.vulnerabilities.delta,
+    },
+    licenses: diff.licenses,
+    vulnerabilities: { ...diff.vulnerabilities, introduced_by: cap(diff.vulnerabilities.introduced_by) },
+    added: cap(diff.added).map(component),
+    removed: cap(diff.removed).map(component),
+    upgraded: cap(diff.upgraded),
+    downgraded: cap(diff.downgraded),
+    changed: cap(diff.changed),
+    relicensed: cap(diff.relicensed),
+    ...(truncatedLists.length > 0 ? { truncated_lists: truncatedLists } : {}),
+    ...(partial.length > 0
+      ? { _hint: `The ${partial.join(" and ")} SBOM has more components than the comparison reads; changes past that point are missing.` }
+      : {}),
+  };
+};
+
+/** Raw download gathered by the scs_sbom download action's collect hook. */
+export interface SbomDownload {
+  orchestration_id: string;
+  /** Where the document came from: "inline" in the response, or the link's host. */
+  source: string;
+  content: string | Record<string, unknown>;
+  bytes: number;
+}
+
+/**
+ * scs_sbom download extractor: a summary of the document instead of the
+ * document itself, which runs to megabytes. With output_dir the document is
+ * written to disk and its path returned alongside the summary.
+ */
+export const scsSbomDownloadExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const download = raw as SbomDownload;
+  const summary = summarizeSbom(download.content);
+  const body = isRecord(input?.body) ? input.body : {};
+  const dir = input?.output_dir ?? body.output_dir;
+  const outputDir = typeof dir === "string" ? dir.trim() : "";
+  const result = { orchestration_id: download.orchestration_id, source: download.source, bytes: download.bytes, ...summary };
+  if (!outputDir) {
+    return { ...result, _hint: "Pass output_dir to keep the full document on the MCP server host." };
+  }
+  const extension = summary.encoding === "json" ? "json" : summary.encoding === "xml" ? "xml" : "spdx";
+  const file = join(resolveToolOutputDir(outputDir), safeFileName(`sbom-${download.orchestration_id}.${extension}`));
+  const text = typeof download.content === "string" ? download.content : JSON.stringify(download.content, null, 2);
+  writeOutputFile(file, text);
+  return { ...result, file };
+};
+
+/** Raw findings gathered by scs_cve_impact's collect hook. */
+export interface CveImpactScan {
+  query: { cve?: string; purl?: string; component?: string };
+  /** Matching scs_component_search rows (name, version, purl, artifactId, artifactName). */
+  matches: Array<Record<string, unknown>>;
+  /** code_repo_security rows, to tell repositories from images. */
+  code_repos: Array<Record<string, unknown>>;
+  /** Deployment rows per artifact ID, for the artifacts looked up. */
+  deployments: Record<string, Array<Record<string, unknown>>>;
+  /** Component versions checked against the CVE, and how many it affects. */
+  verified?: { checked: number; affected: number };
+  /** STO findings, when only a CVE was given. */
+  sto_issues?: Array<Record<string, unknown>>;
+  errors: string[];
+  /** The component search stopped at its page limit. */
+  truncated: boolean;
+}
+
+/** Deployments listed per affected artifact; the count covers all of them. */
+const CVE_IMPACT_MAX_DEPLOYMENTS = 20;
+
+/**
+ * CVE impact grouped by artifact: each affected image or repository with the
+ * component versions that put it there and the environments it runs in,
+ * production first.
+ */
+export const scsCveImpactExtract = (raw: unknown): unknown => {
+  const scan = raw as CveImpactScan;
+  const repoIds = new Set(scan.code_repos.flatMap((r) => [r.id, r.repo_id, r.identifier].filter((v) => v !== undefined && v !== null).map(String)));
+  const repoNames = new Set(scan.code_repos.flatMap((r) => [r.name, r.repo_name].filter((v): v is string => typeof v === "string")));
+  const byArtifact = new Map<string, { artifact_id: string; artifact_name?: string; components: Array<Record<string, unknown>> }>();
+  for (const m of scan.matches) {
+    const id = String(m.artifactId ?? m.artifactName ?? "unknown");
+    const entry = byArtifact.get(id) ?? { artifact_id: id, ...(typeof m.artifactName === "string" ? { artifact_name: m.artifactName } : {}), components: [] };
+    entry.components.push({
+      name: m.name,
+      ...(m.version !== undefined ? { version: m.version } : {}),
+      ...(m.purl !== undefined ? { purl: m.purl } : {}),
+      ...(m._unverified ? { _unverified: true } : {}),
+    });
+    byArtifact.set(id, entry);
+  }
+  const isProd = (d: Record<string, unknown>) => /^prod/i.test(String(d.env_type ?? d.environment_type ?? ""));
+  const affected = [...byArtifact.values()].map((a) => {
+    const kind = repoIds.has(a.artifact_id) || (a.artifact_name !== undefined && repoNames.has(a.artifact_name)) ? "code_repo" : "artifact";
+    const deployments = scan.deployments[a.artifact_id];
+    if (!deployments) return { ...a, kind };
+    const sorted = [...deployments].sort((x, y) => Number(isProd(y)) - Number(isProd(x)));
+    return {
+      ...a,
+      kind,
+      deployments: sorted.slice(0, CVE_IMPACT_MAX_DEPLOYMENTS),
+      deployment_count: deployments.length,
+      in_production: deployments.some(isProd),
+    };
+  });
+  const hints: string[] = [];
+  if (scan.sto_issues) {
+    hints.push("Only a CVE was given, so SBOMs were not searched. Pass component (the package name) or purl to find the artifacts that ship it.");
+  } else if (affected.length === 0) {
+    hints.push("No artifact in the project contains a matching component.");
+  }
+  if (scan.truncated) hints.push("The component search hit its page limit; narrow the query with a versioned purl.");
+  return {
+    query: scan.query,
+    summary: {
+      artifacts: affected.filter((a) => a.kind === "artifact").length,
+      code_repos: affected.filter((a) => a.kind === "code_repo").length,
+      in_production: affected.filter((a) => "in_production" in a && a.in_production).length,
+      ...(scan.verified ? { component_versions_checked: scan.verified.checked, component_versions_affected: scan.verified.affected } : {}),
+      ...(scan.sto_issues ? { sto_findings: scan.sto_issues.length } : {}),
+    },
+    affected,
+    ...(scan.sto_issues ? { sto_findings: scan.sto_issues } : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    ...(hints.length > 0 ? { _hint: hints.join(" ") } : {}),
+  };
+};
+
+/** Raw scan gathered by scs_license_inventory's collect hook. */
+export interface LicenseInventoryScan {
+  allow_list?: string[];
+  /** Artifacts whose components were read. */
+  artifacts: InventoryArtifact[];
+  /** Artifacts and code repositories found in scope. */
+  artifacts_found: number;
+  /** Found but not read, past the per-call artifact limit. */
+  artifacts_skipped: number;
+  errors: string[];
+}
+
+/** Licenses listed in a license inventory; `summary.distinct_licenses` counts all of them. */
+const LICENSE_INVENTORY_MAX_LICENSES = 50;
+
+/**
+ * License inventory: component and artifact counts per license, most widely
+ * used first, and with an allow-list the artifacts that break it. Violations
+ * point at the OPA policy that would enforce the same list.
+ */
+export const scsLicenseInventoryExtract = (raw: unknown): unknown => {
+  const scan = raw as LicenseInventoryScan;
+  const inventory = buildLicenseInventory(scan.artifacts, scan.allow_list);
+  const partial = scan.artifacts.filter((a) => a.truncated).map((a) => a.name ?? a.artifact_id);
+  const hints: string[] = [];
+  if (scan.artifacts_skipped > 0) {
+    hints.push(`Read ${scan.artifacts_found - scan.artifacts_skipped} of ${scan.artifacts_found} artifacts; pass source_id to inventory one artifact source at a time.`);
+  }
+  if (partial.length > 0) hints.push(`Only the first components of ${partial.join(", ")} were read.`);
+  if (inventory.violations && inventory.violations.length > 0) {
+    hints.push("To block these licenses in future builds, create an SBOM enforcement policy: harness_create(resource_type='policy') with type 'sbom_enforcement' and the same allow-list.");
+  }
+  return {
+    summary: {
+      artifacts_scanned: inventory.artifacts_scanned,
+      components: inventory.components,
+      distinct_licenses: inventory.licenses.length,
+      unlicensed_components: inventory.unlicensed.components,
+      ...(inventory.violations ? { artifacts_violating: inventory.violations.length } : {}),
+    },
+    ...(scan.allow_list ? { allow_list: scan.allow_list } : {}),
+    licenses: inventory.licenses.slice(0, LICENSE_INVENTORY_MAX_LICENSES),
+    unlicensed: inventory.unlicensed,
+    ...(inventory.violations ? { violations: inventory.violations } : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    ...(hints.length > 0 ? { _hint: hints.join(" ") } : {}),
+  };
+};
+
+/** Raw scan gathered by scs_dependency_graph's collect hook. */
+export interface DependencyGraphScan {
+  artifact_id: string;
+  components: SbomComponent[];
+  /** Ids (purls) of the artifact's direct dependencies. */
+  direct: string[];
+  /** Dependency tree rows per direct dependency. */
+  trees: Record<string, DependencyTreeRow[]>;
+  /** Direct dependencies whose trees were not read. */
+  trees_skipped: number;
+  /** The component list stopped at the read limit. */
+  truncated: boolean;
+  errors: string[];
+}
+
+/**
+ * scs_dependency_graph extractor: nodes and typed edges rooted at the
+ * artifact, plus the blast radius of `purl` when one is given.
+ */
+export const scsDependencyGraphExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as DependencyGraphScan;
+  const graph = buildDependencyGraph(
+    scan.artifact_id,
+    scan.components.map((c) => ({
+      id: c.purl ?? c.key,
+      name: c.name,
+      ...(c.version ? { version: c.version } : {}),
+      ...(c.license ? { license: c.license } : {}),
+      ...(c.vulnerabilities !== undefined ? { vulnerabilities: c.vulnerabilities } : {}),
+    })),
+    new Set(scan.direct),
+    new Map(Object.entries(scan.trees)),
+  );
+  const connected = new Set(graph.edges.flatMap((e) => [e.from, e.to]));
+  const target = typeof input?.purl === "string" && input.purl.trim() ? input.purl.trim() : undefined;
+  const radius = target ? blastRadius(graph, target) : undefined;
+  const hints: string[] = [];
+  if (scan.truncated) hints.push("The artifact has more components than the graph reads; some are missing.");
+  if (scan.trees_skipped > 0) hints.push(`Dependency trees were read for ${scan.direct.length - scan.trees_skipped} of ${scan.direct.length} direct dependencies.`);
+  if (radius && !radius.found) hints.push(`No component matching "${target}" is in this artifact.`);
+  return {
+    artifact_id: scan.artifact_id,
+    summary: {
+      nodes: graph.nodes.length,
+      edges: graph.edges.length,
+      direct_dependencies: graph.nodes.filter((n) => n.direct).length,
+      transitive_edges: graph.edges.filter((e) => e.type === "transitive").length,
+      unconnected: graph.nodes.filter((n) => !connected.has(n.id)).length,
+      vulnerable: graph.nodes.filter((n) => (n.vulnerabilities ?? 0) > 0).length,
+    },
+    ...(radius ? { blast_radius: radius } : {}),
+    nodes: graph.nodes,
+    edges: graph.edges,
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    ...(hints.length > 0 ? { _hint: hints.join(" ") } : {}),
+  };
+};
+
 function pickFields(obj: Record<string, unknown>, fields: string[]): Record<string, unknown> {
   const result: Record<string, unknown> = {};
   for (const field of fields) {
@@ -201,6 +643,105 @@ function stripEmptyFields(obj: unknown): unknown {
   return obj;
 }
 
+/**
+ * sei_team list extractor: flat team rows with team_ref_id, including the
+ * nested teams of an org tree, narrowed by input.search. When nothing matches
+ * the hint says how to widen the search.
+ */
+export const seiTeamListExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const teams = flattenTeams(raw);
+  const search = typeof input?.search === "string" ? input.search.trim() : "";
+  const items = search ? matchTeams(teams, search) : teams;
+  return {
+    items,
+    total: items.length,
+    ...(items.length === 0 && search
+      ? { _hint: `No team name matches "${search}" among ${teams.length} teams. Try a shorter search, or list sei_org_tree and pass org_tree_id to browse one org tree's teams.` }
+      : {}),
+  };
+};
+
+const INTEGRATION_HEALTH_ORDER: Record<IntegrationHealth["health"], number> = { failing: 0, stale: 1, unknown: 2, healthy: 3 };
+
+/**
+ * sei_integration extractor: each integration's ingestion health, problems
+ * first, narrowed by input.health. get (integration_id) returns the one
+ * integration's health alongside SEI's record.
+ */
+export const seiIntegrationHealthExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  if (input?.integration_id !== undefined) {
+    const row = isRecord(raw) && isRecord(raw.data) ? raw.data : raw;
+    return isRecord(row) ? { ...integrationHealth(row), integration: row } : raw;
+  }
+  const all = integrationRows(raw).map((row) => integrationHealth(row))
+    .sort((a, b) => INTEGRATION_HEALTH_ORDER[a.health] - INTEGRATION_HEALTH_ORDER[b.health]);
+  const wanted = typeof input?.health === "string" ? input.health : undefined;
+  const items = wanted ? all.filter((i) => i.health === wanted) : all;
+  const count = (health: IntegrationHealth["health"]) => all.filter((i) => i.health === health).length;
+  const problems = count("failing") + count("stale");
+  return {
+    items,
+    total: items.length,
+    summary: { integrations: all.length, healthy: count("healthy"), failing: count("failing"), stale: count("stale"), unknown: count("unknown") },
+    ...(problems > 0
+      ? { _hint: `${problems} integration(s) are failing or have not ingested in ${STALE_INGESTION_HOURS}h. Metrics from teams using them may be empty or out of date until ingestion recovers.` }
+      : {}),
+  };
+};
+
+/** Series gathered by sei_ai_delivery_correlation's collect hook; the first is AI adoption. */
+export interface SeiAiCorrelationScan {
+  granularity: string;
+  series: Array<{ name: string; valueKeys: string[]; lowerIsBetter?: boolean; raw?: unknown; error?: string }>;
+}
+
+/**
+ * sei_ai_delivery_correlation extractor: the series joined per period, and
+ * the correlation of adoption with each delivery metric.
+ */
+export const seiAiCorrelationExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as SeiAiCorrelationScan;
+  const [adoption, ...delivery] = scan.series;
+  const read = scan.series.filter((s) => s.error === undefined);
+  const rows = joinSeries(Object.fromEntries(read.map((s) => [s.name, timeSeries(s.raw, s.valueKeys)])));
+  const errors = scan.series.filter((s) => s.error !== undefined).map((s) => ({ metric: s.name, error: s.error }));
+  const correlations = adoption && adoption.error === undefined
+    ? correlate(rows, adoption.name, delivery.filter((d) => d.error === undefined).map((d) => ({ name: d.name, lowerIsBetter: d.lowerIsBetter })))
+    : [];
+  const thin = correlations.some((c) => c.r === undefined);
+  return {
+    team_ref_id: input?.team_ref_id,
+    granularity: scan.granularity,
+    periods: rows,
+    correlations,
+    ...(errors.length > 0 ? { errors } : {}),
+    _hint: "r runs from -1 to 1 across the periods where both series have data. It shows association, not cause: "
+      + "other changes over the same weeks (team size, release cycles) move these metrics too."
+      + (thin ? ` Metrics with fewer than ${MIN_CORRELATION_PERIODS} shared periods have no r; widen the date range or use WEEKLY granularity.` : ""),
+  };
+};
+
+/**
+ * sei_sprint_metric extractor: the aspect's analysis (velocity trend,
+ * carry-over, or burndown progress) in place of SEI's raw sprint rows. When
+ * no sprint rows can be found the response passes through untouched.
+ */
+export const seiSprintMetricExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const aspect = (input?.aspect as string | undefined) ?? "velocity";
+  const teamRefId = input?.team_ref_id;
+  if (aspect === "burndown") {
+    const points = burndownPoints(raw);
+    if (points.length === 0) return raw;
+    const data = isRecord(raw) && isRecord(raw.data) ? raw.data : raw;
+    const sprint: Record<string, unknown> = isRecord(data) && isRecord(data.sprint) ? data.sprint : {};
+    const end = [sprint.endDate, sprint.end].find((v): v is string => typeof v === "string");
+    return { team_ref_id: teamRefId, aspect, ...(typeof sprint.name === "string" ? { sprint: sprint.name } : {}), ...burndownSummary(points, end) };
+  }
+  const sprints = sprintStats(raw);
+  if (sprints.length === 0) return raw;
+  return { team_ref_id: teamRefId, aspect, ...(aspect === "carry_over" ? carryOverAnalysis(sprints) : velocityTrend(sprints)) };
+};
+
 /**
  * Factory for HAR (Artifact Registry) list responses.
  * HAR wraps lists as `{ data: { <arrayKey>: [...], itemCount, pageIndex, ... }, status }`.
@@ -292,6 +833,32 @@ export const projectListExtract = (raw: unknown): { items: unknown[]; total: num
   };
 };
 
+/** What ping's collect hook measured: the account read and how long it took. */
+export interface PingScan {
+  latency_ms: number;
+  account: unknown;
+  server_version: string;
+}
+
+/** Connection check result: latency, the account the credentials resolve to, and the MCP server version. */
+export const pingExtract = (raw: unknown): unknown => {
+  const scan = raw as PingScan;
+  const data = ngExtract(scan.account);
+  const account = isRecord(data) ? data : {};
+  const str = (v: unknown): string | null => (typeof v === "string" && v ? v : null);
+  return {
+    ok: true,
+    latency_ms: scan.latency_ms,
+    account: {
+      id: str(account.identifier),
+      name: str(account.name) ?? str(account.accountName),
+      company: str(account.companyName),
+      cluster: str(account.cluster),
+    },
+    server_version: scan.server_version,
+  };
+};
+
 /** Factory for GraphQL field extraction (used by CCM). */
 export const gqlExtract = (field: string) => (raw: unknown): unknown => {
   const r = raw as { data?: Record<string, unknown> };
@@ -374,426 +941,5426 @@ export const executionInputsExtract = (raw: unknown, input?: Record<string, unkn
   };
 };
 
+interface TimelineTask {
+  id: string;
+  name: string;
+  type: "stage" | "step";
+  stage_id?: string;
+  status: string;
+  started_at: string;
+  ended_at: string | null;
+  start_offset_ms: number;
+  duration_ms: number | null;
+  lane: number;
+  parallel_group?: number;
+}
+
+interface TimelineSpan {
+  start: number;
+  end: number;
+  lane: number;
+}
+
+/** Node stepTypes that are containers in the execution graph, not timed work items. */
+const TIMELINE_CONTAINER_STEP_TYPES = new Set(["NG_FORK", "NG_SECTION", "NG_SECTION_WITH_ROLLBACK_INFO", "STEP_GROUP", "NG_EXECUTION"]);
+
+/** `pipeline.stages.<stage>.(...).steps.<step>` — leaf steps under a stage. */
+const STEP_FQN_PATTERN = /^pipeline\.stages\.([^.]+)\..*\bsteps\.([^.]+)$/;
+
 /**
- * Extracts CCM list responses with views/totalCount structure.
- * Maps `data.views` → `items` and `data.totalCount` → `total`.
- * Used by multiple CCM APIs that return this response pattern.
+ * Greedy interval partitioning: assign each span the lowest lane whose previous
+ * occupant has finished. The lane count equals the peak concurrency.
  */
-export const ccmViewsExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { data?: { views?: unknown[]; totalCount?: number } };
-  return {
-    items: r.data?.views ?? [],
-    total: r.data?.totalCount ?? 0,
-  };
-};
+function assignTimelineLanes(spans: TimelineSpan[]): number {
+  const laneEnds: number[] = [];
+  for (const span of [...spans].sort((a, b) => a.start - b.start)) {
+    let lane = laneEnds.findIndex((end) => end <= span.start);
+    if (lane === -1) {
+      lane = laneEnds.length;
+      laneEnds.push(span.end);
+    } else {
+      laneEnds[lane] = span.end;
+    }
+    span.lane = lane;
+  }
+  return laneEnds.length;
+}
 
-/** Extract anomaly list: returns { items, total } so skipCompact marker survives normalization. */
-export const anomalyListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { data?: unknown[] };
-  const items = Array.isArray(r?.data) ? r.data : [];
-  return { items, total: items.length };
-};
+function mermaidLabel(value: string): string {
+  return value.replace(/[:;#]/g, " ").replace(/\s+/g, " ").trim() || "unnamed";
+}
+
+function mermaidTag(status: string): string {
+  const s = status.toLowerCase();
+  if (s === "failed" || s === "errored" || s === "aborted" || s === "expired") return "crit, ";
+  if (s === "running" || s === "asyncwaiting" || s === "taskwaiting" || s === "waiting") return "active, ";
+  if (s === "success" || s === "ignorefailed") return "done, ";
+  return "";
+}
 
 /**
- * Extracts CCM cost breakdown data from GraphQL perspectiveGrid response.
- * Maps `data.perspectiveGrid.data` → `items` and `data.perspectiveTotalCount` → `total`.
+ * Render a Mermaid `gantt` chart from timeline tasks. Timestamps use
+ * `dateFormat x` (epoch millis) so no timezone conversion is involved.
  */
-export const ccmBreakdownExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as {
-    data?: {
-      perspectiveGrid?: { data?: unknown[] };
-      perspectiveTotalCount?: number;
-    };
-  };
-  return {
-    items: r.data?.perspectiveGrid?.data ?? [],
-    total: r.data?.perspectiveTotalCount ?? 0,
-  };
-};
+function renderTimelineMermaid(title: string, tasks: TimelineTask[], now: number): string {
+  const lines = ["gantt", `  title ${mermaidLabel(title)}`, "  dateFormat x", "  axisFormat %H:%M:%S"];
+  const stages = tasks.filter((t) => t.type === "stage");
+  const steps = tasks.filter((t) => t.type === "step");
+  const end = (t: TimelineTask) => (t.ended_at ? Date.parse(t.ended_at) : now);
+  const sections = stages.length > 0
+    ? stages.map((stage) => ({ stage, items: [stage, ...steps.filter((s) => s.stage_id === stage.id)] }))
+    : [{ stage: undefined, items: steps }];
+  let n = 0;
+  for (const { stage, items } of sections) {
+    lines.push(`  section ${mermaidLabel(stage?.name ?? "Steps")}`);
+    for (const t of items) {
+      lines.push(`  ${mermaidLabel(t.name)} :${mermaidTag(t.status)}t${n++}, ${Date.parse(t.started_at)}, ${end(t)}`);
+    }
+  }
+  return lines.join("\n");
+}
+
+export interface MermaidGraphNode {
+  id: string;
+  label: string;
+  status?: string;
+}
+
+export interface MermaidGraphEdge {
+  from: string;
+  to: string;
+}
+
+/** True when a topology resource was asked for `format=mermaid`. */
+export function wantsMermaid(input?: Record<string, unknown>): boolean {
+  return typeof input?.format === "string" && input.format.toLowerCase() === "mermaid";
+}
+
+/** Status class for flowchart nodes: execution statuses and Kubernetes/Argo health both map here. */
+function mermaidStatusClass(status: string | undefined): "failed" | "running" | "ok" | "warn" | undefined {
+  const s = (status ?? "").toLowerCase();
+  if (!s) return undefined;
+  if (["failed", "errored", "aborted", "expired", "degraded", "missing"].includes(s)) return "failed";
+  if (["running", "asyncwaiting", "taskwaiting", "waiting", "progressing"].includes(s)) return "running";
+  if (["success", "ignorefailed", "healthy", "synced"].includes(s)) return "ok";
+  if (["suspended", "unknown", "outofsync"].includes(s)) return "warn";
+  return undefined;
+}
 
 /**
- * Extracts CCM cost time series stats from GraphQL perspectiveTimeSeriesStats response.
- * Returns the `stats` array from `data.perspectiveTimeSeriesStats.stats`.
+ * Render a Mermaid `flowchart` from nodes and edges. Node ids are replaced
+ * with positional ids (n0, n1, ...) so purls, FQNs, and `kind/ns/name` keys
+ * never break the syntax; labels are quoted with `"` swapped for `'`.
+ * Nodes with a recognised status get a failed/running/ok/warn class.
  */
-export const ccmTimeseriesExtract = (raw: unknown): unknown => {
-  const r = raw as {
-    data?: { perspectiveTimeSeriesStats?: { stats?: unknown[] } };
+export function renderGraphMermaid(nodes: MermaidGraphNode[], edges: MermaidGraphEdge[], direction: "TD" | "LR" = "TD"): string {
+  const ids = new Map<string, string>();
+  const lines = [`flowchart ${direction}`];
+  const classes: Record<string, string[]> = {};
+  for (const node of nodes) {
+    if (ids.has(node.id)) continue;
+    const id = `n${ids.size}`;
+    ids.set(node.id, id);
+    const label = node.label.replace(/"/g, "'").replace(/\s+/g, " ").trim() || "unnamed";
+    lines.push(`  ${id}["${label}"]`);
+    const cls = mermaidStatusClass(node.status);
+    if (cls) (classes[cls] ??= []).push(id);
+  }
+  const seen = new Set<string>();
+  for (const edge of edges) {
+    const from = ids.get(edge.from);
+    const to = ids.get(edge.to);
+    if (!from || !to || seen.has(`${from}>${to}`)) continue;
+    seen.add(`${from}>${to}`);
+    lines.push(`  ${from} --> ${to}`);
+  }
+  const styles: Record<string, string> = {
+    failed: "fill:#fdd,stroke:#c00",
+    running: "fill:#def,stroke:#06c",
+    ok: "fill:#dfd,stroke:#080",
+    warn: "fill:#ffd,stroke:#a80",
   };
-  return r.data?.perspectiveTimeSeriesStats?.stats ?? [];
-};
+  for (const [cls, members] of Object.entries(classes)) {
+    lines.push(`  classDef ${cls} ${styles[cls]}`);
+    lines.push(`  class ${members.join(",")} ${cls}`);
+  }
+  return lines.join("\n");
+}
 
 /**
- * Extracts CCM cost summary from a dual-mode GraphQL response.
- * When `data.ccmMetaData` is present (metadata query), returns it directly.
- * Otherwise returns `{ trendStats, forecastCost }` for a perspective summary query.
+ * Argo CD resource tree for gitops_app_resource_tree. Returns the tree as-is;
+ * with `format=mermaid` a `mermaid` flowchart of owner → owned resources is
+ * added, built from each node's `parentRefs` and coloured by health.
  */
-export const ccmSummaryExtract = (raw: unknown): unknown => {
-  const r = raw as { data?: Record<string, unknown> };
-  if (!r.data) return raw;
-  if (r.data.ccmMetaData) return r.data.ccmMetaData;
-  return {
-    trendStats: r.data.perspectiveTrendStats,
-    forecastCost: r.data.perspectiveForecastCost,
-  };
+export const gitopsResourceTreeExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  if (!wantsMermaid(input) || !isRecord(raw)) return raw;
+  const treeNodes = Array.isArray(raw.nodes) ? raw.nodes.filter(isRecord) : [];
+  const key = (ref: Record<string, unknown>) => `${ref.group ?? ""}/${ref.kind ?? ""}/${ref.namespace ?? ""}/${ref.name ?? ""}`;
+  // parentRefs may omit group; fall back to kind/namespace/name when the exact key is unknown.
+  const loose = (ref: Record<string, unknown>) => `${ref.kind ?? ""}/${ref.namespace ?? ""}/${ref.name ?? ""}`;
+  const byLoose = new Map<string, string>();
+  const nodes: MermaidGraphNode[] = [];
+  for (const node of treeNodes) {
+    const health = isRecord(node.health) && typeof node.health.status === "string" ? node.health.status : undefined;
+    nodes.push({
+      id: key(node),
+      label: `${node.kind ?? "Resource"} ${node.name ?? ""}${health ? ` (${health})` : ""}`,
+      status: health,
+    });
+    byLoose.set(loose(node), key(node));
+  }
+  const known = new Set(nodes.map((n) => n.id));
+  const edges: MermaidGraphEdge[] = [];
+  for (const node of treeNodes) {
+    const parents = Array.isArray(node.parentRefs) ? node.parentRefs.filter(isRecord) : [];
+    for (const parent of parents) {
+      const from = known.has(key(parent)) ? key(parent) : byLoose.get(loose(parent));
+      if (from) edges.push({ from, to: key(node) });
+    }
+  }
+  return { ...raw, mermaid: renderGraphMermaid(nodes, edges, "LR") };
 };
 
 /**
- * Extracts CCM perspective-scoped recommendations from GraphQL response.
- * Returns `{ items, stats }` from `data.recommendationsV2` and `data.recommendationStatsV2`.
+ * Builds a Gantt-ready execution timeline from
+ * GET /pipeline/api/pipelines/execution/v2/{planExecutionId}?renderFullBottomGraph=true.
+ *
+ * Stages come from `pipelineExecutionSummary.layoutNodeMap` (walked from
+ * `startingNodeId`, with `parallel` nodes sharing a `parallel_group`); steps come
+ * from `executionGraph.nodeMap`, matched to their stage by `baseFqn`. Every task
+ * carries ISO start/end, an offset from pipeline start, and a `lane` computed from
+ * time overlap so renderers can stack concurrent bars. Tasks that never started
+ * have no timestamps and are counted in `not_started` instead.
+ * Pass `format=mermaid` to also get a Mermaid `gantt` block; `include_mermaid=true`
+ * is the deprecated spelling and still works.
  */
-export const ccmRecommendationsExtract = (raw: unknown): { items: unknown[]; stats: unknown } => {
-  const r = raw as {
-    data?: {
-      recommendationsV2?: { items?: unknown[] };
-      recommendationStatsV2?: unknown;
-    };
+export const executionTimelineExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const data = isRecord(raw) && isRecord(raw.data) ? raw.data : {};
+  const pes = isRecord(data.pipelineExecutionSummary) ? data.pipelineExecutionSummary : {};
+  const layout = isRecord(pes.layoutNodeMap) ? pes.layoutNodeMap : {};
+  const graph = isRecord(data.executionGraph) && isRecord(data.executionGraph.nodeMap) ? data.executionGraph.nodeMap : {};
+
+  const collected: Array<Omit<TimelineTask, "started_at" | "ended_at" | "start_offset_ms" | "duration_ms" | "lane"> & { start: number; end?: number }> = [];
+  let notStarted = 0;
+  const addTask = (task: Omit<TimelineTask, "started_at" | "ended_at" | "start_offset_ms" | "duration_ms" | "lane">, startTs: unknown, endTs: unknown) => {
+    if (typeof startTs !== "number" || startTs <= 0) {
+      notStarted++;
+      return;
+    }
+    collected.push({ ...task, start: startTs, end: typeof endTs === "number" && endTs > 0 ? endTs : undefined });
   };
-  return {
-    items: r.data?.recommendationsV2?.items ?? [],
-    stats: r.data?.recommendationStatsV2,
+
+  // Stages: walk the layout graph; each hop along nextIds is a new sequential group.
+  const visited = new Set<string>();
+  let group = 0;
+  const walk = (nodeId: string | undefined): void => {
+    while (nodeId && !visited.has(nodeId)) {
+      visited.add(nodeId);
+      const node = layout[nodeId];
+      if (!isRecord(node)) return;
+      const edges = isRecord(node.edgeLayoutList) ? node.edgeLayoutList : {};
+      const children = Array.isArray(edges.currentNodeChildren) ? edges.currentNodeChildren as string[] : [];
+      if (String(node.nodeType ?? "").toLowerCase() === "parallel") {
+        for (const childId of children) {
+          const child = layout[childId];
+          if (!isRecord(child) || visited.has(childId)) continue;
+          visited.add(childId);
+          addTask({
+            id: String(child.nodeIdentifier ?? childId),
+            name: String(child.name ?? child.nodeIdentifier ?? childId),
+            type: "stage",
+            status: String(child.status ?? "Unknown"),
+            parallel_group: group,
+          }, child.startTs, child.endTs);
+        }
+      } else {
+        addTask({
+          id: String(node.nodeIdentifier ?? nodeId),
+          name: String(node.name ?? node.nodeIdentifier ?? nodeId),
+          type: "stage",
+          status: String(node.status ?? "Unknown"),
+          parallel_group: group,
+        }, node.startTs, node.endTs);
+      }
+      group++;
+      const next = Array.isArray(edges.nextIds) ? edges.nextIds as string[] : [];
+      nodeId = next[0];
+    }
   };
-};
+  walk(typeof pes.startingNodeId === "string" ? pes.startingNodeId : undefined);
 
-/** Extract dashboard list response: `{ items, pages, resource }` */
-export const dashboardListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { items?: number; pages?: number; resource?: unknown[] };
+  // Steps: leaf nodes from the full execution graph.
+  for (const [uuid, node] of Object.entries(graph)) {
+    if (!isRecord(node) || typeof node.baseFqn !== "string") continue;
+    if (TIMELINE_CONTAINER_STEP_TYPES.has(String(node.stepType ?? ""))) continue;
+    const match = STEP_FQN_PATTERN.exec(node.baseFqn);
+    if (!match) continue;
+    addTask({
+      id: String(node.identifier ?? match[2] ?? uuid),
+      name: String(node.name ?? node.identifier ?? uuid),
+      type: "step",
+      stage_id: match[1],
+      status: String(node.status ?? "Unknown"),
+    }, node.startTs, node.endTs);
+  }
+
+  const pipelineStart = typeof pes.startTs === "number" && pes.startTs > 0
+    ? pes.startTs
+    : Math.min(...collected.map((t) => t.start));
+  const pipelineEnd = typeof pes.endTs === "number" && pes.endTs > 0 ? pes.endTs : undefined;
+  // Running tasks have no end yet — treat the latest timestamp seen as "now".
+  const now = Math.max(pipelineEnd ?? 0, ...collected.flatMap((t) => [t.start, t.end ?? 0]));
+
+  const stageSpans: TimelineSpan[] = [];
+  const stepSpansByStage = new Map<string, TimelineSpan[]>();
+  const spans = collected.map((t) => {
+    const span: TimelineSpan = { start: t.start, end: t.end ?? now, lane: 0 };
+    if (t.type === "stage") {
+      stageSpans.push(span);
+    } else {
+      const key = t.stage_id ?? "";
+      stepSpansByStage.set(key, [...(stepSpansByStage.get(key) ?? []), span]);
+    }
+    return span;
+  });
+  const maxStageParallelism = assignTimelineLanes(stageSpans);
+  let maxStepParallelism = 0;
+  for (const stageSteps of stepSpansByStage.values()) {
+    maxStepParallelism = Math.max(maxStepParallelism, assignTimelineLanes(stageSteps));
+  }
+
+  const tasks: TimelineTask[] = collected
+    .map(({ start, end, ...task }, i) => ({
+      ...task,
+      started_at: new Date(start).toISOString(),
+      ended_at: end !== undefined ? new Date(end).toISOString() : null,
+      start_offset_ms: Number.isFinite(pipelineStart) ? start - pipelineStart : 0,
+      duration_ms: end !== undefined ? end - start : null,
+      lane: spans[i]?.lane ?? 0,
+    }))
+    .sort((a, b) => a.start_offset_ms - b.start_offset_ms || (a.type === b.type ? 0 : a.type === "stage" ? -1 : 1));
+
+  const name = typeof pes.name === "string" ? pes.name : String(pes.pipelineIdentifier ?? "Pipeline execution");
+  const includeMermaid = wantsMermaid(input) || input?.include_mermaid === true || input?.include_mermaid === "true";
   return {
-    items: r.resource ?? [],
-    total: r.items ?? 0,
+    execution_id: pes.planExecutionId ?? input?.execution_id ?? null,
+    pipeline_id: pes.pipelineIdentifier ?? null,
+    name,
+    status: pes.status ?? null,
+    started_at: Number.isFinite(pipelineStart) ? new Date(pipelineStart).toISOString() : null,
+    ended_at: pipelineEnd !== undefined ? new Date(pipelineEnd).toISOString() : null,
+    duration_ms: pipelineEnd !== undefined && Number.isFinite(pipelineStart) ? pipelineEnd - pipelineStart : null,
+    max_parallelism: { stages: maxStageParallelism, steps: maxStepParallelism },
+    not_started: notStarted,
+    tasks,
+    ...(includeMermaid ? { mermaid: renderTimelineMermaid(name, tasks, now) } : {}),
   };
 };
 
+/** Duration in ms between two epoch-ms timestamps, or null while still running. */
+function spanMs(startTs: unknown, endTs: unknown): number | null {
+  return typeof startTs === "number" && startTs > 0 && typeof endTs === "number" && endTs > 0 ? endTs - startTs : null;
+}
+
+function failureMessage(node: Record<string, unknown>): string | undefined {
+  const info = isRecord(node.failureInfo) ? node.failureInfo : isRecord(node.failureInfoDTO) ? node.failureInfoDTO : {};
+  return typeof info.message === "string" && info.message ? info.message : undefined;
+}
+
 /**
- * Extracts dashboard data from a ZIP ArrayBuffer containing CSVs.
- * Matches v1 `get_dashboard_data` behavior: ZIP → CSV → structured JSON tables.
+ * Curated execution summary for harness_get(resource_type='execution'). Keeps
+ * status, timing, trigger, per-stage status, and failure details from
+ * GET /pipeline/api/pipelines/execution/v2/{planExecutionId} and drops the rest
+ * of the pipeline-service payload (layout edges, module info, governance
+ * metadata). Pass `detail_level=full` for the raw `data` payload.
  */
-export const dashboardDataExtract = (raw: unknown): unknown => {
-  if (raw instanceof ArrayBuffer) {
-    return parseZipCsv(raw);
+export const executionSummaryExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const mermaid = wantsMermaid(input);
+  if (input?.detail_level === "full" && !mermaid) return ngExtract(raw);
+  const data = isRecord(raw) && isRecord(raw.data) ? raw.data : {};
+  const pes = isRecord(data.pipelineExecutionSummary) ? data.pipelineExecutionSummary : {};
+  const layout = isRecord(pes.layoutNodeMap) ? pes.layoutNodeMap : {};
+  const graph = isRecord(data.executionGraph) && isRecord(data.executionGraph.nodeMap) ? data.executionGraph.nodeMap : {};
+
+  // Stages in execution order: walk the layout from startingNodeId, expanding parallel groups.
+  const stages: Array<Record<string, unknown>> = [];
+  const stageGroups: Array<Array<Record<string, unknown>>> = [];
+  const visited = new Set<string>();
+  const addStage = (node: Record<string, unknown>, nodeId: string): void => {
+    const message = failureMessage(node);
+    stageGroups[stageGroups.length - 1]?.push(node);
+    stages.push({
+      identifier: node.nodeIdentifier ?? nodeId,
+      name: node.name ?? null,
+      status: node.status ?? null,
+      startTs: typeof node.startTs === "number" && node.startTs > 0 ? node.startTs : null,
+      endTs: typeof node.endTs === "number" && node.endTs > 0 ? node.endTs : null,
+      durationMs: spanMs(node.startTs, node.endTs),
+      ...(message ? { failureMessage: message } : {}),
+    });
+  };
+  let nodeId = typeof pes.startingNodeId === "string" ? pes.startingNodeId : undefined;
+  while (nodeId && !visited.has(nodeId)) {
+    visited.add(nodeId);
+    const node = layout[nodeId];
+    if (!isRecord(node)) break;
+    const edges = isRecord(node.edgeLayoutList) ? node.edgeLayoutList : {};
+    stageGroups.push([]);
+    if (String(node.nodeType ?? "").toLowerCase() === "parallel") {
+      const children = Array.isArray(edges.currentNodeChildren) ? edges.currentNodeChildren as string[] : [];
+      for (const childId of children) {
+        const child = layout[childId];
+        if (!isRecord(child) || visited.has(childId)) continue;
+        visited.add(childId);
+        addStage(child, childId);
+      }
+    } else {
+      addStage(node, nodeId);
+    }
+    const next = Array.isArray(edges.nextIds) ? edges.nextIds as string[] : [];
+    nodeId = next[0];
   }
-  return raw;
-};
 
-// ---------------------------------------------------------------------------
-// Chaos Engineering extractors
-// ---------------------------------------------------------------------------
+  // Failed leaf steps, when the (bottom) execution graph was returned.
+  const failedSteps: Array<Record<string, unknown>> = [];
+  for (const node of Object.values(graph)) {
+    if (!isRecord(node) || typeof node.baseFqn !== "string") continue;
+    if (node.status !== "Failed" && node.status !== "Errored") continue;
+    if (TIMELINE_CONTAINER_STEP_TYPES.has(String(node.stepType ?? ""))) continue;
+    const match = STEP_FQN_PATTERN.exec(node.baseFqn);
+    if (!match) continue;
+    failedSteps.push({
+      stage: match[1],
+      identifier: node.identifier ?? match[2],
+      name: node.name ?? null,
+      stepType: node.stepType ?? null,
+      message: failureMessage(node) ?? null,
+    });
+  }
+
+  const errorInfo = isRecord(pes.executionErrorInfo) ? pes.executionErrorInfo : {};
+  const failedStages = stages.filter((s) => s.status === "Failed" || s.status === "Errored").map((s) => s.identifier);
+  const hasFailure = typeof errorInfo.message === "string" || failedStages.length > 0 || failedSteps.length > 0;
+  const trigger = isRecord(pes.executionTriggerInfo) ? pes.executionTriggerInfo : {};
+  const triggeredBy = isRecord(trigger.triggeredBy) ? trigger.triggeredBy : {};
+  const triggeredByExtra = isRecord(triggeredBy.extraInfo) ? triggeredBy.extraInfo : {};
+  const graphMermaid = mermaid ? renderExecutionGraphMermaid(stageGroups, graph) : undefined;
+  if (input?.detail_level === "full") {
+    const full = ngExtract(raw);
+    return isRecord(full) ? { ...full, mermaid: graphMermaid } : full;
+  }
 
-/**
- * Extract chaos paginated list response: { data: [...], pagination: { totalItems } }
- * Used by chaos experiments and templates.
- */
-export const chaosPageExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { data?: unknown[]; pagination?: { totalItems?: number } };
   return {
-    items: r.data ?? [],
-    total: r.pagination?.totalItems ?? (Array.isArray(r.data) ? r.data.length : 0),
+    planExecutionId: pes.planExecutionId ?? input?.execution_id ?? null,
+    pipelineIdentifier: pes.pipelineIdentifier ?? null,
+    name: pes.name ?? null,
+    status: pes.status ?? null,
+    runSequence: pes.runSequence ?? null,
+    startTs: typeof pes.startTs === "number" && pes.startTs > 0 ? pes.startTs : null,
+    endTs: typeof pes.endTs === "number" && pes.endTs > 0 ? pes.endTs : null,
+    durationMs: spanMs(pes.startTs, pes.endTs),
+    trigger: {
+      type: trigger.triggerType ?? null,
+      triggeredBy: triggeredByExtra.email ?? triggeredBy.identifier ?? null,
+    },
+    stages,
+    failure: hasFailure
+      ? {
+          message: typeof errorInfo.message === "string" ? errorInfo.message : null,
+          failedStages,
+          failedSteps,
+        }
+      : null,
+    ...(graphMermaid !== undefined ? { mermaid: graphMermaid } : {}),
+    _hint: "Summary view. Pass params={detail_level: 'full'} for the raw execution payload, harness_diagnose for failure analysis with logs, or resource_type='execution_timeline' for step timings.",
   };
 };
 
 /**
- * Chaos v2 experiment list items expose their id as `experimentID` (capital ID),
- * but the deep-link resolver and the get-op path param use `experimentId`. Mirror
- * the value so per-item `openInHarness` links use the UUID instead of falling back
- * to the experiment name. Wraps chaosPageExtract; all other fields are preserved.
+ * Mermaid flowchart of an execution: stages left to right in layout order,
+ * every stage of a parallel group linked from each stage of the previous
+ * group. When the full bottom graph was fetched, each stage's leaf steps are
+ * chained after it in start order.
  */
-export const chaosExperimentListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const page = chaosPageExtract(raw);
-  const items = page.items.map((item) => {
-    if (item && typeof item === "object" && !Array.isArray(item)) {
-      const rec = item as Record<string, unknown>;
-      if (typeof rec.experimentID === "string" && rec.experimentId === undefined) {
-        return { ...rec, experimentId: rec.experimentID };
-      }
+function renderExecutionGraphMermaid(
+  stageGroups: Array<Array<Record<string, unknown>>>,
+  graph: Record<string, unknown>,
+): string {
+  const nodes: MermaidGraphNode[] = [];
+  const edges: MermaidGraphEdge[] = [];
+  const groups = stageGroups.filter((g) => g.length > 0);
+  groups.forEach((group, i) => {
+    for (const stage of group) {
+      const id = `stage:${String(stage.nodeIdentifier ?? stage.name)}`;
+      const status = typeof stage.status === "string" ? stage.status : undefined;
+      nodes.push({ id, label: `${String(stage.name ?? stage.nodeIdentifier)}${status ? ` (${status})` : ""}`, status });
+      for (const prev of groups[i - 1] ?? []) edges.push({ from: `stage:${String(prev.nodeIdentifier ?? prev.name)}`, to: id });
     }
-    return item;
   });
-  return { items, total: page.total };
+
+  const stepsByStage = new Map<string, Array<{ id: string; name: string; status?: string; start: number }>>();
+  for (const [uuid, node] of Object.entries(graph)) {
+    if (!isRecord(node) || typeof node.baseFqn !== "string") continue;
+    if (TIMELINE_CONTAINER_STEP_TYPES.has(String(node.stepType ?? ""))) continue;
+    const match = STEP_FQN_PATTERN.exec(node.baseFqn);
+    if (!match) continue;
+    const steps = stepsByStage.get(match[1]!) ?? [];
+    steps.push({
+      id: `step:${uuid}`,
+      name: String(node.name ?? node.identifier ?? uuid),
+      status: typeof node.status === "string" ? node.status : undefined,
+      start: typeof node.startTs === "number" && node.startTs > 0 ? node.startTs : Number.MAX_SAFE_INTEGER,
+    });
+    stepsByStage.set(match[1]!, steps);
+  }
+  for (const [stageId, steps] of stepsByStage) {
+    let prev = `stage:${stageId}`;
+    for (const step of steps.sort((a, b) => a.start - b.start)) {
+      nodes.push({ id: step.id, label: `${step.name}${step.status ? ` (${step.status})` : ""}`, status: step.status });
+      edges.push({ from: prev, to: step.id });
+      prev = step.id;
+    }
+  }
+  return renderGraphMermaid(nodes, edges, "LR");
+}
+
+/**
+ * Execution statuses an agent can act on to unblock a run, mapped to the call
+ * that does it. Expired runs cannot be resumed but can be retried.
+ */
+const WAITING_EXECUTION_ACTIONS: Record<string, string> = {
+  Paused: "harness_execute(resource_type='execution', action='interrupt', resource_id=<execution_id>, params={interrupt_type: 'Resume'})",
+  InputWaiting: "harness_get(resource_type='execution_input_request', resource_id=<node_execution_id>) for the awaited inputs, then harness_execute(resource_type='waiting_execution', action='resume', params={node_execution_id}, body={inputs_yaml})",
+  InterventionWaiting: "harness_execute(resource_type='waiting_execution', action='intervene', resource_id=<execution_id>, params={node_execution_id, interrupt_type: 'Retry' | 'MarkAsSuccess' | 'Ignore' | 'MarkAsFailure' | 'Abort'})",
+  ApprovalWaiting: "harness_list(resource_type='approval_instance', filters={execution_id, approval_status: 'WAITING'}), then harness_execute(resource_type='approval_instance', action='approve' | 'reject')",
+  WaitStepRunning: "Wait step in progress — it completes on its own; abort with harness_execute(resource_type='execution', action='interrupt', params={interrupt_type: 'AbortAll'})",
+  ResourceWaiting: "Blocked on a resource constraint held by another execution — resolve or abort the holder",
+  Expired: "Cannot be resumed — harness_execute(resource_type='pipeline', action='retry', params={execution_id}) to re-run from the failed stage",
 };
 
+export const WAITING_EXECUTION_STATUSES = Object.keys(WAITING_EXECUTION_ACTIONS);
+
+interface WaitingNode {
+  node_execution_id: string;
+  id: string;
+  name: string;
+  status: string;
+  stage_id?: string;
+  step_type?: string;
+  next_action: string;
+}
+
 /**
- * The create-action handler echoes back the request `actions.Action` (clean,
- * no backend envelope). Project a stable, documented shape so no raw
- * passthrough crosses the tool boundary and future server-added fields stay
- * out of the public contract.
+ * List extractor for waiting_execution: execution summaries annotated with
+ * the stages that are blocked and the call that unblocks each run.
  */
-export const chaosActionExtract = (raw: unknown): unknown => {
-  if (!raw || typeof raw !== "object" || Array.isArray(raw)) return raw;
-  const a = raw as Record<string, unknown>;
+export const waitingExecutionListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const { items, total } = pageExtract(raw);
   return {
-    identity: a.identity,
-    name: a.name,
-    description: a.description,
-    tags: a.tags,
-    type: a.type,
-    infrastructureType: a.infrastructureType,
-    hubRef: a.hubRef,
-    actionsTemplateRef: a.actionsTemplateRef,
-    actionProperties: a.actionProperties,
-    runProperties: a.runProperties,
-    variables: a.variables,
-    inputs: a.inputs,
+    items: items.map((item) => {
+      if (!isRecord(item)) return item;
+      const layout = isRecord(item.layoutNodeMap) ? item.layoutNodeMap : {};
+      const waitingStages = Object.values(layout)
+        .filter((node): node is Record<string, unknown> => isRecord(node) && typeof node.status === "string" && node.status in WAITING_EXECUTION_ACTIONS)
+        .map((node) => ({
+          stage_id: node.nodeIdentifier ?? null,
+          name: node.name ?? null,
+          status: node.status,
+        }));
+      const status = String(item.status ?? "");
+      return {
+        execution_id: item.planExecutionId ?? null,
+        pipeline_id: item.pipelineIdentifier ?? null,
+        name: item.name ?? null,
+        status,
+        run_sequence: item.runSequence ?? null,
+        started_at: typeof item.startTs === "number" ? new Date(item.startTs).toISOString() : null,
+        waiting_stages: waitingStages,
+        next_action: WAITING_EXECUTION_ACTIONS[status] ?? "harness_get(resource_type='waiting_execution', resource_id=<execution_id>) to find the waiting step",
+      };
+    }),
+    total,
   };
 };
 
 /**
- * Input-set list items belong to a single parent experiment (the required
- * experiment_id filter), but don't carry it in the row. Inject experimentId from
- * the request input so each item's deep link resolves to the parent experiment's
- * inputsets page instead of the per-item resolver clobbering it with the row's name.
+ * Get extractor for waiting_execution: walks the full execution graph and
+ * returns each step currently waiting, with the node_execution_id the
+ * resume/intervene actions need.
  */
-export const chaosInputSetListExtract = (
-  raw: unknown,
-  input?: Record<string, unknown>,
-): { items: unknown[]; total: number } => {
-  const page = chaosPageExtract(raw);
-  const experimentId = input?.experiment_id;
-  if (typeof experimentId !== "string" || experimentId === "") return page;
-  const items = page.items.map((item) => {
-    if (item && typeof item === "object" && !Array.isArray(item)) {
-      const rec = item as Record<string, unknown>;
-      if (rec.experimentId === undefined) return { ...rec, experimentId };
-    }
-    return item;
-  });
-  return { items, total: page.total };
+export const waitingExecutionGetExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const data = isRecord(raw) && isRecord(raw.data) ? raw.data : {};
+  const pes = isRecord(data.pipelineExecutionSummary) ? data.pipelineExecutionSummary : {};
+  const graph = isRecord(data.executionGraph) && isRecord(data.executionGraph.nodeMap) ? data.executionGraph.nodeMap : {};
+
+  const waiting: WaitingNode[] = [];
+  for (const [uuid, node] of Object.entries(graph)) {
+    if (!isRecord(node) || typeof node.status !== "string") continue;
+    const nextAction = WAITING_EXECUTION_ACTIONS[node.status];
+    if (!nextAction || node.status === "Expired") continue;
+    if (TIMELINE_CONTAINER_STEP_TYPES.has(String(node.stepType ?? ""))) continue;
+    const match = typeof node.baseFqn === "string" ? STEP_FQN_PATTERN.exec(node.baseFqn) : null;
+    if (!match) continue;
+    waiting.push({
+      node_execution_id: String(node.uuid ?? uuid),
+      id: String(node.identifier ?? match[2] ?? uuid),
+      name: String(node.name ?? node.identifier ?? uuid),
+      status: node.status,
+      stage_id: match[1],
+      ...(typeof node.stepType === "string" ? { step_type: node.stepType } : {}),
+      next_action: nextAction,
+    });
+  }
+
+  const status = String(pes.status ?? "");
+  return {
+    execution_id: pes.planExecutionId ?? input?.execution_id ?? null,
+    pipeline_id: pes.pipelineIdentifier ?? null,
+    status: status || null,
+    waiting_steps: waiting,
+    ...(waiting.length === 0
+      ? {
+          _hint: WAITING_EXECUTION_ACTIONS[status]
+            ?? "No steps are waiting — the execution is not blocked on input, intervention, or approval.",
+        }
+      : {}),
+  };
 };
 
+/** Raw payload gathered by trigger_schedule's collect hook: triggers across the scanned pipelines. */
+export interface TriggerScheduleScan {
+  pipelines_scanned: number;
+  truncated: boolean;
+  triggers: Array<{ pipeline_id: string; pipeline_name?: string; trigger: Record<string, unknown> }>;
+  errors: Array<{ pipeline_id: string; error: string }>;
+}
+
+/** Read the cron source from a trigger's YAML (or parsed object); undefined for non-scheduled triggers. */
+function readCronSource(trigger: Record<string, unknown>): { expression: string; type?: string; timeZone?: string } | undefined {
+  let doc: unknown = trigger;
+  if (typeof trigger.yaml === "string" && trigger.yaml.trim()) {
+    try {
+      doc = YAML.parse(trigger.yaml);
+    } catch {
+      return undefined;
+    }
+  }
+  const root = isRecord(doc) && isRecord(doc.trigger) ? doc.trigger : doc;
+  const source = isRecord(root) ? root.source : undefined;
+  if (!isRecord(source) || source.type !== "Scheduled" || !isRecord(source.spec)) return undefined;
+  const cron = isRecord(source.spec.spec) ? source.spec.spec : undefined;
+  if (!cron || typeof cron.expression !== "string") return undefined;
+  const timeZone = cron.timeZone ?? cron.timezone;
+  return {
+    expression: cron.expression,
+    ...(typeof cron.type === "string" ? { type: cron.type } : {}),
+    ...(typeof timeZone === "string" && timeZone ? { timeZone } : {}),
+  };
+}
+
 /**
- * Normalize chaos experiment variables response (RunTimeInputs shape):
- * { experiment: [...] | null, tasks: { taskName: [...] } | null }
- * → { items: [{ task, variables }], total }
- * Groups variables by task name. Handles null/undefined gracefully.
+ * trigger_schedule extractor: computes the next `count` fire times for every
+ * cron trigger in the scan and merges them into one time-ordered schedule.
+ * Times are rendered in params.timezone (default UTC); each trigger is
+ * evaluated in its own zone (UTC unless the trigger sets one).
  */
-export const chaosRunTimeInputsExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { experiment?: unknown[] | null; tasks?: Record<string, unknown[]> | null } | null | undefined;
-  if (!r) return { items: [], total: 0 };
-  const items: unknown[] = [];
-  const expVars = r.experiment;
-  if (Array.isArray(expVars) && expVars.length > 0) {
-    items.push({ task: "experiment", variables: expVars });
+export const triggerScheduleExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as Partial<TriggerScheduleScan>;
+  const timeZone = typeof input?.timezone === "string" && input.timezone ? input.timezone : "UTC";
+  if (!isValidTimeZone(timeZone)) {
+    throw new Error(`Unknown timezone "${timeZone}". Use an IANA zone name such as "America/New_York" or "UTC".`);
   }
-  const tasks = r.tasks;
-  if (tasks && typeof tasks === "object") {
-    for (const [taskName, vars] of Object.entries(tasks)) {
-      if (Array.isArray(vars) && vars.length > 0) {
-        items.push({ task: taskName, variables: vars });
+  const from = input?.from !== undefined && input.from !== "" ? new Date(input.from as string | number) : new Date();
+  if (Number.isNaN(from.getTime())) {
+    throw new Error(`Invalid from "${String(input?.from)}". Use an ISO-8601 timestamp or epoch milliseconds.`);
+  }
+  const count = Math.min(Math.max(Math.trunc(Number(input?.count ?? 5)) || 5, 1), 50);
+  const withinHours = Number(input?.within_hours ?? 0);
+  const until = withinHours > 0 ? new Date(from.getTime() + withinHours * 3_600_000) : undefined;
+  const includeDisabled = input?.include_disabled === true || input?.include_disabled === "true";
+
+  const items: Array<Record<string, unknown> & { _next?: number }> = [];
+  const schedule: Array<{ at: string; ms: number; pipeline_id: string; trigger_id: unknown; trigger_name: unknown }> = [];
+  for (const entry of scan.triggers ?? []) {
+    const source = readCronSource(entry.trigger);
+    if (!source) continue;
+    const enabled = entry.trigger.enabled !== false;
+    if (!enabled && !includeDisabled) continue;
+    const triggerZone = source.timeZone ?? "UTC";
+    const item: Record<string, unknown> & { _next?: number } = {
+      pipeline_id: entry.pipeline_id,
+      ...(entry.pipeline_name ? { pipeline_name: entry.pipeline_name } : {}),
+      trigger_id: entry.trigger.identifier,
+      trigger_name: entry.trigger.name,
+      enabled,
+      expression: source.expression,
+      cron_type: source.type ?? null,
+      trigger_timezone: triggerZone,
+      next_runs: [],
+    };
+    try {
+      if (!isValidTimeZone(triggerZone)) throw new Error(`Unknown trigger timezone "${triggerZone}"`);
+      const dialect = source.type === "UNIX" || source.type === "QUARTZ" ? source.type : undefined;
+      const runs = enabled ? nextCronRuns(parseCron(source.expression, dialect), from, count, triggerZone, until) : [];
+      item.next_runs = runs.map((r) => formatInTimeZone(r, timeZone));
+      item._next = runs[0]?.getTime();
+      for (const r of runs) {
+        schedule.push({ at: formatInTimeZone(r, timeZone), ms: r.getTime(), pipeline_id: entry.pipeline_id, trigger_id: entry.trigger.identifier, trigger_name: entry.trigger.name });
       }
+    } catch (err) {
+      item.error = err instanceof Error ? err.message : String(err);
     }
+    items.push(item);
   }
-  return { items, total: items.length };
+
+  items.sort((a, b) => (a._next ?? Infinity) - (b._next ?? Infinity));
+  schedule.sort((a, b) => a.ms - b.ms);
+  return {
+    timezone: timeZone,
+    from: formatInTimeZone(from, timeZone),
+    ...(until ? { until: formatInTimeZone(until, timeZone) } : {}),
+    items: items.map(({ _next, ...rest }) => rest),
+    total: items.length,
+    schedule: schedule.map(({ ms, ...rest }) => rest),
+    pipelines_scanned: scan.pipelines_scanned ?? 0,
+    ...(scan.truncated
+      ? { truncated: true, _hint: "Pipeline scan limit reached — pass pipeline_id to check a specific pipeline." }
+      : {}),
+    ...(scan.errors?.length ? { errors: scan.errors } : {}),
+  };
 };
 
+/** One source in a service posture scan: loaded data, or why it is missing. */
+export type PostureSection<T = unknown> =
+  | { status: "ok"; data: T }
+  | { status: "not_configured" | "unavailable" | "error"; reason: string };
+
+/** Raw payload gathered by service_security_posture's collect hook. */
+export interface ServicePostureScan {
+  service_id: string;
+  service: unknown;
+  sto: PostureSection<Record<string, unknown>>;
+  scs: PostureSection<unknown>;
+  chaos: PostureSection<unknown>;
+  policy: PostureSection<unknown>;
+}
+
+type RiskLevel = "critical" | "high" | "medium" | "low";
+const RISK_ORDER: RiskLevel[] = ["low", "medium", "high", "critical"];
+
+/** Total from a list response, whichever pagination envelope the module uses. */
+function listTotal(value: unknown): number {
+  if (Array.isArray(value)) return value.length;
+  if (!isRecord(value)) return 0;
+  const pagination = isRecord(value.pagination) ? value.pagination : {};
+  for (const candidate of [pagination.totalItems, pagination.total, value.totalItems, value.total, value.totalElements]) {
+    if (typeof candidate === "number") return candidate;
+  }
+  for (const key of ["items", "issues", "results", "content"]) {
+    if (Array.isArray(value[key])) return (value[key] as unknown[]).length;
+  }
+  return 0;
+}
+
+function listItems(value: unknown): Record<string, unknown>[] {
+  const items = Array.isArray(value) ? value : isRecord(value) && Array.isArray(value.items) ? value.items : [];
+  return items.filter(isRecord);
+}
+
+/** Sum severity counts from an SCS vulnerability_count (object or bare number). */
+function scsVulnerabilities(value: unknown): { critical: number; high: number; total: number } {
+  if (typeof value === "number") return { critical: 0, high: 0, total: value };
+  if (!isRecord(value)) return { critical: 0, high: 0, total: 0 };
+  const n = (v: unknown) => (typeof v === "number" ? v : 0);
+  const total = typeof value.total === "number"
+    ? value.total
+    : Object.values(value).reduce<number>((sum, v) => sum + n(v), 0);
+  return { critical: n(value.critical), high: n(value.high), total };
+}
+
+function unavailable(section: PostureSection<unknown>): Record<string, unknown> {
+  return { status: section.status, reason: (section as { reason: string }).reason };
+}
+
 /**
- * Extract chaos application-map (a.k.a. network map) list response:
- * { data: [...], page: { index, limit, totalPages, totalItems } }
- *
- * Note: the JSON key is "page" (not "pagination" like other chaos
- * resources), per ListTargetNetworkMapResponse in hce-saas
- * pkg/networkmap/types.go. We don't widen chaosPageExtract because
- * "page" also means a query param, and silently accepting both shapes
- * could mask bugs in unrelated endpoints.
+ * service_security_posture extractor: normalizes STO, SCS, chaos, and policy
+ * results into one snapshot and derives an overall risk_level with the
+ * findings that drove it. Sections that could not be read are kept with their
+ * status and reason so callers can tell "no findings" from "not checked".
  */
-export const chaosAppMapPageExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { data?: unknown[]; page?: { totalItems?: number } };
+export const serviceSecurityPostureExtract = (raw: unknown): unknown => {
+  const scan = raw as ServicePostureScan;
+  const drivers: Array<{ level: RiskLevel; reason: string }> = [];
+  const flag = (level: RiskLevel, reason: string) => drivers.push({ level, reason });
+
+  let sto: Record<string, unknown>;
+  if (scan.sto.status === "ok") {
+    const counts = {
+      critical: listTotal(scan.sto.data.Critical),
+      high: listTotal(scan.sto.data.High),
+      medium: listTotal(scan.sto.data.Medium),
+      low: listTotal(scan.sto.data.Low),
+    };
+    sto = { status: "ok", open_issues: { ...counts, total: counts.critical + counts.high + counts.medium + counts.low } };
+    if (counts.critical > 0) flag("critical", `${counts.critical} open critical STO issue(s)`);
+    if (counts.high > 0) flag("high", `${counts.high} open high STO issue(s)`);
+    if (counts.medium > 0) flag("medium", `${counts.medium} open medium STO issue(s)`);
+  } else {
+    sto = unavailable(scan.sto);
+  }
+
+  let scs: Record<string, unknown>;
+  if (scan.scs.status === "ok") {
+    let violations = 0;
+    const artifacts = listItems(scan.scs.data).map((a) => {
+      const pe = isRecord(a.policy_enforcement) ? a.policy_enforcement : {};
+      const policyViolations = (Number(pe.allow_list_violation_count) || 0) + (Number(pe.deny_list_violation_count) || 0);
+      violations += policyViolations;
+      const vulns = scsVulnerabilities(a.vulnerability_count);
+      if (vulns.critical > 0) flag("high", `${vulns.critical} critical vulnerabilit${vulns.critical === 1 ? "y" : "ies"} in artifact ${String(a.name ?? a.id)}`);
+      return {
+        artifact_id: a.artifact_id ?? a.id,
+        name: a.name,
+        tag: a.tag,
+        vulnerabilities: vulns,
+        policy_violations: policyViolations,
+        ...(pe.id ? { enforcement_id: pe.id } : {}),
+        ...(a.scorecard !== undefined ? { scorecard: a.scorecard } : {}),
+      };
+    });
+    scs = { status: "ok", artifacts, policy_violations: violations };
+    if (violations > 0) flag("high", `${violations} SCS policy violation(s)`);
+  } else {
+    scs = unavailable(scan.scs);
+  }
+
+  let chaos: Record<string, unknown>;
+  if (scan.chaos.status === "ok") {
+    const scored = listItems(scan.chaos.data).flatMap((e) => {
+      const runs = Array.isArray(e.recentExperimentRunDetails) ? e.recentExperimentRunDetails.filter(isRecord) : [];
+      const score = runs[0]?.resiliencyScore;
+      return typeof score === "number"
+        ? [{ experiment_id: e.experimentId ?? e.experimentID, name: e.name, resilience_score: score }]
+        : [];
+    });
+    const average = scored.length
+      ? Math.round(scored.reduce((sum, e) => sum + e.resilience_score, 0) / scored.length)
+      : null;
+    const weakest = scored.reduce<(typeof scored)[number] | null>((min, e) => (!min || e.resilience_score < min.resilience_score ? e : min), null);
+    chaos = { status: "ok", experiments: listTotal(scan.chaos.data), scored_experiments: scored.length, resilience_score: average, weakest };
+    if (average !== null && average < 50) flag("high", `Chaos resilience score ${average} (< 50)`);
+    else if (average !== null && average < 80) flag("medium", `Chaos resilience score ${average} (< 80)`);
+    if (scored.length === 0) flag("medium", "No chaos experiment results for this service");
+  } else {
+    chaos = unavailable(scan.chaos);
+  }
+
+  let policy: Record<string, unknown>;
+  if (scan.policy.status === "ok") {
+    const open = listTotal(scan.policy.data);
+    policy = {
+      status: "ok",
+      open_violations: open,
+      recent: listItems(scan.policy.data).slice(0, 5).map((e) => ({
+        evaluation_id: e.evaluation_id ?? e.id,
+        action: e.action,
+        status: e.status,
+        created: e.created,
+      })),
+    };
+    if (open > 0) flag("high", `${open} failing policy evaluation(s)`);
+  } else {
+    policy = unavailable(scan.policy);
+  }
+
+  const checked = [scan.sto, scan.scs, scan.chaos, scan.policy].filter((s) => s.status === "ok").length;
+  const level = drivers.reduce<RiskLevel>(
+    (max, d) => (RISK_ORDER.indexOf(d.level) > RISK_ORDER.indexOf(max) ? d.level : max),
+    "low",
+  );
+  const service = isRecord(scan.service) && isRecord(scan.service.service) ? scan.service.service : isRecord(scan.service) ? scan.service : {};
+
   return {
-    items: r.data ?? [],
-    total: r.page?.totalItems ?? (Array.isArray(r.data) ? r.data.length : 0),
+    service_id: scan.service_id,
+    ...(service.name ? { service_name: service.name } : {}),
+    risk_level: checked === 0 ? "unknown" : level,
+    risk_drivers: drivers
+      .sort((a, b) => RISK_ORDER.indexOf(b.level) - RISK_ORDER.indexOf(a.level))
+      .map((d) => `${d.level}: ${d.reason}`),
+    sources_checked: checked,
+    sto,
+    scs,
+    chaos,
+    policy,
+    ...(isRecord(scan.service) && typeof scan.service.openInHarness === "string" ? { openInHarness: scan.service.openInHarness } : {}),
   };
 };
 
 /**
- * Extract chaos probe list response: { totalNoOfProbes, data: [...] }
+ * audit_export extractor: formats an audit list page as OCSF or CEF records.
+ * Writing them to output_dir is left to auditSiemExportWrite, which runs on
+ * cached results too.
  */
-export const chaosProbeListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { data?: unknown[]; totalNoOfProbes?: number };
-  return {
-    items: r.data ?? [],
-    total: r.totalNoOfProbes ?? (Array.isArray(r.data) ? r.data.length : 0),
+export const auditSiemExportExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const { items, total } = pageExtract(raw);
+  const format: SiemFormat = String(input?.format ?? "ocsf").toLowerCase() === "cef" ? "cef" : "ocsf";
+  const page = Number(input?.page ?? 0) || 0;
+  const pageSize = Number(input?.size ?? 0) || items.length;
+  const hasMore = pageSize > 0 && (page + 1) * pageSize < total;
+  const records = formatSiemRecords(items, format);
+  const result = {
+    format,
+    window: { start_time: input?.start_time ?? null, end_time: input?.end_time ?? null },
+    page,
+    page_size: pageSize,
+    total,
+    has_more: hasMore,
+    ...(hasMore ? { _nextPageHint: `More events remain — call again with page=${page + 1}.` } : {}),
   };
+  return { ...result, items: records };
 };
 
 /**
- * Extract chaos infrastructure list response: { totalNoOfInfras, infras: [...] }
+ * audit_export output: with output_dir, writes the page's records to a file
+ * and returns the page summary with the file path in place of the records.
  */
-export const chaosInfraListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { infras?: unknown[]; totalNoOfInfras?: number };
-  return {
-    items: r.infras ?? [],
-    total: r.totalNoOfInfras ?? (Array.isArray(r.infras) ? r.infras.length : 0),
-  };
+export const auditSiemExportWrite = (result: unknown, input: Record<string, unknown>): unknown => {
+  const outputDir = typeof input.output_dir === "string" ? input.output_dir.trim() : "";
+  if (!outputDir || !isRecord(result) || !Array.isArray(result.items)) return result;
+  const records = result.items as Array<Record<string, unknown> | string>;
+  const format: SiemFormat = result.format === "cef" ? "cef" : "ocsf";
+  const file = writeSiemExport(outputDir, format, {
+    startTime: new Date(String(input.start_time)).getTime(),
+    endTime: new Date(String(input.end_time)).getTime(),
+    page: Number(result.page) || 0,
+  }, records);
+  return { ...result, items: [], exported: records.length, file };
 };
 
 /**
- * Extract chaos K8s infrastructure list response:
- * { infras: [...], totalNoOfInfrastructures, pagination }
+ * Extracts CCM list responses with views/totalCount structure.
+ * Maps `data.views` → `items` and `data.totalCount` → `total`.
+ * Used by multiple CCM APIs that return this response pattern.
  */
-export const chaosK8sInfraListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { infras?: unknown[]; totalNoOfInfrastructures?: number };
+export const ccmViewsExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { data?: { views?: unknown[]; totalCount?: number } };
   return {
-    items: r.infras ?? [],
-    total: r.totalNoOfInfrastructures ?? (Array.isArray(r.infras) ? r.infras.length : 0),
+    items: r.data?.views ?? [],
+    total: r.data?.totalCount ?? 0,
   };
 };
 
-type LoadtestInputLike = { name?: unknown; value?: unknown; type?: unknown; required?: unknown };
-
-// Read a well-known input value from the inputs[] slice by name.
-const readLoadtestInput = (inputs: LoadtestInputLike[] | undefined, name: string): unknown =>
-  (inputs ?? []).find((i) => i?.name === name)?.value;
+/** Extract anomaly list: returns { items, total } so skipCompact marker survives normalization. */
+export const anomalyListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { data?: unknown[] };
+  const items = Array.isArray(r?.data) ? r.data : [];
+  return { items, total: items.length };
+};
 
 /**
- * Project a single load test (InternalApiLoadTestResponse) to a stable shape.
- *
- * The new wire format carries every recognised tunable inside `inputs[]`
- * (TemplateInput) and user-defined non-secrets inside `variables[]`
- * (TemplateVariable). We pass both arrays through as-is for fidelity AND
- * surface derived convenience scalars (target_url / users / duration_sec / ...)
- * that mirror the create-side LLM surface so round-trips are intuitive.
- *
- * Drops the large base64 scriptContent and backend user-detail envelopes.
- * Mirrors `identity` into `loadtestId` so the deep-link resolver fills
- * {loadtestId} instead of falling back to the name.
+ * Extracts CCM cost breakdown data from GraphQL perspectiveGrid response.
+ * Maps `data.perspectiveGrid.data` → `items` and `data.perspectiveTotalCount` → `total`.
  */
-export const chaosLoadTestExtract = (raw: unknown): unknown => {
-  if (!raw || typeof raw !== "object" || Array.isArray(raw)) return raw;
-  const t = raw as Record<string, unknown>;
-  const inputs = Array.isArray(t.inputs) ? (t.inputs as LoadtestInputLike[]) : undefined;
-  const variables = Array.isArray(t.variables) ? (t.variables as unknown[]) : undefined;
+export const ccmBreakdownExtract = (raw: unknown, input?: Record<string, unknown>): { items: unknown[]; total: number } => {
+  const r = raw as {
+    data?: {
+      perspectiveGrid?: { data?: unknown[] };
+      perspectiveTotalCount?: number;
+    };
+  };
+  const items = r.data?.perspectiveGrid?.data ?? [];
+  const total = r.data?.perspectiveTotalCount ?? 0;
+  const conversion = ccmConversion(input);
+  if (!conversion) return { items, total };
   return {
-    loadtestId: t.identity,
-    identity: t.identity,
-    name: t.name,
-    description: t.description,
-    tags: t.tags,
-    environmentIdentifier: t.environmentIdentifier,
-    infraIdentifier: t.infraIdentifier,
-    targetType: t.targetType,
-    toolType: t.toolType,
-    scriptSource: t.scriptSource,
-    // Canonical slices — passed through as-is for fidelity.
-    inputs,
-    variables,
-    yaml: t.yaml,
-    // Derived convenience scalars (read-side mirror of the create-side LLM surface).
-    target_url: readLoadtestInput(inputs, "targetUrl"),
-    users: readLoadtestInput(inputs, "targetUsers"),
-    duration_sec: readLoadtestInput(inputs, "durationSeconds"),
-    ramp_up_sec: readLoadtestInput(inputs, "rampUpTimeSec"),
-    worker_count: readLoadtestInput(inputs, "workerCount"),
-    script_image: readLoadtestInput(inputs, "scriptImage"),
-    script_entrypoint: readLoadtestInput(inputs, "scriptEntrypoint"),
-    load_args: readLoadtestInput(inputs, "loadArgs"),
-    lastExecuted: t.lastExecuted,
-    createdAt: t.createdAt,
-    updatedAt: t.updatedAt,
+    items: items.map((item) => {
+      if (!isRecord(item) || typeof item.cost !== "number") return item;
+      const cost = convertCost(item.cost, conversion);
+      return { ...item, cost, costDisplay: formatMoney(cost, conversion.target) };
+    }),
+    total,
+    ...ccmCurrencyField(conversion),
   };
 };
 
-/**
- * Load test list response: { items, pagination: { totalItems } }.
- * Projects each item via chaosLoadTestExtract → { items, total }.
- */
-export const chaosLoadTestListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { items?: unknown[]; pagination?: { totalItems?: number } };
-  const items = (r.items ?? []).map(chaosLoadTestExtract);
-  return { items, total: r.pagination?.totalItems ?? items.length };
-};
+/** Rows cost_cluster_workload ranks as the largest increases. */
+const CLUSTER_TOP_INCREASES = 3;
 
 /**
- * Extract chaos hub list response: { items: [...], pagination: { totalItems } }
+ * Cluster workload grid: one row per namespace/workload/node pool/node with
+ * its cost, trend, and cluster cost split, plus the rows whose cost grew the
+ * most in absolute terms (a small namespace doubling matters less than a big
+ * one growing 20%). Amounts are converted when `currency` was requested.
  */
-export const chaosHubListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { items?: unknown[]; pagination?: { totalItems?: number } };
+export const ccmClusterWorkloadExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const r = raw as { data?: { perspectiveGrid?: { data?: unknown[] }; perspectiveTotalCount?: number } };
+  const conversion = ccmConversion(input);
+  const money = (value: unknown) => {
+    if (typeof value !== "number") return null;
+    return conversion ? convertCost(value, conversion) as number : Math.round(value * 100) / 100;
+  };
+  const groupBy = (input?.group_by as string | undefined) || "namespace";
+  const items = (r.data?.perspectiveGrid?.data ?? []).filter(isRecord).map((row) => {
+    const c = isRecord(row.clusterData) ? row.clusterData : {};
+    return {
+      [groupBy]: row.name,
+      cost: money(row.cost),
+      cost_trend: typeof row.costTrend === "number" ? row.costTrend : null,
+      ...(groupBy === "workload" && c.namespace ? { namespace: c.namespace } : {}),
+      ...(groupBy === "workload" && c.workloadType ? { workload_type: c.workloadType } : {}),
+      idle_cost: money(c.idleCost),
+      unallocated_cost: money(c.unallocatedCost),
+      utilized_cost: money(c.utilizedCost),
+      cpu_cost: money(c.cpuBillingAmount),
+      memory_cost: money(c.memoryBillingAmount),
+      storage_cost: money(c.storageCost),
+      ...(typeof c.efficiencyScore === "number" ? { efficiency_score: c.efficiencyScore } : {}),
+    };
+  });
+  // cost_trend is the % change against the previous period of the same length.
+  const increase = (item: (typeof items)[number]) =>
+    typeof item.cost === "number" && typeof item.cost_trend === "number" && item.cost_trend > -100
+      ? item.cost - item.cost / (1 + item.cost_trend / 100)
+      : 0;
+  const largestIncreases = items
+    .map((item) => ({ name: item[groupBy], increase: Math.round(increase(item) * 100) / 100, cost_trend: item.cost_trend }))
+    .filter((item) => item.increase > 0)
+    .sort((a, b) => b.increase - a.increase)
+    .slice(0, CLUSTER_TOP_INCREASES);
   return {
-    items: r.items ?? [],
-    total: r.pagination?.totalItems ?? (Array.isArray(r.items) ? r.items.length : 0),
+    cluster: input?.cluster,
+    group_by: groupBy,
+    items,
+    total: r.data?.perspectiveTotalCount ?? items.length,
+    ...(largestIncreases.length > 0 ? { largest_increases: largestIncreases } : {}),
+    ...(conversion ? ccmCurrencyField(conversion) : {}),
+    ...(groupBy === "namespace"
+      ? { _hint: "Drill into a namespace with group_by='workload' and namespace='<name>'." }
+      : {}),
   };
 };
 
-/** Extract chaos DR test list response: { items: [...], pagination: { totalItems } } */
-export const chaosDRTestListExtract = (raw: unknown): { items: unknown[]; total: number } => {
-  const r = raw as { items?: unknown[]; pagination?: { totalItems?: number } };
-  const items = r.items ?? [];
-  return { items, total: r.pagination?.totalItems ?? items.length };
+/**
+ * Extracts CCM cost time series stats from GraphQL perspectiveTimeSeriesStats response.
+ * Returns the `stats` array from `data.perspectiveTimeSeriesStats.stats`, or
+ * `{ items, total, currency }` with converted values when `currency` was requested.
+ */
+export const ccmTimeseriesExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const r = raw as {
+    data?: { perspectiveTimeSeriesStats?: { stats?: unknown[] } };
+  };
+  const stats = r.data?.perspectiveTimeSeriesStats?.stats ?? [];
+  const conversion = ccmConversion(input);
+  if (!conversion) return stats;
+  const items = stats.map((point) => {
+    if (!isRecord(point) || !Array.isArray(point.values)) return point;
+    return {
+      ...point,
+      values: point.values.map((v) => (isRecord(v) && typeof v.value === "number" ? { ...v, value: convertCost(v.value, conversion) } : v)),
+    };
+  });
+  return { items, total: items.length, ...ccmCurrencyField(conversion) };
 };
 
-// ---------------------------------------------------------------------------
+/**
+ * Extracts CCM cost summary from a dual-mode GraphQL response.
+ * When `data.ccmMetaData` is present (metadata query), returns it directly.
+ * Otherwise returns `{ trendStats, forecastCost }` for a perspective summary query;
+ * with `currency` requested, every stat `value` is converted and its
+ * `statsValue` re-rendered in the target currency.
+ */
+export const ccmSummaryExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const r = raw as { data?: Record<string, unknown> };
+  if (!r.data) return raw;
+  if (r.data.ccmMetaData) return r.data.ccmMetaData;
+  const conversion = ccmConversion(input);
+  if (!conversion) {
+    return {
+      trendStats: r.data.perspectiveTrendStats,
+      forecastCost: r.data.perspectiveForecastCost,
+    };
+  }
+  const convertStats = (group: unknown, keys: string[]): unknown => {
+    if (!isRecord(group)) return group;
+    const out: Record<string, unknown> = { ...group };
+    for (const key of keys) {
+      const stat = group[key];
+      if (!isRecord(stat) || typeof stat.value !== "number") continue;
+      const value = convertCost(stat.value, conversion);
+      out[key] = { ...stat, value, statsValue: formatMoney(value, conversion.target) };
+    }
+    return out;
+  };
+  return {
+    trendStats: convertStats(r.data.perspectiveTrendStats, ["cost", "idleCost", "unallocatedCost", "utilizedCost"]),
+    forecastCost: convertStats(r.data.perspectiveForecastCost, ["cost"]),
+    ...ccmCurrencyField(conversion),
+  };
+};
+
+/**
+ * Extracts CCM budget summaries (`data.budgetSummaryList`). With `currency`
+ * requested, `budgetAmount` and `actualCost` are converted.
+ */
+export const ccmBudgetExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const budgets = gqlExtract("budgetSummaryList")(raw);
+  const conversion = ccmConversion(input);
+  if (!conversion || !Array.isArray(budgets)) return budgets;
+  return {
+    items: budgets.map((b) => (isRecord(b)
+      ? { ...b, budgetAmount: convertCost(b.budgetAmount, conversion), actualCost: convertCost(b.actualCost, conversion) }
+      : b)),
+    ...ccmCurrencyField(conversion),
+  };
+};
+
+/**
+ * Account currency preference from the CCM metadata query. CCM reports every
+ * cost in this currency; accounts that never set one are billed in USD.
+ */
+export const ccmCurrencyPreferenceExtract = (raw: unknown): unknown => {
+  const meta = isRecord(raw) && isRecord(raw.data) && isRecord(raw.data.ccmMetaData) ? raw.data.ccmMetaData : {};
+  const pref = isRecord(meta.currencyPreference) ? meta.currencyPreference : {};
+  const code = typeof pref.destinationCurrency === "string" && pref.destinationCurrency ? pref.destinationCurrency.toUpperCase() : "USD";
+  return {
+    currency: code,
+    symbol: typeof pref.symbol === "string" ? pref.symbol : null,
+    locale: typeof pref.locale === "string" ? pref.locale : null,
+    set_at: typeof pref.setupTime === "number" && pref.setupTime > 0 ? new Date(pref.setupTime).toISOString() : null,
+    configured: typeof pref.destinationCurrency === "string" && pref.destinationCurrency.length > 0,
+  };
+};
+
+/**
+ * CCM currency conversion factors, flattened to `{ source, destination, factor, month }`.
+ * Accepts `data` as a list or as `{ content | conversionFactors }`.
+ */
+export const ccmConversionFactorsExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const data = isRecord(raw) && "data" in raw ? raw.data : raw;
+  const rows = Array.isArray(data)
+    ? data
+    : isRecord(data) && Array.isArray(data.content) ? data.content
+    : isRecord(data) && Array.isArray(data.conversionFactors) ? data.conversionFactors
+    : [];
+  const items = rows.filter(isRecord).flatMap((row) => {
+    const source = row.sourceCurrency ?? row.source;
+    const destination = row.destinationCurrency ?? row.destination;
+    const factor = Number(row.conversionFactor ?? row.factor);
+    if (typeof source !== "string" || typeof destination !== "string" || !Number.isFinite(factor) || factor <= 0) return [];
+    return [{
+      source: source.toUpperCase(),
+      destination: destination.toUpperCase(),
+      factor,
+      month: typeof row.month === "string" ? row.month : null,
+    }];
+  });
+  return { items, total: items.length };
+};
+
+export interface CcmCurrencyConversion {
+  /** Currency CCM reported the costs in (the account currency). */
+  source: string;
+  /** Currency the caller asked for. */
+  target: string;
+  /** Multiply a source amount by this to get the target amount. */
+  rate: number;
+}
+
+/** Conversion resolved by the CCM currency preflight, when `currency` was passed. */
+function ccmConversion(input?: Record<string, unknown>): CcmCurrencyConversion | undefined {
+  const c = input?.currency_conversion;
+  if (!isRecord(c) || typeof c.source !== "string" || typeof c.target !== "string" || typeof c.rate !== "number") return undefined;
+  return { source: c.source, target: c.target, rate: c.rate };
+}
+
+function convertCost(value: unknown, conversion: CcmCurrencyConversion): unknown {
+  return typeof value === "number" ? Math.round(value * conversion.rate * 100) / 100 : value;
+}
+
+function ccmCurrencyField(conversion: CcmCurrencyConversion): { currency: Record<string, unknown> } {
+  return {
+    currency: {
+      code: conversion.target,
+      source: conversion.source,
+      ...(conversion.source !== conversion.target ? { rate: conversion.rate } : {}),
+    },
+  };
+}
+
+/** `1234.5, "USD"` → `$1,234.50`; unknown codes fall back to `1234.50 XYZ`. */
+export function formatMoney(value: unknown, code: string): string | null {
+  if (typeof value !== "number") return null;
+  try {
+    return new Intl.NumberFormat("en-US", { style: "currency", currency: code, minimumFractionDigits: 2, maximumFractionDigits: 2 }).format(value);
+  } catch {
+    return `${value.toFixed(2)} ${code}`;
+  }
+}
+
+/**
+ * Extracts CCM perspective-scoped recommendations from GraphQL response.
+ * Returns `{ items, stats }` from `data.recommendationsV2` and `data.recommendationStatsV2`.
+ */
+export const ccmRecommendationsExtract = (raw: unknown): { items: unknown[]; stats: unknown } => {
+  const r = raw as {
+    data?: {
+      recommendationsV2?: { items?: unknown[] };
+      recommendationStatsV2?: unknown;
+    };
+  };
+  return {
+    items: r.data?.recommendationsV2?.items ?? [],
+    stats: r.data?.recommendationStatsV2,
+  };
+};
+
+/** Resource presets available on a CCM workload container recommendation. */
+export const WORKLOAD_PATCH_PRESETS = ["recommended", "burstable", "guaranteed", "p50", "p80", "p90", "p95", "p99"] as const;
+
+/** Pod-template path and apiVersion per workload kind, for strategic merge patches. */
+const WORKLOAD_KINDS: Record<string, { apiVersion: string; templatePath: string[] }> = {
+  Deployment: { apiVersion: "apps/v1", templatePath: ["spec", "template", "spec"] },
+  StatefulSet: { apiVersion: "apps/v1", templatePath: ["spec", "template", "spec"] },
+  DaemonSet: { apiVersion: "apps/v1", templatePath: ["spec", "template", "spec"] },
+  ReplicaSet: { apiVersion: "apps/v1", templatePath: ["spec", "template", "spec"] },
+  Job: { apiVersion: "batch/v1", templatePath: ["spec", "template", "spec"] },
+  CronJob: { apiVersion: "batch/v1", templatePath: ["spec", "jobTemplate", "spec", "template", "spec"] },
+  Pod: { apiVersion: "v1", templatePath: ["spec"] },
+};
+
+/** Keep only cpu/memory requests and limits with non-empty values. */
+function pickResourceRequirements(value: unknown): { requests?: Record<string, unknown>; limits?: Record<string, unknown> } | undefined {
+  if (!isRecord(value)) return undefined;
+  const result: { requests?: Record<string, unknown>; limits?: Record<string, unknown> } = {};
+  for (const key of ["requests", "limits"] as const) {
+    const section = value[key];
+    if (!isRecord(section)) continue;
+    const picked: Record<string, unknown> = {};
+    for (const resource of ["cpu", "memory"]) {
+      const v = section[resource];
+      if (v !== undefined && v !== null && v !== "") picked[resource] = v;
+    }
+    if (Object.keys(picked).length > 0) result[key] = picked;
+  }
+  return Object.keys(result).length > 0 ? result : undefined;
+}
+
+function nestUnder(path: string[], leaf: Record<string, unknown>): Record<string, unknown> {
+  return path.reduceRight<Record<string, unknown>>((acc, key) => ({ [key]: acc }), leaf);
+}
+
+/**
+ * Builds a Kubernetes strategic-merge patch from a CCM workload recommendation
+ * (GET /ccm/api/recommendation/details/workload).
+ *
+ * Reads `data.containerRecommendations.<container>` and picks the requested
+ * preset (`recommended` by default, falling back to `burstable`; percentile
+ * presets read `percentileBased.pNN`). Emits per-container current vs proposed
+ * requests/limits, the patch as YAML, and a `kubectl patch` command. Workload
+ * kind/name/namespace come from params, then the response; missing values are
+ * left as `<placeholders>` with a `_hint`. When `repo_id` and `file_path` are
+ * passed, `pull_request_steps` lists the harness_* calls that land the change
+ * as a Harness Code PR.
+ */
+export const ccmWorkloadPatchExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const data = isRecord(raw) && isRecord(raw.data) ? raw.data : {};
+  const requested = typeof input?.preset === "string" && input.preset ? input.preset : "recommended";
+  const preset = (WORKLOAD_PATCH_PRESETS as readonly string[]).includes(requested) ? requested : "recommended";
+  const str = (v: unknown): string | undefined => (typeof v === "string" && v ? v : undefined);
+
+  const kindInput = str(input?.kind) ?? str(data.workloadType) ?? "Deployment";
+  const kind = Object.keys(WORKLOAD_KINDS).find((k) => k.toLowerCase() === kindInput.toLowerCase()) ?? kindInput;
+  const shape = WORKLOAD_KINDS[kind] ?? WORKLOAD_KINDS.Deployment!;
+  const workload = {
+    kind,
+    name: str(input?.workload_name) ?? str(data.workloadName) ?? "<workload-name>",
+    namespace: str(input?.namespace) ?? str(data.namespace) ?? "<namespace>",
+    cluster: str(data.clusterName) ?? null,
+  };
+
+  const containerRecs = isRecord(data.containerRecommendations) ? data.containerRecommendations : {};
+  const containers: Array<{ name: string; current: unknown; proposed: unknown }> = [];
+  const skipped: string[] = [];
+  for (const [name, rec] of Object.entries(containerRecs)) {
+    if (!isRecord(rec)) continue;
+    const percentiles = isRecord(rec.percentileBased) ? rec.percentileBased : {};
+    const proposed = preset.startsWith("p")
+      ? pickResourceRequirements(percentiles[preset])
+      : pickResourceRequirements(rec[preset]) ?? (preset === "recommended" ? pickResourceRequirements(rec.burstable) : undefined);
+    if (!proposed) {
+      skipped.push(name);
+      continue;
+    }
+    containers.push({ name, current: pickResourceRequirements(rec.current) ?? null, proposed });
+  }
+
+  const patch = {
+    apiVersion: shape.apiVersion,
+    kind,
+    metadata: { name: workload.name, namespace: workload.namespace },
+    ...nestUnder(shape.templatePath, {
+      containers: containers.map((c) => ({ name: c.name, resources: c.proposed })),
+    }),
+  };
+  const patchYaml = containers.length > 0 ? YAML.stringify(patch) : null;
+
+  const hints: string[] = [];
+  if (containers.length === 0) hints.push(`No container has a '${preset}' recommendation — try another preset.`);
+  if (workload.name.startsWith("<") || workload.namespace.startsWith("<")) {
+    hints.push("Workload name/namespace were not in the recommendation — pass workload_name and namespace params (see harness_list(resource_type='cost_recommendation') resourceName/namespace).");
+  }
+
+  const repoId = str(input?.repo_id);
+  const filePath = str(input?.file_path);
+  const baseBranch = str(input?.branch) ?? "main";
+  const recommendationId = str(input?.recommendation_id) ?? str(data.id) ?? null;
+  const newBranch = `ccm-rightsize-${workload.name.replace(/[^a-zA-Z0-9-]/g, "") || "workload"}`;
+
+  return {
+    recommendation_id: recommendationId,
+    preset,
+    workload,
+    containers,
+    ...(skipped.length > 0 ? { skipped_containers: skipped } : {}),
+    patch_yaml: patchYaml,
+    kubectl_command: patchYaml
+      ? `kubectl -n ${workload.namespace} patch ${kind.toLowerCase()} ${workload.name} --type strategic --patch-file patch.yaml`
+      : null,
+    ...(repoId && filePath && patchYaml
+      ? {
+        pull_request_steps: [
+          `harness_get(resource_type='file_content', repo_id='${repoId}', path='${filePath}', params={git_ref: '${baseBranch}'}) — read the manifest and its blob sha.`,
+          `Apply patch_yaml's container resources to the ${kind} '${workload.name}' in that file (merge by container name).`,
+          `harness_create(resource_type='commit', repo_id='${repoId}', body={title: 'Right-size ${workload.name} (CCM recommendation)', branch: '${baseBranch}', new_branch: '${newBranch}', actions: [{action: 'UPDATE', path: '${filePath}', payload: <updated file>, sha: <blob sha>}]}).`,
+          `harness_create(resource_type='pull_request', repo_id='${repoId}', body={title: 'Right-size ${workload.name}', source_branch: '${newBranch}', target_branch: '${baseBranch}', description: <containers current vs proposed>}).`,
+        ],
+      }
+      : {}),
+    ...(hints.length > 0 ? { _hint: hints.join(" ") } : {}),
+  };
+};
+
+/** Raw reads gathered by cost_commitment_breakdown's collect hook. */
+export interface CommitmentBreakdownScan {
+  metric: "utilisation" | "savings";
+  group_by: "service" | "account" | "day";
+  start_date: string;
+  end_date: string;
+  /** One cost_commitment response per service or cloud account (a single one for day). */
+  groups: Array<{ key: string; response: unknown }>;
+  failures: Array<{ key: string; error: string }>;
+  /** estimated_savings per cloud account, when cloud_account_ids was passed. */
+  recommendations?: Array<{ cloud_account_id: string; response?: unknown; error?: string }>;
+}
+
+/** Root of a Commitment Orchestrator response: `response`, else `data`, else the body itself. */
+function commitmentRoot(raw: unknown): unknown {
+  if (!isRecord(raw)) return raw;
+  return raw.response ?? raw.data ?? raw;
+}
+
+/** Numeric fields of an object (numeric strings included), e.g. a `table` block. */
+function numericFields(value: unknown): Record<string, number> {
+  const out: Record<string, number> = {};
+  if (!isRecord(value)) return out;
+  for (const [key, v] of Object.entries(value)) {
+    const n = typeof v === "number" ? v : typeof v === "string" && v.trim() !== "" ? Number(v) : NaN;
+    if (Number.isFinite(n)) out[key] = n;
+  }
+  return out;
+}
+
+/**
+ * Commitment Orchestrator detail responses hold a `{table, chart}` block per
+ * commitment type (e.g. "Savings Plans", "Reserved Instances"), or a single
+ * block. Returns each block's totals and its chart points by date.
+ */
+function commitmentBlocks(raw: unknown): Array<{ type: string; totals: Record<string, number>; trend: Array<Record<string, unknown>> }> {
+  const root = commitmentRoot(raw);
+  if (!isRecord(root)) return [];
+  const block = (type: string, value: Record<string, unknown>) => ({
+    type,
+    totals: numericFields(value.table),
+    trend: (Array.isArray(value.chart) ? value.chart : []).filter(isRecord).map((point) => {
+      const at = point.date ?? point.time ?? point.timestamp;
+      const values = Object.entries(numericFields(point)).filter(([key]) => !["date", "time", "timestamp"].includes(key));
+      return { date: typeof at === "number" ? new Date(at).toISOString().slice(0, 10) : at, ...Object.fromEntries(values) };
+    }),
+  });
+  if ("table" in root || "chart" in root) return [block("all", root)];
+  return Object.entries(root)
+    .filter((entry): entry is [string, Record<string, unknown>] => isRecord(entry[1]) && ("table" in entry[1] || "chart" in entry[1]))
+    .map(([type, value]) => block(type, value));
+}
+
+/**
+ * Utilisation or savings for cost_commitment_breakdown: one row per group and
+ * commitment type with the reported totals, the per-day trend for
+ * group_by=day, and estimated savings from recommended purchases per cloud
+ * account. Groups that failed to load are listed in errors.
+ */
+export const ccmCommitmentBreakdownExtract = (raw: unknown): unknown => {
+  const scan = raw as CommitmentBreakdownScan;
+  const keyField = scan.group_by === "account" ? "cloud_account_id" : "service";
+  const rows: Array<Record<string, unknown>> = [];
+  const trend: Record<string, Array<Record<string, unknown>>> = {};
+  for (const group of scan.groups) {
+    const blocks = commitmentBlocks(group.response);
+    if (blocks.length === 0) rows.push({ [keyField]: group.key, commitment_type: null, note: "No commitment data in this window." });
+    for (const b of blocks) {
+      rows.push({ [keyField]: group.key, commitment_type: b.type, ...b.totals });
+      if (scan.group_by === "day") trend[b.type] = b.trend;
+    }
+  }
+  const recommendations = scan.recommendations?.map((r) => r.error !== undefined
+    ? { cloud_account_id: r.cloud_account_id, error: r.error }
+    : { cloud_account_id: r.cloud_account_id, ...numericFields(commitmentRoot(r.response)) });
+  const failures = scan.failures.map((f) => ({ [keyField]: f.key, error: f.error }));
+  return {
+    metric: scan.metric,
+    group_by: scan.group_by,
+    window: { start_date: scan.start_date, end_date: scan.end_date },
+    rows,
+    ...(scan.group_by === "day" ? { trend } : {}),
+    ...(recommendations ? { recommended_purchases: recommendations } : {}),
+    ...(failures.length > 0 ? { errors: failures } : {}),
+    ...(!recommendations
+      ? { _hint: "Pass cloud_account_ids to add estimated savings from recommended purchases per cloud account." }
+      : {}),
+  };
+};
+
+/** Raw reads gathered by cost_forecast's collect hook. */
+export interface CostForecastScan {
+  perspective_id?: string;
+  /** Filter set applied on top of (or instead of) the perspective. */
+  filters: Record<string, string[]>;
+  /** Start of the first history day (UTC midnight, epoch ms). */
+  history_start_ms: number;
+  history_days: number;
+  horizon_days: number;
+  confidence: number;
+  /** Raw perspectiveTimeSeriesStats response with daily points. */
+  history: unknown;
+  /** Raw perspective summary response carrying perspectiveForecastCost. */
+  harness_forecast?: { response?: unknown; error?: string };
+}
+
+/** Fewer days with spend than this make a trend line meaningless. */
+const FORECAST_MIN_DATA_DAYS = 7;
+
+const DAY_MS = 86_400_000;
+
+/** Sum timeseries values into one total per history day. */
+function dailySpend(raw: unknown, startMs: number, days: number): number[] {
+  const r = raw as { data?: { perspectiveTimeSeriesStats?: { stats?: unknown[] } } };
+  const totals = new Array<number>(days).fill(0);
+  for (const point of r.data?.perspectiveTimeSeriesStats?.stats ?? []) {
+    if (!isRecord(point) || !Array.isArray(point.values)) continue;
+    const day = Math.floor((Number(point.time) - startMs) / DAY_MS);
+    if (!(day >= 0 && day < days)) continue;
+    for (const v of point.values) {
+      if (isRecord(v) && typeof v.value === "number") totals[day] = (totals[day] ?? 0) + v.value;
+    }
+  }
+  return totals;
+}
+
+/** Consecutive calendar-month slices of the horizon starting at `startMs`. */
+function monthPeriods(startMs: number, days: number): Array<{ month: string; days: number }> {
+  const periods: Array<{ month: string; days: number }> = [];
+  for (let d = 0; d < days; d++) {
+    const month = new Date(startMs + d * DAY_MS).toISOString().slice(0, 7);
+    const last = periods[periods.length - 1];
+    if (last?.month === month) last.days++;
+    else periods.push({ month, days: 1 });
+  }
+  return periods;
+}
+
+/**
+ * Shape a cost_forecast scan: fit a linear trend to daily spend (leading days
+ * before any spend are dropped, since the data simply had not started) and
+ * project it over the horizon with bounds at the requested confidence, per
+ * calendar month and in total. Harness's own perspective forecast is added
+ * for comparison when it was fetched. Amounts are converted when the
+ * currency preflight resolved a conversion.
+ */
+export const ccmCostForecastExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as CostForecastScan;
+  const conversion = ccmConversion(input);
+  const money = (value: number) => (conversion ? convertCost(value, conversion) as number : Math.round(value * 100) / 100);
+  const band = (b: { value: number; lower: number; upper: number }) => ({ forecast: money(b.value), lower: money(b.lower), upper: money(b.upper) });
+
+  const totals = dailySpend(scan.history, scan.history_start_ms, scan.history_days);
+  const firstSpend = totals.findIndex((v) => v > 0);
+  const history = firstSpend === -1 ? [] : totals.slice(firstSpend);
+  const historyStart = new Date(scan.history_start_ms + Math.max(firstSpend, 0) * DAY_MS).toISOString().slice(0, 10);
+  const forecastStartMs = scan.history_start_ms + scan.history_days * DAY_MS;
+  const scope = {
+    ...(scan.perspective_id ? { perspective_id: scan.perspective_id } : {}),
+    ...(Object.keys(scan.filters).length > 0 ? { filters: scan.filters } : {}),
+  };
+  const historySummary = {
+    start: historyStart,
+    end: new Date(forecastStartMs - DAY_MS).toISOString().slice(0, 10),
+    days: history.length,
+    total: money(history.reduce((sum, v) => sum + v, 0)),
+    daily_average: money(history.length > 0 ? history.reduce((sum, v) => sum + v, 0) / history.length : 0),
+  };
+
+  let harness: Record<string, unknown> | undefined;
+  if (scan.harness_forecast?.error !== undefined) {
+    harness = { error: scan.harness_forecast.error };
+  } else if (scan.harness_forecast) {
+    const r = scan.harness_forecast.response as { data?: { perspectiveForecastCost?: { cost?: Record<string, unknown> } } };
+    const cost = r.data?.perspectiveForecastCost?.cost;
+    harness = cost
+      ? { value: typeof cost.value === "number" ? money(cost.value) : null, label: cost.statsLabel ?? null, description: cost.statsDescription ?? null }
+      : { value: null, note: "Harness returned no forecast for this perspective." };
+  }
+
+  if (history.length < FORECAST_MIN_DATA_DAYS) {
+    return {
+      ...scope,
+      history: historySummary,
+      forecast: null,
+      ...(harness ? { harness_forecast: harness } : {}),
+      ...(conversion ? ccmCurrencyField(conversion) : {}),
+      _hint: `Only ${history.length} day(s) with spend in the last ${scan.history_days} days; a forecast needs at least ${FORECAST_MIN_DATA_DAYS}. `
+        + "Check the perspective and filters, or raise history_days.",
+    };
+  }
+
+  const months = monthPeriods(forecastStartMs, scan.horizon_days);
+  const fit = linearForecast(history, scan.horizon_days, scan.confidence, months.map((m) => m.days));
+  const forecastEnd = new Date(forecastStartMs + (scan.horizon_days - 1) * DAY_MS).toISOString().slice(0, 10);
+  const dailyAverage = history.reduce((sum, v) => sum + v, 0) / history.length;
+  const noisy = fit.residual_sd > dailyAverage * 0.25;
+  return {
+    ...scope,
+    history: historySummary,
+    forecast: {
+      start: new Date(forecastStartMs).toISOString().slice(0, 10),
+      end: forecastEnd,
+      horizon_days: scan.horizon_days,
+      confidence: scan.confidence,
+      ...band(fit.total),
+      by_month: months.map((m, i) => ({ month: m.month, days: m.days, ...band(fit.periods[i]!) })),
+    },
+    trend: {
+      change_per_day: money(fit.slope_per_day),
+      direction: Math.abs(fit.slope_per_day) * 30 < dailyAverage * 0.01 ? "flat" : fit.slope_per_day > 0 ? "rising" : "falling",
+      r_squared: fit.r_squared,
+      daily_noise: money(fit.residual_sd),
+    },
+    ...(harness ? { harness_forecast: harness } : {}),
+    ...(conversion ? ccmCurrencyField(conversion) : {}),
+    _hint: `Linear trend over ${history.length} days of spend with ${scan.confidence}% bounds. `
+      + (noisy
+        ? "Daily spend swings widely around the trend, so quote the bounds rather than the point forecast. "
+        : "")
+      + "The bounds assume day-to-day noise like the history's; planned changes (migrations, commitments, launches) are not in the model.",
+  };
+};
+
+/** Rules from a governance rule list response: `{ data: { rules, totalItems } }`. */
+function governanceRules(raw: unknown): { rules: Record<string, unknown>[]; total: number } {
+  const data = isRecord(raw) ? raw.data ?? raw : raw;
+  const rules = Array.isArray(data) ? data : isRecord(data) && Array.isArray(data.rules) ? data.rules : [];
+  const total = isRecord(data) && typeof data.totalItems === "number" ? data.totalItems : rules.length;
+  return { rules: rules.filter(isRecord), total };
+}
+
+/** Governance rule list: one summary per rule, without the rule YAML. */
+export const ccmGovernanceRuleListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const { rules, total } = governanceRules(raw);
+  const items = rules.map((rule) => ({
+    rule_id: rule.uuid ?? rule.identifier,
+    name: rule.name,
+    ...(rule.description ? { description: rule.description } : {}),
+    cloud_provider: rule.cloudProvider,
+    ...(rule.resourceType ? { resource_type: rule.resourceType } : {}),
+    ootb: rule.isOOTB === true,
+    ...(Array.isArray(rule.tags) && rule.tags.length > 0 ? { tags: rule.tags } : {}),
+    ...(rule.lastUpdatedAt ? { updated_at: rule.lastUpdatedAt } : {}),
+  }));
+  return { items, total };
+};
+
+/** One governance rule, YAML included, from a rule list filtered to its ID. */
+export const ccmGovernanceRuleExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const { rules } = governanceRules(raw);
+  const rule = rules.find((r) => r.uuid === input?.rule_id) ?? rules[0];
+  if (!rule) throw new Error(`Governance rule "${String(input?.rule_id)}" not found.`);
+  return rule;
+};
+
+/** Execution IDs from an enqueue response: a string, a list, or `{ ruleExecutionId(s) }`. */
+function executionIds(data: unknown): string[] {
+  if (typeof data === "string") return [data];
+  if (Array.isArray(data)) return data.filter((id): id is string => typeof id === "string");
+  if (!isRecord(data)) return [];
+  return executionIds(data.ruleExecutionIds ?? data.ruleExecutionId ?? data.executionIds ?? data.executionId);
+}
+
+/**
+ * Dry-run enqueue: the execution IDs to poll, with the rule and target
+ * echoed so the caller can tell several dry runs apart.
+ */
+export const ccmGovernanceEnqueueExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const rule = isRecord(input?.governance_rule) ? input.governance_rule : {};
+  const ids = executionIds(isRecord(raw) ? raw.data ?? raw : raw);
+  return {
+    rule_id: input?.rule_id,
+    ...(rule.name ? { rule_name: rule.name } : {}),
+    dry_run: true,
+    target_account: input?.target_account,
+    regions: input?.regions,
+    execution_ids: ids,
+    _hint: ids.length > 0
+      ? `Evaluation queued. Poll harness_get(resource_type='cost_governance_execution', resource_id='${ids[0]}') until status is SUCCESS or FAILED, then add params.view='resources' for what it matched.`
+      : "Evaluation queued. Find it with harness_list(resource_type='cost_governance_execution', filters={rule_id}) — the most recent execution is this dry run.",
+  };
+};
+
+/** Compact view of a governance rule execution. */
+function governanceExecutionSummary(exec: Record<string, unknown>): Record<string, unknown> {
+  return {
+    execution_id: exec.uuid,
+    rule_id: exec.ruleIdentifier,
+    ...(exec.ruleName ? { rule_name: exec.ruleName } : {}),
+    ...(exec.ruleEnforcementIdentifier ? { enforcement_id: exec.ruleEnforcementIdentifier } : {}),
+    cloud_provider: exec.cloudProvider,
+    target_account: exec.targetAccount,
+    regions: exec.targetRegions,
+    status: exec.executionStatus,
+    dry_run: exec.isDryRun === true,
+    resource_count: exec.resourceCount ?? null,
+    potential_savings: typeof exec.potentialSavings === "number" ? exec.potentialSavings : null,
+    realized_savings: typeof exec.realizedSavings === "number" ? exec.realizedSavings : null,
+    ...(exec.errorMessage ? { error: exec.errorMessage } : {}),
+    created_at: exec.createdAt,
+    ...(exec.executionCompletedAt ? { completed_at: exec.executionCompletedAt } : {}),
+  };
+}
+
+/**
+ * Governance execution list: one summary per execution plus savings totals
+ * for the page, so "how much would this cleanup save" needs no arithmetic.
+ */
+export const ccmGovernanceExecutionListExtract = (raw: unknown): unknown => {
+  const data = isRecord(raw) ? raw.data ?? raw : raw;
+  const executions = Array.isArray(data) ? data : isRecord(data) && Array.isArray(data.ruleExecution) ? data.ruleExecution : [];
+  const items = executions.filter(isRecord).map(governanceExecutionSummary);
+  const sum = (key: "potential_savings" | "realized_savings") =>
+    Math.round(items.reduce((total, item) => total + (typeof item[key] === "number" ? item[key] as number : 0), 0) * 100) / 100;
+  return {
+    items,
+    total: isRecord(data) && typeof data.totalItems === "number" ? data.totalItems : items.length,
+    savings: { potential: sum("potential_savings"), realized: sum("realized_savings") },
+  };
+};
+
+/** Resources a governance execution matched are capped at this many in a response. */
+const GOVERNANCE_MAX_RESOURCES = 200;
+
+/**
+ * One governance execution. With view=resources, the resources Cloud
+ * Custodian matched (the API returns them as a JSON document, sometimes
+ * string-encoded), capped at GOVERNANCE_MAX_RESOURCES.
+ */
+export const ccmGovernanceExecutionExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  let data = isRecord(raw) ? raw.data ?? raw : raw;
+  if (input?.view !== "resources") {
+    return isRecord(data) ? governanceExecutionSummary(data) : data;
+  }
+  if (typeof data === "string") {
+    try {
+      data = JSON.parse(data);
+    } catch {
+      return { execution_id: input.execution_id, raw: data };
+    }
+  }
+  const resources = Array.isArray(data) ? data : isRecord(data) && Array.isArray(data.resources) ? data.resources : [];
+  return {
+    execution_id: input.execution_id,
+    total: resources.length,
+    resources: resources.slice(0, GOVERNANCE_MAX_RESOURCES),
+    ...(resources.length > GOVERNANCE_MAX_RESOURCES ? { truncated: true } : {}),
+  };
+};
+
+/** Extract dashboard list response: `{ items, pages, resource }` */
+export const dashboardListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { items?: number; pages?: number; resource?: unknown[] };
+  return {
+    items: r.resource ?? [],
+    total: r.items ?? 0,
+  };
+};
+
+/**
+ * Extracts dashboard data from a ZIP ArrayBuffer containing CSVs.
+ * Matches v1 `get_dashboard_data` behavior: ZIP → CSV → structured JSON tables.
+ */
+export const dashboardDataExtract = (raw: unknown): unknown => {
+  if (raw instanceof ArrayBuffer) {
+    return parseZipCsv(raw);
+  }
+  return raw;
+};
+
+// ---------------------------------------------------------------------------
+// Chaos Engineering extractors
+// ---------------------------------------------------------------------------
+
+/**
+ * Extract chaos paginated list response: { data: [...], pagination: { totalItems } }
+ * Used by chaos experiments and templates.
+ */
+export const chaosPageExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { data?: unknown[]; pagination?: { totalItems?: number } };
+  return {
+    items: r.data ?? [],
+    total: r.pagination?.totalItems ?? (Array.isArray(r.data) ? r.data.length : 0),
+  };
+};
+
+/**
+ * Chaos v2 experiment list items expose their id as `experimentID` (capital ID),
+ * but the deep-link resolver and the get-op path param use `experimentId`. Mirror
+ * the value so per-item `openInHarness` links use the UUID instead of falling back
+ * to the experiment name. Wraps chaosPageExtract; all other fields are preserved.
+ */
+export const chaosExperimentListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const page = chaosPageExtract(raw);
+  const items = page.items.map((item) => {
+    if (item && typeof item === "object" && !Array.isArray(item)) {
+      const rec = item as Record<string, unknown>;
+      const lastRun = chaosRecentRuns(rec)[0];
+      const withRun = lastRun ? { ...rec, last_run: lastRun } : rec;
+      if (typeof rec.experimentID === "string" && rec.experimentId === undefined) {
+        return { ...withRun, experimentId: rec.experimentID };
+      }
+      return withRun;
+    }
+    return item;
+  });
+  return { items, total: page.total };
+};
+
+/** Epoch milliseconds (number or numeric string) or a date string, as RFC3339. */
+const chaosTime = (v: unknown): string | undefined => {
+  const ms = typeof v === "number" ? v : typeof v === "string" && /^\d+$/.test(v) ? Number(v) : typeof v === "string" ? Date.parse(v) : NaN;
+  return Number.isFinite(ms) && ms > 0 ? new Date(ms).toISOString() : undefined;
+};
+
+/**
+ * An experiment's recentExperimentRunDetails reduced to each run's outcome:
+ * run id, phase, resilience score, and when it ran. Newest first, as the
+ * backend returns them.
+ */
+function chaosRecentRuns(experiment: Record<string, unknown>): Array<Record<string, unknown>> {
+  const runs = Array.isArray(experiment.recentExperimentRunDetails) ? experiment.recentExperimentRunDetails.filter(isRecord) : [];
+  return runs.map((run) => {
+    const notifyId = run.notifyID ?? run.notifyId;
+    const at = chaosTime(run.updatedAt ?? run.createdAt);
+    return {
+      run_id: run.experimentRunID ?? run.experimentRunId,
+      ...(notifyId ? { notify_id: notifyId } : {}),
+      ...(run.phase !== undefined ? { phase: run.phase } : {}),
+      ...(typeof run.resiliencyScore === "number" ? { resilience_score: run.resiliencyScore } : {}),
+      ...(at ? { run_at: at } : {}),
+    };
+  });
+}
+
+/**
+ * chaos_experiment get extractor: the experiment as returned, plus its recent
+ * runs with resilience scores and a summary — latest and average score across
+ * the scored runs. Experiments that have never run come back unchanged.
+ */
+export const chaosExperimentGetExtract = (raw: unknown): unknown => {
+  if (!isRecord(raw)) return raw;
+  const experiment = isRecord(raw.data) ? raw.data : raw;
+  const recent = chaosRecentRuns(experiment);
+  if (recent.length === 0) return raw;
+  const scores = recent.flatMap((r) => (typeof r.resilience_score === "number" ? [r.resilience_score] : []));
+  return {
+    ...raw,
+    recent_runs: recent,
+    resilience: {
+      runs: recent.length,
+      scored_runs: scores.length,
+      ...(scores.length > 0
+        ? { latest_score: scores[0], average_score: Math.round(scores.reduce((a, b) => a + b, 0) / scores.length) }
+        : {}),
+    },
+    _hint: "For a run's fault-by-fault timeline, harness_get(resource_type=\"chaos_experiment_run\", resource_id=<experiment_id>, run_id=<run_id>).",
+  };
+};
+
+/**
+ * The create-action handler echoes back the request `actions.Action` (clean,
+ * no backend envelope). Project a stable, documented shape so no raw
+ * passthrough crosses the tool boundary and future server-added fields stay
+ * out of the public contract.
+ */
+export const chaosActionExtract = (raw: unknown): unknown => {
+  if (!raw || typeof raw !== "object" || Array.isArray(raw)) return raw;
+  const a = raw as Record<string, unknown>;
+  return {
+    identity: a.identity,
+    name: a.name,
+    description: a.description,
+    tags: a.tags,
+    type: a.type,
+    infrastructureType: a.infrastructureType,
+    hubRef: a.hubRef,
+    actionsTemplateRef: a.actionsTemplateRef,
+    actionProperties: a.actionProperties,
+    runProperties: a.runProperties,
+    variables: a.variables,
+    inputs: a.inputs,
+  };
+};
+
+/**
+ * Input-set list items belong to a single parent experiment (the required
+ * experiment_id filter), but don't carry it in the row. Inject experimentId from
+ * the request input so each item's deep link resolves to the parent experiment's
+ * inputsets page instead of the per-item resolver clobbering it with the row's name.
+ */
+export const chaosInputSetListExtract = (
+  raw: unknown,
+  input?: Record<string, unknown>,
+): { items: unknown[]; total: number } => {
+  const page = chaosPageExtract(raw);
+  const experimentId = input?.experiment_id;
+  if (typeof experimentId !== "string" || experimentId === "") return page;
+  const items = page.items.map((item) => {
+    if (item && typeof item === "object" && !Array.isArray(item)) {
+      const rec = item as Record<string, unknown>;
+      if (rec.experimentId === undefined) return { ...rec, experimentId };
+    }
+    return item;
+  });
+  return { items, total: page.total };
+};
+
+/**
+ * Normalize chaos experiment variables response (RunTimeInputs shape):
+ * { experiment: [...] | null, tasks: { taskName: [...] } | null }
+ * → { items: [{ task, variables }], total }
+ * Groups variables by task name. Handles null/undefined gracefully.
+ */
+export const chaosRunTimeInputsExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { experiment?: unknown[] | null; tasks?: Record<string, unknown[]> | null } | null | undefined;
+  if (!r) return { items: [], total: 0 };
+  const items: unknown[] = [];
+  const expVars = r.experiment;
+  if (Array.isArray(expVars) && expVars.length > 0) {
+    items.push({ task: "experiment", variables: expVars });
+  }
+  const tasks = r.tasks;
+  if (tasks && typeof tasks === "object") {
+    for (const [taskName, vars] of Object.entries(tasks)) {
+      if (Array.isArray(vars) && vars.length > 0) {
+        items.push({ task: taskName, variables: vars });
+      }
+    }
+  }
+  return { items, total: items.length };
+};
+
+/**
+ * Extract chaos application-map (a.k.a. network map) list response:
+ * { data: [...], page: { index, limit, totalPages, totalItems } }
+ *
+ * Note: the JSON key is "page" (not "pagination" like other chaos
+ * resources), per ListTargetNetworkMapResponse in hce-saas
+ * pkg/networkmap/types.go. We don't widen chaosPageExtract because
+ * "page" also means a query param, and silently accepting both shapes
+ * could mask bugs in unrelated endpoints.
+ */
+export const chaosAppMapPageExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { data?: unknown[]; page?: { totalItems?: number } };
+  return {
+    items: r.data ?? [],
+    total: r.page?.totalItems ?? (Array.isArray(r.data) ? r.data.length : 0),
+  };
+};
+
+/**
+ * Extract chaos probe list response: { totalNoOfProbes, data: [...] }
+ */
+export const chaosProbeListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { data?: unknown[]; totalNoOfProbes?: number };
+  return {
+    items: r.data ?? [],
+    total: r.totalNoOfProbes ?? (Array.isArray(r.data) ? r.data.length : 0),
+  };
+};
+
+/**
+ * Extract chaos infrastructure list response: { totalNoOfInfras, infras: [...] }
+ */
+export const chaosInfraListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { infras?: unknown[]; totalNoOfInfras?: number };
+  return {
+    items: r.infras ?? [],
+    total: r.totalNoOfInfras ?? (Array.isArray(r.infras) ? r.infras.length : 0),
+  };
+};
+
+/**
+ * Extract chaos K8s infrastructure list response:
+ * { infras: [...], totalNoOfInfrastructures, pagination }
+ */
+export const chaosK8sInfraListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { infras?: unknown[]; totalNoOfInfrastructures?: number };
+  return {
+    items: r.infras ?? [],
+    total: r.totalNoOfInfrastructures ?? (Array.isArray(r.infras) ? r.infras.length : 0),
+  };
+};
+
+type LoadtestInputLike = { name?: unknown; value?: unknown; type?: unknown; required?: unknown };
+
+// Read a well-known input value from the inputs[] slice by name.
+const readLoadtestInput = (inputs: LoadtestInputLike[] | undefined, name: string): unknown =>
+  (inputs ?? []).find((i) => i?.name === name)?.value;
+
+/**
+ * Project a single load test (InternalApiLoadTestResponse) to a stable shape.
+ *
+ * The new wire format carries every recognised tunable inside `inputs[]`
+ * (TemplateInput) and user-defined non-secrets inside `variables[]`
+ * (TemplateVariable). We pass both arrays through as-is for fidelity AND
+ * surface derived convenience scalars (target_url / users / duration_sec / ...)
+ * that mirror the create-side LLM surface so round-trips are intuitive.
+ *
+ * Drops the large base64 scriptContent and backend user-detail envelopes.
+ * Mirrors `identity` into `loadtestId` so the deep-link resolver fills
+ * {loadtestId} instead of falling back to the name.
+ */
+export const chaosLoadTestExtract = (raw: unknown): unknown => {
+  if (!raw || typeof raw !== "object" || Array.isArray(raw)) return raw;
+  const t = raw as Record<string, unknown>;
+  const inputs = Array.isArray(t.inputs) ? (t.inputs as LoadtestInputLike[]) : undefined;
+  const variables = Array.isArray(t.variables) ? (t.variables as unknown[]) : undefined;
+  return {
+    loadtestId: t.identity,
+    identity: t.identity,
+    name: t.name,
+    description: t.description,
+    tags: t.tags,
+    environmentIdentifier: t.environmentIdentifier,
+    infraIdentifier: t.infraIdentifier,
+    targetType: t.targetType,
+    toolType: t.toolType,
+    scriptSource: t.scriptSource,
+    // Canonical slices — passed through as-is for fidelity.
+    inputs,
+    variables,
+    yaml: t.yaml,
+    // Derived convenience scalars (read-side mirror of the create-side LLM surface).
+    target_url: readLoadtestInput(inputs, "targetUrl"),
+    users: readLoadtestInput(inputs, "targetUsers"),
+    duration_sec: readLoadtestInput(inputs, "durationSeconds"),
+    ramp_up_sec: readLoadtestInput(inputs, "rampUpTimeSec"),
+    worker_count: readLoadtestInput(inputs, "workerCount"),
+    script_image: readLoadtestInput(inputs, "scriptImage"),
+    script_entrypoint: readLoadtestInput(inputs, "scriptEntrypoint"),
+    load_args: readLoadtestInput(inputs, "loadArgs"),
+    lastExecuted: t.lastExecuted,
+    createdAt: t.createdAt,
+    updatedAt: t.updatedAt,
+  };
+};
+
+/**
+ * Load test list response: { items, pagination: { totalItems } }.
+ * Projects each item via chaosLoadTestExtract → { items, total }.
+ */
+export const chaosLoadTestListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { items?: unknown[]; pagination?: { totalItems?: number } };
+  const items = (r.items ?? []).map(chaosLoadTestExtract);
+  return { items, total: r.pagination?.totalItems ?? items.length };
+};
+
+/**
+ * Extract chaos hub list response: { items: [...], pagination: { totalItems } }
+ */
+export const chaosHubListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { items?: unknown[]; pagination?: { totalItems?: number } };
+  return {
+    items: r.items ?? [],
+    total: r.pagination?.totalItems ?? (Array.isArray(r.items) ? r.items.length : 0),
+  };
+};
+
+/** Extract chaos DR test list response: { items: [...], pagination: { totalItems } } */
+export const chaosDRTestListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  const r = raw as { items?: unknown[]; pagination?: { totalItems?: number } };
+  const items = r.items ?? [];
+  return { items, total: r.pagination?.totalItems ?? items.length };
+};
+
+// ---------------------------------------------------------------------------
 // Service Discovery extractors
 // ---------------------------------------------------------------------------
 
 /**
- * Extract Service Discovery paginated list response — common envelope shape:
- *   { correlationID, page: { totalItems, ... }, items: [...] }
- * Used by namespaces, discoveredservices, workloads, connections, agents.
- *
- * Two server-side quirks are smoothed over here:
- *   1. When the request uses `all=true` or `limit=0`, the SD server returns
- *      `page: { all: true }` with no `totalItems` field — Go zero-values it
- *      to 0. We compensate with `Math.max(reportedTotal, items.length)` so
- *      `total` always reflects what was actually returned.
- *   2. When `items` is empty, we attach a `_hint` covering the four most
- *      common failure modes (wrong agent_identity, no sync yet, case-
- *      sensitive exact `name` match, K8s-only `namespace` filter) so the
- *      LLM can guide the user without an extra round trip.
+ * Extract Service Discovery paginated list response — common envelope shape:
+ *   { correlationID, page: { totalItems, ... }, items: [...] }
+ * Used by namespaces, discoveredservices, workloads, connections, agents.
+ *
+ * Two server-side quirks are smoothed over here:
+ *   1. When the request uses `all=true` or `limit=0`, the SD server returns
+ *      `page: { all: true }` with no `totalItems` field — Go zero-values it
+ *      to 0. We compensate with `Math.max(reportedTotal, items.length)` so
+ *      `total` always reflects what was actually returned.
+ *   2. When `items` is empty, we attach a `_hint` covering the four most
+ *      common failure modes (wrong agent_identity, no sync yet, case-
+ *      sensitive exact `name` match, K8s-only `namespace` filter) so the
+ *      LLM can guide the user without an extra round trip.
+ */
+export const sdPageExtract = (raw: unknown): { items: unknown[]; total: number; _hint?: string } => {
+  const r = raw as { items?: unknown[]; page?: { totalItems?: number } };
+  const items = r.items ?? [];
+  const reported = r.page?.totalItems ?? 0;
+  const total = Math.max(reported, items.length);
+  if (items.length === 0) {
+    return {
+      items,
+      total,
+      _hint:
+        "Empty result. Common causes: " +
+        "(1) agent_identity is not a Service Discovery agent — a chaos infrastructure ID (chaos_k8s_infrastructure) is NOT an SD agent ID, they are separate; verify against the SD UI URL. " +
+        "(2) The agent has not completed a discovery sync yet (new agents take a few minutes to populate). " +
+        "(3) For discovered_namespace, the `name` filter is EXACT case-sensitive equality — for partial/case-insensitive matches, list with `all: true` and filter client-side. " +
+        "(4) For discovered_service, the `namespace` filter only matches Kubernetes-typed records (Lambda/EC2/VM/process records won't match).",
+    };
+  }
+  return { items, total };
+};
+
+// ---------------------------------------------------------------------------
+// Feature Management Enterprise (FME) extractors
+// ---------------------------------------------------------------------------
+
+/**
+ * Flattens `trafficType.id` → `trafficTypeId` at the top level of an FME item.
+ * Enables deep link templates to reference `trafficTypeId` directly.
+ */
+export function flattenTrafficType(item: Record<string, unknown>): void {
+  const tt = item.trafficType;
+  if (tt && typeof tt === "object" && !Array.isArray(tt)) {
+    const ttRecord = tt as Record<string, unknown>;
+    if (ttRecord.id !== undefined && item.trafficTypeId === undefined) {
+      item.trafficTypeId = ttRecord.id;
+    }
+  }
+}
+
+/** Extract FME feature flag list — passthrough with trafficType.id flattened on each item. */
+export const fmeListExtract = (raw: unknown): unknown => {
+  if (raw && typeof raw === "object") {
+    const r = raw as Record<string, unknown>;
+    const objects = r.objects;
+    if (Array.isArray(objects)) {
+      for (const item of objects) {
+        if (item && typeof item === "object") {
+          flattenTrafficType(item as Record<string, unknown>);
+        }
+      }
+    }
+  }
+  return raw;
+};
+
+/** Extract FME feature flag single item — passthrough with trafficType.id flattened. */
+export const fmeGetExtract = (raw: unknown): unknown => {
+  if (raw && typeof raw === "object") {
+    flattenTrafficType(raw as Record<string, unknown>);
+  }
+  return raw;
+};
+
+// ---------------------------------------------------------------------------
+// Harness Code extractors
+// ---------------------------------------------------------------------------
+
+/** Commit list: `{ commits, total_commits }` (or a bare array) → `{ items, total }`. */
+export const codeCommitListExtract = (raw: unknown): { items: unknown[]; total: number } => {
+  if (Array.isArray(raw)) return { items: raw, total: raw.length };
+  const r = isRecord(raw) ? raw : {};
+  const commits = Array.isArray(r.commits) ? r.commits : [];
+  return {
+    items: commits,
+    total: typeof r.total_commits === "number" ? r.total_commits : commits.length,
+  };
+};
+
+interface BlameSignature {
+  name: string | null;
+  email: string | null;
+  when: string | null;
+}
+
+function blameTime(when: string | null): number {
+  return when ? Date.parse(when) || 0 : 0;
+}
+
+function readBlameSignature(value: unknown): BlameSignature {
+  const sig = isRecord(value) ? value : {};
+  const identity = isRecord(sig.identity) ? sig.identity : {};
+  return {
+    name: typeof identity.name === "string" ? identity.name : null,
+    email: typeof identity.email === "string" ? identity.email : null,
+    when: typeof sig.when === "string" ? sig.when : null,
+  };
+}
+
+/**
+ * file_blame extractor: folds the raw blame parts (`[{ commit, lines }]`) into
+ * line-numbered hunks, per-author line counts, and the most recent change —
+ * the "who last touched this and when" answer without the file body.
+ */
+export const codeBlameExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const parts = Array.isArray(raw) ? raw : [];
+  let line = Number(input?.line_from ?? 1) || 1;
+
+  const hunks: Array<{ line_from: number; line_to: number; sha: string | null; title: string | null } & BlameSignature> = [];
+  const authors = new Map<string, { name: string | null; email: string | null; lines: number; last_change: string | null }>();
+  for (const part of parts) {
+    if (!isRecord(part)) continue;
+    const commit = isRecord(part.commit) ? part.commit : {};
+    const count = Array.isArray(part.lines) ? part.lines.length : 0;
+    if (count === 0) continue;
+    const author = readBlameSignature(commit.author);
+    hunks.push({
+      line_from: line,
+      line_to: line + count - 1,
+      sha: typeof commit.sha === "string" ? commit.sha : null,
+      title: typeof commit.title === "string" ? commit.title : null,
+      ...author,
+    });
+    line += count;
+
+    const key = author.email ?? author.name ?? "unknown";
+    const entry = authors.get(key) ?? { name: author.name, email: author.email, lines: 0, last_change: null };
+    entry.lines += count;
+    if (author.when && blameTime(author.when) > blameTime(entry.last_change)) entry.last_change = author.when;
+    authors.set(key, entry);
+  }
+
+  const latest = hunks.reduce<(typeof hunks)[number] | undefined>(
+    (best, h) => (!best || blameTime(h.when) > blameTime(best.when) ? h : best),
+    undefined,
+  );
+  const latestLines = latest ? hunks.filter((h) => h.sha === latest.sha).map((h) => [h.line_from, h.line_to]) : [];
+  return {
+    repo_id: input?.repo_id ?? null,
+    path: input?.path ?? null,
+    git_ref: input?.git_ref ?? null,
+    line_count: line - (Number(input?.line_from ?? 1) || 1),
+    last_change: latest
+      ? { sha: latest.sha, title: latest.title, name: latest.name, email: latest.email, when: latest.when, line_ranges: latestLines }
+      : null,
+    authors: [...authors.values()].sort((a, b) => b.lines - a.lines),
+    hunks,
+  };
+};
+
+/**
+ * file_content extractor: decodes a file's base64 body to UTF-8 text so the
+ * content can be read directly. Binary files (containing NUL bytes) keep
+ * base64. Directory listings and other shapes pass through unchanged.
+ */
+export const codeFileContentExtract = (raw: unknown): unknown => {
+  if (!isRecord(raw) || raw.type !== "file" || !isRecord(raw.content)) return raw;
+  const content = raw.content;
+  if (content.encoding !== "base64" || typeof content.data !== "string") return raw;
+  const bytes = Buffer.from(content.data, "base64");
+  const truncated = typeof content.size === "number" && typeof content.data_size === "number" && content.data_size < content.size;
+  if (bytes.includes(0)) {
+    return { ...raw, content: { ...content, binary: true, ...(truncated ? { truncated } : {}) } };
+  }
+  return {
+    ...raw,
+    content: {
+      ...content,
+      encoding: "utf8",
+      data: bytes.toString("utf8"),
+      ...(truncated ? { truncated } : {}),
+    },
+  };
+};
+
+/** Most entries repo_tree returns in one call. */
+const REPO_TREE_MAX_ENTRIES = 1000;
+
+/**
+ * repo_tree extractor: the repository's path list (`{ files, directories }`)
+ * narrowed to `input.path` and `input.depth` levels below it, as sorted
+ * `{ path, type }` entries with directories first.
+ */
+export const codeTreeExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const r = isRecord(raw) ? raw : {};
+  const root = typeof input?.path === "string" ? input.path.replace(/^\/+|\/+$/g, "") : "";
+  const depth = Number(input?.depth);
+  const maxDepth = Number.isFinite(depth) && depth > 0 ? depth : Infinity;
+  const prefix = root ? `${root}/` : "";
+
+  const entries: Array<{ path: string; type: "dir" | "file" }> = [];
+  const collect = (paths: unknown, type: "dir" | "file") => {
+    for (const path of Array.isArray(paths) ? paths : []) {
+      if (typeof path !== "string" || !path.startsWith(prefix)) continue;
+      const rest = path.slice(prefix.length);
+      if (!rest || rest.split("/").length > maxDepth) continue;
+      entries.push({ path, type });
+    }
+  };
+  collect(r.directories, "dir");
+  collect(r.files, "file");
+  entries.sort((a, b) => (a.type === b.type ? a.path.localeCompare(b.path) : a.type === "dir" ? -1 : 1));
+
+  return {
+    path: root || "/",
+    git_ref: input?.git_ref ?? null,
+    items: entries.slice(0, REPO_TREE_MAX_ENTRIES),
+    total: entries.length,
+    ...(entries.length > REPO_TREE_MAX_ENTRIES
+      ? { truncated: true, note: `Showing the first ${REPO_TREE_MAX_ENTRIES} of ${entries.length} entries. Narrow with path or depth.` }
+      : {}),
+  };
+};
+
+/** Default and largest diff text repo_diff returns, in characters. */
+export const REPO_DIFF_DEFAULT_CHARS = 40_000;
+export const REPO_DIFF_MAX_CHARS = 200_000;
+
+interface DiffFile {
+  path: string;
+  old_path?: string;
+  status: "added" | "deleted" | "renamed" | "modified";
+  additions: number;
+  deletions: number;
+  binary?: true;
+  patch: string;
+}
+
+/** Split a unified (git) diff into per-file patches with line counts. */
+function parseUnifiedDiff(text: string): DiffFile[] {
+  const files: DiffFile[] = [];
+  const sections = text.split(/^(?=diff --git )/m).filter((section) => section.startsWith("diff --git "));
+  for (const patch of sections) {
+    const lines = patch.split("\n");
+    const header = /^diff --git a\/(.+?) b\/(.+)$/.exec(lines[0]!);
+    let oldPath = header?.[1];
+    let path = header?.[2] ?? oldPath ?? "";
+    let status: DiffFile["status"] = "modified";
+    let binary = false;
+    let additions = 0;
+    let deletions = 0;
+    let inHunk = false;
+    for (const line of lines.slice(1)) {
+      if (line.startsWith("@@")) inHunk = true;
+      else if (inHunk && line.startsWith("+")) additions++;
+      else if (inHunk && line.startsWith("-")) deletions++;
+      else if (!inHunk) {
+        if (line.startsWith("new file mode")) status = "added";
+        else if (line.startsWith("deleted file mode")) status = "deleted";
+        else if (line.startsWith("rename from ")) { status = "renamed"; oldPath = line.slice("rename from ".length); }
+        else if (line.startsWith("rename to ")) path = line.slice("rename to ".length);
+        else if (line.startsWith("Binary files ") || line.startsWith("GIT binary patch")) binary = true;
+      }
+    }
+    files.push({
+      path,
+      ...(status === "renamed" && oldPath ? { old_path: oldPath } : {}),
+      status,
+      additions,
+      deletions,
+      ...(binary ? { binary: true as const } : {}),
+      patch: patch.endsWith("\n") ? patch : `${patch}\n`,
+    });
+  }
+  return files;
+}
+
+/** A path filter entry: a glob (`*` within a segment, `**` across) or a directory/file prefix. */
+function diffPathMatches(filter: string, path: string): boolean {
+  if (/[*?]/.test(filter)) return pathGlobMatches(filter, path);
+  const prefix = filter.replace(/^\/+|\/+$/g, "");
+  return path === prefix || path.startsWith(`${prefix}/`);
+}
+
+/**
+ * repo_diff extractor: the raw unified diff between two refs, narrowed to
+ * `input.path` filters, with per-file stats. Patches are included whole, in
+ * order, until `input.max_chars`; the file that crosses the limit is cut at
+ * a line boundary and later files are listed in `omitted_files` so they can
+ * be fetched with a path filter.
+ */
+export const codeDiffExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const text = typeof raw === "string" ? raw : "";
+  const filters = (Array.isArray(input?.path) ? input.path.map(String) : typeof input?.path === "string" ? input.path.split(",") : [])
+    .map((f) => f.trim())
+    .filter(Boolean);
+  const requested = Number(input?.max_chars);
+  const maxChars = Number.isFinite(requested) && requested > 0 ? Math.min(requested, REPO_DIFF_MAX_CHARS) : REPO_DIFF_DEFAULT_CHARS;
+  const statsOnly = input?.stats_only === true || input?.stats_only === "true";
+
+  const files = parseUnifiedDiff(text)
+    .filter((file) => filters.length === 0 || filters.some((f) => diffPathMatches(f, file.path) || (file.old_path !== undefined && diffPathMatches(f, file.old_path))));
+
+  let diff = "";
+  const omitted: string[] = [];
+  let cut: string | undefined;
+  if (!statsOnly) {
+    for (const file of files) {
+      if (cut !== undefined || omitted.length > 0) {
+        omitted.push(file.path);
+      } else if (diff.length + file.patch.length <= maxChars) {
+        diff += file.patch;
+      } else {
+        const room = maxChars - diff.length;
+        const partial = file.patch.slice(0, room);
+        const lastNewline = partial.lastIndexOf("\n");
+        if (lastNewline > 0) {
+          diff += partial.slice(0, lastNewline + 1);
+          cut = file.path;
+        } else {
+          omitted.push(file.path);
+        }
+      }
+    }
+  }
+
+  const truncated = cut !== undefined || omitted.length > 0;
+  return {
+    base: input?.base ?? null,
+    head: input?.head ?? null,
+    ...(filters.length > 0 ? { path_filter: filters } : {}),
+    stats: {
+      files: files.length,
+      additions: files.reduce((n, f) => n + f.additions, 0),
+      deletions: files.reduce((n, f) => n + f.deletions, 0),
+    },
+    files: files.map((file) => ({
+      path: file.path,
+      ...(file.old_path !== undefined ? { old_path: file.old_path } : {}),
+      status: file.status,
+      additions: file.additions,
+      deletions: file.deletions,
+      ...(file.binary ? { binary: true } : {}),
+    })),
+    ...(statsOnly ? {} : { diff }),
+    ...(truncated
+      ? {
+        truncated: true,
+        ...(cut !== undefined ? { truncated_file: cut } : {}),
+        omitted_files: omitted,
+        note: `Diff cut at ${maxChars} characters. Fetch the rest with path set to the omitted files, or raise max_chars (up to ${REPO_DIFF_MAX_CHARS}).`,
+      }
+      : {}),
+  };
+};
+
+/** Longest unified diff returned inline by entity_version_diff, in lines. */
+const VERSION_DIFF_MAX_LINES = 1000;
+
+/**
+ * entity_version list extractor: one row per recorded change of the entity,
+ * newest first. `version_id` is the audit event that holds that version's YAML.
+ */
+export const entityVersionListExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const { items, total } = pageExtract(raw);
+  const versions = items.filter(isRecord).map((event) => {
+    const resource = isRecord(event.resource) ? event.resource : {};
+    const labels = isRecord(resource.labels) ? resource.labels : {};
+    const auth = isRecord(event.authenticationInfo) ? event.authenticationInfo : {};
+    const principal = isRecord(auth.principal) ? auth.principal : {};
+    const authLabels = isRecord(auth.labels) ? auth.labels : {};
+    const timestamp = typeof event.timestamp === "number" ? event.timestamp : undefined;
+    return {
+      version_id: event.auditId ?? null,
+      action: event.action ?? null,
+      changed_at: timestamp !== undefined ? new Date(timestamp).toISOString() : null,
+      changed_by: authLabels.username ?? principal.email ?? principal.identifier ?? null,
+      principal_type: principal.type ?? null,
+      ...(typeof labels.versionLabel === "string" ? { version_label: labels.versionLabel } : {}),
+      ...(typeof labels.resourceName === "string" ? { name: labels.resourceName } : {}),
+    };
+  });
+  return {
+    entity_type: input?.entity_type ?? null,
+    entity_id: input?.entity_id ?? null,
+    items: versions,
+    total,
+  };
+};
+
+/** Raw payload gathered by entity_version_diff's collect hook. */
+export interface EntityVersionDiffScan {
+  from_version: string;
+  to_version: string;
+  /** YAML before the change (the base version's YAML, or the event's previous YAML). */
+  before: string;
+  after: string;
+}
+
+/**
+ * entity_version_diff extractor: unified line diff between two YAML snapshots.
+ * Long diffs are cut at VERSION_DIFF_MAX_LINES with `truncated: true`.
+ */
+export const entityVersionDiffExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as EntityVersionDiffScan;
+  const context = Math.min(Math.max(Math.trunc(Number(input?.context_lines ?? 3)) || 0, 0), 20);
+  const { diff, added, removed } = diffLines(scan.before, scan.after, context);
+  const lines = diff ? diff.split("\n") : [];
+  const truncated = lines.length > VERSION_DIFF_MAX_LINES;
+  return {
+    from_version: scan.from_version,
+    to_version: scan.to_version,
+    changed: added + removed > 0,
+    lines_added: added,
+    lines_removed: removed,
+    ...(scan.before === "" ? { note: "No earlier YAML recorded — the entity was created in to_version." } : {}),
+    ...(scan.after === "" ? { note: "No YAML recorded for to_version — the entity was deleted." } : {}),
+    diff: truncated ? lines.slice(0, VERSION_DIFF_MAX_LINES).join("\n") : diff,
+    ...(truncated ? { truncated: true, diff_lines_total: lines.length } : {}),
+  };
+};
+
+/** Audit actions that remove an entity. */
+const SNAPSHOT_DELETE_ACTIONS = new Set(["DELETE", "FORCE_DELETE"]);
+
+export type SnapshotChangeKind = "created" | "deleted" | "modified" | "created_and_deleted";
+
+/** Net change of one entity across a config_snapshot_diff window. */
+export interface SnapshotEntityChange {
+  key: string;
+  change: SnapshotChangeKind;
+  audit_type: string;
+  identifier: string;
+  name: string | null;
+  org_id: string | null;
+  project_id: string | null;
+  /** Audit events of this entity in the window, oldest first. */
+  events: Record<string, unknown>[];
+}
+
+/**
+ * Group audit change events by entity and classify each entity's net change
+ * over the window: created (first event is CREATE), deleted (last event is a
+ * delete), both, or modified. Shared by the collect hook (to pick which YAML
+ * snapshots to fetch) and the extractor.
+ */
+export function groupSnapshotChanges(events: unknown[]): SnapshotEntityChange[] {
+  const byEntity = new Map<string, SnapshotEntityChange>();
+  const ordered = events
+    .filter(isRecord)
+    .filter((e) => isRecord(e.resource) && typeof e.resource.identifier === "string")
+    .sort((a, b) => Number(a.timestamp ?? 0) - Number(b.timestamp ?? 0));
+  for (const event of ordered) {
+    const resource = event.resource as Record<string, unknown>;
+    const labels = isRecord(resource.labels) ? resource.labels : {};
+    const scope = isRecord(event.resourceScope) ? event.resourceScope : {};
+    const org = typeof scope.orgIdentifier === "string" ? scope.orgIdentifier : null;
+    const project = typeof scope.projectIdentifier === "string" ? scope.projectIdentifier : null;
+    const auditType = String(resource.type ?? "");
+    const identifier = resource.identifier as string;
+    const key = [auditType, org ?? "", project ?? "", identifier].join("/");
+    let entry = byEntity.get(key);
+    if (!entry) {
+      entry = { key, change: "modified", audit_type: auditType, identifier, name: null, org_id: org, project_id: project, events: [] };
+      byEntity.set(key, entry);
+    }
+    if (typeof labels.resourceName === "string") entry.name = labels.resourceName;
+    entry.events.push(event);
+  }
+  for (const entry of byEntity.values()) {
+    const created = entry.events[0]?.action === "CREATE";
+    const deleted = SNAPSHOT_DELETE_ACTIONS.has(String(entry.events[entry.events.length - 1]?.action));
+    entry.change = created && deleted ? "created_and_deleted" : created ? "created" : deleted ? "deleted" : "modified";
+  }
+  return [...byEntity.values()];
+}
+
+/** Raw payload gathered by config_snapshot_diff's collect hook. */
+export interface ConfigSnapshotScan {
+  from_time: string;
+  to_time: string;
+  /** Requested entity types (resource_type names), with their audit resource types. */
+  entity_types: Record<string, string>;
+  events: unknown[];
+  total_events: number;
+  /** YAML at from_time and to_time per entity key, when include_diff was set. */
+  yaml?: Record<string, { before: string; after: string }>;
+  errors: Array<{ entity: string; error: string }>;
+}
+
+/** Longest per-entity diff returned by config_snapshot_diff, in lines. */
+const SNAPSHOT_DIFF_MAX_LINES = 200;
+
+const SNAPSHOT_CHANGE_ORDER: Record<SnapshotChangeKind, number> = { created: 0, deleted: 1, modified: 2, created_and_deleted: 3 };
+
+/**
+ * config_snapshot_diff extractor: the entities that differ between the
+ * configuration at from_time and at to_time, with who changed them and the
+ * version_ids to drill into with entity_version_diff.
+ */
+export const configSnapshotDiffExtract = (raw: unknown): unknown => {
+  const scan = raw as ConfigSnapshotScan;
+  const typeNames = new Map(Object.entries(scan.entity_types).map(([name, auditType]) => [auditType, name]));
+  const summary: Record<SnapshotChangeKind, number> = { created: 0, deleted: 0, modified: 0, created_and_deleted: 0 };
+  const changes = groupSnapshotChanges(scan.events)
+    .sort((a, b) =>
+      SNAPSHOT_CHANGE_ORDER[a.change] - SNAPSHOT_CHANGE_ORDER[b.change]
+      || a.audit_type.localeCompare(b.audit_type)
+      || a.key.localeCompare(b.key))
+    .map((entry) => {
+      summary[entry.change]++;
+      const first = entry.events[0]!;
+      const last = entry.events[entry.events.length - 1]!;
+      const changedBy = new Set<string>();
+      for (const event of entry.events) {
+        const auth = isRecord(event.authenticationInfo) ? event.authenticationInfo : {};
+        const principal = isRecord(auth.principal) ? auth.principal : {};
+        const authLabels = isRecord(auth.labels) ? auth.labels : {};
+        const who = authLabels.username ?? principal.email ?? principal.identifier;
+        if (typeof who === "string") changedBy.add(who);
+      }
+      const at = (event: Record<string, unknown>) =>
+        typeof event.timestamp === "number" ? new Date(event.timestamp).toISOString() : null;
+      const yaml = scan.yaml?.[entry.key];
+      let diff: Record<string, unknown> | undefined;
+      if (yaml) {
+        const result = diffLines(yaml.before, yaml.after, 3);
+        const lines = result.diff ? result.diff.split("\n") : [];
+        diff = {
+          lines_added: result.added,
+          lines_removed: result.removed,
+          diff: lines.slice(0, SNAPSHOT_DIFF_MAX_LINES).join("\n"),
+          ...(lines.length > SNAPSHOT_DIFF_MAX_LINES ? { truncated: true } : {}),
+        };
+      }
+      return {
+        change: entry.change,
+        entity_type: typeNames.get(entry.audit_type) ?? entry.audit_type,
+        identifier: entry.identifier,
+        name: entry.name,
+        org_id: entry.org_id,
+        project_id: entry.project_id,
+        change_count: entry.events.length,
+        actions: entry.events.map((e) => e.action ?? null),
+        first_change_at: at(first),
+        last_change_at: at(last),
+        changed_by: [...changedBy],
+        first_version_id: first.auditId ?? null,
+        last_version_id: last.auditId ?? null,
+        ...(diff ? { diff } : {}),
+      };
+    });
+  const truncated = scan.total_events > scan.events.length;
+  return {
+    from_time: scan.from_time,
+    to_time: scan.to_time,
+    entity_types: Object.keys(scan.entity_types),
+    summary,
+    items: changes,
+    total: changes.length,
+    events_scanned: scan.events.length,
+    ...(truncated
+      ? { truncated: true, note: `Only ${scan.events.length} of ${scan.total_events} audit events were read, so some changes may be missing or misclassified. Narrow the window or entity_types for a complete diff.` }
+      : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+  };
+};
+
+/** Undo log entries as listed: what changed and whether it can be undone, without the restore body. */
+export const undoLogListExtract = (raw: unknown): unknown => {
+  const entries = Array.isArray(raw) ? raw as UndoEntry[] : [];
+  return {
+    items: entries.map((entry) => ({
+      entry_id: entry.entry_id,
+      recorded_at: entry.recorded_at,
+      resource_type: entry.resource_type,
+      operation: entry.operation,
+      ...(entry.action ? { action: entry.action } : {}),
+      ...(entry.resource_id ? { resource_id: entry.resource_id } : {}),
+      undoable: entry.undo !== undefined,
+      ...(entry.undo ? { undo_operation: entry.undo.operation } : { not_undoable: entry.not_undoable }),
+    })),
+    total: entries.length,
+  };
+};
+
+/** Raw result of undo_last_change: the entry that was reversed and the reversing call's response. */
+export interface UndoResultScan {
+  entry: UndoEntry;
+  result: unknown;
+}
+
+export const undoResultExtract = (raw: unknown): unknown => {
+  const { entry, result } = raw as UndoResultScan;
+  return {
+    undone: entry.entry_id,
+    resource_type: entry.resource_type,
+    ...(entry.resource_id ? { resource_id: entry.resource_id } : {}),
+    original_operation: entry.operation,
+    restored_with: entry.undo?.operation,
+    result,
+    _hint: "The entry is removed from the undo log. Run undo_last_change again to reverse the change before it.",
+  };
+};
+
+/** Raw payload gathered by execution_log_tail's collect hook. */
+export interface ExecutionLogTailScan {
+  execution_id?: string;
+  log_key: string;
+  /** live: read from the open stream; complete: read from the closed log; pending: no log yet. */
+  source: "live" | "complete" | "pending";
+  lines: LogLine[];
+  ended: boolean;
+  cursor: string;
+  total_lines?: number;
+}
+
+/**
+ * execution_log_tail extractor: new lines since the cursor, with the cursor
+ * for the next call. has_more is false once the step's log is closed and
+ * every line has been returned.
+ */
+export const executionLogTailExtract = (raw: unknown): unknown => {
+  const scan = raw as ExecutionLogTailScan;
+  const hasMore = !scan.ended;
+  return {
+    ...(scan.execution_id ? { execution_id: scan.execution_id } : {}),
+    log_key: scan.log_key,
+    source: scan.source,
+    lines: scan.lines.map((l) => [l.time, l.level ? `${l.level}:` : undefined, l.out].filter(Boolean).join(" ")),
+    line_count: scan.lines.length,
+    ...(scan.total_lines !== undefined ? { total_lines: scan.total_lines } : {}),
+    has_more: hasMore,
+    cursor: scan.cursor,
+    ...(scan.source === "pending"
+      ? { note: "No log exists for this key yet — the step has not started. Call again with the cursor." }
+      : hasMore && scan.source === "live" && scan.lines.length === 0
+        ? { note: "No new lines within wait_ms. The step is still running; call again with the cursor." }
+        : {}),
+  };
+};
+
+/** Raw payload gathered by trigger_event's collect hook. */
+export interface TriggerEventScan {
+  execution_id: string;
+  pipeline_id?: string;
+  trigger_type?: string;
+  trigger_id?: string;
+  event_correlation_id?: string;
+  /** Matching trigger event history entry (holds the webhook payload). */
+  event?: Record<string, unknown>;
+  trigger_yaml?: string;
+  errors: string[];
+}
+
+/** Longest webhook payload returned inline by trigger_event, in characters. */
+const TRIGGER_PAYLOAD_MAX_CHARS = 20_000;
+
+/** Headers from an event history entry: a `{name: value}` map or `[{key, values[]}]` list. */
+function readEventHeaders(raw: unknown): Record<string, string> {
+  const headers: Record<string, string> = {};
+  if (Array.isArray(raw)) {
+    for (const entry of raw.filter(isRecord)) {
+      const values = Array.isArray(entry.values) ? entry.values : [entry.value];
+      if (typeof entry.key === "string") headers[entry.key] = values.map(String).join(",");
+    }
+  } else if (isRecord(raw)) {
+    for (const [key, value] of Object.entries(raw)) headers[key] = Array.isArray(value) ? value.map(String).join(",") : String(value);
+  }
+  return headers;
+}
+
+/**
+ * trigger_event get extractor: the webhook payload behind an execution, the
+ * branch/commit facts Harness derives from it, and each trigger condition
+ * re-evaluated against it — enough to explain why the trigger fired.
+ */
+export const triggerEventExtract = (raw: unknown): unknown => {
+  const scan = raw as TriggerEventScan;
+  const base = {
+    execution_id: scan.execution_id,
+    pipeline_id: scan.pipeline_id ?? null,
+    trigger_type: scan.trigger_type ?? null,
+    trigger_id: scan.trigger_id ?? null,
+    event_correlation_id: scan.event_correlation_id ?? null,
+  };
+  if (scan.trigger_type !== "WEBHOOK" && scan.trigger_type !== "WEBHOOK_CUSTOM") {
+    return {
+      ...base,
+      note: `This execution was not started by a webhook trigger (trigger type ${scan.trigger_type ?? "unknown"}), so there is no payload to inspect.`,
+    };
+  }
+
+  const event = scan.event ?? {};
+  let payload: unknown = event.payload;
+  if (typeof payload === "string") {
+    try {
+      payload = JSON.parse(payload);
+    } catch {
+      // Keep non-JSON payloads (e.g. form-encoded custom webhooks) as text.
+    }
+  }
+  const headers = readEventHeaders(event.headers);
+  const spec = scan.trigger_yaml ? readTriggerConditions(scan.trigger_yaml) : undefined;
+  const conditions = spec && payload !== undefined ? evaluateTriggerConditions(spec, payload, headers) : [];
+  const status = isRecord(event.triggerEventStatus) ? event.triggerEventStatus : {};
+  const payloadText = payload === undefined ? undefined : typeof payload === "string" ? payload : JSON.stringify(payload);
+  const truncated = payloadText !== undefined && payloadText.length > TRIGGER_PAYLOAD_MAX_CHARS;
+
+  return {
+    ...base,
+    ...(scan.event ? {
+      event: {
+        created_at: typeof event.eventCreatedAt === "number" ? new Date(event.eventCreatedAt).toISOString() : null,
+        status: status.status ?? event.finalStatus ?? null,
+        message: status.message ?? event.message ?? null,
+      },
+    } : {}),
+    webhook: payload === undefined ? null : extractWebhookFacts(payload, headers),
+    ...(spec ? {
+      trigger_filter: {
+        provider: spec.provider ?? null,
+        event_type: spec.event_type ?? null,
+        actions: spec.actions,
+        ...(spec.jexl_condition ? { jexl_condition: spec.jexl_condition } : {}),
+      },
+      conditions,
+      all_conditions_matched: conditions.every((c) => c.matched === true),
+    } : {}),
+    ...(spec?.jexl_condition ? { jexl_note: "JEXL conditions are shown but not evaluated here." } : {}),
+    payload: truncated ? payloadText!.slice(0, TRIGGER_PAYLOAD_MAX_CHARS) : payload ?? null,
+    ...(truncated ? { payload_truncated: true, payload_chars_total: payloadText!.length } : {}),
+    ...(Object.keys(headers).length > 0 ? { headers } : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+  };
+};
+
+/** Raw payload gathered by pending_approval's collect hook. */
+export interface PendingApprovalScan {
+  executions_scanned: number;
+  truncated: boolean;
+  approvals: Array<{ execution: Record<string, unknown>; approval: Record<string, unknown> }>;
+  errors: Array<{ execution_id: string; error: string }>;
+}
+
+function pick(record: Record<string, unknown>, ...keys: string[]): unknown {
+  for (const key of keys) {
+    if (record[key] !== undefined && record[key] !== null) return record[key];
+  }
+  return undefined;
+}
+
+function toIso(value: unknown): string | null {
+  return typeof value === "number" ? new Date(value).toISOString() : typeof value === "string" ? value : null;
+}
+
+/**
+ * pending_approval list extractor: one row per waiting approval across the
+ * project, oldest deadline first, with what is needed to act on it. Only
+ * HarnessApproval instances can be approved or rejected through the API;
+ * Jira/ServiceNow/Custom approvals resolve in the external system.
+ */
+export const pendingApprovalListExtract = (raw: unknown): unknown => {
+  const scan = raw as PendingApprovalScan;
+  const items = scan.approvals.map(({ execution, approval }) => {
+    const details = isRecord(approval.details) ? approval.details : {};
+    const approvers = isRecord(details.approvers) ? details.approvers : {};
+    const inputs = pick(details, "approver_inputs", "approverInputs");
+    const type = String(pick(approval, "type") ?? "");
+    const approvalId = pick(approval, "id", "approval_id", "uuid") ?? null;
+    return {
+      approval_id: approvalId,
+      type,
+      execution_id: execution.planExecutionId ?? null,
+      pipeline_id: execution.pipelineIdentifier ?? null,
+      pipeline_name: execution.name ?? null,
+      run_sequence: execution.runSequence ?? null,
+      node_execution_id: pick(approval, "node_execution_id", "nodeExecutionId") ?? null,
+      step_name: pick(approval, "step_name", "stepName", "name") ?? null,
+      message: pick(details, "approval_message", "approvalMessage") ?? null,
+      created_at: toIso(pick(approval, "created_at", "createdAt")),
+      deadline: toIso(pick(approval, "deadline")),
+      user_groups: pick(approvers, "user_groups", "userGroups") ?? [],
+      minimum_count: pick(approvers, "minimum_count", "minimumCount") ?? null,
+      approvals_so_far: Array.isArray(pick(details, "approval_activities", "approvalActivities"))
+        ? (pick(details, "approval_activities", "approvalActivities") as unknown[]).length
+        : 0,
+      ...(Array.isArray(inputs) && inputs.length > 0
+        ? {
+          approver_inputs: inputs.filter(isRecord).map((i) => ({
+            name: i.name ?? null,
+            default_value: pick(i, "default_value", "defaultValue") ?? null,
+          })),
+        }
+        : {}),
+      actionable: type === "HarnessApproval",
+      next_action: type === "HarnessApproval"
+        ? `harness_execute(resource_type='approval_instance', action='approve' | 'reject', params={approval_id: '${String(approvalId)}', comments: '...'})`
+        : `Resolve the ${type || "external"} ticket; Harness polls it and continues the pipeline.`,
+    };
+  });
+  items.sort((a, b) => (a.deadline ?? "9999").localeCompare(b.deadline ?? "9999"));
+  return {
+    items,
+    total: items.length,
+    executions_scanned: scan.executions_scanned,
+    ...(scan.truncated ? { truncated: true } : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+  };
+};
+
+/** Raw payload gathered by my_action_item's collect hook. */
+export interface MyActionItemsScan {
+  user_id: string;
+  /** pending_approval output, plus the current user's group refs (null when they could not be read). */
+  approvals: PostureSection<{ list: unknown; user_groups: string[] | null }>;
+  pr_reviews: PostureSection<Array<{ repo_id: string; pull_requests?: unknown; error?: string }>>;
+  /** waiting_execution output for InputWaiting executions. */
+  waiting_inputs: PostureSection<unknown>;
+}
+
+/** An approver group as the bare identifier, without its account./org. scope prefix. */
+function bareGroupRef(ref: string): string {
+  return ref.replace(/^(account|org)\./, "");
+}
+
+function sectionItems(section: PostureSection<unknown>, items: unknown[], extra: Record<string, unknown> = {}): Record<string, unknown> {
+  if (section.status !== "ok") return unavailable(section);
+  return { status: "ok", count: items.length, items, ...extra };
+}
+
+/**
+ * my_action_item list extractor: the current user's to-do list in one call —
+ * approvals assigned to one of their user groups, pull requests waiting on
+ * their review, and executions waiting on runtime input. Each section keeps
+ * its own status so one failing source does not hide the others.
+ */
+export const myActionItemsExtract = (raw: unknown): unknown => {
+  const scan = raw as MyActionItemsScan;
+
+  let approvals: Array<Record<string, unknown>> = [];
+  const approvalExtra: Record<string, unknown> = {};
+  if (scan.approvals.status === "ok") {
+    const { list, user_groups: groups } = scan.approvals.data;
+    const all = (isRecord(list) && Array.isArray(list.items) ? list.items : []).filter(isRecord);
+    if (groups) {
+      const mine = new Set(groups);
+      const bare = new Set(groups.map(bareGroupRef));
+      approvals = all.filter((a) => Array.isArray(a.user_groups) && a.user_groups.some((g) =>
+        typeof g === "string" && (mine.has(g) || (!g.includes(".") && bare.has(g)))));
+      if (all.length > approvals.length) approvalExtra.other_approvals = all.length - approvals.length;
+    } else {
+      approvals = all;
+      approvalExtra.note = "Could not read your user groups, so this lists every pending approval in the project.";
+    }
+    if (isRecord(list) && Array.isArray(list.errors)) approvalExtra.errors = list.errors;
+    approvals = approvals.map((a) => ({
+      approval_id: a.approval_id ?? null,
+      type: a.type ?? null,
+      pipeline_id: a.pipeline_id ?? null,
+      pipeline_name: a.pipeline_name ?? null,
+      execution_id: a.execution_id ?? null,
+      step_name: a.step_name ?? null,
+      message: a.message ?? null,
+      created_at: a.created_at ?? null,
+      deadline: a.deadline ?? null,
+      ...(a.approver_inputs ? { approver_inputs: a.approver_inputs } : {}),
+      next_action: a.next_action ?? null,
+    }));
+  }
+
+  const reviews: Array<Record<string, unknown>> = [];
+  const reviewErrors: Array<{ repo_id: string; error: string }> = [];
+  if (scan.pr_reviews.status === "ok") {
+    for (const repo of scan.pr_reviews.data) {
+      if (repo.error) {
+        reviewErrors.push({ repo_id: repo.repo_id, error: repo.error });
+        continue;
+      }
+      for (const pr of (Array.isArray(repo.pull_requests) ? repo.pull_requests : []).filter(isRecord)) {
+        const author = isRecord(pr.author) ? pr.author : {};
+        reviews.push({
+          repo_id: repo.repo_id,
+          pr_number: pr.number ?? null,
+          title: pr.title ?? null,
+          author: pick(author, "display_name", "email", "uid") ?? null,
+          source_branch: pr.source_branch ?? null,
+          target_branch: pr.target_branch ?? null,
+          is_draft: pr.is_draft === true,
+          created_at: toIso(pr.created),
+          next_action: `harness_get(resource_type='pull_request', params={repo_id: '${repo.repo_id}', pr_number: ${String(pr.number)}}), then review it`,
+        });
+      }
+    }
+    reviews.sort((a, b) => String(a.created_at ?? "9999").localeCompare(String(b.created_at ?? "9999")));
+  }
+
+  const waiting = scan.waiting_inputs.status === "ok" && isRecord(scan.waiting_inputs.data) && Array.isArray(scan.waiting_inputs.data.items)
+    ? scan.waiting_inputs.data.items.filter(isRecord)
+    : [];
+  waiting.sort((a, b) => String(a.started_at ?? "9999").localeCompare(String(b.started_at ?? "9999")));
+
+  const counts = {
+    approvals: approvals.length,
+    pr_reviews: reviews.length,
+    waiting_inputs: waiting.length,
+  };
+  return {
+    user_id: scan.user_id,
+    summary: { total: counts.approvals + counts.pr_reviews + counts.waiting_inputs, ...counts },
+    approvals: sectionItems(scan.approvals, approvals, approvalExtra),
+    pr_reviews: sectionItems(scan.pr_reviews, reviews, reviewErrors.length > 0 ? { errors: reviewErrors } : {}),
+    waiting_inputs: sectionItems(scan.waiting_inputs, waiting),
+  };
+};
+
+/** Raw payload gathered by pipeline.preflight's collect hook: the PreFlightDTO of one check. */
+export interface PipelinePreflightScan {
+  preflight_check_id: string;
+  pipeline_id?: string;
+  result: Record<string, unknown>;
+}
+
+interface PreflightFailure {
+  check: "connector" | "input";
+  stage: unknown;
+  step: unknown;
+  fqn: unknown;
+  connector_id?: unknown;
+  summary: string | null;
+  causes: string[];
+  resolution: string[];
+}
+
+/** Messages from a PreFlightEntityErrorInfo list ([{cause}] or [{resolution}]). */
+function preflightMessages(value: unknown, key: string): string[] {
+  return Array.isArray(value)
+    ? value.map((v) => (isRecord(v) ? v[key] : v)).filter((v): v is string => typeof v === "string" && v !== "")
+    : [];
+}
+
+function preflightError(errorInfo: unknown): Pick<PreflightFailure, "summary" | "causes" | "resolution"> {
+  const info = isRecord(errorInfo) ? errorInfo : {};
+  return {
+    summary: (pick(info, "summary", "description") as string | undefined) ?? null,
+    causes: preflightMessages(info.causes, "cause"),
+    resolution: preflightMessages(info.resolution, "resolution"),
+  };
+}
+
+/**
+ * pipeline.preflight extractor: overall status, per-check counts, and one
+ * failure row per connector or runtime input that did not pass, with the
+ * stage and step that use it.
+ */
+export const pipelinePreflightExtract = (raw: unknown): unknown => {
+  const scan = raw as PipelinePreflightScan;
+  const result = scan.result;
+  const connectorWrapper = isRecord(result.connectorWrapperResponse) ? result.connectorWrapperResponse : {};
+  const inputWrapper = isRecord(result.pipelineInputWrapperResponse) ? result.pipelineInputWrapperResponse : {};
+  const connectors = (Array.isArray(connectorWrapper.checkResponses) ? connectorWrapper.checkResponses : []).filter(isRecord);
+  const inputs = (Array.isArray(inputWrapper.pipelineInputResponse) ? inputWrapper.pipelineInputResponse : []).filter(isRecord);
+
+  const failures: PreflightFailure[] = [];
+  for (const c of connectors) {
+    if (c.status !== "FAILURE") continue;
+    failures.push({
+      check: "connector",
+      stage: c.stageName ?? c.stageIdentifier ?? null,
+      step: c.stepName ?? c.stepIdentifier ?? null,
+      fqn: c.fqn ?? null,
+      connector_id: c.connectorIdentifier ?? null,
+      ...preflightError(c.errorInfo),
+    });
+  }
+  const failedInputs = inputs.filter((i) => i.success === false || i.isSuccess === false);
+  for (const i of failedInputs) {
+    failures.push({
+      check: "input",
+      stage: i.stageName ?? null,
+      step: i.stepName ?? null,
+      fqn: i.fqn ?? null,
+      ...preflightError(i.errorInfo),
+    });
+  }
+
+  const status = String(result.status ?? "UNKNOWN");
+  const pending = status === "IN_PROGRESS" || status === "NOT_STARTED";
+  return {
+    preflight_check_id: scan.preflight_check_id,
+    ...(scan.pipeline_id ? { pipeline_id: scan.pipeline_id } : {}),
+    status,
+    passed: status === "SUCCESS",
+    checks: {
+      connectors: { status: connectorWrapper.status ?? null, total: connectors.length, failed: failures.filter((f) => f.check === "connector").length },
+      inputs: { status: inputWrapper.status ?? null, total: inputs.length, failed: failedInputs.length },
+    },
+    failures,
+    ...(result.errorInfo ? { error: preflightError(result.errorInfo) } : {}),
+    ...(pending
+      ? { _hint: `Preflight is still running. Call harness_execute(resource_type='pipeline', action='preflight', params={preflight_check_id: '${scan.preflight_check_id}'}) again for the result.` }
+      : {}),
+  };
+};
+
+/** Raw payload gathered by artifact_version's get collect hook. */
+export interface HarVersionMetadataScan {
+  registry_id: string;
+  summary: Record<string, unknown>;
+  sources: HarVersionSources;
+  errors: string[];
+}
+
+/**
+ * artifact_version get extractor: common version facts plus typed,
+ * package-type-specific metadata (see utils/har-metadata.ts).
+ */
+export const harVersionMetadataExtract = (raw: unknown): unknown => {
+  const scan = raw as HarVersionMetadataScan;
+  const details = scan.sources.details ?? {};
+  const field = (...keys: string[]): unknown => {
+    for (const key of keys) {
+      const value = details[key] ?? scan.summary[key];
+      if (value !== undefined && value !== null && value !== "") return value;
+    }
+    return null;
+  };
+  const quarantined = scan.summary.isQuarantined === true;
+  return {
+    registry_id: scan.registry_id,
+    artifact_id: scan.sources.artifact,
+    version: scan.sources.version,
+    package_type: scan.sources.package_type,
+    size: field("size"),
+    created_at: field("createdAt", "created_at"),
+    modified_at: field("modifiedAt", "modified_at"),
+    download_count: field("downloadCount", "downloadsCount"),
+    quarantined,
+    ...(quarantined && scan.summary.quarantineReason ? { quarantine_reason: scan.summary.quarantineReason } : {}),
+    metadata: buildPackageMetadata(scan.sources),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+  };
+};
+
+/** Raw payload gathered by delegate_upgrade_status's collect hook. */
+export interface DelegateUpgradeScan {
+  /** SupportedDelegateVersion from delegate-setup, or null when it could not be read. */
+  latest: Record<string, unknown> | null;
+  latest_error?: string;
+  delegates: unknown[];
+  /** Delegate tokens in the requested scope, or null when they could not be read. */
+  tokens: unknown[] | null;
+  tokens_error?: string;
+}
+
+const DELEGATE_EXPIRY_WARNING_MS = 7 * 24 * 60 * 60 * 1000;
+
+/**
+ * Compare dotted delegate versions ("24.07.83404") numerically, segment by
+ * segment. Returns a negative number when a is older than b.
+ */
+export function compareDelegateVersions(a: string, b: string): number {
+  const pa = a.split(/[.-]/).map((s) => Number.parseInt(s, 10) || 0);
+  const pb = b.split(/[.-]/).map((s) => Number.parseInt(s, 10) || 0);
+  for (let i = 0; i < Math.max(pa.length, pb.length); i++) {
+    const diff = (pa[i] ?? 0) - (pb[i] ?? 0);
+    if (diff !== 0) return diff;
+  }
+  return 0;
+}
+
+/**
+ * delegate_upgrade_status extractor: each delegate's replica versions against
+ * the latest supported version, auto-upgrade state, image expiry, and token
+ * state, plus the tokens in scope that are expired, expiring, or revoked.
+ * params.issue narrows the delegate list to one kind of problem.
+ */
+export const delegateUpgradeStatusExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as DelegateUpgradeScan;
+  const now = Date.now();
+  const latest = scan.latest ? (pick(scan.latest, "latestSupportedVersion") as string | undefined) ?? null : null;
+  const minimal = scan.latest ? (pick(scan.latest, "latestSupportedMinimalVersion") as string | undefined) ?? null : null;
+
+  const delegates = scan.delegates.filter(isRecord).map((d) => {
+    const replicas = (Array.isArray(d.delegateReplicas) ? d.delegateReplicas : []).filter(isRecord);
+    const versions = [...new Set(replicas.map((r) => r.version).filter((v): v is string => typeof v === "string" && v !== ""))];
+    const oldest = [...versions].sort(compareDelegateVersions)[0];
+    const outdated = latest && oldest ? compareDelegateVersions(oldest, latest) < 0 : null;
+    const expiries = replicas.map((r) => r.expiringAt).filter((t): t is number => typeof t === "number" && t > 0);
+    const expiringAt = expiries.length > 0 ? Math.min(...expiries) : undefined;
+    const autoUpgrade = typeof d.autoUpgrade === "string" ? d.autoUpgrade : null;
+
+    const issues: string[] = [];
+    // Outdated delegates either catch up on their own or need a manual upgrade.
+    if (outdated) issues.push(autoUpgrade === "ON" ? "pending_auto_upgrade" : "auto_upgrade_off");
+    if (expiringAt !== undefined && expiringAt < now + DELEGATE_EXPIRY_WARNING_MS) issues.push(expiringAt < now ? "expired" : "expiring");
+    if (d.tokenActive === false) issues.push("token_inactive");
+    if (d.legacy === true) issues.push("legacy");
+
+    return {
+      name: d.name ?? null,
+      type: d.type ?? null,
+      connected: d.connected ?? null,
+      auto_upgrade: autoUpgrade,
+      versions,
+      outdated,
+      expiring_at: expiringAt !== undefined ? new Date(expiringAt).toISOString() : null,
+      ...(typeof d.tokenActive === "boolean" ? { token_active: d.tokenActive } : {}),
+      ...(d.orgName ? { org: d.orgName } : {}),
+      ...(d.projectName ? { project: d.projectName } : {}),
+      issues,
+    };
+  });
+
+  const count = (issue: string) => delegates.filter((d) => d.issues.includes(issue)).length;
+  const issue = typeof input?.issue === "string" && input.issue ? input.issue : undefined;
+  const matches = (d: typeof delegates[number]): boolean => {
+    if (issue === "outdated") return d.outdated === true;
+    if (issue === "expiring") return d.issues.includes("expiring") || d.issues.includes("expired");
+    return d.issues.includes(issue!);
+  };
+
+  const tokenSummary = (() => {
+    if (scan.tokens === null) return { status: "error", reason: scan.tokens_error ?? "Delegate tokens could not be read" };
+    const tokens = scan.tokens.filter(isRecord).map((t) => ({
+      name: t.name ?? null,
+      status: t.status ?? null,
+      revoke_after: typeof t.revokeAfter === "number" && t.revokeAfter > 0 ? t.revokeAfter : null,
+      created_at: toIso(t.createdAt),
+    }));
+    const expired = tokens.filter((t) => t.status === "ACTIVE" && t.revoke_after !== null && t.revoke_after < now);
+    const expiring = tokens.filter((t) => t.status === "ACTIVE" && t.revoke_after !== null && t.revoke_after >= now && t.revoke_after < now + DELEGATE_EXPIRY_WARNING_MS);
+    const asRow = (t: typeof tokens[number]) => ({ ...t, revoke_after: t.revoke_after !== null ? new Date(t.revoke_after).toISOString() : null });
+    return {
+      status: "ok",
+      total: tokens.length,
+      revoked: tokens.filter((t) => t.status === "REVOKED").length,
+      expired: expired.map(asRow),
+      expiring: expiring.map(asRow),
+    };
+  })();
+
+  return {
+    latest_supported_version: latest,
+    ...(minimal ? { latest_supported_minimal_version: minimal } : {}),
+    ...(scan.latest === null ? { note: `Latest supported version unavailable (${scan.latest_error ?? "unknown error"}); outdated is null.` } : {}),
+    summary: {
+      total: delegates.length,
+      up_to_date: delegates.filter((d) => d.outdated === false).length,
+      outdated: delegates.filter((d) => d.outdated === true).length,
+      pending_auto_upgrade: count("pending_auto_upgrade"),
+      auto_upgrade_off: count("auto_upgrade_off"),
+      expiring_or_expired: count("expiring") + count("expired"),
+      token_inactive: count("token_inactive"),
+    },
+    delegates: issue ? delegates.filter(matches) : delegates,
+    tokens: tokenSummary,
+  };
+};
+
+/** Raw payloads gathered by execution_yaml's get collect hook. */
+export interface ExecutionYamlScan {
+  execution_id: string;
+  /** ExecutionDataResponseDTO from /execution/{id}/metadata. */
+  metadata: Record<string, unknown>;
+  /** inputsetV2 payload with expressions resolved, when requested and readable. */
+  inputs?: Record<string, unknown>;
+  errors: string[];
+}
+
+/** `<+...>` expressions, allowing one level of nesting such as `<+pipeline.stages.<+stage.identifier>.name>`. */
+const HARNESS_EXPRESSION = /<\+(?:[^<>]|<\+[^<>]*>)*>/g;
+const MAX_EXPRESSIONS_LISTED = 50;
+
+/**
+ * execution_yaml extractor: the compiled YAML that ran (templates expanded,
+ * runtime inputs merged), the merged inputs with expressions resolved, and
+ * an inventory of the expressions Harness only resolves at runtime.
+ */
+export const executionYamlExtract = (raw: unknown): unknown => {
+  const scan = raw as ExecutionYamlScan;
+  const compiled = typeof scan.metadata.executionYaml === "string" ? scan.metadata.executionYaml : null;
+  const inputs = scan.inputs?.data;
+  const data = isRecord(inputs) ? inputs : {};
+
+  const counts = new Map<string, number>();
+  for (const match of compiled?.match(HARNESS_EXPRESSION) ?? []) counts.set(match, (counts.get(match) ?? 0) + 1);
+  const expressions = [...counts.entries()]
+    .sort((a, b) => b[1] - a[1] || a[0].localeCompare(b[0]))
+    .map(([expression, count]) => ({ expression, count }));
+  const templateRefs = [...new Set([...(compiled?.matchAll(/templateRef:\s*["']?([^"'\s]+)/g) ?? [])].map((m) => m[1]!))];
+
+  return {
+    execution_id: scan.execution_id,
+    compiled_yaml: compiled,
+    templates_expanded: compiled !== null && templateRefs.length === 0,
+    ...(templateRefs.length > 0 ? { template_refs: templateRefs } : {}),
+    inputs_yaml: (data.inputSetYaml as string | undefined) ?? null,
+    resolved_inputs_yaml: (data.resolvedYaml as string | undefined) ?? null,
+    runtime_expressions: {
+      total: expressions.reduce((n, e) => n + e.count, 0),
+      distinct: expressions.length,
+      items: expressions.slice(0, MAX_EXPRESSIONS_LISTED),
+    },
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    ...(compiled === null ? { note: "Harness returned no compiled YAML for this execution." } : {}),
+  };
+};
+
+/** Executions gathered by pipeline_health's get collect hook, newest first. */
+export interface PipelineHealthScan {
+  pipeline_id: string;
+  window: { start: number; end: number; days: number };
+  bucket: "day" | "week";
+  executions: Array<Record<string, unknown>>;
+  /** Executions Harness reports in the window; more than executions.length when truncated. */
+  total: number;
+}
+
+type HealthOutcome = "succeeded" | "failed" | "aborted" | "in_progress";
+
+const HEALTH_SUCCEEDED = new Set(["Success", "IgnoreFailed"]);
+const HEALTH_FAILED = new Set(["Failed", "Errored", "Expired", "ApprovalRejected"]);
+const HEALTH_ABORTED = new Set(["Aborted", "AbortedByFreeze"]);
+/** Fewer finished runs than this gives no verdict. */
+const HEALTH_MIN_RUNS = 5;
+/** Share of consecutive finished runs that change outcome at which a pipeline counts as flaky. */
+const HEALTH_FLAKY_FLIP_RATE = 0.3;
+/** Change in failure rate between the window's halves that counts as a trend. */
+const HEALTH_TREND_DELTA = 0.1;
+const HEALTH_TOP_FAILURES = 5;
+const HEALTH_MESSAGE_MAX_CHARS = 200;
+
+function healthOutcome(status: unknown): HealthOutcome {
+  const s = String(status ?? "");
+  if (HEALTH_SUCCEEDED.has(s)) return "succeeded";
+  if (HEALTH_FAILED.has(s)) return "failed";
+  if (HEALTH_ABORTED.has(s)) return "aborted";
+  return "in_progress";
+}
+
+function successRate(succeeded: number, failed: number): number | null {
+  return succeeded + failed > 0 ? Math.round((succeeded / (succeeded + failed)) * 1000) / 1000 : null;
+}
+
+function percentile(sorted: number[], q: number): number {
+  return sorted[Math.min(sorted.length - 1, Math.ceil(q * sorted.length) - 1)]!;
+}
+
+/** Start of the UTC day, or of the UTC week beginning Monday, containing `ms`. */
+function bucketStart(ms: number, bucket: "day" | "week"): number {
+  const day = Math.floor(ms / 86_400_000) * 86_400_000;
+  if (bucket === "day") return day;
+  const weekday = (new Date(day).getUTCDay() + 6) % 7;
+  return day - weekday * 86_400_000;
+}
+
+/**
+ * pipeline_health extractor: success rate, durations, failure trend, and
+ * flakiness of a pipeline's recent executions. A pipeline is flaky when its
+ * finished runs keep switching between success and failure, and failing when
+ * it mostly fails without switching back.
+ */
+export const pipelineHealthExtract = (raw: unknown): unknown => {
+  const scan = raw as PipelineHealthScan;
+  const runs = scan.executions
+    .map((e) => ({
+      id: e.planExecutionId ?? null,
+      outcome: healthOutcome(e.status),
+      start: typeof e.startTs === "number" ? e.startTs : null,
+      end: typeof e.endTs === "number" ? e.endTs : null,
+      message: isRecord(e.failureInfo) && typeof e.failureInfo.message === "string" ? e.failureInfo.message.trim() : "",
+    }))
+    .sort((a, b) => (a.start ?? 0) - (b.start ?? 0));
+
+  const counts = { executions: runs.length, succeeded: 0, failed: 0, aborted: 0, in_progress: 0 };
+  for (const run of runs) counts[run.outcome]++;
+
+  const finished = runs.filter((r) => r.outcome === "succeeded" || r.outcome === "failed");
+  const durations = finished
+    .filter((r) => r.start !== null && r.end !== null && r.end >= r.start)
+    .map((r) => r.end! - r.start!)
+    .sort((a, b) => a - b);
+
+  let flips = 0;
+  for (let i = 1; i < finished.length; i++) if (finished[i]!.outcome !== finished[i - 1]!.outcome) flips++;
+  const flipRate = finished.length > 1 ? Math.round((flips / (finished.length - 1)) * 1000) / 1000 : 0;
+
+  const mid = scan.window.start + (scan.window.end - scan.window.start) / 2;
+  const failureRate = (half: typeof finished) => half.length > 0 ? half.filter((r) => r.outcome === "failed").length / half.length : null;
+  const early = failureRate(finished.filter((r) => r.start !== null && r.start < mid));
+  const late = failureRate(finished.filter((r) => r.start !== null && r.start >= mid));
+  const failureTrend = early === null || late === null ? "insufficient_data"
+    : late - early >= HEALTH_TREND_DELTA ? "worsening"
+      : early - late >= HEALTH_TREND_DELTA ? "improving"
+        : "stable";
+
+  const buckets = new Map<number, { executions: number; succeeded: number; failed: number; aborted: number; durations: number[] }>();
+  for (let t = bucketStart(scan.window.start, scan.bucket); t <= scan.window.end; t += (scan.bucket === "day" ? 1 : 7) * 86_400_000) {
+    buckets.set(t, { executions: 0, succeeded: 0, failed: 0, aborted: 0, durations: [] });
+  }
+  for (const run of runs) {
+    const entry = run.start !== null ? buckets.get(bucketStart(run.start, scan.bucket)) : undefined;
+    if (!entry) continue;
+    entry.executions++;
+    if (run.outcome !== "in_progress") entry[run.outcome]++;
+    if (run.outcome !== "aborted" && run.outcome !== "in_progress" && run.start !== null && run.end !== null) entry.durations.push(run.end - run.start);
+  }
+  const trend = [...buckets.entries()].map(([start, b]) => ({
+    start: new Date(start).toISOString(),
+    executions: b.executions,
+    succeeded: b.succeeded,
+    failed: b.failed,
+    aborted: b.aborted,
+    success_rate: successRate(b.succeeded, b.failed),
+    mean_duration_ms: b.durations.length > 0 ? Math.round(b.durations.reduce((n, d) => n + d, 0) / b.durations.length) : null,
+  }));
+
+  const failures = new Map<string, { message: string; count: number; last_execution_id: unknown; last: number }>();
+  for (const run of runs) {
+    if (run.outcome !== "failed") continue;
+    const message = run.message.slice(0, HEALTH_MESSAGE_MAX_CHARS) || "(no failure message)";
+    const entry = failures.get(message) ?? { message, count: 0, last_execution_id: null, last: -1 };
+    entry.count++;
+    if ((run.start ?? 0) >= entry.last) {
+      entry.last = run.start ?? 0;
+      entry.last_execution_id = run.id;
+    }
+    failures.set(message, entry);
+  }
+  const topFailures = [...failures.values()]
+    .sort((a, b) => b.count - a.count || b.last - a.last)
+    .slice(0, HEALTH_TOP_FAILURES)
+    .map(({ message, count, last_execution_id }) => ({ message, count, last_execution_id }));
+
+  const rate = successRate(counts.succeeded, counts.failed);
+  const verdict = finished.length < HEALTH_MIN_RUNS ? "insufficient_data"
+    : flipRate >= HEALTH_FLAKY_FLIP_RATE ? "flaky"
+      : rate !== null && rate < 0.5 ? "failing"
+        : "healthy";
+
+  return {
+    pipeline_id: scan.pipeline_id,
+    window: {
+      start: new Date(scan.window.start).toISOString(),
+      end: new Date(scan.window.end).toISOString(),
+      days: scan.window.days,
+    },
+    verdict,
+    counts,
+    success_rate: rate,
+    duration_ms: durations.length > 0
+      ? {
+        mean: Math.round(durations.reduce((n, d) => n + d, 0) / durations.length),
+        median: percentile(durations, 0.5),
+        p90: percentile(durations, 0.9),
+        max: durations[durations.length - 1]!,
+      }
+      : null,
+    flakiness: { outcome_flips: flips, flip_rate: flipRate },
+    failure_trend: failureTrend,
+    bucket: scan.bucket,
+    trend,
+    top_failures: topFailures,
+    ...(scan.total > scan.executions.length
+      ? { truncated: true, note: `Summarized the newest ${scan.executions.length} of ${scan.total} executions in the window. Narrow window_days for a complete picture.` }
+      : {}),
+  };
+};
+
+/** Raw payloads gathered by ci_resource_usage's get collect hook. */
+export interface CiResourceUsageScan {
+  execution_id: string;
+  /** Execution v2 payload with the full step graph. */
+  execution: unknown;
+  /** CI build metadata with measured usage, when readable. */
+  usage?: unknown;
+  errors: string[];
+}
+
+interface ResourceStats {
+  avg: number | null;
+  max: number | null;
+  limit: number | null;
+  utilization: number | null;
+}
+
+interface CacheStats {
+  hits: number | null;
+  misses: number | null;
+  hit_rate: number | null;
+}
+
+interface MeasuredUsage {
+  cpu?: unknown;
+  memory?: unknown;
+  cache?: unknown;
+}
+
+/** CPU quantity in millicores: "500m", "1.5", or a number of cores. */
+export function parseCpuMillicores(value: unknown): number | undefined {
+  if (typeof value === "number") return Number.isFinite(value) && value >= 0 ? Math.round(value * 1000) : undefined;
+  const match = typeof value === "string" ? /^\s*(\d+(?:\.\d+)?)\s*(m)?\s*$/.exec(value) : null;
+  if (!match) return undefined;
+  const n = Number(match[1]);
+  return Math.round(match[2] ? n : n * 1000);
+}
+
+const MEMORY_UNIT_BYTES: Record<string, number> = {
+  "": 1, k: 1e3, m: 1e6, g: 1e9, t: 1e12, ki: 1024, mi: 1024 ** 2, gi: 1024 ** 3, ti: 1024 ** 4,
+};
+
+/** Memory quantity in MiB: "500Mi", "2Gi", "1G", or a number of bytes. */
+export function parseMemoryMib(value: unknown): number | undefined {
+  if (typeof value === "number") return Number.isFinite(value) && value >= 0 ? Math.round(value / 1024 ** 2) : undefined;
+  const match = typeof value === "string" ? /^\s*(\d+(?:\.\d+)?)\s*([kmgt]i?)?b?\s*$/i.exec(value) : null;
+  if (!match) return undefined;
+  return Math.round((Number(match[1]) * MEMORY_UNIT_BYTES[(match[2] ?? "").toLowerCase()]!) / 1024 ** 2);
+}
+
+const round2 = (n: number) => Math.round(n * 100) / 100;
+
+function resourceStats(
+  measured: unknown,
+  parse: (value: unknown) => number | undefined,
+  configuredLimit: number | undefined,
+): ResourceStats | undefined {
+  const record = isRecord(measured) ? measured : {};
+  const avg = parse(pick(record, "avg", "average", "mean")) ?? null;
+  const max = parse(pick(record, "max", "peak")) ?? null;
+  const limit = configuredLimit ?? parse(pick(record, "limit", "request", "allocated", "requested")) ?? null;
+  if (avg === null && max === null && limit === null) return undefined;
+  return { avg, max, limit, utilization: max !== null && limit ? round2(max / limit) : null };
+}
+
+function cacheStats(measured: unknown): CacheStats | undefined {
+  if (!isRecord(measured)) return undefined;
+  const count = (value: unknown) => (typeof value === "number" && Number.isFinite(value) ? value : null);
+  const hits = count(pick(measured, "hits", "cacheHits", "hit_count"));
+  const misses = count(pick(measured, "misses", "cacheMisses", "miss_count"));
+  const rate = count(pick(measured, "hit_rate", "hitRate"));
+  if (hits === null && misses === null && rate === null) return undefined;
+  return {
+    hits,
+    misses,
+    hit_rate: hits !== null && misses !== null ? (hits + misses > 0 ? round2(hits / (hits + misses)) : null) : rate,
+  };
+}
+
+/**
+ * Measured usage records in CI build metadata, keyed by stage and by
+ * "stage/step". Records carry cpu, memory, or cache objects next to a stage
+ * or step identifier; steps nested under a stage record inherit its stage.
+ */
+function collectMeasuredUsage(raw: unknown): { stages: Map<string, MeasuredUsage>; steps: Map<string, MeasuredUsage> } {
+  const stages = new Map<string, MeasuredUsage>();
+  const steps = new Map<string, MeasuredUsage>();
+  const walk = (node: unknown, stage: string | undefined, depth: number): void => {
+    if (depth > 8) return;
+    if (Array.isArray(node)) {
+      for (const item of node) walk(item, stage, depth + 1);
+      return;
+    }
+    if (!isRecord(node)) return;
+    const stageId = pick(node, "stageIdentifier", "stageId", "stage_id");
+    const currentStage = typeof stageId === "string" ? stageId : stage;
+    const stepId = pick(node, "stepIdentifier", "stepId", "step_id");
+    const usage: MeasuredUsage = {
+      cpu: pick(node, "cpu", "cpuUsage"),
+      memory: pick(node, "memory", "memoryUsage"),
+      cache: pick(node, "cache", "cacheStats"),
+    };
+    if (isRecord(usage.cpu) || isRecord(usage.memory) || isRecord(usage.cache)) {
+      if (typeof stepId === "string" && currentStage) steps.set(`${currentStage}/${stepId}`, usage);
+      else if (typeof stageId === "string") stages.set(stageId, usage);
+    }
+    for (const value of Object.values(node)) walk(value, currentStage, depth + 1);
+  };
+  walk(raw, undefined, 0);
+  return { stages, steps };
+}
+
+interface Recommendation {
+  stage_id: string;
+  step_id?: string;
+  resource: "cpu" | "memory" | "cache";
+  action: "decrease" | "increase" | "review_cache_key";
+  current_limit?: number;
+  suggested_limit?: number;
+  reason: string;
+}
+
+/** Right-sizing below this peak utilization, and headroom above this one. */
+const RIGHT_SIZE_LOW = 0.4;
+const RIGHT_SIZE_HIGH = 0.9;
+const CACHE_LOW_HIT_RATE = 0.5;
+
+function rightSize(stats: ResourceStats | undefined, resource: "cpu" | "memory"): Omit<Recommendation, "stage_id" | "step_id"> | undefined {
+  if (!stats || stats.max === null || !stats.limit) return undefined;
+  const granularity = resource === "cpu" ? 100 : 128;
+  const unit = resource === "cpu" ? "millicores" : "MiB";
+  const pct = Math.round((stats.max / stats.limit) * 100);
+  if (stats.max / stats.limit < RIGHT_SIZE_LOW) {
+    const suggested = Math.max(granularity, Math.ceil((stats.max * 1.3) / granularity) * granularity);
+    if (suggested >= stats.limit) return undefined;
+    return {
+      resource,
+      action: "decrease",
+      current_limit: stats.limit,
+      suggested_limit: suggested,
+      reason: `Peak ${resource} was ${pct}% of the ${stats.limit} ${unit} limit.`,
+    };
+  }
+  if (stats.max / stats.limit > RIGHT_SIZE_HIGH) {
+    return {
+      resource,
+      action: "increase",
+      current_limit: stats.limit,
+      suggested_limit: Math.ceil((stats.max * 1.5) / granularity) * granularity,
+      reason: `Peak ${resource} was ${pct}% of the ${stats.limit} ${unit} limit; ${resource === "memory" ? "builds near the limit risk OOM kills" : "the build is likely CPU-throttled"}.`,
+    };
+  }
+  return undefined;
+}
+
+function durationMs(node: Record<string, unknown>): number | null {
+  return typeof node.startTs === "number" && typeof node.endTs === "number" && node.endTs >= node.startTs
+    ? node.endTs - node.startTs
+    : null;
+}
+
+/**
+ * ci_resource_usage extractor: per CI stage and step, measured CPU and memory
+ * against the configured limits, cache hit rates, and right-sizing
+ * suggestions — decrease when the peak stays under 40% of the limit,
+ * increase above 90%.
+ */
+export const ciResourceUsageExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as CiResourceUsageScan;
+  const data = isRecord(scan.execution) && isRecord(scan.execution.data) ? scan.execution.data : {};
+  const pes = isRecord(data.pipelineExecutionSummary) ? data.pipelineExecutionSummary : {};
+  const layout = isRecord(pes.layoutNodeMap) ? pes.layoutNodeMap : {};
+  const graph = isRecord(data.executionGraph) && isRecord(data.executionGraph.nodeMap) ? data.executionGraph.nodeMap : {};
+  const measured = collectMeasuredUsage(scan.usage);
+  const stageFilter = typeof input?.stage_id === "string" && input.stage_id ? input.stage_id : undefined;
+
+  const stepsByStage = new Map<string, Array<Record<string, unknown>>>();
+  for (const [uuid, node] of Object.entries(graph)) {
+    if (!isRecord(node) || typeof node.baseFqn !== "string") continue;
+    if (TIMELINE_CONTAINER_STEP_TYPES.has(String(node.stepType ?? ""))) continue;
+    const match = STEP_FQN_PATTERN.exec(node.baseFqn);
+    if (!match) continue;
+    const list = stepsByStage.get(match[1]!) ?? [];
+    list.push({ ...node, identifier: node.identifier ?? match[2] ?? uuid });
+    stepsByStage.set(match[1]!, list);
+  }
+
+  const recommendations: Recommendation[] = [];
+  const recommend = (stageId: string, stepId: string | undefined, stats: { cpu?: ResourceStats; memory?: ResourceStats; cache?: CacheStats }) => {
+    const where = { stage_id: stageId, ...(stepId ? { step_id: stepId } : {}) };
+    for (const resource of ["cpu", "memory"] as const) {
+      const rec = rightSize(stats[resource], resource);
+      if (rec) recommendations.push({ ...where, ...rec });
+    }
+    const cache = stats.cache;
+    if (cache?.hit_rate !== null && cache?.hit_rate !== undefined && cache.hit_rate < CACHE_LOW_HIT_RATE) {
+      recommendations.push({
+        ...where,
+        resource: "cache",
+        action: "review_cache_key",
+        reason: `Cache hit rate was ${Math.round(cache.hit_rate * 100)}%. Check that the cache key is stable across builds (e.g. keyed on the lockfile checksum, not the commit).`,
+      });
+    }
+  };
+
+  const ciStages = Object.values(layout)
+    .filter((node): node is Record<string, unknown> => isRecord(node)
+      && String(node.module ?? node.nodeType ?? "").toLowerCase() === "ci"
+      && typeof node.nodeIdentifier === "string")
+    .filter((node) => !stageFilter || node.nodeIdentifier === stageFilter)
+    .sort((a, b) => (Number(a.startTs) || Infinity) - (Number(b.startTs) || Infinity));
+
+  const stages = ciStages.map((node) => {
+    const stageId = node.nodeIdentifier as string;
+    const moduleInfo = isRecord(node.moduleInfo) && isRecord(node.moduleInfo.ci) ? node.moduleInfo.ci : {};
+    const infra = Array.isArray(moduleInfo.infraDetailsList) && isRecord(moduleInfo.infraDetailsList[0]) ? moduleInfo.infraDetailsList[0] : undefined;
+    const stageUsage = measured.stages.get(stageId) ?? {};
+    const stageStats = {
+      cpu: resourceStats(stageUsage.cpu, parseCpuMillicores, undefined),
+      memory: resourceStats(stageUsage.memory, parseMemoryMib, undefined),
+      cache: cacheStats(stageUsage.cache),
+    };
+    recommend(stageId, undefined, stageStats);
+
+    const steps = (stepsByStage.get(stageId) ?? [])
+      .sort((a, b) => (Number(a.startTs) || Infinity) - (Number(b.startTs) || Infinity))
+      .map((step) => {
+        const stepId = String(step.identifier);
+        const params = isRecord(step.stepParameters) && isRecord(step.stepParameters.spec) ? step.stepParameters.spec : {};
+        const limits = isRecord(params.resources) && isRecord(params.resources.limits) ? params.resources.limits : {};
+        const usage = measured.steps.get(`${stageId}/${stepId}`) ?? {};
+        const stats = {
+          cpu: resourceStats(usage.cpu, parseCpuMillicores, parseCpuMillicores(limits.cpu)),
+          memory: resourceStats(usage.memory, parseMemoryMib, parseMemoryMib(limits.memory)),
+          cache: cacheStats(usage.cache),
+        };
+        recommend(stageId, stepId, stats);
+        const type = String(step.stepType ?? "");
+        return {
+          id: stepId,
+          name: String(step.name ?? stepId),
+          type,
+          status: String(step.status ?? "Unknown"),
+          duration_ms: durationMs(step),
+          ...(/cache/i.test(type) ? { cache_step: true } : {}),
+          ...(stats.cpu ? { cpu: stats.cpu } : {}),
+          ...(stats.memory ? { memory: stats.memory } : {}),
+          ...(stats.cache ? { cache: stats.cache } : {}),
+        };
+      });
+
+    return {
+      id: stageId,
+      name: String(node.name ?? stageId),
+      status: String(node.status ?? "Unknown"),
+      duration_ms: durationMs(node),
+      infrastructure: infra
+        ? {
+          type: infra.infraType ?? null,
+          host: infra.infraHostType ?? null,
+          os: infra.infraOSType ?? null,
+          arch: infra.infraArchType ?? null,
+        }
+        : null,
+      ...(stageStats.cpu ? { cpu: stageStats.cpu } : {}),
+      ...(stageStats.memory ? { memory: stageStats.memory } : {}),
+      ...(stageStats.cache ? { cache: stageStats.cache } : {}),
+      steps,
+    };
+  });
+
+  return {
+    execution_id: scan.execution_id,
+    pipeline_id: pes.pipelineIdentifier ?? null,
+    status: pes.status ?? null,
+    measured: measured.stages.size + measured.steps.size > 0,
+    stages,
+    recommendations,
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    ...(stages.length === 0
+      ? { note: stageFilter ? `No CI stage "${stageFilter}" in this execution.` : "No CI stages in this execution." }
+      : {}),
+  };
+};
+
+/**
+ * pull_request merge/check_merge extractor. A dry run becomes a mergeability
+ * report — conflicts, unbypassed rule violations, and whether the requested
+ * method is allowed, listed as `blockers`. Real merges pass through.
+ */
+export const pullRequestMergeExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  if (!isRecord(raw) || raw.dry_run !== true) return raw;
+  const body = isRecord(input?.body) ? input.body : {};
+  const method = typeof (body.method ?? input?.method) === "string" ? (body.method ?? input?.method) as string : undefined;
+  const conflictFiles = Array.isArray(raw.conflict_files) ? raw.conflict_files.filter((f): f is string => typeof f === "string") : [];
+  const allowedMethods = Array.isArray(raw.allowed_methods) ? raw.allowed_methods.filter((m): m is string => typeof m === "string") : undefined;
+
+  const ruleViolations = (Array.isArray(raw.rule_violations) ? raw.rule_violations : []).filter(isRecord).map((entry) => {
+    const rule = isRecord(entry.rule) ? entry.rule : {};
+    const messages = (Array.isArray(entry.violations) ? entry.violations : [])
+      .filter(isRecord)
+      .map((v) => String(v.message ?? v.code ?? "rule violated"));
+    return {
+      rule: rule.identifier ?? null,
+      type: rule.type ?? null,
+      bypassable: entry.bypassable === true,
+      bypassed: entry.bypassed === true,
+      messages,
+    };
+  });
+
+  const blockers: string[] = [];
+  if (conflictFiles.length > 0) blockers.push(`Merge conflicts in ${conflictFiles.length} file(s): ${conflictFiles.slice(0, 10).join(", ")}`);
+  for (const violation of ruleViolations) {
+    if (violation.bypassed) continue;
+    blockers.push(`Rule ${violation.rule ?? "(unnamed)"}: ${violation.messages.join("; ") || "violated"}${violation.bypassable ? " (bypassable)" : ""}`);
+  }
+  if (method && allowedMethods && !allowedMethods.includes(method)) {
+    blockers.push(`Merge method "${method}" is not allowed here; allowed: ${allowedMethods.join(", ") || "none"}`);
+  }
+
+  return {
+    dry_run: true,
+    mergeable: blockers.length === 0,
+    ...(method ? { method } : {}),
+    ...(allowedMethods ? { allowed_methods: allowedMethods } : {}),
+    conflict_files: conflictFiles,
+    rule_violations: ruleViolations,
+    blockers,
+    requirements: {
+      minimum_approvals: raw.minimum_required_approvals_count ?? null,
+      minimum_approvals_latest: raw.minimum_required_approvals_count_latest ?? null,
+      code_owners_approval: raw.requires_code_owners_approval ?? null,
+      comment_resolution: raw.requires_comment_resolution ?? null,
+      no_change_requests: raw.requires_no_change_requests ?? null,
+    },
+  };
+};
+
+/** Raw payloads gathered by pr_check's get collect hook. */
+export interface PrCheckStatusScan {
+  repo_id: string;
+  pr_number: string;
+  pull_request: unknown;
+  checks: unknown;
+  rules: unknown;
+  default_branch?: string;
+  /** Lookups that failed; the report is built from what was read. */
+  errors: string[];
+}
+
+/** Glob over `/`-separated names (branches, file paths): `**` spans segments, `*` and `?` stay within one. */
+function pathGlobMatches(glob: string, value: string): boolean {
+  const source = glob.replace(/[.+^${}()|[\]\\]/g, "\\$&").replace(/\*\*|\*|\?/g, (token) => (token === "**" ? ".*" : token === "*" ? "[^/]*" : "[^/]"));
+  return new RegExp(`^${source}$`).test(value);
+}
+
+/**
+ * Whether a rule's branch pattern covers `branch`, as Harness Code evaluates
+ * it: no default flag and no includes matches every branch; excludes win.
+ */
+function rulePatternMatches(pattern: unknown, branch: string, defaultBranch: string | undefined): boolean {
+  const p = isRecord(pattern) ? pattern : {};
+  const include = Array.isArray(p.include) ? p.include.filter((g): g is string => typeof g === "string") : [];
+  const exclude = Array.isArray(p.exclude) ? p.exclude.filter((g): g is string => typeof g === "string") : [];
+  let matches = p.default !== true && include.length === 0;
+  matches ||= p.default === true && branch === defaultBranch;
+  matches ||= include.some((glob) => pathGlobMatches(glob, branch));
+  return matches && !exclude.some((glob) => pathGlobMatches(glob, branch));
+}
+
+const CHECK_PASSED = new Set(["success", "skipped", "failure_ignored"]);
+const CHECK_FAILED = new Set(["failure", "error"]);
+
+/**
+ * pr_check get extractor: the PR's status checks next to the protection
+ * rules that apply to its target branch, reduced to why checks block the
+ * merge — required checks that failed, are still running, or never reported.
+ */
+export const prCheckStatusExtract = (raw: unknown): unknown => {
+  const scan = raw as PrCheckStatusScan;
+  const pr = isRecord(scan.pull_request) ? scan.pull_request : {};
+  const targetBranch = typeof pr.target_branch === "string" ? pr.target_branch : undefined;
+  const checksPayload = isRecord(scan.checks) ? scan.checks.checks : scan.checks;
+
+  const checks = (Array.isArray(checksPayload) ? checksPayload : []).filter(isRecord).map((entry) => {
+    const check = isRecord(entry.check) ? entry.check : entry;
+    return {
+      identifier: String(check.identifier ?? check.uid ?? ""),
+      status: String(check.status ?? "unknown"),
+      required: entry.required === true,
+      ...(entry.bypassable === true ? { bypassable: true } : {}),
+      ...(typeof check.summary === "string" && check.summary ? { summary: check.summary } : {}),
+      ...(typeof check.link === "string" && check.link ? { link: check.link } : {}),
+    };
+  });
+  const byId = new Map(checks.map((check) => [check.identifier, check]));
+
+  const rules = (Array.isArray(scan.rules) ? scan.rules : []).filter(isRecord)
+    .filter((rule) => (rule.type ?? "branch") === "branch")
+    .filter((rule) => targetBranch === undefined || rulePatternMatches(rule.pattern, targetBranch, scan.default_branch))
+    .map((rule) => {
+      const pullreq = isRecord(rule.definition) && isRecord(rule.definition.pullreq) ? rule.definition.pullreq : {};
+      const statusChecks = isRecord(pullreq.status_checks) ? pullreq.status_checks : {};
+      const approvals = isRecord(pullreq.approvals) ? pullreq.approvals : {};
+      const comments = isRecord(pullreq.comments) ? pullreq.comments : {};
+      const merge = isRecord(pullreq.merge) ? pullreq.merge : {};
+      const requiredChecks = Array.isArray(statusChecks.require_identifiers)
+        ? statusChecks.require_identifiers.filter((id): id is string => typeof id === "string")
+        : [];
+      return {
+        identifier: String(rule.identifier ?? ""),
+        state: String(rule.state ?? "active"),
+        ...(rule.scope !== undefined ? { scope: rule.scope } : {}),
+        required_checks: requiredChecks,
+        requirements: {
+          minimum_approvals: approvals.require_minimum_count ?? null,
+          code_owners_approval: approvals.require_code_owners ?? null,
+          latest_commit_approval: approvals.require_latest_commit ?? null,
+          no_change_requests: approvals.require_no_change_request ?? null,
+          comment_resolution: comments.require_resolve_all ?? null,
+          merge_strategies: merge.strategies_allowed ?? null,
+        },
+      };
+    });
+
+  const required = new Map<string, string[]>();
+  for (const rule of rules) {
+    if (rule.state !== "active") continue;
+    for (const id of rule.required_checks) required.set(id, [...(required.get(id) ?? []), rule.identifier]);
+  }
+  for (const check of checks) {
+    if (check.required && !required.has(check.identifier)) required.set(check.identifier, []);
+  }
+
+  const blockers: string[] = [];
+  const requiredChecks = [...required.entries()].map(([identifier, ruleIds]) => {
+    const check = byId.get(identifier);
+    const state = !check ? "missing" : CHECK_PASSED.has(check.status) ? "passed" : CHECK_FAILED.has(check.status) ? "failed" : "pending";
+    const by = ruleIds.length > 0 ? ` (required by ${ruleIds.join(", ")})` : "";
+    if (state === "missing") blockers.push(`Required check "${identifier}" has not reported on the latest commit${by}.`);
+    if (state === "failed") blockers.push(`Required check "${identifier}" is ${check!.status}${by}${check!.bypassable ? "; it can be bypassed" : ""}.`);
+    if (state === "pending") blockers.push(`Required check "${identifier}" is still ${check!.status}${by}.`);
+    return { identifier, state, rules: ruleIds };
+  });
+  const monitored = rules.filter((rule) => rule.state === "monitor" && rule.required_checks.length > 0).map((rule) => rule.identifier);
+
+  return {
+    repo_id: scan.repo_id,
+    pr_number: scan.pr_number,
+    ...(targetBranch ? { target_branch: targetBranch } : {}),
+    ...(typeof pr.source_sha === "string" ? { source_sha: pr.source_sha } : {}),
+    checks_pass: blockers.length === 0,
+    blockers,
+    required_checks: requiredChecks,
+    optional_failures: checks
+      .filter((check) => !required.has(check.identifier) && CHECK_FAILED.has(check.status))
+      .map((check) => check.identifier),
+    checks,
+    rules,
+    ...(monitored.length > 0 ? { notes: [`Rules in monitor mode do not block: ${monitored.join(", ")}.`] } : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    hint: "Checks are one part of mergeability. Run harness_execute(resource_type='pull_request', action='check_merge') to evaluate approvals, conflicts, and the other rule requirements.",
+  };
+};
+
+/** Raw payloads gathered by pipeline_compliance's get collect hook. */
+export interface PipelineComplianceScan {
+  pipeline_id: string;
+  pipeline_yaml: unknown;
+  golden: { kind: "pipeline" | "template"; id: string; version?: string };
+  golden_yaml: unknown;
+}
+
+interface ComplianceGap {
+  kind: "missing_stage" | "missing_step" | "delegate_selectors" | "missing_failure_strategy";
+  severity: "high" | "medium" | "low";
+  stage_id?: string;
+  step_id?: string;
+  expected: unknown;
+  actual: unknown;
+  message: string;
+}
+
+interface StructureNode {
+  identifier: string;
+  name: string;
+  type: string;
+  node: Record<string, unknown>;
+}
+
+/** Stage and step types that gate a release; a golden pipeline's copy missing from a pipeline is a high-severity gap. */
+const GATE_TYPES = new Set([
+  "Approval", "HarnessApproval", "JiraApproval", "ServiceNowApproval", "CustomApproval",
+  "Verify", "AnalyzeDeploymentImpact", "Policy", "SecurityTests",
+]);
+
+function parseYamlRecord(value: unknown): Record<string, unknown> | undefined {
+  if (isRecord(value)) return value;
+  if (typeof value !== "string" || !value.trim()) return undefined;
+  try {
+    const doc = YAML.parse(value) as unknown;
+    return isRecord(doc) ? doc : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/** The pipeline body of a pipeline YAML, or of a pipeline template's spec. */
+function pipelineBody(doc: Record<string, unknown> | undefined): Record<string, unknown> | undefined {
+  if (!doc) return undefined;
+  if (isRecord(doc.pipeline)) return doc.pipeline;
+  if (isRecord(doc.template) && isRecord(doc.template.spec)) return doc.template.spec;
+  return undefined;
+}
+
+function structureNode(wrapper: unknown, key: "stage" | "step"): StructureNode | undefined {
+  const node = isRecord(wrapper) && isRecord(wrapper[key]) ? wrapper[key] as Record<string, unknown> : undefined;
+  if (!node || typeof node.identifier !== "string") return undefined;
+  return {
+    identifier: node.identifier,
+    name: typeof node.name === "string" ? node.name : node.identifier,
+    type: typeof node.type === "string" ? node.type : isRecord(node.template) ? "Template" : "Unknown",
+    node,
+  };
+}
+
+/** Stages in declaration order, including those inside parallel blocks. */
+function pipelineStages(body: Record<string, unknown> | undefined): StructureNode[] {
+  const out: StructureNode[] = [];
+  const stages = body?.stages;
+  for (const entry of Array.isArray(stages) ? stages : []) {
+    if (isRecord(entry) && Array.isArray(entry.parallel)) {
+      for (const inner of entry.parallel) {
+        const stage = structureNode(inner, "stage");
+        if (stage) out.push(stage);
+      }
+      continue;
+    }
+    const stage = structureNode(entry, "stage");
+    if (stage) out.push(stage);
+  }
+  return out;
+}
+
+/** Execution steps of a stage, flattening parallel blocks and step groups. */
+function stageSteps(stage: Record<string, unknown>): StructureNode[] {
+  const spec = isRecord(stage.spec) ? stage.spec : {};
+  const execution = isRecord(spec.execution) ? spec.execution : {};
+  const out: StructureNode[] = [];
+  const walk = (entries: unknown): void => {
+    for (const entry of Array.isArray(entries) ? entries : []) {
+      if (!isRecord(entry)) continue;
+      if (Array.isArray(entry.parallel)) walk(entry.parallel);
+      else if (isRecord(entry.stepGroup)) walk(entry.stepGroup.steps);
+      else {
+        const step = structureNode(entry, "step");
+        if (step) out.push(step);
+      }
+    }
+  };
+  walk(execution.steps);
+  return out;
+}
+
+/**
+ * Pair golden nodes with pipeline nodes: by identifier first, then by type
+ * among the nodes still unpaired. Returns the pairs and what stayed unpaired.
+ */
+function matchNodes(golden: StructureNode[], actual: StructureNode[]): {
+  pairs: Array<[StructureNode, StructureNode]>;
+  missing: StructureNode[];
+  extra: StructureNode[];
+} {
+  const remaining = [...actual];
+  const take = (predicate: (node: StructureNode) => boolean): StructureNode | undefined => {
+    const index = remaining.findIndex(predicate);
+    return index === -1 ? undefined : remaining.splice(index, 1)[0];
+  };
+  const pairs: Array<[StructureNode, StructureNode]> = [];
+  const unmatched: StructureNode[] = [];
+  for (const node of golden) {
+    const match = take((candidate) => candidate.identifier === node.identifier);
+    if (match) pairs.push([node, match]);
+    else unmatched.push(node);
+  }
+  const missing: StructureNode[] = [];
+  for (const node of unmatched) {
+    const match = take((candidate) => candidate.type === node.type);
+    if (match) pairs.push([node, match]);
+    else missing.push(node);
+  }
+  return { pairs, missing, extra: remaining };
+}
+
+function delegateSelectors(node: Record<string, unknown> | undefined): string[] {
+  const spec = node && isRecord(node.spec) ? node.spec : {};
+  const selectors = node?.delegateSelectors ?? spec.delegateSelectors;
+  return Array.isArray(selectors) ? selectors.filter((s): s is string => typeof s === "string") : [];
+}
+
+function hasFailureStrategies(node: Record<string, unknown> | undefined): boolean {
+  const strategies = node?.failureStrategies;
+  return Array.isArray(strategies) && strategies.length > 0;
+}
+
+/**
+ * pipeline_compliance extractor: structural gaps between a pipeline and its
+ * golden pipeline or template — missing stages and steps (gates such as
+ * approvals and verification are high severity), delegate selectors the
+ * golden sets but the pipeline lacks, and missing failure strategies.
+ */
+export const pipelineComplianceExtract = (raw: unknown): unknown => {
+  const scan = raw as PipelineComplianceScan;
+  const pipelineDoc = parseYamlRecord(scan.pipeline_yaml);
+  const pipeline = pipelineBody(pipelineDoc);
+  const golden = pipelineBody(parseYamlRecord(scan.golden_yaml));
+  const base = { pipeline_id: scan.pipeline_id, golden: scan.golden };
+  if (!pipeline) return { ...base, verdict: "unknown", note: "Could not read the pipeline's YAML." };
+  if (!golden) return { ...base, verdict: "unknown", note: `Could not read the golden ${scan.golden.kind}'s YAML.` };
+
+  const templateRef = isRecord(pipeline.template) ? pipeline.template.templateRef : undefined;
+  if (scan.golden.kind === "template" && templateRef === scan.golden.id) {
+    return {
+      ...base,
+      verdict: "compliant",
+      gaps: [],
+      extra_stages: [],
+      checks: { total: 0, passed: 0 },
+      note: "The pipeline is built from the golden template, so its structure comes from the template.",
+    };
+  }
+
+  const gaps: ComplianceGap[] = [];
+  let checks = 0;
+  const notes: string[] = [];
+
+  const goldenSelectors = delegateSelectors(golden);
+  if (goldenSelectors.length > 0) {
+    checks++;
+    const actual = delegateSelectors(pipeline);
+    const missing = goldenSelectors.filter((s) => !actual.includes(s));
+    if (missing.length > 0) {
+      gaps.push({
+        kind: "delegate_selectors",
+        severity: "medium",
+        expected: goldenSelectors,
+        actual,
+        message: `Pipeline delegate selectors are missing ${missing.join(", ")}.`,
+      });
+    }
+  }
+
+  const { pairs, missing, extra } = matchNodes(pipelineStages(golden), pipelineStages(pipeline));
+  checks += pairs.length + missing.length;
+  for (const stage of missing) {
+    gaps.push({
+      kind: "missing_stage",
+      severity: GATE_TYPES.has(stage.type) ? "high" : "medium",
+      stage_id: stage.identifier,
+      expected: { identifier: stage.identifier, name: stage.name, type: stage.type },
+      actual: null,
+      message: `No ${stage.type} stage matching "${stage.name}".`,
+    });
+  }
+
+  for (const [goldenStage, stage] of pairs) {
+    const selectors = delegateSelectors(goldenStage.node);
+    if (selectors.length > 0) {
+      checks++;
+      const actual = delegateSelectors(stage.node);
+      const absent = selectors.filter((s) => !actual.includes(s));
+      if (absent.length > 0) {
+        gaps.push({
+          kind: "delegate_selectors",
+          severity: "medium",
+          stage_id: stage.identifier,
+          expected: selectors,
+          actual,
+          message: `Stage "${stage.name}" delegate selectors are missing ${absent.join(", ")}.`,
+        });
+      }
+    }
+    if (hasFailureStrategies(goldenStage.node)) {
+      checks++;
+      if (!hasFailureStrategies(stage.node) && !hasFailureStrategies(pipeline)) {
+        gaps.push({
+          kind: "missing_failure_strategy",
+          severity: "low",
+          stage_id: stage.identifier,
+          expected: goldenStage.node.failureStrategies,
+          actual: null,
+          message: `Stage "${stage.name}" has no failure strategy; the golden stage defines one.`,
+        });
+      }
+    }
+    if (stage.type === "Template" || goldenStage.type === "Template") {
+      notes.push(`Steps of stage "${stage.name}" were not compared because it uses a stage template.`);
+      continue;
+    }
+    const steps = matchNodes(stageSteps(goldenStage.node), stageSteps(stage.node));
+    checks += steps.pairs.length + steps.missing.length;
+    for (const step of steps.missing) {
+      gaps.push({
+        kind: "missing_step",
+        severity: GATE_TYPES.has(step.type) ? "high" : "medium",
+        stage_id: stage.identifier,
+        step_id: step.identifier,
+        expected: { identifier: step.identifier, name: step.name, type: step.type },
+        actual: null,
+        message: `Stage "${stage.name}" has no ${step.type} step matching "${step.name}".`,
+      });
+    }
+  }
+
+  const severityRank = { high: 0, medium: 1, low: 2 } as const;
+  gaps.sort((a, b) => severityRank[a.severity] - severityRank[b.severity]);
+  return {
+    ...base,
+    verdict: gaps.length === 0 ? "compliant" : "gaps_found",
+    gaps,
+    extra_stages: extra.map((stage) => ({ identifier: stage.identifier, name: stage.name, type: stage.type })),
+    checks: { total: checks, passed: checks - gaps.length },
+    ...(notes.length > 0 ? { notes } : {}),
+  };
+};
+
+/** CI steps that push an image; their published artifact is readable by later stages. */
+const BUILD_AND_PUSH_STEPS = new Set([
+  "BuildAndPushDockerRegistry", "BuildAndPushECR", "BuildAndPushGCR", "BuildAndPushGAR", "BuildAndPushACR",
+]);
+
+const RUNTIME_INPUT = /^<\+input>/;
+const STAGE_REFERENCE = /<\+pipeline\.stages\.([A-Za-z0-9_$]+)\.[^>]*>/g;
+
+interface ContractVariable {
+  name: string;
+  type?: string;
+  runtime_input?: true;
+  expression: string;
+}
+
+function contractVariables(value: unknown, prefix: string): ContractVariable[] {
+  const out: ContractVariable[] = [];
+  for (const variable of Array.isArray(value) ? value : []) {
+    if (!isRecord(variable) || typeof variable.name !== "string") continue;
+    out.push({
+      name: variable.name,
+      ...(typeof variable.type === "string" ? { type: variable.type } : {}),
+      ...(typeof variable.value === "string" && RUNTIME_INPUT.test(variable.value) ? { runtime_input: true as const } : {}),
+      expression: `<+${prefix}.variables.${variable.name}>`,
+    });
+  }
+  return out;
+}
+
+/**
+ * Steps of a stage with their expression path under `spec.execution`
+ * (`steps.build` or `steps.group.steps.build`), as Harness expressions
+ * address them. Parallel blocks add no path segment; step groups do.
+ */
+function stageStepPaths(stage: Record<string, unknown>): Array<StructureNode & { path: string }> {
+  const spec = isRecord(stage.spec) ? stage.spec : {};
+  const execution = isRecord(spec.execution) ? spec.execution : {};
+  const out: Array<StructureNode & { path: string }> = [];
+  const walk = (entries: unknown, prefix: string): void => {
+    for (const entry of Array.isArray(entries) ? entries : []) {
+      if (!isRecord(entry)) continue;
+      if (Array.isArray(entry.parallel)) walk(entry.parallel, prefix);
+      else if (isRecord(entry.stepGroup) && typeof entry.stepGroup.identifier === "string") {
+        walk(entry.stepGroup.steps, `${prefix}.${entry.stepGroup.identifier}.steps`);
+      } else {
+        const step = structureNode(entry, "step");
+        if (step) out.push({ ...step, path: `${prefix}.${step.identifier}` });
+      }
+    }
+  };
+  walk(execution.steps, "steps");
+  return out;
+}
+
+/**
+ * Call `visit` on every value under a node with its dotted path. List
+ * entries that wrap a stage, step, or step group are addressed by
+ * identifier, the rest by index.
+ */
+function visitYaml(node: unknown, path: string, visit: (value: unknown, path: string) => void): void {
+  visit(node, path);
+  if (Array.isArray(node)) {
+    node.forEach((item, index) => {
+      const wrapped = isRecord(item) ? (item.stage ?? item.step ?? item.stepGroup) : undefined;
+      if (isRecord(wrapped) && typeof wrapped.identifier === "string") visitYaml(wrapped, `${path}.${wrapped.identifier}`, visit);
+      else visitYaml(item, `${path}[${index}]`, visit);
+    });
+  } else if (isRecord(node)) {
+    for (const [key, value] of Object.entries(node)) visitYaml(value, path ? `${path}.${key}` : key, visit);
+  }
+}
+
+/** Every `<+input>` field under a node, as a dotted path. */
+function runtimeInputPaths(node: unknown): string[] {
+  const out: string[] = [];
+  visitYaml(node, "", (value, path) => {
+    if (typeof value === "string" && RUNTIME_INPUT.test(value)) out.push(path);
+  });
+  return out;
+}
+
+/** `<+pipeline.stages.X...>` expressions anywhere under a node, by referenced stage. */
+function stageReferences(node: unknown): Array<{ stage: string; expression: string }> {
+  const seen = new Set<string>();
+  const out: Array<{ stage: string; expression: string }> = [];
+  const visit = (value: unknown): void => {
+    if (typeof value === "string") {
+      for (const match of value.matchAll(STAGE_REFERENCE)) {
+        if (seen.has(match[0])) continue;
+        seen.add(match[0]);
+        out.push({ stage: match[1]!, expression: match[0] });
+      }
+    } else if (Array.isArray(value)) value.forEach(visit);
+    else if (isRecord(value)) Object.values(value).forEach(visit);
+  };
+  visit(node);
+  return out;
+}
+
+/** A fixed ref; runtime inputs are already listed under runtime_inputs. */
+function refValue(value: unknown): string | undefined {
+  return typeof value === "string" && value && !RUNTIME_INPUT.test(value) ? value : undefined;
+}
+
+/**
+ * pipeline_stage_contract extractor: what each stage takes in and hands on,
+ * as the expressions a downstream stage would use. Inputs are runtime
+ * inputs, stage variables, service/environment refs, and references to
+ * other stages' outputs (which become depends_on). Outputs are stage
+ * variables, step output variables, and images pushed by CI build steps.
+ */
+export const pipelineStageContractExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const data = ngExtract(raw);
+  const record = isRecord(data) ? data : {};
+  const pipeline = pipelineBody(parseYamlRecord(record.yamlPipeline));
+  const pipelineId = (input?.pipeline_id as string | undefined) ?? pipeline?.identifier;
+  if (!pipeline) return { pipeline_id: pipelineId, stages: [], note: "Could not read the pipeline's YAML." };
+  if (isRecord(pipeline.template)) {
+    return {
+      pipeline_id: pipelineId,
+      stages: [],
+      note: `The pipeline is built from template ${String(pipeline.template.templateRef)}; read the template's stages for its contract.`,
+    };
+  }
+
+  const stages = pipelineStages(pipeline);
+  const order = new Map(stages.map((stage, index) => [stage.identifier, index]));
+  const warnings: string[] = [];
+
+  const contracts = stages.map((stage, index) => {
+    const prefix = `pipeline.stages.${stage.identifier}`;
+    const spec = isRecord(stage.node.spec) ? stage.node.spec : {};
+    const service = refValue(isRecord(spec.service) ? spec.service.serviceRef : undefined);
+    const environment = refValue(isRecord(spec.environment) ? spec.environment.environmentRef : undefined);
+
+    const references = stageReferences(stage.node).filter((ref) => ref.stage !== stage.identifier);
+    const dependsOn = [...new Set(references.map((ref) => ref.stage))];
+    for (const dependency of dependsOn) {
+      const at = order.get(dependency);
+      if (at === undefined) warnings.push(`Stage "${stage.identifier}" references stage "${dependency}", which is not in the pipeline.`);
+      else if (at >= index) warnings.push(`Stage "${stage.identifier}" references stage "${dependency}", which does not run before it.`);
+    }
+
+    const stepOutputs: Array<{ step: string; name: string; expression: string }> = [];
+    const artifacts: Array<{ step: string; type: string; repo?: string; tags?: unknown; expression: string }> = [];
+    for (const step of stageStepPaths(stage.node)) {
+      const stepSpec = isRecord(step.node.spec) ? step.node.spec : {};
+      for (const output of Array.isArray(stepSpec.outputVariables) ? stepSpec.outputVariables : []) {
+        if (!isRecord(output) || typeof output.name !== "string") continue;
+        stepOutputs.push({
+          step: step.identifier,
+          name: output.name,
+          expression: `<+${prefix}.spec.execution.${step.path}.output.outputVariables.${output.name}>`,
+        });
+      }
+      if (BUILD_AND_PUSH_STEPS.has(step.type)) {
+        const repo = refValue(stepSpec.repo) ?? refValue(stepSpec.imageName);
+        artifacts.push({
+          step: step.identifier,
+          type: step.type,
+          ...(repo ? { repo } : {}),
+          ...(Array.isArray(stepSpec.tags) ? { tags: stepSpec.tags } : {}),
+          expression: `<+${prefix}.spec.execution.${step.path}.artifact_${step.identifier}.stepArtifacts.publishedImageArtifacts[0].imageName>`,
+        });
+      }
+    }
+
+    return {
+      identifier: stage.identifier,
+      name: stage.name,
+      type: stage.type,
+      depends_on: dependsOn,
+      inputs: {
+        runtime_inputs: runtimeInputPaths(stage.node),
+        variables: contractVariables(stage.node.variables, prefix),
+        ...(service ? { service } : {}),
+        ...(environment ? { environment } : {}),
+        references,
+      },
+      outputs: {
+        variables: contractVariables(stage.node.variables, prefix).map(({ name, expression }) => ({ name, expression })),
+        step_outputs: stepOutputs,
+        artifacts,
+      },
+    };
+  });
+
+  return {
+    pipeline_id: pipelineId,
+    variables: contractVariables(pipeline.variables, "pipeline"),
+    stages: contracts,
+    ...(warnings.length > 0 ? { warnings } : {}),
+  };
+};
+
+/** Raw payloads gathered by pipeline_config_inventory's get collect hook. */
+export interface PipelineConfigScan {
+  pipeline_id: string;
+  pipeline_yaml: unknown;
+  /** Templates the pipeline uses, directly or through other templates. */
+  templates: Array<{ ref: string; version?: string; yaml?: unknown; error?: string }>;
+  /** Set when more templates were referenced than were fetched. */
+  truncated?: boolean;
+}
+
+/** Distinct template refs (with version labels) used anywhere in a pipeline or template YAML. */
+export function findTemplateRefs(yaml: unknown): Array<{ ref: string; version?: string }> {
+  const seen = new Set<string>();
+  const out: Array<{ ref: string; version?: string }> = [];
+  visitYaml(parseYamlRecord(yaml), "", (value) => {
+    if (!isRecord(value) || typeof value.templateRef !== "string" || value.templateRef.startsWith("<+")) return;
+    const version = typeof value.versionLabel === "string" && value.versionLabel ? value.versionLabel : undefined;
+    const key = `${value.templateRef}@${version ?? ""}`;
+    if (seen.has(key)) return;
+    seen.add(key);
+    out.push({ ref: value.templateRef, ...(version ? { version } : {}) });
+  });
+  return out;
+}
+
+const SECRET_REFERENCE = /<\+secrets\.getValue\(\s*["']([^"']+)["']\s*\)>/g;
+const VARIABLE_REFERENCE = /<\+variable\.([\w.-]+)>/g;
+/** Most distinct values listed per environment variable. */
+const INVENTORY_MAX_VALUES = 5;
+
+function refScope(ref: string): "account" | "org" | "project" {
+  if (ref.startsWith("account.")) return "account";
+  if (ref.startsWith("org.")) return "org";
+  return "project";
+}
+
+interface InventoryEnvVar {
+  name: string;
+  declared_as: Set<string>;
+  values: Set<string>;
+  secret: boolean;
+  runtime_input: boolean;
+  locations: string[];
+}
+
+/**
+ * pipeline_config_inventory extractor: one consolidated list of the
+ * configuration a pipeline and its templates depend on — environment
+ * variables and variables (with their values), secrets, connectors, and
+ * account/org/project variables — each with the locations that use it.
+ */
+export const pipelineConfigInventoryExtract = (raw: unknown): unknown => {
+  const scan = raw as PipelineConfigScan;
+  const pipelineDoc = parseYamlRecord(scan.pipeline_yaml);
+  if (!pipelineDoc) return { pipeline_id: scan.pipeline_id, note: "Could not read the pipeline's YAML." };
+
+  const envVars = new Map<string, InventoryEnvVar>();
+  const refs = {
+    secrets: new Map<string, string[]>(),
+    connectors: new Map<string, string[]>(),
+    variables: new Map<string, string[]>(),
+  };
+  const addRef = (kind: keyof typeof refs, ref: string, location: string): void => {
+    const locations = refs[kind].get(ref) ?? [];
+    if (!locations.includes(location)) locations.push(location);
+    refs[kind].set(ref, locations);
+  };
+  const addEnv = (name: string, value: unknown, declaredAs: string, location: string, secret: boolean): void => {
+    const entry = envVars.get(name) ?? { name, declared_as: new Set(), values: new Set(), secret: false, runtime_input: false, locations: [] };
+    entry.declared_as.add(declaredAs);
+    entry.locations.push(location);
+    if (secret) entry.secret = true;
+    if (typeof value === "string" && RUNTIME_INPUT.test(value)) entry.runtime_input = true;
+    else if (value !== undefined && value !== null && entry.values.size < INVENTORY_MAX_VALUES) entry.values.add(String(value));
+    envVars.set(name, entry);
+  };
+  const declared = (list: unknown, declaredAs: string, source: string, path: string): void => {
+    for (const item of Array.isArray(list) ? list : []) {
+      if (!isRecord(item) || typeof item.name !== "string") continue;
+      const secret = item.type === "Secret";
+      addEnv(item.name, item.value, declaredAs, `${source}: ${path}.${declaredAs}.${item.name}`, secret);
+      if (secret && typeof item.value === "string" && item.value && !item.value.startsWith("<+")) {
+        addRef("secrets", item.value, `${source}: ${path}.${declaredAs}.${item.name}`);
+      }
+    }
+  };
+
+  const documents: Array<{ source: string; doc: Record<string, unknown> }> = [{ source: "pipeline", doc: pipelineDoc }];
+  for (const template of scan.templates) {
+    const doc = parseYamlRecord(template.yaml);
+    if (doc) documents.push({ source: `template ${template.ref}${template.version ? `@${template.version}` : ""}`, doc });
+  }
+  for (const { source, doc } of documents) {
+    visitYaml(doc, "", (value, path) => {
+      if (typeof value === "string") {
+        for (const match of value.matchAll(SECRET_REFERENCE)) addRef("secrets", match[1]!, `${source}: ${path}`);
+        for (const match of value.matchAll(VARIABLE_REFERENCE)) addRef("variables", match[1]!, `${source}: ${path}`);
+        return;
+      }
+      if (!isRecord(value)) return;
+      if (isRecord(value.envVariables)) {
+        for (const [name, envValue] of Object.entries(value.envVariables)) {
+          addEnv(name, envValue, "envVariables", `${source}: ${path}.envVariables.${name}`, false);
+        }
+      }
+      declared(value.environmentVariables, "environmentVariables", source, path);
+      declared(value.variables, "variables", source, path);
+      if (typeof value.connectorRef === "string" && value.connectorRef && !value.connectorRef.startsWith("<+")) {
+        addRef("connectors", value.connectorRef, `${source}: ${path}.connectorRef`);
+      }
+    });
+  }
+
+  const refList = (kind: keyof typeof refs) => [...refs[kind].entries()]
+    .sort(([a], [b]) => a.localeCompare(b))
+    .map(([ref, locations]) => ({ ref, scope: refScope(ref), locations }));
+  const env = [...envVars.values()]
+    .sort((a, b) => a.name.localeCompare(b.name))
+    .map((entry) => ({
+      name: entry.name,
+      declared_as: [...entry.declared_as],
+      values: [...entry.values],
+      ...(entry.secret ? { secret: true } : {}),
+      ...(entry.runtime_input ? { runtime_input: true } : {}),
+      locations: entry.locations,
+    }));
+  const secrets = refList("secrets");
+  const connectors = refList("connectors");
+  const variables = refList("variables");
+  const templates = scan.templates.map((t) => ({
+    ref: t.ref,
+    ...(t.version ? { version: t.version } : {}),
+    ...(t.error ? { error: t.error } : parseYamlRecord(t.yaml) ? {} : { error: "Could not read the template's YAML." }),
+  }));
+  return {
+    pipeline_id: scan.pipeline_id,
+    env_vars: env,
+    secrets,
+    connectors,
+    variables,
+    templates,
+    totals: { env_vars: env.length, secrets: secrets.length, connectors: connectors.length, variables: variables.length, templates: templates.length },
+    ...(scan.truncated ? { note: "The pipeline uses more templates than were scanned; configuration in the rest is not included." } : {}),
+  };
+};
+
+// ---------------------------------------------------------------------------
+// IDP scorecards
+// ---------------------------------------------------------------------------
+
+/**
+ * scorecard_stats / scorecard_check_stats extractor: the entities scored by a
+ * scorecard or check, with the raw timestamp as RFC3339 `time`. Optional
+ * input filters narrow the entities: status (PASS/FAIL, check stats),
+ * below_score (scorecard stats, lowest first), kind, and type.
  */
-export const sdPageExtract = (raw: unknown): { items: unknown[]; total: number; _hint?: string } => {
-  const r = raw as { items?: unknown[]; page?: { totalItems?: number } };
-  const items = r.items ?? [];
-  const reported = r.page?.totalItems ?? 0;
-  const total = Math.max(reported, items.length);
-  if (items.length === 0) {
+export const idpScorecardStatsExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const r = raw as { name?: string; stats?: unknown[]; timestamp?: number | null };
+  const all = r.stats ?? [];
+  const status = typeof input?.status === "string" ? input.status.toUpperCase() : undefined;
+  const below = input?.below_score !== undefined && input.below_score !== "" ? Number(input.below_score) : undefined;
+  const kind = typeof input?.kind === "string" ? input.kind.toLowerCase() : undefined;
+  const type = typeof input?.type === "string" ? input.type.toLowerCase() : undefined;
+  const filtered = status !== undefined || below !== undefined || kind !== undefined || type !== undefined;
+
+  let stats = all;
+  if (filtered) {
+    stats = all.filter(isRecord).filter((s) =>
+      (status === undefined || String(s.status ?? "").toUpperCase() === status)
+      && (below === undefined || (typeof s.score === "number" && s.score < below))
+      && (kind === undefined || String(s.kind ?? "").toLowerCase() === kind)
+      && (type === undefined || String(s.type ?? "").toLowerCase() === type));
+    if (below !== undefined) stats.sort((a, b) => ((a as { score: number }).score - (b as { score: number }).score));
+  }
+  return {
+    name: r.name,
+    stats,
+    time: r.timestamp != null ? new Date(r.timestamp).toISOString() : "",
+    ...(filtered ? { matched: stats.length, entities: all.length } : {}),
+  };
+};
+
+/**
+ * idp_score list extractor: the entity's overall score and one item per
+ * scorecard, each with its checks counted and the failing ones named.
+ */
+export const idpScoreExtract = (raw: unknown): unknown => {
+  const r = raw as { overall_score?: number; scorecard_scores?: unknown[] };
+  const items = (r.scorecard_scores ?? []).map((card) => {
+    if (!isRecord(card) || !Array.isArray(card.checks)) return card;
+    const checks = card.checks.filter(isRecord);
+    const failing = checks.filter((c) => String(c.status ?? c.result ?? "").toUpperCase() === "FAIL");
     return {
-      items,
-      total,
-      _hint:
-        "Empty result. Common causes: " +
-        "(1) agent_identity is not a Service Discovery agent — a chaos infrastructure ID (chaos_k8s_infrastructure) is NOT an SD agent ID, they are separate; verify against the SD UI URL. " +
-        "(2) The agent has not completed a discovery sync yet (new agents take a few minutes to populate). " +
-        "(3) For discovered_namespace, the `name` filter is EXACT case-sensitive equality — for partial/case-insensitive matches, list with `all: true` and filter client-side. " +
-        "(4) For discovered_service, the `namespace` filter only matches Kubernetes-typed records (Lambda/EC2/VM/process records won't match).",
+      ...card,
+      passed: checks.filter((c) => String(c.status ?? c.result ?? "").toUpperCase() === "PASS").length,
+      failed: failing.length,
+      failing_checks: failing.map((c) => ({
+        name: c.name ?? c.identifier,
+        ...(c.reason ?? c.fail_message ?? c.failMessage ? { reason: c.reason ?? c.fail_message ?? c.failMessage } : {}),
+      })),
     };
-  }
-  return { items, total };
+  });
+  return { overall_score: r.overall_score, items, total: items.length };
 };
 
 // ---------------------------------------------------------------------------
-// Feature Management Enterprise (FME) extractors
+// IDP workflows
 // ---------------------------------------------------------------------------
 
+const IDP_PARAM_REF = /^\s*\$\{\{\s*parameters\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}\s*$/;
+
+export interface IdpWorkflowInput {
+  name: string;
+  type: string;
+  title?: string;
+  description?: string;
+  required: boolean;
+  default?: unknown;
+  enum?: unknown[];
+  ui_field?: string;
+  /** Filled by the execute action (Harness auth tokens); never ask the user for these. */
+  auto_filled?: true;
+}
+
 /**
- * Flattens `trafficType.id` → `trafficTypeId` at the top level of an FME item.
- * Enables deep link templates to reference `trafficTypeId` directly.
+ * The inputs a workflow's YAML declares in spec.parameters (a form page or a
+ * list of pages, each a JSON schema object). Parameters rendered as
+ * HarnessAuthToken or referenced by a step's apikey/apiKeySecret input are
+ * marked auto_filled.
  */
-export function flattenTrafficType(item: Record<string, unknown>): void {
-  const tt = item.trafficType;
-  if (tt && typeof tt === "object" && !Array.isArray(tt)) {
-    const ttRecord = tt as Record<string, unknown>;
-    if (ttRecord.id !== undefined && item.trafficTypeId === undefined) {
-      item.trafficTypeId = ttRecord.id;
+export function idpWorkflowInputs(yaml: unknown): IdpWorkflowInput[] {
+  const doc = parseYamlRecord(yaml);
+  const spec = isRecord(doc?.spec) ? doc.spec : {};
+  const autoFilled = new Set<string>();
+  for (const step of Array.isArray(spec.steps) ? spec.steps : []) {
+    const input = isRecord(step) && isRecord(step.input) ? step.input : {};
+    for (const key of ["apikey", "apiKeySecret"]) {
+      const value = input[key];
+      const ref = typeof value === "string" ? IDP_PARAM_REF.exec(value)?.[1] : undefined;
+      if (ref) autoFilled.add(ref);
     }
   }
+  const pages = Array.isArray(spec.parameters) ? spec.parameters : isRecord(spec.parameters) ? [spec.parameters] : [];
+  const inputs: IdpWorkflowInput[] = [];
+  for (const page of pages) {
+    if (!isRecord(page) || !isRecord(page.properties)) continue;
+    const required = new Set(Array.isArray(page.required) ? page.required.filter((r): r is string => typeof r === "string") : []);
+    for (const [name, prop] of Object.entries(page.properties)) {
+      const p = isRecord(prop) ? prop : {};
+      const uiField = typeof p["ui:field"] === "string" ? p["ui:field"] : undefined;
+      inputs.push({
+        name,
+        type: typeof p.type === "string" ? p.type : "string",
+        ...(typeof p.title === "string" ? { title: p.title } : {}),
+        ...(typeof p.description === "string" ? { description: p.description } : {}),
+        required: required.has(name),
+        ...(p.default !== undefined ? { default: p.default } : {}),
+        ...(Array.isArray(p.enum) ? { enum: p.enum } : {}),
+        ...(uiField ? { ui_field: uiField } : {}),
+        ...(uiField === "HarnessAuthToken" || autoFilled.has(name) ? { auto_filled: true as const } : {}),
+      });
+    }
+  }
+  return inputs;
 }
 
-/** Extract FME feature flag list — passthrough with trafficType.id flattened on each item. */
-export const fmeListExtract = (raw: unknown): unknown => {
-  if (raw && typeof raw === "object") {
-    const r = raw as Record<string, unknown>;
-    const objects = r.objects;
-    if (Array.isArray(objects)) {
-      for (const item of objects) {
-        if (item && typeof item === "object") {
-          flattenTrafficType(item as Record<string, unknown>);
-        }
+/** Workflow entity reduced to what a caller needs to execute it: identity, inputs, and steps. */
+export const idpWorkflowExtract = (raw: unknown): unknown => {
+  const entity = isRecord(raw) && isRecord(raw.data) && raw.yaml === undefined ? raw.data : raw;
+  if (!isRecord(entity)) return raw;
+  const doc = parseYamlRecord(entity.yaml);
+  const spec = isRecord(doc?.spec) ? doc.spec : {};
+  const inputs = idpWorkflowInputs(entity.yaml);
+  const identifier = entity.identifier ?? (isRecord(doc?.metadata) ? doc.metadata.name : undefined);
+  const steps = (Array.isArray(spec.steps) ? spec.steps : []).filter(isRecord).map((step) => ({
+    ...(typeof step.id === "string" ? { id: step.id } : {}),
+    ...(typeof step.name === "string" ? { name: step.name } : {}),
+    ...(typeof step.action === "string" ? { action: step.action } : {}),
+  }));
+  return {
+    identifier,
+    name: entity.name ?? entity.title ?? identifier,
+    ...(entity.description !== undefined ? { description: entity.description } : {}),
+    ...(entity.owner !== undefined ? { owner: entity.owner } : {}),
+    ...(entity.scope !== undefined ? { scope: entity.scope } : {}),
+    ...(entity.lifecycle !== undefined ? { lifecycle: entity.lifecycle } : {}),
+    inputs,
+    required_inputs: inputs.filter((i) => i.required && !i.auto_filled && i.default === undefined).map((i) => i.name),
+    steps,
+    ...(doc ? {} : { note: "The workflow has no readable YAML, so its inputs could not be listed." }),
+    _hint: `Run it with harness_execute(resource_type='idp_workflow', action='execute', resource_id='${String(identifier ?? "<workflow_id>")}', body={values: {...}}), then track it with harness_get(resource_type='idp_workflow_run', resource_id=<task_id>).`,
+  };
+};
+
+/** Execute response plus the task id to poll. */
+export const idpWorkflowExecuteExtract = (raw: unknown): unknown => {
+  const data = ngExtract(raw);
+  if (!isRecord(data)) return data;
+  const taskId = data.id ?? data.taskId ?? data.task_id ?? data.executionId;
+  if (typeof taskId !== "string" || !taskId) return data;
+  return {
+    ...data,
+    task_id: taskId,
+    _hint: `Track the run with harness_get(resource_type='idp_workflow_run', resource_id='${taskId}') until done is true.`,
+  };
+};
+
+const IDP_TASK_DONE = new Set(["completed", "failed", "cancelled", "skipped"]);
+
+/** Workflow run (scaffolder task) status with per-step state, the failed step, and any output links. */
+export const idpWorkflowRunExtract = (raw: unknown): unknown => {
+  const task = isRecord(raw) && isRecord(raw.data) && raw.status === undefined ? raw.data : raw;
+  if (!isRecord(task)) return raw;
+  const spec = isRecord(task.spec) ? task.spec : {};
+  const state = isRecord(task.state) ? task.state : {};
+  const stepStates = isRecord(state.steps) ? state.steps : {};
+  const status = typeof task.status === "string" ? task.status.toLowerCase() : "unknown";
+  const rawSteps = Array.isArray(task.steps) ? task.steps : Array.isArray(spec.steps) ? spec.steps : [];
+  const steps = rawSteps.filter(isRecord).map((step) => {
+    const id = typeof step.id === "string" ? step.id : undefined;
+    const s = id && isRecord(stepStates[id]) ? stepStates[id] : step;
+    return {
+      ...(id ? { id } : {}),
+      ...(typeof step.name === "string" ? { name: step.name } : {}),
+      ...(typeof step.action === "string" ? { action: step.action } : {}),
+      status: typeof s.status === "string" ? s.status.toLowerCase() : "open",
+      ...(s.startedAt !== undefined ? { started_at: s.startedAt } : {}),
+      ...(s.endedAt !== undefined ? { ended_at: s.endedAt } : {}),
+    };
+  });
+  const failed = steps.find((step) => step.status === "failed");
+  const output = isRecord(task.output) ? task.output : isRecord(state.output) ? state.output : undefined;
+  const done = IDP_TASK_DONE.has(status);
+  const workflow = isRecord(spec.templateInfo) ? spec.templateInfo.entityRef : undefined;
+  const error = isRecord(state.error) ? state.error.message : undefined;
+  return {
+    task_id: task.id,
+    ...(workflow !== undefined ? { workflow } : {}),
+    status,
+    done,
+    succeeded: status === "completed",
+    ...(task.createdAt !== undefined ? { created_at: task.createdAt } : {}),
+    ...(task.lastHeartbeatAt !== undefined ? { last_heartbeat_at: task.lastHeartbeatAt } : {}),
+    steps,
+    ...(failed ? { failed_step: failed.name ?? failed.id } : {}),
+    ...(typeof error === "string" ? { error } : {}),
+    ...(output ? { output } : {}),
+    ...(done ? {} : { _hint: "The workflow is still running. Get this run again to refresh its status." }),
+  };
+};
+
+/** Longest unified diff returned per resource by gitops_app_diff. */
+const GITOPS_DIFF_MAX_LINES = 200;
+
+/** Metadata the cluster writes on live objects; dropped from both sides so only real drift shows. */
+const K8S_SERVER_METADATA = ["managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"];
+const K8S_SERVER_ANNOTATIONS = ["kubectl.kubernetes.io/last-applied-configuration", "deployment.kubernetes.io/revision"];
+
+export type GitopsResourceDiffStatus = "out_of_sync" | "missing" | "extra" | "synced";
+
+const GITOPS_DIFF_ORDER: Record<GitopsResourceDiffStatus, number> = { out_of_sync: 0, missing: 1, extra: 2, synced: 3 };
+
+/** A manifest from a managed-resources entry: the agent sends them as JSON strings ("null" when absent). */
+function parseManifest(value: unknown): Record<string, unknown> | undefined {
+  if (isRecord(value)) return value;
+  if (typeof value !== "string" || !value.trim()) return undefined;
+  try {
+    const parsed = JSON.parse(value) as unknown;
+    return isRecord(parsed) ? parsed : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/** `manifest` without status and server-managed metadata, as key-sorted YAML. */
+function comparableManifest(manifest: Record<string, unknown> | undefined): string {
+  if (!manifest) return "";
+  const { status: _status, ...rest } = manifest;
+  if (isRecord(rest.metadata)) {
+    const metadata: Record<string, unknown> = { ...rest.metadata };
+    for (const key of K8S_SERVER_METADATA) delete metadata[key];
+    if (isRecord(metadata.annotations)) {
+      const annotations = { ...metadata.annotations };
+      for (const key of K8S_SERVER_ANNOTATIONS) delete annotations[key];
+      if (Object.keys(annotations).length > 0) metadata.annotations = annotations;
+      else delete metadata.annotations;
+    }
+    rest.metadata = metadata;
+  }
+  return YAML.stringify(rest, { sortMapEntries: true });
+}
+
+/**
+ * gitops_app_diff extractor: per managed resource, the diff from the live
+ * cluster state to the desired state rendered from Git. Desired is Argo CD's
+ * predicted live state when present (the target with defaults applied), and
+ * live is its normalized live state, so ignoreDifferences rules are honored.
+ * Synced resources are counted but only listed with include_synced.
+ */
+export const gitopsAppDiffExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const body = isRecord(raw) && isRecord(raw.data) ? raw.data : raw;
+  const entries = Array.isArray(body) ? body : isRecord(body) && Array.isArray(body.items) ? body.items : [];
+  const kind = typeof input?.kind === "string" ? input.kind.toLowerCase() : undefined;
+  const namespace = typeof input?.namespace === "string" ? input.namespace : undefined;
+  const name = typeof input?.resource_name === "string" ? input.resource_name : undefined;
+  const includeSynced = input?.include_synced === true || input?.include_synced === "true";
+  const summary: Record<GitopsResourceDiffStatus, number> = { out_of_sync: 0, missing: 0, extra: 0, synced: 0 };
+
+  const items = entries
+    .filter(isRecord)
+    .filter((entry) =>
+      (!kind || String(entry.kind ?? "").toLowerCase() === kind)
+      && (!namespace || entry.namespace === namespace)
+      && (!name || entry.name === name))
+    .map((entry) => {
+      const desired = parseManifest(entry.predictedLiveState) ?? parseManifest(entry.targetState);
+      const live = parseManifest(entry.normalizedLiveState) ?? parseManifest(entry.liveState);
+      const { diff, added, removed } = diffLines(comparableManifest(live), comparableManifest(desired), 3);
+      const status: GitopsResourceDiffStatus = !live && desired ? "missing"
+        : live && !desired ? "extra"
+        : entry.modified === true || added + removed > 0 ? "out_of_sync"
+        : "synced";
+      summary[status]++;
+      const lines = diff ? diff.split("\n") : [];
+      return {
+        kind: entry.kind ?? null,
+        group: entry.group || null,
+        namespace: entry.namespace || null,
+        name: entry.name ?? null,
+        status,
+        ...(entry.hook === true ? { hook: true } : {}),
+        lines_added: added,
+        lines_removed: removed,
+        ...(status === "synced" ? {} : { diff: lines.slice(0, GITOPS_DIFF_MAX_LINES).join("\n") }),
+        ...(lines.length > GITOPS_DIFF_MAX_LINES ? { truncated: true, diff_lines_total: lines.length } : {}),
+      };
+    })
+    .sort((a, b) =>
+      GITOPS_DIFF_ORDER[a.status] - GITOPS_DIFF_ORDER[b.status]
+      || String(a.kind).localeCompare(String(b.kind))
+      || String(a.name).localeCompare(String(b.name)));
+
+  const drifted = summary.out_of_sync + summary.missing + summary.extra;
+  const listed = includeSynced ? items : items.filter((item) => item.status !== "synced");
+  return {
+    ...(typeof input?.app_name === "string" ? { app_name: input.app_name } : {}),
+    ...(typeof input?.agent_id === "string" ? { agent_id: input.agent_id } : {}),
+    in_sync: drifted === 0,
+    summary: { total: items.length, ...summary },
+    items: listed,
+    ...(drifted > 0
+      ? { _hint: "Diffs run from live (-) to desired (+). 'missing' resources are in Git but not in the cluster; 'extra' ones are in the cluster but no longer in Git and would be pruned. Sync with harness_execute(resource_type='gitops_application', action='sync')." }
+      : {}),
+  };
+};
+
+/** Raw payloads gathered by gitops_app_history's collect hook. */
+export interface GitopsAppHistoryScan {
+  agent_id: string;
+  app_name: string;
+  /** gitops_application get response. */
+  app: unknown;
+  /** gitops_app_event list response; undefined when it failed to load. */
+  events?: unknown;
+  errors: string[];
+}
+
+type GitopsHistoryEntry = Record<string, unknown> & { at: string; type: "deployment" | "operation" | "health" | "sync" };
+
+const GITOPS_HISTORY_DEFAULT_LIMIT = 50;
+/** Warning events this close to a health change are reported as its likely cause. */
+const GITOPS_DEGRADED_WINDOW_MS = 10 * 60 * 1000;
+
+const GITOPS_STATUS_CHANGE = /^Updated (health|sync) status: (\S+) -> (\S+)/;
+const GITOPS_OPERATION_DONE = /^(\w+) operation to (\S+) (succeeded|failed|was terminated|errored)/i;
+const GITOPS_OPERATION_START = /^(.*?)\s*initiated (automated )?(\w+) to (.+)$/i;
+
+function isoTime(value: unknown): string | undefined {
+  if (typeof value !== "string" || !value) return undefined;
+  const ms = Date.parse(value);
+  return Number.isFinite(ms) ? new Date(ms).toISOString() : undefined;
+}
+
+function durationSeconds(start: string | undefined, end: string | undefined): number | undefined {
+  if (!start || !end) return undefined;
+  return Math.round((Date.parse(end) - Date.parse(start)) / 1000);
+}
+
+/** Who started an Argo CD operation: a username, "automated", or undefined. */
+function gitopsInitiator(initiatedBy: unknown): string | undefined {
+  if (!isRecord(initiatedBy)) return undefined;
+  if (initiatedBy.automated === true) return "automated";
+  return typeof initiatedBy.username === "string" && initiatedBy.username ? initiatedBy.username : undefined;
+}
+
+/** Timeline entries parsed from Argo CD application events. */
+function gitopsEventEntries(events: unknown): { entries: GitopsHistoryEntry[]; warnings: Array<{ at: string; reason?: string; message: string }> } {
+  const body = isRecord(events) && isRecord(events.data) ? events.data : events;
+  const items = Array.isArray(body) ? body : isRecord(body) && Array.isArray(body.items) ? body.items : [];
+  const entries: GitopsHistoryEntry[] = [];
+  const warnings: Array<{ at: string; reason?: string; message: string }> = [];
+  for (const event of items.filter(isRecord)) {
+    const at = isoTime(event.lastTimestamp) ?? isoTime(event.eventTime) ?? isoTime(event.firstTimestamp);
+    const message = typeof event.message === "string" ? event.message.trim() : "";
+    if (!at || !message) continue;
+    const reason = typeof event.reason === "string" ? event.reason : undefined;
+
+    const change = GITOPS_STATUS_CHANGE.exec(message);
+    if (change) {
+      entries.push({ at, type: change[1] === "health" ? "health" : "sync", from: change[2], to: change[3] });
+      continue;
+    }
+    const done = GITOPS_OPERATION_DONE.exec(message);
+    if (done) {
+      const detail = message.slice(done[0].length).replace(/^[:\s]+/, "");
+      entries.push({
+        at,
+        type: "operation",
+        operation: done[1]!.toLowerCase(),
+        phase: done[3]!.toLowerCase() === "succeeded" ? "Succeeded" : done[3]!.toLowerCase() === "failed" ? "Failed" : "Error",
+        revision: done[2],
+        ...(detail ? { message: detail } : {}),
+      });
+      continue;
+    }
+    const start = reason === "OperationStarted" ? GITOPS_OPERATION_START.exec(message) : null;
+    if (start) {
+      entries.push({
+        at,
+        type: "operation",
+        operation: start[3]!.toLowerCase(),
+        phase: "Started",
+        revision: start[4]!.replace(/^'|'$/g, ""),
+        initiator: start[2] ? "automated" : start[1] || null,
+      });
+      continue;
+    }
+    if (event.type === "Warning") warnings.push({ at, ...(reason ? { reason } : {}), message });
+  }
+  return { entries, warnings };
+}
+
+/**
+ * gitops_app_history extractor: the application's sync and health history as
+ * one timeline, newest first. Deployments come from Argo CD's status.history
+ * (successful syncs only, with revision, duration, and initiator); the last
+ * operation from status.operationState; failed syncs and health/sync status
+ * changes from application events. last_degraded pins the most recent change
+ * to Degraded to the deployment before it and the warnings around it.
+ */
+export const gitopsAppHistoryExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const scan = raw as GitopsAppHistoryScan;
+  const root = isRecord(scan.app) && isRecord(scan.app.app) ? scan.app.app : isRecord(scan.app) ? scan.app : {};
+  const status = isRecord(root.status) ? root.status : {};
+  const limit = Math.max(1, Math.trunc(Number(input?.limit)) || GITOPS_HISTORY_DEFAULT_LIMIT);
+
+  const deployments: GitopsHistoryEntry[] = (Array.isArray(status.history) ? status.history : [])
+    .filter(isRecord)
+    .flatMap((h) => {
+      const finished = isoTime(h.deployedAt);
+      if (!finished) return [];
+      const started = isoTime(h.deployStartedAt);
+      const revision = typeof h.revision === "string" ? h.revision : Array.isArray(h.revisions) ? h.revisions.join(",") : undefined;
+      return [{
+        at: finished,
+        type: "deployment" as const,
+        id: h.id ?? null,
+        revision: revision ?? null,
+        ...(started ? { started_at: started, duration_seconds: durationSeconds(started, finished) } : {}),
+        initiator: gitopsInitiator(h.initiatedBy) ?? null,
+      }];
+    });
+
+  const op = isRecord(status.operationState) ? status.operationState : undefined;
+  const opStarted = isoTime(op?.startedAt);
+  const opFinished = isoTime(op?.finishedAt);
+  const opRequest = isRecord(op?.operation) ? op.operation : {};
+  const opSync = isRecord(opRequest.sync) ? opRequest.sync : {};
+  const opResult = isRecord(op?.syncResult) ? op.syncResult : {};
+  const lastOperation = op
+    ? {
+      phase: op.phase ?? null,
+      ...(typeof op.message === "string" && op.message ? { message: op.message } : {}),
+      revision: opResult.revision ?? opSync.revision ?? null,
+      started_at: opStarted ?? null,
+      finished_at: opFinished ?? null,
+      ...(durationSeconds(opStarted, opFinished) !== undefined ? { duration_seconds: durationSeconds(opStarted, opFinished) } : {}),
+      initiator: gitopsInitiator(opRequest.initiatedBy) ?? null,
+      ...(typeof op.retryCount === "number" && op.retryCount > 0 ? { retry_count: op.retryCount } : {}),
+    }
+    : null;
+
+  const health = isRecord(status.health) ? status.health : {};
+  const sync = isRecord(status.sync) ? status.sync : {};
+  const { entries, warnings } = gitopsEventEntries(scan.events);
+  const timeline = [...deployments, ...entries].sort((a, b) => b.at.localeCompare(a.at));
+
+  const degraded = timeline.find((e) => e.type === "health" && e.to === "Degraded");
+  let lastDegraded: Record<string, unknown> | null = null;
+  if (degraded) {
+    const at = Date.parse(degraded.at);
+    const recovered = timeline.find((e) => e.type === "health" && e.from === "Degraded" && e.at > degraded.at);
+    const before = timeline.find((e) => e.at <= degraded.at && (e.type === "deployment" || (e.type === "operation" && e.phase !== "Started")));
+    lastDegraded = {
+      at: degraded.at,
+      from: degraded.from,
+      ...(recovered ? { recovered_at: recovered.at, recovered_to: recovered.to } : { ongoing: health.status === "Degraded" }),
+      after: before ?? null,
+      warnings: warnings.filter((w) => Math.abs(Date.parse(w.at) - at) <= GITOPS_DEGRADED_WINDOW_MS),
+    };
+  }
+
+  return {
+    app_name: scan.app_name,
+    agent_id: scan.agent_id,
+    current: {
+      sync_status: sync.status ?? null,
+      health_status: health.status ?? null,
+      ...(typeof health.message === "string" && health.message ? { health_message: health.message } : {}),
+      revision: sync.revision ?? null,
+    },
+    last_operation: lastOperation,
+    last_degraded: lastDegraded,
+    summary: {
+      deployments: deployments.length,
+      failed_operations: entries.filter((e) => e.type === "operation" && e.phase === "Failed").length,
+      health_changes: entries.filter((e) => e.type === "health").length,
+    },
+    timeline: timeline.slice(0, limit),
+    ...(timeline.length > limit ? { truncated: true, timeline_total: timeline.length } : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+    _hint: "Deployments are successful syncs kept by Argo CD (its revision history limit applies). Failed syncs and health changes come from application events, which the cluster keeps for a limited time (about an hour by default).",
+  };
+};
+
+/** Helm chart the GitOps agent is installed from. */
+const GITOPS_AGENT_HELM_REPO = "https://harness.github.io/gitops-helm/";
+
+/** Text of a YAML endpoint fetched as a buffer, unwrapping a JSON envelope if the API returned one. */
+function gitopsInstallText(raw: unknown): string {
+  const text = raw instanceof ArrayBuffer ? new TextDecoder().decode(raw) : typeof raw === "string" ? raw : "";
+  try {
+    const parsed: unknown = JSON.parse(text);
+    if (typeof parsed === "string") return parsed;
+    if (isRecord(parsed)) {
+      const inner = isRecord(parsed.data) ? parsed.data : parsed;
+      for (const key of ["valuesYaml", "yaml", "content"]) {
+        if (typeof inner[key] === "string") return inner[key] as string;
       }
+      if (typeof parsed.data === "string") return parsed.data;
+      return YAML.stringify(inner);
     }
+  } catch {
+    /* plain YAML */
   }
-  return raw;
+  return text;
+}
+
+/**
+ * gitops_agent_install extractor: the agent's installation manifest (format
+ * yaml) or Helm values override (format helm), with the commands that apply
+ * it. Both carry the agent's registration token, so with output_dir the
+ * content is written to an owner-only file and only its path is returned.
+ */
+export const gitopsAgentInstallExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const agentId = String(input?.agent_id ?? "");
+  const helm = input?.format === "helm";
+  const namespace = typeof input?.namespace === "string" && input.namespace ? input.namespace : "argocd";
+  const content = gitopsInstallText(raw);
+  const fileName = helm ? "override.yaml" : "gitops-agent.yaml";
+  const install = helm
+    ? [
+      `helm repo add gitops-agent ${GITOPS_AGENT_HELM_REPO}`,
+      "helm repo update gitops-agent",
+      `helm install argocd gitops-agent/gitops-helm --values ${fileName} --namespace ${namespace} --create-namespace`,
+    ]
+    : [
+      `kubectl create namespace ${namespace}`,
+      `kubectl apply -f ${fileName} -n ${namespace}`,
+    ];
+  const result = {
+    agent_id: agentId,
+    format: helm ? "helm" : "yaml",
+    namespace,
+    install,
+    _hint: `Run the install commands against the target cluster, then confirm the agent reports HEALTHY with harness_get(resource_type='gitops_agent', resource_id='${agentId}'). The ${helm ? "values" : "manifest"} contain the agent's registration token; store them as a secret.`,
+  };
+
+  const outputDir = typeof input?.output_dir === "string" ? input.output_dir.trim() : "";
+  if (!outputDir) return { ...result, [helm ? "helm_values" : "manifest"]: content };
+  const file = join(resolveToolOutputDir(outputDir), safeFileName(`${agentId}-${fileName}`));
+  writeOutputFile(file, content, { mode: 0o600 });
+  return { ...result, file, install: result.install.map((cmd) => cmd.replace(fileName, file)) };
 };
 
-/** Extract FME feature flag single item — passthrough with trafficType.id flattened. */
-export const fmeGetExtract = (raw: unknown): unknown => {
-  if (raw && typeof raw === "object") {
-    flattenTrafficType(raw as Record<string, unknown>);
+/**
+ * service_account_token create/rotate extractor. Harness returns the token
+ * value once, as `data`, and never again. It is returned with the ids needed
+ * to revoke or rotate it; with output_dir it is written to an owner-only file
+ * instead, so the secret stays out of the conversation.
+ */
+export const serviceAccountTokenExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
+  const value = isRecord(raw) && "data" in raw ? raw.data : raw;
+  const token = typeof value === "string" ? value : undefined;
+  const ids = {
+    service_account_id: input?.service_account_id ?? null,
+    api_key_id: input?.api_key_id ?? null,
+    token_id: input?.token_id ?? null,
+  };
+  if (!token) return { ...ids, token: null, response: value };
+
+  const hint = "The token value is shown only once. Store it in your secret manager now; " +
+    "revoke it with harness_delete(resource_type='service_account_token') or replace it with harness_execute(action='rotate').";
+  const outputDir = typeof input?.output_dir === "string" ? input.output_dir.trim() : "";
+  if (!outputDir) return { ...ids, token, _hint: hint };
+  const file = join(resolveToolOutputDir(outputDir), safeFileName(`${String(ids.service_account_id)}-${String(ids.token_id)}.token`));
+  writeOutputFile(file, token + "\n", { mode: 0o600 });
+  return { ...ids, file, _hint: `Token written to ${file} (mode 0600). ${hint}` };
+};
+
+/** Raw payloads gathered by deprecation_scan's collect hook. */
+export interface DeprecationScan {
+  org_id?: string;
+  project_id?: string;
+  /** Pipelines in scope with their YAML (yaml undefined when it failed to load). */
+  pipelines: Array<{ identifier: string; name?: string; link?: string; yaml?: unknown; error?: string }>;
+  pipelines_total: number;
+  /** Every version of every template visible from the scope, from template list (type All). */
+  templates: unknown[];
+  connectors: unknown[];
+  errors: string[];
+}
+
+export type DeprecationKind =
+  | "deprecated_step"
+  | "service_v1"
+  | "infrastructure_v1"
+  | "helm_v2"
+  | "legacy_connector"
+  | "unstable_template_version"
+  | "missing_template_version";
+
+interface DeprecationFinding {
+  kind: DeprecationKind;
+  construct: string;
+  location?: string;
+  replacement: string;
+}
+
+/** Step types Harness has superseded, with what to migrate to. */
+const DEPRECATED_STEP_TYPES = new Map<string, string>([
+  ["Security", "the scanner-specific STO step for the tool (e.g. Snyk, Trivy, SonarQube)"],
+  ["ServerlessAwsLambdaDeploy", "ServerlessAwsLambdaDeployV2, with ServerlessAwsLambdaPrepareRollbackV2 and ServerlessAwsLambdaPackageV2"],
+  ["ServerlessAwsLambdaRollback", "ServerlessAwsLambdaRollbackV2"],
+  ["JenkinsBuild", "JenkinsBuildV2"],
+]);
+
+/** Entity types in worklist order. */
+const DEPRECATION_ENTITY_ORDER: Record<string, number> = { pipeline: 0, template: 1, connector: 2 };
+
+/** Deprecated constructs in one pipeline or template YAML document. */
+function findDeprecatedConstructs(yaml: unknown): DeprecationFinding[] {
+  const findings: DeprecationFinding[] = [];
+  visitYaml(parseYamlRecord(yaml), "", (value, path) => {
+    if (!isRecord(value)) return;
+    const stepReplacement = typeof value.type === "string" ? DEPRECATED_STEP_TYPES.get(value.type) : undefined;
+    if (stepReplacement && isRecord(value.spec)) {
+      findings.push({ kind: "deprecated_step", construct: `${value.type} step`, location: path, replacement: stepReplacement });
+    }
+    if (value.type === "Deployment" && isRecord(value.spec)) {
+      if (value.spec.serviceConfig !== undefined) {
+        findings.push({ kind: "service_v1", construct: "serviceConfig (Service v1)", location: `${path}.spec.serviceConfig`, replacement: "service.serviceRef pointing at a v2 service with its serviceDefinition" });
+      }
+      if (isRecord(value.spec.infrastructure)) {
+        findings.push({ kind: "infrastructure_v1", construct: "infrastructure (Infrastructure v1)", location: `${path}.spec.infrastructure`, replacement: "environment.environmentRef with infrastructureDefinitions" });
+      }
+    }
+    if (value.helmVersion === "V2") {
+      findings.push({ kind: "helm_v2", construct: "helmVersion: V2", location: `${path}.helmVersion`, replacement: "helmVersion: V3 (Helm 2 is end of life)" });
+    }
+  });
+  return findings;
+}
+
+/** Legacy connector types and settings, or undefined when the connector is current. */
+function findLegacyConnector(connector: Record<string, unknown>): DeprecationFinding | undefined {
+  const spec = isRecord(connector.spec) ? connector.spec : {};
+  if (connector.type === "Codecommit") {
+    return { kind: "legacy_connector", construct: "AWS CodeCommit connector", replacement: "a Git connector for the repository's new host (AWS closed CodeCommit to new customers)" };
   }
-  return raw;
+  if (connector.type === "Nexus" && typeof spec.version === "string" && spec.version.startsWith("2")) {
+    return { kind: "legacy_connector", construct: `Nexus ${spec.version} connector`, replacement: "a Nexus 3.x connector (Nexus Repository 2 is end of life)" };
+  }
+  return undefined;
+}
+
+function templateKey(template: Record<string, unknown>): string | undefined {
+  if (typeof template.identifier !== "string") return undefined;
+  const level = String(template.templateScope ?? (template.projectIdentifier ? "project" : template.orgIdentifier ? "org" : "account")).toLowerCase();
+  return level === "project" ? template.identifier : `${level}.${template.identifier}`;
+}
+
+/**
+ * deprecation_scan extractor: a migration worklist of the pipelines,
+ * templates, and connectors in scope that use deprecated constructs
+ * (superseded step types, Service/Infrastructure v1, Helm 2, legacy connector
+ * types) or pin template versions that are not stable or no longer exist.
+ * Entities with the most findings come first within each type.
+ */
+export const deprecationScanExtract = (raw: unknown): unknown => {
+  const scan = raw as DeprecationScan;
+  const versions = new Map<string, { stable?: string; labels: Set<string>; link?: string }>();
+  const templateEntries: Array<{ template: Record<string, unknown>; key: string }> = [];
+  for (const template of scan.templates.filter(isRecord)) {
+    const key = templateKey(template);
+    if (!key) continue;
+    const entry = versions.get(key) ?? { labels: new Set<string>() };
+    if (typeof template.versionLabel === "string") entry.labels.add(template.versionLabel);
+    if (template.stableTemplate === true && typeof template.versionLabel === "string") {
+      entry.stable = template.versionLabel;
+      if (typeof template.openInHarness === "string") entry.link = template.openInHarness;
+    }
+    entry.link ??= typeof template.openInHarness === "string" ? template.openInHarness : undefined;
+    versions.set(key, entry);
+    templateEntries.push({ template, key });
+  }
+
+  const templateFindings = (yaml: unknown): DeprecationFinding[] => findTemplateRefs(yaml).flatMap((ref) => {
+    const known = versions.get(ref.ref);
+    if (!ref.version || !known) return [];
+    if (!known.labels.has(ref.version)) {
+      return [{ kind: "missing_template_version" as const, construct: `${ref.ref}@${ref.version}`, replacement: known.stable ? `version ${known.stable} (stable)` : "an existing version" }];
+    }
+    if (known.stable && known.stable !== ref.version) {
+      return [{ kind: "unstable_template_version" as const, construct: `${ref.ref}@${ref.version}`, replacement: `version ${known.stable} (stable), or drop versionLabel to follow the stable version` }];
+    }
+    return [];
+  });
+
+  const worklist: Array<Record<string, unknown> & { entity_type: string; findings: DeprecationFinding[] }> = [];
+  for (const pipeline of scan.pipelines) {
+    const findings = [...findDeprecatedConstructs(pipeline.yaml), ...templateFindings(pipeline.yaml)];
+    if (findings.length === 0) continue;
+    worklist.push({
+      entity_type: "pipeline",
+      identifier: pipeline.identifier,
+      ...(pipeline.name ? { name: pipeline.name } : {}),
+      ...(pipeline.link ? { link: pipeline.link } : {}),
+      findings,
+    });
+  }
+  for (const { template, key } of templateEntries) {
+    const findings = [...findDeprecatedConstructs(template.yaml), ...templateFindings(template.yaml)];
+    if (findings.length === 0) continue;
+    worklist.push({
+      entity_type: "template",
+      identifier: key,
+      ...(typeof template.name === "string" ? { name: template.name } : {}),
+      ...(typeof template.versionLabel === "string" ? { version: template.versionLabel } : {}),
+      ...(template.stableTemplate === true ? { stable: true } : {}),
+      ...(typeof template.openInHarness === "string" ? { link: template.openInHarness } : {}),
+      findings,
+    });
+  }
+  for (const item of scan.connectors.filter(isRecord)) {
+    const connector = isRecord(item.connector) ? item.connector : item;
+    const finding = findLegacyConnector(connector);
+    if (!finding) continue;
+    const level = connector.projectIdentifier ? "" : connector.orgIdentifier ? "org." : "account.";
+    worklist.push({
+      entity_type: "connector",
+      identifier: `${level}${String(connector.identifier)}`,
+      ...(typeof connector.name === "string" ? { name: connector.name } : {}),
+      ...(typeof item.openInHarness === "string" ? { link: item.openInHarness } : {}),
+      findings: [finding],
+    });
+  }
+  worklist.sort((a, b) =>
+    (DEPRECATION_ENTITY_ORDER[a.entity_type] ?? 9) - (DEPRECATION_ENTITY_ORDER[b.entity_type] ?? 9)
+    || b.findings.length - a.findings.length
+    || String(a.identifier).localeCompare(String(b.identifier)));
+
+  const byKind: Partial<Record<DeprecationKind, number>> = {};
+  for (const entry of worklist) for (const f of entry.findings) byKind[f.kind] = (byKind[f.kind] ?? 0) + 1;
+  const truncated = scan.pipelines_total > scan.pipelines.length;
+  return {
+    ...(scan.org_id ? { org_id: scan.org_id } : {}),
+    ...(scan.project_id ? { project_id: scan.project_id } : {}),
+    scanned: { pipelines: scan.pipelines.length, templates: templateEntries.length, connectors: scan.connectors.length },
+    summary: { entities: worklist.length, findings: Object.values(byKind).reduce((n, c) => n + c, 0), by_kind: byKind },
+    worklist,
+    ...(truncated
+      ? { truncated: true, note: `Only ${scan.pipelines.length} of ${scan.pipelines_total} pipelines were scanned. Raise max_pipelines to scan more.` }
+      : {}),
+    ...(scan.errors.length > 0 ? { errors: scan.errors } : {}),
+  };
+};
+
+/** Raw results gathered by gitops_application sync_applications. */
+export interface GitopsBulkSyncScan {
+  selector?: string;
+  /** Apps the selector matched, or the explicit targets, in order. */
+  targets: Array<{ agent_id: string; app_name: string }>;
+  /** Sync response per target, by index. */
+  results: Array<{ index: number; response: unknown }>;
+  /** Sync failure per target, by index. */
+  failures: Array<{ index: number; error: string }>;
+  dry_run: boolean;
+}
+
+/**
+ * Consolidated status for gitops_application sync_applications: one row per
+ * app with whether the sync was accepted and the sync/health/operation state
+ * the agent reported back, counts per outcome, and the same rows as a
+ * Markdown table for reporting an environment-wide promotion.
+ */
+export const gitopsBulkSyncExtract = (raw: unknown): unknown => {
+  const scan = raw as GitopsBulkSyncScan;
+  const responses = new Map(scan.results.map((r) => [r.index, r.response]));
+  const failures = new Map(scan.failures.map((f) => [f.index, f.error]));
+  const rows = scan.targets.map((target, index) => {
+    const error = failures.get(index);
+    if (error !== undefined) return { ...target, result: "failed", sync: null, health: null, phase: null, revision: null, error };
+    const response = responses.get(index);
+    const app = isRecord(response) && isRecord(response.app) ? response.app : isRecord(response) ? response : {};
+    const status = isRecord(app.status) ? app.status : {};
+    const sync = isRecord(status.sync) ? status.sync : {};
+    const health = isRecord(status.health) ? status.health : {};
+    const operation = isRecord(status.operationState) ? status.operationState : isRecord(app.operationState) ? app.operationState : {};
+    const syncResult = isRecord(operation.syncResult) ? operation.syncResult : {};
+    const str = (v: unknown): string | null => (typeof v === "string" && v ? v : null);
+    return {
+      ...target,
+      result: scan.dry_run ? "dry_run" : "triggered",
+      sync: str(sync.status),
+      health: str(health.status),
+      phase: str(operation.phase),
+      revision: str(syncResult.revision) ?? str(sync.revision),
+      error: str(operation.phase) === "Failed" || str(operation.phase) === "Error" ? str(operation.message) : null,
+    };
+  });
+  const summary = {
+    requested: rows.length,
+    triggered: rows.filter((r) => r.result !== "failed").length,
+    failed: rows.filter((r) => r.result === "failed").length,
+  };
+  const cell = (v: unknown) => (v === null || v === undefined ? "" : String(v).replace(/\|/g, "\\|").replace(/\s+/g, " "));
+  const table = [
+    "| Agent | Application | Result | Sync | Health | Phase | Error |",
+    "| --- | --- | --- | --- | --- | --- | --- |",
+    ...rows.map((r) => `| ${cell(r.agent_id)} | ${cell(r.app_name)} | ${cell(r.result)} | ${cell(r.sync)} | ${cell(r.health)} | ${cell(r.phase)} | ${cell(r.error)} |`),
+  ].join("\n");
+  return {
+    ...(scan.selector !== undefined ? { label_selector: scan.selector } : {}),
+    ...(scan.dry_run ? { dry_run: true } : {}),
+    summary,
+    applications: rows,
+    table,
+    _hint: summary.failed > 0
+      ? "Some syncs were not accepted; see error per row. Retry just those with body.targets, or check the app with harness_diagnose."
+      : "Syncs run asynchronously. Re-check progress with harness_get(resource_type='gitops_app_history') per app or harness_list(resource_type='gitops_application').",
+  };
+};
+
+/** Raw batches gathered by gitops_pod_log's collect hook: the first fetch, then one per follow poll. */
+export interface GitopsPodLogScan {
+  agent_id: string;
+  app_name: string;
+  pod_name?: string;
+  container?: string;
+  previous: boolean;
+  /** Each logs response as returned (ArrayBuffer, text, or parsed JSON). */
+  batches: unknown[];
+  follow?: { polls: number; duration_ms: number };
+}
+
+/** Cap on lines returned by gitops_pod_log; the newest are kept. */
+const GITOPS_POD_LOG_MAX_LINES = 2000;
+
+/**
+ * Log entries from one Argo CD logs response. The agent streams one JSON
+ * object per line (`{"result": {"content", "timeStamp", "podName"}}`), but a
+ * single object, an array, or plain text are accepted too.
+ */
+function gitopsLogEntries(batch: unknown): Array<{ content: string; at?: string; pod?: string }> {
+  let value: unknown = batch;
+  if (batch instanceof ArrayBuffer) value = new TextDecoder().decode(batch);
+  if (typeof value === "string") {
+    const text = value.trim();
+    if (!text) return [];
+    try {
+      value = JSON.parse(text);
+    } catch {
+      const parsed = text.split("\n").map((line) => {
+        try {
+          return JSON.parse(line) as unknown;
+        } catch {
+          return line;
+        }
+      });
+      return parsed.flatMap(gitopsLogEntries);
+    }
+  }
+  if (Array.isArray(value)) return value.flatMap(gitopsLogEntries);
+  if (typeof value === "string") return [{ content: value }];
+  if (!isRecord(value)) return [];
+  const entry = isRecord(value.result) ? value.result : value;
+  if (Array.isArray(entry.logs)) return entry.logs.flatMap(gitopsLogEntries);
+  if (typeof entry.content !== "string") return [];
+  if (entry.last === true && entry.content === "") return [];
+  return [{
+    content: entry.content,
+    ...(typeof entry.timeStamp === "string" ? { at: entry.timeStamp } : typeof entry.timeStampStr === "string" ? { at: entry.timeStampStr } : {}),
+    ...(typeof entry.podName === "string" ? { pod: entry.podName } : {}),
+  }];
+}
+
+/**
+ * Pod logs for gitops_pod_log as plain lines. Follow polls overlap by a
+ * second, so entries repeated across batches (same timestamp and text) are
+ * dropped. Lines carry their timestamp, and the pod name when logs span pods.
+ */
+export const gitopsPodLogExtract = (raw: unknown): unknown => {
+  const scan = raw as GitopsPodLogScan;
+  const seen = new Set<string>();
+  const entries: Array<{ content: string; at?: string; pod?: string }> = [];
+  for (const batch of scan.batches) {
+    for (const entry of gitopsLogEntries(batch)) {
+      const key = `${entry.at ?? ""}\u0000${entry.pod ?? ""}\u0000${entry.content}`;
+      // Without timestamps identical lines are legitimate repeats within a batch.
+      if (entry.at !== undefined && seen.has(key)) continue;
+      seen.add(key);
+      entries.push(entry);
+    }
+  }
+  const pods = new Set(entries.map((e) => e.pod).filter(Boolean));
+  const lines = entries.map((e) => [e.at, pods.size > 1 && e.pod ? `[${e.pod}]` : undefined, e.content].filter((p) => p !== undefined).join(" "));
+  const truncated = lines.length > GITOPS_POD_LOG_MAX_LINES;
+  return {
+    agent_id: scan.agent_id,
+    app_name: scan.app_name,
+    ...(scan.pod_name ? { pod_name: scan.pod_name } : {}),
+    ...(scan.container ? { container: scan.container } : {}),
+    ...(scan.previous ? { previous: true } : {}),
+    line_count: lines.length,
+    lines: truncated ? lines.slice(-GITOPS_POD_LOG_MAX_LINES) : lines,
+    ...(truncated ? { truncated: true } : {}),
+    ...(scan.follow ? { follow: scan.follow } : {}),
+    ...(lines.length === 0
+      ? { _hint: scan.previous
+        ? "No output from the previous container — it may not have restarted yet."
+        : "No log lines. For a crash-looping pod, retry with previous=true to read the crashed container, or follow=true to wait for output." }
+      : {}),
+  };
+};
+
+/** What gitops_applicationset preview's collect hook gathered for the extractor. */
+export interface GitopsAppSetPreviewScan {
+  agent_id: string;
+  appset_name?: string;
+  render: AppSetRenderResult;
+  /** Application names the existing ApplicationSet owns now; absent when previewing a new one. */
+  current_apps?: string[];
+  /** spec.syncPolicy of the proposed ApplicationSet. */
+  sync_policy?: Record<string, unknown>;
+}
+
+/** Cap on generated applications listed by gitops_applicationset preview. */
+const APPSET_PREVIEW_MAX_APPS = 500;
+
+/**
+ * Blast radius for gitops_applicationset preview: the Applications the
+ * proposed ApplicationSet renders to (name, project, destination, source),
+ * and when it already exists, which apps it would create, delete, or keep.
+ * Deletions account for applicationsSync and preserveResourcesOnDeletion.
+ */
+export const gitopsAppSetPreviewExtract = (raw: unknown): unknown => {
+  const scan = raw as GitopsAppSetPreviewScan;
+  const str = (v: unknown): string | null => (typeof v === "string" && v ? v : null);
+  const rows = scan.render.applications.map((app) => {
+    const metadata = isRecord(app.metadata) ? app.metadata : {};
+    const spec = isRecord(app.spec) ? app.spec : {};
+    const destination = isRecord(spec.destination) ? spec.destination : {};
+    const source = isRecord(spec.source) ? spec.source
+      : Array.isArray(spec.sources) && isRecord(spec.sources[0]) ? spec.sources[0]
+      : {};
+    return {
+      name: str(metadata.name),
+      project: str(spec.project),
+      namespace: str(metadata.namespace),
+      destination: {
+        server: str(destination.server),
+        name: str(destination.name),
+        namespace: str(destination.namespace),
+      },
+      source: {
+        repoURL: str(source.repoURL),
+        path: str(source.path),
+        chart: str(source.chart),
+        targetRevision: str(source.targetRevision),
+      },
+    };
+  });
+
+  const warnings = [...scan.render.warnings];
+  const names = rows.map((r) => r.name).filter((n): n is string => n !== null);
+  const duplicates = [...new Set(names.filter((n, i) => names.indexOf(n) !== i))];
+  if (duplicates.length > 0) {
+    warnings.push(`Duplicate application names ${duplicates.join(", ")}: Argo CD rejects an ApplicationSet that generates the same name twice.`);
+  }
+  if (rows.some((r) => r.name === null)) warnings.push("Some generated applications have no metadata.name.");
+
+  let blastRadius: Record<string, unknown> | undefined;
+  if (scan.current_apps) {
+    const generated = new Set(names);
+    const current = new Set(scan.current_apps);
+    const policy = scan.sync_policy ?? {};
+    const applicationsSync = str(policy.applicationsSync);
+    const deletes = applicationsSync !== "create-only" && applicationsSync !== "create-update";
+    const toDelete = scan.current_apps.filter((n) => !generated.has(n));
+    blastRadius = {
+      to_create: [...generated].filter((n) => !current.has(n)),
+      to_delete: deletes ? toDelete : [],
+      kept: [...generated].filter((n) => current.has(n)),
+      ...(!deletes && toDelete.length > 0 ? { orphaned: toDelete } : {}),
+      ...(applicationsSync ? { applications_sync: applicationsSync } : {}),
+      ...(policy.preserveResourcesOnDeletion === true ? { preserve_resources_on_deletion: true } : {}),
+    };
+  }
+
+  const truncated = rows.length > APPSET_PREVIEW_MAX_APPS;
+  const shown = truncated ? rows.slice(0, APPSET_PREVIEW_MAX_APPS) : rows;
+  const cell = (v: unknown) => (v === null || v === undefined ? "" : String(v).replace(/\|/g, "\\|").replace(/\s+/g, " "));
+  const table = [
+    "| Application | Project | Destination | Namespace | Source |",
+    "| --- | --- | --- | --- | --- |",
+    ...shown.map((r) => `| ${cell(r.name)} | ${cell(r.project)} | ${cell(r.destination.server ?? r.destination.name)} | ${cell(r.destination.namespace)} | ${cell([r.source.repoURL, r.source.path ?? r.source.chart].filter(Boolean).join(" "))} |`),
+  ].join("\n");
+
+  const toDelete = blastRadius ? (blastRadius.to_delete as string[]) : [];
+  return {
+    agent_id: scan.agent_id,
+    ...(scan.appset_name ? { appset_name: scan.appset_name } : {}),
+    generated_count: rows.length,
+    applications: shown,
+    ...(truncated ? { truncated: true } : {}),
+    ...(blastRadius ? { blast_radius: blastRadius } : {}),
+    ...(scan.render.unrendered.length > 0 ? { unrendered_generators: scan.render.unrendered } : {}),
+    ...(warnings.length > 0 ? { warnings } : {}),
+    table,
+    _hint: scan.render.unrendered.length > 0
+      ? "Some generators could not be expanded offline, so the real ApplicationSet generates more applications than listed here."
+      : toDelete.length > 0
+        ? `Applying this change deletes ${toDelete.length} application(s)${blastRadius?.preserve_resources_on_deletion ? " (their cluster resources are preserved)" : " and their cluster resources"}. Review blast_radius.to_delete before updating.`
+        : "Preview only — nothing was changed. Apply with harness_update(resource_type='gitops_applicationset') or harness_create.",
+  };
 };
@@ -7,7 +7,14 @@ import type { AuditManager } from "../audit/manager.js";
 import type { AuditContext, AuditEvent, AuditOutcome } from "../audit/types.js";
 import { createLogger } from "../utils/logger.js";
 import { buildDeepLink, appendStoreType } from "../utils/deep-links.js";
-import { isFormDataBody } from "../utils/type-guards.js";
+import { getConversationId } from "../utils/conversation-context.js";
+import { getRequestSignal } from "../utils/request-signal.js";
+import { detectScopeEscalation, describeScopeEscalation, isCrossScopeAllowed } from "../utils/scope-guard.js";
+import { migrationHint, recordDeprecatedUsage } from "../utils/deprecation-tracker.js";
+import { createResponseCache, isCacheBypassed, type ResponseCache } from "../utils/response-cache.js";
+import { asString, isFormDataBody, isRecord } from "../utils/type-guards.js";
+import { applyDeveloperPrivacy } from "../utils/developer-privacy.js";
+import { createUndoLog, type UndoLog, type UndoPlan } from "../utils/undo-log.js";
 
 // Import all toolsets
 import { pipelinesToolset } from "./toolsets/pipelines.js";
@@ -123,6 +130,35 @@ function getExplicitScopeValues(scope: ResourceScope, input: Record<string, unkn
   return { orgId, projectId };
 }
 
+/**
+ * Attach guardrail notes (`_scopeWarning`, `_undo`) to an object result;
+ * undefined notes are skipped and other result shapes pass through unchanged.
+ */
+function annotateResult(result: unknown, notes: Record<string, unknown>): unknown {
+  if (result && typeof result === "object" && !Array.isArray(result)) {
+    for (const [key, value] of Object.entries(notes)) {
+      if (value !== undefined) (result as Record<string, unknown>)[key] = value;
+    }
+  }
+  return result;
+}
+
+/** State read before an update or delete for the undo log. */
+interface UndoSnapshot {
+  prior?: unknown;
+  /** Why no prior state was captured. */
+  note?: string;
+}
+
+/** Identifier of a created entity from its body or the create response (`{identifier}` or `{service: {identifier}}`). */
+function createdIdentifier(value: unknown): string | undefined {
+  if (!isRecord(value)) return undefined;
+  const direct = asString(value.identifier);
+  if (direct) return direct;
+  const nested = Object.values(value).filter(isRecord);
+  return nested.length === 1 ? asString(nested[0]!.identifier) : undefined;
+}
+
 const ALL_TOOLSETS: ToolsetDefinition[] = [
   pipelinesToolset,
   agentsToolset,
@@ -183,6 +219,20 @@ export interface RegistryOptions {
   accountIdResolver?: () => string | undefined;
   /** When provided, every dispatch emits an AuditEvent to all registered sinks. */
   auditManager?: AuditManager;
+  /**
+   * Toolsets the session is entitled to (from a signed HTTP bearer token).
+   * Applied after HARNESS_TOOLSETS, so it can only narrow the enabled set.
+   * Aliases are resolved; unknown names are ignored.
+   */
+  entitledToolsets?: ReadonlySet<string>;
+  /**
+   * Cache for read-only list/get results. Defaults to one built from
+   * HARNESS_CACHE_TTL_MS / HARNESS_CACHE_MAX_ENTRIES / HARNESS_CACHE_TOOLSETS
+   * (disabled when the TTL is 0).
+   */
+  responseCache?: ResponseCache;
+  /** Undo log for writes. Defaults to one built from HARNESS_UNDO_LOG (off unless set). */
+  undoLog?: UndoLog;
 }
 
 /**
@@ -193,18 +243,29 @@ export class Registry {
   private toolsets: ToolsetDefinition[] = [];
   private accountIdResolver?: () => string | undefined;
   private auditManager?: AuditManager;
+  private responseCache?: ResponseCache;
+  /** Writes made through this registry, for undo_last_change. */
+  readonly undoLog?: UndoLog;
 
   constructor(private config: Config, options: RegistryOptions = {}) {
     this.accountIdResolver = options.accountIdResolver;
     this.auditManager = options.auditManager;
+    this.responseCache = options.responseCache ?? createResponseCache(config);
+    this.undoLog = options.undoLog ?? createUndoLog(config);
     const allToolsets = [...ALL_TOOLSETS, ...(options.additionalToolsets ?? [])];
     const enabledNames = this.parseToolsetFilter(allToolsets);
     this.toolsets = enabledNames
       ? allToolsets.filter((t) => enabledNames.has(t.name))
       : allToolsets.filter((t) => !t.optIn);
+    if (options.entitledToolsets) {
+      const entitled = new Set([...options.entitledToolsets].map((name) => this.resolveToolsetAlias(name, "bearer-token")));
+      this.toolsets = this.toolsets.filter((t) => entitled.has(t.name));
+    }
 
+    const developerMetricsOff = config.HARNESS_SEI_DEVELOPER_METRICS === "off";
     for (const toolset of this.toolsets) {
       for (const resource of toolset.resources) {
+        if (resource.developerMetrics && developerMetricsOff) continue;
         this.resourceMap.set(resource.resourceType, resource);
       }
     }
@@ -218,6 +279,15 @@ export class Registry {
     return this.accountIdResolver?.() ?? this.config.HARNESS_ACCOUNT_ID;
   }
 
+  /** Resolve a renamed toolset to its current name, counting uses of the old name. */
+  private resolveToolsetAlias(name: string, source: string): string {
+    const current = TOOLSET_ALIASES[name];
+    if (!current) return name;
+    recordDeprecatedUsage("toolset", name, current, source);
+    log.warn(migrationHint(name, current));
+    return current;
+  }
+
   /**
    * Parse HARNESS_TOOLSETS env var. Supports three modes:
    *
@@ -245,7 +315,7 @@ export class Registry {
       for (const token of parsed) {
         const op = token[0];
         const rawName = (op === "+" || op === "-") ? token.slice(1) : token;
-        const name = TOOLSET_ALIASES[rawName] ?? rawName;
+        const name = this.resolveToolsetAlias(rawName, "HARNESS_TOOLSETS");
         if (!validNames.has(name)) {
           invalid.push(rawName);
           continue;
@@ -274,7 +344,7 @@ export class Registry {
     const invalid: string[] = [];
 
     for (const rawName of parsed) {
-      const name = TOOLSET_ALIASES[rawName] ?? rawName;
+      const name = this.resolveToolsetAlias(rawName, "HARNESS_TOOLSETS");
       if (validNames.has(name)) {
         valid.push(name);
       } else {
@@ -374,7 +444,7 @@ export class Registry {
     signal?: AbortSignal,
   ): Promise<unknown> {
     const auditCtx = signalOrAudit instanceof AbortSignal ? undefined : signalOrAudit;
-    const abortSignal = signalOrAudit instanceof AbortSignal ? signalOrAudit : signal;
+    const abortSignal = (signalOrAudit instanceof AbortSignal ? signalOrAudit : signal) ?? getRequestSignal();
 
     if (this.config.HARNESS_READ_ONLY && !Registry.READ_OPERATIONS.has(operation)) {
       throw new Error(`Read-only mode is enabled (HARNESS_READ_ONLY=true). "${operation}" operations are not allowed.`);
@@ -412,7 +482,36 @@ export class Registry {
       }
     }
 
-    return this.executeSpecWithAudit(client, def, spec, operation, resourceType, input, auditCtx, abortSignal);
+    const scopeWarning = this.guardScope(def, resourceType, operation, input, auditCtx);
+
+    // Read-only calls may be answered from the response cache; writes clear it.
+    const cache = Registry.READ_OPERATIONS.has(operation) && !spec.skipCache && this.responseCache?.isEnabledFor(def.toolset)
+      ? this.responseCache
+      : undefined;
+    const cacheKey = cache?.key(client.account, resourceType, operation, input);
+    if (cache && cacheKey) {
+      const cached = cache.get(def.toolset, cacheKey, isCacheBypassed(input));
+      if (cached !== undefined) {
+        const output = spec.writeOutput ? spec.writeOutput(cached, input) : cached;
+        return annotateResult(output, { _scopeWarning: scopeWarning });
+      }
+    }
+
+    const undoSnapshot = this.undoLog?.recording && !Registry.READ_OPERATIONS.has(operation) && !spec.skipUndoLog
+      ? await this.captureUndoSnapshot(client, def, operation, input, abortSignal)
+      : undefined;
+    const response = await this.executeSpecWithAudit(client, def, spec, operation, resourceType, input, auditCtx, abortSignal);
+    const result = def.developerMetrics
+      ? applyDeveloperPrivacy(response, this.config.HARNESS_SEI_DEVELOPER_METRICS ?? "aggregate", this.config.HARNESS_SEI_MIN_GROUP_SIZE ?? 5)
+      : response;
+    if (cache && cacheKey) {
+      cache.set(def.toolset, cacheKey, result);
+    } else if (!Registry.READ_OPERATIONS.has(operation)) {
+      this.responseCache?.invalidate();
+    }
+    const undo = undoSnapshot ? this.recordUndo(def, operation, input, result, undoSnapshot, auditCtx) : undefined;
+    const output = spec.writeOutput ? spec.writeOutput(result, input) : result;
+    return annotateResult(output, { _scopeWarning: scopeWarning, _undo: undo });
   }
 
   /** Dispatch an execute action to the Harness API. */
@@ -425,7 +524,7 @@ export class Registry {
     signal?: AbortSignal,
   ): Promise<unknown> {
     const auditCtx = signalOrAudit instanceof AbortSignal ? undefined : signalOrAudit;
-    const abortSignal = signalOrAudit instanceof AbortSignal ? signalOrAudit : signal;
+    const abortSignal = (signalOrAudit instanceof AbortSignal ? signalOrAudit : signal) ?? getRequestSignal();
 
     const def = this.getResource(resourceType);
     const actionSpec = def.executeActions?.[action];
@@ -438,7 +537,118 @@ export class Registry {
       throw new Error(`Read-only mode is enabled (HARNESS_READ_ONLY=true). Execute action "${action}" is not allowed.`);
     }
 
-    return this.executeSpecWithAudit(client, def, actionSpec, "execute", resourceType, input, { ...auditCtx, tool: auditCtx?.tool ?? "harness_execute", action }, abortSignal);
+    const executeAuditCtx: AuditContext = { ...auditCtx, tool: auditCtx?.tool ?? "harness_execute", action };
+    const scopeWarning = this.guardScope(def, resourceType, "execute", input, executeAuditCtx);
+    const result = await this.executeSpecWithAudit(client, def, actionSpec, "execute", resourceType, input, executeAuditCtx, abortSignal);
+    let undo: { entry_id: string; undoable: boolean } | undefined;
+    if (actionSpec.operationPolicy.risk !== "read") {
+      this.responseCache?.invalidate();
+      if (this.undoLog?.recording && !actionSpec.skipUndoLog) {
+        const entry = this.undoLog.record({
+          resource_type: resourceType,
+          operation: "execute",
+          action,
+          resource_id: auditCtx?.resource_id ?? asString(input.resource_id),
+          not_undoable: "Execute actions are not reversed from the undo log.",
+        });
+        undo = { entry_id: entry.entry_id, undoable: false };
+      }
+    }
+    return annotateResult(result, { _scopeWarning: scopeWarning, _undo: undo });
+  }
+
+  /**
+   * Read the entity an update or delete is about to change, for the undo log.
+   * Only resources with `restoreBody` are read. A failed read never blocks the
+   * write; the entry just records why it cannot be undone.
+   */
+  private async captureUndoSnapshot(
+    client: HarnessClient,
+    def: ResourceDefinition,
+    operation: OperationName,
+    input: Record<string, unknown>,
+    signal?: AbortSignal,
+  ): Promise<UndoSnapshot> {
+    if (operation !== "update" && operation !== "delete") return {};
+    const getSpec = def.operations.get;
+    if (!def.restoreBody || !getSpec) return { note: `Prior state is not captured for ${def.resourceType}.` };
+    const { body: _body, ...getInput } = input;
+    try {
+      return { prior: await this.executeSpec(client, def, getSpec, getInput, signal) };
+    } catch (err) {
+      log.warn("Undo snapshot read failed", { resourceType: def.resourceType, operation, error: String(err) });
+      return { note: `Could not read the ${def.resourceType} before the ${operation}: ${err instanceof Error ? err.message : String(err)}` };
+    }
+  }
+
+  /**
+   * Record a successful create/update/delete with the call that reverses it:
+   * delete what a create made, or put back the state read before an update or
+   * delete through `restoreBody`.
+   */
+  private recordUndo(
+    def: ResourceDefinition,
+    operation: OperationName,
+    input: Record<string, unknown>,
+    result: unknown,
+    snapshot: UndoSnapshot,
+    auditCtx: AuditContext | undefined,
+  ): { entry_id: string; undoable: boolean } {
+    const primaryField = def.identifierFields[def.identifierFields.length - 1];
+    const { body, ...target } = input;
+    let resourceId = auditCtx?.resource_id ?? (primaryField ? asString(input[primaryField]) : undefined) ?? asString(input.resource_id);
+    let undo: UndoPlan | undefined;
+    let note = snapshot.note;
+    if (operation === "create") {
+      resourceId ??= createdIdentifier(body) ?? createdIdentifier(result);
+      if (!def.operations.delete) note = `${def.resourceType} has no delete operation to reverse the create.`;
+      else if (!resourceId || !primaryField) note = "The identifier of the created entity is unknown.";
+      else undo = { operation: "delete", input: { ...target, [primaryField]: resourceId } };
+    } else if (snapshot.prior !== undefined) {
+      const restoreOperation = operation === "delete" ? "create" : "update";
+      const restoreBody = def.restoreBody?.(snapshot.prior);
+      if (restoreBody === undefined) note = `The ${def.resourceType} read before the ${operation} had no restorable state.`;
+      else if (!def.operations[restoreOperation]) note = `${def.resourceType} has no ${restoreOperation} operation to reverse the ${operation}.`;
+      else undo = { operation: restoreOperation, input: { ...target, body: restoreBody } };
+    }
+    const entry = this.undoLog!.record({
+      resource_type: def.resourceType,
+      operation: operation as "create" | "update" | "delete",
+      ...(resourceId ? { resource_id: resourceId } : {}),
+      ...(undo ? { undo } : { not_undoable: note ?? `${operation} is not reversible.` }),
+    });
+    return { entry_id: entry.entry_id, undoable: undo !== undefined };
+  }
+
+  /**
+   * Scope-escalation guardrail: compare the org/project a request targets with
+   * the session's pinned scope. In "block" mode a mismatch without
+   * `cross_scope: true` is rejected (and audited as blocked); in "warn" mode the
+   * request proceeds and the returned message is attached to the result.
+   */
+  private guardScope(
+    def: ResourceDefinition,
+    resourceType: string,
+    operation: string,
+    input: Record<string, unknown>,
+    auditCtx: AuditContext | undefined,
+  ): string | undefined {
+    const mode = this.config.HARNESS_SCOPE_GUARD ?? "warn";
+    if (mode === "off" || isCrossScopeAllowed(input)) return undefined;
+    const escalations = detectScopeEscalation(def.scope, input, {
+      org: this.config.HARNESS_ORG,
+      project: this.config.HARNESS_PROJECT,
+    });
+    if (escalations.length === 0) return undefined;
+
+    const message = describeScopeEscalation(escalations);
+    if (mode === "block") {
+      const reason = `Cross-scope request blocked (HARNESS_SCOPE_GUARD=block). ${message}`;
+      this.auditBlockedAttempt(resourceType, operation, input, auditCtx, reason);
+      throw new Error(reason);
+    }
+    log.warn("Cross-scope request", { resourceType, operation, escalations });
+    return message;
   }
 
   /**
@@ -545,6 +755,7 @@ export class Registry {
       ? spec.pathBuilder(input, { HARNESS_ACCOUNT_ID: this.getAccountId(), HARNESS_ORG: this.config.HARNESS_ORG, HARNESS_PROJECT: this.config.HARNESS_PROJECT })
       : spec.path;
 
+    const conversationId = getConversationId();
     const event: AuditEvent = {
       event_id: randomUUID(),
       timestamp: new Date().toISOString(),
@@ -576,6 +787,7 @@ export class Registry {
       http_path: resolvedPath,
       ...(error ? { error } : {}),
       ...(httpStatus ? { http_status: httpStatus } : {}),
+      ...(conversationId ? { conversation_id: conversationId } : {}),
     };
 
     this.auditManager.emit(event);
@@ -599,6 +811,16 @@ export class Registry {
       await spec.preflight({ client, input, registry: this, signal });
     }
 
+    // Aggregated views gather their own raw payload instead of issuing one request.
+    if (spec.collect) {
+      const collected = await spec.collect({ client, input, registry: this, signal });
+      const result = spec.responseExtractor ? spec.responseExtractor(collected, input) : collected;
+      if (spec.skipCompact && result && typeof result === "object" && !Array.isArray(result)) {
+        Object.defineProperty(result, "__skipCompact", { value: true, enumerable: false, configurable: true });
+      }
+      return result;
+    }
+
     // When explicit resource_scope resolved org/project from config defaults,
     // merge them into input so pathBuilder functions see the effective values.
     // Only inject for scopes that actually use those params.
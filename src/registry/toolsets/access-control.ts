@@ -1,5 +1,41 @@
 import type { ToolsetDefinition } from "../types.js";
-import { ngExtract, pageExtract } from "../extractors.js";
+import { ngExtract, pageExtract, serviceAccountTokenExtract } from "../extractors.js";
+import { isRecord } from "../utils/type-guards.js";
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/** Epoch millis from a number or ISO 8601 string, or undefined. */
+function epochMs(value: unknown, field: string): number | undefined {
+  if (value === undefined || value === null || value === "") return undefined;
+  const ms = typeof value === "number" ? value : /^\d+$/.test(String(value)) ? Number(value) : Date.parse(String(value));
+  if (!Number.isFinite(ms)) throw new Error(`${field} must be epoch millis or an ISO 8601 timestamp, got "${String(value)}"`);
+  return ms;
+}
+
+/**
+ * Copy the service account / API key / token ids from the body onto the
+ * input, where the path and query are built from, and require the ones
+ * `needed` names. Callers can pass them either way.
+ */
+function hoistTokenIds(input: Record<string, unknown>, needed: Array<"service_account_id" | "api_key_id" | "token_id">): Record<string, unknown> {
+  const body = isRecord(input.body) ? input.body : {};
+  const aliases: Record<string, unknown[]> = {
+    service_account_id: [body.service_account_id, body.parentIdentifier],
+    api_key_id: [body.api_key_id, body.apiKeyIdentifier],
+    token_id: [body.token_id, body.identifier],
+  };
+  for (const key of needed) {
+    if (input[key] === undefined || input[key] === "") {
+      const value = aliases[key]!.find((v) => typeof v === "string" && v);
+      if (value !== undefined) input[key] = value;
+    }
+  }
+  const missing = needed.filter((key) => typeof input[key] !== "string" || !input[key]);
+  if (missing.length > 0) {
+    throw new Error(`Missing ${missing.join(", ")}. Pass them in params; list keys with harness_list(resource_type='service_account_api_key', filters={service_account_id}).`);
+  }
+  return body;
+}
 
 export const accessControlToolset: ToolsetDefinition = {
   name: "access_control",
@@ -192,6 +228,235 @@ export const accessControlToolset: ToolsetDefinition = {
           description: "Delete a service account",
         },
       },
+      executeActions: {
+        bind_roles: {
+          method: "POST",
+          path: "/authz/api/roleassignments/multi",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          bodyBuilder: (input) => {
+            const body = isRecord(input.body) ? input.body : {};
+            const serviceAccountId = input.service_account_id;
+            const bindings = Array.isArray(body.role_bindings) ? body.role_bindings.filter(isRecord) : [];
+            if (typeof serviceAccountId !== "string" || !serviceAccountId || bindings.length === 0) {
+              throw new Error("bind_roles requires the service account as resource_id and body.role_bindings: [{role, resource_group}].");
+            }
+            return {
+              roleAssignments: bindings.map((binding) => {
+                const role = binding.role ?? binding.roleIdentifier;
+                const resourceGroup = binding.resource_group ?? binding.resourceGroupIdentifier;
+                if (typeof role !== "string" || typeof resourceGroup !== "string") {
+                  throw new Error(`Each role binding needs role and resource_group. Got: ${JSON.stringify(binding)}`);
+                }
+                return {
+                  roleIdentifier: role,
+                  resourceGroupIdentifier: resourceGroup,
+                  principal: { identifier: serviceAccountId, type: "SERVICE_ACCOUNT" },
+                  disabled: false,
+                };
+              }),
+            };
+          },
+          responseExtractor: ngExtract,
+          actionDescription:
+            "Grant roles to a service account at the current scope, e.g. before issuing it a token. " +
+            "Example: harness_execute(resource_type='service_account', action='bind_roles', resource_id='ci_bot', body={role_bindings:[{role:'_pipeline_executor', resource_group:'_all_project_level_resources'}]}). " +
+            "Remove a binding with harness_delete(resource_type='role_assignment').",
+          bodySchema: {
+            description: "Role bindings to create for the service account",
+            fields: [
+              { name: "role_bindings", type: "array", required: true, description: "Bindings to create", itemType: "object", fields: [
+                { name: "role", type: "string", required: true, description: "Role identifier, e.g. '_pipeline_executor'" },
+                { name: "resource_group", type: "string", required: true, description: "Resource group identifier, e.g. '_all_project_level_resources'" },
+              ]},
+            ],
+          },
+        },
+      },
+    },
+    {
+      resourceType: "service_account_api_key",
+      displayName: "Service Account API Key",
+      description:
+        "API key of a service account. Tokens are issued under a key (see service_account_token). Supports list, create, and delete.\n" +
+        "Deleting a key revokes every token issued under it.",
+      toolset: "access_control",
+      scope: "project",
+      scopeOptional: true,
+      supportedScopes: ["account", "org", "project"],
+      identifierFields: ["service_account_id", "api_key_id"],
+      listFilterFields: [
+        { name: "service_account_id", description: "Service account whose keys to list", required: true },
+      ],
+      deepLinkTemplate: "/ng/account/{accountId}/settings/access-control/service-accounts/{serviceAccountIdentifier}",
+      operations: {
+        list: {
+          method: "GET",
+          path: "/ng/api/apikey",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          staticQueryParams: { apiKeyType: "SERVICE_ACCOUNT" },
+          queryParams: { service_account_id: "parentIdentifier" },
+          responseExtractor: ngExtract,
+          description: "List API keys of a service account",
+        },
+        create: {
+          method: "POST",
+          path: "/ng/api/apikey",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          injectAccountInBody: true,
+          bodyBuilder: (input) => {
+            const body = hoistTokenIds(input, ["service_account_id"]);
+            const identifier = body.identifier;
+            if (typeof identifier !== "string" || !identifier) throw new Error("body.identifier is required for a new API key.");
+            const expiryDays = Number(body.default_token_expiry_days ?? 0);
+            return {
+              identifier,
+              name: typeof body.name === "string" && body.name ? body.name : identifier,
+              ...(typeof body.description === "string" ? { description: body.description } : {}),
+              apiKeyType: "SERVICE_ACCOUNT",
+              parentIdentifier: input.service_account_id,
+              ...(expiryDays > 0 ? { defaultTimeToExpireToken: expiryDays * DAY_MS } : {}),
+            };
+          },
+          responseExtractor: ngExtract,
+          description: "Create an API key for a service account",
+          bodySchema: {
+            description: "API key definition. The service account comes from params.service_account_id.",
+            fields: [
+              { name: "identifier", type: "string", required: true, description: "Unique key identifier" },
+              { name: "name", type: "string", required: false, description: "Display name. Defaults to the identifier." },
+              { name: "description", type: "string", required: false, description: "Description" },
+              { name: "default_token_expiry_days", type: "number", required: false, description: "Default lifetime in days for tokens issued under this key" },
+            ],
+          },
+        },
+        delete: {
+          method: "DELETE",
+          path: "/ng/api/apikey/{apiKeyIdentifier}",
+          operationPolicy: { risk: "destructive", retryPolicy: "do_not_retry" },
+          pathParams: { api_key_id: "apiKeyIdentifier" },
+          staticQueryParams: { apiKeyType: "SERVICE_ACCOUNT" },
+          queryParams: { service_account_id: "parentIdentifier" },
+          responseExtractor: ngExtract,
+          description: "Delete a service account API key and revoke all of its tokens",
+        },
+      },
+    },
+    {
+      resourceType: "service_account_token",
+      displayName: "Service Account Token",
+      description:
+        "Token issued under a service account API key. Supports list, create, delete (revoke), and the rotate action.\n" +
+        "The token value is returned only by create and rotate. Pass output_dir to write it to an owner-only file on the server host instead of returning it.\n" +
+        "ROTATE: harness_execute(resource_type='service_account_token', action='rotate', resource_id='<token>', params={service_account_id, api_key_id}).",
+      toolset: "access_control",
+      scope: "project",
+      scopeOptional: true,
+      supportedScopes: ["account", "org", "project"],
+      identifierFields: ["service_account_id", "api_key_id", "token_id"],
+      listFilterFields: [
+        { name: "service_account_id", description: "Service account whose tokens to list", required: true },
+        { name: "api_key_id", description: "Only tokens under this API key" },
+      ],
+      deepLinkTemplate: "/ng/account/{accountId}/settings/access-control/service-accounts/{serviceAccountIdentifier}",
+      operations: {
+        list: {
+          method: "GET",
+          path: "/ng/api/token/aggregate",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          staticQueryParams: { apiKeyType: "SERVICE_ACCOUNT" },
+          queryParams: {
+            service_account_id: "parentIdentifier",
+            api_key_id: "apiKeyIdentifier",
+            page: "pageIndex",
+            size: "pageSize",
+          },
+          responseExtractor: pageExtract,
+          description: "List tokens of a service account with expiry and last-used details",
+        },
+        create: {
+          method: "POST",
+          path: "/ng/api/token",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          injectAccountInBody: true,
+          bodyBuilder: (input) => {
+            const body = hoistTokenIds(input, ["service_account_id", "api_key_id", "token_id"]);
+            const now = Date.now();
+            const days = Number(body.expires_in_days ?? 0);
+            const validTo = epochMs(body.valid_to, "valid_to") ?? (days > 0 ? now + days * DAY_MS : undefined);
+            if (validTo !== undefined && validTo <= now) throw new Error("valid_to must be in the future.");
+            if (typeof body.output_dir === "string" && input.output_dir === undefined) input.output_dir = body.output_dir;
+            return {
+              identifier: input.token_id,
+              name: typeof body.name === "string" && body.name ? body.name : input.token_id,
+              ...(typeof body.description === "string" ? { description: body.description } : {}),
+              apiKeyIdentifier: input.api_key_id,
+              parentIdentifier: input.service_account_id,
+              apiKeyType: "SERVICE_ACCOUNT",
+              validFrom: now,
+              ...(validTo !== undefined ? { validTo } : {}),
+            };
+          },
+          responseExtractor: serviceAccountTokenExtract,
+          description: "Issue a new token under a service account API key. Returns the token value once.",
+          bodySchema: {
+            description: "Token definition. service_account_id and api_key_id come from params (or the body).",
+            fields: [
+              { name: "identifier", type: "string", required: true, description: "Unique token identifier" },
+              { name: "name", type: "string", required: false, description: "Display name. Defaults to the identifier." },
+              { name: "description", type: "string", required: false, description: "Description" },
+              { name: "expires_in_days", type: "number", required: false, description: "Lifetime in days. Omit to use the key's default." },
+              { name: "valid_to", type: "string", required: false, description: "Explicit expiry (ISO 8601 or epoch millis); overrides expires_in_days" },
+              { name: "output_dir", type: "string", required: false, description: "Absolute directory on the MCP server host to write the token to (mode 0600) instead of returning it. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it." },
+            ],
+          },
+        },
+        delete: {
+          method: "DELETE",
+          path: "/ng/api/token/{tokenIdentifier}",
+          operationPolicy: { risk: "destructive", retryPolicy: "do_not_retry" },
+          pathParams: { token_id: "tokenIdentifier" },
+          staticQueryParams: { apiKeyType: "SERVICE_ACCOUNT" },
+          queryParams: {
+            service_account_id: "parentIdentifier",
+            api_key_id: "apiKeyIdentifier",
+          },
+          responseExtractor: ngExtract,
+          description: "Revoke a service account token. Requests using it fail immediately.",
+        },
+      },
+      executeActions: {
+        rotate: {
+          method: "POST",
+          path: "/ng/api/token/rotate/{tokenIdentifier}",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          pathParams: { token_id: "tokenIdentifier" },
+          staticQueryParams: { apiKeyType: "SERVICE_ACCOUNT" },
+          queryParams: {
+            service_account_id: "parentIdentifier",
+            api_key_id: "apiKeyIdentifier",
+            rotate_timestamp: "rotateTimestamp",
+          },
+          bodyBuilder: (input) => {
+            const body = hoistTokenIds(input, ["service_account_id", "api_key_id", "token_id"]);
+            const graceHours = Number(body.grace_period_hours ?? 0);
+            input.rotate_timestamp = Date.now() + (graceHours > 0 ? graceHours * 60 * 60 * 1000 : 0);
+            if (typeof body.output_dir === "string" && input.output_dir === undefined) input.output_dir = body.output_dir;
+            return undefined;
+          },
+          responseExtractor: serviceAccountTokenExtract,
+          actionDescription:
+            "Issue a replacement for a service account token and expire the old one. Returns the new token value once. " +
+            "By default the old token stops working immediately; pass grace_period_hours to keep it valid while consumers switch over. " +
+            "Example: harness_execute(resource_type='service_account_token', action='rotate', resource_id='ci_token', params={service_account_id:'ci_bot', api_key_id:'ci_key'}, body={grace_period_hours:24}).",
+          bodySchema: {
+            description: "Rotation options",
+            fields: [
+              { name: "grace_period_hours", type: "number", required: false, description: "Hours the old token stays valid after rotation (default 0: expires now)" },
+              { name: "output_dir", type: "string", required: false, description: "Absolute directory on the MCP server host to write the new token to (mode 0600) instead of returning it. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it." },
+            ],
+          },
+        },
+      },
     },
     {
       resourceType: "role",
@@ -1,5 +1,22 @@
-import type { ToolsetDefinition } from "../types.js";
-import { ngExtract, pageExtract } from "../extractors.js";
+import type { ToolsetDefinition, ParamsSchema, PreflightContext } from "../types.js";
+import {
+  ngExtract,
+  pageExtract,
+  auditSiemExportExtract,
+  auditSiemExportWrite,
+  entityVersionListExtract,
+  entityVersionDiffExtract,
+  configSnapshotDiffExtract,
+  groupSnapshotChanges,
+  passthrough,
+  undoLogListExtract,
+  undoResultExtract,
+  type EntityVersionDiffScan,
+  type ConfigSnapshotScan,
+  type UndoResultScan,
+} from "../extractors.js";
+import { asString } from "../../utils/type-guards.js";
+import type { UndoEntry, UndoLog } from "../../utils/undo-log.js";
 
 /** Parse ISO 8601 to Unix ms. Returns NaN if invalid. */
 function parseIsoToMs(value: unknown): number {
@@ -15,6 +32,292 @@ function defaultAuditTimeWindow(): { startTime: number; endTime: number } {
   return { startTime, endTime };
 }
 
+/** Audit list filter body for an explicit [startTime, endTime] window. */
+function buildAuditFilterBody(input: Record<string, unknown>, startTime: number, endTime: number): Record<string, unknown> {
+  return {
+    filterType: "Audit",
+    modules: input.module ? [input.module] : undefined,
+    actions: input.action ? [input.action] : undefined,
+    resources: input.audit_resource_type ? [{ type: input.audit_resource_type }] : undefined,
+    startTime,
+    endTime,
+  };
+}
+
+const AUDIT_RESOURCE_TYPES = ["ORGANIZATION", "PROJECT", "USER_GROUP", "SECRET", "PIPELINE", "TRIGGER", "TEMPLATE", "INPUT_SET", "DELEGATE_CONFIGURATION", "DELEGATE_GROUPS", "SERVICE", "ENVIRONMENT", "ENVIRONMENT_GROUP", "DELEGATE", "SERVICE_ACCOUNT", "CONNECTOR", "ROLE", "RESOURCE_GROUP", "DASHBOARD", "GOVERNANCE_POLICY", "GOVERNANCE_POLICY_SET", "VARIABLE", "MONITORED_SERVICE", "FEATURE_FLAG", "CHAOS_HUB", "CHAOS_INFRASTRUCTURE", "CHAOS_EXPERIMENT", "GITOPS_AGENT", "GITOPS_APPLICATION", "CODE_REPOSITORY", "SETTING", "DEPLOYMENT_FREEZE"];
+
+const AUDIT_ACTIONS = ["CREATE", "UPDATE", "RESTORE", "DELETE", "FORCE_DELETE", "UPSERT", "INVITE", "RESEND_INVITE", "REVOKE_INVITE", "ADD_COLLABORATOR", "REMOVE_COLLABORATOR", "CREATE_TOKEN", "REVOKE_TOKEN", "LOGIN", "LOGIN2FA", "UNSUCCESSFUL_LOGIN", "ADD_MEMBERSHIP", "REMOVE_MEMBERSHIP", "START", "END", "PAUSE", "RESUME", "ABORT", "TIMEOUT", "ROLE_ASSIGNMENT_CREATED", "ROLE_ASSIGNMENT_UPDATED", "ROLE_ASSIGNMENT_DELETED", "ENABLED", "DISABLED", "RERUN", "BYPASS"];
+
+/** Entity types with version history, mapped to their audit resource type. */
+const VERSIONED_ENTITY_TYPES: Record<string, string> = {
+  pipeline: "PIPELINE",
+  template: "TEMPLATE",
+  connector: "CONNECTOR",
+};
+
+/** Audit actions that record a new version of an entity's YAML. */
+const VERSION_ACTIONS = ["CREATE", "UPDATE", "UPSERT", "RESTORE", "DELETE"];
+
+/** Default entity_version window: 30 days back from now. */
+const VERSION_HISTORY_WINDOW_MS = 30 * 24 * 60 * 60 * 1000;
+
+/**
+ * Audit scope filter for an entity: project-level unless resource_scope says
+ * org or account (e.g. account-level connectors and templates).
+ */
+function entityAuditScope(ctx: PreflightContext): Record<string, unknown> {
+  const { client, input, registry } = ctx;
+  const scope: Record<string, unknown> = { accountIdentifier: client.account };
+  if (input.resource_scope === "account") return scope;
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  if (org) scope.orgIdentifier = org;
+  if (input.resource_scope === "org") return scope;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  if (project) scope.projectIdentifier = project;
+  return scope;
+}
+
+/**
+ * List the recorded versions of one pipeline, template, or connector. Harness
+ * keeps the full before/after YAML of every change on its audit event, so each
+ * change event is one version.
+ */
+async function collectEntityVersions(ctx: PreflightContext): Promise<unknown> {
+  const { client, input, signal } = ctx;
+  const entityType = String(input.entity_type ?? "");
+  const auditType = VERSIONED_ENTITY_TYPES[entityType];
+  if (!auditType) {
+    throw new Error(`entity_type must be one of: ${Object.keys(VERSIONED_ENTITY_TYPES).join(", ")}`);
+  }
+  const entityId = String(input.entity_id ?? "");
+  if (!entityId) throw new Error("entity_id is required: the identifier of the pipeline, template, or connector.");
+
+  const endMs = parseIsoToMs(input.end_time);
+  const endTime = Number.isNaN(endMs) ? Date.now() : endMs;
+  const startMs = parseIsoToMs(input.start_time);
+  const startTime = Number.isNaN(startMs) ? endTime - VERSION_HISTORY_WINDOW_MS : startMs;
+  if (endTime <= startTime) throw new Error("end_time must be after start_time");
+
+  const resource: Record<string, unknown> = { type: auditType, identifier: entityId };
+  if (typeof input.version_label === "string" && input.version_label) {
+    resource.labels = { versionLabel: input.version_label };
+  }
+  return client.request<unknown>({
+    method: "POST",
+    path: "/audit/api/audits/list",
+    params: { pageIndex: input.page ?? 0, pageSize: input.size ?? 20 },
+    body: {
+      filterType: "Audit",
+      scopes: [entityAuditScope(ctx)],
+      resources: [resource],
+      actions: VERSION_ACTIONS,
+      startTime,
+      endTime,
+    },
+    retryPolicy: "safe",
+    signal,
+  });
+}
+
+async function fetchVersionYaml(ctx: PreflightContext, versionId: string): Promise<{ oldYaml: string; newYaml: string }> {
+  const raw = await ctx.client.request<unknown>({
+    method: "GET",
+    path: "/audit/api/auditYaml",
+    params: { auditId: versionId },
+    signal: ctx.signal,
+  });
+  const data = ngExtract(raw) as { oldYaml?: unknown; newYaml?: unknown } | undefined;
+  return {
+    oldYaml: typeof data?.oldYaml === "string" ? data.oldYaml : "",
+    newYaml: typeof data?.newYaml === "string" ? data.newYaml : "",
+  };
+}
+
+/**
+ * Load the two YAML snapshots for entity_version_diff. Without base_version
+ * the diff is the single change recorded in version_id.
+ */
+async function collectEntityVersionDiff(ctx: PreflightContext): Promise<EntityVersionDiffScan> {
+  const toVersion = String(ctx.input.version_id ?? "");
+  if (!toVersion) throw new Error("version_id is required: a version_id from harness_list(resource_type='entity_version').");
+  const baseVersion = typeof ctx.input.base_version === "string" && ctx.input.base_version ? ctx.input.base_version : undefined;
+
+  const [to, base] = await Promise.all([
+    fetchVersionYaml(ctx, toVersion),
+    baseVersion ? fetchVersionYaml(ctx, baseVersion) : Promise.resolve(undefined),
+  ]);
+  return {
+    from_version: baseVersion ?? `${toVersion}~previous`,
+    to_version: toVersion,
+    before: base ? base.newYaml : to.oldYaml,
+    after: to.newYaml,
+  };
+}
+
+/** Entity types config_snapshot_diff can track, mapped to their audit resource type. */
+const SNAPSHOT_ENTITY_TYPES: Record<string, string> = {
+  pipeline: "PIPELINE",
+  connector: "CONNECTOR",
+  policy: "GOVERNANCE_POLICY",
+  policy_set: "GOVERNANCE_POLICY_SET",
+  template: "TEMPLATE",
+  service: "SERVICE",
+  environment: "ENVIRONMENT",
+  input_set: "INPUT_SET",
+  trigger: "TRIGGER",
+  secret: "SECRET",
+};
+
+const SNAPSHOT_DEFAULT_TYPES = ["pipeline", "connector", "policy"];
+
+/** Audit actions that change an entity's configuration. */
+const SNAPSHOT_ACTIONS = ["CREATE", "UPDATE", "UPSERT", "RESTORE", "DELETE", "FORCE_DELETE"];
+
+const SNAPSHOT_PAGE_SIZE = 100;
+const SNAPSHOT_MAX_PAGES = 20;
+/** Entities whose YAML is fetched for include_diff; each costs one or two auditYaml calls. */
+const SNAPSHOT_DIFF_MAX_ENTITIES = 25;
+const SNAPSHOT_DIFF_CONCURRENCY = 5;
+
+/**
+ * Read every configuration change of the requested entity types between
+ * from_time and to_time. The configuration at from_time is the state just
+ * before the first change in the window; at to_time, the state after the last.
+ * With include_diff, fetch those two YAML snapshots per entity (never for
+ * secrets, whose audit YAML is not shown).
+ */
+async function collectConfigSnapshotDiff(ctx: PreflightContext): Promise<ConfigSnapshotScan> {
+  const { client, input, signal } = ctx;
+  const fromMs = parseIsoToMs(input.from_time);
+  if (Number.isNaN(fromMs)) throw new Error("from_time is required: the start of the change window in ISO 8601.");
+  const toMs = parseIsoToMs(input.to_time);
+  const toTime = Number.isNaN(toMs) ? Date.now() : toMs;
+  if (toTime <= fromMs) throw new Error("to_time must be after from_time");
+
+  const requested = Array.isArray(input.entity_types)
+    ? input.entity_types.map(String)
+    : typeof input.entity_types === "string" && input.entity_types
+      ? input.entity_types.split(",").map((t) => t.trim()).filter(Boolean)
+      : SNAPSHOT_DEFAULT_TYPES;
+  const entityTypes: Record<string, string> = {};
+  for (const name of requested) {
+    const auditType = SNAPSHOT_ENTITY_TYPES[name];
+    if (!auditType) throw new Error(`Unknown entity type "${name}". entity_types must be from: ${Object.keys(SNAPSHOT_ENTITY_TYPES).join(", ")}`);
+    entityTypes[name] = auditType;
+  }
+
+  const events: unknown[] = [];
+  let total = 0;
+  for (let page = 0; page < SNAPSHOT_MAX_PAGES; page++) {
+    const raw = await client.request<unknown>({
+      method: "POST",
+      path: "/audit/api/audits/list",
+      params: { pageIndex: page, pageSize: SNAPSHOT_PAGE_SIZE },
+      body: {
+        filterType: "Audit",
+        scopes: [entityAuditScope(ctx)],
+        resources: Object.values(entityTypes).map((type) => ({ type })),
+        actions: SNAPSHOT_ACTIONS,
+        startTime: fromMs,
+        endTime: toTime,
+      },
+      retryPolicy: "safe",
+      signal,
+    });
+    const { items, total: pageTotal } = pageExtract(raw);
+    events.push(...items);
+    total = Math.max(pageTotal, events.length);
+    if (items.length < SNAPSHOT_PAGE_SIZE || events.length >= total) break;
+  }
+
+  const scan: ConfigSnapshotScan = {
+    from_time: new Date(fromMs).toISOString(),
+    to_time: new Date(toTime).toISOString(),
+    entity_types: entityTypes,
+    events,
+    total_events: total,
+    errors: [],
+  };
+  if (input.include_diff !== true && input.include_diff !== "true") return scan;
+
+  const targets = groupSnapshotChanges(events)
+    .filter((entry) => entry.audit_type !== "SECRET" && entry.change !== "created_and_deleted")
+    .slice(0, SNAPSHOT_DIFF_MAX_ENTITIES);
+  scan.yaml = {};
+  for (let i = 0; i < targets.length; i += SNAPSHOT_DIFF_CONCURRENCY) {
+    await Promise.all(targets.slice(i, i + SNAPSHOT_DIFF_CONCURRENCY).map(async (entry) => {
+      // State at from_time is the YAML before the first change; at to_time, after the last.
+      const beforeId = entry.change === "created" ? undefined : String(entry.events[0]?.auditId ?? "");
+      const afterId = entry.change === "deleted" ? undefined : String(entry.events[entry.events.length - 1]?.auditId ?? "");
+      try {
+        const ids = [...new Set([beforeId, afterId].filter((id): id is string => !!id))];
+        const snapshots = new Map(await Promise.all(ids.map(async (id) => [id, await fetchVersionYaml(ctx, id)] as const)));
+        scan.yaml![entry.key] = {
+          before: beforeId ? snapshots.get(beforeId)?.oldYaml ?? "" : "",
+          after: afterId ? snapshots.get(afterId)?.newYaml ?? "" : "",
+        };
+      } catch (err) {
+        scan.errors.push({ entity: entry.key, error: err instanceof Error ? err.message : String(err) });
+      }
+    }));
+  }
+  return scan;
+}
+
+/** The session's undo log, or an error saying how to turn it on. */
+function requireUndoLog(ctx: PreflightContext): UndoLog {
+  const log = ctx.registry.undoLog;
+  if (!log) {
+    throw new Error("The undo log is off. Set HARNESS_UNDO_LOG=true to record the writes made in a session so they can be undone.");
+  }
+  return log;
+}
+
+/** "update pipeline \"deploy\"" — how an entry is named in errors. */
+function describeUndoEntry(entry: UndoEntry): string {
+  const verb = entry.action ? `${entry.action} on` : entry.operation;
+  return `${verb} ${entry.resource_type}${entry.resource_id ? ` "${entry.resource_id}"` : ""}`;
+}
+
+async function collectUndoLog(ctx: PreflightContext): Promise<unknown> {
+  return requireUndoLog(ctx).entries();
+}
+
+async function collectUndoLogEntry(ctx: PreflightContext): Promise<unknown> {
+  const entryId = asString(ctx.input.entry_id);
+  const entry = requireUndoLog(ctx).entries().find((e) => e.entry_id === entryId);
+  if (!entry) throw new Error(`No undo log entry "${entryId ?? ""}" in this session. List undo_log for entry_ids.`);
+  return { ...entry };
+}
+
+/**
+ * undo_last_change: reverse the newest entry (or `entry_id`) by replaying its
+ * undo call through the registry, then drop it from the log. An older entry
+ * whose entity changed again later is refused unless `force` is set, since
+ * restoring it would also discard the later change.
+ */
+async function undoLastChange(ctx: PreflightContext): Promise<UndoResultScan> {
+  const log = requireUndoLog(ctx);
+  const entries = log.entries();
+  if (entries.length === 0) throw new Error("Nothing to undo: no writes have been recorded in this session.");
+  const requested = asString(ctx.input.entry_id);
+  const index = requested ? entries.findIndex((e) => e.entry_id === requested) : 0;
+  if (index < 0) throw new Error(`No undo log entry "${requested}" in this session. List undo_log for entry_ids.`);
+  const entry = entries[index]!;
+  if (!entry.undo) {
+    throw new Error(`Change ${entry.entry_id} (${describeUndoEntry(entry)}) cannot be undone: ${entry.not_undoable} Pass an earlier entry_id from undo_log to undo that change instead.`);
+  }
+  const later = entries.slice(0, index)
+    .filter((e) => e.resource_type === entry.resource_type && e.resource_id === entry.resource_id);
+  if (later.length > 0 && ctx.input.force !== true && ctx.input.force !== "true") {
+    throw new Error(`${describeUndoEntry(entry)} was changed again later (${later.map((e) => e.entry_id).join(", ")}). Undo those first, or pass force: true to restore the state before ${entry.entry_id} anyway.`);
+  }
+  const plan = entry.undo;
+  const result = await log.replay(() => ctx.registry.dispatch(ctx.client, entry.resource_type, plan.operation, structuredClone(plan.input), ctx.signal));
+  log.remove(entry.entry_id);
+  return { entry, result };
+}
+
 export const auditToolset: ToolsetDefinition = {
   name: "audit",
   displayName: "Audit Trail",
@@ -28,8 +331,8 @@ export const auditToolset: ToolsetDefinition = {
       scope: "account",
       identifierFields: ["audit_id"],
       listFilterFields: [
-        { name: "audit_resource_type", description: "Filter audit logs by resource type (renamed from resource_type to avoid conflict with MCP parameter)", enum: ["ORGANIZATION", "PROJECT", "USER_GROUP", "SECRET", "PIPELINE", "TRIGGER", "TEMPLATE", "INPUT_SET", "DELEGATE_CONFIGURATION", "DELEGATE_GROUPS", "SERVICE", "ENVIRONMENT", "ENVIRONMENT_GROUP", "DELEGATE", "SERVICE_ACCOUNT", "CONNECTOR", "ROLE", "RESOURCE_GROUP", "DASHBOARD", "GOVERNANCE_POLICY", "GOVERNANCE_POLICY_SET", "VARIABLE", "MONITORED_SERVICE", "FEATURE_FLAG", "CHAOS_HUB", "CHAOS_INFRASTRUCTURE", "CHAOS_EXPERIMENT", "GITOPS_AGENT", "GITOPS_APPLICATION", "CODE_REPOSITORY", "SETTING", "DEPLOYMENT_FREEZE"] },
-        { name: "action", description: "Filter audit logs by action type", enum: ["CREATE", "UPDATE", "RESTORE", "DELETE", "FORCE_DELETE", "UPSERT", "INVITE", "RESEND_INVITE", "REVOKE_INVITE", "ADD_COLLABORATOR", "REMOVE_COLLABORATOR", "CREATE_TOKEN", "REVOKE_TOKEN", "LOGIN", "LOGIN2FA", "UNSUCCESSFUL_LOGIN", "ADD_MEMBERSHIP", "REMOVE_MEMBERSHIP", "START", "END", "PAUSE", "RESUME", "ABORT", "TIMEOUT", "ROLE_ASSIGNMENT_CREATED", "ROLE_ASSIGNMENT_UPDATED", "ROLE_ASSIGNMENT_DELETED", "ENABLED", "DISABLED", "RERUN", "BYPASS"] },
+        { name: "audit_resource_type", description: "Filter audit logs by resource type (renamed from resource_type to avoid conflict with MCP parameter)", enum: AUDIT_RESOURCE_TYPES },
+        { name: "action", description: "Filter audit logs by action type", enum: AUDIT_ACTIONS },
         { name: "start_time", description: "Start time in ISO 8601 format (e.g. 2025-07-10T08:00:00Z). Default: 7 days ago." },
         { name: "end_time", description: "End time in ISO 8601 format (e.g. 2025-07-10T23:59:59Z). Default: now." },
         { name: "search_term", description: "Filter audit logs by search term" },
@@ -48,14 +351,7 @@ export const auditToolset: ToolsetDefinition = {
             const endMs = parseIsoToMs(input.end_time);
             const startTime = Number.isNaN(startMs) ? defaultStart : startMs;
             const endTime = Number.isNaN(endMs) ? defaultEnd : endMs;
-            return {
-              filterType: "Audit",
-              modules: input.module ? [input.module] : undefined,
-              actions: input.action ? [input.action] : undefined,
-              resources: input.audit_resource_type ? [{ type: input.audit_resource_type }] : undefined,
-              startTime,
-              endTime,
-            };
+            return buildAuditFilterBody(input, startTime, endTime);
           },
           responseExtractor: pageExtract,
           description: "List audit events",
@@ -70,5 +366,217 @@ export const auditToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "audit_export",
+      displayName: "Audit SIEM Export",
+      description:
+        "Audit events for an explicit time window, formatted for SIEM ingestion as OCSF API Activity JSON (default) or CEF lines. Supports list only. Returns pages inline, or writes each page to output_dir.",
+      toolset: "audit",
+      scope: "account",
+      identifierFields: [],
+      searchAliases: ["siem export", "ocsf", "cef", "audit stream", "audit log export"],
+      relatedResources: [
+        {
+          resourceType: "audit_event",
+          relationship: "formatted-from",
+          description: "Raw audit events. Use harness_get(resource_type='audit_event', resource_id=<auditId>) for the YAML diff behind an exported event.",
+        },
+      ],
+      listFilterFields: [
+        { name: "start_time", description: "Window start in ISO 8601 (required, e.g. 2025-07-10T00:00:00Z)", required: true },
+        { name: "end_time", description: "Window end in ISO 8601 (required)", required: true },
+        { name: "audit_resource_type", description: "Filter by audited resource type", enum: AUDIT_RESOURCE_TYPES },
+        { name: "action", description: "Filter by action type", enum: AUDIT_ACTIONS },
+        { name: "module", description: "Filter by module" },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/audit/api/audits/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: { page: "pageIndex", size: "pageSize" },
+          bodyBuilder: (input) => {
+            const startTime = parseIsoToMs(input.start_time);
+            const endTime = parseIsoToMs(input.end_time);
+            if (Number.isNaN(startTime) || Number.isNaN(endTime)) {
+              throw new Error("audit_export requires start_time and end_time in ISO 8601 format so exported windows are reproducible");
+            }
+            if (endTime <= startTime) {
+              throw new Error("end_time must be after start_time");
+            }
+            return buildAuditFilterBody(input, startTime, endTime);
+          },
+          responseExtractor: auditSiemExportExtract,
+          writeOutput: auditSiemExportWrite,
+          skipCompact: true,
+          description:
+            "Export audit events in a SIEM format. Returns {format, window, page, page_size, total, has_more, items} where items are OCSF API Activity (class_uid 6003) objects or CEF:0 strings. With params.output_dir, the page is written to <output_dir>/harness-audit-<start>-<end>-p<page>.ocsf.jsonl|.cef and the response carries file and exported instead of items. Page through with page/size until has_more is false.",
+          paramsSchema: {
+            fields: [
+              { name: "format", required: false, description: "ocsf (default) or cef" },
+              { name: "output_dir", required: false, description: "Absolute directory on the MCP server host to write the page to instead of returning it inline, e.g. /var/exports or C:\\exports. ~ and (on Windows) %VAR% are expanded. Created if missing. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "entity_version",
+      displayName: "Entity Version",
+      description:
+        "Change history of one pipeline, template, or connector: every recorded version with who changed it and when. Supports list only. Diff two versions with entity_version_diff.",
+      toolset: "audit",
+      scope: "project",
+      supportedScopes: ["account", "org", "project"],
+      identifierFields: [],
+      searchAliases: ["version history", "change log", "changelog", "what changed", "pipeline history", "yaml history"],
+      relatedResources: [
+        { resourceType: "entity_version_diff", relationship: "child", description: "Line diff between two versions. Pass a version_id from this list." },
+        { resourceType: "audit_event", relationship: "filtered-view-of", description: "The audit events each version is recorded on." },
+      ],
+      listFilterFields: [
+        { name: "entity_type", description: "Kind of entity", enum: Object.keys(VERSIONED_ENTITY_TYPES), required: true },
+        { name: "entity_id", description: "Identifier of the pipeline, template, or connector", required: true },
+        { name: "version_label", description: "Templates only: limit to one template version label (e.g. v1)" },
+        { name: "start_time", description: "Window start in ISO 8601. Default: 30 days before end_time." },
+        { name: "end_time", description: "Window end in ISO 8601. Default: now." },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/audit/api/audits/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectEntityVersions,
+          responseExtractor: entityVersionListExtract,
+          skipCompact: true,
+          description:
+            "List versions of an entity, newest first. Returns items[] of {version_id, action, changed_at, changed_by, principal_type, version_label?}. Use resource_scope='account' or 'org' for entities at those scopes. To answer \"what changed last Tuesday\", set start_time/end_time to that day and diff the versions found.",
+        },
+      },
+    },
+    {
+      resourceType: "entity_version_diff",
+      displayName: "Entity Version Diff",
+      description:
+        "Unified YAML diff between two versions of a pipeline, template, or connector. Supports get only. version_ids come from entity_version.",
+      toolset: "audit",
+      scope: "account",
+      identifierFields: ["version_id"],
+      searchAliases: ["version diff", "yaml diff", "compare versions", "what changed"],
+      relatedResources: [
+        { resourceType: "entity_version", relationship: "parent", description: "Versions to compare. List them first to get version_ids." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/audit/api/auditYaml",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: { version_id: "auditId" },
+          collect: collectEntityVersionDiff,
+          responseExtractor: entityVersionDiffExtract,
+          skipCompact: true,
+          paramsSchema: {
+            fields: [
+              { name: "base_version", required: false, description: "Older version_id to compare against. Default: the YAML just before version_id, i.e. the diff of that one change" },
+              { name: "context_lines", required: false, description: "Unchanged lines shown around each change (default 3, max 20)" },
+            ],
+          } satisfies ParamsSchema,
+          description:
+            "Diff an entity between base_version and version_id. Returns {from_version, to_version, changed, lines_added, lines_removed, diff} where diff is a unified diff of the YAML. Diffs longer than 1000 lines are cut with truncated: true.",
+        },
+      },
+    },
+    {
+      resourceType: "config_snapshot_diff",
+      displayName: "Config Snapshot Diff",
+      description:
+        "Which pipelines, connectors, policies (and other configuration entities) differ between two points in time, reconstructed from audit history. Supports list only. For change-window and compliance reviews.",
+      toolset: "audit",
+      scope: "project",
+      supportedScopes: ["account", "org", "project"],
+      identifierFields: [],
+      searchAliases: ["config diff", "snapshot diff", "change window", "what changed between", "compliance review", "configuration drift"],
+      relatedResources: [
+        { resourceType: "entity_version", relationship: "filtered-view-of", description: "Full version history of one entity in the diff." },
+        { resourceType: "entity_version_diff", relationship: "child", description: "YAML diff of a single change. Pass first_version_id or last_version_id." },
+        { resourceType: "audit_event", relationship: "filtered-view-of", description: "The audit events the diff is built from." },
+      ],
+      listFilterFields: [
+        { name: "from_time", description: "First snapshot, ISO 8601 (e.g. 2025-07-01T00:00:00Z)", required: true },
+        { name: "to_time", description: "Second snapshot, ISO 8601. Default: now." },
+        { name: "entity_types", description: `Comma-separated entity types. Default: ${SNAPSHOT_DEFAULT_TYPES.join(",")}. Any of: ${Object.keys(SNAPSHOT_ENTITY_TYPES).join(", ")}` },
+        { name: "include_diff", description: "Also return a YAML diff per entity (first 25 entities, never for secrets)", type: "boolean" },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/audit/api/audits/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectConfigSnapshotDiff,
+          responseExtractor: configSnapshotDiffExtract,
+          skipCompact: true,
+          description:
+            "Compare configuration at from_time and to_time. Returns {summary: {created, deleted, modified, created_and_deleted}, items[]} with one item per changed entity: {change, entity_type, identifier, name, org_id, project_id, change_count, actions, first_change_at, last_change_at, changed_by, first_version_id, last_version_id, diff?}. Entities with no audit event in the window are unchanged and not listed. Use resource_scope='account' or 'org' to review a whole account or org. Reads up to 2000 audit events; beyond that the result has truncated: true.",
+        },
+      },
+    },
+    {
+      resourceType: "undo_log",
+      displayName: "Undo Log",
+      description:
+        "Writes made in this session, newest first, with the state needed to reverse them. Requires HARNESS_UNDO_LOG=true. Supports list and get, and the undo_last_change execute action. Kept in memory per session; lost on restart.",
+      toolset: "audit",
+      scope: "account",
+      identifierFields: ["entry_id"],
+      searchAliases: ["undo", "revert change", "rollback change", "undo last change", "restore previous yaml"],
+      relatedResources: [
+        { resourceType: "entity_version", relationship: "filtered-view-of", description: "Full change history of a pipeline, template, or connector, including changes made outside this session." },
+        { resourceType: "audit_event", relationship: "filtered-view-of", description: "Account-wide audit trail of the same writes." },
+      ],
+      executeHint: "undo_last_change reverses the newest entry: an update or delete is restored from the entity captured before it, a create is deleted. Pipelines, templates, services, environments, infrastructure definitions, and connectors are captured; other writes and execute actions are logged but cannot be undone.",
+      // Served from the session's in-memory log; no Harness request is made.
+      operations: {
+        list: {
+          method: "GET",
+          path: "/undo-log",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectUndoLog,
+          responseExtractor: undoLogListExtract,
+          skipCache: true,
+          skipCompact: true,
+          description:
+            "List this session's writes, newest first. Returns items[] of {entry_id, recorded_at, resource_type, operation, action?, resource_id?, undoable, undo_operation | not_undoable}.",
+        },
+        get: {
+          method: "GET",
+          path: "/undo-log/{entryId}",
+          pathParams: { entry_id: "entryId" },
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectUndoLogEntry,
+          responseExtractor: passthrough,
+          skipCache: true,
+          description: "Get one entry with its undo call, including the body that restores the entity (e.g. the pipeline YAML before the update).",
+        },
+      },
+      executeActions: {
+        undo_last_change: {
+          method: "POST",
+          path: "/undo-log/undo",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          collect: undoLastChange,
+          responseExtractor: undoResultExtract,
+          skipUndoLog: true,
+          skipCompact: true,
+          paramsSchema: {
+            fields: [
+              { name: "force", required: false, description: "Restore an older entry (resource_id) even though the same entity changed again later in the session" },
+            ],
+          } satisfies ParamsSchema,
+          actionDescription:
+            "Reverse the newest change recorded in this session's undo log: put back the entity YAML from before an update, re-create a deleted entity, or delete a created one. " +
+            "Pass resource_id (an entry_id from undo_log) to reverse a specific earlier change. Returns {undone, resource_type, resource_id, original_operation, restored_with, result}.",
+        },
+      },
+    },
   ],
 };
@@ -1,6 +1,9 @@
 import type { ToolsetDefinition, PreflightContext, ParamsSchema } from "../types.js";
 import type { PathBuilderConfig } from "../types.js";
-import { ngExtract, passthrough, gqlExtract, ccmViewsExtract, anomalyListExtract, ccmBreakdownExtract, ccmTimeseriesExtract, ccmSummaryExtract, ccmRecommendationsExtract, countExtract } from "../extractors.js";
+import { ngExtract, passthrough, gqlExtract, ccmViewsExtract, anomalyListExtract, ccmBreakdownExtract, ccmClusterWorkloadExtract, ccmTimeseriesExtract, ccmSummaryExtract, ccmBudgetExtract, ccmCurrencyPreferenceExtract, ccmConversionFactorsExtract, ccmRecommendationsExtract, ccmWorkloadPatchExtract, ccmCommitmentBreakdownExtract, ccmCostForecastExtract, ccmGovernanceRuleListExtract, ccmGovernanceRuleExtract, ccmGovernanceEnqueueExtract, ccmGovernanceExecutionListExtract, ccmGovernanceExecutionExtract, countExtract, type CommitmentBreakdownScan, type CostForecastScan } from "../extractors.js";
+import { isRecord } from "../../utils/type-guards.js";
+import { fanOut } from "../../utils/fan-out.js";
+import { FORECAST_CONFIDENCE_LEVELS } from "../../utils/cost-forecast.js";
 
 // ---------------------------------------------------------------------------
 // GraphQL queries — ported from the official Go MCP server
@@ -39,6 +42,53 @@ query FetchperspectiveGrid(
   )
 }`;
 
+/**
+ * Perspective grid over Kubernetes cluster data. Same grid as
+ * PERSPECTIVE_GRID_QUERY, plus the per-row cluster cost split (idle,
+ * unallocated, CPU/memory/storage) CCM only computes for cluster rows.
+ */
+const PERSPECTIVE_CLUSTER_GRID_QUERY = `
+query FetchPerspectiveClusterGrid(
+  $filters: [QLCEViewFilterWrapperInput],
+  $groupBy: [QLCEViewGroupByInput],
+  $limit: Int,
+  $offset: Int,
+  $aggregateFunction: [QLCEViewAggregationInput],
+  $isClusterOnly: Boolean!,
+  $isClusterHourlyData: Boolean = null,
+  $preferences: ViewPreferencesInput
+) {
+  perspectiveGrid(
+    aggregateFunction: $aggregateFunction
+    filters: $filters
+    groupBy: $groupBy
+    limit: $limit
+    offset: $offset
+    preferences: $preferences
+    isClusterOnly: $isClusterOnly
+    isClusterHourlyData: $isClusterHourlyData
+    sortCriteria: [{sortType: COST, sortOrder: DESCENDING}]
+  ) {
+    data {
+      name id cost costTrend
+      clusterData {
+        clusterName namespace workloadName workloadType instanceName
+        totalCost idleCost unallocatedCost utilizedCost systemCost
+        cpuBillingAmount memoryBillingAmount storageCost networkCost efficiencyScore
+        __typename
+      }
+      __typename
+    }
+    __typename
+  }
+  perspectiveTotalCount(
+    filters: $filters
+    groupBy: $groupBy
+    isClusterQuery: $isClusterOnly
+    isClusterHourlyData: $isClusterHourlyData
+  )
+}`;
+
 const PERSPECTIVE_TIMESERIES_QUERY = `
 query FetchPerspectiveTimeSeries(
   $filters: [QLCEViewFilterWrapperInput],
@@ -171,6 +221,17 @@ const OUTPUT_FIELDS: Record<string, Record<string, string>> = {
   product:             { fieldId: "product",              fieldName: "Product",        identifier: "COMMON", identifierName: "Common" },
 };
 
+/** Kubernetes dimensions of CCM cluster data, for cost_cluster_workload. */
+const CLUSTER_FIELDS: Record<string, Record<string, string>> = {
+  cluster:   { fieldId: "clusterName",  fieldName: "Cluster Name", identifier: "CLUSTER", identifierName: "Cluster" },
+  namespace: { fieldId: "namespace",    fieldName: "Namespace",    identifier: "CLUSTER", identifierName: "Cluster" },
+  workload:  { fieldId: "workloadName", fieldName: "Workload",     identifier: "CLUSTER", identifierName: "Cluster" },
+  node_pool: { fieldId: "nodePoolName", fieldName: "Node Pool",    identifier: "CLUSTER", identifierName: "Cluster" },
+  node:      { fieldId: "instanceName", fieldName: "Node",         identifier: "CLUSTER", identifierName: "Cluster" },
+};
+
+const CLUSTER_GROUP_BY = ["namespace", "workload", "node_pool", "node"] as const;
+
 /**
  * Build the startTime AFTER/BEFORE timeFilter pair from an explicit epoch-ms
  * range — the shape the perspective GraphQL API expects. Shared by both the
@@ -585,6 +646,318 @@ async function perspectiveCreatePreflight(ctx: PreflightContext): Promise<void>
   if (!input.body.viewVersion) input.body.viewVersion = "v1";
 }
 
+// ---------------------------------------------------------------------------
+// Currency normalization — CCM reports every cost in the account's currency
+// preference; `currency` converts perspective results for cross-account comparisons.
+// ---------------------------------------------------------------------------
+
+const CURRENCY_FILTER_FIELD = {
+  name: "currency",
+  description: "ISO 4217 code (e.g. USD) to convert costs into using CCM conversion factors. Adds a currency block; omit to get costs in the account currency as reported.",
+};
+
+interface ConversionFactor {
+  source: string;
+  destination: string;
+  factor: number;
+  month: string | null;
+}
+
+/**
+ * Rate to multiply a `from` amount by to get `to`: a direct factor, the
+ * inverse of the reverse factor, or a hop through USD. The latest month wins
+ * when CCM keeps a factor per month.
+ */
+export function ccmConversionRate(factors: ConversionFactor[], from: string, to: string): number | undefined {
+  if (from === to) return 1;
+  const latest = (source: string, destination: string): number | undefined => factors
+    .filter((f) => f.source === source && f.destination === destination)
+    .sort((a, b) => (b.month ?? "").localeCompare(a.month ?? ""))[0]?.factor;
+  const direct = (a: string, b: string): number | undefined => {
+    const forward = latest(a, b);
+    if (forward !== undefined) return forward;
+    const reverse = latest(b, a);
+    return reverse !== undefined ? 1 / reverse : undefined;
+  };
+  const rate = direct(from, to);
+  if (rate !== undefined) return rate;
+  if (from === "USD" || to === "USD") return undefined;
+  const viaUsd = direct(from, "USD");
+  const fromUsd = direct("USD", to);
+  return viaUsd !== undefined && fromUsd !== undefined ? viaUsd * fromUsd : undefined;
+}
+
+/**
+ * Preflight for perspective cost reads: when `currency` is passed, look up the
+ * account currency and, if it differs, the CCM conversion factor, and leave the
+ * result on `input.currency_conversion` for the response extractor.
+ */
+async function ccmCurrencyPreflight(ctx: PreflightContext): Promise<void> {
+  const { input } = ctx;
+  if (input.currency === undefined || input.currency === "") return;
+  const target = String(input.currency).trim().toUpperCase();
+  if (!/^[A-Z]{3}$/.test(target)) {
+    throw new Error(`currency must be a 3-letter ISO 4217 code such as "USD", got "${String(input.currency)}".`);
+  }
+  const pref = await ctx.registry.dispatch(ctx.client, "cost_currency", "get", {}, ctx.signal);
+  const source = isRecord(pref) && typeof pref.currency === "string" ? pref.currency : "USD";
+  let rate: number | undefined = 1;
+  if (source !== target) {
+    const listed = await ctx.registry.dispatch(ctx.client, "cost_currency", "list", {}, ctx.signal);
+    const factors = isRecord(listed) && Array.isArray(listed.items) ? listed.items as ConversionFactor[] : [];
+    rate = ccmConversionRate(factors, source, target);
+    if (rate === undefined) {
+      throw new Error(`No CCM conversion factor from ${source} (the account currency) to ${target}. `
+        + "List available factors with harness_list(resource_type='cost_currency'), or omit currency to get costs in " + source + ".");
+    }
+  }
+  input.currency_conversion = { source, target, rate };
+}
+
+// ---------------------------------------------------------------------------
+// Commitment breakdowns — utilisation and savings per service, per cloud
+// account, or per day, built from the cost_commitment aspects.
+// ---------------------------------------------------------------------------
+
+/** AWS services Commitment Orchestrator manages RIs and savings plans for. */
+const COMMITMENT_SERVICES = [
+  "Amazon Elastic Compute Cloud - Compute",
+  "Amazon Relational Database Service",
+  "Amazon ElastiCache",
+];
+
+const COMMITMENT_BREAKDOWN_CONCURRENCY = 4;
+
+/** Comma-separated string or array param as a trimmed, non-empty list. */
+function listParam(value: unknown): string[] {
+  const items = Array.isArray(value) ? value.map(String) : typeof value === "string" ? value.split(",") : [];
+  return items.map((s) => s.trim()).filter(Boolean);
+}
+
+/**
+ * Collect hook for cost_commitment_breakdown: one cost_commitment read per
+ * service or cloud account (or a single read for the daily trend), a few at a
+ * time, plus estimated savings from recommended purchases per cloud account.
+ * A failed group is reported next to the others instead of failing the call.
+ */
+async function collectCommitmentBreakdown({ client, input, registry, signal }: PreflightContext): Promise<CommitmentBreakdownScan> {
+  const metric = input.metric === undefined || input.metric === "" ? "utilisation" : String(input.metric);
+  if (metric !== "utilisation" && metric !== "savings") {
+    throw new Error(`metric must be "utilisation" or "savings", got "${metric}".`);
+  }
+  const groupBy = input.group_by === undefined || input.group_by === "" ? "service" : String(input.group_by);
+  if (groupBy !== "service" && groupBy !== "account" && groupBy !== "day") {
+    throw new Error(`group_by must be "service", "account", or "day", got "${groupBy}".`);
+  }
+  const services = listParam(input.services);
+  const accounts = listParam(input.cloud_account_ids);
+  if (groupBy === "account" && accounts.length === 0) {
+    throw new Error("group_by=account needs cloud_account_ids (comma-separated AWS account IDs).");
+  }
+  if (groupBy !== "service" && services.length > 1) {
+    throw new Error(`group_by=${groupBy} reads one service at a time; pass a single service or use group_by=service.`);
+  }
+
+  const window = { start_date: input.start_date, end_date: input.end_date };
+  const read = (aspect: string, body: Record<string, unknown>, extra: Record<string, unknown> = {}) =>
+    registry.dispatch(client, "cost_commitment", "get", { aspect, ...window, ...extra, body }, signal);
+  const accountFilter = groupBy !== "account" && accounts.length > 0 ? { cloud_account_id: accounts } : {};
+  const groups: Array<{ key: string; body: Record<string, unknown> }> =
+    groupBy === "service"
+      ? (services.length > 0 ? services : COMMITMENT_SERVICES).map((service) => ({ key: service, body: { Service: service, ...accountFilter } }))
+      : groupBy === "account"
+        ? accounts.map((account) => ({ key: account, body: { ...(services[0] ? { Service: services[0] } : {}), cloud_account_id: [account] } }))
+        : [{ key: services[0] ?? COMMITMENT_SERVICES[0]!, body: { ...(services[0] ? { Service: services[0] } : {}), ...accountFilter } }];
+
+  const { results, errors } = await fanOut(groups, (group) => read(metric, group.body), { concurrency: COMMITMENT_BREAKDOWN_CONCURRENCY, signal });
+
+  const wantsRecommendations = input.include_recommendations !== false && input.include_recommendations !== "false";
+  let recommendations: CommitmentBreakdownScan["recommendations"];
+  if (wantsRecommendations && accounts.length > 0) {
+    const estimated = await fanOut(
+      accounts,
+      (account) => read("estimated_savings", { ...(services[0] ? { Service: services[0] } : {}) }, { cloud_account_id: account }),
+      { concurrency: COMMITMENT_BREAKDOWN_CONCURRENCY, signal },
+    );
+    recommendations = [
+      ...estimated.results.map(({ item, value }) => ({ cloud_account_id: item, response: value })),
+      ...estimated.errors.map(({ item, error }) => ({ cloud_account_id: item, error })),
+    ];
+  }
+
+  return {
+    metric,
+    group_by: groupBy,
+    start_date: String(input.start_date),
+    end_date: String(input.end_date),
+    groups: results.map(({ item, value }) => ({ key: item.key, response: value })),
+    failures: errors.map(({ item, error }) => ({ key: item.key, error })),
+    ...(recommendations ? { recommendations } : {}),
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Cost forecast — daily spend history for a perspective or filter set,
+// projected forward with confidence bounds.
+// ---------------------------------------------------------------------------
+
+const DAY_MS = 86_400_000;
+const FORECAST_DEFAULT_HORIZON_DAYS = 30;
+const FORECAST_MAX_HORIZON_DAYS = 365;
+const FORECAST_DEFAULT_HISTORY_DAYS = 90;
+const FORECAST_MIN_HISTORY_DAYS = 14;
+
+/** Positive integer param within [min, max], or the default when omitted. */
+function dayCount(value: unknown, name: string, fallback: number, min: number, max: number): number {
+  if (value === undefined || value === null || value === "") return fallback;
+  const n = Number(value);
+  if (!Number.isInteger(n) || n < min || n > max) {
+    throw new Error(`${name} must be a whole number of days between ${min} and ${max}, got "${String(value)}".`);
+  }
+  return n;
+}
+
+/**
+ * Parse the `filters` param — `{ field: [values] }` as an object or JSON
+ * string — into perspective idFilters. Fields are predefined dimensions
+ * (region, product, awsUsageaccountid, …) or label keys, as for group_by.
+ */
+function buildFilterSet(value: unknown): { filters: Record<string, unknown>[]; applied: Record<string, string[]> } {
+  let parsed = value;
+  if (typeof value === "string") {
+    try {
+      parsed = JSON.parse(value);
+    } catch {
+      throw new Error(`filters must be a JSON object such as {"region": ["us-east-1"]}, got "${value}".`);
+    }
+  }
+  if (parsed === undefined || parsed === null) return { filters: [], applied: {} };
+  if (!isRecord(parsed)) throw new Error(`filters must be an object mapping a field to its values, such as {"region": ["us-east-1"]}.`);
+  const filters: Record<string, unknown>[] = [];
+  const applied: Record<string, string[]> = {};
+  for (const [field, raw] of Object.entries(parsed)) {
+    const values = listParam(raw);
+    if (values.length === 0) continue;
+    filters.push({ idFilter: { field: buildGroupBy(field)[0]!.entityGroupBy, operator: "IN", values } });
+    applied[field] = values;
+  }
+  return { filters, applied };
+}
+
+/**
+ * Collect hook for cost_forecast: daily spend over the last history_days
+ * complete days (today is partial and would drag the trend down), plus
+ * Harness's own perspective forecast over the same history when a perspective
+ * is given. The Harness forecast is context, so its failure is reported
+ * rather than failing the call.
+ */
+async function collectCostForecast({ client, input, signal }: PreflightContext): Promise<CostForecastScan> {
+  const perspectiveId = typeof input.perspective_id === "string" && input.perspective_id ? input.perspective_id : undefined;
+  const { filters: filterSet, applied } = buildFilterSet(input.filters);
+  if (!perspectiveId && filterSet.length === 0) {
+    throw new Error("cost_forecast needs a perspective_id, filters, or both.");
+  }
+  const horizonDays = dayCount(input.horizon_days, "horizon_days", FORECAST_DEFAULT_HORIZON_DAYS, 1, FORECAST_MAX_HORIZON_DAYS);
+  const historyDays = dayCount(input.history_days, "history_days", FORECAST_DEFAULT_HISTORY_DAYS, FORECAST_MIN_HISTORY_DAYS, 365);
+  const confidence = input.confidence === undefined || input.confidence === "" ? 80 : Number(input.confidence);
+  if (!FORECAST_CONFIDENCE_LEVELS.includes(confidence)) {
+    throw new Error(`confidence must be one of ${FORECAST_CONFIDENCE_LEVELS.join(", ")}, got "${String(input.confidence)}".`);
+  }
+
+  const now = new Date();
+  const todayMs = Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate());
+  const historyStartMs = todayMs - historyDays * DAY_MS;
+  const scopeFilters = (startMs: number, endMs: number) => [
+    ...(perspectiveId ? buildViewFilter(perspectiveId) : []),
+    ...filterSet,
+    ...buildCustomTimeFilters(startMs, endMs),
+  ];
+
+  const history = await client.request<unknown>({
+    method: "POST",
+    path: "/ccm/api/graphql",
+    body: {
+      query: PERSPECTIVE_TIMESERIES_QUERY,
+      operationName: "FetchPerspectiveTimeSeries",
+      variables: {
+        filters: scopeFilters(historyStartMs, todayMs - 1),
+        // Cloud provider keeps the per-day split small; the days are summed.
+        groupBy: [{ timeTruncGroupBy: { resolution: "DAY" } }, buildGroupBy("cloudProvider")[0]],
+        limit: 100,
+        preferences: buildPreferences(),
+        isClusterHourlyData: false,
+      },
+    },
+    signal,
+  });
+
+  let harnessForecast: CostForecastScan["harness_forecast"];
+  if (perspectiveId) {
+    try {
+      harnessForecast = {
+        response: await client.request<unknown>({
+          method: "POST",
+          path: "/ccm/api/graphql",
+          body: {
+            query: PERSPECTIVE_SUMMARY_QUERY,
+            operationName: "FetchPerspectiveDetailsSummaryWithBudget",
+            variables: {
+              filters: scopeFilters(historyStartMs, todayMs - 1),
+              groupBy: buildGroupBy(),
+              aggregateFunction: buildAggregateFunction(),
+              isClusterQuery: false,
+              isClusterHourlyData: false,
+              preferences: buildPreferences(),
+            },
+          },
+          signal,
+        }),
+      };
+    } catch (err) {
+      harnessForecast = { error: err instanceof Error ? err.message : String(err) };
+    }
+  }
+
+  return {
+    ...(perspectiveId ? { perspective_id: perspectiveId } : {}),
+    filters: applied,
+    history_start_ms: historyStartMs,
+    history_days: historyDays,
+    horizon_days: horizonDays,
+    confidence,
+    history,
+    ...(harnessForecast ? { harness_forecast: harnessForecast } : {}),
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Asset governance — Cloud Custodian rules, dry-run evaluations, and the
+// executions (with potential savings) they produce.
+// ---------------------------------------------------------------------------
+
+const GOVERNANCE_CLOUD_PROVIDERS = ["AWS", "AZURE", "GCP"] as const;
+
+/**
+ * Preflight for cost_governance_rule dry_run: load the rule (its YAML and
+ * cloud are part of the evaluation request) and check the target. The rule
+ * lands on input.governance_rule for the body builder.
+ */
+async function resolveGovernanceRule({ client, input, registry, signal }: PreflightContext): Promise<void> {
+  const ruleId = typeof input.rule_id === "string" ? input.rule_id : "";
+  if (!ruleId) throw new Error("rule_id is required. List rules with harness_list(resource_type='cost_governance_rule').");
+  if (listParam(input.target_account).length !== 1) {
+    throw new Error("target_account is required: the one cloud account (AWS account ID, Azure subscription, or GCP project) to evaluate the rule against.");
+  }
+  if (listParam(input.regions).length === 0) {
+    throw new Error("regions is required: comma-separated regions to evaluate, e.g. 'us-east-1,us-west-2'.");
+  }
+  const rule = await registry.dispatch(client, "cost_governance_rule", "get", { rule_id: ruleId }, signal);
+  if (!isRecord(rule) || typeof rule.rulesYaml !== "string") {
+    throw new Error(`Governance rule "${ruleId}" has no rule YAML to evaluate.`);
+  }
+  input.governance_rule = rule;
+}
+
 // ---------------------------------------------------------------------------
 // Toolset definition: 6 resource types covering REST + GraphQL
 // ---------------------------------------------------------------------------
@@ -593,7 +966,7 @@ export const ccmToolset: ToolsetDefinition = {
   name: "ccm",
   displayName: "Cloud Cost Management",
   description:
-    "Cloud cost visibility, analysis, recommendations, and anomaly detection. Covers perspectives, cost breakdowns, time series, summaries, recommendations, and anomalies.",
+    "Cloud cost visibility, analysis, recommendations, and anomaly detection. Covers perspectives, cost breakdowns, time series, summaries, forecasts, recommendations, and anomalies.",
   resources: [
     // ------------------------------------------------------------------
     // 1. cost_perspective — REST CRUD for perspective management
@@ -836,6 +1209,7 @@ Optional: group_by (predefined: ${VALID_GROUP_BY_FIELDS.join(", ")}, OR any labe
         { name: "time_filter", description: "Time range filter", enum: [...VALID_TIME_FILTERS] },
         { name: "start_time", description: "Custom window start in epoch milliseconds. When set with end_time, overrides time_filter — use for historical/custom ranges the relative enum can't express (e.g. a past quarter).", type: "number" },
         { name: "end_time", description: "Custom window end in epoch milliseconds. Pair with start_time.", type: "number" },
+        CURRENCY_FILTER_FIELD,
         { name: "limit", description: "Result limit", type: "number" },
         { name: "offset", description: "Pagination offset", type: "number" },
       ],
@@ -863,6 +1237,7 @@ Optional: group_by (predefined: ${VALID_GROUP_BY_FIELDS.join(", ")}, OR any labe
               preferences: buildPreferences(),
             },
           }),
+          preflight: ccmCurrencyPreflight,
           responseExtractor: ccmBreakdownExtract,
           description:
             "Get cost breakdown by dimension for a perspective. Group by region, awsServicecode, product, cloudProvider, etc.",
@@ -870,6 +1245,85 @@ Optional: group_by (predefined: ${VALID_GROUP_BY_FIELDS.join(", ")}, OR any labe
       },
     },
 
+    // ------------------------------------------------------------------
+    // 2b. cost_cluster_workload — Kubernetes cost per namespace, workload,
+    //     node pool, or node for one cluster
+    //    Answers: "Which team's namespace is driving the spike?"
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_cluster_workload",
+      displayName: "Cluster Workload Cost",
+      description: `Kubernetes cost for one cluster broken down by namespace, workload, node pool, or node, from CCM cluster data. Answers "which namespace or workload is driving the spike?" Each row has its cost, its change against the previous period of the same length, and the idle/unallocated and CPU/memory/storage split.
+
+Required: cluster (cluster name, as shown in CCM).
+Optional: group_by (${CLUSTER_GROUP_BY.join(", ")}; default namespace), namespace (comma-separated, narrows any grouping), time_filter (${VALID_TIME_FILTERS.join(", ")}) or start_time/end_time, perspective_id, limit, offset.`,
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: ["cluster"],
+      searchAliases: ["kubernetes cost", "k8s cost", "namespace cost", "workload cost", "node pool cost"],
+      relatedResources: [
+        { resourceType: "cost_filter_value", relationship: "lookup", description: "Find cluster names (value_type clusterName) and namespaces" },
+        { resourceType: "cost_recommendation", relationship: "sibling", description: "Workload and node pool right-sizing recommendations for the costly rows" },
+      ],
+      listFilterFields: [
+        { name: "cluster", description: "Cluster name as shown in CCM", required: true },
+        { name: "group_by", description: "Kubernetes dimension to break cost down by (default namespace)", enum: [...CLUSTER_GROUP_BY] },
+        { name: "namespace", description: "Only these namespaces (comma-separated) — e.g. group_by=workload within one namespace" },
+        { name: "perspective_id", description: "Limit to a cluster perspective's scope" },
+        { name: "time_filter", description: "Time range filter (default LAST_7)", enum: [...VALID_TIME_FILTERS] },
+        { name: "start_time", description: "Custom window start in epoch milliseconds. When set with end_time, overrides time_filter.", type: "number" },
+        { name: "end_time", description: "Custom window end in epoch milliseconds. Pair with start_time.", type: "number" },
+        CURRENCY_FILTER_FIELD,
+        { name: "limit", description: "Result limit (default 25)", type: "number" },
+        { name: "offset", description: "Pagination offset", type: "number" },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/ccm/api/graphql",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: (input) => {
+            const cluster = listParam(input.cluster);
+            if (cluster.length === 0) {
+              throw new Error("cluster is required. Find cluster names with harness_list(resource_type='cost_filter_value', filters={value_type: 'clusterName'}).");
+            }
+            const groupBy = (input.group_by as string | undefined) || "namespace";
+            const field = CLUSTER_FIELDS[groupBy];
+            if (!field || groupBy === "cluster") {
+              throw new Error(`group_by must be one of ${CLUSTER_GROUP_BY.join(", ")}, got "${groupBy}".`);
+            }
+            const namespaces = listParam(input.namespace);
+            const { startMs, endMs } = customWindow(input);
+            return {
+              query: PERSPECTIVE_CLUSTER_GRID_QUERY,
+              operationName: "FetchPerspectiveClusterGrid",
+              variables: {
+                filters: [
+                  ...(input.perspective_id ? buildViewFilter(input.perspective_id as string) : []),
+                  { idFilter: { field: CLUSTER_FIELDS.cluster, operator: "IN", values: cluster } },
+                  ...(namespaces.length > 0 ? [{ idFilter: { field: CLUSTER_FIELDS.namespace, operator: "IN", values: namespaces } }] : []),
+                  ...resolveTimeFilters((input.time_filter as string) ?? "LAST_7", startMs, endMs),
+                ],
+                groupBy: [{ entityGroupBy: field }],
+                limit: (input.limit as number) ?? 25,
+                offset: (input.offset as number) ?? 0,
+                aggregateFunction: buildAggregateFunction(),
+                isClusterOnly: true,
+                isClusterHourlyData: false,
+                preferences: buildPreferences(),
+              },
+            };
+          },
+          preflight: ccmCurrencyPreflight,
+          responseExtractor: ccmClusterWorkloadExtract,
+          description:
+            "Break one cluster's cost down by namespace, workload, node pool, or node, largest first. "
+            + "Rows carry cost_trend (% change vs the previous period), idle/unallocated/utilized cost, and CPU/memory/storage cost; "
+            + "largest_increases ranks the rows whose cost grew the most.",
+        },
+      },
+    },
+
     // ------------------------------------------------------------------
     // 3. cost_timeseries — GraphQL perspective time series
     //    Replaces: ccm_perspective_time_series from the official server
@@ -891,6 +1345,7 @@ Optional: time_filter (${VALID_TIME_FILTERS.join(", ")}), time_resolution (DAY,
         { name: "start_time", description: "Custom window start in epoch milliseconds. When set with end_time, overrides time_filter — use for historical/custom ranges the relative enum can't express (e.g. a past quarter).", type: "number" },
         { name: "end_time", description: "Custom window end in epoch milliseconds. Pair with start_time.", type: "number" },
         { name: "time_resolution", description: "Time resolution for aggregation", enum: ["DAY", "MONTH", "WEEK"] },
+        CURRENCY_FILTER_FIELD,
         { name: "limit", description: "Result limit", type: "number" },
       ],
       operations: {
@@ -921,6 +1376,7 @@ Optional: time_filter (${VALID_TIME_FILTERS.join(", ")}), time_resolution (DAY,
               },
             };
           },
+          preflight: ccmCurrencyPreflight,
           responseExtractor: ccmTimeseriesExtract,
           description:
             "Get cost time series data for a perspective. Shows cost trends over time grouped by a dimension.",
@@ -950,6 +1406,7 @@ Use with no perspective_id to get CCM metadata (available connectors, default pe
         { name: "time_filter", description: "Time range filter" },
         { name: "start_time", description: "Custom window start in epoch milliseconds. When set with end_time, overrides time_filter — use for historical/custom ranges the relative enum can't express (e.g. a past quarter).", type: "number" },
         { name: "end_time", description: "Custom window end in epoch milliseconds. Pair with start_time.", type: "number" },
+        CURRENCY_FILTER_FIELD,
       ],
       operations: {
         list: {
@@ -986,6 +1443,7 @@ Use with no perspective_id to get CCM metadata (available connectors, default pe
               },
             };
           },
+          preflight: ccmCurrencyPreflight,
           responseExtractor: ccmSummaryExtract,
           description:
             "Get cost summary with trend, forecast, idle/unallocated costs. Omit perspective_id to get CCM metadata.",
@@ -999,9 +1457,61 @@ Use with no perspective_id to get CCM metadata (available connectors, default pe
             operationName: "FetchPerspectiveBudget",
             variables: { perspectiveId: input.perspective_id as string },
           }),
-          responseExtractor: gqlExtract("budgetSummaryList"),
+          preflight: ccmCurrencyPreflight,
+          responseExtractor: ccmBudgetExtract,
           description:
-            "Get budget status for a perspective (budget amount, actual cost, time remaining).",
+            "Get budget status for a perspective (budget amount, actual cost, time remaining). Pass params.currency to convert amounts.",
+          paramsSchema: {
+            fields: [
+              { name: "currency", required: false, description: CURRENCY_FILTER_FIELD.description },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+
+    // ------------------------------------------------------------------
+    // 4b. cost_forecast — projected spend with confidence bounds
+    //    Answers: "What will we spend next month/quarter?"
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_forecast",
+      displayName: "Cost Forecast",
+      description: `Forecast spend for a perspective or a filter set over a future window, with confidence bounds, for budget planning. Fits a trend to recent daily spend and projects it forward.
+
+Required: perspective_id, filters ({field: [values]} using predefined fields ${VALID_GROUP_BY_FIELDS.filter((f) => f !== "none").join(", ")}, or label keys), or both.
+Optional: horizon_days (default ${FORECAST_DEFAULT_HORIZON_DAYS}, max ${FORECAST_MAX_HORIZON_DAYS}), history_days (default ${FORECAST_DEFAULT_HISTORY_DAYS}), confidence (80, 90, 95), currency.`,
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: ["perspective_id"],
+      relatedResources: [
+        { resourceType: "cost_perspective", relationship: "parent", description: "Find perspective_id to forecast" },
+        { resourceType: "cost_timeseries", relationship: "source", description: "The daily history the forecast is fitted to" },
+        { resourceType: "cost_filter_value", relationship: "lookup", description: "Find values for filters (regions, products, label values)" },
+      ],
+      operations: {
+        get: {
+          method: "POST",
+          path: "/ccm/api/graphql",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          preflight: ccmCurrencyPreflight,
+          collect: collectCostForecast,
+          responseExtractor: ccmCostForecastExtract,
+          skipCompact: true,
+          description:
+            "Project spend over the next horizon_days from a linear trend fitted to the last history_days of daily spend. "
+            + "Returns the total with lower/upper bounds at the chosen confidence, per-month subtotals, the trend and fit quality, "
+            + "and Harness's own forecast for the perspective when perspective_id is passed.",
+          paramsSchema: {
+            fields: [
+              { name: "perspective_id", required: false, description: "Perspective to forecast. Combine with filters to narrow it." },
+              { name: "filters", required: false, description: "Filter set as {field: [values]}, e.g. {\"region\": [\"us-east-1\"], \"team\": [\"payments\"]}. Fields are predefined dimensions or label keys." },
+              { name: "horizon_days", required: false, description: `Days to forecast, starting today (default ${FORECAST_DEFAULT_HORIZON_DAYS}, max ${FORECAST_MAX_HORIZON_DAYS})` },
+              { name: "history_days", required: false, description: `Complete days of history to fit (default ${FORECAST_DEFAULT_HISTORY_DAYS}, ${FORECAST_MIN_HISTORY_DAYS}-365)` },
+              { name: "confidence", required: false, description: "Confidence level for the bounds: 80 (default), 90, or 95" },
+              { name: "currency", required: false, description: CURRENCY_FILTER_FIELD.description },
+            ],
+          } satisfies ParamsSchema,
         },
       },
     },
@@ -1588,6 +2098,41 @@ For cost time-series data, use harness_get with start_time and end_time.`,
       },
     },
 
+    // ------------------------------------------------------------------
+    // 8b. cost_currency — account currency preference + conversion factors
+    //     Backs the `currency` param on perspective cost reads.
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_currency",
+      displayName: "Cost Currency",
+      description: "Currency CCM reports costs in for this account, and the conversion factors CCM keeps between currencies. get returns {currency, symbol, locale, set_at, configured}; list returns factors as {source, destination, factor, month}. "
+        + "Perspective reads (cost_breakdown, cost_timeseries, cost_summary) convert costs with these factors when passed currency='USD' (or any ISO 4217 code), so compare accounts in one currency instead of mixing units.",
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: [],
+      operations: {
+        get: {
+          method: "POST",
+          path: "/ccm/api/graphql",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: () => ({
+            query: CCM_METADATA_QUERY,
+            operationName: "FetchCcmMetaData",
+            variables: {},
+          }),
+          responseExtractor: ccmCurrencyPreferenceExtract,
+          description: "Get the account currency preference (defaults to USD when none was set).",
+        },
+        list: {
+          method: "GET",
+          path: "/ccm/api/currency-preference/conversion-factors",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          responseExtractor: ccmConversionFactorsExtract,
+          description: "List CCM currency conversion factors (source → destination, per month).",
+        },
+      },
+    },
+
     // ------------------------------------------------------------------
     // 9. cost_filter_value — GraphQL perspective filter values (multi-purpose)
     //    Used for: label keys, label values, field values (region, account, etc.)
@@ -1876,6 +2421,45 @@ For cost time-series data, use harness_get with start_time and end_time.`,
       },
     },
 
+    // ------------------------------------------------------------------
+    // 12b. cost_workload_patch — K8s right-sizing patch from a workload rec
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_workload_patch",
+      displayName: "Cost Workload Right-Sizing Patch",
+      description: "Turns a CCM Kubernetes workload recommendation into the exact strategic-merge YAML patch (container requests/limits) plus a kubectl command. Supports get. Pass repo_id and file_path to also get the harness_* steps that open a Harness Code PR with the change.",
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: ["recommendation_id"],
+      searchAliases: ["rightsizing", "right-size", "resource requests", "k8s patch"],
+      relatedResources: [
+        { resourceType: "cost_recommendation", relationship: "generated-from", description: "List workload recommendations (resourceType WORKLOAD) to find recommendation_id, resourceName, and namespace." },
+        { resourceType: "pull_request", relationship: "applied-via", description: "Open a Harness Code PR with the patched manifest — follow pull_request_steps in the response." },
+      ],
+      deepLinkTemplate: "/ng/account/{accountId}/ce/recommendations",
+      operations: {
+        get: {
+          method: "GET",
+          path: "/ccm/api/recommendation/details/workload",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: { recommendation_id: "id" },
+          responseExtractor: ccmWorkloadPatchExtract,
+          description: "Generate a right-sizing patch for a workload recommendation. Returns workload {kind, name, namespace}, containers [{name, current, proposed}], patch_yaml (strategic merge patch), kubectl_command, and — when repo_id and file_path are passed — pull_request_steps for a Harness Code PR.",
+          paramsSchema: {
+            fields: [
+              { name: "preset", required: false, description: "Which recommendation to apply: recommended (default), burstable, guaranteed, or a percentile (p50, p80, p90, p95, p99)." },
+              { name: "workload_name", required: false, description: "Workload name for the patch metadata. Defaults to the value in the recommendation when present." },
+              { name: "namespace", required: false, description: "Workload namespace for the patch metadata. Defaults to the value in the recommendation when present." },
+              { name: "kind", required: false, description: "Workload kind: Deployment (default), StatefulSet, DaemonSet, ReplicaSet, Job, CronJob, or Pod." },
+              { name: "repo_id", required: false, description: "Harness Code repository holding the manifest. With file_path, adds pull_request_steps to the response." },
+              { name: "file_path", required: false, description: "Manifest path in repo_id that defines the workload." },
+              { name: "branch", required: false, description: "Base branch for the PR. Defaults to main." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+
     // ------------------------------------------------------------------
     // 13. cost_commitment — consolidated Lightwing commitment data
     //    Replaces: cost_commitment_coverage, cost_commitment_savings,
@@ -1939,6 +2523,52 @@ For cost time-series data, use harness_get with start_time and end_time.`,
       },
     },
 
+    // ------------------------------------------------------------------
+    // 13b. cost_commitment_breakdown — utilisation/savings per service,
+    //      per cloud account, or per day, plus recommended purchases
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_commitment_breakdown",
+      displayName: "Cost Commitment Breakdown",
+      description:
+        "Commitment (RI/savings plan) utilisation or savings broken down per AWS service, per cloud account, or per day, with estimated savings from recommended purchases. "
+        + "Complements cost_commitment, which reports one service (EC2 by default) as a whole. "
+        + "harness_get with metric (utilisation | savings), group_by (service | account | day), start_date, end_date.",
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: ["metric"],
+      deepLinkTemplate: "/ng/account/{accountId}/ce/commitment-orchestration",
+      relatedResources: [
+        { resourceType: "cost_commitment", relationship: "source", description: "Each group is one cost_commitment read (aspect utilisation, savings, or estimated_savings)" },
+      ],
+      operations: {
+        get: {
+          method: "POST",
+          path: "/lw/co/api/accounts/{accountId}/v1/detail/commitment_utilisation",
+          pathParams: { account_id: "accountId" },
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectCommitmentBreakdown,
+          responseExtractor: ccmCommitmentBreakdownExtract,
+          skipCompact: true,
+          description:
+            "Break commitment utilisation or savings down by service (default: EC2, RDS, ElastiCache), by cloud account, or by day. "
+            + "Returns rows per group and commitment type with the reported totals, a trend per commitment type for group_by=day, "
+            + "and estimated_savings per cloud account from recommended purchases when cloud_account_ids is passed.",
+          paramsSchema: {
+            fields: [
+              { name: "metric", required: false, description: "utilisation (default) or savings" },
+              { name: "group_by", required: false, description: "service (default) | account | day" },
+              { name: "start_date", required: true, description: "Start date (YYYY-MM-DD)" },
+              { name: "end_date", required: true, description: "End date (YYYY-MM-DD)" },
+              { name: "services", required: false, description: "Comma-separated AWS service names, e.g. 'Amazon Relational Database Service'. group_by=account or day takes one." },
+              { name: "cloud_account_ids", required: false, description: "Comma-separated AWS account IDs. Required for group_by=account; otherwise filters the other groupings." },
+              { name: "include_recommendations", required: false, description: "Set false to skip estimated savings from recommended purchases (default true when cloud_account_ids is passed)" },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+
     // ------------------------------------------------------------------
     // 14. unit_metric — Unit Cost Metrics API
     //    Full CRUD for unit metrics with time series data
@@ -2041,5 +2671,160 @@ Requires CCM_UNIT_COST_METRICS feature flag.`,
         },
       },
     },
+
+    // ------------------------------------------------------------------
+    // 15. cost_governance_rule — asset governance (Cloud Custodian) rules
+    //    with a dry-run evaluation against one cloud account
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_governance_rule",
+      displayName: "Cost Governance Rule",
+      description:
+        "Cloud asset governance rules (Cloud Custodian policies) that find idle or wasteful resources and clean them up. "
+        + "harness_list to find rules, harness_get with rule_id for the rule YAML, and the dry_run action to evaluate a rule against one cloud account and region set without acting on any resource. "
+        + "Results land in cost_governance_execution.",
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: ["rule_id"],
+      searchAliases: ["asset governance", "cloud custodian", "governance rule", "cost cleanup rule"],
+      deepLinkTemplate: "/ng/account/{accountId}/ce/governance/rules",
+      relatedResources: [
+        { resourceType: "cost_governance_execution", relationship: "child", description: "Executions of this rule, with resources found and potential savings" },
+      ],
+      listFilterFields: [
+        { name: "cloud_provider", description: "Cloud the rules target", enum: [...GOVERNANCE_CLOUD_PROVIDERS] },
+        { name: "search", description: "Filter rules by name (substring match)" },
+        { name: "ootb", description: "true for Harness's out-of-the-box rules only, false for custom rules only", type: "boolean" },
+        { name: "limit", description: "Result limit (default 50)", type: "number" },
+        { name: "offset", description: "Pagination offset", type: "number" },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/ccm/api/governance/rule/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: (input) => {
+            const query: Record<string, unknown> = {
+              limit: (input.limit as number) ?? 50,
+              offset: (input.offset as number) ?? 0,
+            };
+            if (input.cloud_provider) query.cloudProvider = String(input.cloud_provider).toUpperCase();
+            if (input.search) query.search = input.search;
+            if (input.ootb !== undefined && input.ootb !== "") query.isOOTB = input.ootb === true || input.ootb === "true";
+            return { query };
+          },
+          responseExtractor: ccmGovernanceRuleListExtract,
+          description: "List asset governance rules: name, cloud, resource type, and whether Harness ships the rule. Rule YAML is left out; get a rule to read it.",
+        },
+        get: {
+          method: "POST",
+          path: "/ccm/api/governance/rule/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: (input) => ({ query: { ruleIds: [input.rule_id], limit: 1, offset: 0 } }),
+          responseExtractor: ccmGovernanceRuleExtract,
+          description: "Get one asset governance rule with its Cloud Custodian YAML (rulesYaml).",
+        },
+      },
+      executeActions: {
+        dry_run: {
+          method: "POST",
+          path: "/ccm/api/governance/enqueueAdhoc",
+          operationPolicy: { risk: "low_write", retryPolicy: "do_not_retry" },
+          preflight: resolveGovernanceRule,
+          bodyBuilder: (input) => {
+            const rule = input.governance_rule as Record<string, unknown>;
+            return {
+              ruleId: input.rule_id,
+              policy: rule.rulesYaml,
+              ruleCloudProviderType: rule.cloudProvider,
+              // Always a dry run: the rule's actions are reported, never taken.
+              isDryRun: true,
+              targetRegions: listParam(input.regions),
+              targetAccountDetails: [{
+                targetInfo: listParam(input.target_account)[0],
+                ...(input.connector_id ? { connectorId: input.connector_id } : {}),
+              }],
+            };
+          },
+          bodySchema: {
+            description: "No body required. The rule and target come from rule_id, target_account, regions, and connector_id.",
+            fields: [],
+          },
+          responseExtractor: ccmGovernanceEnqueueExtract,
+          actionDescription:
+            "Evaluate a governance rule in dry-run mode: Cloud Custodian finds the matching resources but takes none of the rule's actions. "
+            + "Requires rule_id, target_account (one AWS account ID, Azure subscription, or GCP project), and regions (comma-separated). "
+            + "Pass connector_id when several CCM connectors cover the account. Returns the execution IDs to read from cost_governance_execution.",
+        },
+      },
+    },
+
+    // ------------------------------------------------------------------
+    // 16. cost_governance_execution — rule evaluations, the resources
+    //    they matched, and potential/realized savings
+    // ------------------------------------------------------------------
+    {
+      resourceType: "cost_governance_execution",
+      displayName: "Cost Governance Execution",
+      description:
+        "Executions of asset governance rules (scheduled enforcements and dry runs): status, resources matched, and potential and realized savings. "
+        + "harness_list to filter by rule, account, region, or status; harness_get with execution_id for one execution, or view=resources for the resources it matched.",
+      toolset: "ccm",
+      scope: "account",
+      identifierFields: ["execution_id"],
+      searchAliases: ["governance evaluation", "rule execution", "custodian run", "governance savings"],
+      deepLinkTemplate: "/ng/account/{accountId}/ce/governance/evaluations",
+      relatedResources: [
+        { resourceType: "cost_governance_rule", relationship: "parent", description: "The rule that was evaluated; run dry_run to start a new evaluation" },
+      ],
+      listFilterFields: [
+        { name: "rule_id", description: "Executions of these rules (comma-separated rule IDs)" },
+        { name: "target_account", description: "Executions against these cloud accounts (comma-separated)" },
+        { name: "region", description: "Executions in these regions (comma-separated)" },
+        { name: "cloud_provider", description: "Cloud the executions ran against", enum: [...GOVERNANCE_CLOUD_PROVIDERS] },
+        { name: "status", description: "Execution status", enum: ["ENQUEUED", "SUCCESS", "FAILED"] },
+        { name: "limit", description: "Result limit (default 25)", type: "number" },
+        { name: "offset", description: "Pagination offset", type: "number" },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/ccm/api/governance/execution/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: (input) => {
+            const filter: Record<string, unknown> = {
+              limit: (input.limit as number) ?? 25,
+              offset: (input.offset as number) ?? 0,
+            };
+            if (input.rule_id) filter.ruleIds = listParam(input.rule_id);
+            if (input.target_account) filter.targetAccount = listParam(input.target_account);
+            if (input.region) filter.region = listParam(input.region);
+            if (input.cloud_provider) filter.cloudProvider = String(input.cloud_provider).toUpperCase();
+            if (input.status) filter.executionStatus = input.status;
+            return { ruleExecutionFilter: filter };
+          },
+          responseExtractor: ccmGovernanceExecutionListExtract,
+          description: "List governance rule executions with resources matched and potential/realized savings, plus savings totals for the page.",
+        },
+        get: {
+          method: "GET",
+          path: "/ccm/api/governance/execution/{ruleExecutionId}",
+          pathBuilder: (input) => {
+            const id = encodeURIComponent(String(input.execution_id ?? ""));
+            return input.view === "resources"
+              ? `/ccm/api/governance/execution/details/${id}`
+              : `/ccm/api/governance/execution/${id}`;
+          },
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          responseExtractor: ccmGovernanceExecutionExtract,
+          description: "Get one execution's status, resource count, savings, and error. Pass view=resources for the resources the rule matched.",
+          paramsSchema: {
+            fields: [
+              { name: "view", required: false, description: "summary (default) or resources — the resources the rule matched, as Cloud Custodian reported them" },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
   ],
 };
@@ -210,8 +210,9 @@ Supports search by name and sort by lastModifiedAt or name.`;
 
 export const descListExperiments = `List chaos experiments with optional filtering.
 Supports filtering by experiment name, status, infrastructure (ID, name, active state), tags, environment, date range, and bulk experiment IDs.
-Default page size is 15, max 50.`;
-export const descGetExperiment = `Get chaos experiment details including revisions and recent run details. The backend for this endpoint REQUIRES the internal UUID (experimentID, e.g. "ef9199b6-0248-4c0b-9d63-9176bf2b7123") — the human-readable identity slug from a UI URL will not work here. If you only have the slug, first call harness_list with resource_type=chaos_experiment and experiment_name=<slug> to obtain the experimentID.`;
+Default page size is 15, max 50.
+Each experiment that has run carries last_run: {run_id, phase, resilience_score, run_at} for its most recent run — experiments without last_run have never run.`;
+export const descGetExperiment = `Get chaos experiment details including revisions and recent run details. Adds recent_runs ({run_id, notify_id, phase, resilience_score, run_at}, newest first) and resilience ({runs, scored_runs, latest_score, average_score}) when the experiment has run. The backend for this endpoint REQUIRES the internal UUID (experimentID, e.g. "ef9199b6-0248-4c0b-9d63-9176bf2b7123") — the human-readable identity slug from a UI URL will not work here. If you only have the slug, first call harness_list with resource_type=chaos_experiment and experiment_name=<slug> to obtain the experimentID.`;
 
 export const descGetExperimentRun = `Get the full timeline of a chaos experiment run. This is a read-only endpoint — it does NOT trigger a run.
 Returns the execution pipeline: individual fault/probe/action nodes with status, timing, chaos data, and error details.
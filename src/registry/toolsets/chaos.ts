@@ -6,6 +6,7 @@ import {
   ngExtract,
   chaosPageExtract,
   chaosExperimentListExtract,
+  chaosExperimentGetExtract,
   chaosInputSetListExtract,
   chaosAppMapPageExtract,
   chaosProbeListExtract,
@@ -175,6 +176,24 @@ const chaosComponentVarExtract = (raw: unknown): unknown => {
   return r.items?.[0] ?? raw;
 };
 
+/** Fields kept when compacting a chaos experiment list item. */
+const CHAOS_EXPERIMENT_COMPACT_FIELDS = [
+  "experimentId", "identity", "name", "description", "tags", "infraType", "updatedAt", "last_run", "openInHarness",
+] as const;
+
+/**
+ * Compact a chaos experiment list item. The generic key whitelist would drop
+ * last_run — the latest run's phase and resilience score that a chaos
+ * coverage review reads — so keep it with the identifying fields.
+ */
+function compactChaosExperiment(item: Record<string, unknown>): Record<string, unknown> {
+  const slim: Record<string, unknown> = {};
+  for (const key of CHAOS_EXPERIMENT_COMPACT_FIELDS) {
+    if (item[key] !== undefined) slim[key] = item[key];
+  }
+  return slim;
+}
+
 /**
  * Parse input.body when LLMs double-serialize it as a JSON string instead of an object.
  * Fails loudly on malformed JSON so callers' defaults can never silently produce a
@@ -489,6 +508,7 @@ export const chaosToolset: ToolsetDefinition = {
       scopeParams: CHAOS_SCOPE,
       identifierFields: ["experiment_id"],
       deepLinkTemplate: "/ng/account/{accountId}/module/chaos/orgs/{orgIdentifier}/projects/{projectIdentifier}/experiments/{experimentId}/chaos-studio",
+      compactItem: compactChaosExperiment,
       searchAliases: [
         "chaos test", "fault injection", "fault injection experiment",
         "blast radius experiment", "resilience test", "chaos engineering test",
@@ -516,6 +536,7 @@ export const chaosToolset: ToolsetDefinition = {
         { name: "exclude_automation", description: descExperimentExcludeAutomation, type: "boolean" },
       ],
       relatedResources: [
+        { resourceType: "chaos_experiment_run", relationship: "child", description: "Fault-by-fault timeline of one run. get returns recent_runs with run_id and resilience_score; pass run_id to chaos_experiment_run get for the detail." },
         { resourceType: "chaos_experiment_variable", relationship: "child", description: "Runtime variables for the experiment. List these to discover required inputs before running." },
         { resourceType: "chaos_input_set", relationship: "child", description: "Saved collections of variable overrides. Create input sets to reuse runtime configurations across runs." },
         { resourceType: "chaos_application_map", relationship: "scoped_by", description: "When an experiment is bound to a chaos_application_map, the backend auto-emits workload=<name> AND service=<name> system tags. To find every experiment that targets a workload/service inside a given app map, either filter chaos_experiment with target_network_map_ids=<map> (returns ALL experiments on that map) or list services via chaos_application_map.get and use tags=workload=<name> / tags=service=<name>." },
@@ -553,7 +574,7 @@ export const chaosToolset: ToolsetDefinition = {
           path: `${CHAOS}/rest/v2/experiments/{experimentId}`,
           operationPolicy: { risk: "read", retryPolicy: "safe" },
           pathParams: { experiment_id: "experimentId" },
-          responseExtractor: passthrough,
+          responseExtractor: chaosExperimentGetExtract,
           description: descGetExperiment,
         },
         delete: {
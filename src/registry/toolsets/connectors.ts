@@ -1,5 +1,6 @@
 import type { ToolsetDefinition, BodySchema } from "../types.js";
 import { buildBodyNormalized } from "../../utils/body-normalizer.js";
+import { isRecord } from "../../utils/type-guards.js";
 import { ngExtract, pageExtract } from "../extractors.js";
 
 const connectorCreateSchema: BodySchema = {
@@ -54,6 +55,7 @@ export const connectorsToolset: ToolsetDefinition = {
         { name: "include_all_connectors_available_at_scope", type: "boolean", description: "When true, also return connectors inherited from parent scopes (org/account). Default: false. Set to true when picking an APM/observability connector for chaos_probe apmProbe." },
       ],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/settings/connectors/{connectorIdentifier}",
+      restoreBody: (prior) => (isRecord(prior) && isRecord(prior.connector) ? { connector: prior.connector } : undefined),
       operations: {
         list: {
           method: "POST",
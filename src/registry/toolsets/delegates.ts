@@ -1,5 +1,85 @@
-import type { ToolsetDefinition } from "../types.js";
-import { ngExtract, v1Unwrap } from "../extractors.js";
+import type { PreflightContext, ToolsetDefinition } from "../types.js";
+import { delegateUpgradeStatusExtract, ngExtract, passthrough, v1Unwrap, type DelegateUpgradeScan } from "../extractors.js";
+import { isRecord } from "../../utils/type-guards.js";
+
+/** Scope input forwarded to delegate_token calls made on the caller's behalf. */
+function delegateTokenScope(input: Record<string, unknown>): Record<string, unknown> {
+  const scope: Record<string, unknown> = {};
+  for (const key of ["resource_scope", "org_id", "project_id"]) {
+    if (input[key] !== undefined && input[key] !== "") scope[key] = input[key];
+  }
+  return scope;
+}
+
+function errorMessage(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+async function collectDelegateUpgradeStatus(ctx: PreflightContext): Promise<DelegateUpgradeScan> {
+  const { client, input, registry, signal } = ctx;
+  const [latest, delegates, tokens] = await Promise.all([
+    client.request<unknown>({ method: "GET", path: "/ng/api/delegate-setup/latest-supported-version", signal })
+      .then((resp) => ({ value: isRecord(resp) && isRecord(resp.resource) ? resp.resource : null }))
+      .catch((err: unknown) => ({ value: null, error: errorMessage(err) })),
+    registry.dispatch(client, "delegate", "list", { all: "true" }, signal),
+    registry.dispatch(client, "delegate_token", "list", delegateTokenScope(input), signal)
+      .then((resp) => ({ value: Array.isArray(resp) ? resp : [] }))
+      .catch((err: unknown) => ({ value: null, error: errorMessage(err) })),
+  ]);
+  return {
+    latest: latest.value,
+    ...("error" in latest ? { latest_error: latest.error } : {}),
+    delegates: Array.isArray(delegates) ? delegates : [],
+    tokens: tokens.value,
+    ...("error" in tokens ? { tokens_error: tokens.error } : {}),
+  };
+}
+
+/**
+ * Replace a delegate token: create a new one and, when asked, revoke the old
+ * one. Delegates keep the token they were installed with, so revoking before
+ * they are redeployed with the new value disconnects them — hence opt-in.
+ */
+async function regenerateDelegateToken(ctx: PreflightContext): Promise<unknown> {
+  const { client, input, registry, signal } = ctx;
+  const oldName = input.token_name;
+  if (typeof oldName !== "string" || !oldName) throw new Error("token_name is required");
+  const body = isRecord(input.body) ? input.body : {};
+  const option = (name: string): unknown => body[name] ?? input[name];
+  const scope = delegateTokenScope(input);
+
+  const existing = await registry.dispatch(client, "delegate_token", "get", { ...scope, token_name: oldName }, signal);
+  const newName = typeof option("new_token_name") === "string" && option("new_token_name")
+    ? option("new_token_name") as string
+    : `${oldName}_${new Date().toISOString().slice(0, 10).replace(/-/g, "")}`;
+  const revokeAfter = option("revoke_after");
+  const created = await registry.dispatch(client, "delegate_token", "create", {
+    ...scope,
+    token_name: newName,
+    ...(revokeAfter !== undefined ? { revoke_after: revokeAfter } : {}),
+  }, signal);
+
+  let oldStatus = isRecord(existing) ? existing.status ?? null : null;
+  if (option("revoke_old") === true) {
+    const level = scope.resource_scope ?? "project";
+    const params: Record<string, string> = { status: "REVOKED" };
+    if (level !== "account") params.orgIdentifier = (scope.org_id as string | undefined) ?? registry.orgId ?? "";
+    if (level === "project") params.projectIdentifier = (scope.project_id as string | undefined) ?? registry.projectId ?? "";
+    await client.request({ method: "PUT", path: `/ng/api/delegate-token-ng/${encodeURIComponent(oldName)}`, params, signal });
+    oldStatus = "REVOKED";
+  }
+
+  return {
+    old_token: { name: oldName, status: oldStatus },
+    new_token: created,
+    next_steps: [
+      `Redeploy each delegate installed with ${oldName} using the new token value (Helm: delegateToken; Kubernetes manifest: the DELEGATE_TOKEN secret).`,
+      oldStatus === "REVOKED"
+        ? `${oldName} is revoked; delegates still using it stay disconnected until redeployed.`
+        : `Once the delegates reconnect with the new token, revoke ${oldName} with action='revoke'.`,
+    ],
+  };
+}
 
 export const delegatesToolset: ToolsetDefinition = {
   name: "delegates",
@@ -57,6 +137,7 @@ export const delegatesToolset: ToolsetDefinition = {
       description: "Delegate registration token. Supports list, get, create, delete, and revoke action.",
       toolset: "delegates",
       scope: "project",
+      supportedScopes: ["account", "org", "project"],
       identifierFields: ["token_name"],
       listFilterFields: [
         { name: "name", description: "Filter delegate tokens by name" },
@@ -87,6 +168,7 @@ export const delegatesToolset: ToolsetDefinition = {
           method: "POST",
           path: "/ng/api/delegate-token-ng",
           operationPolicy: { risk: "low_write", retryPolicy: "do_not_retry" },
+          queryParams: { revoke_after: "revokeAfter" },
           bodyBuilder: (input) => ({
             name: input.token_name ?? input.name,
           }),
@@ -94,6 +176,7 @@ export const delegatesToolset: ToolsetDefinition = {
             description: "Delegate token",
             fields: [
               { name: "name", type: "string", required: true, description: "Token name" },
+              { name: "revoke_after", type: "number", required: false, description: "Epoch millis after which the token is revoked automatically (pass via params)" },
             ],
           },
           responseExtractor: ngExtract,
@@ -129,6 +212,62 @@ export const delegatesToolset: ToolsetDefinition = {
           responseExtractor: ngExtract,
           actionDescription: "Get delegates associated with a specific token.",
         },
+        regenerate: {
+          method: "POST",
+          path: "/ng/api/delegate-token-ng",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          collect: regenerateDelegateToken,
+          responseExtractor: passthrough,
+          actionDescription: "Replace a delegate token: create a new token (named <token_name>_<yyyymmdd> unless new_token_name is given) and return its value with redeploy steps. "
+            + "The old token stays ACTIVE unless revoke_old=true — revoking it disconnects delegates that have not been redeployed with the new value. "
+            + "Pass resource_scope='account' or 'org' for tokens created above project level.",
+          bodySchema: {
+            description: "Regeneration options. All fields are optional.",
+            fields: [
+              { name: "new_token_name", type: "string", required: false, description: "Name for the new token (default: <token_name>_<yyyymmdd>)." },
+              { name: "revoke_after", type: "number", required: false, description: "Epoch millis after which the new token is revoked automatically." },
+              { name: "revoke_old", type: "boolean", required: false, description: "Revoke the old token right away (default false)." },
+            ],
+          },
+        },
+      },
+    },
+    {
+      resourceType: "delegate_upgrade_status",
+      displayName: "Delegate Upgrade Status",
+      description: "Delegate fleet hygiene in one call: each delegate's replica versions against the latest supported delegate version, "
+        + "auto-upgrade state, image expiry, and token state, plus delegate tokens that are expired, expiring within 7 days, or revoked. List-only. "
+        + "Delegates are listed across all scopes; tokens are read at the project scope unless resource_scope='account' or 'org' is passed. "
+        + "Pass params.issue to list only delegates with one problem.",
+      searchAliases: ["delegate version", "delegate upgrade", "outdated delegates", "delegate auto upgrade", "expired delegate token", "delegate fleet"],
+      relatedResources: [
+        { resourceType: "delegate", relationship: "aggregates", description: "Delegates and their replicas" },
+        { resourceType: "delegate_token", relationship: "aggregates", description: "Tokens checked for expiry; regenerate with action='regenerate'" },
+      ],
+      toolset: "delegates",
+      scope: "account",
+      supportedScopes: ["account", "org", "project"],
+      scopeOptional: true,
+      identifierFields: [],
+      deepLinkTemplate: "/ng/account/{accountId}/settings/resources/delegates",
+      listFilterFields: [
+        {
+          name: "issue",
+          description: "Only delegates with this problem: outdated (behind the latest supported version), pending_auto_upgrade (outdated, auto-upgrade ON), "
+            + "auto_upgrade_off (outdated, needs a manual upgrade), expiring (image expires within 7 days or has expired), token_inactive, legacy.",
+          enum: ["outdated", "pending_auto_upgrade", "auto_upgrade_off", "expiring", "token_inactive", "legacy"],
+        },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/ng/api/delegate-setup/listDelegates",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectDelegateUpgradeStatus,
+          responseExtractor: delegateUpgradeStatusExtract,
+          skipCompact: true,
+          description: "Check every delegate against the latest supported version and list delegates pending auto-upgrade, needing a manual upgrade, expiring, or with inactive tokens",
+        },
       },
     },
   ],
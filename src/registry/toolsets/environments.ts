@@ -1,5 +1,6 @@
 import type { ToolsetDefinition, BodySchema } from "../types.js";
 import { buildBodyNormalized } from "../../utils/body-normalizer.js";
+import { wrappedEntityRestoreBody } from "../../utils/undo-log.js";
 import { ngExtract, pageExtract } from "../extractors.js";
 
 const environmentCreateSchema: BodySchema = {
@@ -45,6 +46,7 @@ export const environmentsToolset: ToolsetDefinition = {
         { name: "order", description: "Sort order", enum: ["asc", "desc"] },
       ],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/settings/environments/{environmentIdentifier}/details",
+      restoreBody: wrappedEntityRestoreBody("environment", ["type", "color"]),
       operations: {
         list: {
           method: "GET",
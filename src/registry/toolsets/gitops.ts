@@ -1,5 +1,7 @@
-import type { ToolsetDefinition, ParamsSchema } from "../types.js";
-import { passthrough, ngExtract, pageExtract } from "../extractors.js";
+import type { ToolsetDefinition, ParamsSchema, PreflightContext } from "../types.js";
+import { passthrough, ngExtract, pageExtract, gitopsAppDiffExtract, gitopsAgentInstallExtract, gitopsAppHistoryExtract, gitopsResourceTreeExtract, gitopsBulkSyncExtract, gitopsPodLogExtract, gitopsAppSetPreviewExtract, type GitopsAppHistoryScan, type GitopsBulkSyncScan, type GitopsPodLogScan, type GitopsAppSetPreviewScan } from "../extractors.js";
+import { fanOut } from "../../utils/fan-out.js";
+import { decodeJsonField, renderApplicationSet, type AppSetCluster } from "../../utils/appset-generate.js";
 
 function gitopsListBody(
   input: Record<string, unknown>,
@@ -172,6 +174,565 @@ function encodeAppSetJsonFields(
   };
 }
 
+const DEFAULT_DESTINATION_SERVER = "https://kubernetes.default.svc";
+
+function stringList(value: unknown, field: string): string[] | undefined {
+  if (value === undefined) return undefined;
+  if (!Array.isArray(value) || value.some((v) => typeof v !== "string")) {
+    throw new Error(`body.${field} must be an array of strings.`);
+  }
+  return value as string[];
+}
+
+/**
+ * Build an Argo CD Application from the shorthand create fields, for apps
+ * that deploy one Git path or Helm chart. Returns undefined when the body
+ * has none of them, so callers fall back to body.application.
+ */
+function buildGitopsApplication(body: Record<string, unknown>): Record<string, unknown> | undefined {
+  if (body.repo_url === undefined && body.chart === undefined && body.path === undefined) return undefined;
+  const missing = ["name", "repo_url", "dest_namespace"].filter((f) => typeof body[f] !== "string" || !body[f]);
+  if (typeof body.path !== "string" && typeof body.chart !== "string") missing.push("path or chart");
+  if (missing.length > 0) {
+    throw new Error(`Creating an application from a source needs body.${missing.join(", body.")}.`);
+  }
+
+  const valueFiles = stringList(body.helm_value_files, "helm_value_files");
+  const parameters = isRecord(body.helm_parameters)
+    ? Object.entries(body.helm_parameters).map(([name, value]) => ({ name, value: String(value) }))
+    : undefined;
+  const helm = {
+    ...(typeof body.release_name === "string" ? { releaseName: body.release_name } : {}),
+    ...(typeof body.helm_values === "string" ? { values: body.helm_values } : {}),
+    ...(valueFiles ? { valueFiles } : {}),
+    ...(parameters ? { parameters } : {}),
+  };
+  const source = {
+    repoURL: body.repo_url,
+    ...(typeof body.chart === "string" ? { chart: body.chart } : { path: body.path }),
+    targetRevision: typeof body.target_revision === "string" ? body.target_revision : "HEAD",
+    ...(Object.keys(helm).length > 0 ? { helm } : {}),
+  };
+
+  const syncOptions = stringList(body.sync_options, "sync_options");
+  const automated = body.auto_sync === true || body.auto_sync === "true";
+  const syncPolicy = {
+    ...(automated ? { automated: { prune: body.prune === true || body.prune === "true", selfHeal: body.self_heal === true || body.self_heal === "true" } } : {}),
+    ...(syncOptions ? { syncOptions } : {}),
+  };
+
+  const labels: Record<string, unknown> = {
+    ...(isRecord(body.labels) ? body.labels : {}),
+    ...(typeof body.service_ref === "string" ? { "harness.io/serviceRef": body.service_ref } : {}),
+    ...(typeof body.env_ref === "string" ? { "harness.io/envRef": body.env_ref } : {}),
+  };
+
+  return {
+    metadata: { name: body.name, ...(Object.keys(labels).length > 0 ? { labels } : {}) },
+    spec: {
+      source,
+      destination: {
+        ...(typeof body.dest_cluster_name === "string"
+          ? { name: body.dest_cluster_name }
+          : { server: typeof body.dest_server === "string" ? body.dest_server : DEFAULT_DESTINATION_SERVER }),
+        namespace: body.dest_namespace,
+      },
+      ...(Object.keys(syncPolicy).length > 0 ? { syncPolicy } : {}),
+    },
+  };
+}
+
+/** `patch` merged into `base`: objects merge recursively, anything else replaces, and null removes the key. */
+function mergeSpec(base: Record<string, unknown>, patch: Record<string, unknown>): Record<string, unknown> {
+  const result = { ...base };
+  for (const [key, value] of Object.entries(patch)) {
+    if (value === null) delete result[key];
+    else if (isRecord(value) && isRecord(result[key])) result[key] = mergeSpec(result[key] as Record<string, unknown>, value);
+    else if (value !== undefined) result[key] = value;
+  }
+  return result;
+}
+
+/**
+ * Preflight for gitops_application.update: when the body carries a partial
+ * `spec` (and optionally `labels`) instead of a full application, fetch the
+ * current app and merge the change into it, so callers need not round-trip
+ * the whole object for a one-field edit.
+ */
+const mergeApplicationUpdate = async ({ client, input, registry, signal }: PreflightContext): Promise<void> => {
+  const body = isRecord(input.body) ? input.body : {};
+  if (body.application || (!isRecord(body.spec) && !isRecord(body.labels))) return;
+
+  const current = await registry.dispatch(client, "gitops_application", "get", {
+    agent_id: input.agent_id,
+    app_name: input.app_name,
+    ...(input.org_id !== undefined ? { org_id: input.org_id } : {}),
+    ...(input.project_id !== undefined ? { project_id: input.project_id } : {}),
+  }, signal);
+  const app = isRecord(current) && isRecord(current.app) ? current.app : current;
+  if (!isRecord(app) || !isRecord(app.spec)) {
+    throw new Error(`Could not read the current spec of GitOps application "${String(input.app_name)}" to merge the update into. Pass body.application instead.`);
+  }
+  const metadata = isRecord(app.metadata) ? app.metadata : {};
+  const labels = { ...(isRecord(metadata.labels) ? metadata.labels : {}), ...(isRecord(body.labels) ? body.labels : {}) };
+  input.body = {
+    ...body,
+    application: {
+      metadata: {
+        name: metadata.name ?? input.app_name,
+        ...(metadata.namespace !== undefined ? { namespace: metadata.namespace } : {}),
+        ...(Object.keys(labels).length > 0 ? { labels: Object.fromEntries(Object.entries(labels).filter(([, v]) => v !== null)) } : {}),
+        ...(isRecord(metadata.annotations) ? { annotations: metadata.annotations } : {}),
+      },
+      spec: isRecord(body.spec) ? mergeSpec(app.spec, body.spec) : app.spec,
+    },
+  };
+};
+
+/** body fields of gitops_application.delete_resource and the query inputs they fill. */
+const DELETE_RESOURCE_FIELDS: Array<[string, string]> = [
+  ["namespace", "namespace"],
+  ["resourceName", "resource_name"],
+  ["kind", "kind"],
+  ["group", "group"],
+  ["version", "version"],
+  ["force", "force"],
+  ["orphan", "orphan"],
+];
+
+/**
+ * bodyBuilder for gitops_application.delete_resource: the resource is named in
+ * the body like run_resource_action, but the DELETE endpoint takes it as query
+ * parameters, so hoist the body fields onto the input the query is built from
+ * and send no body.
+ */
+function hoistDeleteResourceRef(input: Record<string, unknown>): undefined {
+  const body = isRecord(input.body) ? input.body : {};
+  for (const [bodyKey, inputKey] of DELETE_RESOURCE_FIELDS) {
+    if (input[inputKey] === undefined && body[bodyKey] !== undefined) input[inputKey] = body[bodyKey];
+  }
+  if (!input.namespace || !input.resource_name || !input.kind || !input.version) {
+    throw new Error(
+      "delete_resource requires body.namespace, body.resourceName, body.kind, and body.version. " +
+      "Use harness_get(resource_type='gitops_app_resource_tree', ...) to look them up first.",
+    );
+  }
+  return undefined;
+}
+
+/**
+ * Agent registration body from the create shorthand (identifier, name,
+ * namespace, ...), or body.agent passed through when the caller has a full
+ * object. Agent identifiers are raw, never scope-prefixed.
+ */
+function buildGitopsAgent(input: Record<string, unknown>): Record<string, unknown> {
+  const body = isRecord(input.body) ? input.body : {};
+  if (isRecord(body.agent)) return body.agent;
+  const identifier = body.identifier ?? input.agent_id;
+  if (typeof identifier !== "string" || !identifier || typeof body.namespace !== "string" || !body.namespace) {
+    throw new Error(
+      "gitops_agent create requires body.identifier and body.namespace (the cluster namespace the agent is installed into), " +
+      "or a full body.agent object.",
+    );
+  }
+  if (identifier.includes(".")) {
+    throw new Error(`Agent identifier "${identifier}" must be raw — drop the scope prefix; set resource_scope instead.`);
+  }
+  return {
+    identifier,
+    name: typeof body.name === "string" && body.name ? body.name : identifier,
+    ...(typeof body.description === "string" ? { description: body.description } : {}),
+    type: typeof body.type === "string" ? body.type : "MANAGED_ARGO_PROVIDER",
+    metadata: {
+      namespace: body.namespace,
+      highAvailability: body.high_availability === true,
+      isNamespaced: body.namespaced === true,
+    },
+    ...(isRecord(body.tags) ? { tags: body.tags } : {}),
+  };
+}
+
+/** Scope inputs forwarded from a gitops_agent_install call to the agent calls it makes. */
+function agentScopeInput(input: Record<string, unknown>): Record<string, unknown> {
+  const scope: Record<string, unknown> = {};
+  for (const key of ["org_id", "project_id", "resource_scope"]) {
+    if (input[key] !== undefined) scope[key] = input[key];
+  }
+  return scope;
+}
+
+/**
+ * Collect hook for gitops_agent_install.create: register the agent, then
+ * fetch its installation manifest or Helm values in the same call. When the
+ * manifest cannot be fetched the agent still exists, so the registration is
+ * returned with the error instead of failing the call.
+ */
+const registerGitopsAgent = async ({ client, input, registry, signal }: PreflightContext): Promise<unknown> => {
+  const body = isRecord(input.body) ? input.body : {};
+  const scope = agentScopeInput(input);
+  const created = await registry.dispatch(client, "gitops_agent", "create", { ...scope, body }, signal);
+  const agent = isRecord(created) && isRecord(created.data) ? created.data : created;
+  const requested = isRecord(body.agent) ? body.agent : body;
+  const agentId = isRecord(agent) && typeof agent.identifier === "string" ? agent.identifier : String(requested.identifier);
+  const metadata = isRecord(requested.metadata) ? requested.metadata : {};
+  const namespace = requested.namespace ?? metadata.namespace;
+  const options = {
+    format: input.format ?? body.format,
+    output_dir: input.output_dir ?? body.output_dir,
+  };
+
+  try {
+    const install = await registry.dispatch(client, "gitops_agent_install", "get", {
+      ...scope,
+      ...options,
+      agent_id: agentId,
+      ...(typeof namespace === "string" ? { namespace } : {}),
+    }, signal);
+    return { agent, ...(isRecord(install) ? install : { install }) };
+  } catch (err) {
+    return {
+      agent,
+      install_error: err instanceof Error ? err.message : String(err),
+      _hint: `The agent was registered but its install manifest could not be fetched. Retry with harness_get(resource_type='gitops_agent_install', resource_id='${agentId}').`,
+    };
+  }
+};
+
+/**
+ * Collect hook for gitops_app_history: the application (for status.history
+ * and operationState) and its events. Events are supplementary, so a failure
+ * there is reported in errors instead of failing the call.
+ */
+const collectGitopsAppHistory = async ({ client, input, registry, signal }: PreflightContext): Promise<GitopsAppHistoryScan> => {
+  const appInput = {
+    agent_id: input.agent_id,
+    app_name: input.app_name,
+    ...(input.org_id !== undefined ? { org_id: input.org_id } : {}),
+    ...(input.project_id !== undefined ? { project_id: input.project_id } : {}),
+  };
+  const [app, events] = await Promise.allSettled([
+    registry.dispatch(client, "gitops_application", "get", appInput, signal),
+    registry.dispatch(client, "gitops_app_event", "list", appInput, signal),
+  ]);
+  if (app.status === "rejected") throw app.reason;
+  return {
+    agent_id: String(input.agent_id),
+    app_name: String(input.app_name),
+    app: app.value,
+    ...(events.status === "fulfilled" ? { events: events.value } : {}),
+    errors: events.status === "rejected" ? [`events: ${events.reason instanceof Error ? events.reason.message : String(events.reason)}`] : [],
+  };
+};
+
+const POD_LOG_FOLLOW_DEFAULT_SECONDS = 15;
+const POD_LOG_FOLLOW_MAX_SECONDS = 60;
+const POD_LOG_POLL_INTERVAL_MS = 3000;
+
+function isTrue(value: unknown): boolean {
+  return value === true || value === "true";
+}
+
+function sleep(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve, reject) => {
+    const onAbort = () => {
+      clearTimeout(timer);
+      reject(signal?.reason);
+    };
+    const timer = setTimeout(() => {
+      signal?.removeEventListener("abort", onAbort);
+      resolve();
+    }, ms);
+    signal?.addEventListener("abort", onAbort, { once: true });
+  });
+}
+
+/**
+ * Collect hook for gitops_pod_log get. One fetch by default. With
+ * `follow=true` it keeps polling for up to `follow_seconds`, asking each
+ * time only for lines since the previous poll, so a crash-looping pod's
+ * output between restarts is caught. The extractor merges and de-duplicates
+ * the batches.
+ */
+const collectGitopsPodLogs = async ({ client, input, registry, signal }: PreflightContext): Promise<GitopsPodLogScan> => {
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  const previous = isTrue(input.previous);
+  const baseParams: Record<string, unknown> = {
+    ...(org ? { orgIdentifier: org } : {}),
+    ...(project ? { projectIdentifier: project } : {}),
+    ...(input.pod_name !== undefined ? { podName: input.pod_name } : {}),
+    ...(input.namespace !== undefined ? { namespace: input.namespace } : {}),
+    ...(input.container !== undefined ? { container: input.container } : {}),
+    ...(previous ? { previous: true } : {}),
+  };
+  const path = `/gitops/api/v1/agents/${String(input.agent_id)}/applications/${String(input.app_name)}/logs`;
+  const fetchLogs = (params: Record<string, unknown>) => client.request<unknown>({
+    method: "GET",
+    path,
+    params: { ...baseParams, ...params },
+    responseType: "buffer",
+    signal,
+  });
+
+  const batches: unknown[] = [await fetchLogs({
+    ...(input.tail_lines !== undefined ? { tailLines: input.tail_lines } : {}),
+    ...(input.since_seconds !== undefined ? { sinceSeconds: input.since_seconds } : {}),
+  })];
+  const scan: GitopsPodLogScan = {
+    agent_id: String(input.agent_id),
+    app_name: String(input.app_name),
+    ...(typeof input.pod_name === "string" ? { pod_name: input.pod_name } : {}),
+    ...(typeof input.container === "string" ? { container: input.container } : {}),
+    previous,
+    batches,
+  };
+  // A terminated container's log does not grow, so there is nothing to follow.
+  if (!isTrue(input.follow) || previous) return scan;
+
+  const seconds = Math.min(
+    POD_LOG_FOLLOW_MAX_SECONDS,
+    Math.max(1, Math.trunc(Number(input.follow_seconds)) || POD_LOG_FOLLOW_DEFAULT_SECONDS),
+  );
+  const started = Date.now();
+  const deadline = started + seconds * 1000;
+  let lastPoll = started;
+  while (Date.now() + POD_LOG_POLL_INTERVAL_MS <= deadline) {
+    await sleep(POD_LOG_POLL_INTERVAL_MS, signal);
+    const since = Math.ceil((Date.now() - lastPoll) / 1000) + 1;
+    lastPoll = Date.now();
+    batches.push(await fetchLogs({ sinceSeconds: since }));
+  }
+  return { ...scan, follow: { polls: batches.length - 1, duration_ms: Date.now() - started } };
+};
+
+/** ApplicationSet object from a gitops_applicationset get response or request body. */
+function unwrapAppSet(value: unknown): Record<string, unknown> | undefined {
+  if (!isRecord(value)) return undefined;
+  if (isRecord(value.applicationset)) return value.applicationset;
+  if (isRecord(value.appset)) return value.appset;
+  return isRecord(value.spec) ? value : undefined;
+}
+
+/** Whether any generator, at any nesting depth, is a clusters generator. */
+function usesClustersGenerator(generators: unknown): boolean {
+  if (!Array.isArray(generators)) return false;
+  return generators.filter(isRecord).some((gen) => {
+    if (gen.clusters !== undefined) return true;
+    const nested = decodeJsonField(gen.matrix ?? gen.merge);
+    return isRecord(nested) && usesClustersGenerator(Array.isArray(nested.generators) ? nested.generators.map(decodeJsonField) : []);
+  });
+}
+
+/** Clusters registered with `agentId` (scope prefix optional on either side), for the clusters generator. */
+async function agentClusters({ client, input, registry, signal }: PreflightContext, agentId: string): Promise<AppSetCluster[]> {
+  const listed = await registry.dispatch(client, "gitops_cluster", "list", {
+    ...(input.org_id !== undefined ? { org_id: input.org_id } : {}),
+    ...(input.project_id !== undefined ? { project_id: input.project_id } : {}),
+    size: 100,
+  }, signal);
+  const rows = isRecord(listed) && Array.isArray(listed.content) ? listed.content : [];
+  const bare = agentId.replace(/^(account|org)\./, "");
+  return rows.filter(isRecord).flatMap((row) => {
+    const agent = typeof row.agentIdentifier === "string" ? row.agentIdentifier.replace(/^(account|org)\./, "") : undefined;
+    const cluster = isRecord(row.cluster) ? row.cluster : {};
+    if (agent !== bare || typeof cluster.server !== "string") return [];
+    const strings = (v: unknown): Record<string, string> => (isRecord(v) ? Object.fromEntries(Object.entries(v).map(([k, s]) => [k, String(s)])) : {});
+    return [{
+      name: typeof cluster.name === "string" ? cluster.name : String(row.identifier ?? cluster.server),
+      server: cluster.server,
+      labels: strings(cluster.labels),
+      annotations: strings(cluster.annotations),
+    }];
+  });
+}
+
+/**
+ * Collect hook for gitops_applicationset preview: render the proposed
+ * ApplicationSet (body.applicationset, or the stored one when only appset_id
+ * is given) and, for an existing ApplicationSet, the apps it owns today so
+ * the extractor can say what the change would create and delete. Nothing is
+ * written.
+ */
+const previewGitopsApplicationSet = async (ctx: PreflightContext): Promise<GitopsAppSetPreviewScan> => {
+  const { client, input, registry, signal } = ctx;
+  if (typeof input.agent_id !== "string" || !input.agent_id) {
+    throw new Error("preview requires params.agent_id (scope-prefixed, e.g. 'account.myagent').");
+  }
+  const body = isRecord(input.body) ? input.body : {};
+  const current = input.appset_id
+    ? unwrapAppSet(await registry.dispatch(client, "gitops_applicationset", "get", {
+      appset_id: input.appset_id,
+      agent_id: input.agent_id,
+      ...(input.org_id !== undefined ? { org_id: input.org_id } : {}),
+      ...(input.project_id !== undefined ? { project_id: input.project_id } : {}),
+    }, signal))
+    : undefined;
+  const proposed = unwrapAppSet(body) ?? current;
+  if (!proposed) {
+    throw new Error(
+      "preview needs body.applicationset (the proposed ApplicationSet) or resource_id=<appset UUID> to render the stored one. " +
+      "Pass both to compare a change against what the ApplicationSet generates today.",
+    );
+  }
+  const spec = isRecord(proposed.spec) ? proposed.spec : {};
+  const clusters = usesClustersGenerator(spec.generators) ? await agentClusters(ctx, input.agent_id) : [];
+  const metadata = isRecord(proposed.metadata) ? proposed.metadata : {};
+  const status = current && isRecord(current.status) ? current.status : {};
+  return {
+    agent_id: input.agent_id,
+    ...(typeof metadata.name === "string" ? { appset_name: metadata.name } : {}),
+    render: renderApplicationSet(proposed, clusters),
+    ...(current
+      ? {
+        current_apps: (Array.isArray(status.resources) ? status.resources : [])
+          .filter(isRecord)
+          .map((r) => r.name)
+          .filter((n): n is string => typeof n === "string"),
+      }
+      : {}),
+    ...(isRecord(spec.syncPolicy) ? { sync_policy: spec.syncPolicy } : {}),
+  };
+};
+
+/** Syncs in flight at once for sync_applications unless body.concurrency says otherwise. */
+const BULK_SYNC_CONCURRENCY = 5;
+const BULK_SYNC_MAX_CONCURRENCY = 10;
+/** Upper bound on apps one sync_applications call will sync; raise with body.max_apps. */
+const BULK_SYNC_DEFAULT_MAX_APPS = 50;
+const BULK_SYNC_PAGE_SIZE = 100;
+const BULK_SYNC_MAX_PAGES = 20;
+
+type LabelTerm = { key: string; op: "=" | "!=" | "exists" | "missing"; value?: string };
+
+/**
+ * Parse a Kubernetes-style equality selector — `env=prod,team!=web,canary,!legacy`
+ * — or a `{key: value}` object into match terms.
+ */
+export function parseLabelSelector(selector: unknown): LabelTerm[] {
+  if (isRecord(selector)) {
+    return Object.entries(selector).map(([key, value]) => ({ key, op: "=" as const, value: String(value) }));
+  }
+  if (typeof selector !== "string" || !selector.trim()) {
+    throw new Error("label_selector must be a string like 'env=prod,team=payments' or an object like {env: 'prod'}.");
+  }
+  return selector.split(",").map((raw) => raw.trim()).filter(Boolean).map((term) => {
+    const neq = /^([^!=\s]+)\s*!=\s*(.*)$/.exec(term);
+    if (neq) return { key: neq[1]!, op: "!=" as const, value: neq[2]! };
+    const eq = /^([^!=\s]+)\s*==?\s*(.*)$/.exec(term);
+    if (eq) return { key: eq[1]!, op: "=" as const, value: eq[2]! };
+    if (term.startsWith("!")) return { key: term.slice(1).trim(), op: "missing" as const };
+    if (/[\s()]/.test(term)) throw new Error(`Unsupported label selector term "${term}". Use key=value, key!=value, key, or !key.`);
+    return { key: term, op: "exists" as const };
+  });
+}
+
+function matchesLabels(labels: Record<string, unknown>, terms: LabelTerm[]): boolean {
+  return terms.every((t) => {
+    const has = Object.prototype.hasOwnProperty.call(labels, t.key);
+    switch (t.op) {
+      case "=": return has && String(labels[t.key]) === t.value;
+      case "!=": return !has || String(labels[t.key]) !== t.value;
+      case "exists": return has;
+      case "missing": return !has;
+    }
+  });
+}
+
+/** Agent, name, and labels of one gitops_application list entry. */
+function appListEntry(item: unknown): { agent_id: string; app_name: string; labels: Record<string, unknown> } | undefined {
+  if (!isRecord(item)) return undefined;
+  const app = isRecord(item.app) ? item.app : item;
+  const metadata = isRecord(app.metadata) ? app.metadata : {};
+  const name = item.name ?? metadata.name;
+  const agent = item.agentIdentifier ?? item.agent_id;
+  if (typeof name !== "string" || typeof agent !== "string") return undefined;
+  return { agent_id: agent, app_name: name, labels: isRecord(metadata.labels) ? metadata.labels : {} };
+}
+
+/**
+ * Collect hook for gitops_application sync_applications: resolve targets from
+ * body.targets or by listing apps and matching body.label_selector, then sync
+ * each app with the same options, a few at a time. One app failing does not
+ * stop the others; every outcome lands in the consolidated table.
+ */
+const syncGitopsApplications = async ({ client, input, registry, signal }: PreflightContext): Promise<GitopsBulkSyncScan> => {
+  const body = isRecord(input.body) ? input.body : {};
+  const scope = {
+    ...(input.org_id !== undefined ? { org_id: input.org_id } : {}),
+    ...(input.project_id !== undefined ? { project_id: input.project_id } : {}),
+  };
+  const selector = body.label_selector ?? input.label_selector;
+  let targets: Array<{ agent_id: string; app_name: string }>;
+  if (Array.isArray(body.targets)) {
+    targets = buildBulkTargets(input, "sync_applications").map((t) => ({ agent_id: t.agentIdentifier, app_name: t.applicationName }));
+  } else if (selector !== undefined) {
+    const terms = parseLabelSelector(selector);
+    const agentFilter = typeof input.agent_id === "string" && input.agent_id ? input.agent_id : undefined;
+    targets = [];
+    for (let page = 0; page < BULK_SYNC_MAX_PAGES; page++) {
+      const listed = await registry.dispatch(client, "gitops_application", "list", {
+        ...scope,
+        page,
+        size: BULK_SYNC_PAGE_SIZE,
+        ...(typeof body.search_term === "string" ? { search_term: body.search_term } : {}),
+      }, signal);
+      const rows = isRecord(listed) && Array.isArray(listed.content) ? listed.content
+        : isRecord(listed) && Array.isArray(listed.items) ? listed.items
+        : [];
+      for (const entry of rows.map(appListEntry)) {
+        if (!entry || (agentFilter && entry.agent_id !== agentFilter)) continue;
+        if (matchesLabels(entry.labels, terms)) targets.push({ agent_id: entry.agent_id, app_name: entry.app_name });
+      }
+      const totalPages = isRecord(listed) && typeof listed.totalPages === "number" ? listed.totalPages : undefined;
+      if (rows.length < BULK_SYNC_PAGE_SIZE || (totalPages !== undefined && page + 1 >= totalPages)) break;
+    }
+    if (targets.length === 0) {
+      throw new Error(`No GitOps applications match label_selector ${JSON.stringify(selector)}${agentFilter ? ` on agent ${agentFilter}` : ""}.`);
+    }
+  } else {
+    throw new Error("sync_applications needs body.targets=[{agent_id, app_name}, ...] or body.label_selector (e.g. 'env=prod').");
+  }
+
+  const maxApps = typeof body.max_apps === "number" && body.max_apps > 0 ? body.max_apps : BULK_SYNC_DEFAULT_MAX_APPS;
+  if (targets.length > maxApps) {
+    throw new Error(`sync_applications would sync ${targets.length} applications, over the limit of ${maxApps}. `
+      + "Narrow the selector or pass body.max_apps to confirm the larger rollout.");
+  }
+
+  const dryRun = body.dryRun === true || body.dry_run === true;
+  const syncBody: Record<string, unknown> = {
+    ...(body.prune !== undefined ? { prune: body.prune } : {}),
+    ...(dryRun ? { dryRun: true } : {}),
+    ...(typeof body.revision === "string" ? { revision: body.revision } : {}),
+    ...(body.syncOptions !== undefined ? { syncOptions: Array.isArray(body.syncOptions) ? { items: body.syncOptions } : body.syncOptions } : {}),
+  };
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  const concurrency = Math.min(
+    BULK_SYNC_MAX_CONCURRENCY,
+    typeof body.concurrency === "number" && body.concurrency > 0 ? body.concurrency : BULK_SYNC_CONCURRENCY,
+  );
+  const indexed = targets.map((target, index) => ({ ...target, index }));
+  const { results, errors } = await fanOut(indexed, (target, workerSignal) => client.request<unknown>({
+    method: "POST",
+    path: `/gitops/api/v1/agents/${encodeURIComponent(target.agent_id)}/applications/${encodeURIComponent(target.app_name)}/sync`,
+    params: {
+      ...(org ? { orgIdentifier: org } : {}),
+      ...(project ? { projectIdentifier: project } : {}),
+    },
+    body: syncBody,
+    signal: workerSignal,
+  }), { concurrency, signal });
+
+  return {
+    ...(selector !== undefined && !Array.isArray(body.targets) ? { selector: typeof selector === "string" ? selector : JSON.stringify(selector) } : {}),
+    targets,
+    results: results.map(({ item, value }) => ({ index: item.index, response: value })),
+    failures: errors.map(({ item, error }) => ({ index: item.index, error })),
+    dry_run: dryRun,
+  };
+};
+
 export const gitopsToolset: ToolsetDefinition = {
   name: "gitops",
   displayName: "GitOps",
@@ -220,6 +781,31 @@ export const gitopsToolset: ToolsetDefinition = {
           responseExtractor: passthrough,
           description: "Get GitOps agent details",
         },
+        create: {
+          method: "POST",
+          path: "/gitops/api/v1/agents",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          injectAccountInBody: true,
+          bodyBuilder: buildGitopsAgent,
+          responseExtractor: passthrough,
+          description:
+            "Register a GitOps agent. This only creates the registration; install it on the cluster with the manifest from gitops_agent_install. " +
+            "To register and get the manifest in one call, use harness_create(resource_type='gitops_agent_install') instead.",
+          bodySchema: {
+            description: "Agent registration. Pass the shorthand fields, or a full V1Agent object as body.agent.",
+            fields: [
+              { name: "identifier", type: "string", required: false, description: "Raw agent identifier (no scope prefix). Required unless body.agent is given." },
+              { name: "name", type: "string", required: false, description: "Display name. Defaults to the identifier." },
+              { name: "namespace", type: "string", required: false, description: "Cluster namespace the agent is installed into, e.g. 'argocd'. Required unless body.agent is given." },
+              { name: "description", type: "string", required: false, description: "Agent description." },
+              { name: "high_availability", type: "boolean", required: false, description: "Install Argo CD components in HA mode (default false)." },
+              { name: "namespaced", type: "boolean", required: false, description: "Restrict the agent to its own namespace instead of cluster-wide access (default false)." },
+              { name: "type", type: "string", required: false, description: "MANAGED_ARGO_PROVIDER (default) for a new Harness-managed Argo CD, or CONNECTED_ARGO_PROVIDER for an existing Argo CD." },
+              { name: "tags", type: "object", required: false, description: "Key/value tags." },
+              { name: "agent", type: "object", required: false, description: "Full V1Agent object, sent as-is instead of the shorthand fields." },
+            ],
+          },
+        },
         delete: {
           method: "DELETE",
           path: "/gitops/api/v1/agents/{agentIdentifier}",
@@ -262,11 +848,82 @@ export const gitopsToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "gitops_agent_install",
+      displayName: "GitOps Agent Install",
+      description:
+        "Installation manifest for a GitOps agent: the Kubernetes YAML (format 'yaml', default) or Helm values override (format 'helm') plus the commands that apply it.\n" +
+        "ONBOARD A CLUSTER: harness_create(resource_type='gitops_agent_install', body={identifier:'prodcluster', namespace:'argocd'}) registers the agent and returns its manifest in one call.\n" +
+        "EXISTING AGENT: harness_get(resource_type='gitops_agent_install', resource_id='prodcluster', params={namespace:'argocd'}).\n" +
+        "The manifest carries the agent's registration token. Pass output_dir to write it to an owner-only file on the server host instead of returning it.\n" +
+        "IDENTIFIERS: agent_id is the raw identifier (e.g. 'myagent', NOT 'account.myagent'); scope follows resource_scope like gitops_agent.",
+      toolset: "gitops",
+      scope: "project",
+      scopeOptional: true,
+      supportedScopes: ["account", "org", "project"],
+      identifierFields: ["agent_id"],
+      deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/gitops/agents/{agentIdentifier}",
+      operations: {
+        get: {
+          method: "GET",
+          path: "/gitops/api/v1/agents/{agentIdentifier}/deploy.yaml",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { agent_id: "agentIdentifier" },
+          pathBuilder: (input) => {
+            if (typeof input.agent_id !== "string" || !input.agent_id) {
+              throw new Error('Missing required field "agent_id" for gitops_agent_install.');
+            }
+            const file = input.format === "helm" ? "helm/overrides" : "deploy.yaml";
+            return `/gitops/api/v1/agents/${encodeURIComponent(input.agent_id)}/${file}`;
+          },
+          queryParams: {
+            namespace: "namespace",
+          },
+          responseType: "buffer",
+          responseExtractor: gitopsAgentInstallExtract,
+          skipCache: true,
+          description: "Installation manifest (format yaml) or Helm values override (format helm) for an existing agent, with install commands.",
+          paramsSchema: {
+            fields: [
+              { name: "agent_id", required: true, description: "Raw agent identifier — no scope prefix. Passed as resource_id." },
+              { name: "namespace", required: false, description: "Namespace the agent is installed into. Should match the namespace it was registered with (default 'argocd')." },
+              { name: "format", required: false, description: "'yaml' (default) for a kubectl manifest, or 'helm' for a values override for the gitops-helm chart." },
+              { name: "output_dir", required: false, description: "Absolute directory on the MCP server host to write the manifest to (mode 0600) instead of returning it inline. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it." },
+            ],
+          } satisfies ParamsSchema,
+        },
+        create: {
+          method: "POST",
+          path: "/gitops/api/v1/agents",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          collect: registerGitopsAgent,
+          responseExtractor: passthrough,
+          skipCompact: true,
+          description:
+            "Register a GitOps agent and return its installation manifest in one call. Takes the gitops_agent create body plus optional format and output_dir.",
+          bodySchema: {
+            description: "Agent registration (same shorthand as gitops_agent create) plus install options.",
+            fields: [
+              { name: "identifier", type: "string", required: true, description: "Raw agent identifier (no scope prefix)." },
+              { name: "namespace", type: "string", required: true, description: "Cluster namespace the agent is installed into, e.g. 'argocd'." },
+              { name: "name", type: "string", required: false, description: "Display name. Defaults to the identifier." },
+              { name: "description", type: "string", required: false, description: "Agent description." },
+              { name: "high_availability", type: "boolean", required: false, description: "Install Argo CD components in HA mode (default false)." },
+              { name: "namespaced", type: "boolean", required: false, description: "Restrict the agent to its own namespace (default false)." },
+              { name: "format", type: "string", required: false, description: "'yaml' (default) or 'helm' for the returned install instructions." },
+              { name: "output_dir", type: "string", required: false, description: "Absolute directory on the MCP server host to write the manifest to instead of returning it. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it." },
+            ],
+          },
+        },
+      },
+    },
     {
       resourceType: "gitops_application",
       displayName: "GitOps Application",
       description:
         "GitOps application managed by an agent. List returns all apps (no agent required). Get/sync require agent_id.\n" +
+        "DRIFT: harness_get resource_type='gitops_app_diff' with the same agent_id and app name returns the live vs Git manifest diff per resource.\n" +
+        "HISTORY: harness_get resource_type='gitops_app_history' returns deployments, failed syncs, and health changes over time.\n" +
         "IDENTIFIERS: agent_id is scope-prefixed:\n" +
         "- Account-scoped agent: 'account.myagent'\n" +
         "- Org-scoped agent: 'org.myagent'\n" +
@@ -276,11 +933,13 @@ export const gitopsToolset: ToolsetDefinition = {
       diagnosticHint: "Use harness_diagnose with resource_type='gitops_application', agent_id, and resource_id (app name) to analyze sync failures, health issues, and unhealthy K8s resources. Combines app status, resource tree, and recent events.",
       executeHint:
         "SYNC: action='sync' for single app, action='bulk_sync' for multiple. " +
+        "PROMOTE BY LABEL: action='sync_applications' with body.label_selector (e.g. 'env=prod') syncs every matching app and returns a status table. " +
         "REFRESH: action='refresh' (body.refresh='normal' or 'hard'). " +
         "CANCEL: action='cancel_operation' to stop a running sync/rollback. " +
         "RESOURCE ACTIONS (restart, pause, etc.): 1) harness_get resource_type='gitops_app_resource_tree' to discover K8s resources, " +
         "2) harness_list resource_type='gitops_resource_action' to discover available actions, " +
         "3) harness_execute action='run_resource_action'. " +
+        "DELETE ONE RESOURCE: action='delete_resource' with body {namespace, resourceName, kind, group, version, force?, orphan?}. " +
         "NOTE: resource_id maps to agent_id in harness_execute, but to app_name in harness_get and harness_update.",
       identifierFields: ["agent_id", "app_name"],
       listFilterFields: [
@@ -323,15 +982,17 @@ export const gitopsToolset: ToolsetDefinition = {
           },
           bodyBuilder: (input) => {
             const body = (input.body ?? {}) as Record<string, unknown>;
-            if (!body.application) {
+            const application = body.application ?? buildGitopsApplication(body);
+            if (!application) {
               throw new Error(
                 "body.application is required. Provide the full ArgoCD Application object: " +
-                "{ metadata: { name, labels?, annotations? }, spec: { source|sources, destination, syncPolicy? } }. " +
+                "{ metadata: { name, labels?, annotations? }, spec: { source|sources, destination, syncPolicy? } }, " +
+                "or the shorthand fields name, repo_url, path (or chart), and dest_namespace. " +
                 "Use harness_describe(resource_type='gitops_application') for the full schema.",
               );
             }
             return {
-              application: body.application,
+              application,
               upsert: body.upsert ?? false,
               validate: body.validate ?? true,
             };
@@ -345,6 +1006,10 @@ export const gitopsToolset: ToolsetDefinition = {
             "  body={application:{metadata:{name:'my-app'}, spec:{source:{repoURL:'https://github.com/org/repo', path:'manifests', targetRevision:'HEAD'}, destination:{server:'https://kubernetes.default.svc', namespace:'default'}}}})\n\n" +
             "EXAMPLE (multi-source):\n" +
             "Use spec.sources (array) instead of spec.source. Each source object has: { repoURL, path, targetRevision, chart, helm, ref, name }.\n\n" +
+            "SHORTHAND (one Git path or Helm chart): instead of body.application pass\n" +
+            "  body={name:'my-app', repo_url:'https://github.com/org/repo', path:'manifests', dest_namespace:'default'}\n" +
+            "  or body={name:'redis', repo_url:'https://charts.bitnami.com/bitnami', chart:'redis', target_revision:'18.1.0', helm_values:'replica:\\n  replicaCount: 1', dest_namespace:'cache'}\n" +
+            "  Optional: target_revision (default HEAD), dest_server (default in-cluster) or dest_cluster_name, helm_value_files, helm_parameters, release_name, auto_sync with prune/self_heal, sync_options, labels, service_ref, env_ref.\n\n" +
             "REQUIRED params:\n" +
             "  agent_id — scope-prefixed agent identifier (e.g. 'account.myagent'). NOTE: harness_create has no resource_id — pass agent_id inside params.\n" +
             "  cluster_identifier — scope-prefixed cluster ID (e.g. 'account.incluster')\n" +
@@ -354,12 +1019,12 @@ export const gitopsToolset: ToolsetDefinition = {
             "LINKING SERVICE/ENVIRONMENT: Set labels 'harness.io/serviceRef' and 'harness.io/envRef' in metadata.labels. Values are scope-prefixed: 'account.myservice' for account-level, 'org.myservice' for org-level, 'myservice' for project-level.",
           bodySchema: {
             description:
-              "Body must contain 'application' with the full ArgoCD Application object. Uses native ArgoCD camelCase field names.",
+              "Body must contain 'application' with the full ArgoCD Application object, or the shorthand fields for a single Git path or Helm chart. Uses native ArgoCD camelCase field names.",
             fields: [
               {
-                name: "application", type: "object", required: true,
+                name: "application", type: "object", required: false,
                 description:
-                  "ArgoCD Application object. Structure:\n" +
+                  "ArgoCD Application object (required unless the shorthand fields are used). Structure:\n" +
                   "{ metadata: { name (required), labels?, annotations? },\n" +
                   "  spec: {\n" +
                   "    source: { repoURL, path, targetRevision ('HEAD' default), chart?, helm?, kustomize?, directory?, plugin? },\n" +
@@ -370,6 +1035,23 @@ export const gitopsToolset: ToolsetDefinition = {
                   "}.\n" +
                   "Do NOT set spec.project (Harness auto-maps it).",
               },
+              { name: "name", type: "string", required: false, description: "Shorthand: application name. Use with repo_url, path or chart, and dest_namespace instead of application." },
+              { name: "repo_url", type: "string", required: false, description: "Shorthand: Git repository or Helm repository URL." },
+              { name: "path", type: "string", required: false, description: "Shorthand: manifest directory in the Git repository." },
+              { name: "chart", type: "string", required: false, description: "Shorthand: Helm chart name in the Helm repository (instead of path)." },
+              { name: "target_revision", type: "string", required: false, description: "Shorthand: branch, tag, or commit; chart version for Helm (default: HEAD)." },
+              { name: "dest_namespace", type: "string", required: false, description: "Shorthand: namespace to deploy to." },
+              { name: "dest_server", type: "string", required: false, description: "Shorthand: destination cluster API URL (default: https://kubernetes.default.svc)." },
+              { name: "dest_cluster_name", type: "string", required: false, description: "Shorthand: destination cluster name (instead of dest_server)." },
+              { name: "helm_values", type: "string", required: false, description: "Shorthand: inline Helm values YAML." },
+              { name: "helm_value_files", type: "array", required: false, description: "Shorthand: Helm value files relative to the source." },
+              { name: "helm_parameters", type: "object", required: false, description: "Shorthand: Helm parameter overrides as {name: value}." },
+              { name: "release_name", type: "string", required: false, description: "Shorthand: Helm release name." },
+              { name: "auto_sync", type: "boolean", required: false, description: "Shorthand: enable automated sync. prune and self_heal (booleans) tune it." },
+              { name: "sync_options", type: "array", required: false, description: "Shorthand: Argo CD sync options, e.g. ['CreateNamespace=true']." },
+              { name: "labels", type: "object", required: false, description: "Shorthand: application labels." },
+              { name: "service_ref", type: "string", required: false, description: "Shorthand: Harness service to link (sets label harness.io/serviceRef)." },
+              { name: "env_ref", type: "string", required: false, description: "Shorthand: Harness environment to link (sets label harness.io/envRef)." },
               { name: "upsert", type: "boolean", required: false, description: "If true, update existing app instead of failing on duplicate (default: false)." },
               { name: "validate", type: "boolean", required: false, description: "Validate spec before creating (default: true)." },
             ],
@@ -389,12 +1071,13 @@ export const gitopsToolset: ToolsetDefinition = {
             repo_identifiers: "repoIdentifiers",
             skip_repo_validation: "skipRepoValidation",
           },
+          preflight: mergeApplicationUpdate,
           bodyBuilder: (input) => {
             const body = (input.body ?? {}) as Record<string, unknown>;
             if (!body.application) {
               throw new Error(
-                "body.application is required. Provide the full ArgoCD Application object. " +
-                "RECOMMENDED: harness_get the current app first, modify fields, then pass the full object.",
+                "body.application is required. Provide the full ArgoCD Application object, " +
+                "or body.spec with only the spec fields to change (merged into the current app; null removes a field).",
               );
             }
             return {
@@ -404,8 +1087,9 @@ export const gitopsToolset: ToolsetDefinition = {
           },
           responseExtractor: passthrough,
           description:
-            "Update a GitOps application. This is a full PUT replace — provide the complete desired state.\n" +
+            "Update a GitOps application. body.application is a full PUT replace — provide the complete desired state.\n" +
             "RECOMMENDED FLOW: First harness_get the current app, modify the fields you need, then pass the full application object.\n" +
+            "PARTIAL UPDATE: pass body.spec with only the spec fields to change (and optionally body.labels) instead; the current app is fetched and the change merged in. null removes a field, e.g. body={spec:{syncPolicy:{automated:null}}} turns off auto-sync.\n" +
             "IMPORTANT: resource_id must be the app_name (plain name, not scope-prefixed), and agent_id goes in params.\n" +
             "Example: harness_update(resource_type='gitops_application', resource_id='my-app', params={agent_id:'account.myagent', cluster_identifier:'account.incluster', skip_repo_validation:'true'}, body={application:{...}})\n" +
             "SCOPE PREFIXES for agent_id: 'account.' for account-level, 'org.' for org-level, no prefix for project-level.\n" +
@@ -413,15 +1097,17 @@ export const gitopsToolset: ToolsetDefinition = {
             "LINKING SERVICE/ENVIRONMENT: Set labels 'harness.io/serviceRef' and 'harness.io/envRef' in metadata.labels. Values are scope-prefixed.",
           bodySchema: {
             description:
-              "Body must contain 'application' with the full ArgoCD Application object. Uses native ArgoCD camelCase field names.\n" +
+              "Body must contain 'application' with the full ArgoCD Application object, or a partial 'spec'. Uses native ArgoCD camelCase field names.\n" +
               "Query params via 'params': app_name (path), cluster_identifier, repo_identifier/repo_identifiers, skip_repo_validation.",
             fields: [
               {
-                name: "application", type: "object", required: true,
+                name: "application", type: "object", required: false,
                 description:
-                  "Full ArgoCD Application object: { metadata: { name, labels?, annotations? }, spec: { source|sources, destination, syncPolicy? } }.\n" +
+                  "Full ArgoCD Application object (required unless spec is given): { metadata: { name, labels?, annotations? }, spec: { source|sources, destination, syncPolicy? } }.\n" +
                   "Get the current app first with harness_get, modify what you need, pass the whole object back. Do NOT set spec.project (Harness auto-maps it).",
               },
+              { name: "spec", type: "object", required: false, description: "Partial spec to merge into the current app instead of a full application. Objects merge, other values replace, null removes." },
+              { name: "labels", type: "object", required: false, description: "Labels to add or change (null removes) with a partial update." },
               { name: "validate", type: "boolean", required: false, description: "Validate spec before applying (default: true)." },
             ],
           },
@@ -613,6 +1299,36 @@ export const gitopsToolset: ToolsetDefinition = {
             ],
           },
         },
+        sync_applications: {
+          method: "POST",
+          path: "/gitops/api/v1/agents/{agentIdentifier}/applications/{appName}/sync",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          pathParams: { agent_id: "agentIdentifier", app_name: "appName" },
+          collect: syncGitopsApplications,
+          responseExtractor: gitopsBulkSyncExtract,
+          skipCompact: true,
+          actionDescription:
+            "Sync many GitOps applications at once — e.g. every app labelled env=prod for an environment-wide promotion — and get one consolidated status table. " +
+            "Each app is synced with its own request, a few at a time, so one failure does not block the rest.\n\n" +
+            "BY LABEL: harness_execute(resource_type='gitops_application', action='sync_applications', body={label_selector:'env=prod,team=payments', prune:true})\n" +
+            "EXPLICIT LIST: harness_execute(resource_type='gitops_application', action='sync_applications', body={targets:[{agent_id:'account.myagent', app_name:'app1'}, {agent_id:'account.myagent', app_name:'app2'}]})\n\n" +
+            "Returns summary {requested, triggered, failed}, applications[] {agent_id, app_name, result, sync, health, phase, revision, error}, and a Markdown table. " +
+            "Pass resource_id (agent_id) to restrict a label selector to one agent. Refuses to sync more than max_apps (default 50) apps.",
+          bodySchema: {
+            description: "Targets (label_selector or targets) plus the sync options applied to every app.",
+            fields: [
+              { name: "label_selector", type: "string", required: false, description: "Kubernetes-style selector on application labels: 'env=prod', 'team!=web', 'canary' (has label), '!legacy' (lacks label), comma-separated and ANDed. An object {env: 'prod'} also works." },
+              { name: "targets", type: "array", required: false, description: "Explicit apps instead of a selector: [{agent_id: 'account.myagent', app_name: 'my-app'}, ...]." },
+              { name: "search_term", type: "string", required: false, description: "Narrow the app listing before the selector is applied." },
+              { name: "prune", type: "boolean", required: false, description: "Delete resources from the cluster that are not in git." },
+              { name: "dryRun", type: "boolean", required: false, description: "Simulate the syncs without applying changes." },
+              { name: "revision", type: "string", required: false, description: "Revision to sync every app to." },
+              { name: "syncOptions", type: "array", required: false, description: "Sync option strings, e.g. ['ApplyOutOfSyncOnly=true']." },
+              { name: "concurrency", type: "number", required: false, description: "Syncs in flight at once (default 5, max 10)." },
+              { name: "max_apps", type: "number", required: false, description: "Safety limit on how many apps one call may sync (default 50)." },
+            ],
+          },
+        },
         cancel_operation: {
           method: "DELETE",
           path: "/gitops/api/v1/agents/{agentIdentifier}/applications/{appName}/operation",
@@ -688,6 +1404,46 @@ export const gitopsToolset: ToolsetDefinition = {
             ],
           },
         },
+        delete_resource: {
+          method: "DELETE",
+          path: "/gitops/api/v1/agents/{agentIdentifier}/applications/{appName}/resource",
+          operationPolicy: { risk: "destructive", retryPolicy: "do_not_retry" },
+          pathParams: {
+            agent_id: "agentIdentifier",
+            app_name: "appName",
+          },
+          queryParams: {
+            namespace: "request.namespace",
+            resource_name: "request.resourceName",
+            kind: "request.kind",
+            group: "request.group",
+            version: "request.version",
+            force: "request.force",
+            orphan: "request.orphan",
+          },
+          bodyBuilder: hoistDeleteResourceRef,
+          responseExtractor: passthrough,
+          actionDescription:
+            "Delete one Kubernetes resource managed by a GitOps application from the cluster (e.g. a stuck Job or a Pod to recreate). The app stays; a later sync recreates the resource if it is still in Git.\n\n" +
+            "Example: harness_execute(resource_type='gitops_application', action='delete_resource', resource_id='account.myagent', params={app_name:'my-app'}, body={namespace:'default', resourceName:'migrate-db', kind:'Job', group:'batch', version:'v1'})\n\n" +
+            "OPTIONS:\n" +
+            "  force=true — delete immediately without waiting for graceful termination\n" +
+            "  orphan=true — delete only this object and leave its dependents (e.g. the Pods of a ReplicaSet) running\n\n" +
+            "NOTE: resource_id is the agent_id (scope-prefixed). Look up namespace/kind/group/version with harness_get(resource_type='gitops_app_resource_tree').",
+          bodySchema: {
+            description:
+              "Resource to delete. Identifies the K8s resource like run_resource_action; sent as query parameters.",
+            fields: [
+              { name: "namespace", type: "string", required: true, description: "Kubernetes namespace of the target resource." },
+              { name: "resourceName", type: "string", required: true, description: "Name of the Kubernetes resource." },
+              { name: "kind", type: "string", required: true, description: "Kubernetes resource kind (e.g. 'Pod', 'Job', 'Deployment')." },
+              { name: "group", type: "string", required: false, description: "Kubernetes API group (e.g. 'apps', 'batch'). Omit for core resources such as Pods." },
+              { name: "version", type: "string", required: true, description: "Kubernetes API version (e.g. 'v1')." },
+              { name: "force", type: "boolean", required: false, description: "Delete immediately, skipping graceful termination (default: false)." },
+              { name: "orphan", type: "boolean", required: false, description: "Leave dependent objects in place instead of cascading the delete (default: false)." },
+            ],
+          },
+        },
       },
     },
     {
@@ -1098,6 +1854,34 @@ export const gitopsToolset: ToolsetDefinition = {
             "NOTE: Deleting an ApplicationSet also deletes all Applications it generated, unless the ApplicationSet's syncPolicy preserves them.",
         },
       },
+      executeActions: {
+        preview: {
+          method: "GET",
+          path: "/gitops/api/v1/applicationset/{identifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            appset_id: "identifier",
+          },
+          collect: previewGitopsApplicationSet,
+          responseExtractor: gitopsAppSetPreviewExtract,
+          skipCompact: true,
+          actionDescription:
+            "Dry-run an ApplicationSet: expand its generators and render the template to list the Applications it would generate, without changing anything. " +
+            "Use it to review the blast radius of an ApplicationSet change before harness_update.\n\n" +
+            "CHANGE TO AN EXISTING APPSET: harness_execute(resource_type='gitops_applicationset', action='preview', resource_id='<appset UUID>', params={agent_id:'account.myagent'}, body={applicationset:{metadata:{...}, spec:{...}}})\n" +
+            "  → also returns blast_radius {to_create, to_delete, kept} against the apps it owns today.\n" +
+            "NEW APPSET: omit resource_id and pass body.applicationset.\n" +
+            "CURRENT APPSET: pass resource_id without a body to list what it generates now.\n\n" +
+            "list, clusters (from the agent's registered clusters), matrix, and merge generators are expanded. " +
+            "git, scmProvider, pullRequest, and plugin generators need data the preview cannot read; they are listed in unrendered_generators.",
+          bodySchema: {
+            description: "The proposed ApplicationSet, in the same shape as harness_create/harness_update.",
+            fields: [
+              { name: "applicationset", type: "object", required: false, description: "Full ArgoCD ApplicationSet object {metadata, spec: {generators, template, goTemplate, syncPolicy}}. Defaults to the stored ApplicationSet." },
+            ],
+          },
+        },
+      },
     },
     {
       resourceType: "gitops_repo_credential",
@@ -1212,7 +1996,7 @@ export const gitopsToolset: ToolsetDefinition = {
       resourceType: "gitops_pod_log",
       displayName: "GitOps Pod Log",
       description:
-        "Pod logs for a GitOps application. Supports get with pod_name, namespace, container, tail_lines.\n" +
+        "Pod logs for a GitOps application. Supports get with pod_name, namespace, container, tail_lines, since_seconds, previous, and follow (bounded polling).\n" +
         "IDENTIFIERS: agent_id is scope-prefixed:\n" +
         "- Account-scoped agent: 'account.myagent'\n" +
         "- Org-scoped agent: 'org.myagent'\n" +
@@ -1235,15 +2019,26 @@ export const gitopsToolset: ToolsetDefinition = {
             namespace: "namespace",
             container: "container",
             tail_lines: "tailLines",
+            since_seconds: "sinceSeconds",
+            previous: "previous",
           },
-          responseExtractor: passthrough,
-          description: "Get pod logs for a GitOps application",
+          collect: collectGitopsPodLogs,
+          responseExtractor: gitopsPodLogExtract,
+          skipCompact: true,
+          skipCache: true,
+          description:
+            "Get pod logs for a GitOps application as {lines[], line_count}. For a crash-looping pod, pass previous=true for the last terminated container's output, " +
+            "or follow=true to keep polling for new lines for up to follow_seconds.",
           paramsSchema: {
             fields: [
               { name: "pod_name", required: false, description: "Pod name filter" },
               { name: "namespace", required: false, description: "Kubernetes namespace filter" },
               { name: "container", required: false, description: "Container name filter" },
               { name: "tail_lines", required: false, description: "Number of log lines to tail" },
+              { name: "since_seconds", required: false, description: "Only lines written in the last N seconds" },
+              { name: "previous", required: false, description: "true to read the previous (terminated) container instance — the logs of the crash in a CrashLoopBackOff" },
+              { name: "follow", required: false, description: "true to keep polling for new lines instead of a one-shot fetch" },
+              { name: "follow_seconds", required: false, description: `How long to follow (default ${POD_LOG_FOLLOW_DEFAULT_SECONDS}, max ${POD_LOG_FOLLOW_MAX_SECONDS})` },
             ],
           } satisfies ParamsSchema,
         },
@@ -1360,8 +2155,86 @@ export const gitopsToolset: ToolsetDefinition = {
             agent_id: "agentIdentifier",
             app_name: "appName",
           },
-          responseExtractor: passthrough,
-          description: "Get the Kubernetes resource tree for a GitOps application",
+          responseExtractor: gitopsResourceTreeExtract,
+          description: "Get the Kubernetes resource tree for a GitOps application. Pass params={format: 'mermaid'} to add a Mermaid flowchart of owner → owned resources, coloured by health, in the mermaid field.",
+          paramsSchema: {
+            fields: [
+              { name: "format", required: false, description: "'mermaid' to add a ready-to-render Mermaid flowchart of the tree alongside the JSON" },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "gitops_app_diff",
+      displayName: "GitOps App Diff",
+      description:
+        "Drift between a GitOps application's live cluster state and its desired state in Git, as a manifest diff per managed resource. Supports get.\n" +
+        "Each resource is 'out_of_sync' (fields differ), 'missing' (in Git, not in the cluster), 'extra' (in the cluster, no longer in Git), or 'synced'. Server-managed metadata and status are ignored.\n" +
+        "IDENTIFIERS: resource_id is the app name; agent_id is scope-prefixed:\n" +
+        "- Account-scoped agent: 'account.myagent'\n" +
+        "- Org-scoped agent: 'org.myagent'\n" +
+        "- Project-scoped agent: 'myagent' (no prefix)",
+      toolset: "gitops",
+      scope: "project",
+      identifierFields: ["agent_id", "app_name"],
+      deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/gitops/applications/{appName}",
+      operations: {
+        get: {
+          method: "GET",
+          path: "/gitops/api/v1/agents/{agentIdentifier}/applications/{appName}/managed-resources",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            agent_id: "agentIdentifier",
+            app_name: "appName",
+          },
+          responseExtractor: gitopsAppDiffExtract,
+          description: "Get the live vs desired manifest diff for each resource of a GitOps application",
+          paramsSchema: {
+            fields: [
+              { name: "agent_id", required: true, description: "Scope-prefixed agent identifier (e.g. 'account.myagent')." },
+              { name: "kind", required: false, description: "Only resources of this Kubernetes kind (e.g. 'Deployment')." },
+              { name: "namespace", required: false, description: "Only resources in this namespace." },
+              { name: "resource_name", required: false, description: "Only the resource with this name." },
+              { name: "include_synced", required: false, description: "Also list resources with no drift (default false; they are always counted in summary)." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "gitops_app_history",
+      displayName: "GitOps App History",
+      description:
+        "Chronological sync and health history of a GitOps application, newest first: deployments (revision, duration, initiator), the last operation, failed syncs, and health/sync status changes. Supports get.\n" +
+        "last_degraded answers 'when did this app last go Degraded and why': the time, the deployment or sync just before it, and warning events around it.\n" +
+        "IDENTIFIERS: resource_id is the app name; agent_id is scope-prefixed:\n" +
+        "- Account-scoped agent: 'account.myagent'\n" +
+        "- Org-scoped agent: 'org.myagent'\n" +
+        "- Project-scoped agent: 'myagent' (no prefix)",
+      toolset: "gitops",
+      scope: "project",
+      identifierFields: ["agent_id", "app_name"],
+      deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/gitops/applications/{appName}",
+      operations: {
+        get: {
+          method: "GET",
+          path: "/gitops/api/v1/agents/{agentIdentifier}/applications/{appName}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            agent_id: "agentIdentifier",
+            app_name: "appName",
+          },
+          collect: collectGitopsAppHistory,
+          responseExtractor: gitopsAppHistoryExtract,
+          skipCompact: true,
+          description: "Get the sync and health history of a GitOps application",
+          paramsSchema: {
+            fields: [
+              { name: "agent_id", required: true, description: "Scope-prefixed agent identifier (e.g. 'account.myagent')." },
+              { name: "limit", required: false, description: "Most recent timeline entries to return (default 50)." },
+            ],
+          } satisfies ParamsSchema,
         },
       },
     },
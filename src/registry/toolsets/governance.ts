@@ -1,5 +1,9 @@
-import type { ToolsetDefinition, BodySchema } from "../types.js";
-import { v1ListExtract, passthrough } from "../extractors.js";
+import type { ToolsetDefinition, BodySchema, ParamsSchema, PreflightContext } from "../types.js";
+import { v1ListExtract, passthrough, deprecationScanExtract, type DeprecationScan } from "../extractors.js";
+import { POLICY_PACKS, type PolicyPack } from "../../data/policy-packs.js";
+import { HarnessApiError } from "../../utils/errors.js";
+import { isRecord } from "../../utils/type-guards.js";
+import { fanOut } from "../../utils/fan-out.js";
 
 // ---------------------------------------------------------------------------
 // Body schemas (for harness_describe output)
@@ -53,6 +57,234 @@ const policySetUpdateSchema: BodySchema = {
   ],
 };
 
+// ---------------------------------------------------------------------------
+// Policy packs
+// ---------------------------------------------------------------------------
+
+const PACK_SEVERITIES = ["warning", "error"] as const;
+type PackSeverity = (typeof PACK_SEVERITIES)[number];
+
+function findPack(input: Record<string, unknown>): PolicyPack {
+  const packId = input.pack_id;
+  if (typeof packId !== "string" || !packId) throw new Error("pack_id is required");
+  const pack = POLICY_PACKS.find((p) => p.id === packId);
+  if (!pack) {
+    throw new Error(`Unknown policy pack "${packId}". Available: ${POLICY_PACKS.map((p) => p.id).join(", ")}`);
+  }
+  return pack;
+}
+
+function packSummary(pack: PolicyPack) {
+  return {
+    pack_id: pack.id,
+    name: pack.name,
+    category: pack.category,
+    description: pack.description,
+    entity_type: pack.entityType,
+    action: pack.action,
+    policies: pack.policies.map((p) => ({ identifier: p.identifier, name: p.name, description: p.description })),
+  };
+}
+
+function parseSeverity(value: unknown, field: string): PackSeverity | undefined {
+  if (value === undefined) return undefined;
+  if (!PACK_SEVERITIES.includes(value as PackSeverity)) {
+    throw new Error(`${field} must be one of: ${PACK_SEVERITIES.join(", ")}`);
+  }
+  return value as PackSeverity;
+}
+
+/** Create or update failures that mean "this identifier is already taken". */
+function isAlreadyExists(err: unknown): boolean {
+  if (err instanceof HarnessApiError && err.statusCode === 409) return true;
+  return err instanceof Error && /already exists/i.test(err.message);
+}
+
+async function listPolicyPacks(ctx: PreflightContext) {
+  const category = ctx.input.category;
+  const packs = typeof category === "string" && category
+    ? POLICY_PACKS.filter((p) => p.category === category)
+    : POLICY_PACKS;
+  return { items: packs.map(packSummary), total: packs.length };
+}
+
+async function getPolicyPack(ctx: PreflightContext) {
+  const pack = findPack(ctx.input);
+  return {
+    ...packSummary(pack),
+    policies: pack.policies.map((p) => ({ identifier: p.identifier, name: p.name, description: p.description, rego: p.rego })),
+  };
+}
+
+/**
+ * Install a pack: create each selected policy, then one policy set holding
+ * them with the chosen severities. Identifiers that already exist are left
+ * as they are unless overwrite is set, so re-running an install never
+ * silently replaces a policy someone has edited.
+ */
+async function installPolicyPack(ctx: PreflightContext) {
+  const { client, input, registry, signal } = ctx;
+  const pack = findPack(input);
+  const body = isRecord(input.body) ? input.body : {};
+  const option = (name: string): unknown => body[name] ?? input[name];
+
+  const severity = parseSeverity(option("severity"), "severity") ?? "warning";
+  const overrides = isRecord(option("severities")) ? option("severities") as Record<string, unknown> : {};
+  for (const [id, value] of Object.entries(overrides)) parseSeverity(value, `severities.${id}`);
+
+  const requested = option("policies");
+  let selected = pack.policies;
+  if (Array.isArray(requested) && requested.length > 0) {
+    const missing = requested.filter((id) => !pack.policies.some((p) => p.identifier === id));
+    if (missing.length > 0) {
+      throw new Error(`Policies not in pack ${pack.id}: ${missing.join(", ")}. Available: ${pack.policies.map((p) => p.identifier).join(", ")}`);
+    }
+    selected = pack.policies.filter((p) => requested.includes(p.identifier));
+  }
+  const overwrite = option("overwrite") === true;
+  const enabled = option("enabled") !== false;
+  const policySetId = typeof option("policy_set_id") === "string" && option("policy_set_id") ? option("policy_set_id") as string : pack.id;
+
+  const scope: Record<string, unknown> = {};
+  for (const key of ["resource_scope", "org_id", "project_id"]) {
+    if (input[key] !== undefined) scope[key] = input[key];
+  }
+
+  const policies: Array<{ identifier: string; severity: PackSeverity; status: string; error?: string }> = [];
+  for (const policy of selected) {
+    const policySeverity = (overrides[policy.identifier] as PackSeverity | undefined) ?? severity;
+    const entry = { identifier: policy.identifier, severity: policySeverity };
+    try {
+      await registry.dispatch(client, "policy", "create", { ...scope, body: { identifier: policy.identifier, name: policy.name, rego: policy.rego } }, signal);
+      policies.push({ ...entry, status: "created" });
+    } catch (err) {
+      if (!isAlreadyExists(err)) {
+        policies.push({ ...entry, status: "failed", error: err instanceof Error ? err.message : String(err) });
+        continue;
+      }
+      if (!overwrite) {
+        policies.push({ ...entry, status: "exists" });
+        continue;
+      }
+      await registry.dispatch(client, "policy", "update", { ...scope, policy_id: policy.identifier, body: { name: policy.name, rego: policy.rego } }, signal);
+      policies.push({ ...entry, status: "updated" });
+    }
+  }
+
+  const members = policies.filter((p) => p.status !== "failed").map((p) => ({ identifier: p.identifier, severity: p.severity }));
+  let policySet: { identifier: string; status: string; error?: string };
+  if (members.length === 0) {
+    policySet = { identifier: policySetId, status: "skipped", error: "No policies were installed" };
+  } else {
+    const setBody = {
+      name: pack.name,
+      action: pack.action,
+      type: pack.entityType,
+      enabled,
+      description: `${pack.description} (installed from policy pack ${pack.id})`,
+      policies: members,
+    };
+    try {
+      await registry.dispatch(client, "policy_set", "create", { ...scope, body: { identifier: policySetId, ...setBody } }, signal);
+      policySet = { identifier: policySetId, status: "created" };
+    } catch (err) {
+      if (isAlreadyExists(err) && overwrite) {
+        await registry.dispatch(client, "policy_set", "update", { ...scope, policy_set_id: policySetId, body: setBody }, signal);
+        policySet = { identifier: policySetId, status: "updated" };
+      } else {
+        policySet = { identifier: policySetId, status: isAlreadyExists(err) ? "exists" : "failed", error: err instanceof Error ? err.message : String(err) };
+      }
+    }
+  }
+
+  return {
+    pack_id: pack.id,
+    installed: policySet.status === "created" || policySet.status === "updated",
+    enabled,
+    entity_type: pack.entityType,
+    action: pack.action,
+    policies,
+    policy_set: policySet,
+    ...(policySet.status === "exists"
+      ? { _hint: `Policy set ${policySetId} already exists. Pass overwrite=true to replace its policies, or policy_set_id to install alongside it.` }
+      : {}),
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Deprecation scan
+// ---------------------------------------------------------------------------
+
+const DEPRECATION_DEFAULT_PIPELINES = 25;
+const DEPRECATION_MAX_PIPELINES = 100;
+const DEPRECATION_LIST_SIZE = 100;
+const DEPRECATION_CONCURRENCY = 4;
+
+function listItems(result: unknown): unknown[] {
+  return isRecord(result) && Array.isArray(result.items) ? result.items : [];
+}
+
+function errorMessage(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+/**
+ * Gather what deprecation_scan inspects: the YAML of up to max_pipelines
+ * pipelines in the project, every template version visible from it, and the
+ * connectors available at the scope. A list or pipeline that fails to load
+ * is recorded and the scan continues with the rest.
+ */
+async function collectDeprecationScan(ctx: PreflightContext): Promise<DeprecationScan> {
+  const { client, input, registry, signal } = ctx;
+  const orgId = (input.org_id as string | undefined) ?? registry.orgId;
+  const projectId = (input.project_id as string | undefined) ?? registry.projectId;
+  const scope = { ...(orgId ? { org_id: orgId } : {}), ...(projectId ? { project_id: projectId } : {}) };
+  const requested = Math.trunc(Number(input.max_pipelines ?? DEPRECATION_DEFAULT_PIPELINES)) || DEPRECATION_DEFAULT_PIPELINES;
+  const maxPipelines = Math.min(Math.max(requested, 1), DEPRECATION_MAX_PIPELINES);
+
+  const [pipelines, templates, connectors] = await Promise.allSettled([
+    registry.dispatch(client, "pipeline", "list", { ...scope, size: maxPipelines }, signal),
+    registry.dispatch(client, "template", "list", { ...scope, template_list_type: "All", size: DEPRECATION_LIST_SIZE }, signal),
+    registry.dispatch(client, "connector", "list", { ...scope, size: DEPRECATION_LIST_SIZE, include_all_connectors_available_at_scope: true }, signal),
+  ]);
+  const scan: DeprecationScan = {
+    ...scope,
+    pipelines: [],
+    pipelines_total: 0,
+    templates: templates.status === "fulfilled" ? listItems(templates.value) : [],
+    connectors: connectors.status === "fulfilled" ? listItems(connectors.value) : [],
+    errors: [],
+  };
+  if (templates.status === "rejected") scan.errors.push(`templates: ${errorMessage(templates.reason)}`);
+  if (connectors.status === "rejected") scan.errors.push(`connectors: ${errorMessage(connectors.reason)}`);
+  if (pipelines.status === "rejected") {
+    scan.errors.push(`pipelines: ${errorMessage(pipelines.reason)}`);
+    return scan;
+  }
+
+  const listed = listItems(pipelines.value).filter(isRecord).filter((p) => typeof p.identifier === "string");
+  const total = isRecord(pipelines.value) && typeof pipelines.value.total === "number" ? pipelines.value.total : listed.length;
+  scan.pipelines_total = Math.max(total, listed.length);
+  const { results, errors } = await fanOut(
+    listed.slice(0, maxPipelines),
+    (p, sig) => registry.dispatch(client, "pipeline", "get", { ...scope, pipeline_id: p.identifier }, sig),
+    { concurrency: DEPRECATION_CONCURRENCY, signal },
+  );
+  const summary = (p: Record<string, unknown>) => ({
+    identifier: p.identifier as string,
+    ...(typeof p.name === "string" ? { name: p.name } : {}),
+    ...(typeof p.openInHarness === "string" ? { link: p.openInHarness } : {}),
+  });
+  for (const { item, value } of results) {
+    scan.pipelines.push({ ...summary(item), yaml: isRecord(value) ? value.yamlPipeline : undefined });
+  }
+  for (const { item, error } of errors) {
+    scan.pipelines.push({ ...summary(item), error });
+    scan.errors.push(`pipeline ${String(item.identifier)}: ${error}`);
+  }
+  return scan;
+}
+
 // ---------------------------------------------------------------------------
 // Toolset definition
 // ---------------------------------------------------------------------------
@@ -60,7 +292,7 @@ const policySetUpdateSchema: BodySchema = {
 export const governanceToolset: ToolsetDefinition = {
   name: "governance",
   displayName: "Governance",
-  description: "OPA policy management — policies, policy sets, and policy evaluation results",
+  description: "OPA policy management — policies, policy sets, policy evaluation results, and built-in policy packs",
   resources: [
     // ----- Policy -----
     {
@@ -80,6 +312,7 @@ export const governanceToolset: ToolsetDefinition = {
       ],
       toolset: "governance",
       scope: "project",
+      supportedScopes: ["account", "org", "project"],
       identifierFields: ["policy_id"],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/settings/governance/policies/edit/{identifier}",
       listFilterFields: [
@@ -166,6 +399,7 @@ export const governanceToolset: ToolsetDefinition = {
       ],
       toolset: "governance",
       scope: "project",
+      supportedScopes: ["account", "org", "project"],
       identifierFields: ["policy_set_id"],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/settings/governance/policy-sets/{identifier}",
       listFilterFields: [
@@ -296,5 +530,106 @@ export const governanceToolset: ToolsetDefinition = {
         },
       },
     },
+
+    // ----- Policy Pack (built-in catalog) -----
+    {
+      resourceType: "policy_pack",
+      displayName: "Policy Pack",
+      description: "Built-in library of OPA policy packs grouped by category (security, cost, quality). "
+        + "Each pack is a few Rego policies sharing one entity type and enforcement action. "
+        + "List to browse, get to preview the Rego, and execute action 'install' to create the policies and a policy set "
+        + "in the policy service with a chosen severity (warning or error). The catalog ships with the server; nothing is read from Harness until install.",
+      searchAliases: ["policy library", "policy samples", "governance pack", "opa samples", "policy templates", "governance rollout"],
+      relatedResources: [
+        { resourceType: "policy", relationship: "child", description: "Policies created when a pack is installed" },
+        { resourceType: "policy_set", relationship: "child", description: "Policy set created when a pack is installed (identifier defaults to the pack_id)" },
+      ],
+      toolset: "governance",
+      scope: "project",
+      supportedScopes: ["account", "org", "project"],
+      identifierFields: ["pack_id"],
+      listFilterFields: [
+        { name: "category", description: "Filter packs by category", enum: ["security", "cost", "quality"] },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/pm/api/v1/policies",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: listPolicyPacks,
+          responseExtractor: passthrough,
+          skipCompact: true,
+          description: "List built-in policy packs with their policies (without Rego). Scope params are ignored; the catalog is the same everywhere.",
+        },
+        get: {
+          method: "GET",
+          path: "/pm/api/v1/policies",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: getPolicyPack,
+          responseExtractor: passthrough,
+          description: "Get a built-in policy pack, including the Rego source of every policy",
+        },
+      },
+      executeActions: {
+        install: {
+          method: "POST",
+          path: "/pm/api/v1/policysets",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          collect: installPolicyPack,
+          responseExtractor: passthrough,
+          skipCompact: true,
+          actionDescription: "Install a built-in policy pack: create its policies and one policy set (type and action from the pack) that enforces them. "
+            + "Pass resource_id=<pack_id>. Installs at project scope by default; pass resource_scope='account' or 'org' to govern more broadly. "
+            + "Policies or a policy set that already exist are reported as 'exists' and left unchanged unless overwrite=true. "
+            + "Returns per-policy status (created, updated, exists, failed) and the policy set status.",
+          bodySchema: {
+            description: "Install options. All fields are optional.",
+            fields: [
+              { name: "severity", type: "string", required: false, description: "Enforcement level for every policy: 'warning' (report and continue, default) or 'error' (block the run or save)." },
+              { name: "severities", type: "object", required: false, description: "Per-policy severity overrides: { <policy identifier>: 'warning' | 'error' }." },
+              { name: "policies", type: "array", required: false, description: "Policy identifiers to install from the pack (default: all).", itemType: "string" },
+              { name: "policy_set_id", type: "string", required: false, description: "Identifier for the policy set (default: the pack_id)." },
+              { name: "enabled", type: "boolean", required: false, description: "Enable the policy set on install (default true). Pass false to stage it disabled." },
+              { name: "overwrite", type: "boolean", required: false, description: "Replace the Rego of existing policies and the members of an existing policy set (default false)." },
+            ],
+          },
+        },
+      },
+    },
+    {
+      resourceType: "deprecation_scan",
+      displayName: "Deprecation Scan",
+      description: "Migration worklist of deprecated constructs in a project: pipelines and templates using superseded step types "
+        + "(Security, ServerlessAwsLambdaDeploy/Rollback v1, JenkinsBuild), Service v1 (serviceConfig) or Infrastructure v1 stages, or Helm 2; "
+        + "pipelines and templates pinned to a template version that is not stable or no longer exists; and legacy connectors (AWS CodeCommit, Nexus 2). "
+        + "Supports get only. Each worklist entry has the entity, a link to it in Harness, and its findings with the location and the replacement to migrate to.",
+      searchAliases: ["deprecated", "deprecation", "migration worklist", "legacy steps", "service v1", "upgrade plan", "tech debt"],
+      relatedResources: [
+        { resourceType: "pipeline", relationship: "related", description: "Pipelines scanned for deprecated steps and stage definitions" },
+        { resourceType: "template", relationship: "related", description: "Template versions scanned and checked against pinned references" },
+        { resourceType: "connector", relationship: "related", description: "Connectors checked for legacy types" },
+      ],
+      toolset: "governance",
+      scope: "project",
+      identifierFields: [],
+      operations: {
+        get: {
+          method: "POST",
+          path: "/pipeline/api/pipelines/list",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectDeprecationScan,
+          responseExtractor: deprecationScanExtract,
+          skipCompact: true,
+          description: `Scan the project's pipelines (up to max_pipelines, default ${DEPRECATION_DEFAULT_PIPELINES}), template versions, and connectors for deprecated constructs. `
+            + "Returns scanned counts, summary {entities, findings, by_kind}, and worklist[] {entity_type, identifier, name, link, findings[] {kind, construct, location, replacement}}. "
+            + "Kinds: deprecated_step, service_v1, infrastructure_v1, helm_v2, legacy_connector, unstable_template_version, missing_template_version.",
+          paramsSchema: {
+            fields: [
+              { name: "max_pipelines", required: false, description: `Most pipelines to scan (default ${DEPRECATION_DEFAULT_PIPELINES}, max ${DEPRECATION_MAX_PIPELINES}).` },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
   ],
 };
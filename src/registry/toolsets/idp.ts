@@ -1,5 +1,15 @@
-import type { BodySchema, PathBuilderConfig, ToolsetDefinition } from "../types.js";
-import { ngExtract, passthrough, v1ListExtract } from "../extractors.js";
+import type { BodySchema, PathBuilderConfig, PreflightContext, ToolsetDefinition } from "../types.js";
+import {
+  idpScoreExtract,
+  idpScorecardStatsExtract,
+  idpWorkflowExecuteExtract,
+  idpWorkflowExtract,
+  idpWorkflowInputs,
+  idpWorkflowRunExtract,
+  passthrough,
+  v1ListExtract,
+} from "../extractors.js";
+import { isRecord } from "../../utils/type-guards.js";
 import { parse as parseYaml } from "yaml";
 
 const CONFIG_API_KEY = "__config_api_key";
@@ -38,15 +48,6 @@ const extractAuthParamRefs = (yamlStr: string): { apikeyRefs: string[]; apiKeySe
   return { apikeyRefs, apiKeySecretRefs };
 };
 
-const scorecardStatsExtract = (raw: unknown): unknown => {
-  const r = raw as { name?: string; stats?: unknown[]; timestamp?: number | null };
-  return {
-    name: r.name,
-    stats: r.stats ?? [],
-    time: r.timestamp != null ? new Date(r.timestamp).toISOString() : "",
-  };
-};
-
 const idpEntityMutateBodySchema: BodySchema = {
   description: "IDP catalog entity YAML payload",
   fields: [
@@ -112,6 +113,46 @@ const buildIdpEntityScopePath = (input: Record<string, unknown>, config: PathBui
   return `/v1/entities/${encodeURIComponent(scope)}/${encodeURIComponent(kind)}/${encodeURIComponent(entityId)}`;
 };
 
+/** Scope path and query params for a workflow entity, the same lookup idp_entity get does with kind=workflow. */
+const buildIdpWorkflowPath = (input: Record<string, unknown>, config: PathBuilderConfig): string => {
+  const workflowId = input.workflow_id as string | undefined;
+  if (!workflowId) {
+    throw new Error(`Missing required field "workflow_id" for idp_workflow. Pass it via params or as resource_id.`);
+  }
+  input.kind = "workflow";
+  input.entity_id = workflowId;
+  return buildIdpEntityScopePath(input, config);
+};
+
+/**
+ * Fetch the workflow entity when execute is called without workflow_details,
+ * so the body builder can inspect its steps and required inputs.
+ */
+const loadWorkflowDetails = async ({ client, input, registry, signal }: PreflightContext): Promise<void> => {
+  const body = isRecord(input.body) ? input.body : {};
+  if (body.workflow_details !== undefined) return;
+  const workflowId = (body.identifier as string | undefined) ?? (input.workflow_id as string | undefined);
+  if (!workflowId) return;
+
+  const lookup: Record<string, unknown> = { ...input, workflow_id: workflowId };
+  const path = buildIdpWorkflowPath(lookup, {
+    HARNESS_ACCOUNT_ID: client.account,
+    HARNESS_ORG: registry.orgId,
+    HARNESS_PROJECT: registry.projectId,
+  });
+  const raw = await client.request<unknown>({
+    method: "GET",
+    path,
+    params: {
+      ...(typeof lookup.org_id === "string" ? { orgIdentifier: lookup.org_id } : {}),
+      ...(typeof lookup.project_id === "string" ? { projectIdentifier: lookup.project_id } : {}),
+    },
+    signal,
+  });
+  const entity = isRecord(raw) && isRecord(raw.data) && raw.yaml === undefined ? raw.data : raw;
+  input.body = { ...body, workflow_details: entity };
+};
+
 const buildIdpEntityMutateBody = (input: Record<string, unknown>): Record<string, unknown> => {
   const body = input.body;
   if (typeof body === "string") {
@@ -493,10 +534,21 @@ export const idpToolset: ToolsetDefinition = {
     {
       resourceType: "scorecard_stats",
       displayName: "Scorecard Stats",
-      description: "Aggregate statistics for an IDP scorecard — the scores of every entity that has this scorecard configured. Supports get.",
+      description:
+        "Aggregate statistics for an IDP scorecard — the scores of every entity that has this scorecard configured. Supports get. " +
+        "To find the services falling short (e.g. 'which services fail the production-readiness scorecard'), pass below_score=100 (or a passing threshold) and type='service'; matches come lowest score first.",
       toolset: "idp",
       scope: "account",
       identifierFields: ["scorecard_id"],
+      listFilterFields: [
+        { name: "below_score", type: "number", description: "(get) Only entities scoring below this, lowest first" },
+        { name: "kind", description: "(get) Only entities of this kind, e.g. Component or API" },
+        { name: "type", description: "(get) Only entities of this type, e.g. service, library, website" },
+      ],
+      relatedResources: [
+        { resourceType: "scorecard_check_stats", relationship: "child", description: "Which entities fail one of the scorecard's checks (status='FAIL')" },
+        { resourceType: "idp_score", relationship: "related", description: "One entity's results on every scorecard, check by check" },
+      ],
       deepLinkTemplate: "/ng/account/{accountId}/idp/scorecards/{scorecardIdentifier}",
       operations: {
         get: {
@@ -504,18 +556,26 @@ export const idpToolset: ToolsetDefinition = {
           path: "/v1/scorecards/{scorecardIdentifier}/stats",
           operationPolicy: { risk: "read", retryPolicy: "safe" },
           pathParams: { scorecard_id: "scorecardIdentifier" },
-          responseExtractor: scorecardStatsExtract,
-          description: "Get aggregate stats for a scorecard — the scores of every entity that has this scorecard configured. The raw 'timestamp' field is converted to RFC3339 'time'.",
+          responseExtractor: idpScorecardStatsExtract,
+          description: "Get aggregate stats for a scorecard — the scores of every entity that has this scorecard configured. The raw 'timestamp' field is converted to RFC3339 'time'. Optional below_score, kind, and type narrow the entities and add matched/entities counts.",
         },
       },
     },
     {
       resourceType: "scorecard_check_stats",
       displayName: "Scorecard Check Stats",
-      description: "Statistics for a specific scorecard check — the PASS/FAIL status for every entity whose scorecard contains this check. Supports get.",
+      description:
+        "Statistics for a specific scorecard check — the PASS/FAIL status for every entity whose scorecard contains this check. Supports get. " +
+        "Pass status='FAIL' to list only the components failing the check.",
       toolset: "idp",
       scope: "account",
       identifierFields: ["check_id"],
+      listFilterFields: [
+        { name: "status", description: "(get) Only entities with this check result", enum: ["PASS", "FAIL"] },
+        { name: "kind", description: "(get) Only entities of this kind, e.g. Component or API" },
+        { name: "type", description: "(get) Only entities of this type, e.g. service, library, website" },
+        { name: "is_custom", description: "(get) Whether the check is a custom check", type: "boolean" },
+      ],
       deepLinkTemplate: "/ng/account/{accountId}/idp/scorecards",
       operations: {
         get: {
@@ -525,8 +585,8 @@ export const idpToolset: ToolsetDefinition = {
           pathParams: { check_id: "checkIdentifier" },
           queryParams: { is_custom: "custom" },
           defaultQueryParams: { custom: "false" },
-          responseExtractor: scorecardStatsExtract,
-          description: "Get stats for a scorecard check — the PASS/FAIL status for every entity whose scorecard contains this check. Pass is_custom=true for custom checks. The raw 'timestamp' field is converted to RFC3339 'time'.",
+          responseExtractor: idpScorecardStatsExtract,
+          description: "Get stats for a scorecard check — the PASS/FAIL status for every entity whose scorecard contains this check. Pass is_custom=true for custom checks. The raw 'timestamp' field is converted to RFC3339 'time'. Optional status (PASS/FAIL), kind, and type narrow the entities and add matched/entities counts.",
         },
       },
     },
@@ -548,12 +608,8 @@ export const idpToolset: ToolsetDefinition = {
           queryParams: {
             entity_identifier: "entity_identifier",
           },
-          responseExtractor: (raw: unknown) => {
-            const r = raw as { overall_score?: number; scorecard_scores?: unknown[] };
-            const items = r.scorecard_scores ?? [];
-            return { overall_score: r.overall_score, items, total: items.length };
-          },
-          description: "Get scores for every scorecard configured against an entity. Required filter: entity_identifier (format 'namespace/Kind/name', e.g. 'default/Component/my-service').",
+          responseExtractor: idpScoreExtract,
+          description: "Get scores for every scorecard configured against an entity, with each scorecard's passed/failed check counts and failing checks. Required filter: entity_identifier (format 'namespace/Kind/name', e.g. 'default/Component/my-service').",
         },
       },
     },
@@ -590,11 +646,16 @@ export const idpToolset: ToolsetDefinition = {
       resourceType: "idp_workflow",
       displayName: "IDP Workflow",
       description:
-        "IDP self-service workflow. Supports list and execute. " +
-        "Workflows are IDP catalog entities with kind=workflow — list here is a thin wrapper over /v1/entities that pins kind=workflow and exposes the same filter surface as idp_entity (search_term, scope_level, owned_by_me, favorites, owner, lifecycle, tags, sort).",
+        "IDP self-service workflow (software template). Supports list, get, and execute. " +
+        "Workflows are IDP catalog entities with kind=workflow — list here is a thin wrapper over /v1/entities that pins kind=workflow and exposes the same filter surface as idp_entity (search_term, scope_level, owned_by_me, favorites, owner, lifecycle, tags, sort). " +
+        "get returns the workflow's input schema; execute starts a run, tracked with idp_workflow_run.",
       toolset: "idp",
       scope: "account",
       identifierFields: ["workflow_id"],
+      searchAliases: ["software template", "scaffolder", "self-service", "golden path"],
+      relatedResources: [
+        { resourceType: "idp_workflow_run", relationship: "child", description: "Status and output of a run started by execute (by task_id)" },
+      ],
       listFilterFields: [
         { name: "search_term", description: "Filter workflows by name or keyword" },
         { name: "scope_level", description: "Scope level for the workflow query. 'default' uses the configured org/project; 'account', 'org', and 'project' force that scope explicitly.", enum: ["default", "account", "org", "project"] },
@@ -662,7 +723,19 @@ export const idpToolset: ToolsetDefinition = {
             favorites: "false",
           },
           responseExtractor: v1ListExtract(),
-          description: "List IDP self-service workflows. Pins kind=workflow on the underlying /v1/entities call. Defaults: page=0, limit=20 (max 100). If 'limit' is not supplied, paginate by calling repeatedly. Workflow entities may include a 'token' field — IGNORE it.",
+          description: "List IDP self-service workflows. Pins kind=workflow on the underlying /v1/entities call. Defaults: page=0, limit=20 (max 100). If 'limit' is not supplied, paginate by calling repeatedly. Workflow entities may include a 'token' field — IGNORE it. Get a workflow for its inputs before executing it.",
+        },
+        get: {
+          method: "GET",
+          path: "/v1/entities/{scope}/workflow/{entityId}",
+          pathBuilder: buildIdpWorkflowPath,
+          queryParams: idpEntityScopeQueryParams,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          responseExtractor: idpWorkflowExtract,
+          description:
+            "Get a workflow's input schema. Returns {identifier, name, description, owner, inputs, required_inputs, steps}. " +
+            "inputs come from the workflow YAML's spec.parameters: [{name, type, title, description, required, default, enum, ui_field, auto_filled}]. " +
+            "Ask the user for each required_inputs entry before executing; auto_filled inputs (Harness auth tokens) are filled by the execute action.",
         },
       },
       executeActions: {
@@ -684,7 +757,7 @@ export const idpToolset: ToolsetDefinition = {
             const wfDetails = b.workflow_details as Record<string, unknown> | undefined;
             if (!wfDetails) {
               throw new Error(
-                "workflow_details is required. Fetch it first with harness_get(resource_type=idp_entity, kind=workflow, entity_id=<id>) and pass the result.",
+                "workflow_details is required. Pass the workflow identifier (body.identifier or resource_id) so it can be fetched, or pass the entity from harness_get(resource_type=idp_entity, kind=workflow, entity_id=<id>).",
               );
             }
 
@@ -727,22 +800,32 @@ export const idpToolset: ToolsetDefinition = {
               }
             }
 
+            const missing = idpWorkflowInputs(yamlStr)
+              .filter((i) => i.required && !i.auto_filled && i.default === undefined && values[i.name] === undefined)
+              .map((i) => i.name);
+            if (missing.length > 0) {
+              throw new Error(
+                `Missing required workflow inputs: ${missing.join(", ")}. Get the workflow with harness_get(resource_type=idp_workflow, resource_id=${identifier}) for their descriptions and allowed values, then pass them in body.values.`,
+              );
+            }
+
             const requestBody = { identifier, values };
             return requestBody;
           },
-          responseExtractor: ngExtract,
+          preflight: loadWorkflowDetails,
+          responseExtractor: idpWorkflowExecuteExtract,
           actionDescription:
-            "Execute a workflow in the Harness IDP Catalog.\n\n" +
+            "Execute a workflow in the Harness IDP Catalog and return the task_id of the run.\n\n" +
             "Required inputs:\n" +
-            "- workflow_details: full workflow entity. Fetch FIRST via harness_get(resource_type=idp_entity, kind=workflow, entity_id=<id>) and pass the result. Required so the tool can inspect spec.steps[] and inject the correct values for HarnessAuthToken-style parameters.\n" +
             "- identifier: workflow identifier (or pass via resource_id; auto-extracted from workflow_details.identifier).\n" +
-            "- values: user-supplied values for the workflow's spec.parameters (validated against required fields). OMIT any parameter whose ui:field is HarnessAuthToken — the tool auto-fills those.\n\n" +
-            "Optional input:\n" +
+            "- values: user-supplied values for the workflow's spec.parameters. Call harness_get(resource_type=idp_workflow) first for the input schema. Missing required inputs are rejected before the run starts. OMIT any parameter whose ui:field is HarnessAuthToken — the tool auto-fills those.\n\n" +
+            "Optional inputs:\n" +
+            "- workflow_details: full workflow entity. Fetched automatically when omitted; the tool inspects its spec.parameters and spec.steps[] to validate values and inject HarnessAuthToken-style parameters.\n" +
             "- api_key_secret: user-supplied API key. Required only when the workflow has a step input named \"apiKeySecret\" AND HARNESS_API_KEY is not configured (e.g. when the server runs in JWT-only mode). Otherwise the tool falls back to HARNESS_API_KEY.\n\n" +
             "Auto-injection rules per step in spec.steps[]:\n" +
             "- step.input.apikey: ${{ parameters.X }} -> values[X] = constant placeholder (\"user.token\")\n" +
             "- step.input.apiKeySecret: ${{ parameters.Y }} -> values[Y] = api_key_secret (or HARNESS_API_KEY fallback)\n\n" +
-            "Do NOT execute if required parameters in spec.parameters[] are missing from values.",
+            "Track the run with harness_get(resource_type=idp_workflow_run, resource_id=<task_id>).",
           bodySchema: {
             description: "Workflow execution inputs.",
             fields: [
@@ -751,7 +834,7 @@ export const idpToolset: ToolsetDefinition = {
                 type: "object",
                 required: false,
                 description:
-                  "Required tool input, not sent to the Harness API. A json representation of the workflow entity. This json contains the metadata of the workflow(like owner, name, description, ref etc) and a yaml field which should contain the spec.parameters against which the values should be validated. Only the parameters marked required are mandatory.",
+                  "Tool input, not sent to the Harness API. A json representation of the workflow entity, with a yaml field containing the spec.parameters the values are validated against. Fetched automatically when omitted.",
               },
               {
                 name: "identifier",
@@ -778,6 +861,29 @@ export const idpToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "idp_workflow_run",
+      displayName: "IDP Workflow Run",
+      description:
+        "A run of an IDP self-service workflow, started by harness_execute(resource_type=idp_workflow, action=execute). Supports get. " +
+        "Use it to follow a run until done is true and read its output links (e.g. the new repository or service).",
+      toolset: "idp",
+      scope: "account",
+      identifierFields: ["task_id"],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/v2/workflows/tasks/{taskId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { task_id: "taskId" },
+          responseExtractor: idpWorkflowRunExtract,
+          description:
+            "Get the status of a workflow run by task_id (returned by the execute action). " +
+            "Returns {task_id, workflow, status, done, succeeded, created_at, steps: [{id, name, action, status, started_at, ended_at}], failed_step, error, output}. " +
+            "status is open, processing, completed, failed, or cancelled; output carries the links and text the workflow publishes.",
+        },
+      },
+    },
     {
       resourceType: "idp_tech_doc",
       displayName: "IDP Tech Doc",
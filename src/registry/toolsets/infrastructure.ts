@@ -1,5 +1,6 @@
 import type { ToolsetDefinition } from "../types.js";
 import { ngExtract, pageExtract } from "../extractors.js";
+import { wrappedEntityRestoreBody } from "../../utils/undo-log.js";
 
 export const infrastructureToolset: ToolsetDefinition = {
   name: "infrastructure",
@@ -22,6 +23,7 @@ export const infrastructureToolset: ToolsetDefinition = {
         { name: "order", description: "Sort order", enum: ["asc", "desc"] },
       ],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/environments",
+      restoreBody: wrappedEntityRestoreBody("infrastructure", ["environmentRef", "type", "deploymentType"]),
       operations: {
         list: {
           method: "GET",
@@ -1,5 +1,92 @@
-import type { ToolsetDefinition, ParamsSchema } from "../types.js";
-import { passthrough } from "../extractors.js";
+import type { ToolsetDefinition, ParamsSchema, PreflightContext } from "../types.js";
+import { passthrough, executionLogTailExtract, type ExecutionLogTailScan } from "../extractors.js";
+import { HarnessApiError } from "../../utils/errors.js";
+import { buildLogPrefixFromExecution } from "../../utils/log-prefix.js";
+import { decodeLogCursor, encodeLogCursor, readLogBlob, readLogStream } from "../../utils/log-stream.js";
+
+const TAIL_DEFAULT_LINES = 200;
+const TAIL_MAX_LINES = 1000;
+const TAIL_DEFAULT_WAIT_MS = 3000;
+const TAIL_MAX_WAIT_MS = 20_000;
+
+function clampInt(value: unknown, fallback: number, min: number, max: number): number {
+  const n = Math.trunc(Number(value));
+  return Number.isFinite(n) ? Math.min(Math.max(n, min), max) : fallback;
+}
+
+/** A stream or blob that does not exist (yet, or any more). */
+function isMissingLog(err: unknown): boolean {
+  return err instanceof HarnessApiError && (err.statusCode === 404 || err.statusCode === 400);
+}
+
+/**
+ * Read the lines of one step's log written since the cursor. Reads the live
+ * stream while the step runs, and the closed log blob once it has finished.
+ */
+async function collectExecutionLogTail(ctx: PreflightContext): Promise<ExecutionLogTailScan> {
+  const { client, input, registry, signal } = ctx;
+  const maxLines = clampInt(input.max_lines, TAIL_DEFAULT_LINES, 1, TAIL_MAX_LINES);
+  const waitMs = clampInt(input.wait_ms, TAIL_DEFAULT_WAIT_MS, 0, TAIL_MAX_WAIT_MS);
+  const executionId = typeof input.execution_id === "string" && input.execution_id ? input.execution_id : undefined;
+
+  let key: string;
+  let offset = 0;
+  if (typeof input.cursor === "string" && input.cursor) {
+    const cursor = decodeLogCursor(input.cursor);
+    if (!cursor) throw new Error("Invalid cursor. Pass the cursor from the previous execution_log_tail result unchanged.");
+    key = cursor.key;
+    offset = cursor.offset;
+  } else if (typeof input.log_key === "string" && input.log_key) {
+    key = input.log_key;
+  } else {
+    if (!executionId) throw new Error("execution_id is required (or params.log_key / params.cursor).");
+    key = await buildLogPrefixFromExecution(client, registry, executionId, input);
+    if (typeof input.command_unit === "string" && input.command_unit) key = `${key}-commandUnit:${input.command_unit}`;
+  }
+
+  const base = { ...(executionId ? { execution_id: executionId } : {}), log_key: key };
+  try {
+    const response = await client.requestStream({
+      method: "GET",
+      path: "/gateway/log-service/stream",
+      params: { key },
+      headers: { Accept: "text/event-stream" },
+      timeoutMs: waitMs + 10_000,
+      signal,
+    });
+    if (response.body) {
+      const result = await readLogStream(response.body, { skip: offset, maxLines, waitMs });
+      // A stream that closes before replaying past the cursor was already
+      // closed on the server: read the rest from the blob instead.
+      if (!(result.ended && result.lines.length === 0)) {
+        return { ...base, source: "live", lines: result.lines, ended: result.ended, cursor: encodeLogCursor({ key, offset: result.offset }) };
+      }
+    }
+  } catch (err) {
+    if (!isMissingLog(err)) throw err;
+  }
+
+  try {
+    const response = await client.requestStream({
+      method: "GET",
+      path: "/gateway/log-service/blob",
+      params: { key },
+      signal,
+    });
+    const result = readLogBlob(await response.text(), { skip: offset, maxLines });
+    return {
+      ...base,
+      source: "complete",
+      lines: result.lines,
+      ended: result.ended,
+      cursor: encodeLogCursor({ key, offset: result.offset }),
+      total_lines: result.total,
+    };
+  } catch (err) {
+    if (!isMissingLog(err)) throw err;
+    return { ...base, source: "pending", lines: [], ended: false, cursor: encodeLogCursor({ key, offset }) };
+  }
+}
 
 export const logsToolset: ToolsetDefinition = {
   name: "logs",
@@ -9,7 +96,7 @@ export const logsToolset: ToolsetDefinition = {
     {
       resourceType: "execution_log",
       displayName: "Execution Log",
-      description: "Pipeline execution logs. Returns readable log text by default for backward compatibility. Set return_download_url=true to return only a signed download URL without downloading log content. Accepts a raw Harness logBaseKey prefix, or an execution_id to auto-resolve the real log key from the execution graph. When a Harness execution URL includes step/stage query params, the MCP uses them to resolve the matching step log key. Use harness_diagnose with include_logs=true for the best failure analysis experience.",
+      description: "Pipeline execution logs. Returns readable log text by default for backward compatibility. Set return_download_url=true to return only a signed download URL without downloading log content. Accepts a raw Harness logBaseKey prefix, or an execution_id to auto-resolve the real log key from the execution graph. When a Harness execution URL includes step/stage query params, the MCP uses them to resolve the matching step log key. Use harness_diagnose with include_logs=true for the best failure analysis experience. For a step that is still running, use execution_log_tail.",
       toolset: "logs",
       scope: "project",
       identifierFields: ["prefix"],
@@ -22,7 +109,7 @@ export const logsToolset: ToolsetDefinition = {
             prefix: "prefix",
           },
           responseExtractor: passthrough,
-          description: "Download and return execution log content by prefix, or return a signed download URL when return_download_url=true",
+          description: "Download and return execution log text by prefix or execution_id (optionally one step via step_id/stage_id) as {log_content, log_key}, or return a signed download URL when return_download_url=true. Logs over max_chars are cut in the middle and marked with truncated: true",
           paramsSchema: {
             fields: [
               {
@@ -30,8 +117,60 @@ export const logsToolset: ToolsetDefinition = {
                 required: false,
                 description: "Execution identifier — auto-builds log prefix from execution metadata",
               },
+              {
+                name: "step_id",
+                required: false,
+                description: "With execution_id: return only this step's log (step identifier or node id)",
+              },
+              {
+                name: "stage_id",
+                required: false,
+                description: "With execution_id: stage of the step, or the stage whose log to return",
+              },
+              {
+                name: "max_chars",
+                required: false,
+                description: "Cap on returned log text (default 200000, max 2000000). Longer logs keep the start and end with a truncation marker",
+              },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "execution_log_tail",
+      displayName: "Execution Log Tail",
+      description: "Incremental log lines of one step, including steps that are still running. Supports get only. Each call returns the lines written since the previous call's cursor, so an agent can watch a build in near real time. For finished executions, execution_log returns the whole log at once.",
+      toolset: "logs",
+      scope: "project",
+      identifierFields: ["execution_id"],
+      searchAliases: ["tail logs", "live logs", "follow logs", "stream logs", "watch build", "running step logs"],
+      relatedResources: [
+        { resourceType: "execution_log", relationship: "sibling", description: "Full log download once the execution has finished." },
+        { resourceType: "execution", relationship: "parent", description: "The execution being tailed; its graph gives step and stage identifiers." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/gateway/log-service/stream",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectExecutionLogTail,
+          responseExtractor: executionLogTailExtract,
+          skipCompact: true,
+          skipCache: true,
+          paramsSchema: {
+            fields: [
+              { name: "step_id", required: false, description: "Step identifier or node id to tail. Default: the pipeline-level log, else the deepest step log" },
+              { name: "stage_id", required: false, description: "Stage identifier, to pick the step within that stage" },
+              { name: "command_unit", required: false, description: "CD steps only: command unit whose log to tail (e.g. Execute)" },
+              { name: "log_key", required: false, description: "Raw log-service key, instead of resolving one from execution_id/step_id" },
+              { name: "cursor", required: false, description: "Cursor from the previous result. Continues after the lines already returned" },
+              { name: "max_lines", required: false, description: `Lines per call (default ${TAIL_DEFAULT_LINES}, max ${TAIL_MAX_LINES})` },
+              { name: "wait_ms", required: false, description: `How long to wait for new lines on a running step (default ${TAIL_DEFAULT_WAIT_MS}, max ${TAIL_MAX_WAIT_MS})` },
             ],
           } satisfies ParamsSchema,
+          description:
+            "Tail a step's log. Returns {log_key, source: live|complete|pending, lines[], line_count, has_more, cursor}. Pass cursor back unchanged to get only newer lines; stop when has_more is false. source=live reads the open log stream, complete reads the finished log, pending means the step has not started writing yet.",
         },
       },
     },
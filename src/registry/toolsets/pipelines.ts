@@ -1,6 +1,83 @@
-import type { ToolsetDefinition, BodySchema, ParamsSchema } from "../types.js";
-import { ngExtract, pageExtract, passthrough, v1ListExtract, runtimeInputExtract, executionInputsExtract, dynamicExecutionExtract } from "../extractors.js";
+import type { ToolsetDefinition, BodySchema, ParamsSchema, PreflightContext } from "../types.js";
+import { ngExtract, pageExtract, passthrough, v1ListExtract, runtimeInputExtract, executionInputsExtract, executionTimelineExtract, executionSummaryExtract, dynamicExecutionExtract, waitingExecutionListExtract, waitingExecutionGetExtract, WAITING_EXECUTION_STATUSES, triggerScheduleExtract, type TriggerScheduleScan, triggerEventExtract, type TriggerEventScan, pendingApprovalListExtract, type PendingApprovalScan, myActionItemsExtract, type MyActionItemsScan, type PostureSection, pipelinePreflightExtract, type PipelinePreflightScan, executionYamlExtract, type ExecutionYamlScan, pipelineHealthExtract, type PipelineHealthScan, ciResourceUsageExtract, type CiResourceUsageScan, pipelineComplianceExtract, type PipelineComplianceScan, pipelineStageContractExtract, pipelineConfigInventoryExtract, findTemplateRefs, type PipelineConfigScan } from "../extractors.js";
 import YAML from "yaml";
+import { coerceRecord, isRecord } from "../../utils/type-guards.js";
+import { abortableSleep } from "../../utils/poll-execution.js";
+import { toEpochMs } from "../../utils/time-fields.js";
+import { fanOut } from "../../utils/fan-out.js";
+
+/** A comma-separated string or an array as trimmed, non-empty strings; undefined when empty. */
+function listInput(value: unknown): string[] | undefined {
+  const parts = Array.isArray(value) ? value.map(String) : typeof value === "string" ? value.split(",") : [];
+  const list = parts.map((p) => p.trim()).filter(Boolean);
+  return list.length > 0 ? list : undefined;
+}
+
+/** Epoch millis from epoch millis/seconds or an ISO 8601 string. */
+function timeInput(value: unknown, name: string): number | undefined {
+  if (value === undefined || value === null || value === "") return undefined;
+  const epoch = toEpochMs(value);
+  if (epoch !== undefined) return epoch;
+  const parsed = typeof value === "string" ? Date.parse(value) : Number.NaN;
+  if (Number.isNaN(parsed)) throw new Error(`${name} must be epoch millis or an ISO 8601 date/time (got ${JSON.stringify(value)})`);
+  return parsed;
+}
+
+/**
+ * Body for the execution list: a PipelineExecutionFilterProperties payload.
+ * params.filter_properties passes a full payload through as-is; the shorthand
+ * filters (statuses, trigger_types, time range, tags, services, environments,
+ * repositories) are set on top of it.
+ */
+export function executionFilterBody(input: Record<string, unknown>): Record<string, unknown> {
+  const raw = coerceRecord(input.filter_properties) ?? {};
+  const body: Record<string, unknown> = { ...raw, filterType: "PipelineExecution" };
+
+  const statuses = listInput(input.statuses);
+  if (statuses) body.status = statuses;
+  if (typeof input.pipeline_name === "string" && input.pipeline_name) body.pipelineName = input.pipeline_name;
+  const triggerTypes = listInput(input.trigger_types);
+  if (triggerTypes) body.triggerTypes = triggerTypes.map((t) => t.toUpperCase());
+  const triggerIds = listInput(input.trigger_ids);
+  if (triggerIds) body.triggerIdentifiers = triggerIds;
+
+  const startTime = timeInput(input.start_time_from, "start_time_from");
+  const endTime = timeInput(input.start_time_to, "start_time_to");
+  if (startTime !== undefined || endTime !== undefined) {
+    body.timeRange = {
+      ...(isRecord(raw.timeRange) ? raw.timeRange : {}),
+      ...(startTime !== undefined ? { startTime } : {}),
+      ...(endTime !== undefined ? { endTime } : {}),
+    };
+  }
+
+  const tags = listInput(input.tags);
+  if (tags) {
+    body.pipelineTags = tags.map((tag) => {
+      const sep = tag.indexOf(":");
+      return sep === -1 ? { key: tag, value: "" } : { key: tag.slice(0, sep).trim(), value: tag.slice(sep + 1).trim() };
+    });
+  }
+
+  const serviceIds = listInput(input.service_ids);
+  const envIds = listInput(input.env_ids);
+  const repoNames = listInput(input.repo_names);
+  if (serviceIds || envIds || repoNames) {
+    const moduleProperties = isRecord(raw.moduleProperties) ? { ...raw.moduleProperties } : {};
+    if (serviceIds || envIds) {
+      moduleProperties.cd = {
+        ...(isRecord(moduleProperties.cd) ? moduleProperties.cd : {}),
+        ...(serviceIds ? { serviceIdentifiers: serviceIds } : {}),
+        ...(envIds ? { envIdentifiers: envIds } : {}),
+      };
+    }
+    if (repoNames) {
+      moduleProperties.ci = { ...(isRecord(moduleProperties.ci) ? moduleProperties.ci : {}), repoNames };
+    }
+    body.moduleProperties = moduleProperties;
+  }
+  return body;
+}
 
 /**
  * Normalize a trigger body into the canonical `{ trigger: { ... } }` shape,
@@ -39,6 +116,687 @@ function normalizeTriggerBody(
   return { trigger: body };
 }
 
+/**
+ * Build the YAML body for submitting execution-time inputs to a waiting step.
+ * Accepts a raw YAML string body, `{ inputs_yaml }`, or `{ inputs: {...} }`
+ * (serialized to YAML).
+ */
+function buildExecutionInputBody(input: Record<string, unknown>): string {
+  const body = input.body;
+  if (typeof body === "string" && body.trim()) return body;
+  if (body && typeof body === "object") {
+    const obj = body as Record<string, unknown>;
+    if (typeof obj.inputs_yaml === "string" && obj.inputs_yaml.trim()) return obj.inputs_yaml;
+    if (obj.inputs && typeof obj.inputs === "object") return YAML.stringify(obj.inputs);
+  }
+  throw new Error(
+    "inputs_yaml is required: the filled-in input template YAML from execution_input_request, passed as body.inputs_yaml, body.inputs (object), or a raw YAML body string",
+  );
+}
+
+/** Upper bound on pipelines trigger_schedule scans in one call. */
+const SCHEDULE_MAX_PIPELINES = 200;
+/** Parallel trigger-list requests while scanning pipelines. */
+const SCHEDULE_CONCURRENCY = 5;
+
+/**
+ * Gather triggers across a project for trigger_schedule. The trigger API only
+ * lists per pipeline, so this pages through pipelines (or uses pipeline_id)
+ * and lists each one's triggers. Scheduled triggers whose list entry lacks
+ * YAML are fetched individually. Per-pipeline failures are reported, not fatal.
+ */
+async function collectScheduledTriggers(ctx: PreflightContext): Promise<TriggerScheduleScan> {
+  const { client, input, registry, signal } = ctx;
+  const scope = {
+    orgIdentifier: (input.org_id as string | undefined) ?? registry.orgId,
+    projectIdentifier: (input.project_id as string | undefined) ?? registry.projectId,
+  };
+
+  let pipelines: Array<{ identifier: string; name?: string }> = [];
+  let truncated = false;
+  if (typeof input.pipeline_id === "string" && input.pipeline_id) {
+    pipelines = [{ identifier: input.pipeline_id }];
+  } else {
+    let available = 0;
+    for (let page = 0; pipelines.length < SCHEDULE_MAX_PIPELINES; page++) {
+      const resp = await client.request<unknown>({
+        method: "POST",
+        path: "/pipeline/api/pipelines/list",
+        params: { ...scope, page, size: 100 },
+        body: { filterType: "PipelineSetup" },
+        signal,
+      });
+      const { items, total } = pageExtract(resp);
+      available = total;
+      for (const item of items as Array<{ identifier?: string; name?: string }>) {
+        if (item.identifier) pipelines.push({ identifier: item.identifier, name: item.name });
+      }
+      if (items.length === 0 || (page + 1) * 100 >= total) break;
+    }
+    truncated = available > SCHEDULE_MAX_PIPELINES;
+    pipelines = pipelines.slice(0, SCHEDULE_MAX_PIPELINES);
+  }
+
+  const scan: TriggerScheduleScan = { pipelines_scanned: pipelines.length, truncated, triggers: [], errors: [] };
+  for (let i = 0; i < pipelines.length; i += SCHEDULE_CONCURRENCY) {
+    await Promise.all(pipelines.slice(i, i + SCHEDULE_CONCURRENCY).map(async (pipeline) => {
+      try {
+        const resp = await client.request<unknown>({
+          method: "GET",
+          path: "/pipeline/api/triggers",
+          params: { ...scope, targetIdentifier: pipeline.identifier, size: 100 },
+          signal,
+        });
+        for (const item of pageExtract(resp).items as Array<Record<string, unknown>>) {
+          if (item.type !== undefined && item.type !== "Scheduled") continue;
+          let trigger = item;
+          if (typeof item.yaml !== "string" && typeof item.identifier === "string") {
+            const detail = await client.request<unknown>({
+              method: "GET",
+              path: `/pipeline/api/triggers/${encodeURIComponent(item.identifier)}`,
+              params: { ...scope, targetIdentifier: pipeline.identifier },
+              signal,
+            });
+            trigger = { ...item, ...(ngExtract(detail) as Record<string, unknown>) };
+          }
+          scan.triggers.push({ pipeline_id: pipeline.identifier, pipeline_name: pipeline.name, trigger });
+        }
+      } catch (err) {
+        scan.errors.push({ pipeline_id: pipeline.identifier, error: err instanceof Error ? err.message : String(err) });
+      }
+    }));
+  }
+  return scan;
+}
+
+/** Trigger event history entries scanned to find the event behind an execution. */
+const TRIGGER_EVENT_SCAN_SIZE = 100;
+
+/**
+ * Gather what trigger_event needs for one execution: the execution's trigger
+ * info, the matching entry in the trigger's event history (which holds the
+ * webhook payload), and the trigger YAML with its conditions. Event history
+ * and trigger lookups are best effort — failures are reported in `errors`.
+ */
+async function collectTriggerEvent(ctx: PreflightContext): Promise<TriggerEventScan> {
+  const { client, input, registry, signal } = ctx;
+  const executionId = input.execution_id as string | undefined;
+  if (!executionId) throw new Error("execution_id is required — the planExecutionId of the triggered run");
+  const scope = {
+    orgIdentifier: (input.org_id as string | undefined) ?? registry.orgId,
+    projectIdentifier: (input.project_id as string | undefined) ?? registry.projectId,
+  };
+
+  const execution = ngExtract(await client.request<unknown>({
+    method: "GET",
+    path: `/pipeline/api/pipelines/execution/v2/${encodeURIComponent(executionId)}`,
+    params: scope,
+    signal,
+  })) as Record<string, unknown> | undefined;
+  const summary = (execution?.pipelineExecutionSummary ?? {}) as Record<string, unknown>;
+  const triggerInfo = (summary.executionTriggerInfo ?? {}) as Record<string, unknown>;
+  const triggeredBy = (triggerInfo.triggeredBy ?? {}) as Record<string, unknown>;
+  const extraInfo = (triggeredBy.extraInfo ?? {}) as Record<string, unknown>;
+  const pipelineId = (summary.pipelineIdentifier as string | undefined) ?? (input.pipeline_id as string | undefined);
+  const triggerId = (extraInfo.triggerRef as string | undefined)?.split("/").pop() ?? (triggeredBy.identifier as string | undefined);
+
+  const scan: TriggerEventScan = {
+    execution_id: executionId,
+    pipeline_id: pipelineId,
+    trigger_type: triggerInfo.triggerType as string | undefined,
+    trigger_id: triggerId,
+    event_correlation_id: extraInfo.eventCorrelationId as string | undefined,
+    errors: [],
+  };
+  if (scan.trigger_type !== "WEBHOOK" && scan.trigger_type !== "WEBHOOK_CUSTOM") return scan;
+  if (!pipelineId || !triggerId) {
+    scan.errors.push("The execution does not record which trigger started it.");
+    return scan;
+  }
+
+  await Promise.all([
+    (async () => {
+      try {
+        const resp = await client.request<unknown>({
+          method: "GET",
+          path: `/pipeline/api/triggers/eventHistory/${encodeURIComponent(pipelineId)}`,
+          params: { ...scope, triggerIdentifier: triggerId, page: 0, size: TRIGGER_EVENT_SCAN_SIZE },
+          signal,
+        });
+        scan.event = (pageExtract(resp).items as Array<Record<string, unknown>>).find((event) => {
+          const target = (event.targetExecutionSummary ?? {}) as Record<string, unknown>;
+          return target.planExecutionId === executionId
+            || (scan.event_correlation_id !== undefined && event.eventCorrelationId === scan.event_correlation_id);
+        });
+        if (!scan.event) {
+          scan.errors.push(`No event for this execution in the last ${TRIGGER_EVENT_SCAN_SIZE} events of trigger ${triggerId}.`);
+        }
+      } catch (err) {
+        scan.errors.push(`Trigger event history: ${err instanceof Error ? err.message : String(err)}`);
+      }
+    })(),
+    (async () => {
+      try {
+        const trigger = ngExtract(await client.request<unknown>({
+          method: "GET",
+          path: `/pipeline/api/triggers/${encodeURIComponent(triggerId)}`,
+          params: { ...scope, targetIdentifier: pipelineId },
+          signal,
+        })) as Record<string, unknown> | undefined;
+        if (typeof trigger?.yaml === "string") scan.trigger_yaml = trigger.yaml;
+      } catch (err) {
+        scan.errors.push(`Trigger ${triggerId}: ${err instanceof Error ? err.message : String(err)}`);
+      }
+    })(),
+  ]);
+  return scan;
+}
+
+/** Upper bound on ApprovalWaiting executions pending_approval inspects in one call. */
+const PENDING_APPROVAL_MAX_EXECUTIONS = 50;
+/** Parallel approval-list requests while scanning executions. */
+const PENDING_APPROVAL_CONCURRENCY = 5;
+
+/**
+ * Gather waiting approvals across a project for pending_approval. Approvals
+ * are only listed per execution, so this finds executions in ApprovalWaiting
+ * (optionally for one pipeline) and lists each one's WAITING approvals.
+ * Per-execution failures are reported, not fatal.
+ */
+async function collectPendingApprovals(ctx: PreflightContext): Promise<PendingApprovalScan> {
+  const { client, input, registry, signal } = ctx;
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  if (!org || !project) throw new Error("org_id and project_id are required to list pending approvals");
+
+  const resp = await client.request<unknown>({
+    method: "POST",
+    path: "/pipeline/api/pipelines/execution/summary",
+    params: {
+      orgIdentifier: org,
+      projectIdentifier: project,
+      page: 0,
+      size: PENDING_APPROVAL_MAX_EXECUTIONS,
+      ...(typeof input.pipeline_id === "string" && input.pipeline_id ? { pipelineIdentifier: input.pipeline_id } : {}),
+    },
+    body: { filterType: "PipelineExecution", status: ["ApprovalWaiting"] },
+    signal,
+  });
+  const { items, total } = pageExtract(resp);
+  const executions = (items as Array<Record<string, unknown>>).filter((e) => typeof e.planExecutionId === "string");
+
+  const scan: PendingApprovalScan = {
+    executions_scanned: executions.length,
+    truncated: total > executions.length,
+    approvals: [],
+    errors: [],
+  };
+  const approvalType = typeof input.approval_type === "string" && input.approval_type ? input.approval_type : undefined;
+  for (let i = 0; i < executions.length; i += PENDING_APPROVAL_CONCURRENCY) {
+    await Promise.all(executions.slice(i, i + PENDING_APPROVAL_CONCURRENCY).map(async (execution) => {
+      const executionId = execution.planExecutionId as string;
+      try {
+        const approvals = await client.request<unknown>({
+          method: "GET",
+          path: `/pipeline/api/v1/orgs/${encodeURIComponent(org)}/projects/${encodeURIComponent(project)}/approvals/execution/${encodeURIComponent(executionId)}`,
+          params: { approval_status: "WAITING", ...(approvalType ? { approval_type: approvalType } : {}) },
+          signal,
+        });
+        for (const approval of v1ListExtract()(approvals).items as Array<Record<string, unknown>>) {
+          scan.approvals.push({ execution, approval });
+        }
+      } catch (err) {
+        scan.errors.push({ execution_id: executionId, error: err instanceof Error ? err.message : String(err) });
+      }
+    }));
+  }
+  return scan;
+}
+
+/** Upper bound on repositories my_action_item checks for review requests. */
+const MY_ACTION_ITEMS_MAX_REPOS = 20;
+
+/**
+ * References a user group can appear under in an approval's approver list:
+ * account.<id> and org.<id> for groups above the project, the bare identifier
+ * for project groups.
+ */
+function userGroupRef(group: unknown): string | undefined {
+  if (!isRecord(group) || typeof group.identifier !== "string" || !group.identifier) return undefined;
+  if (!group.orgIdentifier) return `account.${group.identifier}`;
+  if (!group.projectIdentifier) return `org.${group.identifier}`;
+  return group.identifier;
+}
+
+/**
+ * Gather the current user's action items for my_action_item: approvals
+ * assigned to their user groups, open pull requests waiting on their review
+ * (in repo_ids), and executions waiting on runtime input. Like
+ * service_security_posture, each source is read through its own resource
+ * type, so a disabled toolset or a failing source only marks its section.
+ */
+async function collectMyActionItems(ctx: PreflightContext): Promise<MyActionItemsScan> {
+  const { client, input, registry, signal } = ctx;
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  if (!org || !project) throw new Error("org_id and project_id are required to list action items");
+  const scope = { org_id: org, project_id: project };
+  const pipeline = typeof input.pipeline_id === "string" && input.pipeline_id ? { pipeline_id: input.pipeline_id } : {};
+
+  async function read(resourceType: string, operation: string, params: Record<string, unknown>): Promise<unknown> {
+    return registry.dispatch(client, resourceType, operation, { ...scope, ...params }, signal);
+  }
+
+  async function section<T>(resourceType: string, load: () => Promise<T>): Promise<PostureSection<T>> {
+    let toolset: string;
+    try {
+      toolset = registry.getResource(resourceType).toolset;
+    } catch {
+      return { status: "unavailable", reason: `${resourceType} is not enabled — add its toolset to HARNESS_TOOLSETS.` };
+    }
+    try {
+      return { status: "ok", data: await load() };
+    } catch (err) {
+      return { status: "error", reason: `${toolset}: ${err instanceof Error ? err.message : String(err)}` };
+    }
+  }
+
+  const userId = await client.getCurrentUserId();
+  const repoIds = typeof input.repo_ids === "string"
+    ? input.repo_ids.split(",").map((r) => r.trim()).filter(Boolean).slice(0, MY_ACTION_ITEMS_MAX_REPOS)
+    : [];
+
+  const [approvals, prReviews, waitingInputs] = await Promise.all([
+    section("pending_approval", async () => {
+      const list = await read("pending_approval", "list", pipeline);
+      // Without the user's groups every approval is listed, flagged in the result.
+      let userGroups: string[] | null = null;
+      try {
+        const resp = await client.request<unknown>({
+          method: "POST",
+          path: "/ng/api/user-groups/batch",
+          body: {
+            accountIdentifier: client.account,
+            orgIdentifier: org,
+            projectIdentifier: project,
+            userIdentifierFilter: [userId],
+            filterType: "INCLUDE_INHERITED_GROUPS",
+          },
+          signal,
+        });
+        const groups = isRecord(resp) && Array.isArray(resp.data) ? resp.data : [];
+        userGroups = groups.map(userGroupRef).filter((g): g is string => g !== undefined);
+      } catch {
+        userGroups = null;
+      }
+      return { list, user_groups: userGroups };
+    }),
+    repoIds.length > 0
+      ? section("pull_request", async () => {
+        const codeUser = await client.request<unknown>({ method: "GET", path: "/code/api/v1/user", signal });
+        const reviewerId = isRecord(codeUser) ? codeUser.id : undefined;
+        if (typeof reviewerId !== "number" && typeof reviewerId !== "string") {
+          throw new Error("Could not resolve your Harness Code user via /code/api/v1/user.");
+        }
+        return Promise.all(repoIds.map(async (repoId) => {
+          try {
+            const pullRequests = await read("pull_request", "list", {
+              repo_id: repoId,
+              state: "open",
+              reviewer_id: reviewerId,
+              review_decision: "pending",
+              limit: 50,
+            });
+            return { repo_id: repoId, pull_requests: pullRequests };
+          } catch (err) {
+            return { repo_id: repoId, error: err instanceof Error ? err.message : String(err) };
+          }
+        }));
+      })
+      : Promise.resolve<MyActionItemsScan["pr_reviews"]>({
+        status: "not_configured",
+        reason: "Pass repo_ids (comma-separated Harness Code repositories) to include pull requests waiting on your review.",
+      }),
+    section("waiting_execution", () => read("waiting_execution", "list", { ...pipeline, status: "InputWaiting", size: 50 })),
+  ]);
+
+  return { user_id: userId, approvals, pr_reviews: prReviews, waiting_inputs: waitingInputs };
+}
+
+const PREFLIGHT_POLL_INTERVAL_MS = 2000;
+const PREFLIGHT_DEFAULT_WAIT_MS = 60_000;
+const PREFLIGHT_MAX_WAIT_MS = 180_000;
+/** Preflight statuses that mean the check has not finished. */
+const PREFLIGHT_PENDING = new Set(["NOT_STARTED", "IN_PROGRESS"]);
+
+/**
+ * Run the pipeline service's preflight check for pipeline.preflight: start a
+ * check with the runtime inputs, then poll its result until it finishes or
+ * wait_ms runs out. With preflight_check_id, only reads that check's result.
+ */
+async function collectPipelinePreflight(ctx: PreflightContext): Promise<PipelinePreflightScan> {
+  const { client, input, registry, signal } = ctx;
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  if (!org || !project) throw new Error("org_id and project_id are required for a preflight check");
+  const scope = { orgIdentifier: org, projectIdentifier: project };
+  const pipelineId = typeof input.pipeline_id === "string" && input.pipeline_id ? input.pipeline_id : undefined;
+
+  let checkId = typeof input.preflight_check_id === "string" && input.preflight_check_id ? input.preflight_check_id : undefined;
+  if (!checkId) {
+    if (!pipelineId) throw new Error("pipeline_id is required to start a preflight check (or pass params.preflight_check_id).");
+    const branch = input.pipeline_branch ?? input.branch;
+    const inputs = input.inputs;
+    const started = await client.request<{ data?: unknown }>({
+      method: "POST",
+      path: "/pipeline/api/pipeline/execute/preflightCheck",
+      params: {
+        ...scope,
+        pipelineIdentifier: pipelineId,
+        ...(typeof branch === "string" && branch ? { branch } : {}),
+        ...(typeof input.repo_name === "string" && input.repo_name ? { repoName: input.repo_name } : {}),
+        ...(typeof input.connector_ref === "string" && input.connector_ref ? { connectorRef: input.connector_ref } : {}),
+      },
+      headers: { "Content-Type": "application/yaml" },
+      // Same body as pipeline.run: runtime input YAML, or "" for none.
+      body: !inputs ? "" : typeof inputs === "string" ? inputs : JSON.stringify(inputs),
+      signal,
+    });
+    if (typeof started?.data !== "string" || !started.data) {
+      throw new Error("Preflight check did not return a preflight_check_id.");
+    }
+    checkId = started.data;
+  }
+
+  const waitMs = Math.min(Math.max(Number(input.wait_ms ?? PREFLIGHT_DEFAULT_WAIT_MS) || 0, 0), PREFLIGHT_MAX_WAIT_MS);
+  const deadline = Date.now() + waitMs;
+  for (;;) {
+    const resp = await client.request<{ data?: unknown }>({
+      method: "GET",
+      path: "/pipeline/api/pipeline/execute/getPreflightCheckResponse",
+      params: { ...scope, preflightCheckId: checkId },
+      signal,
+    });
+    const result = isRecord(resp?.data) ? resp.data : {};
+    const status = String(result.status ?? "");
+    if (!PREFLIGHT_PENDING.has(status) || Date.now() + PREFLIGHT_POLL_INTERVAL_MS > deadline) {
+      return { preflight_check_id: checkId, ...(pipelineId ? { pipeline_id: pipelineId } : {}), result };
+    }
+    await abortableSleep(PREFLIGHT_POLL_INTERVAL_MS, signal);
+  }
+}
+
+/**
+ * Gather what execution_yaml needs: the compiled YAML the pipeline service
+ * stored when the execution started, and (unless resolve_expressions=false)
+ * the merged runtime inputs with expressions resolved. The inputs lookup is
+ * best effort — a failure is reported in `errors`.
+ */
+async function collectExecutionYaml(ctx: PreflightContext): Promise<ExecutionYamlScan> {
+  const { client, input, registry, signal } = ctx;
+  const executionId = input.execution_id as string | undefined;
+  if (!executionId) throw new Error("execution_id is required — the planExecutionId of the run");
+  const scope = {
+    orgIdentifier: (input.org_id as string | undefined) ?? registry.orgId,
+    projectIdentifier: (input.project_id as string | undefined) ?? registry.projectId,
+  };
+  const path = `/pipeline/api/pipelines/execution/${encodeURIComponent(executionId)}`;
+
+  const metadata = ngExtract(await client.request<unknown>({ method: "GET", path: `${path}/metadata`, params: scope, signal }));
+  const scan: ExecutionYamlScan = { execution_id: executionId, metadata: isRecord(metadata) ? metadata : {}, errors: [] };
+  if (input.resolve_expressions === false || input.resolve_expressions === "false") return scan;
+  try {
+    const inputs = await client.request<unknown>({
+      method: "GET",
+      path: `${path}/inputsetV2`,
+      params: { ...scope, resolveExpressions: true, resolveExpressionsType: "RESOLVE_ALL_EXPRESSIONS" },
+      signal,
+    });
+    if (isRecord(inputs)) scan.inputs = inputs;
+  } catch (err) {
+    scan.errors.push(`inputs: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  return scan;
+}
+
+/** Most templates pipeline_config_inventory fetches, counting nested ones. */
+const CONFIG_TEMPLATE_MAX = 25;
+const CONFIG_TEMPLATE_CONCURRENCY = 4;
+
+/**
+ * Fetch a pipeline's YAML and the YAML of every template it uses for
+ * pipeline_config_inventory, following templates that use other templates
+ * one level at a time. A template that fails to load is recorded and the
+ * scan continues.
+ */
+async function collectPipelineConfigInventory(ctx: PreflightContext): Promise<PipelineConfigScan> {
+  const { client, input, registry, signal } = ctx;
+  const pipelineId = typeof input.pipeline_id === "string" && input.pipeline_id ? input.pipeline_id : undefined;
+  if (!pipelineId) throw new Error("pipeline_id is required — the pipeline to scan");
+  const orgId = (input.org_id as string | undefined) ?? registry.orgId;
+  const projectId = (input.project_id as string | undefined) ?? registry.projectId;
+
+  const pipeline = await registry.dispatch(client, "pipeline", "get", {
+    pipeline_id: pipelineId,
+    ...(orgId ? { org_id: orgId } : {}),
+    ...(projectId ? { project_id: projectId } : {}),
+    ...(input.branch ? { branch: input.branch } : {}),
+  }, signal) as Record<string, unknown> | undefined;
+  const scan: PipelineConfigScan = { pipeline_id: pipelineId, pipeline_yaml: pipeline?.yamlPipeline, templates: [] };
+  if (input.include_templates === false || input.include_templates === "false") return scan;
+
+  const seen = new Set<string>();
+  let pending = findTemplateRefs(scan.pipeline_yaml);
+  while (pending.length > 0) {
+    const batch = pending.filter((t) => {
+      const key = `${t.ref}@${t.version ?? ""}`;
+      if (seen.has(key)) return false;
+      seen.add(key);
+      return true;
+    });
+    const room = CONFIG_TEMPLATE_MAX - scan.templates.length;
+    if (batch.length > room) scan.truncated = true;
+    const { results, errors } = await fanOut(
+      batch.slice(0, room),
+      (t, sig) => fetchTemplate(client, t.ref, { orgId, projectId, versionLabel: t.version }, sig),
+      { concurrency: CONFIG_TEMPLATE_CONCURRENCY, signal },
+    );
+    pending = [];
+    for (const { item, value } of results) {
+      scan.templates.push({ ...item, yaml: value.yaml });
+      pending.push(...findTemplateRefs(value.yaml));
+    }
+    for (const { item, error } of errors) scan.templates.push({ ...item, error });
+    if (scan.truncated) break;
+  }
+  return scan;
+}
+
+/**
+ * Fetch a template by ref. Refs take Harness's `account.` / `org.` prefixes
+ * for templates above project level; without a version label the stable
+ * version is returned.
+ */
+async function fetchTemplate(
+  client: PreflightContext["client"],
+  ref: string,
+  scope: { orgId?: string; projectId?: string; versionLabel?: string },
+  signal?: AbortSignal,
+): Promise<Record<string, unknown>> {
+  const [, level, templateId] = /^(?:(account|org)\.)?(.+)$/.exec(ref)!;
+  const params: Record<string, unknown> = {};
+  if (level !== "account" && scope.orgId) params.orgIdentifier = scope.orgId;
+  if (!level && scope.projectId) params.projectIdentifier = scope.projectId;
+  if (scope.versionLabel) params.versionLabel = scope.versionLabel;
+  const template = ngExtract(await client.request<unknown>({
+    method: "GET",
+    path: `/template/api/templates/${encodeURIComponent(templateId!)}`,
+    params,
+    signal,
+  }));
+  return isRecord(template) ? template : {};
+}
+
+/**
+ * Fetch the YAML of a pipeline and of its golden reference for
+ * pipeline_compliance. The golden is another pipeline (optionally in another
+ * org/project) or a pipeline template; template refs take Harness's
+ * `account.` / `org.` prefixes for templates above project level.
+ */
+async function collectPipelineCompliance(ctx: PreflightContext): Promise<PipelineComplianceScan> {
+  const { client, input, registry, signal } = ctx;
+  const pipelineId = typeof input.pipeline_id === "string" && input.pipeline_id ? input.pipeline_id : undefined;
+  if (!pipelineId) throw new Error("pipeline_id is required — the pipeline to check");
+  const goldenPipeline = typeof input.golden_pipeline_id === "string" && input.golden_pipeline_id ? input.golden_pipeline_id : undefined;
+  const goldenTemplate = typeof input.golden_template_id === "string" && input.golden_template_id ? input.golden_template_id : undefined;
+  if (!goldenPipeline === !goldenTemplate) {
+    throw new Error("Pass exactly one of golden_pipeline_id or golden_template_id — the org-standard pipeline or pipeline template to compare against");
+  }
+  const orgId = (input.org_id as string | undefined) ?? registry.orgId;
+  const projectId = (input.project_id as string | undefined) ?? registry.projectId;
+
+  const pipeline = await registry.dispatch(client, "pipeline", "get", {
+    pipeline_id: pipelineId,
+    ...(orgId ? { org_id: orgId } : {}),
+    ...(projectId ? { project_id: projectId } : {}),
+    ...(input.branch ? { branch: input.branch } : {}),
+  }, signal) as Record<string, unknown> | undefined;
+
+  if (goldenPipeline) {
+    const goldenOrg = (input.golden_org_id as string | undefined) ?? orgId;
+    const goldenProject = (input.golden_project_id as string | undefined) ?? projectId;
+    const golden = await registry.dispatch(client, "pipeline", "get", {
+      pipeline_id: goldenPipeline,
+      ...(goldenOrg ? { org_id: goldenOrg } : {}),
+      ...(goldenProject ? { project_id: goldenProject } : {}),
+    }, signal) as Record<string, unknown> | undefined;
+    return {
+      pipeline_id: pipelineId,
+      pipeline_yaml: pipeline?.yamlPipeline,
+      golden: { kind: "pipeline", id: goldenPipeline },
+      golden_yaml: golden?.yamlPipeline,
+    };
+  }
+
+  const record = await fetchTemplate(client, goldenTemplate!, {
+    orgId: orgId ? (input.golden_org_id as string | undefined) ?? orgId : undefined,
+    projectId: projectId ? (input.golden_project_id as string | undefined) ?? projectId : undefined,
+    versionLabel: typeof input.version_label === "string" && input.version_label ? input.version_label : undefined,
+  }, signal);
+  return {
+    pipeline_id: pipelineId,
+    pipeline_yaml: pipeline?.yamlPipeline,
+    golden: {
+      kind: "template",
+      id: goldenTemplate!,
+      ...(typeof record.versionLabel === "string" ? { version: record.versionLabel } : {}),
+    },
+    golden_yaml: record.yaml,
+  };
+}
+
+/** Executions read per page, and the most pipeline_health aggregates in one call. */
+const PIPELINE_HEALTH_PAGE_SIZE = 100;
+const PIPELINE_HEALTH_MAX_EXECUTIONS = 500;
+const PIPELINE_HEALTH_DEFAULT_DAYS = 30;
+const PIPELINE_HEALTH_MAX_DAYS = 365;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/**
+ * Page through a pipeline's executions started in the last window_days for
+ * pipeline_health, newest first, stopping at PIPELINE_HEALTH_MAX_EXECUTIONS.
+ */
+async function collectPipelineHealth(ctx: PreflightContext): Promise<PipelineHealthScan> {
+  const { client, input, registry, signal } = ctx;
+  const pipelineId = typeof input.pipeline_id === "string" && input.pipeline_id ? input.pipeline_id : undefined;
+  if (!pipelineId) throw new Error("pipeline_id is required — the pipeline identifier to summarize");
+  const days = input.window_days === undefined || input.window_days === "" ? PIPELINE_HEALTH_DEFAULT_DAYS : Number(input.window_days);
+  if (!Number.isInteger(days) || days < 1 || days > PIPELINE_HEALTH_MAX_DAYS) {
+    throw new Error(`window_days must be a whole number from 1 to ${PIPELINE_HEALTH_MAX_DAYS} (got ${JSON.stringify(input.window_days)})`);
+  }
+  const bucket = input.bucket ?? (days <= 14 ? "day" : "week");
+  if (bucket !== "day" && bucket !== "week") throw new Error(`bucket must be "day" or "week" (got ${JSON.stringify(input.bucket)})`);
+
+  const end = Date.now();
+  const start = end - days * DAY_MS;
+  const branch = typeof input.branch === "string" && input.branch ? input.branch : undefined;
+  const executions: Array<Record<string, unknown>> = [];
+  let total = 0;
+  for (let page = 0; executions.length < PIPELINE_HEALTH_MAX_EXECUTIONS; page++) {
+    const resp = await client.request<unknown>({
+      method: "POST",
+      path: "/pipeline/api/pipelines/execution/summary",
+      params: {
+        orgIdentifier: (input.org_id as string | undefined) ?? registry.orgId,
+        projectIdentifier: (input.project_id as string | undefined) ?? registry.projectId,
+        pipelineIdentifier: pipelineId,
+        page,
+        size: PIPELINE_HEALTH_PAGE_SIZE,
+        ...(branch ? { branch } : {}),
+      },
+      body: { filterType: "PipelineExecution", timeRange: { startTime: start, endTime: end } },
+      signal,
+    });
+    const result = pageExtract(resp);
+    total = result.total;
+    executions.push(...(result.items as unknown[]).filter(isRecord));
+    if (result.items.length < PIPELINE_HEALTH_PAGE_SIZE || executions.length >= total) break;
+  }
+  return {
+    pipeline_id: pipelineId,
+    window: { start, end, days },
+    bucket,
+    executions: executions.slice(0, PIPELINE_HEALTH_MAX_EXECUTIONS),
+    total,
+  };
+}
+
+/**
+ * Gather what ci_resource_usage needs: the execution graph (CI stages, steps,
+ * configured resource limits, cache steps) and the CI build metadata with
+ * measured CPU, memory, and cache statistics. The metadata lookup is best
+ * effort — without it the result still shows configured limits and timings.
+ */
+async function collectCiResourceUsage(ctx: PreflightContext): Promise<CiResourceUsageScan> {
+  const { client, input, registry, signal } = ctx;
+  const executionId = input.execution_id as string | undefined;
+  if (!executionId) throw new Error("execution_id is required — the planExecutionId of the CI build");
+  const scope = {
+    orgIdentifier: (input.org_id as string | undefined) ?? registry.orgId,
+    projectIdentifier: (input.project_id as string | undefined) ?? registry.projectId,
+  };
+  const execution = await client.request<unknown>({
+    method: "GET",
+    path: `/pipeline/api/pipelines/execution/v2/${encodeURIComponent(executionId)}`,
+    params: { ...scope, renderFullBottomGraph: true },
+    signal,
+  });
+  const scan: CiResourceUsageScan = { execution_id: executionId, execution, errors: [] };
+  try {
+    scan.usage = await client.request<unknown>({
+      method: "GET",
+      path: "/ci/execution/resource-usage",
+      params: { ...scope, planExecutionId: executionId },
+      signal,
+    });
+  } catch (err) {
+    scan.errors.push(`resource usage: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  return scan;
+}
+
+/**
+ * Approver inputs for the approval activity body: accepts the API's
+ * `[{name, value}]` list or a `{name: value}` map.
+ */
+function normalizeApproverInputs(raw: unknown): Array<{ name: string; value: unknown }> | undefined {
+  if (Array.isArray(raw)) return raw as Array<{ name: string; value: unknown }>;
+  if (raw && typeof raw === "object") {
+    return Object.entries(raw as Record<string, unknown>).map(([name, value]) => ({ name, value }));
+  }
+  return undefined;
+}
+
 // ---------------------------------------------------------------------------
 // V1 Pipeline body schemas and helpers
 // ---------------------------------------------------------------------------
@@ -193,6 +951,7 @@ export const pipelinesToolset: ToolsetDefinition = {
         { name: "filter_type", description: "Filter type qualifier" },
       ],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/pipelines/{pipelineIdentifier}/pipeline-studio",
+      restoreBody: (prior) => (isRecord(prior) && typeof prior.yamlPipeline === "string" ? { yamlPipeline: prior.yamlPipeline } : undefined),
       operations: {
         list: {
           method: "POST",
@@ -350,7 +1109,7 @@ export const pipelinesToolset: ToolsetDefinition = {
               skipIfPresent: "build",
             },
           ],
-          actionDescription: "Execute/run a pipeline. RECOMMENDED: first check harness_get(resource_type='runtime_input_template', resource_id='PIPELINE_ID') to see required inputs. For simple variable inputs: pass key-value pairs in inputs (e.g. {branch: 'main'}) — auto-resolved. For CI pipelines with codebase: pass {branch: 'main'}, {tag: 'v1.0'}, {pr_number: '42'}, or {commit_sha: 'abc123'} — auto-expanded to the full build structure. For complex pipelines with template inputs: use input_set_ids to reference a saved input set. List available sets with harness_list(resource_type='input_set', filters={pipeline_id: '...'}). To load the pipeline YAML from a specific git branch (e.g. a feature branch): pass params={pipeline_branch: 'feature/my-fix'} — sent as ?pipelineBranchName= on the API call.",
+          actionDescription: "Execute/run a pipeline. RECOMMENDED: first check harness_get(resource_type='runtime_input_template', resource_id='PIPELINE_ID') to see required inputs. For simple variable inputs: pass key-value pairs in inputs (e.g. {branch: 'main'}) — auto-resolved. For CI pipelines with codebase: pass {branch: 'main'}, {tag: 'v1.0'}, {pr_number: '42'}, or {commit_sha: 'abc123'} — auto-expanded to the full build structure. For complex pipelines with template inputs: use input_set_ids to reference a saved input set. List available sets with harness_list(resource_type='input_set', filters={pipeline_id: '...'}). To load the pipeline YAML from a specific git branch (e.g. a feature branch): pass params={pipeline_branch: 'feature/my-fix'} — sent as ?pipelineBranchName= on the API call. Returns execution_id (the planExecutionId) and execution_url, a deep link to the new execution.",
           bodySchema: {
             description: "Runtime inputs for pipeline execution. For simple variables: pass key-value pairs in inputs like {branch: 'main', env: 'prod'}, auto-resolved against the pipeline's runtime input template. CI codebase shorthands (branch, tag, pr_number, commit_sha) are auto-expanded to full build structures. For complex pipelines with template inputs, use input_set_ids to reference saved input sets. You can combine both: input_set_ids for the base config + inputs for simple overrides. Check runtime_input_template first to see what the pipeline expects.",
             fields: [
@@ -360,6 +1119,25 @@ export const pipelinesToolset: ToolsetDefinition = {
             ],
           },
         },
+        preflight: {
+          method: "POST",
+          path: "/pipeline/api/pipeline/execute/preflightCheck",
+          operationPolicy: { risk: "read", retryPolicy: "do_not_retry" },
+          collect: collectPipelinePreflight,
+          responseExtractor: pipelinePreflightExtract,
+          skipCompact: true,
+          actionDescription: "Run Harness preflight checks for a pipeline before running it: connector connectivity for every connector the pipeline uses, and validity of the runtime inputs. Takes the same inputs, input_set_ids, and pipeline_branch as run, but starts nothing. Waits up to params.wait_ms (default 60000) and returns status (SUCCESS, FAILURE, or IN_PROGRESS), passed, per-check counts, and failures[] with stage, step, connector_id, summary, causes, and resolution. If still IN_PROGRESS, call again with params.preflight_check_id. Secret values and manifests are not resolved until the run itself.",
+          bodySchema: {
+            description: "Runtime inputs to check, as for pipeline run. Omit for pipelines without runtime inputs.",
+            fields: [
+              { name: "inputs", type: "yaml", required: false, description: "Key-value pairs (e.g. {branch: 'main'}) auto-resolved to full YAML, or full runtime input YAML." },
+              { name: "input_set_ids", type: "array", required: false, description: "Input set identifiers to apply." },
+              { name: "pipeline_branch", type: "string", required: false, description: "Git branch to load the pipeline YAML from." },
+              { name: "preflight_check_id", type: "string", required: false, description: "ID from an earlier preflight result that was still IN_PROGRESS. Reads that check instead of starting a new one." },
+              { name: "wait_ms", type: "number", required: false, description: `How long to wait for the check to finish (default ${PREFLIGHT_DEFAULT_WAIT_MS}, max ${PREFLIGHT_MAX_WAIT_MS}).` },
+            ],
+          },
+        },
         retry: {
           method: "PUT",
           path: "/pipeline/api/pipeline/execute/retry/{planExecutionId}",
@@ -578,7 +1356,7 @@ export const pipelinesToolset: ToolsetDefinition = {
     {
       resourceType: "execution",
       displayName: "Pipeline Execution",
-      description: "Pipeline execution history and details. Supports list and get.",
+      description: "Pipeline execution history and details. Supports list and get. get returns a curated summary by default; pass params={detail_level: 'full'} for the raw payload.",
       toolset: "pipelines",
       scope: "project",
       identifierFields: ["execution_id"],
@@ -589,6 +1367,11 @@ export const pipelinesToolset: ToolsetDefinition = {
           relationship: "produced-from",
           description: "The merged input set YAML that produced this execution. Use harness_get(resource_type='execution_inputs', resource_id=<planExecutionId>) to see what runtime inputs the run actually used (post-run forensics).",
         },
+        {
+          resourceType: "execution_timeline",
+          relationship: "rendered-as",
+          description: "Gantt-ready stage/step timeline for this execution. Use harness_get(resource_type='execution_timeline', resource_id=<planExecutionId>, params={format: 'mermaid'}) for retros and inline timeline charts.",
+        },
       ],
       listFilterFields: [
         { name: "search_term", description: "Filter executions by name or keyword" },
@@ -597,6 +1380,18 @@ export const pipelinesToolset: ToolsetDefinition = {
         { name: "branch", description: "Branch to filter executions" },
         { name: "my_deployments", description: "Show only my deployments", type: "boolean" },
         { name: "module", description: "Harness module filter", enum: ["CD", "CI", "CV", "CF", "CE", "STO"] },
+        { name: "statuses", description: "Comma-separated statuses, matched as any-of (e.g. 'Failed,Aborted,Expired'). Use instead of status to match more than one." },
+        { name: "pipeline_name", description: "Filter by pipeline name" },
+        { name: "trigger_types", description: "Comma-separated trigger types: MANUAL, WEBHOOK, WEBHOOK_CUSTOM, SCHEDULER_CRON, ARTIFACT, MANIFEST" },
+        { name: "trigger_ids", description: "Comma-separated trigger identifiers" },
+        { name: "start_time_from", description: "Only executions started at or after this time (epoch millis or ISO 8601)" },
+        { name: "start_time_to", description: "Only executions started before this time (epoch millis or ISO 8601)" },
+        { name: "tags", description: "Comma-separated pipeline tags as key:value or key (e.g. 'team:payments,critical')" },
+        { name: "service_ids", description: "Comma-separated service identifiers deployed by the execution (CD)" },
+        { name: "env_ids", description: "Comma-separated environment identifiers deployed to (CD)" },
+        { name: "repo_names", description: "Comma-separated repository names built by the execution (CI)" },
+        { name: "filter_id", description: "Identifier of a saved execution filter to apply" },
+        { name: "filter_properties", description: "Full PipelineExecutionFilterProperties payload (object or JSON string), sent as the request body. Shorthand filters above are applied on top of it." },
       ],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/pipelines/{pipelineIdentifier}/deployments/{planExecutionId}/pipeline",
       operations: {
@@ -611,14 +1406,13 @@ export const pipelinesToolset: ToolsetDefinition = {
             branch: "branch",
             my_deployments: "myDeployments",
             module: "module",
+            filter_id: "filterIdentifier",
             page: "page",
             size: "size",
           },
-          bodyBuilder: () => ({
-            filterType: "PipelineExecution",
-          }),
+          bodyBuilder: executionFilterBody,
           responseExtractor: pageExtract,
-          description: "List pipeline execution history",
+          description: "List pipeline execution history. Simple filters go as query params; statuses, trigger types, a start-time range, tags, services, environments, repositories, or a full filter_properties payload go in the filter body.",
         },
         get: {
           method: "GET",
@@ -626,8 +1420,16 @@ export const pipelinesToolset: ToolsetDefinition = {
           operationPolicy: { risk: "read", retryPolicy: "safe" },
           pathParams: { execution_id: "planExecutionId" },
           queryParams: { render_full_graph: "renderFullBottomGraph" },
-          responseExtractor: ngExtract,
-          description: "Get execution details including stage/step status",
+          responseExtractor: executionSummaryExtract,
+          description:
+            "Get an execution summary: status, run sequence, timing, trigger, per-stage status and duration, and failure details (message, failed stages, failed steps). Pass params={detail_level: 'full'} for the raw pipeline-service payload (layoutNodeMap, executionGraph, moduleInfo). Pass params={format: 'mermaid'} to add a Mermaid flowchart of the stage graph (with steps when render_full_graph=true) in the mermaid field.",
+          paramsSchema: {
+            fields: [
+              { name: "detail_level", required: false, description: "'summary' (default) for the curated view, 'full' for the raw execution payload" },
+              { name: "render_full_graph", required: false, description: "Include the full step graph (adds failed steps from every stage to the summary)" },
+              { name: "format", required: false, description: "'mermaid' to add a ready-to-render Mermaid flowchart of the execution graph alongside the JSON" },
+            ],
+          } satisfies ParamsSchema,
         },
       },
       executeActions: {
@@ -694,6 +1496,255 @@ export const pipelinesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "execution_yaml",
+      displayName: "Resolved Execution YAML",
+      description:
+        "The fully resolved pipeline YAML an execution actually ran — templates expanded and runtime inputs merged — plus the merged inputs with expressions resolved. Supports get only. Use for config-impact analysis: what changed between two runs, or what a template resolved to at the time.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["execution_id"],
+      searchAliases: ["compiled yaml", "resolved yaml", "resolved pipeline yaml", "execution yaml", "what actually ran"],
+      relatedResources: [
+        {
+          resourceType: "execution",
+          relationship: "produced-by",
+          description: "The pipeline execution this YAML ran as. Use harness_get(resource_type='execution', resource_id=<planExecutionId>) for status and failure details.",
+        },
+        {
+          resourceType: "execution_inputs",
+          relationship: "merged-from",
+          description: "The merged runtime inputs alone, with the input set template and contributing input sets.",
+        },
+        {
+          resourceType: "pipeline",
+          relationship: "compiled-from",
+          description: "The pipeline as saved now, which may have changed since this run. Diff against compiled_yaml to see drift.",
+        },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/execution/{planExecutionId}/metadata",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { execution_id: "planExecutionId" },
+          collect: collectExecutionYaml,
+          responseExtractor: executionYamlExtract,
+          skipCompact: true,
+          description:
+            "Get the YAML an execution ran. Returns compiled_yaml (templates expanded, runtime inputs merged, as stored when the run started), templates_expanded and any template_refs left unexpanded, inputs_yaml and resolved_inputs_yaml (merged runtime inputs, with `<+...>` expressions resolved), and runtime_expressions {total, distinct, items[{expression, count}]} — the expressions in compiled_yaml that Harness evaluates during the run, such as step outputs. Pass params.resolve_expressions=false to skip resolving inputs.",
+          paramsSchema: {
+            fields: [
+              {
+                name: "resolve_expressions",
+                required: false,
+                description: "When false, skip the resolved inputs lookup. Defaults to true.",
+              },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "ci_resource_usage",
+      displayName: "CI Build Resource Usage",
+      description:
+        "CPU, memory, and cache statistics for the CI stages and steps of an execution, with right-sizing suggestions against the configured limits. Supports get only. Use to pick a smaller or larger resource class for build infrastructure.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["execution_id"],
+      searchAliases: ["build resource usage", "ci cpu", "ci memory", "cache hit rate", "resource class", "right-size build", "build infrastructure size"],
+      relatedResources: [
+        {
+          resourceType: "execution",
+          relationship: "measured-from",
+          description: "The CI execution measured. Use harness_get(resource_type='execution', resource_id=<planExecutionId>) for status and failures.",
+        },
+        {
+          resourceType: "execution_timeline",
+          relationship: "related",
+          description: "Stage and step timings of the same execution, with parallel lanes.",
+        },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/ci/execution/resource-usage",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { execution_id: "planExecutionId" },
+          collect: collectCiResourceUsage,
+          responseExtractor: ciResourceUsageExtract,
+          skipCompact: true,
+          description:
+            "Get resource usage of a CI execution. Returns stages[] {id, name, status, duration_ms, infrastructure, cpu, memory, cache, steps[]} where cpu is {avg, max, limit} in millicores and memory is {avg, max, limit} in MiB (limit from the step's resources.limits or the measured allocation), plus utilization (max / limit) and cache {hits, misses, hit_rate}. recommendations[] lists {stage_id, step_id?, resource, action: decrease|increase|review_cache_key, current_limit, suggested_limit, reason}. Pass params.stage_id to report one stage. When measured usage is unavailable, errors says why and only configured limits and timings are returned.",
+          paramsSchema: {
+            fields: [
+              { name: "stage_id", required: false, description: "Only this CI stage identifier." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "execution_timeline",
+      displayName: "Pipeline Execution Timeline",
+      description:
+        "Gantt-ready timeline of a pipeline execution — stages and steps with start/end timestamps, offsets, durations, and parallel lanes. Supports get only. Optionally renders a Mermaid gantt chart for inline display in retros.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["execution_id"],
+      searchAliases: ["gantt", "execution timeline", "deployment timeline"],
+      relatedResources: [
+        {
+          resourceType: "execution",
+          relationship: "rendered-from",
+          description: "The pipeline execution this timeline was built from. Use harness_get(resource_type='execution', resource_id=<planExecutionId>) for full status and failure details.",
+        },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/execution/v2/{planExecutionId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { execution_id: "planExecutionId" },
+          staticQueryParams: { renderFullBottomGraph: "true" },
+          responseExtractor: executionTimelineExtract,
+          description:
+            "Get a Gantt-friendly execution timeline. Returns pipeline start/end/duration, max_parallelism {stages, steps}, not_started (count of tasks without timestamps), and tasks[] — each {id, name, type: stage|step, stage_id (steps), status, started_at, ended_at (null while running), start_offset_ms, duration_ms, lane, parallel_group (stages)}. Tasks sharing a parallel_group were defined as parallel stages; lane is derived from actual time overlap. Pass params.format='mermaid' to add a Mermaid `gantt` block in the mermaid field.",
+          paramsSchema: {
+            fields: [
+              {
+                name: "format",
+                required: false,
+                description: "'mermaid' to include a Mermaid gantt chart (one section per stage) in the mermaid field.",
+              },
+              {
+                name: "include_mermaid",
+                required: false,
+                description: "Deprecated alias for format='mermaid'. Still accepted; use format instead.",
+              },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "waiting_execution",
+      displayName: "Waiting Pipeline Execution",
+      description:
+        "Pipeline executions that are stuck waiting — paused, waiting on execution-time inputs, manual intervention (e.g. after a step timeout), approval, or a resource constraint — plus expired runs. List to find them, get to see which steps are waiting, then resume with the awaited inputs or intervene.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["execution_id"],
+      searchAliases: ["stuck execution", "paused execution", "resume execution", "interrupted execution", "expired execution"],
+      relatedResources: [
+        {
+          resourceType: "execution_input_request",
+          relationship: "awaits",
+          description: "Input template a step is waiting on. Use harness_get(resource_type='execution_input_request', resource_id=<node_execution_id>) before resuming.",
+        },
+        {
+          resourceType: "approval_instance",
+          relationship: "awaits",
+          description: "Approvals an execution in ApprovalWaiting is blocked on.",
+        },
+        {
+          resourceType: "execution",
+          relationship: "filtered-view-of",
+          description: "Full execution details. Paused executions resume via harness_execute(resource_type='execution', action='interrupt', params={interrupt_type: 'Resume'}).",
+        },
+      ],
+      listFilterFields: [
+        { name: "pipeline_id", description: "Pipeline identifier to filter executions" },
+        { name: "status", description: "Only this waiting status (defaults to all of them)", enum: WAITING_EXECUTION_STATUSES },
+      ],
+      deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/pipelines/{pipeline_id}/deployments/{execution_id}/pipeline",
+      operations: {
+        list: {
+          method: "POST",
+          path: "/pipeline/api/pipelines/execution/summary",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: {
+            pipeline_id: "pipelineIdentifier",
+            page: "page",
+            size: "size",
+          },
+          bodyBuilder: (input) => ({
+            filterType: "PipelineExecution",
+            status: typeof input.status === "string" && input.status ? [input.status] : WAITING_EXECUTION_STATUSES,
+          }),
+          responseExtractor: waitingExecutionListExtract,
+          description:
+            "List executions blocked on Paused, InputWaiting, InterventionWaiting, ApprovalWaiting, WaitStepRunning, ResourceWaiting, or Expired. Each item has execution_id, status, waiting_stages, and next_action — the call that unblocks it.",
+        },
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/execution/v2/{planExecutionId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { execution_id: "planExecutionId" },
+          staticQueryParams: { renderFullBottomGraph: "true" },
+          responseExtractor: waitingExecutionGetExtract,
+          description:
+            "Get the steps an execution is waiting on. Returns waiting_steps[] — each {node_execution_id, id, name, status, stage_id, step_type, next_action}. Pass node_execution_id to the resume or intervene action.",
+        },
+      },
+      executeActions: {
+        resume: {
+          method: "POST",
+          path: "/pipeline/api/execution-input/{nodeExecutionId}",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: { node_execution_id: "nodeExecutionId" },
+          headers: { "Content-Type": "application/yaml" },
+          bodyBuilder: (input) => buildExecutionInputBody(input),
+          bodySchema: {
+            description: "Awaited execution-time inputs. Fill every <+input> in the template from execution_input_request and pass it as inputs_yaml, or pass the filled YAML directly as body.",
+            fields: [
+              { name: "inputs_yaml", type: "string", required: false, description: "Filled-in input template YAML" },
+              { name: "inputs", type: "object", required: false, description: "Inputs as a JSON object matching the template structure (serialized to YAML)" },
+            ],
+          },
+          responseExtractor: ngExtract,
+          actionDescription: "Resume a step waiting on execution-time inputs (status InputWaiting) by submitting the awaited inputs. Requires node_execution_id from harness_get(resource_type='waiting_execution').",
+        },
+        intervene: {
+          method: "PUT",
+          path: "/pipeline/api/pipeline/execute/manualIntervention/interrupt/{planExecutionId}/{nodeExecutionId}",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: { execution_id: "planExecutionId", node_execution_id: "nodeExecutionId" },
+          queryParams: { interrupt_type: "interruptType" },
+          bodyBuilder: () => ({}),
+          bodySchema: { description: "No body required. Pass node_execution_id and interrupt_type as params.", fields: [] },
+          responseExtractor: ngExtract,
+          actionDescription: "Unblock a step waiting on manual intervention (status InterventionWaiting, e.g. after a timeout or failure strategy). Pass node_execution_id and interrupt_type: Retry, MarkAsSuccess, Ignore, MarkAsFailure, ProceedWithDefault, or Abort.",
+        },
+      },
+    },
+    {
+      resourceType: "execution_input_request",
+      displayName: "Execution Input Request",
+      description:
+        "Input template a running step is waiting on (execution-time inputs). Supports get only. Fill every <+input> and submit via harness_execute(resource_type='waiting_execution', action='resume').",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["node_execution_id"],
+      relatedResources: [
+        {
+          resourceType: "waiting_execution",
+          relationship: "requested-by",
+          description: "The waiting execution. Its get lists node_execution_id for each step in InputWaiting.",
+        },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/pipeline/api/execution-input/{nodeExecutionId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { node_execution_id: "nodeExecutionId" },
+          responseExtractor: ngExtract,
+          description: "Get the execution-time input template (inputTemplate YAML) for a step in InputWaiting.",
+        },
+      },
+    },
     {
       resourceType: "trigger",
       displayName: "Pipeline Trigger",
@@ -780,6 +1831,90 @@ export const pipelinesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "trigger_schedule",
+      displayName: "Trigger Schedule",
+      description:
+        "Consolidated schedule of cron triggers across a project (or one pipeline) with their next fire times, computed server-side and rendered in a chosen timezone. Answers \"what's deploying tonight?\". Supports list only.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: [],
+      searchAliases: ["cron schedule", "scheduled triggers", "next run", "what's deploying tonight", "release calendar"],
+      relatedResources: [
+        {
+          resourceType: "trigger",
+          relationship: "filtered-view-of",
+          description: "Full trigger definitions. Edit a schedule via harness_update(resource_type='trigger').",
+        },
+        { resourceType: "pipeline", relationship: "parent", description: "Pipelines the triggers start." },
+      ],
+      listFilterFields: [
+        { name: "pipeline_id", description: "Only this pipeline's triggers (default: every pipeline in the project)" },
+        { name: "count", type: "number", description: "Fire times per trigger (default 5, max 50)" },
+        { name: "within_hours", type: "number", description: "Only fire times within this many hours of from — e.g. 12 for tonight" },
+        { name: "timezone", description: "IANA timezone for returned times, e.g. America/New_York (default UTC)" },
+        { name: "from", description: "Start of the window as ISO-8601 or epoch ms (default now)" },
+        { name: "include_disabled", type: "boolean", description: "Also list disabled cron triggers (they have no next runs). Default false" },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/pipeline/api/triggers",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectScheduledTriggers,
+          responseExtractor: triggerScheduleExtract,
+          skipCompact: true,
+          description:
+            "List cron triggers with their next fire times. Returns items[] per trigger {pipeline_id, trigger_id, expression, trigger_timezone, next_runs[]} and schedule[] — every upcoming run across the project in time order. Cron is evaluated in the trigger's timezone (UTC unless set). Scans up to 200 pipelines.",
+        },
+      },
+    },
+    {
+      resourceType: "trigger_event",
+      displayName: "Trigger Event",
+      description:
+        "Webhook events received by a pipeline's triggers. Get takes an execution ID and returns the webhook payload that started it, the source/target branch and commit Harness derived from it, and each trigger condition re-evaluated against it — explains why a webhook ran the wrong branch or pipeline. Supports list and get.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["execution_id"],
+      searchAliases: ["webhook payload", "trigger payload", "why did this trigger fire", "trigger conditions", "webhook event"],
+      listFilterFields: [
+        { name: "pipeline_id", description: "Pipeline whose trigger events to list", required: true },
+        { name: "trigger_id", description: "Only events for this trigger" },
+        { name: "search_term", description: "Filter events by keyword" },
+      ],
+      relatedResources: [
+        { resourceType: "trigger", relationship: "parent", description: "The trigger definition, including its payload and header conditions." },
+        { resourceType: "execution", relationship: "produces", description: "The execution a webhook event started. Use harness_get(resource_type='execution', resource_id=<execution_id>) for status." },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/pipeline/api/triggers/eventHistory/{targetIdentifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { pipeline_id: "targetIdentifier" },
+          queryParams: {
+            trigger_id: "triggerIdentifier",
+            search_term: "searchTerm",
+            page: "page",
+            size: "size",
+          },
+          responseExtractor: pageExtract,
+          description: "List webhook events received by a pipeline's triggers, newest first, with payload, processing status, and the execution each one started (targetExecutionSummary).",
+        },
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/execution/v2/{planExecutionId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { execution_id: "planExecutionId" },
+          collect: collectTriggerEvent,
+          responseExtractor: triggerEventExtract,
+          skipCompact: true,
+          description:
+            "Inspect the webhook behind an execution. Returns trigger_id, event {created_at, status, message}, webhook {provider, event_type, action, repo, source_branch, target_branch, commit_sha, changed_files}, trigger_filter, conditions[] {kind, key, operator, value, actual, matched} and all_conditions_matched, plus the raw payload (truncated past 20k chars). Non-webhook executions return a note instead.",
+        },
+      },
+    },
     {
       resourceType: "pipeline_summary",
       displayName: "Pipeline Summary",
@@ -799,6 +1934,151 @@ export const pipelinesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "pipeline_health",
+      displayName: "Pipeline Health",
+      description:
+        "Health of one pipeline over a recent window, aggregated from its executions: success rate, duration, failure trend, flakiness, and the most common failure messages. Supports get only. Use to answer 'is this pipeline flaky?' or 'has it been getting worse?'.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["pipeline_id"],
+      searchAliases: ["pipeline health", "flaky pipeline", "success rate", "failure rate", "pipeline reliability", "pipeline metrics"],
+      relatedResources: [
+        {
+          resourceType: "execution",
+          relationship: "aggregates",
+          description: "The executions behind the numbers. Use harness_list(resource_type='execution', filters={pipeline_id, statuses: 'Failed'}) to page through failures, or harness_diagnose on a last_execution_id from top_failures.",
+        },
+        { resourceType: "pipeline", relationship: "parent", description: "The pipeline summarized." },
+      ],
+      deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/pipelines/{pipelineIdentifier}/executions",
+      operations: {
+        get: {
+          method: "POST",
+          path: "/pipeline/api/pipelines/execution/summary",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { pipeline_id: "pipelineIdentifier" },
+          collect: collectPipelineHealth,
+          responseExtractor: pipelineHealthExtract,
+          skipCompact: true,
+          description:
+            "Summarize a pipeline's executions started in the last params.window_days (default 30, up to 365; at most 500 executions, newest first). Returns verdict (healthy, flaky, failing, or insufficient_data), counts {executions, succeeded, failed, aborted, in_progress}, success_rate (succeeded / (succeeded + failed); aborted and running runs are excluded), duration_ms {mean, median, p90, max} of finished runs, flakiness {outcome_flips, flip_rate}, failure_trend (improving, worsening, or stable, comparing the failure rate of the window's two halves), trend[] per day or week, and top_failures[] {message, count, last_execution_id}.",
+          paramsSchema: {
+            fields: [
+              { name: "window_days", required: false, description: "Days to look back. Defaults to 30." },
+              { name: "bucket", required: false, description: "Trend granularity: 'day' or 'week'. Defaults to day for windows up to 14 days, week otherwise." },
+              { name: "branch", required: false, description: "Only executions of this branch (CI)." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "pipeline_compliance",
+      displayName: "Pipeline Compliance",
+      description:
+        "Gap report comparing a pipeline's structure with an org-standard (golden) pipeline or pipeline template: missing stages (e.g. an approval stage), missing steps (e.g. a verification step), delegate selectors that differ, and missing failure strategies. Supports get only. Use for standardization campaigns and 'does this pipeline follow our standard?' questions.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["pipeline_id"],
+      searchAliases: ["golden pipeline", "pipeline standard", "pipeline compliance", "template compliance", "pipeline drift", "standardization", "pipeline gap"],
+      relatedResources: [
+        { resourceType: "pipeline", relationship: "parent", description: "The pipeline checked, and the golden pipeline when compared against one." },
+        { resourceType: "template", relationship: "related", description: "The golden pipeline template when golden_template_id is used." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/{pipelineIdentifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { pipeline_id: "pipelineIdentifier" },
+          collect: collectPipelineCompliance,
+          responseExtractor: pipelineComplianceExtract,
+          skipCompact: true,
+          description:
+            "Compare a pipeline with a golden pipeline (params.golden_pipeline_id, optionally golden_org_id/golden_project_id) or pipeline template (params.golden_template_id, with account./org. prefix for higher scopes, optional version_label). Stages are matched by identifier, then by type; steps within matched stages the same way. Returns verdict (compliant or gaps_found), gaps[] {kind, severity, stage_id, step_id, expected, actual, message}, extra_stages[] (in the pipeline but not the golden), and checks {total, passed}. A pipeline built from the golden template itself is compliant by construction.",
+          paramsSchema: {
+            fields: [
+              { name: "golden_pipeline_id", required: false, description: "Identifier of the golden pipeline. Pass this or golden_template_id." },
+              { name: "golden_org_id", required: false, description: "Org of the golden pipeline or template, when it differs from the checked pipeline's." },
+              { name: "golden_project_id", required: false, description: "Project of the golden pipeline or project-level template, when it differs." },
+              { name: "golden_template_id", required: false, description: "Golden pipeline template ref, e.g. 'org.standard_deploy' or 'account.golden_cd'." },
+              { name: "version_label", required: false, description: "Golden template version. Defaults to the stable version." },
+              { name: "branch", required: false, description: "Branch of a remote (Git-backed) pipeline to check." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "pipeline_config_inventory",
+      displayName: "Pipeline Config Inventory",
+      description:
+        "Consolidated configuration inventory of a pipeline and the templates it uses: environment variables and variables (with their values), secret references, connector references, and account/org/project variable references, each with every location that uses it. Supports get only. Use as the starting point for configuration reviews, secret rotation impact, and connector migration questions.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["pipeline_id"],
+      searchAliases: ["config inventory", "environment variables", "env vars", "secret references", "connector references", "pipeline configuration", "config scan"],
+      relatedResources: [
+        { resourceType: "pipeline", relationship: "parent", description: "The pipeline scanned." },
+        { resourceType: "template", relationship: "related", description: "Templates the pipeline uses, which are scanned too." },
+        { resourceType: "secret", relationship: "related", description: "Secrets referenced by the pipeline." },
+        { resourceType: "connector", relationship: "related", description: "Connectors referenced by the pipeline." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/{pipelineIdentifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { pipeline_id: "pipelineIdentifier" },
+          collect: collectPipelineConfigInventory,
+          responseExtractor: pipelineConfigInventoryExtract,
+          skipCompact: true,
+          description:
+            `Scan a pipeline's YAML and its templates (nested templates included, up to ${CONFIG_TEMPLATE_MAX}) for configuration. Returns env_vars[] {name, declared_as (envVariables, environmentVariables, variables), values, secret, runtime_input, locations}, secrets[], connectors[], and variables[] ({ref, scope, locations}; <+secrets.getValue()> and <+variable.x> expressions, Secret-type variables, and connectorRef fields), templates[] {ref, version, error}, and totals. Locations read '<pipeline|template ref>: <dotted path>'.`,
+          paramsSchema: {
+            fields: [
+              { name: "include_templates", required: false, description: "Scan the templates the pipeline uses (default true). Set false to scan the pipeline YAML only." },
+              { name: "branch", required: false, description: "Branch of a remote (Git-backed) pipeline to scan." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "pipeline_stage_contract",
+      displayName: "Pipeline Stage Contract",
+      description:
+        "Declared inputs and outputs of each stage in a pipeline, as ready-to-use expressions: runtime inputs, stage variables, step output variables, images pushed by CI build steps, and references to other stages. Supports get only. Use when composing or extending a pipeline to wire one stage's outputs into a later stage's inputs without parsing YAML.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: ["pipeline_id"],
+      searchAliases: ["stage outputs", "stage inputs", "output variables", "stage contract", "pipeline expressions", "chain stages", "wire stages"],
+      relatedResources: [
+        { resourceType: "pipeline", relationship: "parent", description: "The pipeline whose stages are described." },
+        { resourceType: "runtime_input_template", relationship: "related", description: "The runtime input template for the whole pipeline." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/pipeline/api/pipelines/{pipelineIdentifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { pipeline_id: "pipelineIdentifier" },
+          queryParams: {
+            branch: "branch",
+          },
+          responseExtractor: pipelineStageContractExtract,
+          skipCompact: true,
+          description:
+            "Stage-by-stage contract of a pipeline. Returns variables[] (pipeline variables with <+pipeline.variables.x> expressions) and stages[] in run order, each with depends_on (stages whose outputs it reads), inputs {runtime_inputs (dotted paths of <+input> fields), variables, service, environment, references[] {stage, expression}} and outputs {variables, step_outputs[] {step, name, expression}, artifacts[] {step, type, repo, tags, expression}}. Expressions are fully qualified, so they work from any later stage. warnings[] flags references to stages that are missing or do not run earlier.",
+          paramsSchema: {
+            fields: [
+              { name: "branch", required: false, description: "Branch of a remote (Git-backed) pipeline to read." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
     {
       resourceType: "input_set",
       displayName: "Input Set",
@@ -942,6 +2222,74 @@ export const pipelinesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "pending_approval",
+      displayName: "Pending Approval",
+      description:
+        "Harness approvals waiting on a decision across a project (or one pipeline), with approver groups, required inputs, deadline, and the call to approve or reject each. Supports list only.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: [],
+      searchAliases: ["pending approvals", "approvals waiting", "approval queue", "what needs my approval", "manual approval gate"],
+      relatedResources: [
+        {
+          resourceType: "approval_instance",
+          relationship: "filtered-view-of",
+          description: "Approvals of one execution. Approve or reject via harness_execute(resource_type='approval_instance', action='approve' | 'reject', params={approval_id}).",
+        },
+        { resourceType: "waiting_execution", relationship: "related", description: "Every blocked execution, not just approvals." },
+      ],
+      listFilterFields: [
+        { name: "pipeline_id", description: "Only approvals for this pipeline's executions" },
+        { name: "approval_type", description: "Only this approval type", enum: ["HarnessApproval", "JiraApproval", "CustomApproval", "ServiceNowApproval"] },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/pipeline/api/pipelines/execution/summary",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectPendingApprovals,
+          responseExtractor: pendingApprovalListExtract,
+          skipCompact: true,
+          description:
+            "List WAITING approvals across ApprovalWaiting executions (up to 50), soonest deadline first. Each item has approval_id, type, execution_id, pipeline_id, step_name, message, deadline, user_groups, minimum_count, approvals_so_far, approver_inputs, actionable (HarnessApproval only), and next_action.",
+        },
+      },
+    },
+    {
+      resourceType: "my_action_item",
+      displayName: "My Action Item",
+      description:
+        "The current user's to-do list in one call: approvals assigned to one of their user groups, open pull requests waiting on their review, and executions waiting on runtime input. Supports list only.",
+      toolset: "pipelines",
+      scope: "project",
+      identifierFields: [],
+      searchAliases: ["my action items", "my approvals", "my to-do", "what needs my attention", "review requests", "approvals inbox"],
+      relatedResources: [
+        { resourceType: "pending_approval", relationship: "aggregates", description: "Every pending approval in the project, not just the user's." },
+        { resourceType: "pull_request", relationship: "aggregates", description: "Pull requests per repository, filterable by reviewer_id and review_decision." },
+        { resourceType: "waiting_execution", relationship: "aggregates", description: "Executions blocked on input, intervention, approval, or a resource constraint." },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: "/pipeline/api/pipelines/execution/summary",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectMyActionItems,
+          responseExtractor: myActionItemsExtract,
+          skipCompact: true,
+          skipCache: true,
+          paramsSchema: {
+            fields: [
+              { name: "repo_ids", required: false, description: `Comma-separated Harness Code repositories to check for review requests (max ${MY_ACTION_ITEMS_MAX_REPOS}). Without it the pr_reviews section is not_configured` },
+              { name: "pipeline_id", required: false, description: "Only approvals and waiting executions of this pipeline" },
+            ],
+          } satisfies ParamsSchema,
+          description:
+            "List the current user's action items. Returns summary {total, approvals, pr_reviews, waiting_inputs} and approvals, pr_reviews, waiting_inputs sections — each with status ok (count, items[] with next_action), not_configured, unavailable (toolset disabled), or error. Approvals are matched to the user's groups; if those cannot be read, every pending approval is listed with a note.",
+        },
+      },
+    },
     {
       resourceType: "approval_instance",
       displayName: "Approval Instance",
@@ -980,7 +2328,7 @@ export const pipelinesToolset: ToolsetDefinition = {
           bodyBuilder: (input) => {
             const body = input.body as Record<string, unknown> | undefined;
             const comments = input.comments ?? body?.comments ?? "";
-            const approverInputs = input.approver_inputs ?? body?.approver_inputs;
+            const approverInputs = normalizeApproverInputs(input.approver_inputs ?? body?.approver_inputs);
             return {
               action: "APPROVE",
               comments,
@@ -988,12 +2336,12 @@ export const pipelinesToolset: ToolsetDefinition = {
             };
           },
           responseExtractor: ngExtract,
-          actionDescription: "Approve a Harness approval instance. Requires approval_id. Optional: comments, approver_inputs (array of {name, value}).",
+          actionDescription: "Approve a Harness approval instance. Requires approval_id (from harness_list(resource_type='pending_approval') or approval_instance). Optional: comments, approver_inputs as [{name, value}] or {name: value}.",
           bodySchema: {
             description: "Approval activity",
             fields: [
               { name: "comments", type: "string", required: false, description: "Approval comment" },
-              { name: "approver_inputs", type: "array", required: false, description: "Approver inputs as [{name, value}]" },
+              { name: "approver_inputs", type: "array", required: false, description: "Approver inputs as [{name, value}] or a {name: value} object" },
             ],
           },
         },
@@ -1,6 +1,7 @@
-import type { ToolsetDefinition, BodySchema } from "../types.js";
-import { pageExtract, projectListExtract, unwrapOrgResponse, unwrapProjectResponse, v1Unwrap } from "../extractors.js";
+import type { ToolsetDefinition, BodySchema, PreflightContext } from "../types.js";
+import { pageExtract, pingExtract, projectListExtract, unwrapOrgResponse, unwrapProjectResponse, v1Unwrap, type PingScan } from "../extractors.js";
 import { stripNulls } from "../../utils/body-normalizer.js";
+import { getVersion } from "../../utils/cli.js";
 
 // ---------------------------------------------------------------------------
 // Body schemas (for harness_describe output)
@@ -137,6 +138,36 @@ function buildProjectUpdateBody(input: Record<string, unknown>): unknown {
   return stripNulls({ project: inner });
 }
 
+// ---------------------------------------------------------------------------
+// Connection check
+// ---------------------------------------------------------------------------
+
+/** A liveness check that takes longer than this is as good as down. */
+const PING_TIMEOUT_MS = 10_000;
+
+/**
+ * Collect hook for ping: one cheap authenticated read (the account record),
+ * timed end to end. It is never retried, so a dead connection fails fast. It
+ * still takes a HARNESS_RATE_LIMIT_RPS token like every upstream call, so
+ * pings cannot eat into the account's API quota unthrottled.
+ */
+const pingHarness = async ({ client, input, signal }: PreflightContext): Promise<PingScan> => {
+  const accountId = typeof input.account_id === "string" && input.account_id ? input.account_id : client.account;
+  const started = performance.now();
+  const account = await client.request<unknown>({
+    method: "GET",
+    path: `/ng/api/accounts/${encodeURIComponent(accountId)}`,
+    retryPolicy: "do_not_retry",
+    timeoutMs: PING_TIMEOUT_MS,
+    signal,
+  });
+  return {
+    latency_ms: Math.round(performance.now() - started),
+    account,
+    server_version: getVersion(),
+  };
+};
+
 // ---------------------------------------------------------------------------
 // Toolset definition
 // ---------------------------------------------------------------------------
@@ -144,8 +175,34 @@ function buildProjectUpdateBody(input: Record<string, unknown>): unknown {
 export const platformToolset: ToolsetDefinition = {
   name: "platform",
   displayName: "Platform",
-  description: "Harness platform entities — organizations and projects",
+  description: "Harness platform entities — organizations and projects, plus a connection check",
   resources: [
+    // ----- Connection check -----
+    {
+      resourceType: "ping",
+      displayName: "Connection Check",
+      description:
+        "Liveness check for the start of a conversation: one minimal authenticated call to Harness. " +
+        "Returns round-trip latency, the resolved account, and the MCP server version. " +
+        "Use it instead of listing a resource to test the connection — it is never cached and is exempt from tool rate limits.\n" +
+        "Example: harness_get(resource_type='ping')",
+      toolset: "platform",
+      scope: "account",
+      identifierFields: [],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/ng/api/accounts/{accountId}",
+          operationPolicy: { risk: "read", retryPolicy: "do_not_retry" },
+          pathParams: { account_id: "accountId" },
+          collect: pingHarness,
+          responseExtractor: pingExtract,
+          skipCache: true,
+          description: "Check connectivity and credentials: {ok, latency_ms, account {id, name, company, cluster}, server_version}",
+        },
+      },
+    },
+
     // ----- Organization -----
     {
       resourceType: "organization",
@@ -1,5 +1,6 @@
-import type { ParamsSchema, ToolsetDefinition } from "../types.js";
-import { passthrough } from "../extractors.js";
+import type { ParamsSchema, PreflightContext, ToolsetDefinition } from "../types.js";
+import { passthrough, pullRequestMergeExtract, prCheckStatusExtract, type PrCheckStatusScan } from "../extractors.js";
+import { isRecord } from "../../utils/type-guards.js";
 
 const REPO_PARAMS: ParamsSchema = {
   fields: [
@@ -66,6 +67,8 @@ function fieldValue(
   return undefined;
 }
 
+const PR_MERGE_METHODS = ["merge", "squash", "rebase", "fast-forward"];
+
 function pullRequestMergeBody(input: Record<string, unknown>): Record<string, unknown> {
   const body = bodyRecord(input);
   const merged: Record<string, unknown> = {};
@@ -86,9 +89,176 @@ function pullRequestMergeBody(input: Record<string, unknown>): Record<string, un
     }
   }
 
+  if (merged.method !== undefined && !PR_MERGE_METHODS.includes(merged.method as string)) {
+    throw new Error(`Unknown merge method ${JSON.stringify(merged.method)}; use one of ${PR_MERGE_METHODS.join(", ")}.`);
+  }
   return merged;
 }
 
+/** Body for check_merge: the merge options, always as a dry run that evaluates rules. */
+function pullRequestCheckMergeBody(input: Record<string, unknown>): Record<string, unknown> {
+  return { ...pullRequestMergeBody(input), dry_run: true, dry_run_rules: true };
+}
+
+/** Harness Code principal or user group IDs from a number, numeric string, comma-separated string, or array. */
+function principalIds(value: unknown, field: string): number[] | undefined {
+  if (value === undefined || value === null || value === "") return undefined;
+  const parts = Array.isArray(value) ? value : typeof value === "string" ? value.split(",") : [value];
+  return parts.map((part) => {
+    const id = typeof part === "string" ? Number(part.trim()) : part;
+    if (typeof id !== "number" || !Number.isInteger(id) || id <= 0) {
+      throw new Error(
+        `${field} must be Harness Code principal IDs (numbers), got ${JSON.stringify(part)}. ` +
+        `Reviewer IDs appear as reviewer.id in harness_list(resource_type='pr_reviewer') results.`,
+      );
+    }
+    return id;
+  });
+}
+
+function booleanInput(value: unknown): boolean | undefined {
+  if (typeof value === "boolean") return value;
+  if (value === "true") return true;
+  if (value === "false") return false;
+  return undefined;
+}
+
+/**
+ * Body for pull_request create: required branches and title checked up front,
+ * draft flag from is_draft or draft, reviewers from reviewer_ids or reviewers.
+ * Other body fields (e.g. source_repo_ref for a fork) pass through.
+ */
+function pullRequestCreateBody(input: Record<string, unknown>): Record<string, unknown> {
+  const { draft, reviewers, ...body } = bodyRecord(input) ?? {};
+  for (const field of ["title", "source_branch", "target_branch"]) {
+    if (typeof body[field] !== "string" || !(body[field] as string).trim()) {
+      throw new Error(`Missing required body field "${field}" for pull_request create.`);
+    }
+  }
+  if (body.source_branch === body.target_branch && !body.source_repo_ref) {
+    throw new Error(`source_branch and target_branch are both "${String(body.source_branch)}"; a pull request needs two different branches.`);
+  }
+  const isDraft = booleanInput(body.is_draft ?? draft ?? input.is_draft ?? input.draft);
+  const reviewerIds = principalIds(body.reviewer_ids ?? reviewers, "reviewer_ids");
+  const groupIds = principalIds(body.user_group_reviewer_ids, "user_group_reviewer_ids");
+  return {
+    ...body,
+    ...(isDraft !== undefined ? { is_draft: isDraft } : {}),
+    ...(reviewerIds ? { reviewer_ids: reviewerIds } : {}),
+    ...(groupIds ? { user_group_reviewer_ids: groupIds } : {}),
+  };
+}
+
+/**
+ * Body for pr_comment create. An inline comment anchors to line_new or line_old
+ * (new or old side of the diff); line_end extends it to a range on the same side.
+ */
+function pullRequestCommentBody(input: Record<string, unknown>): Record<string, unknown> {
+  const { line_new, line_old, line_end, ...body } = bodyRecord(input) ?? {};
+  const line = typeof line_new === "number" ? line_new : typeof line_old === "number" ? line_old : undefined;
+  if (line === undefined) {
+    if (line_end !== undefined) throw new Error("line_end needs line_new or line_old to mark the start of the range.");
+    return body;
+  }
+  if (typeof body.path !== "string" || !body.path) {
+    throw new Error("Inline comments need the file path along with line_new or line_old.");
+  }
+  const end = typeof line_end === "number" ? line_end : line;
+  if (end < line) throw new Error(`line_end (${end}) is before the start line (${line}).`);
+  const newSide = typeof line_new === "number";
+  return { ...body, line_start: line, line_end: end, line_start_new: newSide, line_end_new: newSide };
+}
+
+/**
+ * Review decision on a pull request. Harness Code records a review against a
+ * commit, so commit_sha defaults to the PR's current source_sha; a comment,
+ * when given, is posted first so the author sees why.
+ */
+function submitReviewDecision(decision: "approved" | "changereq") {
+  return async (ctx: PreflightContext): Promise<unknown> => {
+    const { client, input, registry, signal } = ctx;
+    const body = bodyRecord(input) ?? {};
+    const target = { repo_id: input.repo_id, pr_number: input.pr_number, org_id: input.org_id, project_id: input.project_id };
+    const text = body.comment ?? input.comment;
+    const hasComment = typeof text === "string" && text.trim() !== "";
+    if (decision === "changereq" && !hasComment) {
+      throw new Error("request_changes needs a comment describing the changes requested.");
+    }
+    let commitSha = body.commit_sha ?? input.commit_sha;
+    if (typeof commitSha !== "string" || !commitSha) {
+      const pr = await registry.dispatch(client, "pull_request", "get", target, signal) as Record<string, unknown> | undefined;
+      commitSha = pr?.source_sha;
+      if (typeof commitSha !== "string" || !commitSha) {
+        throw new Error("Could not read the pull request's source_sha; pass commit_sha explicitly.");
+      }
+    }
+    const comment = hasComment
+      ? await registry.dispatch(client, "pr_comment", "create", { ...target, body: { text } }, signal)
+      : undefined;
+    const org = (input.org_id as string | undefined) ?? registry.orgId;
+    const project = (input.project_id as string | undefined) ?? registry.projectId;
+    const review = await client.request<unknown>({
+      method: "POST",
+      path: `/code/api/v1/repos/${requiredPathPart(input, "repo_id")}/pullreq/${requiredPathPart(input, "pr_number")}/reviews`,
+      params: {
+        ...(org ? { orgIdentifier: org } : {}),
+        ...(project ? { projectIdentifier: project } : {}),
+      },
+      body: { decision, commit_sha: commitSha },
+      signal,
+    });
+    return { decision, commit_sha: commitSha, review, ...(comment !== undefined ? { comment } : {}) };
+  };
+}
+
+/**
+ * Gather a PR, its status checks, the repository's default branch, and the
+ * branch rules (including those inherited from parent spaces) for pr_check
+ * get. Failed lookups are recorded so the report can still explain the rest.
+ */
+async function collectPrCheckStatus(ctx: PreflightContext): Promise<PrCheckStatusScan> {
+  const { client, input, registry, signal } = ctx;
+  const repo = requiredPathPart(input, "repo_id");
+  requiredPathPart(input, "pr_number");
+  const target = { repo_id: input.repo_id, pr_number: input.pr_number, org_id: input.org_id, project_id: input.project_id };
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  const params = {
+    ...(org ? { orgIdentifier: org } : {}),
+    ...(project ? { projectIdentifier: project } : {}),
+  };
+  const errors: string[] = [];
+  const attempt = async (label: string, load: () => Promise<unknown>): Promise<unknown> => {
+    try {
+      return await load();
+    } catch (err) {
+      errors.push(`${label}: ${err instanceof Error ? err.message : String(err)}`);
+      return undefined;
+    }
+  };
+
+  const pullRequest = await registry.dispatch(client, "pull_request", "get", target, signal);
+  const [checks, rules, repository] = await Promise.all([
+    attempt("checks", () => registry.dispatch(client, "pr_check", "list", target, signal)),
+    attempt("rules", () => client.request<unknown>({
+      method: "GET",
+      path: `/code/api/v1/repos/${repo}/rules`,
+      params: { ...params, type: "branch", inherited: true, limit: 100 },
+      signal,
+    })),
+    attempt("repository", () => client.request<unknown>({ method: "GET", path: `/code/api/v1/repos/${repo}`, params, signal })),
+  ]);
+  return {
+    repo_id: String(input.repo_id),
+    pr_number: String(input.pr_number),
+    pull_request: pullRequest,
+    checks,
+    rules,
+    ...(isRecord(repository) && typeof repository.default_branch === "string" ? { default_branch: repository.default_branch } : {}),
+    errors,
+  };
+}
+
 function pullRequestUpdatePath(input: Record<string, unknown>): string {
   const repoIdentifier = requiredPathPart(input, "repo_id");
   const prNumber = requiredPathPart(input, "pr_number");
@@ -127,7 +297,7 @@ export const pullRequestsToolset: ToolsetDefinition = {
       resourceType: "pull_request",
       displayName: "Pull Request",
       description:
-        "Code pull request. Supports list, get, create, and update. Use execute actions for close and merge.",
+        "Code pull request. Supports list, get, create (optionally as a draft, with reviewers), and update. Use execute actions for close, mark_ready, check_merge, and merge.",
       toolset: "pull-requests",
       scope: "account",
       scopeOptional: true,
@@ -135,6 +305,8 @@ export const pullRequestsToolset: ToolsetDefinition = {
       listFilterFields: [
         { name: "state", description: "Pull request state filter", enum: ["open", "closed", "merged"] },
         { name: "query", description: "Search pull requests by keyword" },
+        { name: "reviewer_id", description: "Only pull requests with this Harness Code principal ID as a reviewer" },
+        { name: "review_decision", description: "With reviewer_id: only PRs where that reviewer's decision is this", enum: ["pending", "reviewed", "approved", "changereq"] },
       ],
       deepLinkTemplate:
         "/ng/account/{accountId}/module/code/orgs/{orgIdentifier}/projects/{projectIdentifier}/repos/{repoIdentifier}/pulls/{number}",
@@ -147,6 +319,8 @@ export const pullRequestsToolset: ToolsetDefinition = {
           queryParams: {
             state: "state",
             query: "query",
+            reviewer_id: "reviewer_id",
+            review_decision: "review_decision",
             page: "page",
             limit: "limit",
           },
@@ -171,9 +345,10 @@ export const pullRequestsToolset: ToolsetDefinition = {
           path: "/code/api/v1/repos/{repoIdentifier}/pullreq",
           operationPolicy: { risk: "low_write", retryPolicy: "do_not_retry" },
           pathParams: { repo_id: "repoIdentifier" },
-          bodyBuilder: (input) => input.body,
+          bodyBuilder: pullRequestCreateBody,
           responseExtractor: passthrough,
-          description: "Create a pull request",
+          description:
+            "Create a pull request from source_branch into target_branch. Set is_draft=true to open it as a draft (publish later with the mark_ready action). reviewer_ids requests reviews from Harness Code principals when the PR is opened.",
           paramsSchema: REPO_PARAMS,
           bodySchema: {
             description: "New pull request",
@@ -182,6 +357,10 @@ export const pullRequestsToolset: ToolsetDefinition = {
               { name: "source_branch", type: "string", required: true, description: "Source branch name" },
               { name: "target_branch", type: "string", required: true, description: "Target branch name" },
               { name: "description", type: "string", required: false, description: "PR description (markdown)" },
+              { name: "is_draft", type: "boolean", required: false, description: "Open as a draft PR. Alias: draft" },
+              { name: "reviewer_ids", type: "array", required: false, description: "Harness Code principal IDs to request reviews from (numbers or a comma-separated string). Alias: reviewers" },
+              { name: "user_group_reviewer_ids", type: "array", required: false, description: "Harness Code user group IDs to request reviews from" },
+              { name: "source_repo_ref", type: "string", required: false, description: "Repository the source branch lives in, when it is a fork" },
             ],
           },
         },
@@ -231,6 +410,25 @@ export const pullRequestsToolset: ToolsetDefinition = {
             fields: [],
           },
         },
+        mark_ready: {
+          method: "POST",
+          path: "/code/api/v1/repos/{repoIdentifier}/pullreq/{prNumber}/state",
+          operationPolicy: { risk: "low_write", retryPolicy: "safe" },
+          skipScopeBodyInjection: true,
+          pathParams: {
+            repo_id: "repoIdentifier",
+            pr_number: "prNumber",
+          },
+          bodyBuilder: () => ({ state: "open", is_draft: false }),
+          responseExtractor: passthrough,
+          paramsSchema: REPO_PR_PARAMS,
+          actionDescription:
+            "Mark a draft pull request ready for review.",
+          bodySchema: {
+            description: "Draft to ready state transition",
+            fields: [],
+          },
+        },
         merge: {
           method: "POST",
           path: "/code/api/v1/repos/{repoIdentifier}/pullreq/{prNumber}/merge",
@@ -241,10 +439,10 @@ export const pullRequestsToolset: ToolsetDefinition = {
           },
           skipScopeBodyInjection: true,
           bodyBuilder: pullRequestMergeBody,
-          responseExtractor: passthrough,
+          responseExtractor: pullRequestMergeExtract,
           paramsSchema: REPO_PR_PARAMS,
           actionDescription:
-            "Merge a pull request. Body fields: method (merge/squash/rebase/fast-forward), source_sha, delete_source_branch (boolean), dry_run (boolean), dry_run_rules (boolean), message, title, bypass_rules (boolean), bypass_message.",
+            "Merge a pull request. Body fields: method (merge/squash/rebase/fast-forward), source_sha, delete_source_branch (boolean), dry_run (boolean), dry_run_rules (boolean), message, title, bypass_rules (boolean), bypass_message. Run check_merge first to see conflicts and blocking rules without confirmation.",
           bodySchema: {
             description: "Merge options",
             fields: [
@@ -260,13 +458,36 @@ export const pullRequestsToolset: ToolsetDefinition = {
             ],
           },
         },
+        check_merge: {
+          method: "POST",
+          path: "/code/api/v1/repos/{repoIdentifier}/pullreq/{prNumber}/merge",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            pr_number: "prNumber",
+          },
+          skipScopeBodyInjection: true,
+          bodyBuilder: pullRequestCheckMergeBody,
+          responseExtractor: pullRequestMergeExtract,
+          paramsSchema: REPO_PR_PARAMS,
+          actionDescription:
+            "Dry-run a merge: report whether the pull request can be merged with the given method, listing conflict files, rule violations, approval requirements, and blockers. Nothing is merged.",
+          bodySchema: {
+            description: "Merge options to check",
+            fields: [
+              { name: "method", type: "string", required: false, description: "Merge method to check: merge, squash, rebase, or fast-forward" },
+              { name: "source_sha", type: "string", required: false, description: "Expected source SHA" },
+              { name: "bypass_rules", type: "boolean", required: false, description: "Check as if bypassing rules (where allowed)" },
+            ],
+          },
+        },
       },
     },
     {
       resourceType: "pr_reviewer",
       displayName: "PR Reviewer",
       description:
-        "Reviewers on a pull request. Supports list and create (add reviewer). Use execute action 'submit_review' to approve or request changes.",
+        "Reviewers on a pull request. Supports list and create (add reviewer). Use execute actions 'approve' and 'request_changes' to review (submit_review takes an explicit decision and commit).",
       toolset: "pull-requests",
       scope: "account",
       scopeOptional: true,
@@ -327,6 +548,48 @@ export const pullRequestsToolset: ToolsetDefinition = {
             ],
           },
         },
+        approve: {
+          method: "POST",
+          path: "/code/api/v1/repos/{repoIdentifier}/pullreq/{prNumber}/reviews",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            pr_number: "prNumber",
+          },
+          collect: submitReviewDecision("approved"),
+          responseExtractor: passthrough,
+          paramsSchema: REPO_PR_PARAMS,
+          actionDescription:
+            "Approve the pull request at its current source commit (or commit_sha). An optional comment is posted alongside the approval.",
+          bodySchema: {
+            description: "Approval options. All fields are optional.",
+            fields: [
+              { name: "comment", type: "string", required: false, description: "General comment to post with the approval (markdown supported)" },
+              { name: "commit_sha", type: "string", required: false, description: "Commit SHA reviewed against (default: the PR's current source_sha)" },
+            ],
+          },
+        },
+        request_changes: {
+          method: "POST",
+          path: "/code/api/v1/repos/{repoIdentifier}/pullreq/{prNumber}/reviews",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            pr_number: "prNumber",
+          },
+          collect: submitReviewDecision("changereq"),
+          responseExtractor: passthrough,
+          paramsSchema: REPO_PR_PARAMS,
+          actionDescription:
+            "Request changes on the pull request at its current source commit (or commit_sha), posting comment first so the author sees what to change. Leave inline feedback with pr_comment create before calling this.",
+          bodySchema: {
+            description: "Review options",
+            fields: [
+              { name: "comment", type: "string", required: true, description: "Summary of the changes requested (markdown supported)" },
+              { name: "commit_sha", type: "string", required: false, description: "Commit SHA reviewed against (default: the PR's current source_sha)" },
+            ],
+          },
+        },
       },
     },
     {
@@ -349,26 +612,10 @@ export const pullRequestsToolset: ToolsetDefinition = {
             repo_id: "repoIdentifier",
             pr_number: "prNumber",
           },
-          bodyBuilder: (input) => {
-            const b = { ...(input.body as Record<string, unknown>) };
-            if (typeof b.line_new === "number") {
-              b.line_start = b.line_new;
-              b.line_end = b.line_new;
-              b.line_start_new = true;
-              b.line_end_new = true;
-              delete b.line_new;
-            } else if (typeof b.line_old === "number") {
-              b.line_start = b.line_old;
-              b.line_end = b.line_old;
-              b.line_start_new = false;
-              b.line_end_new = false;
-              delete b.line_old;
-            }
-            return b;
-          },
+          bodyBuilder: pullRequestCommentBody,
           responseExtractor: passthrough,
           description:
-            "Add a comment to a pull request. Body fields: text (required). For inline code comments, also include: path, line_new OR line_old (line number on the new or old side of the diff), source_commit_sha, target_commit_sha.",
+            "Add a comment to a pull request. Body fields: text (required). For inline code comments, also include: path, line_new OR line_old (line number on the new or old side of the diff), optional line_end for a multi-line range, source_commit_sha, target_commit_sha. Reply to an existing comment with parent_id.",
           paramsSchema: REPO_PR_PARAMS,
           bodySchema: {
             description: "PR comment content",
@@ -377,6 +624,8 @@ export const pullRequestsToolset: ToolsetDefinition = {
               { name: "path", type: "string", required: false, description: "File path for inline code comment" },
               { name: "line_new", type: "number", required: false, description: "Line number in the new file version for inline comment (mutually exclusive with line_old)" },
               { name: "line_old", type: "number", required: false, description: "Line number in the old file version for inline comment (mutually exclusive with line_new)" },
+              { name: "line_end", type: "number", required: false, description: "Last line of a multi-line inline comment, on the same side as line_new/line_old (default: the start line)" },
+              { name: "parent_id", type: "number", required: false, description: "Comment ID to reply to (the activity id from pr_activity)" },
               { name: "source_commit_sha", type: "string", required: false, description: "Source commit SHA (HEAD of source branch) for code comment context" },
               { name: "target_commit_sha", type: "string", required: false, description: "Target/merge-base commit SHA for code comment context" },
             ],
@@ -421,7 +670,8 @@ export const pullRequestsToolset: ToolsetDefinition = {
     {
       resourceType: "pr_check",
       displayName: "PR Check",
-      description: "Status checks on a pull request. Supports list.",
+      description:
+        "Status checks on a pull request. Supports list (raw checks) and get (checks against the branch rules that require them). Use get to explain why checks block a merge.",
       toolset: "pull-requests",
       scope: "account",
       scopeOptional: true,
@@ -439,6 +689,21 @@ export const pullRequestsToolset: ToolsetDefinition = {
           description: "List status checks for a pull request",
           paramsSchema: REPO_PR_PARAMS,
         },
+        get: {
+          method: "GET",
+          path: "/code/api/v1/repos/{repoIdentifier}/pullreq/{prNumber}/checks",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            pr_number: "prNumber",
+          },
+          collect: collectPrCheckStatus,
+          responseExtractor: prCheckStatusExtract,
+          skipCompact: true,
+          description:
+            "Status checks of a pull request evaluated against the protection rules on its target branch (inherited rules included). Returns checks_pass, blockers[] (required checks that failed, are pending, or never reported, with the rules requiring them), required_checks[] {identifier, state: passed|failed|pending|missing, rules}, optional_failures[], checks[], and the applicable rules[] with their required checks and approval, comment, and merge-strategy requirements.",
+          paramsSchema: REPO_PR_PARAMS,
+        },
       },
     },
     {
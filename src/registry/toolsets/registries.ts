@@ -1,5 +1,6 @@
-import type { ToolsetDefinition, PathBuilderConfig } from "../types.js";
-import { passthrough, harListExtract } from "../extractors.js";
+import type { ToolsetDefinition, PathBuilderConfig, PreflightContext, ParamsSchema } from "../types.js";
+import { passthrough, harListExtract, harVersionMetadataExtract, type HarVersionMetadataScan } from "../extractors.js";
+import { isRecord } from "../../utils/type-guards.js";
 
 /**
  * HAR API uses path-based scope refs (not query params).
@@ -21,6 +22,70 @@ function harRegistryRef(input: Record<string, unknown>, config: PathBuilderConfi
   return `${harSpaceRef(input, config)}/${registry}`;
 }
 
+/** Package types with a dedicated `/{type}/details` endpoint; others use the generic `/details`. */
+const TYPED_DETAILS_PATHS = new Set(["DOCKER", "HELM", "MAVEN", "GENERIC"]);
+
+/**
+ * Gather what is known about one artifact version: the summary (which gives
+ * the package type), then the package-type-specific details. Docker adds the
+ * per-platform manifests; npm adds the package document for dist-tags. Only
+ * the summary is required; later failures are reported in `errors`.
+ */
+async function collectArtifactVersionMetadata(ctx: PreflightContext): Promise<HarVersionMetadataScan> {
+  const { client, input, registry, signal } = ctx;
+  const registryId = String(input.registry_id ?? "");
+  const artifact = String(input.artifact_id ?? "");
+  const version = String(input.version ?? "");
+  if (!registryId || !artifact || !version) {
+    throw new Error("registry_id, artifact_id, and version are required (version via resource_id).");
+  }
+  const org = (input.org_id as string | undefined) || registry.orgId || "";
+  const project = (input.project_id as string | undefined) || registry.projectId || "";
+  const base = `/har/api/v1/registry/${client.account}/${org}/${project}/${registryId}/+/artifact/${artifact}/+/version/${version}`;
+  const get = async (path: string): Promise<Record<string, unknown>> => {
+    const raw = await client.request<unknown>({ method: "GET", path, signal });
+    const data = isRecord(raw) && isRecord(raw.data) ? raw.data : raw;
+    return isRecord(data) ? data : {};
+  };
+
+  const summary = await get(`${base}/summary`);
+  const packageType = String(summary.packageType ?? input.package_type ?? "").toUpperCase();
+  const scan: HarVersionMetadataScan = {
+    registry_id: registryId,
+    summary,
+    sources: { artifact, version, package_type: packageType || "UNKNOWN" },
+    errors: [],
+  };
+  const attempt = async (what: string, fn: () => Promise<void>) => {
+    try {
+      await fn();
+    } catch (err) {
+      scan.errors.push(`${what}: ${err instanceof Error ? err.message : String(err)}`);
+    }
+  };
+
+  const detailsPath = TYPED_DETAILS_PATHS.has(packageType) ? `${base}/${packageType.toLowerCase()}/details` : `${base}/details`;
+  await Promise.all([
+    packageType === "DOCKER"
+      ? attempt("manifests", async () => {
+        const manifests = (await get(`${base}/docker/manifests`)).manifests;
+        scan.sources.manifests = Array.isArray(manifests) ? manifests : [];
+      })
+      : undefined,
+    attempt("details", async () => {
+      scan.sources.details = await get(detailsPath);
+    }),
+    packageType === "NPM"
+      ? attempt("dist-tags", async () => {
+        // npm registry protocol: scoped names keep "@" and encode the "/".
+        const name = artifact.replace("/", "%2f");
+        scan.sources.packument = await get(`/pkg/${client.account}/${registryId}/npm/${name}`);
+      })
+      : undefined,
+  ]);
+  return scan;
+}
+
 export const registriesToolset: ToolsetDefinition = {
   name: "registries",
   displayName: "Artifact Registries",
@@ -107,7 +172,7 @@ export const registriesToolset: ToolsetDefinition = {
     {
       resourceType: "artifact_version",
       displayName: "Artifact Version",
-      description: "Version of an artifact. Supports list.",
+      description: "Version of an artifact. Supports list and get. Get returns typed, package-type-specific metadata: Docker platforms, Maven coordinates, npm dist-tags and dependencies, Helm app version, Python requirements.",
       toolset: "registries",
       scope: "project",
       identifierFields: ["registry_id", "artifact_id", "version"],
@@ -115,6 +180,22 @@ export const registriesToolset: ToolsetDefinition = {
         { name: "search", description: "Filter artifact versions by name or keyword" },
       ],
       operations: {
+        get: {
+          method: "GET",
+          path: "/har/api/v1/registry",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectArtifactVersionMetadata,
+          responseExtractor: harVersionMetadataExtract,
+          skipCompact: true,
+          paramsSchema: {
+            fields: [
+              { name: "registry_id", required: true, description: "Registry identifier" },
+              { name: "artifact_id", required: true, description: "Artifact (package) name; Maven artifacts are named groupId:artifactId" },
+            ],
+          } satisfies ParamsSchema,
+          description:
+            "Get one artifact version (resource_id = version; params.registry_id and params.artifact_id). Returns {package_type, size, created_at, download_count, quarantined, metadata} where metadata.kind selects the shape: docker {image, tag, platforms[{os, architecture, variant, digest, size}], pull_command}; maven {group_id, artifact_id, version, coordinates, packaging, files}; npm {name, version, dist_tags, tags_on_version, dependencies, peer_dependencies, tarball, integrity}; helm {chart, version, app_version, pull_command}; python {name, version, requires_python, requires_dist}. Other package types return metadata.details as-is.",
+        },
         list: {
           method: "GET",
           path: "/har/api/v1/registry",
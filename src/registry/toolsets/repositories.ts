@@ -1,5 +1,185 @@
-import type { ToolsetDefinition } from "../types.js";
-import { passthrough } from "../extractors.js";
+import type { ToolsetDefinition, ParamsSchema, PreflightContext } from "../types.js";
+import { passthrough, codeCommitListExtract, codeBlameExtract, codeFileContentExtract, codeTreeExtract, codeDiffExtract, REPO_DIFF_DEFAULT_CHARS, REPO_DIFF_MAX_CHARS } from "../extractors.js";
+import { HarnessApiError } from "../../utils/errors.js";
+import { isRecord } from "../../utils/type-guards.js";
+
+/** Most files one commit_files call may change. */
+const COMMIT_FILES_MAX = 50;
+
+interface FileChange {
+  path: string;
+  content?: string;
+  delete: boolean;
+}
+
+function readFileChanges(files: unknown): FileChange[] {
+  if (!Array.isArray(files) || files.length === 0) {
+    throw new Error("files is required: a list of { path, content } to create or update, or { path, delete: true } to delete.");
+  }
+  if (files.length > COMMIT_FILES_MAX) {
+    throw new Error(`files has ${files.length} entries; commit at most ${COMMIT_FILES_MAX} files per call.`);
+  }
+  const seen = new Set<string>();
+  return files.map((file, i) => {
+    const entry = isRecord(file) ? file : {};
+    const path = typeof entry.path === "string" ? entry.path.replace(/^\/+/, "") : "";
+    if (!path) throw new Error(`files[${i}].path is required.`);
+    if (seen.has(path)) throw new Error(`files lists "${path}" more than once.`);
+    seen.add(path);
+    const remove = entry.delete === true;
+    if (!remove && typeof entry.content !== "string") {
+      throw new Error(`files[${i}] ("${path}") needs content, or delete: true.`);
+    }
+    return { path, delete: remove, ...(remove ? {} : { content: entry.content as string }) };
+  });
+}
+
+/**
+ * commit_files: one commit that creates, updates, or deletes files on a
+ * branch. Each file is looked up on the base branch first, so the agent
+ * passes only paths and new content — the action (CREATE or UPDATE) and the
+ * blob sha Harness Code needs for optimistic locking are filled in here.
+ */
+async function commitFiles(ctx: PreflightContext): Promise<unknown> {
+  const { client, input, registry, signal } = ctx;
+  const body = isRecord(input.body) ? input.body : {};
+  const repoId = typeof input.repo_id === "string" && input.repo_id ? input.repo_id : undefined;
+  if (!repoId) throw new Error("repo_id is required.");
+  const branch = typeof body.branch === "string" && body.branch ? body.branch : undefined;
+  if (!branch) throw new Error("body.branch is required: the branch to commit to, or to start new_branch from.");
+  const title = typeof body.title === "string" && body.title.trim() ? body.title.trim() : undefined;
+  if (!title) throw new Error("body.title is required: the commit message's first line.");
+  const changes = readFileChanges(body.files);
+  const scope = {
+    ...(input.org_id ? { org_id: input.org_id } : {}),
+    ...(input.project_id ? { project_id: input.project_id } : {}),
+  };
+
+  const existing = await Promise.all(changes.map(async (change) => {
+    try {
+      const file = await registry.dispatch(client, "file_content", "get", {
+        repo_id: repoId,
+        path: change.path,
+        git_ref: branch,
+        ...scope,
+      }, signal) as Record<string, unknown> | undefined;
+      if (file?.type && file.type !== "file") throw new Error(`"${change.path}" is a ${String(file.type)}, not a file.`);
+      return typeof file?.sha === "string" ? file.sha : undefined;
+    } catch (err) {
+      if (err instanceof HarnessApiError && err.statusCode === 404) return undefined;
+      throw err;
+    }
+  }));
+
+  const actions = changes.map((change, i) => {
+    const sha = existing[i];
+    if (change.delete) {
+      if (!sha) throw new Error(`Cannot delete "${change.path}": it does not exist on ${branch}.`);
+      return { action: "DELETE", path: change.path, sha };
+    }
+    return sha
+      ? { action: "UPDATE", path: change.path, payload: change.content, encoding: "utf8", sha }
+      : { action: "CREATE", path: change.path, payload: change.content, encoding: "utf8" };
+  });
+
+  const newBranch = typeof body.new_branch === "string" && body.new_branch ? body.new_branch : undefined;
+  const result = await client.request<unknown>({
+    method: "POST",
+    path: `/code/api/v1/repos/${encodeURIComponent(repoId)}/commits`,
+    params: {
+      ...(input.org_id ? { orgIdentifier: String(input.org_id) } : {}),
+      ...(input.project_id ? { projectIdentifier: String(input.project_id) } : {}),
+    },
+    body: {
+      title,
+      ...(typeof body.message === "string" && body.message ? { message: body.message } : {}),
+      branch,
+      ...(newBranch ? { new_branch: newBranch } : {}),
+      actions,
+      ...(body.dry_run_rules === true ? { dry_run_rules: true } : {}),
+    },
+    signal,
+  });
+  const response = isRecord(result) ? result : {};
+  const target = newBranch ?? branch;
+  return {
+    commit_id: response.commit_id ?? null,
+    branch: target,
+    ...(newBranch ? { base_branch: branch } : {}),
+    files: actions.map((a) => ({ path: a.path, action: a.action })),
+    ...(Array.isArray(response.rule_violations) && response.rule_violations.length > 0 ? { rule_violations: response.rule_violations } : {}),
+    ...(body.dry_run_rules === true ? { dry_run: true } : {}),
+    ...(newBranch && body.dry_run_rules !== true
+      ? {
+        next_step: `harness_create(resource_type='pull_request', params={repo_id: '${repoId}'}, body={title: ${JSON.stringify(title)}, source_branch: '${newBranch}', target_branch: '${branch}'}) — open a pull request for review.`,
+      }
+      : {}),
+  };
+}
+
+/** A git ref as a path segment: encoded, but with `/` kept so branch names like feature/x route. */
+function refPathPart(ref: string): string {
+  return encodeURIComponent(ref).replace(/%2F/gi, "/");
+}
+
+/** Fetch the raw unified diff between two refs for repo_diff; an empty diff is an empty string. */
+async function collectRepoDiff(ctx: PreflightContext): Promise<string> {
+  const { client, input, registry, signal } = ctx;
+  const repoId = typeof input.repo_id === "string" ? input.repo_id : "";
+  const base = typeof input.base === "string" ? input.base.trim() : "";
+  const head = typeof input.head === "string" ? input.head.trim() : "";
+  if (!repoId) throw new Error("repo_id is required.");
+  if (!base || !head) throw new Error("base and head are required — the commits, branches, or tags to compare (e.g. base='main', head='feature/login').");
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  const raw = await client.request<unknown>({
+    method: "GET",
+    path: `/code/api/v1/repos/${encodeURIComponent(repoId)}/diff/${refPathPart(base)}..${refPathPart(head)}`,
+    params: {
+      ...(org ? { orgIdentifier: org } : {}),
+      ...(project ? { projectIdentifier: project } : {}),
+    },
+    headers: { Accept: "text/plain" },
+    responseType: "buffer",
+    signal,
+  });
+  return raw instanceof ArrayBuffer ? Buffer.from(raw).toString("utf8") : "";
+}
+
+/** Repository events a Harness Code webhook can subscribe to. */
+const WEBHOOK_TRIGGERS = [
+  "branch_created", "branch_updated", "branch_deleted",
+  "tag_created", "tag_updated", "tag_deleted",
+  "pullreq_created", "pullreq_reopened", "pullreq_branch_updated", "pullreq_updated", "pullreq_closed", "pullreq_merged",
+  "pullreq_comment_created", "pullreq_review_submitted", "pullreq_label_assigned",
+];
+
+/** Create body for a repo webhook: validates the URL and event names; no triggers means every event. */
+function repoWebhookBody(input: Record<string, unknown>): Record<string, unknown> {
+  const body = isRecord(input.body) ? input.body : {};
+  const identifier = typeof body.identifier === "string" ? body.identifier.trim() : "";
+  if (!identifier) throw new Error("body.identifier is required — a unique name for the webhook, e.g. 'ci-trigger'.");
+  if (typeof body.url !== "string" || !/^https?:\/\//.test(body.url)) {
+    throw new Error("body.url is required and must be an http(s) URL, e.g. a Harness pipeline trigger's webhook URL.");
+  }
+  const triggers: unknown[] = typeof body.triggers === "string"
+    ? body.triggers.split(",").map((t) => t.trim()).filter(Boolean)
+    : Array.isArray(body.triggers) ? body.triggers : [];
+  const unknown = triggers.filter((t) => typeof t !== "string" || !WEBHOOK_TRIGGERS.includes(t));
+  if (unknown.length > 0) {
+    throw new Error(`Unknown webhook trigger(s): ${unknown.join(", ")}. Valid triggers: ${WEBHOOK_TRIGGERS.join(", ")}.`);
+  }
+  return {
+    identifier,
+    display_name: typeof body.display_name === "string" && body.display_name ? body.display_name : identifier,
+    ...(typeof body.description === "string" ? { description: body.description } : {}),
+    url: body.url,
+    ...(typeof body.secret === "string" && body.secret ? { secret: body.secret } : {}),
+    enabled: body.enabled !== false,
+    insecure: body.insecure === true,
+    triggers,
+  };
+}
 
 export const repositoriesToolset: ToolsetDefinition = {
   name: "repositories",
@@ -162,17 +342,19 @@ export const repositoriesToolset: ToolsetDefinition = {
       resourceType: "commit",
       displayName: "Commit",
       description:
-        "Git commit in a Harness Code repository. Supports list, get, and create. Use create to commit file changes (CREATE, UPDATE, DELETE, MOVE) directly via the API without cloning.",
+        "Git commit in a Harness Code repository. Supports list, get, and create. Use create to commit file changes (CREATE, UPDATE, DELETE, MOVE) directly via the API without cloning, or execute action 'commit_files' to commit files by path and content without looking up blob shas.",
       toolset: "repositories",
       scope: "account",
       scopeOptional: true,
       identifierFields: ["repo_id", "commit_sha"],
+      searchAliases: ["git log", "file history", "commit history"],
       listFilterFields: [
         { name: "git_ref", description: "Git reference (branch/tag) filter" },
         { name: "path", description: "File path filter" },
-        { name: "since", description: "Filter commits since date" },
-        { name: "until", description: "Filter commits until date" },
-        { name: "committer", description: "Filter by committer" },
+        { name: "since", description: "Only commits after this time (epoch milliseconds)", type: "number" },
+        { name: "until", description: "Only commits before this time (epoch milliseconds)", type: "number" },
+        { name: "committer", description: "Filter by committer name or email" },
+        { name: "author", description: "Filter by author name or email" },
       ],
       operations: {
         list: {
@@ -186,12 +368,13 @@ export const repositoriesToolset: ToolsetDefinition = {
             since: "since",
             until: "until",
             committer: "committer",
+            author: "author",
             page: "page",
             limit: "limit",
           },
-          responseExtractor: passthrough,
+          responseExtractor: codeCommitListExtract,
           description:
-            "List commits in a repository. Filter by git_ref (branch/tag), path, date range, or committer.",
+            "List commits in a repository, newest first. Filter by git_ref (branch/tag), path (commits touching a file or directory), since/until (epoch ms), author, or committer. Each item has sha, title, message, author {identity, when}, and committer.",
         },
         get: {
           method: "GET",
@@ -240,7 +423,7 @@ export const repositoriesToolset: ToolsetDefinition = {
           },
           responseExtractor: passthrough,
           actionDescription:
-            "Get the raw diff between two refs. Set range to 'base..head' (e.g., 'main..feature-branch').",
+            "Get the raw diff between two refs. Set range to 'base..head' (e.g., 'main..feature-branch'). For per-file stats, a path filter, and size limits, use harness_get(resource_type='repo_diff').",
           bodySchema: { description: "No body required. Diff range is specified via path parameter (e.g. main..feature-branch).", fields: [] },
         },
         diff_stats: {
@@ -256,13 +439,35 @@ export const repositoriesToolset: ToolsetDefinition = {
             "Get diff stats (files changed, additions, deletions) between two refs. Set range to 'base..head'.",
           bodySchema: { description: "No body required. Range is specified via path parameter.", fields: [] },
         },
+        commit_files: {
+          method: "POST",
+          path: "/code/api/v1/repos/{repoIdentifier}/commits",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: { repo_id: "repoIdentifier" },
+          collect: commitFiles,
+          responseExtractor: passthrough,
+          actionDescription:
+            "Commit several files in one commit by path and content — no blob shas needed. Each file is created if missing and updated if present; { path, delete: true } deletes. "
+            + "Set new_branch to commit on a new branch started from branch, then open a pull request (the response includes the harness_create call). Returns commit_id, branch, and files[] {path, action}.",
+          bodySchema: {
+            description: "Commit title, target branch, and the files to change.",
+            fields: [
+              { name: "branch", type: "string", required: true, description: "Branch to commit to, or to start new_branch from (e.g. 'main')" },
+              { name: "new_branch", type: "string", required: false, description: "Create this branch from 'branch' and commit there, e.g. for a pull request" },
+              { name: "title", type: "string", required: true, description: "Commit title (first line of the commit message)" },
+              { name: "message", type: "string", required: false, description: "Extended commit message body" },
+              { name: "files", type: "array", required: true, description: `Up to ${COMMIT_FILES_MAX} files: { path, content } to create or update (UTF-8 text), or { path, delete: true }.` },
+              { name: "dry_run_rules", type: "boolean", required: false, description: "Check branch rules without committing" },
+            ],
+          },
+        },
       },
     },
     {
       resourceType: "file_content",
       displayName: "File Content",
       description:
-        "File or directory content from a Harness Code repository. Supports get. Use execute action 'blame' for git blame.",
+        "File or directory content from a Harness Code repository. Supports get. Use file_blame for a summarized git blame, or execute action 'blame' for the raw blame.",
       toolset: "repositories",
       scope: "account",
       scopeOptional: true,
@@ -283,9 +488,9 @@ export const repositoriesToolset: ToolsetDefinition = {
             git_ref: "git_ref",
             include_commit: "include_commit",
           },
-          responseExtractor: passthrough,
+          responseExtractor: codeFileContentExtract,
           description:
-            "Get file or directory content. Specify path and optional git_ref (branch/tag/SHA). Returns file content or directory listing.",
+            "Get file or directory content. Specify path and optional git_ref (branch/tag/SHA). Text files come back decoded: content.data is the file's UTF-8 text (content.encoding 'utf8'); binary files keep base64. Directories return their entries. Use repo_tree to browse recursively.",
         },
       },
       executeActions: {
@@ -311,6 +516,141 @@ export const repositoriesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "file_blame",
+      displayName: "File Blame",
+      description:
+        "Summarized git blame for a file in a Harness Code repository — who last touched which lines and when. Supports get. Useful during incident triage to find the most recent change to a config file.",
+      toolset: "repositories",
+      scope: "account",
+      scopeOptional: true,
+      identifierFields: ["repo_id", "path"],
+      searchAliases: ["blame", "who changed", "last modified by", "git blame"],
+      relatedResources: [
+        {
+          resourceType: "commit",
+          relationship: "references",
+          description: "Commits referenced by blame hunks. Use harness_get(resource_type='commit', params={repo_id, commit_sha}) for full details and changed files.",
+        },
+        {
+          resourceType: "file_content",
+          relationship: "sibling",
+          description: "The file being blamed.",
+        },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/code/api/v1/repos/{repoIdentifier}/blame/{filePath}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            path: "filePath",
+          },
+          queryParams: {
+            org_id: "orgIdentifier",
+            project_id: "projectIdentifier",
+            git_ref: "git_ref",
+            line_from: "line_from",
+            line_to: "line_to",
+          },
+          responseExtractor: codeBlameExtract,
+          description:
+            "Get summarized blame for a file. Returns last_change {sha, title, name, email, when, line_ranges}, authors[] {name, email, lines, last_change} sorted by lines owned, and hunks[] {line_from, line_to, sha, title, name, email, when}. File contents are not included.",
+          paramsSchema: {
+            fields: [
+              { name: "repo_id", required: true, description: "Repository identifier" },
+              { name: "path", required: true, description: "File path within the repository (e.g. config/app.yaml)" },
+              { name: "git_ref", required: false, description: "Branch, tag, or commit SHA. Defaults to the repository's default branch." },
+              { name: "line_from", required: false, description: "First line to blame (1-based)" },
+              { name: "line_to", required: false, description: "Last line to blame" },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+    {
+      resourceType: "repo_tree",
+      displayName: "Repository Tree",
+      description:
+        "Directory tree of a Harness Code repository at a ref. Supports list. Browse files without cloning, then read one with file_content.",
+      toolset: "repositories",
+      scope: "account",
+      scopeOptional: true,
+      identifierFields: ["repo_id"],
+      searchAliases: ["tree", "ls", "list files", "directory listing"],
+      listFilterFields: [
+        { name: "git_ref", description: "Branch, tag, or commit SHA. Defaults to the repository's default branch." },
+        { name: "path", description: "Directory to list (e.g. 'deploy/k8s'). Defaults to the repository root." },
+        { name: "depth", description: "Levels below path to include (1 = direct children). Defaults to all.", type: "number" },
+      ],
+      relatedResources: [
+        {
+          resourceType: "file_content",
+          relationship: "child",
+          description: "Read a listed file with harness_get(resource_type='file_content', params={repo_id, path, git_ref}).",
+        },
+        {
+          resourceType: "commit",
+          relationship: "sibling",
+          description: "History of a listed path: harness_list(resource_type='commit', filters={path, git_ref}).",
+        },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/code/api/v1/repos/{repoIdentifier}/paths",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { repo_id: "repoIdentifier" },
+          queryParams: {
+            org_id: "orgIdentifier",
+            project_id: "projectIdentifier",
+            git_ref: "git_ref",
+          },
+          staticQueryParams: { include_directories: "true" },
+          responseExtractor: codeTreeExtract,
+          description:
+            "List files and directories under path (default: root) at git_ref, depth levels deep (default: all). Returns items[] {path, type: 'dir'|'file'}, directories first, and total. Capped at 1000 entries; narrow with path or depth when truncated.",
+        },
+      },
+    },
+    {
+      resourceType: "repo_diff",
+      displayName: "Repository Diff",
+      description:
+        "Unified diff between any two commits, branches, or tags of a Harness Code repository, with per-file stats, an optional path filter, and a size limit for LLM context. Supports get only.",
+      toolset: "repositories",
+      scope: "account",
+      scopeOptional: true,
+      identifierFields: ["repo_id"],
+      searchAliases: ["get diff", "compare branches", "compare commits", "git diff", "changes between"],
+      relatedResources: [
+        { resourceType: "commit", relationship: "related", description: "Commits between the two refs." },
+        { resourceType: "pull_request", relationship: "related", description: "Open a pull request for the head branch once the diff looks right." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/code/api/v1/repos/{repoIdentifier}/diff",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { repo_id: "repoIdentifier" },
+          collect: collectRepoDiff,
+          responseExtractor: codeDiffExtract,
+          skipCompact: true,
+          description:
+            `Diff from base to head. Returns stats {files, additions, deletions}, files[] {path, old_path, status (added, deleted, renamed, modified), additions, deletions, binary}, and diff (unified diff text). Patches are included whole, in file order, up to max_chars (default ${REPO_DIFF_DEFAULT_CHARS}, max ${REPO_DIFF_MAX_CHARS}). Past that, truncated is set, truncated_file names the file cut mid-patch, and omitted_files lists the rest. Fetch those by passing them as path. Set stats_only=true for stats and files only.`,
+          paramsSchema: {
+            fields: [
+              { name: "base", required: true, description: "Ref to diff from: commit SHA, branch, or tag (e.g. 'main')." },
+              { name: "head", required: true, description: "Ref to diff to: commit SHA, branch, or tag (e.g. 'feature/login')." },
+              { name: "path", required: false, description: "Only these files: comma-separated directory or file prefixes, or globs such as 'src/**/*.ts'." },
+              { name: "max_chars", required: false, description: `Most diff characters returned (default ${REPO_DIFF_DEFAULT_CHARS}, max ${REPO_DIFF_MAX_CHARS}).` },
+              { name: "stats_only", required: false, description: "Return stats and the file list without diff text." },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
     {
       resourceType: "tag",
       displayName: "Tag",
@@ -372,6 +712,88 @@ export const repositoriesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "repo_webhook",
+      displayName: "Repository Webhook",
+      description:
+        "Webhook on a Harness Code repository that calls a URL on repository events (branch and tag pushes, pull request activity). Supports list, get, create, and delete. Point one at a Harness pipeline trigger's webhook URL to run pipelines on repo events. Secrets are write-only: responses only show has_secret.",
+      toolset: "repositories",
+      scope: "account",
+      scopeOptional: true,
+      identifierFields: ["repo_id", "webhook_id"],
+      searchAliases: ["repo webhook", "git webhook", "code webhook", "push hook"],
+      listFilterFields: [
+        { name: "query", description: "Filter webhooks by identifier or name" },
+        { name: "sort", description: "Sort field (identifier, display_name, created, updated)" },
+        { name: "order", description: "Sort order (asc/desc)" },
+      ],
+      relatedResources: [
+        { resourceType: "repository", relationship: "parent", description: "The repository whose events the webhook delivers." },
+        { resourceType: "trigger", relationship: "related", description: "A pipeline webhook trigger whose URL the webhook can call." },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/code/api/v1/repos/{repoIdentifier}/webhooks",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { repo_id: "repoIdentifier" },
+          queryParams: {
+            query: "query",
+            sort: "sort",
+            order: "order",
+            page: "page",
+            limit: "limit",
+          },
+          responseExtractor: passthrough,
+          description: "List webhooks on a repository, with their URL, triggers, enabled state, and latest delivery status.",
+        },
+        get: {
+          method: "GET",
+          path: "/code/api/v1/repos/{repoIdentifier}/webhooks/{webhookIdentifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            webhook_id: "webhookIdentifier",
+          },
+          responseExtractor: passthrough,
+          description: "Get a repository webhook by identifier",
+        },
+        create: {
+          method: "POST",
+          path: "/code/api/v1/repos/{repoIdentifier}/webhooks",
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: { repo_id: "repoIdentifier" },
+          bodyBuilder: repoWebhookBody,
+          responseExtractor: passthrough,
+          description:
+            "Create a repository webhook. Body fields: identifier and url (required), triggers (event names; omit for all events), secret (signs deliveries), display_name, description, enabled (default true), insecure (skip TLS verification, default false). Requires user confirmation.",
+          bodySchema: {
+            description: "Repository webhook definition",
+            fields: [
+              { name: "identifier", type: "string", required: true, description: "Webhook identifier, unique in the repository" },
+              { name: "url", type: "string", required: true, description: "URL called on each event, e.g. a pipeline trigger's webhook URL" },
+              { name: "triggers", type: "array", required: false, description: `Events to deliver; omit for all. One or more of: ${WEBHOOK_TRIGGERS.join(", ")}` },
+              { name: "secret", type: "string", required: false, description: "Shared secret used to sign deliveries. Never returned by the API." },
+              { name: "display_name", type: "string", required: false, description: "Display name. Defaults to the identifier." },
+              { name: "description", type: "string", required: false, description: "Webhook description" },
+              { name: "enabled", type: "boolean", required: false, description: "Deliver events (default true)" },
+              { name: "insecure", type: "boolean", required: false, description: "Skip TLS certificate verification (default false)" },
+            ],
+          },
+        },
+        delete: {
+          method: "DELETE",
+          path: "/code/api/v1/repos/{repoIdentifier}/webhooks/{webhookIdentifier}",
+          operationPolicy: { risk: "destructive", retryPolicy: "do_not_retry" },
+          pathParams: {
+            repo_id: "repoIdentifier",
+            webhook_id: "webhookIdentifier",
+          },
+          responseExtractor: passthrough,
+          description: "Delete a repository webhook",
+        },
+      },
+    },
     {
       resourceType: "repo_rule",
       displayName: "Repository Protection Rule",
@@ -1,6 +1,12 @@
-import type { ToolsetDefinition, FilterFieldSpec, ParamsSchema } from "../types.js";
-import { scsCleanExtract, scsListExtract } from "../extractors.js";
+import { createHash } from "node:crypto";
+import type { ToolsetDefinition, FilterFieldSpec, ParamsSchema, PreflightContext } from "../types.js";
+import { scsCleanExtract, scsListExtract, scsSbomComparisonExtract, scsSbomDownloadExtract, scsCveImpactExtract, scsLicenseInventoryExtract, scsDependencyGraphExtract, renderGraphMermaid, wantsMermaid, type MermaidGraphEdge, type MermaidGraphNode, type SbomComparisonScan, type SbomDownload, type CveImpactScan, type LicenseInventoryScan, type DependencyGraphScan } from "../extractors.js";
 import { HarnessApiError } from "../../utils/errors.js";
+import { fanOut } from "../../utils/fan-out.js";
+import { isRecord } from "../../utils/type-guards.js";
+import { diffSboms, type SbomComponent } from "../../utils/sbom-diff.js";
+import { fetchSbomDocument } from "../../utils/sbom-download.js";
+import type { InventoryArtifact } from "../../utils/license-inventory.js";
 
 function filterFieldsToParamsSchema(fields: FilterFieldSpec[]): ParamsSchema {
   return {
@@ -68,6 +74,7 @@ const PREFLIGHT_MAX_PAGES = 5;
 const ARTIFACT_SOURCE_LIST_FIELDS = [
   "id", "source_id", "identifier", "name", "artifact_type", "source_type",
   "registry_type", "registry_url", "artifact_count", "created", "updated",
+  "artifacts", "artifacts_error",
 ];
 
 const ARTIFACT_SECURITY_LIST_FIELDS = [
@@ -107,36 +114,91 @@ const artifactSecurityListExtract = (raw: unknown): unknown => {
 /**
  * Custom extractor for scs_artifact_source list responses.
  * Appends a `_summary` with item count and breakdown by artifact_type
- * so the LLM doesn't need to manually count large lists.
+ * so the LLM doesn't need to manually count large lists. When sources were
+ * enriched with artifacts, `_summary.enrichment` says how many were not.
  */
 const artifactSourceListExtract = (raw: unknown): unknown => {
   const cleaned = scsListExtract(ARTIFACT_SOURCE_LIST_FIELDS)(raw);
   if (!Array.isArray(cleaned) || cleaned.length === 0) return cleaned;
   const byType: Record<string, number> = {};
+  let failed = 0;
   for (const item of cleaned) {
     if (item && typeof item === "object" && !Array.isArray(item)) {
       const rec = item as Record<string, unknown>;
       const at = rec.artifact_type as Record<string, unknown> | undefined;
       const typeName = (at?.type as string) ?? "UNKNOWN";
       byType[typeName] = (byType[typeName] || 0) + 1;
+      if (rec.artifacts_error) failed++;
     }
   }
-  return [...cleaned, { _summary: { total: cleaned.length, by_type: byType } }];
+  const summary: Record<string, unknown> = { total: cleaned.length, by_type: byType };
+  if (failed > 0) {
+    summary.enrichment = {
+      enriched: cleaned.length - failed,
+      failed,
+      note: "Partial result: some sources have artifacts_error instead of artifacts. Their rows are still complete.",
+    };
+  }
+  return [...cleaned, { _summary: summary }];
 };
 
 /**
  * Custom extractor for scs_component_dependencies.
  * When the API returns an empty list the agent tends to fabricate dependencies
  * from training data. Inject an explicit "no results" message to prevent this.
+ * With `format=mermaid` the list is returned as `{ dependencies, mermaid }`.
  */
-const componentDependenciesExtract = (raw: unknown): unknown => {
+const componentDependenciesExtract = (raw: unknown, input?: Record<string, unknown>): unknown => {
   const cleaned = scsListExtract(COMPONENT_DEPENDENCY_LIST_FIELDS)(raw);
   if (Array.isArray(cleaned) && cleaned.length === 0) {
     return { _result: "EMPTY", _message: "Zero sub-dependencies found. Do NOT fabricate — report as-is." };
   }
-  return cleaned;
+  if (!wantsMermaid(input) || !Array.isArray(cleaned)) return cleaned;
+  return { dependencies: cleaned, mermaid: dependencyTreeMermaid(cleaned, typeof input?.purl === "string" ? input.purl : undefined) };
 };
 
+/**
+ * Flowchart of a component's dependency tree. Each dependency hangs off the
+ * last entry of its relationship_path (a list or a `->`/`>`-separated string
+ * of purls or names), or off the root component when it is DIRECT or has no path.
+ */
+function dependencyTreeMermaid(deps: unknown[], rootPurl: string | undefined): string {
+  const root = rootPurl ?? "root";
+  const nodes: MermaidGraphNode[] = [{ id: root, label: rootPurl ? purlLabel(rootPurl) : "component" }];
+  const edges: MermaidGraphEdge[] = [];
+  const ids = new Map<string, string>();
+  const rows = deps.filter((d): d is Record<string, unknown> => !!d && typeof d === "object" && !Array.isArray(d));
+  for (const dep of rows) {
+    const id = String(dep.purl ?? `${dep.name}@${dep.version}`);
+    const vulns = typeof dep.vulnerabilities_count === "number" && dep.vulnerabilities_count > 0 ? dep.vulnerabilities_count : 0;
+    nodes.push({
+      id,
+      label: `${dep.name ?? purlLabel(id)}${dep.version ? `@${dep.version}` : ""}${vulns ? ` (${vulns} vulns)` : ""}`,
+      ...(vulns ? { status: "failed" } : {}),
+    });
+    for (const key of [id, String(dep.name ?? ""), dep.version ? `${dep.name}@${dep.version}` : ""]) {
+      if (key) ids.set(key, id);
+    }
+  }
+  for (const dep of rows) {
+    const id = String(dep.purl ?? `${dep.name}@${dep.version}`);
+    const path = Array.isArray(dep.relationship_path)
+      ? dep.relationship_path.map(String)
+      : typeof dep.relationship_path === "string" ? dep.relationship_path.split(/\s*-?>\s*/) : [];
+    const hops = path.map((p) => p.trim()).filter((p) => p && ids.get(p) !== id && p !== id);
+    const parent = String(dep.relationship ?? "").toUpperCase() === "DIRECT" || hops.length === 0
+      ? root
+      : ids.get(hops[hops.length - 1]!) ?? root;
+    edges.push({ from: parent, to: id });
+  }
+  return renderGraphMermaid(nodes, edges, "TD");
+}
+
+/** `pkg:npm/express@4.18.0` → `express@4.18.0`. */
+function purlLabel(purl: string): string {
+  return purl.replace(/[?#].*$/, "").split("/").pop() ?? purl;
+}
+
 /**
  * Custom extractor for scs_component_vulnerability.
  * The agent often supplements real CVE results with CVEs from training data
@@ -314,6 +376,106 @@ function ensureArray(val: unknown): unknown[] | undefined {
   return Array.isArray(val) ? val : [val];
 }
 
+const VEX_STATEMENT_LIST_FIELDS = [
+  "id", "vulnerability_id", "product", "subcomponents", "status", "justification",
+  "impact_statement", "action_statement", "author", "created_at", "updated_at",
+];
+
+const VEX_STATUSES = ["not_affected", "affected", "fixed", "under_investigation"];
+
+/** OpenVEX justifications for a not_affected status. */
+const VEX_JUSTIFICATIONS = [
+  "component_not_present",
+  "vulnerable_code_not_present",
+  "vulnerable_code_not_in_execute_path",
+  "vulnerable_code_cannot_be_controlled_by_adversary",
+  "inline_mitigations_already_exist",
+];
+
+/**
+ * Validate a VEX statement from the request body against the OpenVEX rules:
+ * not_affected needs a justification or impact_statement, affected needs an
+ * action_statement. subcomponents accepts one PURL or a list.
+ */
+function vexStatementBody(input: Record<string, unknown>): Record<string, unknown> {
+  const body = (input.body && typeof input.body === "object" ? input.body : {}) as Record<string, unknown>;
+  const field = (name: string): string | undefined => {
+    const value = body[name] ?? input[name];
+    return typeof value === "string" && value.trim() ? value.trim() : undefined;
+  };
+  const vulnerabilityId = field("vulnerability_id");
+  if (!vulnerabilityId) throw new Error("vulnerability_id is required (e.g. CVE-2024-3094 or GHSA-xxxx-xxxx-xxxx).");
+  const status = field("status");
+  if (!status || !VEX_STATUSES.includes(status)) {
+    throw new Error(`status must be one of ${VEX_STATUSES.join(", ")}.`);
+  }
+  const justification = field("justification");
+  if (justification && !VEX_JUSTIFICATIONS.includes(justification)) {
+    throw new Error(`justification must be one of ${VEX_JUSTIFICATIONS.join(", ")}.`);
+  }
+  if (justification && status !== "not_affected") {
+    throw new Error("justification only applies to status not_affected.");
+  }
+  const impactStatement = field("impact_statement");
+  if (status === "not_affected" && !justification && !impactStatement) {
+    throw new Error("A not_affected statement needs a justification or an impact_statement explaining why the vulnerability does not apply.");
+  }
+  const actionStatement = field("action_statement");
+  if (status === "affected" && !actionStatement) {
+    throw new Error("An affected statement needs an action_statement telling consumers what to do (e.g. upgrade to 2.4.1).");
+  }
+  const subcomponents = ensureArray(body.subcomponents ?? input.subcomponents)
+    ?.filter((purl): purl is string => typeof purl === "string" && purl.trim() !== "");
+  const product = field("product");
+  return {
+    vulnerability_id: vulnerabilityId,
+    ...(product ? { product } : {}),
+    ...(subcomponents?.length ? { subcomponents } : {}),
+    status,
+    ...(justification ? { justification } : {}),
+    ...(impactStatement ? { impact_statement: impactStatement } : {}),
+    ...(actionStatement ? { action_statement: actionStatement } : {}),
+  };
+}
+
+/**
+ * Render a VEX statement as an OpenVEX v0.2.0 document, for review or for
+ * attaching to a release outside Harness. Nothing is sent to SCS. The
+ * document @id is a hash of its statement, so regenerating the same
+ * statement yields the same ID.
+ */
+async function generateOpenVexDocument(ctx: PreflightContext): Promise<unknown> {
+  const { client, input } = ctx;
+  const statement = vexStatementBody(input);
+  if (!statement.product) {
+    throw new Error("product is required to generate a VEX document: the artifact the statement covers, as a PURL or image reference (e.g. pkg:oci/api@sha256:...).");
+  }
+  const body = (input.body && typeof input.body === "object" ? input.body : {}) as Record<string, unknown>;
+  const timestamp = new Date().toISOString();
+  const openVexStatement = {
+    vulnerability: { name: statement.vulnerability_id },
+    products: [{
+      "@id": statement.product,
+      ...(Array.isArray(statement.subcomponents)
+        ? { subcomponents: (statement.subcomponents as string[]).map((purl) => ({ "@id": purl })) }
+        : {}),
+    }],
+    status: statement.status,
+    ...(statement.justification ? { justification: statement.justification } : {}),
+    ...(statement.impact_statement ? { impact_statement: statement.impact_statement } : {}),
+    ...(statement.action_statement ? { action_statement: statement.action_statement } : {}),
+  };
+  const digest = createHash("sha256").update(JSON.stringify(openVexStatement)).digest("hex");
+  return {
+    "@context": "https://openvex.dev/ns/v0.2.0",
+    "@id": `https://openvex.dev/docs/public/vex-${digest}`,
+    author: typeof body.author === "string" && body.author.trim() ? body.author.trim() : `Harness account ${client.account}`,
+    timestamp,
+    version: 1,
+    statements: [{ ...openVexStatement, timestamp }],
+  };
+}
+
 /**
  * SCS (Software Supply Chain Security) API base path.
  * The SSCA manager API embeds org/project in the URL path rather than query params.
@@ -321,6 +483,445 @@ function ensureArray(val: unknown): unknown[] | undefined {
  */
 const SCS = "/ssca-manager";
 
+/**
+ * Artifact-source enrichment: each listed source gets a preview of its
+ * artifacts (one artifact_security list per source). Calls run a few at a
+ * time under a wall-clock budget, each capped to a small page, so accounts
+ * with many registries stay within quota. A failed or skipped source keeps
+ * its row and carries a note instead of failing the whole list.
+ */
+const SOURCE_ENRICH_CONCURRENCY = 4;
+const SOURCE_ENRICH_BUDGET_MS = 15_000;
+const ARTIFACTS_PER_SOURCE = 5;
+const MAX_ARTIFACTS_PER_SOURCE = 20;
+
+async function collectArtifactSources(ctx: PreflightContext): Promise<unknown> {
+  const { client, input, registry, signal } = ctx;
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  if (!org || !project) throw new Error("org_id and project_id are required to list SCS artifact sources");
+
+  const raw = await client.request<unknown>({
+    method: "POST",
+    path: `${SCS}/v1/orgs/${encodeURIComponent(org)}/projects/${encodeURIComponent(project)}/artifact-sources`,
+    params: {
+      ...(input.page !== undefined ? { page: String(input.page) } : {}),
+      size: String(input.limit ?? input.size ?? "10"),
+    },
+    body: {
+      ...(input.search_term ? { search_term: input.search_term } : {}),
+      ...(input.artifact_type ? { artifact_type: ensureArray(input.artifact_type) } : {}),
+    },
+    signal,
+  });
+  if (input.include_artifacts === false || input.include_artifacts === "false" || !Array.isArray(raw)) return raw;
+
+  const perSource = Math.min(Math.max(1, Number(input.artifacts_per_source) || ARTIFACTS_PER_SOURCE), MAX_ARTIFACTS_PER_SOURCE);
+  const sources = raw.filter((item): item is Record<string, unknown> => !!item && typeof item === "object" && !Array.isArray(item));
+  const sourceId = (source: Record<string, unknown>) => (source.source_id ?? source.id) as string | undefined;
+  const outcome = await fanOut(
+    sources.filter((source) => sourceId(source)),
+    async (source, laneSignal) => {
+      const artifacts = await registry.dispatch(client, "artifact_security", "list", {
+        source_id: sourceId(source),
+        org_id: org,
+        project_id: project,
+        limit: perSource,
+      }, laneSignal);
+      return Array.isArray(artifacts)
+        ? artifacts.filter((a) => !!a && typeof a === "object" && !Object.keys(a as object).some((k) => k.startsWith("_")))
+        : [];
+    },
+    { concurrency: SOURCE_ENRICH_CONCURRENCY, budgetMs: SOURCE_ENRICH_BUDGET_MS, signal },
+  );
+  for (const { item, value } of outcome.results) item.artifacts = value;
+  for (const { item, error } of outcome.errors) item.artifacts_error = error;
+  for (const source of sources) {
+    if (!("artifacts" in source) && !("artifacts_error" in source)) {
+      source.artifacts_error = sourceId(source)
+        ? "Skipped: the enrichment time budget ran out. List artifact_security with this source_id."
+        : "Skipped: the source has no id.";
+    }
+  }
+  return raw;
+}
+
+/**
+ * SBOM comparison between two artifact versions. Each side is an artifact,
+ * or an orchestration (SBOM generation run) resolved to its artifact through
+ * the source's artifact list. Components are paged in under a cap so a huge
+ * image cannot stall the call; a capped side is flagged as truncated.
+ */
+const SBOM_COMPARE_PAGE_SIZE = 100;
+const SBOM_COMPARE_MAX_PAGES = 20;
+const ORCHESTRATION_LOOKUP_PAGE_SIZE = 50;
+const ORCHESTRATION_LOOKUP_MAX_PAGES = 5;
+
+type SbomSide = SbomComparisonScan["base"];
+
+async function resolveSbomSide(ctx: PreflightContext, side: "base" | "target"): Promise<Omit<SbomSide, "component_count" | "truncated">> {
+  const { client, input, registry, signal } = ctx;
+  const artifactId = input[`${side}_artifact_id`] as string | undefined;
+  if (artifactId) return { artifact_id: artifactId };
+  const orchestrationId = input[`${side}_orchestration_id`] as string | undefined;
+  if (!orchestrationId) {
+    throw new Error(`Pass ${side}_artifact_id or ${side}_orchestration_id. Get both from harness_list(resource_type='artifact_security', source_id='...').`);
+  }
+  if (!input.source_id) {
+    throw new Error(`source_id is required to look up ${side}_orchestration_id — orchestrations are matched against the source's artifact list.`);
+  }
+  for (let page = 0; page < ORCHESTRATION_LOOKUP_MAX_PAGES; page++) {
+    const artifacts = await registry.dispatch(client, "artifact_security", "list", {
+      source_id: input.source_id,
+      org_id: input.org_id,
+      project_id: input.project_id,
+      page,
+      size: ORCHESTRATION_LOOKUP_PAGE_SIZE,
+    }, signal);
+    const items = Array.isArray(artifacts) ? artifacts.filter((a): a is Record<string, unknown> => !!a && typeof a === "object") : [];
+    const match = items.find((a) => (a.orchestration as Record<string, unknown> | undefined)?.id === orchestrationId);
+    if (match) {
+      return {
+        artifact_id: String(match.artifact_id ?? match.id),
+        orchestration_id: orchestrationId,
+        ...(match.name ? { name: String(match.name) } : {}),
+        ...(match.tag ? { tag: String(match.tag) } : {}),
+      };
+    }
+    if (items.length < ORCHESTRATION_LOOKUP_PAGE_SIZE) break;
+  }
+  throw new Error(`No artifact in source "${String(input.source_id)}" has orchestration "${orchestrationId}" as its latest SBOM run. `
+    + `Pass ${side}_artifact_id instead.`);
+}
+
+async function sbomComponents(
+  ctx: PreflightContext,
+  artifactId: string,
+  filters: Record<string, unknown> = {},
+): Promise<{ components: SbomComponent[]; truncated: boolean }> {
+  const { client, input, registry, signal } = ctx;
+  const components: SbomComponent[] = [];
+  for (let page = 0; page < SBOM_COMPARE_MAX_PAGES; page++) {
+    const raw = await registry.dispatch(client, "scs_artifact_component", "list", {
+      ...filters,
+      artifact_id: artifactId,
+      org_id: input.org_id,
+      project_id: input.project_id,
+      page,
+      size: SBOM_COMPARE_PAGE_SIZE,
+    }, signal);
+    const rows = Array.isArray(raw)
+      ? raw.filter((c): c is Record<string, unknown> => !!c && typeof c === "object" && !Object.keys(c).some((k) => k.startsWith("_")))
+      : [];
+    for (const row of rows) {
+      const purl = (row.purl ?? row.packageUrl) as string | undefined;
+      const name = String(row.package_name ?? row.name ?? purl ?? "");
+      if (!name) continue;
+      const version = row.package_version ?? row.version;
+      const license = row.package_license ?? row.license;
+      const count = Number(row.vulnerability_count);
+      components.push({
+        key: purl ? normalizePurl(purl) : name.toLowerCase(),
+        name,
+        ...(version ? { version: String(version) } : {}),
+        ...(license ? { license: Array.isArray(license) ? license.join(" AND ") : String(license) } : {}),
+        ...(purl ? { purl } : {}),
+        ...(Number.isFinite(count) ? { vulnerabilities: count } : {}),
+      });
+    }
+    if (rows.length < SBOM_COMPARE_PAGE_SIZE) return { components, truncated: false };
+  }
+  return { components, truncated: true };
+}
+
+/** Collect hook for scs_sbom_comparison: resolve both sides, page in their components, and diff them. */
+async function compareSboms(ctx: PreflightContext): Promise<SbomComparisonScan> {
+  const [baseRef, targetRef] = await Promise.all([resolveSbomSide(ctx, "base"), resolveSbomSide(ctx, "target")]);
+  if (baseRef.artifact_id === targetRef.artifact_id) {
+    throw new Error(`Both sides resolve to artifact "${baseRef.artifact_id}"; pick two different artifact versions.`);
+  }
+  const [base, target] = await Promise.all([sbomComponents(ctx, baseRef.artifact_id), sbomComponents(ctx, targetRef.artifact_id)]);
+  return {
+    base: { ...baseRef, component_count: base.components.length, truncated: base.truncated },
+    target: { ...targetRef, component_count: target.components.length, truncated: target.truncated },
+    diff: diffSboms(base.components, target.components),
+  };
+}
+
+/** Fields of the sbom-download response that may carry the document's link. */
+const SBOM_URL_FIELDS = ["download_url", "downloadUrl", "sbom_url", "sbomUrl", "signed_url", "url", "link"];
+
+/** The SBOM inline in an sbom-download response, or the link to fetch it from. */
+function sbomLocation(response: unknown): { inline?: string | Record<string, unknown>; url?: string } {
+  for (const candidate of [response, isRecord(response) ? response.data : undefined]) {
+    if (typeof candidate === "string" && candidate.trim()) {
+      return /^(https?:\/\/|\/)/.test(candidate.trim()) ? { url: candidate.trim() } : { inline: candidate };
+    }
+    if (!isRecord(candidate)) continue;
+    if (candidate.bomFormat || candidate.spdxVersion) return { inline: candidate };
+    const sbom = candidate.sbom;
+    if (typeof sbom === "string" || isRecord(sbom)) return { inline: sbom };
+    const url = SBOM_URL_FIELDS.map((k) => candidate[k]).find((v): v is string => typeof v === "string" && !!v.trim());
+    if (url) return { url: url.trim() };
+  }
+  return {};
+}
+
+/**
+ * Collect hook for the scs_sbom download action: take the document from the
+ * sbom-download response, or fetch it from the link the response carries.
+ */
+async function downloadSbom(ctx: PreflightContext): Promise<SbomDownload> {
+  const { client, input, registry, signal } = ctx;
+  const orchestrationId = String(input.orchestration_id ?? "");
+  if (!orchestrationId) {
+    throw new Error("orchestration_id is required. Get it from harness_get(resource_type='scs_chain_of_custody', artifact_id='...').");
+  }
+  const response = await registry.dispatch(client, "scs_sbom", "get", {
+    orchestration_id: orchestrationId,
+    org_id: input.org_id,
+    project_id: input.project_id,
+  }, signal);
+  const { inline, url } = sbomLocation(response);
+  if (inline !== undefined) {
+    const bytes = Buffer.byteLength(typeof inline === "string" ? inline : JSON.stringify(inline));
+    return { orchestration_id: orchestrationId, source: "inline", content: inline, bytes };
+  }
+  if (!url) throw new Error("The sbom-download response has neither an SBOM document nor a download link.");
+  const { text, source } = await fetchSbomDocument(client, url, signal);
+  return { orchestration_id: orchestrationId, source, content: text, bytes: Buffer.byteLength(text) };
+}
+
+/**
+ * CVE impact across the project: which artifacts and code repositories ship
+ * a vulnerable component, and where those artifacts are deployed. The
+ * component comes from the purl, or from the CVE plus a component name, in
+ * which case every version found is checked against the CVE's advisory
+ * data. A CVE alone falls back to STO findings when that toolset is enabled.
+ */
+const CVE_IMPACT_SEARCH_PAGE_SIZE = 100;
+const CVE_IMPACT_SEARCH_MAX_PAGES = 10;
+const CVE_IMPACT_MAX_PURL_CHECKS = 50;
+const CVE_IMPACT_MAX_DEPLOYMENT_LOOKUPS = 25;
+const CVE_IMPACT_CONCURRENCY = 4;
+const CVE_ID = /^(CVE-\d{4}-\d{4,}|GHSA(-[23456789cfghjmpqrvwx]{4}){3})$/i;
+
+/** Package name and version from a purl: pkg:npm/%40scope/name@1.0.0 → { name: "@scope/name", version: "1.0.0" }. */
+function purlParts(purl: string): { name: string; version?: string } {
+  const base = normalizePurl(purl);
+  const path = base.replace(/^pkg:[^/]+\//, "");
+  const segments = path.split("/").map((p) => decodeURIComponent(p));
+  const name = segments.length > 1 && segments[0]!.startsWith("@") ? segments.slice(-2).join("/") : segments[segments.length - 1]!;
+  const tail = purl.split(/[?#]/, 1)[0]!;
+  const at = tail.indexOf("@", tail.lastIndexOf("/"));
+  return { name, ...(at !== -1 ? { version: decodeURIComponent(tail.slice(at + 1)) } : {}) };
+}
+
+const errorText = (err: unknown) => (err instanceof Error ? err.message : String(err));
+
+/** Collect hook for scs_cve_impact. */
+async function collectCveImpact(ctx: PreflightContext): Promise<CveImpactScan> {
+  const { client, input, registry, signal } = ctx;
+  const cve = typeof input.cve === "string" && input.cve.trim() ? input.cve.trim().toUpperCase() : undefined;
+  const purl = typeof input.purl === "string" && input.purl.trim() ? input.purl.trim() : undefined;
+  const componentName = typeof input.component === "string" && input.component.trim() ? input.component.trim() : undefined;
+  if (!cve && !purl) throw new Error("Pass cve (e.g. CVE-2024-3094) or purl (e.g. pkg:npm/lodash@4.17.20).");
+  if (cve && !CVE_ID.test(cve)) throw new Error(`cve must be a CVE or GHSA ID such as CVE-2024-3094, got "${cve}".`);
+  const scope = { org_id: input.org_id, project_id: input.project_id };
+  const scan: CveImpactScan = { query: { ...(cve ? { cve } : {}), ...(purl ? { purl } : {}), ...(componentName ? { component: componentName } : {}) }, matches: [], code_repos: [], deployments: {}, errors: [], truncated: false };
+
+  if (cve && !purl && !componentName) {
+    // No package to search the SBOMs for; STO findings name the affected targets.
+    try {
+      const listed = await registry.dispatch(client, "security_issue", "list", { ...scope, search: cve, page: 0, size: CVE_IMPACT_SEARCH_PAGE_SIZE }, signal);
+      const page = isRecord(listed) ? listed.issues ?? listed.items : undefined;
+      scan.sto_issues = (Array.isArray(page) ? page.filter(isRecord) : [])
+        .filter((issue) => JSON.stringify(issue).toUpperCase().includes(cve));
+    } catch (err) {
+      scan.errors.push(`STO findings: ${errorText(err)}`);
+    }
+    return scan;
+  }
+
+  const target = purl ? purlParts(purl) : { name: componentName! };
+  const wantedPurl = purl ? normalizePurl(purl) : undefined;
+  for (let page = 0; page < CVE_IMPACT_SEARCH_MAX_PAGES; page++) {
+    const raw = await registry.dispatch(client, "scs_component_search", "list", { ...scope, search_term: target.name, page, size: CVE_IMPACT_SEARCH_PAGE_SIZE }, signal);
+    const rows = Array.isArray(raw) ? raw.filter(isRecord) : [];
+    for (const row of rows) {
+      const rowPurl = typeof row.purl === "string" ? row.purl : undefined;
+      if (wantedPurl ? !rowPurl || normalizePurl(rowPurl) !== wantedPurl : String(row.name ?? "").toLowerCase() !== target.name.toLowerCase()) continue;
+      if (target.version && row.version !== undefined && String(row.version) !== target.version) continue;
+      scan.matches.push(row);
+    }
+    if (rows.length < CVE_IMPACT_SEARCH_PAGE_SIZE) break;
+    if (page === CVE_IMPACT_SEARCH_MAX_PAGES - 1) scan.truncated = true;
+  }
+
+  if (cve && scan.matches.length > 0) {
+    // Keep only the component versions the CVE is reported against.
+    const purls = [...new Set(scan.matches.map((m) => m.purl).filter((p): p is string => typeof p === "string"))];
+    const checked = purls.slice(0, CVE_IMPACT_MAX_PURL_CHECKS);
+    const { results, errors } = await fanOut(checked, async (p) => {
+      const vulns = await registry.dispatch(client, "scs_component_vulnerability", "list", { purl: p, size: 100 }, signal);
+      return JSON.stringify(vulns ?? "").toUpperCase().includes(cve);
+    }, { concurrency: CVE_IMPACT_CONCURRENCY, signal });
+    const clear = new Set(results.filter((r) => !r.value).map((r) => r.item));
+    for (const e of errors) scan.errors.push(`Vulnerability check for ${e.item}: ${e.error}`);
+    if (purls.length > checked.length) {
+      scan.errors.push(`Checked the first ${checked.length} of ${purls.length} component versions against ${cve}; the rest are listed unverified.`);
+    }
+    scan.verified = { checked: results.length, affected: results.length - clear.size };
+    // Unchecked or failed versions stay in, flagged, rather than silently dropped.
+    scan.matches = scan.matches
+      .filter((m) => typeof m.purl !== "string" || !clear.has(m.purl))
+      .map((m) => (typeof m.purl === "string" && results.some((r) => r.item === m.purl) ? m : { ...m, _unverified: true }));
+  }
+
+  const artifactIds = [...new Set(scan.matches.map((m) => String(m.artifactId ?? "")).filter(Boolean))];
+  const lookups = artifactIds.slice(0, CVE_IMPACT_MAX_DEPLOYMENT_LOOKUPS);
+  const [repos, deployments] = await Promise.all([
+    registry.dispatch(client, "code_repo_security", "list", { ...scope, page: 0, size: CVE_IMPACT_SEARCH_PAGE_SIZE }, signal)
+      .catch((err: unknown) => {
+        scan.errors.push(`Code repositories: ${errorText(err)}`);
+        return [];
+      }),
+    fanOut(lookups, async (artifactId) => {
+      const rows = await registry.dispatch(client, "scs_artifact_deployment", "list", { ...scope, artifact_id: artifactId, page: 0, size: 50 }, signal);
+      return Array.isArray(rows) ? rows.filter(isRecord) : [];
+    }, { concurrency: CVE_IMPACT_CONCURRENCY, signal }),
+  ]);
+  scan.code_repos = Array.isArray(repos) ? repos.filter(isRecord) : [];
+  for (const r of deployments.results) scan.deployments[r.item] = r.value;
+  for (const e of deployments.errors) scan.errors.push(`Deployments for ${e.item}: ${e.error}`);
+  if (artifactIds.length > lookups.length) {
+    scan.errors.push(`Deployments were looked up for the first ${lookups.length} of ${artifactIds.length} artifacts.`);
+  }
+  return scan;
+}
+
+/**
+ * License inventory: every artifact in the project (each source's artifacts
+ * plus the scanned code repositories), read component by component. Bounded
+ * so one call stays within a tool timeout; the scan reports what it skipped.
+ */
+const LICENSE_INVENTORY_PAGE_SIZE = 100;
+const LICENSE_INVENTORY_MAX_PAGES = 5;
+const LICENSE_INVENTORY_MAX_ARTIFACTS = 50;
+const LICENSE_INVENTORY_CONCURRENCY = 4;
+
+/** Rows of a list result, without the trailing `_summary` / `_note` entries some extractors append. */
+const listRows = (raw: unknown): Array<Record<string, unknown>> =>
+  Array.isArray(raw) ? raw.filter(isRecord).filter((r) => !Object.keys(r).some((k) => k.startsWith("_"))) : [];
+
+/** The allow-list from a comma-separated string or an array. */
+function allowListInput(value: unknown): string[] | undefined {
+  const items = Array.isArray(value) ? value.map(String) : typeof value === "string" ? value.split(",") : [];
+  const list = items.map((l) => l.trim()).filter(Boolean);
+  return list.length > 0 ? list : undefined;
+}
+
+/** Collect hook for scs_license_inventory. */
+async function collectLicenseInventory(ctx: PreflightContext): Promise<LicenseInventoryScan> {
+  const { client, input, registry, signal } = ctx;
+  const scope = { org_id: input.org_id, project_id: input.project_id };
+  const allowList = allowListInput(input.allow_list);
+  const errors: string[] = [];
+  const pages = async (resourceType: string, extra: Record<string, unknown>) => {
+    const rows: Array<Record<string, unknown>> = [];
+    for (let page = 0; page < LICENSE_INVENTORY_MAX_PAGES; page++) {
+      const raw = await registry.dispatch(client, resourceType, "list", { ...scope, ...extra, page, size: LICENSE_INVENTORY_PAGE_SIZE }, signal);
+      const batch = listRows(raw);
+      rows.push(...batch);
+      if (batch.length < LICENSE_INVENTORY_PAGE_SIZE) break;
+    }
+    return rows;
+  };
+
+  const targets: InventoryArtifact[] = [];
+  const sourceIds = input.source_id
+    ? [String(input.source_id)]
+    : (await pages("scs_artifact_source", { include_artifacts: false })).map((s) => String(s.source_id ?? s.id ?? "")).filter(Boolean);
+  for (const sourceId of sourceIds) {
+    try {
+      for (const a of await pages("artifact_security", { source_id: sourceId })) {
+        const id = a.artifact_id ?? a.id;
+        if (id === undefined) continue;
+        const name = [a.name, a.tag].filter((v) => typeof v === "string" && v).join(":");
+        targets.push({ artifact_id: String(id), ...(name ? { name } : {}), kind: "artifact", components: [] });
+      }
+    } catch (err) {
+      errors.push(`Artifacts of source ${sourceId}: ${errorText(err)}`);
+    }
+  }
+  if (!input.source_id && input.include_repos !== false && input.include_repos !== "false") {
+    try {
+      for (const r of await pages("code_repo_security", {})) {
+        const id = r.repo_id ?? r.id;
+        if (id === undefined) continue;
+        const name = r.name ?? r.repo_name;
+        targets.push({ artifact_id: String(id), ...(typeof name === "string" ? { name } : {}), kind: "code_repo", components: [] });
+      }
+    } catch (err) {
+      errors.push(`Code repositories: ${errorText(err)}`);
+    }
+  }
+
+  const scanned = targets.slice(0, LICENSE_INVENTORY_MAX_ARTIFACTS);
+  const { results, errors: failures } = await fanOut(scanned, async (target) => {
+    const { components, truncated } = await sbomComponents(ctx, target.artifact_id);
+    return { ...target, components, ...(truncated ? { truncated } : {}) };
+  }, { concurrency: LICENSE_INVENTORY_CONCURRENCY, signal });
+  for (const f of failures) errors.push(`Components of ${f.item.name ?? f.item.artifact_id}: ${f.error}`);
+
+  return {
+    ...(allowList ? { allow_list: allowList } : {}),
+    artifacts: results.map((r) => r.value),
+    artifacts_found: targets.length,
+    artifacts_skipped: targets.length - scanned.length,
+    errors,
+  };
+}
+
+/**
+ * Dependency graph of an artifact: its components, which of them are direct,
+ * and the dependency tree under each direct component. One tree call per
+ * direct dependency, so artifacts with very many are read only in part.
+ */
+const DEPENDENCY_GRAPH_MAX_TREES = 100;
+const DEPENDENCY_GRAPH_CONCURRENCY = 4;
+
+/** Collect hook for scs_dependency_graph. */
+async function collectDependencyGraph(ctx: PreflightContext): Promise<DependencyGraphScan> {
+  const { client, input, registry, signal } = ctx;
+  const artifactId = typeof input.artifact_id === "string" ? input.artifact_id : undefined;
+  if (!artifactId) throw new Error("artifact_id is required. Get it from harness_list(resource_type='artifact_security', source_id='...').");
+  const [all, direct] = await Promise.all([
+    sbomComponents(ctx, artifactId),
+    sbomComponents(ctx, artifactId, { dependency_type: "DIRECT" }),
+  ]);
+  const directIds = [...new Set(direct.components.map((c) => c.purl ?? c.key))];
+  const read = directIds.slice(0, DEPENDENCY_GRAPH_MAX_TREES);
+  const { results, errors } = await fanOut(read, async (purl) => {
+    const rows = await registry.dispatch(client, "scs_component_dependencies", "get", {
+      artifact_id: artifactId,
+      org_id: input.org_id,
+      project_id: input.project_id,
+      purl,
+    }, signal);
+    return listRows(rows);
+  }, { concurrency: DEPENDENCY_GRAPH_CONCURRENCY, signal });
+  return {
+    artifact_id: artifactId,
+    components: all.components,
+    direct: directIds,
+    trees: Object.fromEntries(results.map((r) => [r.item, r.value])),
+    trees_skipped: directIds.length - read.length,
+    truncated: all.truncated || direct.truncated,
+    errors: errors.map((e) => `Dependency tree of ${e.item}: ${e.error}`),
+  };
+}
+
 export const scsToolset: ToolsetDefinition = {
   name: "scs",
   displayName: "Software Supply Chain Assurance",
@@ -356,6 +957,8 @@ export const scsToolset: ToolsetDefinition = {
       listFilterFields: [
         { name: "search_term", description: "Search artifact sources by name" },
         { name: "artifact_type", description: "Filter by artifact type (e.g., CONTAINER, FILE)" },
+        { name: "include_artifacts", description: "Preview each source's artifacts (default true). Set false for a single call.", type: "boolean" },
+        { name: "artifacts_per_source", description: "Artifacts previewed per source (default 5, max 20)", type: "number" },
       ],
       operations: {
         list: {
@@ -372,8 +975,12 @@ export const scsToolset: ToolsetDefinition = {
             ...(input.artifact_type ? { artifact_type: ensureArray(input.artifact_type) } : {}),
           }),
           defaultQueryParams: { limit: "10" },
+          collect: collectArtifactSources,
           responseExtractor: artifactSourceListExtract,
-          description: "List artifact sources in the project",
+          description: "List artifact sources in the project. Each source includes a preview of its latest artifacts "
+            + "(artifacts[], up to artifacts_per_source, default 5, max 20). Sources whose artifacts could not be listed keep "
+            + "their row with artifacts_error, and _summary.enrichment counts them. Pass include_artifacts=false to skip the "
+            + "per-source calls on accounts with many registries.",
         },
       },
     },
@@ -606,6 +1213,43 @@ export const scsToolset: ToolsetDefinition = {
           description: "Get dependency tree for a component by PURL",
           paramsSchema: filterFieldsToParamsSchema([
             { name: "purl", description: "Package URL of the component (e.g. pkg:npm/express@4.18.0) — required", required: true },
+            { name: "format", description: "'mermaid' to return { dependencies, mermaid } with a ready-to-render Mermaid flowchart of the tree" },
+          ]),
+        },
+      },
+    },
+
+    // ── Artifact Dependency Graph ──────────────────────────────────────
+    {
+      resourceType: "scs_dependency_graph",
+      displayName: "Artifact Dependency Graph",
+      description: "Full dependency graph of an artifact's SBOM as JSON: nodes (one per component, with version, license, vulnerability count, and whether it is a direct dependency) "
+        + "and edges (\"direct\" from the artifact to its direct dependencies, \"transitive\" between components). "
+        + "Pass purl (with or without version) for its blast radius: the direct dependencies that pull it in, every component that depends on it, and the root-to-component paths. "
+        + "Unlike scs_component_dependencies, which shows what one component depends on, this covers the whole artifact and answers reverse questions (what pulls X in?).",
+      diagnosticHint: "If you get a 404: verify artifact_id is correct. Get artifact IDs from harness_list(resource_type='artifact_security', source_id='...'). "
+        + `The graph reads the dependency trees of at most ${DEPENDENCY_GRAPH_MAX_TREES} direct dependencies; components under the rest appear without edges.`,
+      searchAliases: ["dependency graph", "blast radius", "what pulls in", "reverse dependencies", "who depends on", "sbom graph", "export dependency graph"],
+      relatedResources: [
+        { resourceType: "artifact_security", relationship: "parent", description: "Get artifact_id for the graph" },
+        { resourceType: "scs_component_dependencies", relationship: "child", description: "Dependency tree of a single component" },
+        { resourceType: "scs_component_remediation", relationship: "sibling", description: "Upgrade advice for a direct dependency listed under blast_radius.introduced_by" },
+      ],
+      toolset: "scs",
+      scope: "project",
+      identifierFields: ["artifact_id"],
+      operations: {
+        get: {
+          method: "POST",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifacts/{artifact}/components`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project", artifact_id: "artifact" },
+          collect: collectDependencyGraph,
+          responseExtractor: scsDependencyGraphExtract,
+          skipCompact: true,
+          description: "Export an artifact's dependency graph with direct and transitive edges, and optionally a component's blast radius.",
+          paramsSchema: filterFieldsToParamsSchema([
+            { name: "purl", description: "Component to compute the blast radius for (e.g. pkg:npm/qs@6.11.0, or pkg:npm/qs for every version)" },
           ]),
         },
       },
@@ -676,6 +1320,47 @@ export const scsToolset: ToolsetDefinition = {
       },
     },
 
+    // ── Artifact Deployments ───────────────────────────────────────────
+    {
+      resourceType: "scs_artifact_deployment",
+      displayName: "SCS Artifact Deployment",
+      description: "Environments and pipelines an artifact has been deployed to, with environment type (prod / non-prod) and the last deployment time. Supports list.",
+      diagnosticHint: "If you get a 404: verify artifact_id is correct. Get artifact IDs from harness_list(resource_type='artifact_security', source_id='...'). "
+        + "artifactId values from scs_component_search are search-index IDs and may not resolve here.",
+      searchAliases: ["artifact deployments", "where is this artifact deployed", "deployed environments"],
+      relatedResources: [
+        { resourceType: "artifact_security", relationship: "parent", description: "Get artifact_id needed for deployment lookups" },
+        { resourceType: "scs_cve_impact", relationship: "sibling", description: "Artifacts affected by a CVE or package, with their deployments" },
+      ],
+      toolset: "scs",
+      scope: "project",
+      identifierFields: ["artifact_id"],
+      listFilterFields: [
+        { name: "artifact_id", description: "Artifact ID (get from harness_list resource_type=artifact_security)", required: true },
+        { name: "environment_type", description: "Only deployments to this environment type", enum: ["Production", "PreProduction"] },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifacts/{artifact}/deployments`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project", artifact_id: "artifact" },
+          queryParams: {
+            page: "page",
+            size: "limit",
+          },
+          bodyBuilder: (input) => ({
+            ...(input.environment_type ? { env_type: input.environment_type } : {}),
+          }),
+          defaultQueryParams: { limit: "10" },
+          responseExtractor: scsListExtract([
+            "env_id", "env_name", "env_type", "pipeline_id", "pipeline_execution_id", "triggered_by", "triggered_at", "deployed_at",
+          ]),
+          description: "List the environments an artifact is deployed to",
+        },
+      },
+    },
+
     // ── Compliance Results ─────────────────────────────────────────────
     {
       resourceType: "scs_compliance_result",
@@ -1482,12 +2167,133 @@ export const scsToolset: ToolsetDefinition = {
       },
     },
 
+    // ── SBOM Comparison (any two artifact versions) ────────────────────
+    {
+      resourceType: "scs_sbom_comparison",
+      displayName: "SBOM Comparison",
+      description: "Compare the SBOMs of any two artifact versions for release gating: components added, removed, upgraded, or downgraded, "
+        + "licenses introduced or dropped, and the change in known vulnerabilities with the components that brought them in. "
+        + "Each side is an artifact ID (base_artifact_id, target_artifact_id) or an orchestration ID with source_id (base_orchestration_id, target_orchestration_id). "
+        + "Use scs_sbom_drift instead to compare one SBOM run against the previous run or the pinned baseline.",
+      diagnosticHint: "Get artifact IDs and orchestration.id values from harness_list(resource_type='artifact_security', source_id='...'). "
+        + "An orchestration ID only resolves while it is the artifact's latest SBOM run; otherwise pass the artifact ID.",
+      searchAliases: ["compare sboms", "sbom compare", "release diff", "compare artifact versions", "license delta", "vulnerability delta"],
+      relatedResources: [
+        { resourceType: "artifact_security", relationship: "parent", description: "Get artifact IDs and orchestration IDs for both versions" },
+        { resourceType: "scs_sbom_drift", relationship: "sibling", description: "Server-side drift of one SBOM run against its previous run or baseline" },
+        { resourceType: "scs_component_vulnerability", relationship: "child", description: "CVE details for a component listed under introduced_by (pass purl)" },
+      ],
+      toolset: "scs",
+      scope: "project",
+      identifierFields: ["target_artifact_id"],
+      operations: {
+        get: {
+          method: "POST",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifacts/{artifact}/components`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project", target_artifact_id: "artifact" },
+          collect: compareSboms,
+          responseExtractor: scsSbomComparisonExtract,
+          skipCompact: true,
+          description: "Diff two artifact versions' SBOMs: added/removed/upgraded/downgraded components, license and vulnerability deltas.",
+          paramsSchema: {
+            fields: [
+              { name: "base_artifact_id", required: false, description: "Older artifact version (the baseline)" },
+              { name: "target_artifact_id", required: false, description: "Newer artifact version (the release candidate)" },
+              { name: "base_orchestration_id", required: false, description: "Orchestration ID of the baseline, instead of base_artifact_id (needs source_id)" },
+              { name: "target_orchestration_id", required: false, description: "Orchestration ID of the candidate, instead of target_artifact_id (needs source_id)" },
+              { name: "source_id", required: false, description: "Artifact source to resolve orchestration IDs in" },
+            ],
+          } satisfies ParamsSchema,
+        },
+      },
+    },
+
+    // ── CVE Impact (which artifacts ship a vulnerable package) ─────────
+    {
+      resourceType: "scs_cve_impact",
+      displayName: "CVE Impact",
+      description: "Which artifacts (container images and code repositories) in the project contain a vulnerable package, and where those artifacts are deployed. "
+        + "Pass purl (e.g. pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1) to match that package and version, "
+        + "or cve plus component (the package name) to find every version of the package and keep only those the CVE is reported against. "
+        + "A cve alone cannot be searched in SBOMs, so it returns matching STO findings instead (needs the sto toolset). "
+        + "One call replaces scs_component_search → scs_component_vulnerability → scs_artifact_deployment for each hit.",
+      diagnosticHint: "If nothing matches: check the package name in the purl or component (the search is a case-insensitive prefix match on the name), "
+        + "and that the artifacts have SBOMs. Entries flagged _unverified were not checked against the CVE; confirm with scs_component_vulnerability.",
+      searchAliases: ["find artifacts with cve", "cve impact", "affected artifacts", "blast radius", "which images have", "log4shell", "where is this vulnerability deployed"],
+      relatedResources: [
+        { resourceType: "scs_component_search", relationship: "sibling", description: "Raw component search by name across all artifacts" },
+        { resourceType: "scs_component_vulnerability", relationship: "child", description: "CVE details for an affected component (pass purl)" },
+        { resourceType: "scs_artifact_deployment", relationship: "child", description: "Deployments of an affected artifact" },
+        { resourceType: "security_issue", relationship: "sibling", description: "STO findings for the CVE" },
+      ],
+      toolset: "scs",
+      scope: "project",
+      identifierFields: [],
+      listFilterFields: [
+        { name: "cve", description: "CVE or GHSA ID, e.g. CVE-2021-44228" },
+        { name: "purl", description: "Package URL; with a version, only that version matches" },
+        { name: "component", description: "Package name to check against cve when no purl is given, e.g. log4j-core" },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/components/search`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project" },
+          collect: collectCveImpact,
+          responseExtractor: scsCveImpactExtract,
+          skipCompact: true,
+          description: "Find artifacts, code repositories, and deployments affected by a CVE or package",
+        },
+      },
+    },
+
+    // ── License Inventory ──────────────────────────────────────────────
+    {
+      resourceType: "scs_license_inventory",
+      displayName: "License Inventory",
+      description: "License usage across every artifact and code repository in the project: component and artifact counts per license. "
+        + "Pass allow_list (SPDX IDs, e.g. 'MIT,Apache-2.0,BSD-3-Clause') to also get the artifacts with components outside it; "
+        + "SPDX expressions are honored, so 'MIT OR GPL-3.0' passes an allow-list containing MIT. "
+        + "Use it to check an allow-list against what is already shipped before enforcing it with an SBOM policy (governance toolset, resource_type='policy').",
+      diagnosticHint: `Reads at most ${LICENSE_INVENTORY_MAX_ARTIFACTS} artifacts per call. For larger projects pass source_id to inventory one artifact source at a time, `
+        + "or include_repos=false to skip code repositories.",
+      searchAliases: ["license inventory", "license report", "license usage", "license compliance", "allow list check", "gpl usage"],
+      relatedResources: [
+        { resourceType: "scs_artifact_source", relationship: "parent", description: "Get source_id to narrow the inventory" },
+        { resourceType: "scs_artifact_component", relationship: "child", description: "Components and licenses of one violating artifact" },
+        { resourceType: "policy", relationship: "sibling", description: "SBOM enforcement policy (type 'sbom_enforcement') that enforces the allow-list" },
+      ],
+      toolset: "scs",
+      scope: "project",
+      identifierFields: [],
+      listFilterFields: [
+        { name: "allow_list", description: "Allowed licenses, comma-separated SPDX IDs (e.g. MIT,Apache-2.0)" },
+        { name: "source_id", description: "Only this artifact source's artifacts (code repositories are skipped)" },
+        { name: "include_repos", description: "Include code repositories (default true)", type: "boolean" },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifact-sources`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project" },
+          collect: collectLicenseInventory,
+          responseExtractor: scsLicenseInventoryExtract,
+          skipCompact: true,
+          description: "Count license usage across the project's artifacts and check it against an allow-list",
+        },
+      },
+    },
+
     // ── SBOM Download ──────────────────────────────────────────────────
     {
       resourceType: "scs_sbom",
       displayName: "SBOM",
       description: "Software Bill of Materials download. Requires an orchestration ID (from artifact chain of custody). "
-        + "Use this to download the full SBOM for an artifact build.",
+        + "get returns the download link; harness_execute(action='download') fetches the SBOM server-side and returns its format, component count, and top licenses, "
+        + "optionally writing the document to output_dir.",
       diagnosticHint: "If you get a 404: verify orchestration_id is correct. Get orchestration IDs from harness_get(resource_type='scs_chain_of_custody', artifact_id='...').",
       searchAliases: ["sbom", "software bill of materials", "bom", "sbom download"],
       relatedResources: [
@@ -1507,6 +2313,113 @@ export const scsToolset: ToolsetDefinition = {
           description: "Get SBOM download URL for an orchestration run",
         },
       },
+      executeActions: {
+        download: {
+          method: "GET",
+          path: `${SCS}/v1/org/{org}/project/{project}/orchestration/{orchestrationId}/sbom-download`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project", orchestration_id: "orchestrationId" },
+          collect: downloadSbom,
+          responseExtractor: scsSbomDownloadExtract,
+          actionDescription: "Fetch the SBOM server-side and return a summary: format and spec version, generating tool, component count, and top licenses. "
+            + "Pass output_dir to also write the document to <output_dir>/sbom-<orchestration_id>.<json|xml|spdx> on the MCP server host.",
+          bodySchema: {
+            description: "Download options",
+            fields: [
+              { name: "output_dir", type: "string", required: false, description: "Absolute directory on the MCP server host to write the SBOM to. ~ and (on Windows) %VAR% are expanded. Created if missing. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it." },
+            ],
+          },
+        },
+      },
+    },
+
+    // ── VEX Statements ─────────────────────────────────────────────────
+    {
+      resourceType: "scs_vex_statement",
+      displayName: "VEX Statement",
+      description: "Vulnerability Exploitability eXchange (VEX) statements: whether an artifact is affected by a vulnerability, and why. "
+        + "List the statements recorded for an artifact, record a new one with harness_create, or preview it as an OpenVEX document with harness_execute(action='generate'). "
+        + "Status is one of not_affected (with a justification such as vulnerable_code_not_in_execute_path, or an impact_statement), "
+        + "affected (with an action_statement), fixed, or under_investigation. "
+        + "Only record not_affected when the user has confirmed the reason — a VEX statement tells downstream consumers they can ignore the CVE.",
+      diagnosticHint: "If you get a 404: verify artifact_id with harness_list(resource_type='artifact_security'). "
+        + "Get vulnerability IDs and component purls from harness_list(resource_type='scs_component_vulnerability', purl='...') or scs_artifact_component.",
+      searchAliases: ["vex", "openvex", "exploitability", "not affected", "vulnerability exploitability exchange", "csaf vex"],
+      relatedResources: [
+        { resourceType: "artifact_security", relationship: "parent", description: "Get artifact_id for the artifact the statement covers" },
+        { resourceType: "scs_component_vulnerability", relationship: "sibling", description: "CVE details for the component the statement is about" },
+        { resourceType: "security_exemption", relationship: "sibling", description: "STO exemptions suppress findings in scans; VEX statements are published to artifact consumers" },
+      ],
+      toolset: "scs",
+      scope: "project",
+      identifierFields: ["artifact_id"],
+      listFilterFields: [
+        { name: "artifact_id", description: "Artifact ID the statements cover", required: true },
+        { name: "vulnerability_id", description: "Only statements for this vulnerability (e.g. CVE-2024-3094)" },
+        { name: "status", description: "Only statements with this status", enum: VEX_STATUSES },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifacts/{artifact}/vex-statements`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { org_id: "org", project_id: "project", artifact_id: "artifact" },
+          queryParams: {
+            vulnerability_id: "vulnerability_id",
+            status: "status",
+            page: "page",
+            size: "limit",
+          },
+          defaultQueryParams: { limit: "10" },
+          responseExtractor: scsListExtract(VEX_STATEMENT_LIST_FIELDS),
+          description: "List VEX statements recorded for an artifact",
+        },
+        create: {
+          method: "POST",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifacts/{artifact}/vex-statements`,
+          operationPolicy: { risk: "medium_write", retryPolicy: "do_not_retry" },
+          pathParams: { org_id: "org", project_id: "project", artifact_id: "artifact" },
+          bodyBuilder: vexStatementBody,
+          responseExtractor: scsCleanExtract,
+          description: "Record a VEX statement for a vulnerability on an artifact. Body fields: vulnerability_id, status (required); justification or impact_statement for not_affected; action_statement for affected; subcomponents (component purls).",
+          bodySchema: {
+            description: "VEX statement",
+            fields: [
+              { name: "vulnerability_id", type: "string", required: true, description: "Vulnerability ID (CVE, GHSA, ...)" },
+              { name: "status", type: "string", required: true, description: "not_affected, affected, fixed, or under_investigation" },
+              { name: "justification", type: "string", required: false, description: `For not_affected: ${VEX_JUSTIFICATIONS.join(", ")}` },
+              { name: "impact_statement", type: "string", required: false, description: "For not_affected: free-text reason the vulnerability does not apply" },
+              { name: "action_statement", type: "string", required: false, description: "For affected: what consumers should do" },
+              { name: "subcomponents", type: "array", required: false, description: "PURLs of the vulnerable components inside the artifact" },
+              { name: "product", type: "string", required: false, description: "Artifact reference (PURL or image); defaults to the artifact in the path" },
+            ],
+          },
+        },
+      },
+      executeActions: {
+        generate: {
+          method: "POST",
+          path: `${SCS}/v1/orgs/{org}/projects/{project}/artifacts/{artifact}/vex-statements`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: generateOpenVexDocument,
+          responseExtractor: scsCleanExtract,
+          actionDescription: "Generate an OpenVEX v0.2.0 document for a vulnerability/artifact pair without recording it. "
+            + "Takes the same body as create plus product (required, the artifact as a PURL or image reference) and optional author.",
+          bodySchema: {
+            description: "VEX statement to render",
+            fields: [
+              { name: "vulnerability_id", type: "string", required: true, description: "Vulnerability ID (CVE, GHSA, ...)" },
+              { name: "product", type: "string", required: true, description: "The artifact, as a PURL or image reference (e.g. pkg:oci/api@sha256:...)" },
+              { name: "status", type: "string", required: true, description: "not_affected, affected, fixed, or under_investigation" },
+              { name: "justification", type: "string", required: false, description: `For not_affected: ${VEX_JUSTIFICATIONS.join(", ")}` },
+              { name: "impact_statement", type: "string", required: false, description: "For not_affected: free-text reason" },
+              { name: "action_statement", type: "string", required: false, description: "For affected: what consumers should do" },
+              { name: "subcomponents", type: "array", required: false, description: "PURLs of the vulnerable components inside the artifact" },
+              { name: "author", type: "string", required: false, description: "Document author (default: the Harness account)" },
+            ],
+          },
+        },
+      },
     },
   ],
 };
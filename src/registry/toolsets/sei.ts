@@ -1,6 +1,6 @@
-import type { ResourceDefinition, ToolsetDefinition, FilterFieldSpec, ParamsSchema } from "../types.js";
+import type { ResourceDefinition, ToolsetDefinition, FilterFieldSpec, ParamsSchema, PreflightContext } from "../types.js";
 import type { PathBuilderConfig } from "../types.js";
-import { passthrough } from "../extractors.js";
+import { passthrough, seiAiCorrelationExtract, seiIntegrationHealthExtract, seiSprintMetricExtract, seiTeamListExtract, type SeiAiCorrelationScan } from "../extractors.js";
 
 /** SEI base path */
 const SEI = "/gateway/sei/api";
@@ -12,6 +12,7 @@ const AI_DEEP_LINK = "/ng/account/{accountId}/module/sei/insights/ai-coding";
 const ORG_TREE_DEEP_LINK = "/ng/account/{accountId}/module/sei/configuration/org-trees";
 const BA_DEEP_LINK = "/ng/account/{accountId}/module/sei/insights/business-alignment";
 const TEAMS_DEEP_LINK = "/ng/account/{accountId}/module/sei/configuration/teams";
+const INTEGRATIONS_DEEP_LINK = "/ng/account/{accountId}/module/sei/configuration/integrations";
 
 // ─── Shared filter field sets ─────────────────────────────────────────────────
 
@@ -34,6 +35,19 @@ const BA_LIST_FILTER_FIELDS: FilterFieldSpec[] = [
   { name: "date_end", description: "End date (YYYY-MM-DD)" },
 ];
 
+const SPRINT_FILTER_FIELDS: FilterFieldSpec[] = [
+  {
+    name: "aspect",
+    description: "Which sprint analysis to fetch",
+    enum: ["velocity", "carry_over", "burndown"],
+  },
+  { name: "team_ref_id", description: "Team reference identifier (use sei_team list to find)", required: true },
+  { name: "profile_id", description: "Business alignment profile ID" },
+  { name: "date_start", description: "Start date (YYYY-MM-DD); sprints ending in the range are included" },
+  { name: "date_end", description: "End date (YYYY-MM-DD)" },
+  { name: "sprint_id", description: "Sprint for aspect=burndown (default: the team's active sprint)" },
+];
+
 const BA_GET_FILTER_FIELDS: FilterFieldSpec[] = [
   {
     name: "aspect",
@@ -68,12 +82,14 @@ const PRODUCTIVITY_GET_PARAMS = filterFieldsToParamsSchema([
   { name: "team_ref_id", description: "Team reference identifier" },
   { name: "date_start", description: "Start date (YYYY-MM-DD)" },
   { name: "date_end", description: "End date (YYYY-MM-DD)" },
-  { name: "feature_type", description: "Productivity feature type", enum: ["PR_VELOCITY"] },
+  { name: "feature_type", description: "Productivity feature type", enum: ["PR_VELOCITY", "PR_CYCLE_TIME", "PR_REVIEW_TIME"] },
   { name: "granularity", description: "Time granularity", enum: ["WEEKLY", "MONTHLY"] },
 ]);
 
 const DORA_GET_PARAMS = filterFieldsToParamsSchema(DORA_FILTER_FIELDS);
 
+const SPRINT_GET_PARAMS = filterFieldsToParamsSchema(SPRINT_FILTER_FIELDS);
+
 const AI_IMPACT_GET_PARAMS = filterFieldsToParamsSchema([
   {
     name: "aspect",
@@ -110,6 +126,14 @@ function baBuildBody(input: Record<string, unknown>) {
   };
 }
 
+function sprintBuildBody(input: Record<string, unknown>) {
+  if (!input.team_ref_id) throw new Error("team_ref_id is required for sei_sprint_metric. Use harness_list(resource_type='sei_team') to find it.");
+  return {
+    ...baBuildBody(input),
+    ...(input.sprint_id ? { sprintId: input.sprint_id } : {}),
+  };
+}
+
 function parseTeamRefId(value: unknown): number | undefined {
   if (value === undefined || value === null || value === "") return undefined;
   const n = typeof value === "string" ? parseInt(value, 10) : Number(value);
@@ -148,6 +172,11 @@ function doraPathBuilder(input: Record<string, unknown>, _config: PathBuilderCon
   return `${SEI}/v2/insights/efficiency/${suffix}`;
 }
 
+function teamListPathBuilder(input: Record<string, unknown>, _config: PathBuilderConfig): string {
+  const orgTreeId = input.org_tree_id as string | undefined;
+  return orgTreeId ? `${SEI}/v2/org-trees/${encodeURIComponent(orgTreeId)}/teams` : `${SEI}/v2/teams/list`;
+}
+
 function teamDetailPathBuilder(input: Record<string, unknown>, _config: PathBuilderConfig): string {
   const teamRefId = input.team_ref_id as string;
   if (!teamRefId) throw new Error("team_ref_id is required for sei_team_detail");
@@ -189,6 +218,12 @@ function baPathBuilder(input: Record<string, unknown>, _config: PathBuilderConfi
   return `${SEI}/v2/insights/ba/${suffix}`;
 }
 
+function sprintPathBuilder(input: Record<string, unknown>, _config: PathBuilderConfig): string {
+  // Velocity and carry-over both come from the per-sprint summary.
+  const suffix = input.aspect === "burndown" ? "sprint_burndown" : "sprint_metrics";
+  return `${SEI}/v2/insights/ba/${suffix}`;
+}
+
 function aiUsagePathBuilder(input: Record<string, unknown>, _config: PathBuilderConfig): string {
   const aspect = (input.aspect as string) || "metrics";
   const suffix =
@@ -219,6 +254,47 @@ function aiImpactPathBuilder(input: Record<string, unknown>, _config: PathBuilde
   return `${SEI}/v2/insights/coding-assistant/${suffix}`;
 }
 
+// ─── AI / delivery correlation ────────────────────────────────────────────────
+
+/** Series joined by sei_ai_delivery_correlation; durations improve as they fall. */
+const AI_CORRELATION_ADOPTION_KEYS = ["adoptionRate", "adoptionPercentage", "activeUsers", "adoptedUsers", "value", "count"];
+const AI_CORRELATION_DELIVERY = [
+  { name: "pr_velocity", featureType: "PR_VELOCITY", valueKeys: ["prsMerged", "mergedPrs", "count", "value"] },
+  { name: "pr_cycle_time", featureType: "PR_CYCLE_TIME", valueKeys: ["median", "average", "avg", "mean", "value", "duration"], lowerIsBetter: true },
+  { name: "pr_review_time", featureType: "PR_REVIEW_TIME", valueKeys: ["median", "average", "avg", "mean", "value", "duration"], lowerIsBetter: true },
+] as const;
+
+/**
+ * Collect hook for sei_ai_delivery_correlation: AI adoption and each delivery
+ * metric for the same team, period, and granularity, read in parallel. A
+ * series that fails is reported and left out of the join.
+ */
+async function collectAiCorrelation({ client, input, registry, signal }: PreflightContext): Promise<SeiAiCorrelationScan> {
+  if (!input.team_ref_id || !input.date_start || !input.date_end) {
+    throw new Error("team_ref_id, date_start, and date_end are required for sei_ai_delivery_correlation. Use harness_list(resource_type='sei_team', filters={search:'<team name>'}) to find the team.");
+  }
+  const granularity = input.granularity === "MONTHLY" ? "MONTHLY" : "WEEKLY";
+  const shared = { team_ref_id: input.team_ref_id, date_start: input.date_start, date_end: input.date_end, granularity };
+  const settle = (p: Promise<unknown>) => p.then((value) => ({ value }), (err: unknown) => ({ error: err instanceof Error ? err.message : String(err) }));
+
+  const [adoption, ...delivery] = await Promise.all([
+    settle(registry.dispatch(client, "sei_ai_adoption", "get", {
+      ...shared,
+      aspect: "metrics",
+      integration_type: input.integration_type ?? "all_assistants",
+    }, signal)),
+    ...AI_CORRELATION_DELIVERY.map((m) => settle(registry.dispatch(client, "sei_productivity_metric", "get", { ...shared, feature_type: m.featureType }, signal))),
+  ]);
+  const series = [
+    { name: "ai_adoption", valueKeys: AI_CORRELATION_ADOPTION_KEYS, result: adoption! },
+    ...AI_CORRELATION_DELIVERY.map((m, i) => ({ name: m.name, valueKeys: [...m.valueKeys], lowerIsBetter: "lowerIsBetter" in m, result: delivery[i]! })),
+  ];
+  return {
+    granularity,
+    series: series.map(({ result, ...meta }) => ("error" in result ? { ...meta, error: result.error } : { ...meta, raw: result.value })),
+  };
+}
+
 // ─── Toolset Definition ───────────────────────────────────────────────────────
 
 export const seiToolset: ToolsetDefinition = {
@@ -286,6 +362,56 @@ export const seiToolset: ToolsetDefinition = {
       },
     },
 
+    // ─── Developer-level Metrics (privacy-gated) ──────────────────────────────
+    {
+      resourceType: "sei_developer_metric",
+      displayName: "SEI Developer Metric",
+      description:
+        "Individual-contributor productivity metrics (e.g. PR velocity per developer) for coaching insights. Supports list. Governed by server privacy settings: with HARNESS_SEI_DEVELOPER_METRICS=aggregate (default) results are team-level distributions (min, p25, median, p75, max, mean) with no names, and groups under HARNESS_SEI_MIN_GROUP_SIZE developers are suppressed; with individual, per-developer rows are returned; with off, this resource is unavailable.",
+      toolset: "sei",
+      scope: "project",
+      headerBasedScoping: true,
+      developerMetrics: true,
+      identifierFields: [],
+      searchAliases: ["developer metrics", "individual contributor", "per developer", "coaching"],
+      listFilterFields: [
+        { name: "team_ref_id", description: "Team reference identifier (use sei_team list to find)", required: true },
+        { name: "date_start", description: "Start date (YYYY-MM-DD)", required: true },
+        { name: "date_end", description: "End date (YYYY-MM-DD)", required: true },
+        { name: "feature_type", description: "Productivity feature type (default PR_VELOCITY)" },
+        { name: "developer_ids", description: "Limit to these developer IDs. In aggregate mode a group this small is usually suppressed." },
+        { name: "granularity", description: "Time granularity", enum: ["WEEKLY", "MONTHLY"] },
+      ],
+      deepLinkTemplate: "/ng/account/{accountId}/module/sei/insights/productivity",
+      relatedResources: [
+        { resourceType: "sei_productivity_metric", relationship: "aggregates", description: "The same feature metrics for the whole team." },
+        { resourceType: "sei_team_detail", relationship: "related", description: "The team's developers (aspect='developers')." },
+      ],
+      operations: {
+        list: {
+          method: "POST",
+          path: `${SEI}/v2/productivityv3/feature_metrics`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: (input) => {
+            const refId = parseTeamRefId(input.team_ref_id);
+            return {
+              startDate: input.date_start,
+              endDate: input.date_end,
+              featureType: input.feature_type ?? "PR_VELOCITY",
+              granularity: input.granularity ?? "WEEKLY",
+              stackBy: "DEVELOPER",
+              ...(refId !== undefined ? { teamRefIds: [refId] } : {}),
+              ...(input.developer_ids ? { developerIds: input.developer_ids } : {}),
+              ...(input.page !== undefined ? { page: input.page } : {}),
+              ...(input.size !== undefined ? { pageSize: input.size } : {}),
+            };
+          },
+          responseExtractor: passthrough,
+          description: "List productivity feature metrics broken down by developer for a team, subject to the server's developer privacy mode",
+        },
+      },
+    },
+
     // ─── DORA Metrics (consolidated: 6 → 1) ─────────────────────────────────────
     {
       resourceType: "sei_dora_metric",
@@ -315,19 +441,32 @@ export const seiToolset: ToolsetDefinition = {
     {
       resourceType: "sei_team",
       displayName: "SEI Team",
-      description: "SEI team entity. Supports list and get.",
+      description:
+        "SEI team entity. Supports list and get. Start here to resolve a team name to the team_ref_id that SEI metrics " +
+        "(DORA, productivity, sprint, AI insights) require: list with search='<team name>'. Pass org_tree_id to walk one org tree's team hierarchy; " +
+        "each row carries team_ref_id, name, and the path of enclosing teams.",
       toolset: "sei",
       scope: "account",
       headerBasedScoping: true,
       identifierFields: ["team_ref_id"],
+      searchAliases: ["sei teams", "team ref id", "find team", "collections"],
+      listFilterFields: [
+        { name: "search", description: "Match team names (and enclosing team names) containing every word, case-insensitive" },
+        { name: "org_tree_id", description: "List the teams of this org tree, with their hierarchy (use sei_org_tree list to find)" },
+      ],
+      relatedResources: [
+        { resourceType: "sei_org_tree", relationship: "parent", description: "Get org_tree_id to browse one org tree's teams" },
+        { resourceType: "sei_team_detail", relationship: "child", description: "A team's integrations and developers" },
+      ],
       deepLinkTemplate: TEAMS_DEEP_LINK,
       operations: {
         list: {
           method: "GET",
           path: `${SEI}/v2/teams/list`,
+          pathBuilder: teamListPathBuilder,
           operationPolicy: { risk: "read", retryPolicy: "safe" },
-          responseExtractor: passthrough,
-          description: "List SEI teams",
+          responseExtractor: seiTeamListExtract,
+          description: "List SEI teams with their team_ref_id. Pass search to find a team by name, or org_tree_id for one org tree's teams.",
         },
         get: {
           method: "GET",
@@ -371,6 +510,47 @@ export const seiToolset: ToolsetDefinition = {
       },
     },
 
+    // ─── Integrations ─────────────────────────────────────────────────────────
+    {
+      resourceType: "sei_integration",
+      displayName: "SEI Integration",
+      description:
+        "SEI integrations (Jira, GitHub, GitLab, Bitbucket, Harness CD, ...) with their ingestion health: status, last ingestion time, error count, " +
+        "and health (failing | stale | unknown | healthy), problems listed first. Supports list and get. " +
+        "Check this first when SEI metrics come back empty or out of date — a failing or stale integration starves every metric built on it.",
+      toolset: "sei",
+      scope: "account",
+      headerBasedScoping: true,
+      identifierFields: ["integration_id"],
+      searchAliases: ["sei integrations", "ingestion", "ingestion status", "integration health", "empty metrics", "data not showing"],
+      listFilterFields: [
+        { name: "application", description: "Integration type (e.g. jira, github, gitlab, bitbucket, harnessng)" },
+        { name: "health", description: "Only integrations in this health state", enum: ["failing", "stale", "unknown", "healthy"] },
+      ],
+      relatedResources: [
+        { resourceType: "sei_team_detail", relationship: "related", description: "Which integrations a team's metrics use (aspect='integrations')" },
+      ],
+      deepLinkTemplate: INTEGRATIONS_DEEP_LINK,
+      operations: {
+        list: {
+          method: "GET",
+          path: `${SEI}/v2/integrations`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: { application: "application", page: "page", size: "pageSize" },
+          responseExtractor: seiIntegrationHealthExtract,
+          description: "List SEI integrations with ingestion health, failing and stale first. Pass health to keep one state.",
+        },
+        get: {
+          method: "GET",
+          path: `${SEI}/v2/integrations/{integrationId}`,
+          pathParams: { integration_id: "integrationId" },
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          responseExtractor: seiIntegrationHealthExtract,
+          description: "Get one SEI integration and its ingestion health",
+        },
+      },
+    },
+
     // ─── Org Trees ────────────────────────────────────────────────────────────
     {
       resourceType: "sei_org_tree",
@@ -469,6 +649,41 @@ export const seiToolset: ToolsetDefinition = {
       },
     },
 
+    // ─── Sprint Metrics (velocity | carry_over | burndown) ──────────────────────
+    {
+      resourceType: "sei_sprint_metric",
+      displayName: "SEI Sprint Metric",
+      description:
+        "Sprint delivery analysis for a team from SEI business alignment sprint data. harness_get with aspect: " +
+        "velocity (completed work per sprint, say/do ratio, and whether velocity is rising, falling, or flat) | " +
+        "carry_over (work carried into the next sprint as a share of committed work, flagging overcommitted sprints) | " +
+        "burndown (remaining work in one sprint against the ideal line: ahead, on_track, or behind). " +
+        "Pass team_ref_id, date_start, date_end; sprint_id for burndown (default: active sprint).",
+      toolset: "sei",
+      scope: "project",
+      headerBasedScoping: true,
+      identifierFields: [],
+      searchAliases: ["sprint", "velocity", "burndown", "carry over", "spillover", "say do ratio", "sprint predictability"],
+      relatedResources: [
+        { resourceType: "sei_team", relationship: "parent", description: "Get team_ref_id" },
+        { resourceType: "sei_business_alignment", relationship: "related", description: "Where the team's effort went (profile_id)" },
+        { resourceType: "sei_productivity_metric", relationship: "related", description: "PR velocity for the same team" },
+      ],
+      deepLinkTemplate: BA_DEEP_LINK,
+      operations: {
+        get: {
+          method: "POST",
+          path: `${SEI}/v2/insights/ba/sprint_metrics`,
+          pathBuilder: sprintPathBuilder,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          bodyBuilder: sprintBuildBody,
+          responseExtractor: seiSprintMetricExtract,
+          description: "Get a team's sprint velocity trend, carry-over, or burndown. Pass aspect (velocity|carry_over|burndown), team_ref_id, date_start, date_end, and sprint_id for burndown.",
+          paramsSchema: SPRINT_GET_PARAMS,
+        },
+      },
+    },
+
     // ─── AI Coding Insights (consolidated: 11 → 4) ─────────────────────────────
     // sei_ai_usage: metrics | breakdown | summary | top_languages
     {
@@ -577,15 +792,55 @@ export const seiToolset: ToolsetDefinition = {
         },
       },
     },
-    // sei_ai_raw_metric: per-developer raw metrics (unchanged)
+    // sei_ai_delivery_correlation: AI adoption joined with PR delivery metrics
+    {
+      resourceType: "sei_ai_delivery_correlation",
+      displayName: "SEI AI Delivery Correlation",
+      description:
+        "Is AI coding assistant adoption improving delivery? Joins a team's AI adoption with PR velocity, PR cycle time, and PR review time " +
+        "per week (or month) over the same period, and reports the correlation of adoption with each (r, strength, and whether it improves or worsens). " +
+        "Supports get. Pass team_ref_id, date_start, date_end; optionally integration_type and granularity. Correlation is not causation, and few periods mean weak evidence.",
+      toolset: "sei",
+      scope: "project",
+      headerBasedScoping: true,
+      identifierFields: [],
+      searchAliases: ["ai impact", "copilot impact", "cursor impact", "ai throughput", "ai roi", "adoption vs cycle time"],
+      relatedResources: [
+        { resourceType: "sei_ai_adoption", relationship: "related", description: "The adoption series" },
+        { resourceType: "sei_productivity_metric", relationship: "related", description: "The PR velocity, cycle time, and review time series" },
+        { resourceType: "sei_ai_impact", relationship: "related", description: "SEI's own AI vs non-AI PR comparison" },
+        { resourceType: "sei_team", relationship: "parent", description: "Get team_ref_id" },
+      ],
+      deepLinkTemplate: AI_DEEP_LINK,
+      operations: {
+        get: {
+          method: "POST",
+          path: `${SEI}/v2/insights/coding-assistant/adoptions`,
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectAiCorrelation,
+          responseExtractor: seiAiCorrelationExtract,
+          skipCompact: true,
+          description: "Join AI adoption with PR velocity, cycle time, and review time per period, and correlate them. Pass team_ref_id, date_start, date_end.",
+          paramsSchema: filterFieldsToParamsSchema([
+            { name: "team_ref_id", description: "Team reference identifier (use sei_team list to find)", required: true },
+            { name: "date_start", description: "Start date (YYYY-MM-DD)", required: true },
+            { name: "date_end", description: "End date (YYYY-MM-DD)", required: true },
+            { name: "integration_type", description: "AI coding assistant type", enum: ["cursor", "windsurf", "all_assistants"] },
+            { name: "granularity", description: "Period to join on (default WEEKLY)", enum: ["WEEKLY", "MONTHLY"] },
+          ]),
+        },
+      },
+    },
+    // sei_ai_raw_metric: per-developer raw metrics, subject to HARNESS_SEI_DEVELOPER_METRICS
     {
       resourceType: "sei_ai_raw_metric",
       displayName: "SEI AI Raw Metric",
       description:
-        "Per-developer raw AI coding assistant metrics — lines suggested, accepted, acceptance rates per individual. Supports list.",
+        "Per-developer raw AI coding assistant metrics — lines suggested, accepted, acceptance rates per individual. Supports list. Unless the server allows individual metrics (HARNESS_SEI_DEVELOPER_METRICS=individual), results are team-level distributions with small groups suppressed.",
       toolset: "sei",
       scope: "project",
       headerBasedScoping: true,
+      developerMetrics: true,
       identifierFields: [],
       listFilterFields: [...AI_FILTER_FIELDS],
       deepLinkTemplate: AI_DEEP_LINK,
@@ -1,6 +1,7 @@
-import type { ToolsetDefinition, BodySchema } from "../types.js";
+import type { ToolsetDefinition, BodySchema, ParamsSchema, PreflightContext } from "../types.js";
 import { buildBodyNormalized } from "../../utils/body-normalizer.js";
-import { ngExtract, pageExtract } from "../extractors.js";
+import { wrappedEntityRestoreBody } from "../../utils/undo-log.js";
+import { ngExtract, pageExtract, serviceSecurityPostureExtract, type PostureSection, type ServicePostureScan } from "../extractors.js";
 
 const serviceCreateSchema: BodySchema = {
   description: "Service definition",
@@ -23,6 +24,84 @@ const serviceUpdateSchema: BodySchema = {
   ],
 };
 
+/** STO severities counted for the posture snapshot, most severe first. */
+const POSTURE_SEVERITIES = ["Critical", "High", "Medium", "Low"] as const;
+
+/**
+ * Gather the inputs for service_security_posture. Each source is read through
+ * its own resource type, so it respects toolset filtering: a source whose
+ * toolset is not enabled is reported as unavailable and a failing source as
+ * an error, without failing the whole snapshot.
+ */
+async function collectServicePosture(ctx: PreflightContext): Promise<ServicePostureScan> {
+  const { client, input, registry, signal } = ctx;
+  const serviceId = String(input.service_id ?? "");
+  if (!serviceId) throw new Error("service_id is required for service_security_posture.");
+  const scope = {
+    ...(input.org_id ? { org_id: input.org_id } : {}),
+    ...(input.project_id ? { project_id: input.project_id } : {}),
+  };
+
+  async function read(resourceType: string, operation: string, params: Record<string, unknown>): Promise<unknown> {
+    return registry.dispatch(client, resourceType, operation, { ...scope, ...params }, signal);
+  }
+
+  async function section<T>(resourceType: string, load: () => Promise<T>): Promise<PostureSection<T>> {
+    let toolset: string;
+    try {
+      toolset = registry.getResource(resourceType).toolset;
+    } catch {
+      return { status: "unavailable", reason: `${resourceType} is not enabled — add its toolset to HARNESS_TOOLSETS.` };
+    }
+    try {
+      return { status: "ok", data: await load() };
+    } catch (err) {
+      return { status: "error", reason: `${toolset}: ${err instanceof Error ? err.message : String(err)}` };
+    }
+  }
+
+  // Fail fast on an unknown service rather than reporting an empty posture.
+  const service = await read("service", "get", { service_id: serviceId });
+
+  const stoSearch = typeof input.sto_target_ids === "string" && input.sto_target_ids ? undefined : serviceId;
+  const sourceId = typeof input.scs_source_id === "string" ? input.scs_source_id : "";
+
+  const [sto, scs, chaos, policy] = await Promise.all([
+    section("security_issue", async () => {
+      const counts: Record<string, unknown> = {};
+      for (const severity of POSTURE_SEVERITIES) {
+        counts[severity] = await read("security_issue", "list", {
+          severity_codes: severity,
+          exemption_statuses: "None,Pending,Rejected,Expired",
+          ...(stoSearch ? { search: stoSearch } : { target_ids: input.sto_target_ids }),
+          page: 0,
+          size: 1,
+        });
+      }
+      return counts;
+    }),
+    sourceId
+      ? section("artifact_security", () => read("artifact_security", "list", {
+        source_id: sourceId,
+        search_term: typeof input.scs_artifact === "string" && input.scs_artifact ? input.scs_artifact : serviceId,
+        size: 10,
+      }))
+      : Promise.resolve<PostureSection<unknown>>({
+        status: "not_configured",
+        reason: "Pass scs_source_id (from harness_list(resource_type='scs_artifact_source')) to include supply-chain posture.",
+      }),
+    section("chaos_experiment", () => read("chaos_experiment", "list", { tags: `service=${serviceId}`, limit: 50 })),
+    section("policy_evaluation", () => read("policy_evaluation", "list", {
+      type: "service",
+      entity: serviceId,
+      status: "error",
+      size: 100,
+    })),
+  ]);
+
+  return { service_id: serviceId, service, sto, scs, chaos, policy };
+}
+
 export const servicesToolset: ToolsetDefinition = {
   name: "services",
   displayName: "Services",
@@ -42,6 +121,7 @@ export const servicesToolset: ToolsetDefinition = {
         { name: "order", description: "Sort order", enum: ["asc", "desc"] },
       ],
       deepLinkTemplate: "/ng/account/{accountId}/all/orgs/{orgIdentifier}/projects/{projectIdentifier}/settings/services/{serviceIdentifier}",
+      restoreBody: wrappedEntityRestoreBody("service"),
       operations: {
         list: {
           method: "GET",
@@ -96,5 +176,41 @@ export const servicesToolset: ToolsetDefinition = {
         },
       },
     },
+    {
+      resourceType: "service_security_posture",
+      displayName: "Service Security Posture",
+      description:
+        "One-call answer to \"how risky is my service?\" — merges open STO findings, SCS supply-chain policy violations, chaos resilience score, and failing OPA policy evaluations for a single service into one normalized snapshot with an overall risk_level. Supports get only.",
+      toolset: "services",
+      scope: "project",
+      identifierFields: ["service_id"],
+      searchAliases: ["security posture", "service risk", "how risky is my service", "service security", "risk snapshot"],
+      relatedResources: [
+        { resourceType: "service", relationship: "parent", description: "The service the snapshot describes." },
+        { resourceType: "security_issue", relationship: "uses", description: "Open STO findings behind sto.open_issues. List with the same search/target_ids for details." },
+        { resourceType: "artifact_security", relationship: "uses", description: "Artifacts behind scs.artifacts. Follow scs_bom_violation for violation details." },
+        { resourceType: "chaos_experiment", relationship: "uses", description: "Experiments tagged service=<service_id> behind chaos.resilience_score." },
+        { resourceType: "policy_evaluation", relationship: "uses", description: "Failing service policy evaluations behind policy.open_violations." },
+      ],
+      operations: {
+        get: {
+          method: "GET",
+          path: "/ng/api/servicesV2/{serviceIdentifier}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { service_id: "serviceIdentifier" },
+          collect: collectServicePosture,
+          responseExtractor: serviceSecurityPostureExtract,
+          paramsSchema: {
+            fields: [
+              { name: "sto_target_ids", required: false, description: "Comma-separated STO target IDs for this service. Default: issues whose search matches service_id" },
+              { name: "scs_source_id", required: false, description: "SCS artifact source ID holding this service's artifacts. Without it the scs section is not_configured" },
+              { name: "scs_artifact", required: false, description: "Artifact name search within scs_source_id (default: service_id)" },
+            ],
+          } satisfies ParamsSchema,
+          description:
+            "Get the security posture of one service. Returns risk_level (critical/high/medium/low/unknown), risk_drivers[], and sto, scs, chaos, policy sections — each with status ok, not_configured, unavailable (toolset disabled), or error. Chaos experiments are matched by the service=<service_id> tag.",
+        },
+      },
+    },
   ],
 };
@@ -1,5 +1,6 @@
-import type { ToolsetDefinition } from "../types.js";
-import { passthrough, stoExemptionsExtract } from "../extractors.js";
+import type { ToolsetDefinition, PreflightContext } from "../types.js";
+import { passthrough, stoExemptionsExtract, stoExemptionRequestExtract, stoIssueDetailExtract, stoScanSummaryExtract, type StoIssueScan, type StoScanSummaryScan } from "../extractors.js";
+import { isRecord } from "../../utils/type-guards.js";
 
 /**
  * Injects a redirect hint into every security_issue list response.
@@ -30,6 +31,152 @@ const securityIssueListExtract = (raw: unknown): unknown => {
  */
 const STO_SCOPE = { account: "accountId", org: "orgId", project: "projectId" } as const;
 
+/** Issues read when resolving a CVE to the issues it appears in. */
+const EXEMPTION_REQUEST_ISSUE_PAGE_SIZE = 100;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/**
+ * Exemption length in days from body.duration_days or body.expires_on (a
+ * date or timestamp). The create endpoint only takes a duration, so a date is
+ * rounded up to whole days from now.
+ */
+function exemptionDurationDays(body: Record<string, unknown>, now = Date.now()): number | undefined {
+  if (body.duration_days !== undefined && body.expires_on !== undefined) {
+    throw new Error("Pass duration_days or expires_on, not both.");
+  }
+  if (body.expires_on === undefined) return body.duration_days as number | undefined;
+  const expires = Date.parse(String(body.expires_on));
+  if (Number.isNaN(expires)) throw new Error(`expires_on must be a date such as 2026-12-31, got "${String(body.expires_on)}".`);
+  if (expires <= now) throw new Error(`expires_on (${String(body.expires_on)}) is not in the future.`);
+  return Math.ceil((expires - now) / DAY_MS);
+}
+
+/**
+ * Collect hook for security_exemption request: find the STO issues a CVE
+ * appears in, then request an exemption for the one issue (or, with
+ * all_matches, every issue) through the existing create paths. component
+ * narrows the exemption to that component's occurrences.
+ */
+async function requestExemption({ client, input, registry, signal }: PreflightContext): Promise<unknown> {
+  const body = isRecord(input.body) ? input.body : {};
+  const missing = ["type", "reason"].filter((f) => body[f] === undefined);
+  if (!body.issue_id && !body.cve) missing.unshift("cve or issue_id");
+  if (missing.length > 0) {
+    throw new Error(`Missing required fields for security_exemption request: ${missing.join(", ")}.`);
+  }
+  const durationDays = exemptionDurationDays(body);
+  const scope = { org_id: input.org_id, project_id: input.project_id };
+  const shared = {
+    type: body.type,
+    reason: body.reason,
+    ...(durationDays !== undefined ? { duration_days: durationDays } : {}),
+    ...(body.link ? { link: body.link } : {}),
+  };
+  const component = typeof body.component === "string" && body.component.trim() ? body.component.trim() : undefined;
+
+  let issues: Array<Record<string, unknown>> = [];
+  if (body.issue_id) {
+    issues = [{ id: body.issue_id }];
+  } else {
+    const cve = String(body.cve).trim();
+    const listed = await registry.dispatch(client, "security_issue", "list", {
+      ...scope,
+      search: cve,
+      page: 0,
+      size: EXEMPTION_REQUEST_ISSUE_PAGE_SIZE,
+    }, signal);
+    const page = isRecord(listed) ? listed.issues ?? listed.items : undefined;
+    const rows = Array.isArray(page) ? page.filter(isRecord) : [];
+    // The search also matches titles and descriptions; keep issues that name the CVE.
+    const needle = cve.toLowerCase();
+    issues = rows.filter((issue) => JSON.stringify(issue).toLowerCase().includes(needle));
+    if (component) {
+      const narrowed = issues.filter((issue) => JSON.stringify(issue).toLowerCase().includes(component.toLowerCase()));
+      if (narrowed.length > 0) issues = narrowed;
+    }
+    if (issues.length === 0) {
+      throw new Error(`No STO issue in this project mentions ${cve}. Check the CVE ID, or list issues with harness_list(resource_type='security_issue', filters={search:'<component>'}).`);
+    }
+    if (issues.length > 1 && body.all_matches !== true) {
+      const candidates = issues.slice(0, 10).map((i) => `${String(i.id)} (${[i.title, i.targetName].filter(Boolean).join(", ")})`);
+      throw new Error(`${cve} appears in ${issues.length} issues: ${candidates.join("; ")}. `
+        + "Pass issue_id to exempt one, or all_matches=true to request exemptions for all of them.");
+    }
+  }
+
+  const issueIds = issues.map((i) => String(i.id));
+  const created = issueIds.length === 1
+    ? await registry.dispatch(client, "security_exemption", "create", {
+      ...scope,
+      body: { ...shared, issue_id: issueIds[0], ...(component ? { search: component } : {}) },
+    }, signal)
+    : await registry.dispatch(client, "security_exemption_bulk", "create", {
+      ...scope,
+      body: { ...shared, items: issueIds.map((id) => ({ issue_id: id, ...(component ? { search: component } : {}) })) },
+    }, signal);
+  return { issues, created, duration_days: durationDays ?? 30, ...(body.cve ? { cve: body.cve } : {}), ...(component ? { component } : {}) };
+}
+
+/** Occurrences read alongside an issue's detail. */
+const ISSUE_DETAIL_OCCURRENCE_PAGE_SIZE = 50;
+
+/**
+ * Collect hook for security_issue get: the issue itself and its first page
+ * of occurrences, read in parallel. A failed occurrence read is reported
+ * next to the detail rather than failing the call.
+ */
+async function collectIssueDetail({ client, input, registry, signal }: PreflightContext): Promise<StoIssueScan> {
+  const issueId = typeof input.issue_id === "string" ? input.issue_id : undefined;
+  if (!issueId) throw new Error("issue_id is required. Get it from harness_list(resource_type='security_issue').");
+  const org = (input.org_id as string | undefined) ?? registry.orgId;
+  const project = (input.project_id as string | undefined) ?? registry.projectId;
+  const [issue, occurrences] = await Promise.all([
+    client.request<unknown>({
+      method: "GET",
+      path: `/sto/api/v2/issues/${encodeURIComponent(issueId)}`,
+      params: { accountId: client.account, ...(org ? { orgId: org } : {}), ...(project ? { projectId: project } : {}) },
+      signal,
+    }),
+    registry.dispatch(client, "security_issue_occurrence", "list", {
+      org_id: org,
+      project_id: project,
+      issue_id: issueId,
+      ...(input.target_id ? { target_id: input.target_id } : {}),
+      page: 0,
+      size: ISSUE_DETAIL_OCCURRENCE_PAGE_SIZE,
+    }, signal).then(
+      (value) => ({ value }),
+      (err: unknown) => ({ error: err instanceof Error ? err.message : String(err) }),
+    ),
+  ]);
+  return "error" in occurrences ? { issue, occurrences_error: occurrences.error } : { issue, occurrences: occurrences.value };
+}
+
+/** Scans read for a coverage summary: pages of SCAN_SUMMARY_PAGE_SIZE, newest first. */
+const SCAN_SUMMARY_PAGE_SIZE = 100;
+const SCAN_SUMMARY_MAX_PAGES = 5;
+
+/** Collect hook for security_scan_summary: the most recent scans, for one target or all of them. */
+async function collectScanSummary({ client, input, registry, signal }: PreflightContext): Promise<StoScanSummaryScan> {
+  const scans: Array<Record<string, unknown>> = [];
+  let truncated = false;
+  for (let page = 0; page < SCAN_SUMMARY_MAX_PAGES; page++) {
+    const listed = await registry.dispatch(client, "security_scan", "list", {
+      org_id: input.org_id,
+      project_id: input.project_id,
+      ...(input.target_id ? { target_id: input.target_id } : {}),
+      page,
+      size: SCAN_SUMMARY_PAGE_SIZE,
+    }, signal);
+    const rows = isRecord(listed) ? listed.results ?? listed.items ?? listed.scans : listed;
+    const batch = Array.isArray(rows) ? rows.filter(isRecord) : [];
+    scans.push(...batch);
+    if (batch.length < SCAN_SUMMARY_PAGE_SIZE) break;
+    if (page === SCAN_SUMMARY_MAX_PAGES - 1) truncated = true;
+  }
+  return { ...(input.target_id ? { target_id: String(input.target_id) } : {}), scans, truncated };
+}
+
 export const stoToolset: ToolsetDefinition = {
   name: "sto",
   displayName: "Security Testing Orchestration",
@@ -43,7 +190,8 @@ export const stoToolset: ToolsetDefinition = {
       description:
         "STOP — IF THE USER WANTS TO APPROVE, REJECT, OR PROMOTE AN EXEMPTION, USE resource_type='security_exemption' INSTEAD. " +
         "This 'security_issue' resource only lists raw vulnerabilities from scans — it has NO approve/reject/promote actions. " +
-        "Security vulnerability/issue from scan results. Supports list with extensive filtering by severity, type, target, pipeline, and scan tool.",
+        "Security vulnerability/issue from scan results. Supports list with extensive filtering by severity, type, target, pipeline, scan tool, and exemption status, " +
+        "and get (issue_id) for the full detail: reference IDs (CVE/CWE/GHSA), remediation guidance, and occurrences (file:line or component@version per target).",
       toolset: "sto",
       scope: "project",
       scopeParams: STO_SCOPE,
@@ -92,6 +240,164 @@ export const stoToolset: ToolsetDefinition = {
           responseExtractor: securityIssueListExtract,
           description: "List security issues with filtering by severity, type, target, pipeline, and scan tool",
         },
+        get: {
+          method: "GET",
+          path: "/sto/api/v2/issues/{issueId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { issue_id: "issueId" },
+          collect: collectIssueDetail,
+          responseExtractor: stoIssueDetailExtract,
+          description: "Get an issue's detail: severity, reference IDs, remediation guidance, and its first occurrences. Pass target_id to limit occurrences to one target.",
+        },
+      },
+    },
+
+    // ── Security Issue Occurrences ─────────────────────────────────────
+    {
+      resourceType: "security_issue_occurrence",
+      displayName: "Security Issue Occurrence",
+      description:
+        "Every place an STO issue was found: file and line for code findings, component and version for SCA findings, per target and variant. " +
+        "harness_get(resource_type='security_issue') already includes the first page; list this to page through the rest.",
+      toolset: "sto",
+      scope: "project",
+      scopeParams: STO_SCOPE,
+      identifierFields: ["issue_id"],
+      listFilterFields: [
+        { name: "issue_id", description: "Issue ID (get from harness_list resource_type=security_issue)", required: true },
+        { name: "target_id", description: "Only occurrences in this target" },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/sto/api/v2/issues/{issueId}/occurrences",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { issue_id: "issueId" },
+          queryParams: {
+            target_id: "targetId",
+            page: "page",
+            size: "pageSize",
+          },
+          responseExtractor: passthrough,
+          description: "List the occurrences of a security issue",
+        },
+      },
+    },
+
+    // ── Scan Targets ───────────────────────────────────────────────────
+    {
+      resourceType: "security_target",
+      displayName: "Security Scan Target",
+      description:
+        "STO scan targets: the repositories, container images, instances, and configurations that scanners run against, each with its variants (branches or tags). " +
+        "Supports list and get. Use target IDs to list a target's scans (security_scan) or its scanner coverage (security_scan_summary).",
+      searchAliases: ["scan targets", "sto targets", "scanned repos", "scanned images", "targets"],
+      relatedResources: [
+        { resourceType: "security_scan", relationship: "child", description: "Scans run against this target (filter target_id)" },
+        { resourceType: "security_scan_summary", relationship: "child", description: "Latest scan per scanner for this target" },
+        { resourceType: "security_issue", relationship: "child", description: "Issues found on this target (filter target_ids)" },
+      ],
+      toolset: "sto",
+      scope: "project",
+      scopeParams: STO_SCOPE,
+      identifierFields: ["target_id"],
+      listFilterFields: [
+        { name: "search", description: "Filter targets by name" },
+        { name: "type", description: "Target type", enum: ["repository", "container", "instance", "configuration"] },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/sto/api/v2/targets",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: {
+            search: "search",
+            type: "type",
+            page: "page",
+            size: "pageSize",
+          },
+          responseExtractor: passthrough,
+          description: "List scan targets with their type and variants",
+        },
+        get: {
+          method: "GET",
+          path: "/sto/api/v2/targets/{targetId}",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          pathParams: { target_id: "targetId" },
+          responseExtractor: passthrough,
+          description: "Get a scan target",
+        },
+      },
+    },
+
+    // ── Scans ──────────────────────────────────────────────────────────
+    {
+      resourceType: "security_scan",
+      displayName: "Security Scan",
+      description:
+        "STO scans, newest first, with the scanner that ran, the target and variant, the pipeline execution, status, and issue counts by severity. " +
+        "Filter by target_id and status. For the latest scan per scanner (coverage audits), use security_scan_summary.",
+      searchAliases: ["sto scans", "scan history", "scan status", "failed scans", "scans per target"],
+      relatedResources: [
+        { resourceType: "security_target", relationship: "parent", description: "Get target_id to filter scans" },
+        { resourceType: "security_scan_summary", relationship: "sibling", description: "Latest scan per scanner, per target" },
+        { resourceType: "pipeline_security_issue", relationship: "child", description: "Issues from the scan's pipeline execution" },
+      ],
+      toolset: "sto",
+      scope: "project",
+      scopeParams: STO_SCOPE,
+      identifierFields: ["scan_id"],
+      listFilterFields: [
+        { name: "target_id", description: "Only scans of this target" },
+        { name: "status", description: "Scan status", enum: ["Succeeded", "Failed", "Running", "Pending"] },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/sto/api/v2/scans",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          queryParams: {
+            target_id: "targetId",
+            status: "status",
+            page: "page",
+            size: "pageSize",
+          },
+          responseExtractor: passthrough,
+          description: "List scans with status, scanner, target, and issue counts",
+        },
+      },
+    },
+
+    // ── Scan Coverage Summary ──────────────────────────────────────────
+    {
+      resourceType: "security_scan_summary",
+      displayName: "Security Scan Summary",
+      description:
+        "Scanner coverage per target: the latest scan of each scanner type (e.g. aqua-trivy, semgrep, gitleaks) with its status, time, and issue counts, " +
+        "and the scanners that run on other targets but not this one. Pass target_id for one target; without it every recently scanned target is summarized. " +
+        `Built from the ${SCAN_SUMMARY_PAGE_SIZE * SCAN_SUMMARY_MAX_PAGES} most recent scans, so targets not scanned lately are missing.`,
+      searchAliases: ["scan coverage", "latest scan", "scanner coverage", "which scanners ran", "appsec coverage", "last scan per scanner"],
+      relatedResources: [
+        { resourceType: "security_target", relationship: "parent", description: "Get target_id, and find targets that have never been scanned" },
+        { resourceType: "security_scan", relationship: "child", description: "Full scan history behind the summary" },
+      ],
+      toolset: "sto",
+      scope: "project",
+      scopeParams: STO_SCOPE,
+      identifierFields: [],
+      listFilterFields: [
+        { name: "target_id", description: "Summarize only this target" },
+      ],
+      operations: {
+        list: {
+          method: "GET",
+          path: "/sto/api/v2/scans",
+          operationPolicy: { risk: "read", retryPolicy: "safe" },
+          collect: collectScanSummary,
+          responseExtractor: stoScanSummaryExtract,
+          skipCompact: true,
+          description: "Summarize the latest scan per scanner type for each target",
+        },
       },
     },
 
@@ -333,8 +639,8 @@ export const stoToolset: ToolsetDefinition = {
     {
       resourceType: "security_exemption",
       displayName: "Security Exemption",
-      searchAliases: ["approve", "reject", "promote", "waiver", "exception", "exempt", "approval"],
-      description: "Security issue exemption/waiver. THIS is the resource for exemption approval/rejection workflows — even when the user mentions a vulnerability title like 'SQL Injection'. Supports list (POST with status filter), create, and approve/reject actions. Approval with body.scope='ACCOUNT', 'ORG', or 'PROJECT' routes through STO promotion internally. " +
+      searchAliases: ["approve", "reject", "promote", "expire", "revoke", "waiver", "exception", "exempt", "approval"],
+      description: "Security issue exemption/waiver. THIS is the resource for exemption approval/rejection workflows — even when the user mentions a vulnerability title like 'SQL Injection'. Supports list (POST with status filter; status='Pending' is the approval queue), create, and request/approve/reject/expire actions — request takes a CVE (and optional component) instead of an issue ID. Approval with body.scope='ACCOUNT', 'ORG', or 'PROJECT' routes through STO promotion internally. " +
         "CRITICAL SCOPE DISTINCTION: There are TWO different scope concepts that must NOT be confused: " +
         "(1) LISTING scope — security_exemption ALWAYS lists at project scope. NEVER pass resource_scope='account' or resource_scope='org' to harness_list — it will fail. Always list using project defaults. " +
         "(2) APPROVAL scope — the scope the exemption is approved AT, passed as body.scope to harness_execute. This CAN be 'ACCOUNT', 'ORG', 'PROJECT', or 'CURRENT'. " +
@@ -347,7 +653,7 @@ export const stoToolset: ToolsetDefinition = {
       scopeParams: STO_SCOPE,
       identifierFields: ["exemption_id"],
       listFilterFields: [
-        { name: "status", description: "Exemption status filter — SINGLE value only, not comma-separated. Make separate calls for each status.", enum: ["Pending", "Approved", "Rejected", "Expired", "Canceled"], required: true },
+        { name: "status", description: "Exemption status filter — SINGLE value only, not comma-separated. Make separate calls for each status. Use 'Pending' for exemptions awaiting approval.", enum: ["Pending", "Approved", "Rejected", "Expired", "Canceled"], required: true },
         { name: "search", description: "Free-text search for issue/exemption titles" },
         { name: "size", type: "number", description: "Exemptions per page (recommended: 5, max: 50). Always pass explicitly inside `filters` — `harness_list`'s global default of 20 is too large for this resource. Must remain constant across pages in a session." },
         { name: "page", type: "number", description: "0-indexed page number. Increment by 1 for each 'next' request — never repeat the same value." },
@@ -556,6 +862,32 @@ export const stoToolset: ToolsetDefinition = {
             ],
           },
         },
+        request: {
+          method: "POST",
+          path: "/sto/api/v2/exemptions",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          collect: requestExemption,
+          responseExtractor: stoExemptionRequestExtract,
+          actionDescription: "Request an exemption for a CVE without looking up its issue ID first. Finds the STO issues that name body.cve "
+            + "(SCS-reported component vulnerabilities included, once scanned into STO) and requests a pending exemption for the match. "
+            + "body.component narrows the exemption to that component's occurrences. When the CVE appears in several issues, the call lists them: "
+            + "pass issue_id to pick one, or all_matches=true to request exemptions for all of them in one batch. "
+            + "Expiry is duration_days or expires_on (a date). Approve or reject the result with the approve/reject actions.",
+          bodySchema: {
+            description: "Exemption request. Required: cve or issue_id, type, reason.",
+            fields: [
+              { name: "cve", type: "string", required: false, description: "CVE ID to exempt, e.g. CVE-2024-3094. Required unless issue_id is given." },
+              { name: "issue_id", type: "string", required: false, description: "STO issue ID, instead of cve." },
+              { name: "component", type: "string", required: false, description: "Component name or purl to limit the exemption to." },
+              { name: "type", type: "string", required: false, description: "REQUIRED. Compensating Controls | Acceptable Use | Acceptable Risk | False Positive | Fix Unavailable | Other." },
+              { name: "reason", type: "string", required: false, description: "REQUIRED. Justification shown to the approver (max 1024 chars)." },
+              { name: "duration_days", type: "number", required: false, description: "Exemption length in days (default: 30)." },
+              { name: "expires_on", type: "string", required: false, description: "Expiry date (e.g. 2026-12-31), instead of duration_days." },
+              { name: "link", type: "string", required: false, description: "Related ticket or reference URL." },
+              { name: "all_matches", type: "boolean", required: false, description: "Request exemptions for every issue the CVE appears in (at most 100)." },
+            ],
+          },
+        },
         reject: {
           method: "PUT",
           path: "/sto/api/v2/exemptions/{exemptionId}/reject",
@@ -585,6 +917,36 @@ export const stoToolset: ToolsetDefinition = {
             ],
           },
         },
+        expire: {
+          method: "PUT",
+          path: "/sto/api/v2/exemptions/{exemptionId}/expire",
+          operationPolicy: { risk: "high_write", retryPolicy: "do_not_retry" },
+          pathParams: { exemption_id: "exemptionId" },
+          preflight: async ({ client, input }) => {
+            const body = ((input.body as Record<string, unknown> | undefined) ?? {});
+            if (!body.approver_id) {
+              body.approver_id = await client.getCurrentUserId();
+              input.body = body;
+            }
+          },
+          bodyBuilder: (input) => {
+            const b = (input.body as Record<string, unknown> | undefined) ?? {};
+            return {
+              approverId: b.approver_id,
+              ...(b.comment ? { comment: b.comment } : {}),
+            };
+          },
+          responseExtractor: passthrough,
+          actionDescription: "Expire an approved security exemption now, before its expiry date, so its issues count against the project again. "
+            + "Use it to revoke an exemption that is no longer justified (e.g. a fix has shipped). approver_id is auto-derived from the authenticated user when not supplied.",
+          bodySchema: {
+            description: "Exemption expiry details",
+            fields: [
+              { name: "approver_id", type: "string", required: false, description: "User UUID of the user expiring the exemption. Auto-derived from the authenticated PAT via /ng/api/user/currentUser if omitted." },
+              { name: "comment",     type: "string", required: false, description: "Optional comment recorded with the expiry" },
+            ],
+          },
+        },
       },
     },
 
@@ -2,6 +2,7 @@ import YAML from "yaml";
 import type { BodySchema, PathBuilderConfig, ToolsetDefinition } from "../types.js";
 import { ngExtract, pageExtract, passthrough, v1ListExtract } from "../extractors.js";
 import { SCOPE_BEHAVIOR_DOC, templateV1BasePathFromScope } from "../scope-utils.js";
+import { isRecord } from "../../utils/type-guards.js";
 
 function getTemplateYamlFromInput(input: Record<string, unknown>): string {
   const b = (input.body as Record<string, unknown>) ?? {};
@@ -271,6 +272,7 @@ export const templatesToolset: ToolsetDefinition = {
       searchAliases: ["v0 template", "classic template", "step template", "stage template"],
       listFilterFields: templateListFilterFields,
       deepLinkTemplate: templateDeepLink,
+      restoreBody: (prior) => (isRecord(prior) && typeof prior.yaml === "string" ? { yaml: prior.yaml } : undefined),
       operations: {
         list: {
           method: "POST",
@@ -3,6 +3,7 @@
  */
 
 import type { RequestOptions } from "../client/types.js";
+import type { UndoLog } from "../utils/undo-log.js";
 
 export type HttpMethod = "GET" | "POST" | "PUT" | "PATCH" | "DELETE";
 
@@ -85,6 +86,8 @@ export interface HarnessClientInterface {
   getCurrentUserId(): Promise<string>;
   /** Issue an authenticated Harness request. Used by preflight hooks that fetch defaults. */
   request<T>(options: RequestOptions): Promise<T>;
+  /** Issue a request and return the raw Response for streamed reads (e.g. live logs). */
+  requestStream(options: RequestOptions): Promise<Response>;
 }
 
 export interface RegistryDispatchInterface {
@@ -100,6 +103,8 @@ export interface RegistryDispatchInterface {
   readonly orgId: string | undefined;
   /** Default project identifier from config, when set. */
   readonly projectId: string | undefined;
+  /** The session's undo log, when HARNESS_UNDO_LOG is on. */
+  readonly undoLog?: UndoLog;
 }
 
 /**
@@ -331,6 +336,15 @@ export interface EndpointSpec {
    * The runtime shape is `{ client: HarnessClient, input, registry: Registry, signal? }`.
    */
   preflight?: (ctx: PreflightContext) => Promise<void>;
+  /**
+   * Optional aggregation hook that replaces the single HTTP request. Use for
+   * views the Harness API has no one endpoint for (e.g. triggers across every
+   * pipeline in a project). The hook fetches what it needs — usually via
+   * `ctx.registry.dispatch` on existing resources — and its return value is
+   * passed to `responseExtractor` as the raw response. `method`/`path` still
+   * describe the primary endpoint for audit events.
+   */
+  collect?: (ctx: PreflightContext) => Promise<unknown>;
   /**
    * When true, the MCP layer controls ELK→Mongo fallback for this endpoint:
    *  1. First request sent with `enforce_elasticsearch=true` (ELK path).
@@ -349,6 +363,23 @@ export interface EndpointSpec {
    * strip intentional display fields (e.g. `severity`, `requested_by`).
    */
   skipCompact?: boolean;
+  /**
+   * When true, the response cache never answers this read. For results that
+   * change between identical calls, such as tailing a running step's log.
+   */
+  skipCache?: boolean;
+  /**
+   * Optional hook that writes the extracted result to disk (e.g. when the
+   * caller passed output_dir) and returns what the caller sees instead. Runs
+   * after the response cache, so a read answered from the cache still writes
+   * its file. Throw to fail the call.
+   */
+  writeOutput?: (result: unknown, input: Record<string, unknown>) => unknown;
+  /**
+   * When true, a successful call is not recorded in the session undo log.
+   * For actions that only act on the log itself, such as undo_last_change.
+   */
+  skipUndoLog?: boolean;
 }
 
 /**
@@ -419,8 +450,22 @@ export interface ResourceDefinition {
     relationship: string;
     description: string;
   }>;
+  /**
+   * Results describe individual developers. Governed by
+   * HARNESS_SEI_DEVELOPER_METRICS: not registered when "off", reduced to
+   * team-level aggregates when "aggregate" (see utils/developer-privacy.ts).
+   */
+  developerMetrics?: boolean;
   /** Execution guidance for LLMs. Describes how to discover and provide runtime inputs. */
   executeHint?: string;
+  /**
+   * Builds the create/update body that puts the entity back the way its `get`
+   * result shows it (e.g. `{ yamlPipeline }` for a pipeline). When set and
+   * HARNESS_UNDO_LOG is on, the registry reads the entity before each update
+   * or delete so undo_last_change can reverse the change. Return undefined
+   * when the result carries nothing restorable.
+   */
+  restoreBody?: (prior: unknown) => unknown;
   /** CRUD endpoint mappings */
   operations: Partial<Record<OperationName, EndpointSpec>>;
   /** Execute action mappings (e.g. run pipeline, toggle FF) */
@@ -0,0 +1,26 @@
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { summarizeCacheMetrics } from "../utils/response-cache.js";
+
+/**
+ * Admin report of response-cache effectiveness: hits, misses, bypasses,
+ * evictions, and write invalidations, overall and per toolset.
+ */
+export function registerCacheMetricsResource(server: McpServer): void {
+  server.registerResource(
+    "cache-metrics",
+    "cache:///metrics",
+    {
+      title: "Response Cache Metrics",
+      description:
+        "Hit/miss counters for the read-only response cache (HARNESS_CACHE_TTL_MS), overall and per toolset. Counts reset when the server restarts.",
+      mimeType: "application/json",
+    },
+    async (uri) => ({
+      contents: [{
+        uri: uri.href,
+        mimeType: "application/json",
+        text: JSON.stringify(summarizeCacheMetrics(), null, 2),
+      }],
+    }),
+  );
+}
@@ -0,0 +1,26 @@
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { summarizeContextCost } from "../utils/context-cost.js";
+
+/**
+ * Estimated tokens each tool's results add to the context window, per tool
+ * and resource type. Shows prompt engineers which results to trim.
+ */
+export function registerContextCostResource(server: McpServer): void {
+  server.registerResource(
+    "context-cost",
+    "context-cost:///report",
+    {
+      title: "Tool Result Context Cost",
+      description:
+        "Estimated tokens per tool result (characters / 4), per tool and resource type, most expensive first. Counts reset when the server restarts.",
+      mimeType: "application/json",
+    },
+    async (uri) => ({
+      contents: [{
+        uri: uri.href,
+        mimeType: "application/json",
+        text: JSON.stringify(summarizeContextCost(), null, 2),
+      }],
+    }),
+  );
+}
@@ -0,0 +1,26 @@
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { summarizeDeprecatedUsage } from "../utils/deprecation-tracker.js";
+
+/**
+ * Admin report of uses of deprecated names (renamed toolsets), grouped by name
+ * and source. Check it before removing an alias.
+ */
+export function registerDeprecatedUsageResource(server: McpServer): void {
+  server.registerResource(
+    "deprecated-usage",
+    "deprecations:///usage",
+    {
+      title: "Deprecated Name Usage",
+      description:
+        "Who still enables renamed toolsets by their old names, with counts per source (HARNESS_TOOLSETS or bearer-token claim) and the replacement to migrate to. Counts reset when the server restarts.",
+      mimeType: "application/json",
+    },
+    async (uri) => ({
+      contents: [{
+        uri: uri.href,
+        mimeType: "application/json",
+        text: JSON.stringify(summarizeDeprecatedUsage(), null, 2),
+      }],
+    }),
+  );
+}
@@ -6,10 +6,29 @@ import type { Config } from "../config.js";
 import { registerPipelineYamlResource } from "./pipeline-yaml.js";
 import { registerExecutionSummaryResource } from "./execution-summary.js";
 import { registerHarnessSchemaResource } from "./harness-schema.js";
+import { registerDeprecatedUsageResource } from "./deprecated-usage.js";
+import { registerCacheMetricsResource } from "./cache-metrics.js";
+import { registerContextCostResource } from "./context-cost.js";
+import { registerSupportBundleResource } from "./support-bundle.js";
 import type { SchemaEntry } from "../data/schemas/types.js";
 
-export function registerAllResources(server: McpServer, registry: Registry, client: HarnessClient, config: Config, additionalSchemas?: Record<string, SchemaEntry>): void {
+/**
+ * @param operator Also register operator-only resources. support:///bundle
+ *   serves process-wide logs, so HTTP sessions get it only with the static auth token.
+ */
+export function registerAllResources(
+  server: McpServer,
+  registry: Registry,
+  client: HarnessClient,
+  config: Config,
+  additionalSchemas?: Record<string, SchemaEntry>,
+  operator = false,
+): void {
   registerPipelineYamlResource(server, registry, client, config);
   registerExecutionSummaryResource(server, registry, client, config);
   registerHarnessSchemaResource(server, additionalSchemas);
+  registerDeprecatedUsageResource(server);
+  registerCacheMetricsResource(server);
+  registerContextCostResource(server);
+  if (operator) registerSupportBundleResource(server, client, config);
 }
@@ -0,0 +1,29 @@
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import type { HarnessClient } from "../client/harness-client.js";
+import type { Config } from "../config.js";
+import { collectSupportBundle } from "../utils/support-bundle.js";
+
+/**
+ * Sanitized diagnostics for support tickets: version info, effective config,
+ * recent logs and tool errors, and live connectivity checks. The same content
+ * `harness-mcp-server support-bundle` writes to an archive.
+ */
+export function registerSupportBundleResource(server: McpServer, client: HarnessClient, config: Config): void {
+  server.registerResource(
+    "support-bundle",
+    "support:///bundle",
+    {
+      title: "Support Bundle",
+      description:
+        "Sanitized server diagnostics to attach to a support ticket: version, effective config with secrets redacted, recent logs, recent tool errors, and connectivity checks against Harness.",
+      mimeType: "application/json",
+    },
+    async (uri) => ({
+      contents: [{
+        uri: uri.href,
+        mimeType: "application/json",
+        text: JSON.stringify(await collectSupportBundle({ config, client }), null, 2),
+      }],
+    }),
+  );
+}
@@ -1,9 +1,11 @@
+import { tmpdir } from "node:os";
+import { join } from "node:path";
 import type { SearchProvider, SearchResult, SearchOptions, IndexableItem, SearchCorpus } from "./types.js";
 import { CORPUS_DEFAULT_TTL_MS } from "./types.js";
 import { createLogger } from "../utils/logger.js";
 
 const log = createLogger("local-provider");
-const DEFAULT_HF_CACHE_DIR = "/tmp/hf-cache";
+const DEFAULT_HF_CACHE_DIR = join(tmpdir(), "hf-cache");
 export const DEFAULT_EMBEDDING_MODEL = "Xenova/all-MiniLM-L6-v2";
 const EMBEDDING_DIM = 384;
 const CORPORA: SearchCorpus[] = ["entities", "docs", "knowledge"];
@@ -6,6 +6,7 @@ import { sendProgress } from "../../utils/progress.js";
 import { isRecord, asRecord, asString, asNumber } from "../../utils/type-guards.js";
 import { resolveLogContent, resolveLogDownloadUrl } from "../../utils/log-resolver.js";
 import { TERMINAL_STATUSES } from "../../utils/poll-execution.js";
+import { analyzeFailure } from "../../utils/failure-analysis.js";
 
 const log = createLogger("diagnose:pipeline");
 const NON_TERMINAL_EXECUTION_ERROR_PREFIX = "Cannot diagnose execution with status";
@@ -97,7 +98,9 @@ interface StageSummary {
 interface FailedNodeDetail {
   stage: string;
   step: string;
+  step_type?: string;
   failure_message: string;
+  failure_types?: string[];
   log_key?: string;
   delegate?: string;
   script_context?: {
@@ -250,7 +253,9 @@ function findFailedNodes(nodeMap: Record<string, ExecGraphNode>): FailedNodeDeta
     const detail: FailedNodeDetail = {
       stage: stageId,
       step: node.identifier ?? node.name ?? "unknown",
+      step_type: node.stepType,
       failure_message: msg,
+      failure_types: node.failureInfo?.failureTypeList,
       log_key: node.logBaseKey,
       delegate,
     };
@@ -458,6 +463,8 @@ async function resolveDiagnoseLog(
     signal?: AbortSignal;
     returnDownloadUrl?: boolean;
     logSnippetLines: number;
+    /** Receives the full log text before it is cut to logSnippetLines. */
+    onLogText?: (text: string) => void;
   },
 ): Promise<unknown> {
   if (options.returnDownloadUrl) {
@@ -465,6 +472,7 @@ async function resolveDiagnoseLog(
     return { download_url: downloadUrl };
   }
   const logText = await resolveLogContent(client, prefix, { signal: options.signal });
+  if (typeof logText === "string") options.onLogText?.(logText);
   return truncateLog(logText, options.logSnippetLines);
 }
 
@@ -472,7 +480,7 @@ async function resolveDiagnoseLog(
 
 export const pipelineHandler: DiagnoseHandler = {
   entityType: "pipeline",
-  description: "Analyze a pipeline execution — stage/step breakdown, timing, bottlenecks, failure details with exact step, error, delegate, and script context.",
+  description: "Analyze a pipeline execution — stage/step breakdown, timing, bottlenecks, failure details with exact step, error, delegate, and script context, and a root_cause with error category, log excerpt, and suggested fix.",
 
   async diagnose(ctx: DiagnoseContext): Promise<Record<string, unknown>> {
     const { client, registry, config, input, args, extra, signal } = ctx;
@@ -539,6 +547,7 @@ export const pipelineHandler: DiagnoseHandler = {
       const execution = await registry.dispatch(client, "execution", "get", {
         ...input,
         render_full_graph: true,
+        detail_level: "full",
       }, signal);
 
       const exec = asRecord(execution) ?? {};
@@ -619,11 +628,13 @@ export const pipelineHandler: DiagnoseHandler = {
     // Must use `capped` (the actually-fetched subset), not the full `failedNodes`,
     // so that truncated failures don't incorrectly block the requested_step_log fetch.
     let fetchedFailedLogKeys = new Set<string>();
+    // Full log text of each failed step, for root-cause excerpts.
+    const failedLogTexts = new Map<string, string>();
+    const capped = maxFailedSteps > 0 ? failedNodes.slice(0, maxFailedSteps) : failedNodes;
 
     if (includeLogs && failedNodes.length > 0) {
       await sendProgress(extra, currentStep, totalSteps, "Fetching failed step logs...");
 
-      const capped = maxFailedSteps > 0 ? failedNodes.slice(0, maxFailedSteps) : failedNodes;
       if (capped.length < failedNodes.length) {
         diagnostic.failed_steps_truncated = { shown: capped.length, total: failedNodes.length };
       }
@@ -653,6 +664,7 @@ export const pipelineHandler: DiagnoseHandler = {
                 signal,
                 returnDownloadUrl,
                 logSnippetLines,
+                onLogText: (text) => failedLogTexts.set(key, text),
               });
               return { key, value: logValue };
             } catch (err) {
@@ -671,6 +683,18 @@ export const pipelineHandler: DiagnoseHandler = {
       diagnostic.failed_step_logs = stepLogs;
     }
 
+    // Root cause: category, log excerpt, and suggested fix per failed step.
+    // The excerpt needs the step log, so it is present only with include_logs.
+    if (capped.length > 0) {
+      const [primary, ...others] = capped.map((fn) => analyzeFailure(fn, failedLogTexts.get(`${fn.stage}/${fn.step}`)));
+      diagnostic.root_cause = {
+        ...primary,
+        ...(others.length > 0
+          ? { other_failures: others.map((a) => ({ stage: a.stage, step: a.step, category: a.category, error: a.error })) }
+          : {}),
+      };
+    }
+
     // If a specific step was requested via the Harness URL (?step=<nodeExecutionId>),
     // fetch its log regardless of pass/fail status. The nodeMap key IS the nodeExecutionId.
     // Skip only if the step's log was actually fetched in the capped failed_step_logs above.
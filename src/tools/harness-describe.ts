@@ -5,6 +5,8 @@ import type { InputExpansionRule } from "../registry/types.js";
 import { jsonResult } from "../utils/response-formatter.js";
 import { getExamplesForResource } from "../data/examples/index.js";
 import { describeOutputSchema } from "./output-schemas.js";
+import { summarizeContextCost } from "../utils/context-cost.js";
+import { describedToolNames, getFullToolDescription } from "../utils/tool-descriptions.js";
 
 export function registerDescribeTool(server: McpServer, registry: Registry): void {
   const allTypes = registry.getAllResourceTypes() as [string, ...string[]];
@@ -13,11 +15,13 @@ export function registerDescribeTool(server: McpServer, registry: Registry): voi
   server.registerTool(
     "harness_describe",
     {
-      description: "Describe available Harness resource types, their supported operations, and fields. No API call — returns local metadata only. Use this to discover what resource_types you can use with other harness_ tools.",
+      description: "Describe available Harness resource types, their supported operations, and fields. No API call — returns local metadata only. Use this to discover what resource_types you can use with other harness_ tools. Set context_cost=true for the estimated tokens each tool's results have used. Set tool='harness_<name>' for that tool's full usage guidance.",
       inputSchema: {
         resource_type: z.enum(allTypes).optional().describe("Get details for a specific resource type"),
         toolset: z.enum(allToolsets).optional().describe("Filter to a specific toolset"),
         search_term: z.string().optional().describe("Search for resource types by keyword (matches type name, display name, toolset, description)"),
+        context_cost: z.boolean().optional().describe("Return the context cost report instead: estimated tokens per tool result and per resource type since server start, most expensive first"),
+        tool: z.string().optional().describe("Return the full description and parameter guidance of a harness_* tool, e.g. 'harness_execute'. Use when tool descriptions are shortened."),
       },
       outputSchema: describeOutputSchema,
       annotations: {
@@ -29,6 +33,24 @@ export function registerDescribeTool(server: McpServer, registry: Registry): voi
       },
     },
     async (args) => {
+      if (args.context_cost) {
+        return jsonResult({
+          ...summarizeContextCost(),
+          hint: "Tokens are estimated from result text length. Trim the most expensive resource types with filters, smaller page sizes, or compact output.",
+        });
+      }
+
+      if (args.tool) {
+        const full = getFullToolDescription(args.tool);
+        if (!full) {
+          return jsonResult({
+            error: `Unknown tool "${args.tool}". Available: ${describedToolNames().join(", ")}`,
+            available_tools: describedToolNames(),
+          });
+        }
+        return jsonResult(full);
+      }
+
       if (args.resource_type) {
         try {
           const def = registry.getResource(args.resource_type);
@@ -16,6 +16,7 @@ import { materializeInputSetsToRuntimeYaml, mergeRuntimePipelineFragments } from
 import { resourceScopeSchema, resourceTypeSchema } from "./input-schemas.js";
 import { pollExecutionToTerminal, FAILURE_STATUSES, AbortError } from "../utils/poll-execution.js";
 import { sendProgress } from "../utils/progress.js";
+import { buildDeepLink } from "../utils/deep-links.js";
 import { executeOutputSchema } from "./output-schemas.js";
 
 const log = createLogger("execute");
@@ -285,7 +286,10 @@ export function registerExecuteTool(server: McpServer, registry: Registry, clien
           input.input_set_ids = [...args.input_set_ids];
         }
 
-        if (resourceType === "pipeline" && args.action === "run") {
+        // preflight checks the same runtime inputs run would send, so it goes
+        // through the same git-param normalization and input resolution.
+        const resolvesRunInputs = resourceType === "pipeline" && (args.action === "run" || args.action === "preflight");
+        if (resolvesRunInputs) {
           normalizeRemotePipelineRunParams(input);
         }
 
@@ -298,8 +302,7 @@ export function registerExecuteTool(server: McpServer, registry: Registry, clien
         // Harness execute often does not apply `inputSetIdentifiers` from the query string alone;
         // sending the merged `pipeline` fragment as the YAML body matches the working UI path.
         if (
-          resourceType === "pipeline" &&
-          args.action === "run" &&
+          resolvesRunInputs &&
           hasInputSets &&
           (hasNoInlineRuntimeInputs(args.inputs) ||
             isResolvableInputs(args.inputs) ||
@@ -372,8 +375,7 @@ export function registerExecuteTool(server: McpServer, registry: Registry, clien
         let resolved: ResolutionResult | undefined;
 
         if (
-          resourceType === "pipeline" &&
-          args.action === "run" &&
+          resolvesRunInputs &&
           !materializedInputSets &&
           isResolvableInputs(args.inputs)
         ) {
@@ -477,7 +479,7 @@ export function registerExecuteTool(server: McpServer, registry: Registry, clien
 
             if (!pipelineId && input.execution_id) {
               try {
-                const exec = asRecord(await registry.dispatch(client, "execution", "get", input));
+                const exec = asRecord(await registry.dispatch(client, "execution", "get", { ...input, detail_level: "full" }));
                 const pes = asRecord(exec?.pipelineExecutionSummary);
                 pipelineId = asString(pes?.pipelineIdentifier);
               } catch {
@@ -506,6 +508,18 @@ export function registerExecuteTool(server: McpServer, registry: Registry, clien
           };
         }
 
+        // Surface the execution ID and a link to the execution for every v0
+        // pipeline run/retry. The resource-level openInHarness on this result
+        // points at the pipeline studio, not at the run that was just started.
+        if (effectiveResourceType === "pipeline" && (effectiveAction === "run" || effectiveAction === "retry")) {
+          const executionId = extractExecutionId(result, effectiveResourceType);
+          if (executionId) {
+            envelope.execution_id = executionId;
+            const executionUrl = buildExecutionUrl(registry, config, client, input, result, executionId);
+            if (executionUrl) envelope.execution_url = executionUrl;
+          }
+        }
+
         // Opt-in server-side wait for pipeline run/retry. Avoids the LLM
         // burning tokens on a polling loop — a single tool call returns the
         // terminal status.
@@ -600,6 +614,35 @@ export function registerExecuteTool(server: McpServer, registry: Registry, clien
   );
 }
 
+/**
+ * Deep link to a v0 pipeline execution, built from the `execution` resource's
+ * template. Undefined when the pipeline identifier cannot be determined (e.g.
+ * a retry called with only execution_id and a response without metadata).
+ */
+function buildExecutionUrl(
+  registry: Registry,
+  config: Config,
+  client: HarnessClient,
+  input: Record<string, unknown>,
+  result: unknown,
+  executionId: string,
+): string | undefined {
+  const template = registry.getResource("execution").deepLinkTemplate;
+  const planExec = asRecord(asRecord(result)?.planExecution);
+  const pipelineId = asString(input.pipeline_id)
+    ?? asString(asRecord(planExec?.metadata)?.pipelineIdentifier)
+    ?? asString(planExec?.planId);
+  const orgId = asString(input.org_id) || registry.orgId;
+  const projectId = asString(input.project_id) || registry.projectId;
+  if (!template || !pipelineId || !orgId || !projectId) return undefined;
+  return buildDeepLink(config.HARNESS_BASE_URL, client.account, template, {
+    orgIdentifier: orgId,
+    projectIdentifier: projectId,
+    pipelineIdentifier: pipelineId,
+    planExecutionId: executionId,
+  });
+}
+
 function normalizeRemotePipelineRunParams(input: Record<string, unknown>): void {
   input.store_type ??= input.storeType;
   input.connector_ref ??= input.connectorRef;
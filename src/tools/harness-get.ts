@@ -7,6 +7,7 @@ import { isUserError, isUserFixableApiError, toMcpError, enrichErrorWithHint, Ha
 import { applyUrlDefaults } from "../utils/url-parser.js";
 import { asString, coerceRecord } from "../utils/type-guards.js";
 import { resolveLogContent, resolveLogDownloadUrl } from "../utils/log-resolver.js";
+import { truncateLogText } from "../utils/log-truncate.js";
 import { buildLogPrefixFromExecution } from "../utils/log-prefix.js";
 import type { SearchManager } from "../search/index.js";
 import { buildResourceIndexContent } from "../search/embedding-content.js";
@@ -18,6 +19,16 @@ function isTrue(value: unknown): boolean {
   return value === true || value === "true";
 }
 
+/** Default and largest `max_chars` for execution_log content returned inline. */
+const LOG_DEFAULT_MAX_CHARS = 200_000;
+const LOG_MAX_CHARS_LIMIT = 2_000_000;
+
+function logMaxChars(value: unknown): number {
+  const n = Math.trunc(Number(value));
+  if (value === undefined || value === "" || !Number.isFinite(n) || n <= 0) return LOG_DEFAULT_MAX_CHARS;
+  return Math.min(n, LOG_MAX_CHARS_LIMIT);
+}
+
 export function registerGetTool(server: McpServer, registry: Registry, client: HarnessClient, searchManager?: SearchManager): void {
   const gettableTypes = registry.getTypesForOperation("get");
 
@@ -34,6 +45,7 @@ export function registerGetTool(server: McpServer, registry: Registry, client: H
         project_id: z.string().optional().describe("Project identifier (overrides default)"),
         params: z.record(z.string(), z.unknown()).optional().describe("Additional identifiers for nested resources. Call harness_describe for fields per resource_type."),
         return_download_url: z.union([z.boolean(), z.enum(["true", "false"])]).optional().describe("For execution_log only: return a directly fetchable log download URL instead of buffering log content."),
+        cache_bypass: z.boolean().optional().describe("Skip the response cache and fetch fresh data (only relevant when HARNESS_CACHE_TTL_MS is set)"),
       },
       outputSchema: getOutputSchema,
       annotations: {
@@ -43,7 +55,7 @@ export function registerGetTool(server: McpServer, registry: Registry, client: H
         openWorldHint: true,
       },
     },
-    async (args) => {
+    async (args, extra) => {
       try {
         const { params, ...rest } = args;
         const input = applyUrlDefaults(rest as Record<string, unknown>, args.url, { includeResourceScope: true });
@@ -104,14 +116,27 @@ export function registerGetTool(server: McpServer, registry: Registry, client: H
               return jsonResult({ download_url: downloadUrl });
             }
             const logText = await resolveLogContent(client, prefix);
-            return jsonResult({ log_content: logText });
+            const capped = truncateLogText(logText, logMaxChars(input.max_chars));
+            return jsonResult({
+              log_content: capped.text,
+              log_key: prefix,
+              ...(capped.truncated
+                ? {
+                  truncated: true,
+                  total_chars: capped.total_chars,
+                  total_lines: capped.total_lines,
+                  omitted_lines: capped.omitted_lines,
+                  hint: "Log truncated in the middle. Raise params.max_chars, or narrow to one step with params.step_id/stage_id.",
+                }
+                : {}),
+            });
           } catch (err) {
             const msg = err instanceof Error ? err.message : String(err);
             return errorResult(`Failed to resolve execution logs: ${msg}. Try harness_diagnose with include_logs=true for better failure analysis.`);
           }
         }
 
-        const result = await registry.dispatch(client, resourceType, "get", input);
+        const result = await registry.dispatch(client, resourceType, "get", input, extra.signal);
 
         // Fire-and-forget: index item for semantic search (skipped in multi-user + local)
         if (searchManager && result && typeof result === "object") {
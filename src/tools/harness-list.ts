@@ -8,12 +8,22 @@ import { compactItems } from "../utils/compact.js";
 import { applyUrlDefaults } from "../utils/url-parser.js";
 import { asString, isRecord, coerceRecord } from "../utils/type-guards.js";
 import type { SearchManager } from "../search/index.js";
+import type { Config } from "../config.js";
 import { buildResourceIndexContent } from "../search/embedding-content.js";
 import { buildEntityDocumentId, buildEntityMetadata, resolveEntityScope } from "../search/entity-index.js";
 import { resourceTypeSchema } from "./input-schemas.js";
 import { listOutputSchema } from "./output-schemas.js";
+import { listAcrossProjects } from "./list-across-projects.js";
+import { exportListToJsonl } from "./list-export.js";
+import { sendProgress } from "../utils/progress.js";
 
-export function registerListTool(server: McpServer, registry: Registry, client: HarnessClient, searchManager?: SearchManager): void {
+export function registerListTool(
+  server: McpServer,
+  registry: Registry,
+  client: HarnessClient,
+  searchManager?: SearchManager,
+  config?: Pick<Config, "HARNESS_FANOUT_CONCURRENCY" | "HARNESS_FANOUT_BUDGET_MS" | "HARNESS_SCOPE_GUARD">,
+): void {
   // Build a dynamic description for the filters param from all enabled resource definitions
   const allFilterNames = registry.getAllFilterFields().map((f) => f.name);
   const filtersDesc = allFilterNames.length > 0
@@ -38,6 +48,11 @@ export function registerListTool(server: McpServer, registry: Registry, client:
         compact: z.boolean().default(true).optional().describe("Strip verbose metadata from list items, keeping only essential fields (default true)"),
         params: z.record(z.string(), z.unknown()).optional().describe("Additional identifiers for nested resources (e.g. repo_id for pull requests). Call harness_describe for fields per resource_type."),
         filters: z.record(z.string(), z.unknown()).optional().describe(filtersDesc),
+        all_projects: z.boolean().optional().describe("Run this list in every project of the account (or of org_id) and merge the results; each item gains org_id and project_id. page/size apply per project. Large accounts may return partial=true with next_cursor"),
+        cursor: z.string().optional().describe("next_cursor from a partial all_projects response, to continue where it stopped"),
+        export: z.enum(["jsonl"]).optional().describe("Fetch every page and write the items to a JSONL file in output_dir instead of returning them. The response has only file, exported, pages, and total. Use for audits over large windows"),
+        output_dir: z.string().optional().describe("Absolute directory on the MCP server host for export, e.g. /var/exports or C:\\exports. ~ and (on Windows) %VAR% are expanded. Created if missing. When the server sets HARNESS_OUTPUT_ROOT it must be under that root, and relative paths resolve against it"),
+        cache_bypass: z.boolean().optional().describe("Skip the response cache and fetch fresh data (only relevant when HARNESS_CACHE_TTL_MS is set)"),
       },
       outputSchema: listOutputSchema,
       annotations: {
@@ -47,7 +62,7 @@ export function registerListTool(server: McpServer, registry: Registry, client:
         openWorldHint: true,
       },
     },
-    async (args) => {
+    async (args, extra) => {
       try {
         const { params, filters, ...rest } = args;
         const input = applyUrlDefaults(rest as Record<string, unknown>, args.url, { includeResourceScope: true });
@@ -64,7 +79,29 @@ export function registerListTool(server: McpServer, registry: Registry, client:
         if (resourceType === "template" && input.template_list_type === undefined) {
           input.template_list_type = "All";
         }
-        const rawResult = await registry.dispatch(client, resourceType, "list", input);
+        if (args.export) {
+          const outputDir = asString(args.output_dir);
+          if (!outputDir) return errorResult("output_dir is required with export. Pass an absolute directory on the server host.");
+          if (args.all_projects) return errorResult("export does not combine with all_projects. Export one project at a time.");
+          const exported = await exportListToJsonl(registry, client, resourceType, input, {
+            outputDir,
+            signal: extra.signal,
+            onProgress: (count, total) => sendProgress(extra, count, total, `Exported ${count}${total !== undefined ? ` of ${total}` : ""} ${resourceType} items...`),
+          });
+          return jsonResult(exported);
+        }
+        if (args.all_projects) {
+          const merged = await listAcrossProjects(registry, client, resourceType, input, {
+            HARNESS_FANOUT_CONCURRENCY: config?.HARNESS_FANOUT_CONCURRENCY ?? 8,
+            HARNESS_FANOUT_BUDGET_MS: config?.HARNESS_FANOUT_BUDGET_MS ?? 25_000,
+            HARNESS_SCOPE_GUARD: config?.HARNESS_SCOPE_GUARD,
+          });
+          if (args.compact !== false && Array.isArray(merged.items)) {
+            merged.items = compactItems(merged.items, registry.getResource(resourceType).compactItem);
+          }
+          return jsonResult(merged);
+        }
+        const rawResult = await registry.dispatch(client, resourceType, "list", input, extra.signal);
         const page = typeof args.page === "number" ? args.page : 0;
         const result = normalizeHarnessListPayload(rawResult, { page });
 
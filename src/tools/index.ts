@@ -16,11 +16,32 @@ import { registerStatusTool } from "./harness-status.js";
 import { registerSchemaTool } from "./harness-schema.js";
 import type { SchemaEntry } from "../data/schemas/types.js";
 import type { SearchManager } from "../search/index.js";
+import { withContextCost } from "../utils/context-cost.js";
+import { withResultLocalization } from "../utils/result-localization.js";
+import { withResultProcessors } from "../utils/result-processors.js";
+import { withRequestCancellation } from "../utils/request-signal.js";
+import { withTimeFields } from "../utils/time-fields.js";
+import { withToolDescriptions } from "../utils/tool-descriptions.js";
+import { withToolErrorLogging } from "../utils/support-bundle.js";
 import "../data/examples/load-all.js";
 
 
-export function registerAllTools(server: McpServer, registry: Registry, client: HarnessClient, config: Config, additionalSchemas?: Record<string, SchemaEntry>, searchManager?: SearchManager): void {
-  registerListTool(server, registry, client, searchManager);
+export function registerAllTools(mcpServer: McpServer, registry: Registry, client: HarnessClient, config: Config, additionalSchemas?: Record<string, SchemaEntry>, searchManager?: SearchManager): void {
+  // Time fields are normalized first, then configured post-processors run on
+  // every tool result, then composite tool summaries are translated when a
+  // locale is requested (after redaction, so nothing redacted reaches the model),
+  // then the result is measured for the context cost report and, if it is an
+  // error, logged for support bundles. Tool descriptions are recorded, and
+  // shortened in short mode, on the way in. The whole chain runs with the
+  // call's abort signal active, so a cancelled call stops its upstream requests.
+  const server = withToolDescriptions(
+    withTimeFields(
+      withResultProcessors(withResultLocalization(withContextCost(withToolErrorLogging(withRequestCancellation(mcpServer))))),
+      config.HARNESS_TIME_FORMAT,
+    ),
+    config.HARNESS_TOOL_DESCRIPTIONS,
+  );
+  registerListTool(server, registry, client, searchManager, config);
   registerGetTool(server, registry, client, searchManager);
   registerCreateTool(server, registry, client, config);
   registerUpdateTool(server, registry, client, config);
@@ -0,0 +1,137 @@
+import type { Config } from "../config.js";
+import type { Registry } from "../registry/index.js";
+import type { HarnessClient } from "../client/harness-client.js";
+import { decodeContinuation, encodeContinuation, fanOut } from "../utils/fan-out.js";
+import { normalizeHarnessListPayload } from "../utils/response-formatter.js";
+import { isCrossScopeAllowed } from "../utils/scope-guard.js";
+import { isRecord } from "../utils/type-guards.js";
+
+/** Upper bound on projects enumerated for one account-wide list. */
+const MAX_PROJECTS = 5000;
+const PROJECT_PAGE_SIZE = 100;
+
+type FanOutConfig = Pick<Config, "HARNESS_FANOUT_CONCURRENCY" | "HARNESS_FANOUT_BUDGET_MS">
+  & Partial<Pick<Config, "HARNESS_SCOPE_GUARD">>;
+
+interface ProjectRef {
+  org_id: string;
+  project_id: string;
+}
+
+/**
+ * Enumerate every project in the account (or in `orgId`), sorted so that a
+ * continuation index means the same project on the next call.
+ */
+async function listProjects(
+  registry: Registry,
+  client: HarnessClient,
+  orgId: string | undefined,
+  signal?: AbortSignal,
+): Promise<{ projects: ProjectRef[]; truncated: boolean }> {
+  const projects: ProjectRef[] = [];
+  let total = 0;
+  for (let page = 0; projects.length < MAX_PROJECTS; page++) {
+    const raw = await registry.dispatch(client, "project", "list", {
+      ...(orgId ? { org_id: orgId } : {}),
+      page,
+      size: PROJECT_PAGE_SIZE,
+    }, signal);
+    const { items = [], total: reported } = (raw ?? {}) as { items?: unknown[]; total?: number };
+    total = typeof reported === "number" ? reported : total;
+    for (const item of items) {
+      if (!isRecord(item)) continue;
+      const org = item.orgIdentifier;
+      const project = item.identifier;
+      if (typeof org === "string" && typeof project === "string") projects.push({ org_id: org, project_id: project });
+    }
+    if (items.length < PROJECT_PAGE_SIZE || (page + 1) * PROJECT_PAGE_SIZE >= total) break;
+  }
+  projects.sort((a, b) => a.org_id.localeCompare(b.org_id) || a.project_id.localeCompare(b.project_id));
+  return { projects: projects.slice(0, MAX_PROJECTS), truncated: total > MAX_PROJECTS || projects.length > MAX_PROJECTS };
+}
+
+/**
+ * harness_list with `all_projects: true`: run the same list in every project
+ * of the account (or of `org_id`) with bounded concurrency. When the time
+ * budget runs out the partial result carries `next_cursor`; pass it back as
+ * `cursor` to continue with the next project.
+ */
+export async function listAcrossProjects(
+  registry: Registry,
+  client: HarnessClient,
+  resourceType: string,
+  input: Record<string, unknown>,
+  config: FanOutConfig,
+  signal?: AbortSignal,
+): Promise<Record<string, unknown>> {
+  const def = registry.getResource(resourceType);
+  if (!registry.getSupportedScopes(resourceType).includes("project")) {
+    throw new Error(`all_projects applies to project-scoped resource types; "${resourceType}" is ${def.scope}-scoped. List it without all_projects.`);
+  }
+  if (config.HARNESS_SCOPE_GUARD === "block" && (registry.orgId || registry.projectId) && !isCrossScopeAllowed(input)) {
+    throw new Error(
+      "all_projects reaches outside this session's pinned org/project (HARNESS_SCOPE_GUARD=block). " +
+      "Pass cross_scope: true in params to confirm the account-wide request.",
+    );
+  }
+
+  const { all_projects: _all, cursor, project_id: _project, resource_scope: _scope, ...listInput } = input;
+  const orgId = typeof input.org_id === "string" && input.org_id ? input.org_id : undefined;
+
+  let startIndex = 0;
+  if (typeof cursor === "string" && cursor) {
+    const state = decodeContinuation(cursor);
+    if (state.resource_type !== resourceType || (state.org_id ?? undefined) !== orgId || typeof state.index !== "number") {
+      throw new Error("This cursor belongs to a different all_projects request. Repeat the original resource_type and org_id, or drop the cursor to start over.");
+    }
+    startIndex = state.index;
+  }
+
+  let enumeration: Awaited<ReturnType<typeof listProjects>>;
+  try {
+    enumeration = await listProjects(registry, client, orgId, signal);
+  } catch (err) {
+    if (err instanceof Error && err.message.startsWith("Unknown resource_type")) {
+      throw new Error("all_projects needs the platform toolset to enumerate projects — add it to HARNESS_TOOLSETS.");
+    }
+    throw err;
+  }
+  const { projects, truncated } = enumeration;
+  const page = typeof input.page === "number" ? input.page : 0;
+  const scan = await fanOut(
+    projects,
+    async (project, laneSignal) => {
+      const raw = await registry.dispatch(client, resourceType, "list", {
+        ...listInput,
+        org_id: project.org_id,
+        project_id: project.project_id,
+        ...(def.scope !== "project" ? { resource_scope: "project" } : {}),
+        cross_scope: true,
+      }, laneSignal);
+      const normalized = normalizeHarnessListPayload(raw, { page });
+      const items = isRecord(normalized) && Array.isArray(normalized.items) ? normalized.items : [];
+      return items.map((item) => isRecord(item) ? { ...item, org_id: project.org_id, project_id: project.project_id } : item);
+    },
+    { concurrency: config.HARNESS_FANOUT_CONCURRENCY, budgetMs: config.HARNESS_FANOUT_BUDGET_MS, startIndex, signal },
+  );
+
+  const items = scan.results.flatMap((r) => r.value);
+  const scanned = startIndex + scan.processed;
+  const result: Record<string, unknown> = {
+    items,
+    total: items.length,
+    projects_scanned: scanned,
+    projects_total: projects.length,
+    ...(truncated ? { projects_truncated: true } : {}),
+    ...(scan.errors.length > 0
+      ? { errors: scan.errors.map((e) => ({ org_id: e.item.org_id, project_id: e.item.project_id, error: e.error })) }
+      : {}),
+  };
+  if (scan.nextIndex !== undefined) {
+    result.partial = true;
+    result.next_cursor = encodeContinuation({ resource_type: resourceType, org_id: orgId, index: scan.nextIndex });
+    result._hint = `Time budget reached after ${scanned} of ${projects.length} projects. ` +
+      "Call harness_list again with the same arguments plus cursor=next_cursor to continue.";
+  }
+  return result;
+}
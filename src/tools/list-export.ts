@@ -0,0 +1,123 @@
+import { open, rm } from "node:fs/promises";
+import { mkdirSync } from "node:fs";
+import { join } from "node:path";
+import type { Registry } from "../registry/index.js";
+import type { HarnessClient } from "../client/harness-client.js";
+import { normalizeHarnessListPayload } from "../utils/response-formatter.js";
+import { moveIntoPlace, outputTempPath, resolveToolOutputDir, safeFileName } from "../utils/output-paths.js";
+import { isRecord } from "../utils/type-guards.js";
+
+/** Page size used for exports unless the caller passes a smaller `size`. */
+const EXPORT_PAGE_SIZE = 100;
+/** Upper bound on pages fetched for one export, so an API that ignores `page` cannot loop forever. */
+const MAX_EXPORT_PAGES = 10_000;
+
+export interface ListExportOptions {
+  /** Directory to write the file to; resolved with resolveToolOutputDir. */
+  outputDir: string;
+  signal?: AbortSignal;
+  /** Called after each page is written with the running count and the reported total, if any. */
+  onProgress?: (exported: number, total: number | undefined) => Promise<void> | void;
+  /** Clock for the file name; tests pin it. */
+  now?: () => Date;
+}
+
+export interface ListExportResult {
+  file: string;
+  resource_type: string;
+  format: "jsonl";
+  exported: number;
+  pages: number;
+  total?: number;
+  truncated?: boolean;
+  _hint?: string;
+}
+
+/**
+ * harness_list with `export: "jsonl"`: fetch every page of a listing and
+ * append its items to `<output_dir>/harness-<resource_type>-<timestamp>.jsonl`,
+ * one JSON object per line. Only the current page is held in memory, and the
+ * response carries the file path and counts instead of the items, so audits
+ * over thousands of executions or audit events stay out of the context
+ * window. The file is written under a temp name (in HARNESS_TEMP_DIR when set)
+ * and moved into place when the last page is in, so a cancelled or failed
+ * export leaves nothing behind.
+ */
+export async function exportListToJsonl(
+  registry: Registry,
+  client: HarnessClient,
+  resourceType: string,
+  input: Record<string, unknown>,
+  options: ListExportOptions,
+): Promise<ListExportResult> {
+  const dir = resolveToolOutputDir(options.outputDir);
+  try {
+    mkdirSync(dir, { recursive: true });
+  } catch (err) {
+    throw new Error(`Cannot create output directory "${dir}": ${(err as Error).message}`);
+  }
+  const stamp = (options.now ?? (() => new Date()))().toISOString().replace(/[:.]/g, "-");
+  const file = join(dir, safeFileName(`harness-${resourceType}-${stamp}.jsonl`));
+  const temp = outputTempPath(file);
+
+  const { export: _export, output_dir: _dir, page: _page, ...listInput } = input;
+  const requested = typeof input.size === "number" && input.size > 0 ? input.size : EXPORT_PAGE_SIZE;
+  const size = Math.min(requested, EXPORT_PAGE_SIZE);
+
+  let exported = 0;
+  let pages = 0;
+  let total: number | undefined;
+  let truncated = false;
+  let previousFirst: string | undefined;
+  const handle = await open(temp, "w");
+  try {
+    for (let page = 0; ; page++) {
+      if (page >= MAX_EXPORT_PAGES) {
+        truncated = true;
+        break;
+      }
+      options.signal?.throwIfAborted();
+      const raw = await registry.dispatch(client, resourceType, "list", { ...listInput, page, size }, options.signal);
+      const normalized = normalizeHarnessListPayload(raw, { page });
+      const items = isRecord(normalized) && Array.isArray(normalized.items) ? normalized.items : [];
+      // normalizeHarnessListPayload fills in the page length and pageExtract a 0
+      // when the API reports no total, so only a positive raw total bounds the export.
+      if (isRecord(raw) && typeof raw.total === "number" && raw.total > 0) total = raw.total;
+      if (items.length === 0) break;
+
+      // An endpoint without server-side paging returns the same page for every
+      // `page` value; stop instead of writing duplicates.
+      const first = JSON.stringify(items[0]);
+      if (page > 0 && first === previousFirst) break;
+      previousFirst = first;
+
+      await handle.appendFile(items.map((item) => JSON.stringify(item)).join("\n") + "\n");
+      exported += items.length;
+      pages++;
+      await options.onProgress?.(exported, total);
+
+      if (items.length < size || (total !== undefined && exported >= total)) break;
+    }
+    await handle.close();
+    moveIntoPlace(temp, file);
+  } catch (err) {
+    await handle.close().catch(() => { /* already closed */ });
+    await rm(temp, { force: true });
+    throw err;
+  }
+
+  return {
+    file,
+    resource_type: resourceType,
+    format: "jsonl",
+    exported,
+    pages,
+    ...(total !== undefined ? { total } : {}),
+    ...(truncated
+      ? {
+        truncated: true,
+        _hint: `Stopped after ${MAX_EXPORT_PAGES} pages. Narrow the window with filters and export the rest separately.`,
+      }
+      : {}),
+  };
+}
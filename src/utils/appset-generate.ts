@@ -0,0 +1,283 @@
+/**
+ * Offline ApplicationSet rendering: expand generators into parameter sets and
+ * render the Application template once per set, the way the Argo CD
+ * ApplicationSet controller would.
+ *
+ * Used to preview the applications an ApplicationSet change would create or
+ * remove before it is applied. List, clusters, matrix, and merge generators
+ * are expanded here (clusters from the agent's registered clusters). Git,
+ * SCM provider, pull request, and plugin generators need access the MCP
+ * server does not have, so they are reported as unrendered instead of guessed.
+ */
+import { isRecord } from "./type-guards.js";
+
+/** One parameter set produced by a generator. */
+export type AppSetParams = Record<string, unknown>;
+
+/** A cluster registered with the agent, as seen by the clusters generator. */
+export interface AppSetCluster {
+  name: string;
+  server: string;
+  labels: Record<string, string>;
+  annotations: Record<string, string>;
+}
+
+export interface AppSetRenderResult {
+  /** Rendered Application objects ({metadata, spec}), in generator order. */
+  applications: Array<Record<string, unknown>>;
+  /** Generators that could not be expanded offline, with why. */
+  unrendered: Array<{ generator: string; reason: string }>;
+  /** Template expressions that could not be resolved and other caveats. */
+  warnings: string[];
+}
+
+const OFFLINE_UNSUPPORTED: Record<string, string> = {
+  git: "reads directories or files from the Git repository",
+  scmProvider: "queries the SCM provider for repositories",
+  pullRequest: "queries the SCM provider for open pull requests",
+  plugin: "calls an external generator plugin",
+  clusterDecisionResource: "reads a cluster decision resource from the cluster",
+};
+
+/**
+ * Decode an `apiextensionsv1.JSON` field the GitOps API returns as
+ * `{ raw: "<base64 JSON>" }`. Plain values pass through.
+ */
+export function decodeJsonField(value: unknown): unknown {
+  if (!isRecord(value) || typeof value.raw !== "string" || Object.keys(value).length !== 1) return value;
+  try {
+    return JSON.parse(Buffer.from(value.raw, "base64").toString("utf-8"));
+  } catch {
+    return value;
+  }
+}
+
+/** Argo CD's `nameNormalized`: lowercase, with characters outside [a-z0-9-.] replaced by "-". */
+function normalizeName(name: string): string {
+  return name.toLowerCase().replace(/[^a-z0-9.-]/g, "-");
+}
+
+/** Whether a cluster's labels satisfy a Kubernetes LabelSelector. An empty selector matches every cluster. */
+export function matchesLabelSelector(labels: Record<string, string>, selector: unknown): boolean {
+  if (!isRecord(selector)) return true;
+  if (isRecord(selector.matchLabels)) {
+    for (const [key, value] of Object.entries(selector.matchLabels)) {
+      if (labels[key] !== String(value)) return false;
+    }
+  }
+  for (const expr of Array.isArray(selector.matchExpressions) ? selector.matchExpressions.filter(isRecord) : []) {
+    const key = String(expr.key ?? "");
+    const values = Array.isArray(expr.values) ? expr.values.map(String) : [];
+    const has = Object.prototype.hasOwnProperty.call(labels, key);
+    switch (expr.operator) {
+      case "In": if (!has || !values.includes(labels[key]!)) return false; break;
+      case "NotIn": if (has && values.includes(labels[key]!)) return false; break;
+      case "Exists": if (!has) return false; break;
+      case "DoesNotExist": if (has) return false; break;
+    }
+  }
+  return true;
+}
+
+/** Flatten nested params to dotted keys, as the non-Go-template renderer sees them. */
+function flattenParams(params: AppSetParams, prefix = "", out: Record<string, string> = {}): Record<string, string> {
+  for (const [key, value] of Object.entries(params)) {
+    const path = prefix ? `${prefix}.${key}` : key;
+    if (isRecord(value)) flattenParams(value, path, out);
+    else if (Array.isArray(value)) out[path] = value.join(",");
+    else if (value !== undefined && value !== null) out[path] = String(value);
+  }
+  return out;
+}
+
+/** Look up a dotted path (`.path.basename`) in nested params. */
+function lookupPath(params: AppSetParams, path: string): unknown {
+  let current: unknown = params;
+  for (const part of path.split(".").filter(Boolean)) {
+    if (!isRecord(current)) return undefined;
+    current = current[part];
+  }
+  return current;
+}
+
+/** Evaluate one Go template action; undefined when the expression is beyond this renderer. */
+function evalGoExpression(expr: string, params: AppSetParams): { value: unknown } | undefined {
+  const [head, ...pipes] = expr.split("|").map((part) => part.trim());
+  let value: unknown;
+  const index = /^index\s+(\.[\w.]*)\s+(?:"([^"]*)"|(\d+))$/.exec(head!);
+  if (index) {
+    const base = lookupPath(params, index[1]!);
+    value = index[3] !== undefined
+      ? (Array.isArray(base) ? base[Number(index[3])] : undefined)
+      : (isRecord(base) ? base[index[2]!] : undefined);
+  } else if (/^\.[\w.]*$/.test(head!)) {
+    value = lookupPath(params, head!);
+  } else if (/^"[^"]*"$/.test(head!)) {
+    value = head!.slice(1, -1);
+  } else {
+    return undefined;
+  }
+  for (const pipe of pipes) {
+    const fallback = /^default\s+"([^"]*)"$/.exec(pipe);
+    if (fallback) value = value === undefined || value === null || value === "" ? fallback[1] : value;
+    else if (pipe === "lower") value = String(value ?? "").toLowerCase();
+    else if (pipe === "upper") value = String(value ?? "").toUpperCase();
+    else if (pipe === "normalize") value = normalizeName(String(value ?? ""));
+    else return undefined;
+  }
+  return { value };
+}
+
+/** Render template placeholders in one string. Unresolvable ones are kept and noted in `warnings`. */
+function renderString(text: string, params: AppSetParams, goTemplate: boolean, warnings: Set<string>): string {
+  if (goTemplate) {
+    return text.replace(/\{\{-?\s*(.*?)\s*-?\}\}/g, (match, expr: string) => {
+      const result = evalGoExpression(expr, params);
+      if (!result) {
+        warnings.add(`Template expression ${match} is not supported by the preview and was left as is.`);
+        return match;
+      }
+      if (result.value === undefined) {
+        warnings.add(`${match} has no value in some parameter sets; Argo CD renders "<no value>".`);
+        return "<no value>";
+      }
+      return typeof result.value === "object" ? JSON.stringify(result.value) : String(result.value);
+    });
+  }
+  const flat = flattenParams(params);
+  return text.replace(/\{\{\s*([\w.-]+)\s*\}\}/g, (match, key: string) => {
+    if (key in flat) return flat[key]!;
+    warnings.add(`${match} has no value in some parameter sets and was left as is.`);
+    return match;
+  });
+}
+
+function renderValue(value: unknown, params: AppSetParams, goTemplate: boolean, warnings: Set<string>): unknown {
+  if (typeof value === "string") return renderString(value, params, goTemplate, warnings);
+  if (Array.isArray(value)) return value.map((v) => renderValue(v, params, goTemplate, warnings));
+  if (isRecord(value)) {
+    const out: Record<string, unknown> = {};
+    for (const [key, v] of Object.entries(value)) out[key] = renderValue(v, params, goTemplate, warnings);
+    return out;
+  }
+  return value;
+}
+
+/** Deep-merge a generator's template override onto the ApplicationSet template. */
+function mergeTemplate(base: unknown, override: unknown): unknown {
+  if (!isRecord(base) || !isRecord(override)) return override === undefined ? base : override;
+  const out: Record<string, unknown> = { ...base };
+  for (const [key, value] of Object.entries(override)) out[key] = mergeTemplate(base[key], value);
+  return out;
+}
+
+/** Generator type: the one key besides `selector`, `template`, and `values`. */
+function generatorType(generator: Record<string, unknown>): string {
+  return Object.keys(generator).find((key) => key !== "selector" && key !== "template" && key !== "values") ?? "unknown";
+}
+
+/** `values` from a generator, exposed to templates as `values.<key>`. */
+function withValues(params: AppSetParams, spec: Record<string, unknown>): AppSetParams {
+  return isRecord(spec.values) ? { ...params, values: spec.values } : params;
+}
+
+/**
+ * Expand one generator into parameter sets. Returns undefined (and records
+ * why in `unrendered`) when the generator cannot be expanded offline.
+ */
+function expandGenerator(
+  generator: Record<string, unknown>,
+  clusters: readonly AppSetCluster[],
+  unrendered: AppSetRenderResult["unrendered"],
+): AppSetParams[] | undefined {
+  const type = generatorType(generator);
+  const spec = decodeJsonField(generator[type]);
+  if (!isRecord(spec)) {
+    unrendered.push({ generator: type, reason: "generator has no settings" });
+    return undefined;
+  }
+  let sets: AppSetParams[] | undefined;
+  switch (type) {
+    case "list": {
+      const elements = Array.isArray(spec.elements) ? spec.elements.map(decodeJsonField).filter(isRecord) : [];
+      sets = elements.map((element) => withValues(element, spec));
+      break;
+    }
+    case "clusters":
+      sets = clusters
+        .filter((cluster) => matchesLabelSelector(cluster.labels, spec.selector))
+        .map((cluster) => withValues({
+          name: cluster.name,
+          nameNormalized: normalizeName(cluster.name),
+          server: cluster.server,
+          metadata: { labels: cluster.labels, annotations: cluster.annotations },
+        }, spec));
+      break;
+    case "matrix": {
+      const children = Array.isArray(spec.generators) ? spec.generators.map(decodeJsonField).filter(isRecord) : [];
+      const expanded = children.map((child) => expandGenerator(child, clusters, unrendered));
+      if (children.length !== 2 || expanded.some((e) => e === undefined)) {
+        if (children.length !== 2) unrendered.push({ generator: "matrix", reason: "a matrix needs exactly two generators" });
+        return undefined;
+      }
+      sets = expanded[0]!.flatMap((left) => expanded[1]!.map((right) => ({ ...left, ...right })));
+      break;
+    }
+    case "merge": {
+      const children = Array.isArray(spec.generators) ? spec.generators.map(decodeJsonField).filter(isRecord) : [];
+      const keys = Array.isArray(spec.mergeKeys) ? spec.mergeKeys.map(String) : [];
+      const expanded = children.map((child) => expandGenerator(child, clusters, unrendered));
+      if (children.length < 2 || keys.length === 0 || expanded.some((e) => e === undefined)) {
+        if (children.length < 2 || keys.length === 0) unrendered.push({ generator: "merge", reason: "a merge needs two or more generators and mergeKeys" });
+        return undefined;
+      }
+      const keyOf = (params: AppSetParams) => JSON.stringify(keys.map((key) => lookupPath(params, key)));
+      sets = expanded[0]!.map((base) => {
+        let merged = base;
+        for (const other of expanded.slice(1)) {
+          const match = other!.find((params) => keyOf(params) === keyOf(base));
+          if (match) merged = { ...merged, ...match };
+        }
+        return merged;
+      });
+      break;
+    }
+    default:
+      unrendered.push({
+        generator: type,
+        reason: OFFLINE_UNSUPPORTED[type]
+          ? `${type} generator ${OFFLINE_UNSUPPORTED[type]}, which the preview cannot do`
+          : `unknown generator type "${type}"`,
+      });
+      return undefined;
+  }
+  return sets;
+}
+
+/**
+ * Render the Applications an ApplicationSet would generate. `clusters` feeds
+ * the clusters generator; pass the agent's registered clusters.
+ */
+export function renderApplicationSet(
+  appset: Record<string, unknown>,
+  clusters: readonly AppSetCluster[] = [],
+): AppSetRenderResult {
+  const spec = isRecord(appset.spec) ? appset.spec : {};
+  const goTemplate = spec.goTemplate === true;
+  const unrendered: AppSetRenderResult["unrendered"] = [];
+  const warnings = new Set<string>();
+  const applications: Array<Record<string, unknown>> = [];
+  if (spec.templatePatch !== undefined) warnings.add("spec.templatePatch is not applied by the preview.");
+
+  for (const generator of Array.isArray(spec.generators) ? spec.generators.filter(isRecord) : []) {
+    const sets = expandGenerator(generator, clusters, unrendered);
+    if (!sets) continue;
+    if (generator.selector !== undefined) warnings.add("Generator post-selectors are not applied by the preview.");
+    const template = mergeTemplate(spec.template, generator.template);
+    for (const params of sets) {
+      const rendered = renderValue(template, params, goTemplate, warnings);
+      if (isRecord(rendered)) applications.push(rendered);
+    }
+  }
+  return { applications, unrendered, warnings: [...warnings] };
+}
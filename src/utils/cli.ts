@@ -6,15 +6,24 @@ import { dirname, resolve } from "node:path";
  * CLI argument parsing for transport selection and port configuration.
  */
 
-export type Transport = "stdio" | "http";
+export type Transport = "stdio" | "http" | "sse";
+
+export type Command = "serve" | "support-bundle" | "run-playbook";
 
 export interface CliArgs {
+  command: Command;
   transport: Transport;
   port: number;
   envFile?: string;
+  /** support-bundle: directory the archive is written to. */
+  outputDir?: string;
+  /** run-playbook: path of the playbook YAML. */
+  playbookFile?: string;
+  /** run-playbook: `key=value` overrides for the playbook's vars. */
+  playbookVars?: string[];
 }
 
-const VALID_TRANSPORTS = new Set<string>(["stdio", "http"]);
+const VALID_TRANSPORTS = new Set<string>(["stdio", "http", "sse"]);
 const DEFAULT_PORT = 3000;
 const MIN_PORT = 1;
 const MAX_PORT = 65535;
@@ -23,11 +32,27 @@ const HELP_TEXT = `
 harness-mcp-server — MCP server for Harness.io CI/CD platform
 
 Usage:
-  harness-mcp-server [stdio|http] [options]
+  harness-mcp-server [stdio|http|sse] [options]
+  harness-mcp-server support-bundle [--output <dir>] [--env-file <path>]
+  harness-mcp-server run-playbook <file.yaml> [--var key=value]... [--env-file <path>]
+
+Transports:
+  stdio                 Standard input/output (default)
+  http                  Streamable HTTP on /mcp
+  sse                   Streamable HTTP plus legacy SSE (GET /sse, POST /messages)
+                        for older MCP clients
+
+Commands:
+  support-bundle        Write a sanitized diagnostics archive (version, config,
+                        recent logs, connectivity checks) for support tickets
+  run-playbook          Run a YAML sequence of tool calls with assertions and
+                        print a JSON report; exits 1 if any step fails
 
 Options:
-  --port <number>       Port for HTTP transport (default: 3000, or PORT env var)
+  --port <number>       Port for HTTP/SSE transport (default: 3000, or PORT env var)
   --env-file <path>     Path to .env file (default: .env in current directory)
+  --output <dir>        Directory for the support bundle (default: current directory)
+  --var key=value       Playbook variable override (repeatable)
   --help                Show this help message and exit
   --version             Print version and exit
 
@@ -49,7 +74,9 @@ export function getVersion(): string {
  * Parse CLI arguments for transport mode and port.
  *
  * Usage:
- *   node build/index.js [stdio|http] [--port <number>]
+ *   node build/index.js [stdio|http|sse] [--port <number>]
+ *   node build/index.js support-bundle [--output <dir>]
+ *   node build/index.js run-playbook <file> [--var key=value]...
  *
  * - Transport defaults to "stdio" if not specified.
  * - Port defaults to --port flag, then PORT env var, then 3000.
@@ -66,30 +93,48 @@ export function parseArgs(argv: string[] = process.argv.slice(2)): CliArgs {
     process.exit(0);
   }
 
-  const transport = parseTransport(argv);
   const port = resolvePort(argv);
-  const envFile = parseEnvFile(argv);
-  return { transport, port, envFile };
+  const envFile = parseFlag(argv, "--env-file");
+  if (firstPositional(argv) === "support-bundle") {
+    return { command: "support-bundle", transport: "stdio", port, envFile, outputDir: parseFlag(argv, "--output") };
+  }
+  if (firstPositional(argv) === "run-playbook") {
+    const playbookFile = positionals(argv)[1];
+    if (!playbookFile) throw new Error("run-playbook needs the path of a playbook file");
+    return { command: "run-playbook", transport: "stdio", port, envFile, playbookFile, playbookVars: parseFlagAll(argv, "--var") };
+  }
+  const transport = parseTransport(argv);
+  return { command: "serve", transport, port, envFile };
 }
 
-function parseTransport(argv: string[]): Transport {
-  // First positional arg that isn't a flag or flag value
+function positionals(argv: string[]): string[] {
+  // Args that aren't flags or flag values
+  const result: string[] = [];
   for (let i = 0; i < argv.length; i++) {
     const arg = argv[i]!;
-    if (arg === "--port" || arg === "--env-file") {
+    if (arg === "--port" || arg === "--env-file" || arg === "--output" || arg === "--var") {
       i++; // skip the value after the flag
       continue;
     }
     if (arg.startsWith("-")) continue;
+    result.push(arg);
+  }
+  return result;
+}
 
-    if (!VALID_TRANSPORTS.has(arg)) {
-      throw new Error(
-        `Unknown transport: "${arg}". Supported: stdio, http`,
-      );
-    }
-    return arg as Transport;
+function firstPositional(argv: string[]): string | undefined {
+  return positionals(argv)[0];
+}
+
+function parseTransport(argv: string[]): Transport {
+  const arg = firstPositional(argv);
+  if (arg === undefined) return "stdio";
+  if (!VALID_TRANSPORTS.has(arg)) {
+    throw new Error(
+      `Unknown transport: "${arg}". Supported: stdio, http, sse`,
+    );
   }
-  return "stdio";
+  return arg as Transport;
 }
 
 export function resolvePort(argv: string[] = process.argv.slice(2)): number {
@@ -114,18 +159,28 @@ function isValidPort(n: number): boolean {
   return Number.isInteger(n) && n >= MIN_PORT && n <= MAX_PORT;
 }
 
-function parseEnvFile(argv: string[]): string | undefined {
-  // Check --env-file flag (supports both space-separated and = syntax)
+function parseFlagAll(argv: string[], flag: "--var"): string[] {
+  const values: string[] = [];
+  for (let i = 0; i < argv.length; i++) {
+    const arg = argv[i]!;
+    if (arg.startsWith(`${flag}=`)) values.push(arg.slice(flag.length + 1));
+    else if (arg === flag && i + 1 < argv.length) values.push(argv[++i]!);
+  }
+  return values;
+}
+
+function parseFlag(argv: string[], flag: "--env-file" | "--output"): string | undefined {
+  // Supports both space-separated and = syntax
   for (let i = 0; i < argv.length; i++) {
     const arg = argv[i]!;
 
-    // Handle --env-file=path
-    if (arg.startsWith("--env-file=")) {
-      return arg.slice("--env-file=".length);
+    // Handle --flag=value
+    if (arg.startsWith(`${flag}=`)) {
+      return arg.slice(flag.length + 1);
     }
 
-    // Handle --env-file path 
-    if ((arg === "--env-file") && i + 1 < argv.length) {
+    // Handle --flag value
+    if (arg === flag && i + 1 < argv.length) {
       return argv[i + 1]!;
     }
   }
@@ -0,0 +1,118 @@
+/**
+ * Configuration reload without a restart (SIGHUP or POST /admin/reload-config).
+ *
+ * A reload re-reads the .env file and the environment, validates the result
+ * as at startup, and applies only the settings that are safe to change on a
+ * running server:
+ *
+ *   live          process-wide settings applied immediately: log level, tool
+ *                 rate limits, context cost sampling, result processors
+ *   new_sessions  settings read when a session is created: toolsets, read-only
+ *                 mode, default org/project, cache, timeouts. Open sessions
+ *                 keep what they started with, so nothing is dropped.
+ *
+ * Other changes (credentials, base URL, transport, auth) are reported as
+ * `restart_required` and not applied. An invalid configuration rejects the
+ * whole reload and the running configuration stays in place.
+ */
+import { loadConfig, type Config } from "../config.js";
+import { reloadEnvFile } from "./env.js";
+import { createLogger } from "./logger.js";
+
+const log = createLogger("config-reload");
+
+export type ReloadScope = "live" | "new_sessions";
+
+/** Settings a reload may change, and when the change takes effect. */
+export const RELOADABLE_SETTINGS: Partial<Record<keyof Config, ReloadScope>> = {
+  LOG_LEVEL: "live",
+  HARNESS_TOOL_RATE_LIMIT_PER_MIN: "live",
+  HARNESS_TOOL_RATE_LIMIT_PER_TOOL_PER_MIN: "live",
+  HARNESS_TOOL_RATE_LIMITS: "live",
+  HARNESS_METRICS_MAX_ACCOUNTS: "live",
+  HARNESS_CONTEXT_COST_SAMPLE_RATE: "live",
+  HARNESS_RESULT_PROCESSORS: "live",
+  HARNESS_TOOLSETS: "new_sessions",
+  HARNESS_READ_ONLY: "new_sessions",
+  HARNESS_SKIP_ELICITATION: "new_sessions",
+  HARNESS_AUTO_APPROVE_RISK: "new_sessions",
+  HARNESS_ORG: "new_sessions",
+  HARNESS_PROJECT: "new_sessions",
+  HARNESS_PIPELINE_VERSION: "new_sessions",
+  HARNESS_API_TIMEOUT_MS: "new_sessions",
+  HARNESS_MAX_RETRIES: "new_sessions",
+  HARNESS_CACHE_TTL_MS: "new_sessions",
+  HARNESS_CACHE_MAX_ENTRIES: "new_sessions",
+  HARNESS_CACHE_TOOLSETS: "new_sessions",
+  HARNESS_FANOUT_CONCURRENCY: "new_sessions",
+  HARNESS_FANOUT_BUDGET_MS: "new_sessions",
+  HARNESS_TIME_FORMAT: "new_sessions",
+  HARNESS_TOOL_DESCRIPTIONS: "new_sessions",
+  HARNESS_SEI_DEVELOPER_METRICS: "new_sessions",
+  HARNESS_SEI_MIN_GROUP_SIZE: "new_sessions",
+};
+
+export interface ConfigReloadResult {
+  /** Changed settings now in effect, by scope. Names only — values may be secret. */
+  applied: Record<ReloadScope, string[]>;
+  /** Changed settings that were not applied because they need a restart. */
+  restart_required: string[];
+}
+
+function sameValue(a: unknown, b: unknown): boolean {
+  return JSON.stringify(a) === JSON.stringify(b);
+}
+
+/**
+ * Merge the reloadable settings of `next` into `current`. Settings that need
+ * a restart keep their current values and are listed in `restart_required`.
+ */
+export function planConfigReload(current: Config, next: Config): { config: Config; result: ConfigReloadResult } {
+  const config = { ...current } as Record<string, unknown>;
+  const result: ConfigReloadResult = { applied: { live: [], new_sessions: [] }, restart_required: [] };
+  const keys = [...new Set([...Object.keys(current), ...Object.keys(next)])].sort() as Array<keyof Config>;
+  for (const key of keys) {
+    if (sameValue(current[key], next[key])) continue;
+    const scope = RELOADABLE_SETTINGS[key];
+    if (!scope) {
+      result.restart_required.push(key);
+      continue;
+    }
+    config[key] = next[key];
+    result.applied[scope].push(key);
+  }
+  return { config: config as Config, result };
+}
+
+/**
+ * Holds the running configuration and swaps in reloaded settings. Listeners
+ * apply live settings; the first one to throw rejects the reload, so put
+ * validation first.
+ */
+export class ConfigReloader {
+  private config: Config;
+  private readonly listeners: Array<(config: Config) => void> = [];
+
+  constructor(initial: Config, private readonly envFile?: string) {
+    this.config = initial;
+  }
+
+  /** The configuration new sessions should start from. */
+  get current(): Config {
+    return this.config;
+  }
+
+  onReload(listener: (config: Config) => void): void {
+    this.listeners.push(listener);
+  }
+
+  /** Re-read .env and the environment and apply what can change live. Throws on invalid configuration. */
+  reload(): ConfigReloadResult {
+    reloadEnvFile(this.envFile);
+    const { config, result } = planConfigReload(this.config, loadConfig());
+    for (const listener of this.listeners) listener(config);
+    this.config = config;
+    log.info("Configuration reloaded", { ...result.applied, restart_required: result.restart_required });
+    return result;
+  }
+}
@@ -0,0 +1,192 @@
+/**
+ * Context cost of tool results: how many tokens each tool puts into the
+ * model's context window.
+ *
+ * Tokens are estimated from the result text at CHARS_PER_TOKEN characters per
+ * token — close enough to rank tools and resource types, not to bill by.
+ * HARNESS_CONTEXT_COST_SAMPLE_RATE measures a random fraction of calls;
+ * totals in the report are scaled back up by that rate.
+ *
+ * Counters are process-wide and in-memory, like the usage metrics, and reset
+ * when the server restarts.
+ */
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { withRegisterTool } from "./register-tool.js";
+import type { ToolResult } from "./response-formatter.js";
+
+/** Rough characters per token for English prose and compact JSON. */
+export const CHARS_PER_TOKEN = 4;
+
+let sampleRate = 1;
+let since = new Date().toISOString();
+
+interface CostCounters {
+  samples: number;
+  errors: number;
+  chars: number;
+  tokens: number;
+  max_tokens: number;
+}
+
+interface ToolCost extends CostCounters {
+  resource_types: Map<string, CostCounters>;
+}
+
+const costs = new Map<string, ToolCost>();
+
+function emptyCounters(): CostCounters {
+  return { samples: 0, errors: 0, chars: 0, tokens: 0, max_tokens: 0 };
+}
+
+/** Set the fraction of tool calls measured (HARNESS_CONTEXT_COST_SAMPLE_RATE). 0 disables. */
+export function configureContextCost(options: { sampleRate?: number }): void {
+  if (options.sampleRate !== undefined && options.sampleRate >= 0 && options.sampleRate <= 1) sampleRate = options.sampleRate;
+}
+
+/** Reset counters and the sample rate (tests). */
+export function resetContextCost(): void {
+  costs.clear();
+  sampleRate = 1;
+  since = new Date().toISOString();
+}
+
+function add(counters: CostCounters, chars: number, tokens: number, isError: boolean): void {
+  counters.samples++;
+  if (isError) counters.errors++;
+  counters.chars += chars;
+  counters.tokens += tokens;
+  if (tokens > counters.max_tokens) counters.max_tokens = tokens;
+}
+
+/**
+ * Measure one tool result, subject to sampling. Only the text content is
+ * counted: structuredContent repeats it for clients that read structure.
+ */
+export function recordToolResult(tool: string, resourceType: string | undefined, result: ToolResult, random = Math.random): void {
+  if (sampleRate <= 0 || (sampleRate < 1 && random() >= sampleRate)) return;
+  const chars = result.content.reduce((n, item) => n + item.text.length, 0);
+  const tokens = Math.ceil(chars / CHARS_PER_TOKEN);
+  const isError = result.isError === true;
+  let row = costs.get(tool);
+  if (!row) {
+    row = { ...emptyCounters(), resource_types: new Map() };
+    costs.set(tool, row);
+  }
+  add(row, chars, tokens, isError);
+  if (resourceType) {
+    let byType = row.resource_types.get(resourceType);
+    if (!byType) {
+      byType = emptyCounters();
+      row.resource_types.set(resourceType, byType);
+    }
+    add(byType, chars, tokens, isError);
+  }
+}
+
+type ToolCallback = (args: Record<string, unknown>, extra: unknown) => ToolResult | Promise<ToolResult>;
+type RegisterTool = (name: string, config: unknown, callback: ToolCallback) => unknown;
+
+/**
+ * A view of `server` whose registerTool measures every result of the tools
+ * registered through it. The server itself is left untouched.
+ */
+export function withContextCost(server: McpServer): McpServer {
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) =>
+    register(name, config, async (args, extra) => {
+      const result = await callback(args, extra);
+      recordToolResult(name, typeof args?.resource_type === "string" ? args.resource_type : undefined, result);
+      return result;
+    });
+  return withRegisterTool(server, registerTool);
+}
+
+function escapeLabel(value: string): string {
+  return value.replace(/\\/g, "\\\\").replace(/"/g, '\\"').replace(/\n/g, "\\n");
+}
+
+/** Context cost counters in Prometheus text exposition format. Counts are of sampled calls. */
+export function renderContextCostMetrics(): string {
+  const tokens = [
+    "# HELP harness_mcp_tool_result_tokens_total Estimated tokens in sampled tool results, by tool.",
+    "# TYPE harness_mcp_tool_result_tokens_total counter",
+  ];
+  const samples = [
+    "# HELP harness_mcp_tool_result_samples_total Tool results measured for context cost, by tool.",
+    "# TYPE harness_mcp_tool_result_samples_total counter",
+  ];
+  for (const tool of [...costs.keys()].sort()) {
+    const row = costs.get(tool)!;
+    tokens.push(`harness_mcp_tool_result_tokens_total{tool="${escapeLabel(tool)}"} ${row.tokens}`);
+    samples.push(`harness_mcp_tool_result_samples_total{tool="${escapeLabel(tool)}"} ${row.samples}`);
+  }
+  const rate = [
+    "# HELP harness_mcp_context_cost_sample_rate Fraction of tool calls measured for context cost.",
+    "# TYPE harness_mcp_context_cost_sample_rate gauge",
+    `harness_mcp_context_cost_sample_rate ${sampleRate}`,
+  ];
+  return `${[...tokens, ...samples, ...rate].join("\n")}\n`;
+}
+
+export interface ContextCostEntry {
+  samples: number;
+  avg_tokens: number;
+  max_tokens: number;
+  /** Tokens across all calls, scaled up from the sample. */
+  est_total_tokens: number;
+}
+
+export interface ToolContextCost extends ContextCostEntry {
+  tool: string;
+  errors: number;
+  /** Share of all estimated result tokens, in percent. */
+  share_pct: number;
+  top_resource_types: Array<{ resource_type: string } & ContextCostEntry>;
+}
+
+export interface ContextCostReport {
+  generated_at: string;
+  since: string;
+  sample_rate: number;
+  chars_per_token: number;
+  est_total_tokens: number;
+  tools: ToolContextCost[];
+}
+
+function entry(c: CostCounters): ContextCostEntry {
+  return {
+    samples: c.samples,
+    avg_tokens: c.samples > 0 ? Math.round(c.tokens / c.samples) : 0,
+    max_tokens: c.max_tokens,
+    est_total_tokens: sampleRate > 0 ? Math.round(c.tokens / sampleRate) : 0,
+  };
+}
+
+/**
+ * Per-tool context cost, most expensive first. Each tool lists the resource
+ * types that cost the most, which is where trimming fields pays off.
+ */
+export function summarizeContextCost(): ContextCostReport {
+  const rows = [...costs.entries()].map(([tool, row]) => ({ tool, row, cost: entry(row) }));
+  const total = rows.reduce((n, r) => n + r.cost.est_total_tokens, 0);
+  const tools = rows
+    .map(({ tool, row, cost }) => ({
+      tool,
+      ...cost,
+      errors: row.errors,
+      share_pct: total > 0 ? Math.round((cost.est_total_tokens / total) * 1000) / 10 : 0,
+      top_resource_types: [...row.resource_types.entries()]
+        .map(([resource_type, c]) => ({ resource_type, ...entry(c) }))
+        .sort((a, b) => b.est_total_tokens - a.est_total_tokens || a.resource_type.localeCompare(b.resource_type))
+        .slice(0, 10),
+    }))
+    .sort((a, b) => b.est_total_tokens - a.est_total_tokens || a.tool.localeCompare(b.tool));
+  return {
+    generated_at: new Date().toISOString(),
+    since,
+    sample_rate: sampleRate,
+    chars_per_token: CHARS_PER_TOKEN,
+    est_total_tokens: total,
+    tools,
+  };
+}
@@ -0,0 +1,70 @@
+/**
+ * Per-conversation context for stdio servers shared by several chats.
+ *
+ * Some wrappers spawn one stdio server and multiplex conversations through it.
+ * They identify the conversation in the request `_meta` (e.g.
+ * `{"_meta": {"harness/conversation_id": "chat-42"}}`). The transport wrapper
+ * below runs each inbound message inside an AsyncLocalStorage context so
+ * process-wide caches and logs can key on the conversation without threading
+ * an extra argument through every handler.
+ *
+ * Keyed per conversation: the response cache, the runtime-input cache and the
+ * undo log. Deliberately shared: the upstream rate limiter and the pinned
+ * scope, which comes from immutable config.
+ */
+import { AsyncLocalStorage } from "node:async_hooks";
+import type { Transport } from "@modelcontextprotocol/sdk/shared/transport.js";
+import { isRecord } from "./type-guards.js";
+
+/** `_meta` keys checked, in order, for the conversation ID. */
+export const CONVERSATION_META_KEYS = ["harness/conversation_id", "conversation_id", "conversationId"] as const;
+
+const MAX_CONVERSATION_ID_LENGTH = 200;
+
+const storage = new AsyncLocalStorage<string>();
+
+/** Read the conversation ID from a JSON-RPC message's `params._meta`, if present. */
+export function extractConversationId(message: unknown): string | undefined {
+  if (!isRecord(message) || !isRecord(message.params) || !isRecord(message.params._meta)) return undefined;
+  const meta = message.params._meta;
+  for (const key of CONVERSATION_META_KEYS) {
+    const value = meta[key];
+    if (typeof value === "string" && value.trim()) {
+      return value.trim().slice(0, MAX_CONVERSATION_ID_LENGTH);
+    }
+  }
+  return undefined;
+}
+
+/** Conversation ID of the request currently being handled, or undefined. */
+export function getConversationId(): string | undefined {
+  return storage.getStore();
+}
+
+/** Run `fn` with `conversationId` as the active conversation. */
+export function runInConversation<T>(conversationId: string | undefined, fn: () => T): T {
+  return conversationId ? storage.run(conversationId, fn) : storage.exit(fn);
+}
+
+/**
+ * Prefix a cache key with the active conversation so entries written by one
+ * chat are never served to another. Requests without a conversation ID share
+ * the unscoped namespace, preserving single-client behaviour.
+ */
+export function conversationScopedKey(key: string): string {
+  const conversationId = getConversationId();
+  return conversationId ? `conv:${conversationId}|${key}` : key;
+}
+
+/**
+ * Wrap a connected transport so every inbound message is handled inside its
+ * conversation context. Call after `server.connect(transport)`, which installs
+ * the `onmessage` handler being wrapped.
+ */
+export function isolateConversations(transport: Transport): void {
+  const handler = transport.onmessage;
+  if (!handler) return;
+  transport.onmessage = (message, extra) => {
+    runInConversation(extractConversationId(message), () => handler(message, extra));
+  };
+}
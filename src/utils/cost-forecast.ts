@@ -0,0 +1,113 @@
+/**
+ * Spend forecasting from a daily cost history: an ordinary least-squares
+ * trend line projected forward, with prediction intervals from the spread of
+ * the history around that line.
+ *
+ * Deliberately simple and explainable — a budget conversation needs "where is
+ * this heading, and how sure are we", not a black box. Residuals are treated
+ * as independent, so the intervals are narrower than reality when spend has
+ * strong weekly or monthly cycles; callers should say so when the fit is poor.
+ */
+
+/** Two-sided normal quantiles for the supported confidence levels. */
+const Z_SCORES: Record<number, number> = { 80: 1.2816, 90: 1.6449, 95: 1.96 };
+
+export const FORECAST_CONFIDENCE_LEVELS = Object.keys(Z_SCORES).map(Number);
+
+export interface ForecastBand {
+  value: number;
+  lower: number;
+  upper: number;
+}
+
+export interface LinearForecast {
+  /** Change in daily spend per day. */
+  slope_per_day: number;
+  /** Fitted spend for the first history day. */
+  intercept: number;
+  /** Share of daily variation the trend line explains (0-1). */
+  r_squared: number;
+  /** Standard deviation of the history around the trend line. */
+  residual_sd: number;
+  /** One band per forecast day. */
+  daily: ForecastBand[];
+  /** One band per entry of `periodLengths` (e.g. calendar months). */
+  periods: ForecastBand[];
+  /** Band for the whole horizon's spend. */
+  total: ForecastBand;
+}
+
+const round2 = (n: number) => Math.round(n * 100) / 100;
+
+/**
+ * Fit a linear trend to `history` (spend per day, oldest first) and project
+ * `horizonDays` ahead at the given confidence level (80, 90, or 95).
+ * `periodLengths` splits the horizon into consecutive periods (in days) that
+ * get their own band. Spend cannot be negative, so values and lower bounds
+ * are clamped at zero. Needs at least three history points.
+ */
+export function linearForecast(
+  history: readonly number[],
+  horizonDays: number,
+  confidence = 80,
+  periodLengths: readonly number[] = [],
+): LinearForecast {
+  const n = history.length;
+  if (n < 3) throw new Error(`A forecast needs at least 3 days of history, got ${n}.`);
+  const z = Z_SCORES[confidence];
+  if (z === undefined) throw new Error(`confidence must be one of ${FORECAST_CONFIDENCE_LEVELS.join(", ")}, got ${confidence}.`);
+
+  const meanT = (n - 1) / 2;
+  const meanY = history.reduce((sum, y) => sum + y, 0) / n;
+  let sxx = 0;
+  let sxy = 0;
+  for (let t = 0; t < n; t++) {
+    sxx += (t - meanT) ** 2;
+    sxy += (t - meanT) * (history[t]! - meanY);
+  }
+  const slope = sxy / sxx;
+  const intercept = meanY - slope * meanT;
+
+  let sse = 0;
+  let sst = 0;
+  for (let t = 0; t < n; t++) {
+    sse += (history[t]! - (intercept + slope * t)) ** 2;
+    sst += (history[t]! - meanY) ** 2;
+  }
+  const sd = Math.sqrt(sse / (n - 2));
+
+  const clamp = (value: number, margin: number): ForecastBand => ({
+    value: round2(Math.max(0, value)),
+    lower: round2(Math.max(0, value - margin)),
+    upper: round2(Math.max(0, value + margin)),
+  });
+  // Band for the summed spend of forecast days [from, from + length): each
+  // day's own noise plus the shared uncertainty in the fitted level and slope.
+  const band = (from: number, length: number): ForecastBand => {
+    let value = 0;
+    let offsetSum = 0;
+    for (let t = n + from; t < n + from + length; t++) {
+      value += intercept + slope * t;
+      offsetSum += t - meanT;
+    }
+    return clamp(value, z * sd * Math.sqrt(length + length ** 2 / n + offsetSum ** 2 / sxx));
+  };
+
+  const daily = Array.from({ length: horizonDays }, (_, h) => band(h, 1));
+  const periods: ForecastBand[] = [];
+  let from = 0;
+  for (const length of periodLengths) {
+    periods.push(band(from, length));
+    from += length;
+  }
+
+  return {
+    slope_per_day: round2(slope),
+    intercept: round2(intercept),
+    r_squared: sst === 0 ? 1 : Math.round((1 - sse / sst) * 1000) / 1000,
+    residual_sd: round2(sd),
+    daily,
+    periods,
+    total: band(0, horizonDays),
+  };
+}
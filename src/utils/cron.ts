@@ -0,0 +1,275 @@
+/**
+ * Minimal cron evaluator for Harness scheduled triggers.
+ *
+ * Supports UNIX (5 fields: minute hour day-of-month month day-of-week) and
+ * QUARTZ (6–7 fields: second minute hour day-of-month month day-of-week [year])
+ * expressions with `*`, `?`, lists, ranges, steps, month/day names, `L` as
+ * the last day of the month, and the `@daily`-style macros. Fire times are
+ * computed in an IANA time zone via Intl, so DST shifts follow the zone rules.
+ */
+
+export type CronDialect = "UNIX" | "QUARTZ";
+
+export interface CronSchedule {
+  dialect: CronDialect;
+  /** Second of the minute the schedule fires at (QUARTZ only; 0 for UNIX). */
+  second: number;
+  minutes: Set<number>;
+  hours: Set<number>;
+  daysOfMonth: Set<number>;
+  lastDayOfMonth: boolean;
+  months: Set<number>;
+  /** 0 = Sunday … 6 = Saturday, regardless of dialect. */
+  daysOfWeek: Set<number>;
+  years?: Set<number>;
+  domRestricted: boolean;
+  dowRestricted: boolean;
+}
+
+const MACROS: Record<string, string> = {
+  "@yearly": "0 0 1 1 *",
+  "@annually": "0 0 1 1 *",
+  "@monthly": "0 0 1 * *",
+  "@weekly": "0 0 * * 0",
+  "@daily": "0 0 * * *",
+  "@midnight": "0 0 * * *",
+  "@hourly": "0 * * * *",
+};
+
+const MONTH_NAMES = ["JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"];
+const DAY_NAMES = ["SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"];
+
+/** Safety valve for pathological expressions (e.g. Feb 30) that never fire. */
+const MAX_ITERATIONS = 100_000;
+
+interface FieldSpec {
+  label: string;
+  min: number;
+  max: number;
+  /** Names mapped to values starting at `nameBase` (JAN=1, SUN=0 or 1). */
+  names?: string[];
+  nameBase?: number;
+}
+
+interface ParsedField {
+  values: Set<number>;
+  restricted: boolean;
+  last: boolean;
+}
+
+function parseValue(token: string, spec: FieldSpec): number {
+  const upper = token.toUpperCase();
+  const nameIndex = spec.names?.indexOf(upper) ?? -1;
+  const value = nameIndex >= 0 ? nameIndex + (spec.nameBase ?? 0) : /^\d+$/.test(token) ? Number(token) : NaN;
+  if (Number.isNaN(value) || value < spec.min || value > spec.max) {
+    throw new Error(`Invalid ${spec.label} value "${token}" (expected ${spec.min}-${spec.max})`);
+  }
+  return value;
+}
+
+function parseField(text: string, spec: FieldSpec, allowLast = false): ParsedField {
+  if (text === "*" || text === "?") {
+    const values = new Set<number>();
+    for (let v = spec.min; v <= spec.max; v++) values.add(v);
+    return { values, restricted: false, last: false };
+  }
+  const values = new Set<number>();
+  let last = false;
+  for (const part of text.split(",")) {
+    if (allowLast && part.toUpperCase() === "L") {
+      last = true;
+      continue;
+    }
+    if (/[LW#]/i.test(part.replace(/[A-Z]{3}/gi, ""))) {
+      throw new Error(`Unsupported cron syntax "${part}" in ${spec.label}`);
+    }
+    const [base, stepText] = part.split("/");
+    const step = stepText === undefined ? 1 : Number(stepText);
+    if (!Number.isInteger(step) || step < 1) {
+      throw new Error(`Invalid step "${stepText}" in ${spec.label}`);
+    }
+    let from: number;
+    let to: number;
+    if (base === "*" || base === "?") {
+      from = spec.min;
+      to = spec.max;
+    } else if (base!.includes("-")) {
+      const [a, b] = base!.split("-");
+      from = parseValue(a!, spec);
+      to = parseValue(b!, spec);
+    } else {
+      from = parseValue(base!, spec);
+      to = stepText === undefined ? from : spec.max;
+    }
+    if (from > to) throw new Error(`Invalid range "${base}" in ${spec.label}`);
+    for (let v = from; v <= to; v += step) values.add(v);
+  }
+  return { values, restricted: true, last };
+}
+
+/**
+ * Parse a cron expression. The dialect is inferred from the field count when
+ * not given; it only changes day-of-week numbering (UNIX 0/7 = Sunday,
+ * QUARTZ 1 = Sunday) and whether a seconds/year field is present.
+ */
+export function parseCron(expression: string, dialect?: CronDialect): CronSchedule {
+  const trimmed = expression.trim();
+  const expanded = MACROS[trimmed.toLowerCase()] ?? trimmed;
+  const fields = expanded.split(/\s+/).filter(Boolean);
+  const resolved: CronDialect = dialect ?? (fields.length >= 6 ? "QUARTZ" : "UNIX");
+
+  if (resolved === "UNIX" && fields.length !== 5) {
+    throw new Error(`UNIX cron expects 5 fields, got ${fields.length}: "${expression}"`);
+  }
+  if (resolved === "QUARTZ" && fields.length !== 6 && fields.length !== 7) {
+    throw new Error(`QUARTZ cron expects 6 or 7 fields, got ${fields.length}: "${expression}"`);
+  }
+
+  const [secondText, minuteText, hourText, domText, monthText, dowText, yearText] =
+    resolved === "QUARTZ" ? fields : ["0", ...fields];
+
+  const seconds = parseField(secondText!, { label: "second", min: 0, max: 59 });
+  const minutes = parseField(minuteText!, { label: "minute", min: 0, max: 59 });
+  const hours = parseField(hourText!, { label: "hour", min: 0, max: 23 });
+  const dom = parseField(domText!, { label: "day-of-month", min: 1, max: 31 }, true);
+  const months = parseField(monthText!, { label: "month", min: 1, max: 12, names: MONTH_NAMES, nameBase: 1 });
+  const dowRaw = resolved === "QUARTZ"
+    ? parseField(dowText!, { label: "day-of-week", min: 1, max: 7, names: DAY_NAMES, nameBase: 1 })
+    : parseField(dowText!, { label: "day-of-week", min: 0, max: 7, names: DAY_NAMES, nameBase: 0 });
+  const daysOfWeek = new Set([...dowRaw.values].map((d) => (resolved === "QUARTZ" ? d - 1 : d % 7)));
+  const years = yearText ? parseField(yearText, { label: "year", min: 1970, max: 2199 }) : undefined;
+
+  return {
+    dialect: resolved,
+    second: Math.min(...seconds.values),
+    minutes: minutes.values,
+    hours: hours.values,
+    daysOfMonth: dom.values,
+    lastDayOfMonth: dom.last,
+    months: months.values,
+    daysOfWeek,
+    years: years?.restricted ? years.values : undefined,
+    domRestricted: dom.restricted,
+    dowRestricted: dowRaw.restricted,
+  };
+}
+
+/** True when `timeZone` is an IANA zone name Intl understands. */
+export function isValidTimeZone(timeZone: string): boolean {
+  try {
+    new Intl.DateTimeFormat("en-US", { timeZone });
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+interface ZonedParts {
+  year: number;
+  month: number;
+  day: number;
+  hour: number;
+  minute: number;
+  second: number;
+  weekday: number;
+}
+
+const formatters = new Map<string, Intl.DateTimeFormat>();
+
+function zonedParts(ms: number, timeZone: string): ZonedParts {
+  let fmt = formatters.get(timeZone);
+  if (!fmt) {
+    fmt = new Intl.DateTimeFormat("en-US", {
+      timeZone,
+      hourCycle: "h23",
+      year: "numeric",
+      month: "numeric",
+      day: "numeric",
+      hour: "numeric",
+      minute: "numeric",
+      second: "numeric",
+      weekday: "short",
+    });
+    formatters.set(timeZone, fmt);
+  }
+  const parts: Record<string, string> = {};
+  for (const p of fmt.formatToParts(new Date(ms))) parts[p.type] = p.value;
+  return {
+    year: Number(parts.year),
+    month: Number(parts.month),
+    day: Number(parts.day),
+    hour: Number(parts.hour) % 24,
+    minute: Number(parts.minute),
+    second: Number(parts.second),
+    weekday: DAY_NAMES.indexOf(String(parts.weekday).toUpperCase().slice(0, 3)),
+  };
+}
+
+function daysInMonth(year: number, month: number): number {
+  return new Date(Date.UTC(year, month, 0)).getUTCDate();
+}
+
+function dayMatches(schedule: CronSchedule, p: ZonedParts): boolean {
+  const domMatch = schedule.daysOfMonth.has(p.day) || (schedule.lastDayOfMonth && p.day === daysInMonth(p.year, p.month));
+  const dowMatch = schedule.daysOfWeek.has(p.weekday);
+  if (schedule.domRestricted && schedule.dowRestricted) {
+    // UNIX cron fires when either day field matches; QUARTZ requires both.
+    return schedule.dialect === "UNIX" ? domMatch || dowMatch : domMatch && dowMatch;
+  }
+  if (schedule.domRestricted) return domMatch;
+  if (schedule.dowRestricted) return dowMatch;
+  return true;
+}
+
+/**
+ * Next `count` fire times strictly after `from`, evaluated in `timeZone`.
+ * Stops early at `until` when given.
+ */
+export function nextCronRuns(
+  schedule: CronSchedule,
+  from: Date,
+  count: number,
+  timeZone = "UTC",
+  until?: Date,
+): Date[] {
+  const runs: Date[] = [];
+  const fromMs = from.getTime();
+  const untilMs = until?.getTime() ?? Infinity;
+  const sortedMinutes = [...schedule.minutes].sort((a, b) => a - b);
+  let t = Math.floor(fromMs / 60_000) * 60_000;
+
+  for (let i = 0; i < MAX_ITERATIONS && runs.length < count && t <= untilMs; i++) {
+    const p = zonedParts(t, timeZone);
+    const toNextDay = ((23 - p.hour) * 60 + (60 - p.minute)) * 60_000;
+    if ((schedule.years && !schedule.years.has(p.year)) || !schedule.months.has(p.month) || !dayMatches(schedule, p)) {
+      t += toNextDay;
+      continue;
+    }
+    if (!schedule.hours.has(p.hour)) {
+      t += (60 - p.minute) * 60_000;
+      continue;
+    }
+    if (!schedule.minutes.has(p.minute)) {
+      const next = sortedMinutes.find((m) => m > p.minute);
+      t += ((next ?? 60) - p.minute) * 60_000;
+      continue;
+    }
+    const fireMs = t + schedule.second * 1000;
+    if (fireMs > fromMs && fireMs <= untilMs) runs.push(new Date(fireMs));
+    t += 60_000;
+  }
+  return runs;
+}
+
+/** ISO-8601 timestamp rendered in `timeZone`, e.g. `2026-03-09T08:00:00-04:00` (`Z` for UTC). */
+export function formatInTimeZone(date: Date, timeZone: string): string {
+  const ms = Math.floor(date.getTime() / 1000) * 1000;
+  const p = zonedParts(ms, timeZone);
+  const offsetMin = Math.round((Date.UTC(p.year, p.month - 1, p.day, p.hour, p.minute, p.second) - ms) / 60_000);
+  const pad = (n: number) => String(n).padStart(2, "0");
+  const local = `${p.year}-${pad(p.month)}-${pad(p.day)}T${pad(p.hour)}:${pad(p.minute)}:${pad(p.second)}`;
+  if (offsetMin === 0) return `${local}Z`;
+  const sign = offsetMin > 0 ? "+" : "-";
+  const abs = Math.abs(offsetMin);
+  return `${local}${sign}${pad(Math.floor(abs / 60))}:${pad(abs % 60)}`;
+}
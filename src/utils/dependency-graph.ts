@@ -0,0 +1,197 @@
+/**
+ * Artifact dependency graphs assembled from an SBOM's component list and the
+ * per-component dependency trees: the artifact at the root, edges to its
+ * direct dependencies, and transitive edges between components. Answers
+ * blast-radius questions ("how does this vulnerable package get in, and what
+ * pulls it?") by walking the edges backwards.
+ */
+
+export interface GraphComponent {
+  id: string;
+  name: string;
+  version?: string;
+  license?: string;
+  vulnerabilities?: number;
+}
+
+/** One row of a component's dependency tree (scs_component_dependencies). */
+export interface DependencyTreeRow {
+  name?: unknown;
+  version?: unknown;
+  purl?: unknown;
+  /** DIRECT when the row is a direct dependency of the tree's component. */
+  relationship?: unknown;
+  /** Hops from the tree's component to the row, as a list or a "->"-separated string. */
+  relationship_path?: unknown;
+  vulnerabilities_count?: unknown;
+}
+
+export interface GraphNode extends GraphComponent {
+  /** A direct dependency of the artifact. */
+  direct: boolean;
+}
+
+export interface GraphEdge {
+  from: string;
+  to: string;
+  /** "direct" edges leave the artifact root; "transitive" edges join two components. */
+  type: "direct" | "transitive";
+}
+
+export interface DependencyGraph {
+  root: string;
+  nodes: GraphNode[];
+  edges: GraphEdge[];
+}
+
+export interface BlastRadius {
+  target: string;
+  found: boolean;
+  /** Direct dependencies of the artifact that pull the target in. */
+  introduced_by: string[];
+  /** Every component that depends on the target, directly or transitively. */
+  dependents: string[];
+  /** Root-to-target chains, shortest first. */
+  paths: string[][];
+  paths_truncated?: boolean;
+}
+
+/** Root-to-target chains returned by blastRadius. */
+export const BLAST_RADIUS_MAX_PATHS = 20;
+const BLAST_RADIUS_MAX_STEPS = 10_000;
+
+const text = (v: unknown): string | undefined => (typeof v === "string" && v.trim() ? v.trim() : typeof v === "number" ? String(v) : undefined);
+
+/**
+ * Build the graph for an artifact. `trees` maps each direct dependency's id to
+ * its dependency tree; tree rows are attached under the last hop of their
+ * relationship path, or under the tree's component when they are DIRECT or
+ * have no path. Components that appear in no tree stay as unconnected nodes.
+ */
+export function buildDependencyGraph(
+  root: string,
+  components: readonly GraphComponent[],
+  directIds: ReadonlySet<string>,
+  trees: ReadonlyMap<string, readonly DependencyTreeRow[]>,
+): DependencyGraph {
+  const nodes = new Map<string, GraphNode>();
+  // Tree rows name their hops by purl or name; map both back to node ids.
+  const aliases = new Map<string, string>();
+  const addNode = (c: GraphComponent) => {
+    const existing = nodes.get(c.id);
+    if (existing) return existing;
+    const node: GraphNode = { ...c, direct: directIds.has(c.id) };
+    nodes.set(c.id, node);
+    for (const alias of [c.id, c.name, c.version ? `${c.name}@${c.version}` : ""]) {
+      if (alias && !aliases.has(alias)) aliases.set(alias, c.id);
+    }
+    return node;
+  };
+  for (const c of components) addNode(c);
+
+  const edges: GraphEdge[] = [];
+  const seen = new Set<string>();
+  const addEdge = (from: string, to: string, type: GraphEdge["type"]) => {
+    const key = `${from}\n${to}`;
+    if (from === to || seen.has(key)) return;
+    seen.add(key);
+    edges.push({ from, to, type });
+  };
+
+  for (const id of directIds) {
+    if (!nodes.has(id)) addNode({ id, name: id });
+    addEdge(root, id, "direct");
+  }
+  for (const [parentId, rows] of trees) {
+    const resolved: Array<{ id: string; row: DependencyTreeRow }> = [];
+    for (const row of rows) {
+      const name = text(row.name);
+      const version = text(row.version);
+      const id = text(row.purl) ?? (name ? (version ? `${name}@${version}` : name) : undefined);
+      if (!id) continue;
+      const vulns = Number(row.vulnerabilities_count);
+      const node = nodes.get(aliases.get(id) ?? id) ?? addNode({
+        id,
+        name: name ?? id,
+        ...(version ? { version } : {}),
+        ...(Number.isFinite(vulns) ? { vulnerabilities: vulns } : {}),
+      });
+      resolved.push({ id: node.id, row });
+    }
+    for (const { id, row } of resolved) {
+      const path = Array.isArray(row.relationship_path)
+        ? row.relationship_path.map(String)
+        : typeof row.relationship_path === "string" ? row.relationship_path.split(/\s*-?>\s*/) : [];
+      const hops = path.map((p) => p.trim()).filter((p) => p && (aliases.get(p) ?? p) !== id);
+      const last = hops[hops.length - 1];
+      const parent = String(row.relationship ?? "").toUpperCase() === "DIRECT" || last === undefined
+        ? parentId
+        : aliases.get(last) ?? parentId;
+      addEdge(parent, id, "transitive");
+    }
+  }
+  return { root, nodes: [...nodes.values()], edges };
+}
+
+/**
+ * Blast radius of one component: how it enters the artifact and everything
+ * above it. `target` matches a node id exactly, or a version-less purl or
+ * name against every version in the graph.
+ */
+export function blastRadius(graph: DependencyGraph, target: string): BlastRadius {
+  const wanted = target.toLowerCase();
+  const targets = graph.nodes.filter((n) => n.id.toLowerCase() === wanted).map((n) => n.id);
+  if (targets.length === 0) {
+    const base = (id: string) => id.toLowerCase().replace(/[?#].*$/, "").replace(/@[^/@]*$/, "");
+    targets.push(...graph.nodes.filter((n) => base(n.id) === wanted || n.name.toLowerCase() === wanted).map((n) => n.id));
+  }
+  if (targets.length === 0) return { target, found: false, introduced_by: [], dependents: [], paths: [] };
+
+  const parents = new Map<string, string[]>();
+  for (const e of graph.edges) parents.set(e.to, [...(parents.get(e.to) ?? []), e.from]);
+
+  const dependents = new Set<string>();
+  const queue = [...targets];
+  while (queue.length > 0) {
+    const id = queue.shift()!;
+    for (const p of parents.get(id) ?? []) {
+      if (p === graph.root || dependents.has(p) || targets.includes(p)) continue;
+      dependents.add(p);
+      queue.push(p);
+    }
+  }
+
+  // Breadth-first from the targets up to the root, so shorter chains come first.
+  const paths: string[][] = [];
+  let truncated = false;
+  const frontier: string[][] = targets.map((t) => [t]);
+  // Dense graphs have exponentially many chains; stop expanding after a fixed amount of work.
+  for (let steps = 0; frontier.length > 0; steps++) {
+    if (steps >= BLAST_RADIUS_MAX_STEPS) {
+      truncated = true;
+      break;
+    }
+    const chain = frontier.shift()!;
+    for (const p of parents.get(chain[0]!) ?? []) {
+      if (p === graph.root) {
+        if (paths.length >= BLAST_RADIUS_MAX_PATHS) {
+          truncated = true;
+          break;
+        }
+        paths.push(chain);
+      } else if (!chain.includes(p)) {
+        frontier.push([p, ...chain]);
+      }
+    }
+    if (truncated) break;
+  }
+
+  return {
+    target,
+    found: true,
+    introduced_by: graph.nodes.filter((n) => n.direct && (dependents.has(n.id) || targets.includes(n.id))).map((n) => n.id),
+    dependents: [...dependents],
+    paths,
+    ...(truncated ? { paths_truncated: true } : {}),
+  };
+}
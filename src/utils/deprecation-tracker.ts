@@ -0,0 +1,103 @@
+/**
+ * Usage tracking for deprecated names — currently the renamed toolsets in
+ * TOOLSET_ALIASES (registry/index.ts), e.g. agent-pipelines → agents.
+ *
+ * Each use of an old name is counted per source (HARNESS_TOOLSETS or a bearer
+ * token's toolsets claim) so maintainers can see who still depends on it
+ * before removing the alias.
+ * Counts are process-wide and in-memory; they reset when the server restarts.
+ */
+
+export type DeprecatedKind = "toolset";
+
+export interface DeprecatedUsage {
+  kind: DeprecatedKind;
+  name: string;
+  replacement: string;
+  client: string;
+  count: number;
+  first_seen: string;
+  last_seen: string;
+}
+
+export interface DeprecatedUsageReport {
+  total_calls: number;
+  names: Array<{
+    kind: DeprecatedKind;
+    name: string;
+    replacement: string;
+    hint: string;
+    total: number;
+    last_seen: string;
+    clients: Array<{ client: string; count: number; first_seen: string; last_seen: string }>;
+  }>;
+}
+
+/** Upper bound on tracked (kind, name, client) rows so a noisy client cannot grow memory unbounded. */
+const MAX_TRACKED = 1000;
+
+const usage = new Map<string, DeprecatedUsage>();
+
+/** Migration hint logged when a deprecated name is used. */
+export function migrationHint(name: string, replacement: string): string {
+  return `Toolset "${name}" is deprecated and will be removed. Use "${replacement}" in HARNESS_TOOLSETS or the toolsets claim instead.`;
+}
+
+/** Count one use of a deprecated name from `client` (the config source that named it). */
+export function recordDeprecatedUsage(
+  kind: DeprecatedKind,
+  name: string,
+  replacement: string,
+  client = "unknown",
+): void {
+  const key = `${kind}|${name}|${client}`;
+  const now = new Date().toISOString();
+  const existing = usage.get(key);
+  if (existing) {
+    existing.count++;
+    existing.last_seen = now;
+    return;
+  }
+  if (usage.size >= MAX_TRACKED) return;
+  usage.set(key, { kind, name, replacement, client, count: 1, first_seen: now, last_seen: now });
+}
+
+/** Raw per-client rows, most recently seen first. */
+export function getDeprecatedUsage(): DeprecatedUsage[] {
+  return [...usage.values()]
+    .map((row) => ({ ...row }))
+    .sort((a, b) => b.last_seen.localeCompare(a.last_seen));
+}
+
+/** Usage grouped by deprecated name — who still calls what, and how often. */
+export function summarizeDeprecatedUsage(): DeprecatedUsageReport {
+  const byName = new Map<string, DeprecatedUsageReport["names"][number]>();
+  let totalCalls = 0;
+  for (const row of getDeprecatedUsage()) {
+    totalCalls += row.count;
+    const key = `${row.kind}|${row.name}`;
+    let entry = byName.get(key);
+    if (!entry) {
+      entry = {
+        kind: row.kind,
+        name: row.name,
+        replacement: row.replacement,
+        hint: migrationHint(row.name, row.replacement),
+        total: 0,
+        last_seen: row.last_seen,
+        clients: [],
+      };
+      byName.set(key, entry);
+    }
+    entry.total += row.count;
+    entry.clients.push({ client: row.client, count: row.count, first_seen: row.first_seen, last_seen: row.last_seen });
+  }
+  const names = [...byName.values()].sort((a, b) => b.total - a.total);
+  for (const entry of names) entry.clients.sort((a, b) => b.count - a.count);
+  return { total_calls: totalCalls, names };
+}
+
+/** Clear all counts. Intended for tests. */
+export function resetDeprecatedUsage(): void {
+  usage.clear();
+}
@@ -0,0 +1,134 @@
+/**
+ * Privacy controls for individual-contributor SEI metrics.
+ *
+ * Some orgs want the agent to coach individual developers; others must never
+ * let per-person numbers leave SEI. HARNESS_SEI_DEVELOPER_METRICS picks one:
+ *
+ *   off         resources marked `developerMetrics` are not registered at all
+ *   aggregate   their results are reduced to a team-level distribution of each
+ *               numeric metric, with no names, emails, or IDs; groups smaller
+ *               than HARNESS_SEI_MIN_GROUP_SIZE are suppressed, since a
+ *               distribution over two people still identifies them
+ *   individual  per-developer rows are returned as SEI sends them
+ *
+ * The mode is enforced by the registry from server config, so no tool input
+ * can widen it.
+ */
+import { isRecord } from "./type-guards.js";
+
+export type DeveloperMetricsMode = "off" | "aggregate" | "individual";
+
+/** Keys that identify a person in SEI developer rows. */
+const IDENTITY_KEY =
+  /^(?:developer|user|author|contributor|assignee|committer)?_?(?:id|ids|ref_?id|name|full_?name|display_?name|email|login|username|handle)$|^(?:developer|user|author|contributor|assignee|committer)$/i;
+
+const MAX_DEPTH = 6;
+
+function isIdentityKey(key: string): boolean {
+  return IDENTITY_KEY.test(key);
+}
+
+/** Whether an array looks like one row per developer: records carrying an identity key. */
+function isDeveloperRows(value: unknown): value is Array<Record<string, unknown>> {
+  return Array.isArray(value)
+    && value.length > 0
+    && value.every(isRecord)
+    && value.some((row) => Object.keys(row).some(isIdentityKey));
+}
+
+/** The first array of per-developer rows in an SEI response, searched breadth-first. */
+export function findDeveloperRows(data: unknown): Array<Record<string, unknown>> | undefined {
+  let level: unknown[] = [data];
+  for (let depth = 0; depth <= MAX_DEPTH && level.length > 0; depth++) {
+    const next: unknown[] = [];
+    for (const node of level) {
+      if (isDeveloperRows(node)) return node;
+      if (Array.isArray(node)) next.push(...node);
+      else if (isRecord(node)) next.push(...Object.values(node));
+    }
+    level = next;
+  }
+  return undefined;
+}
+
+export interface MetricDistribution {
+  developers: number;
+  min: number;
+  p25: number;
+  median: number;
+  p75: number;
+  max: number;
+  mean: number;
+}
+
+const round = (n: number) => Math.round(n * 100) / 100;
+
+function quantile(sorted: number[], q: number): number {
+  const pos = (sorted.length - 1) * q;
+  const lower = Math.floor(pos);
+  const upper = Math.ceil(pos);
+  return sorted[lower]! + (sorted[upper]! - sorted[lower]!) * (pos - lower);
+}
+
+function distribution(values: number[]): MetricDistribution {
+  const sorted = [...values].sort((a, b) => a - b);
+  return {
+    developers: sorted.length,
+    min: round(sorted[0]!),
+    p25: round(quantile(sorted, 0.25)),
+    median: round(quantile(sorted, 0.5)),
+    p75: round(quantile(sorted, 0.75)),
+    max: round(sorted[sorted.length - 1]!),
+    mean: round(sorted.reduce((n, v) => n + v, 0) / sorted.length),
+  };
+}
+
+/**
+ * Team-level view of per-developer rows: one distribution per numeric metric
+ * (top-level numbers in each row), or a suppression notice when fewer than
+ * `minGroupSize` developers are in the group. Scalar top-level fields of the
+ * response, such as the date range, are kept.
+ */
+export function aggregateDeveloperMetrics(data: unknown, minGroupSize: number): unknown {
+  const rows = findDeveloperRows(data);
+  if (!rows) return data;
+
+  const context: Record<string, unknown> = {};
+  if (isRecord(data)) {
+    for (const [key, value] of Object.entries(data)) {
+      if (value !== null && typeof value !== "object" && !isIdentityKey(key)) context[key] = value;
+    }
+  }
+  const privacy = { mode: "aggregate", developers: rows.length, min_group_size: minGroupSize };
+  if (rows.length < minGroupSize) {
+    return {
+      ...context,
+      _privacy: {
+        ...privacy,
+        suppressed: true,
+        reason: `Fewer than ${minGroupSize} developers in this group; metrics are withheld so no individual can be singled out. Widen the team or date range.`,
+      },
+    };
+  }
+
+  const values = new Map<string, number[]>();
+  for (const row of rows) {
+    for (const [key, value] of Object.entries(row)) {
+      if (isIdentityKey(key) || typeof value !== "number" || !Number.isFinite(value)) continue;
+      const list = values.get(key) ?? [];
+      list.push(value);
+      values.set(key, list);
+    }
+  }
+  const metrics: Record<string, MetricDistribution> = {};
+  for (const [key, list] of [...values.entries()].sort(([a], [b]) => a.localeCompare(b))) {
+    // A metric reported for only a few developers is as identifying as a small group.
+    if (list.length >= minGroupSize) metrics[key] = distribution(list);
+  }
+  return { ...context, metrics, _privacy: privacy };
+}
+
+/** Apply the configured mode to a developer-metrics result. */
+export function applyDeveloperPrivacy(data: unknown, mode: DeveloperMetricsMode, minGroupSize: number): unknown {
+  return mode === "individual" ? data : aggregateDeveloperMetrics(data, minGroupSize);
+}
@@ -1,9 +1,39 @@
-import { config as loadDotenv } from "dotenv";
+import { existsSync, readFileSync } from "node:fs";
+import { resolve } from "node:path";
+import { config as loadDotenv, parse as parseDotenv } from "dotenv";
+
+/** Variables set by the real environment before the .env file was loaded; the file never overrides them. */
+let inheritedKeys: Set<string> | undefined;
+/** Variables the .env file set, so a reload can unset the ones removed from it. */
+let fileKeys = new Set<string>();
 
 export function loadEnvFile(envFile?: string): void {
-  if (envFile) {
-    loadDotenv({ path: envFile, quiet: true });
-  } else {
-    loadDotenv({ quiet: true });
+  inheritedKeys ??= new Set(Object.keys(process.env));
+  const result = envFile
+    ? loadDotenv({ path: envFile, quiet: true })
+    : loadDotenv({ quiet: true });
+  for (const key of Object.keys(result.parsed ?? {})) {
+    if (!inheritedKeys.has(key)) fileKeys.add(key);
+  }
+}
+
+/**
+ * Re-read the .env file loaded at startup into process.env. Variables from
+ * the real environment still win; variables removed from the file are unset
+ * so their defaults apply again. A missing file counts as empty.
+ */
+export function reloadEnvFile(envFile?: string): void {
+  inheritedKeys ??= new Set(Object.keys(process.env));
+  const path = resolve(envFile ?? ".env");
+  const parsed = existsSync(path) ? parseDotenv(readFileSync(path)) : {};
+  const next = new Set<string>();
+  for (const [key, value] of Object.entries(parsed)) {
+    if (inheritedKeys.has(key)) continue;
+    process.env[key] = value;
+    next.add(key);
+  }
+  for (const key of fileKeys) {
+    if (!next.has(key)) delete process.env[key];
   }
+  fileKeys = next;
 }
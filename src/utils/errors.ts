@@ -1,4 +1,5 @@
 import { McpError, ErrorCode } from "@modelcontextprotocol/sdk/types.js";
+import { findRemediation, formatRemediation } from "./remediation.js";
 
 /*
  * Error handling convention for tool handlers:
@@ -75,7 +76,10 @@ export function toMcpError(err: unknown): McpError {
     const code = mapHttpStatusToMcpCode(err.statusCode);
     const detail = err.correlationId ? ` (correlationId: ${err.correlationId})` : "";
     const cleanMessage = sanitizeErrorMessage(err.message);
-    const mcpErr = new McpError(code, `${cleanMessage}${detail}`);
+    const remediation = findRemediation(cleanMessage, err.statusCode);
+    const mcpErr = remediation
+      ? new McpError(code, `${cleanMessage}${detail}\n\n${formatRemediation(remediation)}`, { remediation })
+      : new McpError(code, `${cleanMessage}${detail}`);
     mcpErr.cause = err;
     return mcpErr;
   }
@@ -0,0 +1,221 @@
+/**
+ * Root-cause hints for failed pipeline steps.
+ *
+ * Classifies a failure from Harness's failure types, the failure message, and
+ * the step log, picks the log lines around the error, and suggests a fix.
+ * Matching is heuristic: the category is a starting point for the agent, and
+ * `evidence` says which input decided it.
+ */
+
+export type FailureCategory =
+  | "timeout"
+  | "policy"
+  | "approval"
+  | "delegate"
+  | "image_pull"
+  | "authentication"
+  | "resource_exhausted"
+  | "connectivity"
+  | "dependency"
+  | "compilation"
+  | "test_failure"
+  | "script"
+  | "unknown";
+
+interface CategoryRule {
+  category: Exclude<FailureCategory, "unknown">;
+  /** Harness failureTypeList values that map to this category. */
+  failureTypes: string[];
+  pattern: RegExp;
+  fix: string;
+}
+
+// Order matters: the first rule whose pattern matches wins, so specific
+// causes (image pulls, OOM kills) come before broader ones (auth, networking).
+const RULES: CategoryRule[] = [
+  {
+    category: "policy",
+    failureTypes: ["POLICY_EVALUATION_FAILURE"],
+    pattern: /policy (evaluation|violation|set)|\bOPA\b|denied by policy/i,
+    fix: "A governance policy blocked the run. Read the policy_evaluation result for the failing rule, then change the pipeline or ask the policy owner for an exemption.",
+  },
+  {
+    category: "approval",
+    failureTypes: ["APPROVAL_REJECTION", "INPUT_TIMEOUT_ERROR"],
+    pattern: /approval.*(rejected|expired)|rejected by/i,
+    fix: "An approval was rejected or expired. Check the approver comments, then re-run and get the approval within its timeout.",
+  },
+  {
+    category: "timeout",
+    failureTypes: ["TIMEOUT_ERROR"],
+    pattern: /timed? ?out|deadline exceeded|timeout (exceeded|reached)/i,
+    fix: "The step exceeded its timeout. Check the log for where it stalled; raise the step timeout only if the work is legitimately slow.",
+  },
+  {
+    category: "delegate",
+    failureTypes: ["DELEGATE_PROVISIONING_ERROR", "DELEGATE_RESTART"],
+    pattern: /no (eligible|active|available) delegates?|delegate.*(not available|disconnected|lost|not connected)|no delegates? (could|can) /i,
+    fix: "No delegate could run the task. Check delegate health with harness_diagnose(resource_type='delegate') and that the step's delegate selectors match a connected delegate.",
+  },
+  {
+    category: "image_pull",
+    failureTypes: [],
+    pattern: /ImagePullBackOff|ErrImagePull|manifest (for .* )?unknown|pull access denied|failed to pull image|repository does not exist/i,
+    fix: "The container image could not be pulled. Verify the image name and tag exist and that the registry connector's credentials can read it.",
+  },
+  {
+    category: "authentication",
+    failureTypes: ["AUTHENTICATION_ERROR", "AUTHORIZATION_ERROR"],
+    pattern: /\b(401|403)\b|unauthori[sz]ed|forbidden|permission denied|access denied|authentication failed|invalid (credentials|token)|bad credentials|expired token/i,
+    fix: "Credentials were rejected. Test the connector used by this step (harness_diagnose resource_type='connector') and rotate the secret if the token expired.",
+  },
+  {
+    category: "resource_exhausted",
+    failureTypes: [],
+    pattern: /OOMKilled|out of memory|exit (code|status) 137|no space left on device|\bevicted\b|insufficient (cpu|memory)/i,
+    fix: "The step ran out of memory, CPU, or disk. Raise the step's resource limits or reduce the workload (e.g. build parallelism, cache size).",
+  },
+  {
+    category: "connectivity",
+    failureTypes: ["CONNECTIVITY_ERROR"],
+    pattern: /connection (refused|reset)|ECONNREFUSED|ECONNRESET|ENOTFOUND|EAI_AGAIN|could not resolve host|no such host|TLS handshake|x509|certificate (verify|signed by unknown)/i,
+    fix: "A network call failed. Check that the target host is reachable from the delegate (DNS, proxy, firewall) and that its TLS certificate is trusted.",
+  },
+  {
+    category: "dependency",
+    failureTypes: [],
+    pattern: /ERESOLVE|could not resolve dependenc|npm ERR! (404|notarget)|no matching distribution|could not find artifact|ModuleNotFoundError|cannot find module|unable to locate package|go: .* not found/i,
+    fix: "A dependency could not be resolved. Check the version pins and that the package registry (and its connector) serves the requested versions.",
+  },
+  {
+    category: "compilation",
+    failureTypes: [],
+    pattern: /compilation (failed|error)|error TS\d+|SyntaxError|cannot find symbol|error\[E\d+\]|undefined reference|build failed/i,
+    fix: "The code does not compile. Fix the error in the excerpt; if it passes locally, compare toolchain versions with the build image.",
+  },
+  {
+    category: "test_failure",
+    failureTypes: [],
+    pattern: /tests? failed|failing tests?|\b[1-9]\d* (tests? )?failed\b|AssertionError|assertion failed|--- FAIL:|Failures: [1-9]/i,
+    fix: "Tests failed. Read the failing test names in the excerpt and reproduce them locally; re-run once to rule out a flaky test.",
+  },
+];
+
+/**
+ * Fallback when nothing more specific matched: most Run and Shell Script
+ * failures end in a non-zero exit, so this only says *that* a command failed.
+ */
+const SCRIPT_RULE: CategoryRule = {
+  category: "script",
+  failureTypes: ["APPLICATION_ERROR"],
+  pattern: /exit (status|code) [1-9]\d*|command not found|non-zero exit|returned a non-zero/i,
+  fix: "The step's command exited with an error. Check the last commands in the excerpt and the script_context of the step.",
+};
+
+const UNKNOWN_FIX = "No known failure pattern matched. Read the log excerpt and the step's failure message; harness_get(resource_type='execution_log') returns the full log.";
+
+/** Lines that usually carry the error itself. */
+const ERROR_LINE = /\b(error|fatal|failed|failure|exception|panic|traceback|denied|refused|not found|exit (code|status))\b/i;
+
+export interface LogExcerpt {
+  /** The selected lines, with "..." between windows. */
+  text: string;
+  /** error_lines: windows around lines that look like errors; tail: the end of the log (no error lines found). */
+  source: "error_lines" | "tail";
+  total_lines: number;
+}
+
+/**
+ * Pick the lines of a log that explain a failure: every line that looks like
+ * an error with `context` lines around it. When that is more than `maxLines`,
+ * the windows nearest the end of the log are kept, since a step fails on its
+ * last error. Without any error lines, the log's tail is returned.
+ */
+export function extractErrorExcerpt(text: string, options: { context?: number; maxLines?: number } = {}): LogExcerpt {
+  const context = options.context ?? 3;
+  const maxLines = options.maxLines ?? 40;
+  const lines = text.split("\n");
+  while (lines.length > 0 && lines[lines.length - 1]!.trim() === "") lines.pop();
+
+  const keep = new Set<number>();
+  lines.forEach((line, i) => {
+    if (!ERROR_LINE.test(line)) return;
+    for (let j = Math.max(0, i - context); j <= Math.min(lines.length - 1, i + context); j++) keep.add(j);
+  });
+  if (keep.size === 0) {
+    const tailLines = lines.slice(-Math.min(maxLines, 20));
+    return { text: tailLines.join("\n"), source: "tail", total_lines: lines.length };
+  }
+
+  const selected = [...keep].sort((a, b) => a - b).slice(-maxLines);
+  const out: string[] = [];
+  let previous = -1;
+  for (const i of selected) {
+    if (previous >= 0 && i > previous + 1) out.push("...");
+    out.push(lines[i]!);
+    previous = i;
+  }
+  return { text: out.join("\n"), source: "error_lines", total_lines: lines.length };
+}
+
+export interface FailedStepInput {
+  stage: string;
+  step: string;
+  step_type?: string;
+  failure_message: string;
+  failure_types?: string[];
+}
+
+export interface FailureAnalysis {
+  stage: string;
+  step: string;
+  step_type?: string;
+  category: FailureCategory;
+  /** What decided the category. */
+  evidence: "failure_type" | "error_message" | "log" | "none";
+  error: string;
+  failure_types?: string[];
+  log_excerpt?: LogExcerpt;
+  suggested_fix: string;
+}
+
+/** Categorize one failed step and attach its log excerpt and a suggested fix. */
+export function analyzeFailure(step: FailedStepInput, logText?: string): FailureAnalysis {
+  const failureTypes = (step.failure_types ?? []).filter((t) => t && t !== "UNKNOWN");
+  const excerpt = logText ? extractErrorExcerpt(logText) : undefined;
+
+  // Specific causes first: message, then Harness failure type, then log.
+  // Only when none match does a non-zero exit count as a script failure — a
+  // Run step that fails its tests reports "exit status 1" as well.
+  const logText = excerpt?.source === "error_lines" ? excerpt.text : "";
+  let rule: CategoryRule | undefined;
+  let evidence: FailureAnalysis["evidence"] = "none";
+  if ((rule = RULES.find((r) => r.pattern.test(step.failure_message)))) {
+    evidence = "error_message";
+  } else if ((rule = RULES.find((r) => r.failureTypes.some((t) => failureTypes.includes(t))))) {
+    evidence = "failure_type";
+  } else if (logText && (rule = RULES.find((r) => r.pattern.test(logText)))) {
+    evidence = "log";
+  } else if (SCRIPT_RULE.pattern.test(step.failure_message)) {
+    rule = SCRIPT_RULE;
+    evidence = "error_message";
+  } else if (failureTypes.some((t) => SCRIPT_RULE.failureTypes.includes(t))) {
+    rule = SCRIPT_RULE;
+    evidence = "failure_type";
+  } else if (logText && SCRIPT_RULE.pattern.test(logText)) {
+    rule = SCRIPT_RULE;
+    evidence = "log";
+  }
+
+  return {
+    stage: step.stage,
+    step: step.step,
+    ...(step.step_type ? { step_type: step.step_type } : {}),
+    category: rule?.category ?? "unknown",
+    evidence,
+    error: step.failure_message,
+    ...(failureTypes.length > 0 ? { failure_types: failureTypes } : {}),
+    ...(excerpt ? { log_excerpt: excerpt } : {}),
+    suggested_fix: rule?.fix ?? UNKNOWN_FIX,
+  };
+}
@@ -0,0 +1,98 @@
+/**
+ * Bounded-concurrency fan-out with a wall-clock budget.
+ *
+ * Account-wide questions touch every org or project; doing that serially
+ * times out on large accounts, and doing it all at once trips rate limits.
+ * `fanOut` runs at most `concurrency` workers at a time and stops starting
+ * new ones once `budgetMs` has elapsed. Workers already running finish (each
+ * is a single bounded API call), so every call makes progress and the items
+ * processed always form a contiguous range that a continuation can resume
+ * after.
+ */
+
+export interface FanOutOptions {
+  /** Maximum workers in flight. */
+  concurrency: number;
+  /** Wall-clock budget in ms. Omit for no deadline. */
+  budgetMs?: number;
+  /** Index of the first item to process (from a previous `nextIndex`). */
+  startIndex?: number;
+  signal?: AbortSignal;
+}
+
+export interface FanOutResult<T, R> {
+  /** Successful results in item order. */
+  results: Array<{ item: T; value: R }>;
+  /** Per-item failures in item order. Failures never stop the fan-out. */
+  errors: Array<{ item: T; error: string }>;
+  /** Items settled (ok or error) in this call. */
+  processed: number;
+  /**
+   * Set when the budget ran out before every item was started: resume by
+   * calling again with `startIndex: nextIndex`.
+   */
+  nextIndex?: number;
+}
+
+function errorMessage(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+export async function fanOut<T, R>(
+  items: readonly T[],
+  worker: (item: T, signal: AbortSignal) => Promise<R>,
+  options: FanOutOptions,
+): Promise<FanOutResult<T, R>> {
+  const start = Math.max(0, options.startIndex ?? 0);
+  const concurrency = Math.max(1, Math.floor(options.concurrency));
+  const deadline = options.budgetMs !== undefined ? Date.now() + options.budgetMs : undefined;
+
+  const signal = options.signal ?? new AbortController().signal;
+
+  type Outcome = { ok: true; value: R } | { ok: false; error: string };
+  const outcomes = new Map<number, Outcome>();
+  let cursor = start;
+
+  async function lane(): Promise<void> {
+    while (cursor < items.length && !signal.aborted) {
+      if (deadline !== undefined && Date.now() >= deadline && cursor > start) return;
+      const index = cursor++;
+      try {
+        outcomes.set(index, { ok: true, value: await worker(items[index] as T, signal) });
+      } catch (err) {
+        outcomes.set(index, { ok: false, error: errorMessage(err) });
+      }
+    }
+  }
+
+  await Promise.all(Array.from({ length: Math.min(concurrency, Math.max(0, items.length - start)) }, lane));
+  signal.throwIfAborted();
+
+  const result: FanOutResult<T, R> = { results: [], errors: [], processed: 0 };
+  let index = start;
+  for (; index < items.length; index++) {
+    const outcome = outcomes.get(index);
+    if (!outcome) break;
+    result.processed++;
+    if (outcome.ok) result.results.push({ item: items[index] as T, value: outcome.value });
+    else result.errors.push({ item: items[index] as T, error: outcome.error });
+  }
+  if (index < items.length) result.nextIndex = index;
+  return result;
+}
+
+/** Encode a continuation token: opaque to callers, base64url JSON inside. */
+export function encodeContinuation(state: Record<string, unknown>): string {
+  return Buffer.from(JSON.stringify(state), "utf8").toString("base64url");
+}
+
+/** Decode a token from `encodeContinuation`. Throws a user-facing error when malformed. */
+export function decodeContinuation(token: string): Record<string, unknown> {
+  try {
+    const state: unknown = JSON.parse(Buffer.from(token, "base64url").toString("utf8"));
+    if (state && typeof state === "object" && !Array.isArray(state)) return state as Record<string, unknown>;
+  } catch {
+    // fall through
+  }
+  throw new Error("Invalid continuation token. Pass the next_cursor value from the previous response unchanged.");
+}
@@ -0,0 +1,209 @@
+/**
+ * Package-type-specific metadata for Harness Artifact Registry versions.
+ *
+ * The HAR API returns version details as loosely shaped JSON that differs by
+ * package type. These normalizers turn it into one typed shape per type, so an
+ * agent can read Docker platforms, Maven coordinates, or npm dist-tags without
+ * knowing the raw response layout. Fields the API did not return are null.
+ */
+import { isRecord } from "./type-guards.js";
+
+export interface DockerPlatform {
+  os: string | null;
+  architecture: string | null;
+  variant: string | null;
+  digest: string | null;
+  size: string | null;
+  created_at: string | null;
+}
+
+export interface DockerMetadata {
+  kind: "docker";
+  image: string;
+  tag: string;
+  platforms: DockerPlatform[];
+  pull_command: string | null;
+}
+
+export interface MavenMetadata {
+  kind: "maven";
+  group_id: string | null;
+  artifact_id: string;
+  version: string;
+  /** group:artifact:version, ready for a dependency declaration. */
+  coordinates: string;
+  packaging: string | null;
+  files: string[];
+}
+
+export interface NpmMetadata {
+  kind: "npm";
+  name: string;
+  version: string;
+  /** Every dist-tag of the package, e.g. { latest: "1.4.0", next: "2.0.0-rc.1" }. */
+  dist_tags: Record<string, string>;
+  /** dist-tags that point at this version. */
+  tags_on_version: string[];
+  dependencies: Record<string, string>;
+  peer_dependencies: Record<string, string>;
+  tarball: string | null;
+  integrity: string | null;
+}
+
+export interface HelmMetadata {
+  kind: "helm";
+  chart: string;
+  version: string;
+  app_version: string | null;
+  pull_command: string | null;
+}
+
+export interface PythonMetadata {
+  kind: "python";
+  name: string;
+  version: string;
+  requires_python: string | null;
+  requires_dist: string[];
+}
+
+export interface GenericMetadata {
+  kind: string;
+  /** Package-specific details as returned by the API, for types without a typed shape. */
+  details: Record<string, unknown>;
+}
+
+export type PackageMetadata = DockerMetadata | MavenMetadata | NpmMetadata | HelmMetadata | PythonMetadata | GenericMetadata;
+
+/** Raw responses gathered for one artifact version. */
+export interface HarVersionSources {
+  artifact: string;
+  version: string;
+  package_type: string;
+  details?: Record<string, unknown>;
+  /** Docker only: manifests of a multi-platform image. */
+  manifests?: unknown[];
+  /** npm only: the package document (packument) with dist-tags. */
+  packument?: Record<string, unknown>;
+}
+
+function str(value: unknown): string | null {
+  return typeof value === "string" && value ? value : typeof value === "number" ? String(value) : null;
+}
+
+function stringMap(value: unknown): Record<string, string> {
+  if (!isRecord(value)) return {};
+  const out: Record<string, string> = {};
+  for (const [key, v] of Object.entries(value)) {
+    if (typeof v === "string") out[key] = v;
+  }
+  return out;
+}
+
+/** Version-level package metadata from a details response: `metadata` when present, else the details themselves. */
+function packageFields(details: Record<string, unknown> | undefined): Record<string, unknown> {
+  if (!details) return {};
+  return isRecord(details.metadata) ? details.metadata : details;
+}
+
+function dockerMetadata(src: HarVersionSources): DockerMetadata {
+  const platforms = (src.manifests ?? []).filter(isRecord).map((m): DockerPlatform => {
+    const [os, architecture, variant] = (str(m.osArch) ?? "").split("/");
+    return {
+      os: os || null,
+      architecture: architecture || null,
+      variant: variant || null,
+      digest: str(m.digest),
+      size: str(m.size),
+      created_at: str(m.createdAt),
+    };
+  });
+  return {
+    kind: "docker",
+    image: src.artifact,
+    tag: src.version,
+    platforms,
+    pull_command: str(src.details?.pullCommand),
+  };
+}
+
+function mavenMetadata(src: HarVersionSources): MavenMetadata {
+  // HAR names Maven artifacts "groupId:artifactId".
+  const fields = packageFields(src.details);
+  const [group, artifact] = src.artifact.includes(":") ? src.artifact.split(":", 2) : [null, src.artifact];
+  const groupId = str(fields.groupId) ?? group ?? null;
+  const artifactId = str(fields.artifactId) ?? artifact ?? src.artifact;
+  const files = Array.isArray(fields.files)
+    ? fields.files.map((f) => (isRecord(f) ? str(f.name) : str(f))).filter((f): f is string => f !== null)
+    : [];
+  const packaging = str(fields.packaging) ?? files.map((f) => f.match(/\.(jar|war|ear|aar|pom)$/)?.[1]).find(Boolean) ?? null;
+  return {
+    kind: "maven",
+    group_id: groupId,
+    artifact_id: artifactId,
+    version: src.version,
+    coordinates: [groupId, artifactId, src.version].filter(Boolean).join(":"),
+    packaging,
+    files,
+  };
+}
+
+function npmMetadata(src: HarVersionSources): NpmMetadata {
+  const fields = packageFields(src.details);
+  const versions = src.packument?.versions;
+  const fromPackument = isRecord(versions) ? versions[src.version] : undefined;
+  const versionDoc = isRecord(fromPackument) ? fromPackument : fields;
+  const distTags = stringMap(src.packument?.["dist-tags"] ?? fields["dist-tags"] ?? fields.distTags);
+  const dist = isRecord(versionDoc.dist) ? versionDoc.dist : {};
+  return {
+    kind: "npm",
+    name: str(versionDoc.name) ?? src.artifact,
+    version: src.version,
+    dist_tags: distTags,
+    tags_on_version: Object.entries(distTags).filter(([, v]) => v === src.version).map(([tag]) => tag),
+    dependencies: stringMap(versionDoc.dependencies),
+    peer_dependencies: stringMap(versionDoc.peerDependencies),
+    tarball: str(dist.tarball),
+    integrity: str(dist.integrity) ?? str(dist.shasum),
+  };
+}
+
+function helmMetadata(src: HarVersionSources): HelmMetadata {
+  const fields = packageFields(src.details);
+  return {
+    kind: "helm",
+    chart: src.artifact,
+    version: src.version,
+    app_version: str(fields.appVersion),
+    pull_command: str(src.details?.pullCommand),
+  };
+}
+
+function pythonMetadata(src: HarVersionSources): PythonMetadata {
+  const fields = packageFields(src.details);
+  const requires = fields.requiresDist ?? fields.requires_dist;
+  return {
+    kind: "python",
+    name: str(fields.name) ?? src.artifact,
+    version: src.version,
+    requires_python: str(fields.requiresPython ?? fields.requires_python),
+    requires_dist: Array.isArray(requires) ? requires.filter((r): r is string => typeof r === "string") : [],
+  };
+}
+
+/** Typed metadata for one artifact version, by package type. */
+export function buildPackageMetadata(src: HarVersionSources): PackageMetadata {
+  switch (src.package_type.toUpperCase()) {
+    case "DOCKER":
+      return dockerMetadata(src);
+    case "MAVEN":
+      return mavenMetadata(src);
+    case "NPM":
+      return npmMetadata(src);
+    case "HELM":
+      return helmMetadata(src);
+    case "PYTHON":
+      return pythonMetadata(src);
+    default:
+      return { kind: src.package_type.toLowerCase(), details: packageFields(src.details) };
+  }
+}
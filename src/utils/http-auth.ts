@@ -3,10 +3,27 @@ import type { IncomingHttpHeaders } from "node:http";
 import type { RequestHandler } from "express";
 import type { Config } from "../config.js";
 import { createLogger } from "./logger.js";
+import { hasValidEntitlementToken } from "./http-entitlements.js";
+import { buildWwwAuthenticate, type OAuthPrincipal, type TokenIntrospector } from "./http-oauth.js";
 
 const log = createLogger("http-auth");
 
-type HttpAuthConfig = Pick<Config, "HARNESS_MCP_AUTH_TOKEN" | "HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP" | "HARNESS_MCP_MODE" | "HARNESS_API_KEY">;
+type HttpAuthConfig = Pick<Config, "HARNESS_MCP_AUTH_TOKEN" | "HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP" | "HARNESS_MCP_MODE" | "HARNESS_API_KEY">
+  & Partial<Pick<Config, "HARNESS_MCP_ENTITLEMENTS_SECRET" | "HARNESS_MCP_OAUTH_ISSUER">>;
+
+/** OAuth resource-server settings for the auth middleware. */
+export interface HttpOAuthOptions {
+  introspector: TokenIntrospector;
+  config: Pick<Config, "HARNESS_MCP_OAUTH_SCOPES" | "HARNESS_MCP_OAUTH_RESOURCE">;
+}
+
+/** `res.locals` key holding the OAuth principal of an introspected request. */
+export const OAUTH_PRINCIPAL_LOCAL = "oauthPrincipal";
+
+/** The OAuth principal the auth middleware attached to this response, if any. */
+export function getOAuthPrincipal(locals: Record<string, unknown>): OAuthPrincipal | undefined {
+  return locals[OAUTH_PRINCIPAL_LOCAL] as OAuthPrincipal | undefined;
+}
 
 export function isLoopbackBindHost(host: string): boolean {
   return host === "127.0.0.1" || host === "::1" || host === "localhost";
@@ -32,37 +49,94 @@ function timingSafeStringEqual(left: string, right: string): boolean {
   return timingSafeEqual(leftBuffer, rightBuffer);
 }
 
+/**
+ * A request is authorized by the static bearer token, or — when an
+ * entitlements secret is configured — by a bearer JWT signed with it.
+ */
 export function isAuthorizedHttpRequest(
   headers: IncomingHttpHeaders,
   token: string | undefined,
+  entitlementsSecret?: string,
 ): boolean {
-  if (!token) return true;
+  if (!token && !entitlementsSecret) return true;
+  if (hasValidEntitlementToken(headers, entitlementsSecret)) return true;
+  if (!token) return false;
   const authorization = getHeader(headers, "authorization");
   if (!authorization) return false;
   return timingSafeStringEqual(authorization, `Bearer ${token}`);
 }
 
-export function createHttpAuthMiddleware(token: string | undefined): RequestHandler {
-  return (req, res, next) => {
-    if (req.path === "/health" || req.method === "OPTIONS" || isAuthorizedHttpRequest(req.headers, token)) {
-      next();
-      return;
-    }
+/**
+ * Operator requests carry the static HARNESS_MCP_AUTH_TOKEN bearer itself —
+ * not an entitlement token or an OAuth user. Always false without that token.
+ */
+export function isOperatorRequest(headers: IncomingHttpHeaders, token: string | undefined): boolean {
+  return Boolean(token) && isAuthorizedHttpRequest(headers, token);
+}
 
+/**
+ * Gate every route except /health, /.well-known metadata, and CORS preflight.
+ * With OAuth enabled, a bearer that is neither the static token nor a signed
+ * entitlements JWT is introspected; the resulting principal is stored on
+ * `res.locals` for session creation, and 401s carry a `WWW-Authenticate`
+ * challenge pointing at the protected-resource metadata.
+ */
+export function createHttpAuthMiddleware(
+  token: string | undefined,
+  entitlementsSecret?: string,
+  oauth?: HttpOAuthOptions,
+): RequestHandler {
+  const unauthorized = (res: Parameters<RequestHandler>[1], invalidToken: boolean) => {
+    if (oauth) {
+      res.setHeader("WWW-Authenticate", buildWwwAuthenticate(oauth.config, invalidToken ? "invalid_token" : undefined));
+    }
     res.status(401).json({
       jsonrpc: "2.0",
       error: { code: -32001, message: "Unauthorized" },
       id: null,
     });
   };
+
+  return (req, res, next) => {
+    if (req.path === "/health" || req.path.startsWith("/.well-known/") || req.method === "OPTIONS") {
+      next();
+      return;
+    }
+    if (!oauth) {
+      if (isAuthorizedHttpRequest(req.headers, token, entitlementsSecret)) {
+        next();
+        return;
+      }
+      unauthorized(res, false);
+      return;
+    }
+
+    if ((token || entitlementsSecret) && isAuthorizedHttpRequest(req.headers, token, entitlementsSecret)) {
+      next();
+      return;
+    }
+    const hasBearer = getHeader(req.headers, "authorization")?.startsWith("Bearer ") ?? false;
+    oauth.introspector.authenticate(req.headers).then((principal) => {
+      if (!principal) {
+        unauthorized(res, hasBearer);
+        return;
+      }
+      res.locals[OAUTH_PRINCIPAL_LOCAL] = principal;
+      next();
+    }, next);
+  };
 }
 
 export function validateHttpAuthForBindHost(host: string, config: HttpAuthConfig): void {
+  const hasHttpAuth = !!config.HARNESS_MCP_AUTH_TOKEN
+    || !!config.HARNESS_MCP_ENTITLEMENTS_SECRET
+    || !!config.HARNESS_MCP_OAUTH_ISSUER;
+
   // Check 1: credentials at risk — single-user with an API key and no MCP auth token.
   // Bind address is irrelevant here: a loopback port exposed via reverse proxy or tunnel
   // is just as reachable as a public bind. Warn now; will become an error in next major.
   const hasSingleUserCredentials = config.HARNESS_MCP_MODE !== "multi-user" && !!config.HARNESS_API_KEY;
-  if (hasSingleUserCredentials && !config.HARNESS_MCP_AUTH_TOKEN && !config.HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP) {
+  if (hasSingleUserCredentials && !hasHttpAuth && !config.HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP) {
     log.warn(
       "HTTP single-user mode has no HARNESS_MCP_AUTH_TOKEN set. " +
       "If this port is reachable via a reverse proxy or tunnel, the configured Harness API key is exposed. " +
@@ -73,7 +147,7 @@ export function validateHttpAuthForBindHost(host: string, config: HttpAuthConfig
   }
 
   // Check 2: DNS-rebinding defense — non-loopback binds must be explicitly secured.
-  if (!isLoopbackBindHost(host) && !config.HARNESS_MCP_AUTH_TOKEN && !config.HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP) {
+  if (!isLoopbackBindHost(host) && !hasHttpAuth && !config.HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP) {
     throw new Error(
       "HARNESS_MCP_AUTH_TOKEN is required when HTTP transport binds to a non-loopback host. " +
       "Set HARNESS_MCP_AUTH_TOKEN or explicitly set HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP=true.",
@@ -0,0 +1,106 @@
+import { createHmac, timingSafeEqual } from "node:crypto";
+import type { IncomingHttpHeaders } from "node:http";
+import { isRecord } from "./type-guards.js";
+
+/**
+ * JWT claim listing the toolsets (Harness modules) a session is entitled to.
+ * Toolset aliases are accepted and resolved by the registry.
+ */
+export const ENTITLEMENTS_CLAIM = "toolsets";
+
+/** Allowed clock skew when checking `exp` / `nbf`. */
+const CLOCK_SKEW_SECONDS = 30;
+
+/** Longest lifetime a token may claim, so a leaked token cannot outlive a day. */
+export const MAX_TOKEN_LIFETIME_SECONDS = 24 * 60 * 60;
+
+/**
+ * Error thrown when a session presents a signed bearer token whose
+ * entitlements are missing or malformed. Handled in the HTTP session
+ * creation path to return a JSON-RPC 401.
+ */
+export class InvalidEntitlementsError extends Error {
+  constructor(reason: string) {
+    super(`Invalid session entitlements: ${reason}`);
+    this.name = "InvalidEntitlementsError";
+  }
+}
+
+function getBearerToken(headers: IncomingHttpHeaders): string | undefined {
+  const raw = headers.authorization;
+  const value = Array.isArray(raw) ? raw[0] : raw;
+  if (typeof value !== "string" || !value.startsWith("Bearer ")) return undefined;
+  return value.slice("Bearer ".length).trim() || undefined;
+}
+
+function decodeSegment(segment: string): unknown {
+  try {
+    return JSON.parse(Buffer.from(segment, "base64url").toString("utf8"));
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Verify an HS256 JWT against `secret` and return its claims.
+ * Returns `undefined` for anything that is not a valid, currently-active token
+ * signed with the secret — including static (non-JWT) bearer tokens. Tokens
+ * must carry a numeric `exp` no more than MAX_TOKEN_LIFETIME_SECONDS after
+ * `iat` (or after now, without `iat`).
+ */
+export function verifyEntitlementToken(
+  token: string,
+  secret: string,
+  nowSeconds: number = Math.floor(Date.now() / 1000),
+): Record<string, unknown> | undefined {
+  const parts = token.split(".");
+  if (parts.length !== 3) return undefined;
+  const [headerPart, payloadPart, signaturePart] = parts as [string, string, string];
+
+  const header = decodeSegment(headerPart);
+  if (!isRecord(header) || header.alg !== "HS256") return undefined;
+
+  const expected = createHmac("sha256", secret).update(`${headerPart}.${payloadPart}`).digest();
+  const actual = Buffer.from(signaturePart, "base64url");
+  if (actual.length !== expected.length || !timingSafeEqual(actual, expected)) return undefined;
+
+  const claims = decodeSegment(payloadPart);
+  if (!isRecord(claims)) return undefined;
+  if (typeof claims.exp !== "number" || nowSeconds > claims.exp + CLOCK_SKEW_SECONDS) return undefined;
+  if (claims.exp - nowSeconds > MAX_TOKEN_LIFETIME_SECONDS + CLOCK_SKEW_SECONDS) return undefined;
+  if (typeof claims.iat === "number" && claims.exp - claims.iat > MAX_TOKEN_LIFETIME_SECONDS) return undefined;
+  if (typeof claims.nbf === "number" && nowSeconds + CLOCK_SKEW_SECONDS < claims.nbf) return undefined;
+  return claims;
+}
+
+/** True when the request carries a bearer JWT that verifies against `secret`. */
+export function hasValidEntitlementToken(headers: IncomingHttpHeaders, secret: string | undefined): boolean {
+  if (!secret) return false;
+  const token = getBearerToken(headers);
+  return token !== undefined && verifyEntitlementToken(token, secret) !== undefined;
+}
+
+/**
+ * Resolve the toolsets a new HTTP session is entitled to from its bearer token.
+ *
+ * Returns `undefined` (no restriction beyond HARNESS_TOOLSETS) when no secret is
+ * configured or the bearer is not a token signed with it — e.g. the operator's
+ * static HARNESS_MCP_AUTH_TOKEN. A signed token must carry a non-empty
+ * `toolsets` string array; otherwise the session is rejected.
+ */
+export function parseSessionEntitlements(
+  headers: IncomingHttpHeaders,
+  secret: string | undefined,
+): ReadonlySet<string> | undefined {
+  if (!secret) return undefined;
+  const token = getBearerToken(headers);
+  if (!token) return undefined;
+  const claims = verifyEntitlementToken(token, secret);
+  if (!claims) return undefined;
+
+  const entitled = claims[ENTITLEMENTS_CLAIM];
+  if (!Array.isArray(entitled) || entitled.length === 0 || !entitled.every((t) => typeof t === "string" && t.trim())) {
+    throw new InvalidEntitlementsError(`token must carry a non-empty "${ENTITLEMENTS_CLAIM}" string array claim`);
+  }
+  return new Set(entitled.map((t) => (t as string).trim()));
+}
@@ -0,0 +1,280 @@
+import { createHash } from "node:crypto";
+import type { IncomingHttpHeaders } from "node:http";
+import type { Config } from "../config.js";
+import { createLogger } from "./logger.js";
+import { ENTITLEMENTS_CLAIM } from "./http-entitlements.js";
+import { isRecord } from "./type-guards.js";
+
+const log = createLogger("http-oauth");
+
+/** RFC 9728 protected-resource metadata, discovered from a 401 challenge. */
+export const OAUTH_PROTECTED_RESOURCE_PATH = "/.well-known/oauth-protected-resource";
+/**
+ * RFC 8414 authorization-server metadata. Mirrored from the issuer for
+ * clients that still look it up on the MCP server (protocol 2025-03-26).
+ */
+export const OAUTH_AUTHORIZATION_SERVER_PATH = "/.well-known/oauth-authorization-server";
+
+/** Claims that may carry the Harness account ID, in order of preference. */
+const ACCOUNT_CLAIMS = ["accountId", "account_id", "harness_account_id"] as const;
+
+/** Upper bound on how long an introspection result is reused. */
+const INTROSPECTION_CACHE_TTL_MS = 60_000;
+const INTROSPECTION_CACHE_MAX = 1_000;
+const METADATA_CACHE_TTL_MS = 60 * 60_000;
+const OAUTH_TIMEOUT_MS = 10_000;
+const CLOCK_SKEW_SECONDS = 30;
+
+type OAuthConfig = Pick<Config, "HARNESS_MCP_OAUTH_SCOPES">
+  & Partial<Pick<Config,
+    | "HARNESS_MCP_OAUTH_ISSUER"
+    | "HARNESS_MCP_OAUTH_RESOURCE"
+    | "HARNESS_MCP_OAUTH_INTROSPECTION_URL"
+    | "HARNESS_MCP_OAUTH_CLIENT_ID"
+    | "HARNESS_MCP_OAUTH_CLIENT_SECRET">>;
+
+type FetchFn = typeof fetch;
+
+/** The authenticated caller behind an OAuth access token. */
+export interface OAuthPrincipal {
+  subject: string;
+  clientId?: string;
+  email?: string;
+  accountId?: string;
+  scopes: string[];
+  /** Toolsets from the `toolsets` claim, when the issuer emits one. */
+  toolsets?: ReadonlySet<string>;
+  /** The access token itself, forwarded to Harness in multi-user mode. */
+  token: string;
+  /** Expiry in epoch seconds, from the `exp` claim. */
+  expiresAt?: number;
+}
+
+export function isOAuthEnabled(config: Partial<Pick<Config, "HARNESS_MCP_OAUTH_ISSUER">>): boolean {
+  return !!config.HARNESS_MCP_OAUTH_ISSUER;
+}
+
+function scopesSupported(config: OAuthConfig): string[] {
+  return config.HARNESS_MCP_OAUTH_SCOPES.split(/[\s,]+/).filter(Boolean);
+}
+
+/** URL of the protected-resource metadata document for `resource`. */
+export function protectedResourceMetadataUrl(resource: string): string {
+  return new URL(OAUTH_PROTECTED_RESOURCE_PATH, resource).toString();
+}
+
+/** RFC 9728 metadata pointing clients at the Harness authorization server. */
+export function buildProtectedResourceMetadata(config: OAuthConfig): Record<string, unknown> {
+  return {
+    resource: config.HARNESS_MCP_OAUTH_RESOURCE,
+    authorization_servers: [config.HARNESS_MCP_OAUTH_ISSUER],
+    scopes_supported: scopesSupported(config),
+    bearer_methods_supported: ["header"],
+    resource_name: "Harness MCP Server",
+  };
+}
+
+/**
+ * `WWW-Authenticate` value for a 401 response. Points clients at the
+ * protected-resource metadata so they can start the authorization flow.
+ */
+export function buildWwwAuthenticate(config: OAuthConfig, error?: "invalid_token"): string {
+  const parts = [`resource_metadata="${protectedResourceMetadataUrl(config.HARNESS_MCP_OAUTH_RESOURCE!)}"`];
+  if (error) parts.push(`error="${error}"`);
+  return `Bearer ${parts.join(", ")}`;
+}
+
+function getBearerToken(headers: IncomingHttpHeaders): string | undefined {
+  const raw = headers.authorization;
+  const value = Array.isArray(raw) ? raw[0] : raw;
+  if (typeof value !== "string" || !value.startsWith("Bearer ")) return undefined;
+  return value.slice("Bearer ".length).trim() || undefined;
+}
+
+function stringClaim(claims: Record<string, unknown>, name: string): string | undefined {
+  const value = claims[name];
+  return typeof value === "string" && value.trim() ? value.trim() : undefined;
+}
+
+function audienceMatches(aud: unknown, resource: string): boolean {
+  const normalize = (value: string) => value.replace(/\/+$/, "");
+  const expected = normalize(resource);
+  if (typeof aud === "string") return normalize(aud) === expected;
+  return Array.isArray(aud) && aud.some((a) => typeof a === "string" && normalize(a) === expected);
+}
+
+/**
+ * Map an active introspection response to the session principal.
+ * Returns `undefined` when the token must not be accepted: inactive, expired,
+ * from another issuer, issued for another resource, or carrying a malformed
+ * `toolsets` claim (a restriction we cannot read must not widen access).
+ */
+export function mapClaimsToPrincipal(
+  claims: Record<string, unknown>,
+  token: string,
+  config: OAuthConfig,
+  nowSeconds: number = Math.floor(Date.now() / 1000),
+): OAuthPrincipal | undefined {
+  if (claims.active !== true) return undefined;
+  if (typeof claims.exp === "number" && nowSeconds > claims.exp + CLOCK_SKEW_SECONDS) return undefined;
+  if (typeof claims.nbf === "number" && nowSeconds + CLOCK_SKEW_SECONDS < claims.nbf) return undefined;
+
+  const issuer = stringClaim(claims, "iss");
+  if (issuer && config.HARNESS_MCP_OAUTH_ISSUER && issuer.replace(/\/+$/, "") !== config.HARNESS_MCP_OAUTH_ISSUER.replace(/\/+$/, "")) {
+    return undefined;
+  }
+  if (config.HARNESS_MCP_OAUTH_RESOURCE && !audienceMatches(claims.aud, config.HARNESS_MCP_OAUTH_RESOURCE)) {
+    return undefined;
+  }
+
+  const subject = stringClaim(claims, "sub") ?? stringClaim(claims, "username") ?? stringClaim(claims, "client_id");
+  if (!subject) return undefined;
+
+  let toolsets: ReadonlySet<string> | undefined;
+  const entitled = claims[ENTITLEMENTS_CLAIM];
+  if (entitled !== undefined) {
+    if (!Array.isArray(entitled) || entitled.length === 0 || !entitled.every((t) => typeof t === "string" && t.trim())) {
+      return undefined;
+    }
+    toolsets = new Set(entitled.map((t) => (t as string).trim()));
+  }
+
+  const accountId = ACCOUNT_CLAIMS.map((name) => stringClaim(claims, name)).find(Boolean);
+  const clientId = stringClaim(claims, "client_id");
+  const email = stringClaim(claims, "email");
+  return {
+    subject,
+    ...(clientId ? { clientId } : {}),
+    ...(email ? { email } : {}),
+    ...(accountId ? { accountId } : {}),
+    scopes: typeof claims.scope === "string" ? claims.scope.split(" ").filter(Boolean) : [],
+    ...(toolsets ? { toolsets } : {}),
+    token,
+    ...(typeof claims.exp === "number" ? { expiresAt: claims.exp } : {}),
+  };
+}
+
+/**
+ * Validates OAuth access tokens against the authorization server's RFC 7662
+ * introspection endpoint. Results are cached briefly (never past the token's
+ * `exp`) keyed by a hash of the token, so a session's follow-up requests do
+ * not each cost an introspection round trip.
+ */
+export class TokenIntrospector {
+  private readonly cache = new Map<string, { principal: OAuthPrincipal | undefined; expiresAt: number }>();
+  private metadata?: { value: Record<string, unknown>; expiresAt: number };
+
+  constructor(
+    private readonly config: OAuthConfig,
+    private readonly fetchFn: FetchFn = fetch,
+  ) {}
+
+  /** The issuer's authorization-server metadata (OIDC discovery, then RFC 8414). */
+  async authorizationServerMetadata(): Promise<Record<string, unknown>> {
+    if (this.metadata && Date.now() < this.metadata.expiresAt) return this.metadata.value;
+    const issuer = this.config.HARNESS_MCP_OAUTH_ISSUER!.replace(/\/+$/, "");
+    let lastError: unknown;
+    for (const path of ["/.well-known/openid-configuration", OAUTH_AUTHORIZATION_SERVER_PATH]) {
+      try {
+        const res = await this.fetchFn(`${issuer}${path}`, {
+          headers: { Accept: "application/json" },
+          signal: AbortSignal.timeout(OAUTH_TIMEOUT_MS),
+        });
+        if (!res.ok) {
+          lastError = new Error(`HTTP ${res.status} from ${issuer}${path}`);
+          continue;
+        }
+        const body = await res.json();
+        if (!isRecord(body)) {
+          lastError = new Error(`Malformed metadata from ${issuer}${path}`);
+          continue;
+        }
+        this.metadata = { value: body, expiresAt: Date.now() + METADATA_CACHE_TTL_MS };
+        return body;
+      } catch (err) {
+        lastError = err;
+      }
+    }
+    throw new Error(`OAuth issuer metadata unavailable: ${lastError instanceof Error ? lastError.message : String(lastError)}`);
+  }
+
+  private async introspectionEndpoint(): Promise<string> {
+    if (this.config.HARNESS_MCP_OAUTH_INTROSPECTION_URL) return this.config.HARNESS_MCP_OAUTH_INTROSPECTION_URL;
+    const endpoint = (await this.authorizationServerMetadata()).introspection_endpoint;
+    if (typeof endpoint !== "string" || !endpoint) {
+      throw new Error("OAuth issuer does not advertise an introspection_endpoint; set HARNESS_MCP_OAUTH_INTROSPECTION_URL.");
+    }
+    return endpoint;
+  }
+
+  /**
+   * Resolve the principal for `token`, or `undefined` when the token is not
+   * active for this resource. Errors reaching the authorization server are
+   * logged and treated as unauthenticated, and are not cached.
+   */
+  async introspect(token: string): Promise<OAuthPrincipal | undefined> {
+    const key = createHash("sha256").update(token).digest("hex");
+    const now = Date.now();
+    const cached = this.cache.get(key);
+    if (cached && now < cached.expiresAt) return cached.principal;
+    this.cache.delete(key);
+
+    let principal: OAuthPrincipal | undefined;
+    try {
+      const headers: Record<string, string> = {
+        "Content-Type": "application/x-www-form-urlencoded",
+        Accept: "application/json",
+      };
+      const clientId = this.config.HARNESS_MCP_OAUTH_CLIENT_ID;
+      if (clientId) {
+        const secret = this.config.HARNESS_MCP_OAUTH_CLIENT_SECRET ?? "";
+        headers.Authorization = `Basic ${Buffer.from(`${encodeURIComponent(clientId)}:${encodeURIComponent(secret)}`).toString("base64")}`;
+      }
+      const res = await this.fetchFn(await this.introspectionEndpoint(), {
+        method: "POST",
+        headers,
+        body: new URLSearchParams({ token, token_type_hint: "access_token" }).toString(),
+        signal: AbortSignal.timeout(OAUTH_TIMEOUT_MS),
+      });
+      if (!res.ok) throw new Error(`introspection returned HTTP ${res.status}`);
+      const claims = await res.json();
+      principal = isRecord(claims) ? mapClaimsToPrincipal(claims, token, this.config) : undefined;
+    } catch (err) {
+      log.warn("OAuth token introspection failed", { error: err instanceof Error ? err.message : String(err) });
+      return undefined;
+    }
+
+    let expiresAt = now + INTROSPECTION_CACHE_TTL_MS;
+    if (principal?.expiresAt !== undefined) expiresAt = Math.min(expiresAt, principal.expiresAt * 1000);
+    if (this.cache.size >= INTROSPECTION_CACHE_MAX) {
+      const oldest = this.cache.keys().next().value;
+      if (oldest !== undefined) this.cache.delete(oldest);
+    }
+    this.cache.set(key, { principal, expiresAt });
+    return principal;
+  }
+
+  /** Introspect the request's bearer token, if any. */
+  async authenticate(headers: IncomingHttpHeaders): Promise<OAuthPrincipal | undefined> {
+    const token = getBearerToken(headers);
+    return token ? this.introspect(token) : undefined;
+  }
+}
+
+/**
+ * Authorization-server metadata served on the MCP origin: the issuer's own
+ * document, so authorization, token, and dynamic client registration
+ * (`registration_endpoint`) requests go straight to Harness OIDC.
+ */
+export async function buildAuthorizationServerMetadata(
+  config: OAuthConfig,
+  introspector: TokenIntrospector,
+): Promise<Record<string, unknown>> {
+  const upstream = await introspector.authorizationServerMetadata();
+  return {
+    ...upstream,
+    issuer: upstream.issuer ?? config.HARNESS_MCP_OAUTH_ISSUER,
+    scopes_supported: upstream.scopes_supported ?? scopesSupported(config),
+    code_challenge_methods_supported: upstream.code_challenge_methods_supported ?? ["S256"],
+  };
+}
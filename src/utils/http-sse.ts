@@ -0,0 +1,37 @@
+/**
+ * Helpers for the legacy HTTP+SSE transport (MCP protocol 2024-11-05), served
+ * by `harness-mcp-server sse` for clients that predate Streamable HTTP. The
+ * client opens `GET /sse`, receives an `endpoint` event, and POSTs JSON-RPC
+ * messages to `/messages?sessionId=<id>`.
+ */
+import type { ServerResponse } from "node:http";
+
+export const LEGACY_SSE_PATH = "/sse";
+export const LEGACY_MESSAGES_PATH = "/messages";
+
+/** Read the session ID the legacy transport appends to the messages endpoint. */
+export function legacySseSessionId(query: unknown): string | undefined {
+  if (!query || typeof query !== "object") return undefined;
+  const value = (query as Record<string, unknown>).sessionId;
+  return typeof value === "string" && value ? value : undefined;
+}
+
+/**
+ * Write an SSE comment every `intervalMs` so proxies and load balancers do not
+ * close idle streams. SSE clients ignore comment lines. Stops when the response
+ * closes or the returned function is called; `intervalMs <= 0` disables it.
+ */
+export function startSseHeartbeat(res: ServerResponse, intervalMs: number): () => void {
+  if (intervalMs <= 0) return () => {};
+  const timer = setInterval(() => {
+    if (res.writableEnded || res.destroyed) {
+      stop();
+      return;
+    }
+    res.write(": heartbeat\n\n");
+  }, intervalMs);
+  timer.unref();
+  const stop = (): void => clearInterval(timer);
+  res.once("close", stop);
+  return stop;
+}
@@ -0,0 +1,358 @@
+/**
+ * Per-principal and per-tool rate limiting for tools/call in HTTP mode.
+ *
+ * The per-IP limit in front of /mcp counts HTTP requests; it cannot tell one
+ * runaway agent from a busy team behind a NAT, and it does not distinguish a
+ * cheap harness_describe from a harness_execute. This limiter keys on the
+ * caller (OAuth subject, else a hash of the session API key, else client IP)
+ * and keeps two token buckets per call: one for the principal across all
+ * tools, and one for the principal on the specific tool.
+ *
+ * Throttled calls are counted per account, tool, and limit kind and exposed
+ * in Prometheus text format on GET /metrics. The account label goes through
+ * the shared cardinality cap in usage-metrics.ts.
+ */
+import { createHash } from "node:crypto";
+import type { NextFunction, Request, Response } from "express";
+import type { Config } from "../config.js";
+import { getOAuthPrincipal } from "./http-auth.js";
+import { ACCOUNT_ID_HEADER } from "./session-headers.js";
+import { accountLabel } from "./usage-metrics.js";
+
+/** Buckets idle longer than this are dropped by `prune()`. */
+const IDLE_BUCKET_MS = 10 * 60_000;
+
+class TokenBucket {
+  private tokens: number;
+  private lastRefill: number;
+
+  constructor(private readonly perMinute: number, now: number) {
+    this.tokens = perMinute;
+    this.lastRefill = now;
+  }
+
+  get lastUsed(): number {
+    return this.lastRefill;
+  }
+
+  /** Take one token, or return the ms until one is available. */
+  take(now: number): number {
+    const refillPerMs = this.perMinute / 60_000;
+    this.tokens = Math.min(this.perMinute, this.tokens + (now - this.lastRefill) * refillPerMs);
+    this.lastRefill = now;
+    if (this.tokens >= 1) {
+      this.tokens -= 1;
+      return 0;
+    }
+    return Math.ceil((1 - this.tokens) / refillPerMs);
+  }
+}
+
+export type ToolLimitKind = "principal" | "tool";
+
+export type ToolRateDecision =
+  | { allowed: true }
+  | { allowed: false; limit: ToolLimitKind; retryAfterMs: number };
+
+export interface ToolRateLimitOptions {
+  /** Calls per minute per principal across all tools. 0 disables. */
+  principalPerMinute: number;
+  /** Default calls per minute per principal per tool. 0 disables. */
+  toolPerMinute: number;
+  /** Per-tool overrides of `toolPerMinute`, e.g. { harness_execute: 10 }. */
+  toolOverrides?: Record<string, number>;
+}
+
+const throttled = new Map<string, number>();
+
+function recordThrottle(account: string, tool: string, limit: ToolLimitKind): void {
+  const key = `${accountLabel(account)}\u0000${tool}\u0000${limit}`;
+  throttled.set(key, (throttled.get(key) ?? 0) + 1);
+}
+
+/** Reset throttle counters (tests). */
+export function resetToolRateLimitMetrics(): void {
+  throttled.clear();
+}
+
+function escapeLabel(value: string): string {
+  return value.replace(/\\/g, "\\\\").replace(/"/g, '\\"').replace(/\n/g, "\\n");
+}
+
+/** Throttle counters in Prometheus text exposition format. */
+export function renderToolRateLimitMetrics(): string {
+  const lines = [
+    "# HELP harness_mcp_tool_calls_throttled_total Tool calls rejected by the per-principal or per-tool rate limit.",
+    "# TYPE harness_mcp_tool_calls_throttled_total counter",
+  ];
+  for (const [key, count] of [...throttled.entries()].sort(([a], [b]) => a.localeCompare(b))) {
+    const [account, tool, limit] = key.split("\u0000") as [string, string, string];
+    lines.push(
+      `harness_mcp_tool_calls_throttled_total{account="${escapeLabel(account)}",tool="${escapeLabel(tool)}",limit="${limit}"} ${count}`,
+    );
+  }
+  return `${lines.join("\n")}\n`;
+}
+
+export class ToolRateLimiter {
+  private readonly principals = new Map<string, TokenBucket>();
+  private readonly tools = new Map<string, TokenBucket>();
+
+  constructor(private options: ToolRateLimitOptions) {}
+
+  /** Replace the limits (config reload). Buckets restart full at the new sizes. */
+  reconfigure(options: ToolRateLimitOptions): void {
+    this.options = options;
+    this.principals.clear();
+    this.tools.clear();
+  }
+
+  get enabled(): boolean {
+    return this.options.principalPerMinute > 0
+      || this.options.toolPerMinute > 0
+      || Object.values(this.options.toolOverrides ?? {}).some((n) => n > 0);
+  }
+
+  private toolLimit(tool: string): number {
+    return this.options.toolOverrides?.[tool] ?? this.options.toolPerMinute;
+  }
+
+  /**
+   * Charge one call to `principal` on `tool`. The tool bucket is checked
+   * first so a throttled tool does not also drain the principal's budget.
+   * `account` only labels the throttle counter.
+   */
+  check(principal: string, tool: string, now = Date.now(), account = "unknown"): ToolRateDecision {
+    const toolLimit = this.toolLimit(tool);
+    if (toolLimit > 0) {
+      const key = `${principal}\u0000${tool}`;
+      let bucket = this.tools.get(key);
+      if (!bucket) {
+        bucket = new TokenBucket(toolLimit, now);
+        this.tools.set(key, bucket);
+      }
+      const wait = bucket.take(now);
+      if (wait > 0) {
+        recordThrottle(account, tool, "tool");
+        return { allowed: false, limit: "tool", retryAfterMs: wait };
+      }
+    }
+    if (this.options.principalPerMinute > 0) {
+      let bucket = this.principals.get(principal);
+      if (!bucket) {
+        bucket = new TokenBucket(this.options.principalPerMinute, now);
+        this.principals.set(principal, bucket);
+      }
+      const wait = bucket.take(now);
+      if (wait > 0) {
+        recordThrottle(account, tool, "principal");
+        return { allowed: false, limit: "principal", retryAfterMs: wait };
+      }
+    }
+    return { allowed: true };
+  }
+
+  /** Drop buckets unused for IDLE_BUCKET_MS (a full bucket after that long anyway). */
+  prune(now = Date.now()): void {
+    for (const map of [this.principals, this.tools]) {
+      for (const [key, bucket] of map) {
+        if (now - bucket.lastUsed >= IDLE_BUCKET_MS) map.delete(key);
+      }
+    }
+  }
+}
+
+/** Parse "harness_execute=10,harness_list=120" into per-tool limits. */
+export function parseToolRateOverrides(spec: string | undefined): Record<string, number> {
+  const overrides: Record<string, number> = {};
+  for (const raw of (spec ?? "").split(",")) {
+    const token = raw.trim();
+    if (!token) continue;
+    const [tool, value] = token.split("=").map((s) => s.trim());
+    const limit = Number(value);
+    if (!tool || !Number.isInteger(limit) || limit < 0) {
+      throw new Error(`Invalid HARNESS_TOOL_RATE_LIMITS entry "${token}" (expected tool=calls_per_minute)`);
+    }
+    overrides[tool] = limit;
+  }
+  return overrides;
+}
+
+type ToolRateLimitConfig = Pick<Config, "HARNESS_TOOL_RATE_LIMIT_PER_MIN" | "HARNESS_TOOL_RATE_LIMIT_PER_TOOL_PER_MIN" | "HARNESS_TOOL_RATE_LIMITS">;
+
+/** Limiter options from config. Throws on malformed HARNESS_TOOL_RATE_LIMITS. */
+export function toolRateLimitOptions(config: ToolRateLimitConfig): ToolRateLimitOptions {
+  return {
+    principalPerMinute: config.HARNESS_TOOL_RATE_LIMIT_PER_MIN,
+    toolPerMinute: config.HARNESS_TOOL_RATE_LIMIT_PER_TOOL_PER_MIN,
+    toolOverrides: parseToolRateOverrides(config.HARNESS_TOOL_RATE_LIMITS),
+  };
+}
+
+/** Build the limiter from config; undefined when every limit is 0. */
+export function createToolRateLimiter(config: ToolRateLimitConfig): ToolRateLimiter | undefined {
+  const limiter = new ToolRateLimiter(toolRateLimitOptions(config));
+  return limiter.enabled ? limiter : undefined;
+}
+
+/**
+ * Rate-limit key for a request: the OAuth subject, else a hash of the
+ * session's Harness API key (multi-user mode), else the client IP.
+ */
+export function resolveRateLimitPrincipal(req: Request, res: Response): string {
+  const principal = getOAuthPrincipal(res.locals);
+  if (principal) return `sub:${principal.subject}`;
+  const apiKey = req.headers["x-harness-api-key"];
+  if (typeof apiKey === "string" && apiKey) {
+    return `key:${createHash("sha256").update(apiKey).digest("hex").slice(0, 16)}`;
+  }
+  return `ip:${req.ip ?? "unknown"}`;
+}
+
+/**
+ * Harness account a request acts for: the OAuth principal's account, else the
+ * x-harness-account-id header, else `fallback` (HARNESS_ACCOUNT_ID).
+ */
+export function resolveRequestAccount(req: Request, res: Response, fallback?: string): string {
+  const principal = getOAuthPrincipal(res.locals);
+  if (principal?.accountId) return principal.accountId;
+  const header = req.headers[ACCOUNT_ID_HEADER];
+  if (typeof header === "string" && header) return header;
+  return fallback || "unknown";
+}
+
+/**
+ * Resource types whose harness_get is a liveness check. Agents call it at the
+ * start of a conversation to test the connection, so neither the per-IP
+ * limit nor the tool limits throttle it. Only this server's limits are
+ * waived: the upstream call still waits for the HARNESS_RATE_LIMIT_RPS bucket.
+ */
+const RATE_LIMIT_EXEMPT_RESOURCES = new Set(["ping"]);
+
+/**
+ * Exempt calls allowed per HTTP request. Further pings in the same JSON-RPC
+ * batch count like any other call, so batching cannot multiply free calls.
+ */
+const MAX_EXEMPT_CALLS_PER_REQUEST = 1;
+
+function isRateLimitExempt(name: string, args: unknown): boolean {
+  if (name !== "harness_get" || !args || typeof args !== "object") return false;
+  const resourceType = (args as { resource_type?: unknown }).resource_type;
+  return typeof resourceType === "string" && RATE_LIMIT_EXEMPT_RESOURCES.has(resourceType);
+}
+
+/** True when a JSON-RPC message or batch holds only exempt liveness checks, no more than the per-request cap. */
+function isExemptRequestBody(body: unknown): boolean {
+  const messages = Array.isArray(body) ? body : [body];
+  return messages.length > 0 && messages.length <= MAX_EXEMPT_CALLS_PER_REQUEST && messages.every((message) => {
+    if (!message || typeof message !== "object") return false;
+    const { method, params } = message as { method?: unknown; params?: { name?: unknown; arguments?: unknown } };
+    return method === "tools/call" && typeof params?.name === "string" && isRateLimitExempt(params.name, params.arguments);
+  });
+}
+
+export interface IpRateLimiter {
+  /**
+   * Counts the request and answers 429 once the IP is over its limit. Mount
+   * before body parsing so a flooding client is turned away without its body
+   * being buffered.
+   */
+  middleware: (req: Request, res: Response, next: NextFunction) => void;
+  /**
+   * Gives the count back when the parsed body turns out to be a single ping,
+   * so liveness checks do not use up the limit. Mount after body parsing.
+   */
+  refundExempt: (req: Request, res: Response, next: NextFunction) => void;
+  /** Drop counters whose window has ended, so the map does not grow per IP forever. */
+  prune(now?: number): void;
+  /** Number of IPs currently tracked. */
+  readonly size: number;
+}
+
+/** Limits each client IP to `limit` HTTP requests per window. A limit of 0 disables it. */
+export function createIpRateLimiter(limit = 60, windowMs = 60_000): IpRateLimiter {
+  const hits = new Map<string, { count: number; resetAt: number }>();
+  const middleware = (req: Request, res: Response, next: NextFunction): void => {
+    if (limit === 0) {
+      next();
+      return;
+    }
+    const ip = req.ip ?? "unknown";
+    const now = Date.now();
+    let entry = hits.get(ip);
+    if (!entry || now >= entry.resetAt) {
+      entry = { count: 0, resetAt: now + windowMs };
+      hits.set(ip, entry);
+    }
+    entry.count++;
+    if (entry.count > limit) {
+      res.status(429).json({
+        jsonrpc: "2.0",
+        error: { code: -32000, message: "Too many requests. Try again later." },
+        id: null,
+      });
+      return;
+    }
+    next();
+  };
+  const refundExempt = (req: Request, _res: Response, next: NextFunction): void => {
+    const entry = hits.get(req.ip ?? "unknown");
+    if (entry && entry.count > 0 && isExemptRequestBody(req.body)) entry.count--;
+    next();
+  };
+  const prune = (now = Date.now()): void => {
+    for (const [ip, entry] of hits) {
+      if (now >= entry.resetAt) hits.delete(ip);
+    }
+  };
+  return { middleware, refundExempt, prune, get size() { return hits.size; } };
+}
+
+/** Tool names of every rate-limited tools/call in a JSON-RPC message or batch. */
+function toolCallNames(body: unknown): string[] {
+  const messages = Array.isArray(body) ? body : [body];
+  const names: string[] = [];
+  let exempt = 0;
+  for (const message of messages) {
+    if (!message || typeof message !== "object") continue;
+    const { method, params } = message as { method?: unknown; params?: { name?: unknown; arguments?: unknown } };
+    if (method !== "tools/call" || typeof params?.name !== "string") continue;
+    if (isRateLimitExempt(params.name, params.arguments) && exempt < MAX_EXEMPT_CALLS_PER_REQUEST) exempt++;
+    else names.push(params.name);
+  }
+  return names;
+}
+
+/**
+ * Express middleware for POST /mcp (after JSON parsing). Rejects a request
+ * with 429 and Retry-After when any tools/call in it is over its limit. A
+ * disabled limiter passes everything, so limits can be turned on by reload.
+ */
+export function createToolRateLimitMiddleware(limiter: ToolRateLimiter, defaultAccount?: string) {
+  return (req: Request, res: Response, next: NextFunction): void => {
+    const names = limiter.enabled ? toolCallNames(req.body) : [];
+    if (names.length === 0) {
+      next();
+      return;
+    }
+    const principal = resolveRateLimitPrincipal(req, res);
+    const account = resolveRequestAccount(req, res, defaultAccount);
+    for (const name of names) {
+      const decision = limiter.check(principal, name, Date.now(), account);
+      if (!decision.allowed) {
+        const scope = decision.limit === "tool" ? `${name} calls` : "tool calls";
+        res.setHeader("Retry-After", String(Math.max(1, Math.ceil(decision.retryAfterMs / 1000))));
+        res.status(429).json({
+          jsonrpc: "2.0",
+          error: {
+            code: -32000,
+            message: `Rate limit exceeded for ${scope}. Retry in ${Math.ceil(decision.retryAfterMs / 1000)}s.`,
+          },
+          id: (req.body as { id?: unknown } | undefined)?.id ?? null,
+        });
+        return;
+      }
+    }
+    next();
+  };
+}
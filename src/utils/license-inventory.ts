@@ -0,0 +1,163 @@
+/**
+ * License inventory across a project's artifacts: how many components and
+ * artifacts use each license, and which artifacts carry licenses outside an
+ * allow-list. License fields are SPDX expressions, so "MIT OR GPL-3.0" passes
+ * an allow-list containing MIT while "MIT AND GPL-3.0" does not.
+ */
+
+export interface InventoryComponent {
+  name: string;
+  version?: string;
+  license?: string;
+}
+
+export interface InventoryArtifact {
+  artifact_id: string;
+  name?: string;
+  kind: "artifact" | "code_repo";
+  components: InventoryComponent[];
+  /** The component list stopped at the read limit. */
+  truncated?: boolean;
+}
+
+export interface LicenseViolation {
+  artifact_id: string;
+  name?: string;
+  kind: "artifact" | "code_repo";
+  licenses: Array<{ license: string; components: number; examples: string[] }>;
+}
+
+export interface LicenseInventory {
+  artifacts_scanned: number;
+  components: number;
+  licenses: Array<{ license: string; components: number; artifacts: number; allowed?: boolean }>;
+  /** Components with no license, or only NOASSERTION/NONE. */
+  unlicensed: { components: number; artifacts: number };
+  /** Present when an allow-list was given. */
+  violations?: LicenseViolation[];
+}
+
+/** Component names listed per violating license. */
+export const LICENSE_VIOLATION_EXAMPLES = 5;
+
+const NO_LICENSE = new Set(["", "NOASSERTION", "NONE", "UNKNOWN"]);
+
+type Expr = { op: "or" | "and"; terms: Expr[] } | { license: string };
+
+/** Parse an SPDX expression: AND binds tighter than OR, parentheses group, WITH stays on its license. */
+function parseExpression(text: string): Expr | undefined {
+  const tokens = text.match(/\(|\)|[^\s()]+/g) ?? [];
+  let pos = 0;
+  const primary = (): Expr | undefined => {
+    const token = tokens[pos++];
+    if (token === undefined || token === ")") return undefined;
+    if (token === "(") {
+      const inner = or();
+      if (tokens[pos] === ")") pos++;
+      return inner;
+    }
+    let license = token;
+    if (tokens[pos]?.toUpperCase() === "WITH" && tokens[pos + 1] !== undefined) {
+      license = `${license} WITH ${tokens[pos + 1]}`;
+      pos += 2;
+    }
+    return { license };
+  };
+  const sequence = (op: "or" | "and", next: () => Expr | undefined): Expr | undefined => {
+    const terms: Expr[] = [];
+    for (;;) {
+      const term = next();
+      if (term) terms.push(term);
+      if (tokens[pos]?.toUpperCase() !== op.toUpperCase()) break;
+      pos++;
+    }
+    if (terms.length === 0) return undefined;
+    return terms.length === 1 ? terms[0] : { op, terms };
+  };
+  const and = () => sequence("and", primary);
+  const or = (): Expr | undefined => sequence("or", and);
+  return or();
+}
+
+/**
+ * Whether a license expression is satisfied by the allow-list (matched
+ * case-insensitively). A license with an exception ("Apache-2.0 WITH
+ * LLVM-exception") passes when either the full term or its base license is
+ * allowed.
+ */
+export function licenseAllowed(expression: string, allowList: ReadonlySet<string>): boolean {
+  const allowed = new Set([...allowList].map((l) => l.toLowerCase()));
+  const check = (expr: Expr): boolean => {
+    if ("license" in expr) {
+      const full = expr.license.toLowerCase();
+      return allowed.has(full) || allowed.has(full.split(" with ", 1)[0]!);
+    }
+    return expr.op === "or" ? expr.terms.some(check) : expr.terms.every(check);
+  };
+  const parsed = parseExpression(expression);
+  return parsed ? check(parsed) : false;
+}
+
+const licenseOf = (c: InventoryComponent): string | undefined => {
+  const license = c.license?.trim();
+  return license && !NO_LICENSE.has(license.toUpperCase()) ? license : undefined;
+};
+
+/**
+ * Count license usage across artifacts and, given an allow-list, list the
+ * artifacts with components whose license it does not satisfy. Unlicensed
+ * components are counted but never reported as violations.
+ */
+export function buildLicenseInventory(artifacts: readonly InventoryArtifact[], allowList?: readonly string[]): LicenseInventory {
+  const allow = allowList && allowList.length > 0 ? new Set(allowList) : undefined;
+  const usage = new Map<string, { components: number; artifacts: Set<string> }>();
+  const unlicensedArtifacts = new Set<string>();
+  let unlicensed = 0;
+  let components = 0;
+  const violations: LicenseViolation[] = [];
+
+  for (const artifact of artifacts) {
+    const offending = new Map<string, string[]>();
+    for (const component of artifact.components) {
+      components++;
+      const license = licenseOf(component);
+      if (!license) {
+        unlicensed++;
+        unlicensedArtifacts.add(artifact.artifact_id);
+        continue;
+      }
+      const entry = usage.get(license) ?? { components: 0, artifacts: new Set<string>() };
+      entry.components++;
+      entry.artifacts.add(artifact.artifact_id);
+      usage.set(license, entry);
+      if (allow && !licenseAllowed(license, allow)) {
+        offending.set(license, [...(offending.get(license) ?? []), component.version ? `${component.name}@${component.version}` : component.name]);
+      }
+    }
+    if (offending.size > 0) {
+      violations.push({
+        artifact_id: artifact.artifact_id,
+        ...(artifact.name ? { name: artifact.name } : {}),
+        kind: artifact.kind,
+        licenses: [...offending.entries()]
+          .sort((a, b) => b[1].length - a[1].length || a[0].localeCompare(b[0]))
+          .map(([license, names]) => ({ license, components: names.length, examples: names.slice(0, LICENSE_VIOLATION_EXAMPLES) })),
+      });
+    }
+  }
+
+  return {
+    artifacts_scanned: artifacts.length,
+    components,
+    licenses: [...usage.entries()]
+      .sort((a, b) => b[1].artifacts.size - a[1].artifacts.size || b[1].components - a[1].components || a[0].localeCompare(b[0]))
+      .map(([license, u]) => ({
+        license,
+        components: u.components,
+        artifacts: u.artifacts.size,
+        ...(allow ? { allowed: licenseAllowed(license, allow) } : {}),
+      })),
+    unlicensed: { components: unlicensed, artifacts: unlicensedArtifacts.size },
+    ...(allow ? { violations } : {}),
+  };
+}
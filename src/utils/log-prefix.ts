@@ -1,5 +1,4 @@
-import type { HarnessClient } from "../client/harness-client.js";
-import type { Registry } from "../registry/index.js";
+import type { HarnessClientInterface, RegistryDispatchInterface } from "../registry/types.js";
 import { asRecord, asString, asNumber } from "./type-guards.js";
 
 interface ExecGraphNode {
@@ -93,8 +92,8 @@ function findNodeLogBaseKey(
  * Standard:   accountId:{accountId}/orgId:{orgId}/projectId:{projectId}/pipelineId:{pipelineId}/runSequence:{seq}/level0:pipeline
  */
 export async function buildLogPrefixFromExecution(
-  client: HarnessClient,
-  registry: Registry,
+  client: HarnessClientInterface,
+  registry: RegistryDispatchInterface,
   executionId: string,
   input: Record<string, unknown>,
 ): Promise<string> {
@@ -102,6 +101,7 @@ export async function buildLogPrefixFromExecution(
     ...input,
     execution_id: executionId,
     render_full_graph: true,
+    detail_level: "full",
   }) as Record<string, unknown>;
 
   const exec = asRecord(execution) ?? {};
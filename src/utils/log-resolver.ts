@@ -108,7 +108,7 @@ function signedHeadersIncludeHost(url: URL): boolean {
 
 const ANSI_RE = /\x1b\[[0-9;]*[a-zA-Z]/g;
 
-function stripAnsi(text: string): string {
+export function stripAnsi(text: string): string {
   return text.replace(ANSI_RE, "");
 }
 
@@ -0,0 +1,145 @@
+/**
+ * Incremental log reads for running executions.
+ *
+ * The log-service keeps an open stream per step while it runs
+ * (GET /gateway/log-service/stream?key=...). A subscriber first receives every
+ * line written so far, then new lines as server-sent events, and finally
+ * `eof` when the step finishes. Once closed, the same lines are served as a
+ * JSONL blob (GET /gateway/log-service/blob?key=...).
+ *
+ * Both replay from the first line, so a tail is resumed by skipping the lines
+ * already returned. The continuation cursor carries the log key and that
+ * offset, which also saves re-resolving the key from the execution graph.
+ */
+import { stripAnsi } from "./log-resolver.js";
+
+/** Longest single log line returned, in characters. */
+const MAX_LINE_CHARS = 2000;
+
+export interface LogLine {
+  time?: string;
+  level?: string;
+  out: string;
+}
+
+export interface LogCursor {
+  key: string;
+  offset: number;
+}
+
+export function encodeLogCursor(cursor: LogCursor): string {
+  return Buffer.from(JSON.stringify({ k: cursor.key, o: cursor.offset })).toString("base64url");
+}
+
+/** Decode a cursor from encodeLogCursor; undefined when malformed. */
+export function decodeLogCursor(token: string): LogCursor | undefined {
+  try {
+    const parsed = JSON.parse(Buffer.from(token, "base64url").toString("utf8")) as { k?: unknown; o?: unknown };
+    if (typeof parsed.k !== "string" || !parsed.k || typeof parsed.o !== "number" || parsed.o < 0) return undefined;
+    return { key: parsed.k, offset: Math.trunc(parsed.o) };
+  } catch {
+    return undefined;
+  }
+}
+
+/** One log-service entry (`{"level","time","out"}`) as a clean line. */
+export function parseLogEntry(raw: string): LogLine | undefined {
+  const trimmed = raw.trim();
+  if (!trimmed) return undefined;
+  let out = trimmed;
+  let time: string | undefined;
+  let level: string | undefined;
+  if (trimmed.startsWith("{")) {
+    try {
+      const entry = JSON.parse(trimmed) as Record<string, unknown>;
+      out = String(entry.out ?? entry.message ?? entry.msg ?? "");
+      if (typeof entry.time === "string") time = entry.time;
+      if (typeof entry.level === "string") level = entry.level.toLowerCase();
+    } catch {
+      // not JSON — keep the raw text
+    }
+  }
+  out = stripAnsi(out).replace(/\r?\n$/, "");
+  if (out.length > MAX_LINE_CHARS) out = `${out.slice(0, MAX_LINE_CHARS)}…`;
+  return { ...(time ? { time } : {}), ...(level ? { level } : {}), out };
+}
+
+export interface LogReadOptions {
+  /** Lines already returned by earlier reads. */
+  skip: number;
+  maxLines: number;
+  /** How long to wait for new lines on a live stream before returning. */
+  waitMs: number;
+}
+
+export interface LogReadResult {
+  lines: LogLine[];
+  /** True when the stream sent `eof` or closed: the step has finished writing. */
+  ended: boolean;
+  /** Offset to resume from. */
+  offset: number;
+}
+
+/**
+ * Read server-sent log events until `maxLines` new lines arrive, `waitMs`
+ * passes, or the stream ends. The stream is cancelled on return.
+ */
+export async function readLogStream(body: ReadableStream<Uint8Array>, options: LogReadOptions): Promise<LogReadResult> {
+  const reader = body.getReader();
+  const decoder = new TextDecoder();
+  const lines: LogLine[] = [];
+  const deadline = Date.now() + options.waitMs;
+  let buffer = "";
+  let seen = 0;
+  let ended = false;
+
+  try {
+    read: while (lines.length < options.maxLines) {
+      const remaining = deadline - Date.now();
+      if (remaining <= 0) break;
+      let timer: ReturnType<typeof setTimeout> | undefined;
+      const timeout = new Promise<"timeout">((resolve) => {
+        timer = setTimeout(() => resolve("timeout"), remaining);
+      });
+      let chunk: Awaited<ReturnType<typeof reader.read>> | "timeout";
+      try {
+        chunk = await Promise.race([reader.read(), timeout]);
+      } finally {
+        clearTimeout(timer);
+      }
+      if (chunk === "timeout") break;
+      if (chunk.done) {
+        ended = true;
+        break;
+      }
+      buffer += decoder.decode(chunk.value, { stream: true });
+      let newline: number;
+      while ((newline = buffer.indexOf("\n")) >= 0) {
+        const line = buffer.slice(0, newline).replace(/\r$/, "");
+        buffer = buffer.slice(newline + 1);
+        if (!line.startsWith("data:")) continue;
+        const data = line.slice(5).trim();
+        if (data === "eof") {
+          ended = true;
+          break read;
+        }
+        const entry = parseLogEntry(data);
+        if (!entry) continue;
+        if (seen++ < options.skip) continue;
+        lines.push(entry);
+        if (lines.length >= options.maxLines) break read;
+      }
+    }
+  } finally {
+    reader.cancel().catch(() => {});
+  }
+  return { lines, ended, offset: options.skip + lines.length };
+}
+
+/** Page through a closed log's JSONL blob from `skip`. */
+export function readLogBlob(text: string, options: Pick<LogReadOptions, "skip" | "maxLines">): LogReadResult & { total: number } {
+  const entries = text.split("\n").map(parseLogEntry).filter((e): e is LogLine => e !== undefined);
+  const lines = entries.slice(options.skip, options.skip + options.maxLines);
+  const offset = Math.min(entries.length, options.skip + lines.length);
+  return { lines, ended: offset >= entries.length, offset, total: entries.length };
+}
@@ -0,0 +1,52 @@
+/**
+ * Size capping for log text returned inline in tool results.
+ */
+
+/** Share of a truncated log kept from the start; the rest comes from the end, where failures usually are. */
+const TRUNCATE_HEAD_SHARE = 0.2;
+
+export interface TruncatedLog {
+  text: string;
+  truncated: boolean;
+  total_chars: number;
+  total_lines: number;
+  omitted_lines?: number;
+}
+
+/**
+ * Cap log text at `maxChars`, keeping whole lines from the start and the end
+ * and marking the cut with a `... [N lines truncated] ...` line.
+ */
+export function truncateLogText(text: string, maxChars: number): TruncatedLog {
+  const lines = text.split("\n");
+  if (text.length <= maxChars) {
+    return { text, truncated: false, total_chars: text.length, total_lines: lines.length };
+  }
+  const headBudget = Math.floor(maxChars * TRUNCATE_HEAD_SHARE);
+  let head = 0;
+  let used = 0;
+  while (head < lines.length && used + lines[head]!.length + 1 <= headBudget) used += lines[head++]!.length + 1;
+  let tail = 0;
+  while (tail < lines.length - head && used + lines[lines.length - 1 - tail]!.length + 1 <= maxChars) {
+    used += lines[lines.length - 1 - tail]!.length + 1;
+    tail++;
+  }
+  if (head === 0 && tail === 0) {
+    // One line longer than the cap: keep its end.
+    return {
+      text: `... [${text.length - maxChars} chars truncated] ...\n${text.slice(text.length - maxChars)}`,
+      truncated: true,
+      total_chars: text.length,
+      total_lines: lines.length,
+    };
+  }
+  const omitted = lines.length - head - tail;
+  const marker = `... [${omitted} lines truncated; showing the first ${head} and last ${tail} of ${lines.length} lines] ...`;
+  return {
+    text: [...lines.slice(0, head), marker, ...lines.slice(lines.length - tail)].join("\n"),
+    truncated: true,
+    total_chars: text.length,
+    total_lines: lines.length,
+    omitted_lines: omitted,
+  };
+}
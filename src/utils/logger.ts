@@ -2,6 +2,7 @@
  * stderr-only structured logger.
  * CRITICAL: Never write to stdout — it's reserved for JSON-RPC in stdio transport.
  */
+import { getConversationId } from "./conversation-context.js";
 
 type LogLevel = "debug" | "info" | "warn" | "error";
 
@@ -18,6 +19,20 @@ export function setLogLevel(level: LogLevel): void {
   globalLevel = level;
 }
 
+/** Emitted entries kept in memory for support bundles. */
+const RECENT_LOG_LIMIT = 500;
+const recentEntries: Array<Record<string, unknown>> = [];
+
+/** The last RECENT_LOG_LIMIT entries written, oldest first. Not redacted. */
+export function getRecentLogEntries(): Array<Record<string, unknown>> {
+  return [...recentEntries];
+}
+
+/** Drop the in-memory log history (tests). */
+export function clearRecentLogEntries(): void {
+  recentEntries.length = 0;
+}
+
 export interface Logger {
   debug: (msg: string, data?: Record<string, unknown>) => void;
   info: (msg: string, data?: Record<string, unknown>) => void;
@@ -77,14 +92,18 @@ export function createLogger(module: string): Logger {
   function log(level: LogLevel, message: string, data?: Record<string, unknown>): void {
     if (LOG_LEVELS[level] < LOG_LEVELS[globalLevel]) return;
 
+    const conversationId = getConversationId();
     const entry = {
       ts: new Date().toISOString(),
       level,
       module,
       msg: message,
+      ...(conversationId ? { conversation_id: conversationId } : {}),
       ...data,
     };
 
+    recentEntries.push(entry);
+    if (recentEntries.length > RECENT_LOG_LIMIT) recentEntries.shift();
     console.error(JSON.stringify(entry));
   }
 
@@ -0,0 +1,138 @@
+/**
+ * Joining SEI time series on their period and correlating them: AI assistant
+ * adoption against delivery metrics such as PR cycle time, so "is adoption
+ * improving throughput?" gets numbers instead of two separate charts.
+ *
+ * Correlation over a handful of weeks is weak evidence and says nothing about
+ * cause; results carry the sample size so callers can say so.
+ */
+import { isRecord } from "./type-guards.js";
+
+/** Fewest shared periods for which a correlation is reported. */
+export const MIN_CORRELATION_PERIODS = 4;
+
+const PERIOD_KEYS = ["date", "startDate", "start_date", "period", "week", "month", "timestamp", "key"] as const;
+const MAX_DEPTH = 4;
+
+/** Epoch seconds or milliseconds as an ISO day. */
+const day = (epoch: number) => new Date(epoch < 1e11 ? epoch * 1000 : epoch).toISOString().slice(0, 10);
+
+/** Period of a row as YYYY-MM-DD when it is a date or timestamp, else as given. */
+function periodOf(row: Record<string, unknown>): string | undefined {
+  for (const key of PERIOD_KEYS) {
+    const v = row[key];
+    if (typeof v === "number" && Number.isFinite(v)) return day(v);
+    if (typeof v === "string" && v.trim()) {
+      if (/^\d+$/.test(v)) return day(Number(v));
+      const ms = Date.parse(v);
+      return Number.isFinite(ms) ? new Date(ms).toISOString().slice(0, 10) : v.trim();
+    }
+  }
+  return undefined;
+}
+
+function isSeriesRows(value: unknown): value is Array<Record<string, unknown>> {
+  return Array.isArray(value) && value.length > 0 && value.every(isRecord) && value.some((row) => periodOf(row) !== undefined);
+}
+
+/**
+ * Period → value for the first array of dated rows in an SEI response. The
+ * value is the first of `valueKeys` present on the row, or the row's first
+ * numeric field that is not a period.
+ */
+export function timeSeries(raw: unknown, valueKeys: readonly string[] = []): Map<string, number> {
+  let level: unknown[] = [raw];
+  let rows: Array<Record<string, unknown>> = [];
+  for (let depth = 0; depth <= MAX_DEPTH && level.length > 0 && rows.length === 0; depth++) {
+    const next: unknown[] = [];
+    for (const node of level) {
+      if (isSeriesRows(node)) {
+        rows = node;
+        break;
+      }
+      if (Array.isArray(node)) next.push(...node);
+      else if (isRecord(node)) next.push(...Object.values(node));
+    }
+    level = next;
+  }
+
+  const series = new Map<string, number>();
+  for (const row of rows) {
+    const period = periodOf(row);
+    if (period === undefined) continue;
+    const key = valueKeys.find((k) => typeof row[k] === "number")
+      ?? Object.keys(row).find((k) => typeof row[k] === "number" && !(PERIOD_KEYS as readonly string[]).includes(k));
+    const value = key ? (row[key] as number) : undefined;
+    if (value !== undefined && Number.isFinite(value)) series.set(period, value);
+  }
+  return series;
+}
+
+/** One row per period seen in any series, oldest first; a series missing a period leaves its column out. */
+export function joinSeries(series: Record<string, ReadonlyMap<string, number>>): Array<Record<string, number | string>> {
+  const periods = [...new Set(Object.values(series).flatMap((s) => [...s.keys()]))].sort();
+  return periods.map((period) => {
+    const row: Record<string, number | string> = { period };
+    for (const [name, s] of Object.entries(series)) {
+      const v = s.get(period);
+      if (v !== undefined) row[name] = v;
+    }
+    return row;
+  });
+}
+
+/** Pearson correlation coefficient, or undefined when either side is constant. */
+export function pearson(xs: readonly number[], ys: readonly number[]): number | undefined {
+  const n = Math.min(xs.length, ys.length);
+  if (n < 2) return undefined;
+  const mx = xs.slice(0, n).reduce((a, b) => a + b, 0) / n;
+  const my = ys.slice(0, n).reduce((a, b) => a + b, 0) / n;
+  let cov = 0;
+  let vx = 0;
+  let vy = 0;
+  for (let i = 0; i < n; i++) {
+    const dx = xs[i]! - mx;
+    const dy = ys[i]! - my;
+    cov += dx * dy;
+    vx += dx * dx;
+    vy += dy * dy;
+  }
+  return vx === 0 || vy === 0 ? undefined : cov / Math.sqrt(vx * vy);
+}
+
+export interface Correlation {
+  metric: string;
+  /** Periods where both series have a value. */
+  periods: number;
+  r?: number;
+  strength?: "strong" | "moderate" | "weak" | "none";
+  /** Whether rising adoption goes with the metric getting better, given which direction is better. */
+  association?: "improves" | "worsens" | "unrelated";
+}
+
+/**
+ * Correlate column `x` of joined rows with each metric. `lowerIsBetter`
+ * metrics (durations) improve when they fall as `x` rises.
+ */
+export function correlate(
+  rows: ReadonlyArray<Record<string, number | string>>,
+  x: string,
+  metrics: ReadonlyArray<{ name: string; lowerIsBetter?: boolean }>,
+): Correlation[] {
+  return metrics.map(({ name, lowerIsBetter }) => {
+    const pairs = rows.filter((r) => typeof r[x] === "number" && typeof r[name] === "number");
+    if (pairs.length < MIN_CORRELATION_PERIODS) return { metric: name, periods: pairs.length };
+    const r = pearson(pairs.map((p) => p[x] as number), pairs.map((p) => p[name] as number));
+    if (r === undefined) return { metric: name, periods: pairs.length };
+    const abs = Math.abs(r);
+    const strength = abs >= 0.7 ? "strong" : abs >= 0.4 ? "moderate" : abs >= 0.2 ? "weak" : "none";
+    const better = lowerIsBetter ? r < 0 : r > 0;
+    return {
+      metric: name,
+      periods: pairs.length,
+      r: Math.round(r * 100) / 100,
+      strength,
+      association: strength === "none" ? "unrelated" : better ? "improves" : "worsens",
+    };
+  });
+}
@@ -0,0 +1,242 @@
+/**
+ * Cross-platform handling for files the server writes to disk (SIEM export
+ * pages, support bundles).
+ *
+ * stdio users on Windows pass paths like `C:\Users\me\exports`,
+ * `"%USERPROFILE%\exports"` (quotes included, pasted from Explorer) or
+ * `~/exports`, and their antivirus and indexers briefly lock new files.
+ * resolveOutputDir normalizes those inputs with the rules of the host
+ * platform, safeFileName keeps generated names valid on every file system,
+ * and writeOutputFile writes through a temp file and retries the final rename
+ * while the target is locked. Temp files go in HARNESS_TEMP_DIR when it is
+ * set (e.g. to keep partial exports off a synced folder), else next to the
+ * target; a temp dir on another volume falls back to a copy beside the target
+ * so the final step is still a same-volume rename.
+ *
+ * Directories named by tool callers go through resolveToolOutputDir, which
+ * confines them to HARNESS_OUTPUT_ROOT. Over HTTP the caller is a remote
+ * client, so tool file output is off unless a root is configured.
+ */
+import { randomBytes } from "node:crypto";
+import { copyFileSync, existsSync, mkdirSync, realpathSync, renameSync, rmSync, writeFileSync } from "node:fs";
+import { homedir } from "node:os";
+import { basename, dirname, join, posix, win32, type PlatformPath } from "node:path";
+
+export interface OutputPathOptions {
+  /** Resolve a relative path against the working directory instead of rejecting it. */
+  allowRelative?: boolean;
+  /** Resolve a relative path against this directory instead of rejecting it. */
+  base?: string;
+  /** Path rules to apply. Defaults to the host platform; tests pass "win32". */
+  platform?: NodeJS.Platform;
+  /** Environment for `~` and `%VAR%` expansion. Defaults to process.env. */
+  env?: NodeJS.ProcessEnv;
+}
+
+let outputRoot: string | undefined;
+let remoteCallers = false;
+let tempDir: string | undefined;
+
+/**
+ * Set where tools may write files (HARNESS_OUTPUT_ROOT), whether tool
+ * callers are remote (HTTP transport), and where temp files are written
+ * (HARNESS_TEMP_DIR). Remote callers without a root cannot write files at all.
+ */
+export function configureOutputRoot(options: { root?: string; remote?: boolean; tempDir?: string }): void {
+  outputRoot = options.root ? resolveOutputDir(options.root) : undefined;
+  remoteCallers = options.remote === true;
+  tempDir = options.tempDir ? resolveOutputDir(options.tempDir, { allowRelative: true }) : undefined;
+}
+
+/** Reset to no root, local callers, and temp files next to their target (tests). */
+export function resetOutputRoot(): void {
+  outputRoot = undefined;
+  remoteCallers = false;
+  tempDir = undefined;
+}
+
+/** Lock errors Windows reports while another process holds the target open. */
+const RETRYABLE_RENAME_CODES = new Set(["EBUSY", "EPERM", "EACCES"]);
+const RENAME_ATTEMPTS = 5;
+const RENAME_DELAY_MS = 100;
+
+/** Characters Windows rejects in file names, plus control characters. */
+const UNSAFE_FILE_CHARS = /[<>:"/\\|?*\u0000-\u001f]/g;
+const RESERVED_WINDOWS_NAMES = /^(con|prn|aux|nul|com[1-9]|lpt[1-9])(\..*)?$/i;
+
+function pathFor(platform: NodeJS.Platform): PlatformPath {
+  return platform === "win32" ? win32 : posix;
+}
+
+function homeDir(env: NodeJS.ProcessEnv, platform: NodeJS.Platform): string {
+  const fromEnv = platform === "win32" ? env.USERPROFILE ?? env.HOME : env.HOME;
+  return fromEnv || homedir();
+}
+
+/**
+ * Normalize a user-supplied output directory for `platform`: strips
+ * surrounding quotes, expands a leading `~` and (on Windows) `%VAR%`, and
+ * converts separators. A relative path is resolved against `base` when set
+ * and otherwise throws unless allowRelative is set, since the server's
+ * working directory is rarely what the caller expects.
+ */
+export function resolveOutputDir(dir: string, options: OutputPathOptions = {}): string {
+  const platform = options.platform ?? process.platform;
+  const env = options.env ?? process.env;
+  const p = pathFor(platform);
+
+  let value = dir.trim().replace(/^(["'])(.*)\1$/, "$2").trim();
+  if (!value) throw new Error("output_dir must not be empty");
+  if (value === "~" || /^~[\\/]/.test(value)) value = homeDir(env, platform) + value.slice(1);
+  if (platform === "win32") {
+    value = value.replace(/%([^%]+)%/g, (match, name: string) => {
+      const key = Object.keys(env).find((k) => k.toLowerCase() === name.toLowerCase());
+      return key ? env[key]! : match;
+    });
+  }
+
+  if (!p.isAbsolute(value)) {
+    if (options.base) return p.resolve(options.base, value);
+    if (!options.allowRelative) {
+      const example = platform === "win32" ? "C:\\Users\\me\\exports" : "/home/me/exports";
+      throw new Error(`output_dir must be an absolute path (e.g. ${example}), got "${dir}"`);
+    }
+    return p.resolve(value);
+  }
+  return p.normalize(value);
+}
+
+/** `path` with symlinks in its longest existing prefix resolved, so a link inside the root cannot lead out of it. */
+function realPath(path: string, p: PlatformPath): string {
+  let existing = path;
+  const rest: string[] = [];
+  while (!existsSync(existing)) {
+    const parent = p.dirname(existing);
+    if (parent === existing) return path;
+    rest.unshift(p.basename(existing));
+    existing = parent;
+  }
+  return p.join(realpathSync(existing), ...rest);
+}
+
+/**
+ * Resolve an output_dir passed by a tool caller. With HARNESS_OUTPUT_ROOT set,
+ * a relative path is taken from the root and any path that resolves outside
+ * it is rejected. Without a root, remote (HTTP) callers are refused.
+ */
+export function resolveToolOutputDir(dir: string, options: OutputPathOptions = {}): string {
+  const root = outputRoot;
+  if (!root) {
+    if (remoteCallers) {
+      throw new Error("output_dir is disabled over HTTP. Set HARNESS_OUTPUT_ROOT on the server to the directory tools may write to.");
+    }
+    return resolveOutputDir(dir, options);
+  }
+  const p = pathFor(options.platform ?? process.platform);
+  const target = resolveOutputDir(dir, { ...options, base: root });
+  const rel = p.relative(realPath(root, p), realPath(target, p));
+  if (rel === ".." || rel.startsWith(`..${p.sep}`) || p.isAbsolute(rel)) {
+    throw new Error(`output_dir "${dir}" is outside HARNESS_OUTPUT_ROOT (${root})`);
+  }
+  return target;
+}
+
+/** `name` with characters and names that are invalid on Windows replaced, so files copy between systems. */
+export function safeFileName(name: string): string {
+  const cleaned = name.replace(UNSAFE_FILE_CHARS, "-").replace(/[. ]+$/, "");
+  if (!cleaned) return "_";
+  return RESERVED_WINDOWS_NAMES.test(cleaned) ? `_${cleaned}` : cleaned;
+}
+
+function sleepSync(ms: number): void {
+  Atomics.wait(new Int32Array(new SharedArrayBuffer(4)), 0, 0, ms);
+}
+
+/**
+ * Rename `from` to `to`, retrying while the target is locked. On Windows a
+ * virus scanner or search indexer can hold a just-written file for a moment,
+ * which fails the rename with EBUSY or EPERM.
+ */
+export function renameWithRetry(
+  from: string,
+  to: string,
+  rename: (from: string, to: string) => void = renameSync,
+  attempts = RENAME_ATTEMPTS,
+  delayMs = RENAME_DELAY_MS,
+): void {
+  for (let attempt = 1; ; attempt++) {
+    try {
+      rename(from, to);
+      return;
+    } catch (err) {
+      const code = (err as NodeJS.ErrnoException).code;
+      if (attempt >= attempts || !code || !RETRYABLE_RENAME_CODES.has(code)) throw err;
+      sleepSync(delayMs * attempt);
+    }
+  }
+}
+
+/**
+ * Temp path to write `file` through: in HARNESS_TEMP_DIR when configured
+ * (created if missing), else beside `file`.
+ */
+export function outputTempPath(file: string): string {
+  if (!tempDir) return `${file}.${process.pid}.tmp`;
+  try {
+    mkdirSync(tempDir, { recursive: true });
+  } catch (err) {
+    throw new Error(`Cannot create HARNESS_TEMP_DIR "${tempDir}": ${(err as Error).message}`);
+  }
+  return join(tempDir, `${basename(file)}.${process.pid}.${randomBytes(4).toString("hex")}.tmp`);
+}
+
+/**
+ * Move a finished temp file onto `file`. When the temp file is on another
+ * volume (EXDEV), it is first copied beside `file` so the replacement is
+ * still a rename and readers never see a partial file.
+ */
+export function moveIntoPlace(
+  temp: string,
+  file: string,
+  rename: (from: string, to: string) => void = renameSync,
+): void {
+  try {
+    renameWithRetry(temp, file, rename);
+    return;
+  } catch (err) {
+    if ((err as NodeJS.ErrnoException).code !== "EXDEV") throw err;
+  }
+  const sibling = `${file}.${process.pid}.tmp`;
+  try {
+    copyFileSync(temp, sibling);
+    renameWithRetry(sibling, file, rename);
+  } catch (err) {
+    rmSync(sibling, { force: true });
+    throw err;
+  } finally {
+    rmSync(temp, { force: true });
+  }
+}
+
+/**
+ * Write `data` to `file`, creating its directory. The data goes to a temp
+ * file first (see outputTempPath) and is moved into place, so a reader
+ * never sees a partial file and a locked target is retried rather than
+ * corrupted.
+ */
+export function writeOutputFile(file: string, data: string | Uint8Array, options: { mode?: number } = {}): void {
+  const dir = dirname(file);
+  try {
+    mkdirSync(dir, { recursive: true });
+  } catch (err) {
+    throw new Error(`Cannot create output directory "${dir}": ${(err as Error).message}`);
+  }
+  const temp = outputTempPath(file);
+  try {
+    writeFileSync(temp, data, options.mode !== undefined ? { mode: options.mode } : {});
+    moveIntoPlace(temp, file);
+  } catch (err) {
+    rmSync(temp, { force: true });
+    throw new Error(`Cannot write "${file}": ${(err as Error).message}`);
+  }
+}
@@ -0,0 +1,294 @@
+/**
+ * Playbooks: recurring investigations written down as a named sequence of
+ * tool calls, run with `harness-mcp-server run-playbook <file>`.
+ *
+ * A playbook is YAML:
+ *
+ *   name: weekly-security-review
+ *   vars:
+ *     project_id: payments
+ *   steps:
+ *     - name: critical
+ *       tool: harness_list
+ *       args:
+ *         resource_type: security_issue
+ *         project_id: "{{ vars.project_id }}"
+ *         filters: { severity: Critical }
+ *       assert:
+ *         - path: items.length
+ *           lte: 0
+ *           message: No open critical issues
+ *
+ * Args are templated with `{{ path }}` against `vars` and the results of
+ * earlier steps (`steps.<name>.<path>`). A string that is exactly one
+ * template keeps the value's type; otherwise values are interpolated as text.
+ * A failed call or assertion stops the run unless the step sets
+ * `continue_on_failure: true`; later steps are reported as skipped.
+ *
+ * Steps run through the same tool handlers an MCP client would call, so
+ * scoping, read-only mode, and write confirmations apply unchanged.
+ */
+import * as z from "zod/v4";
+import YAML from "yaml";
+import { isRecord } from "./type-guards.js";
+
+const AssertionSchema = z.object({
+  /** Dotted path into the step result; `length` reads an array's or string's length. */
+  path: z.string().min(1),
+  exists: z.boolean().optional(),
+  equals: z.unknown().optional(),
+  not_equals: z.unknown().optional(),
+  gt: z.number().optional(),
+  gte: z.number().optional(),
+  lt: z.number().optional(),
+  lte: z.number().optional(),
+  /** Substring of a string, or an element of an array. */
+  contains: z.unknown().optional(),
+  message: z.string().optional(),
+}).strict();
+
+const StepSchema = z.object({
+  name: z.string().regex(/^[A-Za-z_][\w-]*$/, "step names are letters, digits, _ and -, so later steps can reference them"),
+  tool: z.string().regex(/^harness_\w+$/, "tool must be a harness_* tool name"),
+  args: z.record(z.string(), z.unknown()).default({}),
+  assert: z.array(AssertionSchema).default([]),
+  continue_on_failure: z.boolean().default(false),
+}).strict();
+
+const PlaybookSchema = z.object({
+  name: z.string().min(1),
+  description: z.string().optional(),
+  vars: z.record(z.string(), z.unknown()).default({}),
+  steps: z.array(StepSchema).min(1),
+}).strict();
+
+export type Playbook = z.infer<typeof PlaybookSchema>;
+export type PlaybookAssertion = z.infer<typeof AssertionSchema>;
+
+/** Parse and validate playbook YAML. Throws with every problem listed. */
+export function parsePlaybook(text: string): Playbook {
+  let raw: unknown;
+  try {
+    raw = YAML.parse(text);
+  } catch (err) {
+    throw new Error(`Invalid playbook YAML: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  const parsed = PlaybookSchema.safeParse(raw);
+  if (!parsed.success) {
+    const issues = parsed.error.issues.map((i) => `  ${i.path.join(".")}: ${i.message}`).join("\n");
+    throw new Error(`Invalid playbook:\n${issues}`);
+  }
+  const seen = new Set<string>();
+  for (const step of parsed.data.steps) {
+    if (seen.has(step.name)) throw new Error(`Invalid playbook: duplicate step name "${step.name}"`);
+    seen.add(step.name);
+  }
+  return parsed.data;
+}
+
+/** Read a dotted path ("steps.list.items.0.id") from a value. `length` works on arrays and strings. */
+export function readPath(value: unknown, path: string): unknown {
+  let current = value;
+  for (const part of path.split(".")) {
+    if (current === undefined || current === null) return undefined;
+    if (part === "length" && (Array.isArray(current) || typeof current === "string")) {
+      current = current.length;
+    } else if (Array.isArray(current) && /^\d+$/.test(part)) {
+      current = current[Number(part)];
+    } else if (isRecord(current)) {
+      current = current[part];
+    } else {
+      return undefined;
+    }
+  }
+  return current;
+}
+
+const TEMPLATE = /\{\{\s*([^}]+?)\s*\}\}/g;
+const WHOLE_TEMPLATE = /^\{\{\s*([^}]+?)\s*\}\}$/;
+
+/** Substitute `{{ path }}` templates throughout `value`. Unknown paths are an error, not an empty string. */
+export function renderTemplate(value: unknown, context: Record<string, unknown>): unknown {
+  if (typeof value === "string") {
+    const lookup = (path: string): unknown => {
+      const resolved = readPath(context, path);
+      if (resolved === undefined) throw new Error(`Template {{ ${path} }} did not resolve`);
+      return resolved;
+    };
+    const whole = WHOLE_TEMPLATE.exec(value);
+    if (whole) return lookup(whole[1]!);
+    return value.replace(TEMPLATE, (_, path: string) => {
+      const resolved = lookup(path);
+      return typeof resolved === "string" ? resolved : JSON.stringify(resolved);
+    });
+  }
+  if (Array.isArray(value)) return value.map((item) => renderTemplate(item, context));
+  if (isRecord(value)) {
+    return Object.fromEntries(Object.entries(value).map(([key, item]) => [key, renderTemplate(item, context)]));
+  }
+  return value;
+}
+
+function sameValue(a: unknown, b: unknown): boolean {
+  return JSON.stringify(a) === JSON.stringify(b);
+}
+
+function describeAssertion(assertion: PlaybookAssertion): string {
+  const checks = (["exists", "equals", "not_equals", "gt", "gte", "lt", "lte", "contains"] as const)
+    .filter((key) => assertion[key] !== undefined)
+    .map((key) => `${key} ${JSON.stringify(assertion[key])}`);
+  return `${assertion.path} ${checks.join(", ") || "exists true"}`;
+}
+
+/** Evaluate one assertion against a step result. With no check given, the path must exist. */
+export function checkAssertion(result: unknown, assertion: PlaybookAssertion): boolean {
+  const actual = readPath(result, assertion.path);
+  const exists = actual !== undefined && actual !== null;
+  const noCheck = (["exists", "equals", "not_equals", "gt", "gte", "lt", "lte", "contains"] as const)
+    .every((key) => assertion[key] === undefined);
+  if (noCheck) return exists;
+  if (assertion.exists !== undefined && exists !== assertion.exists) return false;
+  if (assertion.equals !== undefined && !sameValue(actual, assertion.equals)) return false;
+  if (assertion.not_equals !== undefined && sameValue(actual, assertion.not_equals)) return false;
+  for (const [key, test] of [
+    ["gt", (a: number, b: number) => a > b],
+    ["gte", (a: number, b: number) => a >= b],
+    ["lt", (a: number, b: number) => a < b],
+    ["lte", (a: number, b: number) => a <= b],
+  ] as const) {
+    const bound = assertion[key];
+    if (bound === undefined) continue;
+    if (typeof actual !== "number" || !test(actual, bound)) return false;
+  }
+  if (assertion.contains !== undefined) {
+    if (typeof actual === "string") return typeof assertion.contains === "string" && actual.includes(assertion.contains);
+    if (Array.isArray(actual)) return actual.some((item) => sameValue(item, assertion.contains));
+    return false;
+  }
+  return true;
+}
+
+export type StepStatus = "passed" | "failed" | "error" | "skipped";
+
+export interface StepReport {
+  name: string;
+  tool: string;
+  status: StepStatus;
+  duration_ms: number;
+  assertions: Array<{ check: string; passed: boolean; actual: unknown; message?: string }>;
+  error?: string;
+}
+
+export interface PlaybookReport {
+  playbook: string;
+  passed: boolean;
+  started_at: string;
+  duration_ms: number;
+  steps: StepReport[];
+}
+
+/** Calls a tool by name and returns its parsed result. Throws when the tool reports an error. */
+export type PlaybookToolCaller = (tool: string, args: Record<string, unknown>) => Promise<unknown>;
+
+/**
+ * Run a playbook. `vars` override the playbook's own vars (e.g. from
+ * `--var key=value`). Every step appears in the report, in order.
+ */
+export async function runPlaybook(
+  playbook: Playbook,
+  callTool: PlaybookToolCaller,
+  vars: Record<string, unknown> = {},
+): Promise<PlaybookReport> {
+  const started = Date.now();
+  const context: { vars: Record<string, unknown>; steps: Record<string, unknown> } = {
+    vars: { ...playbook.vars, ...vars },
+    steps: {},
+  };
+  const steps: StepReport[] = [];
+  let stopped = false;
+
+  for (const step of playbook.steps) {
+    if (stopped) {
+      steps.push({ name: step.name, tool: step.tool, status: "skipped", duration_ms: 0, assertions: [] });
+      continue;
+    }
+    const stepStarted = Date.now();
+    const report: StepReport = { name: step.name, tool: step.tool, status: "passed", duration_ms: 0, assertions: [] };
+    try {
+      const args = renderTemplate(step.args, context) as Record<string, unknown>;
+      const result = await callTool(step.tool, args);
+      context.steps[step.name] = result;
+      for (const assertion of step.assert) {
+        const passed = checkAssertion(result, assertion);
+        report.assertions.push({
+          check: describeAssertion(assertion),
+          passed,
+          actual: readPath(result, assertion.path) ?? null,
+          ...(assertion.message ? { message: assertion.message } : {}),
+        });
+        if (!passed) report.status = "failed";
+      }
+    } catch (err) {
+      report.status = "error";
+      report.error = err instanceof Error ? err.message : String(err);
+    }
+    report.duration_ms = Date.now() - stepStarted;
+    steps.push(report);
+    if (report.status !== "passed" && !step.continue_on_failure) stopped = true;
+  }
+
+  return {
+    playbook: playbook.name,
+    passed: steps.every((s) => s.status === "passed"),
+    started_at: new Date(started).toISOString(),
+    duration_ms: Date.now() - started,
+    steps,
+  };
+}
+
+/** Parse `--var key=value` pairs. Values that parse as JSON (numbers, booleans, arrays) keep their type. */
+export function parsePlaybookVars(pairs: string[]): Record<string, unknown> {
+  const vars: Record<string, unknown> = {};
+  for (const pair of pairs) {
+    const eq = pair.indexOf("=");
+    if (eq <= 0) throw new Error(`--var expects key=value, got "${pair}"`);
+    const raw = pair.slice(eq + 1);
+    let value: unknown = raw;
+    try {
+      value = JSON.parse(raw);
+    } catch {
+      // plain string
+    }
+    vars[pair.slice(0, eq)] = value;
+  }
+  return vars;
+}
+
+/**
+ * Result of an MCP tools/call as data: structuredContent when present,
+ * otherwise the first text item parsed as JSON (or kept as text). Error
+ * results throw with their message.
+ */
+export function toolResultData(raw: unknown): unknown {
+  const result = isRecord(raw) ? raw : {};
+  const texts = (Array.isArray(result.content) ? result.content : [])
+    .filter((item): item is { type: "text"; text: string } => isRecord(item) && item.type === "text" && typeof item.text === "string")
+    .map((item) => item.text);
+  if (result.isError === true) {
+    let message = texts.join("\n") || "tool returned an error";
+    try {
+      const parsed = JSON.parse(message) as unknown;
+      if (isRecord(parsed) && typeof parsed.error === "string") message = parsed.error;
+    } catch {
+      // not JSON
+    }
+    throw new Error(message);
+  }
+  if (result.structuredContent !== undefined) return result.structuredContent;
+  if (texts.length === 0) return null;
+  try {
+    return JSON.parse(texts[0]!) as unknown;
+  } catch {
+    return texts[0];
+  }
+}
@@ -89,7 +89,7 @@ class AbortError extends Error {
  * Sleep for `ms`, rejecting early if the signal aborts.
  * Cleans up listeners and timers in both branches to avoid leaks across long polls.
  */
-function abortableSleep(ms: number, signal?: AbortSignal): Promise<void> {
+export function abortableSleep(ms: number, signal?: AbortSignal): Promise<void> {
   return new Promise<void>((resolve, reject) => {
     if (signal?.aborted) {
       reject(new AbortError());
@@ -183,6 +183,7 @@ export async function pollExecutionToTerminal(
         org_id: opts.orgId,
         project_id: opts.projectId,
         render_full_graph: false,
+        detail_level: "full",
       }, opts.signal);
       lastSnapshot = snapshotFromExecution(raw);
       consecutiveErrors = 0;
@@ -4,6 +4,20 @@
 
 const MAX_WAIT_MS = 30_000;
 
+function wait(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve, reject) => {
+    const onAbort = () => {
+      clearTimeout(timer);
+      reject(signal!.reason);
+    };
+    const timer = setTimeout(() => {
+      signal?.removeEventListener("abort", onAbort);
+      resolve();
+    }, ms);
+    signal?.addEventListener("abort", onAbort, { once: true });
+  });
+}
+
 export class RateLimiter {
   private tokens: number;
   private lastRefill: number;
@@ -16,10 +30,12 @@ export class RateLimiter {
     this.lastRefill = Date.now();
   }
 
-  async acquire(): Promise<void> {
+  /** Take a token, waiting for one if needed. Rejects with the signal's reason when `signal` aborts first. */
+  async acquire(signal?: AbortSignal): Promise<void> {
     const deadline = Date.now() + MAX_WAIT_MS;
 
     while (Date.now() < deadline) {
+      signal?.throwIfAborted();
       this.refill();
       if (this.tokens >= 1) {
         this.tokens -= 1;
@@ -27,7 +43,7 @@ export class RateLimiter {
       }
       // Wait until a token is available
       const waitMs = Math.ceil((1 - this.tokens) / this.refillRatePerMs);
-      await new Promise((resolve) => setTimeout(resolve, waitMs));
+      await wait(waitMs, signal);
     }
 
     throw new Error(`Rate limiter: timed out waiting ${MAX_WAIT_MS}ms for a token`);
@@ -0,0 +1,24 @@
+/**
+ * Views of the McpServer that change how tools are registered.
+ *
+ * Cross-cutting tool behaviour (cancellation, redaction, context cost, error
+ * logging) is layered by wrapping registerTool. Tool handlers keep the server
+ * they were registered with and use more than registerTool — elicitation
+ * reads the client's capabilities from `server.server` — so each view must
+ * still behave as the real server for everything else.
+ */
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+
+/**
+ * `server` with registerTool replaced. Every other member is read from
+ * `server`, with methods bound to it, so views can be stacked.
+ */
+export function withRegisterTool(server: McpServer, registerTool: (...args: never[]) => unknown): McpServer {
+  return new Proxy(server, {
+    get(target, prop) {
+      if (prop === "registerTool") return registerTool;
+      const value: unknown = Reflect.get(target, prop, target);
+      return typeof value === "function" ? value.bind(target) : value;
+    },
+  });
+}
@@ -0,0 +1,49 @@
+/**
+ * Remediation lookup for error results.
+ *
+ * Matches an error message (and HTTP status when known) against the hint data
+ * in src/data/remediation-hints.ts and returns structured next steps. Used by
+ * errorResult() and toMcpError() so every tool gets the same hints without
+ * per-handler code.
+ */
+import { REMEDIATION_HINTS, type RemediationHint } from "../data/remediation-hints.js";
+
+export interface Remediation {
+  id: string;
+  title: string;
+  steps: string[];
+  see?: string;
+}
+
+function statusMatches(hint: RemediationHint, message: string, status?: number): boolean {
+  const statuses = hint.match.status;
+  if (!statuses || statuses.length === 0) return true;
+  if (status !== undefined) return statuses.includes(status);
+  return statuses.some((s) => new RegExp(`\\b${s}\\b`).test(message));
+}
+
+/** First hint matching `message` (and `status` when given), or undefined. */
+export function findRemediation(
+  message: string,
+  status?: number,
+  hints: readonly RemediationHint[] = REMEDIATION_HINTS,
+): Remediation | undefined {
+  for (const hint of hints) {
+    if (!hint.match.pattern.test(message) || !statusMatches(hint, message, status)) continue;
+    return {
+      id: hint.id,
+      title: hint.title,
+      steps: [...hint.steps],
+      ...(hint.see ? { see: hint.see } : {}),
+    };
+  }
+  return undefined;
+}
+
+/** Plain-text rendering appended to JSON-RPC error messages. */
+export function formatRemediation(remediation: Remediation): string {
+  const lines = [`Remediation (${remediation.title}):`];
+  remediation.steps.forEach((step, i) => lines.push(`${i + 1}. ${step}`));
+  if (remediation.see) lines.push(`See: ${remediation.see}`);
+  return lines.join("\n");
+}
@@ -0,0 +1,44 @@
+/**
+ * Cancellation of the tool call being handled.
+ *
+ * The MCP SDK hands every tool handler an AbortSignal that fires when the
+ * client sends notifications/cancelled or the transport closes. Threading it
+ * through every dispatch, preflight hook, and helper is easy to miss, and a
+ * missed call keeps a log tail or long query running upstream after the
+ * client has gone. withRequestCancellation runs each handler inside an
+ * AsyncLocalStorage context holding that signal, and HarnessClient uses it
+ * for any request made without an explicit signal.
+ */
+import { AsyncLocalStorage } from "node:async_hooks";
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { withRegisterTool } from "./register-tool.js";
+import { isRecord } from "./type-guards.js";
+
+const storage = new AsyncLocalStorage<AbortSignal>();
+
+/** Abort signal of the tool call currently being handled, or undefined outside one. */
+export function getRequestSignal(): AbortSignal | undefined {
+  return storage.getStore();
+}
+
+/** Run `fn` with `signal` as the active tool call's signal. */
+export function runWithRequestSignal<T>(signal: AbortSignal | undefined, fn: () => T): T {
+  return signal ? storage.run(signal, fn) : fn();
+}
+
+type ToolCallback = (args: Record<string, unknown>, extra: unknown) => unknown;
+type RegisterTool = (name: string, config: unknown, callback: ToolCallback) => unknown;
+
+/**
+ * A view of `server` whose registerTool runs every handler with the call's
+ * abort signal active, so upstream requests stop when the client cancels.
+ */
+export function withRequestCancellation(server: McpServer): McpServer {
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) =>
+    register(name, config, (args, extra) => {
+      const signal = isRecord(extra) && extra.signal instanceof AbortSignal ? extra.signal : undefined;
+      return runWithRequestSignal(signal, () => callback(args, extra));
+    });
+  return withRegisterTool(server, registerTool);
+}
@@ -0,0 +1,226 @@
+/**
+ * Response cache for read-only registry calls.
+ *
+ * Agents often repeat the same list/get within a conversation (list pipelines,
+ * list connectors, get the same service twice). With HARNESS_CACHE_TTL_MS set,
+ * the registry answers repeats from this cache instead of calling Harness.
+ *
+ * Each registry (one per MCP session) owns its cache, so entries never cross
+ * HTTP sessions or credentials. A stdio server multiplexing several
+ * conversations shares one registry; keys are scoped to the conversation ID
+ * from the request `_meta`, so one chat is never answered from another's
+ * entries. Requests without a conversation ID share one namespace. Any
+ * successful write through the same registry clears the whole cache, so a
+ * create/update/delete is never followed by a stale read in any conversation.
+ * Callers skip the cache for one call with `cache_bypass: true`.
+ *
+ * Storage is pluggable via `ResponseCacheStore`; the default is an in-memory
+ * LRU. Hit/miss counters are process-wide and in-memory, like the deprecated
+ * name tracker, and reset when the server restarts.
+ */
+import { createHash } from "node:crypto";
+import type { Config } from "../config.js";
+import { conversationScopedKey } from "./conversation-context.js";
+
+/** Storage backend for cached responses. Implementations must honour `ttlMs`. */
+export interface ResponseCacheStore {
+  get(key: string): unknown | undefined;
+  set(key: string, value: unknown, ttlMs: number): void;
+  clear(): void;
+  readonly size: number;
+}
+
+/** In-memory LRU store. Evicts the least recently used entry past `maxEntries`. */
+export class MemoryCacheStore implements ResponseCacheStore {
+  private readonly entries = new Map<string, { value: unknown; expiresAt: number }>();
+
+  constructor(
+    private readonly maxEntries: number,
+    private readonly onEvict: () => void = () => {},
+  ) {}
+
+  get size(): number {
+    return this.entries.size;
+  }
+
+  get(key: string): unknown | undefined {
+    const entry = this.entries.get(key);
+    if (!entry) return undefined;
+    this.entries.delete(key);
+    if (Date.now() >= entry.expiresAt) return undefined;
+    this.entries.set(key, entry);
+    return entry.value;
+  }
+
+  set(key: string, value: unknown, ttlMs: number): void {
+    this.entries.delete(key);
+    this.entries.set(key, { value, expiresAt: Date.now() + ttlMs });
+    while (this.entries.size > this.maxEntries) {
+      const oldest = this.entries.keys().next().value;
+      if (oldest === undefined) break;
+      this.entries.delete(oldest);
+      this.onEvict();
+    }
+  }
+
+  clear(): void {
+    this.entries.clear();
+  }
+}
+
+type CacheEvent = "hits" | "misses" | "bypasses" | "evictions";
+
+interface ToolsetCounters {
+  hits: number;
+  misses: number;
+  bypasses: number;
+  evictions: number;
+}
+
+export interface CacheMetricsReport {
+  hits: number;
+  misses: number;
+  bypasses: number;
+  evictions: number;
+  invalidations: number;
+  hit_rate: number;
+  toolsets: Array<{ toolset: string } & ToolsetCounters>;
+}
+
+const counters = new Map<string, ToolsetCounters>();
+let invalidations = 0;
+
+function record(toolset: string, event: CacheEvent): void {
+  let row = counters.get(toolset);
+  if (!row) {
+    row = { hits: 0, misses: 0, bypasses: 0, evictions: 0 };
+    counters.set(toolset, row);
+  }
+  row[event]++;
+}
+
+/** Process-wide cache counters, overall and per toolset. */
+export function summarizeCacheMetrics(): CacheMetricsReport {
+  const toolsets = [...counters.entries()]
+    .map(([toolset, row]) => ({ toolset, ...row }))
+    .sort((a, b) => (b.hits + b.misses) - (a.hits + a.misses) || a.toolset.localeCompare(b.toolset));
+  const sum = (event: CacheEvent) => toolsets.reduce((n, row) => n + row[event], 0);
+  const hits = sum("hits");
+  const misses = sum("misses");
+  return {
+    hits,
+    misses,
+    bypasses: sum("bypasses"),
+    evictions: sum("evictions"),
+    invalidations,
+    hit_rate: hits + misses > 0 ? Math.round((hits / (hits + misses)) * 1000) / 1000 : 0,
+    toolsets,
+  };
+}
+
+/** Reset process-wide counters (tests). */
+export function resetCacheMetrics(): void {
+  counters.clear();
+  invalidations = 0;
+}
+
+/** True when the caller asked to skip the cache for this call. */
+export function isCacheBypassed(input: Record<string, unknown>): boolean {
+  return input.cache_bypass === true || input.cache_bypass === "true";
+}
+
+function canonicalJson(value: unknown): string {
+  if (Array.isArray(value)) return `[${value.map(canonicalJson).join(",")}]`;
+  if (value && typeof value === "object") {
+    const entries = Object.entries(value as Record<string, unknown>)
+      .filter(([, v]) => v !== undefined)
+      .sort(([a], [b]) => a.localeCompare(b));
+    return `{${entries.map(([k, v]) => `${JSON.stringify(k)}:${canonicalJson(v)}`).join(",")}}`;
+  }
+  return JSON.stringify(value) ?? "null";
+}
+
+/** Deep copy that keeps the registry's non-enumerable `__skipCompact` marker. */
+function cloneResult<T>(value: T): T {
+  const copy = structuredClone(value);
+  if (value && typeof value === "object" && (value as { __skipCompact?: boolean }).__skipCompact === true) {
+    Object.defineProperty(copy, "__skipCompact", { value: true, enumerable: false, configurable: true });
+  }
+  return copy;
+}
+
+export interface ResponseCacheOptions {
+  ttlMs: number;
+  maxEntries: number;
+  /** Toolsets to cache. Undefined caches every toolset. */
+  toolsets?: ReadonlySet<string>;
+  store?: ResponseCacheStore;
+}
+
+export class ResponseCache {
+  private readonly store: ResponseCacheStore;
+  private evictingToolset = "unknown";
+
+  constructor(private readonly options: ResponseCacheOptions) {
+    this.store = options.store ?? new MemoryCacheStore(options.maxEntries, () => record(this.evictingToolset, "evictions"));
+  }
+
+  get size(): number {
+    return this.store.size;
+  }
+
+  isEnabledFor(toolset: string): boolean {
+    return !this.options.toolsets || this.options.toolsets.has(toolset);
+  }
+
+  /** Cache key for one call in the active conversation. `cache_bypass` never affects the key. */
+  key(account: string, resourceType: string, operation: string, input: Record<string, unknown>): string {
+    const { cache_bypass: _bypass, ...rest } = input;
+    return conversationScopedKey(createHash("sha256")
+      .update(canonicalJson({ account, resourceType, operation, input: rest }))
+      .digest("hex"));
+  }
+
+  /**
+   * Return a cached copy for `key`, or undefined on a miss. A bypassed call
+   * counts as a bypass and always misses.
+   */
+  get(toolset: string, key: string, bypass: boolean): unknown | undefined {
+    if (bypass) {
+      record(toolset, "bypasses");
+      return undefined;
+    }
+    const hit = this.store.get(key);
+    if (hit === undefined) {
+      record(toolset, "misses");
+      return undefined;
+    }
+    record(toolset, "hits");
+    return cloneResult(hit);
+  }
+
+  set(toolset: string, key: string, value: unknown): void {
+    if (value === undefined) return;
+    this.evictingToolset = toolset;
+    this.store.set(key, cloneResult(value), this.options.ttlMs);
+  }
+
+  /** Drop every entry — called after any successful write. */
+  invalidate(): void {
+    if (this.store.size === 0) return;
+    this.store.clear();
+    invalidations++;
+  }
+}
+
+/** Build the cache from config, or undefined when HARNESS_CACHE_TTL_MS is 0. */
+export function createResponseCache(
+  config: Partial<Pick<Config, "HARNESS_CACHE_TTL_MS" | "HARNESS_CACHE_MAX_ENTRIES" | "HARNESS_CACHE_TOOLSETS">>,
+): ResponseCache | undefined {
+  const ttlMs = config.HARNESS_CACHE_TTL_MS ?? 0;
+  if (ttlMs <= 0) return undefined;
+  const toolsets = config.HARNESS_CACHE_TOOLSETS
+    ? new Set(config.HARNESS_CACHE_TOOLSETS.split(",").map((t) => t.trim()).filter(Boolean))
+    : undefined;
+  return new ResponseCache({ ttlMs, maxEntries: config.HARNESS_CACHE_MAX_ENTRIES ?? 500, toolsets });
+}
@@ -4,6 +4,7 @@
  * Uses compact JSON (no indentation) to minimize token count for LLM consumers.
  * Errors keep minimal formatting for readability in tool-call error surfaces.
  */
+import { findRemediation } from "./remediation.js";
 
 export type ContentItem = { type: "text"; text: string };
 
@@ -67,9 +68,14 @@ export function jsonResult(data: unknown): ToolResult {
   };
 }
 
+/**
+ * Error result for the LLM. When the message matches a known error signature,
+ * structured `remediation` steps are included alongside it.
+ */
 export function errorResult(message: string): ToolResult {
+  const remediation = findRemediation(message);
   return {
-    content: [{ type: "text", text: JSON.stringify({ error: message }) }],
+    content: [{ type: "text", text: JSON.stringify(remediation ? { error: message, remediation } : { error: message }) }],
     isError: true,
   };
 }
@@ -0,0 +1,251 @@
+/**
+ * Localized summaries for composite tools.
+ *
+ * harness_diagnose and harness_status accept an optional `locale` (a BCP 47
+ * tag such as "ja" or "pt-BR"). When it names a non-English language, the
+ * human-readable summary fields of the result (suggested fixes, issues,
+ * notes, hints) are translated by the client's own model through MCP sampling
+ * (`sampling/createMessage`, https://modelcontextprotocol.io/specification/2025-06-18/client/sampling)
+ * before the result is returned. Harness has no translation API, and the
+ * client's model already sees the result, so no data leaves for a new
+ * destination. Identifiers, statuses, error messages, log excerpts, and every
+ * other raw field are left exactly as the API returned them, so follow-up
+ * calls and automation still see the original values.
+ *
+ * Translation is best effort: when the client does not support sampling, or
+ * the request fails, the result is returned untranslated with a
+ * `_locale_note`, never as an error.
+ */
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import type { Server } from "@modelcontextprotocol/sdk/server/index.js";
+import { withRegisterTool } from "./register-tool.js";
+import * as z from "zod/v4";
+import { createLogger } from "./logger.js";
+import { errorResult, type ToolResult } from "./response-formatter.js";
+import { isRecord } from "./type-guards.js";
+
+const log = createLogger("result-localization");
+
+/** Tools that take `locale`: the ones that compose a summary rather than return one resource. */
+export const LOCALIZED_TOOLS: ReadonlySet<string> = new Set(["harness_diagnose", "harness_status"]);
+
+/** Fields holding text written for a person. Only string values (or arrays of strings) under these keys are translated. */
+const SUMMARY_FIELDS = new Set([
+  "summary",
+  "suggested_fix",
+  "issues",
+  "note",
+  "hint",
+  "_hint",
+  "recommendation",
+  "recommendations",
+  "explanation",
+]);
+
+const TRANSLATE_TIMEOUT_MS = 30_000;
+/** Most strings sent in one translation request; the rest stay untranslated. */
+const MAX_TEXTS = 100;
+const MAX_TEXT_CHARS = 2000;
+/** Upper bound on the tokens the client's model may spend on one translation. */
+const MAX_SAMPLING_TOKENS = 16_000;
+const CACHE_MAX_ENTRIES = 1000;
+const MAX_DEPTH = 32;
+
+const TRANSLATE_PROMPT =
+  "You translate short operational summaries for a DevOps tool. The user message is JSON " +
+  "{\"target_locale\": string, \"texts\": string[]}. Reply with only a JSON array of strings: " +
+  "the texts translated to target_locale, in the same order and the same count. Keep identifiers, " +
+  "URLs, numbers, code, and quoted values unchanged.";
+
+/** The part of the MCP server connection used to ask the client's model for a translation. */
+export type Sampler = Pick<Server, "createMessage" | "getClientCapabilities">;
+
+const localeSchema = z.string().optional().describe(
+  "Language for human-readable summary fields (suggested fixes, issues, notes), as a BCP 47 tag such as 'ja' or 'pt-BR'. Translated by the client's model via MCP sampling; raw data is never translated. Default: English.",
+);
+
+const cache = new Map<string, string>();
+
+/** Clear the translation cache (tests). */
+export function resetLocalizationCache(): void {
+  cache.clear();
+}
+
+/**
+ * Canonical form of `locale`, or undefined when no translation is needed
+ * (unset or English). Throws on a malformed tag.
+ */
+export function normalizeLocale(locale: unknown): string | undefined {
+  if (locale === undefined || locale === null || locale === "") return undefined;
+  if (typeof locale !== "string") throw new Error("locale must be a BCP 47 language tag such as 'ja' or 'pt-BR'");
+  let canonical: string;
+  try {
+    canonical = Intl.getCanonicalLocales(locale.trim())[0]!;
+  } catch {
+    throw new Error(`Invalid locale "${locale}". Use a BCP 47 language tag such as 'ja' or 'pt-BR'.`);
+  }
+  return canonical.split("-")[0]!.toLowerCase() === "en" ? undefined : canonical;
+}
+
+/** Summary strings in `data`, in document order and without duplicates. */
+export function collectSummaryTexts(data: unknown, out = new Set<string>(), depth = 0): Set<string> {
+  if (depth > MAX_DEPTH) return out;
+  if (Array.isArray(data)) {
+    for (const item of data) collectSummaryTexts(item, out, depth + 1);
+  } else if (isRecord(data)) {
+    for (const [key, value] of Object.entries(data)) {
+      if (SUMMARY_FIELDS.has(key) && typeof value === "string" && value.trim()) out.add(value);
+      else if (SUMMARY_FIELDS.has(key) && Array.isArray(value) && value.every((v) => typeof v === "string")) {
+        for (const v of value) if (v.trim()) out.add(v);
+      } else collectSummaryTexts(value, out, depth + 1);
+    }
+  }
+  return out;
+}
+
+/** `data` with summary strings replaced from `translations`. Everything else is copied unchanged. */
+export function applyTranslations(data: unknown, translations: ReadonlyMap<string, string>, depth = 0): unknown {
+  if (depth > MAX_DEPTH) return data;
+  if (Array.isArray(data)) return data.map((item) => applyTranslations(item, translations, depth + 1));
+  if (!isRecord(data)) return data;
+  const out: Record<string, unknown> = {};
+  for (const [key, value] of Object.entries(data)) {
+    if (SUMMARY_FIELDS.has(key) && typeof value === "string") out[key] = translations.get(value) ?? value;
+    else if (SUMMARY_FIELDS.has(key) && Array.isArray(value) && value.every((v) => typeof v === "string")) {
+      out[key] = value.map((v) => translations.get(v) ?? v);
+    } else out[key] = applyTranslations(value, translations, depth + 1);
+  }
+  return out;
+}
+
+/** The JSON array of strings in a sampling reply, tolerating a Markdown code fence around it. */
+function parseTranslations(text: string): unknown[] | undefined {
+  const unfenced = text.trim().replace(/^```(?:json)?\s*/i, "").replace(/\s*```$/, "");
+  try {
+    const parsed: unknown = JSON.parse(unfenced);
+    return Array.isArray(parsed) ? parsed : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Translate `texts` to `locale` with the client's model over MCP sampling,
+ * using cached translations where available. The reply must be a JSON array
+ * with one string per text, in input order.
+ */
+export async function translateTexts(
+  sampler: Sampler,
+  texts: string[],
+  locale: string,
+  signal?: AbortSignal,
+): Promise<Map<string, string>> {
+  const result = new Map<string, string>();
+  const pending: string[] = [];
+  for (const text of texts) {
+    const cached = cache.get(`${locale}\u0000${text}`);
+    if (cached !== undefined) result.set(text, cached);
+    else if (pending.length < MAX_TEXTS && text.length <= MAX_TEXT_CHARS) pending.push(text);
+  }
+  if (pending.length === 0) return result;
+  if (!sampler.getClientCapabilities()?.sampling) {
+    throw new Error("the MCP client does not support sampling");
+  }
+
+  const chars = pending.reduce((sum, text) => sum + text.length, 0);
+  const response = await sampler.createMessage(
+    {
+      systemPrompt: TRANSLATE_PROMPT,
+      messages: [{ role: "user", content: { type: "text", text: JSON.stringify({ target_locale: locale, texts: pending }) } }],
+      maxTokens: Math.min(MAX_SAMPLING_TOKENS, 512 + chars * 2),
+      temperature: 0,
+      includeContext: "none",
+    },
+    { signal, timeout: TRANSLATE_TIMEOUT_MS },
+  );
+  const blocks: unknown[] = Array.isArray(response.content) ? response.content : [response.content];
+  const reply = blocks.find((b): b is { type: "text"; text: string } => isRecord(b) && b.type === "text" && typeof b.text === "string");
+  const translated = reply ? parseTranslations(reply.text) : undefined;
+  if (!translated || translated.length !== pending.length) {
+    throw new Error("translation reply did not contain one translation per text");
+  }
+  pending.forEach((text, i) => {
+    const value = translated[i];
+    if (typeof value !== "string" || !value.trim()) return;
+    result.set(text, value);
+    if (cache.size >= CACHE_MAX_ENTRIES) cache.delete(cache.keys().next().value!);
+    cache.set(`${locale}\u0000${text}`, value);
+  });
+  return result;
+}
+
+/** Translate the summary fields of a successful result. Failures return the result untranslated with a note. */
+export async function localizeResult(
+  result: ToolResult,
+  sampler: Sampler,
+  locale: string,
+  signal?: AbortSignal,
+): Promise<ToolResult> {
+  if (result.isError) return result;
+  const parsed = result.content.map((item) => {
+    try {
+      return JSON.parse(item.text) as unknown;
+    } catch {
+      return undefined;
+    }
+  });
+  const texts = new Set<string>();
+  for (const data of parsed) collectSummaryTexts(data, texts);
+  if (result.structuredContent) collectSummaryTexts(result.structuredContent, texts);
+  if (texts.size === 0) return result;
+
+  let translations: Map<string, string>;
+  try {
+    translations = await translateTexts(sampler, [...texts], locale, signal);
+  } catch (err) {
+    log.warn("Summary translation failed; returning English", { locale, error: err instanceof Error ? err.message : String(err) });
+    const note = `Summaries could not be translated to ${locale} and are shown in English.`;
+    return {
+      ...result,
+      content: result.content.map((item, i) =>
+        isRecord(parsed[i]) ? { ...item, text: JSON.stringify({ ...(parsed[i] as Record<string, unknown>), _locale_note: note }) } : item),
+    };
+  }
+  const structured = result.structuredContent ? applyTranslations(result.structuredContent, translations) : undefined;
+  return {
+    ...result,
+    content: result.content.map((item, i) =>
+      parsed[i] === undefined ? item : { ...item, text: JSON.stringify(applyTranslations(parsed[i], translations)) }),
+    ...(isRecord(structured) ? { structuredContent: structured } : {}),
+  };
+}
+
+type ToolConfig = { inputSchema?: Record<string, unknown> } & Record<string, unknown>;
+type ToolCallback = (args: Record<string, unknown>, extra: unknown) => ToolResult | Promise<ToolResult>;
+type RegisterTool = (name: string, config: ToolConfig, callback: ToolCallback) => unknown;
+
+/**
+ * A view of `server` whose registerTool adds the `locale` parameter to the
+ * composite tools and translates their summary fields when it is set.
+ * Other tools are registered unchanged.
+ */
+export function withResultLocalization(server: McpServer): McpServer {
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) => {
+    if (!LOCALIZED_TOOLS.has(name) || !config.inputSchema) return register(name, config, callback);
+    return register(name, { ...config, inputSchema: { ...config.inputSchema, locale: localeSchema } }, async (args, extra) => {
+      const { locale: requested, ...rest } = args ?? {};
+      let locale: string | undefined;
+      try {
+        locale = normalizeLocale(requested);
+      } catch (err) {
+        return errorResult((err as Error).message);
+      }
+      const result = await callback(rest, extra);
+      if (!locale) return result;
+      const signal = isRecord(extra) && extra.signal instanceof AbortSignal ? extra.signal : undefined;
+      return localizeResult(result, server.server, locale, signal);
+    });
+  };
+  return withRegisterTool(server, registerTool);
+}
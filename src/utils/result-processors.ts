@@ -0,0 +1,317 @@
+/**
+ * Post-processors for tool results, configured per deployment.
+ *
+ * HARNESS_RESULT_PROCESSORS lists rules (inline JSON, or the path of a JSON
+ * file). Each rule matches tools — and optionally resource types — by glob and
+ * runs either a built-in processor or an HTTP webhook on the result before it
+ * is returned: custom redaction, enrichment from an internal CMDB, or format
+ * conversion. Matching rules run in config order, each on the previous one's
+ * output.
+ *
+ * Built-in processors live in this file; there is no runtime plugin loading.
+ * Anything else goes through a webhook.
+ */
+import { readFileSync } from "node:fs";
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { withRegisterTool } from "./register-tool.js";
+import * as z from "zod/v4";
+import YAML from "yaml";
+import type { Config } from "../config.js";
+import { createLogger } from "./logger.js";
+import { redactSensitiveFields } from "./redact.js";
+import { getRequestSignal } from "./request-signal.js";
+import { errorResult, type ToolResult } from "./response-formatter.js";
+import { isRecord } from "./type-guards.js";
+
+const log = createLogger("result-processors");
+
+const DEFAULT_WEBHOOK_TIMEOUT_MS = 5000;
+
+export interface ResultProcessorContext {
+  tool: string;
+  resource_type?: string;
+  args: Record<string, unknown>;
+}
+
+type BuiltinProcessor = (result: ToolResult, options: Record<string, unknown>) => ToolResult;
+
+/** Apply `fn` to every JSON text item and to structuredContent. Text that is not JSON is left alone. */
+function mapJson(result: ToolResult, fn: (data: unknown) => unknown): ToolResult {
+  const content = result.content.map((item) => {
+    try {
+      return { ...item, text: JSON.stringify(fn(JSON.parse(item.text))) };
+    } catch {
+      return item;
+    }
+  });
+  const structured = result.structuredContent ? fn(result.structuredContent) : undefined;
+  return {
+    ...result,
+    content,
+    ...(isRecord(structured) ? { structuredContent: structured } : {}),
+  };
+}
+
+function stringList(value: unknown, name: string): string[] {
+  if (!Array.isArray(value) || value.some((v) => typeof v !== "string")) {
+    throw new Error(`options.${name} must be an array of strings`);
+  }
+  return value as string[];
+}
+
+const patternCache = new WeakMap<Record<string, unknown>, RegExp[]>();
+
+/** options.patterns compiled once per rule. Throws on a pattern that is not a valid regular expression. */
+function compiledPatterns(options: Record<string, unknown>): RegExp[] {
+  let patterns = patternCache.get(options);
+  if (!patterns) {
+    patterns = stringList(options.patterns, "patterns").map((p) => {
+      try {
+        return new RegExp(p, "g");
+      } catch (err) {
+        throw new Error(`options.patterns: ${err instanceof Error ? err.message : String(err)}`);
+      }
+    });
+    patternCache.set(options, patterns);
+  }
+  return patterns;
+}
+
+function dropFields(data: unknown, fields: Set<string>): unknown {
+  if (Array.isArray(data)) return data.map((item) => dropFields(item, fields));
+  if (!isRecord(data)) return data;
+  const out: Record<string, unknown> = {};
+  for (const [key, value] of Object.entries(data)) {
+    if (!fields.has(key)) out[key] = dropFields(value, fields);
+  }
+  return out;
+}
+
+export const BUILTIN_PROCESSORS: Record<string, BuiltinProcessor> = {
+  /** Redact values under secret-like keys (token, password, api_key, ...), as the audit log does. */
+  redact_secrets: (result) => mapJson(result, (data) => redactSensitiveFields(data)),
+
+  /** Replace every match of options.patterns (regular expressions) with options.replacement. */
+  redact_patterns: (result, options) => {
+    const patterns = compiledPatterns(options);
+    const replacement = typeof options.replacement === "string" ? options.replacement : "[REDACTED]";
+    const scrub = (text: string) => patterns.reduce((t, re) => t.replace(re, replacement), text);
+    let structured: unknown;
+    try {
+      structured = result.structuredContent ? JSON.parse(scrub(JSON.stringify(result.structuredContent))) : undefined;
+    } catch {
+      structured = undefined;
+    }
+    // A pattern that breaks the JSON drops structuredContent rather than leaking the unredacted copy.
+    const { structuredContent: _original, ...rest } = result;
+    return {
+      ...rest,
+      content: result.content.map((item) => ({ ...item, text: scrub(item.text) })),
+      ...(isRecord(structured) ? { structuredContent: structured } : {}),
+    };
+  },
+
+  /** Remove options.fields keys at any depth. */
+  drop_fields: (result, options) => {
+    const fields = new Set(stringList(options.fields, "fields"));
+    return mapJson(result, (data) => dropFields(data, fields));
+  },
+
+  /** Render JSON text as YAML. structuredContent is kept as JSON for clients that read it. */
+  yaml: (result) => ({
+    ...result,
+    content: result.content.map((item) => {
+      try {
+        return { ...item, text: YAML.stringify(JSON.parse(item.text)) };
+      } catch {
+        return item;
+      }
+    }),
+  }),
+};
+
+const RuleSchema = z.object({
+  /** Comma-separated tool name globs, e.g. "harness_get,harness_list" or "harness_*". */
+  tools: z.string().min(1).default("*"),
+  /** Comma-separated resource_type globs. Omit to match calls with any or no resource_type. */
+  resource_types: z.string().min(1).optional(),
+  processor: z.enum(Object.keys(BUILTIN_PROCESSORS) as [string, ...string[]]).optional(),
+  webhook: z.string().url().optional(),
+  options: z.record(z.string(), z.unknown()).default({}),
+  headers: z.record(z.string(), z.string()).default({}),
+  timeout_ms: z.number().int().min(1).max(60_000).default(DEFAULT_WEBHOOK_TIMEOUT_MS),
+  /**
+   * pass: return the result unprocessed; fail: return an error instead.
+   * Defaults to fail for redaction processors and webhooks, which must not fail open.
+   */
+  on_error: z.enum(["pass", "fail"]).optional(),
+}).refine((r) => (r.processor === undefined) !== (r.webhook === undefined), {
+  message: "each rule needs exactly one of processor or webhook",
+}).transform((r) => ({ ...r, on_error: r.on_error ?? defaultOnError(r) }));
+
+function defaultOnError(rule: { processor?: string; webhook?: string }): "pass" | "fail" {
+  return rule.webhook !== undefined || rule.processor?.startsWith("redact_") ? "fail" : "pass";
+}
+
+/** Check a built-in rule's options up front, so a bad pattern or field list fails at load instead of on every call. */
+function validateBuiltinOptions(rule: ResultProcessorRule): void {
+  if (rule.processor === "redact_patterns") compiledPatterns(rule.options);
+  if (rule.processor === "drop_fields") stringList(rule.options.fields, "fields");
+}
+
+export type ResultProcessorRule = z.infer<typeof RuleSchema>;
+
+let rules: ResultProcessorRule[] = [];
+
+/**
+ * Parse HARNESS_RESULT_PROCESSORS: a JSON array of rules, or the path of a
+ * file holding one. Throws on invalid rules so a typo fails at startup
+ * instead of silently skipping a redaction.
+ */
+export function loadResultProcessors(config: Pick<Config, "HARNESS_RESULT_PROCESSORS" | "HARNESS_ALLOW_HTTP">): ResultProcessorRule[] {
+  const spec = config.HARNESS_RESULT_PROCESSORS?.trim();
+  if (!spec) return [];
+  let raw: unknown;
+  try {
+    raw = JSON.parse(spec.startsWith("[") ? spec : readFileSync(spec, "utf8"));
+  } catch (err) {
+    throw new Error(`Invalid HARNESS_RESULT_PROCESSORS: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  const parsed = z.array(RuleSchema).safeParse(raw);
+  if (!parsed.success) {
+    const issues = parsed.error.issues.map((i) => `  ${i.path.join(".")}: ${i.message}`).join("\n");
+    throw new Error(`Invalid HARNESS_RESULT_PROCESSORS:\n${issues}`);
+  }
+  for (const [index, rule] of parsed.data.entries()) {
+    if (rule.webhook && !rule.webhook.startsWith("https://") && !config.HARNESS_ALLOW_HTTP) {
+      throw new Error(
+        `HARNESS_RESULT_PROCESSORS webhook must use HTTPS (got "${rule.webhook}"). ` +
+        "If you need HTTP for local development, set HARNESS_ALLOW_HTTP=true.",
+      );
+    }
+    try {
+      validateBuiltinOptions(rule);
+    } catch (err) {
+      throw new Error(`Invalid HARNESS_RESULT_PROCESSORS:\n  ${index}.${err instanceof Error ? err.message : String(err)}`);
+    }
+  }
+  return parsed.data;
+}
+
+/** Set the active rules (from loadResultProcessors). */
+export function configureResultProcessors(next: ResultProcessorRule[]): void {
+  rules = next;
+}
+
+/** Clear the active rules (tests). */
+export function resetResultProcessors(): void {
+  rules = [];
+}
+
+function globMatch(patterns: string, value: string): boolean {
+  return patterns.split(",").map((p) => p.trim()).filter(Boolean).some((p) => {
+    const re = new RegExp(`^${p.split("*").map((s) => s.replace(/[.+?^${}()|[\]\\]/g, "\\$&")).join(".*")}$`);
+    return re.test(value);
+  });
+}
+
+function ruleMatches(rule: ResultProcessorRule, ctx: ResultProcessorContext): boolean {
+  if (!globMatch(rule.tools, ctx.tool)) return false;
+  if (rule.resource_types === undefined) return true;
+  return ctx.resource_type !== undefined && globMatch(rule.resource_types, ctx.resource_type);
+}
+
+function ruleName(rule: ResultProcessorRule): string {
+  if (rule.processor) return rule.processor;
+  try {
+    return `webhook ${new URL(rule.webhook!).origin}`;
+  } catch {
+    return "webhook";
+  }
+}
+
+/**
+ * Abort signal for a webhook call: the rule's timeout, or the tool call's own
+ * cancellation, whichever comes first — a hung webhook must not hold the
+ * result past either.
+ */
+function webhookSignal(timeoutMs: number): AbortSignal {
+  const timeout = AbortSignal.timeout(timeoutMs);
+  const request = getRequestSignal();
+  return request ? AbortSignal.any([request, timeout]) : timeout;
+}
+
+/**
+ * POST the result to a webhook. A 204 or empty body keeps the result; a JSON
+ * body {content, structuredContent?, isError?} replaces it. Arguments are sent
+ * with secret-like fields redacted.
+ */
+async function callWebhook(rule: ResultProcessorRule, result: ToolResult, ctx: ResultProcessorContext): Promise<ToolResult> {
+  const response = await fetch(rule.webhook!, {
+    method: "POST",
+    headers: { "Content-Type": "application/json", ...rule.headers },
+    body: JSON.stringify({
+      tool: ctx.tool,
+      ...(ctx.resource_type ? { resource_type: ctx.resource_type } : {}),
+      arguments: redactSensitiveFields(ctx.args),
+      result: { content: result.content, isError: result.isError === true, structuredContent: result.structuredContent },
+      ...(Object.keys(rule.options).length > 0 ? { options: rule.options } : {}),
+    }),
+    signal: webhookSignal(rule.timeout_ms),
+  });
+  if (!response.ok) throw new Error(`HTTP ${response.status}`);
+  const text = response.status === 204 ? "" : await response.text();
+  if (!text.trim()) return result;
+  const body: unknown = JSON.parse(text);
+  const content = isRecord(body) ? body.content : undefined;
+  if (!Array.isArray(content) || !content.every((c) => isRecord(c) && c.type === "text" && typeof c.text === "string")) {
+    throw new Error("response must be {content: [{type: \"text\", text}], structuredContent?, isError?}");
+  }
+  const isError = isRecord(body) && typeof body.isError === "boolean" ? body.isError : result.isError === true;
+  return {
+    content: content as ToolResult["content"],
+    ...(isRecord(body) && isRecord(body.structuredContent) ? { structuredContent: body.structuredContent } : {}),
+    ...(isError ? { isError: true } : {}),
+  };
+}
+
+/** Run every matching rule on one tool result, in config order. */
+export async function runResultProcessors(result: ToolResult, ctx: ResultProcessorContext): Promise<ToolResult> {
+  let current = result;
+  for (const rule of rules) {
+    if (!ruleMatches(rule, ctx)) continue;
+    try {
+      current = rule.processor
+        ? BUILTIN_PROCESSORS[rule.processor]!(current, rule.options)
+        : await callWebhook(rule, current, ctx);
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      log.warn("Result processor failed", { processor: ruleName(rule), tool: ctx.tool, error: message, on_error: rule.on_error });
+      if (rule.on_error === "fail") return errorResult(`Result processor ${ruleName(rule)} failed: ${message}`);
+    }
+  }
+  return current;
+}
+
+type ToolCallback = (args: Record<string, unknown>, extra: unknown) => ToolResult | Promise<ToolResult>;
+type RegisterTool = (name: string, config: unknown, callback: ToolCallback) => unknown;
+
+/**
+ * A view of `server` whose registerTool runs the configured processors on
+ * every result of the tools registered through it. Without rules, results
+ * pass through untouched.
+ */
+export function withResultProcessors(server: McpServer): McpServer {
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) =>
+    register(name, config, async (args, extra) => {
+      const result = await callback(args, extra);
+      if (rules.length === 0) return result;
+      return runResultProcessors(result, {
+        tool: name,
+        ...(typeof args?.resource_type === "string" ? { resource_type: args.resource_type } : {}),
+        args: args ?? {},
+      });
+    });
+  return withRegisterTool(server, registerTool);
+}
@@ -11,6 +11,7 @@
 import YAML from "yaml";
 import type { HarnessClient } from "../client/harness-client.js";
 import { createLogger } from "./logger.js";
+import { conversationScopedKey } from "./conversation-context.js";
 import { isRecord, asRecord, asString } from "./type-guards.js";
 
 const log = createLogger("runtime-inputs");
@@ -27,8 +28,8 @@ interface CachedTemplate {
 
 const templateCache = new Map<string, CachedTemplate>();
 
-function templateCacheKey(opts: ResolveOptions): string {
-  return `${opts.pipelineId}|${opts.orgId ?? ""}|${opts.projectId ?? ""}|${opts.branch ?? ""}`;
+function templateCacheKey(client: HarnessClient, opts: ResolveOptions): string {
+  return conversationScopedKey(`${client.account}|${opts.pipelineId}|${opts.orgId ?? ""}|${opts.projectId ?? ""}|${opts.branch ?? ""}`);
 }
 
 /** Evict expired entries. Called on cache writes to prevent unbounded growth. */
@@ -75,7 +76,7 @@ export async function fetchRuntimeInputTemplate(
   client: HarnessClient,
   options: ResolveOptions,
 ): Promise<string | null> {
-  const cacheKey = templateCacheKey(options);
+  const cacheKey = templateCacheKey(client, options);
   const cached = templateCache.get(cacheKey);
   if (cached && Date.now() < cached.expiresAt) {
     log.debug("Runtime input template cache hit", { pipelineId: options.pipelineId });
@@ -0,0 +1,156 @@
+/**
+ * Component-level comparison of two SBOMs: what was added, removed, upgraded,
+ * or downgraded, and how licenses and known vulnerabilities moved between
+ * them. Used to answer release-gating questions ("what does this release pull
+ * in?") from two component inventories instead of diffing them in context.
+ */
+
+/** One SBOM component. `key` identifies the package across versions (e.g. a version-less purl). */
+export interface SbomComponent {
+  key: string;
+  name: string;
+  version?: string;
+  license?: string;
+  purl?: string;
+  /** Known vulnerabilities in this component version. */
+  vulnerabilities?: number;
+}
+
+export interface SbomVersionChange {
+  name: string;
+  from?: string;
+  to?: string;
+  vulnerabilities_before?: number;
+  vulnerabilities_after?: number;
+  license_before?: string;
+  license_after?: string;
+}
+
+export interface SbomDiff {
+  added: SbomComponent[];
+  removed: SbomComponent[];
+  upgraded: SbomVersionChange[];
+  downgraded: SbomVersionChange[];
+  /** Version changed but the versions do not compare (e.g. commit hashes). */
+  changed: SbomVersionChange[];
+  /** Same version, different license. */
+  relicensed: SbomVersionChange[];
+  licenses: { introduced: string[]; dropped: string[] };
+  vulnerabilities: { base: number; target: number; delta: number; introduced_by: Array<{ name: string; vulnerabilities: number }> };
+}
+
+const SEGMENT = /\d+|[a-z]+/gi;
+
+/**
+ * Compare two version strings segment by segment: numeric segments as
+ * numbers, others as text, and a release sorting after its pre-releases
+ * (1.0.0 > 1.0.0-rc1). Returns undefined when either version is missing or
+ * the two share no comparable prefix, such as two commit hashes.
+ */
+export function compareVersions(a: string | undefined, b: string | undefined): number | undefined {
+  if (!a || !b) return undefined;
+  if (a === b) return 0;
+  const left = a.replace(/^v/i, "").match(SEGMENT) ?? [];
+  const right = b.replace(/^v/i, "").match(SEGMENT) ?? [];
+  if (!/^\d/.test(left[0] ?? "") || !/^\d/.test(right[0] ?? "")) return undefined;
+  for (let i = 0; i < Math.max(left.length, right.length); i++) {
+    const l = left[i];
+    const r = right[i];
+    if (l === undefined) return /^\d/.test(r!) ? -1 : 1;
+    if (r === undefined) return /^\d/.test(l) ? 1 : -1;
+    const ln = /^\d+$/.test(l);
+    const rn = /^\d+$/.test(r);
+    if (ln && rn) {
+      const d = Number(l) - Number(r);
+      if (d !== 0) return Math.sign(d);
+    } else if (ln !== rn) {
+      return ln ? 1 : -1;
+    } else if (l !== r) {
+      return l < r ? -1 : 1;
+    }
+  }
+  return 0;
+}
+
+function byKey(components: readonly SbomComponent[]): Map<string, SbomComponent[]> {
+  const map = new Map<string, SbomComponent[]>();
+  for (const c of components) map.set(c.key, [...(map.get(c.key) ?? []), c]);
+  return map;
+}
+
+const vulns = (components: readonly SbomComponent[]) => components.reduce((sum, c) => sum + (c.vulnerabilities ?? 0), 0);
+
+/**
+ * Diff two component inventories. Components are matched by `key`; a
+ * package present in several versions on one side is compared version by
+ * version, with versions present on both sides left out.
+ */
+export function diffSboms(base: readonly SbomComponent[], target: readonly SbomComponent[]): SbomDiff {
+  const before = byKey(base);
+  const after = byKey(target);
+  const diff: SbomDiff = {
+    added: [],
+    removed: [],
+    upgraded: [],
+    downgraded: [],
+    changed: [],
+    relicensed: [],
+    licenses: { introduced: [], dropped: [] },
+    vulnerabilities: { base: vulns(base), target: vulns(target), delta: vulns(target) - vulns(base), introduced_by: [] },
+  };
+
+  for (const [key, olds] of before) {
+    if (!after.has(key)) diff.removed.push(...olds);
+  }
+  for (const [key, news] of after) {
+    const olds = before.get(key);
+    if (!olds) {
+      diff.added.push(...news);
+      continue;
+    }
+    const oldOnly = olds.filter((o) => !news.some((n) => n.version === o.version));
+    const newOnly = news.filter((n) => !olds.some((o) => o.version === n.version));
+    for (const n of news) {
+      const o = olds.find((c) => c.version === n.version);
+      if (o && (o.license ?? "") !== (n.license ?? "")) {
+        diff.relicensed.push({ name: n.name, from: o.version, to: n.version, license_before: o.license, license_after: n.license });
+      }
+    }
+    // Pair the remaining versions in order; extras on either side count as added or removed.
+    const pairs = Math.min(oldOnly.length, newOnly.length);
+    for (let i = 0; i < pairs; i++) {
+      const o = oldOnly[i]!;
+      const n = newOnly[i]!;
+      const change: SbomVersionChange = {
+        name: n.name,
+        from: o.version,
+        to: n.version,
+        ...(o.vulnerabilities !== undefined ? { vulnerabilities_before: o.vulnerabilities } : {}),
+        ...(n.vulnerabilities !== undefined ? { vulnerabilities_after: n.vulnerabilities } : {}),
+        ...((o.license ?? "") !== (n.license ?? "") ? { license_before: o.license, license_after: n.license } : {}),
+      };
+      const order = compareVersions(o.version, n.version);
+      if (order === undefined) diff.changed.push(change);
+      else if (order < 0) diff.upgraded.push(change);
+      else diff.downgraded.push(change);
+    }
+    diff.removed.push(...oldOnly.slice(pairs));
+    diff.added.push(...newOnly.slice(pairs));
+  }
+
+  const licenseSet = (components: readonly SbomComponent[]) => new Set(components.map((c) => c.license).filter((l): l is string => !!l));
+  const oldLicenses = licenseSet(base);
+  const newLicenses = licenseSet(target);
+  diff.licenses.introduced = [...newLicenses].filter((l) => !oldLicenses.has(l)).sort();
+  diff.licenses.dropped = [...oldLicenses].filter((l) => !newLicenses.has(l)).sort();
+
+  // Components that bring vulnerabilities the base did not have: new packages
+  // with any, and version changes that raised the count.
+  diff.vulnerabilities.introduced_by = [
+    ...diff.added.filter((c) => (c.vulnerabilities ?? 0) > 0).map((c) => ({ name: `${c.name}@${c.version ?? "?"}`, vulnerabilities: c.vulnerabilities! })),
+    ...[...diff.upgraded, ...diff.downgraded, ...diff.changed]
+      .filter((c) => (c.vulnerabilities_after ?? 0) > (c.vulnerabilities_before ?? 0))
+      .map((c) => ({ name: `${c.name}@${c.to ?? "?"}`, vulnerabilities: c.vulnerabilities_after! - (c.vulnerabilities_before ?? 0) })),
+  ].sort((a, b) => b.vulnerabilities - a.vulnerabilities);
+  return diff;
+}
@@ -0,0 +1,49 @@
+/**
+ * Fetch an SBOM document from the link the SCS sbom-download endpoint
+ * returns. Links on Harness hosts (and relative paths) go through the
+ * client so its auth headers are attached; pre-signed links to external
+ * storage are fetched directly, without credentials, so the API key is
+ * never sent outside Harness.
+ */
+import type { HarnessClient } from "../client/harness-client.js";
+
+/** SBOMs for large images run to tens of megabytes; refuse anything past this. */
+export const SBOM_DOWNLOAD_MAX_BYTES = 100 * 1024 * 1024;
+
+const SIGNATURE_PARAM = /signature|^sig$/i;
+
+function isHarnessHost(host: string): boolean {
+  const h = host.toLowerCase();
+  return h === "harness.io" || h.endsWith(".harness.io");
+}
+
+function tooLarge(bytes: number): Error {
+  return new Error(`The SBOM is ${Math.ceil(bytes / 1024 / 1024)} MB, over the ${SBOM_DOWNLOAD_MAX_BYTES / 1024 / 1024} MB download limit.`);
+}
+
+/** Download the document at `link` and return it as text, with the host it came from. */
+export async function fetchSbomDocument(
+  client: Pick<HarnessClient, "requestStream">,
+  link: string,
+  signal?: AbortSignal,
+): Promise<{ text: string; source: string }> {
+  let response: Response;
+  let source = "harness";
+  if (link.startsWith("/")) {
+    response = await client.requestStream({ method: "GET", path: link, signal });
+  } else {
+    const url = new URL(link);
+    source = url.hostname;
+    const presigned = [...url.searchParams.keys()].some((k) => SIGNATURE_PARAM.test(k));
+    response = isHarnessHost(url.hostname) && !presigned
+      ? await client.requestStream({ method: "GET", path: url.pathname + url.search, baseUrl: url.origin, signal })
+      : await fetch(link, { signal });
+  }
+  if (!response.ok) throw new Error(`SBOM download from ${source} failed with HTTP ${response.status}.`);
+  const length = Number(response.headers.get("content-length"));
+  if (length > SBOM_DOWNLOAD_MAX_BYTES) throw tooLarge(length);
+  const text = await response.text();
+  const bytes = Buffer.byteLength(text);
+  if (bytes > SBOM_DOWNLOAD_MAX_BYTES) throw tooLarge(bytes);
+  return { text, source };
+}
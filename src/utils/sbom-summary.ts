@@ -0,0 +1,189 @@
+/**
+ * Summaries of downloaded SBOM documents: format and spec version, the tool
+ * and time that produced them, how many components they list, and which
+ * licenses dominate. Handles CycloneDX (JSON and XML) and SPDX (JSON and
+ * tag-value), the formats Harness SBOM orchestration emits.
+ */
+import { isRecord } from "./type-guards.js";
+
+export interface SbomSummary {
+  format: "CycloneDX" | "SPDX";
+  encoding: "json" | "xml" | "tag-value";
+  spec_version?: string;
+  /** Document or root component name. */
+  name?: string;
+  created?: string;
+  tools: string[];
+  component_count: number;
+  licenses: {
+    distinct: number;
+    /** Components with no license, or only NOASSERTION/NONE. */
+    unlicensed: number;
+    top: Array<{ license: string; components: number }>;
+  };
+}
+
+/** Licenses listed in `licenses.top`. */
+export const SBOM_TOP_LICENSES = 10;
+
+const NO_LICENSE = new Set(["", "NOASSERTION", "NONE"]);
+
+const str = (v: unknown): string | undefined => (typeof v === "string" && v.trim() ? v.trim() : undefined);
+
+function licenseStats(perComponent: string[][]): SbomSummary["licenses"] {
+  const counts = new Map<string, number>();
+  let unlicensed = 0;
+  for (const licenses of perComponent) {
+    const known = [...new Set(licenses.filter((l) => !NO_LICENSE.has(l)))];
+    if (known.length === 0) unlicensed++;
+    for (const l of known) counts.set(l, (counts.get(l) ?? 0) + 1);
+  }
+  const top = [...counts.entries()]
+    .sort((a, b) => b[1] - a[1] || a[0].localeCompare(b[0]))
+    .slice(0, SBOM_TOP_LICENSES)
+    .map(([license, components]) => ({ license, components }));
+  return { distinct: counts.size, unlicensed, top };
+}
+
+function cycloneDxJson(doc: Record<string, unknown>): SbomSummary {
+  const metadata = isRecord(doc.metadata) ? doc.metadata : {};
+  const root = isRecord(metadata.component) ? metadata.component : {};
+  // CycloneDX 1.5 moved tools from an array to { components, services }.
+  const tools = Array.isArray(metadata.tools)
+    ? metadata.tools
+    : isRecord(metadata.tools) ? [...(Array.isArray(metadata.tools.components) ? metadata.tools.components : []), ...(Array.isArray(metadata.tools.services) ? metadata.tools.services : [])] : [];
+  const perComponent: string[][] = [];
+  const walk = (components: unknown) => {
+    if (!Array.isArray(components)) return;
+    for (const c of components) {
+      if (!isRecord(c)) continue;
+      const licenses = Array.isArray(c.licenses) ? c.licenses : [];
+      perComponent.push(licenses.flatMap((l) => {
+        if (!isRecord(l)) return [];
+        if (str(l.expression)) return [str(l.expression)!];
+        const license = isRecord(l.license) ? l.license : {};
+        return [str(license.id) ?? str(license.name) ?? ""];
+      }));
+      walk(c.components);
+    }
+  };
+  walk(doc.components);
+  const name = str(root.name);
+  return {
+    format: "CycloneDX",
+    encoding: "json",
+    ...(str(doc.specVersion) ? { spec_version: str(doc.specVersion) } : {}),
+    ...(name ? { name: str(root.version) ? `${name}@${str(root.version)}` : name } : {}),
+    ...(str(metadata.timestamp) ? { created: str(metadata.timestamp) } : {}),
+    tools: tools.filter(isRecord).map((t) => [str(t.name), str(t.version)].filter(Boolean).join(" ")).filter(Boolean),
+    component_count: perComponent.length,
+    licenses: licenseStats(perComponent),
+  };
+}
+
+function spdxJson(doc: Record<string, unknown>): SbomSummary {
+  const creation = isRecord(doc.creationInfo) ? doc.creationInfo : {};
+  const creators = Array.isArray(creation.creators) ? creation.creators : [];
+  const packages = Array.isArray(doc.packages) ? doc.packages.filter(isRecord) : [];
+  return {
+    format: "SPDX",
+    encoding: "json",
+    ...(str(doc.spdxVersion) ? { spec_version: str(doc.spdxVersion)!.replace(/^SPDX-/, "") } : {}),
+    ...(str(doc.name) ? { name: str(doc.name) } : {}),
+    ...(str(creation.created) ? { created: str(creation.created) } : {}),
+    tools: creators.map(str).filter((c): c is string => !!c?.startsWith("Tool:")).map((c) => c.slice("Tool:".length).trim()),
+    component_count: packages.length,
+    licenses: licenseStats(packages.map((p) => [str(p.licenseConcluded) ?? str(p.licenseDeclared) ?? ""])),
+  };
+}
+
+function spdxTagValue(text: string): SbomSummary {
+  let specVersion: string | undefined;
+  let name: string | undefined;
+  let created: string | undefined;
+  const tools: string[] = [];
+  const perComponent: string[][] = [];
+  let current: { concluded?: string; declared?: string } | undefined;
+  const flush = () => {
+    if (current) perComponent.push([current.concluded ?? current.declared ?? ""]);
+  };
+  for (const line of text.split(/\r?\n/)) {
+    const sep = line.indexOf(":");
+    if (sep === -1) continue;
+    const tag = line.slice(0, sep).trim();
+    const value = line.slice(sep + 1).trim();
+    if (tag === "SPDXVersion") specVersion = value.replace(/^SPDX-/, "");
+    else if (tag === "DocumentName") name = value;
+    else if (tag === "Created") created = value;
+    else if (tag === "Creator" && value.startsWith("Tool:")) tools.push(value.slice("Tool:".length).trim());
+    else if (tag === "PackageName") {
+      flush();
+      current = {};
+    } else if (current && tag === "PackageLicenseConcluded" && !NO_LICENSE.has(value)) current.concluded = value;
+    else if (current && tag === "PackageLicenseDeclared" && !NO_LICENSE.has(value)) current.declared = value;
+  }
+  flush();
+  return {
+    format: "SPDX",
+    encoding: "tag-value",
+    ...(specVersion ? { spec_version: specVersion } : {}),
+    ...(name ? { name } : {}),
+    ...(created ? { created } : {}),
+    tools,
+    component_count: perComponent.length,
+    licenses: licenseStats(perComponent),
+  };
+}
+
+function cycloneDxXml(text: string): SbomSummary {
+  const specVersion = /xmlns="http:\/\/cyclonedx\.org\/schema\/bom\/([\d.]+)"/.exec(text)?.[1];
+  const metadata = /<metadata>([\s\S]*?)<\/metadata>/.exec(text)?.[1] ?? "";
+  const body = text.replace(/<metadata>[\s\S]*?<\/metadata>/, "");
+  // Each <component> opening tag starts a component; its licenses are the
+  // <license> ids/names (or <expression>) before the next one opens.
+  const chunks = body.split(/<component[\s>]/).slice(1);
+  const perComponent = chunks.map((chunk) => [
+    ...[...chunk.matchAll(/<license>\s*<(?:id|name)>([^<]+)<\/(?:id|name)>/g)].map((m) => m[1]!.trim()),
+    ...[...chunk.matchAll(/<expression>([^<]+)<\/expression>/g)].map((m) => m[1]!.trim()),
+  ]);
+  const tools = [...metadata.matchAll(/<tool>[\s\S]*?<name>([^<]+)<\/name>(?:[\s\S]*?<version>([^<]+)<\/version>)?[\s\S]*?<\/tool>/g)]
+    .map((m) => [m[1]!.trim(), m[2]?.trim()].filter(Boolean).join(" "));
+  const created = /<timestamp>([^<]+)<\/timestamp>/.exec(metadata)?.[1];
+  return {
+    format: "CycloneDX",
+    encoding: "xml",
+    ...(specVersion ? { spec_version: specVersion } : {}),
+    ...(created ? { created: created.trim() } : {}),
+    tools,
+    component_count: perComponent.length,
+    licenses: licenseStats(perComponent),
+  };
+}
+
+/**
+ * Summarize an SBOM document, given as text or already-parsed JSON. Throws
+ * when the content is neither CycloneDX nor SPDX.
+ */
+export function summarizeSbom(content: string | Record<string, unknown>): SbomSummary {
+  let doc: unknown = content;
+  if (typeof content === "string") {
+    const text = content.replace(/^\uFEFF/, "").trimStart();
+    if (text.startsWith("{")) {
+      try {
+        doc = JSON.parse(text);
+      } catch {
+        throw new Error("The SBOM looks like JSON but does not parse.");
+      }
+    } else if (text.startsWith("<")) {
+      if (!/<bom[\s>]/.test(text)) throw new Error("The SBOM is XML but not a CycloneDX <bom> document.");
+      return cycloneDxXml(text);
+    } else if (/^SPDXVersion:/m.test(text)) {
+      return spdxTagValue(text);
+    } else {
+      throw new Error("The SBOM is neither CycloneDX nor SPDX (JSON, XML, or tag-value).");
+    }
+  }
+  if (isRecord(doc) && doc.bomFormat === "CycloneDX") return cycloneDxJson(doc);
+  if (isRecord(doc) && typeof doc.spdxVersion === "string") return spdxJson(doc);
+  throw new Error("The SBOM JSON has neither bomFormat 'CycloneDX' nor spdxVersion.");
+}
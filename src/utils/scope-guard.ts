@@ -0,0 +1,52 @@
+/**
+ * Scope-escalation guardrail.
+ *
+ * A session is pinned to the org/project it was configured with (HARNESS_ORG /
+ * HARNESS_PROJECT, or the X-Harness-Org / X-Harness-Project headers in HTTP
+ * mode). Agents that pass a different org_id / project_id are reaching outside
+ * that scope — usually by accident. Callers opt in explicitly with
+ * `cross_scope: true`.
+ */
+import type { ResourceScope } from "../registry/types.js";
+
+export type ScopeGuardMode = "off" | "warn" | "block";
+
+export interface ScopeEscalation {
+  field: "org_id" | "project_id";
+  requested: string;
+  pinned: string;
+}
+
+/** True when the caller explicitly allowed a cross-scope request. */
+export function isCrossScopeAllowed(input: Record<string, unknown>): boolean {
+  return input.cross_scope === true || input.cross_scope === "true";
+}
+
+/**
+ * Compare the org/project a request will use against the pinned scope.
+ * Only identifiers the request actually sends are checked: org for org- and
+ * project-scoped requests, project for project-scoped requests. Unpinned
+ * fields never escalate.
+ */
+export function detectScopeEscalation(
+  scope: ResourceScope,
+  input: Record<string, unknown>,
+  pinned: { org?: string; project?: string },
+): ScopeEscalation[] {
+  const effectiveScope = (input.resource_scope as ResourceScope | undefined) ?? scope;
+  const escalations: ScopeEscalation[] = [];
+  const check = (field: ScopeEscalation["field"], pinnedValue: string | undefined): void => {
+    const requested = input[field];
+    if (!pinnedValue || typeof requested !== "string" || requested === "" || requested === pinnedValue) return;
+    escalations.push({ field, requested, pinned: pinnedValue });
+  };
+  if (effectiveScope === "org" || effectiveScope === "project") check("org_id", pinned.org);
+  if (effectiveScope === "project") check("project_id", pinned.project);
+  return escalations;
+}
+
+/** One-line description of the escalation for errors, warnings, and audit rows. */
+export function describeScopeEscalation(escalations: ScopeEscalation[]): string {
+  const parts = escalations.map((e) => `${e.field} "${e.requested}" (session is pinned to "${e.pinned}")`);
+  return `Request targets ${parts.join(" and ")}. Pass cross_scope: true in params to confirm the cross-scope request.`;
+}
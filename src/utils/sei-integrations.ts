@@ -0,0 +1,88 @@
+/**
+ * Health of SEI integrations: whether each one is ingesting. Metric questions
+ * return empty data when the Jira or SCM integration behind them has stopped
+ * ingesting, so this is the first thing to check when numbers look wrong.
+ */
+import { isRecord } from "./type-guards.js";
+
+/** An integration with no ingestion for this long is stale. */
+export const STALE_INGESTION_HOURS = 24;
+
+const HOUR_MS = 60 * 60 * 1000;
+const FAILED_STATUS = /fail|error|unhealthy|broken|invalid|expired|disconnected/i;
+const LIST_KEYS = ["integrations", "content", "items", "records", "data"] as const;
+
+export interface IntegrationHealth {
+  integration_id: string;
+  name?: string;
+  type?: string;
+  status?: string;
+  last_ingested_at?: string;
+  hours_since_ingestion?: number;
+  error_count?: number;
+  last_error?: string;
+  health: "healthy" | "failing" | "stale" | "unknown";
+}
+
+const text = (v: unknown): string | undefined => (typeof v === "string" && v.trim() ? v.trim() : typeof v === "number" ? String(v) : undefined);
+
+function pick(row: Record<string, unknown>, keys: readonly string[]): unknown {
+  for (const key of keys) if (row[key] !== undefined && row[key] !== null) return row[key];
+  return undefined;
+}
+
+/** Epoch seconds, epoch milliseconds, or a date string, as milliseconds. */
+function epochMs(v: unknown): number | undefined {
+  if (typeof v === "number" && Number.isFinite(v) && v > 0) return v < 1e11 ? v * 1000 : v;
+  if (typeof v === "string" && v.trim()) {
+    const ms = /^\d+$/.test(v) ? epochMs(Number(v)) : Date.parse(v);
+    return ms !== undefined && Number.isFinite(ms) ? ms : undefined;
+  }
+  return undefined;
+}
+
+/** The integration records in an SEI integrations response. */
+export function integrationRows(raw: unknown): Array<Record<string, unknown>> {
+  if (Array.isArray(raw)) return raw.filter(isRecord);
+  if (!isRecord(raw)) return [];
+  for (const key of LIST_KEYS) {
+    const value = raw[key];
+    if (Array.isArray(value)) return value.filter(isRecord);
+    if (isRecord(value)) return integrationRows(value);
+  }
+  return [];
+}
+
+/**
+ * One integration's health. Failing when its status or recent errors say so,
+ * stale when the last ingestion is older than STALE_INGESTION_HOURS, unknown
+ * when SEI reports neither status nor an ingestion time.
+ */
+export function integrationHealth(row: Record<string, unknown>, now = Date.now()): IntegrationHealth {
+  const ingestion = isRecord(row.ingestionStatus) ? row.ingestionStatus : isRecord(row.health) ? row.health : {};
+  const status = text(pick(row, ["status", "state", "integrationStatus"])) ?? text(pick(ingestion, ["status", "state"]));
+  const last = epochMs(pick(row, ["lastIngestedAt", "lastIngestionTime", "lastSyncedAt", "lastSuccessfulIngestion"]) ?? pick(ingestion, ["lastIngestedAt", "lastIngestionTime", "lastSuccessAt"]));
+  const errors = pick(row, ["errorCount", "errors", "failedIngestions", "failureCount"]) ?? pick(ingestion, ["errorCount", "failures"]);
+  const errorCount = Array.isArray(errors) ? errors.length : Number(errors);
+  const lastError = text(pick(row, ["lastError", "lastErrorMessage", "errorMessage"]) ?? pick(ingestion, ["lastError", "message"]));
+  const hours = last !== undefined ? Math.max(0, Math.round(((now - last) / HOUR_MS) * 10) / 10) : undefined;
+
+  // An error count alone may be history; it marks the integration failing only with an error to show.
+  const failing = (status !== undefined && FAILED_STATUS.test(status)) || (lastError !== undefined && errorCount > 0);
+  const health = failing
+    ? "failing"
+    : hours !== undefined && hours > STALE_INGESTION_HOURS
+      ? "stale"
+      : hours !== undefined || status !== undefined ? "healthy" : "unknown";
+
+  return {
+    integration_id: text(pick(row, ["id", "integrationId", "identifier"])) ?? "unknown",
+    ...(text(row.name) ? { name: text(row.name) } : {}),
+    ...(text(pick(row, ["application", "type", "integrationType"])) ? { type: text(pick(row, ["application", "type", "integrationType"])) } : {}),
+    ...(status ? { status } : {}),
+    ...(last !== undefined ? { last_ingested_at: new Date(last).toISOString(), hours_since_ingestion: hours } : {}),
+    ...(Number.isFinite(errorCount) ? { error_count: errorCount } : {}),
+    ...(lastError ? { last_error: lastError } : {}),
+    health,
+  };
+}
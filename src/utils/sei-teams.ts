@@ -0,0 +1,85 @@
+/**
+ * SEI team discovery: flatten the team list or an org tree's team hierarchy
+ * into rows carrying the team_ref_id the insight endpoints expect, and match
+ * them against a name so "the payments team" resolves to a refId.
+ */
+import { isRecord } from "./type-guards.js";
+
+export interface SeiTeamRow {
+  team_ref_id: string;
+  name: string;
+  /** Names of the enclosing teams, outermost first, when read from a hierarchy. */
+  path?: string[];
+  parent_ref_id?: string;
+  leaf?: boolean;
+}
+
+const CHILD_KEYS = ["children", "childTeams", "subTeams", "teams"] as const;
+const LIST_KEYS = ["teams", "content", "items", "data", "records"] as const;
+
+const text = (v: unknown): string | undefined => (typeof v === "string" && v.trim() ? v.trim() : typeof v === "number" ? String(v) : undefined);
+
+function teamRefId(row: Record<string, unknown>): string | undefined {
+  return text(row.refId) ?? text(row.teamRefId) ?? text(row.ref_id) ?? text(row.id);
+}
+
+function children(row: Record<string, unknown>): Array<Record<string, unknown>> {
+  for (const key of CHILD_KEYS) {
+    const value = row[key];
+    if (Array.isArray(value)) return value.filter(isRecord);
+  }
+  return [];
+}
+
+/** The top-level team records in an SEI teams or org-tree response. */
+function rootRows(raw: unknown): Array<Record<string, unknown>> {
+  if (Array.isArray(raw)) return raw.filter(isRecord);
+  if (!isRecord(raw)) return [];
+  for (const key of LIST_KEYS) {
+    const value = raw[key];
+    if (Array.isArray(value)) return value.filter(isRecord);
+    if (isRecord(value)) return rootRows(value);
+  }
+  // A single root team, as org-tree hierarchies are returned.
+  return teamRefId(raw) ? [raw] : [];
+}
+
+/** Every team in the response, depth-first, with the names of its ancestors. */
+export function flattenTeams(raw: unknown): SeiTeamRow[] {
+  const rows: SeiTeamRow[] = [];
+  const seen = new Set<string>();
+  const visit = (row: Record<string, unknown>, path: string[], parent?: string) => {
+    const refId = teamRefId(row);
+    const name = text(row.name) ?? text(row.teamName) ?? refId;
+    const kids = children(row);
+    if (refId && name && !seen.has(refId)) {
+      seen.add(refId);
+      const parentRefId = parent ?? text(row.parentRefId) ?? text(row.parentTeamRefId);
+      rows.push({
+        team_ref_id: refId,
+        name,
+        ...(path.length > 0 ? { path } : {}),
+        ...(parentRefId ? { parent_ref_id: parentRefId } : {}),
+        ...(typeof row.leaf === "boolean" ? { leaf: row.leaf } : kids.length > 0 ? { leaf: false } : {}),
+      });
+    }
+    for (const kid of kids) visit(kid, name ? [...path, name] : path, refId);
+  };
+  for (const row of rootRows(raw)) visit(row, []);
+  return rows;
+}
+
+/**
+ * Teams whose name, or ancestor path, contains every word of `search`
+ * (case-insensitive). Exact name matches come first.
+ */
+export function matchTeams(teams: readonly SeiTeamRow[], search: string): SeiTeamRow[] {
+  const words = search.toLowerCase().split(/\s+/).filter(Boolean);
+  if (words.length === 0) return [...teams];
+  const wanted = search.trim().toLowerCase();
+  const matches = teams.filter((t) => {
+    const haystack = [...(t.path ?? []), t.name, t.team_ref_id].join(" ").toLowerCase();
+    return words.every((w) => haystack.includes(w));
+  });
+  return matches.sort((a, b) => Number(b.name.toLowerCase() === wanted) - Number(a.name.toLowerCase() === wanted));
+}
@@ -3,6 +3,7 @@ import type { Config } from "../config.js";
 import { extractAccountIdFromToken } from "../config.js";
 import { RISK_SEVERITY, type RiskLevel } from "../registry/types.js";
 import { createLogger } from "./logger.js";
+import type { OAuthPrincipal } from "./http-oauth.js";
 
 const log = createLogger("session-headers");
 
@@ -79,9 +80,17 @@ export class MissingSessionCredentialsError extends Error {
   }
 }
 
+/**
+ * Build the per-session config from `initialize` request headers.
+ *
+ * In multi-user mode an OAuth `principal` stands in for the identity headers:
+ * its access token is forwarded to Harness as a bearer and its account claim
+ * supplies the account ID. Explicit x-harness-api-key headers still win.
+ */
 export function mergeConfigWithSessionHeaders(
   baseConfig: Config,
   headers: IncomingHttpHeaders,
+  principal?: OAuthPrincipal,
 ): Config {
   const pipelineVersion = parsePipelineVersionHeader(headers);
   const autoApproveRisk = parseAutoApproveRiskHeader(headers);
@@ -90,9 +99,13 @@ export function mergeConfigWithSessionHeaders(
 
   // Identity headers are only accepted in multi-user mode.
   // In single-user mode, the operator's config is authoritative.
-  const sessionApiKey = isMultiUser ? getHeader(headers, API_KEY_HEADER) : undefined;
+  const headerApiKey = isMultiUser ? getHeader(headers, API_KEY_HEADER) : undefined;
+  const oauthPrincipal = isMultiUser && !headerApiKey ? principal : undefined;
+  const sessionApiKey = headerApiKey ?? oauthPrincipal?.token;
   const rawSessionAccountId = isMultiUser ? getHeader(headers, ACCOUNT_ID_HEADER) : undefined;
-  const tokenAccountId = sessionApiKey ? extractAccountIdFromToken(sessionApiKey) : undefined;
+  const tokenAccountId = headerApiKey
+    ? extractAccountIdFromToken(headerApiKey)
+    : oauthPrincipal?.accountId;
   const sessionAccountId = rawSessionAccountId ?? tokenAccountId;
   const sessionOrg = getHeader(headers, ORG_HEADER);
   const sessionProject = getHeader(headers, PROJECT_HEADER);
@@ -129,6 +142,7 @@ export function mergeConfigWithSessionHeaders(
     ...(pipelineVersion !== undefined ? { HARNESS_PIPELINE_VERSION: pipelineVersion } : {}),
     ...(cappedAutoApproveRisk !== undefined ? { HARNESS_AUTO_APPROVE_RISK: cappedAutoApproveRisk } : {}),
     ...(sessionApiKey !== undefined ? { HARNESS_API_KEY: sessionApiKey } : {}),
+    ...(oauthPrincipal ? { HARNESS_API_AUTH_SCHEME: "bearer" as const } : {}),
     ...(sessionAccountId !== undefined ? { HARNESS_ACCOUNT_ID: sessionAccountId } : {}),
     ...(sessionOrg !== undefined ? { HARNESS_ORG: sessionOrg } : {}),
     ...(sessionProject !== undefined ? { HARNESS_PROJECT: sessionProject } : {}),
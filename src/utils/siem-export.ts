@@ -0,0 +1,213 @@
+/**
+ * SIEM formatters for Harness platform audit events.
+ *
+ * Maps NG audit events (`/audit/api/audits/list`) to OCSF API Activity JSON
+ * (class 6003) or ArcSight CEF lines, and writes an exported page to disk as
+ * NDJSON / one CEF line per event. No external dependencies.
+ */
+import { join } from "node:path";
+import { resolveToolOutputDir, writeOutputFile } from "./output-paths.js";
+import { isRecord } from "./type-guards.js";
+
+export type SiemFormat = "ocsf" | "cef";
+
+export const SIEM_FORMATS: readonly SiemFormat[] = ["ocsf", "cef"];
+
+const OCSF_VERSION = "1.1.0";
+const OCSF_API_ACTIVITY_CLASS = 6003;
+const OCSF_APPLICATION_ACTIVITY_CATEGORY = 6;
+
+/** OCSF API Activity activity_id by Harness audit action. Anything else maps to 99 (Other). */
+const OCSF_ACTIVITY_IDS: Record<string, number> = {
+  CREATE: 1,
+  UPSERT: 1,
+  CREATE_TOKEN: 1,
+  UPDATE: 3,
+  RESTORE: 3,
+  ENABLED: 3,
+  DISABLED: 3,
+  DELETE: 4,
+  FORCE_DELETE: 4,
+  REVOKE_TOKEN: 4,
+};
+
+/** Actions reported as failures (OCSF status_id 2, CEF outcome=failure) with raised severity. */
+const FAILURE_ACTIONS = new Set(["UNSUCCESSFUL_LOGIN"]);
+
+/** Security-relevant actions raised above informational severity. */
+const ELEVATED_ACTIONS = new Set([
+  "UNSUCCESSFUL_LOGIN",
+  "FORCE_DELETE",
+  "BYPASS",
+  "ROLE_ASSIGNMENT_CREATED",
+  "ROLE_ASSIGNMENT_UPDATED",
+  "ROLE_ASSIGNMENT_DELETED",
+  "CREATE_TOKEN",
+  "REVOKE_TOKEN",
+]);
+
+/** Normalized view of the NG audit event fields the formatters use. */
+interface AuditFields {
+  id: string;
+  time: number;
+  action: string;
+  module: string;
+  actorType: string;
+  actorId: string;
+  actorName?: string;
+  actorEmail?: string;
+  clientIp?: string;
+  httpMethod?: string;
+  resourceType: string;
+  resourceId: string;
+  resourceName?: string;
+  account?: string;
+  org?: string;
+  project?: string;
+}
+
+function str(value: unknown): string | undefined {
+  return typeof value === "string" && value ? value : undefined;
+}
+
+function readAuditFields(event: Record<string, unknown>): AuditFields {
+  const auth = isRecord(event.authenticationInfo) ? event.authenticationInfo : {};
+  const principal = isRecord(auth.principal) ? auth.principal : {};
+  const authLabels = isRecord(auth.labels) ? auth.labels : {};
+  const resource = isRecord(event.resource) ? event.resource : {};
+  const resourceLabels = isRecord(resource.labels) ? resource.labels : {};
+  const scope = isRecord(event.resourceScope) ? event.resourceScope : {};
+  const request = isRecord(event.requestMetadata) ? event.requestMetadata : {};
+  const http = isRecord(event.httpRequestInfo) ? event.httpRequestInfo : {};
+  return {
+    id: str(event.auditId) ?? str(event.insertId) ?? "",
+    time: typeof event.timestamp === "number" ? event.timestamp : 0,
+    action: str(event.action) ?? "UNKNOWN",
+    module: str(event.module) ?? "CORE",
+    actorType: str(principal.type) ?? "USER",
+    actorId: str(principal.identifier) ?? "",
+    actorName: str(authLabels.username),
+    actorEmail: str(principal.email),
+    clientIp: str(request.clientIP),
+    httpMethod: str(http.requestMethod),
+    resourceType: str(resource.type) ?? "UNKNOWN",
+    resourceId: str(resource.identifier) ?? "",
+    resourceName: str(resourceLabels.resourceName),
+    account: str(scope.accountIdentifier),
+    org: str(scope.orgIdentifier),
+    project: str(scope.projectIdentifier),
+  };
+}
+
+/** Map a Harness NG audit event to an OCSF API Activity (6003) record. */
+export function toOcsfApiActivity(event: Record<string, unknown>): Record<string, unknown> {
+  const f = readAuditFields(event);
+  const activityId = OCSF_ACTIVITY_IDS[f.action] ?? 99;
+  const failed = FAILURE_ACTIONS.has(f.action);
+  return {
+    metadata: {
+      version: OCSF_VERSION,
+      product: { name: "Harness Platform", vendor_name: "Harness" },
+      uid: f.id,
+      original_time: new Date(f.time).toISOString(),
+    },
+    time: f.time,
+    category_uid: OCSF_APPLICATION_ACTIVITY_CATEGORY,
+    category_name: "Application Activity",
+    class_uid: OCSF_API_ACTIVITY_CLASS,
+    class_name: "API Activity",
+    activity_id: activityId,
+    activity_name: f.action,
+    type_uid: OCSF_API_ACTIVITY_CLASS * 100 + activityId,
+    severity_id: ELEVATED_ACTIONS.has(f.action) ? 3 : 1,
+    status_id: failed ? 2 : 1,
+    status: failed ? "Failure" : "Success",
+    actor: {
+      user: {
+        uid: f.actorId,
+        type: f.actorType,
+        ...(f.actorName ? { name: f.actorName } : {}),
+        ...(f.actorEmail ? { email_addr: f.actorEmail } : {}),
+      },
+    },
+    api: {
+      operation: f.action,
+      service: { name: f.module },
+      ...(f.httpMethod ? { request: { method: f.httpMethod } } : {}),
+    },
+    ...(f.clientIp ? { src_endpoint: { ip: f.clientIp } } : {}),
+    resources: [{
+      type: f.resourceType,
+      uid: f.resourceId,
+      ...(f.resourceName ? { name: f.resourceName } : {}),
+    }],
+    unmapped: {
+      account_id: f.account,
+      org_id: f.org,
+      project_id: f.project,
+    },
+  };
+}
+
+function cefHeader(value: string): string {
+  return value.replace(/\\/g, "\\\\").replace(/\|/g, "\\|");
+}
+
+function cefExtension(value: string): string {
+  return value.replace(/\\/g, "\\\\").replace(/=/g, "\\=").replace(/\r?\n/g, "\\n");
+}
+
+/** Map a Harness NG audit event to a single CEF:0 line. */
+export function toCefLine(event: Record<string, unknown>): string {
+  const f = readAuditFields(event);
+  const severity = FAILURE_ACTIONS.has(f.action) ? 7 : ELEVATED_ACTIONS.has(f.action) ? 5 : 3;
+  const scope = [f.account, f.org, f.project].filter(Boolean).join("/");
+  const ext: Array<[string, string | number | undefined]> = [
+    ["rt", f.time],
+    ["externalId", f.id],
+    ["act", f.action],
+    ["suser", f.actorName ?? f.actorId],
+    ["suid", f.actorId],
+    ["src", f.clientIp],
+    ["requestMethod", f.httpMethod],
+    ["outcome", FAILURE_ACTIONS.has(f.action) ? "failure" : "success"],
+    ["cs1Label", "resourceType"],
+    ["cs1", f.resourceType],
+    ["cs2Label", "resourceIdentifier"],
+    ["cs2", f.resourceId],
+    ["cs3Label", "module"],
+    ["cs3", f.module],
+    ["cs4Label", "scope"],
+    ["cs4", scope || undefined],
+  ];
+  const extension = ext
+    .filter(([, value]) => value !== undefined && value !== "")
+    .map(([key, value]) => `${key}=${cefExtension(String(value))}`)
+    .join(" ");
+  const name = `${f.action} ${f.resourceType}`;
+  return `CEF:0|Harness|Harness Platform|1.0|${cefHeader(f.action)}|${cefHeader(name)}|${severity}|${extension}`;
+}
+
+/** Format audit events for the requested SIEM format. Non-object items are skipped. */
+export function formatSiemRecords(events: unknown[], format: SiemFormat): Array<Record<string, unknown> | string> {
+  const records = events.filter(isRecord);
+  return format === "cef" ? records.map(toCefLine) : records.map(toOcsfApiActivity);
+}
+
+/**
+ * Write one exported page to `outputDir` — NDJSON for OCSF, one line per
+ * event for CEF. Returns the absolute file path written.
+ */
+export function writeSiemExport(
+  outputDir: string,
+  format: SiemFormat,
+  window: { startTime: number; endTime: number; page: number },
+  records: Array<Record<string, unknown> | string>,
+): string {
+  const dir = resolveToolOutputDir(outputDir);
+  const ext = format === "cef" ? "cef" : "ocsf.jsonl";
+  const file = join(dir, `harness-audit-${window.startTime}-${window.endTime}-p${window.page}.${ext}`);
+  const lines = records.map((record) => (typeof record === "string" ? record : JSON.stringify(record)));
+  writeOutputFile(file, lines.length > 0 ? lines.join("\n") + "\n" : "");
+  return file;
+}
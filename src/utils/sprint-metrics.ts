@@ -0,0 +1,206 @@
+/**
+ * Sprint analytics over SEI business-alignment sprint data: velocity trend
+ * and carry-over across a team's sprints, and burndown progress within one.
+ *
+ * SEI reports work in story points when the team estimates and in issue
+ * counts when it does not, under a few different field names; each number is
+ * read from whichever known field is present.
+ */
+import { isRecord } from "./type-guards.js";
+
+export interface SprintStats {
+  sprint: string;
+  start?: string;
+  end?: string;
+  committed?: number;
+  completed?: number;
+  carried_over?: number;
+  /** Work added to the sprint after it started. */
+  added?: number;
+}
+
+export interface BurndownPoint {
+  date: string;
+  remaining: number;
+}
+
+/** Relative change per sprint, as a fraction of mean velocity, below which velocity counts as flat. */
+export const VELOCITY_FLAT_THRESHOLD = 0.05;
+/** Velocity trends need at least this many completed sprints. */
+export const VELOCITY_MIN_SPRINTS = 3;
+/** Carry-over above this share of committed work, in percent, marks a sprint as overcommitted. */
+export const CARRY_OVER_ALERT_PCT = 20;
+
+const MAX_DEPTH = 4;
+
+const num = (row: Record<string, unknown>, ...keys: string[]): number | undefined => {
+  for (const key of keys) {
+    const n = typeof row[key] === "string" ? Number(row[key]) : row[key];
+    if (typeof n === "number" && Number.isFinite(n)) return n;
+  }
+  return undefined;
+};
+
+const str = (row: Record<string, unknown>, ...keys: string[]): string | undefined => {
+  for (const key of keys) {
+    const v = row[key];
+    if (typeof v === "string" && v.trim()) return v.trim();
+    if (typeof v === "number") return String(v);
+  }
+  return undefined;
+};
+
+/** Epoch milliseconds from an ISO date or a numeric timestamp string. */
+const when = (date: string | undefined): number => (date !== undefined && /^\d+$/.test(date) ? Number(date) : Date.parse(date ?? ""));
+
+const round = (n: number, places = 1) => Math.round(n * 10 ** places) / 10 ** places;
+
+/** The first array of records in an SEI response that satisfies `match`, searched breadth-first. */
+function findRows(data: unknown, match: (row: Record<string, unknown>) => boolean): Array<Record<string, unknown>> {
+  let level: unknown[] = [data];
+  for (let depth = 0; depth <= MAX_DEPTH && level.length > 0; depth++) {
+    const next: unknown[] = [];
+    for (const node of level) {
+      if (Array.isArray(node) && node.length > 0 && node.every(isRecord) && node.some(match)) return node;
+      if (Array.isArray(node)) next.push(...node);
+      else if (isRecord(node)) next.push(...Object.values(node));
+    }
+    level = next;
+  }
+  return [];
+}
+
+/** Per-sprint rows from an SEI sprint metrics response, oldest sprint first. */
+export function sprintStats(raw: unknown): SprintStats[] {
+  const rows = findRows(raw, (row) => str(row, "sprintName", "sprint", "name", "sprintId") !== undefined
+    && (num(row, "committed", "committedPoints", "committedStoryPoints", "committedIssues") !== undefined
+      || num(row, "completed", "completedPoints", "completedStoryPoints", "completedIssues", "delivered") !== undefined));
+  const stats = rows.map((row): SprintStats => {
+    const committed = num(row, "committed", "committedPoints", "committedStoryPoints", "committedIssues");
+    const completed = num(row, "completed", "completedPoints", "completedStoryPoints", "completedIssues", "delivered");
+    const carried = num(row, "carriedOver", "carryOver", "carryOverPoints", "carriedOverPoints", "spillover", "incomplete");
+    const added = num(row, "added", "addedPoints", "scopeAdded", "scopeCreep");
+    const start = str(row, "startDate", "start", "sprintStartDate");
+    const end = str(row, "endDate", "end", "completeDate", "sprintEndDate");
+    return {
+      sprint: str(row, "sprintName", "sprint", "name", "sprintId") ?? "unknown",
+      ...(start ? { start } : {}),
+      ...(end ? { end } : {}),
+      ...(committed !== undefined ? { committed } : {}),
+      ...(completed !== undefined ? { completed } : {}),
+      // Work not completed was carried over unless SEI says otherwise.
+      ...(carried !== undefined
+        ? { carried_over: carried }
+        : committed !== undefined && completed !== undefined ? { carried_over: Math.max(0, committed + (added ?? 0) - completed) } : {}),
+      ...(added !== undefined ? { added } : {}),
+    };
+  });
+  const time = (s: SprintStats) => when(s.start ?? s.end);
+  return stats.every((s) => Number.isFinite(time(s))) ? stats.sort((a, b) => time(a) - time(b)) : stats;
+}
+
+/**
+ * Velocity per sprint and its trend: the least-squares slope of completed
+ * work across sprints, relative to the mean. Rising or falling needs a change
+ * of more than VELOCITY_FLAT_THRESHOLD of the mean per sprint.
+ */
+export function velocityTrend(sprints: readonly SprintStats[]): Record<string, unknown> {
+  const done = sprints.filter((s) => s.completed !== undefined);
+  const values = done.map((s) => s.completed!);
+  const mean = values.length > 0 ? values.reduce((a, b) => a + b, 0) / values.length : 0;
+  const ratios = done.filter((s) => s.committed).map((s) => s.completed! / s.committed!);
+
+  let trend = "insufficient_data";
+  let slope: number | undefined;
+  if (values.length >= VELOCITY_MIN_SPRINTS) {
+    const xMean = (values.length - 1) / 2;
+    const cov = values.reduce((acc, y, x) => acc + (x - xMean) * (y - mean), 0);
+    const varX = values.reduce((acc, _, x) => acc + (x - xMean) ** 2, 0);
+    slope = cov / varX;
+    const relative = mean === 0 ? 0 : slope / mean;
+    trend = relative > VELOCITY_FLAT_THRESHOLD ? "rising" : relative < -VELOCITY_FLAT_THRESHOLD ? "falling" : "flat";
+  }
+
+  return {
+    sprints: done.map((s) => ({
+      sprint: s.sprint,
+      ...(s.end ? { end: s.end } : {}),
+      ...(s.committed !== undefined ? { committed: s.committed } : {}),
+      completed: s.completed,
+      ...(s.committed ? { say_do_pct: round((s.completed! / s.committed) * 100) } : {}),
+    })),
+    average_velocity: round(mean),
+    ...(ratios.length > 0 ? { average_say_do_pct: round((ratios.reduce((a, b) => a + b, 0) / ratios.length) * 100) } : {}),
+    trend,
+    ...(slope !== undefined ? { change_per_sprint: round(slope) } : {}),
+  };
+}
+
+/**
+ * Carry-over per sprint as a share of committed work, with the sprints above
+ * CARRY_OVER_ALERT_PCT flagged as overcommitted.
+ */
+export function carryOverAnalysis(sprints: readonly SprintStats[]): Record<string, unknown> {
+  const rows = sprints.filter((s) => s.carried_over !== undefined).map((s) => {
+    const planned = (s.committed ?? 0) + (s.added ?? 0);
+    const pct = planned > 0 ? round((s.carried_over! / planned) * 100) : undefined;
+    return {
+      sprint: s.sprint,
+      ...(s.committed !== undefined ? { committed: s.committed } : {}),
+      ...(s.added !== undefined ? { added: s.added } : {}),
+      carried_over: s.carried_over!,
+      ...(pct !== undefined ? { carry_over_pct: pct } : {}),
+      ...(pct !== undefined && pct > CARRY_OVER_ALERT_PCT ? { overcommitted: true } : {}),
+    };
+  });
+  const pcts = rows.flatMap((r) => (r.carry_over_pct !== undefined ? [r.carry_over_pct] : []));
+  const overcommitted = rows.filter((r) => r.overcommitted).length;
+  return {
+    sprints: rows,
+    total_carried_over: rows.reduce((acc, r) => acc + r.carried_over, 0),
+    ...(pcts.length > 0 ? { average_carry_over_pct: round(pcts.reduce((a, b) => a + b, 0) / pcts.length) } : {}),
+    overcommitted_sprints: overcommitted,
+    // Carry-over in most sprints points at planning rather than one bad sprint.
+    ...(rows.length >= VELOCITY_MIN_SPRINTS && overcommitted * 2 > rows.length ? { chronic: true } : {}),
+  };
+}
+
+/** Daily remaining-work points from an SEI burndown response, in date order. */
+export function burndownPoints(raw: unknown): BurndownPoint[] {
+  const rows = findRows(raw, (row) => str(row, "date", "day", "timestamp") !== undefined
+    && num(row, "remaining", "remainingPoints", "remainingWork", "remainingIssues") !== undefined);
+  return rows.flatMap((row) => {
+    const date = str(row, "date", "day", "timestamp");
+    const remaining = num(row, "remaining", "remainingPoints", "remainingWork", "remainingIssues");
+    return date !== undefined && remaining !== undefined ? [{ date, remaining }] : [];
+  }).sort((a, b) => when(a.date) - when(b.date));
+}
+
+/**
+ * Burndown progress: the remaining work at the latest point against an ideal
+ * straight line from the starting scope to zero at sprint end. Within 10% of
+ * the starting scope counts as on track.
+ */
+export function burndownSummary(points: readonly BurndownPoint[], sprintEnd?: string): Record<string, unknown> {
+  const first = points[0];
+  const last = points[points.length - 1];
+  if (!first || !last) return { points: [], status: "no_data" };
+  const start = when(first.date);
+  const end = when(sprintEnd ?? last.date);
+  const span = end - start;
+  const elapsed = span > 0 ? Math.min(1, (when(last.date) - start) / span) : 1;
+  const ideal = first.remaining * (1 - elapsed);
+  const gap = last.remaining - ideal;
+  const tolerance = first.remaining * 0.1;
+  const peak = Math.max(...points.map((p) => p.remaining));
+  return {
+    points,
+    starting_scope: first.remaining,
+    remaining: last.remaining,
+    burned: round(Math.max(0, peak - last.remaining)),
+    ...(peak > first.remaining ? { scope_added: round(peak - first.remaining) } : {}),
+    elapsed_pct: round(elapsed * 100),
+    ideal_remaining: round(ideal),
+    status: last.remaining === 0 ? "done" : gap > tolerance ? "behind" : gap < -tolerance ? "ahead" : "on_track",
+  };
+}
@@ -0,0 +1,59 @@
+/**
+ * Shaping for STO issue details. Scanner output reaches STO in many shapes,
+ * so reference IDs, remediation text, and occurrence locations are read from
+ * whichever of the known fields are present.
+ */
+import { isRecord } from "./type-guards.js";
+
+/** Occurrences listed in an issue detail; the count covers all of them. */
+export const STO_ISSUE_MAX_OCCURRENCES = 25;
+
+const str = (v: unknown): string | undefined => (typeof v === "string" && v.trim() ? v.trim() : typeof v === "number" ? String(v) : undefined);
+
+/**
+ * Reference IDs as conventional strings: { type: "cve", id: "2021-44228" }
+ * becomes CVE-2021-44228, and IDs already carrying their prefix are kept.
+ */
+export function stoReferenceIds(refs: unknown): string[] {
+  if (!Array.isArray(refs)) return [];
+  const ids = refs.flatMap((ref) => {
+    if (typeof ref === "string") return [ref.trim()];
+    if (!isRecord(ref)) return [];
+    const id = str(ref.id) ?? str(ref.value);
+    if (!id) return [];
+    const type = str(ref.type)?.toUpperCase();
+    return [type && !id.toUpperCase().startsWith(`${type}-`) ? `${type}-${id}` : id];
+  });
+  return [...new Set(ids.filter(Boolean))];
+}
+
+/** Remediation guidance from the issue, or undefined when the scanner gave none. */
+export function stoRemediation(issue: Record<string, unknown>): string | undefined {
+  const details = isRecord(issue.details) ? issue.details : {};
+  for (const candidate of [issue.remediation, issue.remediationSteps, details.remediation, details.recommendation, issue.recommendation]) {
+    if (Array.isArray(candidate)) {
+      const text = candidate.map(str).filter(Boolean).join("\n");
+      if (text) return text;
+    }
+    const text = str(candidate);
+    if (text) return text;
+  }
+  return undefined;
+}
+
+/** One occurrence reduced to where it is: file and line, or component and version, plus the target. */
+export function stoOccurrence(row: Record<string, unknown>): Record<string, unknown> {
+  const location = [str(row.fileName) ?? str(row.file) ?? str(row.path), str(row.lineNumber) ?? str(row.line)].filter(Boolean).join(":");
+  const component = str(row.componentName) ?? str(row.packageName) ?? str(row.component);
+  const version = str(row.currentVersion) ?? str(row.version);
+  const fixed = str(row.upgradeVersion) ?? str(row.fixVersion) ?? str(row.fixedVersion);
+  return {
+    ...(str(row.id) ? { id: str(row.id) } : {}),
+    ...(location ? { location } : {}),
+    ...(component ? { component: version ? `${component}@${version}` : component } : {}),
+    ...(fixed ? { fixed_in: fixed } : {}),
+    ...(str(row.targetName) ? { target: str(row.targetName) } : {}),
+    ...(str(row.targetVariantName) ? { variant: str(row.targetVariantName) } : {}),
+    ...(str(row.exemptionStatus) ? { exemption_status: str(row.exemptionStatus) } : {}),
+  };
+}
@@ -0,0 +1,319 @@
+/**
+ * Support bundles: one archive with what Harness support asks for first.
+ *
+ * A bundle holds version info, the effective configuration, recent server
+ * logs, recent tool errors, and the result of connectivity checks against
+ * the Harness API. Everything is sanitized before it is written: secret
+ * config values are replaced, log entries go through the same key-based
+ * redaction as audit bodies, and API keys, bearer tokens, and the literal
+ * values of secret settings are scrubbed from any remaining text.
+ *
+ * `harness-mcp-server support-bundle` writes the archive from the CLI; a
+ * running server serves the same content as the support:///bundle resource.
+ */
+import { existsSync, openSync, readSync, closeSync, statSync } from "node:fs";
+import { join } from "node:path";
+import { arch, platform, release } from "node:os";
+import { gzipSync } from "node:zlib";
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { withRegisterTool } from "./register-tool.js";
+import type { Config } from "../config.js";
+import type { HarnessClient } from "../client/harness-client.js";
+import type { ToolResult } from "./response-formatter.js";
+import { getVersion } from "./cli.js";
+import { createLogger, getRecentLogEntries } from "./logger.js";
+import { HarnessApiError } from "./errors.js";
+import { resolveOutputDir, safeFileName, writeOutputFile } from "./output-paths.js";
+import { redactJsonString } from "./redact.js";
+import { isRecord } from "./type-guards.js";
+
+const log = createLogger("tools");
+
+/** Log message written for every tool call that returns an error result. */
+export const TOOL_ERROR_MSG = "Tool call returned an error";
+
+const REDACTED = "[REDACTED]";
+
+/** Config keys whose values never leave the process. Result processor rules carry webhook URLs and headers. */
+const SECRET_CONFIG_KEY = /(?:KEY|TOKEN|SECRET|PASSWORD|HEADERS|WEBHOOK_URL|RESULT_PROCESSORS)$/;
+
+/** API keys (pat./sat.) and bearer credentials that can appear in free text. */
+const TOKEN_PATTERN = /\b(?:pat|sat)\.[\w-]+\.[\w-]+\.[\w-]+/g;
+const BEARER_PATTERN = /\bBearer\s+[\w.~+/-]+=*/gi;
+
+/** Most log file bytes read from the end of HARNESS_MCP_LOG_FILE. */
+const LOG_FILE_TAIL_BYTES = 256 * 1024;
+const MAX_TOOL_ERRORS = 50;
+const MAX_ERROR_CHARS = 1000;
+const CONNECTIVITY_TIMEOUT_MS = 10_000;
+
+export interface ConnectivityCheck {
+  name: string;
+  target: string;
+  ok: boolean;
+  latency_ms: number;
+  /** HTTP status when Harness answered; absent when it could not be reached. */
+  status?: number;
+  error?: string;
+}
+
+export interface SupportBundle {
+  generated_at: string;
+  version: Record<string, unknown>;
+  config: Record<string, unknown>;
+  connectivity: ConnectivityCheck[] | { skipped: string };
+  tool_errors: Array<Record<string, unknown>>;
+  logs: string[];
+}
+
+/**
+ * A view of `server` whose registerTool logs every error result at warn, so
+ * recent tool errors show up in the log history and in support bundles.
+ */
+export function withToolErrorLogging(server: McpServer): McpServer {
+  type ToolCallback = (args: Record<string, unknown>, extra: unknown) => ToolResult | Promise<ToolResult>;
+  type RegisterTool = (name: string, config: unknown, callback: ToolCallback) => unknown;
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) =>
+    register(name, config, async (args, extra) => {
+      const result = await callback(args, extra);
+      if (result.isError) {
+        log.warn(TOOL_ERROR_MSG, {
+          tool: name,
+          ...(typeof args?.resource_type === "string" ? { resource_type: args.resource_type } : {}),
+          ...(typeof args?.action === "string" ? { action: args.action } : {}),
+          error: (result.content[0]?.text ?? "").slice(0, MAX_ERROR_CHARS),
+        });
+      }
+      return result;
+    });
+  return withRegisterTool(server, registerTool);
+}
+
+/** Literal values of secret settings, longest first so overlapping values scrub fully. */
+function secretValues(config: Partial<Config>): string[] {
+  return Object.entries(config)
+    .filter(([key, value]) => SECRET_CONFIG_KEY.test(key) && typeof value === "string" && value.length >= 8)
+    .map(([, value]) => value as string)
+    .concat(resultProcessorSecrets(config.HARNESS_RESULT_PROCESSORS))
+    .filter((value) => value.length >= 8)
+    .sort((a, b) => b.length - a.length);
+}
+
+/** Webhook URLs and header values of inline HARNESS_RESULT_PROCESSORS rules, which can appear in logs on their own. */
+function resultProcessorSecrets(spec: string | undefined): string[] {
+  if (!spec?.trim().startsWith("[")) return [];
+  let rules: unknown;
+  try {
+    rules = JSON.parse(spec);
+  } catch {
+    return [];
+  }
+  if (!Array.isArray(rules)) return [];
+  return rules.filter(isRecord).flatMap((rule) => [
+    ...(typeof rule.webhook === "string" ? [rule.webhook] : []),
+    ...(isRecord(rule.headers) ? Object.values(rule.headers).filter((v): v is string => typeof v === "string") : []),
+  ]);
+}
+
+/** Remove API keys, bearer tokens, and the given secret values from free text. */
+export function scrubSecrets(text: string, secrets: string[] = []): string {
+  let out = text.replace(TOKEN_PATTERN, REDACTED).replace(BEARER_PATTERN, `Bearer ${REDACTED}`);
+  for (const secret of secrets) out = out.split(secret).join(REDACTED);
+  return out;
+}
+
+/** Effective configuration with secret settings replaced (unset ones stay absent). */
+export function sanitizeConfig(config: Partial<Config>): Record<string, unknown> {
+  const out: Record<string, unknown> = {};
+  for (const [key, value] of Object.entries(config).sort(([a], [b]) => a.localeCompare(b))) {
+    if (value === undefined) continue;
+    out[key] = SECRET_CONFIG_KEY.test(key) && value !== "" ? REDACTED : value;
+  }
+  return out;
+}
+
+/** One log line, redacted by key and scrubbed of token-shaped values. */
+function sanitizeLogLine(line: string, secrets: string[]): string {
+  return scrubSecrets(redactJsonString(line, Number.MAX_SAFE_INTEGER), secrets);
+}
+
+function parseLogLine(line: string): Record<string, unknown> | undefined {
+  try {
+    const entry: unknown = JSON.parse(line);
+    return isRecord(entry) ? entry : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/** The path stdio lifecycle diagnostics are appended to (see logToFile in index.ts). */
+export function defaultLogFilePath(env: NodeJS.ProcessEnv = process.env): string | undefined {
+  const home = env.HOME ?? env.USERPROFILE;
+  return env.HARNESS_MCP_LOG_FILE ?? (home ? join(home, ".claude", "harness-mcp.log") : undefined);
+}
+
+/** Complete lines from the last LOG_FILE_TAIL_BYTES of a log file; [] if it can't be read. */
+function tailLogFile(path: string | undefined): string[] {
+  if (!path || !existsSync(path)) return [];
+  try {
+    const size = statSync(path).size;
+    const length = Math.min(size, LOG_FILE_TAIL_BYTES);
+    const buf = Buffer.alloc(length);
+    const fd = openSync(path, "r");
+    try {
+      readSync(fd, buf, 0, length, size - length);
+    } finally {
+      closeSync(fd);
+    }
+    const lines = buf.toString("utf-8").split("\n");
+    // The first line is partial unless the whole file fit.
+    if (length < size) lines.shift();
+    return lines.filter((line) => line.trim() !== "");
+  } catch {
+    return [];
+  }
+}
+
+async function timedCheck(name: string, target: string, run: (signal: AbortSignal) => Promise<unknown>): Promise<ConnectivityCheck> {
+  const started = Date.now();
+  try {
+    await run(AbortSignal.timeout(CONNECTIVITY_TIMEOUT_MS));
+    return { name, target, ok: true, latency_ms: Date.now() - started };
+  } catch (err) {
+    return {
+      name,
+      target,
+      ok: false,
+      latency_ms: Date.now() - started,
+      ...(err instanceof HarnessApiError ? { status: err.statusCode } : {}),
+      error: err instanceof Error ? err.message : String(err),
+    };
+  }
+}
+
+/**
+ * Authenticated calls as the configured key. A failure with a status means
+ * Harness answered (a 401 or 403 points at the key); one without a status
+ * means the base URL could not be reached.
+ */
+export async function runConnectivityChecks(config: Config, client: HarnessClient): Promise<ConnectivityCheck[]> {
+  return Promise.all([
+    timedCheck("harness_api", `${config.HARNESS_BASE_URL} GET /ng/api/user/currentUser`, (signal) =>
+      client.request({ method: "GET", path: "/ng/api/user/currentUser", signal })),
+    timedCheck("harness_account", `${config.HARNESS_BASE_URL} GET /ng/api/accounts/${client.account}`, (signal) =>
+      client.request({ method: "GET", path: `/ng/api/accounts/${encodeURIComponent(client.account)}`, signal })),
+  ]);
+}
+
+export interface CollectOptions {
+  /** Loaded config, or the error that stopped it from loading. */
+  config: Config | Error;
+  /** Client for the connectivity checks; they are skipped without one. */
+  client?: HarnessClient;
+  /** Log file to include the tail of; defaults to HARNESS_MCP_LOG_FILE. */
+  logFile?: string;
+}
+
+/** Gather and sanitize everything that goes into a support bundle. */
+export async function collectSupportBundle(options: CollectOptions): Promise<SupportBundle> {
+  const config = options.config instanceof Error ? undefined : options.config;
+  const secrets = config ? secretValues(config) : [];
+
+  const connectivity = !config
+    ? { skipped: "configuration did not load" }
+    : !options.client
+      ? { skipped: "no Harness client" }
+      : await runConnectivityChecks(config, options.client);
+
+  const logs = [
+    ...tailLogFile(options.logFile ?? defaultLogFilePath()),
+    ...getRecentLogEntries().map((entry) => JSON.stringify(entry)),
+  ].map((line) => sanitizeLogLine(line, secrets));
+
+  const toolErrors = logs
+    .map(parseLogLine)
+    .filter((entry): entry is Record<string, unknown> => entry?.msg === TOOL_ERROR_MSG)
+    .slice(-MAX_TOOL_ERRORS);
+
+  const checks = Array.isArray(connectivity)
+    ? connectivity.map((check) => (check.error ? { ...check, error: scrubSecrets(check.error, secrets) } : check))
+    : connectivity;
+
+  return {
+    generated_at: new Date().toISOString(),
+    version: {
+      server: getVersion(),
+      node: process.version,
+      platform: platform(),
+      os_release: release(),
+      arch: arch(),
+      pid: process.pid,
+      uptime_s: Math.round(process.uptime()),
+    },
+    config: options.config instanceof Error
+      ? { error: scrubSecrets(options.config.message, secrets) }
+      : sanitizeConfig(options.config),
+    connectivity: checks,
+    tool_errors: toolErrors,
+    logs,
+  };
+}
+
+/** Files in the archive, keyed by name inside the bundle directory. */
+export function supportBundleFiles(bundle: SupportBundle): Record<string, string> {
+  const json = (value: unknown) => `${JSON.stringify(value, null, 2)}\n`;
+  return {
+    "version.json": json({ generated_at: bundle.generated_at, ...bundle.version }),
+    "config.json": json(bundle.config),
+    "connectivity.json": json(bundle.connectivity),
+    "tool-errors.json": json(bundle.tool_errors),
+    "server.log": bundle.logs.length > 0 ? `${bundle.logs.join("\n")}\n` : "",
+  };
+}
+
+function tarHeader(name: string, size: number, mtime: number): Buffer {
+  const header = Buffer.alloc(512);
+  const field = (value: string, offset: number, length: number) => header.write(value, offset, length, "ascii");
+  const octal = (value: number, length: number) => value.toString(8).padStart(length - 1, "0");
+  field(name, 0, 100);
+  field(octal(0o644, 8), 100, 8);
+  field(octal(0, 8), 108, 8);
+  field(octal(0, 8), 116, 8);
+  field(octal(size, 12), 124, 12);
+  field(octal(mtime, 12), 136, 12);
+  field("        ", 148, 8);
+  field("0", 156, 1);
+  field("ustar\u000000", 257, 8);
+  let sum = 0;
+  for (const byte of header) sum += byte;
+  field(`${octal(sum, 7)}\u0000`, 148, 8);
+  return header;
+}
+
+/** A gzipped ustar archive of `files`. Names must be under 100 bytes. */
+export function tarGz(files: Record<string, string>, mtime = Math.floor(Date.now() / 1000)): Buffer {
+  const blocks: Buffer[] = [];
+  for (const [name, text] of Object.entries(files)) {
+    const data = Buffer.from(text, "utf-8");
+    blocks.push(tarHeader(name, data.length, mtime), data, Buffer.alloc((512 - (data.length % 512)) % 512));
+  }
+  blocks.push(Buffer.alloc(1024));
+  return gzipSync(Buffer.concat(blocks));
+}
+
+/**
+ * Write `bundle` to `outputDir` as harness-support-<timestamp>.tar.gz, with
+ * every file under one top-level directory of the same name. Returns the
+ * absolute archive path.
+ */
+export function writeSupportBundle(bundle: SupportBundle, outputDir: string): string {
+  const dir = resolveOutputDir(outputDir, { allowRelative: true });
+  const stem = safeFileName(`harness-support-${bundle.generated_at.replace(/[-:]/g, "").replace(/\.\d+Z$/, "Z")}`);
+  const files = Object.fromEntries(
+    Object.entries(supportBundleFiles(bundle)).map(([name, text]) => [`${stem}/${name}`, text]),
+  );
+  const path = join(dir, `${stem}.tar.gz`);
+  writeOutputFile(path, tarGz(files), { mode: 0o600 });
+  return path;
+}
@@ -0,0 +1,117 @@
+/**
+ * Line-based unified diff for comparing two YAML snapshots.
+ *
+ * Common leading/trailing lines are trimmed first, so the LCS table only
+ * covers the changed middle — pipeline edits usually touch a few lines of a
+ * long document. When the middle is still too large for the table, it is
+ * reported as a single replaced block rather than spending seconds on it.
+ */
+
+/** Largest changed region (old lines × new lines) diffed line by line. */
+const MAX_LCS_CELLS = 4_000_000;
+
+export interface LineDiff {
+  /** Unified diff text (`@@ -a,b +c,d @@` hunks), empty when identical. */
+  diff: string;
+  added: number;
+  removed: number;
+}
+
+type Op = { kind: " " | "-" | "+"; line: string };
+
+function splitLines(text: string): string[] {
+  if (text === "") return [];
+  const lines = text.replace(/\r\n/g, "\n").split("\n");
+  if (lines[lines.length - 1] === "") lines.pop();
+  return lines;
+}
+
+/** Edit script for the changed middle via an LCS table. */
+function diffMiddle(a: string[], b: string[]): Op[] {
+  const n = a.length;
+  const m = b.length;
+  if (n * m > MAX_LCS_CELLS) {
+    return [...a.map((line) => ({ kind: "-" as const, line })), ...b.map((line) => ({ kind: "+" as const, line }))];
+  }
+  // lcs[i * (m + 1) + j] = LCS length of a[i..] and b[j..]
+  const lcs = new Uint32Array((n + 1) * (m + 1));
+  for (let i = n - 1; i >= 0; i--) {
+    for (let j = m - 1; j >= 0; j--) {
+      lcs[i * (m + 1) + j] = a[i] === b[j]
+        ? lcs[(i + 1) * (m + 1) + j + 1]! + 1
+        : Math.max(lcs[(i + 1) * (m + 1) + j]!, lcs[i * (m + 1) + j + 1]!);
+    }
+  }
+  const ops: Op[] = [];
+  let i = 0;
+  let j = 0;
+  while (i < n && j < m) {
+    if (a[i] === b[j]) {
+      ops.push({ kind: " ", line: a[i]! });
+      i++;
+      j++;
+    } else if (lcs[(i + 1) * (m + 1) + j]! >= lcs[i * (m + 1) + j + 1]!) {
+      ops.push({ kind: "-", line: a[i++]! });
+    } else {
+      ops.push({ kind: "+", line: b[j++]! });
+    }
+  }
+  while (i < n) ops.push({ kind: "-", line: a[i++]! });
+  while (j < m) ops.push({ kind: "+", line: b[j++]! });
+  return ops;
+}
+
+/** Diff `before` against `after`, with `context` unchanged lines around each change. */
+export function diffLines(before: string, after: string, context = 3): LineDiff {
+  const a = splitLines(before);
+  const b = splitLines(after);
+
+  let prefix = 0;
+  while (prefix < a.length && prefix < b.length && a[prefix] === b[prefix]) prefix++;
+  let suffix = 0;
+  while (
+    suffix < a.length - prefix &&
+    suffix < b.length - prefix &&
+    a[a.length - 1 - suffix] === b[b.length - 1 - suffix]
+  ) suffix++;
+
+  const ops: Op[] = [
+    ...a.slice(0, prefix).map((line) => ({ kind: " " as const, line })),
+    ...diffMiddle(a.slice(prefix, a.length - suffix), b.slice(prefix, b.length - suffix)),
+    ...a.slice(a.length - suffix).map((line) => ({ kind: " " as const, line })),
+  ];
+
+  const added = ops.filter((op) => op.kind === "+").length;
+  const removed = ops.filter((op) => op.kind === "-").length;
+  if (added === 0 && removed === 0) return { diff: "", added, removed };
+
+  // Group changes into hunks, merging those within 2 * context of each other.
+  const out: string[] = [];
+  let k = 0;
+  while (k < ops.length) {
+    while (k < ops.length && ops[k]!.kind === " ") k++;
+    if (k >= ops.length) break;
+    const start = Math.max(0, k - context);
+    let end = k;
+    let lastChange = k;
+    while (end < ops.length && end - lastChange <= 2 * context) {
+      if (ops[end]!.kind !== " ") lastChange = end;
+      end++;
+    }
+    end = Math.min(ops.length, lastChange + context + 1);
+
+    let oldLine = 1;
+    let newLine = 1;
+    for (let p = 0; p < start; p++) {
+      if (ops[p]!.kind !== "+") oldLine++;
+      if (ops[p]!.kind !== "-") newLine++;
+    }
+    const hunk = ops.slice(start, end);
+    const oldCount = hunk.filter((op) => op.kind !== "+").length;
+    const newCount = hunk.filter((op) => op.kind !== "-").length;
+    out.push(`@@ -${oldCount ? oldLine : oldLine - 1},${oldCount} +${newCount ? newLine : newLine - 1},${newCount} @@`);
+    for (const op of hunk) out.push(`${op.kind}${op.line}`);
+    k = end;
+  }
+  return { diff: out.join("\n"), added, removed };
+}
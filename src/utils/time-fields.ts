@@ -0,0 +1,119 @@
+/**
+ * Consistent timestamps in tool results.
+ *
+ * Harness APIs return times as epoch millis, epoch seconds, or numeric
+ * strings depending on the service. Before a tool result is returned, every
+ * time-named field holding an epoch value is rewritten to an RFC 3339 string
+ * and the original instant is kept next to it as `<field>_epoch_ms`, so
+ * automation can parse one format and still compute with numbers:
+ *
+ *   { "createdAt": 1752000000000 }
+ *   → { "createdAt": "2025-07-08T18:40:00.000Z", "createdAt_epoch_ms": 1752000000000 }
+ *
+ * Strings that are already formatted are left alone, and so are duration
+ * fields (durationTime, executionTime, ...) even when their value happens to
+ * fall in the epoch range. The rewrite is opt-in with
+ * HARNESS_TIME_FORMAT=rfc3339; the default "raw" returns results unchanged.
+ */
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { withRegisterTool } from "./register-tool.js";
+import type { ToolResult } from "./response-formatter.js";
+import { isRecord } from "./type-guards.js";
+
+export type TimeFormat = "rfc3339" | "raw";
+
+export const EPOCH_MS_SUFFIX = "_epoch_ms";
+
+/** 2000-01-01 to 2100-01-01 — outside this a number is a duration, count, or ID, not an instant. */
+const MIN_EPOCH_S = 946_684_800;
+const MAX_EPOCH_S = 4_102_444_800;
+const MIN_EPOCH_MS = MIN_EPOCH_S * 1000;
+const MAX_EPOCH_MS = MAX_EPOCH_S * 1000;
+
+const MAX_DEPTH = 32;
+
+/**
+ * Field names that hold instants: camelCase suffixes (createdAt, startTs,
+ * lastHeartBeat, endTime), snake_case suffixes (created_at, start_ts), and a
+ * few bare names Harness uses (created, lastModified, deadline, revokeAfter).
+ */
+const TIME_KEY =
+  /(?:[a-z0-9](?:At|Ts|Time|Timestamp|Date|Heartbeat|HeartBeat|Updated|Modified)|_(?:at|ts|time|timestamp|date))$|^(?:created|updated|modified|lastModified|deadline|timestamp|time|date|ts|revokeAfter|expiry|expires|expiration)$/;
+
+/**
+ * Time-named fields that hold elapsed time, not an instant. Nanosecond and
+ * microsecond durations of a few seconds land in the epoch-seconds range, so
+ * these are excluded by name rather than by value.
+ */
+const DURATION_KEY =
+  /duration|elapsed|latency|took|(?:total|exec|execution|processing|wait|waiting|queue|queued|lead|cycle|response|up|mean|avg|average|median|spent)_?time(?:_?(?:ms|ns|us|s))?$/i;
+
+export function isTimeKey(key: string): boolean {
+  return TIME_KEY.test(key) && !key.endsWith(EPOCH_MS_SUFFIX) && !DURATION_KEY.test(key);
+}
+
+/** Epoch millis for a numeric or digit-only epoch value in seconds or millis, else undefined. */
+export function toEpochMs(value: unknown): number | undefined {
+  const n = typeof value === "number" ? value
+    : typeof value === "string" && /^\d{9,13}$/.test(value) ? Number(value)
+      : undefined;
+  if (n === undefined || !Number.isInteger(n)) return undefined;
+  if (n >= MIN_EPOCH_MS && n < MAX_EPOCH_MS) return n;
+  if (n >= MIN_EPOCH_S && n < MAX_EPOCH_S) return n * 1000;
+  return undefined;
+}
+
+/**
+ * Rewrite epoch time fields to RFC 3339 at any depth, adding `<field>_epoch_ms`
+ * beside each. Returns a new value; the input is not mutated.
+ */
+export function normalizeTimeFields(data: unknown, depth = 0): unknown {
+  if (depth > MAX_DEPTH) return data;
+  if (Array.isArray(data)) return data.map((item) => normalizeTimeFields(item, depth + 1));
+  if (!isRecord(data)) return data;
+  const out: Record<string, unknown> = {};
+  for (const [key, value] of Object.entries(data)) {
+    const epochMs = isTimeKey(key) && !(`${key}${EPOCH_MS_SUFFIX}` in data) ? toEpochMs(value) : undefined;
+    if (epochMs === undefined) {
+      out[key] = normalizeTimeFields(value, depth + 1);
+      continue;
+    }
+    out[key] = new Date(epochMs).toISOString();
+    out[`${key}${EPOCH_MS_SUFFIX}`] = epochMs;
+  }
+  return out;
+}
+
+/** Normalize the JSON text items and structuredContent of a tool result. Error results pass through. */
+export function normalizeResultTimes(result: ToolResult): ToolResult {
+  if (result.isError) return result;
+  const content = result.content.map((item) => {
+    try {
+      return { ...item, text: JSON.stringify(normalizeTimeFields(JSON.parse(item.text))) };
+    } catch {
+      return item;
+    }
+  });
+  const structured = result.structuredContent ? normalizeTimeFields(result.structuredContent) : undefined;
+  return {
+    ...result,
+    content,
+    ...(isRecord(structured) ? { structuredContent: structured } : {}),
+  };
+}
+
+type ToolCallback = (args: Record<string, unknown>, extra: unknown) => ToolResult | Promise<ToolResult>;
+type RegisterTool = (name: string, config: unknown, callback: ToolCallback) => unknown;
+
+/**
+ * A view of `server` whose registerTool normalizes time fields in every
+ * result of the tools registered through it. With format "raw" (the default)
+ * the server is returned unchanged.
+ */
+export function withTimeFields(server: McpServer, format: TimeFormat = "raw"): McpServer {
+  if (format === "raw") return server;
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) =>
+    register(name, config, async (args, extra) => normalizeResultTimes(await callback(args, extra)));
+  return withRegisterTool(server, registerTool);
+}
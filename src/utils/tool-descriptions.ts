@@ -0,0 +1,90 @@
+/**
+ * Short tool descriptions for clients with a tight tool-list token budget.
+ *
+ * The harness_* descriptions carry a lot of usage guidance, and tools/list
+ * sends all of it on every session. With HARNESS_TOOL_DESCRIPTIONS=short,
+ * tools/list gets each tool's first sentence and first-sentence parameter
+ * descriptions instead, and harness_describe(tool=<name>) returns the full
+ * guidance on demand.
+ *
+ * Full descriptions are recorded in either mode, so harness_describe can
+ * always return them.
+ */
+import type { McpServer } from "@modelcontextprotocol/sdk/server/mcp.js";
+import { withRegisterTool } from "./register-tool.js";
+
+export type ToolDescriptionMode = "full" | "short";
+
+/** Longest short description; a first sentence past this is cut at a word boundary. */
+const SHORT_MAX_CHARS = 160;
+
+export interface FullToolDescription {
+  tool: string;
+  description: string;
+  parameters: Record<string, string>;
+}
+
+const fullDescriptions = new Map<string, FullToolDescription>();
+
+/** The full description of a registered tool, or undefined when it is unknown. */
+export function getFullToolDescription(tool: string): FullToolDescription | undefined {
+  return fullDescriptions.get(tool);
+}
+
+/** Names of the tools with a recorded description, sorted. */
+export function describedToolNames(): string[] {
+  return [...fullDescriptions.keys()].sort();
+}
+
+/** First sentence of `text`, capped at SHORT_MAX_CHARS. */
+export function shortenDescription(text: string): string {
+  const trimmed = text.trim();
+  const end = trimmed.search(/[.!?](\s|$)/);
+  const sentence = end === -1 ? trimmed : trimmed.slice(0, end + 1);
+  if (sentence.length <= SHORT_MAX_CHARS) return sentence;
+  const cut = sentence.slice(0, SHORT_MAX_CHARS);
+  const space = cut.lastIndexOf(" ");
+  return `${(space > 0 ? cut.slice(0, space) : cut).replace(/[,;:]$/, "")}...`;
+}
+
+interface DescribedSchema {
+  description?: string;
+  describe(text: string): unknown;
+}
+
+function isDescribedSchema(value: unknown): value is DescribedSchema {
+  return typeof value === "object" && value !== null && typeof (value as { describe?: unknown }).describe === "function";
+}
+
+type ToolConfig = { description?: string; inputSchema?: Record<string, unknown> } & Record<string, unknown>;
+type RegisterTool = (name: string, config: ToolConfig, callback: unknown) => unknown;
+
+/**
+ * A view of `server` whose registerTool records each tool's full
+ * description and, in short mode, registers the tool with shortened tool
+ * and parameter descriptions.
+ */
+export function withToolDescriptions(server: McpServer, mode: ToolDescriptionMode = "full"): McpServer {
+  const register = server.registerTool.bind(server) as unknown as RegisterTool;
+  const registerTool: RegisterTool = (name, config, callback) => {
+    const parameters: Record<string, string> = {};
+    for (const [key, schema] of Object.entries(config.inputSchema ?? {})) {
+      if (isDescribedSchema(schema) && schema.description) parameters[key] = schema.description;
+    }
+    fullDescriptions.set(name, { tool: name, description: config.description ?? "", parameters });
+    if (mode === "full") return register(name, config, callback);
+
+    const inputSchema = Object.fromEntries(Object.entries(config.inputSchema ?? {}).map(([key, schema]) => {
+      const full = parameters[key];
+      if (!full || !isDescribedSchema(schema)) return [key, schema];
+      const short = shortenDescription(full);
+      return [key, short === full ? schema : schema.describe(short)];
+    }));
+    return register(name, {
+      ...config,
+      ...(config.inputSchema ? { inputSchema } : {}),
+      description: `${shortenDescription(config.description ?? "")} Full guidance: harness_describe(tool='${name}').`,
+    }, callback);
+  };
+  return withRegisterTool(server, registerTool);
+}
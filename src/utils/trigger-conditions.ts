@@ -0,0 +1,304 @@
+/**
+ * Webhook trigger inspection: pull the branch/commit facts Harness derives
+ * from a Git provider payload, read a trigger's conditions from its YAML, and
+ * re-evaluate them against a recorded payload.
+ *
+ * Used to explain why a webhook started (or did not start) a pipeline — the
+ * evaluation mirrors Harness's operators, but it runs offline against the
+ * stored event, so JEXL conditions are reported without being evaluated.
+ */
+import YAML from "yaml";
+import { isRecord } from "./type-guards.js";
+
+export interface WebhookFacts {
+  provider?: string;
+  event_type?: string;
+  action?: string;
+  repo?: string;
+  source_branch?: string;
+  target_branch?: string;
+  commit_sha?: string;
+  changed_files?: string[];
+}
+
+export interface TriggerCondition {
+  kind: "payload" | "header";
+  key: string;
+  operator: string;
+  value: string;
+}
+
+export interface TriggerConditionSpec {
+  provider?: string;
+  event_type?: string;
+  actions: string[];
+  conditions: TriggerCondition[];
+  jexl_condition?: string;
+}
+
+export interface ConditionResult extends TriggerCondition {
+  /** Value the condition was checked against; undefined when it could not be determined. */
+  actual?: string | string[];
+  /** null when the actual value could not be determined. */
+  matched: boolean | null;
+}
+
+function str(value: unknown): string | undefined {
+  return typeof value === "string" && value ? value : undefined;
+}
+
+function stripRef(ref: string | undefined): string | undefined {
+  return ref?.replace(/^refs\/(heads|tags)\//, "");
+}
+
+function readDotted(value: unknown, path: string): unknown {
+  let current = value;
+  for (const part of path.split(".")) {
+    if (!isRecord(current) && !Array.isArray(current)) return undefined;
+    current = (current as Record<string, unknown>)[part];
+  }
+  return current;
+}
+
+/** Branch, commit, and event facts from a GitHub, GitLab, Bitbucket, or Azure Repos payload. */
+export function extractWebhookFacts(payload: unknown, headers: Record<string, string> = {}): WebhookFacts {
+  if (!isRecord(payload)) return {};
+  const header = (name: string) => {
+    const key = Object.keys(headers).find((k) => k.toLowerCase() === name.toLowerCase());
+    return key ? headers[key] : undefined;
+  };
+
+  // GitHub
+  if (isRecord(payload.pull_request)) {
+    const pr = payload.pull_request;
+    return {
+      provider: "Github",
+      event_type: "PullRequest",
+      action: str(payload.action),
+      repo: str(readDotted(payload, "repository.full_name")),
+      source_branch: str(readDotted(pr, "head.ref")),
+      target_branch: str(readDotted(pr, "base.ref")),
+      commit_sha: str(readDotted(pr, "head.sha")),
+    };
+  }
+  if (typeof payload.ref === "string" && (payload.head_commit !== undefined || header("X-GitHub-Event"))) {
+    const branch = stripRef(payload.ref);
+    const files = new Set<string>();
+    for (const commit of Array.isArray(payload.commits) ? payload.commits : []) {
+      if (!isRecord(commit)) continue;
+      for (const key of ["added", "modified", "removed"]) {
+        const list = commit[key];
+        if (!Array.isArray(list)) continue;
+        for (const file of list) {
+          if (typeof file === "string") files.add(file);
+        }
+      }
+    }
+    return {
+      provider: "Github",
+      event_type: "Push",
+      repo: str(readDotted(payload, "repository.full_name")),
+      source_branch: branch,
+      target_branch: branch,
+      commit_sha: str(payload.after) ?? str(readDotted(payload, "head_commit.id")),
+      ...(files.size > 0 ? { changed_files: [...files] } : {}),
+    };
+  }
+
+  // GitLab
+  if (payload.object_kind === "merge_request" && isRecord(payload.object_attributes)) {
+    const mr = payload.object_attributes;
+    return {
+      provider: "Gitlab",
+      event_type: "MergeRequest",
+      action: str(mr.action),
+      repo: str(readDotted(payload, "project.path_with_namespace")),
+      source_branch: str(mr.source_branch),
+      target_branch: str(mr.target_branch),
+      commit_sha: str(readDotted(mr, "last_commit.id")),
+    };
+  }
+  if (payload.object_kind === "push" || payload.object_kind === "tag_push") {
+    const branch = stripRef(str(payload.ref));
+    return {
+      provider: "Gitlab",
+      event_type: payload.object_kind === "push" ? "Push" : "Tag",
+      repo: str(readDotted(payload, "project.path_with_namespace")),
+      source_branch: branch,
+      target_branch: branch,
+      commit_sha: str(payload.checkout_sha) ?? str(payload.after),
+    };
+  }
+
+  // Bitbucket
+  if (isRecord(payload.pullrequest)) {
+    const pr = payload.pullrequest;
+    return {
+      provider: "Bitbucket",
+      event_type: "PullRequest",
+      repo: str(readDotted(payload, "repository.full_name")),
+      source_branch: str(readDotted(pr, "source.branch.name")),
+      target_branch: str(readDotted(pr, "destination.branch.name")),
+      commit_sha: str(readDotted(pr, "source.commit.hash")),
+    };
+  }
+  if (isRecord(payload.push) && Array.isArray(payload.push.changes)) {
+    const change = payload.push.changes.find(isRecord);
+    const branch = str(readDotted(change, "new.name"));
+    return {
+      provider: "Bitbucket",
+      event_type: "Push",
+      repo: str(readDotted(payload, "repository.full_name")),
+      source_branch: branch,
+      target_branch: branch,
+      commit_sha: str(readDotted(change, "new.target.hash")),
+    };
+  }
+
+  // Azure Repos
+  if (typeof payload.eventType === "string" && isRecord(payload.resource)) {
+    const resource = payload.resource;
+    if (payload.eventType.startsWith("git.pullrequest")) {
+      return {
+        provider: "AzureRepo",
+        event_type: "PullRequest",
+        repo: str(readDotted(resource, "repository.name")),
+        source_branch: stripRef(str(resource.sourceRefName)),
+        target_branch: stripRef(str(resource.targetRefName)),
+        commit_sha: str(readDotted(resource, "lastMergeSourceCommit.commitId")),
+      };
+    }
+    if (payload.eventType === "git.push" && Array.isArray(resource.refUpdates)) {
+      const update = resource.refUpdates.find(isRecord);
+      const branch = stripRef(str(update?.name));
+      return {
+        provider: "AzureRepo",
+        event_type: "Push",
+        repo: str(readDotted(resource, "repository.name")),
+        source_branch: branch,
+        target_branch: branch,
+        commit_sha: str(update?.newObjectId),
+      };
+    }
+  }
+  return {};
+}
+
+function readConditions(list: unknown, kind: TriggerCondition["kind"]): TriggerCondition[] {
+  if (!Array.isArray(list)) return [];
+  return list.filter(isRecord).flatMap((c) => {
+    const key = str(c.key);
+    const operator = str(c.operator);
+    if (!key || !operator) return [];
+    return [{ kind, key, operator, value: c.value === undefined || c.value === null ? "" : String(c.value) }];
+  });
+}
+
+/** Conditions of a webhook trigger, from its YAML string or parsed document. Undefined for non-webhook triggers. */
+export function readTriggerConditions(trigger: unknown): TriggerConditionSpec | undefined {
+  let doc = trigger;
+  if (typeof doc === "string") {
+    try {
+      doc = YAML.parse(doc);
+    } catch {
+      return undefined;
+    }
+  }
+  const root = isRecord(doc) && isRecord(doc.trigger) ? doc.trigger : doc;
+  const source = isRecord(root) ? root.source : undefined;
+  if (!isRecord(source) || source.type !== "Webhook" || !isRecord(source.spec)) return undefined;
+  const provider = str(source.spec.type);
+  // Git providers nest one level deeper: spec.spec = { type: PullRequest, spec: {...} }.
+  // Custom webhooks put the conditions directly in spec.spec.
+  const outer = isRecord(source.spec.spec) ? source.spec.spec : {};
+  const inner = isRecord(outer.spec) ? outer.spec : outer;
+  return {
+    provider,
+    event_type: inner === outer ? undefined : str(outer.type),
+    actions: Array.isArray(inner.actions) ? inner.actions.filter((a): a is string => typeof a === "string") : [],
+    conditions: [
+      ...readConditions(inner.payloadConditions, "payload"),
+      ...readConditions(inner.headerConditions, "header"),
+    ],
+    ...(str(inner.jexlCondition) ? { jexl_condition: str(inner.jexlCondition) } : {}),
+  };
+}
+
+function resolveConditionValue(
+  condition: TriggerCondition,
+  payload: unknown,
+  headers: Record<string, string>,
+  facts: WebhookFacts,
+): string | string[] | undefined {
+  if (condition.kind === "header") {
+    const name = condition.key.replace(/^<\+trigger\.header\[['"]?|['"]?\]>$/g, "");
+    const key = Object.keys(headers).find((k) => k.toLowerCase() === name.toLowerCase());
+    return key ? headers[key] : undefined;
+  }
+  switch (condition.key) {
+    case "sourceBranch":
+      return facts.source_branch;
+    case "targetBranch":
+      return facts.target_branch;
+    case "changedFiles":
+      return facts.changed_files;
+  }
+  const expr = condition.key.match(/^<\+(?:trigger\.payload|eventPayload)\.(.+)>$/);
+  if (!expr) return undefined;
+  const value = readDotted(payload, expr[1]!);
+  if (value === undefined || value === null) return undefined;
+  return typeof value === "object" ? JSON.stringify(value) : String(value);
+}
+
+/** Evaluate one operator the way Harness does. In/NotIn take a comma-separated list. */
+export function evaluateOperator(operator: string, actual: string, expected: string): boolean | null {
+  const list = () => expected.split(",").map((v) => v.trim());
+  switch (operator.toLowerCase()) {
+    case "equals":
+      return actual === expected;
+    case "notequals":
+      return actual !== expected;
+    case "in":
+      return list().includes(actual);
+    case "notin":
+      return !list().includes(actual);
+    case "startswith":
+      return actual.startsWith(expected);
+    case "endswith":
+      return actual.endsWith(expected);
+    case "contains":
+      return actual.includes(expected);
+    case "doesnotcontain":
+      return !actual.includes(expected);
+    case "regex":
+      try {
+        return new RegExp(expected).test(actual);
+      } catch {
+        return null;
+      }
+    default:
+      return null;
+  }
+}
+
+/**
+ * Re-evaluate trigger conditions against a recorded event. For `changedFiles`
+ * a condition matches when any changed file matches, as in Harness.
+ */
+export function evaluateTriggerConditions(
+  spec: TriggerConditionSpec,
+  payload: unknown,
+  headers: Record<string, string> = {},
+): ConditionResult[] {
+  const facts = extractWebhookFacts(payload, headers);
+  return spec.conditions.map((condition) => {
+    const actual = resolveConditionValue(condition, payload, headers, facts);
+    if (actual === undefined) return { ...condition, matched: null };
+    if (Array.isArray(actual)) {
+      const results = actual.map((v) => evaluateOperator(condition.operator, v, condition.value));
+      const matched = results.includes(true) ? true : results.includes(null) ? null : false;
+      return { ...condition, actual, matched };
+    }
+    return { ...condition, actual, matched: evaluateOperator(condition.operator, actual, condition.value) };
+  });
+}
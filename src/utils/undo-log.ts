@@ -0,0 +1,124 @@
+/**
+ * Session undo log for writes made through the registry.
+ *
+ * With HARNESS_UNDO_LOG=true, every successful create, update, delete, and
+ * non-read execute action is recorded here. For resources that declare
+ * `restoreBody`, the registry reads the entity before an update or delete and
+ * keeps the body that puts it back (e.g. the pipeline YAML before the update).
+ * A create is reversed by deleting what it created. The `undo_log` resource
+ * lists the entries, and its `undo_last_change` action replays the newest
+ * one in reverse.
+ *
+ * Like the response cache, each registry (one per MCP session) owns its log.
+ * Stdio servers shared by several chats keep one log per conversation ID.
+ * Entries live in memory only and are lost when the server restarts.
+ */
+import { AsyncLocalStorage } from "node:async_hooks";
+import type { Config } from "../config.js";
+import { getConversationId } from "./conversation-context.js";
+import { isRecord } from "./type-guards.js";
+
+/** Entries kept per session; the oldest are dropped first. */
+export const UNDO_LOG_MAX_ENTRIES = 50;
+/** Conversations tracked by one stdio server before the least recent is dropped. */
+const UNDO_LOG_MAX_CONVERSATIONS = 100;
+
+export type UndoOperation = "create" | "update" | "delete" | "execute";
+
+/** The registry call that reverses an entry. */
+export interface UndoPlan {
+  operation: "create" | "update" | "delete";
+  /** Input for that call: the identifiers of the original call plus the restore body. */
+  input: Record<string, unknown>;
+}
+
+export interface UndoEntry {
+  entry_id: string;
+  recorded_at: string;
+  resource_type: string;
+  operation: UndoOperation;
+  action?: string;
+  resource_id?: string;
+  /** How to reverse the change, when enough prior state was captured. */
+  undo?: UndoPlan;
+  /** Why the change cannot be reversed from the log. */
+  not_undoable?: string;
+}
+
+/** Set while an undo is replayed, so the reversing write is not logged itself. */
+const replaying = new AsyncLocalStorage<true>();
+
+export class UndoLog {
+  private readonly logs = new Map<string, UndoEntry[]>();
+  private sequence = 0;
+
+  constructor(private readonly maxEntries: number = UNDO_LOG_MAX_ENTRIES) {}
+
+  /** False inside `replay`, where writes undo earlier entries instead of adding new ones. */
+  get recording(): boolean {
+    return replaying.getStore() === undefined;
+  }
+
+  /** Append an entry to the active conversation's log. */
+  record(entry: Omit<UndoEntry, "entry_id" | "recorded_at">): UndoEntry {
+    const recorded: UndoEntry = {
+      entry_id: `u${++this.sequence}`,
+      recorded_at: new Date().toISOString(),
+      ...entry,
+    };
+    const key = getConversationId() ?? "";
+    const log = this.logs.get(key) ?? [];
+    this.logs.delete(key);
+    this.logs.set(key, log);
+    log.push(recorded);
+    if (log.length > this.maxEntries) log.splice(0, log.length - this.maxEntries);
+    while (this.logs.size > UNDO_LOG_MAX_CONVERSATIONS) {
+      const oldest = this.logs.keys().next().value;
+      if (oldest === undefined) break;
+      this.logs.delete(oldest);
+    }
+    return recorded;
+  }
+
+  /** Entries of the active conversation, newest first. */
+  entries(): UndoEntry[] {
+    return [...(this.logs.get(getConversationId() ?? "") ?? [])].reverse();
+  }
+
+  /** Drop an entry from the active conversation's log once it has been undone. */
+  remove(entryId: string): void {
+    const log = this.logs.get(getConversationId() ?? "");
+    const index = log?.findIndex((entry) => entry.entry_id === entryId) ?? -1;
+    if (log && index >= 0) log.splice(index, 1);
+  }
+
+  /** Run `fn` without recording the writes it makes. */
+  replay<T>(fn: () => Promise<T>): Promise<T> {
+    return replaying.run(true, fn);
+  }
+}
+
+/** Build the registry's undo log from config, or undefined when HARNESS_UNDO_LOG is off. */
+export function createUndoLog(config: Partial<Pick<Config, "HARNESS_UNDO_LOG">>): UndoLog | undefined {
+  return config.HARNESS_UNDO_LOG ? new UndoLog() : undefined;
+}
+
+/** Request fields shared by NG entities that are defined by YAML. */
+const NG_ENTITY_FIELDS = ["identifier", "orgIdentifier", "projectIdentifier", "name", "description", "tags", "yaml"];
+
+/**
+ * `restoreBody` for NG entities whose get result wraps the entity under `key`
+ * (e.g. `{ service: { identifier, name, yaml, ... } }`): the request fields of
+ * that entity, or undefined when it has no YAML to restore.
+ */
+export function wrappedEntityRestoreBody(key: string, extraFields: readonly string[] = []): (prior: unknown) => unknown {
+  return (prior) => {
+    const entity = isRecord(prior) ? prior[key] : undefined;
+    if (!isRecord(entity) || typeof entity.yaml !== "string") return undefined;
+    const body: Record<string, unknown> = {};
+    for (const field of [...NG_ENTITY_FIELDS, ...extraFields]) {
+      if (entity[field] !== undefined && entity[field] !== null) body[field] = entity[field];
+    }
+    return body;
+  };
+}
@@ -0,0 +1,137 @@
+/**
+ * Periodic export of MCP usage to a Harness custom dashboard.
+ *
+ * Account admins want agent adoption next to their other product dashboards,
+ * not on a separate Prometheus stack. When HARNESS_USAGE_DASHBOARD_ID is set,
+ * the HTTP server pushes a snapshot of the usage counters to that dashboard
+ * every HARNESS_USAGE_EXPORT_INTERVAL_MS through the dashboards API.
+ *
+ * Snapshots are cumulative since `since` (the counters reset on restart), so
+ * the dashboard can chart the latest snapshot per server without summing.
+ * A failed push is logged and retried on the next tick; it never affects
+ * tool calls.
+ */
+import type { HarnessClient } from "../client/harness-client.js";
+import { createLogger } from "./logger.js";
+import { summarizeUsageByAccount, type UsageReport } from "./usage-metrics.js";
+
+const log = createLogger("usage-export");
+
+/** Most accounts listed in `top_accounts`. */
+const TOP_ACCOUNTS = 10;
+
+export interface UsageDashboardSnapshot {
+  source: "harness-mcp-server";
+  generated_at: string;
+  since: string;
+  totals: {
+    accounts: number;
+    calls: number;
+    errors: number;
+    blocked: number;
+    writes: number;
+    error_rate: number;
+  };
+  top_accounts: Array<{ account_id: string; calls: number; errors: number; error_rate: number; last_seen: string }>;
+  tools: Array<{ tool: string; calls: number; errors: number; error_rate: number; avg_duration_ms: number }>;
+}
+
+const rate = (errors: number, calls: number) => (calls > 0 ? Math.round((errors / calls) * 10_000) / 10_000 : 0);
+
+/** Reduce the per-account usage report to the rows a dashboard charts. */
+export function buildUsageDashboardSnapshot(report: UsageReport): UsageDashboardSnapshot {
+  const tools = new Map<string, { calls: number; errors: number; duration_ms: number }>();
+  for (const account of report.accounts) {
+    for (const tool of account.tools) {
+      const row = tools.get(tool.tool) ?? { calls: 0, errors: 0, duration_ms: 0 };
+      row.calls += tool.calls;
+      row.errors += tool.errors;
+      row.duration_ms += tool.duration_ms;
+      tools.set(tool.tool, row);
+    }
+  }
+  const sum = (key: "calls" | "errors" | "blocked" | "writes") => report.accounts.reduce((n, a) => n + a[key], 0);
+  const calls = sum("calls");
+  const errors = sum("errors");
+  return {
+    source: "harness-mcp-server",
+    generated_at: report.generated_at,
+    since: report.since,
+    totals: {
+      accounts: report.accounts.length,
+      calls,
+      errors,
+      blocked: sum("blocked"),
+      writes: sum("writes"),
+      error_rate: rate(errors, calls),
+    },
+    top_accounts: report.accounts.slice(0, TOP_ACCOUNTS).map((a) => ({
+      account_id: a.account_id,
+      calls: a.calls,
+      errors: a.errors,
+      error_rate: rate(a.errors, a.calls),
+      last_seen: a.last_seen,
+    })),
+    tools: [...tools.entries()]
+      .map(([tool, t]) => ({
+        tool,
+        calls: t.calls,
+        errors: t.errors,
+        error_rate: rate(t.errors, t.calls),
+        avg_duration_ms: t.calls > 0 ? Math.round(t.duration_ms / t.calls) : 0,
+      }))
+      .sort((a, b) => b.calls - a.calls || a.tool.localeCompare(b.tool)),
+  };
+}
+
+export interface UsageDashboardExporterOptions {
+  dashboardId: string;
+  intervalMs: number;
+}
+
+/** Pushes usage snapshots to one custom dashboard on a timer. */
+export class UsageDashboardExporter {
+  private timer: ReturnType<typeof setInterval> | null = null;
+
+  constructor(
+    private readonly client: Pick<HarnessClient, "request">,
+    private readonly options: UsageDashboardExporterOptions,
+  ) {}
+
+  start(): void {
+    if (this.timer) return;
+    this.timer = setInterval(() => {
+      void this.push();
+    }, this.options.intervalMs);
+    this.timer.unref();
+    log.info("Exporting usage to Harness dashboard", { dashboard_id: this.options.dashboardId, interval_ms: this.options.intervalMs });
+  }
+
+  /** Stop the timer and push a final snapshot, so the dashboard has the last counts before shutdown. */
+  async stop(): Promise<void> {
+    if (!this.timer) return;
+    clearInterval(this.timer);
+    this.timer = null;
+    await this.push();
+  }
+
+  /** Push one snapshot now. Returns false (and logs) when the push fails. */
+  async push(): Promise<boolean> {
+    const snapshot = buildUsageDashboardSnapshot(summarizeUsageByAccount());
+    try {
+      await this.client.request({
+        method: "POST",
+        path: `/dashboard/v1/dashboards/${encodeURIComponent(this.options.dashboardId)}/custom-data`,
+        body: snapshot,
+      });
+      log.debug("Usage snapshot exported", { dashboard_id: this.options.dashboardId, calls: snapshot.totals.calls });
+      return true;
+    } catch (err) {
+      log.warn("Usage snapshot export failed; retrying next interval", {
+        dashboard_id: this.options.dashboardId,
+        error: err instanceof Error ? err.message : String(err),
+      });
+      return false;
+    }
+  }
+}
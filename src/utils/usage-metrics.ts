@@ -0,0 +1,181 @@
+/**
+ * Per-account usage metrics for shared deployments.
+ *
+ * One HTTP server can serve many Harness accounts (multi-user mode, OAuth).
+ * Platform owners who run it for several teams need to see who uses it and
+ * how much, so every counter here carries an `account` label.
+ *
+ * Account labels are capped: the first HARNESS_METRICS_MAX_ACCOUNTS distinct
+ * accounts get their own series, later ones are folded into "__other__" and
+ * counted in harness_mcp_metrics_accounts_overflow_total. This keeps the
+ * Prometheus series count bounded no matter how many accounts connect.
+ *
+ * Counters are process-wide and in-memory, like the cache and throttle
+ * counters, and reset when the server restarts.
+ */
+import type { AuditEvent } from "../audit/types.js";
+
+/** Label used for accounts beyond the cardinality limit. */
+export const OVERFLOW_ACCOUNT = "__other__";
+
+const DEFAULT_MAX_ACCOUNTS = 50;
+
+let maxAccounts = DEFAULT_MAX_ACCOUNTS;
+const trackedAccounts = new Set<string>();
+let overflowEvents = 0;
+let since = new Date().toISOString();
+
+interface ToolCounters {
+  calls: number;
+  errors: number;
+  blocked: number;
+  writes: number;
+  duration_ms: number;
+}
+
+interface AccountUsage {
+  first_seen: string;
+  last_seen: string;
+  tools: Map<string, ToolCounters>;
+  resource_types: Map<string, number>;
+}
+
+const usage = new Map<string, AccountUsage>();
+
+/** Set the account label cap (HARNESS_METRICS_MAX_ACCOUNTS). */
+export function configureUsageMetrics(options: { maxAccounts?: number }): void {
+  if (options.maxAccounts !== undefined && options.maxAccounts > 0) maxAccounts = options.maxAccounts;
+}
+
+/** Reset counters and tracked accounts (tests). */
+export function resetUsageMetrics(): void {
+  usage.clear();
+  trackedAccounts.clear();
+  overflowEvents = 0;
+  maxAccounts = DEFAULT_MAX_ACCOUNTS;
+  since = new Date().toISOString();
+}
+
+/**
+ * Metric label for an account: the account itself while under the cap,
+ * else OVERFLOW_ACCOUNT. Shared by every per-account metric in the process.
+ */
+export function accountLabel(account: string | undefined): string {
+  const id = account || "unknown";
+  if (trackedAccounts.has(id)) return id;
+  if (trackedAccounts.size < maxAccounts) {
+    trackedAccounts.add(id);
+    return id;
+  }
+  overflowEvents++;
+  return OVERFLOW_ACCOUNT;
+}
+
+/** Count one registry-dispatched API call from its audit event. */
+export function recordUsage(event: AuditEvent): void {
+  const account = accountLabel(event.account_id);
+  let row = usage.get(account);
+  if (!row) {
+    row = { first_seen: event.timestamp, last_seen: event.timestamp, tools: new Map(), resource_types: new Map() };
+    usage.set(account, row);
+  }
+  if (event.timestamp > row.last_seen) row.last_seen = event.timestamp;
+  let tool = row.tools.get(event.tool);
+  if (!tool) {
+    tool = { calls: 0, errors: 0, blocked: 0, writes: 0, duration_ms: 0 };
+    row.tools.set(event.tool, tool);
+  }
+  tool.calls++;
+  if (event.outcome === "error") tool.errors++;
+  if (event.outcome === "blocked") tool.blocked++;
+  if (event.risk !== "read") tool.writes++;
+  tool.duration_ms += event.duration_ms;
+  row.resource_types.set(event.resource_type, (row.resource_types.get(event.resource_type) ?? 0) + 1);
+}
+
+function escapeLabel(value: string): string {
+  return value.replace(/\\/g, "\\\\").replace(/"/g, '\\"').replace(/\n/g, "\\n");
+}
+
+/** Usage counters in Prometheus text exposition format. */
+export function renderUsageMetrics(): string {
+  const calls = [
+    "# HELP harness_mcp_api_calls_total Registry-dispatched Harness API calls by account, tool, and outcome.",
+    "# TYPE harness_mcp_api_calls_total counter",
+  ];
+  const duration = [
+    "# HELP harness_mcp_api_call_duration_ms_total Total time spent in Harness API calls by account and tool.",
+    "# TYPE harness_mcp_api_call_duration_ms_total counter",
+  ];
+  for (const account of [...usage.keys()].sort()) {
+    const row = usage.get(account)!;
+    for (const tool of [...row.tools.keys()].sort()) {
+      const c = row.tools.get(tool)!;
+      const labels = `account="${escapeLabel(account)}",tool="${escapeLabel(tool)}"`;
+      const success = c.calls - c.errors - c.blocked;
+      if (success > 0) calls.push(`harness_mcp_api_calls_total{${labels},outcome="success"} ${success}`);
+      if (c.errors > 0) calls.push(`harness_mcp_api_calls_total{${labels},outcome="error"} ${c.errors}`);
+      if (c.blocked > 0) calls.push(`harness_mcp_api_calls_total{${labels},outcome="blocked"} ${c.blocked}`);
+      duration.push(`harness_mcp_api_call_duration_ms_total{${labels}} ${Math.round(c.duration_ms)}`);
+    }
+  }
+  const overflow = [
+    "# HELP harness_mcp_metrics_accounts_overflow_total Metric updates folded into account=\"__other__\" by the account label cap.",
+    "# TYPE harness_mcp_metrics_accounts_overflow_total counter",
+    `harness_mcp_metrics_accounts_overflow_total ${overflowEvents}`,
+  ];
+  return `${[...calls, ...duration, ...overflow].join("\n")}\n`;
+}
+
+export interface AccountUsageSummary {
+  account_id: string;
+  calls: number;
+  errors: number;
+  blocked: number;
+  writes: number;
+  duration_ms: number;
+  first_seen: string;
+  last_seen: string;
+  tools: Array<{ tool: string } & ToolCounters>;
+  top_resource_types: Array<{ resource_type: string; calls: number }>;
+}
+
+export interface UsageReport {
+  generated_at: string;
+  since: string;
+  max_accounts: number;
+  overflow_events: number;
+  accounts: AccountUsageSummary[];
+}
+
+/** Per-account usage summary for chargeback, busiest account first. */
+export function summarizeUsageByAccount(): UsageReport {
+  const accounts = [...usage.entries()].map(([account, row]) => {
+    const tools = [...row.tools.entries()]
+      .map(([tool, c]) => ({ tool, ...c, duration_ms: Math.round(c.duration_ms) }))
+      .sort((a, b) => b.calls - a.calls || a.tool.localeCompare(b.tool));
+    const sum = (key: keyof ToolCounters) => tools.reduce((n, t) => n + t[key], 0);
+    return {
+      account_id: account,
+      calls: sum("calls"),
+      errors: sum("errors"),
+      blocked: sum("blocked"),
+      writes: sum("writes"),
+      duration_ms: sum("duration_ms"),
+      first_seen: row.first_seen,
+      last_seen: row.last_seen,
+      tools,
+      top_resource_types: [...row.resource_types.entries()]
+        .map(([resource_type, calls]) => ({ resource_type, calls }))
+        .sort((a, b) => b.calls - a.calls || a.resource_type.localeCompare(b.resource_type))
+        .slice(0, 10),
+    };
+  }).sort((a, b) => b.calls - a.calls || a.account_id.localeCompare(b.account_id));
+  return {
+    generated_at: new Date().toISOString(),
+    since,
+    max_accounts: maxAccounts,
+    overflow_events: overflowEvents,
+    accounts,
+  };
+}
@@ -1,6 +1,7 @@
 import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
-import { HarnessClient } from "../../src/client/harness-client.js";
+import { HarnessClient, parseRetryAfter } from "../../src/client/harness-client.js";
 import { HarnessApiError } from "../../src/utils/errors.js";
+import { runWithRequestSignal } from "../../src/utils/request-signal.js";
 import type { Config } from "../../src/config.js";
 
 function makeConfig(overrides: Partial<Config> = {}): Config {
@@ -367,6 +368,17 @@ describe("HarnessClient", () => {
       expect(headers["Harness-Account"]).toBe("test-account");
     });
 
+    it("sends the credential as a bearer when HARNESS_API_AUTH_SCHEME is bearer", async () => {
+      fetchSpy.mockResolvedValue(new Response(JSON.stringify({}), { status: 200 }));
+      const client = new HarnessClient(makeConfig({ HARNESS_API_KEY: "oauth-access-token", HARNESS_API_AUTH_SCHEME: "bearer" }));
+
+      await client.request({ path: "/test" });
+
+      const headers = fetchSpy.mock.calls[0][1]?.headers as Record<string, string>;
+      expect(headers["Authorization"]).toBe("Bearer oauth-access-token");
+      expect(headers["x-api-key"]).toBeUndefined();
+    });
+
     it("preserves caller-provided non-FME auth regardless of header casing", async () => {
       fetchSpy.mockResolvedValue(new Response(JSON.stringify({}), { status: 200 }));
       const client = new HarnessClient(makeConfig());
@@ -749,6 +761,83 @@ describe("HarnessClient", () => {
     });
   });
 
+  describe("request — retry policy", () => {
+    const failThenOk = (status: number, headers: Record<string, string> = {}) => {
+      fetchSpy
+        .mockResolvedValueOnce(new Response(JSON.stringify({ message: "fail" }), { status, headers }))
+        .mockResolvedValueOnce(new Response(JSON.stringify({ data: "ok" }), { status: 200 }));
+    };
+
+    it("does not retry POST on 503 without a retry policy", async () => {
+      fetchSpy.mockResolvedValue(new Response(JSON.stringify({ message: "unavailable" }), { status: 503 }));
+      const client = new HarnessClient(makeConfig({ HARNESS_RETRY_BASE_MS: 1 }));
+
+      await expect(client.request({ method: "POST", path: "/test", body: {} })).rejects.toThrow(HarnessApiError);
+      expect(fetchSpy).toHaveBeenCalledTimes(1);
+    });
+
+    it("retries HEAD and POST marked safe", async () => {
+      const client = new HarnessClient(makeConfig({ HARNESS_RETRY_BASE_MS: 1 }));
+
+      failThenOk(502);
+      await client.request({ method: "HEAD", path: "/test" });
+      failThenOk(502);
+      await client.request({ method: "POST", path: "/test", body: {}, retryPolicy: "safe" });
+      expect(fetchSpy).toHaveBeenCalledTimes(4);
+    });
+
+    it("retries idempotency_key_required only when an Idempotency-Key is sent", async () => {
+      const client = new HarnessClient(makeConfig({ HARNESS_RETRY_BASE_MS: 1 }));
+
+      fetchSpy.mockResolvedValueOnce(new Response(JSON.stringify({ message: "fail" }), { status: 500 }));
+      await expect(
+        client.request({ method: "POST", path: "/test", retryPolicy: "idempotency_key_required" }),
+      ).rejects.toThrow(HarnessApiError);
+      expect(fetchSpy).toHaveBeenCalledTimes(1);
+
+      failThenOk(500);
+      await client.request({
+        method: "POST",
+        path: "/test",
+        headers: { "Idempotency-Key": "abc" },
+        retryPolicy: "idempotency_key_required",
+      });
+      expect(fetchSpy).toHaveBeenCalledTimes(3);
+    });
+
+    it("waits for Retry-After, capped at HARNESS_RETRY_MAX_BACKOFF_MS", async () => {
+      const client = new HarnessClient(makeConfig({ HARNESS_RETRY_BASE_MS: 1, HARNESS_RETRY_MAX_BACKOFF_MS: 60 }));
+
+      failThenOk(429, { "Retry-After": "3600" });
+      const started = Date.now();
+      const result = await client.request<{ data: string }>({ path: "/test" });
+      const elapsed = Date.now() - started;
+
+      expect(result.data).toBe("ok");
+      expect(elapsed).toBeGreaterThanOrEqual(50);
+      expect(elapsed).toBeLessThan(2000);
+    });
+
+    it("stops waiting and throws 499 when the caller aborts during backoff", async () => {
+      fetchSpy.mockResolvedValue(new Response(JSON.stringify({ message: "fail" }), { status: 503 }));
+      const client = new HarnessClient(makeConfig({ HARNESS_RETRY_BASE_MS: 10_000, HARNESS_RETRY_MAX_BACKOFF_MS: 10_000 }));
+      const controller = new AbortController();
+      setTimeout(() => controller.abort(), 20);
+
+      await expect(client.request({ path: "/test", signal: controller.signal })).rejects.toMatchObject({ statusCode: 499 });
+      expect(fetchSpy).toHaveBeenCalledTimes(1);
+    });
+
+    it("parses Retry-After as seconds or HTTP-date", () => {
+      const now = Date.parse("2026-01-01T00:00:00Z");
+      expect(parseRetryAfter("5", now)).toBe(5000);
+      expect(parseRetryAfter("Thu, 01 Jan 2026 00:00:10 GMT", now)).toBe(10_000);
+      expect(parseRetryAfter("Wed, 31 Dec 2025 23:59:00 GMT", now)).toBe(0);
+      expect(parseRetryAfter("soon", now)).toBeUndefined();
+      expect(parseRetryAfter(null, now)).toBeUndefined();
+    });
+  });
+
   describe("request — timeout", () => {
     it("throws HarnessApiError with 408 on timeout", async () => {
       fetchSpy.mockImplementation(() => new Promise((_, reject) => {
@@ -842,6 +931,92 @@ describe("HarnessClient", () => {
     });
   });
 
+  describe("request — tool call cancellation", () => {
+    /** fetch that never settles until its signal aborts, like a long poll. */
+    function hangUntilAborted() {
+      fetchSpy.mockImplementation((_url: unknown, init?: RequestInit) => new Promise((_resolve, reject) => {
+        init?.signal?.addEventListener("abort", () => {
+          const err = new Error("The operation was aborted");
+          err.name = "AbortError";
+          reject(err);
+        });
+      }));
+    }
+
+    afterEach(() => {
+      vi.useRealTimers();
+    });
+
+    it("aborts an in-flight request when the active tool call is cancelled and leaves no timers behind", async () => {
+      vi.useFakeTimers();
+      hangUntilAborted();
+      const client = new HarnessClient(makeConfig({ HARNESS_MAX_RETRIES: 3 }));
+      const controller = new AbortController();
+
+      const pending = runWithRequestSignal(controller.signal, () => client.request({ path: "/test" }));
+      const settled = pending.catch((err: unknown) => err);
+      await vi.waitFor(() => expect(fetchSpy).toHaveBeenCalledTimes(1));
+      expect(vi.getTimerCount()).toBe(1);
+
+      controller.abort();
+      const err = await settled;
+      expect(err).toBeInstanceOf(HarnessApiError);
+      expect((err as HarnessApiError).statusCode).toBe(499);
+      expect(fetchSpy).toHaveBeenCalledTimes(1);
+      expect(vi.getTimerCount()).toBe(0);
+    });
+
+    it("aborts an in-flight stream request when the active tool call is cancelled", async () => {
+      vi.useFakeTimers();
+      hangUntilAborted();
+      const client = new HarnessClient(makeConfig());
+      const controller = new AbortController();
+
+      const settled = runWithRequestSignal(controller.signal, () => client.requestStream({ method: "GET", path: "/gateway/log-service/stream" }))
+        .catch((err: unknown) => err);
+      await vi.waitFor(() => expect(fetchSpy).toHaveBeenCalledTimes(1));
+
+      controller.abort();
+      expect(((await settled) as HarnessApiError).statusCode).toBe(499);
+      expect(vi.getTimerCount()).toBe(0);
+    });
+
+    it("prefers an explicit signal over the active tool call's", async () => {
+      fetchSpy.mockResolvedValue(new Response(JSON.stringify({ data: "ok" }), { status: 200 }));
+      const client = new HarnessClient(makeConfig());
+      const ambient = new AbortController();
+      ambient.abort();
+
+      await expect(runWithRequestSignal(ambient.signal, () => client.request({ path: "/test", signal: new AbortController().signal })))
+        .resolves.toEqual({ data: "ok" });
+    });
+
+    it("clears the timeout timer when fetch fails", async () => {
+      vi.useFakeTimers();
+      fetchSpy.mockRejectedValue(new Error("socket hang up"));
+      const client = new HarnessClient(makeConfig({ HARNESS_MAX_RETRIES: 0 }));
+
+      await expect(client.request({ path: "/test" })).rejects.toThrow("socket hang up");
+      expect(vi.getTimerCount()).toBe(0);
+    });
+
+    it("stops waiting for a rate limit token when cancelled", async () => {
+      vi.useFakeTimers();
+      fetchSpy.mockImplementation(async () => new Response(JSON.stringify({ data: "ok" }), { status: 200 }));
+      const client = new HarnessClient(makeConfig({ HARNESS_RATE_LIMIT_RPS: 1 }));
+      await client.request({ path: "/first" });
+      const controller = new AbortController();
+
+      const settled = client.request({ path: "/second", signal: controller.signal }).catch((err: unknown) => err);
+      await vi.waitFor(() => expect(vi.getTimerCount()).toBe(1));
+      controller.abort();
+
+      expect(((await settled) as HarnessApiError).statusCode).toBe(499);
+      expect(fetchSpy).toHaveBeenCalledTimes(1);
+      expect(vi.getTimerCount()).toBe(0);
+    });
+  });
+
   describe("request — non-JSON responses", () => {
     it("throws clear error for HTML response (proxy error page)", async () => {
       const html = "<html><body><h1>502 Bad Gateway</h1></body></html>";
@@ -64,7 +64,6 @@ const ALLOWED_INLINE_EXTRACTOR_COUNTS: Record<string, number> = {
   "src/registry/toolsets/ccm.ts": 1,
   "src/registry/toolsets/governance.ts": 1,
   "src/registry/toolsets/iacm.ts": 1,
-  "src/registry/toolsets/idp.ts": 1,
   "src/registry/toolsets/knowledge-graph.ts": 1,
   "src/registry/toolsets/sto.ts": 3,
 };
@@ -101,8 +100,10 @@ const SEARCH_DIR = join(SRC, "search");
 const ALLOWED_GLOBAL_FETCH_FILES = new Set([
   "src/client/harness-client.ts",
   "src/utils/log-resolver.ts",
+  "src/utils/sbom-download.ts",
   "src/audit/sinks/webhook.ts",
   "src/search/remote-provider.ts",
+  "src/utils/result-processors.ts",
 ]);
 
 /** Only this file may instantiate HarnessClient in production src/. */
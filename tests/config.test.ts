@@ -1,3 +1,5 @@
+import { tmpdir } from "node:os";
+import { join } from "node:path";
 import { describe, it, expect } from "vitest";
 import {
   ConfigSchema,
@@ -240,6 +242,22 @@ describe("ConfigSchema", () => {
     }
   });
 
+  it("defaults HARNESS_MCP_IP_RATE_LIMIT_PER_MIN to 60 and allows 0 to disable", () => {
+    const withDefault = ConfigSchema.safeParse(validConfig);
+    expect(withDefault.success).toBe(true);
+    if (withDefault.success) {
+      expect(withDefault.data.HARNESS_MCP_IP_RATE_LIMIT_PER_MIN).toBe(60);
+    }
+
+    const disabled = ConfigSchema.safeParse({ ...validConfig, HARNESS_MCP_IP_RATE_LIMIT_PER_MIN: "0" });
+    expect(disabled.success).toBe(true);
+    if (disabled.success) {
+      expect(disabled.data.HARNESS_MCP_IP_RATE_LIMIT_PER_MIN).toBe(0);
+    }
+
+    expect(ConfigSchema.safeParse({ ...validConfig, HARNESS_MCP_IP_RATE_LIMIT_PER_MIN: "-1" }).success).toBe(false);
+  });
+
   it("rejects negative HARNESS_MCP_TRUST_PROXY", () => {
     const result = ConfigSchema.safeParse({
       ...validConfig,
@@ -248,6 +266,46 @@ describe("ConfigSchema", () => {
     expect(result.success).toBe(false);
   });
 
+  it("defaults HARNESS_SSE_HEARTBEAT_MS to 15s and allows 0 to disable", () => {
+    const withDefault = ConfigSchema.safeParse(validConfig);
+    expect(withDefault.success).toBe(true);
+    if (withDefault.success) {
+      expect(withDefault.data.HARNESS_SSE_HEARTBEAT_MS).toBe(15_000);
+    }
+
+    const disabled = ConfigSchema.safeParse({
+      ...validConfig,
+      HARNESS_SSE_HEARTBEAT_MS: "0",
+    });
+    expect(disabled.success).toBe(true);
+    if (disabled.success) {
+      expect(disabled.data.HARNESS_SSE_HEARTBEAT_MS).toBe(0);
+    }
+  });
+
+  it("requires an HTTPS HARNESS_MCP_OAUTH_RESOURCE when HARNESS_MCP_OAUTH_ISSUER is set", () => {
+    const missing = ConfigSchema.safeParse({ ...validConfig, HARNESS_MCP_OAUTH_ISSUER: "https://app.harness.io/oidc" });
+    expect(missing.success).toBe(false);
+
+    const insecure = ConfigSchema.safeParse({
+      ...validConfig,
+      HARNESS_MCP_OAUTH_ISSUER: "https://app.harness.io/oidc",
+      HARNESS_MCP_OAUTH_RESOURCE: "http://mcp.example.com/mcp",
+    });
+    expect(insecure.success).toBe(false);
+
+    const valid = ConfigSchema.safeParse({
+      ...validConfig,
+      HARNESS_MCP_OAUTH_ISSUER: "https://app.harness.io/oidc",
+      HARNESS_MCP_OAUTH_RESOURCE: "https://mcp.example.com/mcp",
+    });
+    expect(valid.success).toBe(true);
+    if (valid.success) {
+      expect(valid.data.HARNESS_MCP_OAUTH_SCOPES).toBe("openid");
+      expect(valid.data.HARNESS_API_AUTH_SCHEME).toBe("api_key");
+    }
+  });
+
   it("coerces string numbers for timeout and retries", () => {
     const result = ConfigSchema.safeParse({
       ...validConfig,
@@ -328,6 +386,23 @@ describe("ConfigSchema", () => {
     ).toThrow("HARNESS_FME_API_KEY must not be set in multi-user mode");
   });
 
+  it("requires a dashboard API key for usage export in multi-user mode", () => {
+    expect(() =>
+      ConfigSchema.parse({
+        HARNESS_MCP_MODE: "multi-user",
+        HARNESS_ACCOUNT_ID: "acct123",
+        HARNESS_USAGE_DASHBOARD_ID: "42",
+      }),
+    ).toThrow("HARNESS_USAGE_DASHBOARD_API_KEY is required");
+    const result = ConfigSchema.parse({
+      HARNESS_MCP_MODE: "multi-user",
+      HARNESS_ACCOUNT_ID: "acct123",
+      HARNESS_USAGE_DASHBOARD_ID: "42",
+      HARNESS_USAGE_DASHBOARD_API_KEY: "sat.acct123.svc.secret",
+    });
+    expect(result.HARNESS_USAGE_EXPORT_INTERVAL_MS).toBe(300_000);
+  });
+
   it("requires HARNESS_API_KEY in single-user mode", () => {
     expect(() =>
       ConfigSchema.parse({
@@ -592,10 +667,18 @@ describe("loadConfig — account ID extraction", () => {
     });
   });
 
-  it("defaults HARNESS_HF_CACHE_DIR to /tmp/hf-cache", () => {
+  it("defaults HARNESS_HF_CACHE_DIR to hf-cache in the OS temp directory", () => {
     withEnv({ HARNESS_API_KEY: "pat.acct123.tok.sec" }, () => {
       const config = loadConfig();
-      expect(config.HARNESS_HF_CACHE_DIR).toBe("/tmp/hf-cache");
+      expect(config.HARNESS_HF_CACHE_DIR).toBe(join(tmpdir(), "hf-cache"));
+    });
+  });
+
+  it("places the default HARNESS_HF_CACHE_DIR under HARNESS_TEMP_DIR", () => {
+    withEnv({ HARNESS_API_KEY: "pat.acct123.tok.sec", HARNESS_TEMP_DIR: "/scratch" }, () => {
+      const config = loadConfig();
+      expect(config.HARNESS_TEMP_DIR).toBe("/scratch");
+      expect(config.HARNESS_HF_CACHE_DIR).toBe(join("/scratch", "hf-cache"));
     });
   });
 
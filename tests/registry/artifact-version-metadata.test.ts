@@ -0,0 +1,171 @@
+/**
+ * Tests for artifact_version get: package-type-specific metadata (Docker
+ * platforms, Maven coordinates, npm dist-tags) from the HAR version APIs.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+import { buildPackageMetadata } from "../../src/utils/har-metadata.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "registries",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const BASE = "/har/api/v1/registry/test-account/default/test-project";
+
+describe("artifact_version get", () => {
+  it("returns Docker platforms from the image manifests", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      const path = opts.path as string;
+      if (path.endsWith("/summary")) return { data: { packageType: "DOCKER", imageName: "api", version: "1.2.0", isQuarantined: false } };
+      if (path.endsWith("/docker/manifests")) {
+        return {
+          data: {
+            manifests: [
+              { osArch: "linux/amd64", digest: "sha256:aaa", size: "42 MB", createdAt: "2025-07-01T00:00:00Z" },
+              { osArch: "linux/arm64/v8", digest: "sha256:bbb", size: "40 MB" },
+            ],
+          },
+        };
+      }
+      if (path.endsWith("/docker/details")) return { data: { pullCommand: "docker pull pkg.harness.io/test-account/docker-reg/api:1.2.0", size: "42 MB", downloadCount: 7 } };
+      throw new Error(`unexpected path ${path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "artifact_version", "get", {
+      registry_id: "docker-reg",
+      artifact_id: "api",
+      version: "1.2.0",
+    }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0].path).toBe(`${BASE}/docker-reg/+/artifact/api/+/version/1.2.0/summary`);
+    expect(result).toMatchObject({ registry_id: "docker-reg", artifact_id: "api", version: "1.2.0", package_type: "DOCKER", size: "42 MB", download_count: 7, quarantined: false });
+    expect(result.metadata).toEqual({
+      kind: "docker",
+      image: "api",
+      tag: "1.2.0",
+      platforms: [
+        { os: "linux", architecture: "amd64", variant: null, digest: "sha256:aaa", size: "42 MB", created_at: "2025-07-01T00:00:00Z" },
+        { os: "linux", architecture: "arm64", variant: "v8", digest: "sha256:bbb", size: "40 MB", created_at: null },
+      ],
+      pull_command: "docker pull pkg.harness.io/test-account/docker-reg/api:1.2.0",
+    });
+    expect(result.errors).toBeUndefined();
+  });
+
+  it("returns Maven GAV coordinates", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      const path = opts.path as string;
+      if (path.endsWith("/summary")) return { data: { packageType: "MAVEN" } };
+      if (path.endsWith("/maven/details")) return { data: { size: "1 MB", metadata: { files: [{ name: "core-3.1.0.pom" }, { name: "core-3.1.0.jar" }] } } };
+      throw new Error(`unexpected path ${path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "artifact_version", "get", {
+      registry_id: "maven-reg",
+      artifact_id: "io.harness:core",
+      version: "3.1.0",
+    }) as Record<string, any>;
+
+    expect(result.metadata).toEqual({
+      kind: "maven",
+      group_id: "io.harness",
+      artifact_id: "core",
+      version: "3.1.0",
+      coordinates: "io.harness:core:3.1.0",
+      packaging: "jar",
+      files: ["core-3.1.0.pom", "core-3.1.0.jar"],
+    });
+  });
+
+  it("returns npm dist-tags from the package document and reports partial failures", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      const path = opts.path as string;
+      if (path.endsWith("/summary")) return { data: { packageType: "NPM", isQuarantined: true, quarantineReason: "CVE-2025-0001" } };
+      if (path.endsWith("/details")) throw new HarnessApiError("details unavailable", 500);
+      if (path === "/pkg/test-account/npm-reg/npm/@acme%2fui") {
+        return {
+          "dist-tags": { latest: "2.0.0", next: "2.1.0-rc.1" },
+          versions: {
+            "2.0.0": {
+              name: "@acme/ui",
+              dependencies: { react: "^18.0.0" },
+              peerDependencies: { "react-dom": "^18.0.0" },
+              dist: { tarball: "https://pkg.harness.io/ui-2.0.0.tgz", integrity: "sha512-abc" },
+            },
+          },
+        };
+      }
+      throw new Error(`unexpected path ${path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "artifact_version", "get", {
+      registry_id: "npm-reg",
+      artifact_id: "@acme/ui",
+      version: "2.0.0",
+    }) as Record<string, any>;
+
+    expect(result).toMatchObject({ package_type: "NPM", quarantined: true, quarantine_reason: "CVE-2025-0001" });
+    expect(result.metadata).toEqual({
+      kind: "npm",
+      name: "@acme/ui",
+      version: "2.0.0",
+      dist_tags: { latest: "2.0.0", next: "2.1.0-rc.1" },
+      tags_on_version: ["latest"],
+      dependencies: { react: "^18.0.0" },
+      peer_dependencies: { "react-dom": "^18.0.0" },
+      tarball: "https://pkg.harness.io/ui-2.0.0.tgz",
+      integrity: "sha512-abc",
+    });
+    expect(result.errors).toEqual(["details: details unavailable"]);
+  });
+
+  it("requires registry_id and artifact_id", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({}));
+    await expect(registry.dispatch(makeClient(request), "artifact_version", "get", { version: "1.0.0" }))
+      .rejects.toThrow(/Missing required param\(s\) for artifact_version.get: registry_id, artifact_id/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
+
+describe("buildPackageMetadata", () => {
+  it("returns raw details for package types without a typed shape", () => {
+    expect(buildPackageMetadata({ artifact: "tool", version: "1.0", package_type: "CARGO", details: { metadata: { edition: "2021" } } }))
+      .toEqual({ kind: "cargo", details: { edition: "2021" } });
+  });
+
+  it("reads Helm app version", () => {
+    expect(buildPackageMetadata({ artifact: "web", version: "0.3.0", package_type: "HELM", details: { pullCommand: "helm pull oci://x/web", metadata: { appVersion: "1.9" } } }))
+      .toEqual({ kind: "helm", chart: "web", version: "0.3.0", app_version: "1.9", pull_command: "helm pull oci://x/web" });
+  });
+});
@@ -0,0 +1,100 @@
+/**
+ * Tests for cost_cluster_workload: Kubernetes cost per namespace, workload,
+ * node pool, or node for one cluster, and the rows driving an increase.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "ccm",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+/** CCM GraphQL stand-in returning one cluster grid. */
+function gridApi() {
+  return vi.fn(async () => ({
+    data: {
+      perspectiveGrid: {
+        data: [
+          {
+            name: "payments", id: "payments", cost: 1200, costTrend: 50,
+            clusterData: { namespace: "payments", idleCost: 300, unallocatedCost: 0, utilizedCost: 900, cpuBillingAmount: 800, memoryBillingAmount: 350, storageCost: 50, efficiencyScore: 71 },
+          },
+          {
+            name: "search", id: "search", cost: 2000, costTrend: 5,
+            clusterData: { namespace: "search", idleCost: 100, unallocatedCost: 0, utilizedCost: 1900, cpuBillingAmount: 1500, memoryBillingAmount: 500, storageCost: 0 },
+          },
+          { name: "batch", id: "batch", cost: 100, costTrend: -20, clusterData: {} },
+        ],
+      },
+      perspectiveTotalCount: 3,
+    },
+  }));
+}
+
+describe("cost_cluster_workload", () => {
+  it("filters to the cluster and groups cluster-only data by namespace", async () => {
+    const registry = new Registry(makeConfig());
+    const request = gridApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_cluster_workload", "list", { cluster: "prod-eks", time_filter: "LAST_30_DAYS" }) as Record<string, any>;
+
+    const { variables } = request.mock.calls[0]![0].body;
+    expect(variables.isClusterOnly).toBe(true);
+    expect(variables.groupBy).toEqual([{ entityGroupBy: expect.objectContaining({ fieldId: "namespace", identifier: "CLUSTER" }) }]);
+    expect(variables.filters[0]).toEqual({ idFilter: { field: expect.objectContaining({ fieldId: "clusterName" }), operator: "IN", values: ["prod-eks"] } });
+    expect(result.items[0]).toEqual({
+      namespace: "payments", cost: 1200, cost_trend: 50,
+      idle_cost: 300, unallocated_cost: 0, utilized_cost: 900, cpu_cost: 800, memory_cost: 350, storage_cost: 50, efficiency_score: 71,
+    });
+    expect(result.total).toBe(3);
+  });
+
+  it("ranks rows by how much their cost grew, not by percentage", async () => {
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(gridApi()), "cost_cluster_workload", "list", { cluster: "prod-eks" }) as Record<string, any>;
+
+    expect(result.largest_increases).toEqual([
+      { name: "payments", increase: 400, cost_trend: 50 },
+      { name: "search", increase: 95.24, cost_trend: 5 },
+    ]);
+  });
+
+  it("narrows workloads to a namespace and rejects unknown groupings", async () => {
+    const registry = new Registry(makeConfig());
+    const request = gridApi();
+
+    await registry.dispatch(makeClient(request), "cost_cluster_workload", "list", { cluster: "prod-eks", group_by: "workload", namespace: "payments" });
+
+    const { variables } = request.mock.calls[0]![0].body;
+    expect(variables.groupBy[0].entityGroupBy.fieldId).toBe("workloadName");
+    expect(variables.filters[1]).toEqual({ idFilter: { field: expect.objectContaining({ fieldId: "namespace" }), operator: "IN", values: ["payments"] } });
+    await expect(registry.dispatch(makeClient(request), "cost_cluster_workload", "list", { cluster: "prod-eks", group_by: "pod" })).rejects.toThrow(/group_by must be one of/);
+  });
+});
@@ -0,0 +1,122 @@
+/**
+ * Tests for cost_commitment_breakdown: utilisation and savings per service,
+ * per cloud account, and per day, plus estimated savings per cloud account.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "ccm",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const WINDOW = { start_date: "2026-09-01", end_date: "2026-09-30" };
+
+/** Commitment Orchestrator stand-in: utilisation per commitment type, scaled per service. */
+function commitmentApi() {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path.endsWith("/estimated_savings")) return { response: { annualized_savings: 1200, monthly_savings: "100" } };
+    if (opts.body?.Service === "Amazon ElastiCache") throw new Error("500 Internal Server Error");
+    const scale = opts.body?.Service === "Amazon Relational Database Service" ? 0.5 : 1;
+    return {
+      response: {
+        "Savings Plans": {
+          table: { utilization: 90 * scale, compute_spend: 1000 },
+          chart: [{ date: "2026-09-01", utilization_percentage: 88 }, { date: "2026-09-02", utilization_percentage: 92 }],
+        },
+        "Reserved Instances": { table: { utilization: 75 * scale }, chart: [] },
+      },
+    };
+  });
+}
+
+describe("cost_commitment_breakdown", () => {
+  it("reads each default service and reports failures next to the other rows", async () => {
+    const registry = new Registry(makeConfig());
+    const request = commitmentApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_commitment_breakdown", "get", { ...WINDOW }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(3);
+    expect(request.mock.calls.every(([opts]) => opts.path === "/lw/co/api/accounts/test-account/v1/detail/commitment_utilisation")).toBe(true);
+    expect(result.rows).toEqual([
+      { service: "Amazon Elastic Compute Cloud - Compute", commitment_type: "Savings Plans", utilization: 90, compute_spend: 1000 },
+      { service: "Amazon Elastic Compute Cloud - Compute", commitment_type: "Reserved Instances", utilization: 75 },
+      { service: "Amazon Relational Database Service", commitment_type: "Savings Plans", utilization: 45, compute_spend: 1000 },
+      { service: "Amazon Relational Database Service", commitment_type: "Reserved Instances", utilization: 37.5 },
+    ]);
+    expect(result.errors).toEqual([{ service: "Amazon ElastiCache", error: expect.stringContaining("500") }]);
+  });
+
+  it("breaks savings down per cloud account and adds estimated savings from recommended purchases", async () => {
+    const registry = new Registry(makeConfig());
+    const request = commitmentApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_commitment_breakdown", "get", {
+      ...WINDOW,
+      metric: "savings",
+      group_by: "account",
+      cloud_account_ids: "111,222",
+    }) as Record<string, any>;
+
+    const savingsCalls = request.mock.calls.filter(([opts]) => opts.path.endsWith("/v1/detail/savings"));
+    expect(savingsCalls.map(([opts]) => opts.body.cloud_account_id)).toEqual([["111"], ["222"]]);
+    expect(request.mock.calls.map(([opts]) => opts.path)).toContain("/lw/co/api/accounts/test-account/v2/setup/222/estimated_savings");
+    expect(result.rows.map((r: any) => r.cloud_account_id)).toEqual(["111", "111", "222", "222"]);
+    expect(result.recommended_purchases).toEqual([
+      { cloud_account_id: "111", annualized_savings: 1200, monthly_savings: 100 },
+      { cloud_account_id: "222", annualized_savings: 1200, monthly_savings: 100 },
+    ]);
+  });
+
+  it("returns the daily trend per commitment type for group_by=day", async () => {
+    const registry = new Registry(makeConfig());
+    const request = commitmentApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_commitment_breakdown", "get", { ...WINDOW, group_by: "day" }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(result.trend["Savings Plans"]).toEqual([
+      { date: "2026-09-01", utilization_percentage: 88 },
+      { date: "2026-09-02", utilization_percentage: 92 },
+    ]);
+    expect(result.trend["Reserved Instances"]).toEqual([]);
+  });
+
+  it("validates the grouping before calling Harness", async () => {
+    const registry = new Registry(makeConfig());
+    const request = commitmentApi();
+    const client = makeClient(request);
+
+    await expect(registry.dispatch(client, "cost_commitment_breakdown", "get", { ...WINDOW, group_by: "account" }))
+      .rejects.toThrow(/cloud_account_ids/);
+    await expect(registry.dispatch(client, "cost_commitment_breakdown", "get", { ...WINDOW, metric: "coverage" }))
+      .rejects.toThrow(/metric must be/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
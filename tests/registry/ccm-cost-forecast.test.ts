@@ -0,0 +1,145 @@
+/**
+ * Tests for cost_forecast: daily history for a perspective or filter set,
+ * projected with confidence bounds, plus Harness's own perspective forecast.
+ */
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "ccm",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const DAY_MS = 86_400_000;
+/** 14 history days start here when history_days=14 and today is 2026-10-16. */
+const HISTORY_START = Date.UTC(2026, 9, 2);
+
+/** CCM GraphQL stand-in: spend of 100 + 2·day split across two clouds, starting at `firstDay`. */
+function ccmApi(firstDay = 0) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.body.operationName === "FetchPerspectiveTimeSeries") {
+      const stats = Array.from({ length: 14 - firstDay }, (_, i) => {
+        const day = firstDay + i;
+        return {
+          time: HISTORY_START + day * DAY_MS,
+          values: [{ key: { name: "AWS" }, value: 60 + day }, { key: { name: "GCP" }, value: 40 + day }],
+        };
+      });
+      return { data: { perspectiveTimeSeriesStats: { stats } } };
+    }
+    if (opts.body.operationName === "FetchPerspectiveDetailsSummaryWithBudget") {
+      return { data: { perspectiveForecastCost: { cost: { value: 3100, statsLabel: "Forecasted total cost", statsDescription: "of next 30 days" } } } };
+    }
+    throw new Error(`unexpected ${opts.body.operationName}`);
+  });
+}
+
+describe("cost_forecast", () => {
+  beforeEach(() => {
+    vi.useFakeTimers();
+    vi.setSystemTime(new Date("2026-10-16T12:00:00Z"));
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  it("projects the daily trend per month and in total, next to the Harness forecast", async () => {
+    const registry = new Registry(makeConfig());
+    const request = ccmApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_forecast", "get", {
+      perspective_id: "persp1", history_days: 14, horizon_days: 20,
+    }) as Record<string, any>;
+
+    const [history] = request.mock.calls[0]!;
+    expect(history).toMatchObject({ method: "POST", path: "/ccm/api/graphql" });
+    expect(history.body.variables.filters).toEqual([
+      { viewMetadataFilter: { viewId: "persp1", isPreview: false } },
+      expect.objectContaining({ timeFilter: expect.objectContaining({ operator: "AFTER", value: HISTORY_START }) }),
+      expect.objectContaining({ timeFilter: expect.objectContaining({ operator: "BEFORE", value: Date.UTC(2026, 9, 16) - 1 }) }),
+    ]);
+    expect(result.history).toEqual({ start: "2026-10-02", end: "2026-10-15", days: 14, total: 1582, daily_average: 113 });
+    expect(result.forecast).toEqual({
+      start: "2026-10-16",
+      end: "2026-11-04",
+      horizon_days: 20,
+      confidence: 80,
+      forecast: 2940,
+      lower: 2940,
+      upper: 2940,
+      by_month: [
+        { month: "2026-10", days: 16, forecast: 2288, lower: 2288, upper: 2288 },
+        { month: "2026-11", days: 4, forecast: 652, lower: 652, upper: 652 },
+      ],
+    });
+    expect(result.trend).toMatchObject({ change_per_day: 2, direction: "rising", r_squared: 1 });
+    expect(result.harness_forecast).toEqual({ value: 3100, label: "Forecasted total cost", description: "of next 30 days" });
+  });
+
+  it("forecasts a filter set without a perspective, mapping label keys to label filters", async () => {
+    const registry = new Registry(makeConfig());
+    const request = ccmApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_forecast", "get", {
+      filters: JSON.stringify({ region: ["us-east-1"], team: "payments,search" }), history_days: 14,
+    }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    const filters = request.mock.calls[0]![0].body.variables.filters;
+    expect(filters.slice(0, 2)).toEqual([
+      { idFilter: { field: expect.objectContaining({ fieldId: "region" }), operator: "IN", values: ["us-east-1"] } },
+      { idFilter: { field: expect.objectContaining({ fieldId: "labels.value", fieldName: "team" }), operator: "IN", values: ["payments", "search"] } },
+    ]);
+    expect(result.filters).toEqual({ region: ["us-east-1"], team: ["payments", "search"] });
+    expect(result.forecast.horizon_days).toBe(30);
+    expect(result.harness_forecast).toBeUndefined();
+  });
+
+  it("drops days before spend started and declines to fit too short a history", async () => {
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(ccmApi(9)), "cost_forecast", "get", {
+      perspective_id: "persp1", history_days: 14,
+    }) as Record<string, any>;
+
+    expect(result.history).toMatchObject({ start: "2026-10-11", days: 5 });
+    expect(result.forecast).toBeNull();
+    expect(result._hint).toMatch(/needs at least 7/);
+  });
+
+  it("validates the scope and the parameters before calling CCM", async () => {
+    const registry = new Registry(makeConfig());
+    const request = ccmApi();
+
+    await expect(registry.dispatch(makeClient(request), "cost_forecast", "get", {})).rejects.toThrow(/perspective_id, filters, or both/);
+    await expect(registry.dispatch(makeClient(request), "cost_forecast", "get", { perspective_id: "p", horizon_days: 400 })).rejects.toThrow(/horizon_days/);
+    await expect(registry.dispatch(makeClient(request), "cost_forecast", "get", { perspective_id: "p", confidence: 99 })).rejects.toThrow(/confidence/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
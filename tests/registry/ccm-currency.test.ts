@@ -0,0 +1,169 @@
+/**
+ * Tests for CCM currency normalization: the `currency` param on perspective
+ * cost reads, the cost_currency resource, and conversion-rate resolution.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import { ccmConversionRate } from "../../src/registry/toolsets/ccm.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "ccm",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+/** Account billed in EUR; CCM keeps USD→EUR factors for two months. */
+function route(opts: Record<string, any>): unknown {
+  if (opts.path === "/ccm/api/currency-preference/conversion-factors") {
+    return {
+      data: [
+        { sourceCurrency: "USD", destinationCurrency: "EUR", conversionFactor: 0.9, month: "2026-01" },
+        { sourceCurrency: "USD", destinationCurrency: "EUR", conversionFactor: 0.8, month: "2026-02" },
+      ],
+    };
+  }
+  const operation = opts.body?.operationName;
+  if (operation === "FetchCcmMetaData") {
+    return { data: { ccmMetaData: { currencyPreference: { destinationCurrency: "EUR", symbol: "€", locale: "de-DE", setupTime: 0 } } } };
+  }
+  if (operation === "FetchperspectiveGrid") {
+    return { data: { perspectiveGrid: { data: [{ name: "EC2", id: "ec2", cost: 100, costTrend: 4.2 }] }, perspectiveTotalCount: 1 } };
+  }
+  if (operation === "FetchPerspectiveTimeSeries") {
+    return { data: { perspectiveTimeSeriesStats: { stats: [{ time: 1, values: [{ key: { id: "ec2", name: "EC2" }, value: 10 }] }] } } };
+  }
+  if (operation === "FetchPerspectiveDetailsSummaryWithBudget") {
+    return {
+      data: {
+        perspectiveTrendStats: { cost: { statsLabel: "Total Cost", statsValue: "€1,000.00", value: 1000 }, idleCost: null },
+        perspectiveForecastCost: { cost: { statsLabel: "Forecast", statsValue: "€1,200.00", value: 1200 } },
+      },
+    };
+  }
+  throw new Error(`unexpected ${opts.path} ${operation}`);
+}
+
+describe("currency param on perspective cost reads", () => {
+  it("converts breakdown costs from the account currency to USD and adds a currency block", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => route(opts));
+
+    const result = await registry.dispatch(makeClient(request), "cost_breakdown", "list", {
+      perspective_id: "p1",
+      currency: "usd",
+    }) as { items: Array<Record<string, unknown>>; currency: Record<string, unknown> };
+
+    expect(result.items[0]).toMatchObject({ cost: 125, costDisplay: "$125.00", costTrend: 4.2 });
+    expect(result.currency).toEqual({ code: "USD", source: "EUR", rate: 1.25 });
+    const grid = request.mock.calls.find(([opts]) => opts.body?.operationName === "FetchperspectiveGrid")![0];
+    expect(grid.body.variables).not.toHaveProperty("currency");
+  });
+
+  it("converts time series values and summary stats", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(async (opts) => route(opts));
+
+    const series = await registry.dispatch(client, "cost_timeseries", "list", { perspective_id: "p1", group_by: "product", currency: "USD" }) as Record<string, any>;
+    expect(series.items[0].values[0].value).toBe(12.5);
+    expect(series.total).toBe(1);
+
+    const summary = await registry.dispatch(client, "cost_summary", "list", { perspective_id: "p1", currency: "USD" }) as Record<string, any>;
+    expect(summary.trendStats.cost).toMatchObject({ value: 1250, statsValue: "$1,250.00" });
+    expect(summary.trendStats.idleCost).toBeNull();
+    expect(summary.forecastCost.cost.value).toBe(1500);
+  });
+
+  it("reports costs as-is with a currency block when the account already uses the requested currency", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => route(opts));
+
+    const result = await registry.dispatch(makeClient(request), "cost_breakdown", "list", { perspective_id: "p1", currency: "EUR" }) as Record<string, any>;
+
+    expect(result.items[0].cost).toBe(100);
+    expect(result.currency).toEqual({ code: "EUR", source: "EUR" });
+    expect(request.mock.calls.some(([opts]) => opts.path === "/ccm/api/currency-preference/conversion-factors")).toBe(false);
+  });
+
+  it("makes no currency lookups without the param", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => route(opts));
+
+    const result = await registry.dispatch(makeClient(request), "cost_breakdown", "list", { perspective_id: "p1" }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(result).toEqual({ items: [{ name: "EC2", id: "ec2", cost: 100, costTrend: 4.2 }], total: 1 });
+  });
+
+  it("fails with the account currency named when no factor exists", async () => {
+    const registry = new Registry(makeConfig());
+
+    await expect(registry.dispatch(makeClient(async (opts) => route(opts)), "cost_breakdown", "list", { perspective_id: "p1", currency: "JPY" }))
+      .rejects.toThrow(/No CCM conversion factor from EUR .* to JPY/);
+  });
+
+  it("rejects a currency that is not an ISO 4217 code", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn();
+
+    await expect(registry.dispatch(makeClient(request), "cost_breakdown", "list", { perspective_id: "p1", currency: "dollars" }))
+      .rejects.toThrow(/3-letter ISO 4217/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
+
+describe("cost_currency", () => {
+  it("get returns the account currency preference", async () => {
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(async (opts) => route(opts)), "cost_currency", "get", {});
+
+    expect(result).toMatchObject({ currency: "EUR", symbol: "€", locale: "de-DE", set_at: null, configured: true });
+  });
+
+  it("get defaults to USD when no preference was set", async () => {
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(async () => ({ data: { ccmMetaData: { currencyPreference: null } } })), "cost_currency", "get", {});
+
+    expect(result).toMatchObject({ currency: "USD", configured: false });
+  });
+});
+
+describe("ccmConversionRate", () => {
+  const factors = [
+    { source: "USD", destination: "EUR", factor: 0.8, month: "2026-02" },
+    { source: "USD", destination: "GBP", factor: 0.75, month: "2026-02" },
+  ];
+
+  it("uses direct, inverse, and USD-pivot factors", () => {
+    expect(ccmConversionRate(factors, "USD", "EUR")).toBe(0.8);
+    expect(ccmConversionRate(factors, "EUR", "USD")).toBe(1.25);
+    expect(ccmConversionRate(factors, "EUR", "GBP")).toBeCloseTo(0.9375);
+    expect(ccmConversionRate(factors, "EUR", "EUR")).toBe(1);
+    expect(ccmConversionRate(factors, "EUR", "JPY")).toBeUndefined();
+  });
+});
@@ -0,0 +1,162 @@
+/**
+ * Tests for asset governance: rules, dry-run evaluations, and
+ * executions with their matched resources and savings.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "ccm",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const RULE = {
+  uuid: "rule1",
+  name: "unattached-ebs",
+  description: "Delete unattached EBS volumes",
+  cloudProvider: "AWS",
+  resourceType: "ebs",
+  isOOTB: true,
+  rulesYaml: "policies:\n  - name: unattached-ebs\n    resource: ebs\n    actions: [delete]\n",
+};
+
+const EXECUTION = {
+  uuid: "exec1",
+  ruleIdentifier: "rule1",
+  ruleName: "unattached-ebs",
+  cloudProvider: "AWS",
+  targetAccount: "123456789012",
+  targetRegions: ["us-east-1"],
+  executionStatus: "SUCCESS",
+  isDryRun: true,
+  resourceCount: 3,
+  potentialSavings: 42.5,
+  realizedSavings: 0,
+  createdAt: 1760000000000,
+};
+
+/** Asset governance API stand-in. */
+function governanceApi() {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path === "/ccm/api/governance/rule/list") {
+      const ids = opts.body.query.ruleIds as string[] | undefined;
+      const rules = ids && !ids.includes("rule1") ? [] : [RULE];
+      return { data: { rules, totalItems: rules.length } };
+    }
+    if (opts.path === "/ccm/api/governance/enqueueAdhoc") return { data: { ruleExecutionId: ["exec1"] } };
+    if (opts.path === "/ccm/api/governance/execution/list") {
+      return { data: { ruleExecution: [EXECUTION, { ...EXECUTION, uuid: "exec2", isDryRun: false, potentialSavings: 10, realizedSavings: 7.25 }], totalItems: 2 } };
+    }
+    if (opts.path === "/ccm/api/governance/execution/exec1") return { data: EXECUTION };
+    if (opts.path === "/ccm/api/governance/execution/details/exec1") {
+      return { data: JSON.stringify([{ VolumeId: "vol-1" }, { VolumeId: "vol-2" }, { VolumeId: "vol-3" }]) };
+    }
+    throw new Error(`unexpected ${opts.method} ${opts.path}`);
+  });
+}
+
+describe("cost_governance_rule", () => {
+  it("lists rules without their YAML and passes the filters through", async () => {
+    const registry = new Registry(makeConfig());
+    const request = governanceApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_governance_rule", "list", { cloud_provider: "aws", ootb: "true", search: "ebs" }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0].body).toEqual({ query: { limit: 50, offset: 0, cloudProvider: "AWS", search: "ebs", isOOTB: true } });
+    expect(result).toEqual({
+      items: [{ rule_id: "rule1", name: "unattached-ebs", description: "Delete unattached EBS volumes", cloud_provider: "AWS", resource_type: "ebs", ootb: true }],
+      total: 1,
+    });
+  });
+
+  it("gets one rule with its YAML and reports a missing one", async () => {
+    const registry = new Registry(makeConfig());
+    const request = governanceApi();
+
+    const rule = await registry.dispatch(makeClient(request), "cost_governance_rule", "get", { rule_id: "rule1" }) as Record<string, any>;
+
+    expect(rule.rulesYaml).toContain("resource: ebs");
+    await expect(registry.dispatch(makeClient(request), "cost_governance_rule", "get", { rule_id: "nope" })).rejects.toThrow(/"nope" not found/);
+  });
+
+  it("always enqueues the evaluation as a dry run with the rule's YAML", async () => {
+    const registry = new Registry(makeConfig());
+    const request = governanceApi();
+
+    const result = await registry.dispatchExecute(makeClient(request), "cost_governance_rule", "dry_run", {
+      rule_id: "rule1", target_account: "123456789012", regions: "us-east-1, us-west-2", body: { isDryRun: false },
+    }) as Record<string, any>;
+
+    const enqueue = request.mock.calls.find(([opts]) => opts.path === "/ccm/api/governance/enqueueAdhoc")![0];
+    expect(enqueue.body).toEqual({
+      ruleId: "rule1",
+      policy: RULE.rulesYaml,
+      ruleCloudProviderType: "AWS",
+      isDryRun: true,
+      targetRegions: ["us-east-1", "us-west-2"],
+      targetAccountDetails: [{ targetInfo: "123456789012" }],
+    });
+    expect(result).toMatchObject({ rule_id: "rule1", rule_name: "unattached-ebs", dry_run: true, execution_ids: ["exec1"] });
+  });
+
+  it("needs one target account and at least one region before enqueueing", async () => {
+    const registry = new Registry(makeConfig());
+    const request = governanceApi();
+
+    await expect(registry.dispatchExecute(makeClient(request), "cost_governance_rule", "dry_run", { rule_id: "rule1", regions: "us-east-1" }))
+      .rejects.toThrow(/target_account is required/);
+    await expect(registry.dispatchExecute(makeClient(request), "cost_governance_rule", "dry_run", { rule_id: "rule1", target_account: "123456789012" }))
+      .rejects.toThrow(/regions is required/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
+
+describe("cost_governance_execution", () => {
+  it("lists executions with savings totals", async () => {
+    const registry = new Registry(makeConfig());
+    const request = governanceApi();
+
+    const result = await registry.dispatch(makeClient(request), "cost_governance_execution", "list", { rule_id: "rule1", status: "SUCCESS" }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0].body).toEqual({ ruleExecutionFilter: { limit: 25, offset: 0, ruleIds: ["rule1"], executionStatus: "SUCCESS" } });
+    expect(result.items[0]).toMatchObject({ execution_id: "exec1", status: "SUCCESS", dry_run: true, resource_count: 3, potential_savings: 42.5 });
+    expect(result.total).toBe(2);
+    expect(result.savings).toEqual({ potential: 52.5, realized: 7.25 });
+  });
+
+  it("gets one execution, or the resources it matched", async () => {
+    const registry = new Registry(makeConfig());
+    const request = governanceApi();
+
+    const summary = await registry.dispatch(makeClient(request), "cost_governance_execution", "get", { execution_id: "exec1" }) as Record<string, any>;
+    const resources = await registry.dispatch(makeClient(request), "cost_governance_execution", "get", { execution_id: "exec1", view: "resources" }) as Record<string, any>;
+
+    expect(summary).toMatchObject({ execution_id: "exec1", target_account: "123456789012", realized_savings: 0 });
+    expect(resources).toEqual({ execution_id: "exec1", total: 3, resources: [{ VolumeId: "vol-1" }, { VolumeId: "vol-2" }, { VolumeId: "vol-3" }] });
+  });
+});
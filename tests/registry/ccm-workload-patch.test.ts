@@ -0,0 +1,108 @@
+import { describe, expect, it } from "vitest";
+import YAML from "yaml";
+import { ccmWorkloadPatchExtract } from "../../src/registry/extractors.js";
+import { ccmToolset } from "../../src/registry/toolsets/ccm.js";
+
+const raw = {
+  status: "SUCCESS",
+  data: {
+    id: "rec-1",
+    containerRecommendations: {
+      app: {
+        current: { requests: { cpu: "1", memory: "2Gi" }, limits: { cpu: "2", memory: "4Gi" } },
+        burstable: { requests: { cpu: "250m", memory: "512Mi" }, limits: { memory: "1Gi" } },
+        guaranteed: { requests: { cpu: "300m", memory: "600Mi" }, limits: { cpu: "300m", memory: "600Mi" } },
+        percentileBased: { p95: { requests: { cpu: "400m", memory: "700Mi" } } },
+      },
+      sidecar: { current: { requests: { cpu: "100m" } } },
+    },
+  },
+};
+
+type Patch = {
+  preset: string;
+  workload: { kind: string; name: string; namespace: string };
+  containers: Array<{ name: string; current: unknown; proposed: unknown }>;
+  skipped_containers?: string[];
+  patch_yaml: string | null;
+  kubectl_command: string | null;
+  pull_request_steps?: string[];
+  _hint?: string;
+};
+
+describe("ccmWorkloadPatchExtract", () => {
+  it("builds a Deployment strategic merge patch from the recommended preset", () => {
+    const result = ccmWorkloadPatchExtract(raw, { recommendation_id: "rec-1", workload_name: "web", namespace: "prod" }) as Patch;
+
+    expect(result.preset).toBe("recommended");
+    expect(result.containers).toEqual([
+      {
+        name: "app",
+        current: { requests: { cpu: "1", memory: "2Gi" }, limits: { cpu: "2", memory: "4Gi" } },
+        proposed: { requests: { cpu: "250m", memory: "512Mi" }, limits: { memory: "1Gi" } },
+      },
+    ]);
+    expect(result.skipped_containers).toEqual(["sidecar"]);
+    expect(YAML.parse(result.patch_yaml!)).toEqual({
+      apiVersion: "apps/v1",
+      kind: "Deployment",
+      metadata: { name: "web", namespace: "prod" },
+      spec: { template: { spec: { containers: [{ name: "app", resources: { requests: { cpu: "250m", memory: "512Mi" }, limits: { memory: "1Gi" } } }] } } },
+    });
+    expect(result.kubectl_command).toBe("kubectl -n prod patch deployment web --type strategic --patch-file patch.yaml");
+    expect(result).not.toHaveProperty("pull_request_steps");
+    expect(result).not.toHaveProperty("_hint");
+  });
+
+  it("supports guaranteed and percentile presets", () => {
+    const guaranteed = ccmWorkloadPatchExtract(raw, { preset: "guaranteed" }) as Patch;
+    expect(guaranteed.containers[0]?.proposed).toEqual({ requests: { cpu: "300m", memory: "600Mi" }, limits: { cpu: "300m", memory: "600Mi" } });
+
+    const p95 = ccmWorkloadPatchExtract(raw, { preset: "p95" }) as Patch;
+    expect(p95.containers[0]?.proposed).toEqual({ requests: { cpu: "400m", memory: "700Mi" } });
+  });
+
+  it("nests CronJob patches under jobTemplate", () => {
+    const result = ccmWorkloadPatchExtract(raw, { kind: "cronjob", workload_name: "nightly", namespace: "batch" }) as Patch;
+    const patch = YAML.parse(result.patch_yaml!);
+    expect(patch.apiVersion).toBe("batch/v1");
+    expect(patch.kind).toBe("CronJob");
+    expect(patch.spec.jobTemplate.spec.template.spec.containers[0].name).toBe("app");
+  });
+
+  it("leaves placeholders and a hint when the workload identity is unknown", () => {
+    const result = ccmWorkloadPatchExtract(raw) as Patch;
+    expect(result.workload).toMatchObject({ name: "<workload-name>", namespace: "<namespace>" });
+    expect(result._hint).toMatch(/workload_name and namespace/);
+  });
+
+  it("returns no patch when no container has the preset", () => {
+    const result = ccmWorkloadPatchExtract({ data: { containerRecommendations: { app: { current: {} } } } }, { preset: "p99" }) as Patch;
+    expect(result.patch_yaml).toBeNull();
+    expect(result.kubectl_command).toBeNull();
+    expect(result._hint).toMatch(/p99/);
+  });
+
+  it("adds Harness Code PR steps when repo_id and file_path are passed", () => {
+    const result = ccmWorkloadPatchExtract(raw, {
+      workload_name: "web",
+      namespace: "prod",
+      repo_id: "infra",
+      file_path: "k8s/web.yaml",
+      branch: "develop",
+    }) as Patch;
+    expect(result.pull_request_steps).toHaveLength(4);
+    expect(result.pull_request_steps?.[2]).toContain("resource_type='commit'");
+    expect(result.pull_request_steps?.[2]).toContain("new_branch: 'ccm-rightsize-web'");
+    expect(result.pull_request_steps?.[3]).toContain("target_branch: 'develop'");
+  });
+});
+
+describe("cost_workload_patch resource", () => {
+  it("is a read-only get against the workload recommendation detail", () => {
+    const resource = ccmToolset.resources.find((r) => r.resourceType === "cost_workload_patch");
+    expect(resource?.operations.get?.path).toBe("/ccm/api/recommendation/details/workload");
+    expect(resource?.operations.get?.queryParams).toEqual({ recommendation_id: "id" });
+    expect(resource?.operations.get?.operationPolicy.risk).toBe("read");
+  });
+});
@@ -197,6 +197,66 @@ describe("chaos_experiment list/get", () => {
     expect(result.workflowManifest).toBeDefined();
   });
 
+  it("list: attaches each experiment's most recent run as last_run", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({
+      data: [
+        {
+          experimentID: "a",
+          name: "pod-delete",
+          recentExperimentRunDetails: [
+            { experimentRunID: "run-2", notifyID: "n-2", phase: "Completed", resiliencyScore: 80, updatedAt: "1790000000000" },
+            { experimentRunID: "run-1", phase: "Error", resiliencyScore: 20 },
+          ],
+        },
+        { experimentID: "b", name: "never-run", recentExperimentRunDetails: [] },
+      ],
+      pagination: { totalItems: 2 },
+    });
+
+    const result = (await registry.dispatch(makeClient(mockRequest), "chaos_experiment", "list", {
+      project_id: "proj1",
+    })) as { items: Array<Record<string, unknown>> };
+
+    expect(result.items[0]!.last_run).toEqual({
+      run_id: "run-2",
+      notify_id: "n-2",
+      phase: "Completed",
+      resilience_score: 80,
+      run_at: new Date(1790000000000).toISOString(),
+    });
+    expect(result.items[1]!.last_run).toBeUndefined();
+
+    const compact = registry.getResource("chaos_experiment").compactItem!(result.items[0]!);
+    expect(compact).toMatchObject({ experimentId: "a", name: "pod-delete", last_run: { run_id: "run-2", resilience_score: 80 } });
+    expect(compact.recentExperimentRunDetails).toBeUndefined();
+  });
+
+  it("get: adds recent runs with resilience scores and their summary", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({
+      experimentID: "exp-1",
+      name: "pod-delete",
+      recentExperimentRunDetails: [
+        { experimentRunID: "run-3", phase: "Running" },
+        { experimentRunID: "run-2", phase: "Completed", resiliencyScore: 90 },
+        { experimentRunID: "run-1", phase: "Completed", resiliencyScore: 61 },
+      ],
+    });
+
+    const result = (await registry.dispatch(makeClient(mockRequest), "chaos_experiment", "get", {
+      experiment_id: "exp-1",
+      project_id: "proj1",
+    })) as Record<string, unknown>;
+
+    expect(result.name).toBe("pod-delete");
+    expect(result.recent_runs).toEqual([
+      { run_id: "run-3", phase: "Running" },
+      { run_id: "run-2", phase: "Completed", resilience_score: 90 },
+      { run_id: "run-1", phase: "Completed", resilience_score: 61 },
+    ]);
+    expect(result.resilience).toEqual({ runs: 3, scored_runs: 2, latest_score: 90, average_score: 76 });
+    expect(result._hint).toContain("chaos_experiment_run");
+  });
+
   it("get: uses org and project when provided in input", async () => {
     const mockRequest = vi.fn().mockResolvedValue({ experimentID: "e1", name: "E1" });
     const client = makeClient(mockRequest);
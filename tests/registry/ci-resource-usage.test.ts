@@ -0,0 +1,184 @@
+/**
+ * Tests for ci_resource_usage: measured CPU, memory, and cache statistics of
+ * CI stages and steps, and right-sizing suggestions.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { parseCpuMillicores, parseMemoryMib } from "../../src/registry/extractors.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const EXECUTION = {
+  data: {
+    pipelineExecutionSummary: {
+      pipelineIdentifier: "build",
+      status: "Success",
+      layoutNodeMap: {
+        s1: {
+          nodeIdentifier: "build_and_test",
+          name: "Build and Test",
+          module: "ci",
+          status: "Success",
+          startTs: 1752000000000,
+          endTs: 1752000600000,
+          moduleInfo: { ci: { infraDetailsList: [{ infraType: "KubernetesDirect", infraOSType: "Linux", infraArchType: "Amd64" }] } },
+        },
+        s2: { nodeIdentifier: "deploy", name: "Deploy", module: "cd", status: "Success" },
+      },
+    },
+    executionGraph: {
+      nodeMap: {
+        n1: {
+          baseFqn: "pipeline.stages.build_and_test.spec.execution.steps.compile",
+          identifier: "compile",
+          name: "Compile",
+          stepType: "Run",
+          status: "Success",
+          startTs: 1752000060000,
+          endTs: 1752000360000,
+          stepParameters: { spec: { resources: { limits: { cpu: "2", memory: "4Gi" } } } },
+        },
+        n2: {
+          baseFqn: "pipeline.stages.build_and_test.spec.execution.steps.restore",
+          identifier: "restore",
+          name: "Restore Cache",
+          stepType: "RestoreCacheGCS",
+          status: "Success",
+          startTs: 1752000000000,
+          endTs: 1752000030000,
+        },
+        n3: {
+          baseFqn: "pipeline.stages.build_and_test.spec.execution.steps.unit",
+          identifier: "unit",
+          name: "Unit Tests",
+          stepType: "Run",
+          status: "Success",
+          startTs: 1752000360000,
+          endTs: 1752000600000,
+          stepParameters: { spec: { resources: { limits: { cpu: "500m", memory: "1Gi" } } } },
+        },
+      },
+    },
+  },
+};
+
+const USAGE = {
+  data: {
+    stages: [
+      {
+        stageIdentifier: "build_and_test",
+        cpu: { avg: 0.9, max: 1.5, limit: 4 },
+        memory: { avg: 2147483648, max: 3221225472, limit: 8589934592 },
+        cache: { hits: 1, misses: 3 },
+        steps: [
+          { stepIdentifier: "compile", cpu: { avg: 0.2, max: 0.5 }, memory: { avg: "1Gi", max: "3.9Gi" } },
+          { stepIdentifier: "unit", cpu: { avg: 0.3, max: "480m" }, memory: { max: "600Mi" } },
+        ],
+      },
+    ],
+  },
+};
+
+describe("quantity parsing", () => {
+  it("reads Kubernetes CPU and memory quantities", () => {
+    expect(parseCpuMillicores("500m")).toBe(500);
+    expect(parseCpuMillicores("1.5")).toBe(1500);
+    expect(parseCpuMillicores(2)).toBe(2000);
+    expect(parseCpuMillicores("two")).toBeUndefined();
+    expect(parseMemoryMib("512Mi")).toBe(512);
+    expect(parseMemoryMib("2Gi")).toBe(2048);
+    expect(parseMemoryMib("1G")).toBe(954);
+    expect(parseMemoryMib(1073741824)).toBe(1024);
+  });
+});
+
+describe("ci_resource_usage get", () => {
+  it("joins measured usage onto CI stages and steps and suggests right-sizing", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => opts.path.startsWith("/ci/") ? USAGE : EXECUTION);
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "ci_resource_usage", "get", { execution_id: "exec-1" }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ path: "/pipeline/api/pipelines/execution/v2/exec-1", params: { renderFullBottomGraph: true } });
+    expect(request.mock.calls[1]![0]).toMatchObject({ path: "/ci/execution/resource-usage", params: { planExecutionId: "exec-1" } });
+    expect(result.measured).toBe(true);
+    expect(result.stages).toHaveLength(1);
+
+    const stage = result.stages[0];
+    expect(stage).toMatchObject({
+      id: "build_and_test",
+      duration_ms: 600000,
+      infrastructure: { type: "KubernetesDirect", os: "Linux", arch: "Amd64" },
+      cpu: { avg: 900, max: 1500, limit: 4000, utilization: 0.38 },
+      memory: { avg: 2048, max: 3072, limit: 8192, utilization: 0.38 },
+      cache: { hits: 1, misses: 3, hit_rate: 0.25 },
+    });
+    expect(stage.steps.map((s: Record<string, unknown>) => s.id)).toEqual(["restore", "compile", "unit"]);
+    expect(stage.steps[0]).toMatchObject({ cache_step: true, duration_ms: 30000 });
+    expect(stage.steps[1]).toMatchObject({
+      cpu: { max: 500, limit: 2000, utilization: 0.25 },
+      memory: { max: 3994, limit: 4096, utilization: 0.98 },
+    });
+
+    expect(result.recommendations).toEqual([
+      expect.objectContaining({ stage_id: "build_and_test", resource: "cpu", action: "decrease", current_limit: 4000, suggested_limit: 2000 }),
+      expect.objectContaining({ stage_id: "build_and_test", resource: "memory", action: "decrease", current_limit: 8192, suggested_limit: 4096 }),
+      expect.objectContaining({ stage_id: "build_and_test", resource: "cache", action: "review_cache_key" }),
+      expect.objectContaining({ stage_id: "build_and_test", step_id: "compile", resource: "cpu", action: "decrease", suggested_limit: 700 }),
+      expect.objectContaining({ stage_id: "build_and_test", step_id: "compile", resource: "memory", action: "increase", suggested_limit: 6016 }),
+      expect.objectContaining({ stage_id: "build_and_test", step_id: "unit", resource: "cpu", action: "increase", suggested_limit: 800 }),
+    ]);
+  });
+
+  it("falls back to configured limits when usage is unavailable", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.startsWith("/ci/")) throw new HarnessApiError("Not found", 404);
+      return EXECUTION;
+    });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "ci_resource_usage", "get", { execution_id: "exec-1", stage_id: "build_and_test" }) as Record<string, any>;
+
+    expect(result.measured).toBe(false);
+    expect(result.errors).toEqual([expect.stringContaining("resource usage:")]);
+    expect(result.stages[0].steps[1]).toMatchObject({ cpu: { avg: null, max: null, limit: 2000, utilization: null } });
+    expect(result.recommendations).toEqual([]);
+  });
+
+  it("notes an execution without the requested CI stage", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => opts.path.startsWith("/ci/") ? {} : EXECUTION);
+    const result = await registry.dispatch(makeClient(request), "ci_resource_usage", "get", { execution_id: "exec-1", stage_id: "deploy" }) as Record<string, any>;
+    expect(result.stages).toEqual([]);
+    expect(result.note).toBe('No CI stage "deploy" in this execution.');
+  });
+});
@@ -0,0 +1,156 @@
+/**
+ * Tests for Harness Code commit history and blame: commit list author filter
+ * and the file_blame summary used during incident triage.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { codeBlameExtract, codeCommitListExtract } from "../../src/registry/extractors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "repositories",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+function blamePart(sha: string, name: string, when: string, lines: number) {
+  return {
+    commit: {
+      sha,
+      title: `change ${sha}`,
+      author: { identity: { name, email: `${name}@example.com` }, when },
+    },
+    lines: Array.from({ length: lines }, (_, i) => `line ${i}`),
+  };
+}
+
+describe("codeCommitListExtract", () => {
+  it("unwraps commits and total_commits", () => {
+    const result = codeCommitListExtract({ commits: [{ sha: "a" }, { sha: "b" }], total_commits: 42 });
+    expect(result).toEqual({ items: [{ sha: "a" }, { sha: "b" }], total: 42 });
+  });
+
+  it("accepts a bare array", () => {
+    expect(codeCommitListExtract([{ sha: "a" }])).toEqual({ items: [{ sha: "a" }], total: 1 });
+  });
+
+  it("returns an empty list for unexpected shapes", () => {
+    expect(codeCommitListExtract(null)).toEqual({ items: [], total: 0 });
+  });
+});
+
+describe("codeBlameExtract", () => {
+  const raw = [
+    blamePart("c1", "alice", "2024-01-01T00:00:00Z", 3),
+    blamePart("c2", "bob", "2024-06-01T12:00:00+02:00", 2),
+    blamePart("c1", "alice", "2024-01-01T00:00:00Z", 1),
+  ];
+
+  it("numbers hunks and picks the most recent change", () => {
+    const result = codeBlameExtract(raw, { repo_id: "r", path: "config/app.yaml" }) as Record<string, any>;
+    expect(result.line_count).toBe(6);
+    expect(result.hunks.map((h: any) => [h.line_from, h.line_to, h.sha])).toEqual([
+      [1, 3, "c1"],
+      [4, 5, "c2"],
+      [6, 6, "c1"],
+    ]);
+    expect(result.last_change).toMatchObject({ sha: "c2", name: "bob", line_ranges: [[4, 5]] });
+  });
+
+  it("aggregates authors by lines owned", () => {
+    const result = codeBlameExtract(raw, {}) as Record<string, any>;
+    expect(result.authors).toEqual([
+      { name: "alice", email: "alice@example.com", lines: 4, last_change: "2024-01-01T00:00:00Z" },
+      { name: "bob", email: "bob@example.com", lines: 2, last_change: "2024-06-01T12:00:00+02:00" },
+    ]);
+  });
+
+  it("offsets line numbers by line_from", () => {
+    const result = codeBlameExtract([blamePart("c1", "alice", "2024-01-01T00:00:00Z", 2)], { line_from: "10" }) as Record<string, any>;
+    expect(result.hunks[0]).toMatchObject({ line_from: 10, line_to: 11 });
+    expect(result.line_count).toBe(2);
+  });
+
+  it("returns a null last_change for empty blame", () => {
+    const result = codeBlameExtract([], {}) as Record<string, any>;
+    expect(result.last_change).toBeNull();
+    expect(result.hunks).toEqual([]);
+  });
+});
+
+describe("commit list filters", () => {
+  it("forwards author, path, and time range", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn().mockResolvedValue({ commits: [], total_commits: 0 });
+
+    await registry.dispatch(makeClient(mockRequest), "commit", "list", {
+      repo_id: "my-repo",
+      path: "config/app.yaml",
+      author: "alice@example.com",
+      since: 1700000000000,
+    });
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "GET",
+      path: "/code/api/v1/repos/my-repo/commits",
+      params: expect.objectContaining({
+        path: "config/app.yaml",
+        author: "alice@example.com",
+        since: 1700000000000,
+      }),
+    }));
+  });
+});
+
+describe("file_blame resource", () => {
+  it("calls the blame API with scope and line range and returns the summary", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn().mockResolvedValue([blamePart("c1", "alice", "2024-01-01T00:00:00Z", 2)]);
+
+    const result = await registry.dispatch(makeClient(mockRequest), "file_blame", "get", {
+      repo_id: "my-repo",
+      path: "config/app.yaml",
+      git_ref: "main",
+      org_id: "default",
+      project_id: "test-project",
+      line_from: 5,
+      line_to: 6,
+    }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "GET",
+      path: "/code/api/v1/repos/my-repo/blame/config%2Fapp.yaml",
+      params: expect.objectContaining({
+        orgIdentifier: "default",
+        projectIdentifier: "test-project",
+        git_ref: "main",
+        line_from: 5,
+        line_to: 6,
+      }),
+    }));
+    expect(result.last_change).toMatchObject({ sha: "c1", line_ranges: [[5, 6]] });
+  });
+});
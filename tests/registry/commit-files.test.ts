@@ -0,0 +1,135 @@
+/**
+ * Tests for commit_files: a multi-file Harness Code commit by path and
+ * content, with CREATE/UPDATE and blob shas resolved from the base branch.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "repositories",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+/** Existing files on the base branch, by path → blob sha. */
+function codeServer(files: Record<string, string>, commit: unknown = { commit_id: "c0ffee" }) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    const content = /\/content\/(.+)$/.exec(opts.path);
+    if (content) {
+      const path = decodeURIComponent(content[1]!);
+      if (!(path in files)) throw new HarnessApiError("path not found", 404);
+      return { type: "file", path, sha: files[path], content: { encoding: "base64", data: "", size: 0, data_size: 0 } };
+    }
+    if (opts.method === "POST" && opts.path.endsWith("/commits")) return commit;
+    throw new Error(`unexpected ${opts.method} ${opts.path}`);
+  });
+}
+
+describe("commit commit_files", () => {
+  it("creates missing files, updates existing ones with their sha, and deletes", async () => {
+    const request = codeServer({ "deploy/values.yaml": "sha-values", "old.txt": "sha-old" });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatchExecute(makeClient(request), "commit", "commit_files", {
+      repo_id: "payments",
+      body: {
+        branch: "main",
+        new_branch: "bump-replicas",
+        title: "Bump replicas to 3",
+        files: [
+          { path: "deploy/values.yaml", content: "replicas: 3\n" },
+          { path: "/deploy/prod.yaml", content: "env: prod\n" },
+          { path: "old.txt", delete: true },
+        ],
+      },
+    }) as Record<string, any>;
+
+    const lookup = request.mock.calls.find(([opts]) => decodeURIComponent(opts.path).includes("/content/deploy/values.yaml"))![0];
+    expect(lookup.params).toMatchObject({ git_ref: "main" });
+    const commit = request.mock.calls.find(([opts]) => opts.method === "POST")![0];
+    expect(commit.path).toBe("/code/api/v1/repos/payments/commits");
+    expect(commit.body).toEqual({
+      title: "Bump replicas to 3",
+      branch: "main",
+      new_branch: "bump-replicas",
+      actions: [
+        { action: "UPDATE", path: "deploy/values.yaml", payload: "replicas: 3\n", encoding: "utf8", sha: "sha-values" },
+        { action: "CREATE", path: "deploy/prod.yaml", payload: "env: prod\n", encoding: "utf8" },
+        { action: "DELETE", path: "old.txt", sha: "sha-old" },
+      ],
+    });
+    expect(result).toMatchObject({
+      commit_id: "c0ffee",
+      branch: "bump-replicas",
+      base_branch: "main",
+      files: [
+        { path: "deploy/values.yaml", action: "UPDATE" },
+        { path: "deploy/prod.yaml", action: "CREATE" },
+        { path: "old.txt", action: "DELETE" },
+      ],
+    });
+    expect(result.next_step).toContain("source_branch: 'bump-replicas', target_branch: 'main'");
+  });
+
+  it("refuses to delete a file that does not exist, before committing", async () => {
+    const request = codeServer({});
+    const registry = new Registry(makeConfig());
+
+    await expect(registry.dispatchExecute(makeClient(request), "commit", "commit_files", {
+      repo_id: "payments",
+      body: { branch: "main", title: "Remove", files: [{ path: "gone.txt", delete: true }] },
+    })).rejects.toThrow('Cannot delete "gone.txt": it does not exist on main.');
+    expect(request.mock.calls.some(([opts]) => opts.method === "POST")).toBe(false);
+  });
+
+  it("validates the file list", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(codeServer({}));
+    const run = (files: unknown) => registry.dispatchExecute(client, "commit", "commit_files", {
+      repo_id: "payments",
+      body: { branch: "main", title: "Change", files },
+    });
+
+    await expect(run([])).rejects.toThrow(/files is required/);
+    await expect(run([{ path: "a.txt" }])).rejects.toThrow(/needs content, or delete: true/);
+    await expect(run([{ path: "a.txt", content: "1" }, { path: "a.txt", content: "2" }])).rejects.toThrow(/more than once/);
+  });
+
+  it("reports rule violations on a dry run without a pull request hint", async () => {
+    const request = codeServer({}, { dry_run_rules: true, rule_violations: [{ rule: { identifier: "protect-main" } }] });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatchExecute(makeClient(request), "commit", "commit_files", {
+      repo_id: "payments",
+      body: { branch: "main", new_branch: "x", title: "Try", files: [{ path: "a.txt", content: "a" }], dry_run_rules: true },
+    }) as Record<string, any>;
+
+    expect(result).toMatchObject({ dry_run: true, rule_violations: [{ rule: { identifier: "protect-main" } }] });
+    expect(result.next_step).toBeUndefined();
+  });
+});
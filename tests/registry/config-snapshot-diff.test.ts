@@ -0,0 +1,133 @@
+/**
+ * Tests for config_snapshot_diff: net configuration changes between two
+ * points in time, reconstructed from audit events.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "audit",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+function auditEvent(auditId: string, action: string, type: string, identifier: string, at: string, user = "dev@example.com") {
+  return {
+    auditId,
+    action,
+    timestamp: Date.parse(at),
+    resource: { type, identifier, labels: { resourceName: identifier.toUpperCase() } },
+    resourceScope: { accountIdentifier: "test-account", orgIdentifier: "default", projectIdentifier: "test-project" },
+    authenticationInfo: { principal: { type: "USER", identifier: "u1" }, labels: { username: user } },
+  };
+}
+
+// Newest first, as the audit API returns them.
+const EVENTS = [
+  auditEvent("a6", "DELETE", "CONNECTOR", "old-git", "2025-07-05T00:00:00Z"),
+  auditEvent("a5", "UPDATE", "PIPELINE", "deploy", "2025-07-04T00:00:00Z", "ops@example.com"),
+  auditEvent("a4", "DELETE", "PIPELINE", "scratch", "2025-07-03T00:00:00Z"),
+  auditEvent("a3", "CREATE", "PIPELINE", "scratch", "2025-07-03T00:00:00Z"),
+  auditEvent("a2", "CREATE", "GOVERNANCE_POLICY", "no-prod-friday", "2025-07-02T00:00:00Z"),
+  auditEvent("a1", "UPDATE", "PIPELINE", "deploy", "2025-07-01T12:00:00Z"),
+];
+
+describe("config_snapshot_diff", () => {
+  it("classifies each entity's net change across the window", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (_opts: Record<string, any>) => ({
+      status: "SUCCESS",
+      data: { totalElements: EVENTS.length, content: EVENTS },
+    }));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "config_snapshot_diff", "list", {
+      from_time: "2025-07-01T00:00:00Z",
+      to_time: "2025-07-08T00:00:00Z",
+    }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+    expect(mockRequest.mock.calls[0]![0]).toMatchObject({
+      method: "POST",
+      path: "/audit/api/audits/list",
+      body: {
+        scopes: [{ accountIdentifier: "test-account", orgIdentifier: "default", projectIdentifier: "test-project" }],
+        resources: [{ type: "PIPELINE" }, { type: "CONNECTOR" }, { type: "GOVERNANCE_POLICY" }],
+        startTime: Date.parse("2025-07-01T00:00:00Z"),
+        endTime: Date.parse("2025-07-08T00:00:00Z"),
+      },
+    });
+    expect(result.summary).toEqual({ created: 1, deleted: 1, modified: 1, created_and_deleted: 1 });
+    expect(result.items.map((i: any) => [i.change, i.entity_type, i.identifier])).toEqual([
+      ["created", "policy", "no-prod-friday"],
+      ["deleted", "connector", "old-git"],
+      ["modified", "pipeline", "deploy"],
+      ["created_and_deleted", "pipeline", "scratch"],
+    ]);
+    expect(result.items[2]).toMatchObject({
+      change_count: 2,
+      actions: ["UPDATE", "UPDATE"],
+      first_change_at: "2025-07-01T12:00:00.000Z",
+      last_change_at: "2025-07-04T00:00:00.000Z",
+      changed_by: ["dev@example.com", "ops@example.com"],
+      first_version_id: "a1",
+      last_version_id: "a5",
+    });
+    expect(result.truncated).toBeUndefined();
+  });
+
+  it("diffs the YAML at from_time against the YAML at to_time with include_diff", async () => {
+    const registry = new Registry(makeConfig());
+    const yaml: Record<string, { oldYaml: string; newYaml: string }> = {
+      a1: { oldYaml: "pipeline:\n  timeout: 1h\n", newYaml: "pipeline:\n  timeout: 2h\n" },
+      a5: { oldYaml: "pipeline:\n  timeout: 2h\n", newYaml: "pipeline:\n  timeout: 3h\n" },
+    };
+    const mockRequest = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/audit/api/auditYaml") return { status: "SUCCESS", data: yaml[opts.params.auditId] };
+      return { status: "SUCCESS", data: { totalElements: 2, content: [EVENTS[1], EVENTS[5]] } };
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "config_snapshot_diff", "list", {
+      from_time: "2025-07-01T00:00:00Z",
+      entity_types: "pipeline",
+      include_diff: true,
+    }) as Record<string, any>;
+
+    expect(mockRequest.mock.calls[0]![0].body.resources).toEqual([{ type: "PIPELINE" }]);
+    expect(result.items[0].diff).toMatchObject({ lines_added: 1, lines_removed: 1 });
+    expect(result.items[0].diff.diff).toContain("-  timeout: 1h");
+    expect(result.items[0].diff.diff).toContain("+  timeout: 3h");
+  });
+
+  it("rejects unknown entity types and a missing from_time", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(vi.fn());
+    await expect(registry.dispatch(client, "config_snapshot_diff", "list", {}))
+      .rejects.toThrow(/from_time/);
+    await expect(registry.dispatch(client, "config_snapshot_diff", "list", { from_time: "2025-07-01T00:00:00Z", entity_types: "widget" }))
+      .rejects.toThrow(/Unknown entity type "widget"/);
+  });
+});
@@ -0,0 +1,182 @@
+/**
+ * Tests for delegate upgrade management: delegate_upgrade_status (versions vs
+ * the latest supported, auto-upgrade, expiry, tokens) and
+ * delegate_token.regenerate.
+ */
+import { afterEach, describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { compareDelegateVersions } from "../../src/registry/extractors.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "delegates",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const NOW = Date.parse("2026-03-01T00:00:00Z");
+const DAY = 24 * 60 * 60 * 1000;
+
+const DELEGATES = [
+  { name: "current", type: "KUBERNETES", connected: true, autoUpgrade: "ON", tokenActive: true, delegateReplicas: [{ version: "26.02.88100", expiringAt: NOW + 90 * DAY }] },
+  { name: "catching-up", type: "KUBERNETES", connected: true, autoUpgrade: "ON", tokenActive: true, delegateReplicas: [{ version: "26.01.87900", expiringAt: NOW + 60 * DAY }] },
+  { name: "pinned", type: "DOCKER", connected: true, autoUpgrade: "OFF", tokenActive: false, delegateReplicas: [{ version: "25.10.86500", expiringAt: NOW + 3 * DAY }, { version: "26.02.88100" }] },
+];
+
+const TOKENS = [
+  { name: "default_token", status: "ACTIVE", createdAt: NOW - 400 * DAY },
+  { name: "ci_token", status: "ACTIVE", revokeAfter: NOW - DAY },
+  { name: "staging_token", status: "ACTIVE", revokeAfter: NOW + 2 * DAY },
+  { name: "old_token", status: "REVOKED" },
+];
+
+function harnessApi(overrides: { latest?: () => unknown } = {}) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path === "/ng/api/delegate-setup/latest-supported-version") {
+      return overrides.latest ? overrides.latest() : { resource: { latestSupportedVersion: "26.02.88100", latestSupportedMinimalVersion: "26.02.88100.minimal" } };
+    }
+    if (opts.path === "/ng/api/delegate-setup/listDelegates") return { resource: DELEGATES };
+    if (opts.path === "/ng/api/delegate-token-ng") return { data: TOKENS };
+    throw new Error(`unexpected path ${opts.path}`);
+  });
+}
+
+afterEach(() => {
+  vi.useRealTimers();
+});
+
+describe("compareDelegateVersions", () => {
+  it("compares segments numerically", () => {
+    expect(compareDelegateVersions("25.10.86500", "26.02.88100")).toBeLessThan(0);
+    expect(compareDelegateVersions("26.02.88100", "26.02.88100")).toBe(0);
+    expect(compareDelegateVersions("26.02.100000", "26.02.99999")).toBeGreaterThan(0);
+  });
+});
+
+describe("delegate_upgrade_status list", () => {
+  it("flags outdated, expiring, and token problems against the latest supported version", async () => {
+    vi.useFakeTimers({ now: NOW });
+    const registry = new Registry(makeConfig());
+    const request = harnessApi();
+
+    const result = await registry.dispatch(makeClient(request), "delegate_upgrade_status", "list", {}) as Record<string, any>;
+
+    expect(result.latest_supported_version).toBe("26.02.88100");
+    expect(result.summary).toEqual({
+      total: 3,
+      up_to_date: 1,
+      outdated: 2,
+      pending_auto_upgrade: 1,
+      auto_upgrade_off: 1,
+      expiring_or_expired: 1,
+      token_inactive: 1,
+    });
+    const byName = Object.fromEntries(result.delegates.map((d: any) => [d.name, d]));
+    expect(byName.current.issues).toEqual([]);
+    expect(byName["catching-up"].issues).toEqual(["pending_auto_upgrade"]);
+    expect(byName.pinned).toMatchObject({ outdated: true, token_active: false, issues: ["auto_upgrade_off", "expiring", "token_inactive"] });
+
+    expect(result.tokens).toMatchObject({ status: "ok", total: 4, revoked: 1 });
+    expect(result.tokens.expired.map((t: any) => t.name)).toEqual(["ci_token"]);
+    expect(result.tokens.expiring.map((t: any) => t.name)).toEqual(["staging_token"]);
+
+    const delegateCall = request.mock.calls.find(([opts]) => opts.path === "/ng/api/delegate-setup/listDelegates")![0];
+    expect(delegateCall.params).toMatchObject({ all: "true" });
+  });
+
+  it("filters by issue", async () => {
+    vi.useFakeTimers({ now: NOW });
+    const registry = new Registry(makeConfig());
+
+    const outdated = await registry.dispatch(makeClient(harnessApi()), "delegate_upgrade_status", "list", { issue: "outdated" }) as Record<string, any>;
+    expect(outdated.delegates.map((d: any) => d.name)).toEqual(["catching-up", "pinned"]);
+
+    const pending = await registry.dispatch(makeClient(harnessApi()), "delegate_upgrade_status", "list", { issue: "pending_auto_upgrade" }) as Record<string, any>;
+    expect(pending.delegates.map((d: any) => d.name)).toEqual(["catching-up"]);
+    expect(pending.summary.total).toBe(3);
+  });
+
+  it("still lists delegates when the latest version cannot be read", async () => {
+    const registry = new Registry(makeConfig());
+    const request = harnessApi({ latest: () => { throw new HarnessApiError("forbidden", 403); } });
+
+    const result = await registry.dispatch(makeClient(request), "delegate_upgrade_status", "list", {}) as Record<string, any>;
+
+    expect(result.latest_supported_version).toBeNull();
+    expect(result.note).toContain("forbidden");
+    expect(result.delegates.every((d: any) => d.outdated === null)).toBe(true);
+  });
+});
+
+describe("delegate_token regenerate", () => {
+  it("creates a dated replacement token and keeps the old one active by default", async () => {
+    vi.useFakeTimers({ now: NOW });
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.method === "GET") return { data: { name: "ci_token", status: "ACTIVE" } };
+      if (opts.method === "POST") return { data: { name: opts.body.name, status: "ACTIVE", value: "tok-value" } };
+      throw new Error(`unexpected ${opts.method} ${opts.path}`);
+    });
+
+    const result = await registry.dispatchExecute(makeClient(request), "delegate_token", "regenerate", { token_name: "ci_token" }) as Record<string, any>;
+
+    const create = request.mock.calls.find(([opts]) => opts.method === "POST")![0];
+    expect(create.body).toMatchObject({ name: "ci_token_20260301" });
+    expect(result.new_token).toMatchObject({ name: "ci_token_20260301", value: "tok-value" });
+    expect(result.old_token).toEqual({ name: "ci_token", status: "ACTIVE" });
+    expect(result.next_steps[1]).toContain("action='revoke'");
+    expect(request.mock.calls.some(([opts]) => opts.method === "PUT")).toBe(false);
+  });
+
+  it("revokes the old token at the requested scope with revoke_old", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => (opts.method === "GET" ? { data: { name: "ci_token", status: "ACTIVE" } } : { data: {} }));
+
+    const result = await registry.dispatchExecute(makeClient(request), "delegate_token", "regenerate", {
+      token_name: "ci_token",
+      resource_scope: "account",
+      body: { new_token_name: "ci_token_v2", revoke_old: true },
+    }) as Record<string, any>;
+
+    const revoke = request.mock.calls.find(([opts]) => opts.method === "PUT")![0];
+    expect(revoke.path).toBe("/ng/api/delegate-token-ng/ci_token");
+    expect(revoke.params).toEqual({ status: "REVOKED" });
+    const create = request.mock.calls.find(([opts]) => opts.method === "POST")![0];
+    expect(create.params).toBeTypeOf("object");
+    expect(create.params.orgIdentifier).toBeUndefined();
+    expect(create.params.projectIdentifier).toBeUndefined();
+    expect(result.old_token.status).toBe("REVOKED");
+  });
+
+  it("is blocked in read-only mode", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_READ_ONLY: true }));
+    const request = vi.fn();
+    await expect(registry.dispatchExecute(makeClient(request), "delegate_token", "regenerate", { token_name: "ci_token" }))
+      .rejects.toThrow(/Read-only mode/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
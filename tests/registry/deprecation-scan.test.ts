@@ -0,0 +1,179 @@
+/**
+ * Tests for deprecation_scan: a migration worklist of deprecated steps,
+ * v1 service/infrastructure definitions, template version pins, and legacy
+ * connectors in a project.
+ */
+import { describe, expect, it } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "governance,pipelines,templates,connectors",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const LEGACY_PIPELINE = `
+pipeline:
+  identifier: legacy_deploy
+  stages:
+    - stage:
+        identifier: deploy
+        type: Deployment
+        spec:
+          serviceConfig:
+            serviceRef: api
+            serviceDefinition:
+              type: NativeHelm
+              spec:
+                manifests:
+                  - manifest:
+                      identifier: chart
+                      type: HelmChart
+                      spec:
+                        helmVersion: V2
+          infrastructure:
+            environmentRef: prod
+          execution:
+            steps:
+              - step:
+                  identifier: scan
+                  type: Security
+                  spec:
+                    privileged: true
+    - stage:
+        identifier: approve
+        template:
+          templateRef: account.approval
+          versionLabel: "1"
+`;
+
+const CLEAN_PIPELINE = `
+pipeline:
+  identifier: clean
+  stages:
+    - stage:
+        identifier: build
+        type: CI
+        spec:
+          execution:
+            steps:
+              - step: { identifier: test, type: Run, spec: { command: make test } }
+`;
+
+const TEMPLATES = [
+  { identifier: "approval", name: "Approval", versionLabel: "1", stableTemplate: false, templateScope: "account" },
+  { identifier: "approval", name: "Approval", versionLabel: "2", stableTemplate: true, templateScope: "account" },
+  {
+    identifier: "lambda", name: "Lambda", versionLabel: "v1", stableTemplate: true, templateScope: "project",
+    yaml: "template:\n  type: Step\n  spec:\n    type: ServerlessAwsLambdaDeploy\n    spec: {}\n",
+  },
+];
+
+const CONNECTORS = [
+  { connector: { identifier: "old_repo", name: "Old Repo", type: "Codecommit", spec: {}, orgIdentifier: "default", projectIdentifier: "test-project" } },
+  { connector: { identifier: "nexus", name: "Nexus", type: "Nexus", spec: { version: "2.x" }, orgIdentifier: "default" } },
+  { connector: { identifier: "github", name: "GitHub", type: "Github", spec: {} } },
+];
+
+function route(opts: Record<string, any>): unknown {
+  const path = String(opts.path);
+  if (path === "/pipeline/api/pipelines/list") {
+    return { data: { content: [{ identifier: "legacy_deploy", name: "Legacy Deploy" }, { identifier: "clean", name: "Clean" }], totalElements: 2 } };
+  }
+  if (path === "/pipeline/api/pipelines/legacy_deploy") return { data: { yamlPipeline: LEGACY_PIPELINE } };
+  if (path === "/pipeline/api/pipelines/clean") return { data: { yamlPipeline: CLEAN_PIPELINE } };
+  if (path.startsWith("/template/api/templates/list")) return { data: { content: TEMPLATES, totalElements: TEMPLATES.length } };
+  if (path === "/ng/api/connectors/listV2") return { data: { content: CONNECTORS, totalElements: CONNECTORS.length } };
+  throw new Error(`unexpected ${path}`);
+}
+
+type Finding = { kind: string; construct: string; location?: string; replacement: string };
+type Entry = { entity_type: string; identifier: string; link?: string; findings: Finding[] };
+
+describe("deprecation_scan", () => {
+  it("builds a worklist of deprecated constructs across pipelines, templates, and connectors", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(async (opts) => route(opts));
+
+    const result = await registry.dispatch(client, "deprecation_scan", "get", {}) as {
+      scanned: Record<string, number>;
+      summary: { entities: number; findings: number; by_kind: Record<string, number> };
+      worklist: Entry[];
+    };
+
+    expect(result.scanned).toEqual({ pipelines: 2, templates: 3, connectors: 3 });
+    expect(result.worklist.map((e) => [e.entity_type, e.identifier])).toEqual([
+      ["pipeline", "legacy_deploy"],
+      ["template", "lambda"],
+      ["connector", "old_repo"],
+      ["connector", "org.nexus"],
+    ]);
+    const pipeline = result.worklist[0]!;
+    expect(pipeline.findings.map((f) => f.kind).sort()).toEqual([
+      "deprecated_step", "helm_v2", "infrastructure_v1", "service_v1", "unstable_template_version",
+    ]);
+    expect(pipeline.findings.find((f) => f.kind === "deprecated_step")!.location).toBe("pipeline.stages.deploy.spec.execution.steps.scan");
+    expect(pipeline.findings.find((f) => f.kind === "unstable_template_version")).toMatchObject({
+      construct: "account.approval@1",
+      replacement: expect.stringContaining("version 2 (stable)"),
+    });
+    expect(pipeline.link).toContain("/pipelines/legacy_deploy");
+    expect(result.worklist[1]!.findings[0]).toMatchObject({ kind: "deprecated_step", replacement: expect.stringContaining("ServerlessAwsLambdaDeployV2") });
+    expect(result.summary).toMatchObject({ entities: 4, findings: 8, by_kind: { legacy_connector: 2 } });
+  });
+
+  it("flags a pin to a template version that no longer exists", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(async (opts) => {
+      if (opts.path === "/pipeline/api/pipelines/legacy_deploy") {
+        return { data: { yamlPipeline: LEGACY_PIPELINE.replace('versionLabel: "1"', 'versionLabel: "0.9"') } };
+      }
+      return route(opts);
+    });
+
+    const result = await registry.dispatch(client, "deprecation_scan", "get", {}) as { worklist: Entry[] };
+
+    expect(result.worklist[0]!.findings).toContainEqual(expect.objectContaining({
+      kind: "missing_template_version",
+      construct: "account.approval@0.9",
+    }));
+  });
+
+  it("reports lists that fail to load and scans the rest", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(async (opts) => {
+      if (opts.path === "/ng/api/connectors/listV2") throw new Error("forbidden");
+      return route(opts);
+    });
+
+    const result = await registry.dispatch(client, "deprecation_scan", "get", {}) as { scanned: Record<string, number>; errors: string[] };
+
+    expect(result.scanned.connectors).toBe(0);
+    expect(result.scanned.pipelines).toBe(2);
+    expect(result.errors).toEqual([expect.stringContaining("connectors: ")]);
+  });
+});
@@ -0,0 +1,140 @@
+/**
+ * Tests for entity_version and entity_version_diff: per-entity change history
+ * built from audit YAML snapshots.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "audit",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const V1 = "pipeline:\n  identifier: deploy\n  stages:\n    - stage: build\n";
+const V2 = "pipeline:\n  identifier: deploy\n  stages:\n    - stage: build\n    - stage: prod\n";
+const V3 = "pipeline:\n  identifier: deploy\n  timeout: 1h\n  stages:\n    - stage: build\n    - stage: prod\n";
+
+function yamlFor(auditId: unknown) {
+  const snapshots: Record<string, { oldYaml: string; newYaml: string }> = {
+    a1: { oldYaml: "", newYaml: V1 },
+    a2: { oldYaml: V1, newYaml: V2 },
+    a3: { oldYaml: V2, newYaml: V3 },
+  };
+  return { status: "SUCCESS", data: snapshots[String(auditId)] };
+}
+
+describe("entity_version list", () => {
+  it("filters audit events to one entity and maps them to versions", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async () => ({
+      status: "SUCCESS",
+      data: {
+        totalElements: 1,
+        content: [{
+          auditId: "a2",
+          action: "UPDATE",
+          timestamp: Date.parse("2025-07-08T14:00:00Z"),
+          resource: { type: "PIPELINE", identifier: "deploy", labels: { resourceName: "Deploy" } },
+          authenticationInfo: { principal: { type: "USER", identifier: "u1" }, labels: { username: "dev@example.com" } },
+        }],
+      },
+    }));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "entity_version", "list", {
+      entity_type: "pipeline",
+      entity_id: "deploy",
+      start_time: "2025-07-08T00:00:00Z",
+      end_time: "2025-07-09T00:00:00Z",
+    }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "POST",
+      path: "/audit/api/audits/list",
+      body: expect.objectContaining({
+        scopes: [{ accountIdentifier: "test-account", orgIdentifier: "default", projectIdentifier: "test-project" }],
+        resources: [{ type: "PIPELINE", identifier: "deploy" }],
+        startTime: Date.parse("2025-07-08T00:00:00Z"),
+        endTime: Date.parse("2025-07-09T00:00:00Z"),
+      }),
+    }));
+    expect(result.items).toEqual([{
+      version_id: "a2",
+      action: "UPDATE",
+      changed_at: "2025-07-08T14:00:00.000Z",
+      changed_by: "dev@example.com",
+      principal_type: "USER",
+      name: "Deploy",
+    }]);
+  });
+
+  it("scopes account-level connectors and rejects unsupported entity types", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async () => ({ status: "SUCCESS", data: { content: [], totalElements: 0 } }));
+
+    await registry.dispatch(makeClient(mockRequest), "entity_version", "list", {
+      entity_type: "connector",
+      entity_id: "github",
+      resource_scope: "account",
+    });
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      body: expect.objectContaining({ scopes: [{ accountIdentifier: "test-account" }] }),
+    }));
+
+    await expect(registry.dispatch(makeClient(mockRequest), "entity_version", "list", {
+      entity_type: "secret",
+      entity_id: "token",
+    })).rejects.toThrow(/entity_type must be one of/);
+  });
+});
+
+describe("entity_version_diff get", () => {
+  it("diffs the single change recorded in version_id by default", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { params: Record<string, unknown> }) => yamlFor(opts.params.auditId));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "entity_version_diff", "get", { version_id: "a2" }) as Record<string, any>;
+
+    expect(result).toMatchObject({ to_version: "a2", changed: true, lines_added: 1, lines_removed: 0 });
+    expect(result.diff).toContain("+    - stage: prod");
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+  });
+
+  it("spans every change between base_version and version_id", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { params: Record<string, unknown> }) => yamlFor(opts.params.auditId));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "entity_version_diff", "get", {
+      version_id: "a3",
+      base_version: "a1",
+    }) as Record<string, any>;
+
+    expect(result).toMatchObject({ from_version: "a1", to_version: "a3", lines_added: 2, lines_removed: 0 });
+    expect(result.diff).toContain("+  timeout: 1h");
+    expect(result.diff).toContain("+    - stage: prod");
+  });
+});
@@ -0,0 +1,101 @@
+/**
+ * Tests for execution list filters mapped onto the
+ * PipelineExecutionFilterProperties body.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { executionFilterBody } from "../../src/registry/toolsets/pipelines.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+describe("executionFilterBody", () => {
+  it("keeps the plain body when no advanced filters are given", () => {
+    expect(executionFilterBody({ status: "Failed" })).toEqual({ filterType: "PipelineExecution" });
+  });
+
+  it("maps shorthand filters onto filter properties", () => {
+    expect(executionFilterBody({
+      statuses: "Failed, Aborted",
+      pipeline_name: "Deploy",
+      trigger_types: "webhook,SCHEDULER_CRON",
+      trigger_ids: ["nightly"],
+      start_time_from: "2025-07-01T00:00:00Z",
+      start_time_to: 1752000000000,
+      tags: "team:payments,critical",
+      service_ids: "checkout",
+      env_ids: "prod,staging",
+      repo_names: "api",
+    })).toEqual({
+      filterType: "PipelineExecution",
+      status: ["Failed", "Aborted"],
+      pipelineName: "Deploy",
+      triggerTypes: ["WEBHOOK", "SCHEDULER_CRON"],
+      triggerIdentifiers: ["nightly"],
+      timeRange: { startTime: 1751328000000, endTime: 1752000000000 },
+      pipelineTags: [{ key: "team", value: "payments" }, { key: "critical", value: "" }],
+      moduleProperties: {
+        cd: { serviceIdentifiers: ["checkout"], envIdentifiers: ["prod", "staging"] },
+        ci: { repoNames: ["api"] },
+      },
+    });
+  });
+
+  it("passes filter_properties through and applies shorthand filters on top", () => {
+    const body = executionFilterBody({
+      filter_properties: JSON.stringify({
+        status: ["Success"],
+        moduleProperties: { cd: { serviceDefinitionTypes: ["Kubernetes"] } },
+        timeRange: { startTime: 1, endTime: 2 },
+      }),
+      statuses: "Failed",
+      env_ids: "prod",
+      start_time_to: 1752000000,
+    });
+    expect(body).toEqual({
+      filterType: "PipelineExecution",
+      status: ["Failed"],
+      moduleProperties: { cd: { serviceDefinitionTypes: ["Kubernetes"], envIdentifiers: ["prod"] } },
+      timeRange: { startTime: 1, endTime: 1752000000000 },
+    });
+  });
+
+  it("rejects unparseable times", () => {
+    expect(() => executionFilterBody({ start_time_from: "last tuesday" })).toThrow(/start_time_from must be epoch millis or an ISO 8601/);
+  });
+});
+
+describe("execution list", () => {
+  it("sends advanced filters in the body and keeps simple ones as query params", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: { content: [], totalElements: 0 } }));
+    const client = { request, account: "test-account" } as unknown as HarnessClient;
+
+    await registry.dispatch(client, "execution", "list", { pipeline_id: "deploy", my_deployments: true, filter_id: "prod_failures", statuses: "Failed,Expired" });
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.method).toBe("POST");
+    expect(opts.params).toMatchObject({ pipelineIdentifier: "deploy", myDeployments: true, filterIdentifier: "prod_failures" });
+    expect(opts.body).toMatchObject({ filterType: "PipelineExecution", status: ["Failed", "Expired"] });
+  });
+});
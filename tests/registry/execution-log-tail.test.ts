@@ -0,0 +1,107 @@
+/**
+ * Tests for execution_log_tail: incremental reads of a step's log from the
+ * live log-service stream, falling back to the closed log blob.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+import { decodeLogCursor, encodeLogCursor } from "../../src/utils/log-stream.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "logs",
+    ...overrides,
+  };
+}
+
+function makeClient(requestStream: (opts: Record<string, any>) => Promise<Response>): HarnessClient {
+  return {
+    request: vi.fn(),
+    requestStream,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const entry = (out: string) => JSON.stringify({ level: "INFO", time: "2025-07-01T00:00:00Z", out });
+const KEY = "test-account/pipeline/build/7/-exec-1";
+
+describe("execution_log_tail", () => {
+  it("reads new lines from the live stream and returns a cursor", async () => {
+    const registry = new Registry(makeConfig());
+    const requestStream = vi.fn(async (_opts: Record<string, any>) =>
+      new Response(`data: ${entry("compiling")}\n\ndata: ${entry("tests passed")}\n\n`));
+
+    const result = await registry.dispatch(makeClient(requestStream), "execution_log_tail", "get", {
+      execution_id: "exec-1",
+      log_key: KEY,
+      wait_ms: 100,
+    }) as Record<string, any>;
+
+    expect(requestStream.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/gateway/log-service/stream", params: { key: KEY } });
+    expect(result).toMatchObject({
+      execution_id: "exec-1",
+      log_key: KEY,
+      source: "live",
+      line_count: 2,
+      lines: ["2025-07-01T00:00:00Z info: compiling", "2025-07-01T00:00:00Z info: tests passed"],
+    });
+    // The mock body closes after two lines, so the stream reads as ended.
+    expect(result.has_more).toBe(false);
+    expect(decodeLogCursor(result.cursor)).toEqual({ key: KEY, offset: 2 });
+  });
+
+  it("falls back to the closed log blob and resumes after the cursor", async () => {
+    const registry = new Registry(makeConfig());
+    const requestStream = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/gateway/log-service/stream") throw new HarnessApiError("stream not found", 404);
+      return new Response([entry("one"), entry("two"), entry("three")].join("\n"));
+    });
+
+    const result = await registry.dispatch(makeClient(requestStream), "execution_log_tail", "get", {
+      execution_id: "exec-1",
+      cursor: encodeLogCursor({ key: KEY, offset: 1 }),
+      max_lines: 5,
+    }) as Record<string, any>;
+
+    expect(requestStream.mock.calls[1]![0]).toMatchObject({ path: "/gateway/log-service/blob", params: { key: KEY } });
+    expect(result).toMatchObject({ source: "complete", line_count: 2, total_lines: 3, has_more: false });
+    expect(result.lines.map((l: string) => l.split(" ").pop())).toEqual(["two", "three"]);
+  });
+
+  it("reports pending when the step has not written a log yet", async () => {
+    const registry = new Registry(makeConfig());
+    const requestStream = vi.fn(async () => {
+      throw new HarnessApiError("not found", 404);
+    });
+
+    const result = await registry.dispatch(makeClient(requestStream), "execution_log_tail", "get", {
+      execution_id: "exec-1",
+      log_key: KEY,
+    }) as Record<string, any>;
+
+    expect(result).toMatchObject({ source: "pending", line_count: 0, has_more: true });
+    expect(decodeLogCursor(result.cursor)).toEqual({ key: KEY, offset: 0 });
+  });
+
+  it("rejects a malformed cursor", async () => {
+    const registry = new Registry(makeConfig());
+    await expect(registry.dispatch(makeClient(vi.fn()), "execution_log_tail", "get", { execution_id: "exec-1", cursor: "garbage" }))
+      .rejects.toThrow(/Invalid cursor/);
+  });
+});
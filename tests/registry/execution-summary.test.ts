@@ -0,0 +1,208 @@
+/**
+ * Tests for the curated execution summary returned by
+ * harness_get(resource_type='execution') and the detail_level=full escape hatch.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { executionSummaryExtract } from "../../src/registry/extractors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+function layoutNode(id: string, status: string, startTs: number, endTs: number, nextIds: string[] = [], extra: Record<string, unknown> = {}) {
+  return {
+    nodeType: "Deployment",
+    nodeGroup: "STAGE",
+    nodeIdentifier: id,
+    name: id.toUpperCase(),
+    status,
+    startTs,
+    endTs,
+    moduleInfo: { cd: { serviceInfo: { identifier: "svc", displayName: "Service", artifacts: { primary: { tag: "v1" } } } } },
+    edgeLayoutList: { currentNodeChildren: [], nextIds },
+    ...extra,
+  };
+}
+
+const RAW = {
+  status: "SUCCESS",
+  data: {
+    pipelineExecutionSummary: {
+      planExecutionId: "exec-1",
+      pipelineIdentifier: "deploy",
+      name: "Deploy",
+      status: "Failed",
+      runSequence: 42,
+      startTs: 1_000,
+      endTs: 61_000,
+      executionTriggerInfo: { triggerType: "MANUAL", triggeredBy: { identifier: "alice", extraInfo: { email: "alice@example.com" } } },
+      executionErrorInfo: { message: "Shell Script failed" },
+      governanceMetadata: { id: "0", deny: false, details: [], message: "" },
+      moduleInfo: { cd: { envIdentifiers: ["prod"], serviceIdentifiers: ["svc"] } },
+      startingNodeId: "n-par",
+      layoutNodeMap: {
+        "n-par": {
+          nodeType: "parallel",
+          nodeIdentifier: "parallel1",
+          edgeLayoutList: { currentNodeChildren: ["n-build", "n-test"], nextIds: ["n-deploy"] },
+        },
+        "n-build": layoutNode("build", "Success", 1_000, 20_000),
+        "n-test": layoutNode("test", "Success", 1_000, 25_000),
+        "n-deploy": layoutNode("deploy_prod", "Failed", 25_000, 61_000, [], { failureInfo: { message: "Shell Script failed" } }),
+      },
+    },
+    executionGraph: {
+      rootNodeId: "root",
+      nodeMap: {
+        s1: {
+          uuid: "s1",
+          identifier: "rollout",
+          name: "Rollout",
+          stepType: "ShellScript",
+          status: "Failed",
+          baseFqn: "pipeline.stages.deploy_prod.spec.execution.steps.rollout",
+          failureInfo: { message: "exit code 1" },
+          stepParameters: { spec: { source: { spec: { script: "echo ".repeat(200) } } } },
+        },
+        s2: {
+          uuid: "s2",
+          identifier: "check",
+          name: "Check",
+          stepType: "Http",
+          status: "Success",
+          baseFqn: "pipeline.stages.deploy_prod.spec.execution.steps.check",
+        },
+      },
+    },
+  },
+};
+
+describe("executionSummaryExtract", () => {
+  it("returns status, timing, and trigger", () => {
+    const result = executionSummaryExtract(RAW) as Record<string, any>;
+    expect(result).toMatchObject({
+      planExecutionId: "exec-1",
+      pipelineIdentifier: "deploy",
+      status: "Failed",
+      runSequence: 42,
+      durationMs: 60_000,
+      trigger: { type: "MANUAL", triggeredBy: "alice@example.com" },
+    });
+  });
+
+  it("lists stages in execution order, expanding parallel groups", () => {
+    const result = executionSummaryExtract(RAW) as Record<string, any>;
+    expect(result.stages.map((s: any) => [s.identifier, s.status, s.durationMs])).toEqual([
+      ["build", "Success", 19_000],
+      ["test", "Success", 24_000],
+      ["deploy_prod", "Failed", 36_000],
+    ]);
+    expect(result.stages[2].failureMessage).toBe("Shell Script failed");
+  });
+
+  it("summarizes failure info with failed stages and steps", () => {
+    const result = executionSummaryExtract(RAW) as Record<string, any>;
+    expect(result.failure).toEqual({
+      message: "Shell Script failed",
+      failedStages: ["deploy_prod"],
+      failedSteps: [{ stage: "deploy_prod", identifier: "rollout", name: "Rollout", stepType: "ShellScript", message: "exit code 1" }],
+    });
+  });
+
+  it("is much smaller than the raw payload", () => {
+    const summary = JSON.stringify(executionSummaryExtract(RAW));
+    const full = JSON.stringify(RAW.data);
+    expect(summary.length).toBeLessThan(full.length / 2);
+  });
+
+  it("returns null failure for successful runs", () => {
+    const ok = { data: { pipelineExecutionSummary: { planExecutionId: "e2", status: "Success", startTs: 1, endTs: 2 } } };
+    expect((executionSummaryExtract(ok) as Record<string, any>).failure).toBeNull();
+  });
+
+  it("returns the raw data payload when detail_level=full", () => {
+    expect(executionSummaryExtract(RAW, { detail_level: "full" })).toBe(RAW.data);
+  });
+});
+
+describe("execution get", () => {
+  it("returns the summary with a deep link by default", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn().mockResolvedValue(structuredClone(RAW));
+    const result = await registry.dispatch(makeClient(mockRequest), "execution", "get", { execution_id: "exec-1" }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "GET",
+      path: "/pipeline/api/pipelines/execution/v2/exec-1",
+    }));
+    expect(result.pipelineExecutionSummary).toBeUndefined();
+    expect(result.stages).toHaveLength(3);
+    expect(result.openInHarness).toContain("/pipelines/deploy/deployments/exec-1/pipeline");
+  });
+
+  it("does not send detail_level to the API and returns raw data when full", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn().mockResolvedValue(structuredClone(RAW));
+    const result = await registry.dispatch(makeClient(mockRequest), "execution", "get", { execution_id: "exec-1", detail_level: "full" }) as Record<string, any>;
+
+    const call = mockRequest.mock.calls[0]![0] as { params: Record<string, unknown> };
+    expect(call.params.detail_level).toBeUndefined();
+    expect(result.pipelineExecutionSummary).toMatchObject({ planExecutionId: "exec-1" });
+  });
+});
+
+describe("execution format=mermaid", () => {
+  it("adds a flowchart of parallel stages feeding the next stage, with its steps chained in order", () => {
+    const result = executionSummaryExtract(RAW, { format: "mermaid" }) as Record<string, any>;
+    const lines = (result.mermaid as string).split("\n");
+
+    expect(lines.slice(0, 6)).toEqual([
+      "flowchart LR",
+      '  n0["BUILD (Success)"]',
+      '  n1["TEST (Success)"]',
+      '  n2["DEPLOY_PROD (Failed)"]',
+      '  n3["Rollout (Failed)"]',
+      '  n4["Check (Success)"]',
+    ]);
+    expect(lines).toEqual(expect.arrayContaining(["  n0 --> n2", "  n1 --> n2", "  n2 --> n3", "  n3 --> n4", "  class n2,n3 failed"]));
+    expect(result.stages).toHaveLength(3);
+  });
+
+  it("adds the flowchart to the raw payload with detail_level=full", () => {
+    const result = executionSummaryExtract(RAW, { format: "mermaid", detail_level: "full" }) as Record<string, any>;
+
+    expect(result.pipelineExecutionSummary).toMatchObject({ planExecutionId: "exec-1" });
+    expect(result.mermaid).toMatch(/^flowchart LR/);
+  });
+
+  it("omits the mermaid field by default", () => {
+    expect(executionSummaryExtract(RAW)).not.toHaveProperty("mermaid");
+  });
+});
@@ -0,0 +1,142 @@
+import { describe, expect, it } from "vitest";
+import { executionTimelineExtract } from "../../src/registry/extractors.js";
+import { pipelinesToolset } from "../../src/registry/toolsets/pipelines.js";
+
+function makeRaw() {
+  return {
+    status: "SUCCESS",
+    data: {
+      pipelineExecutionSummary: {
+        planExecutionId: "exec-1",
+        pipelineIdentifier: "deploy",
+        name: "Deploy: prod",
+        status: "Failed",
+        startTs: 1_000,
+        endTs: 61_000,
+        startingNodeId: "n-build",
+        layoutNodeMap: {
+          "n-build": {
+            nodeType: "CI",
+            nodeGroup: "STAGE",
+            nodeIdentifier: "build",
+            name: "Build",
+            status: "Success",
+            startTs: 1_000,
+            endTs: 21_000,
+            edgeLayoutList: { currentNodeChildren: [], nextIds: ["n-par"] },
+          },
+          "n-par": {
+            nodeType: "parallel",
+            nodeGroup: "STAGE",
+            edgeLayoutList: { currentNodeChildren: ["n-east", "n-west"], nextIds: ["n-approve"] },
+          },
+          "n-east": { nodeType: "Deployment", nodeIdentifier: "east", name: "East", status: "Success", startTs: 21_000, endTs: 41_000 },
+          "n-west": { nodeType: "Deployment", nodeIdentifier: "west", name: "West", status: "Failed", startTs: 21_500, endTs: 51_000 },
+          "n-approve": { nodeType: "Approval", nodeIdentifier: "approve", name: "Approve", status: "NotStarted" },
+        },
+      },
+      executionGraph: {
+        nodeMap: {
+          u1: { identifier: "compile", name: "Compile", baseFqn: "pipeline.stages.build.spec.execution.steps.compile", status: "Success", stepType: "Run", startTs: 2_000, endTs: 10_000 },
+          u2: { identifier: "lint", name: "Lint", baseFqn: "pipeline.stages.build.spec.execution.steps.lint", status: "Success", stepType: "Run", startTs: 3_000, endTs: 9_000 },
+          u3: { identifier: "execution", baseFqn: "pipeline.stages.build.spec.execution", stepType: "NG_SECTION", startTs: 1_500, endTs: 20_000 },
+          u4: { identifier: "sg", baseFqn: "pipeline.stages.west.spec.execution.steps.sg", stepType: "STEP_GROUP", startTs: 22_000, endTs: 50_000 },
+          u5: { identifier: "rollout", name: "Rollout", baseFqn: "pipeline.stages.west.spec.execution.steps.sg.steps.rollout", status: "Failed", stepType: "K8sRollingDeploy", startTs: 22_000, endTs: 50_000 },
+        },
+      },
+    },
+  };
+}
+
+type Timeline = {
+  execution_id: string;
+  duration_ms: number | null;
+  max_parallelism: { stages: number; steps: number };
+  not_started: number;
+  tasks: Array<Record<string, unknown>>;
+  mermaid?: string;
+};
+
+describe("executionTimelineExtract", () => {
+  it("builds stage and step tasks with offsets, durations, and lanes", () => {
+    const result = executionTimelineExtract(makeRaw()) as Timeline;
+
+    expect(result.execution_id).toBe("exec-1");
+    expect(result.duration_ms).toBe(60_000);
+    expect(result.tasks.map((t) => t.id)).toEqual(["build", "compile", "lint", "east", "west", "rollout"]);
+    expect(result.tasks.find((t) => t.id === "lint")).toMatchObject({
+      type: "step",
+      stage_id: "build",
+      start_offset_ms: 2_000,
+      duration_ms: 6_000,
+      lane: 1,
+    });
+    expect(result.tasks.find((t) => t.id === "rollout")).toMatchObject({ stage_id: "west", lane: 0 });
+  });
+
+  it("groups parallel stages and reports peak concurrency", () => {
+    const result = executionTimelineExtract(makeRaw()) as Timeline;
+    const east = result.tasks.find((t) => t.id === "east");
+    const west = result.tasks.find((t) => t.id === "west");
+
+    expect(east?.parallel_group).toBe(west?.parallel_group);
+    expect([east?.lane, west?.lane]).toEqual([0, 1]);
+    expect(result.max_parallelism).toEqual({ stages: 2, steps: 2 });
+  });
+
+  it("skips container nodes and counts tasks that never started", () => {
+    const result = executionTimelineExtract(makeRaw()) as Timeline;
+    const ids = result.tasks.map((t) => t.id);
+
+    expect(ids).not.toContain("execution");
+    expect(ids).not.toContain("sg");
+    expect(ids).not.toContain("approve");
+    expect(result.not_started).toBe(1);
+  });
+
+  it("leaves ended_at null for running tasks", () => {
+    const raw = makeRaw();
+    const summary = raw.data.pipelineExecutionSummary as Record<string, unknown>;
+    delete summary.endTs;
+    delete (raw.data.pipelineExecutionSummary.layoutNodeMap["n-west"] as Record<string, unknown>).endTs;
+    const result = executionTimelineExtract(raw) as Timeline;
+
+    expect(result.duration_ms).toBeNull();
+    expect(result.tasks.find((t) => t.id === "west")).toMatchObject({ ended_at: null, duration_ms: null });
+  });
+
+  it("renders Mermaid gantt only when format=mermaid is set", () => {
+    expect(executionTimelineExtract(makeRaw())).not.toHaveProperty("mermaid");
+    expect(executionTimelineExtract(makeRaw(), { format: "json" })).not.toHaveProperty("mermaid");
+
+    const result = executionTimelineExtract(makeRaw(), { format: "mermaid" }) as Timeline;
+    expect(result.mermaid).toContain("gantt\n  title Deploy prod\n  dateFormat x");
+    expect(result.mermaid).toContain("  section West\n  West :crit, t4, 21500, 51000\n  Rollout :crit, t5, 22000, 50000");
+  });
+
+  it("still accepts the deprecated include_mermaid alias", () => {
+    const canonical = executionTimelineExtract(makeRaw(), { format: "mermaid" }) as Timeline;
+    expect((executionTimelineExtract(makeRaw(), { include_mermaid: "true" }) as Timeline).mermaid).toBe(canonical.mermaid);
+    expect((executionTimelineExtract(makeRaw(), { include_mermaid: true }) as Timeline).mermaid).toBe(canonical.mermaid);
+  });
+
+  it("returns an empty timeline for an empty response", () => {
+    const result = executionTimelineExtract({}, { execution_id: "exec-2" }) as Timeline;
+    expect(result.execution_id).toBe("exec-2");
+    expect(result.tasks).toEqual([]);
+    expect(result.max_parallelism).toEqual({ stages: 0, steps: 0 });
+  });
+});
+
+describe("execution_timeline resource", () => {
+  const resource = pipelinesToolset.resources.find((r) => r.resourceType === "execution_timeline");
+
+  it("is a read-only get against the full execution graph", () => {
+    const get = resource?.operations.get;
+    expect(get?.path).toBe("/pipeline/api/pipelines/execution/v2/{planExecutionId}");
+    expect(get?.staticQueryParams).toEqual({ renderFullBottomGraph: "true" });
+    expect(get?.operationPolicy.risk).toBe("read");
+    expect(get?.responseExtractor).toBe(executionTimelineExtract);
+    expect(Object.keys(resource?.operations ?? {})).toEqual(["get"]);
+  });
+});
@@ -0,0 +1,120 @@
+/**
+ * Tests for execution_yaml: the compiled YAML an execution ran, its resolved
+ * inputs, and the runtime expressions left in it.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const COMPILED = `pipeline:
+  identifier: deploy
+  stages:
+    - stage:
+        identifier: build
+        spec:
+          execution:
+            steps:
+              - step:
+                  identifier: tag
+                  spec:
+                    command: echo <+pipeline.sequenceId>
+              - step:
+                  identifier: push
+                  spec:
+                    image: app:<+pipeline.stages.build.spec.execution.steps.tag.output.outputVariables.<+stage.variables.key>>
+                    tag: <+pipeline.sequenceId>
+`;
+
+describe("execution_yaml get", () => {
+  it("returns the compiled YAML, resolved inputs, and runtime expressions", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/metadata")) return { data: { executionId: "exec-1", executionYaml: COMPILED } };
+      if (opts.path.endsWith("/inputsetV2")) {
+        return { data: { inputSetYaml: "pipeline:\n  variables:\n    - name: env\n      value: <+trigger.branch>\n", resolvedYaml: "pipeline:\n  variables:\n    - name: env\n      value: main\n" } };
+      }
+      throw new Error(`unexpected path ${opts.path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "execution_yaml", "get", { execution_id: "exec-1" }) as Record<string, any>;
+
+    expect(request.mock.calls.map(([opts]) => opts.path)).toEqual([
+      "/pipeline/api/pipelines/execution/exec-1/metadata",
+      "/pipeline/api/pipelines/execution/exec-1/inputsetV2",
+    ]);
+    expect(request.mock.calls[1]![0].params).toMatchObject({
+      orgIdentifier: "default",
+      projectIdentifier: "test-project",
+      resolveExpressions: true,
+      resolveExpressionsType: "RESOLVE_ALL_EXPRESSIONS",
+    });
+    expect(result).toMatchObject({
+      execution_id: "exec-1",
+      compiled_yaml: COMPILED,
+      templates_expanded: true,
+      resolved_inputs_yaml: expect.stringContaining("value: main"),
+    });
+    expect(result.runtime_expressions).toEqual({
+      total: 3,
+      distinct: 2,
+      items: [
+        { expression: "<+pipeline.sequenceId>", count: 2 },
+        { expression: "<+pipeline.stages.build.spec.execution.steps.tag.output.outputVariables.<+stage.variables.key>>", count: 1 },
+      ],
+    });
+    expect(result).not.toHaveProperty("errors");
+  });
+
+  it("reports leftover template refs and keeps the YAML when inputs can't be read", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/metadata")) return { data: { executionYaml: "pipeline:\n  template:\n    templateRef: account.deploy_tpl\n" } };
+      throw new HarnessApiError("forbidden", 403);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "execution_yaml", "get", { execution_id: "exec-2" }) as Record<string, any>;
+
+    expect(result).toMatchObject({ templates_expanded: false, template_refs: ["account.deploy_tpl"], resolved_inputs_yaml: null });
+    expect(result.errors).toEqual([expect.stringContaining("inputs: forbidden")]);
+  });
+
+  it("skips the inputs lookup with resolve_expressions=false", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: {} }));
+
+    const result = await registry.dispatch(makeClient(request), "execution_yaml", "get", { execution_id: "exec-3", resolve_expressions: false }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(result).toMatchObject({ compiled_yaml: null, templates_expanded: false, note: expect.stringContaining("no compiled YAML") });
+  });
+});
@@ -182,6 +182,72 @@ describe("gitops_application", () => {
     ).rejects.toThrow(/body\.application is required/);
   });
 
+  it("create: builds the application from a Git path shorthand", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({});
+    const client = makeClient(mockRequest);
+
+    await registry.dispatch(client, "gitops_application", "create", {
+      agent_id: "account.myagent",
+      skip_repo_validation: "true",
+      body: { name: "web", repo_url: "https://github.com/org/repo", path: "k8s/web", dest_namespace: "web", service_ref: "web_svc" },
+    });
+
+    expect(mockRequest.mock.calls[0][0].body.application).toEqual({
+      metadata: { name: "web", labels: { "harness.io/serviceRef": "web_svc" } },
+      spec: {
+        source: { repoURL: "https://github.com/org/repo", path: "k8s/web", targetRevision: "HEAD" },
+        destination: { server: "https://kubernetes.default.svc", namespace: "web" },
+      },
+    });
+  });
+
+  it("create: builds a Helm chart application with automated sync", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({});
+    const client = makeClient(mockRequest);
+
+    await registry.dispatch(client, "gitops_application", "create", {
+      agent_id: "account.myagent",
+      body: {
+        name: "redis", repo_url: "https://charts.example.com", chart: "redis", target_revision: "18.1.0",
+        helm_values: "replicaCount: 1", helm_parameters: { "auth.enabled": false },
+        dest_cluster_name: "prod", dest_namespace: "cache",
+        auto_sync: true, prune: true, sync_options: ["CreateNamespace=true"],
+      },
+    });
+
+    const app = mockRequest.mock.calls[0][0].body.application;
+    expect(app.spec.source).toEqual({
+      repoURL: "https://charts.example.com",
+      chart: "redis",
+      targetRevision: "18.1.0",
+      helm: { values: "replicaCount: 1", parameters: [{ name: "auth.enabled", value: "false" }] },
+    });
+    expect(app.spec.destination).toEqual({ name: "prod", namespace: "cache" });
+    expect(app.spec.syncPolicy).toEqual({ automated: { prune: true, selfHeal: false }, syncOptions: ["CreateNamespace=true"] });
+  });
+
+  it("create: names the missing shorthand fields", async () => {
+    await expect(
+      registry.dispatch(makeClient(vi.fn()), "gitops_application", "create", {
+        agent_id: "account.myagent",
+        body: { name: "web", repo_url: "https://github.com/org/repo" },
+      }),
+    ).rejects.toThrow("body.dest_namespace, body.path or chart");
+  });
+
+  it("create: is blocked in read-only mode", async () => {
+    const readOnly = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops", HARNESS_READ_ONLY: true }));
+    const mockRequest = vi.fn();
+
+    await expect(
+      readOnly.dispatch(makeClient(mockRequest), "gitops_application", "create", {
+        agent_id: "account.myagent",
+        body: { name: "web", repo_url: "https://github.com/org/repo", path: "k8s", dest_namespace: "web" },
+      }),
+    ).rejects.toThrow(/Read-only mode/);
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
+
   it("update: agent_id → {agentIdentifier}, app_name → {appName}", async () => {
     const mockRequest = vi.fn().mockResolvedValue({ metadata: { name: "demo-app" } });
     const client = makeClient(mockRequest);
@@ -222,6 +288,42 @@ describe("gitops_application", () => {
     ).rejects.toThrow(/body\.application is required/);
   });
 
+  it("update: merges a partial spec into the current app", async () => {
+    const mockRequest = vi.fn()
+      .mockResolvedValueOnce({
+        name: "demo-app",
+        app: {
+          metadata: { name: "demo-app", labels: { team: "web", tier: "front" }, resourceVersion: "42" },
+          spec: {
+            source: { repoURL: "https://github.com/org/repo", path: "manifests", targetRevision: "main" },
+            destination: { server: "https://kubernetes.default.svc", namespace: "default" },
+            syncPolicy: { automated: { prune: true }, syncOptions: ["CreateNamespace=true"] },
+          },
+          status: { sync: { status: "Synced" } },
+        },
+      })
+      .mockResolvedValueOnce({});
+    const client = makeClient(mockRequest);
+
+    await registry.dispatch(client, "gitops_application", "update", {
+      agent_id: "account.myagent",
+      app_name: "demo-app",
+      body: { spec: { source: { targetRevision: "v2.0.0" }, syncPolicy: { automated: null } }, labels: { tier: null } },
+    });
+
+    expect(mockRequest.mock.calls[0][0].method).toBe("GET");
+    const put = mockRequest.mock.calls[1][0];
+    expect(put.method).toBe("PUT");
+    expect(put.body.application).toEqual({
+      metadata: { name: "demo-app", labels: { team: "web" } },
+      spec: {
+        source: { repoURL: "https://github.com/org/repo", path: "manifests", targetRevision: "v2.0.0" },
+        destination: { server: "https://kubernetes.default.svc", namespace: "default" },
+        syncPolicy: { syncOptions: ["CreateNamespace=true"] },
+      },
+    });
+  });
+
   it("delete: foreground cascade — cascade + propagation_policy forwarded as query params", async () => {
     const mockRequest = vi.fn().mockResolvedValue({});
     const client = makeClient(mockRequest);
@@ -419,6 +521,45 @@ describe("gitops_application execute actions", () => {
       action: "restart",
     });
   });
+
+  it("delete_resource: sends the resource and options as query params", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({});
+    const client = makeClient(mockRequest);
+
+    await registry.dispatchExecute(client, "gitops_application", "delete_resource", {
+      agent_id: "account.myagent",
+      app_name: "demo-app",
+      body: { namespace: "default", resourceName: "migrate-db", kind: "Job", group: "batch", version: "v1", force: true, orphan: true },
+    });
+
+    const call = mockRequest.mock.calls[0][0];
+    expect(call.method).toBe("DELETE");
+    expect(call.path).toBe("/gitops/api/v1/agents/account.myagent/applications/demo-app/resource");
+    expect(call.params).toMatchObject({
+      "request.namespace": "default",
+      "request.resourceName": "migrate-db",
+      "request.kind": "Job",
+      "request.group": "batch",
+      "request.version": "v1",
+      "request.force": true,
+      "request.orphan": true,
+    });
+    expect(call.body).toBeUndefined();
+  });
+
+  it("delete_resource: requires namespace, name, kind, and version", async () => {
+    const mockRequest = vi.fn();
+    const client = makeClient(mockRequest);
+
+    await expect(
+      registry.dispatchExecute(client, "gitops_application", "delete_resource", {
+        agent_id: "account.myagent",
+        app_name: "demo-app",
+        body: { namespace: "default", resourceName: "migrate-db", kind: "Job" },
+      }),
+    ).rejects.toThrow(/delete_resource requires body\.namespace.*body\.version/);
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
 });
 
 // ---------------------------------------------------------------------------
@@ -566,6 +707,97 @@ describe("gitops_applicationset", () => {
     expect(call.path).toBe("/gitops/api/v1/applicationset/cce8a056-8059-4abc-def0-123456789abc");
     expect(call.params.agentIdentifier).toBe("account.myagent");
   });
+
+  describe("preview", () => {
+    const template = {
+      metadata: { name: "app-{{.env}}" },
+      spec: {
+        project: "default",
+        source: { repoURL: "https://github.com/org/repo", path: "envs/{{.env}}", targetRevision: "HEAD" },
+        destination: { server: "https://kubernetes.default.svc", namespace: "{{.env}}" },
+      },
+    };
+
+    it("renders the proposed generators and diffs them against the apps the appset owns", async () => {
+      const mockRequest = vi.fn().mockResolvedValue({
+        metadata: { name: "envs" },
+        spec: { goTemplate: true, generators: [], template },
+        status: { resources: [{ name: "app-dev" }, { name: "app-old" }] },
+      });
+      const client = makeClient(mockRequest);
+
+      const result = await registry.dispatchExecute(client, "gitops_applicationset", "preview", {
+        agent_id: "account.myagent",
+        appset_id: "cce8a056",
+        body: {
+          applicationset: {
+            metadata: { name: "envs" },
+            spec: { goTemplate: true, generators: [{ list: { elements: [{ env: "dev" }, { env: "staging" }] } }], template },
+          },
+        },
+      }) as Record<string, any>;
+
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(mockRequest.mock.calls[0][0]).toMatchObject({ method: "GET", path: "/gitops/api/v1/applicationset/cce8a056" });
+      expect(result.generated_count).toBe(2);
+      expect(result.applications[1]).toMatchObject({
+        name: "app-staging",
+        destination: { server: "https://kubernetes.default.svc", namespace: "staging" },
+        source: { path: "envs/staging" },
+      });
+      expect(result.blast_radius).toEqual({ to_create: ["app-staging"], to_delete: ["app-old"], kept: ["app-dev"] });
+      expect(result._hint).toMatch(/deletes 1 application/);
+    });
+
+    it("expands a clusters generator from the agent's clusters, matching the label selector", async () => {
+      const mockRequest = vi.fn().mockResolvedValue({
+        content: [
+          { agentIdentifier: "myagent", identifier: "c1", cluster: { name: "prod-east", server: "https://east", labels: { env: "prod" } } },
+          { agentIdentifier: "myagent", identifier: "c2", cluster: { name: "dev", server: "https://dev", labels: { env: "dev" } } },
+          { agentIdentifier: "other", identifier: "c3", cluster: { name: "prod-west", server: "https://west", labels: { env: "prod" } } },
+        ],
+      });
+      const client = makeClient(mockRequest);
+
+      const result = await registry.dispatchExecute(client, "gitops_applicationset", "preview", {
+        agent_id: "account.myagent",
+        body: {
+          applicationset: {
+            metadata: { name: "fleet" },
+            spec: {
+              generators: [{ clusters: { selector: { matchLabels: { env: "prod" } } } }],
+              template: { metadata: { name: "guestbook-{{name}}" }, spec: { project: "default", destination: { server: "{{server}}", namespace: "guestbook" } } },
+            },
+          },
+        },
+      }) as Record<string, any>;
+
+      expect(mockRequest.mock.calls[0][0]).toMatchObject({ method: "POST", path: "/gitops/api/v1/clusters" });
+      expect(result.applications.map((a: any) => [a.name, a.destination.server])).toEqual([["guestbook-prod-east", "https://east"]]);
+      expect(result.blast_radius).toBeUndefined();
+    });
+
+    it("reports generators it cannot expand offline", async () => {
+      const result = await registry.dispatchExecute(makeClient(vi.fn()), "gitops_applicationset", "preview", {
+        agent_id: "account.myagent",
+        body: {
+          applicationset: {
+            metadata: { name: "monorepo" },
+            spec: { generators: [{ git: { repoURL: "https://github.com/org/repo", directories: [{ path: "apps/*" }] } }], template },
+          },
+        },
+      }) as Record<string, any>;
+
+      expect(result.generated_count).toBe(0);
+      expect(result.unrendered_generators).toEqual([expect.objectContaining({ generator: "git" })]);
+    });
+
+    it("needs a proposed or stored ApplicationSet", async () => {
+      await expect(
+        registry.dispatchExecute(makeClient(vi.fn()), "gitops_applicationset", "preview", { agent_id: "account.myagent" }),
+      ).rejects.toThrow(/body\.applicationset/);
+    });
+  });
 });
 
 // ---------------------------------------------------------------------------
@@ -951,6 +1183,39 @@ describe("gitops sub-resources", () => {
     expect(call.path).toBe("/gitops/api/v1/agents/account.myagent/applications/demo-app/resource-tree");
   });
 
+  it("gitops_app_resource_tree get: format=mermaid adds an owner → owned flowchart coloured by health", async () => {
+    const tree = {
+      nodes: [
+        { kind: "Deployment", group: "apps", name: "web", namespace: "prod", health: { status: "Degraded" } },
+        { kind: "ReplicaSet", group: "apps", name: "web-7d9", namespace: "prod", health: { status: "Healthy" }, parentRefs: [{ kind: "Deployment", group: "apps", name: "web", namespace: "prod" }] },
+        { kind: "Pod", name: "web-7d9-x1", namespace: "prod", health: { status: "Progressing" }, parentRefs: [{ kind: "ReplicaSet", name: "web-7d9", namespace: "prod" }] },
+      ],
+    };
+    const client = makeClient(vi.fn().mockResolvedValue(tree));
+
+    const result = await registry.dispatch(client, "gitops_app_resource_tree", "get", {
+      agent_id: "account.myagent",
+      app_name: "demo-app",
+      format: "mermaid",
+    }) as { nodes: unknown[]; mermaid: string };
+
+    expect(result.nodes).toHaveLength(3);
+    expect(result.mermaid.split("\n")).toEqual([
+      "flowchart LR",
+      '  n0["Deployment web (Degraded)"]',
+      '  n1["ReplicaSet web-7d9 (Healthy)"]',
+      '  n2["Pod web-7d9-x1 (Progressing)"]',
+      "  n0 --> n1",
+      "  n1 --> n2",
+      "  classDef failed fill:#fdd,stroke:#c00",
+      "  class n0 failed",
+      "  classDef ok fill:#dfd,stroke:#080",
+      "  class n1 ok",
+      "  classDef running fill:#def,stroke:#06c",
+      "  class n2 running",
+    ]);
+  });
+
   it("gitops_dashboard get: simple GET with no identifiers", async () => {
     const mockRequest = vi.fn().mockResolvedValue({ summary: {} });
     const client = makeClient(mockRequest);
@@ -1684,3 +1949,464 @@ describe("gitops supportedScopes", () => {
     ).rejects.toThrow(/gitops_application does not support account scope/);
   });
 });
+
+// ---------------------------------------------------------------------------
+// gitops_app_diff
+// ---------------------------------------------------------------------------
+
+describe("gitops_app_diff", () => {
+  let registry: Registry;
+
+  const deployment = (replicas: number, extra: Record<string, unknown> = {}) => JSON.stringify({
+    apiVersion: "apps/v1",
+    kind: "Deployment",
+    metadata: { name: "web", namespace: "prod", ...extra },
+    spec: { replicas },
+  });
+
+  const managedResources = {
+    items: [
+      {
+        group: "apps", kind: "Deployment", namespace: "prod", name: "web",
+        targetState: deployment(3),
+        normalizedLiveState: deployment(2, { resourceVersion: "991", uid: "abc", managedFields: [{}] }),
+        modified: true,
+      },
+      {
+        group: "", kind: "ConfigMap", namespace: "prod", name: "settings",
+        targetState: JSON.stringify({ kind: "ConfigMap", metadata: { name: "settings" }, data: { a: "1" } }),
+        normalizedLiveState: JSON.stringify({ kind: "ConfigMap", metadata: { name: "settings", resourceVersion: "5" }, data: { a: "1" }, status: {} }),
+      },
+      { group: "", kind: "Service", namespace: "prod", name: "web", targetState: JSON.stringify({ kind: "Service", metadata: { name: "web" } }), liveState: "null" },
+      { group: "batch", kind: "Job", namespace: "prod", name: "old", targetState: "null", liveState: JSON.stringify({ kind: "Job", metadata: { name: "old" } }) },
+    ],
+  };
+
+  beforeEach(() => {
+    registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+  });
+
+  it("get: reads managed resources for the app", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({ items: [] });
+    const client = makeClient(mockRequest);
+
+    await registry.dispatch(client, "gitops_app_diff", "get", { agent_id: "account.myagent", app_name: "demo-app" });
+
+    const call = mockRequest.mock.calls[0][0];
+    expect(call.method).toBe("GET");
+    expect(call.path).toBe("/gitops/api/v1/agents/account.myagent/applications/demo-app/managed-resources");
+  });
+
+  it("get: classifies drift per resource and diffs live against desired, ignoring server metadata", async () => {
+    const client = makeClient(vi.fn().mockResolvedValue(managedResources));
+
+    const result = await registry.dispatch(client, "gitops_app_diff", "get", { agent_id: "account.myagent", app_name: "demo-app" }) as {
+      in_sync: boolean;
+      summary: Record<string, number>;
+      items: Array<{ kind: string; name: string; status: string; diff?: string }>;
+      _hint?: string;
+    };
+
+    expect(result.in_sync).toBe(false);
+    expect(result.summary).toEqual({ total: 4, out_of_sync: 1, missing: 1, extra: 1, synced: 1 });
+    expect(result.items.map((i) => [i.kind, i.status])).toEqual([["Deployment", "out_of_sync"], ["Service", "missing"], ["Job", "extra"]]);
+    const diff = result.items[0]!.diff!;
+    expect(diff).toContain("-  replicas: 2");
+    expect(diff).toContain("+  replicas: 3");
+    expect(diff).not.toContain("resourceVersion");
+    expect(result._hint).toContain("sync");
+  });
+
+  it("get: filters by kind and lists synced resources on request", async () => {
+    const client = makeClient(vi.fn().mockResolvedValue(managedResources));
+
+    const result = await registry.dispatch(client, "gitops_app_diff", "get", {
+      agent_id: "account.myagent", app_name: "demo-app", kind: "configmap", include_synced: true,
+    }) as { in_sync: boolean; items: Array<{ name: string; status: string; diff?: string }>; _hint?: string };
+
+    expect(result.in_sync).toBe(true);
+    expect(result.items).toEqual([expect.objectContaining({ name: "settings", status: "synced", lines_added: 0 })]);
+    expect(result.items[0]!.diff).toBeUndefined();
+    expect(result._hint).toBeUndefined();
+  });
+});
+
+// ---------------------------------------------------------------------------
+// gitops_agent_install
+// ---------------------------------------------------------------------------
+
+describe("gitops_agent_install", () => {
+  const MANIFEST = "apiVersion: v1\nkind: Secret\nmetadata:\n  name: gitops-agent\nstringData:\n  GITOPS_AGENT_TOKEN: secret-token\n";
+
+  function buffer(text: string): ArrayBuffer {
+    return new TextEncoder().encode(text).buffer as ArrayBuffer;
+  }
+
+  it("get: fetches deploy.yaml for a raw agent id and returns it with kubectl commands", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const mockRequest = vi.fn().mockResolvedValue(buffer(MANIFEST));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "gitops_agent_install", "get", {
+      agent_id: "prodcluster",
+      namespace: "gitops",
+    }) as Record<string, unknown>;
+
+    const call = mockRequest.mock.calls[0][0];
+    expect(call.path).toBe("/gitops/api/v1/agents/prodcluster/deploy.yaml");
+    expect(call.params).toMatchObject({ namespace: "gitops", projectIdentifier: "test-project" });
+    expect(call.responseType).toBe("buffer");
+    expect(result).toMatchObject({ agent_id: "prodcluster", format: "yaml", namespace: "gitops", manifest: MANIFEST });
+    expect(result.install).toContain("kubectl apply -f gitops-agent.yaml -n gitops");
+  });
+
+  it("get: format helm fetches the values override and returns helm commands", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const mockRequest = vi.fn().mockResolvedValue(buffer("agent:\n  token: secret-token\n"));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "gitops_agent_install", "get", {
+      agent_id: "prodcluster",
+      format: "helm",
+      resource_scope: "account",
+    }) as Record<string, unknown>;
+
+    const call = mockRequest.mock.calls[0][0];
+    expect(call.path).toBe("/gitops/api/v1/agents/prodcluster/helm/overrides");
+    expect(call.params.orgIdentifier).toBeUndefined();
+    expect(result.helm_values).toBe("agent:\n  token: secret-token\n");
+    expect((result.install as string[]).at(-1)).toContain("helm install argocd gitops-agent/gitops-helm --values override.yaml --namespace argocd");
+  });
+
+  it("create: registers the agent and returns its manifest in one call", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) =>
+      opts.method === "POST"
+        ? { identifier: "prodcluster", name: "prodcluster", health: { harnessHeartbeat: { status: "UNHEALTHY" } } }
+        : buffer(MANIFEST));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "gitops_agent_install", "create", {
+      body: { identifier: "prodcluster", namespace: "argocd", high_availability: true },
+    }) as Record<string, unknown>;
+
+    const create = mockRequest.mock.calls[0][0];
+    expect(create.path).toBe("/gitops/api/v1/agents");
+    expect(create.body).toMatchObject({
+      identifier: "prodcluster",
+      name: "prodcluster",
+      type: "MANAGED_ARGO_PROVIDER",
+      metadata: { namespace: "argocd", highAvailability: true, isNamespaced: false },
+      accountIdentifier: "test-account",
+      projectIdentifier: "test-project",
+    });
+    expect(mockRequest.mock.calls[1][0]).toMatchObject({
+      path: "/gitops/api/v1/agents/prodcluster/deploy.yaml",
+      params: expect.objectContaining({ namespace: "argocd" }),
+    });
+    expect(result).toMatchObject({ agent: { identifier: "prodcluster" }, manifest: MANIFEST, namespace: "argocd" });
+  });
+
+  it("create: returns the registration when the manifest fetch fails", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.method === "POST") return { identifier: "prodcluster" };
+      throw new Error("manifest unavailable");
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "gitops_agent_install", "create", {
+      body: { identifier: "prodcluster", namespace: "argocd" },
+    }) as Record<string, unknown>;
+
+    expect(result).toMatchObject({ agent: { identifier: "prodcluster" }, install_error: "manifest unavailable" });
+    expect(result._hint).toContain("harness_get(resource_type='gitops_agent_install', resource_id='prodcluster')");
+  });
+
+  it("create: rejects scope-prefixed identifiers and is blocked in read-only mode", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const mockRequest = vi.fn();
+
+    await expect(registry.dispatch(makeClient(mockRequest), "gitops_agent", "create", {
+      body: { identifier: "account.prodcluster", namespace: "argocd" },
+    })).rejects.toThrow(/must be raw/);
+
+    const readOnly = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops", HARNESS_READ_ONLY: true }));
+    await expect(readOnly.dispatch(makeClient(mockRequest), "gitops_agent_install", "create", {
+      body: { identifier: "prodcluster", namespace: "argocd" },
+    })).rejects.toThrow(/Read-only mode/);
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
+});
+
+// ---------------------------------------------------------------------------
+// gitops_app_history
+// ---------------------------------------------------------------------------
+
+describe("gitops_app_history", () => {
+  const APP = {
+    app: {
+      metadata: { name: "web" },
+      status: {
+        sync: { status: "Synced", revision: "c3" },
+        health: { status: "Healthy" },
+        history: [
+          { id: 1, revision: "a1", deployStartedAt: "2026-05-01T10:00:00Z", deployedAt: "2026-05-01T10:00:30Z", initiatedBy: { automated: true } },
+          { id: 2, revision: "b2", deployStartedAt: "2026-05-02T09:00:00Z", deployedAt: "2026-05-02T09:01:00Z", initiatedBy: { username: "alice" } },
+          { id: 3, revision: "c3", deployStartedAt: "2026-05-02T11:00:00Z", deployedAt: "2026-05-02T11:00:20Z", initiatedBy: { username: "bob" } },
+        ],
+        operationState: {
+          phase: "Succeeded",
+          startedAt: "2026-05-02T11:00:00Z",
+          finishedAt: "2026-05-02T11:00:20Z",
+          operation: { sync: { revision: "c3" }, initiatedBy: { username: "bob" } },
+          syncResult: { revision: "c3" },
+        },
+      },
+    },
+  };
+
+  const EVENTS = {
+    items: [
+      { reason: "ResourceUpdated", type: "Normal", message: "Updated health status: Healthy -> Degraded", lastTimestamp: "2026-05-02T09:03:00Z" },
+      { reason: "BackOff", type: "Warning", message: "Back-off restarting failed container web", lastTimestamp: "2026-05-02T09:05:00Z" },
+      { reason: "OperationCompleted", type: "Warning", message: "Sync operation to d4 failed: one or more objects failed to apply", lastTimestamp: "2026-05-02T10:00:00Z" },
+      { reason: "ResourceUpdated", type: "Normal", message: "Updated health status: Degraded -> Healthy", lastTimestamp: "2026-05-02T11:01:00Z" },
+      { reason: "OperationStarted", type: "Normal", message: "bob initiated sync to main (c3)", lastTimestamp: "2026-05-02T11:00:00Z" },
+    ],
+  };
+
+  function route(opts: Record<string, any>): unknown {
+    if (opts.path.endsWith("/events")) return EVENTS;
+    if (opts.path === "/gitops/api/v1/agents/account.myagent/applications/web") return APP;
+    throw new Error(`unexpected ${opts.path}`);
+  }
+
+  it("merges deployments, operations, and health changes into one timeline with the last degradation", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const client = makeClient(vi.fn(async (opts: Record<string, any>) => route(opts)));
+
+    const result = await registry.dispatch(client, "gitops_app_history", "get", {
+      agent_id: "account.myagent",
+      app_name: "web",
+    }) as Record<string, any>;
+
+    expect(result.current).toMatchObject({ sync_status: "Synced", health_status: "Healthy", revision: "c3" });
+    expect(result.last_operation).toMatchObject({ phase: "Succeeded", revision: "c3", duration_seconds: 20, initiator: "bob" });
+    expect(result.timeline.map((e: { type: string; at: string }) => [e.type, e.at])).toEqual([
+      ["health", "2026-05-02T11:01:00.000Z"],
+      ["deployment", "2026-05-02T11:00:20.000Z"],
+      ["operation", "2026-05-02T11:00:00.000Z"],
+      ["operation", "2026-05-02T10:00:00.000Z"],
+      ["health", "2026-05-02T09:03:00.000Z"],
+      ["deployment", "2026-05-02T09:01:00.000Z"],
+      ["deployment", "2026-05-01T10:00:30.000Z"],
+    ]);
+    expect(result.timeline[3]).toMatchObject({ phase: "Failed", revision: "d4", message: "one or more objects failed to apply" });
+    expect(result.timeline[2]).toMatchObject({ phase: "Started", initiator: "bob", revision: "main (c3)" });
+    expect(result.timeline[6]).toMatchObject({ initiator: "automated", duration_seconds: 30 });
+    expect(result.last_degraded).toMatchObject({
+      at: "2026-05-02T09:03:00.000Z",
+      from: "Healthy",
+      recovered_at: "2026-05-02T11:01:00.000Z",
+      after: { type: "deployment", revision: "b2", initiator: "alice" },
+      warnings: [{ reason: "BackOff", message: "Back-off restarting failed container web" }],
+    });
+    expect(result.summary).toEqual({ deployments: 3, failed_operations: 1, health_changes: 2 });
+  });
+
+  it("returns the deployment history when events cannot be loaded", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+    const client = makeClient(vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/events")) throw new Error("events unavailable");
+      return route(opts);
+    }));
+
+    const result = await registry.dispatch(client, "gitops_app_history", "get", {
+      agent_id: "account.myagent",
+      app_name: "web",
+      limit: 2,
+    }) as Record<string, any>;
+
+    expect(result.timeline).toHaveLength(2);
+    expect(result).toMatchObject({ truncated: true, timeline_total: 3, last_degraded: null });
+    expect(result.errors).toEqual([expect.stringContaining("events unavailable")]);
+  });
+});
+
+// ---------------------------------------------------------------------------
+// gitops_application sync_applications (bulk fan-out)
+// ---------------------------------------------------------------------------
+
+describe("gitops_application sync_applications", () => {
+  let registry: Registry;
+
+  beforeEach(() => {
+    registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+  });
+
+  function listedApp(agent: string, name: string, labels: Record<string, string>) {
+    return { agentIdentifier: agent, name, app: { metadata: { name, labels } } };
+  }
+
+  const APPS = [
+    listedApp("account.prod", "payments", { env: "prod", team: "payments" }),
+    listedApp("account.prod", "checkout", { env: "prod", team: "web" }),
+    listedApp("account.prod", "legacy", { env: "prod", legacy: "true" }),
+    listedApp("account.stage", "payments-stage", { env: "stage", team: "payments" }),
+  ];
+
+  function route(opts: Record<string, any>): unknown {
+    if (opts.path === "/gitops/api/v1/applications") return { content: APPS, totalPages: 1 };
+    if (String(opts.path).endsWith("/applications/checkout/sync")) throw new Error("permission denied");
+    if (String(opts.path).endsWith("/sync")) {
+      return { app: { status: { sync: { status: "Synced", revision: "abc123" }, health: { status: "Progressing" }, operationState: { phase: "Running" } } } };
+    }
+    throw new Error(`unexpected ${opts.path}`);
+  }
+
+  it("syncs every app matching the label selector and returns a consolidated status table", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => route(opts));
+
+    const result = await registry.dispatchExecute(makeClient(request), "gitops_application", "sync_applications", {
+      body: { label_selector: "env=prod,!legacy", prune: true },
+    }) as Record<string, any>;
+
+    const syncCalls = request.mock.calls.map(([opts]) => opts).filter((opts) => String(opts.path).endsWith("/sync"));
+    expect(syncCalls.map((c) => c.path).sort()).toEqual([
+      "/gitops/api/v1/agents/account.prod/applications/checkout/sync",
+      "/gitops/api/v1/agents/account.prod/applications/payments/sync",
+    ]);
+    expect(syncCalls[0].body).toEqual({ prune: true });
+    expect(result.summary).toEqual({ requested: 2, triggered: 1, failed: 1 });
+    expect(result.applications).toEqual([
+      { agent_id: "account.prod", app_name: "payments", result: "triggered", sync: "Synced", health: "Progressing", phase: "Running", revision: "abc123", error: null },
+      { agent_id: "account.prod", app_name: "checkout", result: "failed", sync: null, health: null, phase: null, revision: null, error: "permission denied" },
+    ]);
+    expect(result.table.split("\n")[2]).toBe("| account.prod | payments | triggered | Synced | Progressing | Running |  |");
+    expect(result.label_selector).toBe("env=prod,!legacy");
+  });
+
+  it("syncs an explicit list without listing apps", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => route(opts));
+
+    const result = await registry.dispatchExecute(makeClient(request), "gitops_application", "sync_applications", {
+      body: { targets: [{ agent_id: "account.stage", app_name: "payments-stage" }], dryRun: true },
+    }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(request.mock.calls[0]![0].body).toEqual({ dryRun: true });
+    expect(result).toMatchObject({ dry_run: true, summary: { requested: 1, triggered: 1, failed: 0 } });
+    expect(result.applications[0].result).toBe("dry_run");
+  });
+
+  it("refuses to sync more apps than max_apps", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => route(opts));
+
+    await expect(registry.dispatchExecute(makeClient(request), "gitops_application", "sync_applications", {
+      body: { label_selector: { team: "payments" }, max_apps: 1 },
+    })).rejects.toThrow(/would sync 2 applications, over the limit of 1/);
+    expect(request.mock.calls.some(([opts]) => String(opts.path).endsWith("/sync"))).toBe(false);
+  });
+
+  it("reports a selector that matches nothing", async () => {
+    await expect(registry.dispatchExecute(makeClient(async (opts: Record<string, any>) => route(opts)), "gitops_application", "sync_applications", {
+      body: { label_selector: "env=dev" },
+    })).rejects.toThrow(/No GitOps applications match/);
+  });
+
+  it("is blocked in read-only mode", async () => {
+    const readOnly = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops", HARNESS_READ_ONLY: true }));
+    const request = vi.fn();
+
+    await expect(readOnly.dispatchExecute(makeClient(request), "gitops_application", "sync_applications", {
+      body: { label_selector: "env=prod" },
+    })).rejects.toThrow(/Read-only mode/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
+
+// ---------------------------------------------------------------------------
+// gitops_pod_log previous / since / follow
+// ---------------------------------------------------------------------------
+
+describe("gitops_pod_log", () => {
+  let registry: Registry;
+
+  beforeEach(() => {
+    registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "gitops" }));
+  });
+
+  function ndjson(entries: Array<[string, string]>): ArrayBuffer {
+    const text = entries.map(([at, content]) => JSON.stringify({ result: { content, timeStamp: at, podName: "api-7d9-x1" } })).join("\n");
+    return new TextEncoder().encode(text).buffer as ArrayBuffer;
+  }
+
+  it("reads the previous container's streamed log as plain lines", async () => {
+    const request = vi.fn().mockResolvedValue(ndjson([
+      ["2026-03-01T10:00:00Z", "starting"],
+      ["2026-03-01T10:00:01Z", "panic: nil map"],
+    ]));
+
+    const result = await registry.dispatch(makeClient(request), "gitops_pod_log", "get", {
+      agent_id: "account.myagent",
+      app_name: "demo-app",
+      pod_name: "api-7d9-x1",
+      previous: true,
+      since_seconds: 300,
+    }) as Record<string, any>;
+
+    const call = request.mock.calls[0][0];
+    expect(call.params).toMatchObject({ podName: "api-7d9-x1", previous: true, sinceSeconds: 300 });
+    expect(result).toMatchObject({
+      previous: true,
+      line_count: 2,
+      lines: ["2026-03-01T10:00:00Z starting", "2026-03-01T10:00:01Z panic: nil map"],
+    });
+  });
+
+  it("follow polls for new lines until follow_seconds and drops overlapping entries", async () => {
+    vi.useFakeTimers();
+    try {
+      const request = vi.fn()
+        .mockResolvedValueOnce(ndjson([["2026-03-01T10:00:00Z", "boot"]]))
+        .mockResolvedValueOnce(ndjson([["2026-03-01T10:00:00Z", "boot"], ["2026-03-01T10:00:03Z", "ready"]]))
+        .mockResolvedValue(ndjson([["2026-03-01T10:00:05Z", "crash"]]));
+
+      const pending = registry.dispatch(makeClient(request), "gitops_pod_log", "get", {
+        agent_id: "account.myagent",
+        app_name: "demo-app",
+        pod_name: "api-7d9-x1",
+        tail_lines: 50,
+        follow: true,
+        follow_seconds: 7,
+      });
+      await vi.advanceTimersByTimeAsync(10_000);
+      const result = await pending as Record<string, any>;
+
+      expect(request).toHaveBeenCalledTimes(3);
+      expect(request.mock.calls[0][0].params.tailLines).toBe(50);
+      expect(request.mock.calls[1][0].params.tailLines).toBeUndefined();
+      expect(request.mock.calls[1][0].params.sinceSeconds).toBeGreaterThanOrEqual(3);
+      expect(result.lines).toEqual([
+        "2026-03-01T10:00:00Z boot",
+        "2026-03-01T10:00:03Z ready",
+        "2026-03-01T10:00:05Z crash",
+      ]);
+      expect(result.follow.polls).toBe(2);
+    } finally {
+      vi.useRealTimers();
+    }
+  });
+
+  it("does not follow a previous container", async () => {
+    const request = vi.fn().mockResolvedValue(ndjson([]));
+
+    const result = await registry.dispatch(makeClient(request), "gitops_pod_log", "get", {
+      agent_id: "account.myagent",
+      app_name: "demo-app",
+      previous: true,
+      follow: true,
+    }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(result.follow).toBeUndefined();
+    expect(result._hint).toContain("previous container");
+  });
+});
@@ -0,0 +1,139 @@
+/**
+ * Unit tests for IDP scorecard results: failing-entity filters on scorecard
+ * and check stats, and the per-check breakdown on entity scores.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+const SCORECARD_STATS = {
+  name: "Production Readiness",
+  timestamp: 1790000000000,
+  stats: [
+    { name: "payments", kind: "Component", type: "service", owner: "team-a", score: 100 },
+    { name: "ledger", kind: "Component", type: "service", owner: "team-b", score: 40 },
+    { name: "ui-kit", kind: "Component", type: "library", owner: "team-c", score: 20 },
+    { name: "orders", kind: "Component", type: "service", owner: "team-a", score: 75 },
+  ],
+};
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_MCP_MODE: "single-user",
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: undefined,
+    HARNESS_PROJECT: undefined,
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    LOG_LEVEL: "info",
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_AUTO_APPROVE_RISK: "none",
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_TOOLSETS: "idp",
+    ...overrides,
+  } as Config;
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+describe("scorecard_stats get", () => {
+  it("returns every entity unchanged when no filter is given", async () => {
+    const request = vi.fn().mockResolvedValue(SCORECARD_STATS);
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "scorecard_stats", "get", { scorecard_id: "prod_ready" }) as Record<string, unknown>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/v1/scorecards/prod_ready/stats" });
+    expect(result).toEqual({ name: "Production Readiness", stats: SCORECARD_STATS.stats, time: new Date(1790000000000).toISOString() });
+  });
+
+  it("lists services scoring below a threshold, lowest first", async () => {
+    const request = vi.fn().mockResolvedValue(SCORECARD_STATS);
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "scorecard_stats", "get", {
+      scorecard_id: "prod_ready",
+      below_score: 100,
+      type: "service",
+    }) as Record<string, unknown>;
+
+    expect((result.stats as Array<{ name: string }>).map((s) => s.name)).toEqual(["ledger", "orders"]);
+    expect(result).toMatchObject({ matched: 2, entities: 4 });
+    expect((request.mock.calls[0]![0] as { params?: Record<string, unknown> }).params ?? {}).not.toHaveProperty("below_score");
+  });
+});
+
+describe("scorecard_check_stats get", () => {
+  it("narrows to the entities failing the check", async () => {
+    const request = vi.fn().mockResolvedValue({
+      name: "Has owner",
+      timestamp: null,
+      stats: [
+        { name: "payments", kind: "Component", type: "service", status: "PASS" },
+        { name: "ledger", kind: "Component", type: "service", status: "FAIL" },
+        { name: "billing-api", kind: "API", type: "openapi", status: "FAIL" },
+      ],
+    });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "scorecard_check_stats", "get", {
+      check_id: "has_owner",
+      status: "fail",
+      kind: "Component",
+    }) as Record<string, unknown>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/v1/checks/has_owner/stats" });
+    expect(result).toEqual({
+      name: "Has owner",
+      stats: [{ name: "ledger", kind: "Component", type: "service", status: "FAIL" }],
+      time: "",
+      matched: 1,
+      entities: 3,
+    });
+  });
+});
+
+describe("idp_score list", () => {
+  it("counts each scorecard's passed and failed checks and names the failures", async () => {
+    const request = vi.fn().mockResolvedValue({
+      overall_score: 60,
+      scorecard_scores: [
+        {
+          scorecard_name: "Production Readiness",
+          score: 50,
+          checks: [
+            { name: "Has owner", status: "PASS" },
+            { name: "Has runbook", status: "FAIL", reason: "No runbook annotation" },
+          ],
+        },
+        { scorecard_name: "Documentation", score: 70 },
+      ],
+    });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "idp_score", "list", { entity_identifier: "default/Component/ledger" }) as Record<string, unknown>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/v1/scores", params: { entity_identifier: "default/Component/ledger" } });
+    expect(result).toMatchObject({ overall_score: 60, total: 2 });
+    expect(result.items).toEqual([
+      expect.objectContaining({
+        scorecard_name: "Production Readiness",
+        passed: 1,
+        failed: 1,
+        failing_checks: [{ name: "Has runbook", reason: "No runbook annotation" }],
+      }),
+      { scorecard_name: "Documentation", score: 70 },
+    ]);
+  });
+});
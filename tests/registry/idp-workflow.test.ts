@@ -0,0 +1,197 @@
+/**
+ * Unit tests for IDP workflow get (input schema), execute, and run status.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+const WORKFLOW_YAML = `apiVersion: harness.io/v1
+kind: workflow
+name: New Microservice
+identifier: new_microservice
+spec:
+  parameters:
+    - title: Service details
+      required: [service_name, language]
+      properties:
+        service_name:
+          type: string
+          title: Service name
+          description: Name of the new repository
+        language:
+          type: string
+          enum: [go, java, node]
+        visibility:
+          type: string
+          default: private
+    - title: Auth
+      required: [token]
+      properties:
+        token:
+          type: string
+          ui:field: HarnessAuthToken
+  steps:
+    - id: create_repo
+      name: Create repository
+      action: trigger:harness-custom-pipeline
+      input:
+        apikey: \${{ parameters.token }}`;
+
+const WORKFLOW_ENTITY = {
+  identifier: "new_microservice",
+  name: "New Microservice",
+  owner: "group:default/platform",
+  scope: "account",
+  yaml: WORKFLOW_YAML,
+};
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_MCP_MODE: "single-user",
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: undefined,
+    HARNESS_PROJECT: undefined,
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    LOG_LEVEL: "info",
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_AUTO_APPROVE_RISK: "none",
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_TOOLSETS: "idp",
+    ...overrides,
+  } as Config;
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+describe("idp_workflow discovery", () => {
+  it("is found by software template and golden path searches", () => {
+    const registry = new Registry(makeConfig());
+
+    expect(registry.searchResources("software template")[0]?.type).toBe("idp_workflow");
+    expect(registry.searchResources("golden path")[0]?.type).toBe("idp_workflow");
+    expect(registry.getResource("idp_workflow").relatedResources?.map((r) => r.resourceType)).toContain("idp_workflow_run");
+  });
+});
+
+describe("idp_workflow get", () => {
+  it("returns the workflow's inputs with required and auto-filled markers", async () => {
+    const request = vi.fn().mockResolvedValue(WORKFLOW_ENTITY);
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "idp_workflow", "get", { workflow_id: "new_microservice" }) as Record<string, unknown>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/v1/entities/account/workflow/new_microservice" });
+    expect(result).toMatchObject({
+      identifier: "new_microservice",
+      owner: "group:default/platform",
+      required_inputs: ["service_name", "language"],
+      steps: [{ id: "create_repo", name: "Create repository", action: "trigger:harness-custom-pipeline" }],
+    });
+    expect(result.inputs).toEqual([
+      { name: "service_name", type: "string", title: "Service name", description: "Name of the new repository", required: true },
+      { name: "language", type: "string", required: true, enum: ["go", "java", "node"] },
+      { name: "visibility", type: "string", required: false, default: "private" },
+      { name: "token", type: "string", required: true, ui_field: "HarnessAuthToken", auto_filled: true },
+    ]);
+  });
+});
+
+describe("idp_workflow execute", () => {
+  it("fetches the workflow when workflow_details is omitted and returns the task id to track", async () => {
+    const request = vi.fn()
+      .mockResolvedValueOnce(WORKFLOW_ENTITY)
+      .mockResolvedValueOnce({ id: "task-123" });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatchExecute(makeClient(request), "idp_workflow", "execute", {
+      workflow_id: "new_microservice",
+      body: { values: { service_name: "payments", language: "go" } },
+    }) as Record<string, unknown>;
+
+    expect(request).toHaveBeenCalledTimes(2);
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/v1/entities/account/workflow/new_microservice" });
+    expect(request.mock.calls[1]![0]).toMatchObject({
+      method: "POST",
+      path: "/v2/workflows/execute",
+      body: { identifier: "new_microservice", values: { service_name: "payments", language: "go", token: "user.token" } },
+    });
+    expect(result).toMatchObject({ task_id: "task-123" });
+    expect(result._hint).toContain("idp_workflow_run");
+  });
+
+  it("rejects a run with missing required inputs before calling execute", async () => {
+    const request = vi.fn().mockResolvedValueOnce(WORKFLOW_ENTITY);
+    const registry = new Registry(makeConfig());
+
+    await expect(registry.dispatchExecute(makeClient(request), "idp_workflow", "execute", {
+      workflow_id: "new_microservice",
+      body: { values: { service_name: "payments" } },
+    })).rejects.toThrow("Missing required workflow inputs: language");
+    expect(request).toHaveBeenCalledTimes(1);
+  });
+});
+
+describe("idp_workflow_run get", () => {
+  it("summarizes step states, the failed step, and completion", async () => {
+    const request = vi.fn().mockResolvedValue({
+      id: "task-123",
+      status: "failed",
+      createdAt: "2026-10-01T10:00:00Z",
+      spec: {
+        templateInfo: { entityRef: "workflow:account/new_microservice" },
+        steps: [
+          { id: "create_repo", name: "Create repository", action: "trigger:harness-custom-pipeline" },
+          { id: "register", name: "Register in catalog", action: "catalog:register" },
+        ],
+      },
+      state: {
+        steps: {
+          create_repo: { status: "completed", startedAt: "2026-10-01T10:00:01Z", endedAt: "2026-10-01T10:00:30Z" },
+          register: { status: "failed", startedAt: "2026-10-01T10:00:31Z" },
+        },
+        error: { message: "catalog-info.yaml not found" },
+      },
+    });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "idp_workflow_run", "get", { task_id: "task-123" }) as Record<string, unknown>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/v2/workflows/tasks/task-123" });
+    expect(result).toMatchObject({
+      task_id: "task-123",
+      workflow: "workflow:account/new_microservice",
+      status: "failed",
+      done: true,
+      succeeded: false,
+      failed_step: "Register in catalog",
+      error: "catalog-info.yaml not found",
+      steps: [
+        { id: "create_repo", status: "completed", ended_at: "2026-10-01T10:00:30Z" },
+        { id: "register", status: "failed" },
+      ],
+    });
+    expect(result._hint).toBeUndefined();
+  });
+
+  it("asks to poll again while the run is in progress", async () => {
+    const request = vi.fn().mockResolvedValue({ id: "task-9", status: "processing", spec: { steps: [] } });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "idp_workflow_run", "get", { task_id: "task-9" }) as Record<string, unknown>;
+
+    expect(result).toMatchObject({ status: "processing", done: false });
+    expect(result._hint).toMatch(/still running/);
+  });
+});
@@ -0,0 +1,148 @@
+/**
+ * Tests for my_action_item: the current user's approvals, review requests,
+ * and input-waiting executions gathered into one to-do list.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines,pull-requests",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+    getCurrentUserId: vi.fn().mockResolvedValue("user-uuid"),
+  } as unknown as HarnessClient;
+}
+
+const APPROVAL_EXECUTION = { planExecutionId: "exec-1", pipelineIdentifier: "deploy", name: "Deploy", runSequence: 12 };
+const INPUT_EXECUTION = { planExecutionId: "exec-2", pipelineIdentifier: "release", name: "Release", status: "InputWaiting", runSequence: 4, startTs: 1752000000000 };
+
+function approval(id: string, groups: string[], deadline: number) {
+  return {
+    id,
+    type: "HarnessApproval",
+    node_execution_id: `node-${id}`,
+    details: { approvers: { user_groups: groups, minimum_count: 1 }, approval_message: `Approve ${id}` },
+    deadline,
+  };
+}
+
+function harnessApi(overrides: { userGroups?: () => unknown; pullRequests?: (opts: Record<string, any>) => unknown } = {}) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    const path = opts.path as string;
+    if (path === "/pipeline/api/pipelines/execution/summary") {
+      const status = opts.body?.status as string[];
+      if (status.includes("ApprovalWaiting") && status.length === 1) {
+        return { data: { content: [APPROVAL_EXECUTION], totalElements: 1 } };
+      }
+      return { data: { content: [INPUT_EXECUTION], totalElements: 1 } };
+    }
+    if (path.endsWith("/approvals/execution/exec-1")) {
+      return [
+        approval("a-mine", ["account.release_managers"], 1752100000000),
+        approval("a-other", ["qa_team"], 1752000000000),
+      ];
+    }
+    if (path === "/ng/api/user-groups/batch") {
+      return overrides.userGroups ? overrides.userGroups() : { data: [{ identifier: "release_managers", accountIdentifier: "test-account" }] };
+    }
+    if (path === "/code/api/v1/user") return { id: 42, uid: "user-uuid" };
+    if (path.endsWith("/pullreq")) {
+      return overrides.pullRequests
+        ? overrides.pullRequests(opts)
+        : [{ number: 7, title: "Add retries", author: { display_name: "Sam" }, source_branch: "retries", target_branch: "main", created: 1751900000000 }];
+    }
+    throw new Error(`unexpected path ${path}`);
+  });
+}
+
+describe("my_action_item list", () => {
+  it("combines approvals for the user's groups, review requests, and input-waiting executions", async () => {
+    const registry = new Registry(makeConfig());
+    const request = harnessApi();
+
+    const result = await registry.dispatch(makeClient(request), "my_action_item", "list", { repo_ids: "api, web" }) as Record<string, any>;
+
+    expect(result.user_id).toBe("user-uuid");
+    expect(result.summary).toEqual({ total: 4, approvals: 1, pr_reviews: 2, waiting_inputs: 1 });
+
+    expect(result.approvals.status).toBe("ok");
+    expect(result.approvals.items).toHaveLength(1);
+    expect(result.approvals.items[0]).toMatchObject({ approval_id: "a-mine", pipeline_id: "deploy", message: "Approve a-mine" });
+    expect(result.approvals.other_approvals).toBe(1);
+
+    const groupCall = request.mock.calls.find(([opts]) => opts.path === "/ng/api/user-groups/batch")![0];
+    expect(groupCall.body).toMatchObject({ userIdentifierFilter: ["user-uuid"], orgIdentifier: "default", projectIdentifier: "test-project" });
+
+    const prCalls = request.mock.calls.filter(([opts]) => (opts.path as string).endsWith("/pullreq")).map(([opts]) => opts);
+    expect(prCalls.map((c) => c.path)).toEqual(["/code/api/v1/repos/api/pullreq", "/code/api/v1/repos/web/pullreq"]);
+    expect(prCalls[0]!.params).toMatchObject({ state: "open", reviewer_id: 42, review_decision: "pending" });
+    expect(result.pr_reviews.items[0]).toMatchObject({ repo_id: "api", pr_number: 7, title: "Add retries", author: "Sam", created_at: "2025-07-07T14:53:20.000Z" });
+
+    const waitingCall = request.mock.calls.find(([opts]) => opts.path === "/pipeline/api/pipelines/execution/summary" && opts.body?.status?.[0] === "InputWaiting")![0];
+    expect(waitingCall.body.status).toEqual(["InputWaiting"]);
+    expect(result.waiting_inputs.items[0]).toMatchObject({ execution_id: "exec-2", pipeline_id: "release", status: "InputWaiting" });
+  });
+
+  it("reports pr_reviews as not_configured without repo_ids", async () => {
+    const registry = new Registry(makeConfig());
+    const request = harnessApi();
+
+    const result = await registry.dispatch(makeClient(request), "my_action_item", "list", {}) as Record<string, any>;
+
+    expect(result.pr_reviews.status).toBe("not_configured");
+    expect(request.mock.calls.some(([opts]) => opts.path === "/code/api/v1/user")).toBe(false);
+    expect(result.summary.total).toBe(2);
+  });
+
+  it("lists every pending approval with a note when the user's groups cannot be read", async () => {
+    const registry = new Registry(makeConfig());
+    const request = harnessApi({ userGroups: () => { throw new HarnessApiError("forbidden", 403); } });
+
+    const result = await registry.dispatch(makeClient(request), "my_action_item", "list", {}) as Record<string, any>;
+
+    expect(result.approvals.items.map((a: any) => a.approval_id)).toEqual(["a-other", "a-mine"]);
+    expect(result.approvals.note).toMatch(/every pending approval/);
+  });
+
+  it("keeps the other sections when one repository fails, and marks disabled toolsets unavailable", async () => {
+    const request = harnessApi({
+      pullRequests: (opts) => {
+        if ((opts.path as string).includes("/repos/web/")) throw new HarnessApiError("repo not found", 404);
+        return [];
+      },
+    });
+
+    const result = await new Registry(makeConfig()).dispatch(makeClient(request), "my_action_item", "list", { repo_ids: "api,web" }) as Record<string, any>;
+    expect(result.pr_reviews).toMatchObject({ status: "ok", count: 0, errors: [{ repo_id: "web", error: expect.stringContaining("repo not found") }] });
+    expect(result.approvals.status).toBe("ok");
+
+    const pipelinesOnly = await new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines" }))
+      .dispatch(makeClient(harnessApi()), "my_action_item", "list", { repo_ids: "api" }) as Record<string, any>;
+    expect(pipelinesOnly.pr_reviews.status).toBe("unavailable");
+    expect(pipelinesOnly.summary.pr_reviews).toBe(0);
+  });
+});
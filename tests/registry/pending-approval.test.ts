@@ -0,0 +1,126 @@
+/**
+ * Tests for pending_approval (project-wide waiting approvals) and the
+ * approval_instance approve body.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const APPROVALS_PATH = "/pipeline/api/v1/orgs/default/projects/test-project/approvals/execution/";
+
+describe("pending_approval list", () => {
+  it("lists waiting approvals across ApprovalWaiting executions, soonest deadline first", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { method: string; path: string; params: Record<string, unknown>; body?: unknown }) => {
+      if (opts.path === "/pipeline/api/pipelines/execution/summary") {
+        expect(opts.body).toEqual({ filterType: "PipelineExecution", status: ["ApprovalWaiting"] });
+        return {
+          status: "SUCCESS",
+          data: {
+            content: [
+              { planExecutionId: "exec-1", pipelineIdentifier: "deploy", name: "Deploy", runSequence: 7 },
+              { planExecutionId: "exec-2", pipelineIdentifier: "release", name: "Release", runSequence: 3 },
+            ],
+            totalElements: 2,
+          },
+        };
+      }
+      if (opts.path === `${APPROVALS_PATH}exec-1`) {
+        expect(opts.params.approval_status).toBe("WAITING");
+        return [{
+          id: "appr-1",
+          type: "HarnessApproval",
+          deadline: 1767312000000,
+          created_at: 1767225600000,
+          details: {
+            approval_message: "Promote to prod?",
+            approvers: { user_groups: ["_project_all_users"], minimum_count: 1 },
+            approval_activities: [],
+            approver_inputs: [{ name: "ticket", default_value: "" }],
+          },
+        }];
+      }
+      if (opts.path === `${APPROVALS_PATH}exec-2`) {
+        return [{ id: "appr-2", type: "JiraApproval", deadline: 1767268800000, details: {} }];
+      }
+      throw new Error(`unexpected ${opts.path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "pending_approval", "list", {}) as Record<string, any>;
+    expect(result.total).toBe(2);
+    expect(result.executions_scanned).toBe(2);
+    expect(result.items.map((i: any) => i.approval_id)).toEqual(["appr-2", "appr-1"]);
+    expect(result.items[1]).toMatchObject({
+      type: "HarnessApproval",
+      execution_id: "exec-1",
+      pipeline_id: "deploy",
+      message: "Promote to prod?",
+      deadline: "2026-01-02T00:00:00.000Z",
+      user_groups: ["_project_all_users"],
+      minimum_count: 1,
+      approvals_so_far: 0,
+      approver_inputs: [{ name: "ticket", default_value: "" }],
+      actionable: true,
+    });
+    expect(result.items[1].next_action).toContain("approval_id: 'appr-1'");
+    expect(result.items[0].actionable).toBe(false);
+  });
+
+  it("reports per-execution failures without failing the list", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params: Record<string, unknown> }) => {
+      if (opts.path === "/pipeline/api/pipelines/execution/summary") {
+        expect(opts.params.pipelineIdentifier).toBe("deploy");
+        return { status: "SUCCESS", data: { content: [{ planExecutionId: "exec-1" }], totalElements: 1 } };
+      }
+      throw new Error("forbidden");
+    });
+    const result = await registry.dispatch(makeClient(mockRequest), "pending_approval", "list", { pipeline_id: "deploy" }) as Record<string, any>;
+    expect(result.items).toEqual([]);
+    expect(result.errors).toEqual([{ execution_id: "exec-1", error: "forbidden" }]);
+  });
+});
+
+describe("approval_instance approve", () => {
+  it("accepts approver_inputs as a name/value object", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (_opts: { path: string; body?: unknown }) => ({ status: "SUCCESS", data: {} }));
+    await registry.dispatchExecute(makeClient(mockRequest), "approval_instance", "approve", {
+      approval_id: "appr-1",
+      comments: "ok",
+      approver_inputs: { ticket: "CHG-1" },
+    });
+    const call = mockRequest.mock.calls[0]![0];
+    expect(call.path).toBe("/pipeline/api/approvals/appr-1/harness/activity");
+    expect(call.body).toEqual({ action: "APPROVE", comments: "ok", approverInputs: [{ name: "ticket", value: "CHG-1" }] });
+  });
+});
@@ -0,0 +1,83 @@
+/**
+ * Tests for the ping resource: a single uncached, unthrottled account read
+ * that reports latency, the resolved account, and the server version.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import { getVersion } from "../../src/utils/cli.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "platform",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const ACCOUNT = {
+  status: "SUCCESS",
+  data: { identifier: "test-account", name: "Acme", companyName: "Acme Inc", cluster: "prod2" },
+};
+
+describe("ping", () => {
+  it("reads the account once, without retries, through the client rate limiter", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn().mockResolvedValue(ACCOUNT);
+
+    const result = await registry.dispatch(makeClient(request), "ping", "get", {}) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(request.mock.calls[0][0]).toMatchObject({
+      method: "GET",
+      path: "/ng/api/accounts/test-account",
+      retryPolicy: "do_not_retry",
+    });
+    expect(request.mock.calls[0][0]).not.toHaveProperty("skipRateLimit");
+    expect(result).toMatchObject({
+      ok: true,
+      account: { id: "test-account", name: "Acme", company: "Acme Inc", cluster: "prod2" },
+      server_version: getVersion(),
+    });
+    expect(result.latency_ms).toEqual(expect.any(Number));
+  });
+
+  it("is never served from the response cache", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn().mockResolvedValue(ACCOUNT);
+    const client = makeClient(request);
+
+    await registry.dispatch(client, "ping", "get", {});
+    await registry.dispatch(client, "ping", "get", {});
+
+    expect(request).toHaveBeenCalledTimes(2);
+  });
+
+  it("surfaces an authentication failure instead of reporting ok", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn().mockRejectedValue(new Error("401 Unauthorized"));
+
+    await expect(registry.dispatch(makeClient(request), "ping", "get", {})).rejects.toThrow(/401/);
+  });
+});
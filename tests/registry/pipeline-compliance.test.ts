@@ -0,0 +1,171 @@
+/**
+ * Tests for pipeline_compliance: structural gaps between a pipeline and a
+ * golden pipeline or pipeline template.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const GOLDEN_BODY = `
+  delegateSelectors: [prod-delegate]
+  stages:
+    - stage:
+        identifier: build
+        name: Build
+        type: CI
+        spec:
+          execution:
+            steps:
+              - step: { identifier: compile, name: Compile, type: Run }
+              - step: { identifier: scan, name: Scan, type: Security }
+    - stage:
+        identifier: approve
+        name: Approve
+        type: Approval
+    - stage:
+        identifier: deploy
+        name: Deploy
+        type: Deployment
+        delegateSelectors: [k8s-prod]
+        failureStrategies:
+          - onFailure: { errors: [AllErrors], action: { type: StageRollback } }
+        spec:
+          execution:
+            steps:
+              - stepGroup:
+                  identifier: rollout
+                  steps:
+                    - step: { identifier: canary, name: Canary, type: K8sCanaryDeploy }
+                    - step: { identifier: verify, name: Verify, type: Verify }
+`;
+
+const GOLDEN = `pipeline:\n  identifier: golden\n  name: Golden${GOLDEN_BODY}`;
+
+const PIPELINE = `
+pipeline:
+  identifier: checkout
+  name: Checkout
+  stages:
+    - stage:
+        identifier: ci
+        name: CI
+        type: CI
+        spec:
+          execution:
+            steps:
+              - parallel:
+                  - step: { identifier: build, name: Build, type: Run }
+                  - step: { identifier: lint, name: Lint, type: Run }
+              - step: { identifier: scan, name: Scan, type: Security }
+    - stage:
+        identifier: deploy
+        name: Deploy
+        type: Deployment
+        spec:
+          execution:
+            steps:
+              - step: { identifier: canary, name: Canary, type: K8sCanaryDeploy }
+    - stage:
+        identifier: smoke
+        name: Smoke
+        type: Custom
+`;
+
+describe("pipeline_compliance get", () => {
+  it("reports missing gates, selectors, and failure strategies against a golden pipeline", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => ({
+      data: { yamlPipeline: opts.path.endsWith("/golden") ? GOLDEN : PIPELINE },
+    }));
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_compliance", "get", {
+      pipeline_id: "checkout",
+      golden_pipeline_id: "golden",
+      golden_project_id: "platform",
+    }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ path: "/pipeline/api/pipelines/checkout", params: { projectIdentifier: "test-project" } });
+    expect(request.mock.calls[1]![0]).toMatchObject({ path: "/pipeline/api/pipelines/golden", params: { projectIdentifier: "platform" } });
+    expect(result.verdict).toBe("gaps_found");
+    expect(result.gaps.map((g: Record<string, unknown>) => [g.kind, g.severity, g.stage_id, g.step_id])).toEqual([
+      ["missing_stage", "high", "approve", undefined],
+      ["missing_step", "high", "deploy", "verify"],
+      ["delegate_selectors", "medium", undefined, undefined],
+      ["delegate_selectors", "medium", "deploy", undefined],
+      ["missing_failure_strategy", "low", "deploy", undefined],
+    ]);
+    expect(result.extra_stages).toEqual([{ identifier: "smoke", name: "Smoke", type: "Custom" }]);
+    // 1 pipeline selector + 3 stages + build's 2 steps + deploy's selector, failure strategy, and 2 steps
+    expect(result.checks).toEqual({ total: 10, passed: 5 });
+  });
+
+  it("compares against a scoped pipeline template", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => opts.path.startsWith("/template/")
+      ? { data: { versionLabel: "v3", yaml: `template:\n  identifier: golden_cd\n  type: Pipeline\n  spec:${GOLDEN_BODY.replace(/\n/g, "\n  ")}` } }
+      : { data: { yamlPipeline: GOLDEN.replace("identifier: golden", "identifier: copy") } });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_compliance", "get", {
+      pipeline_id: "copy",
+      golden_template_id: "org.golden_cd",
+    }) as Record<string, any>;
+
+    const templateCall = request.mock.calls[1]![0];
+    expect(templateCall.path).toBe("/template/api/templates/golden_cd");
+    expect(templateCall.params).toEqual({ orgIdentifier: "default" });
+    expect(result).toMatchObject({ verdict: "compliant", gaps: [], golden: { kind: "template", id: "org.golden_cd", version: "v3" } });
+  });
+
+  it("treats a pipeline built from the golden template as compliant", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => opts.path.startsWith("/template/")
+      ? { data: { yaml: `template:\n  type: Pipeline\n  spec:\n    stages: []` } }
+      : { data: { yamlPipeline: "pipeline:\n  identifier: svc\n  template:\n    templateRef: account.golden_cd\n    versionLabel: v3\n" } });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_compliance", "get", {
+      pipeline_id: "svc",
+      golden_template_id: "account.golden_cd",
+    }) as Record<string, any>;
+
+    expect(request.mock.calls[1]![0].params).toEqual({});
+    expect(result.verdict).toBe("compliant");
+    expect(result.note).toContain("built from the golden template");
+  });
+
+  it("requires exactly one golden reference", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(vi.fn());
+    await expect(registry.dispatch(client, "pipeline_compliance", "get", { pipeline_id: "p" })).rejects.toThrow(/exactly one/);
+    await expect(registry.dispatch(client, "pipeline_compliance", "get", { pipeline_id: "p", golden_pipeline_id: "g", golden_template_id: "t" }))
+      .rejects.toThrow(/exactly one/);
+  });
+});
@@ -0,0 +1,161 @@
+/**
+ * Tests for pipeline_config_inventory: environment variables, secrets,
+ * connectors, and variables used by a pipeline and its templates.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const PIPELINE = `
+pipeline:
+  identifier: deploy
+  name: Deploy
+  variables:
+    - { name: DB_PASSWORD, type: Secret, value: account.db_password }
+  stages:
+    - stage:
+        identifier: build
+        name: Build
+        type: CI
+        spec:
+          execution:
+            steps:
+              - step:
+                  identifier: test
+                  name: Test
+                  type: Run
+                  spec:
+                    connectorRef: org.dockerhub
+                    envVariables:
+                      LOG_LEVEL: debug
+                      API_TOKEN: <+secrets.getValue("api_token")>
+              - step:
+                  identifier: publish
+                  name: Publish
+                  template:
+                    templateRef: org.publish
+                    versionLabel: v2
+    - stage:
+        identifier: prod
+        name: Prod
+        template:
+          templateRef: missing_stage
+`;
+
+const PUBLISH_TEMPLATE = `
+template:
+  identifier: publish
+  versionLabel: v2
+  type: Step
+  spec:
+    type: Run
+    spec:
+      connectorRef: org.dockerhub
+      envVariables:
+        LOG_LEVEL: info
+        REGION: <+variable.org.region>
+      template:
+        templateRef: account.notify
+`;
+
+const NOTIFY_TEMPLATE = `
+template:
+  identifier: notify
+  type: Step
+  spec:
+    type: ShellScript
+    spec:
+      environmentVariables:
+        - { name: WEBHOOK, type: String, value: <+input> }
+`;
+
+describe("pipeline_config_inventory get", () => {
+  it("consolidates configuration from the pipeline and its nested templates", async () => {
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/pipeline/api/pipelines/deploy") return { data: { yamlPipeline: PIPELINE } };
+      if (opts.path === "/template/api/templates/publish") return { data: { yaml: PUBLISH_TEMPLATE } };
+      if (opts.path === "/template/api/templates/notify") return { data: { yaml: NOTIFY_TEMPLATE } };
+      throw new Error("Template not found");
+    });
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_config_inventory", "get", { pipeline_id: "deploy" }) as Record<string, any>;
+
+    const templateCall = request.mock.calls.find(([opts]) => opts.path === "/template/api/templates/publish")![0];
+    expect(templateCall.params).toEqual({ orgIdentifier: "default", versionLabel: "v2" });
+    expect(result.templates).toEqual([
+      { ref: "org.publish", version: "v2" },
+      { ref: "missing_stage", error: "Template not found" },
+      { ref: "account.notify" },
+    ]);
+    expect(result.env_vars.map((v: Record<string, unknown>) => v.name)).toEqual(["API_TOKEN", "DB_PASSWORD", "LOG_LEVEL", "REGION", "WEBHOOK"]);
+    expect(result.env_vars.find((v: Record<string, unknown>) => v.name === "LOG_LEVEL")).toEqual({
+      name: "LOG_LEVEL",
+      declared_as: ["envVariables"],
+      values: ["debug", "info"],
+      locations: [
+        "pipeline: pipeline.stages.build.spec.execution.steps.test.spec.envVariables.LOG_LEVEL",
+        "template org.publish@v2: template.spec.spec.envVariables.LOG_LEVEL",
+      ],
+    });
+    expect(result.env_vars.find((v: Record<string, unknown>) => v.name === "DB_PASSWORD")).toMatchObject({ secret: true, declared_as: ["variables"] });
+    expect(result.env_vars.find((v: Record<string, unknown>) => v.name === "WEBHOOK")).toMatchObject({ runtime_input: true, values: [] });
+    expect(result.secrets).toEqual([
+      { ref: "account.db_password", scope: "account", locations: ["pipeline: pipeline.variables.DB_PASSWORD"] },
+      { ref: "api_token", scope: "project", locations: ["pipeline: pipeline.stages.build.spec.execution.steps.test.spec.envVariables.API_TOKEN"] },
+    ]);
+    expect(result.connectors).toEqual([{
+      ref: "org.dockerhub",
+      scope: "org",
+      locations: [
+        "pipeline: pipeline.stages.build.spec.execution.steps.test.spec.connectorRef",
+        "template org.publish@v2: template.spec.spec.connectorRef",
+      ],
+    }]);
+    expect(result.variables).toEqual([{ ref: "org.region", scope: "org", locations: ["template org.publish@v2: template.spec.spec.envVariables.REGION"] }]);
+    expect(result.totals).toEqual({ env_vars: 5, secrets: 2, connectors: 1, variables: 1, templates: 3 });
+  });
+
+  it("scans only the pipeline when include_templates is false", async () => {
+    const request = vi.fn(async () => ({ data: { yamlPipeline: PIPELINE } }));
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_config_inventory", "get", {
+      pipeline_id: "deploy",
+      include_templates: false,
+    }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(result.templates).toEqual([]);
+    expect(result.connectors).toHaveLength(1);
+  });
+});
@@ -0,0 +1,167 @@
+/**
+ * Tests for pipeline_health: success rate, durations, failure trend, and
+ * flakiness aggregated from a pipeline's recent executions.
+ */
+import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { pipelineHealthExtract, type PipelineHealthScan } from "../../src/registry/extractors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const NOW = Date.parse("2025-07-15T00:00:00Z");
+const DAY = 24 * 60 * 60 * 1000;
+
+function execution(id: string, status: string, daysAgo: number, durationMs = 60_000, message?: string): Record<string, unknown> {
+  const startTs = NOW - daysAgo * DAY;
+  return {
+    planExecutionId: id,
+    status,
+    startTs,
+    ...(status === "Running" ? {} : { endTs: startTs + durationMs }),
+    ...(message ? { failureInfo: { message } } : {}),
+  };
+}
+
+function scan(executions: Array<Record<string, unknown>>, overrides: Partial<PipelineHealthScan> = {}): PipelineHealthScan {
+  return {
+    pipeline_id: "deploy",
+    window: { start: NOW - 14 * DAY, end: NOW, days: 14 },
+    bucket: "week",
+    executions,
+    total: executions.length,
+    ...overrides,
+  };
+}
+
+describe("pipelineHealthExtract", () => {
+  it("reports a pipeline that alternates outcomes as flaky", () => {
+    const runs = [
+      execution("e1", "Success", 13, 60_000),
+      execution("e2", "Failed", 12, 30_000, "Connection reset by peer"),
+      execution("e3", "Success", 10, 90_000),
+      execution("e4", "Failed", 8, 30_000, "Connection reset by peer"),
+      execution("e5", "Success", 5, 120_000),
+      execution("e6", "Aborted", 4),
+      execution("e7", "Failed", 2, 30_000, "Test suite timed out"),
+      execution("e8", "Running", 0.1),
+    ];
+    const result = pipelineHealthExtract(scan(runs)) as Record<string, any>;
+
+    expect(result.verdict).toBe("flaky");
+    expect(result.counts).toEqual({ executions: 8, succeeded: 3, failed: 3, aborted: 1, in_progress: 1 });
+    expect(result.success_rate).toBe(0.5);
+    expect(result.duration_ms).toEqual({ mean: 60_000, median: 30_000, p90: 120_000, max: 120_000 });
+    expect(result.flakiness).toEqual({ outcome_flips: 5, flip_rate: 1 });
+    expect(result.top_failures).toEqual([
+      { message: "Connection reset by peer", count: 2, last_execution_id: "e4" },
+      { message: "Test suite timed out", count: 1, last_execution_id: "e7" },
+    ]);
+    expect(result.window).toEqual({ start: "2025-07-01T00:00:00.000Z", end: "2025-07-15T00:00:00.000Z", days: 14 });
+  });
+
+  it("buckets executions by UTC week starting Monday and flags a worsening failure rate", () => {
+    const runs = [
+      ...["a", "b", "c", "d"].map((id, i) => execution(id, "Success", 13 - i)),
+      ...["e", "f", "g"].map((id, i) => execution(id, "Failed", 5 - i, 60_000, "OOMKilled")),
+      execution("h", "Success", 2),
+    ];
+    const result = pipelineHealthExtract(scan(runs)) as Record<string, any>;
+
+    expect(result.failure_trend).toBe("worsening");
+    expect(result.trend.map((b: Record<string, unknown>) => b.start)).toEqual([
+      "2025-06-30T00:00:00.000Z",
+      "2025-07-07T00:00:00.000Z",
+      "2025-07-14T00:00:00.000Z",
+    ]);
+    expect(result.trend[0]).toMatchObject({ executions: 4, succeeded: 4, failed: 0, success_rate: 1, mean_duration_ms: 60_000 });
+    expect(result.trend[1]).toMatchObject({ executions: 4, succeeded: 1, failed: 3, success_rate: 0.25 });
+    expect(result.trend[2]).toMatchObject({ executions: 0, success_rate: null, mean_duration_ms: null });
+  });
+
+  it("calls a mostly failing pipeline failing and too few runs insufficient_data", () => {
+    const failing = ["a", "b", "c", "d", "e", "f"].map((id, i) => execution(id, i === 0 ? "Success" : "Failed", 12 - i));
+    expect((pipelineHealthExtract(scan(failing)) as Record<string, unknown>).verdict).toBe("failing");
+
+    const sparse = pipelineHealthExtract(scan([execution("a", "Success", 3), execution("b", "Failed", 2)])) as Record<string, unknown>;
+    expect(sparse.verdict).toBe("insufficient_data");
+    expect(sparse.failure_trend).toBe("insufficient_data");
+    expect(pipelineHealthExtract(scan([])) as Record<string, unknown>).toMatchObject({ success_rate: null, duration_ms: null });
+  });
+
+  it("notes when the window holds more executions than were read", () => {
+    const result = pipelineHealthExtract(scan([execution("a", "Success", 1)], { total: 900 })) as Record<string, unknown>;
+    expect(result.truncated).toBe(true);
+    expect(result.note).toContain("newest 1 of 900");
+  });
+});
+
+describe("pipeline_health get", () => {
+  beforeEach(() => {
+    vi.useFakeTimers();
+    vi.setSystemTime(NOW);
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  it("pages through the window's executions for the pipeline", async () => {
+    const page = (n: number, start: number) => ({
+      data: {
+        content: Array.from({ length: n }, (_, i) => execution(`e${start + i}`, "Success", 1)),
+        totalElements: 150,
+      },
+    });
+    const request = vi.fn(async (opts: Record<string, any>) => page(opts.params.page === 0 ? 100 : 50, opts.params.page * 100));
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_health", "get", { pipeline_id: "deploy", window_days: 7, branch: "main" }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(2);
+    const first = request.mock.calls[0]![0];
+    expect(first).toMatchObject({
+      method: "POST",
+      path: "/pipeline/api/pipelines/execution/summary",
+      params: { pipelineIdentifier: "deploy", page: 0, size: 100, branch: "main" },
+      body: { filterType: "PipelineExecution", timeRange: { startTime: NOW - 7 * DAY, endTime: NOW } },
+    });
+    expect(result.counts.executions).toBe(150);
+    expect(result.bucket).toBe("day");
+    expect(result.trend).toHaveLength(8);
+    expect(result.truncated).toBeUndefined();
+  });
+
+  it("rejects an out-of-range window", async () => {
+    const registry = new Registry(makeConfig());
+    await expect(registry.dispatch(makeClient(vi.fn()), "pipeline_health", "get", { pipeline_id: "deploy", window_days: 0 }))
+      .rejects.toThrow(/window_days must be a whole number from 1 to 365/);
+  });
+});
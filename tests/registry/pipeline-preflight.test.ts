@@ -0,0 +1,164 @@
+/**
+ * Tests for pipeline.preflight: start a Harness preflight check, poll it to
+ * completion, and report failed connectors and inputs.
+ */
+import { afterEach, describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const FAILED_CHECK = {
+  status: "FAILURE",
+  connectorWrapperResponse: {
+    status: "FAILURE",
+    checkResponses: [
+      { connectorIdentifier: "github", status: "SUCCESS", stageName: "Build", stepName: "Clone" },
+      {
+        connectorIdentifier: "prod_k8s",
+        status: "FAILURE",
+        stageName: "Deploy",
+        stepName: "Rollout",
+        fqn: "pipeline.stages.deploy.spec.infrastructure.connectorRef",
+        errorInfo: {
+          summary: "Connector prod_k8s is not reachable",
+          causes: [{ cause: "No eligible delegates" }],
+          resolution: [{ resolution: "Check delegate selectors" }],
+        },
+      },
+    ],
+  },
+  pipelineInputWrapperResponse: {
+    status: "SUCCESS",
+    pipelineInputResponse: [{ success: true, fqn: "pipeline.variables.env" }],
+  },
+};
+
+afterEach(() => {
+  vi.useRealTimers();
+});
+
+describe("pipeline.preflight", () => {
+  it("starts a check with the runtime inputs and polls until it finishes", async () => {
+    vi.useFakeTimers();
+    const registry = new Registry(makeConfig());
+    let polls = 0;
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/pipeline/api/pipeline/execute/preflightCheck") return { data: "check-1" };
+      if (opts.path === "/pipeline/api/pipeline/execute/getPreflightCheckResponse") {
+        polls++;
+        return { data: polls === 1 ? { status: "IN_PROGRESS" } : FAILED_CHECK };
+      }
+      throw new Error(`unexpected path ${opts.path}`);
+    });
+
+    const pending = registry.dispatchExecute(makeClient(request), "pipeline", "preflight", {
+      pipeline_id: "deploy_app",
+      inputs: "pipeline:\n  variables:\n    - name: env\n      value: prod\n",
+      pipeline_branch: "feature/x",
+    }) as Promise<Record<string, any>>;
+    await vi.advanceTimersByTimeAsync(2000);
+    const result = await pending;
+
+    const start = request.mock.calls[0]![0];
+    expect(start.method).toBe("POST");
+    expect(start.params).toMatchObject({ pipelineIdentifier: "deploy_app", orgIdentifier: "default", projectIdentifier: "test-project", branch: "feature/x" });
+    expect(start.body).toContain("value: prod");
+    expect(request.mock.calls[1]![0].params).toMatchObject({ preflightCheckId: "check-1" });
+
+    expect(result).toMatchObject({
+      preflight_check_id: "check-1",
+      pipeline_id: "deploy_app",
+      status: "FAILURE",
+      passed: false,
+      checks: {
+        connectors: { status: "FAILURE", total: 2, failed: 1 },
+        inputs: { status: "SUCCESS", total: 1, failed: 0 },
+      },
+    });
+    expect(result.failures).toEqual([{
+      check: "connector",
+      stage: "Deploy",
+      step: "Rollout",
+      fqn: "pipeline.stages.deploy.spec.infrastructure.connectorRef",
+      connector_id: "prod_k8s",
+      summary: "Connector prod_k8s is not reachable",
+      causes: ["No eligible delegates"],
+      resolution: ["Check delegate selectors"],
+    }]);
+    expect(result._hint).toBeUndefined();
+  });
+
+  it("returns IN_PROGRESS with a hint when wait_ms runs out, and reads an existing check by ID", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/pipeline/api/pipeline/execute/preflightCheck") return { data: "check-2" };
+      return { data: { status: "IN_PROGRESS" } };
+    });
+
+    const first = await registry.dispatchExecute(makeClient(request), "pipeline", "preflight", { pipeline_id: "deploy_app", wait_ms: 0 }) as Record<string, any>;
+    expect(first).toMatchObject({ status: "IN_PROGRESS", passed: false });
+    expect(first._hint).toContain("preflight_check_id: 'check-2'");
+    expect(request.mock.calls[0]![0].body).toBe("");
+
+    request.mockClear();
+    await registry.dispatchExecute(makeClient(request), "pipeline", "preflight", { preflight_check_id: "check-2", wait_ms: 0 });
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(request.mock.calls[0]![0].path).toBe("/pipeline/api/pipeline/execute/getPreflightCheckResponse");
+  });
+
+  it("reports failed runtime inputs", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/pipeline/api/pipeline/execute/preflightCheck") return { data: "check-3" };
+      return {
+        data: {
+          status: "FAILURE",
+          pipelineInputWrapperResponse: {
+            status: "FAILURE",
+            pipelineInputResponse: [{ success: false, fqn: "pipeline.variables.replicas", stageName: "Deploy", errorInfo: { summary: "replicas must be a number" } }],
+          },
+        },
+      };
+    });
+
+    const result = await registry.dispatchExecute(makeClient(request), "pipeline", "preflight", { pipeline_id: "deploy_app" }) as Record<string, any>;
+    expect(result.checks.inputs).toEqual({ status: "FAILURE", total: 1, failed: 1 });
+    expect(result.failures).toEqual([{ check: "input", stage: "Deploy", step: null, fqn: "pipeline.variables.replicas", summary: "replicas must be a number", causes: [], resolution: [] }]);
+  });
+
+  it("requires pipeline_id to start a check", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({}));
+    await expect(registry.dispatchExecute(makeClient(request), "pipeline", "preflight", {}))
+      .rejects.toThrow(/pipeline_id is required/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,168 @@
+/**
+ * Tests for pipeline_stage_contract: each stage's inputs and outputs as
+ * expressions a later stage can use.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const PIPELINE = `
+pipeline:
+  identifier: release
+  name: Release
+  variables:
+    - { name: env, type: String, value: <+input> }
+  stages:
+    - stage:
+        identifier: build
+        name: Build
+        type: CI
+        variables:
+          - { name: version, type: String, value: "1.<+pipeline.sequenceId>" }
+        spec:
+          execution:
+            steps:
+              - step:
+                  identifier: test
+                  name: Test
+                  type: Run
+                  spec:
+                    command: <+input>
+                    outputVariables:
+                      - name: coverage
+              - stepGroup:
+                  identifier: publish
+                  steps:
+                    - step:
+                        identifier: push
+                        name: Push
+                        type: BuildAndPushDockerRegistry
+                        spec:
+                          repo: acme/checkout
+                          tags: [<+pipeline.sequenceId>]
+    - stage:
+        identifier: deploy
+        name: Deploy
+        type: Deployment
+        spec:
+          service:
+            serviceRef: checkout
+          environment:
+            environmentRef: <+input>
+          execution:
+            steps:
+              - step:
+                  identifier: gate
+                  name: Gate
+                  type: ShellScript
+                  spec:
+                    source:
+                      spec:
+                        script: echo <+pipeline.stages.build.spec.execution.steps.test.output.outputVariables.coverage>
+                    outputVariables:
+                      - { name: approved, type: String, value: ok }
+              - step:
+                  identifier: notify
+                  name: Notify
+                  type: ShellScript
+                  spec:
+                    source:
+                      spec:
+                        script: echo <+pipeline.stages.verify.variables.result>
+`;
+
+describe("pipeline_stage_contract get", () => {
+  it("lists each stage's inputs and outputs as fully qualified expressions", async () => {
+    const request = vi.fn(async () => ({ data: { yamlPipeline: PIPELINE } }));
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_stage_contract", "get", {
+      pipeline_id: "release",
+      branch: "main",
+    }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ path: "/pipeline/api/pipelines/release", params: { branch: "main" } });
+    expect(result.variables).toEqual([
+      { name: "env", type: "String", runtime_input: true, expression: "<+pipeline.variables.env>" },
+    ]);
+
+    const [build, deploy] = result.stages;
+    expect(build).toMatchObject({
+      identifier: "build",
+      depends_on: [],
+      inputs: { runtime_inputs: ["spec.execution.steps.test.spec.command"] },
+      outputs: {
+        variables: [{ name: "version", expression: "<+pipeline.stages.build.variables.version>" }],
+        step_outputs: [{
+          step: "test",
+          name: "coverage",
+          expression: "<+pipeline.stages.build.spec.execution.steps.test.output.outputVariables.coverage>",
+        }],
+        artifacts: [{
+          step: "push",
+          type: "BuildAndPushDockerRegistry",
+          repo: "acme/checkout",
+          expression: "<+pipeline.stages.build.spec.execution.steps.publish.steps.push.artifact_push.stepArtifacts.publishedImageArtifacts[0].imageName>",
+        }],
+      },
+    });
+    expect(deploy).toMatchObject({
+      identifier: "deploy",
+      depends_on: ["build", "verify"],
+      inputs: {
+        runtime_inputs: ["spec.environment.environmentRef"],
+        service: "checkout",
+        references: [
+          { stage: "build", expression: "<+pipeline.stages.build.spec.execution.steps.test.output.outputVariables.coverage>" },
+          { stage: "verify", expression: "<+pipeline.stages.verify.variables.result>" },
+        ],
+      },
+      outputs: {
+        step_outputs: [{ step: "gate", name: "approved", expression: "<+pipeline.stages.deploy.spec.execution.steps.gate.output.outputVariables.approved>" }],
+      },
+    });
+    expect(deploy.inputs.environment).toBeUndefined();
+    expect(result.warnings).toEqual(['Stage "deploy" references stage "verify", which is not in the pipeline.']);
+  });
+
+  it("points at the template when the pipeline is built from one", async () => {
+    const request = vi.fn(async () => ({
+      data: { yamlPipeline: "pipeline:\n  identifier: release\n  template:\n    templateRef: org.golden_cd\n" },
+    }));
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "pipeline_stage_contract", "get", { pipeline_id: "release" }) as Record<string, any>;
+
+    expect(result.stages).toEqual([]);
+    expect(result.note).toContain("org.golden_cd");
+  });
+});
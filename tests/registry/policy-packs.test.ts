@@ -0,0 +1,150 @@
+/**
+ * Tests for policy_pack: browsing the built-in OPA policy pack catalog and
+ * installing a pack as policies plus one policy set.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { POLICY_PACKS } from "../../src/data/policy-packs.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "governance",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+describe("policy pack catalog", () => {
+  it("has unique pack and policy identifiers and Rego with a package and deny rule", () => {
+    const packIds = POLICY_PACKS.map((p) => p.id);
+    expect(new Set(packIds).size).toBe(packIds.length);
+    const policyIds = POLICY_PACKS.flatMap((p) => p.policies.map((policy) => policy.identifier));
+    expect(new Set(policyIds).size).toBe(policyIds.length);
+    for (const policy of POLICY_PACKS.flatMap((p) => p.policies)) {
+      expect(policy.rego).toMatch(/^package \w+/);
+      expect(policy.rego).toContain("deny[msg]");
+    }
+    expect(new Set(POLICY_PACKS.map((p) => p.category))).toEqual(new Set(["security", "cost", "quality"]));
+  });
+
+  it("lists packs by category without Rego and previews Rego on get, without calling Harness", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn();
+
+    const list = await registry.dispatch(makeClient(request), "policy_pack", "list", { category: "cost" }) as Record<string, any>;
+    expect(list.items.map((p: any) => p.pack_id)).toEqual(["ci_cost_controls"]);
+    expect(list.items[0].policies[0]).not.toHaveProperty("rego");
+
+    const pack = await registry.dispatch(makeClient(request), "policy_pack", "get", { pack_id: "ci_cost_controls" }) as Record<string, any>;
+    expect(pack.policies[0].rego).toContain("package pipeline");
+    expect(request).not.toHaveBeenCalled();
+
+    await expect(registry.dispatch(makeClient(request), "policy_pack", "get", { pack_id: "nope" }))
+      .rejects.toThrow(/Unknown policy pack "nope"/);
+  });
+});
+
+describe("policy_pack install", () => {
+  it("creates the selected policies and a policy set with the chosen severities", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({}));
+
+    const result = await registry.dispatchExecute(makeClient(request), "policy_pack", "install", {
+      pack_id: "pipeline_security_baseline",
+      body: { severity: "error", severities: { no_plaintext_secret_variables: "warning" } },
+    }) as Record<string, any>;
+
+    const calls = request.mock.calls.map(([opts]: any[]) => opts);
+    expect(calls.map((c) => `${c.method} ${c.path}`)).toEqual([
+      "POST /pm/api/v1/policies",
+      "POST /pm/api/v1/policies",
+      "POST /pm/api/v1/policysets",
+    ]);
+    expect(calls[0].body).toMatchObject({ identifier: "require_approval_before_prod", rego: expect.stringContaining("package pipeline") });
+    expect(calls[2].body).toMatchObject({
+      identifier: "pipeline_security_baseline",
+      type: "pipeline",
+      action: "onrun",
+      enabled: true,
+      policies: [
+        { identifier: "require_approval_before_prod", severity: "error" },
+        { identifier: "no_plaintext_secret_variables", severity: "warning" },
+      ],
+    });
+    expect(result).toMatchObject({ installed: true, policy_set: { status: "created" } });
+    expect(result.policies.map((p: any) => p.status)).toEqual(["created", "created"]);
+  });
+
+  it("installs at account scope without org or project", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({}));
+
+    await registry.dispatchExecute(makeClient(request), "policy_pack", "install", {
+      pack_id: "ci_cost_controls",
+      resource_scope: "account",
+      body: { policies: ["require_step_timeouts"] },
+    });
+
+    expect(request).toHaveBeenCalledTimes(2);
+    for (const [opts] of request.mock.calls as any[]) {
+      expect(opts.params.orgIdentifier).toBeUndefined();
+      expect(opts.params.projectIdentifier).toBeUndefined();
+    }
+  });
+
+  it("leaves existing policies alone unless overwrite is set", async () => {
+    const registry = new Registry(makeConfig());
+    const exists = () => { throw new HarnessApiError("policy already exists", 409); };
+    const request = vi.fn(async (opts: Record<string, any>) => (opts.method === "POST" ? exists() : {}));
+
+    const kept = await registry.dispatchExecute(makeClient(request), "policy_pack", "install", { pack_id: "pipeline_quality_gates" }) as Record<string, any>;
+    expect(kept.policies.map((p: any) => p.status)).toEqual(["exists", "exists"]);
+    expect(kept).toMatchObject({ installed: false, policy_set: { status: "exists" } });
+    expect(kept._hint).toContain("overwrite=true");
+    expect(request.mock.calls.some(([opts]: any[]) => opts.method === "PATCH")).toBe(false);
+
+    request.mockClear();
+    const replaced = await registry.dispatchExecute(makeClient(request), "policy_pack", "install", { pack_id: "pipeline_quality_gates", body: { overwrite: true } }) as Record<string, any>;
+    expect(replaced.policies.map((p: any) => p.status)).toEqual(["updated", "updated"]);
+    expect(replaced).toMatchObject({ installed: true, policy_set: { status: "updated" } });
+    const patches = request.mock.calls.filter(([opts]: any[]) => opts.method === "PATCH").map(([opts]: any[]) => opts.path);
+    expect(patches).toEqual([
+      "/pm/api/v1/policies/require_ci_tests",
+      "/pm/api/v1/policies/require_rollback_steps",
+      "/pm/api/v1/policysets/pipeline_quality_gates",
+    ]);
+  });
+
+  it("rejects unknown severities and policies before writing anything", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({}));
+    await expect(registry.dispatchExecute(makeClient(request), "policy_pack", "install", { pack_id: "ci_cost_controls", body: { severity: "block" } }))
+      .rejects.toThrow(/severity must be one of: warning, error/);
+    await expect(registry.dispatchExecute(makeClient(request), "policy_pack", "install", { pack_id: "ci_cost_controls", body: { policies: ["require_ci_tests"] } }))
+      .rejects.toThrow(/Policies not in pack ci_cost_controls: require_ci_tests/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
@@ -168,6 +168,62 @@ describe("pull_request registry mappings", () => {
     expect(mockRequest).not.toHaveBeenCalled();
   });
 
+  it("creates a draft PR with reviewers, normalizing aliases", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({ number: 7, is_draft: true });
+    const client = makeClient(mockRequest);
+
+    await registry.dispatch(client, "pull_request", "create", {
+      repo_id: "infra-config",
+      body: {
+        title: "chore: generated pipeline config",
+        source_branch: "agent/config",
+        target_branch: "main",
+        description: "Generated by the config agent.",
+        draft: "true",
+        reviewers: "12, 40",
+      },
+    });
+
+    const opts = mockRequest.mock.calls[0]![0] as Record<string, any>;
+    expect(opts).toMatchObject({ method: "POST", path: "/code/api/v1/repos/infra-config/pullreq" });
+    expect(opts.body).toMatchObject({
+      title: "chore: generated pipeline config",
+      source_branch: "agent/config",
+      target_branch: "main",
+      is_draft: true,
+      reviewer_ids: [12, 40],
+    });
+    expect(opts.body).not.toHaveProperty("draft");
+    expect(opts.body).not.toHaveProperty("reviewers");
+  });
+
+  it("rejects a create without branches, with equal branches, or with non-numeric reviewers", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({});
+    const client = makeClient(mockRequest);
+    const create = (body: Record<string, unknown>) => registry.dispatch(client, "pull_request", "create", { repo_id: "r", body });
+
+    await expect(create({ title: "t", target_branch: "main" })).rejects.toThrow(/"source_branch"/);
+    await expect(create({ title: "t", source_branch: "main", target_branch: "main" })).rejects.toThrow(/two different branches/);
+    await expect(create({ title: "t", source_branch: "a", target_branch: "main", reviewer_ids: ["jane@example.com"] })).rejects.toThrow(/principal IDs/);
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
+
+  it("marks a draft ready for review through the state endpoint", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({ number: 7, is_draft: false });
+    const client = makeClient(mockRequest);
+
+    await registry.dispatchExecute(client, "pull_request", "mark_ready", { repo_id: "infra-config", pr_number: "7" });
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "POST",
+      path: "/code/api/v1/repos/infra-config/pullreq/7/state",
+      body: { state: "open", is_draft: false },
+    }));
+  });
+
   it("requires repo_id for create instead of accepting repo_identifier", async () => {
     const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
     const mockRequest = vi.fn().mockResolvedValue({ data: { number: 1 } });
@@ -313,3 +369,218 @@ describe("pr_comment bodyBuilder translation", () => {
     }));
   });
 });
+
+describe("pr_comment ranges and replies", () => {
+  it("anchors a multi-line comment with line_end and passes parent_id through", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({ data: { id: 4 } });
+
+    await registry.dispatch(makeClient(mockRequest), "pr_comment", "create", {
+      repo_id: "my_repo",
+      pr_number: "5",
+      body: { text: "this block", path: "main.ts", line_new: 8, line_end: 12, parent_id: 3 },
+    });
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      body: { text: "this block", path: "main.ts", parent_id: 3, line_start: 8, line_end: 12, line_start_new: true, line_end_new: true },
+    }));
+  });
+
+  it("rejects line anchors without a file path or with a backwards range", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const client = makeClient(vi.fn());
+
+    await expect(registry.dispatch(client, "pr_comment", "create", {
+      repo_id: "my_repo", pr_number: "5", body: { text: "x", line_new: 8 },
+    })).rejects.toThrow(/file path/);
+    await expect(registry.dispatch(client, "pr_comment", "create", {
+      repo_id: "my_repo", pr_number: "5", body: { text: "x", path: "a.ts", line_old: 8, line_end: 2 },
+    })).rejects.toThrow(/before the start line/);
+  });
+});
+
+describe("pr_reviewer approve and request_changes", () => {
+  it("approves at the PR's current source commit", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) =>
+      opts.method === "GET" ? { number: 7, source_sha: "abc123" } : { id: 11, decision: "approved" });
+
+    const result = await registry.dispatchExecute(makeClient(mockRequest), "pr_reviewer", "approve", { repo_id: "infra-config", pr_number: "7" }) as Record<string, any>;
+
+    expect(mockRequest.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/code/api/v1/repos/infra-config/pullreq/7" });
+    expect(mockRequest.mock.calls[1]![0]).toMatchObject({
+      method: "POST",
+      path: "/code/api/v1/repos/infra-config/pullreq/7/reviews",
+      body: { decision: "approved", commit_sha: "abc123" },
+    });
+    expect(result).toMatchObject({ decision: "approved", commit_sha: "abc123" });
+    expect(result.comment).toBeUndefined();
+  });
+
+  it("posts the comment before requesting changes at an explicit commit", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({ id: 12 });
+
+    const result = await registry.dispatchExecute(makeClient(mockRequest), "pr_reviewer", "request_changes", {
+      repo_id: "infra-config",
+      pr_number: "7",
+      body: { comment: "Please add tests.", commit_sha: "def456" },
+    }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledTimes(2);
+    expect(mockRequest.mock.calls[0]![0]).toMatchObject({ path: "/code/api/v1/repos/infra-config/pullreq/7/comments", body: { text: "Please add tests." } });
+    expect(mockRequest.mock.calls[1]![0]).toMatchObject({ body: { decision: "changereq", commit_sha: "def456" } });
+    expect(result).toMatchObject({ decision: "changereq", commit_sha: "def456", comment: { id: 12 } });
+  });
+
+  it("requires a comment to request changes", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn();
+
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "pr_reviewer", "request_changes", { repo_id: "r", pr_number: "1" }))
+      .rejects.toThrow(/needs a comment/);
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
+});
+
+describe("pull_request check_merge", () => {
+  it("dry-runs the merge and reports blockers", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({
+      dry_run: true,
+      conflict_files: ["src/app.ts"],
+      allowed_methods: ["merge", "squash"],
+      minimum_required_approvals_count: 2,
+      requires_comment_resolution: true,
+      rule_violations: [
+        { rule: { identifier: "main-protection", type: "branch" }, bypassable: false, bypassed: false, violations: [{ code: "pullreq.approvals.require_minimum_count", message: "Insufficient number of approvals" }] },
+        { rule: { identifier: "lint", type: "branch" }, bypassable: true, bypassed: true, violations: [{ message: "Status check lint failed" }] },
+      ],
+    });
+
+    const result = await registry.dispatchExecute(makeClient(mockRequest), "pull_request", "check_merge", {
+      repo_id: "rc_tools",
+      pr_number: "42",
+      body: { method: "rebase" },
+    }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "POST",
+      path: "/code/api/v1/repos/rc_tools/pullreq/42/merge",
+      body: { method: "rebase", dry_run: true, dry_run_rules: true },
+    }));
+    expect(result).toMatchObject({
+      dry_run: true,
+      mergeable: false,
+      method: "rebase",
+      conflict_files: ["src/app.ts"],
+      requirements: { minimum_approvals: 2, comment_resolution: true },
+    });
+    expect(result.blockers).toEqual([
+      "Merge conflicts in 1 file(s): src/app.ts",
+      "Rule main-protection: Insufficient number of approvals",
+      'Merge method "rebase" is not allowed here; allowed: merge, squash',
+    ]);
+  });
+
+  it("reports a clean dry run as mergeable", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn().mockResolvedValue({ dry_run: true, allowed_methods: ["squash"], rule_violations: [] });
+
+    const result = await registry.dispatchExecute(makeClient(mockRequest), "pull_request", "check_merge", {
+      repo_id: "rc_tools", pr_number: "42", method: "squash",
+    }) as Record<string, any>;
+
+    expect(result).toMatchObject({ mergeable: true, blockers: [], conflict_files: [] });
+  });
+
+  it("rejects an unknown merge method before calling the API", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn();
+
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "pull_request", "merge", {
+      repo_id: "rc_tools", pr_number: "42", body: { method: "octopus" },
+    })).rejects.toThrow(/Unknown merge method/);
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
+});
+
+describe("pr_check get", () => {
+  const RULES = [
+    {
+      identifier: "main-protection",
+      type: "branch",
+      state: "active",
+      pattern: { default: true },
+      definition: { pullreq: { status_checks: { require_identifiers: ["build", "unit-tests", "sast"] }, approvals: { require_minimum_count: 2 } } },
+    },
+    {
+      identifier: "release-only",
+      type: "branch",
+      state: "active",
+      pattern: { include: ["release/**"] },
+      definition: { pullreq: { status_checks: { require_identifiers: ["e2e"] } } },
+    },
+    {
+      identifier: "lint-trial",
+      type: "branch",
+      state: "monitor",
+      pattern: {},
+      definition: { pullreq: { status_checks: { require_identifiers: ["lint"] } } },
+    },
+  ];
+
+  function respond(opts: Record<string, any>): unknown {
+    if (opts.path.endsWith("/pullreq/42")) return { number: 42, target_branch: "main", source_sha: "abc123" };
+    if (opts.path.endsWith("/pullreq/42/checks")) {
+      return {
+        commit_sha: "abc123",
+        checks: [
+          { required: true, check: { identifier: "build", status: "success" } },
+          { required: true, check: { identifier: "unit-tests", status: "failure", summary: "3 failed", link: "https://ci/run/1" } },
+          { required: false, check: { identifier: "lint", status: "failure" } },
+          { required: false, check: { identifier: "coverage", status: "running" } },
+        ],
+      };
+    }
+    if (opts.path.endsWith("/rules")) return RULES;
+    return { identifier: "rc_tools", default_branch: "main" };
+  }
+
+  it("explains which required checks block the merge and which rules require them", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) => respond(opts));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "pr_check", "get", { repo_id: "rc_tools", pr_number: "42" }) as Record<string, any>;
+
+    const rulesCall = mockRequest.mock.calls.find(([opts]) => opts.path.endsWith("/rules"))![0];
+    expect(rulesCall).toMatchObject({ path: "/code/api/v1/repos/rc_tools/rules", params: { type: "branch", inherited: true } });
+    expect(result).toMatchObject({ target_branch: "main", source_sha: "abc123", checks_pass: false, optional_failures: ["lint"] });
+    expect(result.required_checks).toEqual([
+      { identifier: "build", state: "passed", rules: ["main-protection"] },
+      { identifier: "unit-tests", state: "failed", rules: ["main-protection"] },
+      { identifier: "sast", state: "missing", rules: ["main-protection"] },
+    ]);
+    expect(result.blockers).toEqual([
+      'Required check "unit-tests" is failure (required by main-protection).',
+      'Required check "sast" has not reported on the latest commit (required by main-protection).',
+    ]);
+    expect(result.rules.map((r: Record<string, unknown>) => r.identifier)).toEqual(["main-protection", "lint-trial"]);
+    expect(result.rules[0].requirements).toMatchObject({ minimum_approvals: 2 });
+    expect(result.notes).toEqual(["Rules in monitor mode do not block: lint-trial."]);
+  });
+
+  it("still reports checks when the rules cannot be read", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pull-requests" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/rules")) throw new Error("403 Forbidden");
+      return respond(opts);
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "pr_check", "get", { repo_id: "rc_tools", pr_number: "42" }) as Record<string, any>;
+
+    expect(result.errors).toEqual(["rules: 403 Forbidden"]);
+    expect(result.required_checks.map((c: Record<string, unknown>) => [c.identifier, c.state])).toEqual([["build", "passed"], ["unit-tests", "failed"]]);
+    expect(result.checks).toHaveLength(4);
+  });
+});
@@ -4,6 +4,8 @@ import type { Config } from "../../src/config.js";
 import type { HarnessClient } from "../../src/client/harness-client.js";
 import { HarnessApiError } from "../../src/utils/errors.js";
 import { registerAllTools } from "../../src/tools/index.js";
+import { getDeprecatedUsage, resetDeprecatedUsage } from "../../src/utils/deprecation-tracker.js";
+import { runInConversation } from "../../src/utils/conversation-context.js";
 
 function makeConfig(overrides: Partial<Config> = {}): Config {
   return {
@@ -83,6 +85,25 @@ describe("Registry", () => {
       expect(registry.getResource("agent").toolset).toBe("agents");
     });
 
+    it("narrows enabled toolsets to session entitlements", () => {
+      const registry = new Registry(makeConfig(), { entitledToolsets: new Set(["pipelines", "agent-pipelines"]) });
+      const desc = registry.describe() as { total_toolsets: number };
+      expect(desc.total_toolsets).toBe(2);
+      expect(registry.getResource("pipeline").toolset).toBe("pipelines");
+      expect(registry.getResource("agent").toolset).toBe("agents");
+      expect(() => registry.getResource("service")).toThrow(/Unknown resource_type/);
+    });
+
+    it("entitlements cannot enable toolsets excluded by HARNESS_TOOLSETS", () => {
+      const registry = new Registry(
+        makeConfig({ HARNESS_TOOLSETS: "pipelines,services" }),
+        { entitledToolsets: new Set(["services", "ansible"]) },
+      );
+      const desc = registry.describe() as { total_toolsets: number };
+      expect(desc.total_toolsets).toBe(1);
+      expect(registry.getResource("service").toolset).toBe("services");
+    });
+
     it("includes ai-evals in defaults", () => {
       const registry = new Registry(makeConfig());
       const res = registry.getResource("eval_dataset");
@@ -1289,6 +1310,90 @@ describe("Registry", () => {
     });
   });
 
+  describe("scope guard", () => {
+    const listResponse = { data: { content: [], totalElements: 0 } };
+
+    it("warns by default when a request leaves the pinned project", async () => {
+      const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines" }));
+      const mockRequest = vi.fn().mockResolvedValue(listResponse);
+      const result = await registry.dispatch(makeClient(mockRequest), "pipeline", "list", { project_id: "other-project" }) as Record<string, unknown>;
+      expect(mockRequest).toHaveBeenCalledOnce();
+      expect(result._scopeWarning).toContain('project_id "other-project"');
+    });
+
+    it("blocks cross-scope requests in block mode", async () => {
+      const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines", HARNESS_SCOPE_GUARD: "block" }));
+      const mockRequest = vi.fn().mockResolvedValue(listResponse);
+      await expect(
+        registry.dispatch(makeClient(mockRequest), "pipeline", "list", { org_id: "other-org" }),
+      ).rejects.toThrow(/Cross-scope request blocked.*org_id "other-org"/);
+      expect(mockRequest).not.toHaveBeenCalled();
+    });
+
+    it("blocks cross-scope execute actions in block mode", async () => {
+      const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines", HARNESS_SCOPE_GUARD: "block" }));
+      await expect(
+        registry.dispatchExecute(makeClient(), "execution", "interrupt", { execution_id: "e1", interrupt_type: "AbortAll", project_id: "other" }),
+      ).rejects.toThrow(/cross_scope: true/);
+    });
+
+    it("allows explicit cross_scope requests without a warning", async () => {
+      const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines", HARNESS_SCOPE_GUARD: "block" }));
+      const mockRequest = vi.fn().mockResolvedValue(listResponse);
+      const result = await registry.dispatch(makeClient(mockRequest), "pipeline", "list", { project_id: "other", cross_scope: true }) as Record<string, unknown>;
+      expect(mockRequest).toHaveBeenCalledOnce();
+      expect(result._scopeWarning).toBeUndefined();
+    });
+
+    it("ignores matching scope and off mode", async () => {
+      const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines", HARNESS_SCOPE_GUARD: "off" }));
+      const result = await registry.dispatch(makeClient(vi.fn().mockResolvedValue(listResponse)), "pipeline", "list", { project_id: "other" }) as Record<string, unknown>;
+      expect(result._scopeWarning).toBeUndefined();
+
+      const defaultRegistry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines" }));
+      const same = await defaultRegistry.dispatch(makeClient(vi.fn().mockResolvedValue(listResponse)), "pipeline", "list", { org_id: "default", project_id: "test-project" }) as Record<string, unknown>;
+      expect(same._scopeWarning).toBeUndefined();
+    });
+
+    it("pins every conversation to the configured scope", async () => {
+      const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "pipelines", HARNESS_SCOPE_GUARD: "block" }));
+      const mockRequest = vi.fn().mockResolvedValue(listResponse);
+      const client = makeClient(mockRequest);
+
+      await runInConversation("chat-1", () => registry.dispatch(client, "pipeline", "list", { project_id: "other", cross_scope: true }));
+
+      await expect(
+        runInConversation("chat-2", () => registry.dispatch(client, "pipeline", "list", { project_id: "other" })),
+      ).rejects.toThrow(/Cross-scope request blocked/);
+      await runInConversation("chat-2", () => registry.dispatch(client, "pipeline", "list", { project_id: "test-project" }));
+      expect(mockRequest.mock.calls.map((c) => c[0].params.projectIdentifier)).toEqual(["other", "test-project"]);
+    });
+  });
+
+  describe("deprecated names", () => {
+    beforeEach(() => resetDeprecatedUsage());
+
+    it("counts renamed toolset names from HARNESS_TOOLSETS", () => {
+      new Registry(makeConfig({ HARNESS_TOOLSETS: "agent-pipelines" }));
+      expect(getDeprecatedUsage()).toEqual([
+        expect.objectContaining({ kind: "toolset", name: "agent-pipelines", replacement: "agents", client: "HARNESS_TOOLSETS" }),
+      ]);
+    });
+
+    it("counts renamed toolset names from a bearer token's toolsets claim", () => {
+      const registry = new Registry(makeConfig(), { entitledToolsets: new Set(["agent-pipelines"]) });
+      expect(registry.getAllResourceTypes()).toContain("agent");
+      expect(getDeprecatedUsage()).toEqual([
+        expect.objectContaining({ kind: "toolset", name: "agent-pipelines", replacement: "agents", client: "bearer-token" }),
+      ]);
+    });
+
+    it("does not count current toolset names", () => {
+      new Registry(makeConfig({ HARNESS_TOOLSETS: "agents" }));
+      expect(getDeprecatedUsage()).toEqual([]);
+    });
+  });
+
   describe("read-only mode", () => {
     let registry: Registry;
     beforeEach(() => {
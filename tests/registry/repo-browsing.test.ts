@@ -0,0 +1,124 @@
+/**
+ * Tests for reading Harness Code repositories without cloning: decoded
+ * file_content and the repo_tree directory listing.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { codeFileContentExtract, codeTreeExtract } from "../../src/registry/extractors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "repositories",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+function fileResponse(text: string | Buffer, extra: Record<string, unknown> = {}) {
+  const data = Buffer.from(text).toString("base64");
+  return {
+    type: "file",
+    sha: "blob123",
+    name: "values.yaml",
+    path: "deploy/values.yaml",
+    content: { encoding: "base64", data, size: Buffer.byteLength(text), data_size: Buffer.byteLength(text), ...extra },
+  };
+}
+
+describe("codeFileContentExtract", () => {
+  it("decodes text files to UTF-8 and keeps the blob sha", () => {
+    const result = codeFileContentExtract(fileResponse("replicas: 3\nimage: café\n")) as Record<string, any>;
+    expect(result.sha).toBe("blob123");
+    expect(result.content).toMatchObject({ encoding: "utf8", data: "replicas: 3\nimage: café\n" });
+    expect(result.content.truncated).toBeUndefined();
+  });
+
+  it("keeps binary files as base64", () => {
+    const raw = fileResponse(Buffer.from([0x89, 0x50, 0x00, 0x47]));
+    const result = codeFileContentExtract(raw) as Record<string, any>;
+    expect(result.content).toMatchObject({ encoding: "base64", data: raw.content.data, binary: true });
+  });
+
+  it("flags content the server cut short", () => {
+    const result = codeFileContentExtract(fileResponse("abc", { size: 5_000_000 })) as Record<string, any>;
+    expect(result.content).toMatchObject({ data: "abc", truncated: true });
+  });
+
+  it("passes directory listings through", () => {
+    const dir = { type: "dir", content: { entries: [{ type: "file", name: "a.yaml" }] } };
+    expect(codeFileContentExtract(dir)).toBe(dir);
+  });
+});
+
+describe("codeTreeExtract", () => {
+  const paths = {
+    directories: ["deploy", "deploy/k8s", "deploy/k8s/overlays", "src"],
+    files: ["README.md", "deploy/values.yaml", "deploy/k8s/app.yaml", "deploy/k8s/overlays/prod.yaml", "src/main.go"],
+  };
+
+  it("lists the whole tree, directories first", () => {
+    const result = codeTreeExtract(paths, {}) as Record<string, any>;
+    expect(result.path).toBe("/");
+    expect(result.total).toBe(9);
+    expect(result.items.slice(0, 2)).toEqual([{ path: "deploy", type: "dir" }, { path: "deploy/k8s", type: "dir" }]);
+    expect(result.items.at(-1)).toEqual({ path: "src/main.go", type: "file" });
+  });
+
+  it("narrows to a directory and depth", () => {
+    const result = codeTreeExtract(paths, { path: "/deploy/", depth: "1", git_ref: "main" }) as Record<string, any>;
+    expect(result).toMatchObject({ path: "deploy", git_ref: "main", total: 2 });
+    expect(result.items).toEqual([{ path: "deploy/k8s", type: "dir" }, { path: "deploy/values.yaml", type: "file" }]);
+  });
+
+  it("caps large trees and says how to narrow them", () => {
+    const files = Array.from({ length: 1200 }, (_, i) => `f${String(i).padStart(4, "0")}.txt`);
+    const result = codeTreeExtract({ files }, {}) as Record<string, any>;
+    expect(result.items).toHaveLength(1000);
+    expect(result).toMatchObject({ total: 1200, truncated: true });
+    expect(result.note).toContain("Narrow with path or depth");
+  });
+});
+
+describe("repo_tree list", () => {
+  it("requests the path list with directories at the ref and scope", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn().mockResolvedValue({ directories: ["ci"], files: ["ci/build.yaml", "go.mod"] });
+
+    const result = await registry.dispatch(makeClient(request), "repo_tree", "list", {
+      repo_id: "payments",
+      git_ref: "release",
+      path: "ci",
+      org_id: "eng",
+      project_id: "pay",
+    }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledWith(expect.objectContaining({
+      method: "GET",
+      path: "/code/api/v1/repos/payments/paths",
+      params: expect.objectContaining({ git_ref: "release", include_directories: "true", orgIdentifier: "eng", projectIdentifier: "pay" }),
+    }));
+    expect(result.items).toEqual([{ path: "ci/build.yaml", type: "file" }]);
+  });
+});
@@ -0,0 +1,117 @@
+/**
+ * Tests for repo_diff: the unified diff between two refs with per-file
+ * stats, path filtering, and truncation.
+ */
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "repositories",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+describe("repo_webhook", () => {
+
+const DIFF = [
+  "diff --git a/src/app.ts b/src/app.ts",
+  "index 1111111..2222222 100644",
+  "--- a/src/app.ts",
+  "+++ b/src/app.ts",
+  "@@ -1,3 +1,3 @@",
+  " import x from 'x';",
+  "-const port = 80;",
+  "+const port = 8080;",
+  "+const host = '0.0.0.0';",
+  "diff --git a/docs/old.md b/docs/new.md",
+  "similarity index 90%",
+  "rename from docs/old.md",
+  "rename to docs/new.md",
+  "diff --git a/logo.png b/logo.png",
+  "new file mode 100644",
+  "Binary files /dev/null and b/logo.png differ",
+  "",
+].join("\n");
+
+function diffResponse(text: string): ArrayBuffer {
+  return new TextEncoder().encode(text).buffer as ArrayBuffer;
+}
+
+describe("repo_diff get", () => {
+  it("returns per-file stats and the unified diff between two refs", async () => {
+    const request = vi.fn(async () => diffResponse(DIFF));
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(request), "repo_diff", "get", {
+      repo_id: "checkout",
+      base: "main",
+      head: "feature/login",
+    }) as Record<string, any>;
+
+    expect(request.mock.calls[0]![0]).toMatchObject({
+      path: "/code/api/v1/repos/checkout/diff/main..feature/login",
+      headers: { Accept: "text/plain" },
+      responseType: "buffer",
+    });
+    expect(result.stats).toEqual({ files: 3, additions: 2, deletions: 1 });
+    expect(result.files).toEqual([
+      { path: "src/app.ts", status: "modified", additions: 2, deletions: 1 },
+      { path: "docs/new.md", old_path: "docs/old.md", status: "renamed", additions: 0, deletions: 0 },
+      { path: "logo.png", status: "added", additions: 0, deletions: 0, binary: true },
+    ]);
+    expect(result.diff).toBe(DIFF);
+    expect(result.truncated).toBeUndefined();
+  });
+
+  it("filters by path and truncates at max_chars, listing omitted files", async () => {
+    const request = vi.fn(async () => diffResponse(DIFF));
+    const registry = new Registry(makeConfig());
+    const client = makeClient(request);
+
+    const filtered = await registry.dispatch(client, "repo_diff", "get", {
+      repo_id: "checkout", base: "main", head: "dev", path: "docs/old.md, *.png",
+    }) as Record<string, any>;
+    expect(filtered.files.map((f: Record<string, unknown>) => f.path)).toEqual(["docs/new.md", "logo.png"]);
+    expect(filtered.diff).not.toContain("src/app.ts");
+
+    const cut = await registry.dispatch(client, "repo_diff", "get", {
+      repo_id: "checkout", base: "main", head: "dev", max_chars: 150,
+    }) as Record<string, any>;
+    expect(cut.diff.length).toBeLessThanOrEqual(150);
+    expect(cut.diff.endsWith("\n")).toBe(true);
+    expect(cut).toMatchObject({ truncated: true, truncated_file: "src/app.ts", omitted_files: ["docs/new.md", "logo.png"] });
+    expect(cut.stats.files).toBe(3);
+  });
+
+  it("requires both refs", async () => {
+    const request = vi.fn();
+    const registry = new Registry(makeConfig());
+
+    await expect(registry.dispatch(makeClient(request), "repo_diff", "get", { repo_id: "checkout", base: "main" }))
+      .rejects.toThrow(/base and head are required/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,92 @@
+/**
+ * Tests for repo_webhook: Harness Code repository webhooks.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "repositories",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+describe("repo_webhook", () => {
+  it("creates a webhook with selected events and a secret", async () => {
+    const request = vi.fn(async () => ({ identifier: "ci-trigger", has_secret: true }));
+    const registry = new Registry(makeConfig());
+
+    await registry.dispatch(makeClient(request), "repo_webhook", "create", {
+      repo_id: "checkout",
+      body: {
+        identifier: "ci-trigger",
+        url: "https://app.harness.io/gateway/pipeline/api/webhook/custom/v2?accountIdentifier=acct",
+        triggers: "branch_updated, pullreq_created",
+        secret: "s3cret",
+      },
+    });
+
+    const call = request.mock.calls[0]![0] as Record<string, any>;
+    expect(call).toMatchObject({ method: "POST", path: "/code/api/v1/repos/checkout/webhooks" });
+    expect(call.body).toMatchObject({
+      identifier: "ci-trigger",
+      display_name: "ci-trigger",
+      url: "https://app.harness.io/gateway/pipeline/api/webhook/custom/v2?accountIdentifier=acct",
+      secret: "s3cret",
+      enabled: true,
+      insecure: false,
+      triggers: ["branch_updated", "pullreq_created"],
+    });
+  });
+
+  it("rejects unknown events and non-http URLs before calling the API", async () => {
+    const request = vi.fn();
+    const registry = new Registry(makeConfig());
+    const client = makeClient(request);
+
+    await expect(registry.dispatch(client, "repo_webhook", "create", {
+      repo_id: "checkout",
+      body: { identifier: "hook", url: "https://example.com/hook", triggers: ["push"] },
+    })).rejects.toThrow(/Unknown webhook trigger\(s\): push/);
+    await expect(registry.dispatch(client, "repo_webhook", "create", {
+      repo_id: "checkout",
+      body: { identifier: "hook", url: "ftp://example.com/hook" },
+    })).rejects.toThrow(/http\(s\) URL/);
+    expect(request).not.toHaveBeenCalled();
+  });
+
+  it("lists and deletes webhooks by identifier", async () => {
+    const request = vi.fn(async () => []);
+    const registry = new Registry(makeConfig());
+    const client = makeClient(request);
+
+    await registry.dispatch(client, "repo_webhook", "list", { repo_id: "checkout" });
+    await registry.dispatch(client, "repo_webhook", "delete", { repo_id: "checkout", webhook_id: "ci-trigger" });
+
+    expect(request.mock.calls[0]![0]).toMatchObject({ method: "GET", path: "/code/api/v1/repos/checkout/webhooks" });
+    expect(request.mock.calls[1]![0]).toMatchObject({ method: "DELETE", path: "/code/api/v1/repos/checkout/webhooks/ci-trigger" });
+  });
+});
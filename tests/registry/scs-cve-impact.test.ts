@@ -0,0 +1,151 @@
+/**
+ * Tests for scs_cve_impact: finding the artifacts and code repositories that
+ * ship a vulnerable package, and where those artifacts are deployed.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "scs",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const SEARCH_HITS = [
+  { name: "log4j-core", version: "2.14.1", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", artifactId: "img-api", artifactName: "api" },
+  { name: "log4j-core", version: "2.14.1", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", artifactId: "repo-billing", artifactName: "billing" },
+  { name: "log4j-core", version: "2.17.2", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.2", artifactId: "img-web", artifactName: "web" },
+  { name: "log4j-core-extras", version: "1.0.0", purl: "pkg:maven/org.example/log4j-core-extras@1.0.0", artifactId: "img-web", artifactName: "web" },
+];
+
+/** SCS stand-in: component search, per-purl vulnerabilities, code repos, and deployments. */
+function scsApi(overrides: { deployments?: (artifact: string) => unknown } = {}) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path.endsWith("/components/search")) return Number(opts.params?.page ?? 0) === 0 ? SEARCH_HITS : [];
+    if (opts.path.endsWith("/components/vulnerabilities")) {
+      return String(opts.params?.purl).endsWith("@2.14.1") ? [{ cve: "CVE-2021-44228", severity: "CRITICAL" }] : [];
+    }
+    if (opts.path.endsWith("/code-repos/list")) return [{ id: "repo-billing", name: "billing" }];
+    const deployments = /\/artifacts\/([^/]+)\/deployments$/.exec(opts.path);
+    if (deployments) {
+      if (overrides.deployments) return overrides.deployments(deployments[1]!);
+      return deployments[1] === "img-api"
+        ? [{ env_name: "qa", env_type: "PreProduction" }, { env_name: "prod-us", env_type: "Production" }]
+        : [];
+    }
+    throw new Error(`unexpected path ${opts.path}`);
+  });
+}
+
+describe("scs_cve_impact", () => {
+  it("keeps only the component versions the CVE affects and groups them by artifact", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_cve_impact", "list", {
+      cve: "cve-2021-44228", component: "log4j-core",
+    }) as Record<string, any>;
+
+    const search = request.mock.calls.find(([opts]) => opts.path.endsWith("/components/search"))![0];
+    expect(search.params).toMatchObject({ search_term: "log4j-core", limit: 100 });
+    expect(result.query).toEqual({ cve: "CVE-2021-44228", component: "log4j-core" });
+    expect(result.summary).toEqual({ artifacts: 1, code_repos: 1, in_production: 1, component_versions_checked: 2, component_versions_affected: 1 });
+    expect(result.affected).toEqual([
+      {
+        artifact_id: "img-api",
+        artifact_name: "api",
+        kind: "artifact",
+        components: [{ name: "log4j-core", version: "2.14.1", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1" }],
+        deployments: [{ env_name: "prod-us", env_type: "Production" }, { env_name: "qa", env_type: "PreProduction" }],
+        deployment_count: 2,
+        in_production: true,
+      },
+      expect.objectContaining({ artifact_id: "repo-billing", kind: "code_repo", deployment_count: 0, in_production: false }),
+    ]);
+    expect(result.errors).toBeUndefined();
+  });
+
+  it("matches a versioned purl without consulting vulnerability data", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_cve_impact", "list", {
+      purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.2",
+    }) as Record<string, any>;
+
+    expect(request.mock.calls.some(([opts]) => opts.path.endsWith("/components/vulnerabilities"))).toBe(false);
+    expect(result.affected.map((a: any) => a.artifact_id)).toEqual(["img-web"]);
+    expect(result.affected[0].components).toEqual([
+      { name: "log4j-core", version: "2.17.2", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.2" },
+    ]);
+  });
+
+  it("reports deployment lookups that fail instead of failing the whole query", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi({
+      deployments: (artifact) => {
+        if (artifact === "repo-billing") throw new Error("artifact not found");
+        return [];
+      },
+    });
+
+    const result = await registry.dispatch(makeClient(request), "scs_cve_impact", "list", {
+      purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+    }) as Record<string, any>;
+
+    expect(result.affected).toHaveLength(2);
+    expect(result.affected.find((a: any) => a.artifact_id === "repo-billing").deployments).toBeUndefined();
+    expect(result.errors).toEqual([expect.stringContaining("Deployments for repo-billing")]);
+  });
+
+  it("falls back to STO findings for a bare CVE", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs,sto" }));
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/sto/api/v2/frontend/all-issues/issues") {
+        return { issues: [{ id: "issue-1", title: "CVE-2021-44228 in log4j-core", targetName: "api" }, { id: "issue-2", title: "CVE-2022-0001" }] };
+      }
+      throw new Error(`unexpected path ${opts.path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "scs_cve_impact", "list", { cve: "CVE-2021-44228" }) as Record<string, any>;
+
+    expect(result.summary).toMatchObject({ sto_findings: 1 });
+    expect(result.sto_findings).toEqual([expect.objectContaining({ id: "issue-1" })]);
+    expect(result._hint).toContain("Pass component");
+  });
+
+  it("rejects input without a CVE or purl, and malformed CVE IDs", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    await expect(registry.dispatch(makeClient(request), "scs_cve_impact", "list", { component: "log4j-core" }))
+      .rejects.toThrow(/Pass cve/);
+    await expect(registry.dispatch(makeClient(request), "scs_cve_impact", "list", { cve: "log4shell", component: "log4j-core" }))
+      .rejects.toThrow(/CVE or GHSA ID/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
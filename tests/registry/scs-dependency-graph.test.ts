@@ -0,0 +1,112 @@
+/**
+ * Tests for scs_dependency_graph: an artifact's dependency graph assembled
+ * from its component list and per-component dependency trees.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "scs",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const ALL = [
+  { purl: "pkg:npm/express@4.18.0", package_name: "express", package_version: "4.18.0", package_license: "MIT", dependency_type: "DIRECT" },
+  { purl: "pkg:npm/body-parser@1.20.1", package_name: "body-parser", package_version: "1.20.1", dependency_type: "TRANSITIVE" },
+  { purl: "pkg:npm/qs@6.11.0", package_name: "qs", package_version: "6.11.0", vulnerability_count: 2, dependency_type: "TRANSITIVE" },
+];
+
+const TREES: Record<string, unknown[]> = {
+  "pkg:npm/express@4.18.0": [
+    { name: "body-parser", version: "1.20.1", purl: "pkg:npm/body-parser@1.20.1", relationship: "DIRECT" },
+    { name: "qs", version: "6.11.0", purl: "pkg:npm/qs@6.11.0", relationship: "INDIRECT", relationship_path: ["pkg:npm/body-parser@1.20.1"], vulnerabilities_count: 2 },
+  ],
+};
+
+/** SCS stand-in: component pages (all, or DIRECT only) and dependency trees. */
+function scsApi() {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path.endsWith("/artifacts/art-1/components")) {
+      if (Number(opts.params?.page ?? 0) > 0) return [];
+      const direct = opts.body?.dependency_type_filter?.includes("DIRECT");
+      return direct ? ALL.filter((c) => c.dependency_type === "DIRECT") : ALL;
+    }
+    if (opts.path.endsWith("/artifacts/art-1/component/dependencies")) return TREES[opts.params.purl] ?? [];
+    throw new Error(`unexpected path ${opts.path}`);
+  });
+}
+
+describe("scs_dependency_graph", () => {
+  it("returns nodes with direct and transitive edges rooted at the artifact", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_dependency_graph", "get", { artifact_id: "art-1" }) as Record<string, any>;
+
+    const treeCalls = request.mock.calls.filter(([opts]) => opts.path.endsWith("/component/dependencies"));
+    expect(treeCalls.map(([opts]) => opts.params.purl)).toEqual(["pkg:npm/express@4.18.0"]);
+    expect(result.summary).toEqual({ nodes: 3, edges: 3, direct_dependencies: 1, transitive_edges: 2, unconnected: 0, vulnerable: 1 });
+    expect(result.nodes[0]).toEqual({ id: "pkg:npm/express@4.18.0", name: "express", version: "4.18.0", license: "MIT", direct: true });
+    expect(result.edges).toEqual([
+      { from: "art-1", to: "pkg:npm/express@4.18.0", type: "direct" },
+      { from: "pkg:npm/express@4.18.0", to: "pkg:npm/body-parser@1.20.1", type: "transitive" },
+      { from: "pkg:npm/body-parser@1.20.1", to: "pkg:npm/qs@6.11.0", type: "transitive" },
+    ]);
+    expect(result.blast_radius).toBeUndefined();
+  });
+
+  it("adds the blast radius of purl", async () => {
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(scsApi()), "scs_dependency_graph", "get", {
+      artifact_id: "art-1", purl: "pkg:npm/qs",
+    }) as Record<string, any>;
+
+    expect(result.blast_radius).toEqual({
+      target: "pkg:npm/qs",
+      found: true,
+      introduced_by: ["pkg:npm/express@4.18.0"],
+      dependents: ["pkg:npm/body-parser@1.20.1", "pkg:npm/express@4.18.0"],
+      paths: [["pkg:npm/express@4.18.0", "pkg:npm/body-parser@1.20.1", "pkg:npm/qs@6.11.0"]],
+    });
+  });
+
+  it("keeps the graph when a dependency tree cannot be read", async () => {
+    const registry = new Registry(makeConfig());
+    const base = scsApi();
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/component/dependencies")) throw new Error("tree unavailable");
+      return base(opts);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "scs_dependency_graph", "get", { artifact_id: "art-1" }) as Record<string, any>;
+
+    expect(result.summary).toMatchObject({ nodes: 3, edges: 1, unconnected: 2 });
+    expect(result.errors).toEqual([expect.stringContaining("Dependency tree of pkg:npm/express@4.18.0")]);
+  });
+});
@@ -0,0 +1,116 @@
+/**
+ * Tests for scs_license_inventory: license usage across a project's artifacts
+ * and code repositories, checked against an allow-list.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "scs",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const COMPONENTS: Record<string, unknown[]> = {
+  "art-api": [
+    { purl: "pkg:npm/lodash@4.17.21", package_name: "lodash", package_version: "4.17.21", package_license: "MIT" },
+    { purl: "pkg:deb/debian/readline@8.1", package_name: "readline", package_version: "8.1", package_license: "GPL-3.0-only" },
+  ],
+  "repo-web": [
+    { purl: "pkg:npm/react@18.2.0", package_name: "react", package_version: "18.2.0", package_license: ["MIT"] },
+    { purl: "pkg:npm/left-pad@1.0.0", package_name: "left-pad", package_version: "1.0.0" },
+  ],
+};
+
+/** SCS stand-in: one source with one artifact, one code repository, and their components. */
+function scsApi() {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path.endsWith("/artifact-sources")) return [{ source_id: "src-1", name: "docker-hub" }];
+    if (opts.path.endsWith("/artifact-sources/src-1/artifacts")) return [{ id: "art-api", name: "api", tag: "1.0" }];
+    if (opts.path.endsWith("/code-repos/list")) return [{ id: "repo-web", name: "web" }];
+    const components = /\/artifacts\/([^/]+)\/components$/.exec(opts.path);
+    if (components) return Number(opts.params?.page ?? 0) === 0 ? COMPONENTS[components[1]!] ?? [] : [];
+    throw new Error(`unexpected path ${opts.path}`);
+  });
+}
+
+describe("scs_license_inventory", () => {
+  it("counts licenses across artifacts and code repositories", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_license_inventory", "list", {}) as Record<string, any>;
+
+    expect(result.summary).toEqual({ artifacts_scanned: 2, components: 4, distinct_licenses: 2, unlicensed_components: 1 });
+    expect(result.licenses).toEqual([
+      { license: "MIT", components: 2, artifacts: 2 },
+      { license: "GPL-3.0-only", components: 1, artifacts: 1 },
+    ]);
+    expect(result.violations).toBeUndefined();
+    expect(result._hint).toBeUndefined();
+  });
+
+  it("lists artifacts outside the allow-list and points at SBOM enforcement", async () => {
+    const registry = new Registry(makeConfig());
+
+    const result = await registry.dispatch(makeClient(scsApi()), "scs_license_inventory", "list", {
+      allow_list: "MIT, Apache-2.0",
+    }) as Record<string, any>;
+
+    expect(result.allow_list).toEqual(["MIT", "Apache-2.0"]);
+    expect(result.summary.artifacts_violating).toBe(1);
+    expect(result.violations).toEqual([
+      { artifact_id: "art-api", name: "api:1.0", kind: "artifact", licenses: [{ license: "GPL-3.0-only", components: 1, examples: ["readline@8.1"] }] },
+    ]);
+    expect(result._hint).toContain("sbom_enforcement");
+  });
+
+  it("narrows to one source and skips code repositories", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_license_inventory", "list", { source_id: "src-1" }) as Record<string, any>;
+
+    const paths = request.mock.calls.map(([opts]) => opts.path as string);
+    expect(paths.some((p) => p.endsWith("/artifact-sources") || p.endsWith("/code-repos/list"))).toBe(false);
+    expect(result.summary.artifacts_scanned).toBe(1);
+  });
+
+  it("keeps going when one artifact's components cannot be read", async () => {
+    const registry = new Registry(makeConfig());
+    const base = scsApi();
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/artifacts/repo-web/components")) throw new Error("boom");
+      return base(opts);
+    });
+
+    const result = await registry.dispatch(makeClient(request), "scs_license_inventory", "list", {}) as Record<string, any>;
+
+    expect(result.summary.artifacts_scanned).toBe(1);
+    expect(result.errors).toEqual([expect.stringContaining("Components of web")]);
+  });
+});
@@ -0,0 +1,106 @@
+/**
+ * Tests for scs_sbom_comparison: diffing the component inventories of two
+ * artifact versions, resolved from artifact or orchestration IDs.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "scs",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const COMPONENTS: Record<string, unknown[]> = {
+  "art-v1": [
+    { purl: "pkg:npm/lodash@4.17.20", package_name: "lodash", package_version: "4.17.20", package_license: "MIT", vulnerability_count: 2 },
+    { purl: "pkg:npm/left-pad@1.0.0", package_name: "left-pad", package_version: "1.0.0", package_license: "WTFPL", vulnerability_count: 0 },
+  ],
+  "art-v2": [
+    { purl: "pkg:npm/lodash@4.17.21", package_name: "lodash", package_version: "4.17.21", package_license: "MIT", vulnerability_count: 0 },
+    { purl: "pkg:npm/axios@1.6.0", package_name: "axios", package_version: "1.6.0", package_license: ["Apache-2.0"], vulnerability_count: 1 },
+  ],
+};
+
+/** SCS stand-in serving the artifact list and per-artifact component pages. */
+function scsApi() {
+  return vi.fn(async (opts: Record<string, any>) => {
+    const components = /\/artifacts\/([^/]+)\/components$/.exec(opts.path);
+    if (components) return Number(opts.params?.page ?? 0) === 0 ? COMPONENTS[components[1]!] ?? [] : [];
+    if (opts.path.endsWith("/artifacts")) {
+      return [
+        { id: "art-v1", name: "web", tag: "1.0", orchestration: { id: "orch-1" } },
+        { id: "art-v2", name: "web", tag: "2.0", orchestration: { id: "orch-2" } },
+      ];
+    }
+    throw new Error(`unexpected path ${opts.path}`);
+  });
+}
+
+describe("scs_sbom_comparison", () => {
+  it("diffs two artifacts' components with license and vulnerability deltas", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_sbom_comparison", "get", {
+      base_artifact_id: "art-v1", target_artifact_id: "art-v2",
+    }) as Record<string, any>;
+
+    expect(result.summary).toEqual({
+      added: 1, removed: 1, upgraded: 1, downgraded: 0, changed: 0, relicensed: 0, licenses_introduced: 1, vulnerability_delta: -1,
+    });
+    expect(result.added).toEqual([{ name: "axios", version: "1.6.0", license: "Apache-2.0", vulnerabilities: 1, purl: "pkg:npm/axios@1.6.0" }]);
+    expect(result.upgraded).toEqual([{ name: "lodash", from: "4.17.20", to: "4.17.21", vulnerabilities_before: 2, vulnerabilities_after: 0 }]);
+    expect(result.licenses).toEqual({ introduced: ["Apache-2.0"], dropped: ["WTFPL"] });
+    expect(result.vulnerabilities.introduced_by).toEqual([{ name: "axios@1.6.0", vulnerabilities: 1 }]);
+    expect(result.base).toEqual({ artifact_id: "art-v1", component_count: 2, truncated: false });
+  });
+
+  it("resolves orchestration IDs through the source's artifact list", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    const result = await registry.dispatch(makeClient(request), "scs_sbom_comparison", "get", {
+      base_orchestration_id: "orch-1", target_orchestration_id: "orch-2", source_id: "src-1",
+    }) as Record<string, any>;
+
+    expect(result.target).toEqual({ artifact_id: "art-v2", orchestration_id: "orch-2", name: "web", tag: "2.0", component_count: 2, truncated: false });
+    expect(request.mock.calls.some(([opts]) => opts.path.includes("/artifact-sources/src-1/artifacts"))).toBe(true);
+  });
+
+  it("rejects missing sides and comparing an artifact with itself", async () => {
+    const registry = new Registry(makeConfig());
+    const request = scsApi();
+
+    await expect(registry.dispatch(makeClient(request), "scs_sbom_comparison", "get", { target_artifact_id: "art-v2" }))
+      .rejects.toThrow(/base_artifact_id or base_orchestration_id/);
+    await expect(registry.dispatch(makeClient(request), "scs_sbom_comparison", "get", { base_orchestration_id: "orch-1", target_artifact_id: "art-v2" }))
+      .rejects.toThrow(/source_id is required/);
+    await expect(registry.dispatch(makeClient(request), "scs_sbom_comparison", "get", { base_artifact_id: "art-v1", target_orchestration_id: "orch-1", source_id: "src-1" }))
+      .rejects.toThrow(/Both sides resolve/);
+  });
+});
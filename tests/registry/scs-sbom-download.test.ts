@@ -0,0 +1,115 @@
+/**
+ * Tests for the scs_sbom download action: fetching the SBOM server-side,
+ * summarizing it, and optionally writing it to output_dir.
+ */
+import { afterEach, describe, it, expect, vi } from "vitest";
+import { mkdtempSync, readFileSync, rmSync } from "node:fs";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "scs",
+    ...overrides,
+  };
+}
+
+function makeClient(request: (opts: Record<string, any>) => Promise<unknown>, requestStream?: (opts: Record<string, any>) => Promise<Response>): HarnessClient {
+  return {
+    request,
+    requestStream: requestStream ?? vi.fn(),
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const SBOM = {
+  bomFormat: "CycloneDX",
+  specVersion: "1.5",
+  components: [
+    { name: "lodash", licenses: [{ license: { id: "MIT" } }] },
+    { name: "axios", licenses: [{ license: { id: "MIT" } }] },
+  ],
+};
+
+describe("scs_sbom download", () => {
+  let dir: string | undefined;
+  afterEach(() => {
+    vi.unstubAllGlobals();
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+  });
+
+  it("summarizes an SBOM returned inline", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ sbom: JSON.stringify(SBOM) }));
+
+    const result = await registry.dispatchExecute(makeClient(request), "scs_sbom", "download", { orchestration_id: "orch-1" }) as Record<string, any>;
+
+    expect((request.mock.calls[0] as any)[0].path).toBe("/ssca-manager/v1/org/default/project/test-project/orchestration/orch-1/sbom-download");
+    expect(result).toMatchObject({
+      orchestration_id: "orch-1",
+      source: "inline",
+      format: "CycloneDX",
+      spec_version: "1.5",
+      component_count: 2,
+      licenses: { distinct: 1, unlicensed: 0, top: [{ license: "MIT", components: 2 }] },
+    });
+    expect(result._hint).toMatch(/output_dir/);
+  });
+
+  it("fetches Harness links through the client and writes the document to output_dir", async () => {
+    dir = mkdtempSync(join(tmpdir(), "harness-sbom-"));
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ download_url: "https://app.harness.io/ssca-manager/sbom/orch-1.json" }));
+    const requestStream = vi.fn(async () => new Response(JSON.stringify(SBOM)));
+
+    const result = await registry.dispatchExecute(makeClient(request, requestStream), "scs_sbom", "download", {
+      orchestration_id: "orch-1",
+      body: { output_dir: dir },
+    }) as Record<string, any>;
+
+    expect(requestStream).toHaveBeenCalledWith(expect.objectContaining({ path: "/ssca-manager/sbom/orch-1.json", baseUrl: "https://app.harness.io" }));
+    expect(result.file).toBe(join(dir, "sbom-orch-1.json"));
+    expect(JSON.parse(readFileSync(result.file, "utf8"))).toEqual(SBOM);
+    expect(result._hint).toBeUndefined();
+  });
+
+  it("fetches pre-signed storage links without client credentials", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ url: "https://bucket.s3.amazonaws.com/orch-1.json?X-Amz-Signature=abc" }));
+    const requestStream = vi.fn();
+    const fetchMock = vi.fn(async () => new Response(JSON.stringify(SBOM)));
+    vi.stubGlobal("fetch", fetchMock);
+
+    const result = await registry.dispatchExecute(makeClient(request, requestStream), "scs_sbom", "download", { orchestration_id: "orch-1" }) as Record<string, any>;
+
+    expect(requestStream).not.toHaveBeenCalled();
+    expect(fetchMock).toHaveBeenCalledWith("https://bucket.s3.amazonaws.com/orch-1.json?X-Amz-Signature=abc", expect.anything());
+    expect(result).toMatchObject({ source: "bucket.s3.amazonaws.com", component_count: 2 });
+  });
+
+  it("explains a response with neither a document nor a link", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ status: "SUCCESS" }));
+
+    await expect(registry.dispatchExecute(makeClient(request), "scs_sbom", "download", { orchestration_id: "orch-1" }))
+      .rejects.toThrow(/neither an SBOM document nor a download link/);
+  });
+});
@@ -555,6 +555,26 @@ describe("P3-8: scs_component_dependencies resource", () => {
     expect(child).toBeDefined();
     expect(child!.relationship).toBe("child");
   });
+
+  it("format=mermaid returns the dependencies with a flowchart hung off relationship_path", () => {
+    const extract = getOp("scs_component_dependencies", "get").responseExtractor!;
+    const result = extract([
+      { name: "body-parser", version: "1.20.1", purl: "pkg:npm/body-parser@1.20.1", relationship: "DIRECT", vulnerabilities_count: 0 },
+      { name: "qs", version: "6.11.0", purl: "pkg:npm/qs@6.11.0", relationship: "INDIRECT", relationship_path: ["pkg:npm/body-parser@1.20.1"], vulnerabilities_count: 2 },
+    ], { purl: "pkg:npm/express@4.18.0", format: "mermaid" }) as { dependencies: unknown[]; mermaid: string };
+
+    expect(result.dependencies).toHaveLength(2);
+    expect(result.mermaid.split("\n")).toEqual([
+      "flowchart TD",
+      '  n0["express@4.18.0"]',
+      '  n1["body-parser@1.20.1"]',
+      '  n2["qs@6.11.0 (2 vulns)"]',
+      "  n0 --> n1",
+      "  n1 --> n2",
+      "  classDef failed fill:#fdd,stroke:#c00",
+      "  class n2 failed",
+    ]);
+  });
 });
 
 // ─── P3-6: Component Remediation (upgrade suggestions + impact analysis) ───
@@ -2299,3 +2319,158 @@ describe("scs_auto_pr_config path and scope", () => {
     expect(call.params).toMatchObject({ org_id: "myOrg", project_id: "myProj" });
   });
 });
+
+describe("scs_vex_statement resource", () => {
+  it("records a not_affected statement with its justification", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    const mockRequest = vi.fn().mockResolvedValue({ id: "vex-1", status: "not_affected", author: null });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "scs_vex_statement", "create", {
+      artifact_id: "art-1",
+      body: {
+        vulnerability_id: "CVE-2024-3094",
+        status: "not_affected",
+        justification: "vulnerable_code_not_in_execute_path",
+        subcomponents: "pkg:deb/debian/xz-utils@5.6.0",
+      },
+    });
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "POST",
+      path: "/ssca-manager/v1/orgs/default/projects/test-project/artifacts/art-1/vex-statements",
+      body: expect.objectContaining({
+        vulnerability_id: "CVE-2024-3094",
+        status: "not_affected",
+        justification: "vulnerable_code_not_in_execute_path",
+        subcomponents: ["pkg:deb/debian/xz-utils@5.6.0"],
+      }),
+    }));
+    expect(result).toEqual({ id: "vex-1", status: "not_affected" });
+  });
+
+  it("enforces the OpenVEX status rules", () => {
+    const build = getOp("scs_vex_statement", "create" as "list").bodyBuilder!;
+    expect(() => build({ body: { vulnerability_id: "CVE-1", status: "not_affected" } })).toThrow(/justification or an impact_statement/);
+    expect(() => build({ body: { vulnerability_id: "CVE-1", status: "affected" } })).toThrow(/action_statement/);
+    expect(() => build({ body: { vulnerability_id: "CVE-1", status: "fixed", justification: "component_not_present" } })).toThrow(/only applies/);
+    expect(() => build({ body: { vulnerability_id: "CVE-1", status: "not_affected", justification: "trust_me" } })).toThrow(/must be one of/);
+    expect(() => build({ body: { vulnerability_id: "CVE-1", status: "ignored" } })).toThrow(/status must be one of/);
+    expect(build({ body: { vulnerability_id: "CVE-1", status: "affected", action_statement: "Upgrade to 2.4.1" } }))
+      .toEqual({ vulnerability_id: "CVE-1", status: "affected", action_statement: "Upgrade to 2.4.1" });
+  });
+
+  it("lists statements with vulnerability and status filters", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    const mockRequest = vi.fn().mockResolvedValue([{ id: "vex-1", vulnerability_id: "CVE-1", status: "fixed", internal: "x" }]);
+
+    const result = await registry.dispatch(makeClient(mockRequest), "scs_vex_statement", "list", {
+      artifact_id: "art-1", vulnerability_id: "CVE-1", status: "fixed",
+    });
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      method: "GET",
+      params: expect.objectContaining({ vulnerability_id: "CVE-1", status: "fixed" }),
+    }));
+    expect(result).toEqual([{ id: "vex-1", vulnerability_id: "CVE-1", status: "fixed" }]);
+  });
+
+  it("generates an OpenVEX document without calling SCS", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    const mockRequest = vi.fn();
+    const input = {
+      body: {
+        vulnerability_id: "CVE-2024-3094",
+        product: "pkg:oci/api@sha256%3Aabc",
+        status: "not_affected",
+        impact_statement: "xz is only present in the build stage.",
+        author: "security@example.com",
+      },
+    };
+
+    const doc = await registry.dispatchExecute(makeClient(mockRequest), "scs_vex_statement", "generate", input) as Record<string, any>;
+    const again = await registry.dispatchExecute(makeClient(mockRequest), "scs_vex_statement", "generate", input) as Record<string, any>;
+
+    expect(mockRequest).not.toHaveBeenCalled();
+    expect(doc).toMatchObject({
+      "@context": "https://openvex.dev/ns/v0.2.0",
+      author: "security@example.com",
+      version: 1,
+      statements: [{
+        vulnerability: { name: "CVE-2024-3094" },
+        products: [{ "@id": "pkg:oci/api@sha256%3Aabc" }],
+        status: "not_affected",
+        impact_statement: "xz is only present in the build stage.",
+      }],
+    });
+    expect(doc["@id"]).toMatch(/^https:\/\/openvex\.dev\/docs\/public\/vex-[0-9a-f]{64}$/);
+    expect(again["@id"]).toBe(doc["@id"]);
+  });
+
+  it("requires a product to generate a document", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    await expect(registry.dispatchExecute(makeClient(), "scs_vex_statement", "generate", {
+      body: { vulnerability_id: "CVE-1", status: "fixed" },
+    })).rejects.toThrow(/product is required/);
+  });
+});
+
+describe("scs_artifact_source artifact enrichment", () => {
+  const sources = [
+    { id: "src1", name: "ECR", artifact_type: { type: "CONTAINER" } },
+    { id: "src2", name: "GCR", artifact_type: { type: "CONTAINER" } },
+  ];
+
+  it("previews each source's artifacts with a capped page size", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) => opts.path.endsWith("/artifact-sources")
+      ? structuredClone(sources)
+      : [{ id: `${opts.path.split("/").at(-2)}-a1`, name: "api", tag: "v1", extra: "dropped" }]);
+
+    const result = await registry.dispatch(makeClient(mockRequest), "scs_artifact_source", "list", {
+      artifacts_per_source: 50,
+    }) as Record<string, any>[];
+
+    expect(mockRequest.mock.calls[0]![0]).toMatchObject({
+      method: "POST",
+      path: "/ssca-manager/v1/orgs/default/projects/test-project/artifact-sources",
+      params: { size: "10" },
+    });
+    const artifactCalls = mockRequest.mock.calls.slice(1).map(([opts]) => opts);
+    expect(artifactCalls).toHaveLength(2);
+    expect(String(artifactCalls[0]!.params.size)).toBe("20");
+    expect(result[0]).toMatchObject({ id: "src1", artifacts: [{ id: "src1-a1", name: "api", tag: "v1" }] });
+    expect(result[1]!.artifacts[0].extra).toBeUndefined();
+    expect((result.at(-1) as Record<string, any>)._summary.enrichment).toBeUndefined();
+  });
+
+  it("keeps sources whose artifacts fail and reports a partial result", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    const mockRequest = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path.endsWith("/artifact-sources")) return structuredClone(sources);
+      if (opts.path.includes("/src2/")) throw new Error("quota exceeded");
+      return [{ id: "a1", name: "api" }];
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "scs_artifact_source", "list", {}) as Record<string, any>[];
+
+    expect(result[0]!.artifacts).toHaveLength(1);
+    expect(result[1]).toMatchObject({ id: "src2", name: "GCR" });
+    expect(result[1]!.artifacts_error).toContain("quota exceeded");
+    expect((result.at(-1) as Record<string, any>)._summary.enrichment).toMatchObject({ enriched: 1, failed: 1 });
+  });
+
+  it("makes a single call when include_artifacts is false", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "scs" }));
+    const mockRequest = vi.fn().mockResolvedValue(structuredClone(sources));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "scs_artifact_source", "list", {
+      include_artifacts: false,
+      search_term: "ecr",
+      artifact_type: "CONTAINER",
+    }) as Record<string, any>[];
+
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+    expect(mockRequest.mock.calls[0]![0]).toMatchObject({ body: { search_term: "ecr", artifact_type: ["CONTAINER"] } });
+    expect(result[0]!.artifacts).toBeUndefined();
+  });
+});
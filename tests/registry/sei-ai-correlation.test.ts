@@ -0,0 +1,89 @@
+/**
+ * Tests for sei_ai_delivery_correlation: AI adoption joined with PR delivery metrics.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "sei",
+    HARNESS_SEI_DEVELOPER_METRICS: "aggregate",
+    HARNESS_SEI_MIN_GROUP_SIZE: 3,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const WEEKS = ["2026-09-07", "2026-09-14", "2026-09-21", "2026-09-28"];
+
+function seiApi(failFeature?: string) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.path.endsWith("/coding-assistant/adoptions")) {
+      return { data: WEEKS.map((date, i) => ({ date, adoptionRate: 20 + 10 * i })) };
+    }
+    const feature = opts.body.featureType as string;
+    if (feature === failFeature) throw new Error("feature not enabled");
+    const value = (i: number) => (feature === "PR_VELOCITY" ? 10 + 2 * i : feature === "PR_CYCLE_TIME" ? 48 - 4 * i : 6 + (i % 2));
+    return { data: WEEKS.map((date, i) => ({ date, [feature === "PR_VELOCITY" ? "prsMerged" : "median"]: value(i) })) };
+  });
+}
+
+const INPUT = { team_ref_id: "42", date_start: "2026-09-01", date_end: "2026-09-30" };
+
+describe("sei_ai_delivery_correlation", () => {
+  it("joins adoption with each delivery metric per week and correlates them", async () => {
+    const registry = new Registry(makeConfig());
+    const request = seiApi();
+
+    const result = await registry.dispatch(makeClient(request), "sei_ai_delivery_correlation", "get", INPUT) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(4);
+    const features = request.mock.calls.map(([opts]) => opts.body.featureType).filter(Boolean);
+    expect(new Set(features)).toEqual(new Set(["PR_VELOCITY", "PR_CYCLE_TIME", "PR_REVIEW_TIME"]));
+    expect(request.mock.calls.every(([opts]) => opts.body.granularity === "WEEKLY")).toBe(true);
+    expect(result.periods[0]).toEqual({ period: "2026-09-07", ai_adoption: 20, pr_velocity: 10, pr_cycle_time: 48, pr_review_time: 6 });
+    expect(result.correlations).toEqual([
+      { metric: "pr_velocity", periods: 4, r: 1, strength: "strong", association: "improves" },
+      { metric: "pr_cycle_time", periods: 4, r: -1, strength: "strong", association: "improves" },
+      { metric: "pr_review_time", periods: 4, r: 0.45, strength: "moderate", association: "worsens" },
+    ]);
+    expect(result.errors).toBeUndefined();
+  });
+
+  it("reports a series that fails and correlates the rest", async () => {
+    const registry = new Registry(makeConfig());
+    const result = await registry.dispatch(makeClient(seiApi("PR_REVIEW_TIME")), "sei_ai_delivery_correlation", "get", INPUT) as Record<string, any>;
+
+    expect(result.errors).toEqual([{ metric: "pr_review_time", error: expect.stringContaining("feature not enabled") }]);
+    expect(result.correlations.map((c: any) => c.metric)).toEqual(["pr_velocity", "pr_cycle_time"]);
+  });
+
+  it("requires the team and the date range", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn();
+    await expect(registry.dispatch(makeClient(request), "sei_ai_delivery_correlation", "get", { team_ref_id: "42" })).rejects.toThrow(/date_start/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,115 @@
+/**
+ * Tests for individual-contributor SEI metrics and the
+ * HARNESS_SEI_DEVELOPER_METRICS privacy modes.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { aggregateDeveloperMetrics, findDeveloperRows } from "../../src/utils/developer-privacy.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "sei",
+    HARNESS_SEI_DEVELOPER_METRICS: "aggregate",
+    HARNESS_SEI_MIN_GROUP_SIZE: 3,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const ROWS = [
+  { developerId: "d1", developerName: "Ada", email: "ada@example.com", prsMerged: 4, cycleTimeHours: 20 },
+  { developerId: "d2", developerName: "Grace", email: "grace@example.com", prsMerged: 8, cycleTimeHours: 10 },
+  { developerId: "d3", developerName: "Linus", email: "linus@example.com", prsMerged: 6 },
+  { developerId: "d4", developerName: "Ken", email: "ken@example.com", prsMerged: 2, cycleTimeHours: 30 },
+];
+
+const LIST_INPUT = { team_ref_id: "42", date_start: "2026-01-01", date_end: "2026-03-31" };
+
+describe("findDeveloperRows", () => {
+  it("finds per-developer rows nested in an SEI response", () => {
+    expect(findDeveloperRows({ data: { breakdown: ROWS } })).toBe(ROWS);
+    expect(findDeveloperRows({ data: [{ week: "2026-W01", count: 3 }] })).toBeUndefined();
+  });
+});
+
+describe("aggregateDeveloperMetrics", () => {
+  it("reduces rows to per-metric distributions without identities", () => {
+    const result = aggregateDeveloperMetrics({ startDate: "2026-01-01", owner: "x", data: ROWS }, 3) as Record<string, any>;
+    expect(result.metrics.prsMerged).toEqual({ developers: 4, min: 2, p25: 3.5, median: 5, p75: 6.5, max: 8, mean: 5 });
+    expect(result.metrics.cycleTimeHours).toMatchObject({ developers: 3, median: 20 });
+    expect(result._privacy).toEqual({ mode: "aggregate", developers: 4, min_group_size: 3 });
+    expect(result.startDate).toBe("2026-01-01");
+    expect(JSON.stringify(result)).not.toMatch(/Ada|ada@example\.com|d1/);
+  });
+
+  it("suppresses groups and metrics below the minimum size", () => {
+    const small = aggregateDeveloperMetrics({ data: ROWS.slice(0, 2) }, 3) as Record<string, any>;
+    expect(small).not.toHaveProperty("metrics");
+    expect(small._privacy).toMatchObject({ suppressed: true, developers: 2 });
+
+    const partial = aggregateDeveloperMetrics({ data: ROWS }, 4) as Record<string, any>;
+    expect(Object.keys(partial.metrics)).toEqual(["prsMerged"]);
+  });
+});
+
+describe("sei_developer_metric", () => {
+  it("asks SEI for a per-developer breakdown and returns aggregates by default", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: ROWS }));
+
+    const result = await registry.dispatch(makeClient(request), "sei_developer_metric", "list", LIST_INPUT) as Record<string, any>;
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.path).toBe("/gateway/sei/api/v2/productivityv3/feature_metrics");
+    expect(opts.body).toMatchObject({ stackBy: "DEVELOPER", teamRefIds: [42], featureType: "PR_VELOCITY", startDate: "2026-01-01" });
+    expect(result.metrics.prsMerged.median).toBe(5);
+    expect(JSON.stringify(result)).not.toContain("grace@example.com");
+  });
+
+  it("returns per-developer rows only when the server allows individual metrics", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_SEI_DEVELOPER_METRICS: "individual" }));
+    const result = await registry.dispatch(makeClient(vi.fn(async () => ({ data: ROWS }))), "sei_developer_metric", "list", LIST_INPUT);
+    expect(result).toMatchObject({ data: ROWS });
+  });
+
+  it("is unavailable, along with sei_ai_raw_metric, when developer metrics are off", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_SEI_DEVELOPER_METRICS: "off" }));
+    const request = vi.fn();
+    await expect(registry.dispatch(makeClient(request), "sei_developer_metric", "list", LIST_INPUT)).rejects.toThrow(/sei_developer_metric/);
+    await expect(registry.dispatch(makeClient(request), "sei_ai_raw_metric", "list", {})).rejects.toThrow(/sei_ai_raw_metric/);
+    expect(request).not.toHaveBeenCalled();
+    expect(() => registry.getResource("sei_team")).not.toThrow();
+  });
+});
+
+describe("sei_ai_raw_metric", () => {
+  it("is aggregated under the default privacy mode", async () => {
+    const registry = new Registry(makeConfig());
+    const raw = { data: { content: ROWS.map(({ developerName, email, prsMerged }) => ({ developerName, email, linesAccepted: prsMerged * 10 })) } };
+    const result = await registry.dispatch(makeClient(vi.fn(async () => raw)), "sei_ai_raw_metric", "list", { team_ref_id: "42" }) as Record<string, any>;
+    expect(result.metrics.linesAccepted).toMatchObject({ developers: 4, max: 80 });
+    expect(JSON.stringify(result)).not.toContain("Ada");
+  });
+});
@@ -0,0 +1,79 @@
+/**
+ * Tests for sei_integration: ingestion health of SEI integrations.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "sei",
+    HARNESS_SEI_DEVELOPER_METRICS: "aggregate",
+    HARNESS_SEI_MIN_GROUP_SIZE: 3,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const hoursAgo = (h: number) => new Date(Date.now() - h * 3600_000).toISOString();
+
+const INTEGRATIONS = {
+  data: [
+    { id: 11, name: "GitHub", application: "github", status: "ACTIVE", lastIngestedAt: hoursAgo(1) },
+    { id: 12, name: "Jira", application: "jira", status: "ACTIVE", lastIngestedAt: hoursAgo(50) },
+    { id: 13, name: "GitLab", application: "gitlab", status: "FAILED", lastErrorMessage: "token expired", errorCount: 3 },
+  ],
+};
+
+describe("sei_integration", () => {
+  it("lists failing and stale integrations first with a summary", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => INTEGRATIONS);
+
+    const result = await registry.dispatch(makeClient(request), "sei_integration", "list", { application: "jira" }) as Record<string, any>;
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.path).toBe("/gateway/sei/api/v2/integrations");
+    expect(opts.params).toMatchObject({ application: "jira" });
+    expect(result.items.map((i: any) => [i.name, i.health])).toEqual([["GitLab", "failing"], ["Jira", "stale"], ["GitHub", "healthy"]]);
+    expect(result.summary).toEqual({ integrations: 3, healthy: 1, failing: 1, stale: 1, unknown: 0 });
+    expect(result._hint).toContain("2 integration(s)");
+  });
+
+  it("keeps one health state when asked", async () => {
+    const registry = new Registry(makeConfig());
+    const result = await registry.dispatch(makeClient(vi.fn(async () => INTEGRATIONS)), "sei_integration", "list", { health: "failing" }) as Record<string, any>;
+    expect(result.items).toEqual([expect.objectContaining({ integration_id: "13", last_error: "token expired" })]);
+    expect(result.summary.integrations).toBe(3);
+  });
+
+  it("gets one integration's health", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: INTEGRATIONS.data[1] }));
+    const result = await registry.dispatch(makeClient(request), "sei_integration", "get", { integration_id: "12" }) as Record<string, any>;
+
+    expect((request.mock.calls[0] as unknown as [Record<string, any>])[0].path).toBe("/gateway/sei/api/v2/integrations/12");
+    expect(result).toMatchObject({ integration_id: "12", health: "stale", integration: { name: "Jira" } });
+  });
+});
@@ -0,0 +1,94 @@
+/**
+ * Tests for sei_sprint_metric: velocity trend, carry-over, and burndown.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "sei",
+    HARNESS_SEI_DEVELOPER_METRICS: "aggregate",
+    HARNESS_SEI_MIN_GROUP_SIZE: 3,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const SPRINTS = {
+  data: [
+    { sprintName: "Sprint 41", startDate: "2026-08-10", completedPoints: 30, committedPoints: 34 },
+    { sprintName: "Sprint 42", startDate: "2026-08-24", completedPoints: 24, committedPoints: 34 },
+    { sprintName: "Sprint 43", startDate: "2026-09-07", completedPoints: 18, committedPoints: 36 },
+  ],
+};
+
+const INPUT = { team_ref_id: "42", date_start: "2026-08-01", date_end: "2026-09-30" };
+
+describe("sei_sprint_metric", () => {
+  it("posts to the BA sprint summary and returns the velocity trend", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => SPRINTS);
+
+    const result = await registry.dispatch(makeClient(request), "sei_sprint_metric", "get", { ...INPUT, aspect: "velocity" }) as Record<string, any>;
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.method).toBe("POST");
+    expect(opts.path).toBe("/gateway/sei/api/v2/insights/ba/sprint_metrics");
+    expect(opts.body).toMatchObject({ teamRefId: "42", dateStart: "2026-08-01", dateEnd: "2026-09-30" });
+    expect(result).toMatchObject({ team_ref_id: "42", aspect: "velocity", average_velocity: 24, trend: "falling", change_per_sprint: -6 });
+  });
+
+  it("reads carry-over from the same summary", async () => {
+    const registry = new Registry(makeConfig());
+    const result = await registry.dispatch(makeClient(vi.fn(async () => SPRINTS)), "sei_sprint_metric", "get", { ...INPUT, aspect: "carry_over" }) as Record<string, any>;
+
+    expect(result.sprints.map((s: any) => s.carried_over)).toEqual([4, 10, 18]);
+    expect(result).toMatchObject({ total_carried_over: 32, overcommitted_sprints: 2, chronic: true });
+  });
+
+  it("fetches one sprint's burndown", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({
+      data: {
+        sprint: { name: "Sprint 44", endDate: "2026-10-05" },
+        points: [{ date: "2026-09-21", remainingPoints: 40 }, { date: "2026-09-28", remainingPoints: 18 }],
+      },
+    }));
+
+    const result = await registry.dispatch(makeClient(request), "sei_sprint_metric", "get", { ...INPUT, aspect: "burndown", sprint_id: "s-44" }) as Record<string, any>;
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.path).toBe("/gateway/sei/api/v2/insights/ba/sprint_burndown");
+    expect(opts.body).toMatchObject({ sprintId: "s-44" });
+    expect(result).toMatchObject({ sprint: "Sprint 44", remaining: 18, ideal_remaining: 20, status: "on_track" });
+  });
+
+  it("requires a team", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn();
+    await expect(registry.dispatch(makeClient(request), "sei_sprint_metric", "get", { aspect: "velocity" })).rejects.toThrow(/team_ref_id/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,72 @@
+/**
+ * Tests for SEI team discovery: resolving team names to team_ref_id.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "sei",
+    HARNESS_SEI_DEVELOPER_METRICS: "aggregate",
+    HARNESS_SEI_MIN_GROUP_SIZE: 3,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const TEAMS = [
+  { refId: 101, name: "Payments", parentRefId: 1 },
+  { refId: 102, name: "Payments Mobile", parentRefId: 101 },
+  { refId: 201, name: "Search" },
+];
+
+describe("sei_team list", () => {
+  it("returns team_ref_id rows and narrows them by search", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: TEAMS }));
+
+    const result = await registry.dispatch(makeClient(request), "sei_team", "list", { search: "payments" }) as Record<string, any>;
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.path).toBe("/gateway/sei/api/v2/teams/list");
+    expect(result.items).toEqual([
+      { team_ref_id: "101", name: "Payments", parent_ref_id: "1" },
+      { team_ref_id: "102", name: "Payments Mobile", parent_ref_id: "101" },
+    ]);
+    expect(result.total).toBe(2);
+  });
+
+  it("browses one org tree's teams and hints when a search finds nothing", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ refId: 1, name: "Engineering", children: TEAMS }));
+
+    const result = await registry.dispatch(makeClient(request), "sei_team", "list", { org_tree_id: "tree-9", search: "billing" }) as Record<string, any>;
+
+    const opts = (request.mock.calls[0] as unknown as [Record<string, any>])[0];
+    expect(opts.path).toBe("/gateway/sei/api/v2/org-trees/tree-9/teams");
+    expect(result.items).toEqual([]);
+    expect(result._hint).toContain("among 4 teams");
+  });
+});
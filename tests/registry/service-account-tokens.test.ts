@@ -0,0 +1,201 @@
+/**
+ * Tests for service account credential resources: role bindings, API keys,
+ * and token issue / rotate / revoke.
+ */
+import { afterEach, describe, expect, it, vi } from "vitest";
+import { mkdtempSync, readFileSync, rmSync, statSync } from "node:fs";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "access_control",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+describe("service_account bind_roles", () => {
+  it("creates one role assignment per binding for the service account", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: [] }));
+
+    await registry.dispatchExecute(makeClient(request), "service_account", "bind_roles", {
+      service_account_id: "ci_bot",
+      body: { role_bindings: [{ role: "_pipeline_executor", resource_group: "_all_project_level_resources" }] },
+    });
+
+    const call = request.mock.calls[0]![0] as Record<string, any>;
+    expect(call.path).toBe("/authz/api/roleassignments/multi");
+    expect(call.body.roleAssignments).toEqual([{
+      roleIdentifier: "_pipeline_executor",
+      resourceGroupIdentifier: "_all_project_level_resources",
+      principal: { identifier: "ci_bot", type: "SERVICE_ACCOUNT" },
+      disabled: false,
+    }]);
+  });
+
+  it("rejects a call without bindings", async () => {
+    const registry = new Registry(makeConfig());
+
+    await expect(registry.dispatchExecute(makeClient(vi.fn()), "service_account", "bind_roles", {
+      service_account_id: "ci_bot",
+      body: {},
+    })).rejects.toThrow("body.role_bindings");
+  });
+});
+
+describe("service_account_api_key", () => {
+  it("creates a SERVICE_ACCOUNT key under the service account", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: { identifier: "ci_key" } }));
+
+    await registry.dispatch(makeClient(request), "service_account_api_key", "create", {
+      service_account_id: "ci_bot",
+      body: { identifier: "ci_key", default_token_expiry_days: 30 },
+    });
+
+    const call = request.mock.calls[0]![0] as Record<string, any>;
+    expect(call.path).toBe("/ng/api/apikey");
+    expect(call.body).toMatchObject({
+      identifier: "ci_key",
+      name: "ci_key",
+      apiKeyType: "SERVICE_ACCOUNT",
+      parentIdentifier: "ci_bot",
+      defaultTimeToExpireToken: 30 * 24 * 60 * 60 * 1000,
+      accountIdentifier: "test-account",
+      projectIdentifier: "test-project",
+    });
+  });
+
+  it("requires service_account_id to list keys", async () => {
+    const registry = new Registry(makeConfig());
+
+    await expect(registry.dispatch(makeClient(vi.fn()), "service_account_api_key", "list", {}))
+      .rejects.toThrow(/service_account_id/);
+  });
+});
+
+describe("service_account_token", () => {
+  let dir: string | undefined;
+
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+  });
+
+  it("issues a token with an expiry and returns its value once with the ids to manage it", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ status: "SUCCESS", data: "sat.test-account.ci_token.secret" }));
+    const before = Date.now();
+
+    const result = await registry.dispatch(makeClient(request), "service_account_token", "create", {
+      service_account_id: "ci_bot",
+      api_key_id: "ci_key",
+      body: { identifier: "ci_token", expires_in_days: 7 },
+    }) as Record<string, unknown>;
+
+    const call = request.mock.calls[0]![0] as Record<string, any>;
+    expect(call.path).toBe("/ng/api/token");
+    expect(call.body).toMatchObject({
+      identifier: "ci_token",
+      apiKeyIdentifier: "ci_key",
+      parentIdentifier: "ci_bot",
+      apiKeyType: "SERVICE_ACCOUNT",
+    });
+    expect(call.body.validTo - call.body.validFrom).toBe(7 * 24 * 60 * 60 * 1000);
+    expect(call.body.validFrom).toBeGreaterThanOrEqual(before);
+    expect(result).toMatchObject({
+      service_account_id: "ci_bot",
+      api_key_id: "ci_key",
+      token_id: "ci_token",
+      token: "sat.test-account.ci_token.secret",
+    });
+  });
+
+  it("writes the token to an owner-only file with output_dir", async () => {
+    dir = mkdtempSync(join(tmpdir(), "harness-token-"));
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: "sat.secret" }));
+
+    const result = await registry.dispatch(makeClient(request), "service_account_token", "create", {
+      service_account_id: "ci_bot",
+      api_key_id: "ci_key",
+      body: { identifier: "ci_token", output_dir: dir },
+    }) as Record<string, unknown>;
+
+    expect(result.token).toBeUndefined();
+    expect(result.file).toBe(join(dir, "ci_bot-ci_token.token"));
+    expect(readFileSync(result.file as string, "utf8")).toBe("sat.secret\n");
+    if (process.platform !== "win32") expect(statSync(result.file as string).mode & 0o777).toBe(0o600);
+  });
+
+  it("rotate: posts to the rotate endpoint with a grace period for the old token", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: "sat.new" }));
+    const before = Date.now();
+
+    const result = await registry.dispatchExecute(makeClient(request), "service_account_token", "rotate", {
+      token_id: "ci_token",
+      service_account_id: "ci_bot",
+      api_key_id: "ci_key",
+      body: { grace_period_hours: 24 },
+    }) as Record<string, unknown>;
+
+    const call = request.mock.calls[0]![0] as Record<string, any>;
+    expect(call.method).toBe("POST");
+    expect(call.path).toBe("/ng/api/token/rotate/ci_token");
+    expect(call.params).toMatchObject({ apiKeyType: "SERVICE_ACCOUNT", parentIdentifier: "ci_bot", apiKeyIdentifier: "ci_key" });
+    expect(call.params.rotateTimestamp).toBeGreaterThanOrEqual(before + 24 * 60 * 60 * 1000);
+    expect(result).toMatchObject({ token_id: "ci_token", token: "sat.new" });
+  });
+
+  it("revokes a token with its parent ids", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({ data: true }));
+
+    await registry.dispatch(makeClient(request), "service_account_token", "delete", {
+      token_id: "ci_token",
+      service_account_id: "ci_bot",
+      api_key_id: "ci_key",
+    });
+
+    const call = request.mock.calls[0]![0] as Record<string, any>;
+    expect(call.method).toBe("DELETE");
+    expect(call.path).toBe("/ng/api/token/ci_token");
+    expect(call.params).toMatchObject({ apiKeyType: "SERVICE_ACCOUNT", parentIdentifier: "ci_bot", apiKeyIdentifier: "ci_key" });
+  });
+
+  it("blocks issuing and rotating tokens in read-only mode", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_READ_ONLY: true }));
+    const request = vi.fn();
+    const input = { token_id: "ci_token", service_account_id: "ci_bot", api_key_id: "ci_key", body: { identifier: "ci_token" } };
+
+    await expect(registry.dispatch(makeClient(request), "service_account_token", "create", input)).rejects.toThrow(/Read-only mode/);
+    await expect(registry.dispatchExecute(makeClient(request), "service_account_token", "rotate", input)).rejects.toThrow(/Read-only mode/);
+    expect(request).not.toHaveBeenCalled();
+  });
+});
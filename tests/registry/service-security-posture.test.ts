@@ -0,0 +1,177 @@
+/**
+ * Tests for service_security_posture: merging STO, SCS, chaos, and policy
+ * results for one service into a normalized snapshot.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { serviceSecurityPostureExtract, type ServicePostureScan } from "../../src/registry/extractors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "services,sto,scs,chaos,governance",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const STO_TOTALS: Record<string, number> = { Critical: 0, High: 3, Medium: 1, Low: 7 };
+
+function mockHarness(opts: { path: string; params?: Record<string, unknown> }): unknown {
+  if (opts.path === "/ng/api/servicesV2/checkout") {
+    return { status: "SUCCESS", data: { service: { identifier: "checkout", name: "Checkout" } } };
+  }
+  if (opts.path === "/sto/api/v2/frontend/all-issues/issues") {
+    return { issues: [], pagination: { totalItems: STO_TOTALS[String(opts.params?.severityCodes)] ?? 0 } };
+  }
+  if (opts.path.includes("/artifact-sources/src-1/artifacts")) {
+    return [{
+      id: "art-1",
+      name: "checkout-image",
+      tag: "v2",
+      vulnerability_count: { critical: 2, high: 4, medium: 0, low: 1 },
+      policy_enforcement: { id: "enf-1", allow_list_violation_count: 1, deny_list_violation_count: 2 },
+    }];
+  }
+  if (opts.path === "/chaos/manager/api/rest/v2/experiment") {
+    return {
+      data: [
+        { experimentID: "e1", name: "pod-kill", recentExperimentRunDetails: [{ resiliencyScore: 90 }, { resiliencyScore: 10 }] },
+        { experimentID: "e2", name: "latency", recentExperimentRunDetails: [{ resiliencyScore: 50 }] },
+        { experimentID: "e3", name: "never-run", recentExperimentRunDetails: [] },
+      ],
+      pagination: { totalItems: 3 },
+    };
+  }
+  if (opts.path === "/pm/api/v1/evaluations") {
+    return [{ id: "ev-1", action: "onsave", status: "error", created: 1700000000000 }];
+  }
+  throw new Error(`unexpected ${opts.path}`);
+}
+
+describe("service_security_posture get", () => {
+  it("queries each source scoped to the service and merges the results", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => mockHarness(opts));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "service_security_posture", "get", {
+      service_id: "checkout",
+      scs_source_id: "src-1",
+    }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      path: "/sto/api/v2/frontend/all-issues/issues",
+      params: expect.objectContaining({ search: "checkout", severityCodes: "High", exemptionStatuses: "None,Pending,Rejected,Expired" }),
+    }));
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      path: "/chaos/manager/api/rest/v2/experiment",
+      params: expect.objectContaining({ tags: "service=checkout" }),
+    }));
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      path: "/pm/api/v1/evaluations",
+      params: expect.objectContaining({ type: "service", entity: "checkout", status: "error" }),
+    }));
+
+    expect(result).toMatchObject({
+      service_id: "checkout",
+      service_name: "Checkout",
+      risk_level: "high",
+      sources_checked: 4,
+      sto: { status: "ok", open_issues: { critical: 0, high: 3, medium: 1, low: 7, total: 11 } },
+      scs: { status: "ok", policy_violations: 3, artifacts: [{ artifact_id: "art-1", enforcement_id: "enf-1", vulnerabilities: { critical: 2, high: 4, total: 7 } }] },
+      chaos: { status: "ok", experiments: 3, scored_experiments: 2, resilience_score: 70, weakest: { experiment_id: "e2", resilience_score: 50 } },
+      policy: { status: "ok", open_violations: 1, recent: [{ evaluation_id: "ev-1", action: "onsave" }] },
+    });
+    expect(result.risk_drivers[0]).toMatch(/^high: /);
+    expect(result.risk_drivers).toContain("medium: Chaos resilience score 70 (< 80)");
+  });
+
+  it("uses sto_target_ids instead of a name search when given", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => mockHarness(opts));
+
+    await registry.dispatch(makeClient(mockRequest), "service_security_posture", "get", {
+      service_id: "checkout",
+      sto_target_ids: "t1,t2",
+    });
+
+    const stoCall = mockRequest.mock.calls.find(([o]) => o.path === "/sto/api/v2/frontend/all-issues/issues")![0];
+    expect(stoCall.params).toMatchObject({ targetIds: "t1,t2" });
+    expect(stoCall.params?.search).toBeUndefined();
+  });
+
+  it("reports disabled toolsets and missing SCS source without failing", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "services,sto" }));
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => mockHarness(opts));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "service_security_posture", "get", { service_id: "checkout" }) as Record<string, any>;
+
+    expect(result.scs.status).toBe("not_configured");
+    expect(result.chaos).toMatchObject({ status: "unavailable", reason: expect.stringContaining("HARNESS_TOOLSETS") });
+    expect(result.policy.status).toBe("unavailable");
+    expect(result.sources_checked).toBe(1);
+  });
+
+  it("keeps going when one source errors", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => {
+      if (opts.path === "/pm/api/v1/evaluations") throw new Error("403 Forbidden");
+      return mockHarness(opts);
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "service_security_posture", "get", { service_id: "checkout" }) as Record<string, any>;
+    expect(result.policy).toEqual({ status: "error", reason: "governance: 403 Forbidden" });
+    expect(result.sto.status).toBe("ok");
+  });
+});
+
+describe("serviceSecurityPostureExtract", () => {
+  const base: ServicePostureScan = {
+    service_id: "svc",
+    service: { service: { identifier: "svc", name: "Svc" } },
+    sto: { status: "ok", data: { Critical: { pagination: { totalItems: 1 } } } },
+    scs: { status: "not_configured", reason: "no source" },
+    chaos: { status: "unavailable", reason: "off" },
+    policy: { status: "ok", data: { items: [], total: 0 } },
+  };
+
+  it("escalates to critical on open critical STO issues", () => {
+    const result = serviceSecurityPostureExtract(base) as Record<string, any>;
+    expect(result.risk_level).toBe("critical");
+    expect(result.risk_drivers).toEqual(["critical: 1 open critical STO issue(s)"]);
+  });
+
+  it("is low when checked sources are clean and unknown when nothing was checked", () => {
+    const clean = serviceSecurityPostureExtract({ ...base, sto: { status: "ok", data: {} } }) as Record<string, any>;
+    expect(clean.risk_level).toBe("low");
+
+    const none = serviceSecurityPostureExtract({
+      ...base,
+      sto: { status: "error", reason: "boom" },
+      policy: { status: "unavailable", reason: "off" },
+    }) as Record<string, any>;
+    expect(none.risk_level).toBe("unknown");
+    expect(none.sto).toEqual({ status: "error", reason: "boom" });
+  });
+});
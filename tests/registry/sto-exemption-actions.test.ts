@@ -1,10 +1,10 @@
 /**
- * Regression tests for security_exemption create + approve/reject execute actions.
+ * Regression tests for security_exemption create + request/approve/reject/expire execute actions.
  *
  * Covers scope routing (/approve vs /promote), scope elevation input mutation,
  * approver/requester auto-injection, and snake_case body mapping.
  */
-import { describe, it, expect, vi } from "vitest";
+import { afterEach, describe, it, expect, vi } from "vitest";
 import { stoToolset } from "../../src/registry/toolsets/sto.js";
 import { Registry } from "../../src/registry/index.js";
 import type { Config } from "../../src/config.js";
@@ -216,6 +216,33 @@ describe("security_exemption reject", () => {
   });
 });
 
+// ─── expire ─────────────────────────────────────────────────────────────────
+
+describe("security_exemption expire", () => {
+  it("hits /expire with the current user as approver and the comment", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+    const mockRequest = vi.fn().mockResolvedValue({ status: "Expired" });
+
+    await registry.dispatchExecute(makeClient(mockRequest, "lead-uuid"), "security_exemption", "expire", {
+      exemption_id: "ex-9",
+      body: { comment: "fixed in 2.17.1" },
+    });
+
+    const call = mockRequest.mock.calls[0]![0] as { method: string; path: string; body: Record<string, unknown> };
+    expect(call.method).toBe("PUT");
+    expect(call.path).toBe("/sto/api/v2/exemptions/ex-9/expire");
+    expect(call.body).toEqual({ approverId: "lead-uuid", comment: "fixed in 2.17.1" });
+  });
+
+  it("is refused in read-only mode", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto", HARNESS_READ_ONLY: true }));
+    const mockRequest = vi.fn();
+
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "security_exemption", "expire", { exemption_id: "ex-9" })).rejects.toThrow();
+    expect(mockRequest).not.toHaveBeenCalled();
+  });
+});
+
 // ─── dispatchExecute integration ───────────────────────────────────────────
 
 describe("security_exemption dispatchExecute", () => {
@@ -263,3 +290,91 @@ describe("security_exemption dispatchExecute", () => {
     expect(call.params.projectId).toBe("");
   });
 });
+
+describe("security_exemption request", () => {
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  /** STO stand-in: the all-issues search, single create, and bulk create. */
+  function stoApi(issues: unknown[]) {
+    return vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/sto/api/v2/frontend/all-issues/issues") return { issues, pagination: { totalItems: issues.length } };
+      if (opts.path === "/sto/api/v2/exemptions") return { id: "ex-new" };
+      if (opts.path === "/sto/api/v2/exemptions/bulk") {
+        return { results: opts.body.items.map((it: any, i: number) => ({ issueId: it.issueId, id: `ex-${i}` })), succeeded: opts.body.items.length, failed: 0 };
+      }
+      throw new Error(`unexpected ${opts.path}`);
+    });
+  }
+
+  const xzIssue = { id: "issue-xz", title: "CVE-2024-3094 in xz-utils", severityCode: "Critical", targetName: "api-image" };
+  const openSslIssue = { id: "issue-ssl", title: "CVE-2024-3094 reference in openssl advisory", severityCode: "Low", targetName: "web-image" };
+
+  it("resolves the CVE to its issue and requests an exemption until expires_on", async () => {
+    vi.useFakeTimers();
+    vi.setSystemTime(new Date("2026-10-16T12:00:00Z"));
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+    const mockRequest = stoApi([xzIssue, { id: "issue-other", title: "CVE-2023-0001" }]);
+
+    const result = await registry.dispatchExecute(makeClient(mockRequest, "requester-1"), "security_exemption", "request", {
+      body: { cve: "CVE-2024-3094", component: "xz-utils", type: "Fix Unavailable", reason: "No patched build yet", expires_on: "2026-11-15" },
+    }) as Record<string, any>;
+
+    const search = mockRequest.mock.calls[0]![0] as Record<string, any>;
+    expect(search.params.search).toBe("CVE-2024-3094");
+    const create = mockRequest.mock.calls[1]![0] as Record<string, any>;
+    expect(create.body).toMatchObject({
+      issueId: "issue-xz",
+      type: "Fix Unavailable",
+      reason: "No patched build yet",
+      requesterId: "requester-1",
+      search: "xz-utils",
+      pendingChanges: { durationDays: 30 },
+    });
+    expect(result).toMatchObject({
+      cve: "CVE-2024-3094",
+      duration_days: 30,
+      issues: [{ issue_id: "issue-xz", title: "CVE-2024-3094 in xz-utils", severity: "Critical", target: "api-image" }],
+      exemption_ids: ["ex-new"],
+    });
+    expect(result._hint).toContain("resource_id='ex-new'");
+  });
+
+  it("lists the candidates when the CVE is in several issues, and batches them with all_matches", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+    const mockRequest = stoApi([xzIssue, openSslIssue]);
+    const body = { cve: "CVE-2024-3094", type: "Acceptable Risk", reason: "Not reachable", duration_days: 14 };
+
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "security_exemption", "request", { body }))
+      .rejects.toThrow(/appears in 2 issues: issue-xz .*issue-ssl/);
+
+    const result = await registry.dispatchExecute(makeClient(mockRequest), "security_exemption", "request", {
+      body: { ...body, all_matches: true },
+    }) as Record<string, any>;
+    const bulk = mockRequest.mock.calls.find(([opts]) => (opts as any).path === "/sto/api/v2/exemptions/bulk")![0] as Record<string, any>;
+    expect(bulk.body.items.map((it: any) => it.issueId)).toEqual(["issue-xz", "issue-ssl"]);
+    expect(bulk.body.pendingChanges).toEqual({ durationDays: 14 });
+    expect(result.exemption_ids).toEqual(["ex-0", "ex-1"]);
+  });
+
+  it("validates the request before calling STO and is blocked in read-only mode", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+    const mockRequest = stoApi([xzIssue]);
+
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "security_exemption", "request", { body: { cve: "CVE-2024-3094" } }))
+      .rejects.toThrow(/type, reason/);
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "security_exemption", "request", {
+      body: { issue_id: "issue-xz", type: "Other", reason: "x", expires_on: "2020-01-01" },
+    })).rejects.toThrow(/not in the future/);
+    await expect(registry.dispatchExecute(makeClient(mockRequest), "security_exemption", "request", {
+      body: { cve: "CVE-1999-0001", type: "Other", reason: "x" },
+    })).rejects.toThrow(/No STO issue in this project mentions CVE-1999-0001/);
+
+    const readOnly = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto", HARNESS_READ_ONLY: true }));
+    await expect(readOnly.dispatchExecute(makeClient(mockRequest), "security_exemption", "request", {
+      body: { issue_id: "issue-xz", type: "Other", reason: "x" },
+    })).rejects.toThrow(/Read-only mode/);
+    expect(mockRequest.mock.calls.every(([opts]) => (opts as any).path === "/sto/api/v2/frontend/all-issues/issues")).toBe(true);
+  });
+});
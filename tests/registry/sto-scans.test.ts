@@ -0,0 +1,99 @@
+/**
+ * Tests for STO scan targets, scans, and the per-scanner coverage summary.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    LOG_LEVEL: "info",
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    ...overrides,
+  } as Config;
+}
+
+function makeClient(requestFn?: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn ?? vi.fn().mockResolvedValue({}),
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const SCANS = [
+  { id: "scan-3", targetId: "tgt-api", targetName: "api", productName: "aqua-trivy", status: "Failed", lastModified: "2026-10-15T10:00:00Z", targetVariantName: "v2" },
+  { id: "scan-2", targetId: "tgt-api", targetName: "api", productName: "aqua-trivy", status: "Succeeded", lastModified: "2026-10-10T10:00:00Z", issueCounts: { critical: 1, high: 4 } },
+  { id: "scan-1", targetId: "tgt-api", targetName: "api", productName: "semgrep", status: "Succeeded", lastModified: "2026-10-12T08:00:00Z", critical: 0, high: 2, medium: 5 },
+  { id: "scan-4", targetId: "tgt-web", targetName: "web", productName: "semgrep", status: "Succeeded", lastModified: "2026-10-14T09:30:00Z" },
+];
+
+describe("security_target and security_scan", () => {
+  it("maps target and status filters to STO query params", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({ results: [] });
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+
+    await registry.dispatch(makeClient(mockRequest), "security_target", "list", { type: "container", search: "api" });
+    await registry.dispatch(makeClient(mockRequest), "security_scan", "list", { target_id: "tgt-api", status: "Failed" });
+
+    const [targets, scans] = mockRequest.mock.calls.map(([opts]) => opts as { path: string; params: Record<string, unknown> });
+    expect(targets!.path).toBe("/sto/api/v2/targets");
+    expect(targets!.params).toMatchObject({ type: "container", search: "api" });
+    expect(scans!.path).toBe("/sto/api/v2/scans");
+    expect(scans!.params).toMatchObject({ targetId: "tgt-api", status: "Failed" });
+  });
+});
+
+describe("security_scan_summary", () => {
+  it("keeps the latest scan per scanner and flags scanners missing from a target", async () => {
+    const mockRequest = vi.fn().mockResolvedValue({ results: SCANS });
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "security_scan_summary", "list", {}) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+    expect(result.summary).toEqual({ targets: 2, scanners: ["aqua-trivy", "semgrep"], scans_read: 4, latest_scans_failed: 1 });
+    expect(result.targets).toEqual([
+      {
+        target_id: "tgt-api",
+        target_name: "api",
+        scanners: [
+          { scanner: "aqua-trivy", scan_id: "scan-3", status: "Failed", last_scanned: "2026-10-15T10:00:00.000Z", variant: "v2" },
+          { scanner: "semgrep", scan_id: "scan-1", status: "Succeeded", last_scanned: "2026-10-12T08:00:00.000Z", issues: { critical: 0, high: 2, medium: 5 } },
+        ],
+      },
+      {
+        target_id: "tgt-web",
+        target_name: "web",
+        scanners: [{ scanner: "semgrep", scan_id: "scan-4", status: "Succeeded", last_scanned: "2026-10-14T09:30:00.000Z" }],
+        missing_scanners: ["aqua-trivy"],
+      },
+    ]);
+    expect(result._hint).toBeUndefined();
+  });
+
+  it("pages through scans of one target and says when it stopped early", async () => {
+    const page = Array.from({ length: 100 }, (_, i) => ({ id: `s-${i}`, targetId: "tgt-api", productName: "gitleaks", lastModified: 1_760_000_000_000 + i }));
+    const mockRequest = vi.fn().mockResolvedValue({ results: page });
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "security_scan_summary", "list", { target_id: "tgt-api" }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledTimes(5);
+    expect((mockRequest.mock.calls[0]![0] as any).params).toMatchObject({ targetId: "tgt-api", page: 0, pageSize: 100 });
+    expect(result.targets[0].scanners).toEqual([expect.objectContaining({ scanner: "gitleaks", scan_id: "s-99" })]);
+    expect(result._hint).toContain("Only the most recent scans");
+  });
+});
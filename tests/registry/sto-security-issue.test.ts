@@ -150,3 +150,78 @@ describe("pipeline_security_issue list — partition flattening", () => {
     expect(call.params.projectId).toBe("my-project");
   });
 });
+
+describe("security_issue get — issue detail", () => {
+  const ISSUE = {
+    id: "issue-1",
+    title: "log4j-core: Remote code execution",
+    severityCode: "Critical",
+    issueType: "SCA",
+    productName: "aqua-trivy",
+    targetName: "api-image",
+    referenceIdentifiers: [{ type: "cve", id: "2021-44228" }, { type: "cwe", id: "502" }, { type: "ghsa", id: "GHSA-jfh8-c2jp-5v3q" }],
+    remediation: "Upgrade log4j-core to 2.17.1 or later.",
+  };
+
+  function stoApi(occurrences: (opts: Record<string, any>) => unknown) {
+    return vi.fn(async (opts: Record<string, any>) => {
+      if (opts.path === "/sto/api/v2/issues/issue-1") return ISSUE;
+      if (opts.path === "/sto/api/v2/issues/issue-1/occurrences") return occurrences(opts);
+      throw new Error(`unexpected ${opts.path}`);
+    });
+  }
+
+  it("returns reference IDs, remediation, and occurrences", async () => {
+    const mockRequest = stoApi(() => ({
+      occurrences: [
+        { id: "occ-1", componentName: "log4j-core", currentVersion: "2.14.1", upgradeVersion: "2.17.1", targetName: "api-image", targetVariantName: "v1.2" },
+        { id: "occ-2", fileName: "pom.xml", lineNumber: 42, targetName: "api-repo" },
+      ],
+      pagination: { totalItems: 7 },
+    }));
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "security_issue", "get", {
+      issue_id: "issue-1", org_id: "my-org", project_id: "my-project",
+    }) as Record<string, any>;
+
+    const detail = mockRequest.mock.calls.find(([opts]) => (opts as any).path === "/sto/api/v2/issues/issue-1")![0] as Record<string, any>;
+    expect(detail.params).toEqual({ accountId: "test-account", orgId: "my-org", projectId: "my-project" });
+    expect(result).toMatchObject({
+      id: "issue-1",
+      severity: "Critical",
+      type: "SCA",
+      scan_tool: "aqua-trivy",
+      reference_ids: ["CVE-2021-44228", "CWE-502", "GHSA-jfh8-c2jp-5v3q"],
+      remediation: "Upgrade log4j-core to 2.17.1 or later.",
+      occurrence_count: 7,
+      occurrences: [
+        { id: "occ-1", component: "log4j-core@2.14.1", fixed_in: "2.17.1", target: "api-image", variant: "v1.2" },
+        { id: "occ-2", location: "pom.xml:42", target: "api-repo" },
+      ],
+    });
+  });
+
+  it("keeps the detail when occurrences cannot be read", async () => {
+    const mockRequest = stoApi(() => {
+      throw new Error("occurrences unavailable");
+    });
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+
+    const result = await registry.dispatch(makeClient(mockRequest), "security_issue", "get", { issue_id: "issue-1" }) as Record<string, any>;
+
+    expect(result.title).toBe("log4j-core: Remote code execution");
+    expect(result.occurrences).toEqual([]);
+    expect(result.occurrences_error).toContain("occurrences unavailable");
+  });
+
+  it("passes target_id through to the occurrence list", async () => {
+    const mockRequest = stoApi(() => ({ occurrences: [] }));
+    const registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "sto" }));
+
+    await registry.dispatch(makeClient(mockRequest), "security_issue", "get", { issue_id: "issue-1", target_id: "tgt-9" });
+
+    const occurrences = mockRequest.mock.calls.find(([opts]) => (opts as any).path.endsWith("/occurrences"))![0] as Record<string, any>;
+    expect(occurrences.params).toMatchObject({ targetId: "tgt-9", page: 0, pageSize: 50 });
+  });
+});
@@ -0,0 +1,155 @@
+/**
+ * Tests for trigger_event: webhook payload and condition inspection for an
+ * execution started by a trigger.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const TRIGGER_YAML = `trigger:
+  identifier: pr_main
+  source:
+    type: Webhook
+    spec:
+      type: Github
+      spec:
+        type: PullRequest
+        spec:
+          payloadConditions:
+            - key: targetBranch
+              operator: Equals
+              value: main
+`;
+
+function execution(triggerType: string) {
+  return {
+    status: "SUCCESS",
+    data: {
+      pipelineExecutionSummary: {
+        pipelineIdentifier: "deploy",
+        executionTriggerInfo: {
+          triggerType,
+          triggeredBy: {
+            identifier: "pr_main",
+            extraInfo: { triggerRef: "acct/default/test-project/pr_main", eventCorrelationId: "evt-1" },
+          },
+        },
+      },
+    },
+  };
+}
+
+const payload = {
+  action: "opened",
+  repository: { full_name: "acme/web" },
+  pull_request: { head: { ref: "feature/x", sha: "abc" }, base: { ref: "develop" } },
+};
+
+describe("trigger_event get", () => {
+  it("returns the payload, derived branches, and evaluated conditions", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params: Record<string, unknown> }) => {
+      if (opts.path === "/pipeline/api/pipelines/execution/v2/exec-1") return execution("WEBHOOK");
+      if (opts.path === "/pipeline/api/triggers/eventHistory/deploy") {
+        expect(opts.params.triggerIdentifier).toBe("pr_main");
+        return {
+          status: "SUCCESS",
+          data: {
+            content: [
+              { eventCorrelationId: "other", payload: "{}", targetExecutionSummary: { planExecutionId: "exec-0" } },
+              {
+                eventCorrelationId: "evt-1",
+                eventCreatedAt: 1767225600000,
+                payload: JSON.stringify(payload),
+                triggerEventStatus: { status: "SUCCESS", message: "Target execution requested" },
+                targetExecutionSummary: { planExecutionId: "exec-1" },
+              },
+            ],
+            totalElements: 2,
+          },
+        };
+      }
+      if (opts.path === "/pipeline/api/triggers/pr_main") return { status: "SUCCESS", data: { identifier: "pr_main", yaml: TRIGGER_YAML } };
+      throw new Error(`unexpected ${opts.path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "trigger_event", "get", { execution_id: "exec-1" }) as Record<string, any>;
+    expect(result).toMatchObject({
+      execution_id: "exec-1",
+      pipeline_id: "deploy",
+      trigger_id: "pr_main",
+      event: { created_at: "2026-01-01T00:00:00.000Z", status: "SUCCESS", message: "Target execution requested" },
+      webhook: { provider: "Github", source_branch: "feature/x", target_branch: "develop", commit_sha: "abc" },
+      trigger_filter: { provider: "Github", event_type: "PullRequest" },
+      all_conditions_matched: false,
+    });
+    expect(result.conditions).toEqual([
+      { kind: "payload", key: "targetBranch", operator: "Equals", value: "main", actual: "develop", matched: false },
+    ]);
+    expect(result.payload).toEqual(payload);
+    expect(result.errors).toBeUndefined();
+  });
+
+  it("returns a note for executions not started by a webhook", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (_opts: { path: string }) => execution("MANUAL"));
+    const result = await registry.dispatch(makeClient(mockRequest), "trigger_event", "get", { execution_id: "exec-1" }) as Record<string, any>;
+    expect(result.trigger_type).toBe("MANUAL");
+    expect(result.note).toContain("not started by a webhook");
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+  });
+
+  it("reports a missing event and failed trigger lookup without failing", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string }) => {
+      if (opts.path === "/pipeline/api/pipelines/execution/v2/exec-1") return execution("WEBHOOK");
+      if (opts.path === "/pipeline/api/triggers/eventHistory/deploy") return { status: "SUCCESS", data: { content: [], totalElements: 0 } };
+      throw new Error("trigger deleted");
+    });
+    const result = await registry.dispatch(makeClient(mockRequest), "trigger_event", "get", { execution_id: "exec-1" }) as Record<string, any>;
+    expect(result.payload).toBeNull();
+    expect(result.errors).toHaveLength(2);
+    expect(result.errors.join(" ")).toContain("trigger deleted");
+  });
+});
+
+describe("trigger_event list", () => {
+  it("lists event history for a pipeline's triggers", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (_opts: { path: string; params: Record<string, unknown> }) => ({ status: "SUCCESS", data: { content: [{ eventCorrelationId: "evt-1" }], totalElements: 1 } }));
+    const result = await registry.dispatch(makeClient(mockRequest), "trigger_event", "list", { pipeline_id: "deploy", trigger_id: "pr_main" }) as Record<string, any>;
+    expect(result.items).toHaveLength(1);
+    const call = mockRequest.mock.calls[0]![0] as { path: string; params: Record<string, unknown> };
+    expect(call.path).toBe("/pipeline/api/triggers/eventHistory/deploy");
+    expect(call.params.triggerIdentifier).toBe("pr_main");
+  });
+});
@@ -0,0 +1,162 @@
+/**
+ * Tests for trigger_schedule: project-wide cron trigger scan and next-run
+ * computation.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { triggerScheduleExtract } from "../../src/registry/extractors.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+function cronYaml(id: string, expression: string, extra = ""): string {
+  return [
+    "trigger:",
+    `  identifier: ${id}`,
+    "  source:",
+    "    type: Scheduled",
+    "    spec:",
+    "      type: Cron",
+    "      spec:",
+    "        type: UNIX",
+    `        expression: "${expression}"`,
+    extra,
+  ].join("\n");
+}
+
+function page(content: unknown[]) {
+  return { status: "SUCCESS", data: { content, totalElements: content.length } };
+}
+
+const FROM = "2026-03-07T12:00:00Z";
+
+describe("triggerScheduleExtract", () => {
+  const scan = {
+    pipelines_scanned: 2,
+    truncated: false,
+    errors: [],
+    triggers: [
+      { pipeline_id: "nightly", trigger: { identifier: "nightly_cron", name: "Nightly", enabled: true, yaml: cronYaml("nightly_cron", "0 1 * * *") } },
+      { pipeline_id: "deploy", trigger: { identifier: "evening", name: "Evening", enabled: true, yaml: cronYaml("evening", "0 22 * * *") } },
+      { pipeline_id: "deploy", trigger: { identifier: "off", name: "Off", enabled: false, yaml: cronYaml("off", "0 * * * *") } },
+      { pipeline_id: "deploy", trigger: { identifier: "bad", name: "Bad", enabled: true, yaml: cronYaml("bad", "0 0 15W * ?") } },
+    ],
+  };
+
+  it("merges next runs into one time-ordered schedule in the requested timezone", () => {
+    const result = triggerScheduleExtract(scan, { from: FROM, count: 2, timezone: "America/New_York" }) as Record<string, any>;
+    expect(result.schedule.map((s: any) => [s.at, s.trigger_id])).toEqual([
+      ["2026-03-07T17:00:00-05:00", "evening"],
+      ["2026-03-07T20:00:00-05:00", "nightly_cron"],
+      ["2026-03-08T18:00:00-04:00", "evening"],
+      ["2026-03-08T21:00:00-04:00", "nightly_cron"],
+    ]);
+    expect(result.items.map((i: any) => i.trigger_id)).toEqual(["evening", "nightly_cron", "bad"]);
+  });
+
+  it("limits runs to within_hours", () => {
+    const result = triggerScheduleExtract(scan, { from: FROM, within_hours: 12 }) as Record<string, any>;
+    expect(result.schedule.map((s: any) => s.trigger_id)).toEqual(["evening"]);
+    expect(result.until).toBe("2026-03-08T00:00:00Z");
+  });
+
+  it("reports unsupported expressions per trigger and skips disabled ones by default", () => {
+    const result = triggerScheduleExtract(scan, { from: FROM }) as Record<string, any>;
+    expect(result.items.find((i: any) => i.trigger_id === "bad").error).toContain("Unsupported");
+    expect(result.items.some((i: any) => i.trigger_id === "off")).toBe(false);
+
+    const withDisabled = triggerScheduleExtract(scan, { from: FROM, include_disabled: true }) as Record<string, any>;
+    expect(withDisabled.items.find((i: any) => i.trigger_id === "off")).toMatchObject({ enabled: false, next_runs: [] });
+  });
+
+  it("evaluates in the trigger's own timezone when set", () => {
+    const tzScan = {
+      ...scan,
+      triggers: [{ pipeline_id: "p", trigger: { identifier: "t", yaml: cronYaml("t", "0 9 * * *", "        timeZone: Asia/Kolkata") } }],
+    };
+    const result = triggerScheduleExtract(tzScan, { from: FROM, count: 1 }) as Record<string, any>;
+    expect(result.items[0]).toMatchObject({ trigger_timezone: "Asia/Kolkata", next_runs: ["2026-03-08T03:30:00Z"] });
+  });
+
+  it("rejects unknown display timezones", () => {
+    expect(() => triggerScheduleExtract(scan, { timezone: "Mars/Olympus" })).toThrow(/Unknown timezone/);
+  });
+});
+
+describe("trigger_schedule list", () => {
+  it("scans every pipeline in the project and lists only scheduled triggers", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params: Record<string, unknown> }) => {
+      if (opts.path === "/pipeline/api/pipelines/list") {
+        return page([{ identifier: "deploy", name: "Deploy" }, { identifier: "build", name: "Build" }]);
+      }
+      if (opts.path === "/pipeline/api/triggers" && opts.params.targetIdentifier === "deploy") {
+        return page([
+          { identifier: "evening", name: "Evening", type: "Scheduled", enabled: true, yaml: cronYaml("evening", "0 22 * * *") },
+          { identifier: "push", name: "Push", type: "Webhook", enabled: true },
+        ]);
+      }
+      if (opts.path === "/pipeline/api/triggers") return page([]);
+      throw new Error(`unexpected ${opts.path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "trigger_schedule", "list", { from: FROM, count: 1 }) as Record<string, any>;
+
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      path: "/pipeline/api/triggers",
+      params: expect.objectContaining({ orgIdentifier: "default", projectIdentifier: "test-project", targetIdentifier: "build" }),
+    }));
+    expect(result.pipelines_scanned).toBe(2);
+    expect(result.items).toEqual([
+      expect.objectContaining({ pipeline_id: "deploy", pipeline_name: "Deploy", trigger_id: "evening", next_runs: ["2026-03-07T22:00:00Z"] }),
+    ]);
+  });
+
+  it("fetches trigger details when the list omits YAML and reports per-pipeline errors", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params: Record<string, unknown> }) => {
+      if (opts.path === "/pipeline/api/triggers") {
+        return page([{ identifier: "nightly", name: "Nightly", type: "Scheduled", enabled: true }]);
+      }
+      if (opts.path === "/pipeline/api/triggers/nightly") {
+        return { data: { identifier: "nightly", yaml: cronYaml("nightly", "0 1 * * *") } };
+      }
+      throw new Error(`unexpected ${opts.path}`);
+    });
+
+    const result = await registry.dispatch(makeClient(mockRequest), "trigger_schedule", "list", { pipeline_id: "deploy", from: FROM, count: 1 }) as Record<string, any>;
+    expect(mockRequest).not.toHaveBeenCalledWith(expect.objectContaining({ path: "/pipeline/api/pipelines/list" }));
+    expect(result.items[0]).toMatchObject({ trigger_id: "nightly", next_runs: ["2026-03-08T01:00:00Z"] });
+
+    mockRequest.mockRejectedValueOnce(new Error("403 Forbidden"));
+    const failed = await registry.dispatch(makeClient(mockRequest), "trigger_schedule", "list", { pipeline_id: "deploy", from: FROM }) as Record<string, any>;
+    expect(failed.errors).toEqual([{ pipeline_id: "deploy", error: "403 Forbidden" }]);
+  });
+});
@@ -0,0 +1,195 @@
+/**
+ * Tests for the session undo log: prior-state capture on writes, the
+ * undo_log resource, and undo_last_change.
+ */
+import { describe, it, expect, vi } from "vitest";
+import { Registry } from "../../src/registry/index.js";
+import { runInConversation } from "../../src/utils/conversation-context.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "pipelines,audit",
+    HARNESS_UNDO_LOG: true,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const OLD_YAML = "pipeline:\n  identifier: deploy\n  name: deploy\n";
+const NEW_YAML = "pipeline:\n  identifier: deploy\n  name: deploy v2\n";
+
+/** Harness stand-in holding one pipeline's YAML. */
+function pipelineApi() {
+  let yaml = OLD_YAML;
+  return vi.fn(async (opts: Record<string, any>) => {
+    if (opts.method === "GET" && opts.path === "/pipeline/api/pipelines/deploy") return { data: { yamlPipeline: yaml } };
+    if (opts.method === "PUT" && opts.path === "/pipeline/api/pipelines/v2/deploy") {
+      yaml = opts.body;
+      return { data: { identifier: "deploy" } };
+    }
+    if (opts.method === "POST" && opts.path === "/pipeline/api/pipelines/v2") return { data: { identifier: "build" } };
+    if (opts.method === "DELETE") return { data: true };
+    if (opts.method === "POST" && opts.path === "/pipeline/api/pipeline/execute/deploy") return { data: { planExecution: { uuid: "exec1" } } };
+    throw new Error(`unexpected ${opts.method} ${opts.path}`);
+  });
+}
+
+describe("undo log recording", () => {
+  it("captures the YAML before an update and marks the result with the entry", async () => {
+    const registry = new Registry(makeConfig());
+    const request = pipelineApi();
+
+    const result = await registry.dispatch(makeClient(request), "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML }) as Record<string, any>;
+
+    expect(request.mock.calls.map(([opts]) => `${opts.method} ${opts.path}`)).toEqual([
+      "GET /pipeline/api/pipelines/deploy",
+      "PUT /pipeline/api/pipelines/v2/deploy",
+    ]);
+    expect(result._undo).toEqual({ entry_id: "u1", undoable: true });
+    const entry = await registry.dispatch(makeClient(request), "undo_log", "get", { entry_id: "u1" }) as Record<string, any>;
+    expect(entry).toMatchObject({
+      resource_type: "pipeline",
+      operation: "update",
+      resource_id: "deploy",
+      undo: { operation: "update", input: { pipeline_id: "deploy", body: { yamlPipeline: OLD_YAML } } },
+    });
+  });
+
+  it("still writes when the prior state cannot be read, and says why it cannot be undone", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.method === "GET") throw new Error("403 Forbidden");
+      return { data: { identifier: "deploy" } };
+    });
+
+    await registry.dispatch(makeClient(request), "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML });
+
+    const list = await registry.dispatch(makeClient(request), "undo_log", "list", {}) as Record<string, any>;
+    expect(list.items[0]).toMatchObject({ entry_id: "u1", undoable: false, not_undoable: expect.stringContaining("403 Forbidden") });
+  });
+
+  it("logs execute actions as not undoable", async () => {
+    const registry = new Registry(makeConfig());
+
+    await registry.dispatchExecute(makeClient(pipelineApi()), "pipeline", "run", { pipeline_id: "deploy" });
+
+    const list = await registry.dispatch(makeClient(vi.fn()), "undo_log", "list", {}) as Record<string, any>;
+    expect(list.items).toEqual([expect.objectContaining({ operation: "execute", action: "run", undoable: false })]);
+  });
+
+  it("is off unless HARNESS_UNDO_LOG is set", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_UNDO_LOG: false }));
+    const request = pipelineApi();
+
+    const result = await registry.dispatch(makeClient(request), "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML }) as Record<string, any>;
+
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(result._undo).toBeUndefined();
+    await expect(registry.dispatch(makeClient(request), "undo_log", "list", {})).rejects.toThrow(/HARNESS_UNDO_LOG=true/);
+  });
+
+  it("keeps a separate log per conversation", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(pipelineApi());
+
+    await runInConversation("chat-1", () => registry.dispatch(client, "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML }));
+
+    const own = await runInConversation("chat-1", () => registry.dispatch(client, "undo_log", "list", {})) as Record<string, any>;
+    const other = await runInConversation("chat-2", () => registry.dispatch(client, "undo_log", "list", {})) as Record<string, any>;
+    expect(own.total).toBe(1);
+    expect(other.total).toBe(0);
+  });
+});
+
+describe("undo_last_change", () => {
+  it("restores the YAML from before the last update without logging the restore", async () => {
+    const registry = new Registry(makeConfig());
+    const request = pipelineApi();
+    const client = makeClient(request);
+    await registry.dispatch(client, "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML });
+
+    const result = await registry.dispatchExecute(client, "undo_log", "undo_last_change", {}) as Record<string, any>;
+
+    const restore = request.mock.calls.at(-1)![0];
+    expect(restore).toMatchObject({ method: "PUT", path: "/pipeline/api/pipelines/v2/deploy", body: OLD_YAML });
+    expect(result).toMatchObject({ undone: "u1", resource_type: "pipeline", resource_id: "deploy", original_operation: "update", restored_with: "update" });
+    const list = await registry.dispatch(client, "undo_log", "list", {}) as Record<string, any>;
+    expect(list.total).toBe(0);
+  });
+
+  it("deletes an entity the session created", async () => {
+    const registry = new Registry(makeConfig());
+    const request = pipelineApi();
+    const client = makeClient(request);
+    await registry.dispatch(client, "pipeline", "create", { body: "pipeline:\n  identifier: build\n" });
+
+    await registry.dispatchExecute(client, "undo_log", "undo_last_change", {});
+
+    expect(request.mock.calls.at(-1)![0]).toMatchObject({ method: "DELETE", path: "/pipeline/api/pipelines/build" });
+  });
+
+  it("re-creates a deleted entity from the YAML read before the delete", async () => {
+    const registry = new Registry(makeConfig());
+    const request = pipelineApi();
+    const client = makeClient(request);
+    await registry.dispatch(client, "pipeline", "delete", { pipeline_id: "deploy" });
+
+    await registry.dispatchExecute(client, "undo_log", "undo_last_change", {});
+
+    expect(request.mock.calls.at(-1)![0]).toMatchObject({ method: "POST", path: "/pipeline/api/pipelines/v2", body: OLD_YAML });
+  });
+
+  it("refuses a newest change that cannot be undone and accepts an earlier entry_id", async () => {
+    const registry = new Registry(makeConfig());
+    const request = pipelineApi();
+    const client = makeClient(request);
+    await registry.dispatch(client, "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML });
+    await registry.dispatchExecute(client, "pipeline", "run", { pipeline_id: "deploy" });
+
+    await expect(registry.dispatchExecute(client, "undo_log", "undo_last_change", {}))
+      .rejects.toThrow(/Change u2 \(run on pipeline "deploy"\) cannot be undone/);
+
+    const result = await registry.dispatchExecute(client, "undo_log", "undo_last_change", { entry_id: "u1" }) as Record<string, any>;
+    expect(result.undone).toBe("u1");
+  });
+
+  it("requires force to restore an entity that changed again later", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(pipelineApi());
+    await registry.dispatch(client, "pipeline", "update", { pipeline_id: "deploy", body: NEW_YAML });
+    await registry.dispatch(client, "pipeline", "update", { pipeline_id: "deploy", body: OLD_YAML });
+
+    await expect(registry.dispatchExecute(client, "undo_log", "undo_last_change", { entry_id: "u1" }))
+      .rejects.toThrow(/changed again later \(u2\)/);
+    await expect(registry.dispatchExecute(client, "undo_log", "undo_last_change", { entry_id: "u1", force: true }))
+      .resolves.toMatchObject({ undone: "u1" });
+  });
+
+  it("is blocked in read-only mode", async () => {
+    const registry = new Registry(makeConfig({ HARNESS_READ_ONLY: true }));
+
+    await expect(registry.dispatchExecute(makeClient(vi.fn()), "undo_log", "undo_last_change", {})).rejects.toThrow(/Read-only mode/);
+  });
+});
@@ -0,0 +1,114 @@
+import { describe, expect, it } from "vitest";
+import {
+  WAITING_EXECUTION_STATUSES,
+  waitingExecutionGetExtract,
+  waitingExecutionListExtract,
+} from "../../src/registry/extractors.js";
+import { pipelinesToolset } from "../../src/registry/toolsets/pipelines.js";
+
+describe("waitingExecutionListExtract", () => {
+  it("annotates each execution with waiting stages and the unblocking call", () => {
+    const result = waitingExecutionListExtract({
+      data: {
+        totalElements: 2,
+        content: [
+          {
+            planExecutionId: "exec-1",
+            pipelineIdentifier: "deploy",
+            name: "Deploy",
+            status: "InputWaiting",
+            runSequence: 7,
+            startTs: 0,
+            layoutNodeMap: {
+              a: { nodeIdentifier: "build", name: "Build", status: "Success" },
+              b: { nodeIdentifier: "prod", name: "Prod", status: "InputWaiting" },
+            },
+          },
+          { planExecutionId: "exec-2", pipelineIdentifier: "deploy", status: "Expired" },
+        ],
+      },
+    });
+
+    expect(result.total).toBe(2);
+    const [first, second] = result.items as Array<Record<string, unknown>>;
+    expect(first).toMatchObject({
+      execution_id: "exec-1",
+      pipeline_id: "deploy",
+      status: "InputWaiting",
+      run_sequence: 7,
+      started_at: "1970-01-01T00:00:00.000Z",
+      waiting_stages: [{ stage_id: "prod", name: "Prod", status: "InputWaiting" }],
+    });
+    expect(first?.next_action).toContain("execution_input_request");
+    expect(second?.next_action).toContain("action='retry'");
+  });
+});
+
+describe("waitingExecutionGetExtract", () => {
+  it("returns waiting leaf steps with their node execution IDs", () => {
+    const result = waitingExecutionGetExtract({
+      data: {
+        pipelineExecutionSummary: { planExecutionId: "exec-1", pipelineIdentifier: "deploy", status: "InterventionWaiting" },
+        executionGraph: {
+          nodeMap: {
+            u1: { uuid: "u1", identifier: "rollout", name: "Rollout", baseFqn: "pipeline.stages.prod.spec.execution.steps.rollout", status: "InterventionWaiting", stepType: "K8sRollingDeploy" },
+            u2: { uuid: "u2", identifier: "prod", baseFqn: "pipeline.stages.prod", status: "InterventionWaiting" },
+            u3: { uuid: "u3", identifier: "sg", baseFqn: "pipeline.stages.prod.spec.execution.steps.sg", status: "InputWaiting", stepType: "STEP_GROUP" },
+            u4: { uuid: "u4", identifier: "smoke", baseFqn: "pipeline.stages.prod.spec.execution.steps.smoke", status: "Success", stepType: "Run" },
+          },
+        },
+      },
+    }) as { execution_id: string; waiting_steps: Array<Record<string, unknown>>; _hint?: string };
+
+    expect(result.execution_id).toBe("exec-1");
+    expect(result.waiting_steps).toEqual([
+      expect.objectContaining({
+        node_execution_id: "u1",
+        id: "rollout",
+        stage_id: "prod",
+        status: "InterventionWaiting",
+        step_type: "K8sRollingDeploy",
+      }),
+    ]);
+    expect(result.waiting_steps[0]?.next_action).toContain("action='intervene'");
+    expect(result._hint).toBeUndefined();
+  });
+
+  it("hints at the pipeline-level action when no step is waiting", () => {
+    const result = waitingExecutionGetExtract(
+      { data: { pipelineExecutionSummary: { status: "Paused" }, executionGraph: { nodeMap: {} } } },
+      { execution_id: "exec-9" },
+    ) as { execution_id: string; waiting_steps: unknown[]; _hint?: string };
+
+    expect(result.execution_id).toBe("exec-9");
+    expect(result.waiting_steps).toEqual([]);
+    expect(result._hint).toContain("interrupt_type: 'Resume'");
+  });
+});
+
+describe("waiting_execution resource", () => {
+  const resource = pipelinesToolset.resources.find((r) => r.resourceType === "waiting_execution");
+
+  it("lists only waiting statuses unless narrowed", () => {
+    const list = resource?.operations.list;
+    expect(list?.bodyBuilder?.({})).toEqual({ filterType: "PipelineExecution", status: WAITING_EXECUTION_STATUSES });
+    expect(list?.bodyBuilder?.({ status: "Paused" })).toEqual({ filterType: "PipelineExecution", status: ["Paused"] });
+  });
+
+  it("resume submits inputs YAML to the waiting node", () => {
+    const resume = resource?.executeActions?.resume;
+    expect(resume?.path).toBe("/pipeline/api/execution-input/{nodeExecutionId}");
+    expect(resume?.headers).toEqual({ "Content-Type": "application/yaml" });
+    expect(resume?.bodyBuilder?.({ body: "step:\n  spec: {}\n" })).toBe("step:\n  spec: {}\n");
+    expect(resume?.bodyBuilder?.({ body: { inputs_yaml: "a: 1\n" } })).toBe("a: 1\n");
+    expect(resume?.bodyBuilder?.({ body: { inputs: { step: { timeout: "10m" } } } })).toBe("step:\n  timeout: 10m\n");
+    expect(() => resume?.bodyBuilder?.({})).toThrow(/inputs_yaml is required/);
+  });
+
+  it("intervene targets the node-level manual intervention interrupt", () => {
+    const intervene = resource?.executeActions?.intervene;
+    expect(intervene?.path).toBe("/pipeline/api/pipeline/execute/manualIntervention/interrupt/{planExecutionId}/{nodeExecutionId}");
+    expect(intervene?.pathParams).toEqual({ execution_id: "planExecutionId", node_execution_id: "nodeExecutionId" });
+    expect(intervene?.queryParams).toEqual({ interrupt_type: "interruptType" });
+  });
+});
@@ -0,0 +1,20 @@
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import { registerAllResources } from "../../src/resources/index.js";
+
+function registeredUris(operator?: boolean): string[] {
+  const server = { registerResource: vi.fn() };
+  registerAllResources(server as never, {} as never, {} as never, { HARNESS_PIPELINE_VERSION: "0" } as Config, undefined, operator);
+  return server.registerResource.mock.calls.map((call) => String(call[1]));
+}
+
+describe("support:///bundle registration", () => {
+  it("is served to operator sessions", () => {
+    expect(registeredUris(true)).toContain("support:///bundle");
+  });
+
+  it("is not served to other sessions, since it exposes process-wide logs", () => {
+    expect(registeredUris(false)).not.toContain("support:///bundle");
+    expect(registeredUris()).not.toContain("support:///bundle");
+  });
+});
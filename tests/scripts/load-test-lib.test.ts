@@ -0,0 +1,110 @@
+import { describe, expect, it } from "vitest";
+import {
+  DEFAULT_TOOL_MIX,
+  classifyToolCallResponse,
+  createWeightedPicker,
+  parseMcpResponseBody,
+  parseToolMixSpec,
+  percentile,
+  rateLimitWarning,
+  summarizeSamples,
+  validateToolMix,
+} from "../../scripts/load-test-lib.mjs";
+
+describe("load-test-lib", () => {
+  it("default mix only uses registry-local tools", () => {
+    const tools = new Set(DEFAULT_TOOL_MIX.map((entry) => entry.tool));
+    expect([...tools].sort()).toEqual(["harness_describe", "harness_schema"]);
+  });
+
+  it("parses a compact tool mix spec with optional weights", () => {
+    expect(parseToolMixSpec("harness_describe:3, harness_status")).toEqual([
+      { tool: "harness_describe", weight: 3, arguments: {} },
+      { tool: "harness_status", weight: 1, arguments: {} },
+    ]);
+  });
+
+  it("rejects invalid mix specs", () => {
+    expect(() => parseToolMixSpec("")).toThrow(/empty/);
+    expect(() => parseToolMixSpec("harness_list:0")).toThrow(/weight/);
+    expect(() => parseToolMixSpec("Bad-Tool:1")).toThrow(/tool name/);
+  });
+
+  it("validates mix files and defaults weight and arguments", () => {
+    expect(validateToolMix([{ tool: "harness_list", arguments: { resource_type: "pipeline" } }])).toEqual([
+      { tool: "harness_list", weight: 1, arguments: { resource_type: "pipeline" } },
+    ]);
+    expect(() => validateToolMix([])).toThrow(/non-empty/);
+    expect(() => validateToolMix([{ weight: 1 }])).toThrow(/tool/);
+    expect(() => validateToolMix([{ tool: "harness_list", arguments: [] }])).toThrow(/non-object/);
+  });
+
+  it("picks entries proportionally to weight", () => {
+    const mix = [
+      { tool: "a", weight: 1, arguments: {} },
+      { tool: "b", weight: 3, arguments: {} },
+    ];
+    const rolls = [0, 0.24, 0.25, 0.99];
+    const pick = createWeightedPicker(mix, () => rolls.shift()!);
+    expect([pick().tool, pick().tool, pick().tool, pick().tool]).toEqual(["a", "a", "b", "b"]);
+  });
+
+  it("computes nearest-rank percentiles", () => {
+    const sorted = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10];
+    expect(percentile(sorted, 50)).toBe(5);
+    expect(percentile(sorted, 90)).toBe(9);
+    expect(percentile(sorted, 99)).toBe(10);
+    expect(percentile([], 50)).toBe(0);
+  });
+
+  it("summarizes latency, throughput, and errors overall and per tool", () => {
+    const summary = summarizeSamples(
+      [
+        { tool: "harness_describe", latencyMs: 10, ok: true },
+        { tool: "harness_describe", latencyMs: 20, ok: true },
+        { tool: "harness_list", latencyMs: 100, ok: false, errorKind: "tool_error" },
+        { tool: "harness_list", latencyMs: 50, ok: false, errorKind: "http_429" },
+      ],
+      2000,
+    );
+
+    expect(summary.total_calls).toBe(4);
+    expect(summary.errors).toBe(2);
+    expect(summary.error_rate).toBe(0.5);
+    expect(summary.throughput_rps).toBe(2);
+    expect(summary.latency_ms.p50).toBe(20);
+    expect(summary.latency_ms.max).toBe(100);
+    expect(summary.errors_by_kind).toEqual({ tool_error: 1, http_429: 1 });
+    expect(Object.keys(summary.by_tool)).toEqual(["harness_describe", "harness_list"]);
+    expect(summary.by_tool.harness_list.error_rate).toBe(1);
+    expect(summary.by_tool.harness_describe.latency_ms.mean).toBe(15);
+  });
+
+  it("warns when HTTP 429s make up most of a run", () => {
+    const summary = (calls: number, throttled: number) => ({ total_calls: calls, errors_by_kind: throttled ? { http_429: throttled } : {} });
+    expect(rateLimitWarning(summary(10, 5))).toBeUndefined();
+    expect(rateLimitWarning(summary(10, 6))).toContain("HARNESS_MCP_IP_RATE_LIMIT_PER_MIN");
+    expect(rateLimitWarning(summary(2, 0), ["initialize failed with HTTP 429", "initialize failed with HTTP 429", "initialize failed with HTTP 429"]))
+      .toContain("3 of 5 requests got HTTP 429");
+    expect(rateLimitWarning(summary(0, 0))).toBeUndefined();
+  });
+
+  it("decodes JSON and SSE response bodies", () => {
+    const json = parseMcpResponseBody('{"jsonrpc":"2.0","id":1,"result":{}}', "application/json");
+    expect(json).toEqual([{ jsonrpc: "2.0", id: 1, result: {} }]);
+
+    const sse = parseMcpResponseBody(
+      'event: message\ndata: {"jsonrpc":"2.0","id":2,"result":{"isError":true}}\n\n',
+      "text/event-stream",
+    );
+    expect(sse).toEqual([{ jsonrpc: "2.0", id: 2, result: { isError: true } }]);
+    expect(parseMcpResponseBody("", "application/json")).toEqual([]);
+  });
+
+  it("classifies tool call responses", () => {
+    expect(classifyToolCallResponse([{ id: 1, result: { content: [] } }], 1)).toBeUndefined();
+    expect(classifyToolCallResponse([{ id: 1, result: { isError: true } }], 1)).toBe("tool_error");
+    expect(classifyToolCallResponse([{ id: 1, error: { code: -32000 } }], 1)).toBe("rpc_error");
+    expect(classifyToolCallResponse([{ id: 2, result: {} }], 1)).toBe("no_response");
+  });
+});
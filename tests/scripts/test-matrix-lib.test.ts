@@ -0,0 +1,72 @@
+import { describe, expect, it } from "vitest";
+import { readFileSync } from "node:fs";
+import { join } from "node:path";
+import { parse } from "yaml";
+import {
+  checkExpectations,
+  decodeToolResult,
+  formatReport,
+  resolveArguments,
+  summarizeOutcomes,
+  validateMatrix,
+} from "../../scripts/test-matrix-lib.mjs";
+
+const okResult = (body: unknown) => ({ content: [{ type: "text", text: JSON.stringify(body) }] });
+
+describe("test-matrix-lib", () => {
+  it("accepts the checked-in sandbox matrix", () => {
+    const doc = parse(readFileSync(join(process.cwd(), "tests/matrix/sandbox.yaml"), "utf8"));
+    const { cases } = validateMatrix(doc);
+    expect(cases.length).toBeGreaterThan(0);
+  });
+
+  it("rejects write tools, duplicate names, and unknown expectations", () => {
+    const base = { name: "c", toolset: "pipelines", tool: "harness_list" };
+    expect(() => validateMatrix({ cases: [] })).toThrow(/non-empty/);
+    expect(() => validateMatrix({ cases: [{ ...base, tool: "harness_delete" }] })).toThrow(/read-only tools/);
+    expect(() => validateMatrix({ cases: [base, base] })).toThrow(/Duplicate/);
+    expect(() => validateMatrix({ cases: [{ ...base, expect: { status: "maybe" } }] })).toThrow(/expect.status/);
+    expect(() => validateMatrix({ cases: [{ ...base, expect: { has: "items" } }] })).toThrow(/field paths/);
+  });
+
+  it("resolves placeholders from vars, with MATRIX_ env overrides", () => {
+    const args = { org_id: "${ORG}", params: { ids: ["${PIPE}-1"] }, size: 5 };
+    expect(resolveArguments(args, { ORG: "default", PIPE: "p" }, { MATRIX_ORG: "sandbox" })).toEqual({
+      org_id: "sandbox",
+      params: { ids: ["p-1"] },
+      size: 5,
+    });
+    expect(() => resolveArguments({ id: "${MISSING}" }, {}, {})).toThrow(/MATRIX_MISSING/);
+  });
+
+  it("checks status, fields, and item shape", () => {
+    const decoded = decodeToolResult(okResult({ items: [{ identifier: "a" }, { name: "b" }], total: 2 }));
+    expect(checkExpectations({ status: "ok", has: ["items", "total"], min_items: 1 }, decoded)).toEqual([]);
+    expect(checkExpectations({ status: "ok", items_have: ["identifier"] }, decoded)).toEqual(["items[1] missing field identifier"]);
+    expect(checkExpectations({ status: "ok", min_items: 3 }, decoded)).toEqual(["expected at least 3 items, got 2"]);
+    expect(checkExpectations({ status: "error" }, decoded)).toEqual(["expected an error result, got success"]);
+
+    const error = decodeToolResult({ isError: true, content: [{ type: "text", text: "Project not found" }] });
+    expect(checkExpectations({ status: "error", error_match: "not found" }, error)).toEqual([]);
+    expect(checkExpectations({ status: "ok" }, error)[0]).toMatch(/expected success/);
+  });
+
+  it("rolls outcomes up per toolset, excluding skipped cases from the rate", () => {
+    const outcomes = [
+      { name: "a", toolset: "pipelines", status: "passed", failures: [] },
+      { name: "b", toolset: "pipelines", status: "failed", failures: ["missing field items"] },
+      { name: "c", toolset: "connectors", status: "passed", failures: [] },
+      { name: "d", toolset: "connectors", status: "skipped", failures: [] },
+    ];
+    const summary = summarizeOutcomes(outcomes);
+    expect(summary).toMatchObject({ passed: 2, failed: 1, skipped: 1 });
+    expect(summary.pass_rate).toBeCloseTo(2 / 3);
+    expect(summary.toolsets.map((row: { toolset: string; pass_rate: number }) => [row.toolset, row.pass_rate])).toEqual([
+      ["pipelines", 0.5],
+      ["connectors", 1],
+    ]);
+    const report = formatReport(summary, outcomes);
+    expect(report).toContain("[pipelines] b");
+    expect(report).toContain("missing field items");
+  });
+});
@@ -379,6 +379,79 @@ describe("pipelineHandler", () => {
     expect(logs["s1/step1"]).toBe("resolved log line 1\nresolved log line 2");
   });
 
+  it("adds a root_cause with category, log excerpt, and suggested fix", async () => {
+    const { resolveLogContent } = await import("../../../src/utils/log-resolver.js");
+    (resolveLogContent as ReturnType<typeof vi.fn>).mockResolvedValueOnce(
+      ["npm ci", "added 812 packages", "npm test", "  ✓ renders header", "  ✗ computes totals", "AssertionError: expected 3 to equal 4", "2 tests failed", "exit status 1"].join("\n"),
+    );
+    const exec = makeExecution({
+      status: "Failed",
+      stages: [{ id: "s1", name: "Stage1", status: "Failed", steps: [{ id: "step1", name: "Step1", status: "Failed" }] }],
+      nodeMapEntries: {
+        step1: {
+          uuid: "step1",
+          identifier: "step1",
+          name: "Step1",
+          baseFqn: "pipeline.stages.s1.spec.execution.steps.step1",
+          status: "Failed",
+          stepType: "Run",
+          failureInfo: { message: "1 error occurred: exit status 1", failureTypeList: ["APPLICATION_ERROR"] },
+          logBaseKey: "log/step1",
+        },
+      },
+    });
+
+    const ctx = makeContext({
+      input: { execution_id: "exec-001" },
+      registry: makePipelineRegistry(exec),
+      args: { summary: true, include_logs: true },
+    });
+
+    const result = await pipelineHandler.diagnose(ctx);
+
+    const rootCause = result.root_cause as Record<string, any>;
+    expect(rootCause).toMatchObject({
+      stage: "s1",
+      step: "step1",
+      step_type: "Run",
+      category: "test_failure",
+      evidence: "log",
+      error: "1 error occurred: exit status 1",
+      failure_types: ["APPLICATION_ERROR"],
+    });
+    expect(rootCause.log_excerpt.source).toBe("error_lines");
+    expect(rootCause.log_excerpt.text).toContain("AssertionError: expected 3 to equal 4");
+    expect(rootCause.suggested_fix).toMatch(/Tests failed/);
+  });
+
+  it("categorizes failures from the message alone when logs are not fetched", async () => {
+    const exec = makeExecution({
+      status: "Failed",
+      stages: [{ id: "s1", name: "Stage1", status: "Failed", steps: [{ id: "step1", name: "Step1", status: "Failed" }] }],
+      nodeMapEntries: {
+        step1: {
+          uuid: "step1",
+          identifier: "step1",
+          baseFqn: "pipeline.stages.s1.spec.execution.steps.step1",
+          status: "Failed",
+          failureInfo: { message: "Timeout: step did not finish in 10m", failureTypeList: ["TIMEOUT_ERROR"] },
+          logBaseKey: "log/step1",
+        },
+      },
+    });
+
+    const ctx = makeContext({
+      input: { execution_id: "exec-001" },
+      registry: makePipelineRegistry(exec),
+      args: { summary: true, include_logs: false },
+    });
+
+    const result = await pipelineHandler.diagnose(ctx);
+
+    expect(result.root_cause).toMatchObject({ category: "timeout", evidence: "error_message" });
+    expect((result.root_cause as Record<string, unknown>).log_excerpt).toBeUndefined();
+  });
+
   it("returns download URLs for failed steps when return_download_url is true", async () => {
     const exec = makeExecution({
       status: "Failed",
@@ -0,0 +1,124 @@
+/**
+ * Tests for harness_list all_projects: account-wide fan-out over projects with
+ * per-project errors, scope-guard handling, and continuation cursors.
+ */
+import { describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { listAcrossProjects } from "../../src/tools/list-across-projects.js";
+import { decodeContinuation } from "../../src/utils/fan-out.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "platform,services",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+const PROJECTS = [
+  { orgIdentifier: "org_b", identifier: "p1" },
+  { orgIdentifier: "org_a", identifier: "p2" },
+  { orgIdentifier: "org_a", identifier: "p1" },
+];
+
+function mockHarness(opts: { path: string; params?: Record<string, unknown> }): unknown {
+  if (opts.path === "/ng/api/projects") {
+    const projects = opts.params?.orgIdentifier
+      ? PROJECTS.filter((p) => p.orgIdentifier === opts.params?.orgIdentifier)
+      : PROJECTS;
+    return { status: "SUCCESS", data: { content: projects.map((project) => ({ project })), totalElements: projects.length } };
+  }
+  if (opts.path === "/ng/api/servicesV2") {
+    const { orgIdentifier, projectIdentifier } = opts.params ?? {};
+    if (projectIdentifier === "p2") throw new Error("403 Forbidden");
+    return { status: "SUCCESS", data: { content: [{ service: { identifier: `${orgIdentifier}-${projectIdentifier}-svc` } }], totalElements: 1 } };
+  }
+  throw new Error(`unexpected ${opts.path}`);
+}
+
+const fanOutConfig = { HARNESS_FANOUT_CONCURRENCY: 2, HARNESS_FANOUT_BUDGET_MS: 25_000 };
+
+describe("listAcrossProjects", () => {
+  it("lists in every project, tags items with their scope, and reports failing projects", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => mockHarness(opts));
+
+    const result = await listAcrossProjects(registry, makeClient(mockRequest), "service", { all_projects: true }, fanOutConfig);
+
+    expect(result).toMatchObject({ projects_scanned: 3, projects_total: 3, total: 2 });
+    expect((result.items as Array<Record<string, unknown>>).map((i) => [i.org_id, i.project_id])).toEqual([
+      ["org_a", "p1"],
+      ["org_b", "p1"],
+    ]);
+    expect(result.errors).toEqual([{ org_id: "org_a", project_id: "p2", error: "403 Forbidden" }]);
+    expect(result.next_cursor).toBeUndefined();
+  });
+
+  it("restricts enumeration to org_id when given", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => mockHarness(opts));
+
+    const result = await listAcrossProjects(registry, makeClient(mockRequest), "service", { org_id: "org_b" }, fanOutConfig);
+    expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({
+      path: "/ng/api/projects",
+      params: expect.objectContaining({ orgIdentifier: "org_b" }),
+    }));
+    expect(result.projects_total).toBe(1);
+  });
+
+  it("returns a cursor when the budget runs out and resumes from it", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => {
+      if (opts.path === "/ng/api/servicesV2") await new Promise((resolve) => setTimeout(resolve, 5));
+      return mockHarness(opts);
+    });
+    const tight = { HARNESS_FANOUT_CONCURRENCY: 1, HARNESS_FANOUT_BUDGET_MS: 0 };
+
+    const first = await listAcrossProjects(registry, makeClient(mockRequest), "service", {}, tight);
+    expect(first).toMatchObject({ partial: true, projects_scanned: 1, projects_total: 3 });
+    expect(decodeContinuation(first.next_cursor as string)).toMatchObject({ resource_type: "service", index: 1 });
+
+    const rest = await listAcrossProjects(registry, makeClient(mockRequest), "service", { cursor: first.next_cursor }, fanOutConfig);
+    expect(rest.projects_scanned).toBe(3);
+    expect(rest.partial).toBeUndefined();
+
+    await expect(
+      listAcrossProjects(registry, makeClient(mockRequest), "service", { cursor: first.next_cursor, org_id: "org_b" }, fanOutConfig),
+    ).rejects.toThrow(/different all_projects request/);
+  });
+
+  it("rejects account-scoped types and pinned sessions under scope-guard block without cross_scope", async () => {
+    const registry = new Registry(makeConfig());
+    const client = makeClient(vi.fn(async (opts: { path: string; params?: Record<string, unknown> }) => mockHarness(opts)));
+
+    await expect(listAcrossProjects(registry, client, "organization", {}, fanOutConfig)).rejects.toThrow(/project-scoped/);
+    await expect(
+      listAcrossProjects(registry, client, "service", {}, { ...fanOutConfig, HARNESS_SCOPE_GUARD: "block" }),
+    ).rejects.toThrow(/cross_scope: true/);
+    await expect(
+      listAcrossProjects(registry, client, "service", { cross_scope: true }, { ...fanOutConfig, HARNESS_SCOPE_GUARD: "block" }),
+    ).resolves.toMatchObject({ projects_scanned: 3 });
+  });
+});
@@ -0,0 +1,131 @@
+/**
+ * Tests for harness_list export: every page of a listing streamed to a JSONL
+ * file, with only the path and counts returned.
+ */
+import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
+import { mkdtempSync, readdirSync, readFileSync, rmSync } from "node:fs";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { exportListToJsonl } from "../../src/tools/list-export.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "services",
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (opts: Record<string, any>) => Promise<unknown>): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+/** A services listing of `count` items served `size` at a time. */
+function servicePages(count: number, reportTotal = true) {
+  return vi.fn(async (opts: Record<string, any>) => {
+    const page = Number(opts.params?.page ?? 0);
+    const size = Number(opts.params?.size ?? 20);
+    const content = Array.from({ length: Math.max(0, Math.min(size, count - page * size)) }, (_, i) => ({
+      service: { identifier: `svc_${page * size + i}` },
+    }));
+    return { data: { content, ...(reportTotal ? { totalElements: count } : {}) } };
+  });
+}
+
+const NOW = () => new Date("2026-03-01T12:00:00.000Z");
+
+describe("exportListToJsonl", () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), "harness-export-"));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it("writes every page to one JSONL file and returns only the path and counts", async () => {
+    const registry = new Registry(makeConfig());
+    const request = servicePages(250);
+    const progress: Array<[number, number | undefined]> = [];
+
+    const result = await exportListToJsonl(registry, makeClient(request), "service", { page: 4 }, {
+      outputDir: dir,
+      now: NOW,
+      onProgress: (exported, total) => { progress.push([exported, total]); },
+    });
+
+    expect(result).toMatchObject({ resource_type: "service", format: "jsonl", exported: 250, pages: 3, total: 250 });
+    expect(result).not.toHaveProperty("items");
+    expect(result.file).toBe(join(dir, "harness-service-2026-03-01T12-00-00-000Z.jsonl"));
+    const lines = readFileSync(result.file, "utf8").trimEnd().split("\n");
+    expect(lines).toHaveLength(250);
+    expect(JSON.parse(lines[249]!)).toMatchObject({ service: { identifier: "svc_249" } });
+    expect(request.mock.calls.map(([opts]) => opts.params.page)).toEqual([0, 1, 2]);
+    expect(progress).toEqual([[100, 250], [200, 250], [250, 250]]);
+    expect(readdirSync(dir)).toEqual(["harness-service-2026-03-01T12-00-00-000Z.jsonl"]);
+  });
+
+  it("pages until a short page when the API reports no total", async () => {
+    const registry = new Registry(makeConfig());
+    const request = servicePages(30, false);
+
+    const result = await exportListToJsonl(registry, makeClient(request), "service", { size: 10 }, { outputDir: dir, now: NOW });
+
+    expect(result).toMatchObject({ exported: 30, pages: 3 });
+    expect(result).not.toHaveProperty("total");
+    expect(request).toHaveBeenCalledTimes(4);
+  });
+
+  it("stops when the API ignores page and repeats the first page", async () => {
+    const registry = new Registry(makeConfig());
+    const request = vi.fn(async () => ({
+      data: { content: Array.from({ length: 100 }, (_, i) => ({ service: { identifier: `svc_${i}` } })) },
+    }));
+
+    const result = await exportListToJsonl(registry, makeClient(request), "service", {}, { outputDir: dir, now: NOW });
+
+    expect(result).toMatchObject({ exported: 100, pages: 1 });
+    expect(request).toHaveBeenCalledTimes(2);
+  });
+
+  it("removes the partial file when a page fails", async () => {
+    const registry = new Registry(makeConfig());
+    const pages = servicePages(250);
+    const request = vi.fn(async (opts: Record<string, any>) => {
+      if (opts.params?.page === 1) throw new Error("upstream unavailable");
+      return pages(opts);
+    });
+
+    await expect(exportListToJsonl(registry, makeClient(request), "service", {}, { outputDir: dir, now: NOW }))
+      .rejects.toThrow("upstream unavailable");
+    expect(readdirSync(dir)).toEqual([]);
+  });
+
+  it("rejects a relative output_dir", async () => {
+    const registry = new Registry(makeConfig());
+
+    await expect(exportListToJsonl(registry, makeClient(servicePages(1)), "service", {}, { outputDir: "exports" }))
+      .rejects.toThrow("output_dir must be an absolute path");
+  });
+});
@@ -482,6 +482,23 @@ describe("harness_get — execution_log", () => {
     expect(resolveLogContentMock).toHaveBeenCalledWith(client, "acct1/pipeline/my-pipe/42/-exec-123");
   });
 
+  it("caps long log content at max_chars with a truncation marker", async () => {
+    resolveLogContentMock.mockResolvedValueOnce(Array.from({ length: 500 }, (_, i) => `step output ${i}`).join("\n"));
+    const result = await server.call("harness_get", {
+      resource_type: "execution_log",
+      resource_id: "exec-123",
+      params: { step_id: "run_tests", max_chars: 1000 },
+    });
+    expect(result.isError).toBeUndefined();
+    const data = parseResult(result) as Record<string, any>;
+    expect(data.log_content.length).toBeLessThanOrEqual(1100);
+    expect(data.log_content).toContain("step output 0");
+    expect(data.log_content).toContain("step output 499");
+    expect(data.log_content).toContain("lines truncated");
+    expect(data).toMatchObject({ truncated: true, total_lines: 500, log_key: "acct1/pipeline/my-pipe/42/-exec-123" });
+    expect(buildLogPrefixMock).toHaveBeenCalledWith(client, registry, "exec-123", expect.objectContaining({ step_id: "run_tests" }));
+  });
+
   it("does not override explicit execution_id with resource_id", async () => {
     const result = await server.call("harness_get", {
       resource_type: "execution_log",
@@ -828,6 +845,24 @@ describe("harness_create", () => {
     expect(callArgs.body).toBeInstanceOf(FormData);
   });
 
+  it("confirms a medium_write create through the server as wrapped by registerAllTools", async () => {
+    registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "file_store" }));
+    mockRequest = vi.fn().mockResolvedValue({ data: { identifier: "script-file" } });
+    client = makeClient(mockRequest);
+    const wrappedServer = makeMcpServer("accept");
+    const { registerAllTools } = await import("../../src/tools/index.js");
+    registerAllTools(wrappedServer, registry, client, makeConfig());
+
+    const result = await wrappedServer.call("harness_create", {
+      resource_type: "file_store",
+      body: { name: "script.sh", type: "FILE", parent_identifier: "Root", content_base64: Buffer.from("echo hi").toString("base64") },
+    });
+
+    expect(result.isError).toBeUndefined();
+    expect(wrappedServer.server.elicitInput).toHaveBeenCalledOnce();
+    expect(mockRequest).toHaveBeenCalledOnce();
+  });
+
   it("redacts File Store upload content from create confirmation prompts", async () => {
     registry = new Registry(makeConfig({ HARNESS_TOOLSETS: "file_store" }));
     mockRequest = vi.fn().mockResolvedValue({ data: { identifier: "script-file" } });
@@ -1555,6 +1590,22 @@ describe("harness_execute", () => {
     expect(mockRequest).toHaveBeenCalled();
   });
 
+  it("returns execution_id and an execution deep link for pipeline run without wait", async () => {
+    const result = await server.call("harness_execute", {
+      resource_type: "pipeline",
+      action: "run",
+      resource_id: "my-pipe",
+      org_id: "myorg",
+      project_id: "myproj",
+    });
+    expect(result.isError).toBeUndefined();
+    const data = parseResult(result) as { execution_id?: string; execution_url?: string };
+    expect(data.execution_id).toBe("exec-123");
+    expect(data.execution_url).toBe(
+      "https://app.harness.io/ng/account/test-account/all/orgs/myorg/projects/myproj/pipelines/my-pipe/deployments/exec-123/pipeline",
+    );
+  });
+
   it("passes pipeline_branch from params as ?pipelineBranchName= query param", async () => {
     await server.call("harness_execute", {
       resource_type: "pipeline",
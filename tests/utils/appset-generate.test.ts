@@ -0,0 +1,137 @@
+import { describe, it, expect } from "vitest";
+import { decodeJsonField, matchesLabelSelector, renderApplicationSet } from "../../src/utils/appset-generate.js";
+
+const encode = (value: unknown) => ({ raw: Buffer.from(JSON.stringify(value), "utf-8").toString("base64") });
+
+const clusters = [
+  { name: "Prod_East", server: "https://east", labels: { env: "prod", region: "us" }, annotations: {} },
+  { name: "staging", server: "https://staging", labels: { env: "staging", region: "eu" }, annotations: {} },
+];
+
+describe("decodeJsonField", () => {
+  it("decodes the API's base64 JSON encoding and passes plain values through", () => {
+    expect(decodeJsonField(encode({ env: "dev" }))).toEqual({ env: "dev" });
+    expect(decodeJsonField({ env: "dev" })).toEqual({ env: "dev" });
+    expect(decodeJsonField({ raw: "%%%" })).toEqual({ raw: "%%%" });
+  });
+});
+
+describe("matchesLabelSelector", () => {
+  it("applies matchLabels and matchExpressions together", () => {
+    const labels = { env: "prod", region: "us" };
+    expect(matchesLabelSelector(labels, undefined)).toBe(true);
+    expect(matchesLabelSelector(labels, { matchLabels: { env: "prod" } })).toBe(true);
+    expect(matchesLabelSelector(labels, { matchLabels: { env: "dev" } })).toBe(false);
+    expect(matchesLabelSelector(labels, { matchExpressions: [{ key: "region", operator: "In", values: ["us", "ca"] }] })).toBe(true);
+    expect(matchesLabelSelector(labels, { matchExpressions: [{ key: "region", operator: "NotIn", values: ["us"] }] })).toBe(false);
+    expect(matchesLabelSelector(labels, { matchExpressions: [{ key: "canary", operator: "DoesNotExist" }] })).toBe(true);
+    expect(matchesLabelSelector(labels, { matchExpressions: [{ key: "canary", operator: "Exists" }] })).toBe(false);
+  });
+});
+
+describe("renderApplicationSet", () => {
+  it("renders a list generator with Go templates, including encoded elements", () => {
+    const result = renderApplicationSet({
+      spec: {
+        goTemplate: true,
+        generators: [{ list: { elements: [encode({ env: "dev" }), { env: "Prod" }] } }],
+        template: {
+          metadata: { name: "web-{{ .env | lower }}" },
+          spec: { destination: { namespace: "{{ .env }}" }, source: { targetRevision: "{{ .revision | default \"HEAD\" }}" } },
+        },
+      },
+    });
+
+    expect(result.applications).toEqual([
+      { metadata: { name: "web-dev" }, spec: { destination: { namespace: "dev" }, source: { targetRevision: "HEAD" } } },
+      { metadata: { name: "web-prod" }, spec: { destination: { namespace: "Prod" }, source: { targetRevision: "HEAD" } } },
+    ]);
+    expect(result.warnings).toEqual([]);
+  });
+
+  it("renders a clusters generator with the legacy template syntax", () => {
+    const result = renderApplicationSet({
+      spec: {
+        generators: [{ clusters: { selector: { matchLabels: { env: "prod" } }, values: { tier: "gold" } } }],
+        template: { metadata: { name: "{{nameNormalized}}-{{values.tier}}" }, spec: { destination: { server: "{{server}}" } } },
+      },
+    }, clusters);
+
+    expect(result.applications).toEqual([{ metadata: { name: "prod-east-gold" }, spec: { destination: { server: "https://east" } } }]);
+  });
+
+  it("takes the cartesian product for a matrix and joins on mergeKeys for a merge", () => {
+    const matrix = renderApplicationSet({
+      spec: {
+        goTemplate: true,
+        generators: [{
+          matrix: {
+            generators: [
+              { clusters: {} },
+              { list: { elements: [{ app: "api" }, { app: "web" }] } },
+            ],
+          },
+        }],
+        template: { metadata: { name: "{{.app}}-{{.name}}" } },
+      },
+    }, clusters);
+    expect(matrix.applications.map((a: any) => a.metadata.name)).toEqual(["api-Prod_East", "web-Prod_East", "api-staging", "web-staging"]);
+
+    const merge = renderApplicationSet({
+      spec: {
+        goTemplate: true,
+        generators: [{
+          merge: {
+            mergeKeys: ["server"],
+            generators: [
+              { clusters: {} },
+              { list: { elements: [{ server: "https://staging", replicas: "3" }] } },
+            ],
+          },
+        }],
+        template: { metadata: { name: "{{.nameNormalized}}" }, spec: { replicas: "{{.replicas | default \"1\"}}" } },
+      },
+    }, clusters);
+    expect(merge.applications.map((a: any) => a.spec.replicas)).toEqual(["1", "3"]);
+  });
+
+  it("reports generators that need data the preview cannot read", () => {
+    const result = renderApplicationSet({
+      spec: {
+        generators: [
+          { git: { repoURL: "https://github.com/org/repo" } },
+          { matrix: { generators: [{ list: { elements: [{ a: 1 }] } }, { pullRequest: { github: {} } }] } },
+        ],
+        template: {},
+      },
+    });
+
+    expect(result.applications).toEqual([]);
+    expect(result.unrendered.map((u) => u.generator)).toEqual(["git", "pullRequest"]);
+  });
+
+  it("warns about missing keys and unsupported template features", () => {
+    const result = renderApplicationSet({
+      spec: {
+        goTemplate: true,
+        templatePatch: "spec: {}",
+        generators: [{ list: { elements: [{ env: "dev" }] } }],
+        template: { metadata: { name: "{{ .missing }}", labels: { team: "{{ printf \"%s\" .env }}" } } },
+      },
+    });
+
+    expect(result.applications[0]).toEqual({ metadata: { name: "<no value>", labels: { team: "{{ printf \"%s\" .env }}" } } });
+    expect(result.warnings).toHaveLength(3);
+  });
+
+  it("deep-merges a generator's template override", () => {
+    const result = renderApplicationSet({
+      spec: {
+        generators: [{ list: { elements: [{ env: "dev" }] }, template: { spec: { project: "sandbox" } } }],
+        template: { metadata: { name: "{{env}}" }, spec: { project: "default", destination: { namespace: "{{env}}" } } },
+      },
+    });
+
+    expect(result.applications[0]).toEqual({ metadata: { name: "dev" }, spec: { project: "sandbox", destination: { namespace: "dev" } } });
+  });
+});
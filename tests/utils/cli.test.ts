@@ -45,6 +45,12 @@ describe("parseArgs", () => {
     expect(args.transport).toBe("http");
   });
 
+  it("parses sse transport", () => {
+    const args = parseArgs(["sse", "--port", "8080"]);
+    expect(args.transport).toBe("sse");
+    expect(args.port).toBe(8080);
+  });
+
   it("parses stdio transport explicitly", () => {
     const args = parseArgs(["stdio"]);
     expect(args.transport).toBe("stdio");
@@ -118,4 +124,31 @@ describe("parseArgs", () => {
     const args = parseArgs(["--port", "65535"]);
     expect(args.port).toBe(65535);
   });
+
+  it("serves by default", () => {
+    expect(parseArgs(["http"]).command).toBe("serve");
+  });
+
+  it("parses the support-bundle subcommand with --output", () => {
+    const args = parseArgs(["support-bundle", "--output", "/tmp/bundles", "--env-file=/tmp/harness.env"]);
+    expect(args.command).toBe("support-bundle");
+    expect(args.outputDir).toBe("/tmp/bundles");
+    expect(args.envFile).toBe("/tmp/harness.env");
+  });
+});
+
+describe("parseArgs run-playbook", () => {
+  it("reads the playbook path and repeatable --var flags", () => {
+    const args = parseArgs(["run-playbook", "--var", "project_id=payments", "review.yaml", "--var=limit=5", "--env-file", ".env.prod"]);
+    expect(args).toMatchObject({
+      command: "run-playbook",
+      playbookFile: "review.yaml",
+      playbookVars: ["project_id=payments", "limit=5"],
+      envFile: ".env.prod",
+    });
+  });
+
+  it("requires a playbook path", () => {
+    expect(() => parseArgs(["run-playbook"])).toThrow(/playbook file/);
+  });
 });
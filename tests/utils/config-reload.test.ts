@@ -0,0 +1,77 @@
+import { mkdtempSync, rmSync, writeFileSync } from "node:fs";
+import { join } from "node:path";
+import { tmpdir } from "node:os";
+import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
+import { loadConfig, type Config } from "../../src/config.js";
+import { ConfigReloader, planConfigReload } from "../../src/utils/config-reload.js";
+
+const KEYS = ["HARNESS_API_KEY", "LOG_LEVEL", "HARNESS_TOOLSETS", "HARNESS_BASE_URL", "HARNESS_TOOL_RATE_LIMIT_PER_MIN"];
+let tempDir: string;
+let envPath: string;
+let saved: Record<string, string | undefined>;
+
+beforeEach(() => {
+  saved = Object.fromEntries(KEYS.map((key) => [key, process.env[key]]));
+  for (const key of KEYS) delete process.env[key];
+  process.env.HARNESS_API_KEY = "pat.acct123.tok.sec";
+  tempDir = mkdtempSync(join(tmpdir(), "harness-reload-"));
+  envPath = join(tempDir, ".env");
+  writeFileSync(envPath, "");
+});
+
+afterEach(() => {
+  for (const [key, value] of Object.entries(saved)) {
+    if (value === undefined) delete process.env[key];
+    else process.env[key] = value;
+  }
+  rmSync(tempDir, { recursive: true, force: true });
+});
+
+describe("planConfigReload", () => {
+  it("applies reloadable settings by scope and keeps the rest for a restart", () => {
+    const current = { LOG_LEVEL: "info", HARNESS_TOOLSETS: undefined, HARNESS_API_KEY: "pat.a.b.c", HARNESS_READ_ONLY: false } as unknown as Config;
+    const next = { LOG_LEVEL: "debug", HARNESS_TOOLSETS: "pipelines", HARNESS_API_KEY: "pat.x.y.z", HARNESS_READ_ONLY: false } as unknown as Config;
+
+    const { config, result } = planConfigReload(current, next);
+
+    expect(result).toEqual({
+      applied: { live: ["LOG_LEVEL"], new_sessions: ["HARNESS_TOOLSETS"] },
+      restart_required: ["HARNESS_API_KEY"],
+    });
+    expect(config).toMatchObject({ LOG_LEVEL: "debug", HARNESS_TOOLSETS: "pipelines", HARNESS_API_KEY: "pat.a.b.c" });
+  });
+});
+
+describe("ConfigReloader", () => {
+  it("re-reads the env file, notifies listeners, and swaps the current config", () => {
+    const reloader = new ConfigReloader(loadConfig(), envPath);
+    const listener = vi.fn();
+    reloader.onReload(listener);
+    writeFileSync(envPath, "LOG_LEVEL=warn\nHARNESS_TOOLSETS=pipelines\nHARNESS_BASE_URL=https://eu.harness.io\nHARNESS_TOOL_RATE_LIMIT_PER_MIN=30\n");
+
+    const result = reloader.reload();
+
+    expect(result).toEqual({
+      applied: { live: ["HARNESS_TOOL_RATE_LIMIT_PER_MIN", "LOG_LEVEL"], new_sessions: ["HARNESS_TOOLSETS"] },
+      restart_required: ["HARNESS_BASE_URL"],
+    });
+    expect(reloader.current).toMatchObject({ LOG_LEVEL: "warn", HARNESS_TOOLSETS: "pipelines", HARNESS_BASE_URL: "https://app.harness.io" });
+    expect(listener).toHaveBeenCalledWith(reloader.current);
+  });
+
+  it("keeps the running config when the new one is invalid or a listener rejects it", () => {
+    const initial = loadConfig();
+    const reloader = new ConfigReloader(initial, envPath);
+
+    writeFileSync(envPath, "LOG_LEVEL=loud\n");
+    expect(() => reloader.reload()).toThrow(/Invalid configuration/);
+    expect(reloader.current).toBe(initial);
+
+    writeFileSync(envPath, "LOG_LEVEL=debug\n");
+    reloader.onReload(() => {
+      throw new Error("bad result processors");
+    });
+    expect(() => reloader.reload()).toThrow("bad result processors");
+    expect(reloader.current).toBe(initial);
+  });
+});
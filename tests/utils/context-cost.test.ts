@@ -0,0 +1,74 @@
+import { afterEach, describe, expect, it, vi } from "vitest";
+import {
+  configureContextCost,
+  recordToolResult,
+  renderContextCostMetrics,
+  resetContextCost,
+  summarizeContextCost,
+  withContextCost,
+} from "../../src/utils/context-cost.js";
+import { errorResult, jsonResult } from "../../src/utils/response-formatter.js";
+
+afterEach(() => resetContextCost());
+
+const text = (chars: number) => ({ content: [{ type: "text" as const, text: "x".repeat(chars) }] });
+
+describe("context cost", () => {
+  it("estimates tokens per tool and resource type, most expensive first", () => {
+    recordToolResult("harness_list", "pipeline", text(4000));
+    recordToolResult("harness_list", "pipeline", text(2000));
+    recordToolResult("harness_list", "connector", text(400));
+    recordToolResult("harness_get", "pipeline", text(401));
+
+    const report = summarizeContextCost();
+    expect(report.chars_per_token).toBe(4);
+    expect(report.est_total_tokens).toBe(1000 + 500 + 100 + 101);
+    expect(report.tools.map((t) => t.tool)).toEqual(["harness_list", "harness_get"]);
+    expect(report.tools[0]).toMatchObject({ samples: 3, avg_tokens: 533, max_tokens: 1000, est_total_tokens: 1600, share_pct: 94.1 });
+    expect(report.tools[0]!.top_resource_types).toEqual([
+      { resource_type: "pipeline", samples: 2, avg_tokens: 750, max_tokens: 1000, est_total_tokens: 1500 },
+      { resource_type: "connector", samples: 1, avg_tokens: 100, max_tokens: 100, est_total_tokens: 100 },
+    ]);
+    expect(report.tools[1]).toMatchObject({ est_total_tokens: 101, errors: 0 });
+  });
+
+  it("samples calls and scales totals back up", () => {
+    configureContextCost({ sampleRate: 0.5 });
+    const random = vi.fn().mockReturnValueOnce(0.2).mockReturnValueOnce(0.7);
+    recordToolResult("harness_list", undefined, text(400), random);
+    recordToolResult("harness_list", undefined, text(400), random);
+
+    const [tool] = summarizeContextCost().tools;
+    expect(tool).toMatchObject({ samples: 1, avg_tokens: 100, est_total_tokens: 200 });
+    expect(renderContextCostMetrics()).toContain("harness_mcp_context_cost_sample_rate 0.5");
+  });
+
+  it("records nothing at sample rate 0", () => {
+    configureContextCost({ sampleRate: 0 });
+    recordToolResult("harness_list", "pipeline", text(400));
+    expect(summarizeContextCost().tools).toEqual([]);
+  });
+
+  it("exports Prometheus counters per tool", () => {
+    recordToolResult("harness_get", "pipeline", errorResult("boom"));
+    const metrics = renderContextCostMetrics();
+    expect(metrics).toMatch(/harness_mcp_tool_result_tokens_total\{tool="harness_get"\} \d+/);
+    expect(metrics).toContain('harness_mcp_tool_result_samples_total{tool="harness_get"} 1');
+    expect(summarizeContextCost().tools[0]!.errors).toBe(1);
+  });
+
+  it("measures results of tools registered through withContextCost", async () => {
+    const server = { registerTool: vi.fn() };
+    withContextCost(server as never).registerTool("harness_get", {} as never, (async () => jsonResult({ id: "p1" })) as never);
+
+    expect(server.registerTool).toHaveBeenCalledOnce();
+    const [name, , callback] = server.registerTool.mock.calls[0]!;
+    expect(name).toBe("harness_get");
+    const result = await callback({ resource_type: "pipeline" }, {});
+    expect(result).toEqual(jsonResult({ id: "p1" }));
+
+    const [tool] = summarizeContextCost().tools;
+    expect(tool).toMatchObject({ tool: "harness_get", samples: 1, avg_tokens: Math.ceil('{"id":"p1"}'.length / 4) });
+    expect(tool!.top_resource_types[0]!.resource_type).toBe("pipeline");
+  });
+});
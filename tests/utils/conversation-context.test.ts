@@ -0,0 +1,79 @@
+import { describe, it, expect } from "vitest";
+import type { Transport } from "@modelcontextprotocol/sdk/shared/transport.js";
+import {
+  conversationScopedKey,
+  extractConversationId,
+  getConversationId,
+  isolateConversations,
+  runInConversation,
+} from "../../src/utils/conversation-context.js";
+
+describe("extractConversationId", () => {
+  it("reads the namespaced _meta key first", () => {
+    const message = { params: { _meta: { "harness/conversation_id": "chat-1", conversation_id: "other" } } };
+    expect(extractConversationId(message)).toBe("chat-1");
+  });
+
+  it("falls back to conversation_id and conversationId", () => {
+    expect(extractConversationId({ params: { _meta: { conversation_id: " chat-2 " } } })).toBe("chat-2");
+    expect(extractConversationId({ params: { _meta: { conversationId: "chat-3" } } })).toBe("chat-3");
+  });
+
+  it("returns undefined without _meta or for non-string values", () => {
+    expect(extractConversationId({ params: {} })).toBeUndefined();
+    expect(extractConversationId({ params: { _meta: { conversation_id: 42 } } })).toBeUndefined();
+    expect(extractConversationId("not a message")).toBeUndefined();
+  });
+
+  it("caps overly long IDs", () => {
+    const id = extractConversationId({ params: { _meta: { conversation_id: "x".repeat(500) } } });
+    expect(id).toHaveLength(200);
+  });
+});
+
+describe("runInConversation", () => {
+  it("scopes keys to the active conversation", () => {
+    expect(conversationScopedKey("k")).toBe("k");
+    runInConversation("chat-a", () => {
+      expect(getConversationId()).toBe("chat-a");
+      expect(conversationScopedKey("k")).toBe("conv:chat-a|k");
+    });
+    expect(getConversationId()).toBeUndefined();
+  });
+
+  it("propagates across awaits", async () => {
+    const seen = await runInConversation("chat-b", async () => {
+      await new Promise((resolve) => setTimeout(resolve, 1));
+      return getConversationId();
+    });
+    expect(seen).toBe("chat-b");
+  });
+
+  it("clears an outer conversation when no ID is given", () => {
+    runInConversation("chat-a", () => {
+      runInConversation(undefined, () => {
+        expect(getConversationId()).toBeUndefined();
+      });
+    });
+  });
+});
+
+describe("isolateConversations", () => {
+  it("runs each inbound message inside its conversation context", () => {
+    const seen: Array<string | undefined> = [];
+    const transport = {
+      start: async () => {},
+      send: async () => {},
+      close: async () => {},
+      onmessage: () => {
+        seen.push(getConversationId());
+      },
+    } as unknown as Transport;
+
+    isolateConversations(transport);
+    transport.onmessage?.({ jsonrpc: "2.0", id: 1, method: "tools/call", params: { _meta: { conversation_id: "chat-a" } } });
+    transport.onmessage?.({ jsonrpc: "2.0", id: 2, method: "tools/call", params: {} });
+
+    expect(seen).toEqual(["chat-a", undefined]);
+  });
+});
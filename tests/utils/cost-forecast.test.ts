@@ -0,0 +1,44 @@
+import { describe, it, expect } from "vitest";
+import { linearForecast } from "../../src/utils/cost-forecast.js";
+
+/** 100, 102, 104, … — a perfectly linear history. */
+const linear = Array.from({ length: 30 }, (_, t) => 100 + 2 * t);
+
+/** Linear history with alternating noise of ±10. */
+const noisy = linear.map((v, t) => v + (t % 2 === 0 ? 10 : -10));
+
+describe("linearForecast", () => {
+  it("projects an exact trend with zero-width bounds", () => {
+    const fit = linearForecast(linear, 10, 80, [4, 6]);
+
+    expect(fit).toMatchObject({ slope_per_day: 2, intercept: 100, r_squared: 1, residual_sd: 0 });
+    expect(fit.daily[0]).toEqual({ value: 160, lower: 160, upper: 160 });
+    expect(fit.total).toEqual({ value: 1690, lower: 1690, upper: 1690 });
+    expect(fit.periods.map((p) => p.value)).toEqual([652, 1038]);
+  });
+
+  it("widens the bounds with the noise, the distance ahead, and the confidence level", () => {
+    const at80 = linearForecast(noisy, 30, 80);
+    const at95 = linearForecast(noisy, 30, 95);
+
+    expect(at80.total.lower).toBeLessThan(at80.total.value);
+    expect(at80.total.upper).toBeGreaterThan(at80.total.value);
+    const width = (b: { lower: number; upper: number }) => b.upper - b.lower;
+    expect(width(at80.daily[29]!)).toBeGreaterThan(width(at80.daily[0]!));
+    expect(width(at95.total)).toBeGreaterThan(width(at80.total));
+  });
+
+  it("never forecasts negative spend", () => {
+    const falling = Array.from({ length: 10 }, (_, t) => 100 - 10 * t);
+
+    const fit = linearForecast(falling, 20);
+
+    expect(fit.daily.at(-1)).toEqual({ value: 0, lower: 0, upper: 0 });
+    expect(fit.total.lower).toBeGreaterThanOrEqual(0);
+  });
+
+  it("rejects too little history and unsupported confidence levels", () => {
+    expect(() => linearForecast([1, 2], 5)).toThrow(/at least 3 days/);
+    expect(() => linearForecast(linear, 5, 99)).toThrow(/80, 90, 95/);
+  });
+});
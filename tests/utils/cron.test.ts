@@ -0,0 +1,73 @@
+import { describe, expect, it } from "vitest";
+import { formatInTimeZone, isValidTimeZone, nextCronRuns, parseCron } from "../../src/utils/cron.js";
+
+const FROM = new Date("2026-03-07T12:00:00Z"); // Saturday; US DST starts 2026-03-08
+
+function runs(expression: string, timeZone = "UTC", count = 3): string[] {
+  return nextCronRuns(parseCron(expression), FROM, count, timeZone).map((r) => formatInTimeZone(r, timeZone));
+}
+
+describe("parseCron", () => {
+  it("infers the dialect from the field count", () => {
+    expect(parseCron("0 8 * * *").dialect).toBe("UNIX");
+    expect(parseCron("0 0 8 ? * MON").dialect).toBe("QUARTZ");
+  });
+
+  it("maps day-of-week numbering per dialect to 0 = Sunday", () => {
+    expect([...parseCron("0 0 * * 7").daysOfWeek]).toEqual([0]);
+    expect([...parseCron("0 0 0 ? * 1").daysOfWeek]).toEqual([0]);
+  });
+
+  it("rejects unsupported syntax and wrong field counts", () => {
+    expect(() => parseCron("0 0 15W * ?")).toThrow(/Unsupported cron syntax "15W"/);
+    expect(() => parseCron("0 0 ? * 6#3")).toThrow(/Unsupported/);
+    expect(() => parseCron("0 0 *")).toThrow(/expects 5 fields/);
+    expect(() => parseCron("61 * * * *")).toThrow(/Invalid minute value/);
+  });
+});
+
+describe("nextCronRuns", () => {
+  it("handles steps, ranges, and day names", () => {
+    expect(runs("*/15 9 * * MON-FRI")).toEqual([
+      "2026-03-09T09:00:00Z",
+      "2026-03-09T09:15:00Z",
+      "2026-03-09T09:30:00Z",
+    ]);
+  });
+
+  it("evaluates in the trigger timezone across a DST change", () => {
+    expect(runs("0 20 * * *", "America/New_York")).toEqual([
+      "2026-03-07T20:00:00-05:00",
+      "2026-03-08T20:00:00-04:00",
+      "2026-03-09T20:00:00-04:00",
+    ]);
+    // 02:30 does not exist on 2026-03-08 in New York.
+    expect(runs("30 2 * * *", "America/New_York", 1)).toEqual(["2026-03-09T02:30:00-04:00"]);
+  });
+
+  it("supports L and QUARTZ expressions", () => {
+    expect(runs("0 0 L * *", "UTC", 2)).toEqual(["2026-03-31T00:00:00Z", "2026-04-30T00:00:00Z"]);
+    expect(runs("0 0 12 ? * SUN *", "UTC", 1)).toEqual(["2026-03-08T12:00:00Z"]);
+  });
+
+  it("fires when either day field matches for UNIX", () => {
+    expect(runs("0 0 1,15 * 1", "UTC", 2)).toEqual(["2026-03-09T00:00:00Z", "2026-03-15T00:00:00Z"]);
+  });
+
+  it("stops at until and returns nothing for impossible dates", () => {
+    expect(nextCronRuns(parseCron("@hourly"), FROM, 10, "UTC", new Date("2026-03-07T14:30:00Z"))).toHaveLength(2);
+    expect(nextCronRuns(parseCron("0 0 30 2 *"), FROM, 1)).toEqual([]);
+  });
+});
+
+describe("formatInTimeZone", () => {
+  it("renders offsets, including half-hour zones", () => {
+    expect(formatInTimeZone(new Date("2026-03-09T12:00:00Z"), "Asia/Kolkata")).toBe("2026-03-09T17:30:00+05:30");
+    expect(formatInTimeZone(new Date("2026-03-09T12:00:00Z"), "UTC")).toBe("2026-03-09T12:00:00Z");
+  });
+
+  it("validates timezone names", () => {
+    expect(isValidTimeZone("Europe/Berlin")).toBe(true);
+    expect(isValidTimeZone("Mars/Olympus")).toBe(false);
+  });
+});
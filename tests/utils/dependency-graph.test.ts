@@ -0,0 +1,74 @@
+import { describe, it, expect } from "vitest";
+import { blastRadius, buildDependencyGraph, type GraphComponent } from "../../src/utils/dependency-graph.js";
+
+const EXPRESS = "pkg:npm/express@4.18.0";
+const BODY_PARSER = "pkg:npm/body-parser@1.20.1";
+const QS = "pkg:npm/qs@6.11.0";
+const AXIOS = "pkg:npm/axios@1.6.0";
+const FOLLOW = "pkg:npm/follow-redirects@1.15.0";
+
+const components: GraphComponent[] = [
+  { id: EXPRESS, name: "express", version: "4.18.0" },
+  { id: BODY_PARSER, name: "body-parser", version: "1.20.1" },
+  { id: QS, name: "qs", version: "6.11.0", vulnerabilities: 2 },
+  { id: AXIOS, name: "axios", version: "1.6.0" },
+  { id: FOLLOW, name: "follow-redirects", version: "1.15.0" },
+  { id: "pkg:npm/orphan@1.0.0", name: "orphan", version: "1.0.0" },
+];
+
+function graph() {
+  return buildDependencyGraph("art-1", components, new Set([EXPRESS, AXIOS, QS]), new Map([
+    [EXPRESS, [
+      { name: "body-parser", version: "1.20.1", purl: BODY_PARSER, relationship: "DIRECT" },
+      { name: "qs", version: "6.11.0", purl: QS, relationship: "INDIRECT", relationship_path: [BODY_PARSER] },
+    ]],
+    [AXIOS, [{ name: "follow-redirects", version: "1.15.0", purl: FOLLOW, relationship: "DIRECT" }]],
+    [QS, []],
+  ]));
+}
+
+describe("buildDependencyGraph", () => {
+  it("roots direct edges at the artifact and hangs tree rows off their relationship path", () => {
+    const g = graph();
+
+    expect(g.edges).toEqual([
+      { from: "art-1", to: EXPRESS, type: "direct" },
+      { from: "art-1", to: AXIOS, type: "direct" },
+      { from: "art-1", to: QS, type: "direct" },
+      { from: EXPRESS, to: BODY_PARSER, type: "transitive" },
+      { from: BODY_PARSER, to: QS, type: "transitive" },
+      { from: AXIOS, to: FOLLOW, type: "transitive" },
+    ]);
+    expect(g.nodes.filter((n) => n.direct).map((n) => n.name)).toEqual(["express", "qs", "axios"]);
+    expect(g.nodes.find((n) => n.name === "orphan")).toBeDefined();
+  });
+
+  it("adds components that appear only in a tree, resolving name@version path hops", () => {
+    const g = buildDependencyGraph("art-1", [{ id: EXPRESS, name: "express", version: "4.18.0" }], new Set([EXPRESS]), new Map([
+      [EXPRESS, [
+        { name: "debug", version: "2.6.9", relationship: "DIRECT" },
+        { name: "ms", version: "2.0.0", relationship: "INDIRECT", relationship_path: "express@4.18.0 -> debug@2.6.9" },
+      ]],
+    ]));
+
+    expect(g.nodes.map((n) => n.id)).toEqual([EXPRESS, "debug@2.6.9", "ms@2.0.0"]);
+    expect(g.edges).toContainEqual({ from: "debug@2.6.9", to: "ms@2.0.0", type: "transitive" });
+  });
+});
+
+describe("blastRadius", () => {
+  it("finds every chain into the component and the direct dependencies that introduce it", () => {
+    const radius = blastRadius(graph(), QS);
+
+    expect(radius.found).toBe(true);
+    expect(radius.introduced_by).toEqual([EXPRESS, QS]);
+    expect(radius.dependents.sort()).toEqual([BODY_PARSER, EXPRESS].sort());
+    expect(radius.paths).toEqual([[QS], [EXPRESS, BODY_PARSER, QS]]);
+  });
+
+  it("matches a version-less purl or a bare name, and reports misses", () => {
+    expect(blastRadius(graph(), "pkg:npm/follow-redirects").introduced_by).toEqual([AXIOS]);
+    expect(blastRadius(graph(), "body-parser").paths).toEqual([[EXPRESS, BODY_PARSER]]);
+    expect(blastRadius(graph(), "pkg:npm/left-pad")).toEqual({ target: "pkg:npm/left-pad", found: false, introduced_by: [], dependents: [], paths: [] });
+  });
+});
@@ -0,0 +1,47 @@
+import { beforeEach, describe, expect, it } from "vitest";
+import {
+  getDeprecatedUsage,
+  migrationHint,
+  recordDeprecatedUsage,
+  resetDeprecatedUsage,
+  summarizeDeprecatedUsage,
+} from "../../src/utils/deprecation-tracker.js";
+
+describe("deprecation-tracker", () => {
+  beforeEach(() => resetDeprecatedUsage());
+
+  it("counts uses per name and source", () => {
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents", "HARNESS_TOOLSETS");
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents", "bearer-token");
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents", "bearer-token");
+
+    const rows = getDeprecatedUsage();
+    expect(rows).toHaveLength(2);
+    expect(rows.find((r) => r.client === "bearer-token")?.count).toBe(2);
+    expect(rows.find((r) => r.client === "HARNESS_TOOLSETS")?.count).toBe(1);
+  });
+
+  it("defaults the source to unknown", () => {
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents");
+    expect(getDeprecatedUsage()[0]?.client).toBe("unknown");
+  });
+
+  it("summarizes who still uses what, busiest name first", () => {
+    recordDeprecatedUsage("toolset", "old-widgets", "widgets", "HARNESS_TOOLSETS");
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents", "bearer-token");
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents", "bearer-token");
+    recordDeprecatedUsage("toolset", "agent-pipelines", "agents", "HARNESS_TOOLSETS");
+
+    const report = summarizeDeprecatedUsage();
+    expect(report.total_calls).toBe(4);
+    expect(report.names.map((n) => [n.name, n.total])).toEqual([["agent-pipelines", 3], ["old-widgets", 1]]);
+    expect(report.names[0]?.clients.map((c) => [c.client, c.count])).toEqual([["bearer-token", 2], ["HARNESS_TOOLSETS", 1]]);
+    expect(report.names[0]?.hint).toContain('Use "agents"');
+  });
+
+  it("builds a migration hint naming the replacement", () => {
+    expect(migrationHint("agent-pipelines", "agents")).toBe(
+      'Toolset "agent-pipelines" is deprecated and will be removed. Use "agents" in HARNESS_TOOLSETS or the toolsets claim instead.',
+    );
+  });
+});
@@ -3,15 +3,20 @@ import { mkdtempSync, rmSync, writeFileSync } from "node:fs";
 import { join } from "node:path";
 import { tmpdir } from "node:os";
 import { afterEach, describe, expect, it, vi } from "vitest";
-import { loadEnvFile } from "../../src/utils/env.js";
+import { loadEnvFile, reloadEnvFile } from "../../src/utils/env.js";
 
 const envKey = "HARNESS_TEST_DOTENV_QUIET";
+const removedKey = "HARNESS_TEST_DOTENV_REMOVED";
+// Set before any .env file is loaded, so it counts as the real environment.
+const inheritedKey = "HARNESS_TEST_DOTENV_INHERITED";
+process.env[inheritedKey] = "from-environment";
 let tempDir: string | undefined;
 const originalCwd = cwd();
 
 afterEach(() => {
   chdir(originalCwd);
   delete process.env[envKey];
+  delete process.env[removedKey];
   if (tempDir) {
     rmSync(tempDir, { recursive: true, force: true });
     tempDir = undefined;
@@ -44,3 +49,20 @@ describe("loadEnvFile", () => {
     expect(stdoutSpy).not.toHaveBeenCalled();
   });
 });
+
+describe("reloadEnvFile", () => {
+  it("applies changed values, unsets removed ones, and never overrides the real environment", () => {
+    tempDir = mkdtempSync(join(tmpdir(), "harness-env-"));
+    const envPath = join(tempDir, ".env");
+    writeFileSync(envPath, `${envKey}=first\n${removedKey}=gone-soon\n${inheritedKey}=from-file\n`);
+    loadEnvFile(envPath);
+    expect(process.env[removedKey]).toBe("gone-soon");
+
+    writeFileSync(envPath, `${envKey}=second\n${inheritedKey}=from-file\n`);
+    reloadEnvFile(envPath);
+
+    expect(process.env[envKey]).toBe("second");
+    expect(process.env[removedKey]).toBeUndefined();
+    expect(process.env[inheritedKey]).toBe("from-environment");
+  });
+});
@@ -126,6 +126,12 @@ describe("toMcpError", () => {
     expect(result.message).toContain("abc-123");
   });
 
+  it("appends remediation steps for known error signatures", () => {
+    const result = toMcpError(new HarnessApiError("User is not authorized to access this resource", 403));
+    expect(result.message).toContain("Remediation (The principal lacks permission for this action):");
+    expect(result.data).toMatchObject({ remediation: { id: "rbac-forbidden" } });
+  });
+
   it("wraps plain Error as InternalError", () => {
     const result = toMcpError(new Error("oops"));
     expect(result).toBeInstanceOf(McpError);
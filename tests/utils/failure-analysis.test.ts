@@ -0,0 +1,55 @@
+import { describe, expect, it } from "vitest";
+import { analyzeFailure, extractErrorExcerpt } from "../../src/utils/failure-analysis.js";
+
+describe("extractErrorExcerpt", () => {
+  it("keeps windows around error lines and marks gaps", () => {
+    const lines = Array.from({ length: 30 }, (_, i) => `line ${i}`);
+    lines[5] = "ERROR: first problem";
+    lines[25] = "fatal: second problem";
+    const excerpt = extractErrorExcerpt(lines.join("\n"), { context: 1 });
+    expect(excerpt.source).toBe("error_lines");
+    expect(excerpt.total_lines).toBe(30);
+    expect(excerpt.text).toBe(["line 4", "ERROR: first problem", "line 6", "...", "line 24", "fatal: second problem", "line 26"].join("\n"));
+  });
+
+  it("keeps the windows nearest the end when over maxLines", () => {
+    const text = ["error one", "ok", "ok", "ok", "error two"].join("\n");
+    expect(extractErrorExcerpt(text, { context: 0, maxLines: 1 }).text).toBe("error two");
+  });
+
+  it("falls back to the tail when no line looks like an error", () => {
+    const text = Array.from({ length: 50 }, (_, i) => `step ${i}`).join("\n");
+    const excerpt = extractErrorExcerpt(text);
+    expect(excerpt.source).toBe("tail");
+    expect(excerpt.text.split("\n")).toHaveLength(20);
+    expect(excerpt.text.endsWith("step 49")).toBe(true);
+  });
+});
+
+describe("analyzeFailure", () => {
+  const step = { stage: "build", step: "docker_push" };
+
+  it("prefers a specific message pattern over the failure type", () => {
+    const result = analyzeFailure({ ...step, failure_message: "Back-off pulling image: ErrImagePull", failure_types: ["APPLICATION_ERROR"] });
+    expect(result).toMatchObject({ category: "image_pull", evidence: "error_message" });
+  });
+
+  it("uses the Harness failure type when the message is generic", () => {
+    const result = analyzeFailure({ ...step, failure_message: "Step failed", failure_types: ["CONNECTIVITY_ERROR"] });
+    expect(result).toMatchObject({ category: "connectivity", evidence: "failure_type", failure_types: ["CONNECTIVITY_ERROR"] });
+  });
+
+  it("reads the log before treating a non-zero exit as a script failure", () => {
+    const log = "go build ./...\nmain.go:12:2: cannot find module providing package example.com/x\nexit status 1";
+    const result = analyzeFailure({ ...step, failure_message: "exit status 1", failure_types: ["APPLICATION_ERROR"] }, log);
+    expect(result).toMatchObject({ category: "dependency", evidence: "log" });
+  });
+
+  it("falls back to script, then unknown", () => {
+    expect(analyzeFailure({ ...step, failure_message: "exit status 2" }).category).toBe("script");
+    const unknown = analyzeFailure({ ...step, failure_message: "Something happened", failure_types: ["UNKNOWN"] });
+    expect(unknown).toMatchObject({ category: "unknown", evidence: "none" });
+    expect(unknown.failure_types).toBeUndefined();
+    expect(unknown.suggested_fix).toMatch(/execution_log/);
+  });
+});
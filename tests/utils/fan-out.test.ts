@@ -0,0 +1,59 @@
+import { describe, expect, it } from "vitest";
+import { decodeContinuation, encodeContinuation, fanOut } from "../../src/utils/fan-out.js";
+
+const sleep = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
+
+describe("fanOut", () => {
+  it("never exceeds the concurrency cap and keeps results in item order", async () => {
+    let inFlight = 0;
+    let peak = 0;
+    const result = await fanOut([5, 1, 4, 2, 3], async (n) => {
+      inFlight++;
+      peak = Math.max(peak, inFlight);
+      await sleep(n);
+      inFlight--;
+      return n * 10;
+    }, { concurrency: 2 });
+
+    expect(peak).toBe(2);
+    expect(result.results.map((r) => r.value)).toEqual([50, 10, 40, 20, 30]);
+    expect(result.processed).toBe(5);
+    expect(result.nextIndex).toBeUndefined();
+  });
+
+  it("collects failures without stopping", async () => {
+    const result = await fanOut(["a", "b", "c"], async (s) => {
+      if (s === "b") throw new Error("403 Forbidden");
+      return s;
+    }, { concurrency: 3 });
+    expect(result.results.map((r) => r.item)).toEqual(["a", "c"]);
+    expect(result.errors).toEqual([{ item: "b", error: "403 Forbidden" }]);
+  });
+
+  it("returns a contiguous partial result and resumes from nextIndex when the budget runs out", async () => {
+    const items = Array.from({ length: 10 }, (_, i) => i);
+    const worker = async (i: number) => { await sleep(20); return i; };
+
+    const first = await fanOut(items, worker, { concurrency: 2, budgetMs: 30 });
+    expect(first.nextIndex).toBeGreaterThan(0);
+    expect(first.nextIndex).toBeLessThan(10);
+    expect(first.results.map((r) => r.value)).toEqual(items.slice(0, first.nextIndex));
+
+    const rest = await fanOut(items, worker, { concurrency: 10, startIndex: first.nextIndex });
+    expect([...first.results, ...rest.results].map((r) => r.value)).toEqual(items);
+  });
+
+  it("always makes progress even with an exhausted budget", async () => {
+    const result = await fanOut([1, 2, 3], async (n) => n, { concurrency: 1, budgetMs: 0 });
+    expect(result.processed).toBe(1);
+    expect(result.nextIndex).toBe(1);
+  });
+});
+
+describe("continuation tokens", () => {
+  it("round-trips state and rejects garbage", () => {
+    const token = encodeContinuation({ resource_type: "pipeline", index: 7 });
+    expect(decodeContinuation(token)).toEqual({ resource_type: "pipeline", index: 7 });
+    expect(() => decodeContinuation("not-a-token")).toThrow(/Invalid continuation token/);
+  });
+});
@@ -1,10 +1,12 @@
 import express from "express";
 import { describe, expect, it, vi } from "vitest";
+import { createHmac } from "node:crypto";
 import { request as httpRequest } from "node:http";
 import type { AddressInfo } from "node:net";
 import {
   createHttpAuthMiddleware,
   isAuthorizedHttpRequest,
+  isOperatorRequest,
   validateHttpAuthForBindHost,
 } from "../../src/utils/http-auth.js";
 
@@ -79,6 +81,26 @@ describe("HTTP MCP auth", () => {
     expect(isAuthorizedHttpRequest({ authorization: "Bearer secret-token" }, "secret-token")).toBe(true);
   });
 
+  it("accepts bearer JWTs signed with the entitlements secret", () => {
+    const secret = "entitlements-secret-0123456789abcdef";
+    const header = Buffer.from(JSON.stringify({ alg: "HS256", typ: "JWT" })).toString("base64url");
+    const payload = Buffer.from(JSON.stringify({ toolsets: ["pipelines"], exp: Math.floor(Date.now() / 1000) + 3600 })).toString("base64url");
+    const signature = createHmac("sha256", secret).update(`${header}.${payload}`).digest("base64url");
+    const jwt = `${header}.${payload}.${signature}`;
+
+    expect(isAuthorizedHttpRequest({ authorization: `Bearer ${jwt}` }, undefined, secret)).toBe(true);
+    expect(isAuthorizedHttpRequest({ authorization: `Bearer ${jwt}` }, "secret-token", secret)).toBe(true);
+    expect(isAuthorizedHttpRequest({ authorization: "Bearer secret-token" }, "secret-token", secret)).toBe(true);
+    expect(isAuthorizedHttpRequest({ authorization: "Bearer forged.token.value" }, undefined, secret)).toBe(false);
+    expect(isAuthorizedHttpRequest({}, undefined, secret)).toBe(false);
+  });
+
+  it("treats only the static bearer token as an operator", () => {
+    expect(isOperatorRequest({ authorization: "Bearer secret-token" }, "secret-token")).toBe(true);
+    expect(isOperatorRequest({ authorization: "Bearer wrong" }, "secret-token")).toBe(false);
+    expect(isOperatorRequest({}, undefined)).toBe(false);
+  });
+
   it("rejects unauthenticated MCP routes before handlers run", async () => {
     const app = express();
     app.use(createHttpAuthMiddleware("secret-token"));
@@ -136,6 +158,18 @@ describe("HTTP MCP auth", () => {
     ).not.toThrow();
   });
 
+  it("treats an entitlements secret as HTTP auth for non-loopback binds", () => {
+    expect(() =>
+      validateHttpAuthForBindHost("0.0.0.0", {
+        HARNESS_MCP_AUTH_TOKEN: undefined,
+        HARNESS_MCP_ENTITLEMENTS_SECRET: "entitlements-secret-0123456789abcdef",
+        HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP: false,
+        HARNESS_MCP_MODE: "multi-user",
+        HARNESS_API_KEY: "",
+      }),
+    ).not.toThrow();
+  });
+
   it("warns for loopback single-user with no auth token (reverse-proxy risk)", () => {
     const warnSpy = vi.spyOn(console, "error");
 
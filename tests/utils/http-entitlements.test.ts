@@ -0,0 +1,83 @@
+import { createHmac } from "node:crypto";
+import { describe, expect, it } from "vitest";
+import {
+  InvalidEntitlementsError,
+  MAX_TOKEN_LIFETIME_SECONDS,
+  hasValidEntitlementToken,
+  parseSessionEntitlements,
+  verifyEntitlementToken,
+} from "../../src/utils/http-entitlements.js";
+
+const SECRET = "entitlements-secret-0123456789abcdef";
+
+/** Sign `claims`, expiring in an hour unless they set `exp` (pass `exp: undefined` to omit it). */
+function sign(claims: Record<string, unknown>, secret = SECRET, alg = "HS256"): string {
+  const header = Buffer.from(JSON.stringify({ alg, typ: "JWT" })).toString("base64url");
+  const payload = Buffer.from(JSON.stringify({ exp: Math.floor(Date.now() / 1000) + 3600, ...claims })).toString("base64url");
+  const signature = createHmac("sha256", secret).update(`${header}.${payload}`).digest("base64url");
+  return `${header}.${payload}.${signature}`;
+}
+
+function bearer(token: string) {
+  return { authorization: `Bearer ${token}` };
+}
+
+describe("verifyEntitlementToken", () => {
+  it("returns claims for a token signed with the secret", () => {
+    expect(verifyEntitlementToken(sign({ toolsets: ["pipelines"] }), SECRET)).toMatchObject({ toolsets: ["pipelines"] });
+  });
+
+  it("rejects wrong secrets, other algorithms, and non-JWT strings", () => {
+    expect(verifyEntitlementToken(sign({ toolsets: ["pipelines"] }, "another-secret-0123456789abcdefgh"), SECRET)).toBeUndefined();
+    expect(verifyEntitlementToken(sign({ toolsets: ["pipelines"] }, SECRET, "none"), SECRET)).toBeUndefined();
+    expect(verifyEntitlementToken("static-token", SECRET)).toBeUndefined();
+  });
+
+  it("enforces exp and nbf with clock skew", () => {
+    const now = 1_700_000_000;
+    expect(verifyEntitlementToken(sign({ exp: now - 10 }), SECRET, now)).toBeDefined();
+    expect(verifyEntitlementToken(sign({ exp: now - 60 }), SECRET, now)).toBeUndefined();
+    expect(verifyEntitlementToken(sign({ exp: now + 300, nbf: now + 60 }), SECRET, now)).toBeUndefined();
+  });
+
+  it("rejects tokens without a numeric exp", () => {
+    expect(verifyEntitlementToken(sign({ toolsets: ["pipelines"], exp: undefined }), SECRET)).toBeUndefined();
+    expect(verifyEntitlementToken(sign({ toolsets: ["pipelines"], exp: "never" }), SECRET)).toBeUndefined();
+  });
+
+  it("rejects tokens that claim a lifetime over 24 hours", () => {
+    const now = 1_700_000_000;
+    const day = MAX_TOKEN_LIFETIME_SECONDS;
+    expect(verifyEntitlementToken(sign({ iat: now, exp: now + day }), SECRET, now)).toBeDefined();
+    expect(verifyEntitlementToken(sign({ iat: now, exp: now + day + 1 }), SECRET, now)).toBeUndefined();
+    expect(verifyEntitlementToken(sign({ iat: now - day, exp: now + 60 }), SECRET, now)).toBeUndefined();
+    expect(verifyEntitlementToken(sign({ exp: now + 365 * day }), SECRET, now)).toBeUndefined();
+  });
+});
+
+describe("parseSessionEntitlements", () => {
+  it("returns the toolsets claim for a signed bearer token", () => {
+    const entitled = parseSessionEntitlements(bearer(sign({ toolsets: ["pipelines", " services "] })), SECRET);
+    expect(entitled).toEqual(new Set(["pipelines", "services"]));
+  });
+
+  it("leaves sessions unrestricted without a secret or a signed token", () => {
+    expect(parseSessionEntitlements(bearer(sign({ toolsets: ["pipelines"] })), undefined)).toBeUndefined();
+    expect(parseSessionEntitlements(bearer("static-token"), SECRET)).toBeUndefined();
+    expect(parseSessionEntitlements({}, SECRET)).toBeUndefined();
+  });
+
+  it("rejects signed tokens without a usable toolsets claim", () => {
+    expect(() => parseSessionEntitlements(bearer(sign({ sub: "bot" })), SECRET)).toThrow(InvalidEntitlementsError);
+    expect(() => parseSessionEntitlements(bearer(sign({ toolsets: [] })), SECRET)).toThrow(/toolsets/);
+    expect(() => parseSessionEntitlements(bearer(sign({ toolsets: [1] })), SECRET)).toThrow(/toolsets/);
+  });
+});
+
+describe("hasValidEntitlementToken", () => {
+  it("only accepts bearer tokens verified against the configured secret", () => {
+    expect(hasValidEntitlementToken(bearer(sign({ toolsets: ["pipelines"] })), SECRET)).toBe(true);
+    expect(hasValidEntitlementToken(bearer(sign({ toolsets: ["pipelines"] })), undefined)).toBe(false);
+    expect(hasValidEntitlementToken({ authorization: `Basic ${sign({})}` }, SECRET)).toBe(false);
+  });
+});
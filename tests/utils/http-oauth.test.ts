@@ -0,0 +1,195 @@
+import express from "express";
+import { describe, expect, it, vi } from "vitest";
+import type { AddressInfo } from "node:net";
+import { createHttpAuthMiddleware, getOAuthPrincipal, validateHttpAuthForBindHost } from "../../src/utils/http-auth.js";
+import {
+  TokenIntrospector,
+  buildAuthorizationServerMetadata,
+  buildProtectedResourceMetadata,
+  buildWwwAuthenticate,
+  mapClaimsToPrincipal,
+} from "../../src/utils/http-oauth.js";
+
+const oauthConfig = {
+  HARNESS_MCP_OAUTH_ISSUER: "https://app.harness.io/oidc",
+  HARNESS_MCP_OAUTH_RESOURCE: "https://mcp.example.com/mcp",
+  HARNESS_MCP_OAUTH_CLIENT_ID: "mcp-server",
+  HARNESS_MCP_OAUTH_CLIENT_SECRET: "s3cret",
+  HARNESS_MCP_OAUTH_SCOPES: "openid harness",
+};
+
+const activeClaims = {
+  active: true,
+  iss: "https://app.harness.io/oidc",
+  aud: ["https://mcp.example.com/mcp"],
+  sub: "user-123",
+  email: "dev@example.com",
+  client_id: "claude-desktop",
+  scope: "openid harness",
+  accountId: "acct-1",
+  exp: Math.floor(Date.now() / 1000) + 3600,
+};
+
+function jsonResponse(body: unknown, status = 200): Response {
+  return new Response(JSON.stringify(body), { status, headers: { "Content-Type": "application/json" } });
+}
+
+function fakeIssuer(claims: Record<string, unknown> = activeClaims) {
+  return vi.fn(async (url: string | URL | Request, _init?: RequestInit) => {
+    const href = String(url);
+    if (href.endsWith("/.well-known/openid-configuration")) {
+      return jsonResponse({
+        issuer: "https://app.harness.io/oidc",
+        authorization_endpoint: "https://app.harness.io/oidc/authorize",
+        token_endpoint: "https://app.harness.io/oidc/token",
+        registration_endpoint: "https://app.harness.io/oidc/register",
+        introspection_endpoint: "https://app.harness.io/oidc/introspect",
+      });
+    }
+    if (href === "https://app.harness.io/oidc/introspect") return jsonResponse(claims);
+    return jsonResponse({}, 404);
+  });
+}
+
+describe("mapClaimsToPrincipal", () => {
+  it("maps subject, account, scopes, and toolsets", () => {
+    const principal = mapClaimsToPrincipal({ ...activeClaims, toolsets: ["pipelines", " logs "] }, "tok", oauthConfig);
+    expect(principal).toMatchObject({
+      subject: "user-123",
+      email: "dev@example.com",
+      clientId: "claude-desktop",
+      accountId: "acct-1",
+      scopes: ["openid", "harness"],
+      token: "tok",
+    });
+    expect([...principal!.toolsets!]).toEqual(["pipelines", "logs"]);
+  });
+
+  it("rejects inactive, foreign-audience, foreign-issuer, expired, and malformed-toolset tokens", () => {
+    expect(mapClaimsToPrincipal({ ...activeClaims, active: false }, "tok", oauthConfig)).toBeUndefined();
+    expect(mapClaimsToPrincipal({ ...activeClaims, aud: "https://other.example.com" }, "tok", oauthConfig)).toBeUndefined();
+    expect(mapClaimsToPrincipal({ ...activeClaims, iss: "https://evil.example.com" }, "tok", oauthConfig)).toBeUndefined();
+    expect(mapClaimsToPrincipal({ ...activeClaims, exp: 1 }, "tok", oauthConfig)).toBeUndefined();
+    expect(mapClaimsToPrincipal({ ...activeClaims, toolsets: "pipelines" }, "tok", oauthConfig)).toBeUndefined();
+  });
+});
+
+describe("TokenIntrospector", () => {
+  it("discovers the introspection endpoint and authenticates with client credentials", async () => {
+    const fetchFn = fakeIssuer();
+    const introspector = new TokenIntrospector(oauthConfig, fetchFn as unknown as typeof fetch);
+
+    const principal = await introspector.introspect("access-token");
+    expect(principal?.subject).toBe("user-123");
+
+    const [url, init] = fetchFn.mock.calls.find(([u]) => String(u).endsWith("/introspect"))!;
+    expect(String(url)).toBe("https://app.harness.io/oidc/introspect");
+    expect(init?.body).toBe("token=access-token&token_type_hint=access_token");
+    expect((init?.headers as Record<string, string>).Authorization).toBe(
+      `Basic ${Buffer.from("mcp-server:s3cret").toString("base64")}`,
+    );
+  });
+
+  it("caches results per token", async () => {
+    const fetchFn = fakeIssuer();
+    const introspector = new TokenIntrospector(
+      { ...oauthConfig, HARNESS_MCP_OAUTH_INTROSPECTION_URL: "https://app.harness.io/oidc/introspect" },
+      fetchFn as unknown as typeof fetch,
+    );
+
+    await introspector.introspect("access-token");
+    await introspector.introspect("access-token");
+    expect(fetchFn).toHaveBeenCalledTimes(1);
+  });
+
+  it("treats an unreachable authorization server as unauthenticated", async () => {
+    const fetchFn = vi.fn(async () => { throw new Error("ECONNREFUSED"); });
+    const introspector = new TokenIntrospector(oauthConfig, fetchFn as unknown as typeof fetch);
+    await expect(introspector.introspect("access-token")).resolves.toBeUndefined();
+  });
+});
+
+describe("OAuth metadata", () => {
+  it("advertises the issuer as authorization server for this resource", () => {
+    expect(buildProtectedResourceMetadata(oauthConfig)).toMatchObject({
+      resource: "https://mcp.example.com/mcp",
+      authorization_servers: ["https://app.harness.io/oidc"],
+      scopes_supported: ["openid", "harness"],
+    });
+    expect(buildWwwAuthenticate(oauthConfig, "invalid_token")).toBe(
+      'Bearer resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource", error="invalid_token"',
+    );
+  });
+
+  it("mirrors the issuer's registration endpoint and defaults PKCE to S256", async () => {
+    const introspector = new TokenIntrospector(oauthConfig, fakeIssuer() as unknown as typeof fetch);
+    const metadata = await buildAuthorizationServerMetadata(oauthConfig, introspector);
+    expect(metadata).toMatchObject({
+      issuer: "https://app.harness.io/oidc",
+      registration_endpoint: "https://app.harness.io/oidc/register",
+      code_challenge_methods_supported: ["S256"],
+    });
+  });
+});
+
+describe("createHttpAuthMiddleware with OAuth", () => {
+  async function get(baseUrl: string, path: string, authorization?: string) {
+    const res = await fetch(new URL(path, baseUrl), { headers: authorization ? { Authorization: authorization } : {} });
+    return { status: res.status, wwwAuthenticate: res.headers.get("www-authenticate"), body: await res.json() };
+  }
+
+  it("challenges anonymous requests and attaches the principal for valid tokens", async () => {
+    const introspector = new TokenIntrospector(oauthConfig, fakeIssuer() as unknown as typeof fetch);
+    const app = express();
+    app.use(createHttpAuthMiddleware("static-token", undefined, { introspector, config: oauthConfig }));
+    app.get("/.well-known/oauth-protected-resource", (_req, res) => res.json({ ok: true }));
+    app.get("/mcp", (_req, res) => res.json({ subject: getOAuthPrincipal(res.locals)?.subject ?? null }));
+
+    const server = app.listen(0, "127.0.0.1");
+    await new Promise<void>((resolve) => server.once("listening", resolve));
+    const baseUrl = `http://127.0.0.1:${(server.address() as AddressInfo).port}`;
+    try {
+      const anonymous = await get(baseUrl, "/mcp");
+      expect(anonymous.status).toBe(401);
+      expect(anonymous.wwwAuthenticate).toBe(
+        'Bearer resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"',
+      );
+
+      expect((await get(baseUrl, "/.well-known/oauth-protected-resource")).status).toBe(200);
+      expect((await get(baseUrl, "/mcp", "Bearer static-token")).body).toEqual({ subject: null });
+      expect((await get(baseUrl, "/mcp", "Bearer access-token")).body).toEqual({ subject: "user-123" });
+    } finally {
+      await new Promise<void>((resolve) => server.close(() => resolve()));
+    }
+  });
+
+  it("flags a rejected bearer as invalid_token", async () => {
+    const introspector = new TokenIntrospector(oauthConfig, fakeIssuer({ active: false }) as unknown as typeof fetch);
+    const app = express();
+    app.use(createHttpAuthMiddleware(undefined, undefined, { introspector, config: oauthConfig }));
+    app.get("/mcp", (_req, res) => res.json({ ok: true }));
+
+    const server = app.listen(0, "127.0.0.1");
+    await new Promise<void>((resolve) => server.once("listening", resolve));
+    const baseUrl = `http://127.0.0.1:${(server.address() as AddressInfo).port}`;
+    try {
+      const rejected = await get(baseUrl, "/mcp", "Bearer revoked");
+      expect(rejected.status).toBe(401);
+      expect(rejected.wwwAuthenticate).toContain('error="invalid_token"');
+    } finally {
+      await new Promise<void>((resolve) => server.close(() => resolve()));
+    }
+  });
+
+  it("counts OAuth as HTTP auth for non-loopback binds", () => {
+    expect(() =>
+      validateHttpAuthForBindHost("0.0.0.0", {
+        HARNESS_MCP_AUTH_TOKEN: undefined,
+        HARNESS_MCP_ALLOW_UNAUTHENTICATED_HTTP: false,
+        HARNESS_MCP_MODE: "multi-user",
+        HARNESS_API_KEY: "",
+        HARNESS_MCP_OAUTH_ISSUER: "https://app.harness.io/oidc",
+      }),
+    ).not.toThrow();
+  });
+});
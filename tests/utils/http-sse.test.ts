@@ -0,0 +1,72 @@
+import { EventEmitter } from "node:events";
+import type { ServerResponse } from "node:http";
+import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
+import { legacySseSessionId, startSseHeartbeat } from "../../src/utils/http-sse.js";
+
+function fakeResponse() {
+  const emitter = new EventEmitter();
+  const res = Object.assign(emitter, {
+    writableEnded: false,
+    destroyed: false,
+    write: vi.fn(),
+  });
+  return res as unknown as ServerResponse & { write: ReturnType<typeof vi.fn>; writableEnded: boolean };
+}
+
+describe("startSseHeartbeat", () => {
+  beforeEach(() => vi.useFakeTimers());
+  afterEach(() => vi.useRealTimers());
+
+  it("writes an SSE comment on each interval", () => {
+    const res = fakeResponse();
+    startSseHeartbeat(res, 1000);
+    vi.advanceTimersByTime(3000);
+    expect(res.write).toHaveBeenCalledTimes(3);
+    expect(res.write).toHaveBeenCalledWith(": heartbeat\n\n");
+  });
+
+  it("stops when the response closes", () => {
+    const res = fakeResponse();
+    startSseHeartbeat(res, 1000);
+    vi.advanceTimersByTime(1000);
+    res.emit("close");
+    vi.advanceTimersByTime(5000);
+    expect(res.write).toHaveBeenCalledTimes(1);
+  });
+
+  it("stops when the returned function is called", () => {
+    const res = fakeResponse();
+    const stop = startSseHeartbeat(res, 1000);
+    stop();
+    vi.advanceTimersByTime(5000);
+    expect(res.write).not.toHaveBeenCalled();
+  });
+
+  it("is disabled by a zero interval", () => {
+    const res = fakeResponse();
+    startSseHeartbeat(res, 0);
+    vi.advanceTimersByTime(60_000);
+    expect(res.write).not.toHaveBeenCalled();
+  });
+
+  it("does not write after the response has ended", () => {
+    const res = fakeResponse();
+    startSseHeartbeat(res, 1000);
+    res.writableEnded = true;
+    vi.advanceTimersByTime(3000);
+    expect(res.write).not.toHaveBeenCalled();
+  });
+});
+
+describe("legacySseSessionId", () => {
+  it("reads sessionId from the query", () => {
+    expect(legacySseSessionId({ sessionId: "abc" })).toBe("abc");
+  });
+
+  it("ignores missing, empty, and repeated values", () => {
+    expect(legacySseSessionId({})).toBeUndefined();
+    expect(legacySseSessionId({ sessionId: "" })).toBeUndefined();
+    expect(legacySseSessionId({ sessionId: ["a", "b"] })).toBeUndefined();
+    expect(legacySseSessionId(undefined)).toBeUndefined();
+  });
+});
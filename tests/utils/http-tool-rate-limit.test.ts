@@ -0,0 +1,170 @@
+import express from "express";
+import { afterEach, describe, expect, it, vi } from "vitest";
+import type { AddressInfo } from "node:net";
+import {
+  ToolRateLimiter,
+  createIpRateLimiter,
+  createToolRateLimitMiddleware,
+  createToolRateLimiter,
+  parseToolRateOverrides,
+  renderToolRateLimitMetrics,
+  resetToolRateLimitMetrics,
+} from "../../src/utils/http-tool-rate-limit.js";
+import { resetUsageMetrics } from "../../src/utils/usage-metrics.js";
+
+afterEach(() => {
+  resetToolRateLimitMetrics();
+  resetUsageMetrics();
+});
+
+describe("ToolRateLimiter", () => {
+  it("limits each principal across tools and refills over time", () => {
+    const limiter = new ToolRateLimiter({ principalPerMinute: 2, toolPerMinute: 0 });
+    const t0 = 1_000_000;
+    expect(limiter.check("alice", "harness_list", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_get", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_get", t0)).toEqual({ allowed: false, limit: "principal", retryAfterMs: 30_000 });
+    expect(limiter.check("bob", "harness_get", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_get", t0 + 30_000).allowed).toBe(true);
+  });
+
+  it("applies per-tool limits and overrides without draining the principal budget", () => {
+    const limiter = new ToolRateLimiter({ principalPerMinute: 3, toolPerMinute: 5, toolOverrides: { harness_execute: 1 } });
+    const t0 = 1_000_000;
+    expect(limiter.check("alice", "harness_execute", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_execute", t0)).toMatchObject({ allowed: false, limit: "tool" });
+    expect(limiter.check("alice", "harness_list", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_list", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_list", t0)).toMatchObject({ allowed: false, limit: "principal" });
+
+    expect(renderToolRateLimitMetrics()).toContain('harness_mcp_tool_calls_throttled_total{account="unknown",tool="harness_execute",limit="tool"} 1');
+    expect(renderToolRateLimitMetrics()).toContain('harness_mcp_tool_calls_throttled_total{account="unknown",tool="harness_list",limit="principal"} 1');
+  });
+
+  it("takes new limits on reconfigure, starting from full buckets", () => {
+    const limiter = new ToolRateLimiter({ principalPerMinute: 0, toolPerMinute: 0 });
+    const t0 = 1_000_000;
+    expect(limiter.enabled).toBe(false);
+    limiter.reconfigure({ principalPerMinute: 1, toolPerMinute: 0 });
+    expect(limiter.enabled).toBe(true);
+    expect(limiter.check("alice", "harness_list", t0).allowed).toBe(true);
+    expect(limiter.check("alice", "harness_list", t0).allowed).toBe(false);
+    limiter.reconfigure({ principalPerMinute: 5, toolPerMinute: 0 });
+    expect(limiter.check("alice", "harness_list", t0).allowed).toBe(true);
+  });
+
+  it("is disabled when every limit is 0 and validates overrides", () => {
+    expect(createToolRateLimiter({
+      HARNESS_TOOL_RATE_LIMIT_PER_MIN: 0,
+      HARNESS_TOOL_RATE_LIMIT_PER_TOOL_PER_MIN: 0,
+      HARNESS_TOOL_RATE_LIMITS: undefined,
+    })).toBeUndefined();
+    expect(parseToolRateOverrides("harness_execute=10, harness_list=120")).toEqual({ harness_execute: 10, harness_list: 120 });
+    expect(() => parseToolRateOverrides("harness_execute=fast")).toThrow(/tool=calls_per_minute/);
+  });
+});
+
+describe("createToolRateLimitMiddleware", () => {
+  it("returns 429 with Retry-After for throttled tools/call and passes other messages", async () => {
+    const limiter = new ToolRateLimiter({ principalPerMinute: 0, toolPerMinute: 1 });
+    const app = express();
+    app.use(express.json());
+    app.post("/mcp", createToolRateLimitMiddleware(limiter, "acct-default"), (_req, res) => res.json({ ok: true }));
+
+    const server = app.listen(0, "127.0.0.1");
+    await new Promise<void>((resolve) => server.once("listening", resolve));
+    const url = `http://127.0.0.1:${(server.address() as AddressInfo).port}/mcp`;
+    const post = (body: unknown) =>
+      fetch(url, { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(body) });
+    const call = { jsonrpc: "2.0", id: 7, method: "tools/call", params: { name: "harness_list", arguments: {} } };
+    try {
+      expect((await post(call)).status).toBe(200);
+      const throttled = await post(call);
+      expect(throttled.status).toBe(429);
+      expect(throttled.headers.get("retry-after")).toBe("60");
+      expect(await throttled.json()).toMatchObject({ id: 7, error: { message: expect.stringContaining("harness_list") } });
+      expect((await post({ jsonrpc: "2.0", id: 8, method: "tools/list" })).status).toBe(200);
+      expect(renderToolRateLimitMetrics()).toContain('{account="acct-default",tool="harness_list",limit="tool"} 1');
+    } finally {
+      await new Promise<void>((resolve) => server.close(() => resolve()));
+    }
+  });
+
+  it("never throttles one ping per request, but counts extra pings in a batch", async () => {
+    const limiter = new ToolRateLimiter({ principalPerMinute: 1, toolPerMinute: 1 });
+    const app = express();
+    app.use(express.json());
+    app.post("/mcp", createToolRateLimitMiddleware(limiter), (_req, res) => res.json({ ok: true }));
+
+    const server = app.listen(0, "127.0.0.1");
+    await new Promise<void>((resolve) => server.once("listening", resolve));
+    const url = `http://127.0.0.1:${(server.address() as AddressInfo).port}/mcp`;
+    const post = (args: Record<string, unknown>) => fetch(url, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ jsonrpc: "2.0", id: 1, method: "tools/call", params: { name: "harness_get", arguments: args } }),
+    });
+    try {
+      for (let i = 0; i < 3; i++) expect((await post({ resource_type: "ping" })).status).toBe(200);
+      const ping = { jsonrpc: "2.0", id: 2, method: "tools/call", params: { name: "harness_get", arguments: { resource_type: "ping" } } };
+      const batch = await fetch(url, { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify([ping, ping]) });
+      // The second ping took the only token.
+      expect(batch.status).toBe(200);
+      expect((await post({ resource_type: "pipeline", resource_id: "deploy" })).status).toBe(429);
+      expect((await post({ resource_type: "ping" })).status).toBe(200);
+    } finally {
+      await new Promise<void>((resolve) => server.close(() => resolve()));
+    }
+  });
+});
+
+describe("createIpRateLimiter", () => {
+  it("limits each IP per window and refunds single-ping requests", async () => {
+    const limiter = createIpRateLimiter(2, 60_000);
+    const app = express();
+    app.use(limiter.middleware);
+    app.use(express.json());
+    app.use(limiter.refundExempt);
+    app.post("/mcp", (_req, res) => res.json({ ok: true }));
+
+    const server = app.listen(0, "127.0.0.1");
+    await new Promise<void>((resolve) => server.once("listening", resolve));
+    const url = `http://127.0.0.1:${(server.address() as AddressInfo).port}/mcp`;
+    const call = (resourceType: string) => ({ jsonrpc: "2.0", id: 1, method: "tools/call", params: { name: "harness_get", arguments: { resource_type: resourceType } } });
+    const post = (body: unknown) => fetch(url, { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(body) });
+    try {
+      for (let i = 0; i < 3; i++) expect((await post(call("ping"))).status).toBe(200);
+      expect((await post(call("pipeline"))).status).toBe(200);
+      // A batch of pings is not exempt, so it counts against the limit.
+      expect((await post([call("ping"), call("ping")])).status).toBe(200);
+      expect((await post(call("pipeline"))).status).toBe(429);
+      // Over the limit, requests are turned away before their body is parsed.
+      expect((await post(call("ping"))).status).toBe(429);
+      expect((await fetch(url, { method: "POST", headers: { "Content-Type": "application/json" }, body: "{not json" })).status).toBe(429);
+    } finally {
+      await new Promise<void>((resolve) => server.close(() => resolve()));
+    }
+  });
+
+  it("counts nothing when the limit is 0", () => {
+    const limiter = createIpRateLimiter(0, 60_000);
+    const next = vi.fn();
+    for (let i = 0; i < 100; i++) limiter.middleware({ ip: "10.0.0.1", body: undefined } as never, {} as never, next);
+    expect(next).toHaveBeenCalledTimes(100);
+    expect(limiter.size).toBe(0);
+  });
+
+  it("prunes counters whose window has ended", () => {
+    const limiter = createIpRateLimiter(1, 1_000);
+    const res = { status: () => ({ json: () => undefined }) };
+    const hit = (ip: string) => limiter.middleware({ ip, body: undefined } as never, res as never, () => undefined);
+    hit("10.0.0.1");
+    hit("10.0.0.2");
+    expect(limiter.size).toBe(2);
+
+    limiter.prune(Date.now());
+    expect(limiter.size).toBe(2);
+    limiter.prune(Date.now() + 1_000);
+    expect(limiter.size).toBe(0);
+  });
+});
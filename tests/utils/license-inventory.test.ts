@@ -0,0 +1,69 @@
+import { describe, it, expect } from "vitest";
+import { buildLicenseInventory, licenseAllowed, type InventoryArtifact } from "../../src/utils/license-inventory.js";
+
+const allow = (...licenses: string[]) => new Set(licenses);
+
+describe("licenseAllowed", () => {
+  it("needs one OR alternative and every AND term", () => {
+    expect(licenseAllowed("MIT OR GPL-3.0-only", allow("MIT"))).toBe(true);
+    expect(licenseAllowed("MIT AND GPL-3.0-only", allow("MIT"))).toBe(false);
+    expect(licenseAllowed("(MIT OR GPL-3.0-only) AND BSD-3-Clause", allow("MIT", "BSD-3-Clause"))).toBe(true);
+    expect(licenseAllowed("(MIT OR GPL-3.0-only) AND BSD-3-Clause", allow("MIT"))).toBe(false);
+  });
+
+  it("matches case-insensitively and accepts the base license of a WITH exception", () => {
+    expect(licenseAllowed("apache-2.0", allow("Apache-2.0"))).toBe(true);
+    expect(licenseAllowed("Apache-2.0 WITH LLVM-exception", allow("Apache-2.0"))).toBe(true);
+    expect(licenseAllowed("GPL-2.0-only WITH Classpath-exception-2.0", allow("GPL-2.0-only WITH Classpath-exception-2.0"))).toBe(true);
+    expect(licenseAllowed("GPL-2.0-only WITH Classpath-exception-2.0", allow("MIT"))).toBe(false);
+  });
+});
+
+describe("buildLicenseInventory", () => {
+  const artifacts: InventoryArtifact[] = [
+    {
+      artifact_id: "img-api",
+      name: "api:1.0",
+      kind: "artifact",
+      components: [
+        { name: "lodash", version: "4.17.21", license: "MIT" },
+        { name: "readline", version: "8.1", license: "GPL-3.0-only" },
+        { name: "mystery", version: "0.1" },
+      ],
+    },
+    {
+      artifact_id: "repo-web",
+      name: "web",
+      kind: "code_repo",
+      components: [
+        { name: "react", version: "18.2.0", license: "MIT" },
+        { name: "dual", version: "1.0.0", license: "MIT OR GPL-3.0-only" },
+        { name: "odd", version: "2.0.0", license: "NOASSERTION" },
+      ],
+    },
+  ];
+
+  it("counts components and artifacts per license, most widely used first", () => {
+    const inventory = buildLicenseInventory(artifacts);
+
+    expect(inventory.artifacts_scanned).toBe(2);
+    expect(inventory.components).toBe(6);
+    expect(inventory.licenses).toEqual([
+      { license: "MIT", components: 2, artifacts: 2 },
+      { license: "GPL-3.0-only", components: 1, artifacts: 1 },
+      { license: "MIT OR GPL-3.0-only", components: 1, artifacts: 1 },
+    ]);
+    expect(inventory.unlicensed).toEqual({ components: 2, artifacts: 2 });
+    expect(inventory.violations).toBeUndefined();
+  });
+
+  it("reports artifacts with licenses outside the allow-list", () => {
+    const inventory = buildLicenseInventory(artifacts, ["MIT", "Apache-2.0"]);
+
+    expect(inventory.violations).toEqual([
+      { artifact_id: "img-api", name: "api:1.0", kind: "artifact", licenses: [{ license: "GPL-3.0-only", components: 1, examples: ["readline@8.1"] }] },
+    ]);
+    expect(inventory.licenses.find((l) => l.license === "MIT OR GPL-3.0-only")?.allowed).toBe(true);
+    expect(inventory.licenses.find((l) => l.license === "GPL-3.0-only")?.allowed).toBe(false);
+  });
+});
@@ -0,0 +1,68 @@
+import { describe, expect, it } from "vitest";
+import {
+  decodeLogCursor,
+  encodeLogCursor,
+  parseLogEntry,
+  readLogBlob,
+  readLogStream,
+} from "../../src/utils/log-stream.js";
+
+function sse(...chunks: string[]): ReadableStream<Uint8Array> {
+  const encoder = new TextEncoder();
+  return new ReadableStream({
+    start(controller) {
+      for (const chunk of chunks) controller.enqueue(encoder.encode(chunk));
+    },
+  });
+}
+
+const line = (out: string) => `data: ${JSON.stringify({ level: "INFO", time: "2025-07-01T00:00:00Z", out })}\n\n`;
+
+describe("log cursor", () => {
+  it("round-trips and rejects malformed tokens", () => {
+    const token = encodeLogCursor({ key: "acct/pipeline/p/1/-exec", offset: 42 });
+    expect(decodeLogCursor(token)).toEqual({ key: "acct/pipeline/p/1/-exec", offset: 42 });
+    expect(decodeLogCursor("not-a-cursor")).toBeUndefined();
+    expect(decodeLogCursor(Buffer.from(JSON.stringify({ k: "x", o: -1 })).toString("base64url"))).toBeUndefined();
+  });
+});
+
+describe("parseLogEntry", () => {
+  it("reads log-service JSON and strips ANSI colour codes", () => {
+    expect(parseLogEntry('{"level":"WARN","time":"t1","out":"\\u001b[31mfailed\\u001b[0m\\n"}'))
+      .toEqual({ level: "warn", time: "t1", out: "failed" });
+    expect(parseLogEntry("plain text")).toEqual({ out: "plain text" });
+    expect(parseLogEntry("   ")).toBeUndefined();
+  });
+});
+
+describe("readLogStream", () => {
+  it("skips lines before the cursor and stops at eof", async () => {
+    const result = await readLogStream(
+      sse(line("one"), line("two"), `${line("three")}event: error\ndata: eof\n\n`),
+      { skip: 1, maxLines: 10, waitMs: 1000 },
+    );
+    expect(result.lines.map((l) => l.out)).toEqual(["two", "three"]);
+    expect(result).toMatchObject({ ended: true, offset: 3 });
+  });
+
+  it("returns after max_lines without ending, and resumes from the offset", async () => {
+    const result = await readLogStream(sse(line("one"), line("two"), line("three")), { skip: 0, maxLines: 2, waitMs: 1000 });
+    expect(result.lines.map((l) => l.out)).toEqual(["one", "two"]);
+    expect(result).toMatchObject({ ended: false, offset: 2 });
+  });
+
+  it("returns what it has when no more lines arrive within waitMs", async () => {
+    const result = await readLogStream(sse(line("one")), { skip: 0, maxLines: 10, waitMs: 50 });
+    expect(result.lines.map((l) => l.out)).toEqual(["one"]);
+    expect(result.ended).toBe(false);
+  });
+});
+
+describe("readLogBlob", () => {
+  it("pages through a closed log", () => {
+    const text = ["a", "b", "c"].map((out) => JSON.stringify({ out })).join("\n");
+    expect(readLogBlob(text, { skip: 0, maxLines: 2 })).toMatchObject({ offset: 2, ended: false, total: 3 });
+    expect(readLogBlob(text, { skip: 2, maxLines: 2 })).toMatchObject({ lines: [{ out: "c" }], offset: 3, ended: true });
+  });
+});
@@ -0,0 +1,24 @@
+import { describe, expect, it } from "vitest";
+import { truncateLogText } from "../../src/utils/log-truncate.js";
+
+describe("truncateLogText", () => {
+  it("returns short logs unchanged", () => {
+    expect(truncateLogText("a\nb", 100)).toEqual({ text: "a\nb", truncated: false, total_chars: 3, total_lines: 2 });
+  });
+
+  it("keeps whole lines from the start and end around a marker", () => {
+    const text = Array.from({ length: 100 }, (_, i) => `line ${String(i).padStart(3, "0")}`).join("\n");
+    const result = truncateLogText(text, 100);
+    const lines = result.text.split("\n");
+    expect(result).toMatchObject({ truncated: true, total_lines: 100, total_chars: text.length });
+    expect(lines[0]).toBe("line 000");
+    expect(lines[lines.length - 1]).toBe("line 099");
+    expect(result.text).toMatch(/\.\.\. \[\d+ lines truncated; showing the first 2 and last \d+ of 100 lines\] \.\.\./);
+    expect(result.omitted_lines! + lines.length - 1).toBe(100);
+  });
+
+  it("keeps the end of a single line longer than the cap", () => {
+    const result = truncateLogText("x".repeat(50) + "END", 10);
+    expect(result.text).toBe("... [43 chars truncated] ...\n" + "x".repeat(7) + "END");
+  });
+});
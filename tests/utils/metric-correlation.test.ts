@@ -0,0 +1,44 @@
+import { describe, it, expect } from "vitest";
+import { correlate, joinSeries, pearson, timeSeries } from "../../src/utils/metric-correlation.js";
+
+describe("timeSeries", () => {
+  it("reads the first dated rows, preferring the given value keys", () => {
+    const raw = { data: { summary: { total: 3 }, points: [
+      { date: "2026-09-07T00:00:00Z", activeUsers: 4, adoptionRate: 20 },
+      { date: "2026-09-14T00:00:00Z", activeUsers: 6, adoptionRate: 30 },
+    ] } };
+    expect([...timeSeries(raw, ["adoptionRate"])]).toEqual([["2026-09-07", 20], ["2026-09-14", 30]]);
+    expect([...timeSeries(raw)]).toEqual([["2026-09-07", 4], ["2026-09-14", 6]]);
+  });
+
+  it("accepts epoch seconds and milliseconds as periods", () => {
+    expect([...timeSeries([{ key: 1757203200, value: 1 }, { key: "1757808000000", value: 2 }])])
+      .toEqual([["2025-09-07", 1], ["2025-09-14", 2]]);
+  });
+});
+
+describe("joinSeries", () => {
+  it("lines series up by period, leaving gaps out", () => {
+    expect(joinSeries({ a: new Map([["2026-01-01", 1], ["2026-01-08", 2]]), b: new Map([["2026-01-08", 5]]) })).toEqual([
+      { period: "2026-01-01", a: 1 },
+      { period: "2026-01-08", a: 2, b: 5 },
+    ]);
+  });
+});
+
+describe("correlate", () => {
+  const rows = [10, 20, 30, 40, 50].map((ai, i) => ({ period: `2026-0${i + 1}-01`, ai, velocity: 5 + i, cycle: 40 - 3 * i, flat: 7 }));
+
+  it("judges improvement by the direction that is better for each metric", () => {
+    expect(correlate(rows, "ai", [{ name: "velocity" }, { name: "cycle", lowerIsBetter: true }])).toEqual([
+      { metric: "velocity", periods: 5, r: 1, strength: "strong", association: "improves" },
+      { metric: "cycle", periods: 5, r: -1, strength: "strong", association: "improves" },
+    ]);
+  });
+
+  it("reports no r for constant series or too few periods", () => {
+    expect(correlate(rows, "ai", [{ name: "flat" }])).toEqual([{ metric: "flat", periods: 5 }]);
+    expect(correlate(rows.slice(0, 3), "ai", [{ name: "velocity" }])).toEqual([{ metric: "velocity", periods: 3 }]);
+    expect(pearson([1, 2, 3], [3, 1, 2])).toBeCloseTo(-0.5);
+  });
+});
@@ -0,0 +1,193 @@
+import { existsSync, mkdirSync, mkdtempSync, readdirSync, readFileSync, renameSync, rmSync, symlinkSync, writeFileSync } from "node:fs";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { afterEach, describe, expect, it, vi } from "vitest";
+import {
+  configureOutputRoot,
+  moveIntoPlace,
+  outputTempPath,
+  renameWithRetry,
+  resetOutputRoot,
+  resolveOutputDir,
+  resolveToolOutputDir,
+  safeFileName,
+  writeOutputFile,
+} from "../../src/utils/output-paths.js";
+
+const WINDOWS_ENV = { USERPROFILE: "C:\\Users\\dev", TEMP: "C:\\Users\\dev\\AppData\\Local\\Temp" };
+
+function lockError(code: string): NodeJS.ErrnoException {
+  return Object.assign(new Error(`${code}: resource busy or locked`), { code });
+}
+
+describe("resolveOutputDir", () => {
+  it("normalizes Windows drive, UNC, quoted, ~ and %VAR% paths", () => {
+    const win = { platform: "win32" as const, env: WINDOWS_ENV };
+
+    expect(resolveOutputDir("C:/exports/audit/", win)).toBe("C:\\exports\\audit\\");
+    expect(resolveOutputDir("\\\\fileserver\\share\\audit", win)).toBe("\\\\fileserver\\share\\audit");
+    expect(resolveOutputDir('"C:\\Program Files\\Harness Exports"', win)).toBe("C:\\Program Files\\Harness Exports");
+    expect(resolveOutputDir("~\\exports", win)).toBe("C:\\Users\\dev\\exports");
+    expect(resolveOutputDir("%temp%\\harness", win)).toBe("C:\\Users\\dev\\AppData\\Local\\Temp\\harness");
+  });
+
+  it("rejects relative and drive-relative paths with a platform example", () => {
+    expect(() => resolveOutputDir("exports", { platform: "win32", env: WINDOWS_ENV })).toThrow(/absolute path \(e\.g\. C:\\Users/);
+    expect(() => resolveOutputDir("C:exports", { platform: "win32", env: WINDOWS_ENV })).toThrow(/absolute/);
+    expect(() => resolveOutputDir("exports", { platform: "linux", env: {} })).toThrow(/\/home\/me\/exports/);
+    expect(() => resolveOutputDir("  ", { platform: "linux", env: {} })).toThrow(/empty/);
+  });
+
+  it("uses POSIX rules off Windows and resolves relative paths on request", () => {
+    expect(resolveOutputDir("~/exports//audit", { platform: "linux", env: { HOME: "/home/dev" } })).toBe("/home/dev/exports/audit");
+    expect(resolveOutputDir("%TEMP%/x", { platform: "linux", env: WINDOWS_ENV, allowRelative: true })).toMatch(/%TEMP%\/x$/);
+    expect(resolveOutputDir("out", { platform: "linux", env: {}, allowRelative: true })).toBe(join(process.cwd(), "out"));
+  });
+});
+
+describe("resolveToolOutputDir", () => {
+  let root: string | undefined;
+  afterEach(() => {
+    resetOutputRoot();
+    if (root) rmSync(root, { recursive: true, force: true });
+    root = undefined;
+  });
+
+  it("accepts any absolute path over stdio when no root is set", () => {
+    expect(resolveToolOutputDir("/var/exports", { platform: "linux", env: {} })).toBe("/var/exports");
+  });
+
+  it("refuses output_dir over HTTP unless a root is set", () => {
+    configureOutputRoot({ remote: true });
+    expect(() => resolveToolOutputDir("/var/exports", { platform: "linux", env: {} })).toThrow(/HARNESS_OUTPUT_ROOT/);
+  });
+
+  it("confines paths to the root and resolves relative ones against it", () => {
+    root = mkdtempSync(join(tmpdir(), "out-root-"));
+    configureOutputRoot({ root, remote: true });
+
+    expect(resolveToolOutputDir(join(root, "audit"))).toBe(join(root, "audit"));
+    expect(resolveToolOutputDir("audit/2026")).toBe(join(root, "audit", "2026"));
+    expect(() => resolveToolOutputDir("../elsewhere")).toThrow(/outside HARNESS_OUTPUT_ROOT/);
+    expect(() => resolveToolOutputDir("/etc/cron.d")).toThrow(/outside HARNESS_OUTPUT_ROOT/);
+  });
+
+  it("rejects a symlink inside the root that leads out of it", () => {
+    root = mkdtempSync(join(tmpdir(), "out-root-"));
+    const outside = mkdtempSync(join(tmpdir(), "out-elsewhere-"));
+    mkdirSync(join(root, "ok"));
+    symlinkSync(outside, join(root, "link"));
+    configureOutputRoot({ root });
+
+    try {
+      expect(resolveToolOutputDir("ok/new")).toBe(join(root, "ok", "new"));
+      expect(() => resolveToolOutputDir("link/new")).toThrow(/outside HARNESS_OUTPUT_ROOT/);
+    } finally {
+      rmSync(outside, { recursive: true, force: true });
+    }
+  });
+});
+
+describe("safeFileName", () => {
+  it("replaces characters and names Windows rejects", () => {
+    expect(safeFileName("harness-support-2026-03-01T12:30:45Z")).toBe("harness-support-2026-03-01T12-30-45Z");
+    expect(safeFileName('a<b>c|d?e*f"g/h\\i')).toBe("a-b-c-d-e-f-g-h-i");
+    expect(safeFileName("report. ")).toBe("report");
+    expect(safeFileName("CON")).toBe("_CON");
+    expect(safeFileName("lpt1.txt")).toBe("_lpt1.txt");
+    expect(safeFileName("...")).toBe("_");
+  });
+});
+
+describe("renameWithRetry", () => {
+  it("retries while the target is locked", () => {
+    const rename = vi.fn()
+      .mockImplementationOnce(() => { throw lockError("EBUSY"); })
+      .mockImplementationOnce(() => { throw lockError("EPERM"); })
+      .mockImplementationOnce(() => undefined);
+
+    renameWithRetry("a.tmp", "a", rename, 5, 1);
+
+    expect(rename).toHaveBeenCalledTimes(3);
+  });
+
+  it("gives up after the last attempt and does not retry other errors", () => {
+    const busy = vi.fn(() => { throw lockError("EBUSY"); });
+    expect(() => renameWithRetry("a.tmp", "a", busy, 3, 1)).toThrow(/EBUSY/);
+    expect(busy).toHaveBeenCalledTimes(3);
+
+    const missing = vi.fn(() => { throw lockError("ENOENT"); });
+    expect(() => renameWithRetry("a.tmp", "a", missing, 3, 1)).toThrow(/ENOENT/);
+    expect(missing).toHaveBeenCalledTimes(1);
+  });
+});
+
+describe("moveIntoPlace", () => {
+  let dir: string | undefined;
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+  });
+
+  it("copies beside the target and renames when the temp file is on another volume", () => {
+    dir = mkdtempSync(join(tmpdir(), "output-paths-"));
+    const temp = join(dir, "scratch.tmp");
+    const file = join(dir, "out", "export.jsonl");
+    mkdirSync(join(dir, "out"));
+    writeFileSync(temp, "data\n");
+    const rename = vi.fn((from: string, to: string) => {
+      if (from === temp) throw lockError("EXDEV");
+      renameSync(from, to);
+    });
+
+    moveIntoPlace(temp, file, rename);
+
+    expect(readFileSync(file, "utf8")).toBe("data\n");
+    expect(existsSync(temp)).toBe(false);
+    expect(readdirSync(join(dir, "out"))).toEqual(["export.jsonl"]);
+    expect(rename).toHaveBeenLastCalledWith(`${file}.${process.pid}.tmp`, file);
+  });
+});
+
+describe("writeOutputFile", () => {
+  let dir: string | undefined;
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+    resetOutputRoot();
+  });
+
+  it("writes through HARNESS_TEMP_DIR when it is configured", () => {
+    dir = mkdtempSync(join(tmpdir(), "output-paths-"));
+    const scratch = join(dir, "scratch");
+    configureOutputRoot({ tempDir: scratch });
+    const file = join(dir, "exports", "export.jsonl");
+
+    expect(outputTempPath(file).startsWith(join(scratch, "export.jsonl."))).toBe(true);
+    writeOutputFile(file, "data\n");
+
+    expect(readFileSync(file, "utf8")).toBe("data\n");
+    expect(readdirSync(join(dir, "exports"))).toEqual(["export.jsonl"]);
+    expect(readdirSync(scratch)).toEqual([]);
+  });
+
+  it("creates the directory, replaces an existing file, and leaves no temp file", () => {
+    dir = mkdtempSync(join(tmpdir(), "output-paths-"));
+    const file = join(dir, "nested", "export.jsonl");
+
+    writeOutputFile(file, "first\n");
+    writeOutputFile(file, "second\n");
+
+    expect(readFileSync(file, "utf8")).toBe("second\n");
+    expect(readdirSync(join(dir, "nested"))).toEqual(["export.jsonl"]);
+  });
+
+  it("reports the path when the directory cannot be created", () => {
+    dir = mkdtempSync(join(tmpdir(), "output-paths-"));
+    const blocker = join(dir, "file");
+    writeOutputFile(blocker, "x");
+
+    expect(() => writeOutputFile(join(blocker, "sub", "out.txt"), "y")).toThrow(/Cannot create output directory/);
+    expect(existsSync(join(blocker, "sub"))).toBe(false);
+  });
+});
@@ -0,0 +1,160 @@
+import { readFileSync } from "node:fs";
+import { join } from "node:path";
+import { describe, expect, it, vi } from "vitest";
+import {
+  checkAssertion,
+  parsePlaybook,
+  parsePlaybookVars,
+  renderTemplate,
+  runPlaybook,
+  toolResultData,
+} from "../../src/utils/playbook.js";
+
+const PLAYBOOK = `
+name: failed-builds
+vars:
+  project_id: payments
+  limit: 5
+steps:
+  - name: failed
+    tool: harness_list
+    args:
+      resource_type: execution
+      project_id: "{{ vars.project_id }}"
+      filters: { status: Failed, size: "{{ vars.limit }}" }
+    assert:
+      - path: items.length
+        gte: 1
+  - name: diagnose
+    tool: harness_diagnose
+    args:
+      execution_id: "{{ steps.failed.items.0.id }}"
+      summary: "Diagnosing {{ steps.failed.items.0.id }} in {{ vars.project_id }}"
+    assert:
+      - path: failure.stage
+        equals: build
+        message: Failures should be in the build stage
+  - name: pipeline
+    tool: harness_get
+    args:
+      resource_type: pipeline
+      resource_id: "{{ steps.diagnose.pipeline_id }}"
+`;
+
+describe("parsePlaybook", () => {
+  it("applies defaults and validates structure", () => {
+    const playbook = parsePlaybook(PLAYBOOK);
+    expect(playbook.name).toBe("failed-builds");
+    expect(playbook.steps[2]).toMatchObject({ assert: [], continue_on_failure: false });
+  });
+
+  it("lists every problem", () => {
+    expect(() => parsePlaybook("name: x\nsteps:\n  - name: 1st\n    tool: kubectl\n")).toThrow(/steps\.0\.name[\s\S]*steps\.0\.tool/);
+    expect(() => parsePlaybook("name: x\nsteps:\n  - { name: a, tool: harness_get }\n  - { name: a, tool: harness_get }\n")).toThrow(/duplicate step name "a"/);
+    expect(() => parsePlaybook("name: [")).toThrow(/Invalid playbook YAML/);
+  });
+
+  it("parses the example playbook in docs", () => {
+    const text = readFileSync(join(import.meta.dirname, "../../docs/playbooks/weekly-security-review.yaml"), "utf8");
+    expect(parsePlaybook(text).steps.map((s) => s.name)).toEqual(["critical_issues", "pending_exemptions", "security_overview"]);
+  });
+});
+
+describe("renderTemplate", () => {
+  const context = { vars: { n: 5, name: "api" }, steps: { list: { items: [{ id: "e1" }] } } };
+
+  it("keeps types for whole-value templates and interpolates the rest", () => {
+    expect(renderTemplate({ size: "{{ vars.n }}", label: "{{vars.name}}-{{ vars.n }}", nested: ["{{ steps.list.items.0.id }}"] }, context))
+      .toEqual({ size: 5, label: "api-5", nested: ["e1"] });
+  });
+
+  it("fails on unresolved paths", () => {
+    expect(() => renderTemplate("{{ steps.missing.id }}", context)).toThrow("Template {{ steps.missing.id }} did not resolve");
+  });
+});
+
+describe("checkAssertion", () => {
+  const result = { total: 3, items: [{ id: "a" }], status: "Failed", tags: ["prod"] };
+
+  it("supports existence, equality, bounds, and contains", () => {
+    expect(checkAssertion(result, { path: "total" })).toBe(true);
+    expect(checkAssertion(result, { path: "missing" })).toBe(false);
+    expect(checkAssertion(result, { path: "missing", exists: false })).toBe(true);
+    expect(checkAssertion(result, { path: "status", equals: "Failed" })).toBe(true);
+    expect(checkAssertion(result, { path: "status", not_equals: "Failed" })).toBe(false);
+    expect(checkAssertion(result, { path: "total", gt: 1, lte: 3 })).toBe(true);
+    expect(checkAssertion(result, { path: "items.length", lt: 1 })).toBe(false);
+    expect(checkAssertion(result, { path: "tags", contains: "prod" })).toBe(true);
+    expect(checkAssertion(result, { path: "status", contains: "Fail" })).toBe(true);
+  });
+});
+
+describe("runPlaybook", () => {
+  it("threads step results into later args and reports each step", async () => {
+    const callTool = vi.fn(async (tool: string) => {
+      if (tool === "harness_list") return { items: [{ id: "exec-9" }] };
+      if (tool === "harness_diagnose") return { failure: { stage: "build" }, pipeline_id: "ci" };
+      return { identifier: "ci" };
+    });
+
+    const report = await runPlaybook(parsePlaybook(PLAYBOOK), callTool, { project_id: "checkout" });
+
+    expect(callTool.mock.calls[0]).toEqual(["harness_list", { resource_type: "execution", project_id: "checkout", filters: { status: "Failed", size: 5 } }]);
+    expect(callTool.mock.calls[1]).toEqual(["harness_diagnose", { execution_id: "exec-9", summary: "Diagnosing exec-9 in checkout" }]);
+    expect(callTool.mock.calls[2]).toEqual(["harness_get", { resource_type: "pipeline", resource_id: "ci" }]);
+    expect(report.passed).toBe(true);
+    expect(report.steps.map((s) => s.status)).toEqual(["passed", "passed", "passed"]);
+    expect(report.steps[1]!.assertions).toEqual([
+      { check: 'failure.stage equals "build"', passed: true, actual: "build", message: "Failures should be in the build stage" },
+    ]);
+  });
+
+  it("stops at a failed assertion and skips the remaining steps", async () => {
+    const callTool = vi.fn(async () => ({ items: [] }));
+
+    const report = await runPlaybook(parsePlaybook(PLAYBOOK), callTool);
+
+    expect(callTool).toHaveBeenCalledTimes(1);
+    expect(report.passed).toBe(false);
+    expect(report.steps.map((s) => s.status)).toEqual(["failed", "skipped", "skipped"]);
+    expect(report.steps[0]!.assertions[0]).toMatchObject({ passed: false, actual: 0 });
+  });
+
+  it("continues past a failing step when continue_on_failure is set", async () => {
+    const playbook = parsePlaybook(`
+name: p
+steps:
+  - { name: a, tool: harness_get, continue_on_failure: true }
+  - { name: b, tool: harness_get }
+`);
+    const callTool = vi.fn()
+      .mockRejectedValueOnce(new Error("Not found"))
+      .mockResolvedValueOnce({ ok: true });
+
+    const report = await runPlaybook(playbook, callTool);
+
+    expect(report.steps).toMatchObject([{ status: "error", error: "Not found" }, { status: "passed" }]);
+    expect(report.passed).toBe(false);
+  });
+});
+
+describe("parsePlaybookVars", () => {
+  it("parses JSON values and keeps other strings", () => {
+    expect(parsePlaybookVars(["n=5", "flag=true", "name=api", "list=[1,2]", "expr=a=b"]))
+      .toEqual({ n: 5, flag: true, name: "api", list: [1, 2], expr: "a=b" });
+    expect(() => parsePlaybookVars(["novalue"])).toThrow(/key=value/);
+  });
+});
+
+describe("toolResultData", () => {
+  it("prefers structuredContent, then JSON text", () => {
+    expect(toolResultData({ content: [{ type: "text", text: "{}" }], structuredContent: { a: 1 } })).toEqual({ a: 1 });
+    expect(toolResultData({ content: [{ type: "text", text: '{"items":[]}' }] })).toEqual({ items: [] });
+    expect(toolResultData({ content: [{ type: "text", text: "plain" }] })).toBe("plain");
+  });
+
+  it("throws the error message of an error result", () => {
+    expect(() => toolResultData({ content: [{ type: "text", text: '{"error":"Unknown resource_type"}' }], isError: true }))
+      .toThrow("Unknown resource_type");
+  });
+});
@@ -1,4 +1,4 @@
-import { describe, it, expect } from "vitest";
+import { describe, it, expect, vi, afterEach } from "vitest";
 import { RateLimiter } from "../../src/utils/rate-limiter.js";
 
 describe("RateLimiter", () => {
@@ -45,4 +45,35 @@ describe("RateLimiter", () => {
     await limiter.acquire();
     expect(Date.now() - start).toBeLessThan(100);
   });
+
+  describe("cancellation", () => {
+    afterEach(() => {
+      vi.useRealTimers();
+    });
+
+    it("rejects with the abort reason and clears its wait timer when the signal aborts", async () => {
+      vi.useFakeTimers();
+      const limiter = new RateLimiter(1);
+      await limiter.acquire();
+      const controller = new AbortController();
+
+      const waiting = limiter.acquire(controller.signal);
+      expect(vi.getTimerCount()).toBe(1);
+      controller.abort(new Error("cancelled"));
+
+      await expect(waiting).rejects.toThrow("cancelled");
+      expect(vi.getTimerCount()).toBe(0);
+    });
+
+    it("does not hand out a token to an already aborted caller", async () => {
+      const limiter = new RateLimiter(1);
+      const controller = new AbortController();
+      controller.abort();
+
+      await expect(limiter.acquire(controller.signal)).rejects.toThrow();
+      const start = Date.now();
+      await limiter.acquire();
+      expect(Date.now() - start).toBeLessThan(50);
+    });
+  });
 });
@@ -0,0 +1,53 @@
+import { describe, it, expect } from "vitest";
+import { REMEDIATION_HINTS } from "../../src/data/remediation-hints.js";
+import { findRemediation, formatRemediation } from "../../src/utils/remediation.js";
+
+describe("REMEDIATION_HINTS", () => {
+  it("has unique ids and at least one step per hint", () => {
+    const ids = REMEDIATION_HINTS.map((h) => h.id);
+    expect(new Set(ids).size).toBe(ids.length);
+    for (const hint of REMEDIATION_HINTS) expect(hint.steps.length).toBeGreaterThan(0);
+  });
+});
+
+describe("findRemediation", () => {
+  it("matches a 403 on execution logs before the generic RBAC hint", () => {
+    const hint = findRemediation("Failed to resolve execution logs: HTTP 403 Forbidden — access denied.");
+    expect(hint?.id).toBe("logs-forbidden");
+  });
+
+  it("matches no eligible delegates regardless of status", () => {
+    expect(findRemediation("No eligible delegates present to execute the task", 400)?.id).toBe("no-eligible-delegates");
+  });
+
+  it("matches expression resolution failures", () => {
+    expect(findRemediation("Unresolved expression <+pipeline.stages.build.output>")?.id).toBe("expression-resolution");
+  });
+
+  it("uses the HTTP status when given", () => {
+    expect(findRemediation("Forbidden", 403)?.id).toBe("rbac-forbidden");
+    expect(findRemediation("Forbidden", 400)).toBeUndefined();
+  });
+
+  it("requires the status in the message when no status is given", () => {
+    expect(findRemediation("missing permission to view pipeline")).toBeUndefined();
+    expect(findRemediation("HTTP 403: missing permission to view pipeline")?.id).toBe("rbac-forbidden");
+  });
+
+  it("returns undefined for unrelated messages", () => {
+    expect(findRemediation("not found")).toBeUndefined();
+    expect(findRemediation("resource_type is required")).toBeUndefined();
+  });
+
+  it("accepts a custom hint list", () => {
+    const hints = [{ id: "custom", title: "Custom", match: { pattern: /boom/ }, steps: ["Do the thing"] }];
+    expect(findRemediation("boom", undefined, hints)).toEqual({ id: "custom", title: "Custom", steps: ["Do the thing"] });
+  });
+});
+
+describe("formatRemediation", () => {
+  it("renders numbered steps and the docs link", () => {
+    const text = formatRemediation({ id: "x", title: "Cause", steps: ["First", "Second"], see: "https://example.com" });
+    expect(text).toBe("Remediation (Cause):\n1. First\n2. Second\nSee: https://example.com");
+  });
+});
@@ -0,0 +1,34 @@
+import { describe, it, expect, vi } from "vitest";
+import { getRequestSignal, runWithRequestSignal, withRequestCancellation } from "../../src/utils/request-signal.js";
+
+describe("runWithRequestSignal", () => {
+  it("exposes the signal across awaits and only inside the call", async () => {
+    const controller = new AbortController();
+
+    const seen = await runWithRequestSignal(controller.signal, async () => {
+      await new Promise((resolve) => setTimeout(resolve, 1));
+      return getRequestSignal();
+    });
+
+    expect(seen).toBe(controller.signal);
+    expect(getRequestSignal()).toBeUndefined();
+  });
+
+  it("runs without a store when no signal is given", () => {
+    expect(runWithRequestSignal(undefined, () => getRequestSignal())).toBeUndefined();
+  });
+});
+
+describe("withRequestCancellation", () => {
+  it("runs each tool handler with the call's abort signal active", async () => {
+    const registerTool = vi.fn();
+    const handler = vi.fn(async () => getRequestSignal());
+    withRequestCancellation({ registerTool } as never).registerTool("harness_demo", {} as never, handler as never);
+    const wrapped = registerTool.mock.calls[0]![2] as (args: unknown, extra: unknown) => Promise<AbortSignal | undefined>;
+    const controller = new AbortController();
+
+    await expect(wrapped({ a: 1 }, { signal: controller.signal })).resolves.toBe(controller.signal);
+    await expect(wrapped({}, {})).resolves.toBeUndefined();
+    expect(handler).toHaveBeenCalledWith({ a: 1 }, { signal: controller.signal });
+  });
+});
@@ -0,0 +1,142 @@
+import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { Registry } from "../../src/registry/index.js";
+import { runInConversation } from "../../src/utils/conversation-context.js";
+import {
+  MemoryCacheStore,
+  createResponseCache,
+  resetCacheMetrics,
+  summarizeCacheMetrics,
+} from "../../src/utils/response-cache.js";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: "pat.test",
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_TOOLSETS: "services,connectors",
+    HARNESS_CACHE_TTL_MS: 60_000,
+    HARNESS_CACHE_MAX_ENTRIES: 100,
+    ...overrides,
+  };
+}
+
+function makeClient(requestFn: (...args: unknown[]) => unknown): HarnessClient {
+  return {
+    request: requestFn,
+    account: "test-account",
+  } as unknown as HarnessClient;
+}
+
+function servicePage() {
+  return { status: "SUCCESS", data: { content: [{ service: { identifier: "svc" } }], totalElements: 1 } };
+}
+
+describe("MemoryCacheStore", () => {
+  afterEach(() => vi.useRealTimers());
+
+  it("expires entries after their TTL and evicts least recently used past the cap", () => {
+    vi.useFakeTimers();
+    const evicted = vi.fn();
+    const store = new MemoryCacheStore(2, evicted);
+    store.set("a", 1, 1000);
+    store.set("b", 2, 1000);
+    expect(store.get("a")).toBe(1);
+    store.set("c", 3, 1000);
+    expect(store.get("b")).toBeUndefined();
+    expect(evicted).toHaveBeenCalledTimes(1);
+
+    vi.advanceTimersByTime(1000);
+    expect(store.get("a")).toBeUndefined();
+  });
+});
+
+describe("createResponseCache", () => {
+  it("is disabled at TTL 0 and honours the toolset allow-list", () => {
+    expect(createResponseCache({ HARNESS_CACHE_TTL_MS: 0 })).toBeUndefined();
+    const cache = createResponseCache({ HARNESS_CACHE_TTL_MS: 1000, HARNESS_CACHE_TOOLSETS: "pipelines, gitops" })!;
+    expect(cache.isEnabledFor("gitops")).toBe(true);
+    expect(cache.isEnabledFor("services")).toBe(false);
+  });
+});
+
+describe("Registry response cache", () => {
+  beforeEach(() => resetCacheMetrics());
+
+  it("serves repeated reads from the cache and records hits per toolset", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async () => servicePage());
+    const client = makeClient(mockRequest);
+
+    const first = await registry.dispatch(client, "service", "list", { search_term: "svc" }) as Record<string, unknown>;
+    first.items = [];
+    const second = await registry.dispatch(client, "service", "list", { search_term: "svc" }) as Record<string, unknown>;
+
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+    expect(second.items).toHaveLength(1);
+    expect(summarizeCacheMetrics()).toMatchObject({
+      hits: 1,
+      misses: 1,
+      toolsets: [{ toolset: "services", hits: 1, misses: 1 }],
+    });
+
+    await registry.dispatch(client, "service", "list", { search_term: "other" });
+    expect(mockRequest).toHaveBeenCalledTimes(2);
+  });
+
+  it("keeps each multiplexed conversation's entries to itself", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async () => servicePage());
+    const client = makeClient(mockRequest);
+
+    await runInConversation("chat-a", () => registry.dispatch(client, "service", "list", {}));
+    await runInConversation("chat-a", () => registry.dispatch(client, "service", "list", {}));
+    expect(mockRequest).toHaveBeenCalledTimes(1);
+
+    await runInConversation("chat-b", () => registry.dispatch(client, "service", "list", {}));
+    await registry.dispatch(client, "service", "list", {});
+    expect(mockRequest).toHaveBeenCalledTimes(3);
+  });
+
+  it("refetches on cache_bypass and after a write", async () => {
+    const registry = new Registry(makeConfig());
+    const mockRequest = vi.fn(async () => servicePage());
+    const client = makeClient(mockRequest);
+
+    await registry.dispatch(client, "service", "list", {});
+    await registry.dispatch(client, "service", "list", { cache_bypass: true });
+    expect(mockRequest).toHaveBeenCalledTimes(2);
+
+    await registry.dispatch(client, "service", "delete", { service_id: "svc" });
+    await registry.dispatch(client, "service", "list", {});
+    expect(mockRequest).toHaveBeenCalledTimes(4);
+    expect(summarizeCacheMetrics()).toMatchObject({ bypasses: 1, invalidations: 1 });
+  });
+
+  it("does not cache toolsets outside HARNESS_CACHE_TOOLSETS or when disabled", async () => {
+    const mockRequest = vi.fn(async () => servicePage());
+    const client = makeClient(mockRequest);
+
+    const scoped = new Registry(makeConfig({ HARNESS_CACHE_TOOLSETS: "connectors" }));
+    await scoped.dispatch(client, "service", "list", {});
+    await scoped.dispatch(client, "service", "list", {});
+
+    const disabled = new Registry(makeConfig({ HARNESS_CACHE_TTL_MS: 0 }));
+    await disabled.dispatch(client, "service", "list", {});
+    await disabled.dispatch(client, "service", "list", {});
+
+    expect(mockRequest).toHaveBeenCalledTimes(4);
+  });
+});
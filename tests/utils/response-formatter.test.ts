@@ -74,4 +74,11 @@ describe("errorResult", () => {
     const parsed = JSON.parse((result.content[0] as { type: "text"; text: string }).text);
     expect(parsed).toEqual({ error: "not found" });
   });
+
+  it("adds structured remediation when the message matches a known error", () => {
+    const result = errorResult("No eligible delegates present to execute the task");
+    const parsed = JSON.parse((result.content[0] as { type: "text"; text: string }).text);
+    expect(parsed.error).toBe("No eligible delegates present to execute the task");
+    expect(parsed.remediation).toMatchObject({ id: "no-eligible-delegates", steps: expect.any(Array) });
+  });
 });
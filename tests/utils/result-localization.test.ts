@@ -0,0 +1,151 @@
+import { beforeEach, describe, expect, it, vi } from "vitest";
+import * as z from "zod/v4";
+import { jsonResult } from "../../src/utils/response-formatter.js";
+import {
+  localizeResult,
+  normalizeLocale,
+  resetLocalizationCache,
+  withResultLocalization,
+} from "../../src/utils/result-localization.js";
+
+const DIAGNOSIS = {
+  execution: { status: "Failed", pipeline: "deploy" },
+  root_cause: {
+    category: "connectivity",
+    error: "dial tcp 10.0.0.1:443: i/o timeout",
+    suggested_fix: "Check that the delegate can reach the target host.",
+  },
+  issues: ["Last heartbeat was 12 minutes ago (stale)"],
+};
+
+type SamplingParams = { systemPrompt: string; messages: Array<{ content: { text: string } }> };
+
+/** A client model that "translates" by prefixing each text, replying the way the prompt asks. */
+function translator(prefix = "ja:") {
+  return vi.fn(async (params: SamplingParams) => {
+    const { texts } = JSON.parse(params.messages[0]!.content.text) as { texts: string[] };
+    return { role: "assistant", model: "test-model", content: { type: "text", text: JSON.stringify(texts.map((t) => `${prefix}${t}`)) } };
+  });
+}
+
+function sampler(createMessage: ReturnType<typeof vi.fn> = translator(), capabilities: Record<string, unknown> = { sampling: {} }) {
+  return { createMessage, getClientCapabilities: () => capabilities };
+}
+
+beforeEach(() => resetLocalizationCache());
+
+describe("normalizeLocale", () => {
+  it("canonicalizes tags and skips English", () => {
+    expect(normalizeLocale("pt-br")).toBe("pt-BR");
+    expect(normalizeLocale("ja")).toBe("ja");
+    expect(normalizeLocale("en-GB")).toBeUndefined();
+    expect(normalizeLocale(undefined)).toBeUndefined();
+    expect(() => normalizeLocale("not a locale")).toThrow(/Invalid locale/);
+  });
+});
+
+describe("localizeResult", () => {
+  it("translates summary fields only, through the client's model, and leaves raw data untouched", async () => {
+    const createMessage = translator();
+
+    const result = await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage) as never, "ja");
+
+    expect(createMessage).toHaveBeenCalledOnce();
+    const [params, options] = createMessage.mock.calls[0]!;
+    expect(params).toMatchObject({ includeContext: "none", temperature: 0 });
+    expect(JSON.parse(params.messages[0]!.content.text)).toEqual({
+      target_locale: "ja",
+      texts: ["Check that the delegate can reach the target host.", "Last heartbeat was 12 minutes ago (stale)"],
+    });
+    expect(options).toMatchObject({ timeout: expect.any(Number) });
+    expect(JSON.parse(result.content[0]!.text)).toEqual({
+      execution: { status: "Failed", pipeline: "deploy" },
+      root_cause: {
+        category: "connectivity",
+        error: "dial tcp 10.0.0.1:443: i/o timeout",
+        suggested_fix: "ja:Check that the delegate can reach the target host.",
+      },
+      issues: ["ja:Last heartbeat was 12 minutes ago (stale)"],
+    });
+  });
+
+  it("reuses cached translations", async () => {
+    const createMessage = translator();
+    await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage) as never, "ja");
+    await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage) as never, "ja");
+    expect(createMessage).toHaveBeenCalledOnce();
+  });
+
+  it("accepts a reply wrapped in a Markdown code fence", async () => {
+    const createMessage = vi.fn(async (params: SamplingParams) => {
+      const { texts } = JSON.parse(params.messages[0]!.content.text) as { texts: string[] };
+      return { role: "assistant", model: "m", content: { type: "text", text: "```json\n" + JSON.stringify(texts.map((t) => `de:${t}`)) + "\n```" } };
+    });
+    const result = await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage) as never, "de");
+    expect(JSON.parse(result.content[0]!.text).issues).toEqual(["de:Last heartbeat was 12 minutes ago (stale)"]);
+  });
+
+  it("returns English with a note when translation fails", async () => {
+    const createMessage = vi.fn().mockRejectedValue(new Error("Request timed out"));
+
+    const result = await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage) as never, "de");
+
+    const data = JSON.parse(result.content[0]!.text);
+    expect(data.root_cause.suggested_fix).toBe("Check that the delegate can reach the target host.");
+    expect(data._locale_note).toContain("could not be translated to de");
+    expect(result.isError).toBeUndefined();
+  });
+
+  it("returns English with a note when the client does not support sampling", async () => {
+    const createMessage = translator();
+
+    const result = await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage, {}) as never, "ja");
+
+    expect(createMessage).not.toHaveBeenCalled();
+    expect(JSON.parse(result.content[0]!.text)._locale_note).toContain("could not be translated to ja");
+  });
+
+  it("rejects a reply with the wrong number of translations", async () => {
+    const createMessage = vi.fn().mockResolvedValue({ role: "assistant", model: "m", content: { type: "text", text: "[\"nur eins\"]" } });
+    const result = await localizeResult(jsonResult(DIAGNOSIS), sampler(createMessage) as never, "de");
+    expect(JSON.parse(result.content[0]!.text)._locale_note).toBeDefined();
+  });
+});
+
+describe("withResultLocalization", () => {
+  function register(name: string, createMessage = translator()) {
+    const registerTool = vi.fn();
+    const handler = vi.fn(async () => jsonResult(DIAGNOSIS));
+    withResultLocalization({ registerTool, server: sampler(createMessage) } as never).registerTool(
+      name,
+      { inputSchema: { resource_type: z.string().optional() } } as never,
+      handler as never,
+    );
+    const [, config, wrapped] = registerTool.mock.calls[0]!;
+    return { config, wrapped: wrapped as (args: Record<string, unknown>, extra: unknown) => Promise<{ content: Array<{ text: string }>; isError?: boolean }>, handler, createMessage };
+  }
+
+  it("adds locale to composite tools, strips it from the handler's args, and translates", async () => {
+    const { config, wrapped, handler, createMessage } = register("harness_diagnose");
+
+    expect(Object.keys(config.inputSchema)).toEqual(["resource_type", "locale"]);
+    const result = await wrapped({ resource_type: "pipeline", locale: "ja" }, {});
+
+    expect(handler).toHaveBeenCalledWith({ resource_type: "pipeline" }, {});
+    expect(createMessage).toHaveBeenCalledOnce();
+    expect(JSON.parse(result.content[0]!.text).issues).toEqual(["ja:Last heartbeat was 12 minutes ago (stale)"]);
+  });
+
+  it("does not call the translator without a non-English locale and rejects bad tags", async () => {
+    const { wrapped, createMessage } = register("harness_status");
+
+    await wrapped({ locale: "en" }, {});
+    expect(createMessage).not.toHaveBeenCalled();
+    expect((await wrapped({ locale: "??" }, {})).isError).toBe(true);
+  });
+
+  it("registers other tools unchanged", () => {
+    const { config } = register("harness_get");
+    expect(Object.keys(config.inputSchema)).toEqual(["resource_type"]);
+  });
+});
@@ -9,6 +9,7 @@ import {
   resolveRuntimeInputs,
   clearTemplateCache,
 } from "../../src/utils/runtime-input-resolver.js";
+import { runInConversation } from "../../src/utils/conversation-context.js";
 import { HarnessClient } from "../../src/client/harness-client.js";
 import type { Config } from "../../src/config.js";
 
@@ -616,6 +617,24 @@ describe("fetchRuntimeInputTemplate — caching", () => {
     expect(second).toBeNull();
     expect(fetchSpy).toHaveBeenCalledTimes(1);
   });
+
+  it("does not share cache entries across conversations", async () => {
+    fetchSpy.mockImplementation(async () =>
+      new Response(JSON.stringify({
+        status: "SUCCESS",
+        data: { inputSetTemplateYaml: SIMPLE_TEMPLATE_YAML },
+      }), { status: 200, headers: { "Content-Type": "application/json" } }),
+    );
+
+    const client = new HarnessClient(makeConfig());
+    const opts = { pipelineId: "shared_pipe", orgId: "default", projectId: "proj" };
+
+    await runInConversation("chat-a", () => fetchRuntimeInputTemplate(client, opts));
+    await runInConversation("chat-a", () => fetchRuntimeInputTemplate(client, opts));
+    await runInConversation("chat-b", () => fetchRuntimeInputTemplate(client, opts));
+
+    expect(fetchSpy).toHaveBeenCalledTimes(2);
+  });
 });
 
 describe("isResolvableInputs", () => {
@@ -0,0 +1,61 @@
+import { describe, it, expect } from "vitest";
+import { compareVersions, diffSboms, type SbomComponent } from "../../src/utils/sbom-diff.js";
+
+const pkg = (name: string, version: string, extra: Partial<SbomComponent> = {}): SbomComponent => ({
+  key: `pkg:npm/${name}`, name, version, ...extra,
+});
+
+describe("compareVersions", () => {
+  it("orders numeric segments numerically and releases after pre-releases", () => {
+    expect(compareVersions("1.2.10", "1.2.9")).toBe(1);
+    expect(compareVersions("v2.0.0", "2.0.0")).toBe(0);
+    expect(compareVersions("1.0.0-rc1", "1.0.0")).toBe(-1);
+    expect(compareVersions("1.0.0", "1.0.0.1")).toBe(-1);
+  });
+
+  it("gives up on versions without a numeric lead", () => {
+    expect(compareVersions("abc123f", "9e8d7c6")).toBeUndefined();
+    expect(compareVersions(undefined, "1.0.0")).toBeUndefined();
+  });
+});
+
+describe("diffSboms", () => {
+  it("classifies added, removed, upgraded, downgraded, and unordered version changes", () => {
+    const diff = diffSboms(
+      [pkg("lodash", "4.17.20"), pkg("left-pad", "1.0.0"), pkg("react", "18.2.0"), pkg("tool", "abc123")],
+      [pkg("lodash", "4.17.21"), pkg("axios", "1.6.0"), pkg("react", "17.0.2"), pkg("tool", "def456")],
+    );
+
+    expect(diff.added.map((c) => c.name)).toEqual(["axios"]);
+    expect(diff.removed.map((c) => c.name)).toEqual(["left-pad"]);
+    expect(diff.upgraded).toEqual([{ name: "lodash", from: "4.17.20", to: "4.17.21" }]);
+    expect(diff.downgraded).toEqual([{ name: "react", from: "18.2.0", to: "17.0.2" }]);
+    expect(diff.changed).toEqual([{ name: "tool", from: "abc123", to: "def456" }]);
+  });
+
+  it("tracks license and vulnerability deltas and who introduced new vulnerabilities", () => {
+    const diff = diffSboms(
+      [pkg("lodash", "4.17.20", { license: "MIT", vulnerabilities: 1 }), pkg("zlib", "1.2.13", { license: "Zlib", vulnerabilities: 0 })],
+      [
+        pkg("lodash", "4.17.21", { license: "MIT", vulnerabilities: 0 }),
+        pkg("zlib", "1.2.13", { license: "MIT", vulnerabilities: 0 }),
+        pkg("gpl-thing", "2.0.0", { license: "GPL-3.0-only", vulnerabilities: 3 }),
+      ],
+    );
+
+    expect(diff.relicensed).toEqual([{ name: "zlib", from: "1.2.13", to: "1.2.13", license_before: "Zlib", license_after: "MIT" }]);
+    expect(diff.licenses).toEqual({ introduced: ["GPL-3.0-only"], dropped: ["Zlib"] });
+    expect(diff.vulnerabilities).toEqual({ base: 1, target: 3, delta: 2, introduced_by: [{ name: "gpl-thing@2.0.0", vulnerabilities: 3 }] });
+  });
+
+  it("compares a package shipped in several versions version by version", () => {
+    const diff = diffSboms(
+      [pkg("semver", "5.7.1"), pkg("semver", "7.5.0")],
+      [pkg("semver", "7.5.0"), pkg("semver", "7.6.0"), pkg("semver", "6.3.1")],
+    );
+
+    expect(diff.upgraded).toEqual([{ name: "semver", from: "5.7.1", to: "7.6.0" }]);
+    expect(diff.added.map((c) => c.version)).toEqual(["6.3.1"]);
+    expect(diff.removed).toEqual([]);
+  });
+});
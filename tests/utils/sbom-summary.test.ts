@@ -0,0 +1,79 @@
+import { describe, it, expect } from "vitest";
+import { summarizeSbom } from "../../src/utils/sbom-summary.js";
+
+describe("summarizeSbom", () => {
+  it("summarizes CycloneDX JSON, counting nested components and license expressions", () => {
+    const summary = summarizeSbom(JSON.stringify({
+      bomFormat: "CycloneDX",
+      specVersion: "1.5",
+      metadata: {
+        timestamp: "2026-10-01T12:00:00Z",
+        tools: { components: [{ name: "syft", version: "1.4.1" }] },
+        component: { name: "web", version: "2.0.0" },
+      },
+      components: [
+        { name: "lodash", licenses: [{ license: { id: "MIT" } }] },
+        { name: "react", licenses: [{ license: { id: "MIT" } }], components: [{ name: "scheduler", licenses: [{ expression: "MIT OR Apache-2.0" }] }] },
+        { name: "mystery" },
+      ],
+    }));
+
+    expect(summary).toEqual({
+      format: "CycloneDX",
+      encoding: "json",
+      spec_version: "1.5",
+      name: "web@2.0.0",
+      created: "2026-10-01T12:00:00Z",
+      tools: ["syft 1.4.1"],
+      component_count: 4,
+      licenses: { distinct: 2, unlicensed: 1, top: [{ license: "MIT", components: 2 }, { license: "MIT OR Apache-2.0", components: 1 }] },
+    });
+  });
+
+  it("summarizes SPDX JSON, falling back to the declared license", () => {
+    const summary = summarizeSbom({
+      spdxVersion: "SPDX-2.3",
+      name: "web-2.0.0",
+      creationInfo: { created: "2026-10-01T12:00:00Z", creators: ["Organization: Harness", "Tool: syft-1.4.1"] },
+      packages: [
+        { name: "lodash", licenseConcluded: "MIT" },
+        { name: "zlib", licenseConcluded: "NOASSERTION", licenseDeclared: "Zlib" },
+        { name: "blob", licenseConcluded: "NOASSERTION" },
+      ],
+    });
+
+    expect(summary).toMatchObject({ format: "SPDX", encoding: "json", spec_version: "2.3", name: "web-2.0.0", tools: ["syft-1.4.1"], component_count: 3 });
+    expect(summary.licenses).toEqual({ distinct: 2, unlicensed: 1, top: [{ license: "MIT", components: 1 }, { license: "Zlib", components: 1 }] });
+  });
+
+  it("summarizes SPDX tag-value and CycloneDX XML", () => {
+    const tagValue = summarizeSbom([
+      "SPDXVersion: SPDX-2.3",
+      "DocumentName: web",
+      "Creator: Tool: cdxgen-10",
+      "PackageName: lodash",
+      "PackageLicenseConcluded: MIT",
+      "PackageName: zlib",
+      "PackageLicenseConcluded: NOASSERTION",
+    ].join("\n"));
+    expect(tagValue).toMatchObject({ format: "SPDX", encoding: "tag-value", spec_version: "2.3", name: "web", tools: ["cdxgen-10"], component_count: 2 });
+    expect(tagValue.licenses.unlicensed).toBe(1);
+
+    const xml = summarizeSbom(`<?xml version="1.0"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.4" version="1">
+  <metadata><timestamp>2026-10-01T12:00:00Z</timestamp><tools><tool><name>trivy</name><version>0.50</version></tool></tools></metadata>
+  <components>
+    <component type="library"><name>lodash</name><licenses><license><id>MIT</id></license></licenses></component>
+    <component type="library"><name>left-pad</name></component>
+  </components>
+</bom>`);
+    expect(xml).toMatchObject({ format: "CycloneDX", encoding: "xml", spec_version: "1.4", created: "2026-10-01T12:00:00Z", tools: ["trivy 0.50"], component_count: 2 });
+    expect(xml.licenses).toEqual({ distinct: 1, unlicensed: 1, top: [{ license: "MIT", components: 1 }] });
+  });
+
+  it("rejects documents that are not SBOMs", () => {
+    expect(() => summarizeSbom("hello")).toThrow(/neither CycloneDX nor SPDX/);
+    expect(() => summarizeSbom({ kind: "Deployment" })).toThrow(/bomFormat/);
+    expect(() => summarizeSbom("{ not json")).toThrow(/does not parse/);
+  });
+});
@@ -0,0 +1,42 @@
+import { describe, it, expect } from "vitest";
+import { describeScopeEscalation, detectScopeEscalation, isCrossScopeAllowed } from "../../src/utils/scope-guard.js";
+
+const pinned = { org: "default", project: "web" };
+
+describe("detectScopeEscalation", () => {
+  it("flags org and project that differ from the pinned scope", () => {
+    expect(detectScopeEscalation("project", { org_id: "other", project_id: "api" }, pinned)).toEqual([
+      { field: "org_id", requested: "other", pinned: "default" },
+      { field: "project_id", requested: "api", pinned: "web" },
+    ]);
+  });
+
+  it("only checks identifiers the scope sends", () => {
+    expect(detectScopeEscalation("org", { project_id: "api" }, pinned)).toEqual([]);
+    expect(detectScopeEscalation("account", { org_id: "other" }, pinned)).toEqual([]);
+    expect(detectScopeEscalation("project", { org_id: "other", resource_scope: "account" }, pinned)).toEqual([]);
+  });
+
+  it("ignores matching, empty, and unpinned values", () => {
+    expect(detectScopeEscalation("project", { org_id: "default", project_id: "web" }, pinned)).toEqual([]);
+    expect(detectScopeEscalation("project", { org_id: "", project_id: undefined }, pinned)).toEqual([]);
+    expect(detectScopeEscalation("project", { org_id: "other", project_id: "api" }, {})).toEqual([]);
+  });
+});
+
+describe("isCrossScopeAllowed", () => {
+  it("accepts boolean and string true", () => {
+    expect(isCrossScopeAllowed({ cross_scope: true })).toBe(true);
+    expect(isCrossScopeAllowed({ cross_scope: "true" })).toBe(true);
+    expect(isCrossScopeAllowed({ cross_scope: "yes" })).toBe(false);
+    expect(isCrossScopeAllowed({})).toBe(false);
+  });
+});
+
+describe("describeScopeEscalation", () => {
+  it("names each escalated field and the opt-in", () => {
+    const message = describeScopeEscalation([{ field: "project_id", requested: "api", pinned: "web" }]);
+    expect(message).toContain('project_id "api" (session is pinned to "web")');
+    expect(message).toContain("cross_scope: true");
+  });
+});
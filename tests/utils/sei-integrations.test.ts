@@ -0,0 +1,30 @@
+import { describe, it, expect } from "vitest";
+import { integrationHealth, integrationRows } from "../../src/utils/sei-integrations.js";
+
+const NOW = Date.parse("2026-10-16T12:00:00Z");
+
+describe("integrationHealth", () => {
+  it("marks integrations healthy, stale, or failing from status, last ingestion, and errors", () => {
+    expect(integrationHealth({ id: 1, name: "Jira Cloud", application: "jira", status: "ACTIVE", lastIngestedAt: "2026-10-16T09:00:00Z" }, NOW)).toEqual({
+      integration_id: "1",
+      name: "Jira Cloud",
+      type: "jira",
+      status: "ACTIVE",
+      last_ingested_at: "2026-10-16T09:00:00.000Z",
+      hours_since_ingestion: 3,
+      health: "healthy",
+    });
+    expect(integrationHealth({ id: 2, lastIngestionTime: NOW / 1000 - 3 * 24 * 3600 }, NOW)).toMatchObject({ hours_since_ingestion: 72, health: "stale" });
+    expect(integrationHealth({ id: 3, status: "AUTH_FAILED" }, NOW).health).toBe("failing");
+    expect(integrationHealth({ id: 4, ingestionStatus: { errorCount: 5, lastError: "401 Unauthorized" } }, NOW)).toMatchObject({ error_count: 5, last_error: "401 Unauthorized", health: "failing" });
+    expect(integrationHealth({ id: 5, errorCount: 5 }, NOW)).toMatchObject({ error_count: 5, health: "unknown" });
+  });
+});
+
+describe("integrationRows", () => {
+  it("finds the integration list under the usual wrapper keys", () => {
+    expect(integrationRows({ data: { content: [{ id: 1 }, "x"] } })).toEqual([{ id: 1 }]);
+    expect(integrationRows([{ id: 2 }])).toEqual([{ id: 2 }]);
+    expect(integrationRows(null)).toEqual([]);
+  });
+});
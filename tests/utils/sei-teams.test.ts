@@ -0,0 +1,41 @@
+import { describe, it, expect } from "vitest";
+import { flattenTeams, matchTeams } from "../../src/utils/sei-teams.js";
+
+const TREE = {
+  data: {
+    refId: 1,
+    name: "Engineering",
+    children: [
+      { refId: 10, name: "Payments", children: [{ refId: 11, name: "Payments API", leaf: true }] },
+      { refId: 20, name: "Platform", children: [{ refId: 21, name: "Payments Infra", leaf: true }] },
+    ],
+  },
+};
+
+describe("flattenTeams", () => {
+  it("walks an org tree hierarchy, keeping each team's ancestors", () => {
+    expect(flattenTeams(TREE)).toEqual([
+      { team_ref_id: "1", name: "Engineering", leaf: false },
+      { team_ref_id: "10", name: "Payments", path: ["Engineering"], parent_ref_id: "1", leaf: false },
+      { team_ref_id: "11", name: "Payments API", path: ["Engineering", "Payments"], parent_ref_id: "10", leaf: true },
+      { team_ref_id: "20", name: "Platform", path: ["Engineering"], parent_ref_id: "1", leaf: false },
+      { team_ref_id: "21", name: "Payments Infra", path: ["Engineering", "Platform"], parent_ref_id: "20", leaf: true },
+    ]);
+  });
+
+  it("reads flat team lists under the usual wrapper keys", () => {
+    expect(flattenTeams({ content: [{ teamRefId: "7", teamName: "Mobile", parentRefId: "1" }] }))
+      .toEqual([{ team_ref_id: "7", name: "Mobile", parent_ref_id: "1" }]);
+    expect(flattenTeams([{ id: 3, name: "Web" }])).toEqual([{ team_ref_id: "3", name: "Web" }]);
+    expect(flattenTeams({ data: [] })).toEqual([]);
+  });
+});
+
+describe("matchTeams", () => {
+  it("matches every word against the name and ancestors, exact names first", () => {
+    const teams = flattenTeams(TREE);
+    expect(matchTeams(teams, "payments").map((t) => t.team_ref_id)).toEqual(["10", "11", "21"]);
+    expect(matchTeams(teams, "platform payments").map((t) => t.team_ref_id)).toEqual(["21"]);
+    expect(matchTeams(teams, "payments api").map((t) => t.team_ref_id)).toEqual(["11"]);
+  });
+});
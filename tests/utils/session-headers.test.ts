@@ -150,6 +150,23 @@ describe("multi-user session credentials", () => {
     ).toThrow(MissingSessionCredentialsError);
   });
 
+  it("uses an OAuth principal's token and account claim when no API key header is sent", () => {
+    const base = makeConfig({ HARNESS_MCP_MODE: "multi-user", HARNESS_API_KEY: "", HARNESS_ACCOUNT_ID: "" });
+    const principal = { subject: "user@example.com", accountId: "acct-oauth", scopes: [], token: "oauth-access-token" };
+
+    const merged = mergeConfigWithSessionHeaders(base, {}, principal);
+    expect(merged.HARNESS_API_KEY).toBe("oauth-access-token");
+    expect(merged.HARNESS_ACCOUNT_ID).toBe("acct-oauth");
+    expect(merged.HARNESS_API_AUTH_SCHEME).toBe("bearer");
+
+    const explicit = mergeConfigWithSessionHeaders(base, { "x-harness-api-key": "pat.user1.tok.sec" }, principal);
+    expect(explicit.HARNESS_API_KEY).toBe("pat.user1.tok.sec");
+    expect(explicit.HARNESS_API_AUTH_SCHEME).toBeUndefined();
+
+    const single = mergeConfigWithSessionHeaders(makeConfig(), {}, principal);
+    expect(single.HARNESS_API_KEY).toBe("pat.test.abc.xyz");
+  });
+
   it("derives account ID from a PAT when x-harness-account-id is missing in multi-user mode", () => {
     const base = makeConfig({ HARNESS_MCP_MODE: "multi-user", HARNESS_API_KEY: "", HARNESS_ACCOUNT_ID: "" });
     const merged = mergeConfigWithSessionHeaders(base, {
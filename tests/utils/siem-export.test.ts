@@ -0,0 +1,161 @@
+import { describe, it, expect, afterEach, vi } from "vitest";
+import { existsSync, mkdtempSync, readFileSync, rmSync } from "node:fs";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { formatSiemRecords, toCefLine, toOcsfApiActivity, writeSiemExport } from "../../src/utils/siem-export.js";
+import { auditSiemExportExtract, auditSiemExportWrite } from "../../src/registry/extractors.js";
+import { Registry } from "../../src/registry/index.js";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+
+function makeEvent(overrides: Record<string, unknown> = {}): Record<string, unknown> {
+  return {
+    auditId: "audit-1",
+    timestamp: Date.UTC(2025, 6, 10, 8, 0, 0),
+    module: "CORE",
+    action: "DELETE",
+    resourceScope: { accountIdentifier: "acct", orgIdentifier: "default", projectIdentifier: "web" },
+    authenticationInfo: {
+      principal: { type: "USER", identifier: "u-1", email: "dev@example.com" },
+      labels: { username: "Dev User" },
+    },
+    requestMetadata: { clientIP: "10.0.0.7" },
+    httpRequestInfo: { requestMethod: "DELETE" },
+    resource: { type: "PIPELINE", identifier: "deploy", labels: { resourceName: "Deploy" } },
+    ...overrides,
+  };
+}
+
+describe("toOcsfApiActivity", () => {
+  it("maps an audit event to an OCSF API Activity record", () => {
+    const record = toOcsfApiActivity(makeEvent());
+    expect(record).toMatchObject({
+      class_uid: 6003,
+      category_uid: 6,
+      activity_id: 4,
+      activity_name: "DELETE",
+      type_uid: 600304,
+      severity_id: 1,
+      status_id: 1,
+      time: Date.UTC(2025, 6, 10, 8, 0, 0),
+      metadata: { uid: "audit-1", product: { vendor_name: "Harness" } },
+      actor: { user: { uid: "u-1", type: "USER", name: "Dev User", email_addr: "dev@example.com" } },
+      api: { operation: "DELETE", service: { name: "CORE" }, request: { method: "DELETE" } },
+      src_endpoint: { ip: "10.0.0.7" },
+      resources: [{ type: "PIPELINE", uid: "deploy", name: "Deploy" }],
+      unmapped: { account_id: "acct", org_id: "default", project_id: "web" },
+    });
+  });
+
+  it("marks unsuccessful logins as elevated failures", () => {
+    const record = toOcsfApiActivity(makeEvent({ action: "UNSUCCESSFUL_LOGIN" }));
+    expect(record).toMatchObject({ activity_id: 99, type_uid: 600399, severity_id: 3, status_id: 2, status: "Failure" });
+  });
+});
+
+describe("toCefLine", () => {
+  it("renders a CEF:0 line with escaped header and extension values", () => {
+    const line = toCefLine(makeEvent({
+      action: "UPDATE",
+      resource: { type: "PIPELINE", identifier: "a=b" },
+      authenticationInfo: { principal: { type: "USER", identifier: "u|1" } },
+    }));
+    expect(line.startsWith("CEF:0|Harness|Harness Platform|1.0|UPDATE|UPDATE PIPELINE|3|")).toBe(true);
+    expect(line).toContain("suser=u|1");
+    expect(line).toContain("cs2=a\\=b");
+    expect(line).toContain("cs4=acct/default/web");
+    expect(line).toContain("outcome=success");
+  });
+
+  it("escapes pipes in header fields", () => {
+    const line = toCefLine(makeEvent({ action: "A|B" }));
+    expect(line).toContain("|A\\|B|A\\|B PIPELINE|");
+  });
+});
+
+describe("formatSiemRecords", () => {
+  it("skips non-object items", () => {
+    expect(formatSiemRecords([makeEvent(), null, "x"], "cef")).toHaveLength(1);
+  });
+});
+
+describe("writeSiemExport", () => {
+  let dir: string | undefined;
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+  });
+
+  it("writes NDJSON for OCSF under the output directory", () => {
+    dir = mkdtempSync(join(tmpdir(), "siem-"));
+    const file = writeSiemExport(join(dir, "nested"), "ocsf", { startTime: 1, endTime: 2, page: 0 }, [{ a: 1 }, { b: 2 }]);
+    expect(file).toBe(join(dir, "nested", "harness-audit-1-2-p0.ocsf.jsonl"));
+    expect(readFileSync(file, "utf8")).toBe('{"a":1}\n{"b":2}\n');
+  });
+
+  it("rejects relative output directories", () => {
+    expect(() => writeSiemExport("exports", "cef", { startTime: 1, endTime: 2, page: 0 }, [])).toThrow(/absolute/);
+  });
+});
+
+describe("auditSiemExportExtract", () => {
+  let dir: string | undefined;
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+  });
+
+  const raw = { status: "SUCCESS", data: { content: [makeEvent(), makeEvent({ auditId: "audit-2" })], totalElements: 5 } };
+  const window = { start_time: "2025-07-10T00:00:00Z", end_time: "2025-07-11T00:00:00Z" };
+
+  it("returns formatted records inline with paging state", () => {
+    const result = auditSiemExportExtract(raw, { ...window, page: 0, size: 2 }) as Record<string, unknown>;
+    expect(result).toMatchObject({ format: "ocsf", page: 0, page_size: 2, total: 5, has_more: true });
+    expect(result.items).toHaveLength(2);
+    expect(result._nextPageHint).toContain("page=1");
+  });
+
+  it("leaves the records inline for auditSiemExportWrite, which writes them to output_dir", () => {
+    dir = mkdtempSync(join(tmpdir(), "siem-"));
+    const input = { ...window, page: 2, size: 2, format: "cef", output_dir: dir };
+    const extracted = auditSiemExportExtract(raw, input) as Record<string, unknown>;
+    expect(extracted.items).toHaveLength(2);
+    expect(extracted.file).toBeUndefined();
+
+    const result = auditSiemExportWrite(extracted, input) as Record<string, unknown>;
+    expect(result).toMatchObject({ format: "cef", has_more: false, exported: 2, items: [] });
+    const lines = readFileSync(String(result.file), "utf8").trim().split("\n");
+    expect(lines).toHaveLength(2);
+    expect(lines[0]).toMatch(/^CEF:0\|Harness\|/);
+  });
+});
+
+describe("audit_export with output_dir", () => {
+  let dir: string | undefined;
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+    dir = undefined;
+  });
+
+  it("writes the file again when the page is answered from the response cache", async () => {
+    dir = mkdtempSync(join(tmpdir(), "siem-"));
+    const request = vi.fn().mockResolvedValue({ status: "SUCCESS", data: { content: [makeEvent()], totalElements: 1 } });
+    const client = { request, account: "test-account" } as unknown as HarnessClient;
+    const registry = new Registry({
+      HARNESS_API_KEY: "pat.test",
+      HARNESS_ACCOUNT_ID: "test-account",
+      HARNESS_BASE_URL: "https://app.harness.io",
+      HARNESS_TOOLSETS: "audit",
+      HARNESS_CACHE_TTL_MS: 60_000,
+    } as Config);
+    const input = { start_time: "2025-07-10T00:00:00Z", end_time: "2025-07-11T00:00:00Z", output_dir: dir };
+
+    const first = await registry.dispatch(client, "audit_export", "list", { ...input }) as Record<string, unknown>;
+    rmSync(String(first.file));
+    const second = await registry.dispatch(client, "audit_export", "list", { ...input }) as Record<string, unknown>;
+
+    expect(request).toHaveBeenCalledOnce();
+    expect(second.file).toBe(first.file);
+    expect(existsSync(String(second.file))).toBe(true);
+  });
+});
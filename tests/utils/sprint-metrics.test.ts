@@ -0,0 +1,82 @@
+import { describe, it, expect } from "vitest";
+import { burndownPoints, burndownSummary, carryOverAnalysis, sprintStats, velocityTrend } from "../../src/utils/sprint-metrics.js";
+
+const SPRINTS = {
+  data: {
+    sprints: [
+      { sprintName: "Sprint 3", startDate: "2026-08-24", endDate: "2026-09-06", committedPoints: 40, completedPoints: 36 },
+      { sprintName: "Sprint 1", startDate: "2026-07-27", endDate: "2026-08-09", committedPoints: 30, completedPoints: 20, carriedOver: 10 },
+      { sprintName: "Sprint 2", startDate: "2026-08-10", endDate: "2026-08-23", committedPoints: 32, completedPoints: 28, scopeAdded: 4 },
+    ],
+  },
+};
+
+describe("sprintStats", () => {
+  it("finds nested sprint rows, orders them by start, and derives carry-over from unfinished work", () => {
+    expect(sprintStats(SPRINTS)).toEqual([
+      { sprint: "Sprint 1", start: "2026-07-27", end: "2026-08-09", committed: 30, completed: 20, carried_over: 10 },
+      { sprint: "Sprint 2", start: "2026-08-10", end: "2026-08-23", committed: 32, completed: 28, carried_over: 8, added: 4 },
+      { sprint: "Sprint 3", start: "2026-08-24", end: "2026-09-06", committed: 40, completed: 36, carried_over: 4 },
+    ]);
+    expect(sprintStats({ data: [] })).toEqual([]);
+  });
+});
+
+describe("velocityTrend", () => {
+  it("reports say/do per sprint and the direction of completed work", () => {
+    const result = velocityTrend(sprintStats(SPRINTS));
+
+    expect(result.average_velocity).toBe(28);
+    expect(result.average_say_do_pct).toBe(81.4);
+    expect(result.trend).toBe("rising");
+    expect(result.change_per_sprint).toBe(8);
+    expect((result.sprints as unknown[])[0]).toEqual({ sprint: "Sprint 1", end: "2026-08-09", committed: 30, completed: 20, say_do_pct: 66.7 });
+  });
+
+  it("calls small changes flat and needs three sprints for a trend", () => {
+    const flat = [40, 41, 40, 39].map((completed, i) => ({ sprint: `S${i}`, completed }));
+    expect(velocityTrend(flat).trend).toBe("flat");
+    expect(velocityTrend(flat.slice(0, 2))).toMatchObject({ trend: "insufficient_data", average_velocity: 40.5 });
+  });
+});
+
+describe("carryOverAnalysis", () => {
+  it("measures carry-over against committed plus added work and flags chronic overcommitment", () => {
+    const result = carryOverAnalysis(sprintStats(SPRINTS));
+
+    expect(result.sprints).toEqual([
+      { sprint: "Sprint 1", committed: 30, carried_over: 10, carry_over_pct: 33.3, overcommitted: true },
+      { sprint: "Sprint 2", committed: 32, added: 4, carried_over: 8, carry_over_pct: 22.2, overcommitted: true },
+      { sprint: "Sprint 3", committed: 40, carried_over: 4, carry_over_pct: 10 },
+    ]);
+    expect(result).toMatchObject({ total_carried_over: 22, average_carry_over_pct: 21.8, overcommitted_sprints: 2, chronic: true });
+  });
+});
+
+describe("burndownSummary", () => {
+  const points = burndownPoints({
+    burndown: [
+      { date: "2026-10-05", remaining: 40 },
+      { date: "2026-10-01", remaining: 50 },
+      { date: "2026-10-09", remaining: 35 },
+    ],
+  });
+
+  it("compares the latest remaining work with the ideal line to sprint end", () => {
+    expect(points.map((p) => p.date)).toEqual(["2026-10-01", "2026-10-05", "2026-10-09"]);
+    expect(burndownSummary(points, "2026-10-11")).toMatchObject({
+      starting_scope: 50,
+      remaining: 35,
+      burned: 15,
+      elapsed_pct: 80,
+      ideal_remaining: 10,
+      status: "behind",
+    });
+  });
+
+  it("reports added scope and finished sprints", () => {
+    const grown = [{ date: "2026-10-01", remaining: 20 }, { date: "2026-10-03", remaining: 26 }, { date: "2026-10-10", remaining: 0 }];
+    expect(burndownSummary(grown)).toMatchObject({ scope_added: 6, burned: 26, status: "done" });
+    expect(burndownSummary([])).toEqual({ points: [], status: "no_data" });
+  });
+});
@@ -0,0 +1,30 @@
+import { describe, it, expect } from "vitest";
+import { stoOccurrence, stoReferenceIds, stoRemediation } from "../../src/utils/sto-issue.js";
+
+describe("stoReferenceIds", () => {
+  it("prefixes bare IDs with their type and keeps prefixed ones", () => {
+    expect(stoReferenceIds([
+      { type: "cve", id: "2021-44228" },
+      { type: "CVE", id: "CVE-2021-44228" },
+      { type: "cwe", id: 79 },
+      "GHSA-jfh8-c2jp-5v3q",
+      { type: "cwe" },
+    ])).toEqual(["CVE-2021-44228", "CWE-79", "GHSA-jfh8-c2jp-5v3q"]);
+    expect(stoReferenceIds(undefined)).toEqual([]);
+  });
+});
+
+describe("stoRemediation", () => {
+  it("reads the first remediation field present, joining step lists", () => {
+    expect(stoRemediation({ remediationSteps: ["Upgrade to 2.17.1", "Redeploy"] })).toBe("Upgrade to 2.17.1\nRedeploy");
+    expect(stoRemediation({ details: { recommendation: "Rotate the secret" } })).toBe("Rotate the secret");
+    expect(stoRemediation({ remediation: "  " })).toBeUndefined();
+  });
+});
+
+describe("stoOccurrence", () => {
+  it("reduces a row to its location or component", () => {
+    expect(stoOccurrence({ id: "o1", file: "src/app.ts", line: 12, targetName: "web" })).toEqual({ id: "o1", location: "src/app.ts:12", target: "web" });
+    expect(stoOccurrence({ packageName: "lodash", version: "4.17.20", fixVersion: "4.17.21" })).toEqual({ component: "lodash@4.17.20", fixed_in: "4.17.21" });
+  });
+});
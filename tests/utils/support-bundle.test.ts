@@ -0,0 +1,189 @@
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from "node:fs";
+import { tmpdir } from "node:os";
+import { join } from "node:path";
+import { gunzipSync } from "node:zlib";
+import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
+import type { Config } from "../../src/config.js";
+import type { HarnessClient } from "../../src/client/harness-client.js";
+import { clearRecentLogEntries, createLogger } from "../../src/utils/logger.js";
+import { HarnessApiError } from "../../src/utils/errors.js";
+import { errorResult, jsonResult } from "../../src/utils/response-formatter.js";
+import {
+  collectSupportBundle,
+  sanitizeConfig,
+  scrubSecrets,
+  tarGz,
+  TOOL_ERROR_MSG,
+  withToolErrorLogging,
+  writeSupportBundle,
+} from "../../src/utils/support-bundle.js";
+
+const API_KEY = "pat.test-account.abc123.def456";
+
+function makeConfig(overrides: Partial<Config> = {}): Config {
+  return {
+    HARNESS_API_KEY: API_KEY,
+    HARNESS_ACCOUNT_ID: "test-account",
+    HARNESS_BASE_URL: "https://app.harness.io",
+    HARNESS_ORG: "default",
+    HARNESS_PROJECT: "test-project",
+    HARNESS_API_TIMEOUT_MS: 30000,
+    HARNESS_MAX_RETRIES: 3,
+    HARNESS_MAX_BODY_SIZE_MB: 10,
+    HARNESS_RATE_LIMIT_RPS: 10,
+    HARNESS_READ_ONLY: false,
+    HARNESS_SKIP_ELICITATION: false,
+    HARNESS_ALLOW_HTTP: false,
+    HARNESS_FME_BASE_URL: "https://api.split.io",
+    LOG_LEVEL: "info",
+    HARNESS_MCP_AUTH_TOKEN: "http-bearer-secret-value",
+    ...overrides,
+  };
+}
+
+/** name → content for every file in a .tar.gz written by tarGz. */
+function untar(archive: Buffer): Record<string, string> {
+  const tar = gunzipSync(archive);
+  const files: Record<string, string> = {};
+  let offset = 0;
+  while (offset + 512 <= tar.length && tar[offset] !== 0) {
+    const name = tar.subarray(offset, offset + 100).toString("ascii").replace(/\0.*$/s, "");
+    const size = parseInt(tar.subarray(offset + 124, offset + 136).toString("ascii"), 8);
+    files[name] = tar.subarray(offset + 512, offset + 512 + size).toString("utf-8");
+    offset += 512 + Math.ceil(size / 512) * 512;
+  }
+  return files;
+}
+
+let dir: string;
+
+beforeEach(() => {
+  dir = mkdtempSync(join(tmpdir(), "support-bundle-"));
+  clearRecentLogEntries();
+  vi.spyOn(console, "error").mockImplementation(() => {});
+});
+
+afterEach(() => {
+  rmSync(dir, { recursive: true, force: true });
+  vi.restoreAllMocks();
+});
+
+describe("sanitizeConfig and scrubSecrets", () => {
+  it("redacts secret settings and drops unset ones", () => {
+    const config = sanitizeConfig(makeConfig({ HARNESS_FME_API_KEY: undefined }));
+    expect(config.HARNESS_API_KEY).toBe("[REDACTED]");
+    expect(config.HARNESS_MCP_AUTH_TOKEN).toBe("[REDACTED]");
+    expect(config.HARNESS_BASE_URL).toBe("https://app.harness.io");
+    expect(config).not.toHaveProperty("HARNESS_FME_API_KEY");
+  });
+
+  it("redacts result processor rules, which carry webhook URLs and headers", () => {
+    const rules = JSON.stringify([{ webhook: "https://hooks.example.com/enrich", headers: { Authorization: "Bearer hook-secret" } }]);
+    expect(sanitizeConfig(makeConfig({ HARNESS_RESULT_PROCESSORS: rules })).HARNESS_RESULT_PROCESSORS).toBe("[REDACTED]");
+  });
+
+  it("removes API keys, bearer tokens, and known secret values from text", () => {
+    expect(scrubSecrets(`key ${API_KEY} sent with Authorization: Bearer abc.def-ghi and hunter2hunter2`, ["hunter2hunter2"]))
+      .toBe("key [REDACTED] sent with Authorization: Bearer [REDACTED] and [REDACTED]");
+  });
+});
+
+describe("tarGz", () => {
+  it("writes a gzipped ustar archive that round-trips", () => {
+    const files = untar(tarGz({ "a/one.json": "{}\n", "a/empty.log": "", "a/big.txt": "x".repeat(1000) }));
+    expect(files).toEqual({ "a/one.json": "{}\n", "a/empty.log": "", "a/big.txt": "x".repeat(1000) });
+  });
+});
+
+describe("withToolErrorLogging", () => {
+  it("logs error results so they land in the bundle's tool errors", async () => {
+    const registerTool = vi.fn();
+    const server = withToolErrorLogging({ registerTool } as never);
+    server.registerTool("harness_get", {} as never, (async () => errorResult(`Unauthorized for ${API_KEY}`)) as never);
+    server.registerTool("harness_list", {} as never, (async () => jsonResult({ items: [] })) as never);
+    const failing = registerTool.mock.calls[0]![2] as (args: Record<string, unknown>, extra: unknown) => Promise<unknown>;
+    const passing = registerTool.mock.calls[1]![2] as (args: Record<string, unknown>, extra: unknown) => Promise<unknown>;
+    await failing({ resource_type: "pipeline" }, {});
+    await passing({ resource_type: "pipeline" }, {});
+
+    const bundle = await collectSupportBundle({ config: makeConfig(), logFile: join(dir, "missing.log") });
+    expect(bundle.tool_errors).toHaveLength(1);
+    expect(bundle.tool_errors[0]).toMatchObject({ msg: TOOL_ERROR_MSG, tool: "harness_get", resource_type: "pipeline" });
+    expect(JSON.stringify(bundle)).not.toContain(API_KEY);
+  });
+});
+
+describe("collectSupportBundle", () => {
+  it("includes sanitized log file lines and in-memory logs", async () => {
+    const logFile = join(dir, "harness-mcp.log");
+    writeFileSync(logFile, [
+      JSON.stringify({ level: "error", msg: "stdin closed", token: "raw-token" }),
+      `plain text with ${API_KEY}`,
+      "",
+    ].join("\n"));
+    createLogger("test").info("request", { authorization: "Bearer xyz", detail: "http-bearer-secret-value" });
+
+    const bundle = await collectSupportBundle({ config: makeConfig(), logFile });
+
+    expect(bundle.logs).toHaveLength(3);
+    expect(JSON.parse(bundle.logs[0]!)).toMatchObject({ msg: "stdin closed", token: "[REDACTED]" });
+    expect(bundle.logs[1]).toBe("plain text with [REDACTED]");
+    expect(JSON.parse(bundle.logs[2]!)).toMatchObject({ module: "test", authorization: "[REDACTED]", detail: "[REDACTED]" });
+    expect(bundle.connectivity).toEqual({ skipped: "no Harness client" });
+  });
+
+  it("scrubs result processor webhook URLs and header values from logs", async () => {
+    const rules = JSON.stringify([{ webhook: "https://hooks.example.com/enrich", headers: { "X-Hook-Auth": "hook-shared-secret" } }]);
+    const logFile = join(dir, "harness-mcp.log");
+    writeFileSync(logFile, "webhook https://hooks.example.com/enrich rejected hook-shared-secret\n");
+
+    const bundle = await collectSupportBundle({ config: makeConfig({ HARNESS_RESULT_PROCESSORS: rules }), logFile });
+
+    expect(bundle.logs).toEqual(["webhook [REDACTED] rejected [REDACTED]"]);
+  });
+
+  it("records connectivity results, including rejected credentials", async () => {
+    const client = {
+      account: "test-account",
+      request: vi.fn(async (opts: Record<string, any>) => {
+        if (opts.path === "/ng/api/user/currentUser") return { data: { uuid: "u1" } };
+        throw new HarnessApiError(`Invalid API key ${API_KEY}`, 401);
+      }),
+    } as unknown as HarnessClient;
+
+    const bundle = await collectSupportBundle({ config: makeConfig(), client, logFile: join(dir, "missing.log") });
+
+    expect(bundle.connectivity).toEqual([
+      expect.objectContaining({ name: "harness_api", ok: true }),
+      expect.objectContaining({ name: "harness_account", ok: false, status: 401, error: "Invalid API key [REDACTED]" }),
+    ]);
+    expect(client.request).toHaveBeenCalledWith(expect.objectContaining({ path: "/ng/api/accounts/test-account" }));
+  });
+
+  it("records a config that failed to load instead of checking connectivity", async () => {
+    const bundle = await collectSupportBundle({ config: new Error("Invalid configuration:\n  HARNESS_API_KEY: required"), logFile: join(dir, "missing.log") });
+    expect(bundle.config).toEqual({ error: "Invalid configuration:\n  HARNESS_API_KEY: required" });
+    expect(bundle.connectivity).toEqual({ skipped: "configuration did not load" });
+  });
+});
+
+describe("writeSupportBundle", () => {
+  it("writes every section under one directory in a timestamped archive", async () => {
+    const bundle = await collectSupportBundle({ config: makeConfig(), logFile: join(dir, "missing.log") });
+    bundle.generated_at = "2026-03-01T12:30:45.123Z";
+
+    const path = writeSupportBundle(bundle, join(dir, "out"));
+
+    expect(path).toBe(join(dir, "out", "harness-support-20260301T123045Z.tar.gz"));
+    const files = untar(readFileSync(path));
+    expect(Object.keys(files).sort()).toEqual([
+      "harness-support-20260301T123045Z/config.json",
+      "harness-support-20260301T123045Z/connectivity.json",
+      "harness-support-20260301T123045Z/server.log",
+      "harness-support-20260301T123045Z/tool-errors.json",
+      "harness-support-20260301T123045Z/version.json",
+    ]);
+    expect(JSON.parse(files["harness-support-20260301T123045Z/version.json"]!)).toMatchObject({ generated_at: "2026-03-01T12:30:45.123Z", node: process.version });
+    expect(JSON.parse(files["harness-support-20260301T123045Z/config.json"]!).HARNESS_API_KEY).toBe("[REDACTED]");
+  });
+});
@@ -0,0 +1,33 @@
+import { describe, expect, it } from "vitest";
+import { diffLines } from "../../src/utils/text-diff.js";
+
+describe("diffLines", () => {
+  it("returns an empty diff for identical text", () => {
+    expect(diffLines("a\nb\n", "a\nb")).toEqual({ diff: "", added: 0, removed: 0 });
+  });
+
+  it("emits one hunk with context around a change", () => {
+    const before = ["a", "b", "c", "d", "e", "f", "g", "h"].join("\n");
+    const after = ["a", "b", "c", "d", "E", "f", "g", "h"].join("\n");
+    expect(diffLines(before, after, 2)).toEqual({
+      diff: ["@@ -3,5 +3,5 @@", " c", " d", "-e", "+E", " f", " g"].join("\n"),
+      added: 1,
+      removed: 1,
+    });
+  });
+
+  it("splits distant changes into separate hunks and merges close ones", () => {
+    const before = Array.from({ length: 20 }, (_, i) => `line${i}`);
+    const after = [...before];
+    after[2] = "changed2";
+    after[4] = "changed4";
+    after[17] = "changed17";
+    const { diff } = diffLines(before.join("\n"), after.join("\n"), 1);
+    expect(diff.split("\n").filter((l) => l.startsWith("@@"))).toEqual(["@@ -2,5 +2,5 @@", "@@ -17,3 +17,3 @@"]);
+  });
+
+  it("handles creation and deletion", () => {
+    expect(diffLines("", "x\ny")).toEqual({ diff: "@@ -0,0 +1,2 @@\n+x\n+y", added: 2, removed: 0 });
+    expect(diffLines("x", "")).toEqual({ diff: "@@ -1,1 +0,0 @@\n-x", added: 0, removed: 1 });
+  });
+});
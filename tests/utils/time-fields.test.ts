@@ -0,0 +1,94 @@
+import { describe, expect, it, vi } from "vitest";
+import { isTimeKey, normalizeResultTimes, normalizeTimeFields, toEpochMs, withTimeFields } from "../../src/utils/time-fields.js";
+import { errorResult, jsonResult } from "../../src/utils/response-formatter.js";
+
+describe("isTimeKey", () => {
+  it("matches camelCase, snake_case, and bare Harness time names", () => {
+    for (const key of ["createdAt", "startTs", "endTime", "lastHeartBeat", "lastUpdated", "created_at", "start_ts", "created", "lastModified", "deadline", "revokeAfter", "lastRunTime"]) {
+      expect(isTimeKey(key), key).toBe(true);
+    }
+    for (const key of ["id", "format", "timeoutMs", "count", "createdAt_epoch_ms", "status"]) {
+      expect(isTimeKey(key), key).toBe(false);
+    }
+  });
+
+  it("does not match duration fields", () => {
+    for (const key of ["durationTime", "executionTime", "totalTime", "leadTime", "cycleTime", "waitTime", "upTime", "elapsedTime", "latencyTs", "processing_time", "responseTime", "wait_time_ms"]) {
+      expect(isTimeKey(key), key).toBe(false);
+    }
+  });
+});
+
+describe("toEpochMs", () => {
+  it("accepts millis, seconds, and digit-only strings within 2000–2100", () => {
+    expect(toEpochMs(1752000000000)).toBe(1752000000000);
+    expect(toEpochMs(1752000000)).toBe(1752000000000);
+    expect(toEpochMs("1752000000000")).toBe(1752000000000);
+    expect(toEpochMs(120000)).toBeUndefined();
+    expect(toEpochMs(0)).toBeUndefined();
+    expect(toEpochMs(1752000000000.5)).toBeUndefined();
+    expect(toEpochMs("2025-07-08T18:40:00Z")).toBeUndefined();
+  });
+});
+
+describe("normalizeTimeFields", () => {
+  it("rewrites epoch fields at any depth and keeps the instant under <field>_epoch_ms", () => {
+    const input = {
+      createdAt: 1752000000000,
+      items: [{ startTs: 1752000000, name: "deploy", durationTime: 5400 }],
+      details: { created_at: "1752000000000", updated_at: "2025-07-08 18:40" },
+    };
+    expect(normalizeTimeFields(input)).toEqual({
+      createdAt: "2025-07-08T18:40:00.000Z",
+      createdAt_epoch_ms: 1752000000000,
+      items: [{ startTs: "2025-07-08T18:40:00.000Z", startTs_epoch_ms: 1752000000000, name: "deploy", durationTime: 5400 }],
+      details: { created_at: "2025-07-08T18:40:00.000Z", created_at_epoch_ms: 1752000000000, updated_at: "2025-07-08 18:40" },
+    });
+    expect(input.createdAt).toBe(1752000000000);
+  });
+
+  it("leaves duration fields alone even when the value is in the epoch range", () => {
+    // 1.75 s in nanoseconds and about 55 years in seconds both look like epoch seconds.
+    const input = { startTs: 1752000000000, executionTime: 1_750_000_000, durationTime: 1_752_000_000, stage: { totalTime: 1_752_000_000_000 } };
+    expect(normalizeTimeFields(input)).toEqual({
+      startTs: "2025-07-08T18:40:00.000Z",
+      startTs_epoch_ms: 1752000000000,
+      executionTime: 1_750_000_000,
+      durationTime: 1_752_000_000,
+      stage: { totalTime: 1_752_000_000_000 },
+    });
+  });
+
+  it("places the epoch key right after the field and leaves fields that already have one", () => {
+    const result = normalizeTimeFields({ a: 1, createdAt: 1752000000000, b: 2, endTs: 1752000000000, endTs_epoch_ms: 1 }) as Record<string, unknown>;
+    expect(Object.keys(result)).toEqual(["a", "createdAt", "createdAt_epoch_ms", "b", "endTs", "endTs_epoch_ms"]);
+    expect(result.endTs).toBe(1752000000000);
+  });
+});
+
+describe("normalizeResultTimes", () => {
+  it("normalizes JSON text and structuredContent but not errors or non-JSON text", () => {
+    const result = normalizeResultTimes(jsonResult({ createdAt: 1752000000000 }));
+    expect(JSON.parse(result.content[0]!.text)).toEqual({ createdAt: "2025-07-08T18:40:00.000Z", createdAt_epoch_ms: 1752000000000 });
+    expect(result.structuredContent).toEqual({ createdAt: "2025-07-08T18:40:00.000Z", createdAt_epoch_ms: 1752000000000 });
+
+    const error = errorResult("failed at 1752000000000");
+    expect(normalizeResultTimes(error)).toBe(error);
+
+    const text = { content: [{ type: "text" as const, text: "createdAt: 1752000000000" }] };
+    expect(normalizeResultTimes(text).content[0]!.text).toBe("createdAt: 1752000000000");
+  });
+});
+
+describe("withTimeFields", () => {
+  it("normalizes results of tools registered through it when the format is rfc3339", async () => {
+    const registerTool = vi.fn();
+    withTimeFields({ registerTool } as never, "rfc3339").registerTool("harness_get", {} as never, (async () => jsonResult({ startTs: 1752000000000 })) as never);
+    const wrapped = registerTool.mock.calls[0]![2] as (args: Record<string, unknown>, extra: unknown) => Promise<{ content: Array<{ text: string }> }>;
+    expect(JSON.parse((await wrapped({}, {})).content[0]!.text)).toEqual({ startTs: "2025-07-08T18:40:00.000Z", startTs_epoch_ms: 1752000000000 });
+
+    const server = { registerTool: vi.fn() };
+    expect(withTimeFields(server as never, "raw")).toBe(server);
+    expect(withTimeFields(server as never)).toBe(server);
+  });
+});
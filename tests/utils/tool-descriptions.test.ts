@@ -0,0 +1,49 @@
+import { describe, expect, it, vi } from "vitest";
+import * as z from "zod/v4";
+import { getFullToolDescription, shortenDescription, withToolDescriptions } from "../../src/utils/tool-descriptions.js";
+
+const DESCRIPTION = "Run an action on a Harness resource. Use harness_describe to find actions. Writes ask for confirmation first.";
+
+function register(mode: "full" | "short") {
+  const registerTool = vi.fn();
+  withToolDescriptions({ registerTool } as never, mode).registerTool("harness_demo", {
+    description: DESCRIPTION,
+    inputSchema: {
+      action: z.string().describe("Action to run. See executeActions in harness_describe output."),
+      confirm: z.boolean().optional(),
+    },
+  } as never, (async () => ({ content: [] })) as never);
+  return registerTool.mock.calls[0]![1] as { description: string; inputSchema: Record<string, { description?: string }> };
+}
+
+describe("shortenDescription", () => {
+  it("keeps the first sentence and cuts long ones at a word boundary", () => {
+    expect(shortenDescription(DESCRIPTION)).toBe("Run an action on a Harness resource.");
+    expect(shortenDescription("List resources by type (v1.2 API)")).toBe("List resources by type (v1.2 API)");
+    const long = shortenDescription(`${"word ".repeat(50)}end.`);
+    expect(long.length).toBeLessThanOrEqual(163);
+    expect(long.endsWith("word...")).toBe(true);
+  });
+});
+
+describe("withToolDescriptions", () => {
+  it("registers full descriptions unchanged and records them", () => {
+    const config = register("full");
+
+    expect(config.description).toBe(DESCRIPTION);
+    expect(getFullToolDescription("harness_demo")).toEqual({
+      tool: "harness_demo",
+      description: DESCRIPTION,
+      parameters: { action: "Action to run. See executeActions in harness_describe output." },
+    });
+  });
+
+  it("shortens tool and parameter descriptions in short mode and keeps the full ones for harness_describe", () => {
+    const config = register("short");
+
+    expect(config.description).toBe("Run an action on a Harness resource. Full guidance: harness_describe(tool='harness_demo').");
+    expect(config.inputSchema.action!.description).toBe("Action to run.");
+    expect(config.inputSchema.confirm!.description).toBeUndefined();
+    expect(getFullToolDescription("harness_demo")!.description).toBe(DESCRIPTION);
+  });
+});
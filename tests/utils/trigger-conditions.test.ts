@@ -0,0 +1,145 @@
+import { describe, it, expect } from "vitest";
+import {
+  evaluateOperator,
+  evaluateTriggerConditions,
+  extractWebhookFacts,
+  readTriggerConditions,
+} from "../../src/utils/trigger-conditions.js";
+
+const githubPr = {
+  action: "opened",
+  repository: { full_name: "acme/web" },
+  pull_request: { head: { ref: "feature/login", sha: "abc123" }, base: { ref: "main" } },
+};
+
+const githubPush = {
+  ref: "refs/heads/release/1.2",
+  after: "def456",
+  head_commit: { id: "def456" },
+  repository: { full_name: "acme/web" },
+  commits: [{ added: ["src/a.ts"], modified: ["docs/readme.md"], removed: [] }],
+};
+
+const prTriggerYaml = `trigger:
+  identifier: pr_main
+  source:
+    type: Webhook
+    spec:
+      type: Github
+      spec:
+        type: PullRequest
+        spec:
+          connectorRef: github
+          actions: [Open, Reopen]
+          payloadConditions:
+            - key: targetBranch
+              operator: Equals
+              value: main
+            - key: sourceBranch
+              operator: StartsWith
+              value: release/
+            - key: <+trigger.payload.repository.full_name>
+              operator: In
+              value: acme/web, acme/api
+          headerConditions:
+            - key: X-GitHub-Event
+              operator: Equals
+              value: pull_request
+          jexlCondition: <+trigger.payload.pull_request.draft> == false
+`;
+
+describe("extractWebhookFacts", () => {
+  it("reads branches and commit from a GitHub pull request", () => {
+    expect(extractWebhookFacts(githubPr)).toEqual({
+      provider: "Github",
+      event_type: "PullRequest",
+      action: "opened",
+      repo: "acme/web",
+      source_branch: "feature/login",
+      target_branch: "main",
+      commit_sha: "abc123",
+    });
+  });
+
+  it("reads the pushed branch and changed files from a GitHub push", () => {
+    const facts = extractWebhookFacts(githubPush);
+    expect(facts).toMatchObject({ event_type: "Push", source_branch: "release/1.2", target_branch: "release/1.2", commit_sha: "def456" });
+    expect(facts.changed_files).toEqual(["src/a.ts", "docs/readme.md"]);
+  });
+
+  it("reads GitLab merge requests and Bitbucket pushes", () => {
+    expect(extractWebhookFacts({
+      object_kind: "merge_request",
+      object_attributes: { source_branch: "fix", target_branch: "develop", last_commit: { id: "c1" } },
+    })).toMatchObject({ provider: "Gitlab", source_branch: "fix", target_branch: "develop", commit_sha: "c1" });
+    expect(extractWebhookFacts({
+      push: { changes: [{ new: { name: "main", target: { hash: "c2" } } }] },
+    })).toMatchObject({ provider: "Bitbucket", target_branch: "main", commit_sha: "c2" });
+  });
+
+  it("returns nothing for unknown payloads", () => {
+    expect(extractWebhookFacts({ hello: "world" })).toEqual({});
+    expect(extractWebhookFacts("text")).toEqual({});
+  });
+});
+
+describe("readTriggerConditions", () => {
+  it("reads provider, event, actions, conditions, and JEXL from trigger YAML", () => {
+    const spec = readTriggerConditions(prTriggerYaml);
+    expect(spec).toMatchObject({ provider: "Github", event_type: "PullRequest", actions: ["Open", "Reopen"] });
+    expect(spec?.conditions).toHaveLength(4);
+    expect(spec?.conditions[3]).toEqual({ kind: "header", key: "X-GitHub-Event", operator: "Equals", value: "pull_request" });
+    expect(spec?.jexl_condition).toContain("draft");
+  });
+
+  it("reads custom webhook conditions one level up", () => {
+    const spec = readTriggerConditions({
+      trigger: { source: { type: "Webhook", spec: { type: "Custom", spec: { payloadConditions: [{ key: "<+trigger.payload.env>", operator: "Equals", value: "prod" }] } } } },
+    });
+    expect(spec).toMatchObject({ provider: "Custom", conditions: [{ key: "<+trigger.payload.env>", value: "prod" }] });
+    expect(spec?.event_type).toBeUndefined();
+  });
+
+  it("returns undefined for scheduled triggers", () => {
+    expect(readTriggerConditions({ trigger: { source: { type: "Scheduled", spec: {} } } })).toBeUndefined();
+  });
+});
+
+describe("evaluateTriggerConditions", () => {
+  it("reports actual values and which conditions matched", () => {
+    const results = evaluateTriggerConditions(readTriggerConditions(prTriggerYaml)!, githubPr, { "X-GitHub-Event": "pull_request" });
+    expect(results.map((r) => [r.key, r.actual, r.matched])).toEqual([
+      ["targetBranch", "main", true],
+      ["sourceBranch", "feature/login", false],
+      ["<+trigger.payload.repository.full_name>", "acme/web", true],
+      ["X-GitHub-Event", "pull_request", true],
+    ]);
+  });
+
+  it("marks conditions without a resolvable value as null", () => {
+    const [header] = evaluateTriggerConditions(
+      { actions: [], conditions: [{ kind: "header", key: "X-Missing", operator: "Equals", value: "x" }] },
+      githubPr,
+    );
+    expect(header).toMatchObject({ matched: null });
+    expect(header?.actual).toBeUndefined();
+  });
+
+  it("matches changedFiles when any file matches", () => {
+    const [result] = evaluateTriggerConditions(
+      { actions: [], conditions: [{ kind: "payload", key: "changedFiles", operator: "Regex", value: "^docs/" }] },
+      githubPush,
+    );
+    expect(result?.matched).toBe(true);
+  });
+});
+
+describe("evaluateOperator", () => {
+  it("implements Harness operators", () => {
+    expect(evaluateOperator("NotIn", "dev", "main, release")).toBe(true);
+    expect(evaluateOperator("EndsWith", "hotfix-1", "-1")).toBe(true);
+    expect(evaluateOperator("DoesNotContain", "feature/x", "feature")).toBe(false);
+    expect(evaluateOperator("Regex", "x", "(")).toBeNull();
+    expect(evaluateOperator("Unknown", "x", "x")).toBeNull();
+  });
+});
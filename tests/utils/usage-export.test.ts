@@ -0,0 +1,70 @@
+import { afterEach, describe, expect, it, vi } from "vitest";
+import type { AuditEvent } from "../../src/audit/types.js";
+import { recordUsage, resetUsageMetrics, summarizeUsageByAccount } from "../../src/utils/usage-metrics.js";
+import { UsageDashboardExporter, buildUsageDashboardSnapshot } from "../../src/utils/usage-export.js";
+
+afterEach(() => {
+  resetUsageMetrics();
+  vi.useRealTimers();
+});
+
+function event(overrides: Partial<AuditEvent> = {}): AuditEvent {
+  return {
+    event_id: "e1",
+    timestamp: "2026-01-01T00:00:00.000Z",
+    tool: "harness_list",
+    operation: "list",
+    resource_type: "pipeline",
+    account_id: "acct-a",
+    risk: "read",
+    outcome: "success",
+    duration_ms: 100,
+    ...overrides,
+  };
+}
+
+describe("buildUsageDashboardSnapshot", () => {
+  it("totals calls and error rates across accounts and tools", () => {
+    recordUsage(event());
+    recordUsage(event({ outcome: "error", duration_ms: 300 }));
+    recordUsage(event({ account_id: "acct-b", tool: "harness_execute", risk: "high_write" }));
+
+    const snapshot = buildUsageDashboardSnapshot(summarizeUsageByAccount());
+
+    expect(snapshot.totals).toEqual({ accounts: 2, calls: 3, errors: 1, blocked: 0, writes: 1, error_rate: 0.3333 });
+    expect(snapshot.top_accounts.map((a) => [a.account_id, a.calls, a.error_rate])).toEqual([["acct-a", 2, 0.5], ["acct-b", 1, 0]]);
+    expect(snapshot.tools).toEqual([
+      { tool: "harness_list", calls: 2, errors: 1, error_rate: 0.5, avg_duration_ms: 200 },
+      { tool: "harness_execute", calls: 1, errors: 0, error_rate: 0, avg_duration_ms: 100 },
+    ]);
+  });
+});
+
+describe("UsageDashboardExporter", () => {
+  it("pushes a snapshot each interval and a final one on stop", async () => {
+    vi.useFakeTimers();
+    const request = vi.fn().mockResolvedValue({});
+    const exporter = new UsageDashboardExporter({ request }, { dashboardId: "42", intervalMs: 60_000 });
+    recordUsage(event());
+
+    exporter.start();
+    await vi.advanceTimersByTimeAsync(60_000);
+    expect(request).toHaveBeenCalledTimes(1);
+    expect(request.mock.calls[0]![0]).toMatchObject({
+      method: "POST",
+      path: "/dashboard/v1/dashboards/42/custom-data",
+      body: { source: "harness-mcp-server", totals: { calls: 1 } },
+    });
+
+    await exporter.stop();
+    expect(request).toHaveBeenCalledTimes(2);
+  });
+
+  it("reports a failed push without throwing", async () => {
+    const exporter = new UsageDashboardExporter(
+      { request: vi.fn().mockRejectedValue(new Error("403 Forbidden")) },
+      { dashboardId: "42", intervalMs: 60_000 },
+    );
+    await expect(exporter.push()).resolves.toBe(false);
+  });
+});
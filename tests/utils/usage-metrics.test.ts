@@ -0,0 +1,80 @@
+import { afterEach, describe, expect, it } from "vitest";
+import type { AuditEvent } from "../../src/audit/types.js";
+import {
+  OVERFLOW_ACCOUNT,
+  accountLabel,
+  configureUsageMetrics,
+  recordUsage,
+  renderUsageMetrics,
+  resetUsageMetrics,
+  summarizeUsageByAccount,
+} from "../../src/utils/usage-metrics.js";
+
+afterEach(() => resetUsageMetrics());
+
+function event(overrides: Partial<AuditEvent> = {}): AuditEvent {
+  return {
+    event_id: "e1",
+    timestamp: "2026-01-01T00:00:00.000Z",
+    tool: "harness_list",
+    operation: "list",
+    resource_type: "pipeline",
+    account_id: "acct-a",
+    risk: "read",
+    outcome: "success",
+    duration_ms: 100,
+    ...overrides,
+  };
+}
+
+describe("usage metrics", () => {
+  it("counts calls per account and tool in Prometheus format", () => {
+    recordUsage(event());
+    recordUsage(event({ outcome: "error", duration_ms: 50 }));
+    recordUsage(event({ account_id: "acct-b", tool: "harness_execute", operation: "execute", risk: "medium_write", outcome: "blocked" }));
+
+    const text = renderUsageMetrics();
+    expect(text).toContain('harness_mcp_api_calls_total{account="acct-a",tool="harness_list",outcome="success"} 1');
+    expect(text).toContain('harness_mcp_api_calls_total{account="acct-a",tool="harness_list",outcome="error"} 1');
+    expect(text).toContain('harness_mcp_api_calls_total{account="acct-b",tool="harness_execute",outcome="blocked"} 1');
+    expect(text).toContain('harness_mcp_api_call_duration_ms_total{account="acct-a",tool="harness_list"} 150');
+    expect(text).toContain("harness_mcp_metrics_accounts_overflow_total 0");
+  });
+
+  it("summarizes usage per account, busiest first", () => {
+    recordUsage(event({ account_id: "acct-b" }));
+    recordUsage(event());
+    recordUsage(event({ timestamp: "2026-01-02T00:00:00.000Z", tool: "harness_update", risk: "low_write", resource_type: "service" }));
+
+    const report = summarizeUsageByAccount();
+    expect(report.accounts.map((a) => a.account_id)).toEqual(["acct-a", "acct-b"]);
+    expect(report.accounts[0]).toMatchObject({
+      calls: 2,
+      errors: 0,
+      writes: 1,
+      duration_ms: 200,
+      first_seen: "2026-01-01T00:00:00.000Z",
+      last_seen: "2026-01-02T00:00:00.000Z",
+      top_resource_types: [
+        { resource_type: "pipeline", calls: 1 },
+        { resource_type: "service", calls: 1 },
+      ],
+    });
+    expect(report.accounts[0]!.tools.map((t) => t.tool)).toEqual(["harness_list", "harness_update"]);
+  });
+
+  it("folds accounts beyond the cardinality cap into __other__", () => {
+    configureUsageMetrics({ maxAccounts: 2 });
+    expect(accountLabel("acct-a")).toBe("acct-a");
+    expect(accountLabel("acct-b")).toBe("acct-b");
+    expect(accountLabel("acct-c")).toBe(OVERFLOW_ACCOUNT);
+    expect(accountLabel("acct-a")).toBe("acct-a");
+
+    recordUsage(event({ account_id: "acct-d" }));
+    const report = summarizeUsageByAccount();
+    expect(report.max_accounts).toBe(2);
+    expect(report.overflow_events).toBe(2);
+    expect(report.accounts.map((a) => a.account_id)).toEqual([OVERFLOW_ACCOUNT]);
+    expect(renderUsageMetrics()).toContain("harness_mcp_metrics_accounts_overflow_total 2");
+  });
+});